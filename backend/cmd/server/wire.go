@@ -92,9 +92,13 @@ func provideCleanup(
 	proxyExpiry *service.ProxyExpiryService,
 	subscriptionExpiry *service.SubscriptionExpiryService,
 	usageCleanup *service.UsageCleanupService,
+	compositeExperiments *service.CompositeRouteExperimentService,
+	trafficMirror *service.TrafficMirrorService,
 	idempotencyCleanup *service.IdempotencyCleanupService,
 	batchImageCleanup *service.BatchImageCleanupService,
 	batchImageWorker *service.BatchImageWorkerRuntime,
+	messageBatch *service.MessageBatchService,
+	openAIBatch *service.OpenAIBatchService,
 	pricing *service.PricingService,
 	emailQueue *service.EmailQueueService,
 	billingCache *service.BillingCacheService,
@@ -109,6 +113,11 @@ func provideCleanup(
 	scheduledTestRunner *service.ScheduledTestRunnerService,
 	backupSvc *service.BackupService,
 	paymentOrderExpiry *service.PaymentOrderExpiryService,
+	subscriptionAutoRenewal *service.SubscriptionAutoRenewalService,
+	balanceLedger *service.BalanceLedgerService,
+	creditStatement *service.CreditStatementService,
+	spendAnomaly *service.SpendAnomalyService,
+	usageExport *service.UsageExportService,
 	channelMonitorRunner *service.ChannelMonitorRunner,
 	channelMonitorV2Aggregator *service.ChannelMonitorV2Aggregator,
 	quotaFlusher *service.UserPlatformQuotaUsageFlusher,
@@ -212,6 +221,18 @@ func provideCleanup(
 				}
 				return nil
 			}},
+			{"CompositeRouteExperimentService", func() error {
+				if compositeExperiments != nil {
+					compositeExperiments.Stop()
+				}
+				return nil
+			}},
+			{"TrafficMirrorService", func() error {
+				if trafficMirror != nil {
+					trafficMirror.Stop()
+				}
+				return nil
+			}},
 			{"IdempotencyCleanupService", func() error {
 				if idempotencyCleanup != nil {
 					idempotencyCleanup.Stop()
@@ -230,6 +251,18 @@ func provideCleanup(
 				}
 				return nil
 			}},
+			{"MessageBatchService", func() error {
+				if messageBatch != nil {
+					messageBatch.Stop()
+				}
+				return nil
+			}},
+			{"OpenAIBatchService", func() error {
+				if openAIBatch != nil {
+					openAIBatch.Stop()
+				}
+				return nil
+			}},
 			{"TokenRefreshService", func() error {
 				tokenRefresh.Stop()
 				return nil
@@ -320,6 +353,36 @@ func provideCleanup(
 				}
 				return nil
 			}},
+			{"SubscriptionAutoRenewalService", func() error {
+				if subscriptionAutoRenewal != nil {
+					subscriptionAutoRenewal.Stop()
+				}
+				return nil
+			}},
+			{"BalanceLedgerService", func() error {
+				if balanceLedger != nil {
+					balanceLedger.Stop()
+				}
+				return nil
+			}},
+			{"CreditStatementService", func() error {
+				if creditStatement != nil {
+					creditStatement.Stop()
+				}
+				return nil
+			}},
+			{"SpendAnomalyService", func() error {
+				if spendAnomaly != nil {
+					spendAnomaly.Stop()
+				}
+				return nil
+			}},
+			{"UsageExportService", func() error {
+				if usageExport != nil {
+					usageExport.Stop()
+				}
+				return nil
+			}},
 			{"ChannelMonitorV2Aggregator", func() error {
 			if channelMonitorV2Aggregator != nil {
 				channelMonitorV2Aggregator.Stop()
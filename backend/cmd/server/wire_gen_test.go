@@ -70,9 +70,13 @@ func TestProvideCleanup_WithMinimalDependencies_NoPanic(t *testing.T) {
 		proxyExpirySvc,
 		subscriptionExpirySvc,
 		&service.UsageCleanupService{},
+		nil, // compositeRouteExperiment
+		nil, // trafficMirror
 		idempotencyCleanupSvc,
 		&service.BatchImageCleanupService{},
 		nil, // batchImageWorker
+		nil, // messageBatch
+		nil, // openAIBatch
 		pricingSvc,
 		emailQueueSvc,
 		billingCacheSvc,
@@ -87,6 +91,11 @@ func TestProvideCleanup_WithMinimalDependencies_NoPanic(t *testing.T) {
 		nil, // scheduledTestRunner
 		nil, // backupSvc
 		nil, // paymentOrderExpiry
+		nil, // subscriptionAutoRenewal
+		nil, // balanceLedger
+		nil, // creditStatement
+		nil, // spendAnomaly
+		nil, // usageExport
 		nil, // channelMonitorRunner
 		nil, // channelMonitorV2Aggregator
 		nil, // quotaFlusher
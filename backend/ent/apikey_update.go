@@ -190,6 +190,42 @@ func (_u *APIKeyUpdate) ClearIPBlacklist() *APIKeyUpdate {
 	return _u
 }
 
+// SetModelAllowlist sets the "model_allowlist" field.
+func (_u *APIKeyUpdate) SetModelAllowlist(v []string) *APIKeyUpdate {
+	_u.mutation.SetModelAllowlist(v)
+	return _u
+}
+
+// AppendModelAllowlist appends value to the "model_allowlist" field.
+func (_u *APIKeyUpdate) AppendModelAllowlist(v []string) *APIKeyUpdate {
+	_u.mutation.AppendModelAllowlist(v)
+	return _u
+}
+
+// ClearModelAllowlist clears the value of the "model_allowlist" field.
+func (_u *APIKeyUpdate) ClearModelAllowlist() *APIKeyUpdate {
+	_u.mutation.ClearModelAllowlist()
+	return _u
+}
+
+// SetModelDenylist sets the "model_denylist" field.
+func (_u *APIKeyUpdate) SetModelDenylist(v []string) *APIKeyUpdate {
+	_u.mutation.SetModelDenylist(v)
+	return _u
+}
+
+// AppendModelDenylist appends value to the "model_denylist" field.
+func (_u *APIKeyUpdate) AppendModelDenylist(v []string) *APIKeyUpdate {
+	_u.mutation.AppendModelDenylist(v)
+	return _u
+}
+
+// ClearModelDenylist clears the value of the "model_denylist" field.
+func (_u *APIKeyUpdate) ClearModelDenylist() *APIKeyUpdate {
+	_u.mutation.ClearModelDenylist()
+	return _u
+}
+
 // SetQuota sets the "quota" field.
 func (_u *APIKeyUpdate) SetQuota(v float64) *APIKeyUpdate {
 	_u.mutation.ResetQuota()
@@ -438,6 +474,48 @@ func (_u *APIKeyUpdate) ClearWindow7dStart() *APIKeyUpdate {
 	return _u
 }
 
+// SetRpmLimit sets the "rpm_limit" field.
+func (_u *APIKeyUpdate) SetRpmLimit(v int) *APIKeyUpdate {
+	_u.mutation.ResetRpmLimit()
+	_u.mutation.SetRpmLimit(v)
+	return _u
+}
+
+// SetNillableRpmLimit sets the "rpm_limit" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableRpmLimit(v *int) *APIKeyUpdate {
+	if v != nil {
+		_u.SetRpmLimit(*v)
+	}
+	return _u
+}
+
+// AddRpmLimit adds value to the "rpm_limit" field.
+func (_u *APIKeyUpdate) AddRpmLimit(v int) *APIKeyUpdate {
+	_u.mutation.AddRpmLimit(v)
+	return _u
+}
+
+// SetMaxConcurrency sets the "max_concurrency" field.
+func (_u *APIKeyUpdate) SetMaxConcurrency(v int) *APIKeyUpdate {
+	_u.mutation.ResetMaxConcurrency()
+	_u.mutation.SetMaxConcurrency(v)
+	return _u
+}
+
+// SetNillableMaxConcurrency sets the "max_concurrency" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableMaxConcurrency(v *int) *APIKeyUpdate {
+	if v != nil {
+		_u.SetMaxConcurrency(*v)
+	}
+	return _u
+}
+
+// AddMaxConcurrency adds value to the "max_concurrency" field.
+func (_u *APIKeyUpdate) AddMaxConcurrency(v int) *APIKeyUpdate {
+	_u.mutation.AddMaxConcurrency(v)
+	return _u
+}
+
 // SetUser sets the "user" edge to the User entity.
 func (_u *APIKeyUpdate) SetUser(v *User) *APIKeyUpdate {
 	return _u.SetUserID(v.ID)
@@ -624,6 +702,28 @@ func (_u *APIKeyUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if _u.mutation.IPBlacklistCleared() {
 		_spec.ClearField(apikey.FieldIPBlacklist, field.TypeJSON)
 	}
+	if value, ok := _u.mutation.ModelAllowlist(); ok {
+		_spec.SetField(apikey.FieldModelAllowlist, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedModelAllowlist(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, apikey.FieldModelAllowlist, value)
+		})
+	}
+	if _u.mutation.ModelAllowlistCleared() {
+		_spec.ClearField(apikey.FieldModelAllowlist, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.ModelDenylist(); ok {
+		_spec.SetField(apikey.FieldModelDenylist, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedModelDenylist(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, apikey.FieldModelDenylist, value)
+		})
+	}
+	if _u.mutation.ModelDenylistCleared() {
+		_spec.ClearField(apikey.FieldModelDenylist, field.TypeJSON)
+	}
 	if value, ok := _u.mutation.Quota(); ok {
 		_spec.SetField(apikey.FieldQuota, field.TypeFloat64, value)
 	}
@@ -696,6 +796,18 @@ func (_u *APIKeyUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if _u.mutation.Window7dStartCleared() {
 		_spec.ClearField(apikey.FieldWindow7dStart, field.TypeTime)
 	}
+	if value, ok := _u.mutation.RpmLimit(); ok {
+		_spec.SetField(apikey.FieldRpmLimit, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedRpmLimit(); ok {
+		_spec.AddField(apikey.FieldRpmLimit, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.MaxConcurrency(); ok {
+		_spec.SetField(apikey.FieldMaxConcurrency, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedMaxConcurrency(); ok {
+		_spec.AddField(apikey.FieldMaxConcurrency, field.TypeInt, value)
+	}
 	if _u.mutation.UserCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2O,
@@ -977,6 +1089,42 @@ func (_u *APIKeyUpdateOne) ClearIPBlacklist() *APIKeyUpdateOne {
 	return _u
 }
 
+// SetModelAllowlist sets the "model_allowlist" field.
+func (_u *APIKeyUpdateOne) SetModelAllowlist(v []string) *APIKeyUpdateOne {
+	_u.mutation.SetModelAllowlist(v)
+	return _u
+}
+
+// AppendModelAllowlist appends value to the "model_allowlist" field.
+func (_u *APIKeyUpdateOne) AppendModelAllowlist(v []string) *APIKeyUpdateOne {
+	_u.mutation.AppendModelAllowlist(v)
+	return _u
+}
+
+// ClearModelAllowlist clears the value of the "model_allowlist" field.
+func (_u *APIKeyUpdateOne) ClearModelAllowlist() *APIKeyUpdateOne {
+	_u.mutation.ClearModelAllowlist()
+	return _u
+}
+
+// SetModelDenylist sets the "model_denylist" field.
+func (_u *APIKeyUpdateOne) SetModelDenylist(v []string) *APIKeyUpdateOne {
+	_u.mutation.SetModelDenylist(v)
+	return _u
+}
+
+// AppendModelDenylist appends value to the "model_denylist" field.
+func (_u *APIKeyUpdateOne) AppendModelDenylist(v []string) *APIKeyUpdateOne {
+	_u.mutation.AppendModelDenylist(v)
+	return _u
+}
+
+// ClearModelDenylist clears the value of the "model_denylist" field.
+func (_u *APIKeyUpdateOne) ClearModelDenylist() *APIKeyUpdateOne {
+	_u.mutation.ClearModelDenylist()
+	return _u
+}
+
 // SetQuota sets the "quota" field.
 func (_u *APIKeyUpdateOne) SetQuota(v float64) *APIKeyUpdateOne {
 	_u.mutation.ResetQuota()
@@ -1225,6 +1373,48 @@ func (_u *APIKeyUpdateOne) ClearWindow7dStart() *APIKeyUpdateOne {
 	return _u
 }
 
+// SetRpmLimit sets the "rpm_limit" field.
+func (_u *APIKeyUpdateOne) SetRpmLimit(v int) *APIKeyUpdateOne {
+	_u.mutation.ResetRpmLimit()
+	_u.mutation.SetRpmLimit(v)
+	return _u
+}
+
+// SetNillableRpmLimit sets the "rpm_limit" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableRpmLimit(v *int) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetRpmLimit(*v)
+	}
+	return _u
+}
+
+// AddRpmLimit adds value to the "rpm_limit" field.
+func (_u *APIKeyUpdateOne) AddRpmLimit(v int) *APIKeyUpdateOne {
+	_u.mutation.AddRpmLimit(v)
+	return _u
+}
+
+// SetMaxConcurrency sets the "max_concurrency" field.
+func (_u *APIKeyUpdateOne) SetMaxConcurrency(v int) *APIKeyUpdateOne {
+	_u.mutation.ResetMaxConcurrency()
+	_u.mutation.SetMaxConcurrency(v)
+	return _u
+}
+
+// SetNillableMaxConcurrency sets the "max_concurrency" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableMaxConcurrency(v *int) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetMaxConcurrency(*v)
+	}
+	return _u
+}
+
+// AddMaxConcurrency adds value to the "max_concurrency" field.
+func (_u *APIKeyUpdateOne) AddMaxConcurrency(v int) *APIKeyUpdateOne {
+	_u.mutation.AddMaxConcurrency(v)
+	return _u
+}
+
 // SetUser sets the "user" edge to the User entity.
 func (_u *APIKeyUpdateOne) SetUser(v *User) *APIKeyUpdateOne {
 	return _u.SetUserID(v.ID)
@@ -1441,6 +1631,28 @@ func (_u *APIKeyUpdateOne) sqlSave(ctx context.Context) (_node *APIKey, err erro
 	if _u.mutation.IPBlacklistCleared() {
 		_spec.ClearField(apikey.FieldIPBlacklist, field.TypeJSON)
 	}
+	if value, ok := _u.mutation.ModelAllowlist(); ok {
+		_spec.SetField(apikey.FieldModelAllowlist, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedModelAllowlist(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, apikey.FieldModelAllowlist, value)
+		})
+	}
+	if _u.mutation.ModelAllowlistCleared() {
+		_spec.ClearField(apikey.FieldModelAllowlist, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.ModelDenylist(); ok {
+		_spec.SetField(apikey.FieldModelDenylist, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedModelDenylist(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, apikey.FieldModelDenylist, value)
+		})
+	}
+	if _u.mutation.ModelDenylistCleared() {
+		_spec.ClearField(apikey.FieldModelDenylist, field.TypeJSON)
+	}
 	if value, ok := _u.mutation.Quota(); ok {
 		_spec.SetField(apikey.FieldQuota, field.TypeFloat64, value)
 	}
@@ -1513,6 +1725,18 @@ func (_u *APIKeyUpdateOne) sqlSave(ctx context.Context) (_node *APIKey, err erro
 	if _u.mutation.Window7dStartCleared() {
 		_spec.ClearField(apikey.FieldWindow7dStart, field.TypeTime)
 	}
+	if value, ok := _u.mutation.RpmLimit(); ok {
+		_spec.SetField(apikey.FieldRpmLimit, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedRpmLimit(); ok {
+		_spec.AddField(apikey.FieldRpmLimit, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.MaxConcurrency(); ok {
+		_spec.SetField(apikey.FieldMaxConcurrency, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedMaxConcurrency(); ok {
+		_spec.AddField(apikey.FieldMaxConcurrency, field.TypeInt, value)
+	}
 	if _u.mutation.UserCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2O,
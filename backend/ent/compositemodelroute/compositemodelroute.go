@@ -39,6 +39,8 @@ const (
 	FieldEnabled = "enabled"
 	// FieldNotes holds the string denoting the notes field in the database.
 	FieldNotes = "notes"
+	// FieldSplit holds the string denoting the split field in the database.
+	FieldSplit = "split"
 	// EdgeGroup holds the string denoting the group edge name in mutations.
 	EdgeGroup = "group"
 	// Table holds the table name of the compositemodelroute in the database.
@@ -67,6 +69,7 @@ var Columns = []string{
 	FieldPriority,
 	FieldEnabled,
 	FieldNotes,
+	FieldSplit,
 }
 
 // ValidColumn reports if the column name is valid (part of the table columns).
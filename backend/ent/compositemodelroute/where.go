@@ -715,6 +715,16 @@ func NotesContainsFold(v string) predicate.CompositeModelRoute {
 	return predicate.CompositeModelRoute(sql.FieldContainsFold(FieldNotes, v))
 }
 
+// SplitIsNil applies the IsNil predicate on the "split" field.
+func SplitIsNil() predicate.CompositeModelRoute {
+	return predicate.CompositeModelRoute(sql.FieldIsNull(FieldSplit))
+}
+
+// SplitNotNil applies the NotNil predicate on the "split" field.
+func SplitNotNil() predicate.CompositeModelRoute {
+	return predicate.CompositeModelRoute(sql.FieldNotNull(FieldSplit))
+}
+
 // HasGroup applies the HasEdge predicate on the "group" edge.
 func HasGroup() predicate.CompositeModelRoute {
 	return predicate.CompositeModelRoute(func(s *sql.Selector) {
@@ -13,6 +13,7 @@ import (
 	"entgo.io/ent/schema/field"
 	"github.com/Wei-Shaw/sub2api/ent/compositemodelroute"
 	"github.com/Wei-Shaw/sub2api/ent/group"
+	"github.com/Wei-Shaw/sub2api/internal/domain"
 )
 
 // CompositeModelRouteCreate is the builder for creating a CompositeModelRoute entity.
@@ -175,6 +176,12 @@ func (_c *CompositeModelRouteCreate) SetNillableNotes(v *string) *CompositeModel
 	return _c
 }
 
+// SetSplit sets the "split" field.
+func (_c *CompositeModelRouteCreate) SetSplit(v *domain.CompositeRouteSplit) *CompositeModelRouteCreate {
+	_c.mutation.SetSplit(v)
+	return _c
+}
+
 // SetGroup sets the "group" edge to the Group entity.
 func (_c *CompositeModelRouteCreate) SetGroup(v *Group) *CompositeModelRouteCreate {
 	return _c.SetGroupID(v.ID)
@@ -389,6 +396,10 @@ func (_c *CompositeModelRouteCreate) createSpec() (*CompositeModelRoute, *sqlgra
 		_spec.SetField(compositemodelroute.FieldNotes, field.TypeString, value)
 		_node.Notes = &value
 	}
+	if value, ok := _c.mutation.Split(); ok {
+		_spec.SetField(compositemodelroute.FieldSplit, field.TypeJSON, value)
+		_node.Split = value
+	}
 	if nodes := _c.mutation.GroupIDs(); len(nodes) > 0 {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2O,
@@ -608,6 +619,24 @@ func (u *CompositeModelRouteUpsert) ClearNotes() *CompositeModelRouteUpsert {
 	return u
 }
 
+// SetSplit sets the "split" field.
+func (u *CompositeModelRouteUpsert) SetSplit(v *domain.CompositeRouteSplit) *CompositeModelRouteUpsert {
+	u.Set(compositemodelroute.FieldSplit, v)
+	return u
+}
+
+// UpdateSplit sets the "split" field to the value that was provided on create.
+func (u *CompositeModelRouteUpsert) UpdateSplit() *CompositeModelRouteUpsert {
+	u.SetExcluded(compositemodelroute.FieldSplit)
+	return u
+}
+
+// ClearSplit clears the value of the "split" field.
+func (u *CompositeModelRouteUpsert) ClearSplit() *CompositeModelRouteUpsert {
+	u.SetNull(compositemodelroute.FieldSplit)
+	return u
+}
+
 // UpdateNewValues updates the mutable fields using the new values that were set on create.
 // Using this option is equivalent to using:
 //
@@ -828,6 +857,27 @@ func (u *CompositeModelRouteUpsertOne) ClearNotes() *CompositeModelRouteUpsertOn
 	})
 }
 
+// SetSplit sets the "split" field.
+func (u *CompositeModelRouteUpsertOne) SetSplit(v *domain.CompositeRouteSplit) *CompositeModelRouteUpsertOne {
+	return u.Update(func(s *CompositeModelRouteUpsert) {
+		s.SetSplit(v)
+	})
+}
+
+// UpdateSplit sets the "split" field to the value that was provided on create.
+func (u *CompositeModelRouteUpsertOne) UpdateSplit() *CompositeModelRouteUpsertOne {
+	return u.Update(func(s *CompositeModelRouteUpsert) {
+		s.UpdateSplit()
+	})
+}
+
+// ClearSplit clears the value of the "split" field.
+func (u *CompositeModelRouteUpsertOne) ClearSplit() *CompositeModelRouteUpsertOne {
+	return u.Update(func(s *CompositeModelRouteUpsert) {
+		s.ClearSplit()
+	})
+}
+
 // Exec executes the query.
 func (u *CompositeModelRouteUpsertOne) Exec(ctx context.Context) error {
 	if len(u.create.conflict) == 0 {
@@ -1214,6 +1264,27 @@ func (u *CompositeModelRouteUpsertBulk) ClearNotes() *CompositeModelRouteUpsertB
 	})
 }
 
+// SetSplit sets the "split" field.
+func (u *CompositeModelRouteUpsertBulk) SetSplit(v *domain.CompositeRouteSplit) *CompositeModelRouteUpsertBulk {
+	return u.Update(func(s *CompositeModelRouteUpsert) {
+		s.SetSplit(v)
+	})
+}
+
+// UpdateSplit sets the "split" field to the value that was provided on create.
+func (u *CompositeModelRouteUpsertBulk) UpdateSplit() *CompositeModelRouteUpsertBulk {
+	return u.Update(func(s *CompositeModelRouteUpsert) {
+		s.UpdateSplit()
+	})
+}
+
+// ClearSplit clears the value of the "split" field.
+func (u *CompositeModelRouteUpsertBulk) ClearSplit() *CompositeModelRouteUpsertBulk {
+	return u.Update(func(s *CompositeModelRouteUpsert) {
+		s.ClearSplit()
+	})
+}
+
 // Exec executes the query.
 func (u *CompositeModelRouteUpsertBulk) Exec(ctx context.Context) error {
 	if u.create.err != nil {
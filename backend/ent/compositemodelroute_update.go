@@ -14,6 +14,7 @@ import (
 	"github.com/Wei-Shaw/sub2api/ent/compositemodelroute"
 	"github.com/Wei-Shaw/sub2api/ent/group"
 	"github.com/Wei-Shaw/sub2api/ent/predicate"
+	"github.com/Wei-Shaw/sub2api/internal/domain"
 )
 
 // CompositeModelRouteUpdate is the builder for updating CompositeModelRoute entities.
@@ -194,6 +195,18 @@ func (_u *CompositeModelRouteUpdate) ClearNotes() *CompositeModelRouteUpdate {
 	return _u
 }
 
+// SetSplit sets the "split" field.
+func (_u *CompositeModelRouteUpdate) SetSplit(v *domain.CompositeRouteSplit) *CompositeModelRouteUpdate {
+	_u.mutation.SetSplit(v)
+	return _u
+}
+
+// ClearSplit clears the value of the "split" field.
+func (_u *CompositeModelRouteUpdate) ClearSplit() *CompositeModelRouteUpdate {
+	_u.mutation.ClearSplit()
+	return _u
+}
+
 // SetGroup sets the "group" edge to the Group entity.
 func (_u *CompositeModelRouteUpdate) SetGroup(v *Group) *CompositeModelRouteUpdate {
 	return _u.SetGroupID(v.ID)
@@ -336,6 +349,12 @@ func (_u *CompositeModelRouteUpdate) sqlSave(ctx context.Context) (_node int, er
 	if _u.mutation.NotesCleared() {
 		_spec.ClearField(compositemodelroute.FieldNotes, field.TypeString)
 	}
+	if value, ok := _u.mutation.Split(); ok {
+		_spec.SetField(compositemodelroute.FieldSplit, field.TypeJSON, value)
+	}
+	if _u.mutation.SplitCleared() {
+		_spec.ClearField(compositemodelroute.FieldSplit, field.TypeJSON)
+	}
 	if _u.mutation.GroupCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2O,
@@ -550,6 +569,18 @@ func (_u *CompositeModelRouteUpdateOne) ClearNotes() *CompositeModelRouteUpdateO
 	return _u
 }
 
+// SetSplit sets the "split" field.
+func (_u *CompositeModelRouteUpdateOne) SetSplit(v *domain.CompositeRouteSplit) *CompositeModelRouteUpdateOne {
+	_u.mutation.SetSplit(v)
+	return _u
+}
+
+// ClearSplit clears the value of the "split" field.
+func (_u *CompositeModelRouteUpdateOne) ClearSplit() *CompositeModelRouteUpdateOne {
+	_u.mutation.ClearSplit()
+	return _u
+}
+
 // SetGroup sets the "group" edge to the Group entity.
 func (_u *CompositeModelRouteUpdateOne) SetGroup(v *Group) *CompositeModelRouteUpdateOne {
 	return _u.SetGroupID(v.ID)
@@ -722,6 +753,12 @@ func (_u *CompositeModelRouteUpdateOne) sqlSave(ctx context.Context) (_node *Com
 	if _u.mutation.NotesCleared() {
 		_spec.ClearField(compositemodelroute.FieldNotes, field.TypeString)
 	}
+	if value, ok := _u.mutation.Split(); ok {
+		_spec.SetField(compositemodelroute.FieldSplit, field.TypeJSON, value)
+	}
+	if _u.mutation.SplitCleared() {
+		_spec.ClearField(compositemodelroute.FieldSplit, field.TypeJSON)
+	}
 	if _u.mutation.GroupCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2O,
@@ -139,6 +139,20 @@ type Group struct {
 	ProfitMinMargin float64 `json:"profit_min_margin,omitempty"`
 	// 安全缓冲，小数；与 margin 相加后从下游倍率中扣除，默认 0
 	ProfitSafetyBuffer float64 `json:"profit_safety_buffer,omitempty"`
+	// 是否对该分组的确定性请求启用精确匹配响应缓存
+	ResponseCacheEnabled bool `json:"response_cache_enabled,omitempty"`
+	// 响应缓存有效期（秒），0 表示使用全局默认值
+	ResponseCacheTTLSeconds int `json:"response_cache_ttl_seconds,omitempty"`
+	// 缓存命中计费倍率，按原价乘以该值计费；0 表示命中免费
+	ResponseCacheHitMultiplier float64 `json:"response_cache_hit_multiplier,omitempty"`
+	// 月度累计消费阶梯 [{up_to_usd, multiplier}]，最后一档 up_to_usd 为空；空数组表示不启用
+	VolumeTiers []domain.VolumeTier `json:"volume_tiers,omitempty"`
+	// 是否允许 Anthropic/OpenAI 分组通过协议转换接入 Gemini 原生 generateContent 请求
+	AllowGeminiNative bool `json:"allow_gemini_native,omitempty"`
+	// OpenAI 非流式对冲请求策略 {enabled, percentile, min_delay_ms, max_delay_ms}
+	HedgePolicy domain.GroupHedgePolicy `json:"hedge_policy,omitempty"`
+	// 流量镜像策略 {enabled, sample_rate, target_group_id, target_account_id, compare_output}
+	MirrorPolicy domain.GroupMirrorPolicy `json:"mirror_policy,omitempty"`
 	// Edges holds the relations/edges for other nodes in the graph.
 	// The values are being populated by the GroupQuery when eager-loading is set.
 	Edges        GroupEdges `json:"edges"`
@@ -245,13 +259,13 @@ func (*Group) scanValues(columns []string) ([]any, error) {
 	values := make([]any, len(columns))
 	for i := range columns {
 		switch columns[i] {
-		case group.FieldVideoModelPrices, group.FieldModelRouting, group.FieldSupportedModelScopes, group.FieldMessagesDispatchModelConfig, group.FieldModelsListConfig, group.FieldReasoningEffortMappings:
+		case group.FieldVideoModelPrices, group.FieldModelRouting, group.FieldSupportedModelScopes, group.FieldMessagesDispatchModelConfig, group.FieldModelsListConfig, group.FieldReasoningEffortMappings, group.FieldVolumeTiers, group.FieldHedgePolicy, group.FieldMirrorPolicy:
 			values[i] = new([]byte)
-		case group.FieldPeakRateEnabled, group.FieldIsExclusive, group.FieldAllowImageGeneration, group.FieldAllowBatchImageGeneration, group.FieldImageRateIndependent, group.FieldVideoRateIndependent, group.FieldClaudeCodeOnly, group.FieldModelRoutingEnabled, group.FieldMcpXMLInject, group.FieldAllowMessagesDispatch, group.FieldAllowLive, group.FieldRequireOauthOnly, group.FieldRequirePrivacySet, group.FieldProfitControlEnabled:
+		case group.FieldPeakRateEnabled, group.FieldIsExclusive, group.FieldAllowImageGeneration, group.FieldAllowBatchImageGeneration, group.FieldImageRateIndependent, group.FieldVideoRateIndependent, group.FieldClaudeCodeOnly, group.FieldModelRoutingEnabled, group.FieldMcpXMLInject, group.FieldAllowMessagesDispatch, group.FieldAllowLive, group.FieldRequireOauthOnly, group.FieldRequirePrivacySet, group.FieldProfitControlEnabled, group.FieldResponseCacheEnabled, group.FieldAllowGeminiNative:
 			values[i] = new(sql.NullBool)
-		case group.FieldRateMultiplier, group.FieldPeakRateMultiplier, group.FieldDailyLimitUsd, group.FieldWeeklyLimitUsd, group.FieldMonthlyLimitUsd, group.FieldImageRateMultiplier, group.FieldImagePrice1k, group.FieldImagePrice2k, group.FieldImagePrice4k, group.FieldBatchImageDiscountMultiplier, group.FieldBatchImageHoldMultiplier, group.FieldVideoRateMultiplier, group.FieldVideoPrice480p, group.FieldVideoPrice720p, group.FieldVideoPrice1080p, group.FieldWebSearchPricePerCall, group.FieldSearchPricePer1k, group.FieldAudioRealtimePricePerMin, group.FieldAudioTtsPricePerMillionChars, group.FieldAudioSttPricePerHour, group.FieldProfitMinMargin, group.FieldProfitSafetyBuffer:
+		case group.FieldRateMultiplier, group.FieldPeakRateMultiplier, group.FieldDailyLimitUsd, group.FieldWeeklyLimitUsd, group.FieldMonthlyLimitUsd, group.FieldImageRateMultiplier, group.FieldImagePrice1k, group.FieldImagePrice2k, group.FieldImagePrice4k, group.FieldBatchImageDiscountMultiplier, group.FieldBatchImageHoldMultiplier, group.FieldVideoRateMultiplier, group.FieldVideoPrice480p, group.FieldVideoPrice720p, group.FieldVideoPrice1080p, group.FieldWebSearchPricePerCall, group.FieldSearchPricePer1k, group.FieldAudioRealtimePricePerMin, group.FieldAudioTtsPricePerMillionChars, group.FieldAudioSttPricePerHour, group.FieldProfitMinMargin, group.FieldProfitSafetyBuffer, group.FieldResponseCacheHitMultiplier:
 			values[i] = new(sql.NullFloat64)
-		case group.FieldID, group.FieldDefaultValidityDays, group.FieldFallbackGroupID, group.FieldFallbackGroupIDOnInvalidRequest, group.FieldSortOrder, group.FieldRpmLimit:
+		case group.FieldID, group.FieldDefaultValidityDays, group.FieldFallbackGroupID, group.FieldFallbackGroupIDOnInvalidRequest, group.FieldSortOrder, group.FieldRpmLimit, group.FieldResponseCacheTTLSeconds:
 			values[i] = new(sql.NullInt64)
 		case group.FieldName, group.FieldDescription, group.FieldPeakStart, group.FieldPeakEnd, group.FieldStatus, group.FieldDuplicateOperationID, group.FieldPlatform, group.FieldSubscriptionType, group.FieldDefaultMappedModel, group.FieldMaxReasoningEffort:
 			values[i] = new(sql.NullString)
@@ -669,6 +683,54 @@ func (_m *Group) assignValues(columns []string, values []any) error {
 			} else if value.Valid {
 				_m.ProfitSafetyBuffer = value.Float64
 			}
+		case group.FieldResponseCacheEnabled:
+			if value, ok := values[i].(*sql.NullBool); !ok {
+				return fmt.Errorf("unexpected type %T for field response_cache_enabled", values[i])
+			} else if value.Valid {
+				_m.ResponseCacheEnabled = value.Bool
+			}
+		case group.FieldResponseCacheTTLSeconds:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field response_cache_ttl_seconds", values[i])
+			} else if value.Valid {
+				_m.ResponseCacheTTLSeconds = int(value.Int64)
+			}
+		case group.FieldResponseCacheHitMultiplier:
+			if value, ok := values[i].(*sql.NullFloat64); !ok {
+				return fmt.Errorf("unexpected type %T for field response_cache_hit_multiplier", values[i])
+			} else if value.Valid {
+				_m.ResponseCacheHitMultiplier = value.Float64
+			}
+		case group.FieldVolumeTiers:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field volume_tiers", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &_m.VolumeTiers); err != nil {
+					return fmt.Errorf("unmarshal field volume_tiers: %w", err)
+				}
+			}
+		case group.FieldAllowGeminiNative:
+			if value, ok := values[i].(*sql.NullBool); !ok {
+				return fmt.Errorf("unexpected type %T for field allow_gemini_native", values[i])
+			} else if value.Valid {
+				_m.AllowGeminiNative = value.Bool
+			}
+		case group.FieldHedgePolicy:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field hedge_policy", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &_m.HedgePolicy); err != nil {
+					return fmt.Errorf("unmarshal field hedge_policy: %w", err)
+				}
+			}
+		case group.FieldMirrorPolicy:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field mirror_policy", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &_m.MirrorPolicy); err != nil {
+					return fmt.Errorf("unmarshal field mirror_policy: %w", err)
+				}
+			}
 		default:
 			_m.selectValues.Set(columns[i], values[i])
 		}
@@ -962,6 +1024,27 @@ func (_m *Group) String() string {
 	builder.WriteString(", ")
 	builder.WriteString("profit_safety_buffer=")
 	builder.WriteString(fmt.Sprintf("%v", _m.ProfitSafetyBuffer))
+	builder.WriteString(", ")
+	builder.WriteString("response_cache_enabled=")
+	builder.WriteString(fmt.Sprintf("%v", _m.ResponseCacheEnabled))
+	builder.WriteString(", ")
+	builder.WriteString("response_cache_ttl_seconds=")
+	builder.WriteString(fmt.Sprintf("%v", _m.ResponseCacheTTLSeconds))
+	builder.WriteString(", ")
+	builder.WriteString("response_cache_hit_multiplier=")
+	builder.WriteString(fmt.Sprintf("%v", _m.ResponseCacheHitMultiplier))
+	builder.WriteString(", ")
+	builder.WriteString("volume_tiers=")
+	builder.WriteString(fmt.Sprintf("%v", _m.VolumeTiers))
+	builder.WriteString(", ")
+	builder.WriteString("allow_gemini_native=")
+	builder.WriteString(fmt.Sprintf("%v", _m.AllowGeminiNative))
+	builder.WriteString(", ")
+	builder.WriteString("hedge_policy=")
+	builder.WriteString(fmt.Sprintf("%v", _m.HedgePolicy))
+	builder.WriteString(", ")
+	builder.WriteString("mirror_policy=")
+	builder.WriteString(fmt.Sprintf("%v", _m.MirrorPolicy))
 	builder.WriteByte(')')
 	return builder.String()
 }
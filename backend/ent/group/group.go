@@ -136,6 +136,20 @@ const (
 	FieldProfitMinMargin = "profit_min_margin"
 	// FieldProfitSafetyBuffer holds the string denoting the profit_safety_buffer field in the database.
 	FieldProfitSafetyBuffer = "profit_safety_buffer"
+	// FieldResponseCacheEnabled holds the string denoting the response_cache_enabled field in the database.
+	FieldResponseCacheEnabled = "response_cache_enabled"
+	// FieldResponseCacheTTLSeconds holds the string denoting the response_cache_ttl_seconds field in the database.
+	FieldResponseCacheTTLSeconds = "response_cache_ttl_seconds"
+	// FieldResponseCacheHitMultiplier holds the string denoting the response_cache_hit_multiplier field in the database.
+	FieldResponseCacheHitMultiplier = "response_cache_hit_multiplier"
+	// FieldVolumeTiers holds the string denoting the volume_tiers field in the database.
+	FieldVolumeTiers = "volume_tiers"
+	// FieldAllowGeminiNative holds the string denoting the allow_gemini_native field in the database.
+	FieldAllowGeminiNative = "allow_gemini_native"
+	// FieldHedgePolicy holds the string denoting the hedge_policy field in the database.
+	FieldHedgePolicy = "hedge_policy"
+	// FieldMirrorPolicy holds the string denoting the mirror_policy field in the database.
+	FieldMirrorPolicy = "mirror_policy"
 	// EdgeAPIKeys holds the string denoting the api_keys edge name in mutations.
 	EdgeAPIKeys = "api_keys"
 	// EdgeRedeemCodes holds the string denoting the redeem_codes edge name in mutations.
@@ -271,6 +285,13 @@ var Columns = []string{
 	FieldProfitControlEnabled,
 	FieldProfitMinMargin,
 	FieldProfitSafetyBuffer,
+	FieldResponseCacheEnabled,
+	FieldResponseCacheTTLSeconds,
+	FieldResponseCacheHitMultiplier,
+	FieldVolumeTiers,
+	FieldAllowGeminiNative,
+	FieldHedgePolicy,
+	FieldMirrorPolicy,
 }
 
 var (
@@ -404,6 +425,20 @@ var (
 	DefaultProfitMinMargin float64
 	// DefaultProfitSafetyBuffer holds the default value on creation for the "profit_safety_buffer" field.
 	DefaultProfitSafetyBuffer float64
+	// DefaultResponseCacheEnabled holds the default value on creation for the "response_cache_enabled" field.
+	DefaultResponseCacheEnabled bool
+	// DefaultResponseCacheTTLSeconds holds the default value on creation for the "response_cache_ttl_seconds" field.
+	DefaultResponseCacheTTLSeconds int
+	// DefaultResponseCacheHitMultiplier holds the default value on creation for the "response_cache_hit_multiplier" field.
+	DefaultResponseCacheHitMultiplier float64
+	// DefaultVolumeTiers holds the default value on creation for the "volume_tiers" field.
+	DefaultVolumeTiers []domain.VolumeTier
+	// DefaultAllowGeminiNative holds the default value on creation for the "allow_gemini_native" field.
+	DefaultAllowGeminiNative bool
+	// DefaultHedgePolicy holds the default value on creation for the "hedge_policy" field.
+	DefaultHedgePolicy domain.GroupHedgePolicy
+	// DefaultMirrorPolicy holds the default value on creation for the "mirror_policy" field.
+	DefaultMirrorPolicy domain.GroupMirrorPolicy
 )
 
 // OrderOption defines the ordering options for the Group queries.
@@ -684,6 +719,26 @@ func ByProfitSafetyBuffer(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldProfitSafetyBuffer, opts...).ToFunc()
 }
 
+// ByResponseCacheEnabled orders the results by the response_cache_enabled field.
+func ByResponseCacheEnabled(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldResponseCacheEnabled, opts...).ToFunc()
+}
+
+// ByResponseCacheTTLSeconds orders the results by the response_cache_ttl_seconds field.
+func ByResponseCacheTTLSeconds(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldResponseCacheTTLSeconds, opts...).ToFunc()
+}
+
+// ByResponseCacheHitMultiplier orders the results by the response_cache_hit_multiplier field.
+func ByResponseCacheHitMultiplier(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldResponseCacheHitMultiplier, opts...).ToFunc()
+}
+
+// ByAllowGeminiNative orders the results by the allow_gemini_native field.
+func ByAllowGeminiNative(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldAllowGeminiNative, opts...).ToFunc()
+}
+
 // ByAPIKeysCount orders the results by api_keys count.
 func ByAPIKeysCount(opts ...sql.OrderTermOption) OrderOption {
 	return func(s *sql.Selector) {
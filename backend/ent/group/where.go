@@ -325,6 +325,26 @@ func ProfitSafetyBuffer(v float64) predicate.Group {
 	return predicate.Group(sql.FieldEQ(FieldProfitSafetyBuffer, v))
 }
 
+// ResponseCacheEnabled applies equality check predicate on the "response_cache_enabled" field. It's identical to ResponseCacheEnabledEQ.
+func ResponseCacheEnabled(v bool) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldResponseCacheEnabled, v))
+}
+
+// ResponseCacheTTLSeconds applies equality check predicate on the "response_cache_ttl_seconds" field. It's identical to ResponseCacheTTLSecondsEQ.
+func ResponseCacheTTLSeconds(v int) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldResponseCacheTTLSeconds, v))
+}
+
+// ResponseCacheHitMultiplier applies equality check predicate on the "response_cache_hit_multiplier" field. It's identical to ResponseCacheHitMultiplierEQ.
+func ResponseCacheHitMultiplier(v float64) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldResponseCacheHitMultiplier, v))
+}
+
+// AllowGeminiNative applies equality check predicate on the "allow_gemini_native" field. It's identical to AllowGeminiNativeEQ.
+func AllowGeminiNative(v bool) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldAllowGeminiNative, v))
+}
+
 // CreatedAtEQ applies the EQ predicate on the "created_at" field.
 func CreatedAtEQ(v time.Time) predicate.Group {
 	return predicate.Group(sql.FieldEQ(FieldCreatedAt, v))
@@ -2525,6 +2545,106 @@ func ProfitSafetyBufferLTE(v float64) predicate.Group {
 	return predicate.Group(sql.FieldLTE(FieldProfitSafetyBuffer, v))
 }
 
+// ResponseCacheEnabledEQ applies the EQ predicate on the "response_cache_enabled" field.
+func ResponseCacheEnabledEQ(v bool) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldResponseCacheEnabled, v))
+}
+
+// ResponseCacheEnabledNEQ applies the NEQ predicate on the "response_cache_enabled" field.
+func ResponseCacheEnabledNEQ(v bool) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldResponseCacheEnabled, v))
+}
+
+// ResponseCacheTTLSecondsEQ applies the EQ predicate on the "response_cache_ttl_seconds" field.
+func ResponseCacheTTLSecondsEQ(v int) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldResponseCacheTTLSeconds, v))
+}
+
+// ResponseCacheTTLSecondsNEQ applies the NEQ predicate on the "response_cache_ttl_seconds" field.
+func ResponseCacheTTLSecondsNEQ(v int) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldResponseCacheTTLSeconds, v))
+}
+
+// ResponseCacheTTLSecondsIn applies the In predicate on the "response_cache_ttl_seconds" field.
+func ResponseCacheTTLSecondsIn(vs ...int) predicate.Group {
+	return predicate.Group(sql.FieldIn(FieldResponseCacheTTLSeconds, vs...))
+}
+
+// ResponseCacheTTLSecondsNotIn applies the NotIn predicate on the "response_cache_ttl_seconds" field.
+func ResponseCacheTTLSecondsNotIn(vs ...int) predicate.Group {
+	return predicate.Group(sql.FieldNotIn(FieldResponseCacheTTLSeconds, vs...))
+}
+
+// ResponseCacheTTLSecondsGT applies the GT predicate on the "response_cache_ttl_seconds" field.
+func ResponseCacheTTLSecondsGT(v int) predicate.Group {
+	return predicate.Group(sql.FieldGT(FieldResponseCacheTTLSeconds, v))
+}
+
+// ResponseCacheTTLSecondsGTE applies the GTE predicate on the "response_cache_ttl_seconds" field.
+func ResponseCacheTTLSecondsGTE(v int) predicate.Group {
+	return predicate.Group(sql.FieldGTE(FieldResponseCacheTTLSeconds, v))
+}
+
+// ResponseCacheTTLSecondsLT applies the LT predicate on the "response_cache_ttl_seconds" field.
+func ResponseCacheTTLSecondsLT(v int) predicate.Group {
+	return predicate.Group(sql.FieldLT(FieldResponseCacheTTLSeconds, v))
+}
+
+// ResponseCacheTTLSecondsLTE applies the LTE predicate on the "response_cache_ttl_seconds" field.
+func ResponseCacheTTLSecondsLTE(v int) predicate.Group {
+	return predicate.Group(sql.FieldLTE(FieldResponseCacheTTLSeconds, v))
+}
+
+// ResponseCacheHitMultiplierEQ applies the EQ predicate on the "response_cache_hit_multiplier" field.
+func ResponseCacheHitMultiplierEQ(v float64) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldResponseCacheHitMultiplier, v))
+}
+
+// ResponseCacheHitMultiplierNEQ applies the NEQ predicate on the "response_cache_hit_multiplier" field.
+func ResponseCacheHitMultiplierNEQ(v float64) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldResponseCacheHitMultiplier, v))
+}
+
+// ResponseCacheHitMultiplierIn applies the In predicate on the "response_cache_hit_multiplier" field.
+func ResponseCacheHitMultiplierIn(vs ...float64) predicate.Group {
+	return predicate.Group(sql.FieldIn(FieldResponseCacheHitMultiplier, vs...))
+}
+
+// ResponseCacheHitMultiplierNotIn applies the NotIn predicate on the "response_cache_hit_multiplier" field.
+func ResponseCacheHitMultiplierNotIn(vs ...float64) predicate.Group {
+	return predicate.Group(sql.FieldNotIn(FieldResponseCacheHitMultiplier, vs...))
+}
+
+// ResponseCacheHitMultiplierGT applies the GT predicate on the "response_cache_hit_multiplier" field.
+func ResponseCacheHitMultiplierGT(v float64) predicate.Group {
+	return predicate.Group(sql.FieldGT(FieldResponseCacheHitMultiplier, v))
+}
+
+// ResponseCacheHitMultiplierGTE applies the GTE predicate on the "response_cache_hit_multiplier" field.
+func ResponseCacheHitMultiplierGTE(v float64) predicate.Group {
+	return predicate.Group(sql.FieldGTE(FieldResponseCacheHitMultiplier, v))
+}
+
+// ResponseCacheHitMultiplierLT applies the LT predicate on the "response_cache_hit_multiplier" field.
+func ResponseCacheHitMultiplierLT(v float64) predicate.Group {
+	return predicate.Group(sql.FieldLT(FieldResponseCacheHitMultiplier, v))
+}
+
+// ResponseCacheHitMultiplierLTE applies the LTE predicate on the "response_cache_hit_multiplier" field.
+func ResponseCacheHitMultiplierLTE(v float64) predicate.Group {
+	return predicate.Group(sql.FieldLTE(FieldResponseCacheHitMultiplier, v))
+}
+
+// AllowGeminiNativeEQ applies the EQ predicate on the "allow_gemini_native" field.
+func AllowGeminiNativeEQ(v bool) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldAllowGeminiNative, v))
+}
+
+// AllowGeminiNativeNEQ applies the NEQ predicate on the "allow_gemini_native" field.
+func AllowGeminiNativeNEQ(v bool) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldAllowGeminiNative, v))
+}
+
 // HasAPIKeys applies the HasEdge predicate on the "api_keys" edge.
 func HasAPIKeys() predicate.Group {
 	return predicate.Group(func(s *sql.Selector) {
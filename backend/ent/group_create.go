@@ -829,6 +829,96 @@ func (_c *GroupCreate) SetNillableProfitSafetyBuffer(v *float64) *GroupCreate {
 	return _c
 }
 
+// SetResponseCacheEnabled sets the "response_cache_enabled" field.
+func (_c *GroupCreate) SetResponseCacheEnabled(v bool) *GroupCreate {
+	_c.mutation.SetResponseCacheEnabled(v)
+	return _c
+}
+
+// SetNillableResponseCacheEnabled sets the "response_cache_enabled" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableResponseCacheEnabled(v *bool) *GroupCreate {
+	if v != nil {
+		_c.SetResponseCacheEnabled(*v)
+	}
+	return _c
+}
+
+// SetResponseCacheTTLSeconds sets the "response_cache_ttl_seconds" field.
+func (_c *GroupCreate) SetResponseCacheTTLSeconds(v int) *GroupCreate {
+	_c.mutation.SetResponseCacheTTLSeconds(v)
+	return _c
+}
+
+// SetNillableResponseCacheTTLSeconds sets the "response_cache_ttl_seconds" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableResponseCacheTTLSeconds(v *int) *GroupCreate {
+	if v != nil {
+		_c.SetResponseCacheTTLSeconds(*v)
+	}
+	return _c
+}
+
+// SetResponseCacheHitMultiplier sets the "response_cache_hit_multiplier" field.
+func (_c *GroupCreate) SetResponseCacheHitMultiplier(v float64) *GroupCreate {
+	_c.mutation.SetResponseCacheHitMultiplier(v)
+	return _c
+}
+
+// SetNillableResponseCacheHitMultiplier sets the "response_cache_hit_multiplier" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableResponseCacheHitMultiplier(v *float64) *GroupCreate {
+	if v != nil {
+		_c.SetResponseCacheHitMultiplier(*v)
+	}
+	return _c
+}
+
+// SetVolumeTiers sets the "volume_tiers" field.
+func (_c *GroupCreate) SetVolumeTiers(v []domain.VolumeTier) *GroupCreate {
+	_c.mutation.SetVolumeTiers(v)
+	return _c
+}
+
+// SetAllowGeminiNative sets the "allow_gemini_native" field.
+func (_c *GroupCreate) SetAllowGeminiNative(v bool) *GroupCreate {
+	_c.mutation.SetAllowGeminiNative(v)
+	return _c
+}
+
+// SetNillableAllowGeminiNative sets the "allow_gemini_native" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableAllowGeminiNative(v *bool) *GroupCreate {
+	if v != nil {
+		_c.SetAllowGeminiNative(*v)
+	}
+	return _c
+}
+
+// SetHedgePolicy sets the "hedge_policy" field.
+func (_c *GroupCreate) SetHedgePolicy(v domain.GroupHedgePolicy) *GroupCreate {
+	_c.mutation.SetHedgePolicy(v)
+	return _c
+}
+
+// SetNillableHedgePolicy sets the "hedge_policy" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableHedgePolicy(v *domain.GroupHedgePolicy) *GroupCreate {
+	if v != nil {
+		_c.SetHedgePolicy(*v)
+	}
+	return _c
+}
+
+// SetMirrorPolicy sets the "mirror_policy" field.
+func (_c *GroupCreate) SetMirrorPolicy(v domain.GroupMirrorPolicy) *GroupCreate {
+	_c.mutation.SetMirrorPolicy(v)
+	return _c
+}
+
+// SetNillableMirrorPolicy sets the "mirror_policy" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableMirrorPolicy(v *domain.GroupMirrorPolicy) *GroupCreate {
+	if v != nil {
+		_c.SetMirrorPolicy(*v)
+	}
+	return _c
+}
+
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by IDs.
 func (_c *GroupCreate) AddAPIKeyIDs(ids ...int64) *GroupCreate {
 	_c.mutation.AddAPIKeyIDs(ids...)
@@ -1114,6 +1204,34 @@ func (_c *GroupCreate) defaults() error {
 		v := group.DefaultProfitSafetyBuffer
 		_c.mutation.SetProfitSafetyBuffer(v)
 	}
+	if _, ok := _c.mutation.ResponseCacheEnabled(); !ok {
+		v := group.DefaultResponseCacheEnabled
+		_c.mutation.SetResponseCacheEnabled(v)
+	}
+	if _, ok := _c.mutation.ResponseCacheTTLSeconds(); !ok {
+		v := group.DefaultResponseCacheTTLSeconds
+		_c.mutation.SetResponseCacheTTLSeconds(v)
+	}
+	if _, ok := _c.mutation.ResponseCacheHitMultiplier(); !ok {
+		v := group.DefaultResponseCacheHitMultiplier
+		_c.mutation.SetResponseCacheHitMultiplier(v)
+	}
+	if _, ok := _c.mutation.VolumeTiers(); !ok {
+		v := group.DefaultVolumeTiers
+		_c.mutation.SetVolumeTiers(v)
+	}
+	if _, ok := _c.mutation.AllowGeminiNative(); !ok {
+		v := group.DefaultAllowGeminiNative
+		_c.mutation.SetAllowGeminiNative(v)
+	}
+	if _, ok := _c.mutation.HedgePolicy(); !ok {
+		v := group.DefaultHedgePolicy
+		_c.mutation.SetHedgePolicy(v)
+	}
+	if _, ok := _c.mutation.MirrorPolicy(); !ok {
+		v := group.DefaultMirrorPolicy
+		_c.mutation.SetMirrorPolicy(v)
+	}
 	return nil
 }
 
@@ -1301,6 +1419,27 @@ func (_c *GroupCreate) check() error {
 	if _, ok := _c.mutation.ProfitSafetyBuffer(); !ok {
 		return &ValidationError{Name: "profit_safety_buffer", err: errors.New(`ent: missing required field "Group.profit_safety_buffer"`)}
 	}
+	if _, ok := _c.mutation.ResponseCacheEnabled(); !ok {
+		return &ValidationError{Name: "response_cache_enabled", err: errors.New(`ent: missing required field "Group.response_cache_enabled"`)}
+	}
+	if _, ok := _c.mutation.ResponseCacheTTLSeconds(); !ok {
+		return &ValidationError{Name: "response_cache_ttl_seconds", err: errors.New(`ent: missing required field "Group.response_cache_ttl_seconds"`)}
+	}
+	if _, ok := _c.mutation.ResponseCacheHitMultiplier(); !ok {
+		return &ValidationError{Name: "response_cache_hit_multiplier", err: errors.New(`ent: missing required field "Group.response_cache_hit_multiplier"`)}
+	}
+	if _, ok := _c.mutation.VolumeTiers(); !ok {
+		return &ValidationError{Name: "volume_tiers", err: errors.New(`ent: missing required field "Group.volume_tiers"`)}
+	}
+	if _, ok := _c.mutation.AllowGeminiNative(); !ok {
+		return &ValidationError{Name: "allow_gemini_native", err: errors.New(`ent: missing required field "Group.allow_gemini_native"`)}
+	}
+	if _, ok := _c.mutation.HedgePolicy(); !ok {
+		return &ValidationError{Name: "hedge_policy", err: errors.New(`ent: missing required field "Group.hedge_policy"`)}
+	}
+	if _, ok := _c.mutation.MirrorPolicy(); !ok {
+		return &ValidationError{Name: "mirror_policy", err: errors.New(`ent: missing required field "Group.mirror_policy"`)}
+	}
 	return nil
 }
 
@@ -1568,6 +1707,34 @@ func (_c *GroupCreate) createSpec() (*Group, *sqlgraph.CreateSpec) {
 		_spec.SetField(group.FieldProfitSafetyBuffer, field.TypeFloat64, value)
 		_node.ProfitSafetyBuffer = value
 	}
+	if value, ok := _c.mutation.ResponseCacheEnabled(); ok {
+		_spec.SetField(group.FieldResponseCacheEnabled, field.TypeBool, value)
+		_node.ResponseCacheEnabled = value
+	}
+	if value, ok := _c.mutation.ResponseCacheTTLSeconds(); ok {
+		_spec.SetField(group.FieldResponseCacheTTLSeconds, field.TypeInt, value)
+		_node.ResponseCacheTTLSeconds = value
+	}
+	if value, ok := _c.mutation.ResponseCacheHitMultiplier(); ok {
+		_spec.SetField(group.FieldResponseCacheHitMultiplier, field.TypeFloat64, value)
+		_node.ResponseCacheHitMultiplier = value
+	}
+	if value, ok := _c.mutation.VolumeTiers(); ok {
+		_spec.SetField(group.FieldVolumeTiers, field.TypeJSON, value)
+		_node.VolumeTiers = value
+	}
+	if value, ok := _c.mutation.AllowGeminiNative(); ok {
+		_spec.SetField(group.FieldAllowGeminiNative, field.TypeBool, value)
+		_node.AllowGeminiNative = value
+	}
+	if value, ok := _c.mutation.HedgePolicy(); ok {
+		_spec.SetField(group.FieldHedgePolicy, field.TypeJSON, value)
+		_node.HedgePolicy = value
+	}
+	if value, ok := _c.mutation.MirrorPolicy(); ok {
+		_spec.SetField(group.FieldMirrorPolicy, field.TypeJSON, value)
+		_node.MirrorPolicy = value
+	}
 	if nodes := _c.mutation.APIKeysIDs(); len(nodes) > 0 {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
@@ -2702,6 +2869,102 @@ func (u *GroupUpsert) AddProfitSafetyBuffer(v float64) *GroupUpsert {
 	return u
 }
 
+// SetResponseCacheEnabled sets the "response_cache_enabled" field.
+func (u *GroupUpsert) SetResponseCacheEnabled(v bool) *GroupUpsert {
+	u.Set(group.FieldResponseCacheEnabled, v)
+	return u
+}
+
+// UpdateResponseCacheEnabled sets the "response_cache_enabled" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateResponseCacheEnabled() *GroupUpsert {
+	u.SetExcluded(group.FieldResponseCacheEnabled)
+	return u
+}
+
+// SetResponseCacheTTLSeconds sets the "response_cache_ttl_seconds" field.
+func (u *GroupUpsert) SetResponseCacheTTLSeconds(v int) *GroupUpsert {
+	u.Set(group.FieldResponseCacheTTLSeconds, v)
+	return u
+}
+
+// UpdateResponseCacheTTLSeconds sets the "response_cache_ttl_seconds" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateResponseCacheTTLSeconds() *GroupUpsert {
+	u.SetExcluded(group.FieldResponseCacheTTLSeconds)
+	return u
+}
+
+// AddResponseCacheTTLSeconds adds v to the "response_cache_ttl_seconds" field.
+func (u *GroupUpsert) AddResponseCacheTTLSeconds(v int) *GroupUpsert {
+	u.Add(group.FieldResponseCacheTTLSeconds, v)
+	return u
+}
+
+// SetResponseCacheHitMultiplier sets the "response_cache_hit_multiplier" field.
+func (u *GroupUpsert) SetResponseCacheHitMultiplier(v float64) *GroupUpsert {
+	u.Set(group.FieldResponseCacheHitMultiplier, v)
+	return u
+}
+
+// UpdateResponseCacheHitMultiplier sets the "response_cache_hit_multiplier" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateResponseCacheHitMultiplier() *GroupUpsert {
+	u.SetExcluded(group.FieldResponseCacheHitMultiplier)
+	return u
+}
+
+// AddResponseCacheHitMultiplier adds v to the "response_cache_hit_multiplier" field.
+func (u *GroupUpsert) AddResponseCacheHitMultiplier(v float64) *GroupUpsert {
+	u.Add(group.FieldResponseCacheHitMultiplier, v)
+	return u
+}
+
+// SetVolumeTiers sets the "volume_tiers" field.
+func (u *GroupUpsert) SetVolumeTiers(v []domain.VolumeTier) *GroupUpsert {
+	u.Set(group.FieldVolumeTiers, v)
+	return u
+}
+
+// UpdateVolumeTiers sets the "volume_tiers" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateVolumeTiers() *GroupUpsert {
+	u.SetExcluded(group.FieldVolumeTiers)
+	return u
+}
+
+// SetAllowGeminiNative sets the "allow_gemini_native" field.
+func (u *GroupUpsert) SetAllowGeminiNative(v bool) *GroupUpsert {
+	u.Set(group.FieldAllowGeminiNative, v)
+	return u
+}
+
+// UpdateAllowGeminiNative sets the "allow_gemini_native" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateAllowGeminiNative() *GroupUpsert {
+	u.SetExcluded(group.FieldAllowGeminiNative)
+	return u
+}
+
+// SetHedgePolicy sets the "hedge_policy" field.
+func (u *GroupUpsert) SetHedgePolicy(v domain.GroupHedgePolicy) *GroupUpsert {
+	u.Set(group.FieldHedgePolicy, v)
+	return u
+}
+
+// UpdateHedgePolicy sets the "hedge_policy" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateHedgePolicy() *GroupUpsert {
+	u.SetExcluded(group.FieldHedgePolicy)
+	return u
+}
+
+// SetMirrorPolicy sets the "mirror_policy" field.
+func (u *GroupUpsert) SetMirrorPolicy(v domain.GroupMirrorPolicy) *GroupUpsert {
+	u.Set(group.FieldMirrorPolicy, v)
+	return u
+}
+
+// UpdateMirrorPolicy sets the "mirror_policy" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateMirrorPolicy() *GroupUpsert {
+	u.SetExcluded(group.FieldMirrorPolicy)
+	return u
+}
+
 // UpdateNewValues updates the mutable fields using the new values that were set on create.
 // Using this option is equivalent to using:
 //
@@ -3891,6 +4154,118 @@ func (u *GroupUpsertOne) UpdateProfitSafetyBuffer() *GroupUpsertOne {
 	})
 }
 
+// SetResponseCacheEnabled sets the "response_cache_enabled" field.
+func (u *GroupUpsertOne) SetResponseCacheEnabled(v bool) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetResponseCacheEnabled(v)
+	})
+}
+
+// UpdateResponseCacheEnabled sets the "response_cache_enabled" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateResponseCacheEnabled() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateResponseCacheEnabled()
+	})
+}
+
+// SetResponseCacheTTLSeconds sets the "response_cache_ttl_seconds" field.
+func (u *GroupUpsertOne) SetResponseCacheTTLSeconds(v int) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetResponseCacheTTLSeconds(v)
+	})
+}
+
+// AddResponseCacheTTLSeconds adds v to the "response_cache_ttl_seconds" field.
+func (u *GroupUpsertOne) AddResponseCacheTTLSeconds(v int) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddResponseCacheTTLSeconds(v)
+	})
+}
+
+// UpdateResponseCacheTTLSeconds sets the "response_cache_ttl_seconds" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateResponseCacheTTLSeconds() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateResponseCacheTTLSeconds()
+	})
+}
+
+// SetResponseCacheHitMultiplier sets the "response_cache_hit_multiplier" field.
+func (u *GroupUpsertOne) SetResponseCacheHitMultiplier(v float64) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetResponseCacheHitMultiplier(v)
+	})
+}
+
+// AddResponseCacheHitMultiplier adds v to the "response_cache_hit_multiplier" field.
+func (u *GroupUpsertOne) AddResponseCacheHitMultiplier(v float64) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddResponseCacheHitMultiplier(v)
+	})
+}
+
+// UpdateResponseCacheHitMultiplier sets the "response_cache_hit_multiplier" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateResponseCacheHitMultiplier() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateResponseCacheHitMultiplier()
+	})
+}
+
+// SetVolumeTiers sets the "volume_tiers" field.
+func (u *GroupUpsertOne) SetVolumeTiers(v []domain.VolumeTier) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetVolumeTiers(v)
+	})
+}
+
+// UpdateVolumeTiers sets the "volume_tiers" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateVolumeTiers() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateVolumeTiers()
+	})
+}
+
+// SetAllowGeminiNative sets the "allow_gemini_native" field.
+func (u *GroupUpsertOne) SetAllowGeminiNative(v bool) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetAllowGeminiNative(v)
+	})
+}
+
+// UpdateAllowGeminiNative sets the "allow_gemini_native" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateAllowGeminiNative() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateAllowGeminiNative()
+	})
+}
+
+// SetHedgePolicy sets the "hedge_policy" field.
+func (u *GroupUpsertOne) SetHedgePolicy(v domain.GroupHedgePolicy) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetHedgePolicy(v)
+	})
+}
+
+// UpdateHedgePolicy sets the "hedge_policy" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateHedgePolicy() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateHedgePolicy()
+	})
+}
+
+// SetMirrorPolicy sets the "mirror_policy" field.
+func (u *GroupUpsertOne) SetMirrorPolicy(v domain.GroupMirrorPolicy) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetMirrorPolicy(v)
+	})
+}
+
+// UpdateMirrorPolicy sets the "mirror_policy" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateMirrorPolicy() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateMirrorPolicy()
+	})
+}
+
 // Exec executes the query.
 func (u *GroupUpsertOne) Exec(ctx context.Context) error {
 	if len(u.create.conflict) == 0 {
@@ -5246,6 +5621,118 @@ func (u *GroupUpsertBulk) UpdateProfitSafetyBuffer() *GroupUpsertBulk {
 	})
 }
 
+// SetResponseCacheEnabled sets the "response_cache_enabled" field.
+func (u *GroupUpsertBulk) SetResponseCacheEnabled(v bool) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetResponseCacheEnabled(v)
+	})
+}
+
+// UpdateResponseCacheEnabled sets the "response_cache_enabled" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateResponseCacheEnabled() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateResponseCacheEnabled()
+	})
+}
+
+// SetResponseCacheTTLSeconds sets the "response_cache_ttl_seconds" field.
+func (u *GroupUpsertBulk) SetResponseCacheTTLSeconds(v int) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetResponseCacheTTLSeconds(v)
+	})
+}
+
+// AddResponseCacheTTLSeconds adds v to the "response_cache_ttl_seconds" field.
+func (u *GroupUpsertBulk) AddResponseCacheTTLSeconds(v int) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddResponseCacheTTLSeconds(v)
+	})
+}
+
+// UpdateResponseCacheTTLSeconds sets the "response_cache_ttl_seconds" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateResponseCacheTTLSeconds() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateResponseCacheTTLSeconds()
+	})
+}
+
+// SetResponseCacheHitMultiplier sets the "response_cache_hit_multiplier" field.
+func (u *GroupUpsertBulk) SetResponseCacheHitMultiplier(v float64) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetResponseCacheHitMultiplier(v)
+	})
+}
+
+// AddResponseCacheHitMultiplier adds v to the "response_cache_hit_multiplier" field.
+func (u *GroupUpsertBulk) AddResponseCacheHitMultiplier(v float64) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddResponseCacheHitMultiplier(v)
+	})
+}
+
+// UpdateResponseCacheHitMultiplier sets the "response_cache_hit_multiplier" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateResponseCacheHitMultiplier() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateResponseCacheHitMultiplier()
+	})
+}
+
+// SetVolumeTiers sets the "volume_tiers" field.
+func (u *GroupUpsertBulk) SetVolumeTiers(v []domain.VolumeTier) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetVolumeTiers(v)
+	})
+}
+
+// UpdateVolumeTiers sets the "volume_tiers" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateVolumeTiers() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateVolumeTiers()
+	})
+}
+
+// SetAllowGeminiNative sets the "allow_gemini_native" field.
+func (u *GroupUpsertBulk) SetAllowGeminiNative(v bool) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetAllowGeminiNative(v)
+	})
+}
+
+// UpdateAllowGeminiNative sets the "allow_gemini_native" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateAllowGeminiNative() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateAllowGeminiNative()
+	})
+}
+
+// SetHedgePolicy sets the "hedge_policy" field.
+func (u *GroupUpsertBulk) SetHedgePolicy(v domain.GroupHedgePolicy) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetHedgePolicy(v)
+	})
+}
+
+// UpdateHedgePolicy sets the "hedge_policy" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateHedgePolicy() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateHedgePolicy()
+	})
+}
+
+// SetMirrorPolicy sets the "mirror_policy" field.
+func (u *GroupUpsertBulk) SetMirrorPolicy(v domain.GroupMirrorPolicy) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetMirrorPolicy(v)
+	})
+}
+
+// UpdateMirrorPolicy sets the "mirror_policy" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateMirrorPolicy() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateMirrorPolicy()
+	})
+}
+
 // Exec executes the query.
 func (u *GroupUpsertBulk) Exec(ctx context.Context) error {
 	if u.create.err != nil {
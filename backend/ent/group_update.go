@@ -1129,6 +1129,116 @@ func (_u *GroupUpdate) AddProfitSafetyBuffer(v float64) *GroupUpdate {
 	return _u
 }
 
+// SetResponseCacheEnabled sets the "response_cache_enabled" field.
+func (_u *GroupUpdate) SetResponseCacheEnabled(v bool) *GroupUpdate {
+	_u.mutation.SetResponseCacheEnabled(v)
+	return _u
+}
+
+// SetNillableResponseCacheEnabled sets the "response_cache_enabled" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableResponseCacheEnabled(v *bool) *GroupUpdate {
+	if v != nil {
+		_u.SetResponseCacheEnabled(*v)
+	}
+	return _u
+}
+
+// SetResponseCacheTTLSeconds sets the "response_cache_ttl_seconds" field.
+func (_u *GroupUpdate) SetResponseCacheTTLSeconds(v int) *GroupUpdate {
+	_u.mutation.ResetResponseCacheTTLSeconds()
+	_u.mutation.SetResponseCacheTTLSeconds(v)
+	return _u
+}
+
+// SetNillableResponseCacheTTLSeconds sets the "response_cache_ttl_seconds" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableResponseCacheTTLSeconds(v *int) *GroupUpdate {
+	if v != nil {
+		_u.SetResponseCacheTTLSeconds(*v)
+	}
+	return _u
+}
+
+// AddResponseCacheTTLSeconds adds value to the "response_cache_ttl_seconds" field.
+func (_u *GroupUpdate) AddResponseCacheTTLSeconds(v int) *GroupUpdate {
+	_u.mutation.AddResponseCacheTTLSeconds(v)
+	return _u
+}
+
+// SetResponseCacheHitMultiplier sets the "response_cache_hit_multiplier" field.
+func (_u *GroupUpdate) SetResponseCacheHitMultiplier(v float64) *GroupUpdate {
+	_u.mutation.ResetResponseCacheHitMultiplier()
+	_u.mutation.SetResponseCacheHitMultiplier(v)
+	return _u
+}
+
+// SetNillableResponseCacheHitMultiplier sets the "response_cache_hit_multiplier" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableResponseCacheHitMultiplier(v *float64) *GroupUpdate {
+	if v != nil {
+		_u.SetResponseCacheHitMultiplier(*v)
+	}
+	return _u
+}
+
+// AddResponseCacheHitMultiplier adds value to the "response_cache_hit_multiplier" field.
+func (_u *GroupUpdate) AddResponseCacheHitMultiplier(v float64) *GroupUpdate {
+	_u.mutation.AddResponseCacheHitMultiplier(v)
+	return _u
+}
+
+// SetVolumeTiers sets the "volume_tiers" field.
+func (_u *GroupUpdate) SetVolumeTiers(v []domain.VolumeTier) *GroupUpdate {
+	_u.mutation.SetVolumeTiers(v)
+	return _u
+}
+
+// AppendVolumeTiers appends value to the "volume_tiers" field.
+func (_u *GroupUpdate) AppendVolumeTiers(v []domain.VolumeTier) *GroupUpdate {
+	_u.mutation.AppendVolumeTiers(v)
+	return _u
+}
+
+// SetAllowGeminiNative sets the "allow_gemini_native" field.
+func (_u *GroupUpdate) SetAllowGeminiNative(v bool) *GroupUpdate {
+	_u.mutation.SetAllowGeminiNative(v)
+	return _u
+}
+
+// SetNillableAllowGeminiNative sets the "allow_gemini_native" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableAllowGeminiNative(v *bool) *GroupUpdate {
+	if v != nil {
+		_u.SetAllowGeminiNative(*v)
+	}
+	return _u
+}
+
+// SetHedgePolicy sets the "hedge_policy" field.
+func (_u *GroupUpdate) SetHedgePolicy(v domain.GroupHedgePolicy) *GroupUpdate {
+	_u.mutation.SetHedgePolicy(v)
+	return _u
+}
+
+// SetNillableHedgePolicy sets the "hedge_policy" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableHedgePolicy(v *domain.GroupHedgePolicy) *GroupUpdate {
+	if v != nil {
+		_u.SetHedgePolicy(*v)
+	}
+	return _u
+}
+
+// SetMirrorPolicy sets the "mirror_policy" field.
+func (_u *GroupUpdate) SetMirrorPolicy(v domain.GroupMirrorPolicy) *GroupUpdate {
+	_u.mutation.SetMirrorPolicy(v)
+	return _u
+}
+
+// SetNillableMirrorPolicy sets the "mirror_policy" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableMirrorPolicy(v *domain.GroupMirrorPolicy) *GroupUpdate {
+	if v != nil {
+		_u.SetMirrorPolicy(*v)
+	}
+	return _u
+}
+
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by IDs.
 func (_u *GroupUpdate) AddAPIKeyIDs(ids ...int64) *GroupUpdate {
 	_u.mutation.AddAPIKeyIDs(ids...)
@@ -1797,6 +1907,38 @@ func (_u *GroupUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if value, ok := _u.mutation.AddedProfitSafetyBuffer(); ok {
 		_spec.AddField(group.FieldProfitSafetyBuffer, field.TypeFloat64, value)
 	}
+	if value, ok := _u.mutation.ResponseCacheEnabled(); ok {
+		_spec.SetField(group.FieldResponseCacheEnabled, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.ResponseCacheTTLSeconds(); ok {
+		_spec.SetField(group.FieldResponseCacheTTLSeconds, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedResponseCacheTTLSeconds(); ok {
+		_spec.AddField(group.FieldResponseCacheTTLSeconds, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.ResponseCacheHitMultiplier(); ok {
+		_spec.SetField(group.FieldResponseCacheHitMultiplier, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedResponseCacheHitMultiplier(); ok {
+		_spec.AddField(group.FieldResponseCacheHitMultiplier, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.VolumeTiers(); ok {
+		_spec.SetField(group.FieldVolumeTiers, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedVolumeTiers(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, group.FieldVolumeTiers, value)
+		})
+	}
+	if value, ok := _u.mutation.AllowGeminiNative(); ok {
+		_spec.SetField(group.FieldAllowGeminiNative, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.HedgePolicy(); ok {
+		_spec.SetField(group.FieldHedgePolicy, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.MirrorPolicy(); ok {
+		_spec.SetField(group.FieldMirrorPolicy, field.TypeJSON, value)
+	}
 	if _u.mutation.APIKeysCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
@@ -3204,6 +3346,116 @@ func (_u *GroupUpdateOne) AddProfitSafetyBuffer(v float64) *GroupUpdateOne {
 	return _u
 }
 
+// SetResponseCacheEnabled sets the "response_cache_enabled" field.
+func (_u *GroupUpdateOne) SetResponseCacheEnabled(v bool) *GroupUpdateOne {
+	_u.mutation.SetResponseCacheEnabled(v)
+	return _u
+}
+
+// SetNillableResponseCacheEnabled sets the "response_cache_enabled" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableResponseCacheEnabled(v *bool) *GroupUpdateOne {
+	if v != nil {
+		_u.SetResponseCacheEnabled(*v)
+	}
+	return _u
+}
+
+// SetResponseCacheTTLSeconds sets the "response_cache_ttl_seconds" field.
+func (_u *GroupUpdateOne) SetResponseCacheTTLSeconds(v int) *GroupUpdateOne {
+	_u.mutation.ResetResponseCacheTTLSeconds()
+	_u.mutation.SetResponseCacheTTLSeconds(v)
+	return _u
+}
+
+// SetNillableResponseCacheTTLSeconds sets the "response_cache_ttl_seconds" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableResponseCacheTTLSeconds(v *int) *GroupUpdateOne {
+	if v != nil {
+		_u.SetResponseCacheTTLSeconds(*v)
+	}
+	return _u
+}
+
+// AddResponseCacheTTLSeconds adds value to the "response_cache_ttl_seconds" field.
+func (_u *GroupUpdateOne) AddResponseCacheTTLSeconds(v int) *GroupUpdateOne {
+	_u.mutation.AddResponseCacheTTLSeconds(v)
+	return _u
+}
+
+// SetResponseCacheHitMultiplier sets the "response_cache_hit_multiplier" field.
+func (_u *GroupUpdateOne) SetResponseCacheHitMultiplier(v float64) *GroupUpdateOne {
+	_u.mutation.ResetResponseCacheHitMultiplier()
+	_u.mutation.SetResponseCacheHitMultiplier(v)
+	return _u
+}
+
+// SetNillableResponseCacheHitMultiplier sets the "response_cache_hit_multiplier" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableResponseCacheHitMultiplier(v *float64) *GroupUpdateOne {
+	if v != nil {
+		_u.SetResponseCacheHitMultiplier(*v)
+	}
+	return _u
+}
+
+// AddResponseCacheHitMultiplier adds value to the "response_cache_hit_multiplier" field.
+func (_u *GroupUpdateOne) AddResponseCacheHitMultiplier(v float64) *GroupUpdateOne {
+	_u.mutation.AddResponseCacheHitMultiplier(v)
+	return _u
+}
+
+// SetVolumeTiers sets the "volume_tiers" field.
+func (_u *GroupUpdateOne) SetVolumeTiers(v []domain.VolumeTier) *GroupUpdateOne {
+	_u.mutation.SetVolumeTiers(v)
+	return _u
+}
+
+// AppendVolumeTiers appends value to the "volume_tiers" field.
+func (_u *GroupUpdateOne) AppendVolumeTiers(v []domain.VolumeTier) *GroupUpdateOne {
+	_u.mutation.AppendVolumeTiers(v)
+	return _u
+}
+
+// SetAllowGeminiNative sets the "allow_gemini_native" field.
+func (_u *GroupUpdateOne) SetAllowGeminiNative(v bool) *GroupUpdateOne {
+	_u.mutation.SetAllowGeminiNative(v)
+	return _u
+}
+
+// SetNillableAllowGeminiNative sets the "allow_gemini_native" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableAllowGeminiNative(v *bool) *GroupUpdateOne {
+	if v != nil {
+		_u.SetAllowGeminiNative(*v)
+	}
+	return _u
+}
+
+// SetHedgePolicy sets the "hedge_policy" field.
+func (_u *GroupUpdateOne) SetHedgePolicy(v domain.GroupHedgePolicy) *GroupUpdateOne {
+	_u.mutation.SetHedgePolicy(v)
+	return _u
+}
+
+// SetNillableHedgePolicy sets the "hedge_policy" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableHedgePolicy(v *domain.GroupHedgePolicy) *GroupUpdateOne {
+	if v != nil {
+		_u.SetHedgePolicy(*v)
+	}
+	return _u
+}
+
+// SetMirrorPolicy sets the "mirror_policy" field.
+func (_u *GroupUpdateOne) SetMirrorPolicy(v domain.GroupMirrorPolicy) *GroupUpdateOne {
+	_u.mutation.SetMirrorPolicy(v)
+	return _u
+}
+
+// SetNillableMirrorPolicy sets the "mirror_policy" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableMirrorPolicy(v *domain.GroupMirrorPolicy) *GroupUpdateOne {
+	if v != nil {
+		_u.SetMirrorPolicy(*v)
+	}
+	return _u
+}
+
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by IDs.
 func (_u *GroupUpdateOne) AddAPIKeyIDs(ids ...int64) *GroupUpdateOne {
 	_u.mutation.AddAPIKeyIDs(ids...)
@@ -3902,6 +4154,38 @@ func (_u *GroupUpdateOne) sqlSave(ctx context.Context) (_node *Group, err error)
 	if value, ok := _u.mutation.AddedProfitSafetyBuffer(); ok {
 		_spec.AddField(group.FieldProfitSafetyBuffer, field.TypeFloat64, value)
 	}
+	if value, ok := _u.mutation.ResponseCacheEnabled(); ok {
+		_spec.SetField(group.FieldResponseCacheEnabled, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.ResponseCacheTTLSeconds(); ok {
+		_spec.SetField(group.FieldResponseCacheTTLSeconds, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedResponseCacheTTLSeconds(); ok {
+		_spec.AddField(group.FieldResponseCacheTTLSeconds, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.ResponseCacheHitMultiplier(); ok {
+		_spec.SetField(group.FieldResponseCacheHitMultiplier, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedResponseCacheHitMultiplier(); ok {
+		_spec.AddField(group.FieldResponseCacheHitMultiplier, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.VolumeTiers(); ok {
+		_spec.SetField(group.FieldVolumeTiers, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedVolumeTiers(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, group.FieldVolumeTiers, value)
+		})
+	}
+	if value, ok := _u.mutation.AllowGeminiNative(); ok {
+		_spec.SetField(group.FieldAllowGeminiNative, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.HedgePolicy(); ok {
+		_spec.SetField(group.FieldHedgePolicy, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.MirrorPolicy(); ok {
+		_spec.SetField(group.FieldMirrorPolicy, field.TypeJSON, value)
+	}
 	if _u.mutation.APIKeysCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
@@ -108,51 +108,59 @@ const (
 // APIKeyMutation represents an operation that mutates the APIKey nodes in the graph.
 type APIKeyMutation struct {
 	config
-	op                 Op
-	typ                string
-	id                 *int64
-	created_at         *time.Time
-	updated_at         *time.Time
-	deleted_at         *time.Time
-	key                *string
-	name               *string
-	status             *string
-	last_used_at       *time.Time
-	ip_whitelist       *[]string
-	appendip_whitelist []string
-	ip_blacklist       *[]string
-	appendip_blacklist []string
-	quota              *float64
-	addquota           *float64
-	quota_used         *float64
-	addquota_used      *float64
-	expires_at         *time.Time
-	rate_limit_5h      *float64
-	addrate_limit_5h   *float64
-	rate_limit_1d      *float64
-	addrate_limit_1d   *float64
-	rate_limit_7d      *float64
-	addrate_limit_7d   *float64
-	usage_5h           *float64
-	addusage_5h        *float64
-	usage_1d           *float64
-	addusage_1d        *float64
-	usage_7d           *float64
-	addusage_7d        *float64
-	window_5h_start    *time.Time
-	window_1d_start    *time.Time
-	window_7d_start    *time.Time
-	clearedFields      map[string]struct{}
-	user               *int64
-	cleareduser        bool
-	group              *int64
-	clearedgroup       bool
-	usage_logs         map[int64]struct{}
-	removedusage_logs  map[int64]struct{}
-	clearedusage_logs  bool
-	done               bool
-	oldValue           func(context.Context) (*APIKey, error)
-	predicates         []predicate.APIKey
+	op                    Op
+	typ                   string
+	id                    *int64
+	created_at            *time.Time
+	updated_at            *time.Time
+	deleted_at            *time.Time
+	key                   *string
+	name                  *string
+	status                *string
+	last_used_at          *time.Time
+	ip_whitelist          *[]string
+	appendip_whitelist    []string
+	ip_blacklist          *[]string
+	appendip_blacklist    []string
+	model_allowlist       *[]string
+	appendmodel_allowlist []string
+	model_denylist        *[]string
+	appendmodel_denylist  []string
+	quota                 *float64
+	addquota              *float64
+	quota_used            *float64
+	addquota_used         *float64
+	expires_at            *time.Time
+	rate_limit_5h         *float64
+	addrate_limit_5h      *float64
+	rate_limit_1d         *float64
+	addrate_limit_1d      *float64
+	rate_limit_7d         *float64
+	addrate_limit_7d      *float64
+	usage_5h              *float64
+	addusage_5h           *float64
+	usage_1d              *float64
+	addusage_1d           *float64
+	usage_7d              *float64
+	addusage_7d           *float64
+	window_5h_start       *time.Time
+	window_1d_start       *time.Time
+	window_7d_start       *time.Time
+	rpm_limit             *int
+	addrpm_limit          *int
+	max_concurrency       *int
+	addmax_concurrency    *int
+	clearedFields         map[string]struct{}
+	user                  *int64
+	cleareduser           bool
+	group                 *int64
+	clearedgroup          bool
+	usage_logs            map[int64]struct{}
+	removedusage_logs     map[int64]struct{}
+	clearedusage_logs     bool
+	done                  bool
+	oldValue              func(context.Context) (*APIKey, error)
+	predicates            []predicate.APIKey
 }
 
 var _ ent.Mutation = (*APIKeyMutation)(nil)
@@ -746,6 +754,136 @@ func (m *APIKeyMutation) ResetIPBlacklist() {
 	delete(m.clearedFields, apikey.FieldIPBlacklist)
 }
 
+// SetModelAllowlist sets the "model_allowlist" field.
+func (m *APIKeyMutation) SetModelAllowlist(s []string) {
+	m.model_allowlist = &s
+	m.appendmodel_allowlist = nil
+}
+
+// ModelAllowlist returns the value of the "model_allowlist" field in the mutation.
+func (m *APIKeyMutation) ModelAllowlist() (r []string, exists bool) {
+	v := m.model_allowlist
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldModelAllowlist returns the old "model_allowlist" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldModelAllowlist(ctx context.Context) (v []string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldModelAllowlist is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldModelAllowlist requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldModelAllowlist: %w", err)
+	}
+	return oldValue.ModelAllowlist, nil
+}
+
+// AppendModelAllowlist adds s to the "model_allowlist" field.
+func (m *APIKeyMutation) AppendModelAllowlist(s []string) {
+	m.appendmodel_allowlist = append(m.appendmodel_allowlist, s...)
+}
+
+// AppendedModelAllowlist returns the list of values that were appended to the "model_allowlist" field in this mutation.
+func (m *APIKeyMutation) AppendedModelAllowlist() ([]string, bool) {
+	if len(m.appendmodel_allowlist) == 0 {
+		return nil, false
+	}
+	return m.appendmodel_allowlist, true
+}
+
+// ClearModelAllowlist clears the value of the "model_allowlist" field.
+func (m *APIKeyMutation) ClearModelAllowlist() {
+	m.model_allowlist = nil
+	m.appendmodel_allowlist = nil
+	m.clearedFields[apikey.FieldModelAllowlist] = struct{}{}
+}
+
+// ModelAllowlistCleared returns if the "model_allowlist" field was cleared in this mutation.
+func (m *APIKeyMutation) ModelAllowlistCleared() bool {
+	_, ok := m.clearedFields[apikey.FieldModelAllowlist]
+	return ok
+}
+
+// ResetModelAllowlist resets all changes to the "model_allowlist" field.
+func (m *APIKeyMutation) ResetModelAllowlist() {
+	m.model_allowlist = nil
+	m.appendmodel_allowlist = nil
+	delete(m.clearedFields, apikey.FieldModelAllowlist)
+}
+
+// SetModelDenylist sets the "model_denylist" field.
+func (m *APIKeyMutation) SetModelDenylist(s []string) {
+	m.model_denylist = &s
+	m.appendmodel_denylist = nil
+}
+
+// ModelDenylist returns the value of the "model_denylist" field in the mutation.
+func (m *APIKeyMutation) ModelDenylist() (r []string, exists bool) {
+	v := m.model_denylist
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldModelDenylist returns the old "model_denylist" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldModelDenylist(ctx context.Context) (v []string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldModelDenylist is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldModelDenylist requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldModelDenylist: %w", err)
+	}
+	return oldValue.ModelDenylist, nil
+}
+
+// AppendModelDenylist adds s to the "model_denylist" field.
+func (m *APIKeyMutation) AppendModelDenylist(s []string) {
+	m.appendmodel_denylist = append(m.appendmodel_denylist, s...)
+}
+
+// AppendedModelDenylist returns the list of values that were appended to the "model_denylist" field in this mutation.
+func (m *APIKeyMutation) AppendedModelDenylist() ([]string, bool) {
+	if len(m.appendmodel_denylist) == 0 {
+		return nil, false
+	}
+	return m.appendmodel_denylist, true
+}
+
+// ClearModelDenylist clears the value of the "model_denylist" field.
+func (m *APIKeyMutation) ClearModelDenylist() {
+	m.model_denylist = nil
+	m.appendmodel_denylist = nil
+	m.clearedFields[apikey.FieldModelDenylist] = struct{}{}
+}
+
+// ModelDenylistCleared returns if the "model_denylist" field was cleared in this mutation.
+func (m *APIKeyMutation) ModelDenylistCleared() bool {
+	_, ok := m.clearedFields[apikey.FieldModelDenylist]
+	return ok
+}
+
+// ResetModelDenylist resets all changes to the "model_denylist" field.
+func (m *APIKeyMutation) ResetModelDenylist() {
+	m.model_denylist = nil
+	m.appendmodel_denylist = nil
+	delete(m.clearedFields, apikey.FieldModelDenylist)
+}
+
 // SetQuota sets the "quota" field.
 func (m *APIKeyMutation) SetQuota(f float64) {
 	m.quota = &f
@@ -1390,6 +1528,118 @@ func (m *APIKeyMutation) ResetWindow7dStart() {
 	delete(m.clearedFields, apikey.FieldWindow7dStart)
 }
 
+// SetRpmLimit sets the "rpm_limit" field.
+func (m *APIKeyMutation) SetRpmLimit(i int) {
+	m.rpm_limit = &i
+	m.addrpm_limit = nil
+}
+
+// RpmLimit returns the value of the "rpm_limit" field in the mutation.
+func (m *APIKeyMutation) RpmLimit() (r int, exists bool) {
+	v := m.rpm_limit
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldRpmLimit returns the old "rpm_limit" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldRpmLimit(ctx context.Context) (v int, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldRpmLimit is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldRpmLimit requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldRpmLimit: %w", err)
+	}
+	return oldValue.RpmLimit, nil
+}
+
+// AddRpmLimit adds i to the "rpm_limit" field.
+func (m *APIKeyMutation) AddRpmLimit(i int) {
+	if m.addrpm_limit != nil {
+		*m.addrpm_limit += i
+	} else {
+		m.addrpm_limit = &i
+	}
+}
+
+// AddedRpmLimit returns the value that was added to the "rpm_limit" field in this mutation.
+func (m *APIKeyMutation) AddedRpmLimit() (r int, exists bool) {
+	v := m.addrpm_limit
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetRpmLimit resets all changes to the "rpm_limit" field.
+func (m *APIKeyMutation) ResetRpmLimit() {
+	m.rpm_limit = nil
+	m.addrpm_limit = nil
+}
+
+// SetMaxConcurrency sets the "max_concurrency" field.
+func (m *APIKeyMutation) SetMaxConcurrency(i int) {
+	m.max_concurrency = &i
+	m.addmax_concurrency = nil
+}
+
+// MaxConcurrency returns the value of the "max_concurrency" field in the mutation.
+func (m *APIKeyMutation) MaxConcurrency() (r int, exists bool) {
+	v := m.max_concurrency
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldMaxConcurrency returns the old "max_concurrency" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldMaxConcurrency(ctx context.Context) (v int, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldMaxConcurrency is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldMaxConcurrency requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldMaxConcurrency: %w", err)
+	}
+	return oldValue.MaxConcurrency, nil
+}
+
+// AddMaxConcurrency adds i to the "max_concurrency" field.
+func (m *APIKeyMutation) AddMaxConcurrency(i int) {
+	if m.addmax_concurrency != nil {
+		*m.addmax_concurrency += i
+	} else {
+		m.addmax_concurrency = &i
+	}
+}
+
+// AddedMaxConcurrency returns the value that was added to the "max_concurrency" field in this mutation.
+func (m *APIKeyMutation) AddedMaxConcurrency() (r int, exists bool) {
+	v := m.addmax_concurrency
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetMaxConcurrency resets all changes to the "max_concurrency" field.
+func (m *APIKeyMutation) ResetMaxConcurrency() {
+	m.max_concurrency = nil
+	m.addmax_concurrency = nil
+}
+
 // ClearUser clears the "user" edge to the User entity.
 func (m *APIKeyMutation) ClearUser() {
 	m.cleareduser = true
@@ -1532,7 +1782,7 @@ func (m *APIKeyMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *APIKeyMutation) Fields() []string {
-	fields := make([]string, 0, 23)
+	fields := make([]string, 0, 27)
 	if m.created_at != nil {
 		fields = append(fields, apikey.FieldCreatedAt)
 	}
@@ -1566,6 +1816,12 @@ func (m *APIKeyMutation) Fields() []string {
 	if m.ip_blacklist != nil {
 		fields = append(fields, apikey.FieldIPBlacklist)
 	}
+	if m.model_allowlist != nil {
+		fields = append(fields, apikey.FieldModelAllowlist)
+	}
+	if m.model_denylist != nil {
+		fields = append(fields, apikey.FieldModelDenylist)
+	}
 	if m.quota != nil {
 		fields = append(fields, apikey.FieldQuota)
 	}
@@ -1602,6 +1858,12 @@ func (m *APIKeyMutation) Fields() []string {
 	if m.window_7d_start != nil {
 		fields = append(fields, apikey.FieldWindow7dStart)
 	}
+	if m.rpm_limit != nil {
+		fields = append(fields, apikey.FieldRpmLimit)
+	}
+	if m.max_concurrency != nil {
+		fields = append(fields, apikey.FieldMaxConcurrency)
+	}
 	return fields
 }
 
@@ -1632,6 +1894,10 @@ func (m *APIKeyMutation) Field(name string) (ent.Value, bool) {
 		return m.IPWhitelist()
 	case apikey.FieldIPBlacklist:
 		return m.IPBlacklist()
+	case apikey.FieldModelAllowlist:
+		return m.ModelAllowlist()
+	case apikey.FieldModelDenylist:
+		return m.ModelDenylist()
 	case apikey.FieldQuota:
 		return m.Quota()
 	case apikey.FieldQuotaUsed:
@@ -1656,6 +1922,10 @@ func (m *APIKeyMutation) Field(name string) (ent.Value, bool) {
 		return m.Window1dStart()
 	case apikey.FieldWindow7dStart:
 		return m.Window7dStart()
+	case apikey.FieldRpmLimit:
+		return m.RpmLimit()
+	case apikey.FieldMaxConcurrency:
+		return m.MaxConcurrency()
 	}
 	return nil, false
 }
@@ -1687,6 +1957,10 @@ func (m *APIKeyMutation) OldField(ctx context.Context, name string) (ent.Value,
 		return m.OldIPWhitelist(ctx)
 	case apikey.FieldIPBlacklist:
 		return m.OldIPBlacklist(ctx)
+	case apikey.FieldModelAllowlist:
+		return m.OldModelAllowlist(ctx)
+	case apikey.FieldModelDenylist:
+		return m.OldModelDenylist(ctx)
 	case apikey.FieldQuota:
 		return m.OldQuota(ctx)
 	case apikey.FieldQuotaUsed:
@@ -1711,6 +1985,10 @@ func (m *APIKeyMutation) OldField(ctx context.Context, name string) (ent.Value,
 		return m.OldWindow1dStart(ctx)
 	case apikey.FieldWindow7dStart:
 		return m.OldWindow7dStart(ctx)
+	case apikey.FieldRpmLimit:
+		return m.OldRpmLimit(ctx)
+	case apikey.FieldMaxConcurrency:
+		return m.OldMaxConcurrency(ctx)
 	}
 	return nil, fmt.Errorf("unknown APIKey field %s", name)
 }
@@ -1797,6 +2075,20 @@ func (m *APIKeyMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetIPBlacklist(v)
 		return nil
+	case apikey.FieldModelAllowlist:
+		v, ok := value.([]string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetModelAllowlist(v)
+		return nil
+	case apikey.FieldModelDenylist:
+		v, ok := value.([]string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetModelDenylist(v)
+		return nil
 	case apikey.FieldQuota:
 		v, ok := value.(float64)
 		if !ok {
@@ -1881,6 +2173,20 @@ func (m *APIKeyMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetWindow7dStart(v)
 		return nil
+	case apikey.FieldRpmLimit:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetRpmLimit(v)
+		return nil
+	case apikey.FieldMaxConcurrency:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetMaxConcurrency(v)
+		return nil
 	}
 	return fmt.Errorf("unknown APIKey field %s", name)
 }
@@ -1913,6 +2219,12 @@ func (m *APIKeyMutation) AddedFields() []string {
 	if m.addusage_7d != nil {
 		fields = append(fields, apikey.FieldUsage7d)
 	}
+	if m.addrpm_limit != nil {
+		fields = append(fields, apikey.FieldRpmLimit)
+	}
+	if m.addmax_concurrency != nil {
+		fields = append(fields, apikey.FieldMaxConcurrency)
+	}
 	return fields
 }
 
@@ -1937,6 +2249,10 @@ func (m *APIKeyMutation) AddedField(name string) (ent.Value, bool) {
 		return m.AddedUsage1d()
 	case apikey.FieldUsage7d:
 		return m.AddedUsage7d()
+	case apikey.FieldRpmLimit:
+		return m.AddedRpmLimit()
+	case apikey.FieldMaxConcurrency:
+		return m.AddedMaxConcurrency()
 	}
 	return nil, false
 }
@@ -2002,6 +2318,20 @@ func (m *APIKeyMutation) AddField(name string, value ent.Value) error {
 		}
 		m.AddUsage7d(v)
 		return nil
+	case apikey.FieldRpmLimit:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddRpmLimit(v)
+		return nil
+	case apikey.FieldMaxConcurrency:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddMaxConcurrency(v)
+		return nil
 	}
 	return fmt.Errorf("unknown APIKey numeric field %s", name)
 }
@@ -2025,6 +2355,12 @@ func (m *APIKeyMutation) ClearedFields() []string {
 	if m.FieldCleared(apikey.FieldIPBlacklist) {
 		fields = append(fields, apikey.FieldIPBlacklist)
 	}
+	if m.FieldCleared(apikey.FieldModelAllowlist) {
+		fields = append(fields, apikey.FieldModelAllowlist)
+	}
+	if m.FieldCleared(apikey.FieldModelDenylist) {
+		fields = append(fields, apikey.FieldModelDenylist)
+	}
 	if m.FieldCleared(apikey.FieldExpiresAt) {
 		fields = append(fields, apikey.FieldExpiresAt)
 	}
@@ -2066,6 +2402,12 @@ func (m *APIKeyMutation) ClearField(name string) error {
 	case apikey.FieldIPBlacklist:
 		m.ClearIPBlacklist()
 		return nil
+	case apikey.FieldModelAllowlist:
+		m.ClearModelAllowlist()
+		return nil
+	case apikey.FieldModelDenylist:
+		m.ClearModelDenylist()
+		return nil
 	case apikey.FieldExpiresAt:
 		m.ClearExpiresAt()
 		return nil
@@ -2119,6 +2461,12 @@ func (m *APIKeyMutation) ResetField(name string) error {
 	case apikey.FieldIPBlacklist:
 		m.ResetIPBlacklist()
 		return nil
+	case apikey.FieldModelAllowlist:
+		m.ResetModelAllowlist()
+		return nil
+	case apikey.FieldModelDenylist:
+		m.ResetModelDenylist()
+		return nil
 	case apikey.FieldQuota:
 		m.ResetQuota()
 		return nil
@@ -2155,6 +2503,12 @@ func (m *APIKeyMutation) ResetField(name string) error {
 	case apikey.FieldWindow7dStart:
 		m.ResetWindow7dStart()
 		return nil
+	case apikey.FieldRpmLimit:
+		m.ResetRpmLimit()
+		return nil
+	case apikey.FieldMaxConcurrency:
+		m.ResetMaxConcurrency()
+		return nil
 	}
 	return fmt.Errorf("unknown APIKey field %s", name)
 }
@@ -19488,6 +19842,7 @@ type CompositeModelRouteMutation struct {
 	addpriority     *int
 	enabled         *bool
 	notes           *string
+	split           **domain.CompositeRouteSplit
 	clearedFields   map[string]struct{}
 	group           *int64
 	clearedgroup    bool
@@ -20072,6 +20427,55 @@ func (m *CompositeModelRouteMutation) ResetNotes() {
 	delete(m.clearedFields, compositemodelroute.FieldNotes)
 }
 
+// SetSplit sets the "split" field.
+func (m *CompositeModelRouteMutation) SetSplit(drs *domain.CompositeRouteSplit) {
+	m.split = &drs
+}
+
+// Split returns the value of the "split" field in the mutation.
+func (m *CompositeModelRouteMutation) Split() (r *domain.CompositeRouteSplit, exists bool) {
+	v := m.split
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldSplit returns the old "split" field's value of the CompositeModelRoute entity.
+// If the CompositeModelRoute object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *CompositeModelRouteMutation) OldSplit(ctx context.Context) (v *domain.CompositeRouteSplit, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldSplit is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldSplit requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldSplit: %w", err)
+	}
+	return oldValue.Split, nil
+}
+
+// ClearSplit clears the value of the "split" field.
+func (m *CompositeModelRouteMutation) ClearSplit() {
+	m.split = nil
+	m.clearedFields[compositemodelroute.FieldSplit] = struct{}{}
+}
+
+// SplitCleared returns if the "split" field was cleared in this mutation.
+func (m *CompositeModelRouteMutation) SplitCleared() bool {
+	_, ok := m.clearedFields[compositemodelroute.FieldSplit]
+	return ok
+}
+
+// ResetSplit resets all changes to the "split" field.
+func (m *CompositeModelRouteMutation) ResetSplit() {
+	m.split = nil
+	delete(m.clearedFields, compositemodelroute.FieldSplit)
+}
+
 // ClearGroup clears the "group" edge to the Group entity.
 func (m *CompositeModelRouteMutation) ClearGroup() {
 	m.clearedgroup = true
@@ -20133,7 +20537,7 @@ func (m *CompositeModelRouteMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *CompositeModelRouteMutation) Fields() []string {
-	fields := make([]string, 0, 12)
+	fields := make([]string, 0, 13)
 	if m.created_at != nil {
 		fields = append(fields, compositemodelroute.FieldCreatedAt)
 	}
@@ -20170,6 +20574,9 @@ func (m *CompositeModelRouteMutation) Fields() []string {
 	if m.notes != nil {
 		fields = append(fields, compositemodelroute.FieldNotes)
 	}
+	if m.split != nil {
+		fields = append(fields, compositemodelroute.FieldSplit)
+	}
 	return fields
 }
 
@@ -20202,6 +20609,8 @@ func (m *CompositeModelRouteMutation) Field(name string) (ent.Value, bool) {
 		return m.Enabled()
 	case compositemodelroute.FieldNotes:
 		return m.Notes()
+	case compositemodelroute.FieldSplit:
+		return m.Split()
 	}
 	return nil, false
 }
@@ -20235,6 +20644,8 @@ func (m *CompositeModelRouteMutation) OldField(ctx context.Context, name string)
 		return m.OldEnabled(ctx)
 	case compositemodelroute.FieldNotes:
 		return m.OldNotes(ctx)
+	case compositemodelroute.FieldSplit:
+		return m.OldSplit(ctx)
 	}
 	return nil, fmt.Errorf("unknown CompositeModelRoute field %s", name)
 }
@@ -20328,6 +20739,13 @@ func (m *CompositeModelRouteMutation) SetField(name string, value ent.Value) err
 		}
 		m.SetNotes(v)
 		return nil
+	case compositemodelroute.FieldSplit:
+		v, ok := value.(*domain.CompositeRouteSplit)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetSplit(v)
+		return nil
 	}
 	return fmt.Errorf("unknown CompositeModelRoute field %s", name)
 }
@@ -20379,6 +20797,9 @@ func (m *CompositeModelRouteMutation) ClearedFields() []string {
 	if m.FieldCleared(compositemodelroute.FieldNotes) {
 		fields = append(fields, compositemodelroute.FieldNotes)
 	}
+	if m.FieldCleared(compositemodelroute.FieldSplit) {
+		fields = append(fields, compositemodelroute.FieldSplit)
+	}
 	return fields
 }
 
@@ -20399,6 +20820,9 @@ func (m *CompositeModelRouteMutation) ClearField(name string) error {
 	case compositemodelroute.FieldNotes:
 		m.ClearNotes()
 		return nil
+	case compositemodelroute.FieldSplit:
+		m.ClearSplit()
+		return nil
 	}
 	return fmt.Errorf("unknown CompositeModelRoute nullable field %s", name)
 }
@@ -20443,6 +20867,9 @@ func (m *CompositeModelRouteMutation) ResetField(name string) error {
 	case compositemodelroute.FieldNotes:
 		m.ResetNotes()
 		return nil
+	case compositemodelroute.FieldSplit:
+		m.ResetSplit()
+		return nil
 	}
 	return fmt.Errorf("unknown CompositeModelRoute field %s", name)
 }
@@ -21936,6 +22363,16 @@ type GroupMutation struct {
 	addprofit_min_margin                    *float64
 	profit_safety_buffer                    *float64
 	addprofit_safety_buffer                 *float64
+	response_cache_enabled                  *bool
+	response_cache_ttl_seconds              *int
+	addresponse_cache_ttl_seconds           *int
+	response_cache_hit_multiplier           *float64
+	addresponse_cache_hit_multiplier        *float64
+	volume_tiers                            *[]domain.VolumeTier
+	appendvolume_tiers                      []domain.VolumeTier
+	allow_gemini_native                     *bool
+	hedge_policy                            *domain.GroupHedgePolicy
+	mirror_policy                           *domain.GroupMirrorPolicy
 	clearedFields                           map[string]struct{}
 	api_keys                                map[int64]struct{}
 	removedapi_keys                         map[int64]struct{}
@@ -25077,6 +25514,313 @@ func (m *GroupMutation) ResetProfitSafetyBuffer() {
 	m.addprofit_safety_buffer = nil
 }
 
+// SetResponseCacheEnabled sets the "response_cache_enabled" field.
+func (m *GroupMutation) SetResponseCacheEnabled(b bool) {
+	m.response_cache_enabled = &b
+}
+
+// ResponseCacheEnabled returns the value of the "response_cache_enabled" field in the mutation.
+func (m *GroupMutation) ResponseCacheEnabled() (r bool, exists bool) {
+	v := m.response_cache_enabled
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldResponseCacheEnabled returns the old "response_cache_enabled" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldResponseCacheEnabled(ctx context.Context) (v bool, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldResponseCacheEnabled is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldResponseCacheEnabled requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldResponseCacheEnabled: %w", err)
+	}
+	return oldValue.ResponseCacheEnabled, nil
+}
+
+// ResetResponseCacheEnabled resets all changes to the "response_cache_enabled" field.
+func (m *GroupMutation) ResetResponseCacheEnabled() {
+	m.response_cache_enabled = nil
+}
+
+// SetResponseCacheTTLSeconds sets the "response_cache_ttl_seconds" field.
+func (m *GroupMutation) SetResponseCacheTTLSeconds(i int) {
+	m.response_cache_ttl_seconds = &i
+	m.addresponse_cache_ttl_seconds = nil
+}
+
+// ResponseCacheTTLSeconds returns the value of the "response_cache_ttl_seconds" field in the mutation.
+func (m *GroupMutation) ResponseCacheTTLSeconds() (r int, exists bool) {
+	v := m.response_cache_ttl_seconds
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldResponseCacheTTLSeconds returns the old "response_cache_ttl_seconds" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldResponseCacheTTLSeconds(ctx context.Context) (v int, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldResponseCacheTTLSeconds is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldResponseCacheTTLSeconds requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldResponseCacheTTLSeconds: %w", err)
+	}
+	return oldValue.ResponseCacheTTLSeconds, nil
+}
+
+// AddResponseCacheTTLSeconds adds i to the "response_cache_ttl_seconds" field.
+func (m *GroupMutation) AddResponseCacheTTLSeconds(i int) {
+	if m.addresponse_cache_ttl_seconds != nil {
+		*m.addresponse_cache_ttl_seconds += i
+	} else {
+		m.addresponse_cache_ttl_seconds = &i
+	}
+}
+
+// AddedResponseCacheTTLSeconds returns the value that was added to the "response_cache_ttl_seconds" field in this mutation.
+func (m *GroupMutation) AddedResponseCacheTTLSeconds() (r int, exists bool) {
+	v := m.addresponse_cache_ttl_seconds
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetResponseCacheTTLSeconds resets all changes to the "response_cache_ttl_seconds" field.
+func (m *GroupMutation) ResetResponseCacheTTLSeconds() {
+	m.response_cache_ttl_seconds = nil
+	m.addresponse_cache_ttl_seconds = nil
+}
+
+// SetResponseCacheHitMultiplier sets the "response_cache_hit_multiplier" field.
+func (m *GroupMutation) SetResponseCacheHitMultiplier(f float64) {
+	m.response_cache_hit_multiplier = &f
+	m.addresponse_cache_hit_multiplier = nil
+}
+
+// ResponseCacheHitMultiplier returns the value of the "response_cache_hit_multiplier" field in the mutation.
+func (m *GroupMutation) ResponseCacheHitMultiplier() (r float64, exists bool) {
+	v := m.response_cache_hit_multiplier
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldResponseCacheHitMultiplier returns the old "response_cache_hit_multiplier" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldResponseCacheHitMultiplier(ctx context.Context) (v float64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldResponseCacheHitMultiplier is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldResponseCacheHitMultiplier requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldResponseCacheHitMultiplier: %w", err)
+	}
+	return oldValue.ResponseCacheHitMultiplier, nil
+}
+
+// AddResponseCacheHitMultiplier adds f to the "response_cache_hit_multiplier" field.
+func (m *GroupMutation) AddResponseCacheHitMultiplier(f float64) {
+	if m.addresponse_cache_hit_multiplier != nil {
+		*m.addresponse_cache_hit_multiplier += f
+	} else {
+		m.addresponse_cache_hit_multiplier = &f
+	}
+}
+
+// AddedResponseCacheHitMultiplier returns the value that was added to the "response_cache_hit_multiplier" field in this mutation.
+func (m *GroupMutation) AddedResponseCacheHitMultiplier() (r float64, exists bool) {
+	v := m.addresponse_cache_hit_multiplier
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetResponseCacheHitMultiplier resets all changes to the "response_cache_hit_multiplier" field.
+func (m *GroupMutation) ResetResponseCacheHitMultiplier() {
+	m.response_cache_hit_multiplier = nil
+	m.addresponse_cache_hit_multiplier = nil
+}
+
+// SetVolumeTiers sets the "volume_tiers" field.
+func (m *GroupMutation) SetVolumeTiers(dt []domain.VolumeTier) {
+	m.volume_tiers = &dt
+	m.appendvolume_tiers = nil
+}
+
+// VolumeTiers returns the value of the "volume_tiers" field in the mutation.
+func (m *GroupMutation) VolumeTiers() (r []domain.VolumeTier, exists bool) {
+	v := m.volume_tiers
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldVolumeTiers returns the old "volume_tiers" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldVolumeTiers(ctx context.Context) (v []domain.VolumeTier, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldVolumeTiers is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldVolumeTiers requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldVolumeTiers: %w", err)
+	}
+	return oldValue.VolumeTiers, nil
+}
+
+// AppendVolumeTiers adds dt to the "volume_tiers" field.
+func (m *GroupMutation) AppendVolumeTiers(dt []domain.VolumeTier) {
+	m.appendvolume_tiers = append(m.appendvolume_tiers, dt...)
+}
+
+// AppendedVolumeTiers returns the list of values that were appended to the "volume_tiers" field in this mutation.
+func (m *GroupMutation) AppendedVolumeTiers() ([]domain.VolumeTier, bool) {
+	if len(m.appendvolume_tiers) == 0 {
+		return nil, false
+	}
+	return m.appendvolume_tiers, true
+}
+
+// ResetVolumeTiers resets all changes to the "volume_tiers" field.
+func (m *GroupMutation) ResetVolumeTiers() {
+	m.volume_tiers = nil
+	m.appendvolume_tiers = nil
+}
+
+// SetAllowGeminiNative sets the "allow_gemini_native" field.
+func (m *GroupMutation) SetAllowGeminiNative(b bool) {
+	m.allow_gemini_native = &b
+}
+
+// AllowGeminiNative returns the value of the "allow_gemini_native" field in the mutation.
+func (m *GroupMutation) AllowGeminiNative() (r bool, exists bool) {
+	v := m.allow_gemini_native
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldAllowGeminiNative returns the old "allow_gemini_native" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldAllowGeminiNative(ctx context.Context) (v bool, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldAllowGeminiNative is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldAllowGeminiNative requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldAllowGeminiNative: %w", err)
+	}
+	return oldValue.AllowGeminiNative, nil
+}
+
+// ResetAllowGeminiNative resets all changes to the "allow_gemini_native" field.
+func (m *GroupMutation) ResetAllowGeminiNative() {
+	m.allow_gemini_native = nil
+}
+
+// SetHedgePolicy sets the "hedge_policy" field.
+func (m *GroupMutation) SetHedgePolicy(dhp domain.GroupHedgePolicy) {
+	m.hedge_policy = &dhp
+}
+
+// HedgePolicy returns the value of the "hedge_policy" field in the mutation.
+func (m *GroupMutation) HedgePolicy() (r domain.GroupHedgePolicy, exists bool) {
+	v := m.hedge_policy
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldHedgePolicy returns the old "hedge_policy" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldHedgePolicy(ctx context.Context) (v domain.GroupHedgePolicy, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldHedgePolicy is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldHedgePolicy requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldHedgePolicy: %w", err)
+	}
+	return oldValue.HedgePolicy, nil
+}
+
+// ResetHedgePolicy resets all changes to the "hedge_policy" field.
+func (m *GroupMutation) ResetHedgePolicy() {
+	m.hedge_policy = nil
+}
+
+// SetMirrorPolicy sets the "mirror_policy" field.
+func (m *GroupMutation) SetMirrorPolicy(dmp domain.GroupMirrorPolicy) {
+	m.mirror_policy = &dmp
+}
+
+// MirrorPolicy returns the value of the "mirror_policy" field in the mutation.
+func (m *GroupMutation) MirrorPolicy() (r domain.GroupMirrorPolicy, exists bool) {
+	v := m.mirror_policy
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldMirrorPolicy returns the old "mirror_policy" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldMirrorPolicy(ctx context.Context) (v domain.GroupMirrorPolicy, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldMirrorPolicy is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldMirrorPolicy requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldMirrorPolicy: %w", err)
+	}
+	return oldValue.MirrorPolicy, nil
+}
+
+// ResetMirrorPolicy resets all changes to the "mirror_policy" field.
+func (m *GroupMutation) ResetMirrorPolicy() {
+	m.mirror_policy = nil
+}
+
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by ids.
 func (m *GroupMutation) AddAPIKeyIDs(ids ...int64) {
 	if m.api_keys == nil {
@@ -25435,7 +26179,7 @@ func (m *GroupMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *GroupMutation) Fields() []string {
-	fields := make([]string, 0, 60)
+	fields := make([]string, 0, 67)
 	if m.created_at != nil {
 		fields = append(fields, group.FieldCreatedAt)
 	}
@@ -25616,6 +26360,27 @@ func (m *GroupMutation) Fields() []string {
 	if m.profit_safety_buffer != nil {
 		fields = append(fields, group.FieldProfitSafetyBuffer)
 	}
+	if m.response_cache_enabled != nil {
+		fields = append(fields, group.FieldResponseCacheEnabled)
+	}
+	if m.response_cache_ttl_seconds != nil {
+		fields = append(fields, group.FieldResponseCacheTTLSeconds)
+	}
+	if m.response_cache_hit_multiplier != nil {
+		fields = append(fields, group.FieldResponseCacheHitMultiplier)
+	}
+	if m.volume_tiers != nil {
+		fields = append(fields, group.FieldVolumeTiers)
+	}
+	if m.allow_gemini_native != nil {
+		fields = append(fields, group.FieldAllowGeminiNative)
+	}
+	if m.hedge_policy != nil {
+		fields = append(fields, group.FieldHedgePolicy)
+	}
+	if m.mirror_policy != nil {
+		fields = append(fields, group.FieldMirrorPolicy)
+	}
 	return fields
 }
 
@@ -25744,6 +26509,20 @@ func (m *GroupMutation) Field(name string) (ent.Value, bool) {
 		return m.ProfitMinMargin()
 	case group.FieldProfitSafetyBuffer:
 		return m.ProfitSafetyBuffer()
+	case group.FieldResponseCacheEnabled:
+		return m.ResponseCacheEnabled()
+	case group.FieldResponseCacheTTLSeconds:
+		return m.ResponseCacheTTLSeconds()
+	case group.FieldResponseCacheHitMultiplier:
+		return m.ResponseCacheHitMultiplier()
+	case group.FieldVolumeTiers:
+		return m.VolumeTiers()
+	case group.FieldAllowGeminiNative:
+		return m.AllowGeminiNative()
+	case group.FieldHedgePolicy:
+		return m.HedgePolicy()
+	case group.FieldMirrorPolicy:
+		return m.MirrorPolicy()
 	}
 	return nil, false
 }
@@ -25873,6 +26652,20 @@ func (m *GroupMutation) OldField(ctx context.Context, name string) (ent.Value, e
 		return m.OldProfitMinMargin(ctx)
 	case group.FieldProfitSafetyBuffer:
 		return m.OldProfitSafetyBuffer(ctx)
+	case group.FieldResponseCacheEnabled:
+		return m.OldResponseCacheEnabled(ctx)
+	case group.FieldResponseCacheTTLSeconds:
+		return m.OldResponseCacheTTLSeconds(ctx)
+	case group.FieldResponseCacheHitMultiplier:
+		return m.OldResponseCacheHitMultiplier(ctx)
+	case group.FieldVolumeTiers:
+		return m.OldVolumeTiers(ctx)
+	case group.FieldAllowGeminiNative:
+		return m.OldAllowGeminiNative(ctx)
+	case group.FieldHedgePolicy:
+		return m.OldHedgePolicy(ctx)
+	case group.FieldMirrorPolicy:
+		return m.OldMirrorPolicy(ctx)
 	}
 	return nil, fmt.Errorf("unknown Group field %s", name)
 }
@@ -26302,6 +27095,55 @@ func (m *GroupMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetProfitSafetyBuffer(v)
 		return nil
+	case group.FieldResponseCacheEnabled:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetResponseCacheEnabled(v)
+		return nil
+	case group.FieldResponseCacheTTLSeconds:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetResponseCacheTTLSeconds(v)
+		return nil
+	case group.FieldResponseCacheHitMultiplier:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetResponseCacheHitMultiplier(v)
+		return nil
+	case group.FieldVolumeTiers:
+		v, ok := value.([]domain.VolumeTier)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetVolumeTiers(v)
+		return nil
+	case group.FieldAllowGeminiNative:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetAllowGeminiNative(v)
+		return nil
+	case group.FieldHedgePolicy:
+		v, ok := value.(domain.GroupHedgePolicy)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetHedgePolicy(v)
+		return nil
+	case group.FieldMirrorPolicy:
+		v, ok := value.(domain.GroupMirrorPolicy)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetMirrorPolicy(v)
+		return nil
 	}
 	return fmt.Errorf("unknown Group field %s", name)
 }
@@ -26391,6 +27233,12 @@ func (m *GroupMutation) AddedFields() []string {
 	if m.addprofit_safety_buffer != nil {
 		fields = append(fields, group.FieldProfitSafetyBuffer)
 	}
+	if m.addresponse_cache_ttl_seconds != nil {
+		fields = append(fields, group.FieldResponseCacheTTLSeconds)
+	}
+	if m.addresponse_cache_hit_multiplier != nil {
+		fields = append(fields, group.FieldResponseCacheHitMultiplier)
+	}
 	return fields
 }
 
@@ -26453,6 +27301,10 @@ func (m *GroupMutation) AddedField(name string) (ent.Value, bool) {
 		return m.AddedProfitMinMargin()
 	case group.FieldProfitSafetyBuffer:
 		return m.AddedProfitSafetyBuffer()
+	case group.FieldResponseCacheTTLSeconds:
+		return m.AddedResponseCacheTTLSeconds()
+	case group.FieldResponseCacheHitMultiplier:
+		return m.AddedResponseCacheHitMultiplier()
 	}
 	return nil, false
 }
@@ -26651,6 +27503,20 @@ func (m *GroupMutation) AddField(name string, value ent.Value) error {
 		}
 		m.AddProfitSafetyBuffer(v)
 		return nil
+	case group.FieldResponseCacheTTLSeconds:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddResponseCacheTTLSeconds(v)
+		return nil
+	case group.FieldResponseCacheHitMultiplier:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddResponseCacheHitMultiplier(v)
+		return nil
 	}
 	return fmt.Errorf("unknown Group numeric field %s", name)
 }
@@ -26987,6 +27853,27 @@ func (m *GroupMutation) ResetField(name string) error {
 	case group.FieldProfitSafetyBuffer:
 		m.ResetProfitSafetyBuffer()
 		return nil
+	case group.FieldResponseCacheEnabled:
+		m.ResetResponseCacheEnabled()
+		return nil
+	case group.FieldResponseCacheTTLSeconds:
+		m.ResetResponseCacheTTLSeconds()
+		return nil
+	case group.FieldResponseCacheHitMultiplier:
+		m.ResetResponseCacheHitMultiplier()
+		return nil
+	case group.FieldVolumeTiers:
+		m.ResetVolumeTiers()
+		return nil
+	case group.FieldAllowGeminiNative:
+		m.ResetAllowGeminiNative()
+		return nil
+	case group.FieldHedgePolicy:
+		m.ResetHedgePolicy()
+		return nil
+	case group.FieldMirrorPolicy:
+		m.ResetMirrorPolicy()
+		return nil
 	}
 	return fmt.Errorf("unknown Group field %s", name)
 }
@@ -29513,6 +30400,9 @@ type PaymentOrderMutation struct {
 	user_email               *string
 	user_name                *string
 	user_notes               *string
+	buyer_name               *string
+	buyer_tax_id             *string
+	buyer_address            *string
 	amount                   *float64
 	addamount                *float64
 	pay_amount               *float64
@@ -29818,6 +30708,153 @@ func (m *PaymentOrderMutation) ResetUserNotes() {
 	delete(m.clearedFields, paymentorder.FieldUserNotes)
 }
 
+// SetBuyerName sets the "buyer_name" field.
+func (m *PaymentOrderMutation) SetBuyerName(s string) {
+	m.buyer_name = &s
+}
+
+// BuyerName returns the value of the "buyer_name" field in the mutation.
+func (m *PaymentOrderMutation) BuyerName() (r string, exists bool) {
+	v := m.buyer_name
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldBuyerName returns the old "buyer_name" field's value of the PaymentOrder entity.
+// If the PaymentOrder object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *PaymentOrderMutation) OldBuyerName(ctx context.Context) (v *string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldBuyerName is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldBuyerName requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldBuyerName: %w", err)
+	}
+	return oldValue.BuyerName, nil
+}
+
+// ClearBuyerName clears the value of the "buyer_name" field.
+func (m *PaymentOrderMutation) ClearBuyerName() {
+	m.buyer_name = nil
+	m.clearedFields[paymentorder.FieldBuyerName] = struct{}{}
+}
+
+// BuyerNameCleared returns if the "buyer_name" field was cleared in this mutation.
+func (m *PaymentOrderMutation) BuyerNameCleared() bool {
+	_, ok := m.clearedFields[paymentorder.FieldBuyerName]
+	return ok
+}
+
+// ResetBuyerName resets all changes to the "buyer_name" field.
+func (m *PaymentOrderMutation) ResetBuyerName() {
+	m.buyer_name = nil
+	delete(m.clearedFields, paymentorder.FieldBuyerName)
+}
+
+// SetBuyerTaxID sets the "buyer_tax_id" field.
+func (m *PaymentOrderMutation) SetBuyerTaxID(s string) {
+	m.buyer_tax_id = &s
+}
+
+// BuyerTaxID returns the value of the "buyer_tax_id" field in the mutation.
+func (m *PaymentOrderMutation) BuyerTaxID() (r string, exists bool) {
+	v := m.buyer_tax_id
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldBuyerTaxID returns the old "buyer_tax_id" field's value of the PaymentOrder entity.
+// If the PaymentOrder object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *PaymentOrderMutation) OldBuyerTaxID(ctx context.Context) (v *string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldBuyerTaxID is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldBuyerTaxID requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldBuyerTaxID: %w", err)
+	}
+	return oldValue.BuyerTaxID, nil
+}
+
+// ClearBuyerTaxID clears the value of the "buyer_tax_id" field.
+func (m *PaymentOrderMutation) ClearBuyerTaxID() {
+	m.buyer_tax_id = nil
+	m.clearedFields[paymentorder.FieldBuyerTaxID] = struct{}{}
+}
+
+// BuyerTaxIDCleared returns if the "buyer_tax_id" field was cleared in this mutation.
+func (m *PaymentOrderMutation) BuyerTaxIDCleared() bool {
+	_, ok := m.clearedFields[paymentorder.FieldBuyerTaxID]
+	return ok
+}
+
+// ResetBuyerTaxID resets all changes to the "buyer_tax_id" field.
+func (m *PaymentOrderMutation) ResetBuyerTaxID() {
+	m.buyer_tax_id = nil
+	delete(m.clearedFields, paymentorder.FieldBuyerTaxID)
+}
+
+// SetBuyerAddress sets the "buyer_address" field.
+func (m *PaymentOrderMutation) SetBuyerAddress(s string) {
+	m.buyer_address = &s
+}
+
+// BuyerAddress returns the value of the "buyer_address" field in the mutation.
+func (m *PaymentOrderMutation) BuyerAddress() (r string, exists bool) {
+	v := m.buyer_address
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldBuyerAddress returns the old "buyer_address" field's value of the PaymentOrder entity.
+// If the PaymentOrder object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *PaymentOrderMutation) OldBuyerAddress(ctx context.Context) (v *string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldBuyerAddress is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldBuyerAddress requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldBuyerAddress: %w", err)
+	}
+	return oldValue.BuyerAddress, nil
+}
+
+// ClearBuyerAddress clears the value of the "buyer_address" field.
+func (m *PaymentOrderMutation) ClearBuyerAddress() {
+	m.buyer_address = nil
+	m.clearedFields[paymentorder.FieldBuyerAddress] = struct{}{}
+}
+
+// BuyerAddressCleared returns if the "buyer_address" field was cleared in this mutation.
+func (m *PaymentOrderMutation) BuyerAddressCleared() bool {
+	_, ok := m.clearedFields[paymentorder.FieldBuyerAddress]
+	return ok
+}
+
+// ResetBuyerAddress resets all changes to the "buyer_address" field.
+func (m *PaymentOrderMutation) ResetBuyerAddress() {
+	m.buyer_address = nil
+	delete(m.clearedFields, paymentorder.FieldBuyerAddress)
+}
+
 // SetAmount sets the "amount" field.
 func (m *PaymentOrderMutation) SetAmount(f float64) {
 	m.amount = &f
@@ -31529,7 +32566,7 @@ func (m *PaymentOrderMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *PaymentOrderMutation) Fields() []string {
-	fields := make([]string, 0, 39)
+	fields := make([]string, 0, 42)
 	if m.user != nil {
 		fields = append(fields, paymentorder.FieldUserID)
 	}
@@ -31542,6 +32579,15 @@ func (m *PaymentOrderMutation) Fields() []string {
 	if m.user_notes != nil {
 		fields = append(fields, paymentorder.FieldUserNotes)
 	}
+	if m.buyer_name != nil {
+		fields = append(fields, paymentorder.FieldBuyerName)
+	}
+	if m.buyer_tax_id != nil {
+		fields = append(fields, paymentorder.FieldBuyerTaxID)
+	}
+	if m.buyer_address != nil {
+		fields = append(fields, paymentorder.FieldBuyerAddress)
+	}
 	if m.amount != nil {
 		fields = append(fields, paymentorder.FieldAmount)
 	}
@@ -31663,6 +32709,12 @@ func (m *PaymentOrderMutation) Field(name string) (ent.Value, bool) {
 		return m.UserName()
 	case paymentorder.FieldUserNotes:
 		return m.UserNotes()
+	case paymentorder.FieldBuyerName:
+		return m.BuyerName()
+	case paymentorder.FieldBuyerTaxID:
+		return m.BuyerTaxID()
+	case paymentorder.FieldBuyerAddress:
+		return m.BuyerAddress()
 	case paymentorder.FieldAmount:
 		return m.Amount()
 	case paymentorder.FieldPayAmount:
@@ -31750,6 +32802,12 @@ func (m *PaymentOrderMutation) OldField(ctx context.Context, name string) (ent.V
 		return m.OldUserName(ctx)
 	case paymentorder.FieldUserNotes:
 		return m.OldUserNotes(ctx)
+	case paymentorder.FieldBuyerName:
+		return m.OldBuyerName(ctx)
+	case paymentorder.FieldBuyerTaxID:
+		return m.OldBuyerTaxID(ctx)
+	case paymentorder.FieldBuyerAddress:
+		return m.OldBuyerAddress(ctx)
 	case paymentorder.FieldAmount:
 		return m.OldAmount(ctx)
 	case paymentorder.FieldPayAmount:
@@ -31857,6 +32915,27 @@ func (m *PaymentOrderMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetUserNotes(v)
 		return nil
+	case paymentorder.FieldBuyerName:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetBuyerName(v)
+		return nil
+	case paymentorder.FieldBuyerTaxID:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetBuyerTaxID(v)
+		return nil
+	case paymentorder.FieldBuyerAddress:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetBuyerAddress(v)
+		return nil
 	case paymentorder.FieldAmount:
 		v, ok := value.(float64)
 		if !ok {
@@ -32222,6 +33301,15 @@ func (m *PaymentOrderMutation) ClearedFields() []string {
 	if m.FieldCleared(paymentorder.FieldUserNotes) {
 		fields = append(fields, paymentorder.FieldUserNotes)
 	}
+	if m.FieldCleared(paymentorder.FieldBuyerName) {
+		fields = append(fields, paymentorder.FieldBuyerName)
+	}
+	if m.FieldCleared(paymentorder.FieldBuyerTaxID) {
+		fields = append(fields, paymentorder.FieldBuyerTaxID)
+	}
+	if m.FieldCleared(paymentorder.FieldBuyerAddress) {
+		fields = append(fields, paymentorder.FieldBuyerAddress)
+	}
 	if m.FieldCleared(paymentorder.FieldPayURL) {
 		fields = append(fields, paymentorder.FieldPayURL)
 	}
@@ -32296,6 +33384,15 @@ func (m *PaymentOrderMutation) ClearField(name string) error {
 	case paymentorder.FieldUserNotes:
 		m.ClearUserNotes()
 		return nil
+	case paymentorder.FieldBuyerName:
+		m.ClearBuyerName()
+		return nil
+	case paymentorder.FieldBuyerTaxID:
+		m.ClearBuyerTaxID()
+		return nil
+	case paymentorder.FieldBuyerAddress:
+		m.ClearBuyerAddress()
+		return nil
 	case paymentorder.FieldPayURL:
 		m.ClearPayURL()
 		return nil
@@ -32373,6 +33470,15 @@ func (m *PaymentOrderMutation) ResetField(name string) error {
 	case paymentorder.FieldUserNotes:
 		m.ResetUserNotes()
 		return nil
+	case paymentorder.FieldBuyerName:
+		m.ResetBuyerName()
+		return nil
+	case paymentorder.FieldBuyerTaxID:
+		m.ResetBuyerTaxID()
+		return nil
+	case paymentorder.FieldBuyerAddress:
+		m.ResetBuyerAddress()
+		return nil
 	case paymentorder.FieldAmount:
 		m.ResetAmount()
 		return nil
@@ -42998,8 +44104,8 @@ func (m *UsageCleanupTaskMutation) ResetStatus() {
 }
 
 // SetFilters sets the "filters" field.
-func (m *UsageCleanupTaskMutation) SetFilters(jm json.RawMessage) {
-	m.filters = &jm
+func (m *UsageCleanupTaskMutation) SetFilters(j json.RawMessage) {
+	m.filters = &j
 	m.appendfilters = nil
 }
 
@@ -43029,9 +44135,9 @@ func (m *UsageCleanupTaskMutation) OldFilters(ctx context.Context) (v json.RawMe
 	return oldValue.Filters, nil
 }
 
-// AppendFilters adds jm to the "filters" field.
-func (m *UsageCleanupTaskMutation) AppendFilters(jm json.RawMessage) {
-	m.appendfilters = append(m.appendfilters, jm...)
+// AppendFilters adds j to the "filters" field.
+func (m *UsageCleanupTaskMutation) AppendFilters(j json.RawMessage) {
+	m.appendfilters = append(m.appendfilters, j...)
 }
 
 // AppendedFilters returns the list of values that were appended to the "filters" field in this mutation.
@@ -48053,6 +49159,8 @@ type UserMutation struct {
 	addtotal_recharged            *float64
 	rpm_limit                     *int
 	addrpm_limit                  *int
+	credit_limit                  *float64
+	addcredit_limit               *float64
 	clearedFields                 map[string]struct{}
 	api_keys                      map[int64]struct{}
 	removedapi_keys               map[int64]struct{}
@@ -49259,6 +50367,62 @@ func (m *UserMutation) ResetRpmLimit() {
 	m.addrpm_limit = nil
 }
 
+// SetCreditLimit sets the "credit_limit" field.
+func (m *UserMutation) SetCreditLimit(f float64) {
+	m.credit_limit = &f
+	m.addcredit_limit = nil
+}
+
+// CreditLimit returns the value of the "credit_limit" field in the mutation.
+func (m *UserMutation) CreditLimit() (r float64, exists bool) {
+	v := m.credit_limit
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldCreditLimit returns the old "credit_limit" field's value of the User entity.
+// If the User object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *UserMutation) OldCreditLimit(ctx context.Context) (v float64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldCreditLimit is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldCreditLimit requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldCreditLimit: %w", err)
+	}
+	return oldValue.CreditLimit, nil
+}
+
+// AddCreditLimit adds f to the "credit_limit" field.
+func (m *UserMutation) AddCreditLimit(f float64) {
+	if m.addcredit_limit != nil {
+		*m.addcredit_limit += f
+	} else {
+		m.addcredit_limit = &f
+	}
+}
+
+// AddedCreditLimit returns the value that was added to the "credit_limit" field in this mutation.
+func (m *UserMutation) AddedCreditLimit() (r float64, exists bool) {
+	v := m.addcredit_limit
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetCreditLimit resets all changes to the "credit_limit" field.
+func (m *UserMutation) ResetCreditLimit() {
+	m.credit_limit = nil
+	m.addcredit_limit = nil
+}
+
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by ids.
 func (m *UserMutation) AddAPIKeyIDs(ids ...int64) {
 	if m.api_keys == nil {
@@ -49995,7 +51159,7 @@ func (m *UserMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *UserMutation) Fields() []string {
-	fields := make([]string, 0, 24)
+	fields := make([]string, 0, 25)
 	if m.created_at != nil {
 		fields = append(fields, user.FieldCreatedAt)
 	}
@@ -50068,6 +51232,9 @@ func (m *UserMutation) Fields() []string {
 	if m.rpm_limit != nil {
 		fields = append(fields, user.FieldRpmLimit)
 	}
+	if m.credit_limit != nil {
+		fields = append(fields, user.FieldCreditLimit)
+	}
 	return fields
 }
 
@@ -50124,6 +51291,8 @@ func (m *UserMutation) Field(name string) (ent.Value, bool) {
 		return m.TotalRecharged()
 	case user.FieldRpmLimit:
 		return m.RpmLimit()
+	case user.FieldCreditLimit:
+		return m.CreditLimit()
 	}
 	return nil, false
 }
@@ -50181,6 +51350,8 @@ func (m *UserMutation) OldField(ctx context.Context, name string) (ent.Value, er
 		return m.OldTotalRecharged(ctx)
 	case user.FieldRpmLimit:
 		return m.OldRpmLimit(ctx)
+	case user.FieldCreditLimit:
+		return m.OldCreditLimit(ctx)
 	}
 	return nil, fmt.Errorf("unknown User field %s", name)
 }
@@ -50358,6 +51529,13 @@ func (m *UserMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetRpmLimit(v)
 		return nil
+	case user.FieldCreditLimit:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetCreditLimit(v)
+		return nil
 	}
 	return fmt.Errorf("unknown User field %s", name)
 }
@@ -50384,6 +51562,9 @@ func (m *UserMutation) AddedFields() []string {
 	if m.addrpm_limit != nil {
 		fields = append(fields, user.FieldRpmLimit)
 	}
+	if m.addcredit_limit != nil {
+		fields = append(fields, user.FieldCreditLimit)
+	}
 	return fields
 }
 
@@ -50404,6 +51585,8 @@ func (m *UserMutation) AddedField(name string) (ent.Value, bool) {
 		return m.AddedTotalRecharged()
 	case user.FieldRpmLimit:
 		return m.AddedRpmLimit()
+	case user.FieldCreditLimit:
+		return m.AddedCreditLimit()
 	}
 	return nil, false
 }
@@ -50455,6 +51638,13 @@ func (m *UserMutation) AddField(name string, value ent.Value) error {
 		}
 		m.AddRpmLimit(v)
 		return nil
+	case user.FieldCreditLimit:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddCreditLimit(v)
+		return nil
 	}
 	return fmt.Errorf("unknown User numeric field %s", name)
 }
@@ -50593,6 +51783,9 @@ func (m *UserMutation) ResetField(name string) error {
 	case user.FieldRpmLimit:
 		m.ResetRpmLimit()
 		return nil
+	case user.FieldCreditLimit:
+		m.ResetCreditLimit()
+		return nil
 	}
 	return fmt.Errorf("unknown User field %s", name)
 }
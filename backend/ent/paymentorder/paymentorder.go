@@ -22,6 +22,12 @@ const (
 	FieldUserName = "user_name"
 	// FieldUserNotes holds the string denoting the user_notes field in the database.
 	FieldUserNotes = "user_notes"
+	// FieldBuyerName holds the string denoting the buyer_name field in the database.
+	FieldBuyerName = "buyer_name"
+	// FieldBuyerTaxID holds the string denoting the buyer_tax_id field in the database.
+	FieldBuyerTaxID = "buyer_tax_id"
+	// FieldBuyerAddress holds the string denoting the buyer_address field in the database.
+	FieldBuyerAddress = "buyer_address"
 	// FieldAmount holds the string denoting the amount field in the database.
 	FieldAmount = "amount"
 	// FieldPayAmount holds the string denoting the pay_amount field in the database.
@@ -112,6 +118,9 @@ var Columns = []string{
 	FieldUserEmail,
 	FieldUserName,
 	FieldUserNotes,
+	FieldBuyerName,
+	FieldBuyerTaxID,
+	FieldBuyerAddress,
 	FieldAmount,
 	FieldPayAmount,
 	FieldFeeRate,
@@ -164,6 +173,10 @@ var (
 	UserEmailValidator func(string) error
 	// UserNameValidator is a validator for the "user_name" field. It is called by the builders before save.
 	UserNameValidator func(string) error
+	// BuyerNameValidator is a validator for the "buyer_name" field. It is called by the builders before save.
+	BuyerNameValidator func(string) error
+	// BuyerTaxIDValidator is a validator for the "buyer_tax_id" field. It is called by the builders before save.
+	BuyerTaxIDValidator func(string) error
 	// DefaultFeeRate holds the default value on creation for the "fee_rate" field.
 	DefaultFeeRate float64
 	// RechargeCodeValidator is a validator for the "recharge_code" field. It is called by the builders before save.
@@ -234,6 +247,21 @@ func ByUserNotes(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldUserNotes, opts...).ToFunc()
 }
 
+// ByBuyerName orders the results by the buyer_name field.
+func ByBuyerName(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldBuyerName, opts...).ToFunc()
+}
+
+// ByBuyerTaxID orders the results by the buyer_tax_id field.
+func ByBuyerTaxID(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldBuyerTaxID, opts...).ToFunc()
+}
+
+// ByBuyerAddress orders the results by the buyer_address field.
+func ByBuyerAddress(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldBuyerAddress, opts...).ToFunc()
+}
+
 // ByAmount orders the results by the amount field.
 func ByAmount(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldAmount, opts...).ToFunc()
@@ -75,6 +75,21 @@ func UserNotes(v string) predicate.PaymentOrder {
 	return predicate.PaymentOrder(sql.FieldEQ(FieldUserNotes, v))
 }
 
+// BuyerName applies equality check predicate on the "buyer_name" field. It's identical to BuyerNameEQ.
+func BuyerName(v string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldEQ(FieldBuyerName, v))
+}
+
+// BuyerTaxID applies equality check predicate on the "buyer_tax_id" field. It's identical to BuyerTaxIDEQ.
+func BuyerTaxID(v string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldEQ(FieldBuyerTaxID, v))
+}
+
+// BuyerAddress applies equality check predicate on the "buyer_address" field. It's identical to BuyerAddressEQ.
+func BuyerAddress(v string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldEQ(FieldBuyerAddress, v))
+}
+
 // Amount applies equality check predicate on the "amount" field. It's identical to AmountEQ.
 func Amount(v float64) predicate.PaymentOrder {
 	return predicate.PaymentOrder(sql.FieldEQ(FieldAmount, v))
@@ -470,6 +485,231 @@ func UserNotesContainsFold(v string) predicate.PaymentOrder {
 	return predicate.PaymentOrder(sql.FieldContainsFold(FieldUserNotes, v))
 }
 
+// BuyerNameEQ applies the EQ predicate on the "buyer_name" field.
+func BuyerNameEQ(v string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldEQ(FieldBuyerName, v))
+}
+
+// BuyerNameNEQ applies the NEQ predicate on the "buyer_name" field.
+func BuyerNameNEQ(v string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldNEQ(FieldBuyerName, v))
+}
+
+// BuyerNameIn applies the In predicate on the "buyer_name" field.
+func BuyerNameIn(vs ...string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldIn(FieldBuyerName, vs...))
+}
+
+// BuyerNameNotIn applies the NotIn predicate on the "buyer_name" field.
+func BuyerNameNotIn(vs ...string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldNotIn(FieldBuyerName, vs...))
+}
+
+// BuyerNameGT applies the GT predicate on the "buyer_name" field.
+func BuyerNameGT(v string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldGT(FieldBuyerName, v))
+}
+
+// BuyerNameGTE applies the GTE predicate on the "buyer_name" field.
+func BuyerNameGTE(v string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldGTE(FieldBuyerName, v))
+}
+
+// BuyerNameLT applies the LT predicate on the "buyer_name" field.
+func BuyerNameLT(v string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldLT(FieldBuyerName, v))
+}
+
+// BuyerNameLTE applies the LTE predicate on the "buyer_name" field.
+func BuyerNameLTE(v string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldLTE(FieldBuyerName, v))
+}
+
+// BuyerNameContains applies the Contains predicate on the "buyer_name" field.
+func BuyerNameContains(v string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldContains(FieldBuyerName, v))
+}
+
+// BuyerNameHasPrefix applies the HasPrefix predicate on the "buyer_name" field.
+func BuyerNameHasPrefix(v string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldHasPrefix(FieldBuyerName, v))
+}
+
+// BuyerNameHasSuffix applies the HasSuffix predicate on the "buyer_name" field.
+func BuyerNameHasSuffix(v string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldHasSuffix(FieldBuyerName, v))
+}
+
+// BuyerNameIsNil applies the IsNil predicate on the "buyer_name" field.
+func BuyerNameIsNil() predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldIsNull(FieldBuyerName))
+}
+
+// BuyerNameNotNil applies the NotNil predicate on the "buyer_name" field.
+func BuyerNameNotNil() predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldNotNull(FieldBuyerName))
+}
+
+// BuyerNameEqualFold applies the EqualFold predicate on the "buyer_name" field.
+func BuyerNameEqualFold(v string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldEqualFold(FieldBuyerName, v))
+}
+
+// BuyerNameContainsFold applies the ContainsFold predicate on the "buyer_name" field.
+func BuyerNameContainsFold(v string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldContainsFold(FieldBuyerName, v))
+}
+
+// BuyerTaxIDEQ applies the EQ predicate on the "buyer_tax_id" field.
+func BuyerTaxIDEQ(v string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldEQ(FieldBuyerTaxID, v))
+}
+
+// BuyerTaxIDNEQ applies the NEQ predicate on the "buyer_tax_id" field.
+func BuyerTaxIDNEQ(v string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldNEQ(FieldBuyerTaxID, v))
+}
+
+// BuyerTaxIDIn applies the In predicate on the "buyer_tax_id" field.
+func BuyerTaxIDIn(vs ...string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldIn(FieldBuyerTaxID, vs...))
+}
+
+// BuyerTaxIDNotIn applies the NotIn predicate on the "buyer_tax_id" field.
+func BuyerTaxIDNotIn(vs ...string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldNotIn(FieldBuyerTaxID, vs...))
+}
+
+// BuyerTaxIDGT applies the GT predicate on the "buyer_tax_id" field.
+func BuyerTaxIDGT(v string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldGT(FieldBuyerTaxID, v))
+}
+
+// BuyerTaxIDGTE applies the GTE predicate on the "buyer_tax_id" field.
+func BuyerTaxIDGTE(v string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldGTE(FieldBuyerTaxID, v))
+}
+
+// BuyerTaxIDLT applies the LT predicate on the "buyer_tax_id" field.
+func BuyerTaxIDLT(v string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldLT(FieldBuyerTaxID, v))
+}
+
+// BuyerTaxIDLTE applies the LTE predicate on the "buyer_tax_id" field.
+func BuyerTaxIDLTE(v string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldLTE(FieldBuyerTaxID, v))
+}
+
+// BuyerTaxIDContains applies the Contains predicate on the "buyer_tax_id" field.
+func BuyerTaxIDContains(v string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldContains(FieldBuyerTaxID, v))
+}
+
+// BuyerTaxIDHasPrefix applies the HasPrefix predicate on the "buyer_tax_id" field.
+func BuyerTaxIDHasPrefix(v string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldHasPrefix(FieldBuyerTaxID, v))
+}
+
+// BuyerTaxIDHasSuffix applies the HasSuffix predicate on the "buyer_tax_id" field.
+func BuyerTaxIDHasSuffix(v string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldHasSuffix(FieldBuyerTaxID, v))
+}
+
+// BuyerTaxIDIsNil applies the IsNil predicate on the "buyer_tax_id" field.
+func BuyerTaxIDIsNil() predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldIsNull(FieldBuyerTaxID))
+}
+
+// BuyerTaxIDNotNil applies the NotNil predicate on the "buyer_tax_id" field.
+func BuyerTaxIDNotNil() predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldNotNull(FieldBuyerTaxID))
+}
+
+// BuyerTaxIDEqualFold applies the EqualFold predicate on the "buyer_tax_id" field.
+func BuyerTaxIDEqualFold(v string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldEqualFold(FieldBuyerTaxID, v))
+}
+
+// BuyerTaxIDContainsFold applies the ContainsFold predicate on the "buyer_tax_id" field.
+func BuyerTaxIDContainsFold(v string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldContainsFold(FieldBuyerTaxID, v))
+}
+
+// BuyerAddressEQ applies the EQ predicate on the "buyer_address" field.
+func BuyerAddressEQ(v string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldEQ(FieldBuyerAddress, v))
+}
+
+// BuyerAddressNEQ applies the NEQ predicate on the "buyer_address" field.
+func BuyerAddressNEQ(v string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldNEQ(FieldBuyerAddress, v))
+}
+
+// BuyerAddressIn applies the In predicate on the "buyer_address" field.
+func BuyerAddressIn(vs ...string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldIn(FieldBuyerAddress, vs...))
+}
+
+// BuyerAddressNotIn applies the NotIn predicate on the "buyer_address" field.
+func BuyerAddressNotIn(vs ...string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldNotIn(FieldBuyerAddress, vs...))
+}
+
+// BuyerAddressGT applies the GT predicate on the "buyer_address" field.
+func BuyerAddressGT(v string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldGT(FieldBuyerAddress, v))
+}
+
+// BuyerAddressGTE applies the GTE predicate on the "buyer_address" field.
+func BuyerAddressGTE(v string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldGTE(FieldBuyerAddress, v))
+}
+
+// BuyerAddressLT applies the LT predicate on the "buyer_address" field.
+func BuyerAddressLT(v string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldLT(FieldBuyerAddress, v))
+}
+
+// BuyerAddressLTE applies the LTE predicate on the "buyer_address" field.
+func BuyerAddressLTE(v string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldLTE(FieldBuyerAddress, v))
+}
+
+// BuyerAddressContains applies the Contains predicate on the "buyer_address" field.
+func BuyerAddressContains(v string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldContains(FieldBuyerAddress, v))
+}
+
+// BuyerAddressHasPrefix applies the HasPrefix predicate on the "buyer_address" field.
+func BuyerAddressHasPrefix(v string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldHasPrefix(FieldBuyerAddress, v))
+}
+
+// BuyerAddressHasSuffix applies the HasSuffix predicate on the "buyer_address" field.
+func BuyerAddressHasSuffix(v string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldHasSuffix(FieldBuyerAddress, v))
+}
+
+// BuyerAddressIsNil applies the IsNil predicate on the "buyer_address" field.
+func BuyerAddressIsNil() predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldIsNull(FieldBuyerAddress))
+}
+
+// BuyerAddressNotNil applies the NotNil predicate on the "buyer_address" field.
+func BuyerAddressNotNil() predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldNotNull(FieldBuyerAddress))
+}
+
+// BuyerAddressEqualFold applies the EqualFold predicate on the "buyer_address" field.
+func BuyerAddressEqualFold(v string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldEqualFold(FieldBuyerAddress, v))
+}
+
+// BuyerAddressContainsFold applies the ContainsFold predicate on the "buyer_address" field.
+func BuyerAddressContainsFold(v string) predicate.PaymentOrder {
+	return predicate.PaymentOrder(sql.FieldContainsFold(FieldBuyerAddress, v))
+}
+
 // AmountEQ applies the EQ predicate on the "amount" field.
 func AmountEQ(v float64) predicate.PaymentOrder {
 	return predicate.PaymentOrder(sql.FieldEQ(FieldAmount, v))
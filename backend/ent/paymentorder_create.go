@@ -55,6 +55,48 @@ func (_c *PaymentOrderCreate) SetNillableUserNotes(v *string) *PaymentOrderCreat
 	return _c
 }
 
+// SetBuyerName sets the "buyer_name" field.
+func (_c *PaymentOrderCreate) SetBuyerName(v string) *PaymentOrderCreate {
+	_c.mutation.SetBuyerName(v)
+	return _c
+}
+
+// SetNillableBuyerName sets the "buyer_name" field if the given value is not nil.
+func (_c *PaymentOrderCreate) SetNillableBuyerName(v *string) *PaymentOrderCreate {
+	if v != nil {
+		_c.SetBuyerName(*v)
+	}
+	return _c
+}
+
+// SetBuyerTaxID sets the "buyer_tax_id" field.
+func (_c *PaymentOrderCreate) SetBuyerTaxID(v string) *PaymentOrderCreate {
+	_c.mutation.SetBuyerTaxID(v)
+	return _c
+}
+
+// SetNillableBuyerTaxID sets the "buyer_tax_id" field if the given value is not nil.
+func (_c *PaymentOrderCreate) SetNillableBuyerTaxID(v *string) *PaymentOrderCreate {
+	if v != nil {
+		_c.SetBuyerTaxID(*v)
+	}
+	return _c
+}
+
+// SetBuyerAddress sets the "buyer_address" field.
+func (_c *PaymentOrderCreate) SetBuyerAddress(v string) *PaymentOrderCreate {
+	_c.mutation.SetBuyerAddress(v)
+	return _c
+}
+
+// SetNillableBuyerAddress sets the "buyer_address" field if the given value is not nil.
+func (_c *PaymentOrderCreate) SetNillableBuyerAddress(v *string) *PaymentOrderCreate {
+	if v != nil {
+		_c.SetBuyerAddress(*v)
+	}
+	return _c
+}
+
 // SetAmount sets the "amount" field.
 func (_c *PaymentOrderCreate) SetAmount(v float64) *PaymentOrderCreate {
 	_c.mutation.SetAmount(v)
@@ -568,6 +610,16 @@ func (_c *PaymentOrderCreate) check() error {
 			return &ValidationError{Name: "user_name", err: fmt.Errorf(`ent: validator failed for field "PaymentOrder.user_name": %w`, err)}
 		}
 	}
+	if v, ok := _c.mutation.BuyerName(); ok {
+		if err := paymentorder.BuyerNameValidator(v); err != nil {
+			return &ValidationError{Name: "buyer_name", err: fmt.Errorf(`ent: validator failed for field "PaymentOrder.buyer_name": %w`, err)}
+		}
+	}
+	if v, ok := _c.mutation.BuyerTaxID(); ok {
+		if err := paymentorder.BuyerTaxIDValidator(v); err != nil {
+			return &ValidationError{Name: "buyer_tax_id", err: fmt.Errorf(`ent: validator failed for field "PaymentOrder.buyer_tax_id": %w`, err)}
+		}
+	}
 	if _, ok := _c.mutation.Amount(); !ok {
 		return &ValidationError{Name: "amount", err: errors.New(`ent: missing required field "PaymentOrder.amount"`)}
 	}
@@ -713,6 +765,18 @@ func (_c *PaymentOrderCreate) createSpec() (*PaymentOrder, *sqlgraph.CreateSpec)
 		_spec.SetField(paymentorder.FieldUserNotes, field.TypeString, value)
 		_node.UserNotes = &value
 	}
+	if value, ok := _c.mutation.BuyerName(); ok {
+		_spec.SetField(paymentorder.FieldBuyerName, field.TypeString, value)
+		_node.BuyerName = &value
+	}
+	if value, ok := _c.mutation.BuyerTaxID(); ok {
+		_spec.SetField(paymentorder.FieldBuyerTaxID, field.TypeString, value)
+		_node.BuyerTaxID = &value
+	}
+	if value, ok := _c.mutation.BuyerAddress(); ok {
+		_spec.SetField(paymentorder.FieldBuyerAddress, field.TypeString, value)
+		_node.BuyerAddress = &value
+	}
 	if value, ok := _c.mutation.Amount(); ok {
 		_spec.SetField(paymentorder.FieldAmount, field.TypeFloat64, value)
 		_node.Amount = value
@@ -976,6 +1040,60 @@ func (u *PaymentOrderUpsert) ClearUserNotes() *PaymentOrderUpsert {
 	return u
 }
 
+// SetBuyerName sets the "buyer_name" field.
+func (u *PaymentOrderUpsert) SetBuyerName(v string) *PaymentOrderUpsert {
+	u.Set(paymentorder.FieldBuyerName, v)
+	return u
+}
+
+// UpdateBuyerName sets the "buyer_name" field to the value that was provided on create.
+func (u *PaymentOrderUpsert) UpdateBuyerName() *PaymentOrderUpsert {
+	u.SetExcluded(paymentorder.FieldBuyerName)
+	return u
+}
+
+// ClearBuyerName clears the value of the "buyer_name" field.
+func (u *PaymentOrderUpsert) ClearBuyerName() *PaymentOrderUpsert {
+	u.SetNull(paymentorder.FieldBuyerName)
+	return u
+}
+
+// SetBuyerTaxID sets the "buyer_tax_id" field.
+func (u *PaymentOrderUpsert) SetBuyerTaxID(v string) *PaymentOrderUpsert {
+	u.Set(paymentorder.FieldBuyerTaxID, v)
+	return u
+}
+
+// UpdateBuyerTaxID sets the "buyer_tax_id" field to the value that was provided on create.
+func (u *PaymentOrderUpsert) UpdateBuyerTaxID() *PaymentOrderUpsert {
+	u.SetExcluded(paymentorder.FieldBuyerTaxID)
+	return u
+}
+
+// ClearBuyerTaxID clears the value of the "buyer_tax_id" field.
+func (u *PaymentOrderUpsert) ClearBuyerTaxID() *PaymentOrderUpsert {
+	u.SetNull(paymentorder.FieldBuyerTaxID)
+	return u
+}
+
+// SetBuyerAddress sets the "buyer_address" field.
+func (u *PaymentOrderUpsert) SetBuyerAddress(v string) *PaymentOrderUpsert {
+	u.Set(paymentorder.FieldBuyerAddress, v)
+	return u
+}
+
+// UpdateBuyerAddress sets the "buyer_address" field to the value that was provided on create.
+func (u *PaymentOrderUpsert) UpdateBuyerAddress() *PaymentOrderUpsert {
+	u.SetExcluded(paymentorder.FieldBuyerAddress)
+	return u
+}
+
+// ClearBuyerAddress clears the value of the "buyer_address" field.
+func (u *PaymentOrderUpsert) ClearBuyerAddress() *PaymentOrderUpsert {
+	u.SetNull(paymentorder.FieldBuyerAddress)
+	return u
+}
+
 // SetAmount sets the "amount" field.
 func (u *PaymentOrderUpsert) SetAmount(v float64) *PaymentOrderUpsert {
 	u.Set(paymentorder.FieldAmount, v)
@@ -1648,6 +1766,69 @@ func (u *PaymentOrderUpsertOne) ClearUserNotes() *PaymentOrderUpsertOne {
 	})
 }
 
+// SetBuyerName sets the "buyer_name" field.
+func (u *PaymentOrderUpsertOne) SetBuyerName(v string) *PaymentOrderUpsertOne {
+	return u.Update(func(s *PaymentOrderUpsert) {
+		s.SetBuyerName(v)
+	})
+}
+
+// UpdateBuyerName sets the "buyer_name" field to the value that was provided on create.
+func (u *PaymentOrderUpsertOne) UpdateBuyerName() *PaymentOrderUpsertOne {
+	return u.Update(func(s *PaymentOrderUpsert) {
+		s.UpdateBuyerName()
+	})
+}
+
+// ClearBuyerName clears the value of the "buyer_name" field.
+func (u *PaymentOrderUpsertOne) ClearBuyerName() *PaymentOrderUpsertOne {
+	return u.Update(func(s *PaymentOrderUpsert) {
+		s.ClearBuyerName()
+	})
+}
+
+// SetBuyerTaxID sets the "buyer_tax_id" field.
+func (u *PaymentOrderUpsertOne) SetBuyerTaxID(v string) *PaymentOrderUpsertOne {
+	return u.Update(func(s *PaymentOrderUpsert) {
+		s.SetBuyerTaxID(v)
+	})
+}
+
+// UpdateBuyerTaxID sets the "buyer_tax_id" field to the value that was provided on create.
+func (u *PaymentOrderUpsertOne) UpdateBuyerTaxID() *PaymentOrderUpsertOne {
+	return u.Update(func(s *PaymentOrderUpsert) {
+		s.UpdateBuyerTaxID()
+	})
+}
+
+// ClearBuyerTaxID clears the value of the "buyer_tax_id" field.
+func (u *PaymentOrderUpsertOne) ClearBuyerTaxID() *PaymentOrderUpsertOne {
+	return u.Update(func(s *PaymentOrderUpsert) {
+		s.ClearBuyerTaxID()
+	})
+}
+
+// SetBuyerAddress sets the "buyer_address" field.
+func (u *PaymentOrderUpsertOne) SetBuyerAddress(v string) *PaymentOrderUpsertOne {
+	return u.Update(func(s *PaymentOrderUpsert) {
+		s.SetBuyerAddress(v)
+	})
+}
+
+// UpdateBuyerAddress sets the "buyer_address" field to the value that was provided on create.
+func (u *PaymentOrderUpsertOne) UpdateBuyerAddress() *PaymentOrderUpsertOne {
+	return u.Update(func(s *PaymentOrderUpsert) {
+		s.UpdateBuyerAddress()
+	})
+}
+
+// ClearBuyerAddress clears the value of the "buyer_address" field.
+func (u *PaymentOrderUpsertOne) ClearBuyerAddress() *PaymentOrderUpsertOne {
+	return u.Update(func(s *PaymentOrderUpsert) {
+		s.ClearBuyerAddress()
+	})
+}
+
 // SetAmount sets the "amount" field.
 func (u *PaymentOrderUpsertOne) SetAmount(v float64) *PaymentOrderUpsertOne {
 	return u.Update(func(s *PaymentOrderUpsert) {
@@ -2580,6 +2761,69 @@ func (u *PaymentOrderUpsertBulk) ClearUserNotes() *PaymentOrderUpsertBulk {
 	})
 }
 
+// SetBuyerName sets the "buyer_name" field.
+func (u *PaymentOrderUpsertBulk) SetBuyerName(v string) *PaymentOrderUpsertBulk {
+	return u.Update(func(s *PaymentOrderUpsert) {
+		s.SetBuyerName(v)
+	})
+}
+
+// UpdateBuyerName sets the "buyer_name" field to the value that was provided on create.
+func (u *PaymentOrderUpsertBulk) UpdateBuyerName() *PaymentOrderUpsertBulk {
+	return u.Update(func(s *PaymentOrderUpsert) {
+		s.UpdateBuyerName()
+	})
+}
+
+// ClearBuyerName clears the value of the "buyer_name" field.
+func (u *PaymentOrderUpsertBulk) ClearBuyerName() *PaymentOrderUpsertBulk {
+	return u.Update(func(s *PaymentOrderUpsert) {
+		s.ClearBuyerName()
+	})
+}
+
+// SetBuyerTaxID sets the "buyer_tax_id" field.
+func (u *PaymentOrderUpsertBulk) SetBuyerTaxID(v string) *PaymentOrderUpsertBulk {
+	return u.Update(func(s *PaymentOrderUpsert) {
+		s.SetBuyerTaxID(v)
+	})
+}
+
+// UpdateBuyerTaxID sets the "buyer_tax_id" field to the value that was provided on create.
+func (u *PaymentOrderUpsertBulk) UpdateBuyerTaxID() *PaymentOrderUpsertBulk {
+	return u.Update(func(s *PaymentOrderUpsert) {
+		s.UpdateBuyerTaxID()
+	})
+}
+
+// ClearBuyerTaxID clears the value of the "buyer_tax_id" field.
+func (u *PaymentOrderUpsertBulk) ClearBuyerTaxID() *PaymentOrderUpsertBulk {
+	return u.Update(func(s *PaymentOrderUpsert) {
+		s.ClearBuyerTaxID()
+	})
+}
+
+// SetBuyerAddress sets the "buyer_address" field.
+func (u *PaymentOrderUpsertBulk) SetBuyerAddress(v string) *PaymentOrderUpsertBulk {
+	return u.Update(func(s *PaymentOrderUpsert) {
+		s.SetBuyerAddress(v)
+	})
+}
+
+// UpdateBuyerAddress sets the "buyer_address" field to the value that was provided on create.
+func (u *PaymentOrderUpsertBulk) UpdateBuyerAddress() *PaymentOrderUpsertBulk {
+	return u.Update(func(s *PaymentOrderUpsert) {
+		s.UpdateBuyerAddress()
+	})
+}
+
+// ClearBuyerAddress clears the value of the "buyer_address" field.
+func (u *PaymentOrderUpsertBulk) ClearBuyerAddress() *PaymentOrderUpsertBulk {
+	return u.Update(func(s *PaymentOrderUpsert) {
+		s.ClearBuyerAddress()
+	})
+}
+
 // SetAmount sets the "amount" field.
 func (u *PaymentOrderUpsertBulk) SetAmount(v float64) *PaymentOrderUpsertBulk {
 	return u.Update(func(s *PaymentOrderUpsert) {
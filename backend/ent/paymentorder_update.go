@@ -91,6 +91,66 @@ func (_u *PaymentOrderUpdate) ClearUserNotes() *PaymentOrderUpdate {
 	return _u
 }
 
+// SetBuyerName sets the "buyer_name" field.
+func (_u *PaymentOrderUpdate) SetBuyerName(v string) *PaymentOrderUpdate {
+	_u.mutation.SetBuyerName(v)
+	return _u
+}
+
+// SetNillableBuyerName sets the "buyer_name" field if the given value is not nil.
+func (_u *PaymentOrderUpdate) SetNillableBuyerName(v *string) *PaymentOrderUpdate {
+	if v != nil {
+		_u.SetBuyerName(*v)
+	}
+	return _u
+}
+
+// ClearBuyerName clears the value of the "buyer_name" field.
+func (_u *PaymentOrderUpdate) ClearBuyerName() *PaymentOrderUpdate {
+	_u.mutation.ClearBuyerName()
+	return _u
+}
+
+// SetBuyerTaxID sets the "buyer_tax_id" field.
+func (_u *PaymentOrderUpdate) SetBuyerTaxID(v string) *PaymentOrderUpdate {
+	_u.mutation.SetBuyerTaxID(v)
+	return _u
+}
+
+// SetNillableBuyerTaxID sets the "buyer_tax_id" field if the given value is not nil.
+func (_u *PaymentOrderUpdate) SetNillableBuyerTaxID(v *string) *PaymentOrderUpdate {
+	if v != nil {
+		_u.SetBuyerTaxID(*v)
+	}
+	return _u
+}
+
+// ClearBuyerTaxID clears the value of the "buyer_tax_id" field.
+func (_u *PaymentOrderUpdate) ClearBuyerTaxID() *PaymentOrderUpdate {
+	_u.mutation.ClearBuyerTaxID()
+	return _u
+}
+
+// SetBuyerAddress sets the "buyer_address" field.
+func (_u *PaymentOrderUpdate) SetBuyerAddress(v string) *PaymentOrderUpdate {
+	_u.mutation.SetBuyerAddress(v)
+	return _u
+}
+
+// SetNillableBuyerAddress sets the "buyer_address" field if the given value is not nil.
+func (_u *PaymentOrderUpdate) SetNillableBuyerAddress(v *string) *PaymentOrderUpdate {
+	if v != nil {
+		_u.SetBuyerAddress(*v)
+	}
+	return _u
+}
+
+// ClearBuyerAddress clears the value of the "buyer_address" field.
+func (_u *PaymentOrderUpdate) ClearBuyerAddress() *PaymentOrderUpdate {
+	_u.mutation.ClearBuyerAddress()
+	return _u
+}
+
 // SetAmount sets the "amount" field.
 func (_u *PaymentOrderUpdate) SetAmount(v float64) *PaymentOrderUpdate {
 	_u.mutation.ResetAmount()
@@ -778,6 +838,16 @@ func (_u *PaymentOrderUpdate) check() error {
 			return &ValidationError{Name: "user_name", err: fmt.Errorf(`ent: validator failed for field "PaymentOrder.user_name": %w`, err)}
 		}
 	}
+	if v, ok := _u.mutation.BuyerName(); ok {
+		if err := paymentorder.BuyerNameValidator(v); err != nil {
+			return &ValidationError{Name: "buyer_name", err: fmt.Errorf(`ent: validator failed for field "PaymentOrder.buyer_name": %w`, err)}
+		}
+	}
+	if v, ok := _u.mutation.BuyerTaxID(); ok {
+		if err := paymentorder.BuyerTaxIDValidator(v); err != nil {
+			return &ValidationError{Name: "buyer_tax_id", err: fmt.Errorf(`ent: validator failed for field "PaymentOrder.buyer_tax_id": %w`, err)}
+		}
+	}
 	if v, ok := _u.mutation.RechargeCode(); ok {
 		if err := paymentorder.RechargeCodeValidator(v); err != nil {
 			return &ValidationError{Name: "recharge_code", err: fmt.Errorf(`ent: validator failed for field "PaymentOrder.recharge_code": %w`, err)}
@@ -863,6 +933,24 @@ func (_u *PaymentOrderUpdate) sqlSave(ctx context.Context) (_node int, err error
 	if _u.mutation.UserNotesCleared() {
 		_spec.ClearField(paymentorder.FieldUserNotes, field.TypeString)
 	}
+	if value, ok := _u.mutation.BuyerName(); ok {
+		_spec.SetField(paymentorder.FieldBuyerName, field.TypeString, value)
+	}
+	if _u.mutation.BuyerNameCleared() {
+		_spec.ClearField(paymentorder.FieldBuyerName, field.TypeString)
+	}
+	if value, ok := _u.mutation.BuyerTaxID(); ok {
+		_spec.SetField(paymentorder.FieldBuyerTaxID, field.TypeString, value)
+	}
+	if _u.mutation.BuyerTaxIDCleared() {
+		_spec.ClearField(paymentorder.FieldBuyerTaxID, field.TypeString)
+	}
+	if value, ok := _u.mutation.BuyerAddress(); ok {
+		_spec.SetField(paymentorder.FieldBuyerAddress, field.TypeString, value)
+	}
+	if _u.mutation.BuyerAddressCleared() {
+		_spec.ClearField(paymentorder.FieldBuyerAddress, field.TypeString)
+	}
 	if value, ok := _u.mutation.Amount(); ok {
 		_spec.SetField(paymentorder.FieldAmount, field.TypeFloat64, value)
 	}
@@ -1154,6 +1242,66 @@ func (_u *PaymentOrderUpdateOne) ClearUserNotes() *PaymentOrderUpdateOne {
 	return _u
 }
 
+// SetBuyerName sets the "buyer_name" field.
+func (_u *PaymentOrderUpdateOne) SetBuyerName(v string) *PaymentOrderUpdateOne {
+	_u.mutation.SetBuyerName(v)
+	return _u
+}
+
+// SetNillableBuyerName sets the "buyer_name" field if the given value is not nil.
+func (_u *PaymentOrderUpdateOne) SetNillableBuyerName(v *string) *PaymentOrderUpdateOne {
+	if v != nil {
+		_u.SetBuyerName(*v)
+	}
+	return _u
+}
+
+// ClearBuyerName clears the value of the "buyer_name" field.
+func (_u *PaymentOrderUpdateOne) ClearBuyerName() *PaymentOrderUpdateOne {
+	_u.mutation.ClearBuyerName()
+	return _u
+}
+
+// SetBuyerTaxID sets the "buyer_tax_id" field.
+func (_u *PaymentOrderUpdateOne) SetBuyerTaxID(v string) *PaymentOrderUpdateOne {
+	_u.mutation.SetBuyerTaxID(v)
+	return _u
+}
+
+// SetNillableBuyerTaxID sets the "buyer_tax_id" field if the given value is not nil.
+func (_u *PaymentOrderUpdateOne) SetNillableBuyerTaxID(v *string) *PaymentOrderUpdateOne {
+	if v != nil {
+		_u.SetBuyerTaxID(*v)
+	}
+	return _u
+}
+
+// ClearBuyerTaxID clears the value of the "buyer_tax_id" field.
+func (_u *PaymentOrderUpdateOne) ClearBuyerTaxID() *PaymentOrderUpdateOne {
+	_u.mutation.ClearBuyerTaxID()
+	return _u
+}
+
+// SetBuyerAddress sets the "buyer_address" field.
+func (_u *PaymentOrderUpdateOne) SetBuyerAddress(v string) *PaymentOrderUpdateOne {
+	_u.mutation.SetBuyerAddress(v)
+	return _u
+}
+
+// SetNillableBuyerAddress sets the "buyer_address" field if the given value is not nil.
+func (_u *PaymentOrderUpdateOne) SetNillableBuyerAddress(v *string) *PaymentOrderUpdateOne {
+	if v != nil {
+		_u.SetBuyerAddress(*v)
+	}
+	return _u
+}
+
+// ClearBuyerAddress clears the value of the "buyer_address" field.
+func (_u *PaymentOrderUpdateOne) ClearBuyerAddress() *PaymentOrderUpdateOne {
+	_u.mutation.ClearBuyerAddress()
+	return _u
+}
+
 // SetAmount sets the "amount" field.
 func (_u *PaymentOrderUpdateOne) SetAmount(v float64) *PaymentOrderUpdateOne {
 	_u.mutation.ResetAmount()
@@ -1854,6 +2002,16 @@ func (_u *PaymentOrderUpdateOne) check() error {
 			return &ValidationError{Name: "user_name", err: fmt.Errorf(`ent: validator failed for field "PaymentOrder.user_name": %w`, err)}
 		}
 	}
+	if v, ok := _u.mutation.BuyerName(); ok {
+		if err := paymentorder.BuyerNameValidator(v); err != nil {
+			return &ValidationError{Name: "buyer_name", err: fmt.Errorf(`ent: validator failed for field "PaymentOrder.buyer_name": %w`, err)}
+		}
+	}
+	if v, ok := _u.mutation.BuyerTaxID(); ok {
+		if err := paymentorder.BuyerTaxIDValidator(v); err != nil {
+			return &ValidationError{Name: "buyer_tax_id", err: fmt.Errorf(`ent: validator failed for field "PaymentOrder.buyer_tax_id": %w`, err)}
+		}
+	}
 	if v, ok := _u.mutation.RechargeCode(); ok {
 		if err := paymentorder.RechargeCodeValidator(v); err != nil {
 			return &ValidationError{Name: "recharge_code", err: fmt.Errorf(`ent: validator failed for field "PaymentOrder.recharge_code": %w`, err)}
@@ -1956,6 +2114,24 @@ func (_u *PaymentOrderUpdateOne) sqlSave(ctx context.Context) (_node *PaymentOrd
 	if _u.mutation.UserNotesCleared() {
 		_spec.ClearField(paymentorder.FieldUserNotes, field.TypeString)
 	}
+	if value, ok := _u.mutation.BuyerName(); ok {
+		_spec.SetField(paymentorder.FieldBuyerName, field.TypeString, value)
+	}
+	if _u.mutation.BuyerNameCleared() {
+		_spec.ClearField(paymentorder.FieldBuyerName, field.TypeString)
+	}
+	if value, ok := _u.mutation.BuyerTaxID(); ok {
+		_spec.SetField(paymentorder.FieldBuyerTaxID, field.TypeString, value)
+	}
+	if _u.mutation.BuyerTaxIDCleared() {
+		_spec.ClearField(paymentorder.FieldBuyerTaxID, field.TypeString)
+	}
+	if value, ok := _u.mutation.BuyerAddress(); ok {
+		_spec.SetField(paymentorder.FieldBuyerAddress, field.TypeString, value)
+	}
+	if _u.mutation.BuyerAddressCleared() {
+		_spec.ClearField(paymentorder.FieldBuyerAddress, field.TypeString)
+	}
 	if value, ok := _u.mutation.Amount(); ok {
 		_spec.SetField(paymentorder.FieldAmount, field.TypeFloat64, value)
 	}
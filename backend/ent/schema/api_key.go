@@ -57,6 +57,12 @@ func (APIKey) Fields() []ent.Field {
 		field.JSON("ip_blacklist", []string{}).
 			Optional().
 			Comment("Blocked IPs/CIDRs"),
+		field.JSON("model_allowlist", []string{}).
+			Optional().
+			Comment("Allowed model patterns (glob or re:<regex>), e.g. [\"claude-sonnet-*\", \"gpt-5-mini\"]"),
+		field.JSON("model_denylist", []string{}).
+			Optional().
+			Comment("Blocked model patterns (glob or re:<regex>); deny wins over allow"),
 
 		// ========== Quota fields ==========
 		// Quota limit in USD (0 = unlimited)
@@ -115,6 +121,15 @@ func (APIKey) Fields() []ent.Field {
 			Optional().
 			Nillable().
 			Comment("Start time of the current 7d rate limit window"),
+
+		// ========== Request throttling fields ==========
+		// 单 Key 限流，与用户/分组级限流同时生效（0 = 不限制）
+		field.Int("rpm_limit").
+			Default(0).
+			Comment("Requests per minute for this API key (0 = unlimited)"),
+		field.Int("max_concurrency").
+			Default(0).
+			Comment("Max in-flight requests for this API key (0 = unlimited)"),
 	}
 }
 
@@ -63,6 +63,11 @@ func (CompositeModelRoute) Fields() []ent.Field {
 			Optional().
 			Nillable().
 			SchemaType(map[string]string{dialect.Postgres: "text"}),
+		// 流量拆分实验（migration 238）：非空时按权重/粘连在多个目标间分流。
+		field.JSON("split", &domain.CompositeRouteSplit{}).
+			Optional().
+			SchemaType(map[string]string{dialect.Postgres: "jsonb"}).
+			Comment("Weighted traffic split across arms; null means the single target above."),
 	}
 }
 
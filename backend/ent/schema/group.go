@@ -280,6 +280,41 @@ func (Group) Fields() []ent.Field {
 			SchemaType(map[string]string{dialect.Postgres: "decimal(10,4)"}).
 			Default(0).
 			Comment("安全缓冲，小数；与 margin 相加后从下游倍率中扣除，默认 0"),
+
+		// 精确匹配响应缓存（migration 225）：请求体归一化后完全相同的确定性请求直接回放缓存响应。
+		field.Bool("response_cache_enabled").
+			Default(false).
+			Comment("是否对该分组的确定性请求启用精确匹配响应缓存"),
+		field.Int("response_cache_ttl_seconds").
+			Default(0).
+			Comment("响应缓存有效期（秒），0 表示使用全局默认值"),
+		field.Float("response_cache_hit_multiplier").
+			SchemaType(map[string]string{dialect.Postgres: "decimal(10,4)"}).
+			Default(0.1).
+			Comment("缓存命中计费倍率，按原价乘以该值计费；0 表示命中免费"),
+
+		// 月度消费阶梯计费（migration 232）：按用户在分组内当月累计消费选档。
+		field.JSON("volume_tiers", []domain.VolumeTier{}).
+			Default([]domain.VolumeTier{}).
+			SchemaType(map[string]string{dialect.Postgres: "jsonb"}).
+			Comment("月度累计消费阶梯 [{up_to_usd, multiplier}]，最后一档 up_to_usd 为空；空数组表示不启用"),
+
+		// Gemini 原生协议接入（migration 236）：anthropic/openai 分组经协议转换服务 /v1beta generateContent。
+		field.Bool("allow_gemini_native").
+			Default(false).
+			Comment("是否允许 Anthropic/OpenAI 分组通过协议转换接入 Gemini 原生 generateContent 请求"),
+
+		// 非流式请求对冲（migration 237）：首个账号超过分位延迟未响应时向第二个账号并发。
+		field.JSON("hedge_policy", domain.GroupHedgePolicy{}).
+			Default(domain.GroupHedgePolicy{}).
+			SchemaType(map[string]string{dialect.Postgres: "jsonb"}).
+			Comment("OpenAI 非流式对冲请求策略 {enabled, percentile, min_delay_ms, max_delay_ms}"),
+
+		// 流量镜像（migration 239）：抽样异步重放到候选分组/账号，只记录对比结果，不计费。
+		field.JSON("mirror_policy", domain.GroupMirrorPolicy{}).
+			Default(domain.GroupMirrorPolicy{}).
+			SchemaType(map[string]string{dialect.Postgres: "jsonb"}).
+			Comment("流量镜像策略 {enabled, sample_rate, target_group_id, target_account_id, compare_output}"),
 	}
 }
 
@@ -42,6 +42,20 @@ func (PaymentOrder) Fields() []ent.Field {
 			Nillable().
 			SchemaType(map[string]string{dialect.Postgres: "text"}),
 
+		// 发票抬头（下单时可选填写，开票时快照到发票）
+		field.String("buyer_name").
+			Optional().
+			Nillable().
+			MaxLen(255),
+		field.String("buyer_tax_id").
+			Optional().
+			Nillable().
+			MaxLen(64),
+		field.String("buyer_address").
+			Optional().
+			Nillable().
+			SchemaType(map[string]string{dialect.Postgres: "text"}),
+
 		// 金额信息
 		field.Float("amount").
 			SchemaType(map[string]string{dialect.Postgres: "decimal(20,2)"}),
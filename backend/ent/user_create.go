@@ -354,6 +354,20 @@ func (_c *UserCreate) SetNillableRpmLimit(v *int) *UserCreate {
 	return _c
 }
 
+// SetCreditLimit sets the "credit_limit" field.
+func (_c *UserCreate) SetCreditLimit(v float64) *UserCreate {
+	_c.mutation.SetCreditLimit(v)
+	return _c
+}
+
+// SetNillableCreditLimit sets the "credit_limit" field if the given value is not nil.
+func (_c *UserCreate) SetNillableCreditLimit(v *float64) *UserCreate {
+	if v != nil {
+		_c.SetCreditLimit(*v)
+	}
+	return _c
+}
+
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by IDs.
 func (_c *UserCreate) AddAPIKeyIDs(ids ...int64) *UserCreate {
 	_c.mutation.AddAPIKeyIDs(ids...)
@@ -656,6 +670,10 @@ func (_c *UserCreate) defaults() error {
 		v := user.DefaultRpmLimit
 		_c.mutation.SetRpmLimit(v)
 	}
+	if _, ok := _c.mutation.CreditLimit(); !ok {
+		v := user.DefaultCreditLimit
+		_c.mutation.SetCreditLimit(v)
+	}
 	return nil
 }
 
@@ -745,6 +763,9 @@ func (_c *UserCreate) check() error {
 	if _, ok := _c.mutation.RpmLimit(); !ok {
 		return &ValidationError{Name: "rpm_limit", err: errors.New(`ent: missing required field "User.rpm_limit"`)}
 	}
+	if _, ok := _c.mutation.CreditLimit(); !ok {
+		return &ValidationError{Name: "credit_limit", err: errors.New(`ent: missing required field "User.credit_limit"`)}
+	}
 	return nil
 }
 
@@ -868,6 +889,10 @@ func (_c *UserCreate) createSpec() (*User, *sqlgraph.CreateSpec) {
 		_spec.SetField(user.FieldRpmLimit, field.TypeInt, value)
 		_node.RpmLimit = value
 	}
+	if value, ok := _c.mutation.CreditLimit(); ok {
+		_spec.SetField(user.FieldCreditLimit, field.TypeFloat64, value)
+		_node.CreditLimit = value
+	}
 	if nodes := _c.mutation.APIKeysIDs(); len(nodes) > 0 {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
@@ -1480,6 +1505,24 @@ func (u *UserUpsert) AddRpmLimit(v int) *UserUpsert {
 	return u
 }
 
+// SetCreditLimit sets the "credit_limit" field.
+func (u *UserUpsert) SetCreditLimit(v float64) *UserUpsert {
+	u.Set(user.FieldCreditLimit, v)
+	return u
+}
+
+// UpdateCreditLimit sets the "credit_limit" field to the value that was provided on create.
+func (u *UserUpsert) UpdateCreditLimit() *UserUpsert {
+	u.SetExcluded(user.FieldCreditLimit)
+	return u
+}
+
+// AddCreditLimit adds v to the "credit_limit" field.
+func (u *UserUpsert) AddCreditLimit(v float64) *UserUpsert {
+	u.Add(user.FieldCreditLimit, v)
+	return u
+}
+
 // UpdateNewValues updates the mutable fields using the new values that were set on create.
 // Using this option is equivalent to using:
 //
@@ -1931,6 +1974,27 @@ func (u *UserUpsertOne) UpdateRpmLimit() *UserUpsertOne {
 	})
 }
 
+// SetCreditLimit sets the "credit_limit" field.
+func (u *UserUpsertOne) SetCreditLimit(v float64) *UserUpsertOne {
+	return u.Update(func(s *UserUpsert) {
+		s.SetCreditLimit(v)
+	})
+}
+
+// AddCreditLimit adds v to the "credit_limit" field.
+func (u *UserUpsertOne) AddCreditLimit(v float64) *UserUpsertOne {
+	return u.Update(func(s *UserUpsert) {
+		s.AddCreditLimit(v)
+	})
+}
+
+// UpdateCreditLimit sets the "credit_limit" field to the value that was provided on create.
+func (u *UserUpsertOne) UpdateCreditLimit() *UserUpsertOne {
+	return u.Update(func(s *UserUpsert) {
+		s.UpdateCreditLimit()
+	})
+}
+
 // Exec executes the query.
 func (u *UserUpsertOne) Exec(ctx context.Context) error {
 	if len(u.create.conflict) == 0 {
@@ -2548,6 +2612,27 @@ func (u *UserUpsertBulk) UpdateRpmLimit() *UserUpsertBulk {
 	})
 }
 
+// SetCreditLimit sets the "credit_limit" field.
+func (u *UserUpsertBulk) SetCreditLimit(v float64) *UserUpsertBulk {
+	return u.Update(func(s *UserUpsert) {
+		s.SetCreditLimit(v)
+	})
+}
+
+// AddCreditLimit adds v to the "credit_limit" field.
+func (u *UserUpsertBulk) AddCreditLimit(v float64) *UserUpsertBulk {
+	return u.Update(func(s *UserUpsert) {
+		s.AddCreditLimit(v)
+	})
+}
+
+// UpdateCreditLimit sets the "credit_limit" field to the value that was provided on create.
+func (u *UserUpsertBulk) UpdateCreditLimit() *UserUpsertBulk {
+	return u.Update(func(s *UserUpsert) {
+		s.UpdateCreditLimit()
+	})
+}
+
 // Exec executes the query.
 func (u *UserUpsertBulk) Exec(ctx context.Context) error {
 	if u.create.err != nil {
@@ -71,12 +71,15 @@ type Config struct {
 	Database                DatabaseConfig                `mapstructure:"database"`
 	Redis                   RedisConfig                   `mapstructure:"redis"`
 	Ops                     OpsConfig                     `mapstructure:"ops"`
+	Metrics                 MetricsConfig                 `mapstructure:"metrics"`
+	Tracing                 TracingConfig                 `mapstructure:"tracing"`
 	JWT                     JWTConfig                     `mapstructure:"jwt"`
 	Totp                    TotpConfig                    `mapstructure:"totp"`
 	WebAuthn                WebAuthnConfig                `mapstructure:"webauthn"`
 	LinuxDo                 LinuxDoConnectConfig          `mapstructure:"linuxdo_connect"`
 	WeChat                  WeChatConnectConfig           `mapstructure:"wechat_connect"`
 	OIDC                    OIDCConnectConfig             `mapstructure:"oidc_connect"`
+	SAML                    SAMLConnectConfig             `mapstructure:"saml_connect"`
 	DingTalk                DingTalkConnectConfig         `mapstructure:"dingtalk_connect"`
 	GitHubOAuth             EmailOAuthProviderConfig      `mapstructure:"github_oauth"`
 	GoogleOAuth             EmailOAuthProviderConfig      `mapstructure:"google_oauth"`
@@ -98,7 +101,15 @@ type Config struct {
 	Update                  UpdateConfig                  `mapstructure:"update"`
 	Idempotency             IdempotencyConfig             `mapstructure:"idempotency"`
 	BatchImage              BatchImageConfig              `mapstructure:"batch_image"`
+	MessageBatch            MessageBatchConfig            `mapstructure:"message_batch"`
+	OpenAIBatch             OpenAIBatchConfig             `mapstructure:"openai_batch"`
 	ImageStorage            ImageStorageConfig            `mapstructure:"image_storage"`
+	ResponseCache           ResponseCacheConfig           `mapstructure:"response_cache"`
+	BalanceLedger           BalanceLedgerConfig           `mapstructure:"balance_ledger"`
+	CreditLine              CreditLineConfig              `mapstructure:"credit_line"`
+	SpendAnomaly            SpendAnomalyConfig            `mapstructure:"spend_anomaly"`
+	UsageExport             UsageExportConfig             `mapstructure:"usage_export"`
+	PaymentInvoice          PaymentInvoiceConfig          `mapstructure:"payment_invoice"`
 }
 
 type LogConfig struct {
@@ -231,6 +242,138 @@ type BatchImageConfig struct {
 	VertexGCSBaseURL             string `mapstructure:"vertex_gcs_base_url"`
 }
 
+// MessageBatchConfig 配置 Anthropic Message Batches 代理（/v1/messages/batches）。
+// 批次绑定到分组内的 Anthropic API Key 账号：提交时按估算成本冻结余额，
+// 批次结束后由后台轮询拉取结果按批量折扣价结算。
+type MessageBatchConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// DiscountMultiplier 批量折扣系数（相对标准价），Anthropic 官方为 0.5。
+	DiscountMultiplier float64 `mapstructure:"discount_multiplier"`
+	// HoldMultiplier 冻结系数（相对标准价的估算上限），不得低于 DiscountMultiplier。
+	HoldMultiplier         float64 `mapstructure:"hold_multiplier"`
+	MaxRequestsPerBatch    int     `mapstructure:"max_requests_per_batch"`
+	PollIntervalSeconds    int     `mapstructure:"poll_interval_seconds"`
+	PollBatchSize          int     `mapstructure:"poll_batch_size"`
+	UpstreamTimeoutSeconds int     `mapstructure:"upstream_timeout_seconds"`
+}
+
+// OpenAIBatchConfig 配置 OpenAI Files / Batch API 代理（/v1/files、/v1/batches）。
+// 文件上传到分组内的 OpenAI API Key 账号，批次固定在持有输入文件的账号上；
+// 创建批次时按上传时的估算冻结余额，批次结束后从输出文件按批量折扣价结算。
+type OpenAIBatchConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// DiscountMultiplier 批量折扣系数（相对标准价），OpenAI 官方为 0.5。
+	DiscountMultiplier float64 `mapstructure:"discount_multiplier"`
+	// HoldMultiplier 冻结系数（相对标准价的估算上限），不得低于 DiscountMultiplier。
+	HoldMultiplier     float64 `mapstructure:"hold_multiplier"`
+	MaxRequestsPerFile int     `mapstructure:"max_requests_per_file"`
+	MaxFileBytes       int64   `mapstructure:"max_file_bytes"`
+	// DefaultMaxOutputTokens 请求未声明 max_tokens / max_completion_tokens / max_output_tokens 时用于估算的输出上限。
+	DefaultMaxOutputTokens int `mapstructure:"default_max_output_tokens"`
+	PollIntervalSeconds    int `mapstructure:"poll_interval_seconds"`
+	PollBatchSize          int `mapstructure:"poll_batch_size"`
+	UpstreamTimeoutSeconds int `mapstructure:"upstream_timeout_seconds"`
+}
+
+// BalanceLedgerConfig 配置余额账本对账任务。
+// 账本流水由数据库触发器写入，这里只控制定时对账（users.balance 与账本汇总比对）。
+type BalanceLedgerConfig struct {
+	ReconcileEnabled bool `mapstructure:"reconcile_enabled"`
+	// ReconcileSchedule 5 段 cron 表达式（分 时 日 月 周），按 timezone 解释。
+	ReconcileSchedule string `mapstructure:"reconcile_schedule"`
+}
+
+// CreditLineConfig 配置后付费授信的月度账单任务。
+// 授信额度由管理员按用户设置；任务按 schedule 运行，补生成上一自然月账单并处理到期与结清。
+type CreditLineConfig struct {
+	StatementEnabled bool `mapstructure:"statement_enabled"`
+	// StatementSchedule 5 段 cron 表达式（分 时 日 月 周），按 timezone 解释。
+	// 每次运行都会检查结清与逾期，因此建议至少每小时运行一次。
+	StatementSchedule string `mapstructure:"statement_schedule"`
+	// DueDays 账单周期结束后的付款期限（天），逾期后自动挂起该用户的 API Key。
+	DueDays int `mapstructure:"due_days"`
+}
+
+// SpendAnomalyConfig 配置消费异常检测。
+// 每次运行统计最近 window_minutes 内各用户与各 API Key 的消费，与前 baseline_days 天的
+// 小时均值比较；超过 multiplier 倍即发送邮件提醒，可选自动禁用异常的 API Key。
+type SpendAnomalyConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Schedule 5 段 cron 表达式（分 时 日 月 周），按 timezone 解释。
+	Schedule string `mapstructure:"schedule"`
+	// WindowMinutes 当前消费的滚动窗口（分钟），基线按同样长度折算。
+	WindowMinutes int `mapstructure:"window_minutes"`
+	// BaselineDays 基线取窗口之前多少天的平均消费。
+	BaselineDays int `mapstructure:"baseline_days"`
+	// Multiplier 当前窗口消费超过基线的倍数即判定为异常。
+	Multiplier float64 `mapstructure:"multiplier"`
+	// MinSpend 窗口消费低于该金额（USD）时不告警，避免小额波动误报。
+	MinSpend float64 `mapstructure:"min_spend"`
+	// MinHistoryHours 用户/Key 的历史用量不足该时长时不判定（新账号没有可靠基线）。
+	MinHistoryHours int `mapstructure:"min_history_hours"`
+	// CooldownMinutes 同一用户/Key 两次告警的最小间隔。
+	CooldownMinutes int `mapstructure:"cooldown_minutes"`
+	// AutoDisableKeys 为 true 时自动禁用消费异常的 API Key（用户可在确认后自行重新启用）。
+	AutoDisableKeys bool `mapstructure:"auto_disable_keys"`
+}
+
+// PaymentInvoiceConfig 配置支付单据的 PDF 渲染。
+// 内置字体只覆盖拉丁、希腊与西里尔字符；单据含中日韩等其他字符时需要提供后备字体，
+// 否则该单据只能以 HTML 格式下载。
+type PaymentInvoiceConfig struct {
+	// CJKFontPath 后备 TrueType 字体文件路径（.ttf，glyf 轮廓；不支持 .ttc 与 CFF 轮廓的 .otf），
+	// 按需子集嵌入 PDF。留空表示不启用。
+	CJKFontPath string `mapstructure:"cjk_font_path"`
+}
+
+// UsageExportConfig 配置用量明细导出。
+// 导出任务由管理员在后台定义（频率、格式、过滤条件、目标存储），文件写入已配置的备份 S3
+// 或图片对象存储；用户也可导出自己的用量，文件写入 user_storage 并通过预签名链接下载。
+type UsageExportConfig struct {
+	// SchedulerEnabled 为 false 时不自动生成周期导出，手动运行、补跑与用户导出不受影响。
+	SchedulerEnabled bool `mapstructure:"scheduler_enabled"`
+	// Schedule 5 段 cron 表达式（分 时 日 月 周），按 timezone 解释；每次运行为到期的任务补齐已结束周期。
+	Schedule string `mapstructure:"schedule"`
+	// MaxBackfillPeriods 单次补跑最多生成的周期数。
+	MaxBackfillPeriods int `mapstructure:"max_backfill_periods"`
+	// UserStorage 用户自助导出写入的存储：backup 或 image_storage。
+	UserStorage string `mapstructure:"user_storage"`
+	// UserMaxRangeDays 用户单次导出的最大日期跨度（天）。
+	UserMaxRangeDays int `mapstructure:"user_max_range_days"`
+	// UserMaxPending 单个用户同时排队/执行中的导出上限。
+	UserMaxPending int `mapstructure:"user_max_pending"`
+	// DownloadURLExpiryMinutes 下载链接（预签名 URL）有效期。
+	DownloadURLExpiryMinutes int `mapstructure:"download_url_expiry_minutes"`
+}
+
+// ResponseCacheConfig 配置精确匹配响应缓存（/v1/messages、/v1/chat/completions、/v1/responses）。
+// 全局开关打开后仍需分组单独启用；请求体归一化后哈希作为缓存键，
+// 命中时回放原始 JSON/SSE 响应，并按分组的命中倍率计费。
+type ResponseCacheConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Backend 缓存存储后端："redis"（默认）或 "s3"（S3 兼容对象存储，适合大响应）。
+	Backend string `mapstructure:"backend"`
+	// DefaultTTLSeconds 分组未设置 TTL 时使用的默认有效期。
+	DefaultTTLSeconds int `mapstructure:"default_ttl_seconds"`
+	// MaxBodyBytes 可缓存的请求体/响应体字节上限，超过则不缓存。
+	MaxBodyBytes int64 `mapstructure:"max_body_bytes"`
+	// RequireZeroTemperature 为 true 时仅缓存显式 temperature=0 的请求（确定性请求）。
+	RequireZeroTemperature bool                  `mapstructure:"require_zero_temperature"`
+	S3                     ResponseCacheS3Config `mapstructure:"s3"`
+}
+
+// ResponseCacheS3Config 响应缓存的 S3 兼容对象存储配置（backend=s3 时生效）。
+// 过期由对象元数据中的写入时间判断，建议在桶上额外配置生命周期规则清理旧对象。
+type ResponseCacheS3Config struct {
+	Endpoint        string `mapstructure:"endpoint"`
+	Region          string `mapstructure:"region"`
+	Bucket          string `mapstructure:"bucket"`
+	AccessKeyID     string `mapstructure:"access_key_id"`
+	SecretAccessKey string `mapstructure:"secret_access_key"`
+	Prefix          string `mapstructure:"prefix"`
+	ForcePathStyle  bool   `mapstructure:"force_path_style"`
+}
+
 // ImageStorageConfig 配置异步图片任务结果上传的 S3 兼容对象存储。
 // Enabled 同时作为异步图片任务功能的总开关：未启用或未配置完整凭证时，
 // 异步生图接口整体禁用，避免把上游返回的大 base64 结果塞进 Redis。
@@ -343,6 +486,36 @@ type OIDCConnectConfig struct {
 	UserInfoUsernamePath string `mapstructure:"userinfo_username_path"`
 }
 
+// SAMLConnectConfig SAML 2.0 SSO（本系统作为 SP，对接单个 IdP）。
+type SAMLConnectConfig struct {
+	Enabled             bool   `mapstructure:"enabled"`
+	ProviderName        string `mapstructure:"provider_name"` // 显示名: "Okta" / "ADFS" 等
+	SPEntityID          string `mapstructure:"sp_entity_id"`  // SP 实体 ID（即 Audience，需在 IdP 后台登记）
+	ACSURL              string `mapstructure:"acs_url"`       // 断言消费地址：{后端}/api/v1/auth/oauth/saml/acs
+	IdPEntityID         string `mapstructure:"idp_entity_id"`
+	IdPSSOURL           string `mapstructure:"idp_sso_url"`           // IdP 的 HTTP-Redirect SSO 地址
+	IdPCertificate      string `mapstructure:"idp_certificate"`       // IdP 签名证书（PEM 或 base64 DER，可含多张用于轮换）
+	FrontendRedirectURL string `mapstructure:"frontend_redirect_url"` // 前端接收 token 的路由（默认：/auth/saml/callback）
+	AllowIdPInitiated   bool   `mapstructure:"allow_idp_initiated"`   // 是否接受 IdP 主动发起的登录（默认 false）
+	ClockSkewSeconds    int    `mapstructure:"clock_skew_seconds"`    // 默认 120
+	NameIDFormat        string `mapstructure:"name_id_format"`        // 为空时不约束
+
+	// 属性映射：为空时按常见属性名依次尝试。
+	EmailAttribute       string `mapstructure:"email_attribute"`
+	UsernameAttribute    string `mapstructure:"username_attribute"`
+	DisplayNameAttribute string `mapstructure:"display_name_attribute"`
+	GroupsAttribute      string `mapstructure:"groups_attribute"`
+	// TrustEmail 为 true 时视 IdP 下发的邮箱为已验证邮箱（企业 IdP 通常如此）。
+	TrustEmail bool `mapstructure:"trust_email"`
+	// GroupMappings 将 IdP 组映射为本地可用分组（仅追加，不会移除已有分组）。
+	GroupMappings []SAMLGroupMapping `mapstructure:"group_mappings"`
+}
+
+type SAMLGroupMapping struct {
+	IdPGroup string  `mapstructure:"idp_group"`
+	GroupIDs []int64 `mapstructure:"group_ids"`
+}
+
 type DingTalkConnectConfig struct {
 	Enabled             bool   `mapstructure:"enabled"`
 	ClientID            string `mapstructure:"client_id"`
@@ -1503,6 +1676,30 @@ func (r *RedisConfig) Address() string {
 	return fmt.Sprintf("%s:%d", r.Host, r.Port)
 }
 
+// MetricsConfig Prometheus 指标暴露配置
+type MetricsConfig struct {
+	// Enabled 是否在 GET /metrics 暴露 Prometheus 指标
+	Enabled bool `mapstructure:"enabled"`
+	// AuthToken 抓取时需携带的 Bearer Token（启用时必填）
+	AuthToken string `mapstructure:"auth_token"`
+}
+
+// TracingConfig OpenTelemetry 分布式追踪配置（OTLP/gRPC 导出）
+type TracingConfig struct {
+	// Enabled 是否启用追踪导出
+	Enabled bool `mapstructure:"enabled"`
+	// Endpoint OTLP/gRPC collector 地址（host:port）
+	Endpoint string `mapstructure:"endpoint"`
+	// Insecure 是否使用明文 gRPC 连接（本机/同网段 collector）
+	Insecure bool `mapstructure:"insecure"`
+	// Headers 导出请求附带的元数据（如认证 Token）
+	Headers map[string]string `mapstructure:"headers"`
+	// ServiceName 资源属性 service.name，为空时沿用 log.service_name
+	ServiceName string `mapstructure:"service_name"`
+	// SampleRatio 根 span 采样率 [0,1]；客户端携带 traceparent 时沿用其采样标记
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+}
+
 type OpsConfig struct {
 	// Enabled controls whether ops features should run.
 	//
@@ -1785,6 +1982,18 @@ func load(allowMissingJWTSecret bool) (*Config, error) {
 	cfg.OIDC.UserInfoUsernamePath = strings.TrimSpace(cfg.OIDC.UserInfoUsernamePath)
 	cfg.OIDC.UsePKCEExplicit = hasExplicitConfigOrEnv("oidc_connect.use_pkce", "OIDC_CONNECT_USE_PKCE")
 	cfg.OIDC.ValidateIDTokenExplicit = hasExplicitConfigOrEnv("oidc_connect.validate_id_token", "OIDC_CONNECT_VALIDATE_ID_TOKEN")
+	cfg.SAML.ProviderName = strings.TrimSpace(cfg.SAML.ProviderName)
+	cfg.SAML.SPEntityID = strings.TrimSpace(cfg.SAML.SPEntityID)
+	cfg.SAML.ACSURL = strings.TrimSpace(cfg.SAML.ACSURL)
+	cfg.SAML.IdPEntityID = strings.TrimSpace(cfg.SAML.IdPEntityID)
+	cfg.SAML.IdPSSOURL = strings.TrimSpace(cfg.SAML.IdPSSOURL)
+	cfg.SAML.IdPCertificate = strings.TrimSpace(cfg.SAML.IdPCertificate)
+	cfg.SAML.FrontendRedirectURL = strings.TrimSpace(cfg.SAML.FrontendRedirectURL)
+	cfg.SAML.NameIDFormat = strings.TrimSpace(cfg.SAML.NameIDFormat)
+	cfg.SAML.EmailAttribute = strings.TrimSpace(cfg.SAML.EmailAttribute)
+	cfg.SAML.UsernameAttribute = strings.TrimSpace(cfg.SAML.UsernameAttribute)
+	cfg.SAML.DisplayNameAttribute = strings.TrimSpace(cfg.SAML.DisplayNameAttribute)
+	cfg.SAML.GroupsAttribute = strings.TrimSpace(cfg.SAML.GroupsAttribute)
 	cfg.Dashboard.KeyPrefix = strings.TrimSpace(cfg.Dashboard.KeyPrefix)
 	cfg.CORS.AllowedOrigins = normalizeStringSlice(cfg.CORS.AllowedOrigins)
 	cfg.Security.ResponseHeaders.AdditionalAllowed = normalizeStringSlice(cfg.Security.ResponseHeaders.AdditionalAllowed)
@@ -2048,6 +2257,25 @@ func setDefaults() {
 	viper.SetDefault("oidc_connect.userinfo_id_path", "")
 	viper.SetDefault("oidc_connect.userinfo_username_path", "")
 
+	// SAML 2.0 SSO 登录
+	viper.SetDefault("saml_connect.enabled", false)
+	viper.SetDefault("saml_connect.provider_name", "SSO")
+	viper.SetDefault("saml_connect.sp_entity_id", "")
+	viper.SetDefault("saml_connect.acs_url", "")
+	viper.SetDefault("saml_connect.idp_entity_id", "")
+	viper.SetDefault("saml_connect.idp_sso_url", "")
+	viper.SetDefault("saml_connect.idp_certificate", "")
+	viper.SetDefault("saml_connect.frontend_redirect_url", "/auth/saml/callback")
+	viper.SetDefault("saml_connect.allow_idp_initiated", false)
+	viper.SetDefault("saml_connect.clock_skew_seconds", 120)
+	viper.SetDefault("saml_connect.name_id_format", "")
+	viper.SetDefault("saml_connect.email_attribute", "")
+	viper.SetDefault("saml_connect.username_attribute", "")
+	viper.SetDefault("saml_connect.display_name_attribute", "")
+	viper.SetDefault("saml_connect.groups_attribute", "")
+	viper.SetDefault("saml_connect.trust_email", true)
+	viper.SetDefault("saml_connect.group_mappings", []SAMLGroupMapping{})
+
 	// DingTalk Connect OAuth 登录
 	viper.SetDefault("dingtalk_connect.enabled", false)
 	viper.SetDefault("dingtalk_connect.authorize_url", "https://login.dingtalk.com/oauth2/auth")
@@ -2136,6 +2364,23 @@ func setDefaults() {
 	viper.SetDefault("batch_image.vertex_batch_prediction_base_url", "")
 	viper.SetDefault("batch_image.vertex_gcs_base_url", "")
 
+	viper.SetDefault("message_batch.enabled", false)
+	viper.SetDefault("message_batch.discount_multiplier", 0.5)
+	viper.SetDefault("message_batch.hold_multiplier", 1.0)
+	viper.SetDefault("message_batch.max_requests_per_batch", 10000)
+	viper.SetDefault("message_batch.poll_interval_seconds", 60)
+	viper.SetDefault("message_batch.poll_batch_size", 50)
+	viper.SetDefault("message_batch.upstream_timeout_seconds", 300)
+	viper.SetDefault("openai_batch.enabled", false)
+	viper.SetDefault("openai_batch.discount_multiplier", 0.5)
+	viper.SetDefault("openai_batch.hold_multiplier", 1.0)
+	viper.SetDefault("openai_batch.max_requests_per_file", 50000)
+	viper.SetDefault("openai_batch.max_file_bytes", int64(200*1024*1024))
+	viper.SetDefault("openai_batch.default_max_output_tokens", 4096)
+	viper.SetDefault("openai_batch.poll_interval_seconds", 60)
+	viper.SetDefault("openai_batch.poll_batch_size", 50)
+	viper.SetDefault("openai_batch.upstream_timeout_seconds", 300)
+
 	// Image storage (async image task result offload to S3-compatible object storage)
 	viper.SetDefault("image_storage.enabled", false)
 	viper.SetDefault("image_storage.region", "auto")
@@ -2153,6 +2398,63 @@ func setDefaults() {
 	viper.SetDefault("image_storage.secret_access_key", "")
 	viper.SetDefault("image_storage.public_base_url", "")
 
+	// Response cache
+	viper.SetDefault("response_cache.enabled", false)
+	viper.SetDefault("response_cache.backend", "redis")
+	viper.SetDefault("response_cache.default_ttl_seconds", 3600)
+	viper.SetDefault("response_cache.max_body_bytes", int64(4*1024*1024))
+	viper.SetDefault("response_cache.require_zero_temperature", true)
+	viper.SetDefault("response_cache.s3.endpoint", "")
+	viper.SetDefault("response_cache.s3.region", "auto")
+	viper.SetDefault("response_cache.s3.bucket", "")
+	viper.SetDefault("response_cache.s3.access_key_id", "")
+	viper.SetDefault("response_cache.s3.secret_access_key", "")
+	viper.SetDefault("response_cache.s3.prefix", "response-cache/")
+	viper.SetDefault("response_cache.s3.force_path_style", false)
+
+	// Balance ledger reconciliation
+	viper.SetDefault("balance_ledger.reconcile_enabled", true)
+	viper.SetDefault("balance_ledger.reconcile_schedule", "30 3 * * *")
+
+	// Postpaid credit line statements
+	viper.SetDefault("credit_line.statement_enabled", true)
+	viper.SetDefault("credit_line.statement_schedule", "15 * * * *")
+	viper.SetDefault("credit_line.due_days", 15)
+
+	// Spend anomaly detection
+	viper.SetDefault("spend_anomaly.enabled", true)
+	viper.SetDefault("spend_anomaly.schedule", "*/10 * * * *")
+	viper.SetDefault("spend_anomaly.window_minutes", 60)
+	viper.SetDefault("spend_anomaly.baseline_days", 7)
+	viper.SetDefault("spend_anomaly.multiplier", 5.0)
+	viper.SetDefault("spend_anomaly.min_spend", 1.0)
+	viper.SetDefault("spend_anomaly.min_history_hours", 24)
+	viper.SetDefault("spend_anomaly.cooldown_minutes", 360)
+	viper.SetDefault("spend_anomaly.auto_disable_keys", false)
+
+	// Usage export
+	viper.SetDefault("usage_export.scheduler_enabled", true)
+	viper.SetDefault("usage_export.schedule", "20 * * * *")
+	viper.SetDefault("usage_export.max_backfill_periods", 400)
+	viper.SetDefault("usage_export.user_storage", "backup")
+	viper.SetDefault("usage_export.user_max_range_days", 93)
+	viper.SetDefault("usage_export.user_max_pending", 3)
+	viper.SetDefault("usage_export.download_url_expiry_minutes", 60)
+
+	// Payment invoice
+	viper.SetDefault("payment_invoice.cjk_font_path", "")
+
+	// Metrics
+	viper.SetDefault("metrics.enabled", false)
+	viper.SetDefault("metrics.auth_token", "")
+
+	// Tracing
+	viper.SetDefault("tracing.enabled", false)
+	viper.SetDefault("tracing.endpoint", "localhost:4317")
+	viper.SetDefault("tracing.insecure", true)
+	viper.SetDefault("tracing.service_name", "")
+	viper.SetDefault("tracing.sample_ratio", 1.0)
+
 	// Ops (vNext)
 	viper.SetDefault("ops.enabled", true)
 	viper.SetDefault("ops.use_preaggregated_tables", true)
@@ -2911,6 +3213,43 @@ func (c *Config) Validate() error {
 		warnIfInsecureURL("oidc_connect.redirect_url", c.OIDC.RedirectURL)
 		warnIfInsecureURL("oidc_connect.frontend_redirect_url", c.OIDC.FrontendRedirectURL)
 	}
+	if c.SAML.Enabled {
+		if c.SAML.SPEntityID == "" {
+			return fmt.Errorf("saml_connect.sp_entity_id is required when saml_connect.enabled=true")
+		}
+		if c.SAML.IdPEntityID == "" {
+			return fmt.Errorf("saml_connect.idp_entity_id is required when saml_connect.enabled=true")
+		}
+		if c.SAML.IdPCertificate == "" {
+			return fmt.Errorf("saml_connect.idp_certificate is required when saml_connect.enabled=true")
+		}
+		if err := ValidateAbsoluteHTTPURL(c.SAML.ACSURL); err != nil {
+			return fmt.Errorf("saml_connect.acs_url invalid: %w", err)
+		}
+		if err := ValidateAbsoluteHTTPURL(c.SAML.IdPSSOURL); err != nil {
+			return fmt.Errorf("saml_connect.idp_sso_url invalid: %w", err)
+		}
+		if err := ValidateFrontendRedirectURL(c.SAML.FrontendRedirectURL); err != nil {
+			return fmt.Errorf("saml_connect.frontend_redirect_url invalid: %w", err)
+		}
+		if c.SAML.ClockSkewSeconds < 0 || c.SAML.ClockSkewSeconds > 600 {
+			return fmt.Errorf("saml_connect.clock_skew_seconds must be between 0 and 600")
+		}
+		for i, m := range c.SAML.GroupMappings {
+			if strings.TrimSpace(m.IdPGroup) == "" {
+				return fmt.Errorf("saml_connect.group_mappings[%d].idp_group is required", i)
+			}
+			for _, id := range m.GroupIDs {
+				if id <= 0 {
+					return fmt.Errorf("saml_connect.group_mappings[%d].group_ids must be positive", i)
+				}
+			}
+		}
+
+		warnIfInsecureURL("saml_connect.acs_url", c.SAML.ACSURL)
+		warnIfInsecureURL("saml_connect.idp_sso_url", c.SAML.IdPSSOURL)
+		warnIfInsecureURL("saml_connect.frontend_redirect_url", c.SAML.FrontendRedirectURL)
+	}
 	if c.Billing.CircuitBreaker.Enabled {
 		if c.Billing.CircuitBreaker.FailureThreshold <= 0 {
 			return fmt.Errorf("billing.circuit_breaker.failure_threshold must be positive")
@@ -3013,6 +3352,109 @@ func (c *Config) Validate() error {
 			return fmt.Errorf("batch_image.vertex_output_retention_hours must be positive")
 		}
 	}
+	if c.MessageBatch.Enabled {
+		if c.MessageBatch.DiscountMultiplier < 0 {
+			return fmt.Errorf("message_batch.discount_multiplier must be non-negative")
+		}
+		if c.MessageBatch.HoldMultiplier < c.MessageBatch.DiscountMultiplier {
+			return fmt.Errorf("message_batch.hold_multiplier must be >= message_batch.discount_multiplier")
+		}
+		if c.MessageBatch.MaxRequestsPerBatch <= 0 || c.MessageBatch.MaxRequestsPerBatch > 100000 {
+			return fmt.Errorf("message_batch.max_requests_per_batch must be between 1 and 100000")
+		}
+		if c.MessageBatch.PollIntervalSeconds <= 0 {
+			return fmt.Errorf("message_batch.poll_interval_seconds must be positive")
+		}
+		if c.MessageBatch.PollBatchSize <= 0 {
+			return fmt.Errorf("message_batch.poll_batch_size must be positive")
+		}
+		if c.MessageBatch.UpstreamTimeoutSeconds <= 0 {
+			return fmt.Errorf("message_batch.upstream_timeout_seconds must be positive")
+		}
+	}
+	if c.OpenAIBatch.Enabled {
+		if c.OpenAIBatch.DiscountMultiplier < 0 {
+			return fmt.Errorf("openai_batch.discount_multiplier must be non-negative")
+		}
+		if c.OpenAIBatch.HoldMultiplier < c.OpenAIBatch.DiscountMultiplier {
+			return fmt.Errorf("openai_batch.hold_multiplier must be >= openai_batch.discount_multiplier")
+		}
+		if c.OpenAIBatch.MaxRequestsPerFile <= 0 || c.OpenAIBatch.MaxRequestsPerFile > 50000 {
+			return fmt.Errorf("openai_batch.max_requests_per_file must be between 1 and 50000")
+		}
+		if c.OpenAIBatch.MaxFileBytes <= 0 {
+			return fmt.Errorf("openai_batch.max_file_bytes must be positive")
+		}
+		if c.OpenAIBatch.DefaultMaxOutputTokens <= 0 {
+			return fmt.Errorf("openai_batch.default_max_output_tokens must be positive")
+		}
+		if c.OpenAIBatch.PollIntervalSeconds <= 0 {
+			return fmt.Errorf("openai_batch.poll_interval_seconds must be positive")
+		}
+		if c.OpenAIBatch.PollBatchSize <= 0 {
+			return fmt.Errorf("openai_batch.poll_batch_size must be positive")
+		}
+		if c.OpenAIBatch.UpstreamTimeoutSeconds <= 0 {
+			return fmt.Errorf("openai_batch.upstream_timeout_seconds must be positive")
+		}
+	}
+	if c.BalanceLedger.ReconcileEnabled && strings.TrimSpace(c.BalanceLedger.ReconcileSchedule) == "" {
+		return fmt.Errorf("balance_ledger.reconcile_schedule is required when balance_ledger.reconcile_enabled is true")
+	}
+	if c.CreditLine.StatementEnabled {
+		if strings.TrimSpace(c.CreditLine.StatementSchedule) == "" {
+			return fmt.Errorf("credit_line.statement_schedule is required when credit_line.statement_enabled is true")
+		}
+		if c.CreditLine.DueDays < 0 {
+			return fmt.Errorf("credit_line.due_days must be non-negative")
+		}
+	}
+	if c.SpendAnomaly.Enabled {
+		if strings.TrimSpace(c.SpendAnomaly.Schedule) == "" {
+			return fmt.Errorf("spend_anomaly.schedule is required when spend_anomaly.enabled is true")
+		}
+		if c.SpendAnomaly.WindowMinutes <= 0 {
+			return fmt.Errorf("spend_anomaly.window_minutes must be positive")
+		}
+		if c.SpendAnomaly.BaselineDays <= 0 {
+			return fmt.Errorf("spend_anomaly.baseline_days must be positive")
+		}
+		if c.SpendAnomaly.Multiplier <= 1 {
+			return fmt.Errorf("spend_anomaly.multiplier must be greater than 1")
+		}
+		if c.SpendAnomaly.MinSpend < 0 || c.SpendAnomaly.MinHistoryHours < 0 || c.SpendAnomaly.CooldownMinutes < 0 {
+			return fmt.Errorf("spend_anomaly.min_spend, min_history_hours and cooldown_minutes must be non-negative")
+		}
+	}
+	if c.UsageExport.SchedulerEnabled && strings.TrimSpace(c.UsageExport.Schedule) == "" {
+		return fmt.Errorf("usage_export.schedule is required when usage_export.scheduler_enabled is true")
+	}
+	switch c.UsageExport.UserStorage {
+	case "backup", "image_storage":
+	default:
+		return fmt.Errorf("usage_export.user_storage must be one of: backup, image_storage")
+	}
+	if c.UsageExport.MaxBackfillPeriods <= 0 || c.UsageExport.UserMaxRangeDays <= 0 ||
+		c.UsageExport.UserMaxPending <= 0 || c.UsageExport.DownloadURLExpiryMinutes <= 0 {
+		return fmt.Errorf("usage_export.max_backfill_periods, user_max_range_days, user_max_pending and download_url_expiry_minutes must be positive")
+	}
+	if c.ResponseCache.Enabled {
+		switch c.ResponseCache.Backend {
+		case "redis":
+		case "s3":
+			if c.ResponseCache.S3.Bucket == "" || c.ResponseCache.S3.AccessKeyID == "" || c.ResponseCache.S3.SecretAccessKey == "" {
+				return fmt.Errorf("response_cache.s3.bucket, access_key_id and secret_access_key are required when response_cache.backend is s3")
+			}
+		default:
+			return fmt.Errorf("response_cache.backend must be one of: redis, s3")
+		}
+		if c.ResponseCache.DefaultTTLSeconds <= 0 {
+			return fmt.Errorf("response_cache.default_ttl_seconds must be positive")
+		}
+		if c.ResponseCache.MaxBodyBytes <= 0 {
+			return fmt.Errorf("response_cache.max_body_bytes must be positive")
+		}
+	}
 	if c.Dashboard.Enabled {
 		if c.Dashboard.StatsFreshTTLSeconds <= 0 {
 			return fmt.Errorf("dashboard_cache.stats_fresh_ttl_seconds must be positive")
@@ -3126,6 +3568,15 @@ func (c *Config) Validate() error {
 			return fmt.Errorf("usage_cleanup.task_timeout_seconds must be non-negative")
 		}
 	}
+	if c.Metrics.Enabled && strings.TrimSpace(c.Metrics.AuthToken) == "" {
+		return fmt.Errorf("metrics.auth_token is required when metrics.enabled is true")
+	}
+	if c.Tracing.Enabled && strings.TrimSpace(c.Tracing.Endpoint) == "" {
+		return fmt.Errorf("tracing.endpoint is required when tracing.enabled is true")
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		return fmt.Errorf("tracing.sample_ratio must be between 0 and 1")
+	}
 	if c.Idempotency.DefaultTTLSeconds <= 0 {
 		return fmt.Errorf("idempotency.default_ttl_seconds must be positive")
 	}
@@ -656,6 +656,67 @@ func TestLoadDefaultBatchImageQueueDisabled(t *testing.T) {
 	require.False(t, cfg.BatchImage.QueueEnabled)
 }
 
+func TestLoadMessageBatchConfig(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.False(t, cfg.MessageBatch.Enabled)
+	require.Equal(t, 0.5, cfg.MessageBatch.DiscountMultiplier)
+	require.Equal(t, 10000, cfg.MessageBatch.MaxRequestsPerBatch)
+
+	resetViperWithJWTSecret(t)
+	t.Setenv("MESSAGE_BATCH_ENABLED", "true")
+	t.Setenv("MESSAGE_BATCH_HOLD_MULTIPLIER", "0.4")
+	_, err = Load()
+	require.ErrorContains(t, err, "message_batch.hold_multiplier")
+}
+
+func TestLoadOpenAIBatchConfig(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.False(t, cfg.OpenAIBatch.Enabled)
+	require.Equal(t, 0.5, cfg.OpenAIBatch.DiscountMultiplier)
+	require.Equal(t, 50000, cfg.OpenAIBatch.MaxRequestsPerFile)
+	require.Equal(t, int64(200*1024*1024), cfg.OpenAIBatch.MaxFileBytes)
+
+	resetViperWithJWTSecret(t)
+	t.Setenv("OPENAI_BATCH_ENABLED", "true")
+	t.Setenv("OPENAI_BATCH_MAX_REQUESTS_PER_FILE", "60000")
+	_, err = Load()
+	require.ErrorContains(t, err, "openai_batch.max_requests_per_file")
+}
+
+func TestLoadResponseCacheConfig(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.False(t, cfg.ResponseCache.Enabled)
+	require.Equal(t, "redis", cfg.ResponseCache.Backend)
+	require.Equal(t, 3600, cfg.ResponseCache.DefaultTTLSeconds)
+	require.True(t, cfg.ResponseCache.RequireZeroTemperature)
+	require.Equal(t, "response-cache/", cfg.ResponseCache.S3.Prefix)
+
+	resetViperWithJWTSecret(t)
+	t.Setenv("RESPONSE_CACHE_ENABLED", "true")
+	t.Setenv("RESPONSE_CACHE_BACKEND", "s3")
+	_, err = Load()
+	require.ErrorContains(t, err, "response_cache.s3.bucket")
+
+	resetViperWithJWTSecret(t)
+	t.Setenv("RESPONSE_CACHE_ENABLED", "true")
+	t.Setenv("RESPONSE_CACHE_BACKEND", "s3")
+	t.Setenv("RESPONSE_CACHE_S3_BUCKET", "cache")
+	t.Setenv("RESPONSE_CACHE_S3_ACCESS_KEY_ID", "ak")
+	t.Setenv("RESPONSE_CACHE_S3_SECRET_ACCESS_KEY", "sk")
+	cfg, err = Load()
+	require.NoError(t, err)
+	require.Equal(t, "cache", cfg.ResponseCache.S3.Bucket)
+}
+
 func TestLoadIdempotencyConfigFromEnv(t *testing.T) {
 	resetViperWithJWTSecret(t)
 	t.Setenv("IDEMPOTENCY_OBSERVE_ONLY", "false")
@@ -920,6 +981,42 @@ func TestValidateOIDCScopesMustContainOpenID(t *testing.T) {
 	}
 }
 
+func TestValidateSAMLConnect(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.SAML.FrontendRedirectURL != "/auth/saml/callback" || cfg.SAML.ClockSkewSeconds != 120 || !cfg.SAML.TrustEmail {
+		t.Fatalf("unexpected saml_connect defaults: %+v", cfg.SAML)
+	}
+
+	cfg.SAML.Enabled = true
+	cfg.SAML.SPEntityID = "https://example.com/saml"
+	cfg.SAML.ACSURL = "https://example.com/api/v1/auth/oauth/saml/acs"
+	cfg.SAML.IdPEntityID = "https://idp.example.com/metadata"
+	cfg.SAML.IdPSSOURL = "https://idp.example.com/sso"
+	cfg.SAML.IdPCertificate = "MIIB"
+	cfg.SAML.GroupMappings = []SAMLGroupMapping{{IdPGroup: "engineering", GroupIDs: []int64{1, 2}}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() expected valid saml config, got: %v", err)
+	}
+
+	cfg.SAML.GroupMappings = []SAMLGroupMapping{{IdPGroup: "engineering", GroupIDs: []int64{0}}}
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "saml_connect.group_mappings[0].group_ids") {
+		t.Fatalf("Validate() expected group_mappings error, got: %v", err)
+	}
+
+	cfg.SAML.GroupMappings = nil
+	cfg.SAML.ACSURL = "/relative/acs"
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "saml_connect.acs_url") {
+		t.Fatalf("Validate() expected saml_connect.acs_url error, got: %v", err)
+	}
+}
+
 func TestValidateOIDCAllowsIssuerOnlyEndpointsWithDiscoveryFallback(t *testing.T) {
 	resetViperWithJWTSecret(t)
 
@@ -0,0 +1,24 @@
+package domain
+
+// CompositeRouteSplit turns a composite model route into a named traffic
+// experiment: matching requests are spread across Arms by Weight instead of
+// always going to the route's single target.
+//
+// StickyBy pins a caller to one arm for the lifetime of the experiment by
+// hashing the API key or user ID together with the experiment name, so
+// multi-turn conversations do not flip between arms. "none" (or empty) picks
+// an arm per request.
+type CompositeRouteSplit struct {
+	Experiment string                   `json:"experiment"`
+	StickyBy   string                   `json:"sticky_by,omitempty"`
+	Arms       []CompositeRouteSplitArm `json:"arms"`
+}
+
+// CompositeRouteSplitArm is one branch of a split. An empty UpstreamModel
+// forwards the requested model unchanged.
+type CompositeRouteSplitArm struct {
+	Name           string `json:"name"`
+	Weight         int    `json:"weight"`
+	TargetPlatform string `json:"target_platform"`
+	UpstreamModel  string `json:"upstream_model,omitempty"`
+}
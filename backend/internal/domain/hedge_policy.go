@@ -0,0 +1,15 @@
+package domain
+
+// GroupHedgePolicy configures hedged requests for non-streaming OpenAI calls:
+// when the first account has not answered within the hedge delay, the same
+// request is dispatched to a second account and the first answer wins.
+//
+// The delay is the Percentile of the first account's recent non-streaming
+// latency, clamped to [MinDelayMs, MaxDelayMs]. Until enough samples exist
+// MaxDelayMs is used.
+type GroupHedgePolicy struct {
+	Enabled    bool `json:"enabled"`
+	Percentile int  `json:"percentile,omitempty"`
+	MinDelayMs int  `json:"min_delay_ms,omitempty"`
+	MaxDelayMs int  `json:"max_delay_ms,omitempty"`
+}
@@ -0,0 +1,17 @@
+package domain
+
+// GroupMirrorPolicy configures traffic mirroring for evaluating a candidate
+// upstream before it serves production traffic: a sampled fraction of the
+// group's text requests is replayed asynchronously to TargetGroupID (the
+// source group itself when unset), optionally pinned to TargetAccountID.
+//
+// Mirrored responses never reach the client and are never billed; only a
+// comparison record is kept. CompareOutput additionally stores output
+// excerpts and a similarity score.
+type GroupMirrorPolicy struct {
+	Enabled         bool    `json:"enabled"`
+	SampleRate      float64 `json:"sample_rate,omitempty"`
+	TargetGroupID   *int64  `json:"target_group_id,omitempty"`
+	TargetAccountID *int64  `json:"target_account_id,omitempty"`
+	CompareOutput   bool    `json:"compare_output,omitempty"`
+}
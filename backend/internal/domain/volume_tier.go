@@ -0,0 +1,9 @@
+package domain
+
+// VolumeTier is one band of a group's cumulative monthly spend pricing.
+// UpToUSD is the exclusive upper bound of month-to-date spend for the band;
+// nil marks the open-ended last band.
+type VolumeTier struct {
+	UpToUSD    *float64 `json:"up_to_usd,omitempty"`
+	Multiplier float64  `json:"multiplier"`
+}
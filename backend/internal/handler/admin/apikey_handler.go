@@ -26,6 +26,9 @@ func NewAdminAPIKeyHandler(adminService service.AdminService) *AdminAPIKeyHandle
 type AdminUpdateAPIKeyGroupRequest struct {
 	GroupID             *int64 `json:"group_id"`               // nil=不修改, 0=解绑, >0=绑定到目标分组
 	ResetRateLimitUsage *bool  `json:"reset_rate_limit_usage"` // true=重置 5h/1d/7d 限速用量
+	// 模型白/黑名单（nil=不修改，空数组=清空）
+	ModelAllowlist *[]string `json:"model_allowlist"`
+	ModelDenylist  *[]string `json:"model_denylist"`
 }
 
 // UpdateGroup handles updating an API key's admin-managed fields.
@@ -44,6 +47,13 @@ func (h *AdminAPIKeyHandler) UpdateGroup(c *gin.Context) {
 	}
 
 	var resetKey *service.APIKey
+	if req.ModelAllowlist != nil || req.ModelDenylist != nil {
+		resetKey, err = h.adminService.AdminUpdateAPIKeyModelRestrictions(c.Request.Context(), keyID, req.ModelAllowlist, req.ModelDenylist)
+		if err != nil {
+			response.ErrorFrom(c, err)
+			return
+		}
+	}
 	if req.ResetRateLimitUsage != nil && *req.ResetRateLimitUsage {
 		resetKey, err = h.adminService.AdminResetAPIKeyRateLimitUsage(c.Request.Context(), keyID)
 		if err != nil {
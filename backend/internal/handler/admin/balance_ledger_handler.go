@@ -0,0 +1,116 @@
+package admin
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BalanceLedgerHandler 余额账本与对账管理接口。账本只读，不提供任何修改入口。
+type BalanceLedgerHandler struct {
+	ledgerService *service.BalanceLedgerService
+}
+
+// NewBalanceLedgerHandler 创建余额账本处理器。
+func NewBalanceLedgerHandler(ledgerService *service.BalanceLedgerService) *BalanceLedgerHandler {
+	return &BalanceLedgerHandler{ledgerService: ledgerService}
+}
+
+// ListUserEntries 分页查询用户的余额流水。
+// GET /api/v1/admin/users/:id/balance-ledger
+func (h *BalanceLedgerHandler) ListUserEntries(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || userID <= 0 {
+		response.BadRequest(c, "Invalid user ID")
+		return
+	}
+	h.listEntries(c, service.BalanceLedgerFilter{UserID: userID})
+}
+
+// ListOrganizationEntries 分页查询组织钱包的余额流水。
+// GET /api/v1/admin/organizations/:id/balance-ledger
+func (h *BalanceLedgerHandler) ListOrganizationEntries(c *gin.Context) {
+	organizationID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || organizationID <= 0 {
+		response.BadRequest(c, "Invalid organization ID")
+		return
+	}
+	h.listEntries(c, service.BalanceLedgerFilter{OrganizationID: organizationID})
+}
+
+func (h *BalanceLedgerHandler) listEntries(c *gin.Context, filter service.BalanceLedgerFilter) {
+	page, pageSize := response.ParsePagination(c)
+	filter.EntryType = strings.TrimSpace(c.Query("entry_type"))
+	filter.Page = page
+	filter.PageSize = pageSize
+	if v := strings.TrimSpace(c.Query("start_time")); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			response.BadRequest(c, "Invalid start_time, expect RFC3339")
+			return
+		}
+		filter.StartTime = &t
+	}
+	if v := strings.TrimSpace(c.Query("end_time")); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			response.BadRequest(c, "Invalid end_time, expect RFC3339")
+			return
+		}
+		filter.EndTime = &t
+	}
+
+	result, err := h.ledgerService.ListEntries(c.Request.Context(), filter)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Paginated(c, result.Entries, result.Total, result.Page, result.PageSize)
+}
+
+// ListReconciliations 分页查询对账记录。
+// GET /api/v1/admin/balance-ledger/reconciliations
+func (h *BalanceLedgerHandler) ListReconciliations(c *gin.Context) {
+	page, pageSize := response.ParsePagination(c)
+	if pageSize > 100 {
+		pageSize = 100
+	}
+	runs, total, err := h.ledgerService.ListReconciliations(c.Request.Context(), page, pageSize)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Paginated(c, runs, total, page, pageSize)
+}
+
+// GetReconciliation 查询单次对账结果及差异用户明细。
+// GET /api/v1/admin/balance-ledger/reconciliations/:id
+func (h *BalanceLedgerHandler) GetReconciliation(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(c, "Invalid reconciliation ID")
+		return
+	}
+	run, mismatches, err := h.ledgerService.GetReconciliation(c.Request.Context(), id)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"reconciliation": run, "mismatches": mismatches})
+}
+
+// Reconcile 立即执行一次对账。
+// POST /api/v1/admin/balance-ledger/reconciliations
+func (h *BalanceLedgerHandler) Reconcile(c *gin.Context) {
+	run, err := h.ledgerService.Reconcile(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, run)
+}
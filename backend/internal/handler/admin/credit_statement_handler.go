@@ -0,0 +1,89 @@
+package admin
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreditStatementHandler 授信月度账单管理接口。
+type CreditStatementHandler struct {
+	creditStatementService *service.CreditStatementService
+}
+
+// NewCreditStatementHandler 创建授信账单处理器。
+func NewCreditStatementHandler(creditStatementService *service.CreditStatementService) *CreditStatementHandler {
+	return &CreditStatementHandler{creditStatementService: creditStatementService}
+}
+
+// List 分页查询账单，可按 user_id / status 过滤。
+// GET /api/v1/admin/credit-statements
+func (h *CreditStatementHandler) List(c *gin.Context) {
+	page, pageSize := response.ParsePagination(c)
+	filter := service.CreditStatementFilter{
+		Status:   strings.TrimSpace(c.Query("status")),
+		Page:     page,
+		PageSize: pageSize,
+	}
+	if v := strings.TrimSpace(c.Query("user_id")); v != "" {
+		userID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || userID <= 0 {
+			response.BadRequest(c, "Invalid user_id")
+			return
+		}
+		filter.UserID = userID
+	}
+	items, total, err := h.creditStatementService.List(c.Request.Context(), filter)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Paginated(c, items, total, page, pageSize)
+}
+
+// Get 查询单张账单。
+// GET /api/v1/admin/credit-statements/:id
+func (h *CreditStatementHandler) Get(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(c, "Invalid statement ID")
+		return
+	}
+	st, err := h.creditStatementService.Get(c.Request.Context(), id)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, st)
+}
+
+// Run 立即执行一次账单任务：补生成上月账单并处理结清/逾期。
+// POST /api/v1/admin/credit-statements/run
+func (h *CreditStatementHandler) Run(c *gin.Context) {
+	result, err := h.creditStatementService.Run(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, result)
+}
+
+// Settle 手动将账单标记为已结清，无其他逾期账单时恢复用户的 API Key。
+// POST /api/v1/admin/credit-statements/:id/settle
+func (h *CreditStatementHandler) Settle(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(c, "Invalid statement ID")
+		return
+	}
+	st, err := h.creditStatementService.Settle(c.Request.Context(), id)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, st)
+}
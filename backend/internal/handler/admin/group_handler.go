@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"strconv"
 	"strings"
 
@@ -24,6 +25,18 @@ type GroupHandler struct {
 	adminService         service.AdminService
 	dashboardService     *service.DashboardService
 	groupCapacityService *service.GroupCapacityService
+	hedgeStats           *service.GroupHedgeStatsService
+	compositeExperiments *service.CompositeRouteExperimentService
+}
+
+// SetHedgeStatsService attaches the hedged-request statistics service.
+func (h *GroupHandler) SetHedgeStatsService(hedgeStats *service.GroupHedgeStatsService) {
+	h.hedgeStats = hedgeStats
+}
+
+// SetCompositeExperimentService attaches the traffic-split experiment statistics service.
+func (h *GroupHandler) SetCompositeExperimentService(experiments *service.CompositeRouteExperimentService) {
+	h.compositeExperiments = experiments
 }
 
 // GetLiveCapability 返回当前服务端是否具备生成 Live attestation 的运行环境。
@@ -121,6 +134,9 @@ type CreateGroupRequest struct {
 	ProfitControlEnabled            bool                          `json:"profit_control_enabled"`
 	ProfitMinMargin                 *float64                      `json:"profit_min_margin"`
 	ProfitSafetyBuffer              *float64                      `json:"profit_safety_buffer"`
+	ResponseCacheEnabled            bool                          `json:"response_cache_enabled"`
+	ResponseCacheTTLSeconds         *int                          `json:"response_cache_ttl_seconds"`
+	ResponseCacheHitMultiplier      *float64                      `json:"response_cache_hit_multiplier"`
 	ImagePrice1K                    *float64                      `json:"image_price_1k"`
 	ImagePrice2K                    *float64                      `json:"image_price_2k"`
 	ImagePrice4K                    *float64                      `json:"image_price_4k"`
@@ -156,6 +172,14 @@ type CreateGroupRequest struct {
 	MaxReasoningEffort string `json:"max_reasoning_effort"`
 	// OpenAI/Codex 推理强度精确映射。
 	ReasoningEffortMappings []service.ReasoningEffortMapping `json:"reasoning_effort_mappings"`
+	// 月度消费阶梯，空表示不启用。
+	VolumeTiers []service.VolumeTier `json:"volume_tiers"`
+	// 允许 anthropic/openai 分组接入 Gemini 原生 generateContent 请求。
+	AllowGeminiNative bool `json:"allow_gemini_native"`
+	// 非流式请求对冲策略，仅 openai 分组生效。
+	HedgePolicy service.GroupHedgePolicy `json:"hedge_policy"`
+	// 流量镜像策略：抽样重放到候选分组/账号，不计费。
+	MirrorPolicy service.GroupMirrorPolicy `json:"mirror_policy"`
 	// 从指定分组复制账号（创建后自动绑定）
 	CopyAccountsFromGroupIDs []int64 `json:"copy_accounts_from_group_ids"`
 }
@@ -188,6 +212,9 @@ type UpdateGroupRequest struct {
 	ProfitControlEnabled            *bool                         `json:"profit_control_enabled"`
 	ProfitMinMargin                 *float64                      `json:"profit_min_margin"`
 	ProfitSafetyBuffer              *float64                      `json:"profit_safety_buffer"`
+	ResponseCacheEnabled            *bool                         `json:"response_cache_enabled"`
+	ResponseCacheTTLSeconds         *int                          `json:"response_cache_ttl_seconds"`
+	ResponseCacheHitMultiplier      *float64                      `json:"response_cache_hit_multiplier"`
 	ImagePrice1K                    *float64                      `json:"image_price_1k"`
 	ImagePrice2K                    *float64                      `json:"image_price_2k"`
 	ImagePrice4K                    *float64                      `json:"image_price_4k"`
@@ -223,6 +250,14 @@ type UpdateGroupRequest struct {
 	MaxReasoningEffort *string `json:"max_reasoning_effort"`
 	// nil 不修改，空数组清空，非空数组替换。
 	ReasoningEffortMappings *[]service.ReasoningEffortMapping `json:"reasoning_effort_mappings"`
+	// 月度消费阶梯：nil 不修改，空数组关闭，非空数组替换。
+	VolumeTiers *[]service.VolumeTier `json:"volume_tiers"`
+	// Gemini 原生协议接入：nil 不修改。
+	AllowGeminiNative *bool `json:"allow_gemini_native"`
+	// 对冲请求策略：nil 不修改。
+	HedgePolicy *service.GroupHedgePolicy `json:"hedge_policy"`
+	// 流量镜像策略：nil 不修改。
+	MirrorPolicy *service.GroupMirrorPolicy `json:"mirror_policy"`
 	// 从指定分组复制账号（同步操作：先清空当前分组的账号绑定，再绑定源分组的账号）
 	CopyAccountsFromGroupIDs []int64 `json:"copy_accounts_from_group_ids"`
 }
@@ -236,6 +271,8 @@ type CompositeRouteRequest struct {
 	Priority       int    `json:"priority"`
 	Enabled        *bool  `json:"enabled"`
 	Notes          string `json:"notes"`
+	// Split 非空时路由按权重/粘连在多个分支间分流（A/B 实验），target_platform 取首个分支。
+	Split *service.CompositeRouteSplit `json:"split"`
 }
 
 type CompositeRoutePreviewRequest struct {
@@ -378,6 +415,30 @@ func (h *GroupHandler) PreviewCompositeRoute(c *gin.Context) {
 	response.Success(c, decision)
 }
 
+// GetCompositeRouteExperimentStats 返回拆分路由各实验分支的请求数、错误率、延迟、token 与成本。
+// GET /api/v1/admin/groups/:id/composite-routes/:route_id/experiment-stats?start_date=&end_date=&timezone=
+func (h *GroupHandler) GetCompositeRouteExperimentStats(c *gin.Context) {
+	groupID, ok := parsePositiveIDParam(c, "id")
+	if !ok {
+		return
+	}
+	routeID, ok := parsePositiveIDParam(c, "route_id")
+	if !ok {
+		return
+	}
+	if h.compositeExperiments == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Composite experiment service not available")
+		return
+	}
+	startTime, endTime := parseTimeRange(c)
+	stats, err := h.compositeExperiments.Stats(c.Request.Context(), groupID, routeID, startTime, endTime)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, stats)
+}
+
 func compositeRouteRequestToInput(req CompositeRouteRequest, defaultEnabled bool) service.CompositeRouteInput {
 	enabled := defaultEnabled
 	if req.Enabled != nil {
@@ -392,6 +453,7 @@ func compositeRouteRequestToInput(req CompositeRouteRequest, defaultEnabled bool
 		Priority:       req.Priority,
 		Enabled:        enabled,
 		Notes:          req.Notes,
+		Split:          req.Split,
 	}
 }
 
@@ -523,6 +585,9 @@ func (h *GroupHandler) Create(c *gin.Context) {
 		ProfitControlEnabled:            req.ProfitControlEnabled,
 		ProfitMinMargin:                 req.ProfitMinMargin,
 		ProfitSafetyBuffer:              req.ProfitSafetyBuffer,
+		ResponseCacheEnabled:            req.ResponseCacheEnabled,
+		ResponseCacheTTLSeconds:         req.ResponseCacheTTLSeconds,
+		ResponseCacheHitMultiplier:      req.ResponseCacheHitMultiplier,
 		ImagePrice1K:                    req.ImagePrice1K,
 		ImagePrice2K:                    req.ImagePrice2K,
 		ImagePrice4K:                    req.ImagePrice4K,
@@ -552,6 +617,10 @@ func (h *GroupHandler) Create(c *gin.Context) {
 		RPMLimit:                        req.RPMLimit,
 		MaxReasoningEffort:              req.MaxReasoningEffort,
 		ReasoningEffortMappings:         req.ReasoningEffortMappings,
+		VolumeTiers:                     req.VolumeTiers,
+		AllowGeminiNative:               req.AllowGeminiNative,
+		HedgePolicy:                     req.HedgePolicy,
+		MirrorPolicy:                    req.MirrorPolicy,
 		CopyAccountsFromGroupIDs:        req.CopyAccountsFromGroupIDs,
 	})
 	if err != nil {
@@ -650,6 +719,9 @@ func (h *GroupHandler) Update(c *gin.Context) {
 		ProfitControlEnabled:            req.ProfitControlEnabled,
 		ProfitMinMargin:                 req.ProfitMinMargin,
 		ProfitSafetyBuffer:              req.ProfitSafetyBuffer,
+		ResponseCacheEnabled:            req.ResponseCacheEnabled,
+		ResponseCacheTTLSeconds:         req.ResponseCacheTTLSeconds,
+		ResponseCacheHitMultiplier:      req.ResponseCacheHitMultiplier,
 		ImagePrice1K:                    req.ImagePrice1K,
 		ImagePrice2K:                    req.ImagePrice2K,
 		ImagePrice4K:                    req.ImagePrice4K,
@@ -679,6 +751,10 @@ func (h *GroupHandler) Update(c *gin.Context) {
 		RPMLimit:                        req.RPMLimit,
 		MaxReasoningEffort:              req.MaxReasoningEffort,
 		ReasoningEffortMappings:         req.ReasoningEffortMappings,
+		VolumeTiers:                     req.VolumeTiers,
+		AllowGeminiNative:               req.AllowGeminiNative,
+		HedgePolicy:                     req.HedgePolicy,
+		MirrorPolicy:                    req.MirrorPolicy,
 		CopyAccountsFromGroupIDs:        req.CopyAccountsFromGroupIDs,
 	})
 	if err != nil {
@@ -726,6 +802,34 @@ func (h *GroupHandler) GetStats(c *gin.Context) {
 	_ = groupID // TODO: implement actual stats
 }
 
+// GetHedgeStats 返回分组最近 N 天的对冲请求触发次数、胜率与估算对冲成本。
+// GET /api/v1/admin/groups/:id/hedge-stats?days=7
+func (h *GroupHandler) GetHedgeStats(c *gin.Context) {
+	groupID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || groupID <= 0 {
+		response.BadRequest(c, "Invalid group ID")
+		return
+	}
+	days := 0
+	if v := strings.TrimSpace(c.Query("days")); v != "" {
+		days, err = strconv.Atoi(v)
+		if err != nil || days <= 0 {
+			response.BadRequest(c, "Invalid days")
+			return
+		}
+	}
+	if h.hedgeStats == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Hedge stats service not available")
+		return
+	}
+	summary, err := h.hedgeStats.Summary(c.Request.Context(), groupID, days)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, summary)
+}
+
 // GetUsageSummary returns today's and cumulative cost for all groups.
 // GET /api/v1/admin/groups/usage-summary?timezone=Asia/Shanghai
 func (h *GroupHandler) GetUsageSummary(c *gin.Context) {
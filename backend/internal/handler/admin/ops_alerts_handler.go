@@ -33,6 +33,7 @@ var validOpsAlertMetricTypes = []string{
 	"overload_account_count",
 	"proxy_expired_count",
 	"proxy_expiring_soon_count",
+	"batch_settlement_stuck_count",
 }
 
 var validOpsAlertMetricTypeSet = func() map[string]struct{} {
@@ -254,7 +255,11 @@ func (h *OpsHandler) ListAlertRules(c *gin.Context) {
 		response.ErrorFrom(c, err)
 		return
 	}
-	response.Success(c, rules)
+	masked := make([]*service.OpsAlertRule, 0, len(rules))
+	for _, rule := range rules {
+		masked = append(masked, service.MaskOpsAlertRuleSecrets(rule))
+	}
+	response.Success(c, masked)
 }
 
 // CreateAlertRule creates an ops alert rule.
@@ -302,7 +307,7 @@ func (h *OpsHandler) CreateAlertRule(c *gin.Context) {
 		response.ErrorFrom(c, err)
 		return
 	}
-	response.Success(c, created)
+	response.Success(c, service.MaskOpsAlertRuleSecrets(created))
 }
 
 // UpdateAlertRule updates an existing ops alert rule.
@@ -357,7 +362,7 @@ func (h *OpsHandler) UpdateAlertRule(c *gin.Context) {
 		response.ErrorFrom(c, err)
 		return
 	}
-	response.Success(c, updated)
+	response.Success(c, service.MaskOpsAlertRuleSecrets(updated))
 }
 
 // DeleteAlertRule deletes an ops alert rule.
@@ -385,6 +390,35 @@ func (h *OpsHandler) DeleteAlertRule(c *gin.Context) {
 	response.Success(c, gin.H{"deleted": true})
 }
 
+// TestAlertChannel sends a test notification to a single channel config.
+// POST /api/v1/admin/ops/alert-rules/test-channel
+func (h *OpsHandler) TestAlertChannel(c *gin.Context) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return
+	}
+	if err := h.opsService.RequireMonitoringEnabled(c.Request.Context()); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	var payload struct {
+		Channel service.OpsAlertNotifyChannel `json:"channel"`
+		RuleID  int64                         `json:"rule_id"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		response.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	delivery, err := h.opsService.TestAlertChannel(c.Request.Context(), payload.Channel, payload.RuleID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, delivery)
+}
+
 // GetAlertEvent returns a single ops alert event.
 // GET /api/v1/admin/ops/alert-events/:id
 func (h *OpsHandler) GetAlertEvent(c *gin.Context) {
@@ -14,7 +14,8 @@ import (
 )
 
 type OpsHandler struct {
-	opsService *service.OpsService
+	opsService    *service.OpsService
+	trafficMirror *service.TrafficMirrorService
 }
 
 // GetErrorLogByID returns ops error log detail.
@@ -0,0 +1,82 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// SetTrafficMirrorService 注入流量镜像服务，用于在运维看板查看镜像对比结果。
+func (h *OpsHandler) SetTrafficMirrorService(mirror *service.TrafficMirrorService) {
+	h.trafficMirror = mirror
+}
+
+// GetTrafficMirrorSummary 按镜像目标汇总主请求与镜像请求的成功率、延迟、TTFT、token 与输出相似度。
+// GET /api/v1/admin/ops/traffic-mirror/summary
+func (h *OpsHandler) GetTrafficMirrorSummary(c *gin.Context) {
+	filter, ok := h.parseTrafficMirrorFilter(c)
+	if !ok {
+		return
+	}
+	rows, filter, err := h.trafficMirror.Summaries(c.Request.Context(), filter)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{
+		"start_time": filter.StartTime,
+		"end_time":   filter.EndTime,
+		"items":      rows,
+	})
+}
+
+// ListTrafficMirrorRecords 分页返回逐条镜像对比记录。
+// GET /api/v1/admin/ops/traffic-mirror/records
+func (h *OpsHandler) ListTrafficMirrorRecords(c *gin.Context) {
+	filter, ok := h.parseTrafficMirrorFilter(c)
+	if !ok {
+		return
+	}
+	filter.Page, filter.PageSize = response.ParsePagination(c)
+	filter.OnlyDiverged = c.Query("only_diverged") == "true"
+	records, total, filter, err := h.trafficMirror.ListRecords(c.Request.Context(), filter)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Paginated(c, records, total, filter.Page, filter.PageSize)
+}
+
+func (h *OpsHandler) parseTrafficMirrorFilter(c *gin.Context) (service.TrafficMirrorFilter, bool) {
+	if h.trafficMirror == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Traffic mirror service not available")
+		return service.TrafficMirrorFilter{}, false
+	}
+	startTime, endTime, err := parseOpsTimeRange(c, "24h")
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return service.TrafficMirrorFilter{}, false
+	}
+	filter := service.TrafficMirrorFilter{StartTime: startTime, EndTime: endTime}
+	if v := strings.TrimSpace(c.Query("group_id")); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id <= 0 {
+			response.BadRequest(c, "Invalid group_id")
+			return service.TrafficMirrorFilter{}, false
+		}
+		filter.GroupID = id
+	}
+	if v := strings.TrimSpace(c.Query("target_group_id")); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id <= 0 {
+			response.BadRequest(c, "Invalid target_group_id")
+			return service.TrafficMirrorFilter{}, false
+		}
+		filter.TargetGroupID = id
+	}
+	return filter, true
+}
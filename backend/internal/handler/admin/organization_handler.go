@@ -0,0 +1,379 @@
+package admin
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/handler/dto"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OrganizationHandler handles admin organization management
+type OrganizationHandler struct {
+	organizationService *service.OrganizationService
+}
+
+// NewOrganizationHandler creates a new admin organization handler
+func NewOrganizationHandler(organizationService *service.OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{
+		organizationService: organizationService,
+	}
+}
+
+type CreateOrganizationRequest struct {
+	Name        string `json:"name" binding:"required"`
+	OwnerUserID int64  `json:"owner_user_id" binding:"required,gt=0"`
+}
+
+type UpdateOrganizationRequest struct {
+	Name   *string `json:"name"`
+	Status *string `json:"status" binding:"omitempty,oneof=active disabled"`
+}
+
+type AdjustOrganizationBalanceRequest struct {
+	Amount float64 `json:"amount" binding:"required"` // 正数充值，负数扣减
+}
+
+type AddOrganizationMemberRequest struct {
+	UserID             int64    `json:"user_id"`
+	Email              string   `json:"email"`
+	Role               string   `json:"role" binding:"omitempty,oneof=admin member"`
+	MonthlySpendCapUSD *float64 `json:"monthly_spend_cap_usd"`
+}
+
+type UpdateOrganizationMemberRequest struct {
+	Role               *string  `json:"role" binding:"omitempty,oneof=admin member"`
+	MonthlySpendCapUSD *float64 `json:"monthly_spend_cap_usd"`
+	ClearSpendCap      bool     `json:"clear_spend_cap"`
+}
+
+type AssignOrganizationSubscriptionRequest struct {
+	GroupID      int64 `json:"group_id" binding:"required,gt=0"`
+	ValidityDays int   `json:"validity_days" binding:"omitempty,max=36500"`
+}
+
+// List handles listing organizations
+// GET /api/v1/admin/organizations
+func (h *OrganizationHandler) List(c *gin.Context) {
+	page, pageSize := response.ParsePagination(c)
+	search := strings.TrimSpace(c.Query("search"))
+	if len(search) > 100 {
+		search = search[:100]
+	}
+
+	orgs, paginationResult, err := h.organizationService.List(c.Request.Context(), pagination.PaginationParams{
+		Page:      page,
+		PageSize:  pageSize,
+		SortBy:    c.DefaultQuery("sort_by", "created_at"),
+		SortOrder: c.DefaultQuery("sort_order", "desc"),
+	}, search)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	out := make([]dto.Organization, 0, len(orgs))
+	for i := range orgs {
+		out = append(out, *dto.OrganizationFromService(&orgs[i]))
+	}
+	response.PaginatedWithResult(c, out, toResponsePagination(paginationResult))
+}
+
+// GetByID handles getting an organization
+// GET /api/v1/admin/organizations/:id
+func (h *OrganizationHandler) GetByID(c *gin.Context) {
+	orgID, ok := parseOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	org, err := h.organizationService.GetByID(c.Request.Context(), orgID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, dto.OrganizationFromService(org))
+}
+
+// Create handles creating an organization for an existing user
+// POST /api/v1/admin/organizations
+func (h *OrganizationHandler) Create(c *gin.Context) {
+	var req CreateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	org, err := h.organizationService.CreateOrganization(c.Request.Context(), req.OwnerUserID, req.Name)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, dto.OrganizationFromService(org))
+}
+
+// Update handles renaming or enabling/disabling an organization
+// PUT /api/v1/admin/organizations/:id
+func (h *OrganizationHandler) Update(c *gin.Context) {
+	orgID, ok := parseOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	org, err := h.organizationService.UpdateOrganization(c.Request.Context(), orgID, &service.UpdateOrganizationInput{
+		Name:   req.Name,
+		Status: req.Status,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, dto.OrganizationFromService(org))
+}
+
+// Delete handles deleting an organization
+// DELETE /api/v1/admin/organizations/:id
+func (h *OrganizationHandler) Delete(c *gin.Context) {
+	orgID, ok := parseOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.organizationService.DeleteOrganization(c.Request.Context(), orgID); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "Organization deleted successfully"})
+}
+
+// AdjustBalance handles crediting or debiting the organization wallet
+// POST /api/v1/admin/organizations/:id/balance
+func (h *OrganizationHandler) AdjustBalance(c *gin.Context) {
+	orgID, ok := parseOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	var req AdjustOrganizationBalanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	org, err := h.organizationService.AdjustBalance(c.Request.Context(), orgID, req.Amount)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, dto.OrganizationFromService(org))
+}
+
+// ListMembers handles listing organization members
+// GET /api/v1/admin/organizations/:id/members
+func (h *OrganizationHandler) ListMembers(c *gin.Context) {
+	orgID, ok := parseOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.organizationService.ListMembers(c.Request.Context(), orgID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, dto.OrganizationMembersFromService(members))
+}
+
+// AddMember handles adding a user (by ID or email) to an organization
+// POST /api/v1/admin/organizations/:id/members
+func (h *OrganizationHandler) AddMember(c *gin.Context) {
+	orgID, ok := parseOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	var req AddOrganizationMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	if req.UserID <= 0 && strings.TrimSpace(req.Email) == "" {
+		response.BadRequest(c, "user_id or email is required")
+		return
+	}
+
+	member, err := h.organizationService.AddMember(c.Request.Context(), orgID, &service.AddOrganizationMemberInput{
+		UserID:             req.UserID,
+		Email:              strings.TrimSpace(req.Email),
+		Role:               req.Role,
+		MonthlySpendCapUSD: req.MonthlySpendCapUSD,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, dto.OrganizationMemberFromService(member))
+}
+
+// UpdateMember handles updating a member's role or monthly spend cap
+// PUT /api/v1/admin/organizations/:id/members/:user_id
+func (h *OrganizationHandler) UpdateMember(c *gin.Context) {
+	orgID, ok := parseOrganizationID(c)
+	if !ok {
+		return
+	}
+	userID, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil || userID <= 0 {
+		response.BadRequest(c, "Invalid user ID")
+		return
+	}
+
+	var req UpdateOrganizationMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	member, err := h.organizationService.UpdateMember(c.Request.Context(), orgID, userID, &service.UpdateOrganizationMemberInput{
+		Role:               req.Role,
+		MonthlySpendCapUSD: req.MonthlySpendCapUSD,
+		ClearSpendCap:      req.ClearSpendCap,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, dto.OrganizationMemberFromService(member))
+}
+
+// RemoveMember handles removing a member from an organization
+// DELETE /api/v1/admin/organizations/:id/members/:user_id
+func (h *OrganizationHandler) RemoveMember(c *gin.Context) {
+	orgID, ok := parseOrganizationID(c)
+	if !ok {
+		return
+	}
+	userID, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil || userID <= 0 {
+		response.BadRequest(c, "Invalid user ID")
+		return
+	}
+
+	if err := h.organizationService.RemoveMember(c.Request.Context(), orgID, userID); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "Member removed successfully"})
+}
+
+// ListSubscriptions handles listing organization subscriptions
+// GET /api/v1/admin/organizations/:id/subscriptions
+func (h *OrganizationHandler) ListSubscriptions(c *gin.Context) {
+	orgID, ok := parseOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	subs, err := h.organizationService.ListSubscriptions(c.Request.Context(), orgID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, dto.OrganizationSubscriptionsFromService(subs))
+}
+
+// AssignSubscription handles assigning (or extending) a group subscription shared by the organization
+// POST /api/v1/admin/organizations/:id/subscriptions
+func (h *OrganizationHandler) AssignSubscription(c *gin.Context) {
+	orgID, ok := parseOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	var req AssignOrganizationSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	sub, err := h.organizationService.AssignSubscription(c.Request.Context(), orgID, &service.AssignOrganizationSubscriptionInput{
+		GroupID:      req.GroupID,
+		ValidityDays: req.ValidityDays,
+		AssignedBy:   getAdminIDFromContext(c),
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, dto.UserSubscriptionFromServiceAdmin(sub))
+}
+
+// RemoveSubscription handles unsharing a group subscription from the organization
+// DELETE /api/v1/admin/organizations/:id/subscriptions/:group_id
+func (h *OrganizationHandler) RemoveSubscription(c *gin.Context) {
+	orgID, ok := parseOrganizationID(c)
+	if !ok {
+		return
+	}
+	groupID, err := strconv.ParseInt(c.Param("group_id"), 10, 64)
+	if err != nil || groupID <= 0 {
+		response.BadRequest(c, "Invalid group ID")
+		return
+	}
+
+	if err := h.organizationService.RemoveSubscription(c.Request.Context(), orgID, groupID); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "Organization subscription removed successfully"})
+}
+
+// Usage handles the organization usage dashboard
+// GET /api/v1/admin/organizations/:id/usage
+// Query params: start_date, end_date (YYYY-MM-DD), timezone, granularity (day/hour)
+func (h *OrganizationHandler) Usage(c *gin.Context) {
+	orgID, ok := parseOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	startTime, endTime := parseTimeRange(c)
+	dashboard, err := h.organizationService.GetUsageDashboard(c.Request.Context(), orgID, startTime, endTime, c.DefaultQuery("granularity", "day"))
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, dto.OrganizationUsageDashboardFromService(dashboard))
+}
+
+func parseOrganizationID(c *gin.Context) (int64, bool) {
+	orgID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || orgID <= 0 {
+		response.BadRequest(c, "Invalid organization ID")
+		return 0, false
+	}
+	return orgID, true
+}
@@ -369,6 +369,18 @@ func (h *SettingHandler) GetSettings(c *gin.Context) {
 		PaymentCancelRateLimitMode:                             paymentCfg.CancelRateLimitMode,
 		PaymentAlipayForceQRCode:                               paymentCfg.AlipayForceQRCode,
 		PaymentAlipayMobilePrecreateDeepLink:                   paymentCfg.AlipayMobilePrecreateDeepLink,
+		PaymentAutoRenewEnabled:                                paymentCfg.AutoRenewEnabled,
+		PaymentAutoRenewLeadDays:                               paymentCfg.AutoRenewLeadDays,
+		PaymentAutoRenewRetryHours:                             paymentCfg.AutoRenewRetryHours,
+		PaymentAutoRenewMaxAttempts:                            paymentCfg.AutoRenewMaxAttempts,
+		PaymentInvoiceEnabled:                                  paymentCfg.InvoiceEnabled,
+		PaymentInvoiceSellerName:                               paymentCfg.InvoiceSellerName,
+		PaymentInvoiceSellerAddress:                            paymentCfg.InvoiceSellerAddress,
+		PaymentInvoiceSellerTaxID:                              paymentCfg.InvoiceSellerTaxID,
+		PaymentInvoiceSellerEmail:                              paymentCfg.InvoiceSellerEmail,
+		PaymentInvoiceNumberPrefix:                             paymentCfg.InvoiceNumberPrefix,
+		PaymentCreditNoteNumberPrefix:                          paymentCfg.CreditNoteNumberPrefix,
+		PaymentInvoiceFooter:                                   paymentCfg.InvoiceFooter,
 
 		ChannelMonitorEnabled:                settings.ChannelMonitorEnabled,
 		ChannelMonitorMode:                   settings.ChannelMonitorMode,
@@ -327,6 +327,22 @@ type UpdateSettingsRequest struct {
 	// Use Alipay face-to-face precreate and an app deep link on mobile clients.
 	PaymentAlipayMobilePrecreateDeepLink *bool `json:"payment_alipay_mobile_precreate_deep_link"`
 
+	// 订阅自动续费
+	PaymentAutoRenewEnabled     *bool `json:"payment_auto_renew_enabled"`
+	PaymentAutoRenewLeadDays    *int  `json:"payment_auto_renew_lead_days"`
+	PaymentAutoRenewRetryHours  *int  `json:"payment_auto_renew_retry_hours"`
+	PaymentAutoRenewMaxAttempts *int  `json:"payment_auto_renew_max_attempts"`
+
+	// 发票与收据
+	PaymentInvoiceEnabled         *bool   `json:"payment_invoice_enabled"`
+	PaymentInvoiceSellerName      *string `json:"payment_invoice_seller_name"`
+	PaymentInvoiceSellerAddress   *string `json:"payment_invoice_seller_address"`
+	PaymentInvoiceSellerTaxID     *string `json:"payment_invoice_seller_tax_id"`
+	PaymentInvoiceSellerEmail     *string `json:"payment_invoice_seller_email"`
+	PaymentInvoiceNumberPrefix    *string `json:"payment_invoice_number_prefix"`
+	PaymentCreditNoteNumberPrefix *string `json:"payment_credit_note_number_prefix"`
+	PaymentInvoiceFooter          *string `json:"payment_invoice_footer"`
+
 	// Channel Monitor feature switch
 	ChannelMonitorEnabled                *bool   `json:"channel_monitor_enabled"`
 	ChannelMonitorMode                   *string `json:"channel_monitor_mode"`
@@ -2061,6 +2077,18 @@ func (h *SettingHandler) UpdateSettings(c *gin.Context) {
 			CancelRateLimitMode:           req.PaymentCancelRateLimitMode,
 			AlipayForceQRCode:             req.PaymentAlipayForceQRCode,
 			AlipayMobilePrecreateDeepLink: req.PaymentAlipayMobilePrecreateDeepLink,
+			AutoRenewEnabled:              req.PaymentAutoRenewEnabled,
+			AutoRenewLeadDays:             req.PaymentAutoRenewLeadDays,
+			AutoRenewRetryHours:           req.PaymentAutoRenewRetryHours,
+			AutoRenewMaxAttempts:          req.PaymentAutoRenewMaxAttempts,
+			InvoiceEnabled:                req.PaymentInvoiceEnabled,
+			InvoiceSellerName:             req.PaymentInvoiceSellerName,
+			InvoiceSellerAddress:          req.PaymentInvoiceSellerAddress,
+			InvoiceSellerTaxID:            req.PaymentInvoiceSellerTaxID,
+			InvoiceSellerEmail:            req.PaymentInvoiceSellerEmail,
+			InvoiceNumberPrefix:           req.PaymentInvoiceNumberPrefix,
+			CreditNoteNumberPrefix:        req.PaymentCreditNoteNumberPrefix,
+			InvoiceFooter:                 req.PaymentInvoiceFooter,
 		}
 		if err := h.paymentConfigService.UpdatePaymentConfig(c.Request.Context(), paymentReq); err != nil {
 			response.ErrorFrom(c, err)
@@ -2337,6 +2365,18 @@ func (h *SettingHandler) UpdateSettings(c *gin.Context) {
 		PaymentCancelRateLimitMode:                             updatedPaymentCfg.CancelRateLimitMode,
 		PaymentAlipayForceQRCode:                               updatedPaymentCfg.AlipayForceQRCode,
 		PaymentAlipayMobilePrecreateDeepLink:                   updatedPaymentCfg.AlipayMobilePrecreateDeepLink,
+		PaymentAutoRenewEnabled:                                updatedPaymentCfg.AutoRenewEnabled,
+		PaymentAutoRenewLeadDays:                               updatedPaymentCfg.AutoRenewLeadDays,
+		PaymentAutoRenewRetryHours:                             updatedPaymentCfg.AutoRenewRetryHours,
+		PaymentAutoRenewMaxAttempts:                            updatedPaymentCfg.AutoRenewMaxAttempts,
+		PaymentInvoiceEnabled:                                  updatedPaymentCfg.InvoiceEnabled,
+		PaymentInvoiceSellerName:                               updatedPaymentCfg.InvoiceSellerName,
+		PaymentInvoiceSellerAddress:                            updatedPaymentCfg.InvoiceSellerAddress,
+		PaymentInvoiceSellerTaxID:                              updatedPaymentCfg.InvoiceSellerTaxID,
+		PaymentInvoiceSellerEmail:                              updatedPaymentCfg.InvoiceSellerEmail,
+		PaymentInvoiceNumberPrefix:                             updatedPaymentCfg.InvoiceNumberPrefix,
+		PaymentCreditNoteNumberPrefix:                          updatedPaymentCfg.CreditNoteNumberPrefix,
+		PaymentInvoiceFooter:                                   updatedPaymentCfg.InvoiceFooter,
 
 		ChannelMonitorEnabled:                updatedSettings.ChannelMonitorEnabled,
 		ChannelMonitorMode:                   updatedSettings.ChannelMonitorMode,
@@ -2401,7 +2441,13 @@ func hasPaymentFields(req UpdateSettingsRequest) bool {
 		req.PaymentHelpText != nil || req.PaymentCancelRateLimitEnabled != nil ||
 		req.PaymentCancelRateLimitMax != nil || req.PaymentCancelRateLimitWindow != nil ||
 		req.PaymentCancelRateLimitUnit != nil || req.PaymentCancelRateLimitMode != nil ||
-		req.PaymentAlipayForceQRCode != nil || req.PaymentAlipayMobilePrecreateDeepLink != nil
+		req.PaymentAlipayForceQRCode != nil || req.PaymentAlipayMobilePrecreateDeepLink != nil ||
+		req.PaymentAutoRenewEnabled != nil || req.PaymentAutoRenewLeadDays != nil ||
+		req.PaymentAutoRenewRetryHours != nil || req.PaymentAutoRenewMaxAttempts != nil ||
+		req.PaymentInvoiceEnabled != nil || req.PaymentInvoiceSellerName != nil ||
+		req.PaymentInvoiceSellerAddress != nil || req.PaymentInvoiceSellerTaxID != nil ||
+		req.PaymentInvoiceSellerEmail != nil || req.PaymentInvoiceNumberPrefix != nil ||
+		req.PaymentCreditNoteNumberPrefix != nil || req.PaymentInvoiceFooter != nil
 }
 
 // ensureDingTalkSyncAttributes 在保存 settings 后，按 admin 配置的 (attr key, attr name)
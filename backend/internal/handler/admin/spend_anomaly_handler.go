@@ -0,0 +1,53 @@
+package admin
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SpendAnomalyHandler 消费异常检测管理接口。
+type SpendAnomalyHandler struct {
+	spendAnomalyService *service.SpendAnomalyService
+}
+
+// NewSpendAnomalyHandler 创建消费异常处理器。
+func NewSpendAnomalyHandler(spendAnomalyService *service.SpendAnomalyService) *SpendAnomalyHandler {
+	return &SpendAnomalyHandler{spendAnomalyService: spendAnomalyService}
+}
+
+// List 分页查询异常记录，可按 user_id 过滤。
+// GET /api/v1/admin/spend-anomalies
+func (h *SpendAnomalyHandler) List(c *gin.Context) {
+	page, pageSize := response.ParsePagination(c)
+	filter := service.SpendAnomalyFilter{Page: page, PageSize: pageSize}
+	if v := strings.TrimSpace(c.Query("user_id")); v != "" {
+		userID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || userID <= 0 {
+			response.BadRequest(c, "Invalid user_id")
+			return
+		}
+		filter.UserID = userID
+	}
+	events, total, err := h.spendAnomalyService.ListEvents(c.Request.Context(), filter)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Paginated(c, events, total, page, pageSize)
+}
+
+// Detect 立即执行一次检测。
+// POST /api/v1/admin/spend-anomalies/detect
+func (h *SpendAnomalyHandler) Detect(c *gin.Context) {
+	result, err := h.spendAnomalyService.Detect(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, result)
+}
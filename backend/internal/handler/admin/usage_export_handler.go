@@ -0,0 +1,231 @@
+package admin
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UsageExportHandler 用量导出任务与导出记录管理接口。
+type UsageExportHandler struct {
+	usageExportService *service.UsageExportService
+}
+
+// NewUsageExportHandler 创建用量导出处理器。
+func NewUsageExportHandler(usageExportService *service.UsageExportService) *UsageExportHandler {
+	return &UsageExportHandler{usageExportService: usageExportService}
+}
+
+// UsageExportJobRequest 创建/更新导出任务的请求体。
+type UsageExportJobRequest struct {
+	Name      string   `json:"name" binding:"required"`
+	Frequency string   `json:"frequency" binding:"required,oneof=daily weekly monthly"`
+	Format    string   `json:"format" binding:"required,oneof=csv parquet"`
+	Storage   string   `json:"storage" binding:"required,oneof=backup image_storage"`
+	Prefix    string   `json:"prefix"`
+	GroupIDs  []int64  `json:"group_ids"`
+	UserIDs   []int64  `json:"user_ids"`
+	Models    []string `json:"models"`
+	Enabled   *bool    `json:"enabled"`
+}
+
+func (r *UsageExportJobRequest) toInput() service.UsageExportJobInput {
+	enabled := true
+	if r.Enabled != nil {
+		enabled = *r.Enabled
+	}
+	return service.UsageExportJobInput{
+		Name:      r.Name,
+		Frequency: r.Frequency,
+		Format:    r.Format,
+		Storage:   r.Storage,
+		Prefix:    r.Prefix,
+		Filters: service.UsageExportFilter{
+			GroupIDs: r.GroupIDs,
+			UserIDs:  r.UserIDs,
+			Models:   r.Models,
+		},
+		Enabled: enabled,
+	}
+}
+
+// UsageExportBackfillRequest 补跑请求：日期为系统时区，结束日期包含在内。
+type UsageExportBackfillRequest struct {
+	StartDate string `json:"start_date" binding:"required"`
+	EndDate   string `json:"end_date" binding:"required"`
+}
+
+func parseUsageExportID(c *gin.Context, msg string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(c, msg)
+		return 0, false
+	}
+	return id, true
+}
+
+// ListJobs 列出所有导出任务。
+// GET /api/v1/admin/usage-exports/jobs
+func (h *UsageExportHandler) ListJobs(c *gin.Context) {
+	jobs, err := h.usageExportService.ListJobs(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, jobs)
+}
+
+// CreateJob 创建导出任务。
+// POST /api/v1/admin/usage-exports/jobs
+func (h *UsageExportHandler) CreateJob(c *gin.Context) {
+	var req UsageExportJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	job, err := h.usageExportService.CreateJob(c.Request.Context(), req.toInput())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, job)
+}
+
+// UpdateJob 更新导出任务。
+// PUT /api/v1/admin/usage-exports/jobs/:id
+func (h *UsageExportHandler) UpdateJob(c *gin.Context) {
+	id, ok := parseUsageExportID(c, "Invalid job ID")
+	if !ok {
+		return
+	}
+	var req UsageExportJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	job, err := h.usageExportService.UpdateJob(c.Request.Context(), id, req.toInput())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, job)
+}
+
+// DeleteJob 删除导出任务；已生成的导出记录与文件保留。
+// DELETE /api/v1/admin/usage-exports/jobs/:id
+func (h *UsageExportHandler) DeleteJob(c *gin.Context) {
+	id, ok := parseUsageExportID(c, "Invalid job ID")
+	if !ok {
+		return
+	}
+	if err := h.usageExportService.DeleteJob(c.Request.Context(), id); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "Usage export job deleted"})
+}
+
+// RunJob 立即导出最近一个已结束的周期。
+// POST /api/v1/admin/usage-exports/jobs/:id/run
+func (h *UsageExportHandler) RunJob(c *gin.Context) {
+	id, ok := parseUsageExportID(c, "Invalid job ID")
+	if !ok {
+		return
+	}
+	run, err := h.usageExportService.RunJobNow(c.Request.Context(), id)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, run)
+}
+
+// Backfill 重新导出日期范围内的所有已结束周期。
+// POST /api/v1/admin/usage-exports/jobs/:id/backfill
+func (h *UsageExportHandler) Backfill(c *gin.Context) {
+	id, ok := parseUsageExportID(c, "Invalid job ID")
+	if !ok {
+		return
+	}
+	var req UsageExportBackfillRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	from, err := timezone.ParseInLocation("2006-01-02", strings.TrimSpace(req.StartDate))
+	if err != nil {
+		response.BadRequest(c, "Invalid start_date, use YYYY-MM-DD")
+		return
+	}
+	to, err := timezone.ParseInLocation("2006-01-02", strings.TrimSpace(req.EndDate))
+	if err != nil {
+		response.BadRequest(c, "Invalid end_date, use YYYY-MM-DD")
+		return
+	}
+	runs, err := h.usageExportService.Backfill(c.Request.Context(), id, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"runs": runs, "count": len(runs)})
+}
+
+// ListRuns 分页查询任务导出记录，可按 job_id / status 过滤；scope=user 时列出用户自助导出。
+// GET /api/v1/admin/usage-exports/runs
+func (h *UsageExportHandler) ListRuns(c *gin.Context) {
+	page, pageSize := response.ParsePagination(c)
+	filter := service.UsageExportRunFilter{
+		Status:   strings.TrimSpace(c.Query("status")),
+		OnlyJobs: c.Query("scope") != "user",
+		Page:     page,
+		PageSize: pageSize,
+	}
+	if v := strings.TrimSpace(c.Query("job_id")); v != "" {
+		jobID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || jobID <= 0 {
+			response.BadRequest(c, "Invalid job_id")
+			return
+		}
+		filter.JobID = jobID
+	}
+	if v := strings.TrimSpace(c.Query("user_id")); v != "" {
+		userID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || userID <= 0 {
+			response.BadRequest(c, "Invalid user_id")
+			return
+		}
+		filter.UserID = userID
+		filter.OnlyJobs = false
+	}
+	runs, total, err := h.usageExportService.ListRuns(c.Request.Context(), filter)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Paginated(c, runs, total, page, pageSize)
+}
+
+// DownloadRun 返回导出文件的限时下载链接。
+// GET /api/v1/admin/usage-exports/runs/:id/download
+func (h *UsageExportHandler) DownloadRun(c *gin.Context) {
+	id, ok := parseUsageExportID(c, "Invalid export ID")
+	if !ok {
+		return
+	}
+	run, err := h.usageExportService.GetRun(c.Request.Context(), id)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	url, err := h.usageExportService.DownloadURL(c.Request.Context(), run)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"url": url})
+}
@@ -30,18 +30,25 @@ func NewAPIKeyHandler(apiKeyService *service.APIKeyService) *APIKeyHandler {
 
 // CreateAPIKeyRequest represents the create API key request payload
 type CreateAPIKeyRequest struct {
-	Name          string   `json:"name" binding:"required"`
-	GroupID       *int64   `json:"group_id"`        // nullable
-	CustomKey     *string  `json:"custom_key"`      // 可选的自定义key
-	IPWhitelist   []string `json:"ip_whitelist"`    // IP 白名单
-	IPBlacklist   []string `json:"ip_blacklist"`    // IP 黑名单
-	Quota         *float64 `json:"quota"`           // 配额限制 (USD)
-	ExpiresInDays *int     `json:"expires_in_days"` // 过期天数
+	Name        string   `json:"name" binding:"required"`
+	GroupID     *int64   `json:"group_id"`     // nullable
+	CustomKey   *string  `json:"custom_key"`   // 可选的自定义key
+	IPWhitelist []string `json:"ip_whitelist"` // IP 白名单
+	IPBlacklist []string `json:"ip_blacklist"` // IP 黑名单
+	// 模型白/黑名单（glob 如 "claude-sonnet-*"，或 "re:" 前缀的正则）
+	ModelAllowlist []string `json:"model_allowlist"`
+	ModelDenylist  []string `json:"model_denylist"`
+	Quota          *float64 `json:"quota"`           // 配额限制 (USD)
+	ExpiresInDays  *int     `json:"expires_in_days"` // 过期天数
 
 	// Rate limit fields (0 = unlimited)
 	RateLimit5h *float64 `json:"rate_limit_5h"`
 	RateLimit1d *float64 `json:"rate_limit_1d"`
 	RateLimit7d *float64 `json:"rate_limit_7d"`
+
+	// Request throttling (0 = unlimited)
+	RPMLimit       *int `json:"rpm_limit" binding:"omitempty,min=0"`
+	MaxConcurrency *int `json:"max_concurrency" binding:"omitempty,min=0"`
 }
 
 // UpdateAPIKeyRequest represents the update API key request payload
@@ -51,15 +58,22 @@ type UpdateAPIKeyRequest struct {
 	Status      string    `json:"status" binding:"omitempty,oneof=active inactive"`
 	IPWhitelist *[]string `json:"ip_whitelist"` // IP 白名单（nil 不修改，空数组清空）
 	IPBlacklist *[]string `json:"ip_blacklist"` // IP 黑名单（nil 不修改，空数组清空）
-	Quota       *float64  `json:"quota"`        // 配额限制 (USD), 0=无限制
-	ExpiresAt   *string   `json:"expires_at"`   // 过期时间 (ISO 8601)
-	ResetQuota  *bool     `json:"reset_quota"`  // 重置已用配额
+	// 模型白/黑名单（nil 不修改，空数组清空）
+	ModelAllowlist *[]string `json:"model_allowlist"`
+	ModelDenylist  *[]string `json:"model_denylist"`
+	Quota          *float64  `json:"quota"`       // 配额限制 (USD), 0=无限制
+	ExpiresAt      *string   `json:"expires_at"`  // 过期时间 (ISO 8601)
+	ResetQuota     *bool     `json:"reset_quota"` // 重置已用配额
 
 	// Rate limit fields (nil = no change, 0 = unlimited)
 	RateLimit5h         *float64 `json:"rate_limit_5h"`
 	RateLimit1d         *float64 `json:"rate_limit_1d"`
 	RateLimit7d         *float64 `json:"rate_limit_7d"`
 	ResetRateLimitUsage *bool    `json:"reset_rate_limit_usage"` // 重置限速用量
+
+	// Request throttling (nil = no change, 0 = unlimited)
+	RPMLimit       *int `json:"rpm_limit" binding:"omitempty,min=0"`
+	MaxConcurrency *int `json:"max_concurrency" binding:"omitempty,min=0"`
 }
 
 // List handles listing user's API keys with pagination
@@ -154,12 +168,14 @@ func (h *APIKeyHandler) Create(c *gin.Context) {
 	}
 
 	svcReq := service.CreateAPIKeyRequest{
-		Name:          req.Name,
-		GroupID:       req.GroupID,
-		CustomKey:     req.CustomKey,
-		IPWhitelist:   req.IPWhitelist,
-		IPBlacklist:   req.IPBlacklist,
-		ExpiresInDays: req.ExpiresInDays,
+		Name:           req.Name,
+		GroupID:        req.GroupID,
+		CustomKey:      req.CustomKey,
+		IPWhitelist:    req.IPWhitelist,
+		IPBlacklist:    req.IPBlacklist,
+		ModelAllowlist: req.ModelAllowlist,
+		ModelDenylist:  req.ModelDenylist,
+		ExpiresInDays:  req.ExpiresInDays,
 	}
 	if req.Quota != nil {
 		svcReq.Quota = *req.Quota
@@ -173,6 +189,12 @@ func (h *APIKeyHandler) Create(c *gin.Context) {
 	if req.RateLimit7d != nil {
 		svcReq.RateLimit7d = *req.RateLimit7d
 	}
+	if req.RPMLimit != nil {
+		svcReq.RPMLimit = *req.RPMLimit
+	}
+	if req.MaxConcurrency != nil {
+		svcReq.MaxConcurrency = *req.MaxConcurrency
+	}
 
 	executeUserIdempotentJSON(c, "user.api_keys.create", req, service.DefaultWriteIdempotencyTTL(), func(ctx context.Context) (any, error) {
 		key, err := h.apiKeyService.Create(ctx, subject.UserID, svcReq)
@@ -207,12 +229,16 @@ func (h *APIKeyHandler) Update(c *gin.Context) {
 	svcReq := service.UpdateAPIKeyRequest{
 		IPWhitelist:         req.IPWhitelist,
 		IPBlacklist:         req.IPBlacklist,
+		ModelAllowlist:      req.ModelAllowlist,
+		ModelDenylist:       req.ModelDenylist,
 		Quota:               req.Quota,
 		ResetQuota:          req.ResetQuota,
 		RateLimit5h:         req.RateLimit5h,
 		RateLimit1d:         req.RateLimit1d,
 		RateLimit7d:         req.RateLimit7d,
 		ResetRateLimitUsage: req.ResetRateLimitUsage,
+		RPMLimit:            req.RPMLimit,
+		MaxConcurrency:      req.MaxConcurrency,
 	}
 	if req.Name != "" {
 		svcReq.Name = &req.Name
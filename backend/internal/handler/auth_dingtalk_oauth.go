@@ -191,7 +191,8 @@ func (h *AuthHandler) findDingTalkCompatEmailUser(ctx context.Context, email str
 		strings.HasSuffix(email, service.DingTalkConnectSyntheticEmailDomain) ||
 		strings.HasSuffix(email, service.LinuxDoConnectSyntheticEmailDomain) ||
 		strings.HasSuffix(email, service.OIDCConnectSyntheticEmailDomain) ||
-		strings.HasSuffix(email, service.WeChatConnectSyntheticEmailDomain) {
+		strings.HasSuffix(email, service.WeChatConnectSyntheticEmailDomain) ||
+		strings.HasSuffix(email, service.SAMLConnectSyntheticEmailDomain) {
 		return nil, nil
 	}
 
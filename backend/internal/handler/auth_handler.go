@@ -30,6 +30,8 @@ type AuthHandler struct {
 
 	dingTalkClientInstance *DingTalkClient
 	dingTalkClientMu       sync.Mutex
+
+	samlReplayCache service.SAMLAssertionReplayCache
 }
 
 // NewAuthHandler creates a new AuthHandler
@@ -543,6 +543,7 @@ func (h *AuthHandler) findOAuthIdentityUser(ctx context.Context, identity servic
 func (h *AuthHandler) BindLinuxDoOAuthLogin(c *gin.Context) { h.bindPendingOAuthLogin(c, "linuxdo") }
 func (h *AuthHandler) BindOIDCOAuthLogin(c *gin.Context)    { h.bindPendingOAuthLogin(c, "oidc") }
 func (h *AuthHandler) BindWeChatOAuthLogin(c *gin.Context)  { h.bindPendingOAuthLogin(c, "wechat") }
+func (h *AuthHandler) BindSAMLOAuthLogin(c *gin.Context)    { h.bindPendingOAuthLogin(c, "saml") }
 func (h *AuthHandler) BindPendingOAuthLogin(c *gin.Context) { h.bindPendingOAuthLogin(c, "") }
 
 func (h *AuthHandler) CreateLinuxDoOAuthAccount(c *gin.Context) {
@@ -555,6 +556,8 @@ func (h *AuthHandler) CreateWeChatOAuthAccount(c *gin.Context) {
 	h.createPendingOAuthAccount(c, "wechat")
 }
 
+func (h *AuthHandler) CreateSAMLOAuthAccount(c *gin.Context) { h.createPendingOAuthAccount(c, "saml") }
+
 func (h *AuthHandler) CreatePendingOAuthAccount(c *gin.Context) {
 	h.createPendingOAuthAccount(c, "")
 }
@@ -1269,6 +1272,12 @@ func applyPendingOAuthBindingTx(
 		}
 	}
 
+	if strings.EqualFold(strings.TrimSpace(session.ProviderType), "saml") {
+		if err := applySAMLAllowedGroups(ctx, tx.Client(), targetUserID, samlAllowedGroupIDsFromClaims(session.UpstreamIdentityClaims)); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
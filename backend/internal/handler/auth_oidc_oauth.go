@@ -526,7 +526,8 @@ func (h *AuthHandler) findOIDCCompatEmailUser(ctx context.Context, email string)
 		strings.HasSuffix(email, service.LinuxDoConnectSyntheticEmailDomain) ||
 		strings.HasSuffix(email, service.OIDCConnectSyntheticEmailDomain) ||
 		strings.HasSuffix(email, service.WeChatConnectSyntheticEmailDomain) ||
-		strings.HasSuffix(email, service.DingTalkConnectSyntheticEmailDomain) {
+		strings.HasSuffix(email, service.DingTalkConnectSyntheticEmailDomain) ||
+		strings.HasSuffix(email, service.SAMLConnectSyntheticEmailDomain) {
 		return nil, nil
 	}
 
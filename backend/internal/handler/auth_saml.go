@@ -0,0 +1,548 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	dbent "github.com/Wei-Shaw/sub2api/ent"
+	"github.com/Wei-Shaw/sub2api/ent/group"
+	"github.com/Wei-Shaw/sub2api/ent/userallowedgroup"
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/oauth"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/saml"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	samlCookiePath           = "/api/v1/auth/oauth/saml"
+	samlStateCookieName      = "saml_sso_state"
+	samlCookieMaxAgeSec      = 10 * 60 // 10 minutes
+	samlDefaultRedirectTo    = "/dashboard"
+	samlDefaultFrontendCB    = "/auth/saml/callback"
+	samlAllowedGroupIDsClaim = "saml_allowed_group_ids"
+)
+
+// 未配置属性名时依次尝试的常见属性（含 ADFS/Azure AD 的 claim URI）。
+var (
+	samlDefaultEmailAttributes = []string{
+		"email", "mail", "emailAddress",
+		"http://schemas.xmlsoap.org/ws/2005/05/identity/claims/emailaddress",
+		"urn:oid:0.9.2342.19200300.100.1.3",
+	}
+	samlDefaultUsernameAttributes = []string{
+		"username", "uid", "preferred_username",
+		"http://schemas.xmlsoap.org/ws/2005/05/identity/claims/name",
+		"urn:oid:0.9.2342.19200300.100.1.1",
+	}
+	samlDefaultDisplayNameAttributes = []string{
+		"displayName", "name", "cn",
+		"http://schemas.microsoft.com/identity/claims/displayname",
+		"urn:oid:2.16.840.1.113730.3.1.241",
+	}
+	samlDefaultGroupsAttributes = []string{
+		"groups", "memberOf", "Group",
+		"http://schemas.microsoft.com/ws/2008/06/identity/claims/groups",
+	}
+)
+
+// samlFlowState 保存 SP-initiated 登录在浏览器侧的状态。
+// IdP 以跨站 POST 回调 ACS，SameSite=Lax 的 cookie 不会被携带，因此本 cookie 在 HTTPS 下使用 SameSite=None。
+type samlFlowState struct {
+	RequestID      string `json:"request_id"`
+	RelayState     string `json:"relay_state"`
+	RedirectTo     string `json:"redirect"`
+	Intent         string `json:"intent"`
+	BrowserSession string `json:"browser_session"`
+	BindUser       string `json:"bind_user,omitempty"`
+}
+
+// SAMLOAuthStart 启动 SP-initiated SAML 登录（HTTP-Redirect 绑定）。
+// GET /api/v1/auth/oauth/saml/start?redirect=/dashboard
+func (h *AuthHandler) SAMLOAuthStart(c *gin.Context) {
+	if !h.requireActionCaptchaForOAuthLoginStart(c) {
+		return
+	}
+	_, sp, err := h.getSAMLServiceProvider()
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	relayState, err := oauth.GenerateState()
+	if err != nil {
+		response.ErrorFrom(c, infraerrors.InternalServer("OAUTH_STATE_GEN_FAILED", "failed to generate oauth state").WithCause(err))
+		return
+	}
+	browserSessionKey, err := generateOAuthPendingBrowserSession()
+	if err != nil {
+		response.ErrorFrom(c, infraerrors.InternalServer("OAUTH_BROWSER_SESSION_GEN_FAILED", "failed to generate oauth browser session").WithCause(err))
+		return
+	}
+
+	redirectTo := sanitizeFrontendRedirectPath(c.Query("redirect"))
+	if redirectTo == "" {
+		redirectTo = samlDefaultRedirectTo
+	}
+	state := samlFlowState{
+		RelayState:     relayState,
+		RedirectTo:     redirectTo,
+		Intent:         normalizeOAuthIntent(c.Query("intent")),
+		BrowserSession: browserSessionKey,
+	}
+	if state.Intent == oauthIntentBindCurrentUser {
+		bindCookieValue, err := h.buildOAuthBindUserCookieFromContext(c)
+		if err != nil {
+			response.ErrorFrom(c, err)
+			return
+		}
+		state.BindUser = bindCookieValue
+	}
+
+	authnRequest, err := sp.NewAuthnRequest(relayState)
+	if err != nil {
+		response.ErrorFrom(c, infraerrors.InternalServer("OAUTH_BUILD_URL_FAILED", "failed to build saml authn request").WithCause(err))
+		return
+	}
+	state.RequestID = authnRequest.ID
+
+	secureCookie := isRequestHTTPS(c)
+	if err := samlSetStateCookie(c, state, secureCookie); err != nil {
+		response.ErrorFrom(c, infraerrors.InternalServer("OAUTH_STATE_GEN_FAILED", "failed to store saml state").WithCause(err))
+		return
+	}
+	setOAuthPendingBrowserCookie(c, browserSessionKey, secureCookie)
+	clearOAuthPendingSessionCookie(c, secureCookie)
+
+	respondOAuthStart(c, authnRequest.RedirectURL)
+}
+
+// SAMLAssertionConsumerService 处理 IdP 以 HTTP-POST 绑定提交的 SAMLResponse，
+// 校验通过后接入与 OIDC 相同的 pending identity 流程。
+// POST /api/v1/auth/oauth/saml/acs
+func (h *AuthHandler) SAMLAssertionConsumerService(c *gin.Context) {
+	cfg, sp, err := h.getSAMLServiceProvider()
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	frontendCallback := strings.TrimSpace(cfg.FrontendRedirectURL)
+	if frontendCallback == "" {
+		frontendCallback = samlDefaultFrontendCB
+	}
+
+	secureCookie := isRequestHTTPS(c)
+	state, hasState := samlReadStateCookie(c)
+	defer samlClearStateCookie(c, secureCookie)
+
+	samlResponse := strings.TrimSpace(c.PostForm("SAMLResponse"))
+	if samlResponse == "" {
+		redirectOAuthError(c, frontendCallback, "missing_params", "missing SAMLResponse", "")
+		return
+	}
+	relayState := strings.TrimSpace(c.PostForm("RelayState"))
+
+	// RelayState 与 cookie 一致才视为本浏览器发起的请求；否则按 IdP-initiated 处理，
+	// 由 ParseResponse 要求响应不带 InResponseTo 且配置允许 IdP-initiated。
+	requestID := ""
+	redirectTo := ""
+	intent := oauthIntentLogin
+	browserSessionKey := ""
+	solicited := hasState && state.RelayState != "" && relayState == state.RelayState
+	if solicited {
+		requestID = state.RequestID
+		redirectTo = sanitizeFrontendRedirectPath(state.RedirectTo)
+		intent = normalizeOAuthIntent(state.Intent)
+		browserSessionKey = strings.TrimSpace(state.BrowserSession)
+	} else {
+		redirectTo = sanitizeFrontendRedirectPath(relayState)
+	}
+	if redirectTo == "" {
+		redirectTo = samlDefaultRedirectTo
+	}
+
+	assertion, err := sp.ParseResponse(samlResponse, requestID)
+	if err != nil {
+		log.Printf("[SAML] response validation failed: solicited=%t err=%v", solicited, err)
+		redirectOAuthError(c, frontendCallback, "invalid_saml_response", "failed to validate saml response", "")
+		return
+	}
+	if !h.samlMarkAssertionUsed(c.Request.Context(), assertion) {
+		log.Printf("[SAML] assertion replay rejected: id=%s", assertion.ID)
+		redirectOAuthError(c, frontendCallback, "invalid_saml_response", "saml assertion has already been used", "")
+		return
+	}
+
+	if browserSessionKey == "" {
+		browserSessionKey, err = generateOAuthPendingBrowserSession()
+		if err != nil {
+			redirectOAuthError(c, frontendCallback, "session_error", "failed to generate oauth browser session", "")
+			return
+		}
+		setOAuthPendingBrowserCookie(c, browserSessionKey, secureCookie)
+	}
+
+	issuer := assertion.Issuer
+	subject := assertion.NameID
+	email := samlSyntheticEmail(issuer, subject)
+	compatEmail := strings.ToLower(samlAttribute(assertion, cfg.EmailAttribute, samlDefaultEmailAttributes))
+	if compatEmail == "" && assertion.NameIDFormat == saml.NameIDFormatEmail {
+		compatEmail = strings.ToLower(strings.TrimSpace(subject))
+	}
+	username := firstNonEmpty(
+		samlAttribute(assertion, cfg.UsernameAttribute, samlDefaultUsernameAttributes),
+		func() string {
+			local, _, _ := strings.Cut(compatEmail, "@")
+			return local
+		}(),
+		samlFallbackUsername(subject),
+	)
+	displayName := firstNonEmpty(samlAttribute(assertion, cfg.DisplayNameAttribute, samlDefaultDisplayNameAttributes), username)
+	emailVerified := cfg.TrustEmail && compatEmail != ""
+	allowedGroupIDs := samlMappedGroupIDs(cfg, assertion)
+
+	identityRef := service.PendingAuthIdentityKey{
+		ProviderType:    "saml",
+		ProviderKey:     issuer,
+		ProviderSubject: subject,
+	}
+	upstreamClaims := map[string]any{
+		"email":                  email,
+		"username":               username,
+		"subject":                subject,
+		"issuer":                 issuer,
+		"name_id_format":         assertion.NameIDFormat,
+		"session_index":          assertion.SessionIndex,
+		"email_verified":         emailVerified,
+		"provider_fallback":      strings.TrimSpace(cfg.ProviderName),
+		"suggested_display_name": displayName,
+		"suggested_avatar_url":   "",
+	}
+	if len(allowedGroupIDs) > 0 {
+		upstreamClaims[samlAllowedGroupIDsClaim] = allowedGroupIDs
+	}
+	if compatEmail != "" && !strings.EqualFold(compatEmail, email) {
+		upstreamClaims["compat_email"] = compatEmail
+	}
+
+	if intent == oauthIntentBindCurrentUser {
+		targetUserID, err := parseOAuthBindUserCookieValue(state.BindUser, h.oauthBindCookieSecret())
+		if err != nil {
+			redirectOAuthError(c, frontendCallback, "invalid_state", "invalid oauth bind target", "")
+			return
+		}
+		if err := h.createOAuthPendingSession(c, oauthPendingSessionPayload{
+			Intent:                 oauthIntentBindCurrentUser,
+			Identity:               identityRef,
+			TargetUserID:           &targetUserID,
+			ResolvedEmail:          email,
+			RedirectTo:             redirectTo,
+			BrowserSessionKey:      browserSessionKey,
+			UpstreamIdentityClaims: upstreamClaims,
+			CompletionResponse: map[string]any{
+				"redirect": redirectTo,
+			},
+		}); err != nil {
+			redirectOAuthError(c, frontendCallback, "session_error", "failed to continue oauth bind", "")
+			return
+		}
+		redirectToFrontendCallback(c, frontendCallback)
+		return
+	}
+
+	existingIdentityUser, err := h.findOAuthIdentityUser(c.Request.Context(), identityRef)
+	if err != nil {
+		redirectOAuthError(c, frontendCallback, "session_error", infraerrors.Reason(err), infraerrors.Message(err))
+		return
+	}
+	if existingIdentityUser != nil {
+		// 组映射只追加：每次登录都按 IdP 当前下发的组补齐可用分组。
+		if err := applySAMLAllowedGroups(c.Request.Context(), h.entClient(), existingIdentityUser.ID, allowedGroupIDs); err != nil {
+			log.Printf("[SAML] apply group mappings failed: user_id=%d err=%v", existingIdentityUser.ID, err)
+		}
+		if err := h.createOAuthPendingSession(c, oauthPendingSessionPayload{
+			Intent:                 oauthIntentLogin,
+			Identity:               identityRef,
+			TargetUserID:           &existingIdentityUser.ID,
+			ResolvedEmail:          existingIdentityUser.Email,
+			RedirectTo:             redirectTo,
+			BrowserSessionKey:      browserSessionKey,
+			UpstreamIdentityClaims: upstreamClaims,
+			CompletionResponse: map[string]any{
+				"redirect": redirectTo,
+			},
+		}); err != nil {
+			redirectOAuthError(c, frontendCallback, "session_error", "failed to continue oauth login", "")
+			return
+		}
+		redirectToFrontendCallback(c, frontendCallback)
+		return
+	}
+
+	compatEmailUser, err := h.findOIDCCompatEmailUser(c.Request.Context(), compatEmail)
+	if err != nil {
+		redirectOAuthError(c, frontendCallback, "session_error", infraerrors.Reason(err), infraerrors.Message(err))
+		return
+	}
+
+	if compatEmailUser == nil && emailVerified {
+		if handled := h.tryOIDCVerifiedEmailFastPath(
+			c,
+			frontendCallback,
+			redirectTo,
+			identityRef,
+			compatEmail,
+			username,
+			upstreamClaims,
+		); handled {
+			if user, err := h.findOAuthIdentityUser(c.Request.Context(), identityRef); err == nil && user != nil {
+				if err := applySAMLAllowedGroups(c.Request.Context(), h.entClient(), user.ID, allowedGroupIDs); err != nil {
+					log.Printf("[SAML] apply group mappings failed: user_id=%d err=%v", user.ID, err)
+				}
+			}
+			return
+		}
+	}
+
+	if err := h.createOIDCOAuthChoicePendingSession(
+		c,
+		identityRef,
+		email,
+		email,
+		redirectTo,
+		browserSessionKey,
+		upstreamClaims,
+		compatEmail,
+		compatEmailUser,
+		h.isForceEmailOnThirdPartySignup(c.Request.Context()),
+	); err != nil {
+		redirectOAuthError(c, frontendCallback, "session_error", "failed to continue oauth login", "")
+		return
+	}
+	redirectToFrontendCallback(c, frontendCallback)
+}
+
+// SAMLMetadata 输出 SP 元数据，供 IdP 管理员导入。
+// GET /api/v1/auth/oauth/saml/metadata
+func (h *AuthHandler) SAMLMetadata(c *gin.Context) {
+	_, sp, err := h.getSAMLServiceProvider()
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	metadata, err := sp.Metadata()
+	if err != nil {
+		response.ErrorFrom(c, infraerrors.InternalServer("SAML_METADATA_FAILED", "failed to build saml metadata").WithCause(err))
+		return
+	}
+	c.Data(http.StatusOK, "application/samlmetadata+xml; charset=utf-8", metadata)
+}
+
+func (h *AuthHandler) getSAMLServiceProvider() (config.SAMLConnectConfig, *saml.ServiceProvider, error) {
+	if h == nil || h.cfg == nil {
+		return config.SAMLConnectConfig{}, nil, infraerrors.ServiceUnavailable("CONFIG_NOT_READY", "config not loaded")
+	}
+	cfg := h.cfg.SAML
+	if !cfg.Enabled {
+		return config.SAMLConnectConfig{}, nil, infraerrors.NotFound("OAUTH_DISABLED", "oauth login is disabled")
+	}
+	certs, err := saml.ParseCertificates(cfg.IdPCertificate)
+	if err != nil {
+		return config.SAMLConnectConfig{}, nil, infraerrors.InternalServer("OAUTH_CONFIG_INVALID", "saml idp certificate is invalid").WithCause(err)
+	}
+	sp := &saml.ServiceProvider{
+		EntityID:          cfg.SPEntityID,
+		ACSURL:            cfg.ACSURL,
+		IdPEntityID:       cfg.IdPEntityID,
+		IdPSSOURL:         cfg.IdPSSOURL,
+		IdPCertificates:   certs,
+		NameIDFormat:      cfg.NameIDFormat,
+		AllowIdPInitiated: cfg.AllowIdPInitiated,
+		ClockSkew:         time.Duration(cfg.ClockSkewSeconds) * time.Second,
+	}
+	if err := sp.Validate(); err != nil {
+		return config.SAMLConnectConfig{}, nil, infraerrors.InternalServer("OAUTH_CONFIG_INVALID", err.Error())
+	}
+	return cfg, sp, nil
+}
+
+// samlMarkAssertionUsed 在共享缓存中登记已消费的断言，拒绝在有效期内重放同一断言。
+// 缓存不可用时按失败处理，避免退化为可重放。
+func (h *AuthHandler) samlMarkAssertionUsed(ctx context.Context, assertion *saml.Assertion) bool {
+	if assertion == nil || assertion.ID == "" || h.samlReplayCache == nil {
+		return false
+	}
+	ttl := time.Until(assertion.ExpiresAt)
+	if ttl < time.Minute {
+		ttl = time.Minute
+	}
+	first, err := h.samlReplayCache.MarkSAMLAssertionUsed(ctx, assertion.Issuer, assertion.ID, ttl)
+	if err != nil {
+		log.Printf("[SAML] replay cache unavailable: id=%s err=%v", assertion.ID, err)
+		return false
+	}
+	return first
+}
+
+func samlAttribute(assertion *saml.Assertion, configured string, defaults []string) string {
+	if configured = strings.TrimSpace(configured); configured != "" {
+		return assertion.FirstAttribute(configured)
+	}
+	return assertion.FirstAttribute(defaults...)
+}
+
+// samlMappedGroupIDs 根据 IdP 下发的组属性匹配 group_mappings，返回去重排序后的本地分组 ID。
+func samlMappedGroupIDs(cfg config.SAMLConnectConfig, assertion *saml.Assertion) []int64 {
+	if len(cfg.GroupMappings) == 0 || assertion == nil {
+		return nil
+	}
+	names := []string{strings.TrimSpace(cfg.GroupsAttribute)}
+	if names[0] == "" {
+		names = samlDefaultGroupsAttributes
+	}
+	idpGroups := make(map[string]struct{})
+	for _, name := range names {
+		for _, v := range assertion.Attributes[name] {
+			if v = strings.TrimSpace(v); v != "" {
+				idpGroups[strings.ToLower(v)] = struct{}{}
+			}
+		}
+	}
+
+	seen := make(map[int64]struct{})
+	var out []int64
+	for _, m := range cfg.GroupMappings {
+		if _, ok := idpGroups[strings.ToLower(strings.TrimSpace(m.IdPGroup))]; !ok {
+			continue
+		}
+		for _, id := range m.GroupIDs {
+			if _, dup := seen[id]; id > 0 && !dup {
+				seen[id] = struct{}{}
+				out = append(out, id)
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+// samlAllowedGroupIDsFromClaims 读取 pending session 中保存的映射分组；
+// 经过 JSON 持久化后数字会变为 float64。
+func samlAllowedGroupIDsFromClaims(claims map[string]any) []int64 {
+	switch raw := claims[samlAllowedGroupIDsClaim].(type) {
+	case []int64:
+		return raw
+	case []any:
+		out := make([]int64, 0, len(raw))
+		for _, v := range raw {
+			switch n := v.(type) {
+			case float64:
+				out = append(out, int64(n))
+			case int64:
+				out = append(out, n)
+			case json.Number:
+				if id, err := n.Int64(); err == nil {
+					out = append(out, id)
+				}
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+// applySAMLAllowedGroups 将映射得到的分组追加到用户可用分组，已删除的分组会被忽略。
+func applySAMLAllowedGroups(ctx context.Context, client *dbent.Client, userID int64, groupIDs []int64) error {
+	if client == nil || userID <= 0 || len(groupIDs) == 0 {
+		return nil
+	}
+	existing, err := client.Group.Query().Where(group.IDIn(groupIDs...)).IDs(ctx)
+	if err != nil {
+		return err
+	}
+	for _, groupID := range existing {
+		err := client.UserAllowedGroup.Create().
+			SetUserID(userID).
+			SetGroupID(groupID).
+			OnConflictColumns(userallowedgroup.FieldUserID, userallowedgroup.FieldGroupID).
+			DoNothing().
+			Exec(ctx)
+		if err != nil && !dbent.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func samlSyntheticEmail(issuer, subject string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(issuer)) + "\x1f" + strings.TrimSpace(subject)))
+	return "saml-" + hex.EncodeToString(sum[:16]) + service.SAMLConnectSyntheticEmailDomain
+}
+
+func samlFallbackUsername(subject string) string {
+	subject = strings.TrimSpace(subject)
+	if subject == "" {
+		return "saml_user"
+	}
+	sum := sha256.Sum256([]byte(subject))
+	return "saml_" + hex.EncodeToString(sum[:])[:12]
+}
+
+func samlStateCookieSameSite(secure bool) http.SameSite {
+	if secure {
+		return http.SameSiteNoneMode
+	}
+	return http.SameSiteLaxMode
+}
+
+func samlSetStateCookie(c *gin.Context, state samlFlowState, secure bool) error {
+	raw, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     samlStateCookieName,
+		Value:    encodeCookieValue(string(raw)),
+		Path:     samlCookiePath,
+		MaxAge:   samlCookieMaxAgeSec,
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: samlStateCookieSameSite(secure),
+	})
+	return nil
+}
+
+func samlReadStateCookie(c *gin.Context) (samlFlowState, bool) {
+	raw, err := readCookieDecoded(c, samlStateCookieName)
+	if err != nil || raw == "" {
+		return samlFlowState{}, false
+	}
+	var state samlFlowState
+	if err := json.Unmarshal([]byte(raw), &state); err != nil {
+		return samlFlowState{}, false
+	}
+	return state, true
+}
+
+func samlClearStateCookie(c *gin.Context, secure bool) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     samlStateCookieName,
+		Value:    "",
+		Path:     samlCookiePath,
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: samlStateCookieSameSite(secure),
+	})
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/saml"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSAMLMappedGroupIDs(t *testing.T) {
+	assertion := &saml.Assertion{Attributes: map[string][]string{
+		"groups":   {"Engineering", " admins "},
+		"memberOf": {"cn=ops"},
+	}}
+	cfg := config.SAMLConnectConfig{GroupMappings: []config.SAMLGroupMapping{
+		{IdPGroup: "engineering", GroupIDs: []int64{5, 2}},
+		{IdPGroup: "admins", GroupIDs: []int64{2, 9}},
+		{IdPGroup: "sales", GroupIDs: []int64{7}},
+		{IdPGroup: "cn=ops", GroupIDs: []int64{11}},
+	}}
+
+	// 未指定属性时合并所有常见组属性，结果去重并排序。
+	require.Equal(t, []int64{2, 5, 9, 11}, samlMappedGroupIDs(cfg, assertion))
+
+	cfg.GroupsAttribute = "memberOf"
+	require.Equal(t, []int64{11}, samlMappedGroupIDs(cfg, assertion))
+
+	cfg.GroupMappings = nil
+	require.Empty(t, samlMappedGroupIDs(cfg, assertion))
+}
+
+func TestSAMLAllowedGroupIDsFromClaims(t *testing.T) {
+	require.Equal(t, []int64{3, 4}, samlAllowedGroupIDsFromClaims(map[string]any{
+		samlAllowedGroupIDsClaim: []int64{3, 4},
+	}))
+
+	// pending session 的 claims 经 JSON 持久化后回读。
+	raw, err := json.Marshal(map[string]any{samlAllowedGroupIDsClaim: []int64{8, 13}})
+	require.NoError(t, err)
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(raw, &decoded))
+	require.Equal(t, []int64{8, 13}, samlAllowedGroupIDsFromClaims(decoded))
+
+	require.Nil(t, samlAllowedGroupIDsFromClaims(map[string]any{}))
+}
+
+func TestSAMLSyntheticIdentityValues(t *testing.T) {
+	email := samlSyntheticEmail("https://idp.example.com", "user-1")
+	require.True(t, strings.HasPrefix(email, "saml-"))
+	require.True(t, strings.HasSuffix(email, service.SAMLConnectSyntheticEmailDomain))
+	require.Equal(t, email, samlSyntheticEmail("HTTPS://IDP.example.com ", "user-1"))
+	require.NotEqual(t, email, samlSyntheticEmail("https://idp.example.com", "user-2"))
+
+	require.Equal(t, "saml_user", samlFallbackUsername(" "))
+	require.Len(t, samlFallbackUsername("user-1"), len("saml_")+12)
+}
+
+type samlReplayCacheStub struct {
+	used map[string]bool
+	ttl  time.Duration
+	err  error
+}
+
+func (s *samlReplayCacheStub) MarkSAMLAssertionUsed(_ context.Context, issuer, assertionID string, ttl time.Duration) (bool, error) {
+	if s.err != nil {
+		return false, s.err
+	}
+	s.ttl = ttl
+	key := issuer + "|" + assertionID
+	if s.used[key] {
+		return false, nil
+	}
+	s.used[key] = true
+	return true, nil
+}
+
+func TestSAMLMarkAssertionUsed(t *testing.T) {
+	ctx := context.Background()
+	assertion := &saml.Assertion{ID: "_a1", Issuer: "https://idp.example.com", ExpiresAt: time.Now().Add(5 * time.Minute)}
+
+	// 未注入共享缓存时拒绝登录，而不是放行可重放的断言。
+	require.False(t, (&AuthHandler{}).samlMarkAssertionUsed(ctx, assertion))
+
+	cache := &samlReplayCacheStub{used: map[string]bool{}}
+	h := &AuthHandler{samlReplayCache: cache}
+	require.True(t, h.samlMarkAssertionUsed(ctx, assertion))
+	require.InDelta(t, (5 * time.Minute).Seconds(), cache.ttl.Seconds(), 5)
+	require.False(t, h.samlMarkAssertionUsed(ctx, assertion))
+
+	expired := &saml.Assertion{ID: "_a2", Issuer: assertion.Issuer, ExpiresAt: time.Now().Add(-time.Second)}
+	require.True(t, h.samlMarkAssertionUsed(ctx, expired))
+	require.Equal(t, time.Minute, cache.ttl)
+
+	require.False(t, (&AuthHandler{samlReplayCache: &samlReplayCacheStub{err: errors.New("redis down")}}).samlMarkAssertionUsed(ctx, assertion))
+}
@@ -35,7 +35,16 @@ func clientRequestedModel(c *gin.Context, fallback string) string {
 }
 
 func clientRequestedUsageFields(c *gin.Context, mapping service.ChannelMappingResult, fallbackModel, upstreamModel string) service.ChannelUsageFields {
-	return mapping.ToUsageFields(clientRequestedModel(c, fallbackModel), upstreamModel)
+	fields := mapping.ToUsageFields(clientRequestedModel(c, fallbackModel), upstreamModel)
+	// 经过模型降级链时在映射链前记录已失败的模型，requested_model 保持为实际使用的模型。
+	if trail := modelFallbackTrail(c); trail != "" {
+		chain := fields.ModelMappingChain
+		if chain == "" {
+			chain = fields.OriginalModel
+		}
+		fields.ModelMappingChain = trail + chain
+	}
+	return fields
 }
 
 func runContentModeration(c *gin.Context, reqLog *zap.Logger, svc *service.ContentModerationService, apiKey *service.APIKey, subject middleware2.AuthSubject, protocol string, model string, body []byte) *service.ContentModerationDecision {
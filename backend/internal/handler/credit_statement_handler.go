@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreditStatementHandler 用户查看自己的授信月度账单
+type CreditStatementHandler struct {
+	creditStatementService *service.CreditStatementService
+}
+
+// NewCreditStatementHandler creates a new user credit statement handler
+func NewCreditStatementHandler(creditStatementService *service.CreditStatementService) *CreditStatementHandler {
+	return &CreditStatementHandler{creditStatementService: creditStatementService}
+}
+
+// List 分页查询当前用户的账单
+// GET /api/v1/user/credit-statements
+func (h *CreditStatementHandler) List(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not found in context")
+		return
+	}
+	page, pageSize := response.ParsePagination(c)
+	items, total, err := h.creditStatementService.List(c.Request.Context(), service.CreditStatementFilter{
+		UserID:   subject.UserID,
+		Status:   c.Query("status"),
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Paginated(c, items, total, page, pageSize)
+}
+
+// Get 查询当前用户的单张账单（含分组/模型明细）
+// GET /api/v1/user/credit-statements/:id
+func (h *CreditStatementHandler) Get(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not found in context")
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(c, "Invalid statement ID")
+		return
+	}
+	st, err := h.creditStatementService.GetForUser(c.Request.Context(), subject.UserID, id)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, st)
+}
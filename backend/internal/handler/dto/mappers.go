@@ -31,6 +31,7 @@ func UserFromServiceShallow(u *service.User) *User {
 		BalanceNotifyExtraEmails:   NotifyEmailEntriesFromService(u.BalanceNotifyExtraEmails),
 		TotalRecharged:             u.TotalRecharged,
 		RPMLimit:                   u.RPMLimit,
+		CreditLimit:                u.CreditLimit,
 		DeletedAt:                  u.DeletedAt,
 	}
 }
@@ -88,6 +89,8 @@ func APIKeyFromService(k *service.APIKey) *APIKey {
 		Status:             k.Status,
 		IPWhitelist:        k.IPWhitelist,
 		IPBlacklist:        k.IPBlacklist,
+		ModelAllowlist:     k.ModelAllowlist,
+		ModelDenylist:      k.ModelDenylist,
 		LastUsedAt:         k.LastUsedAt,
 		LastUsedIP:         k.LastUsedIP,
 		Quota:              k.Quota,
@@ -105,6 +108,9 @@ func APIKeyFromService(k *service.APIKey) *APIKey {
 		Window5hStart:      k.Window5hStart,
 		Window1dStart:      k.Window1dStart,
 		Window7dStart:      k.Window7dStart,
+		RPMLimit:           k.RPMLimit,
+		MaxConcurrency:     k.MaxConcurrency,
+		CurrentRPM:         k.CurrentRPM,
 		User:               UserFromServiceShallow(k.User),
 		Group:              GroupFromServiceShallow(k.Group),
 	}
@@ -208,6 +214,9 @@ func groupFromServiceBase(g *service.Group) Group {
 		AudioRealtimePricePerMin:        g.AudioRealtimePricePerMin,
 		AudioTtsPricePerMillionChars:    g.AudioTTSPricePerMillionChars,
 		AudioSttPricePerHour:            g.AudioSTTPricePerHour,
+		ResponseCacheEnabled:            g.ResponseCacheEnabled,
+		ResponseCacheTTLSeconds:         g.ResponseCacheTTLSeconds,
+		ResponseCacheHitMultiplier:      g.ResponseCacheHitMultiplier,
 		ClaudeCodeOnly:                  g.ClaudeCodeOnly,
 		FallbackGroupID:                 g.FallbackGroupID,
 		FallbackGroupIDOnInvalidRequest: g.FallbackGroupIDOnInvalidRequest,
@@ -218,6 +227,10 @@ func groupFromServiceBase(g *service.Group) Group {
 		RPMLimit:                        g.RPMLimit,
 		MaxReasoningEffort:              g.MaxReasoningEffort,
 		ReasoningEffortMappings:         g.ReasoningEffortMappings,
+		VolumeTiers:                     g.VolumeTiers,
+		AllowGeminiNative:               g.AllowGeminiNative,
+		HedgePolicy:                     g.HedgePolicy,
+		MirrorPolicy:                    g.MirrorPolicy,
 		CreatedAt:                       g.CreatedAt,
 		UpdatedAt:                       g.UpdatedAt,
 	}
@@ -681,6 +694,7 @@ func usageLogFromServiceUser(l *service.UsageLog) UsageLog {
 		SessionID:                 l.SessionID,
 		CacheTTLOverridden:        l.CacheTTLOverridden,
 		BillingMode:               l.BillingMode,
+		ResponseCacheMultiplier:   l.ResponseCacheMultiplier,
 		CreatedAt:                 l.CreatedAt,
 		User:                      UserFromServiceShallow(l.User),
 		APIKey:                    APIKeyFromService(l.APIKey),
@@ -0,0 +1,192 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/usagestats"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type Organization struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	OwnerUserID int64     `json:"owner_user_id"`
+	OwnerEmail  string    `json:"owner_email"`
+	Balance     float64   `json:"balance"`
+	Status      string    `json:"status"`
+	MemberCount int       `json:"member_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type OrganizationMember struct {
+	UserID             int64     `json:"user_id"`
+	Email              string    `json:"email"`
+	Username           string    `json:"username"`
+	Role               string    `json:"role"`
+	MonthlySpendCapUSD *float64  `json:"monthly_spend_cap_usd"`
+	MonthlySpentUSD    float64   `json:"monthly_spent_usd"`
+	JoinedAt           time.Time `json:"joined_at"`
+}
+
+type OrganizationInvitation struct {
+	ID                 int64     `json:"id"`
+	OrganizationID     int64     `json:"organization_id"`
+	OrganizationName   string    `json:"organization_name"`
+	UserID             int64     `json:"user_id"`
+	Email              string    `json:"email"`
+	InvitedByEmail     string    `json:"invited_by_email"`
+	Role               string    `json:"role"`
+	MonthlySpendCapUSD *float64  `json:"monthly_spend_cap_usd"`
+	Status             string    `json:"status"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+type OrganizationSubscription struct {
+	GroupID         int64     `json:"group_id"`
+	GroupName       string    `json:"group_name"`
+	SubscriptionID  int64     `json:"subscription_id"`
+	Status          string    `json:"status"`
+	ExpiresAt       time.Time `json:"expires_at"`
+	DailyUsageUSD   float64   `json:"daily_usage_usd"`
+	WeeklyUsageUSD  float64   `json:"weekly_usage_usd"`
+	MonthlyUsageUSD float64   `json:"monthly_usage_usd"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type OrganizationMemberUsage struct {
+	UserID             int64    `json:"user_id"`
+	Email              string   `json:"email"`
+	Role               string   `json:"role"`
+	TodayActualCost    float64  `json:"today_actual_cost"`
+	RangeActualCost    float64  `json:"range_actual_cost"`
+	MonthlySpentUSD    float64  `json:"monthly_spent_usd"`
+	MonthlySpendCapUSD *float64 `json:"monthly_spend_cap_usd"`
+}
+
+type OrganizationUsageDashboard struct {
+	OrganizationID int64                       `json:"organization_id"`
+	StartDate      string                      `json:"start_date"`
+	EndDate        string                      `json:"end_date"`
+	Requests       int64                       `json:"requests"`
+	TotalTokens    int64                       `json:"total_tokens"`
+	Cost           float64                     `json:"cost"`
+	ActualCost     float64                     `json:"actual_cost"`
+	Trend          []usagestats.TrendDataPoint `json:"trend"`
+	Models         []usagestats.ModelStat      `json:"models"`
+	Members        []OrganizationMemberUsage   `json:"members"`
+}
+
+func OrganizationFromService(o *service.Organization) *Organization {
+	if o == nil {
+		return nil
+	}
+	return &Organization{
+		ID:          o.ID,
+		Name:        o.Name,
+		OwnerUserID: o.OwnerUserID,
+		OwnerEmail:  o.OwnerEmail,
+		Balance:     o.Balance,
+		Status:      o.Status,
+		MemberCount: o.MemberCount,
+		CreatedAt:   o.CreatedAt,
+		UpdatedAt:   o.UpdatedAt,
+	}
+}
+
+func OrganizationMemberFromService(m *service.OrganizationMember) *OrganizationMember {
+	if m == nil {
+		return nil
+	}
+	return &OrganizationMember{
+		UserID:             m.UserID,
+		Email:              m.Email,
+		Username:           m.Username,
+		Role:               m.Role,
+		MonthlySpendCapUSD: m.MonthlySpendCapUSD,
+		MonthlySpentUSD:    m.CurrentMonthSpent(time.Now()),
+		JoinedAt:           m.CreatedAt,
+	}
+}
+
+func OrganizationMembersFromService(members []service.OrganizationMember) []OrganizationMember {
+	out := make([]OrganizationMember, 0, len(members))
+	for i := range members {
+		out = append(out, *OrganizationMemberFromService(&members[i]))
+	}
+	return out
+}
+
+func OrganizationInvitationFromService(i *service.OrganizationInvitation) *OrganizationInvitation {
+	if i == nil {
+		return nil
+	}
+	return &OrganizationInvitation{
+		ID:                 i.ID,
+		OrganizationID:     i.OrganizationID,
+		OrganizationName:   i.OrganizationName,
+		UserID:             i.UserID,
+		Email:              i.Email,
+		InvitedByEmail:     i.InvitedByEmail,
+		Role:               i.Role,
+		MonthlySpendCapUSD: i.MonthlySpendCapUSD,
+		Status:             i.Status,
+		CreatedAt:          i.CreatedAt,
+	}
+}
+
+func OrganizationInvitationsFromService(invitations []service.OrganizationInvitation) []OrganizationInvitation {
+	out := make([]OrganizationInvitation, 0, len(invitations))
+	for i := range invitations {
+		out = append(out, *OrganizationInvitationFromService(&invitations[i]))
+	}
+	return out
+}
+
+func OrganizationSubscriptionsFromService(subs []service.OrganizationSubscription) []OrganizationSubscription {
+	out := make([]OrganizationSubscription, 0, len(subs))
+	for _, s := range subs {
+		out = append(out, OrganizationSubscription{
+			GroupID:         s.GroupID,
+			GroupName:       s.GroupName,
+			SubscriptionID:  s.SubscriptionID,
+			Status:          s.Status,
+			ExpiresAt:       s.ExpiresAt,
+			DailyUsageUSD:   s.DailyUsageUSD,
+			WeeklyUsageUSD:  s.WeeklyUsageUSD,
+			MonthlyUsageUSD: s.MonthlyUsageUSD,
+			CreatedAt:       s.CreatedAt,
+		})
+	}
+	return out
+}
+
+func OrganizationUsageDashboardFromService(d *service.OrganizationUsageDashboard) *OrganizationUsageDashboard {
+	if d == nil {
+		return nil
+	}
+	members := make([]OrganizationMemberUsage, 0, len(d.Members))
+	for _, m := range d.Members {
+		members = append(members, OrganizationMemberUsage{
+			UserID:             m.UserID,
+			Email:              m.Email,
+			Role:               m.Role,
+			TodayActualCost:    m.TodayActualCost,
+			RangeActualCost:    m.RangeActualCost,
+			MonthlySpentUSD:    m.MonthlySpentUSD,
+			MonthlySpendCapUSD: m.MonthlySpendCapUSD,
+		})
+	}
+	return &OrganizationUsageDashboard{
+		OrganizationID: d.OrganizationID,
+		StartDate:      d.StartTime.Format("2006-01-02"),
+		EndDate:        d.EndTime.Add(-24 * time.Hour).Format("2006-01-02"),
+		Requests:       d.Requests,
+		TotalTokens:    d.TotalTokens,
+		Cost:           d.Cost,
+		ActualCost:     d.ActualCost,
+		Trend:          d.Trend,
+		Models:         d.Models,
+		Members:        members,
+	}
+}
@@ -292,6 +292,22 @@ type SystemSettings struct {
 	// Use Alipay face-to-face precreate and an app deep link on mobile clients.
 	PaymentAlipayMobilePrecreateDeepLink bool `json:"payment_alipay_mobile_precreate_deep_link"`
 
+	// 订阅自动续费
+	PaymentAutoRenewEnabled     bool `json:"payment_auto_renew_enabled"`
+	PaymentAutoRenewLeadDays    int  `json:"payment_auto_renew_lead_days"`
+	PaymentAutoRenewRetryHours  int  `json:"payment_auto_renew_retry_hours"`
+	PaymentAutoRenewMaxAttempts int  `json:"payment_auto_renew_max_attempts"`
+
+	// 发票与收据
+	PaymentInvoiceEnabled         bool   `json:"payment_invoice_enabled"`
+	PaymentInvoiceSellerName      string `json:"payment_invoice_seller_name"`
+	PaymentInvoiceSellerAddress   string `json:"payment_invoice_seller_address"`
+	PaymentInvoiceSellerTaxID     string `json:"payment_invoice_seller_tax_id"`
+	PaymentInvoiceSellerEmail     string `json:"payment_invoice_seller_email"`
+	PaymentInvoiceNumberPrefix    string `json:"payment_invoice_number_prefix"`
+	PaymentCreditNoteNumberPrefix string `json:"payment_credit_note_number_prefix"`
+	PaymentInvoiceFooter          string `json:"payment_invoice_footer"`
+
 	// 余额、订阅到期与账号限额通知
 	BalanceLowNotifyEnabled         bool               `json:"balance_low_notify_enabled"`
 	BalanceLowNotifyThreshold       float64            `json:"balance_low_notify_threshold"`
@@ -395,6 +411,8 @@ type PublicSettings struct {
 	WeChatOAuthMobileEnabled            bool                     `json:"wechat_oauth_mobile_enabled"`
 	OIDCOAuthEnabled                    bool                     `json:"oidc_oauth_enabled"`
 	OIDCOAuthProviderName               string                   `json:"oidc_oauth_provider_name"`
+	SAMLOAuthEnabled                    bool                     `json:"saml_oauth_enabled"`
+	SAMLOAuthProviderName               string                   `json:"saml_oauth_provider_name"`
 	GitHubOAuthEnabled                  bool                     `json:"github_oauth_enabled"`
 	GoogleOAuthEnabled                  bool                     `json:"google_oauth_enabled"`
 	SoraClientEnabled                   bool                     `json:"sora_client_enabled"`
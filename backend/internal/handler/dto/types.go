@@ -34,6 +34,9 @@ type User struct {
 	// RPMLimit 用户级每分钟请求数上限（0 = 不限制），仅在所用分组未设置 rpm_limit 时作为兜底生效。
 	RPMLimit int `json:"rpm_limit"`
 
+	// CreditLimit 后付费授信额度（0 = 纯预付费），余额可透支到 -credit_limit。
+	CreditLimit float64 `json:"credit_limit"`
+
 	APIKeys       []APIKey           `json:"api_keys,omitempty"`
 	Subscriptions []UserSubscription `json:"subscriptions,omitempty"`
 }
@@ -51,21 +54,24 @@ type AdminUser struct {
 }
 
 type APIKey struct {
-	ID          int64      `json:"id"`
-	UserID      int64      `json:"user_id"`
-	Key         string     `json:"key"`
-	Name        string     `json:"name"`
-	GroupID     *int64     `json:"group_id"`
-	Status      string     `json:"status"`
-	IPWhitelist []string   `json:"ip_whitelist"`
-	IPBlacklist []string   `json:"ip_blacklist"`
-	LastUsedAt  *time.Time `json:"last_used_at"`
-	LastUsedIP  *string    `json:"last_used_ip"`
-	Quota       float64    `json:"quota"`      // Quota limit in USD (0 = unlimited)
-	QuotaUsed   float64    `json:"quota_used"` // Used quota amount in USD
-	ExpiresAt   *time.Time `json:"expires_at"` // Expiration time (nil = never expires)
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
+	ID          int64    `json:"id"`
+	UserID      int64    `json:"user_id"`
+	Key         string   `json:"key"`
+	Name        string   `json:"name"`
+	GroupID     *int64   `json:"group_id"`
+	Status      string   `json:"status"`
+	IPWhitelist []string `json:"ip_whitelist"`
+	IPBlacklist []string `json:"ip_blacklist"`
+	// ModelAllowlist / ModelDenylist 模型白/黑名单（glob 或 re:<regex>）
+	ModelAllowlist []string   `json:"model_allowlist"`
+	ModelDenylist  []string   `json:"model_denylist"`
+	LastUsedAt     *time.Time `json:"last_used_at"`
+	LastUsedIP     *string    `json:"last_used_ip"`
+	Quota          float64    `json:"quota"`      // Quota limit in USD (0 = unlimited)
+	QuotaUsed      float64    `json:"quota_used"` // Used quota amount in USD
+	ExpiresAt      *time.Time `json:"expires_at"` // Expiration time (nil = never expires)
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
 	// CurrentConcurrency is the real-time active request count for this API key.
 	CurrentConcurrency int `json:"current_concurrency"`
 
@@ -83,6 +89,11 @@ type APIKey struct {
 	Reset1dAt     *time.Time `json:"reset_1d_at,omitempty"`
 	Reset7dAt     *time.Time `json:"reset_7d_at,omitempty"`
 
+	// Request throttling (0 = unlimited); CurrentRPM counts requests in the current minute.
+	RPMLimit       int `json:"rpm_limit"`
+	MaxConcurrency int `json:"max_concurrency"`
+	CurrentRPM     int `json:"current_rpm"`
+
 	User  *User  `json:"user,omitempty"`
 	Group *Group `json:"group,omitempty"`
 }
@@ -130,6 +141,11 @@ type Group struct {
 	AudioTtsPricePerMillionChars *float64 `json:"audio_tts_price_per_million_chars"`
 	AudioSttPricePerHour         *float64 `json:"audio_stt_price_per_hour"`
 
+	// 精确匹配响应缓存：命中时按原价乘以 response_cache_hit_multiplier 计费
+	ResponseCacheEnabled       bool    `json:"response_cache_enabled"`
+	ResponseCacheTTLSeconds    int     `json:"response_cache_ttl_seconds"`
+	ResponseCacheHitMultiplier float64 `json:"response_cache_hit_multiplier"`
+
 	// Claude Code 客户端限制
 	ClaudeCodeOnly  bool   `json:"claude_code_only"`
 	FallbackGroupID *int64 `json:"fallback_group_id"`
@@ -151,6 +167,14 @@ type Group struct {
 	MaxReasoningEffort string `json:"max_reasoning_effort"`
 	// ReasoningEffortMappings OpenAI/Codex 推理强度精确映射。
 	ReasoningEffortMappings []domain.ReasoningEffortMapping `json:"reasoning_effort_mappings"`
+	// VolumeTiers 月度累计消费阶梯，空表示未启用。
+	VolumeTiers []domain.VolumeTier `json:"volume_tiers"`
+	// AllowGeminiNative 允许 anthropic/openai 分组接入 Gemini 原生 generateContent 请求。
+	AllowGeminiNative bool `json:"allow_gemini_native"`
+	// HedgePolicy 非流式请求对冲策略，仅 openai 分组生效。
+	HedgePolicy domain.GroupHedgePolicy `json:"hedge_policy"`
+	// MirrorPolicy 流量镜像策略，用于在真实流量上评估候选上游。
+	MirrorPolicy domain.GroupMirrorPolicy `json:"mirror_policy"`
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -545,6 +569,8 @@ type UsageLog struct {
 
 	// BillingMode 计费模式：token/image
 	BillingMode *string `json:"billing_mode,omitempty"`
+	// ResponseCacheMultiplier 响应缓存命中计费倍率；非空表示该请求由缓存回放
+	ResponseCacheMultiplier *float64 `json:"response_cache_multiplier,omitempty"`
 
 	CreatedAt time.Time `json:"created_at"`
 
@@ -570,7 +596,7 @@ type AdminUsageLog struct {
 	ChannelID *int64 `json:"channel_id,omitempty"`
 	// ModelMappingChain 模型映射链，如 "a→b→c"
 	ModelMappingChain *string `json:"model_mapping_chain,omitempty"`
-	// BillingTier 计费层级标签（per_request/image 模式）
+	// BillingTier 计费层级标签（per_request/image 模式，或分组月度消费阶梯 volume_tN）
 	BillingTier *string `json:"billing_tier,omitempty"`
 
 	// AccountRateMultiplier 账号计费倍率快照（nil 表示按 1.0 处理）
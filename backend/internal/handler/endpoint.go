@@ -29,6 +29,8 @@ const (
 	EndpointVideosExtensions  = "/v1/videos/extensions"
 	EndpointVideos            = "/v1/videos"
 	EndpointGeminiModels      = "/v1beta/models"
+	EndpointOllamaChat        = "/api/chat"
+	EndpointOllamaGenerate    = "/api/generate"
 )
 
 const EndpointAntigravityGenerateContent = "/v1internal:streamGenerateContent"
@@ -2,13 +2,16 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tracing"
 	"github.com/Wei-Shaw/sub2api/internal/service"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 )
 
@@ -70,6 +73,8 @@ type FailoverState struct {
 	profitVetoedAccountIDs map[int64]struct{}
 	// profitVetoCount 本次请求累计的利润否决次数，用于 maxProfitVetoAttempts 上限。
 	profitVetoCount int
+	// attemptCount 已发起的上游转发尝试次数（含同账号重试），用于 trace span 编号。
+	attemptCount int
 }
 
 // NewFailoverState 创建 failover 状态
@@ -119,6 +124,34 @@ func (s *FailoverState) allExclusionsAreProfitVetoed() bool {
 	return true
 }
 
+// StartAttempt 为一次上游转发尝试开启 trace span（第几次尝试、账号、平台、已切换次数）。
+// 调用方把返回的 ctx 传给 Forward，并在 Forward 返回后调用 end(err)。
+func (s *FailoverState) StartAttempt(ctx context.Context, account *service.Account) (context.Context, func(error)) {
+	s.attemptCount++
+	attrs := []attribute.KeyValue{
+		tracing.AttrAttempt.Int(s.attemptCount),
+		attribute.Int("sub2api.failover.switch_count", s.SwitchCount),
+	}
+	if account != nil {
+		attrs = append(attrs,
+			tracing.AttrAccountID.Int64(account.ID),
+			tracing.AttrPlatform.String(account.Platform),
+			attribute.Int("sub2api.failover.same_account_retry", s.SameAccountRetryCount[account.ID]),
+		)
+	}
+	ctx, span := tracing.Start(ctx, "gateway.failover_attempt", attrs...)
+	return ctx, func(err error) {
+		var failoverErr *service.UpstreamFailoverError
+		if errors.As(err, &failoverErr) {
+			span.SetAttributes(
+				attribute.Int("sub2api.upstream.status_code", failoverErr.StatusCode),
+				attribute.Bool("sub2api.failover.retry_next_account", failoverErr.ShouldRetryNextAccount()),
+			)
+		}
+		tracing.End(span, err)
+	}
+}
+
 // HandleFailoverError 处理 UpstreamFailoverError，返回下一步动作。
 // 包含：缓存计费判断、同账号重试、临时封禁、切换计数、Antigravity 延时。
 func (s *FailoverState) HandleFailoverError(
@@ -246,6 +279,34 @@ func (s *FailoverState) HandleSelectionExhausted(ctx context.Context) FailoverAc
 	return FailoverExhausted
 }
 
+// modelFallbackEligibleStatus 判断失败是否可交给模型降级链接管：
+// 限流（429）、容量不足（503/529）与模型不可用（404）。
+// 鉴权、请求体等其它错误换模型也无济于事，照常返回给客户端。
+func modelFallbackEligibleStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable, 529, http.StatusNotFound:
+		return true
+	default:
+		return false
+	}
+}
+
+// deferToModelFallback 在请求携带了模型降级链（见 ModelFallback）、当前模型的
+// failover 已耗尽且失败属于可降级类型时，把失败交给外层降级调度，而不是写出错误响应。
+// 返回 true 时调用方必须直接 return，不得再写响应；流已开始或已是链上最后一个模型时返回 false。
+func deferToModelFallback(c *gin.Context, statusCode int, streamStarted bool) bool {
+	state := modelFallbackStateFromContext(c)
+	if state == nil || streamStarted || c.Writer.Written() || len(state.remaining) == 0 {
+		return false
+	}
+	if !modelFallbackEligibleStatus(statusCode) {
+		return false
+	}
+	state.pending = true
+	state.lastStatus = statusCode
+	return true
+}
+
 // needForceCacheBilling 判断 failover 时是否需要强制缓存计费。
 // 粘性会话实际切换账号、或上游明确标记时，将 input_tokens 转为 cache_read 计费。
 func needForceCacheBilling(hasBoundSession bool, failoverErr *service.UpstreamFailoverError, sameAccountRetry bool) bool {
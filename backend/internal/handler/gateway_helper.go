@@ -207,12 +207,48 @@ func (h *ConcurrencyHelper) TryAcquireUserSlot(ctx context.Context, userID int64
 	return result.ReleaseFunc, true, nil
 }
 
-func (h *ConcurrencyHelper) TryAcquireUserSlotForAPIKey(ctx context.Context, userID int64, maxConcurrency int, apiKeyID int64) (func(), bool, error) {
+// TryAcquireUserSlotForAPIKey 先占用 API Key 并发槽位（未配置上限时仅统计），再尝试用户槽位。
+// 任一维度已满时返回 acquired=false，且不会残留已占用的槽位。
+func (h *ConcurrencyHelper) TryAcquireUserSlotForAPIKey(ctx context.Context, userID int64, maxConcurrency int, apiKeyID int64, apiKeyMaxConcurrency int) (func(), bool, error) {
+	apiKeyReleaseFunc, acquired, err := h.tryAcquireAPIKeySlot(ctx, apiKeyID, apiKeyMaxConcurrency)
+	if err != nil || !acquired {
+		return nil, false, err
+	}
 	releaseFunc, acquired, err := h.TryAcquireUserSlot(ctx, userID, maxConcurrency)
 	if err != nil || !acquired {
-		return releaseFunc, acquired, err
+		apiKeyReleaseFunc()
+		return nil, false, err
+	}
+	return combineReleaseFuncs(releaseFunc, apiKeyReleaseFunc), true, nil
+}
+
+// tryAcquireAPIKeySlot 获取 API Key 维度的并发槽位，不排队等待。
+// 返回的 releaseFunc 在 acquired=true 时总是非 nil。
+func (h *ConcurrencyHelper) tryAcquireAPIKeySlot(ctx context.Context, apiKeyID int64, maxConcurrency int) (func(), bool, error) {
+	if h == nil || h.concurrencyService == nil || apiKeyID <= 0 {
+		return func() {}, true, nil
+	}
+	result, err := h.concurrencyService.AcquireAPIKeySlot(ctx, apiKeyID, maxConcurrency)
+	if err != nil {
+		return nil, false, err
+	}
+	if !result.Acquired {
+		return nil, false, nil
+	}
+	if result.ReleaseFunc == nil {
+		return func() {}, true, nil
+	}
+	return result.ReleaseFunc, true, nil
+}
+
+func combineReleaseFuncs(releaseFuncs ...func()) func() {
+	return func() {
+		for _, releaseFunc := range releaseFuncs {
+			if releaseFunc != nil {
+				releaseFunc()
+			}
+		}
 	}
-	return h.withAPIKeySlot(ctx, apiKeyID, releaseFunc), true, nil
 }
 
 // AcquireOpenAIWSIngressLease bounds the whole client WebSocket lifecycle,
@@ -245,6 +281,25 @@ func (h *ConcurrencyHelper) AcquireUserSlotWithWait(c *gin.Context, userID int64
 }
 
 func (h *ConcurrencyHelper) acquireUserSlotWithWaitTimeout(c *gin.Context, userID int64, maxConcurrency int, timeout time.Duration, isStream bool, streamStarted *bool) (func(), error) {
+	// 流量镜像请求在后台重放，不占用用户与 API Key 的并发额度。
+	if service.IsTrafficMirrorContext(c.Request.Context()) {
+		return func() {}, nil
+	}
+	// API Key 级并发上限不排队：已满时直接拒绝，避免单个 Key 占满用户的等待队列。
+	apiKeyReleaseFunc, err := h.acquireAPIKeySlotFromGin(c)
+	if err != nil {
+		return nil, err
+	}
+
+	releaseFunc, err := h.acquireUserSlotOnly(c, userID, maxConcurrency, timeout, isStream, streamStarted)
+	if err != nil {
+		apiKeyReleaseFunc()
+		return nil, err
+	}
+	return combineReleaseFuncs(releaseFunc, apiKeyReleaseFunc), nil
+}
+
+func (h *ConcurrencyHelper) acquireUserSlotOnly(c *gin.Context, userID int64, maxConcurrency int, timeout time.Duration, isStream bool, streamStarted *bool) (func(), error) {
 	ctx := c.Request.Context()
 
 	// Try to acquire immediately
@@ -254,7 +309,7 @@ func (h *ConcurrencyHelper) acquireUserSlotWithWaitTimeout(c *gin.Context, userI
 	}
 
 	if acquired {
-		return h.withAPIKeySlotFromGin(c, releaseFunc), nil
+		return releaseFunc, nil
 	}
 
 	queueLimit := service.CalculateMaxWait(maxConcurrency) - maxConcurrency
@@ -271,37 +326,27 @@ func (h *ConcurrencyHelper) acquireUserSlotWithWaitTimeout(c *gin.Context, userI
 	defer h.DecrementWaitCount(ctx, userID)
 
 	// Need to wait - handle streaming ping if needed
-	releaseFunc, err = h.waitForSlotWithPingTimeout(c, "user", userID, maxConcurrency, timeout, isStream, streamStarted, false)
-	if err != nil {
-		return nil, err
-	}
-	return h.withAPIKeySlotFromGin(c, releaseFunc), nil
+	return h.waitForSlotWithPingTimeout(c, "user", userID, maxConcurrency, timeout, isStream, streamStarted, false)
 }
 
-func (h *ConcurrencyHelper) withAPIKeySlotFromGin(c *gin.Context, releaseFunc func()) func() {
+// acquireAPIKeySlotFromGin 按上下文中的 API Key 获取 Key 级并发槽位。
+// 返回的 releaseFunc 总是非 nil；Key 已满时返回 ConcurrencyError（SlotType=api_key）。
+func (h *ConcurrencyHelper) acquireAPIKeySlotFromGin(c *gin.Context) (func(), error) {
 	if c == nil {
-		return releaseFunc
+		return func() {}, nil
 	}
 	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
 	if !ok || apiKey == nil {
-		return releaseFunc
+		return func() {}, nil
 	}
-	return h.withAPIKeySlot(c.Request.Context(), apiKey.ID, releaseFunc)
-}
-
-func (h *ConcurrencyHelper) withAPIKeySlot(ctx context.Context, apiKeyID int64, releaseFunc func()) func() {
-	if h == nil || h.concurrencyService == nil || apiKeyID <= 0 {
-		return releaseFunc
+	releaseFunc, acquired, err := h.tryAcquireAPIKeySlot(c.Request.Context(), apiKey.ID, apiKey.MaxConcurrency)
+	if err != nil {
+		return nil, err
 	}
-	apiKeyReleaseFunc := h.concurrencyService.TrackAPIKeySlot(ctx, apiKeyID)
-	return func() {
-		if releaseFunc != nil {
-			releaseFunc()
-		}
-		if apiKeyReleaseFunc != nil {
-			apiKeyReleaseFunc()
-		}
+	if !acquired {
+		return nil, &ConcurrencyError{SlotType: "api_key"}
 	}
+	return releaseFunc, nil
 }
 
 // AcquireAccountSlotWithWait acquires an account concurrency slot, waiting if necessary.
@@ -33,6 +33,8 @@ type helperConcurrencyCacheStub struct {
 	apiKeyTrackCalls    int
 	apiKeyReleaseCalls  int
 	apiKeyTrackIDs      []int64
+	apiKeySeq           []bool
+	apiKeyAcquireCalls  int
 }
 
 func (s *helperConcurrencyCacheStub) AcquireAccountSlot(ctx context.Context, accountID int64, maxConcurrency int, requestID string) (bool, error) {
@@ -109,6 +111,18 @@ func (s *helperConcurrencyCacheStub) TrackAPIKeySlot(ctx context.Context, apiKey
 	return nil
 }
 
+func (s *helperConcurrencyCacheStub) AcquireAPIKeySlot(ctx context.Context, apiKeyID int64, maxConcurrency int, requestID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.apiKeyAcquireCalls++
+	if len(s.apiKeySeq) == 0 {
+		return false, nil
+	}
+	v := s.apiKeySeq[0]
+	s.apiKeySeq = s.apiKeySeq[1:]
+	return v, nil
+}
+
 func (s *helperConcurrencyCacheStub) ReleaseAPIKeySlot(ctx context.Context, apiKeyID int64, requestID string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -319,6 +333,65 @@ func TestAcquireUserSlotWithWait_TracksAPIKeySlot(t *testing.T) {
 	require.Equal(t, 1, cache.apiKeyReleaseCalls)
 }
 
+func TestAcquireUserSlotWithWait_APIKeyLimitRejectsWithoutQueueing(t *testing.T) {
+	cache := &helperConcurrencyCacheStub{
+		apiKeySeq: []bool{false},
+		userSeq:   []bool{true},
+	}
+	concurrency := service.NewConcurrencyService(cache)
+	helper := NewConcurrencyHelper(concurrency, SSEPingFormatNone, 5*time.Millisecond)
+	c, _ := newHelperTestContext(http.MethodPost, "/v1/messages")
+	c.Set(string(middleware2.ContextKeyAPIKey), &service.APIKey{ID: 77, MaxConcurrency: 2})
+	streamStarted := false
+
+	release, err := helper.acquireUserSlotWithWaitTimeout(c, 202, 3, time.Second, false, &streamStarted)
+	require.Nil(t, release)
+	var concurrencyErr *ConcurrencyError
+	require.ErrorAs(t, err, &concurrencyErr)
+	require.Equal(t, "api_key", concurrencyErr.SlotType)
+	status, _, _ := concurrencyErrorResponse(err, "user")
+	require.Equal(t, http.StatusTooManyRequests, status)
+
+	require.Equal(t, 1, cache.apiKeyAcquireCalls)
+	require.Equal(t, 0, cache.userAcquireCalls)
+	require.Equal(t, 0, cache.waitIncrementCalls)
+}
+
+func TestAcquireUserSlotWithWait_ReleasesAPIKeySlotWhenUserQueueFull(t *testing.T) {
+	cache := &helperConcurrencyCacheStub{
+		apiKeySeq:   []bool{true},
+		userSeq:     []bool{false},
+		waitAllowed: false,
+	}
+	concurrency := service.NewConcurrencyService(cache)
+	helper := NewConcurrencyHelper(concurrency, SSEPingFormatNone, 5*time.Millisecond)
+	c, _ := newHelperTestContext(http.MethodPost, "/v1/messages")
+	c.Set(string(middleware2.ContextKeyAPIKey), &service.APIKey{ID: 77, MaxConcurrency: 2})
+	streamStarted := false
+
+	release, err := helper.acquireUserSlotWithWaitTimeout(c, 202, 3, time.Second, false, &streamStarted)
+	require.Nil(t, release)
+	var queueErr *WaitQueueFullError
+	require.ErrorAs(t, err, &queueErr)
+	require.Equal(t, 1, cache.apiKeyAcquireCalls)
+	require.Equal(t, 1, cache.apiKeyReleaseCalls)
+}
+
+func TestTryAcquireUserSlotForAPIKey_APIKeyLimit(t *testing.T) {
+	cache := &helperConcurrencyCacheStub{
+		apiKeySeq: []bool{false},
+		userSeq:   []bool{true},
+	}
+	concurrency := service.NewConcurrencyService(cache)
+	helper := NewConcurrencyHelper(concurrency, SSEPingFormatNone, 5*time.Millisecond)
+
+	release, acquired, err := helper.TryAcquireUserSlotForAPIKey(context.Background(), 202, 3, 77, 1)
+	require.NoError(t, err)
+	require.False(t, acquired)
+	require.Nil(t, release)
+	require.Equal(t, 0, cache.userAcquireCalls)
+}
+
 func TestTryAcquireUserSlotForAPIKey_TracksAPIKeySlot(t *testing.T) {
 	cache := &helperConcurrencyCacheStub{
 		userSeq: []bool{true},
@@ -326,7 +399,7 @@ func TestTryAcquireUserSlotForAPIKey_TracksAPIKeySlot(t *testing.T) {
 	concurrency := service.NewConcurrencyService(cache)
 	helper := NewConcurrencyHelper(concurrency, SSEPingFormatNone, 5*time.Millisecond)
 
-	release, acquired, err := helper.TryAcquireUserSlotForAPIKey(context.Background(), 202, 3, 77)
+	release, acquired, err := helper.TryAcquireUserSlotForAPIKey(context.Background(), 202, 3, 77, 0)
 	require.NoError(t, err)
 	require.True(t, acquired)
 	require.NotNil(t, release)
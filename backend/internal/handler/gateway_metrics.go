@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/metrics"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// recordGatewayRequestMetrics 在请求结束后上报 Prometheus 请求指标。
+//
+// 与 ops 错误日志共用同一中间件挂载点，因此覆盖所有网关路由；平台/模型/分组
+// 的解析口径与 ops 错误日志一致。入口拒绝（认证失败等）同样计数，模型与平台
+// 缺失时落入 unknown。
+func recordGatewayRequestMetrics(c *gin.Context, duration time.Duration) {
+	if c == nil || c.Request == nil {
+		return
+	}
+	apiKey := getOpsAPIKey(c)
+	platform := resolveOpsPlatform(c.Request.Context(), apiKey, guessPlatformFromPath(c.Request.URL.Path))
+
+	var model string
+	if v, ok := c.Get(opsModelKey); ok {
+		model, _ = v.(string)
+	}
+	var group string
+	if apiKey != nil && apiKey.Group != nil {
+		group = apiKey.Group.Name
+	}
+	var ttft time.Duration
+	if ms := getContextLatencyMs(c, service.OpsTimeToFirstTokenMsKey); ms != nil {
+		ttft = time.Duration(*ms) * time.Millisecond
+	}
+
+	metrics.ObserveGatewayRequest(metrics.GatewayRequest{
+		Platform:   platform,
+		Model:      model,
+		Group:      group,
+		StatusCode: c.Writer.Status(),
+		Duration:   duration,
+		TTFT:       ttft,
+	})
+
+	if v, ok := c.Get(service.OpsUpstreamErrorsKey); ok {
+		if events, ok := v.([]*service.OpsUpstreamErrorEvent); ok {
+			for _, ev := range events {
+				if ev == nil {
+					continue
+				}
+				evPlatform := ev.Platform
+				if evPlatform == "" {
+					evPlatform = platform
+				}
+				metrics.IncUpstreamError(evPlatform, classifyUpstreamErrorForMetrics(ev))
+			}
+		}
+	}
+}
+
+// classifyUpstreamErrorForMetrics 将上游错误事件归并为低基数的错误类别。
+func classifyUpstreamErrorForMetrics(ev *service.OpsUpstreamErrorEvent) string {
+	switch code := ev.UpstreamStatusCode; {
+	case code == 429:
+		return "rate_limited"
+	case code == 401 || code == 403:
+		return "auth"
+	case code == 529 || code == 503:
+		return "overloaded"
+	case code >= 500:
+		return "server_error"
+	case code >= 400:
+		return "client_error"
+	}
+	switch ev.Kind {
+	case "request_error":
+		return "network"
+	case "":
+		return "other"
+	default:
+		return ev.Kind
+	}
+}
+
+// setForwardTTFT 把转发结果的首字时间写入 ops 上下文，ops 错误日志与 Prometheus 共用。
+func setForwardTTFT(c *gin.Context, result *service.ForwardResult, err error) {
+	if err == nil && result != nil && result.FirstTokenMs != nil {
+		service.SetOpsLatencyMs(c, service.OpsTimeToFirstTokenMsKey, int64(*result.FirstTokenMs))
+	}
+}
@@ -0,0 +1,604 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/domain"
+	pkghttputil "github.com/Wei-Shaw/sub2api/internal/pkg/httputil"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"go.uber.org/zap"
+)
+
+// Gemini 原生协议桥接：分组开启 allow_gemini_native 后，anthropic / openai 分组
+// 也能服务 POST /v1beta/models/{model}:generateContent|streamGenerateContent。
+// 请求经 apicompat 转换为 Anthropic Messages / OpenAI Responses，响应再转回
+// Gemini 格式；错误统一使用 Google API 错误格式。
+
+// geminiBridgeRequest 是两条桥接路径共用的入口解析结果。
+type geminiBridgeRequest struct {
+	model  string
+	action string
+	stream bool
+	body   []byte
+}
+
+// readGeminiBridgeRequest 解析 URL 中的 {model}:{action} 并读取请求体；失败时已写出错误。
+func readGeminiBridgeRequest(c *gin.Context) (*geminiBridgeRequest, bool) {
+	modelName, action, err := parseGeminiModelAction(strings.TrimPrefix(c.Param("modelAction"), "/"))
+	if err != nil {
+		googleError(c, http.StatusNotFound, err.Error())
+		return nil, false
+	}
+	if !service.IsSafeGeminiModelPathSegment(modelName) {
+		googleError(c, http.StatusBadRequest, "Invalid model in URL")
+		return nil, false
+	}
+	if action != "generateContent" && action != "streamGenerateContent" {
+		googleError(c, http.StatusNotFound, "Action "+action+" is not supported for this group")
+		return nil, false
+	}
+
+	body, err := pkghttputil.ReadRequestBodyWithPrealloc(c.Request)
+	if err != nil {
+		if maxErr, ok := extractMaxBytesError(err); ok {
+			googleError(c, http.StatusRequestEntityTooLarge, buildBodyTooLargeMessage(maxErr.Limit))
+			return nil, false
+		}
+		googleError(c, http.StatusBadRequest, "Failed to read request body")
+		return nil, false
+	}
+	if len(body) == 0 {
+		googleError(c, http.StatusBadRequest, "Request body is empty")
+		return nil, false
+	}
+	if !gjson.ValidBytes(body) {
+		googleError(c, http.StatusBadRequest, "Failed to parse request body")
+		return nil, false
+	}
+	return &geminiBridgeRequest{
+		model:  modelName,
+		action: action,
+		stream: action == "streamGenerateContent",
+		body:   body,
+	}, true
+}
+
+// writeGeminiBridgeFailoverExhausted 以 Google 错误格式写出 failover 耗尽错误；
+// 流已开始时不再追加响应。
+func writeGeminiBridgeFailoverExhausted(c *gin.Context, lastErr *service.UpstreamFailoverError, streamStarted bool) {
+	if streamStarted || c.Writer.Written() {
+		return
+	}
+	if lastErr != nil {
+		copyFailoverRetryAfter(c, lastErr.ResponseHeaders)
+	}
+	if lastErr != nil && lastErr.IsCredentialFailure() {
+		status, message := credentialFailoverClientResponse(lastErr)
+		googleError(c, status, message)
+		return
+	}
+	statusCode := http.StatusBadGateway
+	if lastErr != nil && lastErr.StatusCode > 0 {
+		statusCode = lastErr.StatusCode
+	}
+	googleError(c, statusCode, "All available accounts exhausted")
+}
+
+// geminiV1BetaModelsViaAnthropic serves a Gemini-native request from an
+// anthropic group with allow_gemini_native enabled.
+func (h *GatewayHandler) geminiV1BetaModelsViaAnthropic(c *gin.Context, apiKey *service.APIKey, subject middleware.AuthSubject, reqLog *zap.Logger) {
+	streamStarted := false
+
+	req, ok := readGeminiBridgeRequest(c)
+	if !ok {
+		return
+	}
+	reqModel := req.model
+	reqLog = reqLog.With(zap.String("model", reqModel), zap.String("action", req.action), zap.Bool("stream", req.stream), zap.Bool("gemini_bridge", true))
+
+	setOpsRequestContext(c, reqModel, req.stream)
+	setOpsEndpointContext(c, "", int16(service.RequestTypeFromLegacy(req.stream, false)))
+	pricingCtx, pricingAt := service.WithGatewayTokenRequestPricing(c.Request.Context())
+	c.Request = c.Request.WithContext(pricingCtx)
+
+	if apiKey.Group != nil && apiKey.Group.ClaudeCodeOnly {
+		googleError(c, http.StatusForbidden, "This group is restricted to Claude Code clients (/v1/messages only)")
+		return
+	}
+	if decision := h.checkSecurityAudit(c, reqLog, apiKey, subject, service.ContentModerationProtocolGemini, reqModel, req.body); decision != nil && !decision.AllowNextStage {
+		googleSecurityAuditError(c, decision)
+		return
+	}
+
+	channelMapping, _ := h.gatewayService.ResolveChannelMappingAndRestrict(c.Request.Context(), apiKey.GroupID, reqModel)
+	forwardModel := reqModel
+	if channelMapping.Mapped {
+		forwardModel = channelMapping.MappedModel
+	}
+
+	if h.errorPassthroughService != nil {
+		service.BindErrorPassthroughService(c, h.errorPassthroughService)
+	}
+	subscription, _ := middleware.GetSubscriptionFromContext(c)
+
+	// Gemini 客户端不识别 Claude 风格的 ping 帧。
+	geminiConcurrency := NewConcurrencyHelper(h.concurrencyHelper.concurrencyService, SSEPingFormatNone, 0)
+	userReleaseFunc, err := geminiConcurrency.AcquireUserSlotWithWait(c, subject.UserID, subject.Concurrency, req.stream, &streamStarted)
+	if err != nil {
+		reqLog.Warn("gemini_bridge.user_slot_acquire_failed", zap.Error(err))
+		googleError(c, http.StatusTooManyRequests, err.Error())
+		return
+	}
+	userReleaseFunc = wrapReleaseOnDone(c.Request.Context(), userReleaseFunc)
+	if userReleaseFunc != nil {
+		defer userReleaseFunc()
+	}
+
+	if err := h.billingCacheService.CheckBillingEligibility(c.Request.Context(), apiKey.User, apiKey, apiKey.Group, subscription, service.QuotaPlatform(c.Request.Context(), apiKey)); err != nil {
+		reqLog.Info("gemini_bridge.billing_check_failed", zap.Error(err))
+		status, _, message, retryAfter := billingErrorDetails(err)
+		if retryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+		}
+		googleError(c, status, message)
+		return
+	}
+
+	sessionHash := extractGeminiCLISessionHash(c, req.body)
+	if sessionHash == "" {
+		parsedReq, _ := service.ParseGatewayRequest(service.NewRequestBodyRef(req.body), domain.PlatformGemini)
+		if parsedReq != nil {
+			parsedReq.SessionContext = &service.SessionContext{
+				ClientIP:  ip.GetClientIP(c),
+				UserAgent: c.GetHeader("User-Agent"),
+				APIKeyID:  apiKey.ID,
+			}
+		}
+		sessionHash = h.gatewayService.GenerateSessionHash(parsedReq)
+	}
+
+	fs := NewFailoverState(h.maxAccountSwitches, false)
+	for {
+		if c.Request.Context().Err() != nil {
+			return
+		}
+		selection, err := h.gatewayService.SelectAccountWithLoadAwareness(c.Request.Context(), apiKey.GroupID, sessionHash, reqModel, fs.FailedAccountIDs, "", int64(0))
+		if err != nil {
+			if len(fs.FailedAccountIDs) == 0 {
+				cls := classifyNoAccountErrorFromGin(c, h.gatewayService, apiKey, reqModel, reqModel, service.PlatformAnthropic)
+				if !cls.ModelNotFound {
+					markOpsRoutingCapacityLimitedIfNoAvailable(c, err)
+				}
+				message := cls.Message
+				if !cls.ModelNotFound {
+					message = "No available accounts: " + err.Error()
+				}
+				googleError(c, cls.Status, message)
+				return
+			}
+			switch fs.HandleSelectionExhausted(c.Request.Context()) {
+			case FailoverContinue:
+				continue
+			case FailoverCanceled:
+				failoverClientGone(c)
+				return
+			default:
+				writeGeminiBridgeFailoverExhausted(c, fs.LastFailoverErr, streamStarted)
+				return
+			}
+		}
+		account := selection.Account
+		setOpsSelectedAccount(c, account.ID, account.Platform)
+
+		accountReleaseFunc := selection.ReleaseFunc
+		if !selection.Acquired {
+			if selection.WaitPlan == nil {
+				markOpsRoutingCapacityLimited(c)
+				googleError(c, http.StatusServiceUnavailable, "No available accounts")
+				return
+			}
+			accountReleaseFunc, err = geminiConcurrency.AcquireAccountSlotWithWaitTimeout(
+				c,
+				account.ID,
+				selection.WaitPlan.MaxConcurrency,
+				selection.WaitPlan.Timeout,
+				req.stream,
+				&streamStarted,
+			)
+			if err != nil {
+				reqLog.Warn("gemini_bridge.account_slot_acquire_failed", zap.Int64("account_id", account.ID), zap.Error(err))
+				googleError(c, http.StatusTooManyRequests, err.Error())
+				return
+			}
+		}
+		admissionCtx := service.ContextWithSelectionProfitGate(c.Request.Context(), selection)
+		latest, vetoed, reason := h.gatewayService.GatewayProfitControlVetoLatest(admissionCtx, account)
+		if vetoed {
+			if accountReleaseFunc != nil {
+				accountReleaseFunc()
+			}
+			reqLog.Debug("gemini_bridge.account_slot_profit_vetoed", zap.Int64("account_id", account.ID), zap.String("reason", reason))
+			if fs.RecordProfitVeto(account.ID) == FailoverExhausted {
+				googleError(c, http.StatusServiceUnavailable, profitVetoExhaustedMessage)
+				return
+			}
+			continue
+		}
+		account = latest
+		selection.Account = latest
+		if selection.ProfitGateActive() {
+			if err := h.gatewayService.BindStickySessionAfterProfitAdmission(admissionCtx, apiKey.GroupID, sessionHash, account.ID); err != nil {
+				reqLog.Warn("gemini_bridge.bind_sticky_session_after_profit_admission_failed", zap.Int64("account_id", account.ID), zap.Error(err))
+			}
+		}
+		accountReleaseFunc = wrapReleaseOnDone(c.Request.Context(), accountReleaseFunc)
+
+		// 仅 Anthropic 上游走 Messages 转换链；分组内的其他平台账号跳过。
+		if account.Platform != service.PlatformAnthropic || shouldUseAntigravityCompat(account) {
+			if accountReleaseFunc != nil {
+				accountReleaseFunc()
+			}
+			fs.FailedAccountIDs[account.ID] = struct{}{}
+			continue
+		}
+
+		writerSizeBeforeForward := c.Writer.Size()
+		setActualUpstreamEndpoint(c, "")
+		attemptCtx, endAttempt := fs.StartAttempt(c.Request.Context(), account)
+		result, err := h.gatewayService.ForwardAsGemini(attemptCtx, c, account, req.body, forwardModel, req.stream)
+		setForwardTTFT(c, result, err)
+		endAttempt(err)
+		if accountReleaseFunc != nil {
+			accountReleaseFunc()
+		}
+
+		if err != nil {
+			var failoverErr *service.UpstreamFailoverError
+			if errors.As(err, &failoverErr) {
+				if c.Writer.Size() != writerSizeBeforeForward {
+					writeGeminiBridgeFailoverExhausted(c, failoverErr, true)
+					return
+				}
+				switch fs.HandleFailoverError(c.Request.Context(), h.gatewayService, account.ID, account.Platform, account.GetPoolModeRetryCount(), failoverErr) {
+				case FailoverContinue:
+					continue
+				case FailoverExhausted:
+					writeGeminiBridgeFailoverExhausted(c, fs.LastFailoverErr, streamStarted)
+					return
+				case FailoverCanceled:
+					failoverClientGone(c)
+					return
+				}
+			}
+			alreadyWritten := gatewayForwardErrorAlreadyCommunicated(c, writerSizeBeforeForward, err) || service.IsResponseCommitted(c)
+			if !alreadyWritten && !c.Writer.Written() {
+				googleError(c, http.StatusBadGateway, "Upstream request failed")
+			}
+			reqLog.Error("gemini_bridge.forward_failed",
+				zap.Int64("account_id", account.ID),
+				zap.Bool("upstream_error_response_already_written", alreadyWritten),
+				zap.Error(err),
+			)
+			return
+		}
+
+		userAgent := c.GetHeader("User-Agent")
+		clientIP := ip.GetClientIP(c)
+		requestPayloadHash := service.HashUsageRequestPayload(req.body)
+		inboundEndpoint := GetInboundEndpoint(c)
+		upstreamEndpoint := GetUpstreamEndpoint(c, account.Platform)
+		quotaPlatform := service.QuotaPlatform(c.Request.Context(), apiKey)
+		sessionID := service.ExtractClientSessionID(c)
+		h.submitUsageRecordTask(c.Request.Context(), func(ctx context.Context) {
+			if err := h.gatewayService.RecordUsage(ctx, &service.RecordUsageInput{
+				Result:             result,
+				QuotaPlatform:      quotaPlatform,
+				APIKey:             apiKey,
+				User:               apiKey.User,
+				Account:            account,
+				Subscription:       subscription,
+				PricingAt:          pricingAt,
+				InboundEndpoint:    inboundEndpoint,
+				UpstreamEndpoint:   upstreamEndpoint,
+				UserAgent:          userAgent,
+				IPAddress:          clientIP,
+				RequestPayloadHash: requestPayloadHash,
+				APIKeyService:      h.apiKeyService,
+				SessionID:          sessionID,
+				ChannelUsageFields: clientRequestedUsageFields(c, channelMapping, reqModel, result.UpstreamModel),
+			}); err != nil {
+				reqLog.Error("gemini_bridge.record_usage_failed",
+					zap.Int64("account_id", account.ID),
+					zap.Error(err),
+				)
+			}
+		})
+		return
+	}
+}
+
+// GeminiV1BetaModels serves Gemini-native generateContent requests for openai
+// groups with allow_gemini_native enabled, via the Responses API.
+// POST /v1beta/models/{model}:generateContent
+// POST /v1beta/models/{model}:streamGenerateContent?alt=sse
+func (h *OpenAIGatewayHandler) GeminiV1BetaModels(c *gin.Context) {
+	streamStarted := false
+	defer h.recoverResponsesPanic(c, &streamStarted)
+
+	requestStart := time.Now()
+
+	apiKey, ok := middleware.GetAPIKeyFromContext(c)
+	if !ok || apiKey == nil {
+		googleError(c, http.StatusUnauthorized, "Invalid API key")
+		return
+	}
+	subject, ok := middleware.GetAuthSubjectFromContext(c)
+	if !ok {
+		googleError(c, http.StatusInternalServerError, "User context not found")
+		return
+	}
+	reqLog := requestLogger(
+		c,
+		"handler.openai_gateway.gemini_v1beta",
+		zap.Int64("user_id", subject.UserID),
+		zap.Int64("api_key_id", apiKey.ID),
+		zap.Any("group_id", apiKey.GroupID),
+	)
+	if !apiKey.Group.AllowsGeminiNative() {
+		googleError(c, http.StatusBadRequest, "API key group platform is not gemini")
+		return
+	}
+	if !h.ensureResponsesDependencies(c, reqLog) {
+		return
+	}
+
+	req, ok := readGeminiBridgeRequest(c)
+	if !ok {
+		return
+	}
+	reqModel := req.model
+	reqLog = reqLog.With(zap.String("model", reqModel), zap.String("action", req.action), zap.Bool("stream", req.stream))
+
+	setOpsRequestContext(c, reqModel, req.stream)
+	setOpsEndpointContext(c, "", int16(service.RequestTypeFromLegacy(req.stream, false)))
+
+	if decision := h.checkSecurityAudit(c, reqLog, apiKey, subject, service.ContentModerationProtocolGemini, reqModel, req.body); decision != nil && !decision.AllowNextStage {
+		googleSecurityAuditError(c, decision)
+		return
+	}
+
+	channelMapping, _ := h.gatewayService.ResolveChannelMappingAndRestrict(c.Request.Context(), apiKey.GroupID, reqModel)
+	forwardModel := reqModel
+	if channelMapping.Mapped {
+		forwardModel = channelMapping.MappedModel
+	}
+
+	if h.errorPassthroughService != nil {
+		service.BindErrorPassthroughService(c, h.errorPassthroughService)
+	}
+	subscription, _ := middleware.GetSubscriptionFromContext(c)
+	requestPlatform := openAICompatibleRequestPlatform(c.Request.Context(), apiKey)
+
+	service.SetOpsLatencyMs(c, service.OpsAuthLatencyMsKey, time.Since(requestStart).Milliseconds())
+	routingStart := time.Now()
+
+	userReleaseFunc, acquired := h.acquireResponsesUserSlot(c, subject.UserID, subject.Concurrency, req.stream, &streamStarted, reqLog)
+	if !acquired {
+		return
+	}
+	if userReleaseFunc != nil {
+		defer userReleaseFunc()
+	}
+
+	if err := h.billingCacheService.CheckBillingEligibility(c.Request.Context(), apiKey.User, apiKey, apiKey.Group, subscription, service.QuotaPlatform(c.Request.Context(), apiKey)); err != nil {
+		reqLog.Info("openai_gemini.billing_eligibility_check_failed", zap.Error(err))
+		status, _, message, retryAfter := billingErrorDetails(err)
+		if retryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+		}
+		googleError(c, status, message)
+		return
+	}
+
+	sessionHash := extractGeminiCLISessionHash(c, req.body)
+	if sessionHash == "" {
+		sessionHash = h.gatewayService.GenerateSessionHash(c, req.body)
+	}
+	promptCacheKey := h.gatewayService.ExtractSessionID(c, req.body)
+
+	maxAccountSwitches := h.maxAccountSwitches
+	switchCount := 0
+	profitVetoCount := 0
+	failedAccountIDs := make(map[int64]struct{})
+	sameAccountRetryCount := make(map[int64]int)
+	var lastFailoverErr *service.UpstreamFailoverError
+	var oauth429FailoverState service.OpenAIOAuth429FailoverState
+
+	pricingCtx, pricingAt := h.gatewayService.WithOpenAIRequestPricingContext(c.Request.Context(), apiKey.GroupID)
+	c.Request = c.Request.WithContext(pricingCtx)
+
+	for {
+		if failoverClientGone(c) {
+			return
+		}
+		selection, _, err := h.gatewayService.SelectAccountWithSchedulerForCapability(
+			c.Request.Context(),
+			apiKey.GroupID,
+			"",
+			sessionHash,
+			reqModel,
+			failedAccountIDs,
+			service.OpenAIUpstreamTransportAny,
+			service.OpenAIEndpointCapabilityResponses,
+			false,
+			false,
+			true,
+			requestPlatform,
+		)
+		if err != nil || selection == nil || selection.Account == nil {
+			if failoverClientGone(c) {
+				return
+			}
+			if err != nil {
+				reqLog.Warn("openai_gemini.account_select_failed",
+					zap.Error(openAICompatibleSelectionErrorForLog(err, requestPlatform)),
+					zap.Int("excluded_account_count", len(failedAccountIDs)),
+				)
+			}
+			if len(failedAccountIDs) > 0 {
+				writeGeminiBridgeFailoverExhausted(c, lastFailoverErr, streamStarted)
+				return
+			}
+			cls := classifyOpenAICompatibleNoAccountErrorFromGin(c, h.gatewayService, apiKey, reqModel, reqModel)
+			if !cls.ModelNotFound {
+				markOpsRoutingCapacityLimitedIfNoAvailable(c, err)
+			}
+			googleError(c, cls.Status, cls.Message)
+			return
+		}
+		account := selection.Account
+		sessionHash = ensureOpenAIPoolModeSessionHash(sessionHash, account)
+		setOpsSelectedAccount(c, account.ID, account.Platform)
+
+		accountReleaseFunc, slotResult := h.acquireResponsesAccountSlot(c, apiKey.GroupID, sessionHash, selection, req.stream, &streamStarted, reqLog)
+		if slotResult == openAISlotAcquireProfitVetoed {
+			if !recordOpenAIProfitVeto(failedAccountIDs, account.ID, &profitVetoCount) {
+				markOpsRoutingCapacityLimited(c)
+				googleError(c, http.StatusServiceUnavailable, profitVetoExhaustedMessage)
+				return
+			}
+			continue
+		}
+		if slotResult != openAISlotAcquireOK {
+			return
+		}
+
+		service.SetOpsLatencyMs(c, service.OpsRoutingLatencyMsKey, time.Since(routingStart).Milliseconds())
+		forwardStart := time.Now()
+		writerSizeBeforeForward := c.Writer.Size()
+		result, err := func() (*service.OpenAIForwardResult, error) {
+			defer func() {
+				if accountReleaseFunc != nil {
+					accountReleaseFunc()
+				}
+			}()
+			return h.gatewayService.ForwardAsGemini(c.Request.Context(), c, account, req.body, forwardModel, req.stream, promptCacheKey, "")
+		}()
+		h.recordCyberPolicyIfMarked(c, apiKey, account, subscription, reqModel, err != nil, "", clientRequestedUsageFields(c, channelMapping, reqModel, ""), service.HashUsageRequestPayload(req.body))
+
+		service.SetOpsLatencyMs(c, service.OpsResponseLatencyMsKey, time.Since(forwardStart).Milliseconds())
+		if err == nil && result != nil && result.FirstTokenMs != nil {
+			service.SetOpsLatencyMs(c, service.OpsTimeToFirstTokenMsKey, int64(*result.FirstTokenMs))
+		}
+		if err != nil {
+			var failoverErr *service.UpstreamFailoverError
+			if errors.As(err, &failoverErr) {
+				if failoverClientGone(c) {
+					return
+				}
+				if c.Writer.Size() != writerSizeBeforeForward {
+					writeGeminiBridgeFailoverExhausted(c, failoverErr, true)
+					return
+				}
+				if failoverErr.ShouldReportAccountScheduleFailure() {
+					h.gatewayService.ReportOpenAIAccountScheduleResult(account.ID, account.GetMappedModel(reqModel), false, nil)
+				}
+				if !failoverErr.ShouldRetryNextAccount() {
+					writeGeminiBridgeFailoverExhausted(c, failoverErr, streamStarted)
+					return
+				}
+				if failoverErr.RetryableOnSameAccount {
+					retryLimit := account.GetPoolModeRetryCount()
+					if sameAccountRetryCount[account.ID] < retryLimit {
+						sameAccountRetryCount[account.ID]++
+						select {
+						case <-c.Request.Context().Done():
+							return
+						case <-time.After(sameAccountRetryDelay):
+						}
+						continue
+					}
+				}
+				h.gatewayService.RecordOpenAIAccountSwitch()
+				failedAccountIDs[account.ID] = struct{}{}
+				lastFailoverErr = failoverErr
+				if switchCount >= maxAccountSwitches {
+					writeGeminiBridgeFailoverExhausted(c, failoverErr, streamStarted)
+					return
+				}
+				switchCount++
+				if h.gatewayService.ShouldStopOpenAIOAuth429Failover(account, failoverErr.StatusCode, switchCount, &oauth429FailoverState) {
+					writeGeminiBridgeFailoverExhausted(c, failoverErr, streamStarted)
+					return
+				}
+				reqLog.Warn("openai_gemini.upstream_failover_switching",
+					zap.Int64("account_id", account.ID),
+					zap.Int("upstream_status", failoverErr.StatusCode),
+					zap.Int("switch_count", switchCount),
+				)
+				continue
+			}
+			h.gatewayService.ReportOpenAIAccountScheduleResult(account.ID, account.GetMappedModel(reqModel), false, nil)
+			alreadyWritten := openAIForwardErrorAlreadyCommunicated(c, writerSizeBeforeForward, err) || service.IsResponseCommitted(c)
+			if !alreadyWritten && !c.Writer.Written() {
+				googleError(c, http.StatusBadGateway, "Upstream request failed")
+			}
+			reqLog.Warn("openai_gemini.forward_failed",
+				zap.Int64("account_id", account.ID),
+				zap.Bool("upstream_error_response_already_written", alreadyWritten),
+				zap.Error(err),
+			)
+			return
+		}
+		var firstTokenMs *int
+		if result != nil {
+			firstTokenMs = result.FirstTokenMs
+		}
+		h.gatewayService.ReportOpenAIAccountScheduleResult(account.ID, account.GetMappedModel(reqModel), true, firstTokenMs)
+
+		userAgent := c.GetHeader("User-Agent")
+		clientIP := ip.GetClientIP(c)
+		inboundEndpoint := GetInboundEndpoint(c)
+		upstreamEndpoint := resolveOpenAIUpstreamEndpoint(c, account, result)
+		quotaPlatform := service.QuotaPlatform(c.Request.Context(), apiKey)
+		sessionID := service.ExtractClientSessionID(c)
+		cyberBlocked := service.GetOpsCyberPolicy(c) != nil
+		h.submitOpenAIUsageRecordTask(c.Request.Context(), result, func(ctx context.Context) {
+			if err := h.gatewayService.RecordUsage(ctx, &service.OpenAIRecordUsageInput{
+				Result:             result,
+				APIKey:             apiKey,
+				User:               apiKey.User,
+				Account:            account,
+				Subscription:       subscription,
+				InboundEndpoint:    inboundEndpoint,
+				UpstreamEndpoint:   upstreamEndpoint,
+				UserAgent:          userAgent,
+				IPAddress:          clientIP,
+				APIKeyService:      h.apiKeyService,
+				QuotaPlatform:      quotaPlatform,
+				SessionID:          sessionID,
+				ChannelUsageFields: clientRequestedUsageFields(c, channelMapping, reqModel, result.UpstreamModel),
+				PricingAt:          pricingAt,
+				CyberBlocked:       cyberBlocked,
+			}); err != nil {
+				logger.L().With(
+					zap.String("component", "handler.openai_gateway.gemini_v1beta"),
+					zap.Int64("api_key_id", apiKey.ID),
+					zap.String("model", reqModel),
+					zap.Int64("account_id", account.ID),
+				).Error("openai_gemini.record_usage_failed", zap.Error(err))
+			}
+		})
+		return
+	}
+}
@@ -164,7 +164,12 @@ func (h *GatewayHandler) GeminiV1BetaModels(c *gin.Context) {
 
 	// 检查平台：优先使用强制平台（/antigravity 路由，中间件已设置 request.Context），否则要求 gemini 分组
 	if !middleware.HasForcePlatform(c) {
-		if effectiveAPIKeyPlatform(c, apiKey) != service.PlatformGemini {
+		if platform := effectiveAPIKeyPlatform(c, apiKey); platform != service.PlatformGemini {
+			// anthropic 分组开启 allow_gemini_native 时经 Messages 转换链服务。
+			if platform == service.PlatformAnthropic && apiKey.Group.AllowsGeminiNative() {
+				h.geminiV1BetaModelsViaAnthropic(c, apiKey, authSubject, reqLog)
+				return
+			}
 			googleError(c, http.StatusBadRequest, "API key group platform is not gemini")
 			return
 		}
@@ -503,6 +508,7 @@ func (h *GatewayHandler) GeminiV1BetaModels(c *gin.Context) {
 		if fs.SwitchCount > 0 {
 			requestCtx = service.WithAccountSwitchCount(requestCtx, fs.SwitchCount, h.metadataBridgeEnabled())
 		}
+		requestCtx, endAttempt := fs.StartAttempt(requestCtx, account)
 		sessionGroupID := derefGroupID(apiKey.GroupID)
 		if account.Platform == service.PlatformAntigravity && account.Type != service.AccountTypeAPIKey {
 			result, err = h.antigravityGatewayService.ForwardGemini(
@@ -519,6 +525,8 @@ func (h *GatewayHandler) GeminiV1BetaModels(c *gin.Context) {
 		} else {
 			result, err = h.geminiCompatService.ForwardNative(requestCtx, c, account, modelName, action, stream, body)
 		}
+		setForwardTTFT(c, result, err)
+		endAttempt(err)
 		if accountReleaseFunc != nil {
 			accountReleaseFunc()
 		}
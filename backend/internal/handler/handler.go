@@ -41,6 +41,11 @@ type AdminHandlers struct {
 	Affiliate              *admin.AffiliateHandler
 	Compliance             *admin.ComplianceHandler
 	AuditLog               *admin.AuditLogHandler
+	Organization           *admin.OrganizationHandler
+	BalanceLedger          *admin.BalanceLedgerHandler
+	CreditStatement        *admin.CreditStatementHandler
+	SpendAnomaly           *admin.SpendAnomalyHandler
+	UsageExport            *admin.UsageExportHandler
 }
 
 // Handlers contains all HTTP handlers
@@ -66,6 +71,13 @@ type Handlers struct {
 	ModelPlaza       *ModelPlazaHandler
 	AsyncImage       *AsyncImageHandler
 	BatchImage       *BatchImageHandler
+	MessageBatch     *MessageBatchHandler
+	OpenAIBatch      *OpenAIBatchHandler
+	ResponseCache    *ResponseCacheHandler
+	TrafficMirror    *TrafficMirrorHandler
+	Organization     *OrganizationHandler
+	CreditStatement  *CreditStatementHandler
+	UsageExport      *UsageExportHandler
 }
 
 // BuildInfo contains build-time information
@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"go.uber.org/zap"
+)
+
+// MessageBatchHandler 提供 Anthropic Message Batches API（/v1/messages/batches）。
+type MessageBatchHandler struct {
+	service *service.MessageBatchService
+	gateway *GatewayHandler
+}
+
+func NewMessageBatchHandler(service *service.MessageBatchService) *MessageBatchHandler {
+	return &MessageBatchHandler{service: service}
+}
+
+// Create POST /v1/messages/batches
+func (h *MessageBatchHandler) Create(c *gin.Context) {
+	owner, ok := messageBatchOwnerFromContext(c)
+	if !ok {
+		messageBatchAuthError(c)
+		return
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		messageBatchError(c, service.ErrMessageBatchInvalidRequests.WithCause(err))
+		return
+	}
+	if !h.checkSecurityAuditBeforeCreate(c, body) {
+		return
+	}
+	job, err := h.service.Create(c.Request.Context(), owner, body, c.GetHeader("Idempotency-Key"))
+	if err != nil {
+		messageBatchError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, service.MessageBatchJobToPublic(job, messageBatchResultsBaseURL(c)))
+}
+
+// checkSecurityAuditBeforeCreate 逐条审核 requests[].params，任意一条被拦截即拒绝整个批次。
+func (h *MessageBatchHandler) checkSecurityAuditBeforeCreate(c *gin.Context, body []byte) bool {
+	if h == nil || h.gateway == nil {
+		return true
+	}
+	apiKey, ok := middleware.GetAPIKeyFromContext(c)
+	if !ok || apiKey == nil {
+		messageBatchAuthError(c)
+		return false
+	}
+	subject, ok := middleware.GetAuthSubjectFromContext(c)
+	if !ok {
+		messageBatchError(c, infraerrors.New(http.StatusInternalServerError, "USER_CONTEXT_REQUIRED", "User context not found"))
+		return false
+	}
+	reqLog := requestLogger(c, "handler.message_batch.security_audit",
+		zap.Int64("user_id", subject.UserID), zap.Int64("api_key_id", apiKey.ID))
+	for _, entry := range gjson.GetBytes(body, "requests").Array() {
+		params := entry.Get("params")
+		if !params.IsObject() {
+			// 结构错误交给服务层按批次校验规则返回。
+			continue
+		}
+		decision := h.gateway.checkSecurityAuditStage(c, reqLog, apiKey, subject,
+			service.ContentModerationProtocolAnthropicMessages, params.Get("model").String(), []byte(params.Raw), "batch")
+		if decision != nil && !decision.AllowNextStage {
+			h.gateway.anthropicSecurityAuditError(c, decision)
+			return false
+		}
+	}
+	return true
+}
+
+// List GET /v1/messages/batches
+func (h *MessageBatchHandler) List(c *gin.Context) {
+	owner, ok := messageBatchOwnerFromContext(c)
+	if !ok {
+		messageBatchAuthError(c)
+		return
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	query := service.MessageBatchListQuery{
+		BeforeID: strings.TrimSpace(c.Query("before_id")),
+		AfterID:  strings.TrimSpace(c.Query("after_id")),
+		Limit:    limit,
+	}
+	if query.BeforeID != "" && query.AfterID != "" {
+		messageBatchError(c, infraerrors.New(http.StatusBadRequest, "MESSAGE_BATCH_INVALID_CURSOR", "before_id and after_id cannot be used together"))
+		return
+	}
+	jobs, hasMore, err := h.service.List(c.Request.Context(), owner, query)
+	if err != nil {
+		messageBatchError(c, err)
+		return
+	}
+	baseURL := messageBatchResultsBaseURL(c)
+	resp := service.MessageBatchListResponse{Data: make([]*service.MessageBatchPublic, 0, len(jobs)), HasMore: hasMore}
+	for _, job := range jobs {
+		resp.Data = append(resp.Data, service.MessageBatchJobToPublic(job, baseURL))
+	}
+	if len(resp.Data) > 0 {
+		resp.FirstID = &resp.Data[0].ID
+		resp.LastID = &resp.Data[len(resp.Data)-1].ID
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// Get GET /v1/messages/batches/:id
+func (h *MessageBatchHandler) Get(c *gin.Context) {
+	owner, ok := messageBatchOwnerFromContext(c)
+	if !ok {
+		messageBatchAuthError(c)
+		return
+	}
+	job, err := h.service.Get(c.Request.Context(), owner, c.Param("id"))
+	if err != nil {
+		messageBatchError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, service.MessageBatchJobToPublic(job, messageBatchResultsBaseURL(c)))
+}
+
+// Cancel POST /v1/messages/batches/:id/cancel
+func (h *MessageBatchHandler) Cancel(c *gin.Context) {
+	owner, ok := messageBatchOwnerFromContext(c)
+	if !ok {
+		messageBatchAuthError(c)
+		return
+	}
+	job, err := h.service.Cancel(c.Request.Context(), owner, c.Param("id"))
+	if err != nil {
+		messageBatchError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, service.MessageBatchJobToPublic(job, messageBatchResultsBaseURL(c)))
+}
+
+// Results GET /v1/messages/batches/:id/results
+// 结果按上游 JSONL 原样流式转发，不在内存中缓冲。
+func (h *MessageBatchHandler) Results(c *gin.Context) {
+	owner, ok := messageBatchOwnerFromContext(c)
+	if !ok {
+		messageBatchAuthError(c)
+		return
+	}
+	results, err := h.service.OpenResults(c.Request.Context(), owner, c.Param("id"))
+	if err != nil {
+		messageBatchError(c, err)
+		return
+	}
+	defer func() { _ = results.Close() }()
+
+	c.Header("Content-Type", "application/x-jsonl")
+	c.Header("Cache-Control", "private, no-store")
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, results); err != nil {
+		logger.L().Warn("message_batch.results_stream_failed", zap.String("batch_id", c.Param("id")), zap.Error(err))
+	}
+}
+
+func messageBatchOwnerFromContext(c *gin.Context) (service.MessageBatchOwner, bool) {
+	apiKey, ok := middleware.GetAPIKeyFromContext(c)
+	if !ok || apiKey == nil || apiKey.ID <= 0 || apiKey.UserID <= 0 {
+		return service.MessageBatchOwner{}, false
+	}
+	return service.MessageBatchOwner{
+		UserID:   apiKey.UserID,
+		APIKeyID: apiKey.ID,
+		GroupID:  apiKey.GroupID,
+		APIKey:   apiKey,
+	}, true
+}
+
+func messageBatchResultsBaseURL(c *gin.Context) string {
+	scheme := "http"
+	if isRequestHTTPS(c) {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host
+}
+
+func messageBatchAuthError(c *gin.Context) {
+	messageBatchError(c, infraerrors.New(http.StatusUnauthorized, "API_KEY_REQUIRED", "Invalid API key"))
+}
+
+// messageBatchError 以 Claude API 错误格式返回。
+func messageBatchError(c *gin.Context, err error) {
+	status := infraerrors.Code(err)
+	message := infraerrors.Message(err)
+	if status == 0 || status == http.StatusInternalServerError {
+		status = http.StatusInternalServerError
+		message = "internal error"
+	}
+	c.JSON(status, gin.H{
+		"type": "error",
+		"error": gin.H{
+			"type":    messageBatchErrorType(status),
+			"message": message,
+		},
+	})
+}
+
+func messageBatchErrorType(status int) string {
+	switch status {
+	case http.StatusUnauthorized:
+		return "authentication_error"
+	case http.StatusPaymentRequired:
+		return "billing_error"
+	case http.StatusForbidden:
+		return "permission_error"
+	case http.StatusNotFound:
+		return "not_found_error"
+	case http.StatusRequestEntityTooLarge:
+		return "request_too_large"
+	case http.StatusTooManyRequests:
+		return "rate_limit_error"
+	case http.StatusServiceUnavailable, 529:
+		return "overloaded_error"
+	}
+	if status >= http.StatusInternalServerError {
+		return "api_error"
+	}
+	return "invalid_request_error"
+}
@@ -0,0 +1,231 @@
+package handler
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	pkghttputil "github.com/Wei-Shaw/sub2api/internal/pkg/httputil"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"go.uber.org/zap"
+)
+
+const (
+	// ModelFallbackHeader 请求头：逗号分隔的有序降级模型列表，如 "gpt-5, gemini-2.5-pro"。
+	ModelFallbackHeader = "X-Sub2API-Fallback-Models"
+	// ModelUsedHeader 响应头：携带降级链的请求最终实际使用的模型。
+	ModelUsedHeader = "X-Sub2API-Model"
+
+	// modelFallbackBodyField 请求体字段，与请求头等价（字符串数组或逗号分隔字符串），转发前会被移除。
+	modelFallbackBodyField = "fallback_models"
+	// maxModelFallbacks 单次请求最多接受的降级模型数，超出部分忽略。
+	maxModelFallbacks = 4
+	// modelFallbackTrailSeparator 用于 usage_logs.model_mapping_chain 中标记降级跳转，区别于映射链的 "→"。
+	modelFallbackTrailSeparator = " ⇢ "
+
+	ctxKeyModelFallback = "model_fallback_state"
+)
+
+// modelFallbackTarget 是降级链上一个已解析的候选模型。
+type modelFallbackTarget struct {
+	model    string
+	decision service.CompositeRouteDecision
+}
+
+// modelFallbackState 记录一次请求在降级链上的进度，由 ModelFallback 挂到 gin.Context。
+type modelFallbackState struct {
+	remaining  []modelFallbackTarget
+	tried      []string
+	pending    bool
+	lastStatus int
+}
+
+func modelFallbackStateFromContext(c *gin.Context) *modelFallbackState {
+	if c == nil {
+		return nil
+	}
+	value, ok := c.Get(ctxKeyModelFallback)
+	if !ok {
+		return nil
+	}
+	state, _ := value.(*modelFallbackState)
+	return state
+}
+
+// modelFallbackTrail 返回已失败模型的降级轨迹前缀（如 "claude-opus-4-1 ⇢ "），无降级时为空。
+func modelFallbackTrail(c *gin.Context) string {
+	state := modelFallbackStateFromContext(c)
+	if state == nil || len(state.tried) == 0 {
+		return ""
+	}
+	return strings.Join(state.tried, modelFallbackTrailSeparator) + modelFallbackTrailSeparator
+}
+
+// ModelFallback 包装按平台分流的处理器 next，支持客户端指定的模型降级链。
+//
+// 当前模型在 failover 循环中因限流、容量或模型不可用而耗尽时，处理器通过
+// deferToModelFallback 放弃写错误；这里改写请求体的 model，并对 composite 分组重新解析
+// 目标平台后再次调用 next。目标平台变化时 next 会分流到对应协议的处理器，由其中已有的
+// apicompat 转换完成跨协议调用。响应头 ModelUsedHeader 与使用记录中的模型均为最终实际使用的模型。
+func ModelFallback(resolver *service.CompositeRouteResolver, endpoint string, next gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request == nil || c.Request.Method != http.MethodPost || c.Request.Body == nil {
+			next(c)
+			return
+		}
+		body, err := pkghttputil.ReadRequestBodyWithPrealloc(c.Request)
+		if err != nil {
+			// 读取失败（如超出大小限制）交给处理器按各自协议报告。
+			c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), modelFallbackErrReader{err: err}))
+			next(c)
+			return
+		}
+		chain := parseModelFallbackChain(c.GetHeader(ModelFallbackHeader), body)
+		c.Request.Header.Del(ModelFallbackHeader)
+		if gjson.GetBytes(body, modelFallbackBodyField).Exists() {
+			if stripped, err := sjson.DeleteBytes(body, modelFallbackBodyField); err == nil {
+				body = stripped
+			}
+		}
+		if len(chain) == 0 {
+			setModelFallbackRequestBody(c, body)
+			next(c)
+			return
+		}
+
+		baseCtx := c.Request.Context()
+		model := strings.TrimSpace(gjson.GetBytes(body, "model").String())
+		publicModel := model
+		if requested, ok := service.RequestedPublicModelFromContext(baseCtx); ok {
+			publicModel = requested
+		}
+		state := &modelFallbackState{remaining: resolveModelFallbackTargets(c, resolver, endpoint, publicModel, chain)}
+		if model == "" || len(state.remaining) == 0 {
+			setModelFallbackRequestBody(c, body)
+			next(c)
+			return
+		}
+		c.Set(ctxKeyModelFallback, state)
+
+		reqLog := requestLogger(c, "handler.model_fallback")
+		for {
+			setModelFallbackRequestBody(c, body)
+			c.Header(ModelUsedHeader, publicModel)
+			next(c)
+			if !state.pending || c.Writer.Written() {
+				return
+			}
+			state.pending = false
+			state.tried = append(state.tried, publicModel)
+
+			target := state.remaining[0]
+			state.remaining = state.remaining[1:]
+			reqLog.Info("gateway.model_fallback_switch",
+				zap.String("from_model", publicModel),
+				zap.String("to_model", target.model),
+				zap.String("target_platform", target.decision.TargetPlatform),
+				zap.Int("trigger_status", state.lastStatus),
+				zap.Int("remaining", len(state.remaining)),
+			)
+			publicModel = target.model
+			upstreamModel := target.model
+			ctx := baseCtx
+			if target.decision.Matched {
+				ctx = service.WithCompositeRouteDecision(ctx, target.decision)
+				if m := strings.TrimSpace(target.decision.UpstreamModel); m != "" {
+					upstreamModel = m
+				}
+			}
+			if rewritten, err := sjson.SetBytes(body, "model", upstreamModel); err == nil {
+				body = rewritten
+			}
+			c.Request = c.Request.WithContext(ctx)
+			// 上一个模型的 Retry-After 只描述它自己的限流窗口。
+			c.Writer.Header().Del("Retry-After")
+		}
+	}
+}
+
+// resolveModelFallbackTargets 预先解析降级链：API Key 模型策略不允许的模型直接剔除，
+// composite 分组按路由规则/内置识别确定每个模型的目标平台，无法解析的模型同样剔除，
+// 保证 deferToModelFallback 判断"还有下一个"时不落空。
+func resolveModelFallbackTargets(c *gin.Context, resolver *service.CompositeRouteResolver, endpoint, primary string, chain []string) []modelFallbackTarget {
+	apiKey, _ := middleware2.GetAPIKeyFromContext(c)
+	composite := apiKey != nil && apiKey.Group != nil && apiKey.Group.Platform == service.PlatformComposite
+	seen := map[string]struct{}{strings.ToLower(primary): {}}
+	targets := make([]modelFallbackTarget, 0, len(chain))
+	for _, model := range chain {
+		key := strings.ToLower(model)
+		if _, dup := seen[key]; dup {
+			continue
+		}
+		seen[key] = struct{}{}
+		if apiKey != nil && !apiKey.IsModelAllowed(model) {
+			continue
+		}
+		target := modelFallbackTarget{model: model}
+		if composite {
+			decision, err := resolver.ResolveFor(c.Request.Context(), apiKey.Group.ID, model, endpoint,
+				service.CompositeRouteSubject{APIKeyID: apiKey.ID, UserID: apiKey.UserID})
+			if err != nil || !decision.Matched {
+				continue
+			}
+			target.decision = decision
+		}
+		targets = append(targets, target)
+		if len(targets) >= maxModelFallbacks {
+			break
+		}
+	}
+	return targets
+}
+
+// parseModelFallbackChain 合并请求头与请求体中的降级模型；请求头优先。
+func parseModelFallbackChain(header string, body []byte) []string {
+	var chain []string
+	for _, model := range strings.Split(header, ",") {
+		if model = strings.TrimSpace(model); model != "" {
+			chain = append(chain, model)
+		}
+	}
+	field := gjson.GetBytes(body, modelFallbackBodyField)
+	switch {
+	case field.IsArray():
+		for _, item := range field.Array() {
+			if item.Type != gjson.String {
+				continue
+			}
+			if model := strings.TrimSpace(item.String()); model != "" {
+				chain = append(chain, model)
+			}
+		}
+	case field.Type == gjson.String:
+		for _, model := range strings.Split(field.String(), ",") {
+			if model = strings.TrimSpace(model); model != "" {
+				chain = append(chain, model)
+			}
+		}
+	}
+	return chain
+}
+
+func setModelFallbackRequestBody(c *gin.Context, body []byte) {
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	c.Request.ContentLength = int64(len(body))
+	c.Request.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	c.Request.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+}
+
+// modelFallbackErrReader 在已读出的部分之后重放原始读取错误。
+type modelFallbackErrReader struct{ err error }
+
+func (r modelFallbackErrReader) Read([]byte) (int, error) { return 0, r.err }
@@ -0,0 +1,158 @@
+//go:build unit
+
+package handler
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	middleware "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func newModelFallbackTestContext(body, header string, group *service.Group) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	if header != "" {
+		c.Request.Header.Set(ModelFallbackHeader, header)
+	}
+	groupID := int64(1)
+	if group != nil {
+		groupID = group.ID
+	}
+	c.Set(string(middleware.ContextKeyAPIKey), &service.APIKey{ID: 1, GroupID: &groupID, Group: group})
+	return c, w
+}
+
+func TestParseModelFallbackChain(t *testing.T) {
+	chain := parseModelFallbackChain(" gpt-5 , ,gemini-2.5-pro", []byte(`{"fallback_models":["claude-sonnet-4-5",1,""]}`))
+	require.Equal(t, []string{"gpt-5", "gemini-2.5-pro", "claude-sonnet-4-5"}, chain)
+
+	require.Equal(t, []string{"a", "b"}, parseModelFallbackChain("", []byte(`{"fallback_models":"a, b"}`)))
+	require.Empty(t, parseModelFallbackChain("", []byte(`{"model":"x"}`)))
+}
+
+func TestModelFallback_WalksChainOnCapacityErrors(t *testing.T) {
+	c, w := newModelFallbackTestContext(`{"model":"claude-opus-4-1","fallback_models":["claude-opus-4-1","claude-sonnet-4-5","claude-haiku-4-5"]}`, "", &service.Group{ID: 1, Platform: service.PlatformAnthropic})
+
+	var seen []string
+	handler := ModelFallback(nil, service.CompositeRouteEndpointMessages, func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		require.NoError(t, err)
+		require.False(t, gjson.GetBytes(body, modelFallbackBodyField).Exists(), "fallback_models must not reach upstream")
+		model := gjson.GetBytes(body, "model").String()
+		seen = append(seen, model)
+		switch model {
+		case "claude-opus-4-1":
+			require.True(t, deferToModelFallback(c, http.StatusTooManyRequests, false))
+		case "claude-sonnet-4-5":
+			require.True(t, deferToModelFallback(c, 529, false))
+		default:
+			require.Equal(t, "claude-opus-4-1 ⇢ claude-sonnet-4-5 ⇢ claude-haiku-4-5",
+				clientRequestedUsageFields(c, service.ChannelMappingResult{}, model, model).ModelMappingChain)
+			c.JSON(http.StatusOK, gin.H{"model": model})
+		}
+	})
+	handler(c)
+
+	require.Equal(t, []string{"claude-opus-4-1", "claude-sonnet-4-5", "claude-haiku-4-5"}, seen, "duplicates of the primary model are dropped")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "claude-haiku-4-5", w.Header().Get(ModelUsedHeader))
+}
+
+func TestModelFallback_LastModelAndIneligibleErrorsAreNotDeferred(t *testing.T) {
+	c, w := newModelFallbackTestContext(`{"model":"gpt-5"}`, "gpt-5-mini", &service.Group{ID: 1, Platform: service.PlatformOpenAI})
+
+	calls := 0
+	handler := ModelFallback(nil, service.CompositeRouteEndpointChatCompletions, func(c *gin.Context) {
+		calls++
+		require.Empty(t, c.Request.Header.Get(ModelFallbackHeader))
+		if calls == 1 {
+			require.False(t, deferToModelFallback(c, http.StatusUnauthorized, false), "auth errors are not model problems")
+			require.False(t, deferToModelFallback(c, http.StatusServiceUnavailable, true), "stream already started")
+			require.True(t, deferToModelFallback(c, http.StatusServiceUnavailable, false))
+			return
+		}
+		require.False(t, deferToModelFallback(c, http.StatusServiceUnavailable, false), "no model left in the chain")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "exhausted"})
+	})
+	handler(c)
+
+	require.Equal(t, 2, calls)
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	require.Equal(t, "gpt-5-mini", w.Header().Get(ModelUsedHeader))
+}
+
+func TestModelFallback_CompositeGroupReResolvesTargetPlatform(t *testing.T) {
+	c, w := newModelFallbackTestContext(`{"model":"claude-opus-4-1"}`, "unknown-model, gpt-5", &service.Group{ID: 7, Platform: service.PlatformComposite})
+	c.Request = c.Request.WithContext(service.WithCompositeRouteDecision(c.Request.Context(), service.CompositeRouteDecision{
+		Matched:        true,
+		Source:         service.CompositeRouteSourceDetector,
+		PublicModel:    "claude-opus-4-1",
+		TargetPlatform: service.PlatformAnthropic,
+		UpstreamModel:  "claude-opus-4-1",
+	}))
+
+	var platforms []string
+	handler := ModelFallback(nil, service.CompositeRouteEndpointMessages, func(c *gin.Context) {
+		platform, _ := service.ResolvedTargetPlatformFromContext(c.Request.Context())
+		platforms = append(platforms, platform)
+		if platform == service.PlatformAnthropic {
+			require.True(t, deferToModelFallback(c, http.StatusNotFound, false))
+			return
+		}
+		requested, _ := service.RequestedPublicModelFromContext(c.Request.Context())
+		require.Equal(t, "gpt-5", requested)
+		c.Status(http.StatusOK)
+	})
+	handler(c)
+
+	require.Equal(t, []string{service.PlatformAnthropic, service.PlatformOpenAI}, platforms, "unresolvable fallback models are skipped")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "gpt-5", w.Header().Get(ModelUsedHeader))
+}
+
+func TestModelFallback_SkipsModelsDeniedByAPIKeyPolicy(t *testing.T) {
+	c, w := newModelFallbackTestContext(`{"model":"gpt-5"}`, "o3-pro, gpt-5-mini", &service.Group{ID: 1, Platform: service.PlatformOpenAI})
+	apiKey, ok := middleware.GetAPIKeyFromContext(c)
+	require.True(t, ok)
+	apiKey.ModelDenylist = []string{"o3-*"}
+
+	var seen []string
+	handler := ModelFallback(nil, service.CompositeRouteEndpointChatCompletions, func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		require.NoError(t, err)
+		model := gjson.GetBytes(body, "model").String()
+		seen = append(seen, model)
+		if model == "gpt-5" {
+			require.True(t, deferToModelFallback(c, http.StatusTooManyRequests, false))
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"model": model})
+	})
+	handler(c)
+
+	require.Equal(t, []string{"gpt-5", "gpt-5-mini"}, seen, "denylisted fallback models must never be sent upstream")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "gpt-5-mini", w.Header().Get(ModelUsedHeader))
+}
+
+func TestModelFallback_NoChainPassesThrough(t *testing.T) {
+	c, _ := newModelFallbackTestContext(`{"model":"gpt-5"}`, "", nil)
+	called := false
+	ModelFallback(nil, service.CompositeRouteEndpointResponses, func(c *gin.Context) {
+		called = true
+		require.Nil(t, modelFallbackStateFromContext(c))
+		require.False(t, deferToModelFallback(c, http.StatusServiceUnavailable, false))
+	})(c)
+	require.True(t, called)
+}
@@ -0,0 +1,487 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/apicompat"
+	pkghttputil "github.com/Wei-Shaw/sub2api/internal/pkg/httputil"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+)
+
+// Ollama 兼容层：/api/chat、/api/generate 在入口转换为 Chat Completions 请求，
+// 之后完全复用 /v1/chat/completions 的鉴权、调度与计费链路；响应由
+// ollamaResponseWriter 在写出时转换回 Ollama JSON / NDJSON。
+// /api/tags、/api/show 的模型来自分组模型列表配置（与 /v1/models 一致）。
+
+// ctxKeyOllamaLoadOnly 标记空请求：Ollama 客户端用它预加载模型，直接返回 done_reason=load。
+const ctxKeyOllamaLoadOnly = "_ollama_load_only"
+
+type ollamaWriterMode int
+
+const (
+	ollamaModeErrorsOnly ollamaWriterMode = iota // 成功响应原样透传，仅转换错误
+	ollamaModeChat
+	ollamaModeGenerate
+)
+
+// ollamaResponseWriter 把下游 Chat Completions 响应转换为 Ollama 格式：
+// 流式 SSE 逐行转为 NDJSON；非流式与错误响应缓冲后在 finish 中一次性转换。
+// Size/Written 反映下游写入量，使 failover 判断与未包装时一致。
+type ollamaResponseWriter struct {
+	gin.ResponseWriter
+	mode   ollamaWriterMode
+	model  string
+	stream bool
+
+	state         *apicompat.ChatCompletionsToOllamaState
+	size          int
+	buf           bytes.Buffer
+	headersFixed  bool
+	streamErrored bool
+}
+
+func newOllamaResponseWriter(w gin.ResponseWriter, mode ollamaWriterMode, model string, stream bool) *ollamaResponseWriter {
+	return &ollamaResponseWriter{
+		ResponseWriter: w,
+		mode:           mode,
+		model:          model,
+		stream:         stream,
+		state:          apicompat.NewChatCompletionsToOllamaState(model, mode == ollamaModeGenerate),
+		size:           -1,
+	}
+}
+
+// buffered 报告当前写入是否需要缓冲到 finish 再转换。
+func (w *ollamaResponseWriter) buffered() bool {
+	if w.ResponseWriter.Status() >= http.StatusBadRequest {
+		return true
+	}
+	switch w.mode {
+	case ollamaModeErrorsOnly:
+		return false
+	default:
+		return !w.stream
+	}
+}
+
+func (w *ollamaResponseWriter) Write(b []byte) (int, error) {
+	if w.size < 0 {
+		w.size = 0
+	}
+	w.size += len(b)
+	if w.buffered() {
+		return w.buf.Write(b)
+	}
+	if w.mode == ollamaModeErrorsOnly {
+		return w.ResponseWriter.Write(b)
+	}
+	w.buf.Write(b)
+	if err := w.drainSSELines(); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (w *ollamaResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *ollamaResponseWriter) WriteHeaderNow() {
+	if w.size < 0 {
+		w.size = 0
+	}
+	if w.buffered() {
+		return
+	}
+	w.fixHeaders()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *ollamaResponseWriter) Flush() {
+	if w.buffered() {
+		return
+	}
+	w.fixHeaders()
+	w.ResponseWriter.Flush()
+}
+
+func (w *ollamaResponseWriter) Size() int {
+	return w.size
+}
+
+func (w *ollamaResponseWriter) Written() bool {
+	return w.size != -1
+}
+
+func (w *ollamaResponseWriter) fixHeaders() {
+	if w.headersFixed || w.mode == ollamaModeErrorsOnly && w.ResponseWriter.Status() < http.StatusBadRequest {
+		return
+	}
+	w.headersFixed = true
+	h := w.ResponseWriter.Header()
+	h.Del("Content-Length")
+	if w.stream && w.ResponseWriter.Status() < http.StatusBadRequest {
+		h.Set("Content-Type", "application/x-ndjson")
+		return
+	}
+	h.Set("Content-Type", "application/json; charset=utf-8")
+}
+
+// drainSSELines 消费缓冲区中完整的 SSE 行并输出对应的 NDJSON 行。
+func (w *ollamaResponseWriter) drainSSELines() error {
+	for {
+		data := w.buf.Bytes()
+		idx := bytes.IndexByte(data, '\n')
+		if idx < 0 {
+			return nil
+		}
+		line := strings.TrimSpace(string(data[:idx]))
+		w.buf.Next(idx + 1)
+		if err := w.handleSSELine(line); err != nil {
+			return err
+		}
+	}
+}
+
+func (w *ollamaResponseWriter) handleSSELine(line string) error {
+	payload, ok := strings.CutPrefix(line, "data:")
+	if !ok {
+		return nil // event:、注释与 keepalive 行在 NDJSON 中没有对应
+	}
+	payload = strings.TrimSpace(payload)
+	if payload == "" {
+		return nil
+	}
+	if payload == "[DONE]" {
+		return w.emit(apicompat.FinalizeChatCompletionsOllamaStream(w.state))
+	}
+	if w.state.Finalized {
+		return nil
+	}
+	if gjson.Get(payload, "error").Exists() {
+		w.streamErrored = true
+		w.state.Finalized = true
+		return w.emitRaw(ollamaErrorBody([]byte(payload)))
+	}
+	var chunk apicompat.ChatCompletionsChunk
+	if err := json.Unmarshal([]byte(payload), &chunk); err != nil {
+		return nil
+	}
+	return w.emit(apicompat.ChatCompletionsChunkToOllama(&chunk, w.state))
+}
+
+func (w *ollamaResponseWriter) emit(lines []apicompat.OllamaResponse) error {
+	for _, line := range lines {
+		data, err := apicompat.OllamaResponseToNDJSON(line)
+		if err != nil {
+			return err
+		}
+		if err := w.emitRaw(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *ollamaResponseWriter) emitRaw(data []byte) error {
+	w.fixHeaders()
+	if _, err := w.ResponseWriter.Write(data); err != nil {
+		return err
+	}
+	w.ResponseWriter.Flush()
+	return nil
+}
+
+// finish 在下游处理结束后输出缓冲的响应，或补齐流式响应的 done 行。
+func (w *ollamaResponseWriter) finish() {
+	if w.size < 0 {
+		return
+	}
+	if w.buffered() {
+		body := w.buf.Bytes()
+		w.fixHeaders()
+		if w.ResponseWriter.Status() >= http.StatusBadRequest {
+			_, _ = w.ResponseWriter.Write(ollamaErrorBody(body))
+			return
+		}
+		var resp apicompat.ChatCompletionsResponse
+		if err := json.Unmarshal(body, &resp); err != nil {
+			_, _ = w.ResponseWriter.Write(body)
+			return
+		}
+		data, _ := json.Marshal(apicompat.ChatCompletionsToOllama(&resp, w.model, w.mode == ollamaModeGenerate))
+		_, _ = w.ResponseWriter.Write(data)
+		return
+	}
+	if w.mode == ollamaModeErrorsOnly {
+		return
+	}
+	if w.buf.Len() > 0 {
+		_ = w.handleSSELine(strings.TrimSpace(w.buf.String()))
+		w.buf.Reset()
+	}
+	if !w.streamErrored {
+		_ = w.emit(apicompat.FinalizeChatCompletionsOllamaStream(w.state))
+	}
+}
+
+// ollamaErrorBody 把任意网关错误体（OpenAI / Anthropic / 通用格式）转为 {"error":"..."}。
+func ollamaErrorBody(body []byte) []byte {
+	message := ""
+	for _, path := range []string{"error.message", "error", "message"} {
+		if v := gjson.GetBytes(body, path); v.Type == gjson.String && v.String() != "" {
+			message = v.String()
+			break
+		}
+	}
+	if message == "" {
+		message = strings.TrimSpace(string(body))
+	}
+	if message == "" {
+		message = "upstream request failed"
+	}
+	data, _ := json.Marshal(apicompat.OllamaErrorResponse{Error: message})
+	return append(data, '\n')
+}
+
+func ollamaError(c *gin.Context, status int, message string) {
+	c.AbortWithStatusJSON(status, apicompat.OllamaErrorResponse{Error: message})
+}
+
+// OllamaErrorMiddleware 把后续中间件与处理器写出的错误转换为 Ollama 的
+// {"error":"..."} 格式，成功响应原样透传。用于 /api/tags、/api/show。
+func OllamaErrorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		w := newOllamaResponseWriter(c.Writer, ollamaModeErrorsOnly, "", false)
+		c.Writer = w
+		defer func() { c.Writer = w.ResponseWriter }()
+		c.Next()
+		w.finish()
+	}
+}
+
+// OllamaCompatMiddleware 把 /api/chat（generate=false）或 /api/generate
+// （generate=true）请求体改写为 Chat Completions 请求，并包装响应写出器。
+// 需放在 API Key 鉴权之前，使鉴权与调度错误同样以 Ollama 格式返回。
+func OllamaCompatMiddleware(generate bool) gin.HandlerFunc {
+	mode := ollamaModeChat
+	if generate {
+		mode = ollamaModeGenerate
+	}
+	return func(c *gin.Context) {
+		body, err := pkghttputil.ReadRequestBodyWithPrealloc(c.Request)
+		if err != nil {
+			if maxErr, ok := extractMaxBytesError(err); ok {
+				ollamaError(c, http.StatusRequestEntityTooLarge, buildBodyTooLargeMessage(maxErr.Limit))
+				return
+			}
+			ollamaError(c, http.StatusBadRequest, "failed to read request body")
+			return
+		}
+
+		var (
+			chatReq   *apicompat.ChatCompletionsRequest
+			model     string
+			stream    bool
+			loadOnly  bool
+			convErr   error
+			decodeErr error
+		)
+		if generate {
+			var req apicompat.OllamaGenerateRequest
+			if decodeErr = json.Unmarshal(body, &req); decodeErr == nil {
+				model, stream = req.Model, apicompat.OllamaStreamRequested(req.Stream)
+				loadOnly = req.Prompt == "" && len(req.Images) == 0
+				chatReq, convErr = apicompat.OllamaGenerateToChatCompletions(&req)
+			}
+		} else {
+			var req apicompat.OllamaChatRequest
+			if decodeErr = json.Unmarshal(body, &req); decodeErr == nil {
+				model, stream = req.Model, apicompat.OllamaStreamRequested(req.Stream)
+				loadOnly = len(req.Messages) == 0
+				chatReq, convErr = apicompat.OllamaChatToChatCompletions(&req)
+			}
+		}
+		if decodeErr != nil {
+			ollamaError(c, http.StatusBadRequest, "invalid request body: "+decodeErr.Error())
+			return
+		}
+		if strings.TrimSpace(model) == "" {
+			ollamaError(c, http.StatusBadRequest, "model is required")
+			return
+		}
+		if convErr != nil {
+			ollamaError(c, http.StatusBadRequest, convErr.Error())
+			return
+		}
+
+		converted, err := json.Marshal(chatReq)
+		if err != nil {
+			ollamaError(c, http.StatusInternalServerError, "failed to convert request")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(converted))
+		c.Request.ContentLength = int64(len(converted))
+		c.Request.Header.Set("Content-Length", strconv.Itoa(len(converted)))
+		c.Set(ctxKeyOllamaLoadOnly, loadOnly)
+
+		w := newOllamaResponseWriter(c.Writer, mode, model, stream)
+		c.Writer = w
+		defer func() { c.Writer = w.ResponseWriter }()
+		c.Next()
+		w.finish()
+	}
+}
+
+// OllamaCompletions 包装 Chat Completions 处理器 next；空的预加载请求
+// 在鉴权通过后直接返回 done_reason=load，不占用上游。
+func OllamaCompletions(next gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		w, ok := c.Writer.(*ollamaResponseWriter)
+		if !ok || !c.GetBool(ctxKeyOllamaLoadOnly) {
+			next(c)
+			return
+		}
+		resp := apicompat.OllamaResponse{
+			Model:      w.model,
+			CreatedAt:  time.Now().UTC().Format(time.RFC3339Nano),
+			Done:       true,
+			DoneReason: "load",
+		}
+		if w.mode == ollamaModeGenerate {
+			empty := ""
+			resp.Response = &empty
+		} else {
+			resp.Message = &apicompat.OllamaMessage{Role: "assistant"}
+		}
+		w.mode = ollamaModeErrorsOnly // 已是 Ollama 格式，无需再转换
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+// ollamaModelDetails 是 /api/tags 与 /api/show 中的 details 字段。
+type ollamaModelDetails struct {
+	ParentModel       string   `json:"parent_model"`
+	Format            string   `json:"format"`
+	Family            string   `json:"family"`
+	Families          []string `json:"families"`
+	ParameterSize     string   `json:"parameter_size"`
+	QuantizationLevel string   `json:"quantization_level"`
+}
+
+type ollamaTagModel struct {
+	Name       string             `json:"name"`
+	Model      string             `json:"model"`
+	ModifiedAt string             `json:"modified_at"`
+	Size       int64              `json:"size"`
+	Digest     string             `json:"digest"`
+	Details    ollamaModelDetails `json:"details"`
+}
+
+// ollamaModelIDs 返回 Key 可见的模型：分组启用自定义模型列表时取其配置，
+// 否则取账号可用模型并回落到平台默认列表，最后按 Key 级模型策略过滤。
+func (h *GatewayHandler) ollamaModelIDs(c *gin.Context) (string, []string) {
+	apiKey, _ := middleware2.GetAPIKeyFromContext(c)
+	var groupID *int64
+	platform := ""
+	if apiKey != nil && apiKey.Group != nil {
+		groupID = &apiKey.Group.ID
+		platform = apiKey.Group.Platform
+	}
+
+	var models []string
+	if platform == service.PlatformComposite {
+		models = h.compositeAvailableModels(c.Request.Context(), groupID)
+	} else {
+		models = h.gatewayService.GetAvailableModels(c.Request.Context(), groupID, platform)
+	}
+	fallback := defaultModelIDsForPlatform(platform)
+	if apiKey != nil && apiKey.Group != nil && apiKey.Group.CustomModelsListEnabled() {
+		models = filterModelsByCustomList(customModelsListSource(platform, models, fallback), fallback, apiKey.Group.ModelsListConfig.Models)
+	} else if len(models) == 0 {
+		models = fallback
+	}
+	return platform, apiKey.ModelPolicy().FilterModelIDs(models)
+}
+
+func ollamaDetailsForPlatform(platform string) ollamaModelDetails {
+	return ollamaModelDetails{Format: "api", Family: platform, Families: []string{platform}}
+}
+
+func ollamaDigest(model string) string {
+	sum := sha256.Sum256([]byte(model))
+	return hex.EncodeToString(sum[:])
+}
+
+// OllamaTags lists models in Ollama format.
+// GET /api/tags
+func (h *GatewayHandler) OllamaTags(c *gin.Context) {
+	platform, modelIDs := h.ollamaModelIDs(c)
+	modifiedAt := time.Now().UTC().Format(time.RFC3339)
+	models := make([]ollamaTagModel, 0, len(modelIDs))
+	for _, id := range modelIDs {
+		models = append(models, ollamaTagModel{
+			Name:       id,
+			Model:      id,
+			ModifiedAt: modifiedAt,
+			Digest:     ollamaDigest(id),
+			Details:    ollamaDetailsForPlatform(platform),
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"models": models})
+}
+
+// OllamaShow describes a single model in Ollama format.
+// POST /api/show
+func (h *GatewayHandler) OllamaShow(c *gin.Context) {
+	var req struct {
+		Model string `json:"model"`
+		Name  string `json:"name"` // 旧版客户端字段
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ollamaError(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	requested := strings.TrimSpace(req.Model)
+	if requested == "" {
+		requested = strings.TrimSpace(req.Name)
+	}
+	if requested == "" {
+		ollamaError(c, http.StatusBadRequest, "model is required")
+		return
+	}
+
+	model := apicompat.NormalizeOllamaModelName(requested)
+	platform, modelIDs := h.ollamaModelIDs(c)
+	found := false
+	for _, id := range modelIDs {
+		if id == model {
+			found = true
+			break
+		}
+	}
+	if !found {
+		ollamaError(c, http.StatusNotFound, "model '"+requested+"' not found")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"modelfile":    "",
+		"parameters":   "",
+		"template":     "",
+		"details":      ollamaDetailsForPlatform(platform),
+		"model_info":   gin.H{"general.basename": model},
+		"capabilities": []string{"completion", "tools"},
+		"modified_at":  time.Now().UTC().Format(time.RFC3339),
+	})
+}
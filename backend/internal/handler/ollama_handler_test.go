@@ -0,0 +1,117 @@
+//go:build unit
+
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newOllamaTestRouter(generate bool, next gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	path := "/api/chat"
+	if generate {
+		path = "/api/generate"
+	}
+	r.POST(path, OllamaCompatMiddleware(generate), OllamaCompletions(next))
+	return r
+}
+
+func TestOllamaCompatMiddleware_StreamsNDJSON(t *testing.T) {
+	var upstreamBody map[string]any
+	r := newOllamaTestRouter(false, func(c *gin.Context) {
+		raw, _ := io.ReadAll(c.Request.Body)
+		require.NoError(t, json.Unmarshal(raw, &upstreamBody))
+		c.Header("Content-Type", "text/event-stream")
+		c.Status(http.StatusOK)
+		// SSE 帧被刻意拆分到多次写入，验证行缓冲。
+		_, _ = c.Writer.WriteString(`data: {"choices":[{"index":0,"delta":{"content":"Hel`)
+		_, _ = c.Writer.WriteString("lo\"}}]}\n\n: ping\n\n")
+		_, _ = c.Writer.WriteString(`data: {"choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}` + "\n\n")
+		_, _ = c.Writer.WriteString(`data: {"choices":[],"usage":{"prompt_tokens":4,"completion_tokens":2,"total_tokens":6}}` + "\n\ndata: [DONE]\n\n")
+		c.Writer.Flush()
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/chat", strings.NewReader(`{"model":"gpt-5:latest","messages":[{"role":"user","content":"hi"}]}`))
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+	require.Equal(t, "gpt-5", upstreamBody["model"])
+	require.Equal(t, true, upstreamBody["stream"])
+
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	require.Len(t, lines, 2)
+	require.JSONEq(t, `"Hello"`, mustJSONField(t, lines[0], "message", "content"))
+	var done map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &done))
+	require.Equal(t, true, done["done"])
+	require.Equal(t, "gpt-5:latest", done["model"])
+	require.EqualValues(t, 4, done["prompt_eval_count"])
+	require.EqualValues(t, 2, done["eval_count"])
+}
+
+func TestOllamaCompatMiddleware_NonStreamAndErrors(t *testing.T) {
+	r := newOllamaTestRouter(true, func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"choices": []gin.H{{"index": 0, "message": gin.H{"role": "assistant", "content": "42"}, "finish_reason": "length"}},
+			"usage":   gin.H{"prompt_tokens": 3, "completion_tokens": 1},
+		})
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/generate", strings.NewReader(`{"model":"m","prompt":"q","stream":false}`)))
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, "42", resp["response"])
+	require.Equal(t, "length", resp["done_reason"])
+
+	r = newOllamaTestRouter(false, func(c *gin.Context) {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": gin.H{"type": "rate_limit_error", "message": "slow down"}})
+	})
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/chat", strings.NewReader(`{"model":"m","messages":[{"role":"user","content":"hi"}]}`)))
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.JSONEq(t, `{"error":"slow down"}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/chat", strings.NewReader(`{"messages":[]}`)))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.JSONEq(t, `{"error":"model is required"}`, w.Body.String())
+}
+
+func TestOllamaCompletions_LoadOnlySkipsUpstream(t *testing.T) {
+	called := false
+	r := newOllamaTestRouter(false, func(c *gin.Context) { called = true })
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/chat", strings.NewReader(`{"model":"m","messages":[]}`)))
+	require.False(t, called)
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, "load", resp["done_reason"])
+	require.Equal(t, true, resp["done"])
+}
+
+func mustJSONField(t *testing.T, line string, path ...string) string {
+	t.Helper()
+	var cur any
+	require.NoError(t, json.Unmarshal([]byte(line), &cur))
+	for _, key := range path {
+		obj, ok := cur.(map[string]any)
+		require.True(t, ok)
+		cur = obj[key]
+	}
+	out, err := json.Marshal(cur)
+	require.NoError(t, err)
+	return string(out)
+}
@@ -0,0 +1,302 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/securityaudit"
+	"github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// OpenAIBatchHandler 提供 OpenAI Files API（/v1/files）与 Batch API（/v1/batches）。
+type OpenAIBatchHandler struct {
+	service *service.OpenAIBatchService
+	openAI  *OpenAIGatewayHandler
+}
+
+func NewOpenAIBatchHandler(service *service.OpenAIBatchService) *OpenAIBatchHandler {
+	return &OpenAIBatchHandler{service: service}
+}
+
+// UploadFile POST /v1/files
+// multipart 请求体直接流式交给服务层，不经过 ParseMultipartForm 落盘。
+func (h *OpenAIBatchHandler) UploadFile(c *gin.Context) {
+	owner, ok := openAIBatchOwnerFromContext(c)
+	if !ok {
+		openAIBatchAuthError(c)
+		return
+	}
+	var blocked *securityaudit.Decision
+	if h.openAI != nil {
+		subject, ok := middleware.GetAuthSubjectFromContext(c)
+		if !ok {
+			openAIBatchError(c, infraerrors.New(http.StatusInternalServerError, "USER_CONTEXT_REQUIRED", "User context not found"))
+			return
+		}
+		owner.AuditLine = h.batchLineAuditor(c, owner.APIKey, subject, &blocked)
+	}
+	mr, err := c.Request.MultipartReader()
+	if err != nil {
+		openAIBatchError(c, service.ErrOpenAIBatchInvalidUpload.WithCause(err))
+		return
+	}
+	file, err := h.service.UploadFile(c.Request.Context(), owner, mr)
+	if blocked != nil {
+		h.openAI.openAISecurityAuditError(c, blocked)
+		return
+	}
+	if err != nil {
+		openAIBatchError(c, err)
+		return
+	}
+	c.Data(http.StatusOK, "application/json", service.OpenAIBatchFileToPublic(file))
+}
+
+// batchLineAuditor 返回逐行审核批处理输入的回调：任意一行被拦截即中止上传，
+// 拦截决策写入 blocked，由调用方按 OpenAI 错误格式返回。
+func (h *OpenAIBatchHandler) batchLineAuditor(c *gin.Context, apiKey *service.APIKey, subject middleware.AuthSubject, blocked **securityaudit.Decision) func(endpoint, model string, body []byte) error {
+	reqLog := requestLogger(c, "handler.openai_batch.security_audit",
+		zap.Int64("user_id", subject.UserID), zap.Int64("api_key_id", apiKey.ID))
+	return func(endpoint, model string, body []byte) error {
+		decision := h.openAI.checkSecurityAuditStage(c, reqLog, apiKey, subject, openAIBatchAuditProtocol(endpoint), model, body, "batch")
+		if decision != nil && !decision.AllowNextStage {
+			*blocked = decision
+			return infraerrors.New(http.StatusForbidden, "OPENAI_BATCH_PROMPT_BLOCKED", "batch input was blocked by prompt audit")
+		}
+		return nil
+	}
+}
+
+func openAIBatchAuditProtocol(endpoint string) string {
+	switch endpoint {
+	case "/v1/chat/completions":
+		return service.ContentModerationProtocolOpenAIChat
+	case "/v1/responses":
+		return service.ContentModerationProtocolOpenAIResponses
+	case "/v1/embeddings":
+		return "openai_embeddings"
+	default:
+		return "openai_completions"
+	}
+}
+
+// ListFiles GET /v1/files
+func (h *OpenAIBatchHandler) ListFiles(c *gin.Context) {
+	owner, ok := openAIBatchOwnerFromContext(c)
+	if !ok {
+		openAIBatchAuthError(c)
+		return
+	}
+	files, hasMore, err := h.service.ListFiles(c.Request.Context(), owner, c.Query("purpose"), openAIBatchListQuery(c))
+	if err != nil {
+		openAIBatchError(c, err)
+		return
+	}
+	resp := service.OpenAIListResponse{Object: "list", Data: make([]json.RawMessage, 0, len(files)), HasMore: hasMore}
+	for _, file := range files {
+		resp.Data = append(resp.Data, service.OpenAIBatchFileToPublic(file))
+	}
+	if len(files) > 0 {
+		resp.FirstID = &files[0].FileID
+		resp.LastID = &files[len(files)-1].FileID
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetFile GET /v1/files/:id
+func (h *OpenAIBatchHandler) GetFile(c *gin.Context) {
+	owner, ok := openAIBatchOwnerFromContext(c)
+	if !ok {
+		openAIBatchAuthError(c)
+		return
+	}
+	file, err := h.service.GetFile(c.Request.Context(), owner, c.Param("id"))
+	if err != nil {
+		openAIBatchError(c, err)
+		return
+	}
+	c.Data(http.StatusOK, "application/json", file)
+}
+
+// DeleteFile DELETE /v1/files/:id
+func (h *OpenAIBatchHandler) DeleteFile(c *gin.Context) {
+	owner, ok := openAIBatchOwnerFromContext(c)
+	if !ok {
+		openAIBatchAuthError(c)
+		return
+	}
+	resp, err := h.service.DeleteFile(c.Request.Context(), owner, c.Param("id"))
+	if err != nil {
+		openAIBatchError(c, err)
+		return
+	}
+	c.Data(http.StatusOK, "application/json", resp)
+}
+
+// FileContent GET /v1/files/:id/content
+// 文件内容按上游原样流式转发，不在内存中缓冲。
+func (h *OpenAIBatchHandler) FileContent(c *gin.Context) {
+	owner, ok := openAIBatchOwnerFromContext(c)
+	if !ok {
+		openAIBatchAuthError(c)
+		return
+	}
+	content, err := h.service.OpenFileContent(c.Request.Context(), owner, c.Param("id"))
+	if err != nil {
+		openAIBatchError(c, err)
+		return
+	}
+	defer func() { _ = content.Close() }()
+
+	c.Header("Content-Type", "application/octet-stream")
+	c.Header("Cache-Control", "private, no-store")
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, content); err != nil {
+		logger.L().Warn("openai_batch.file_content_stream_failed", zap.String("file_id", c.Param("id")), zap.Error(err))
+	}
+}
+
+// CreateBatch POST /v1/batches
+func (h *OpenAIBatchHandler) CreateBatch(c *gin.Context) {
+	owner, ok := openAIBatchOwnerFromContext(c)
+	if !ok {
+		openAIBatchAuthError(c)
+		return
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		openAIBatchError(c, service.ErrOpenAIBatchInvalidRequest.WithCause(err))
+		return
+	}
+	job, err := h.service.CreateBatch(c.Request.Context(), owner, body, c.GetHeader("Idempotency-Key"))
+	if err != nil {
+		openAIBatchError(c, err)
+		return
+	}
+	c.Data(http.StatusOK, "application/json", service.OpenAIBatchJobToPublic(job))
+}
+
+// ListBatches GET /v1/batches
+func (h *OpenAIBatchHandler) ListBatches(c *gin.Context) {
+	owner, ok := openAIBatchOwnerFromContext(c)
+	if !ok {
+		openAIBatchAuthError(c)
+		return
+	}
+	jobs, hasMore, err := h.service.ListBatches(c.Request.Context(), owner, openAIBatchListQuery(c))
+	if err != nil {
+		openAIBatchError(c, err)
+		return
+	}
+	resp := service.OpenAIListResponse{Object: "list", Data: make([]json.RawMessage, 0, len(jobs)), HasMore: hasMore}
+	for _, job := range jobs {
+		resp.Data = append(resp.Data, service.OpenAIBatchJobToPublic(job))
+	}
+	if len(jobs) > 0 {
+		resp.FirstID = &jobs[0].BatchID
+		resp.LastID = &jobs[len(jobs)-1].BatchID
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetBatch GET /v1/batches/:id
+func (h *OpenAIBatchHandler) GetBatch(c *gin.Context) {
+	owner, ok := openAIBatchOwnerFromContext(c)
+	if !ok {
+		openAIBatchAuthError(c)
+		return
+	}
+	job, err := h.service.GetBatch(c.Request.Context(), owner, c.Param("id"))
+	if err != nil {
+		openAIBatchError(c, err)
+		return
+	}
+	c.Data(http.StatusOK, "application/json", service.OpenAIBatchJobToPublic(job))
+}
+
+// CancelBatch POST /v1/batches/:id/cancel
+func (h *OpenAIBatchHandler) CancelBatch(c *gin.Context) {
+	owner, ok := openAIBatchOwnerFromContext(c)
+	if !ok {
+		openAIBatchAuthError(c)
+		return
+	}
+	job, err := h.service.CancelBatch(c.Request.Context(), owner, c.Param("id"))
+	if err != nil {
+		openAIBatchError(c, err)
+		return
+	}
+	c.Data(http.StatusOK, "application/json", service.OpenAIBatchJobToPublic(job))
+}
+
+func openAIBatchOwnerFromContext(c *gin.Context) (service.OpenAIBatchOwner, bool) {
+	apiKey, ok := middleware.GetAPIKeyFromContext(c)
+	if !ok || apiKey == nil || apiKey.ID <= 0 || apiKey.UserID <= 0 {
+		return service.OpenAIBatchOwner{}, false
+	}
+	return service.OpenAIBatchOwner{
+		UserID:   apiKey.UserID,
+		APIKeyID: apiKey.ID,
+		GroupID:  apiKey.GroupID,
+		APIKey:   apiKey,
+	}, true
+}
+
+func openAIBatchListQuery(c *gin.Context) service.OpenAIBatchListQuery {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	return service.OpenAIBatchListQuery{
+		After: strings.TrimSpace(c.Query("after")),
+		Limit: limit,
+	}
+}
+
+func openAIBatchAuthError(c *gin.Context) {
+	openAIBatchError(c, infraerrors.New(http.StatusUnauthorized, "API_KEY_REQUIRED", "Invalid API key"))
+}
+
+// openAIBatchError 以 OpenAI API 错误格式返回。
+func openAIBatchError(c *gin.Context, err error) {
+	status := infraerrors.Code(err)
+	code := infraerrors.Reason(err)
+	message := infraerrors.Message(err)
+	if status == 0 || status == http.StatusInternalServerError {
+		status = http.StatusInternalServerError
+		code = "INTERNAL_ERROR"
+		message = "internal error"
+	}
+	c.JSON(status, gin.H{
+		"error": gin.H{
+			"type":    openAIBatchErrorType(status),
+			"code":    code,
+			"message": message,
+		},
+	})
+}
+
+func openAIBatchErrorType(status int) string {
+	switch status {
+	case http.StatusUnauthorized:
+		return "authentication_error"
+	case http.StatusPaymentRequired:
+		return "insufficient_quota"
+	case http.StatusForbidden:
+		return "permission_error"
+	case http.StatusNotFound:
+		return "not_found_error"
+	case http.StatusTooManyRequests:
+		return "rate_limit_error"
+	}
+	if status >= http.StatusInternalServerError {
+		return "api_error"
+	}
+	return "invalid_request_error"
+}
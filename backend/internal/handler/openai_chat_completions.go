@@ -182,6 +182,9 @@ func (h *OpenAIGatewayHandler) ChatCompletions(c *gin.Context) {
 			)
 			if len(failedAccountIDs) == 0 {
 				cls := classifyOpenAICompatibleNoAccountErrorFromGin(c, h.gatewayService, apiKey, reqModel, reqModel)
+				if deferToModelFallback(c, cls.Status, streamStarted) {
+					return
+				}
 				if !cls.ModelNotFound {
 					markOpsRoutingCapacityLimitedIfNoAvailable(c, err)
 				}
@@ -198,6 +201,9 @@ func (h *OpenAIGatewayHandler) ChatCompletions(c *gin.Context) {
 		}
 		if selection == nil || selection.Account == nil {
 			cls := classifyOpenAICompatibleNoAccountErrorFromGin(c, h.gatewayService, apiKey, reqModel, reqModel)
+			if deferToModelFallback(c, cls.Status, streamStarted) {
+				return
+			}
 			if !cls.ModelNotFound {
 				markOpsRoutingCapacityLimited(c)
 			}
@@ -231,14 +237,17 @@ func (h *OpenAIGatewayHandler) ChatCompletions(c *gin.Context) {
 			forwardBody = h.gatewayService.ReplaceModelInBody(body, channelMapping.MappedModel)
 		}
 		writerSizeBeforeForward := c.Writer.Size()
-		result, err := func() (*service.OpenAIForwardResult, error) {
-			defer func() {
-				if accountReleaseFunc != nil {
-					accountReleaseFunc()
-				}
-			}()
-			return h.gatewayService.ForwardAsChatCompletions(c.Request.Context(), c, account, forwardBody, promptCacheKey, "")
-		}()
+		result, account, err := h.forwardWithHedge(c, openAIHedgeRequest{
+			body:            body,
+			apiKey:          apiKey,
+			reqModel:        reqModel,
+			capability:      service.OpenAIEndpointCapabilityChatCompletions,
+			requestPlatform: requestPlatform,
+			excluded:        failedAccountIDs,
+			hedgeable:       !reqStream,
+		}, account, accountReleaseFunc, func(ctx context.Context, fc *gin.Context, acc *service.Account) (*service.OpenAIForwardResult, error) {
+			return h.gatewayService.ForwardAsChatCompletions(ctx, fc, acc, forwardBody, promptCacheKey, "")
+		}, reqLog)
 		cyberBlockKeyChat := ""
 		if service.GetOpsCyberPolicy(c) != nil {
 			cyberBlockKeyChat = service.CyberSessionBlockKey(apiKey.ID, c, body)
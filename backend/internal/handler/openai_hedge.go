@@ -0,0 +1,263 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// openAIHedgeForwardFunc 在给定上下文上执行一次转发。对冲时每一路都运行在
+// 独立的影子 gin.Context 上，响应先缓冲在各自的 shadowResponseWriter，胜出方再回放给客户端。
+type openAIHedgeForwardFunc func(ctx context.Context, c *gin.Context, account *service.Account) (*service.OpenAIForwardResult, error)
+
+// openAIHedgeRequest 描述一次可能被对冲的转发。hedgeable 由调用方判定：
+// 流式、依赖 previous_response_id 粘连、compact 心跳与生图请求都不可对冲。
+type openAIHedgeRequest struct {
+	// body 为客户端原始请求体，每一路影子请求各持一份独立的 reader。
+	body            []byte
+	apiKey          *service.APIKey
+	reqModel        string
+	capability      service.OpenAIEndpointCapability
+	requestPlatform string
+	excluded        map[int64]struct{}
+	hedgeable       bool
+}
+
+type openAIHedgeAttempt struct {
+	account *service.Account
+	ctx     *gin.Context
+	writer  *shadowResponseWriter
+	cancel  context.CancelFunc
+	started time.Time
+	done    chan struct{}
+	result  *service.OpenAIForwardResult
+	err     error
+}
+
+// forwardWithHedge 转发请求；分组启用对冲且首个账号超过分位延迟仍未返回时，
+// 向第二个账号并发同一请求，先成功者回放给客户端，另一方被取消并释放槽位。
+// 返回实际产出结果的账号，调用方据此上报调度结果与计费，只对胜出方计费。
+// 不满足对冲条件时与直接转发完全等价。
+func (h *OpenAIGatewayHandler) forwardWithHedge(
+	c *gin.Context,
+	req openAIHedgeRequest,
+	account *service.Account,
+	releaseFunc func(),
+	forward openAIHedgeForwardFunc,
+	reqLog *zap.Logger,
+) (*service.OpenAIForwardResult, *service.Account, error) {
+	if !req.hedgeable || req.apiKey == nil || !req.apiKey.Group.HedgeEnabled() {
+		defer func() {
+			if releaseFunc != nil {
+				releaseFunc()
+			}
+		}()
+		result, err := forward(c.Request.Context(), c, account)
+		return result, account, err
+	}
+
+	policy := req.apiKey.Group.HedgePolicy
+	primary := startOpenAIHedgeAttempt(c, req.body, account, releaseFunc, forward)
+	timer := time.NewTimer(h.gatewayService.OpenAIHedgeDelay(policy, account.ID))
+	defer timer.Stop()
+
+	select {
+	case <-primary.done:
+		return h.commitOpenAIHedgeAttempt(c, primary)
+	case <-timer.C:
+	}
+
+	hedge := h.startOpenAIHedgeSecondary(c, req, primary.account, forward, reqLog)
+	if hedge == nil {
+		<-primary.done
+		return h.commitOpenAIHedgeAttempt(c, primary)
+	}
+	reqLog.Info("openai.hedge_dispatched",
+		zap.Int64("primary_account_id", primary.account.ID),
+		zap.Int64("hedge_account_id", hedge.account.ID),
+		zap.Int64("waited_ms", time.Since(primary.started).Milliseconds()),
+	)
+
+	winner, loser := waitOpenAIHedgeWinner(primary, hedge)
+	if winner != hedge && openAIHedgeAttemptFailed(hedge) {
+		h.gatewayService.ReportOpenAIAccountScheduleResult(hedge.account.ID, hedge.account.GetMappedModel(req.reqModel), false, nil)
+	}
+	outcome := service.GroupHedgeOutcome{GroupID: *req.apiKey.GroupID, Winner: service.HedgeWinnerNone, At: time.Now()}
+	if winner == nil {
+		// 两路都失败：对冲账号的失败已上报调度器，按首个账号的错误走常规 failover。
+		h.recordOpenAIHedgeOutcome(c, outcome)
+		return h.commitOpenAIHedgeAttempt(c, primary)
+	}
+
+	// 被取消方的已耗时只是其真实耗时的下界（右删失样本），计入会把分位往低估，
+	// 使对冲越来越早触发，因此不上报；分位只由完整完成的请求构成。
+	loser.cancel()
+	outcome.Winner = service.HedgeWinnerPrimary
+	if winner == hedge {
+		outcome.Winner = service.HedgeWinnerHedge
+	}
+	outcome.HedgeCost = h.hedgeStats.EstimateLoserCost(winner.result, loser.account)
+	h.recordOpenAIHedgeOutcome(c, outcome)
+	reqLog.Info("openai.hedge_settled",
+		zap.String("winner", outcome.Winner),
+		zap.Int64("winner_account_id", winner.account.ID),
+		zap.Int64("loser_account_id", loser.account.ID),
+		zap.Float64("estimated_hedge_cost", outcome.HedgeCost),
+	)
+	return h.commitOpenAIHedgeAttempt(c, winner)
+}
+
+// startOpenAIHedgeAttempt 在影子上下文中异步执行一路转发；结束后释放该路的账号槽位。
+func startOpenAIHedgeAttempt(c *gin.Context, body []byte, account *service.Account, releaseFunc func(), forward openAIHedgeForwardFunc) *openAIHedgeAttempt {
+	attemptCtx, cancel := context.WithCancel(c.Request.Context())
+	writer := newShadowResponseWriter()
+	shadow := newShadowContext(attemptCtx, c, body, writer)
+	setOpsSelectedAccount(shadow, account.ID, account.Platform)
+
+	attempt := &openAIHedgeAttempt{
+		account: account,
+		ctx:     shadow,
+		writer:  writer,
+		cancel:  cancel,
+		started: time.Now(),
+		done:    make(chan struct{}),
+	}
+	go func() {
+		defer close(attempt.done)
+		defer func() {
+			if releaseFunc != nil {
+				releaseFunc()
+			}
+		}()
+		attempt.result, attempt.err = forward(attemptCtx, shadow, account)
+	}()
+	return attempt
+}
+
+// startOpenAIHedgeSecondary 为对冲挑选第二个账号并立即抢槽；抢不到槽位、
+// 被利润控制否决或无可用账号时放弃对冲（返回 nil），绝不排队等待。
+func (h *OpenAIGatewayHandler) startOpenAIHedgeSecondary(
+	c *gin.Context,
+	req openAIHedgeRequest,
+	primary *service.Account,
+	forward openAIHedgeForwardFunc,
+	reqLog *zap.Logger,
+) *openAIHedgeAttempt {
+	excluded := make(map[int64]struct{}, len(req.excluded)+1)
+	for id := range req.excluded {
+		excluded[id] = struct{}{}
+	}
+	excluded[primary.ID] = struct{}{}
+
+	ctx := c.Request.Context()
+	selection, _, err := h.gatewayService.SelectAccountWithSchedulerForCapability(
+		ctx,
+		req.apiKey.GroupID,
+		"",
+		"", // 不使用会话粘连：粘连只会把对冲送回首个账号
+		req.reqModel,
+		excluded,
+		service.OpenAIUpstreamTransportAny,
+		req.capability,
+		false,
+		false,
+		true,
+		req.requestPlatform,
+	)
+	if err != nil || selection == nil || selection.Account == nil {
+		reqLog.Debug("openai.hedge_skipped_no_account", zap.Error(err))
+		return nil
+	}
+
+	gateCtx := service.ContextWithSelectionProfitGate(ctx, selection)
+	account := selection.Account
+	releaseFunc := selection.ReleaseFunc
+	if !selection.Acquired {
+		if selection.WaitPlan == nil {
+			return nil
+		}
+		fastRelease, acquired, err := h.concurrencyHelper.TryAcquireAccountSlot(gateCtx, account.ID, selection.WaitPlan.MaxConcurrency)
+		if err != nil || !acquired {
+			reqLog.Debug("openai.hedge_skipped_slot_busy", zap.Int64("account_id", account.ID), zap.Error(err))
+			return nil
+		}
+		releaseFunc = fastRelease
+	}
+	latest, vetoed, reason := h.gatewayService.ProfitControlVetoLatest(gateCtx, account)
+	if vetoed {
+		if releaseFunc != nil {
+			releaseFunc()
+		}
+		reqLog.Debug("openai.hedge_skipped_profit_vetoed", zap.Int64("account_id", account.ID), zap.String("reason", reason))
+		return nil
+	}
+	return startOpenAIHedgeAttempt(c, req.body, latest, wrapReleaseOnDone(gateCtx, releaseFunc), forward)
+}
+
+// waitOpenAIHedgeWinner 等待第一路成功的转发；两路都失败时返回 nil。
+func waitOpenAIHedgeWinner(primary, hedge *openAIHedgeAttempt) (winner, loser *openAIHedgeAttempt) {
+	primaryDone, hedgeDone := primary.done, hedge.done
+	for primaryDone != nil || hedgeDone != nil {
+		select {
+		case <-primaryDone:
+			primaryDone = nil
+			if primary.err == nil {
+				return primary, hedge
+			}
+		case <-hedgeDone:
+			hedgeDone = nil
+			if hedge.err == nil {
+				return hedge, primary
+			}
+		}
+	}
+	return nil, nil
+}
+
+// openAIHedgeAttemptFailed 报告该路是否已自行失败结束（而非仍在运行或被取消）。
+func openAIHedgeAttemptFailed(attempt *openAIHedgeAttempt) bool {
+	select {
+	case <-attempt.done:
+		return attempt.err != nil
+	default:
+		return false
+	}
+}
+
+// commitOpenAIHedgeAttempt 把一路已结束转发的上下文键与已写出的响应回放到客户端上下文。
+func (h *OpenAIGatewayHandler) commitOpenAIHedgeAttempt(c *gin.Context, attempt *openAIHedgeAttempt) (*service.OpenAIForwardResult, *service.Account, error) {
+	attempt.cancel()
+	if attempt.err == nil {
+		h.gatewayService.ReportOpenAIHedgeLatency(attempt.account.ID, time.Since(attempt.started))
+	}
+	for key, value := range attempt.ctx.Keys {
+		c.Set(key, value)
+	}
+	if attempt.writer.Written() {
+		header := c.Writer.Header()
+		for key, values := range attempt.writer.Header() {
+			header[key] = append([]string(nil), values...)
+		}
+		c.Writer.WriteHeader(attempt.writer.Status())
+		_, _ = c.Writer.Write(attempt.writer.Bytes())
+	}
+	return attempt.result, attempt.account, attempt.err
+}
+
+func (h *OpenAIGatewayHandler) recordOpenAIHedgeOutcome(c *gin.Context, outcome service.GroupHedgeOutcome) {
+	if h.hedgeStats == nil {
+		return
+	}
+	h.submitUsageRecordTask(c.Request.Context(), func(ctx context.Context) {
+		if err := h.hedgeStats.Record(ctx, outcome); err != nil {
+			logger.L().With(
+				zap.String("component", "handler.openai_gateway.hedge"),
+				zap.Int64("group_id", outcome.GroupID),
+			).Warn("openai.hedge_stats_record_failed", zap.Error(err))
+		}
+	})
+}
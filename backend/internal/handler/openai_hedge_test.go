@@ -0,0 +1,138 @@
+//go:build unit
+
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newOpenAIHedgeTestContext() (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	return c, w
+}
+
+func TestOpenAIHedge_FirstSuccessWinsAndLoserIsCancelled(t *testing.T) {
+	c, w := newOpenAIHedgeTestContext()
+	var released atomic.Int32
+	release := func() { released.Add(1) }
+
+	slow := startOpenAIHedgeAttempt(c, nil, &service.Account{ID: 1}, release, func(ctx context.Context, fc *gin.Context, _ *service.Account) (*service.OpenAIForwardResult, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	fast := startOpenAIHedgeAttempt(c, nil, &service.Account{ID: 2}, release, func(_ context.Context, fc *gin.Context, acc *service.Account) (*service.OpenAIForwardResult, error) {
+		fc.Set("hedge_test_key", acc.ID)
+		fc.Header("X-Upstream", "fast")
+		fc.JSON(http.StatusOK, gin.H{"id": "chatcmpl-fast"})
+		return &service.OpenAIForwardResult{RequestID: "fast"}, nil
+	})
+
+	winner, loser := waitOpenAIHedgeWinner(slow, fast)
+	require.Same(t, fast, winner)
+	require.Same(t, slow, loser)
+	require.Empty(t, w.Body.String(), "nothing reaches the client before commit")
+
+	loser.cancel()
+	<-slow.done
+	require.ErrorIs(t, slow.err, context.Canceled)
+
+	h := &OpenAIGatewayHandler{}
+	result, account, err := h.commitOpenAIHedgeAttempt(c, winner)
+	require.NoError(t, err)
+	require.Equal(t, "fast", result.RequestID)
+	require.EqualValues(t, 2, account.ID)
+	require.EqualValues(t, 2, released.Load())
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "fast", w.Header().Get("X-Upstream"))
+	require.JSONEq(t, `{"id":"chatcmpl-fast"}`, w.Body.String())
+	value, ok := c.Get("hedge_test_key")
+	require.True(t, ok)
+	require.EqualValues(t, 2, value)
+}
+
+func TestOpenAIHedge_EachAttemptReadsItsOwnBody(t *testing.T) {
+	c, _ := newOpenAIHedgeTestContext()
+	body := []byte(`{"model":"gpt-5","input":"hi"}`)
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	read := func(_ context.Context, fc *gin.Context, _ *service.Account) (*service.OpenAIForwardResult, error) {
+		got, err := io.ReadAll(fc.Request.Body)
+		if err != nil {
+			return nil, err
+		}
+		again, err := fc.Request.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		replay, err := io.ReadAll(again)
+		if err != nil {
+			return nil, err
+		}
+		return &service.OpenAIForwardResult{RequestID: string(got) + "|" + string(replay)}, nil
+	}
+	primary := startOpenAIHedgeAttempt(c, body, &service.Account{ID: 1}, nil, read)
+	hedge := startOpenAIHedgeAttempt(c, body, &service.Account{ID: 2}, nil, read)
+	<-primary.done
+	<-hedge.done
+
+	want := string(body) + "|" + string(body)
+	require.NoError(t, primary.err)
+	require.NoError(t, hedge.err)
+	require.Equal(t, want, primary.result.RequestID)
+	require.Equal(t, want, hedge.result.RequestID)
+}
+
+func TestOpenAIHedge_BothFailHasNoWinner(t *testing.T) {
+	c, w := newOpenAIHedgeTestContext()
+	fail := func(context.Context, *gin.Context, *service.Account) (*service.OpenAIForwardResult, error) {
+		return nil, errors.New("upstream failed")
+	}
+	primary := startOpenAIHedgeAttempt(c, nil, &service.Account{ID: 1}, nil, fail)
+	hedge := startOpenAIHedgeAttempt(c, nil, &service.Account{ID: 2}, nil, fail)
+
+	winner, loser := waitOpenAIHedgeWinner(primary, hedge)
+	require.Nil(t, winner)
+	require.Nil(t, loser)
+	require.True(t, openAIHedgeAttemptFailed(hedge))
+
+	_, account, err := (&OpenAIGatewayHandler{}).commitOpenAIHedgeAttempt(c, primary)
+	require.Error(t, err)
+	require.EqualValues(t, 1, account.ID)
+	require.False(t, c.Writer.Written(), "failover errors leave the client response untouched")
+	require.Empty(t, w.Body.String())
+}
+
+func TestForwardWithHedge_DisabledForwardsDirectly(t *testing.T) {
+	c, w := newOpenAIHedgeTestContext()
+	groupID := int64(9)
+	apiKey := &service.APIKey{GroupID: &groupID, Group: &service.Group{Platform: service.PlatformOpenAI}}
+	released := false
+
+	result, account, err := (&OpenAIGatewayHandler{}).forwardWithHedge(c, openAIHedgeRequest{apiKey: apiKey, hedgeable: true}, &service.Account{ID: 3},
+		func() { released = true },
+		func(_ context.Context, fc *gin.Context, _ *service.Account) (*service.OpenAIForwardResult, error) {
+			require.Same(t, c, fc, "no shadow context without an enabled policy")
+			fc.String(http.StatusOK, "ok")
+			return &service.OpenAIForwardResult{}, nil
+		}, zap.NewNop())
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	require.EqualValues(t, 3, account.ID)
+	require.True(t, released)
+	require.Equal(t, "ok", w.Body.String())
+}
@@ -691,7 +691,9 @@ func OpsErrorLoggerMiddleware(ops *service.OpsService) gin.HandlerFunc {
 			releaseOpsCaptureWriter(w)
 		}()
 		c.Writer = w
+		start := time.Now()
 		c.Next()
+		recordGatewayRequestMetrics(c, time.Since(start))
 
 		if _, rejected := middleware2.GetIngressRejectReason(c); rejected {
 			return
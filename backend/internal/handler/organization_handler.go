@@ -0,0 +1,428 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/handler/dto"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OrganizationHandler handles organization self-service for the current user
+type OrganizationHandler struct {
+	organizationService *service.OrganizationService
+}
+
+// NewOrganizationHandler creates a new organization handler
+func NewOrganizationHandler(organizationService *service.OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{
+		organizationService: organizationService,
+	}
+}
+
+// CreateOrganizationRequest represents create organization request
+type CreateOrganizationRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// RenameOrganizationRequest represents rename organization request
+type RenameOrganizationRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// InviteOrganizationMemberRequest represents invite member request
+type InviteOrganizationMemberRequest struct {
+	Email              string   `json:"email" binding:"required"`
+	Role               string   `json:"role"`
+	MonthlySpendCapUSD *float64 `json:"monthly_spend_cap_usd"`
+}
+
+// UpdateOrganizationMemberRequest represents update member request
+type UpdateOrganizationMemberRequest struct {
+	Role               *string  `json:"role"`
+	MonthlySpendCapUSD *float64 `json:"monthly_spend_cap_usd"`
+	ClearSpendCap      bool     `json:"clear_spend_cap"`
+}
+
+// OrganizationWalletTransferRequest represents a personal balance -> organization wallet transfer
+type OrganizationWalletTransferRequest struct {
+	Amount float64 `json:"amount" binding:"required,gt=0"`
+}
+
+// Get returns the current user's organization and role
+// GET /api/v1/organization
+func (h *OrganizationHandler) Get(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not found in context")
+		return
+	}
+
+	org, member, err := h.organizationService.GetMyOrganization(c.Request.Context(), subject.UserID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"organization": dto.OrganizationFromService(org),
+		"membership":   dto.OrganizationMemberFromService(member),
+	})
+}
+
+// Create creates an organization owned by the current user
+// POST /api/v1/organization
+func (h *OrganizationHandler) Create(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not found in context")
+		return
+	}
+
+	var req CreateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	org, err := h.organizationService.CreateOrganization(c.Request.Context(), subject.UserID, req.Name)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, dto.OrganizationFromService(org))
+}
+
+// Rename renames the current user's organization (owner only)
+// PUT /api/v1/organization
+func (h *OrganizationHandler) Rename(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not found in context")
+		return
+	}
+
+	var req RenameOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	org, err := h.organizationService.RenameMyOrganization(c.Request.Context(), subject.UserID, req.Name)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, dto.OrganizationFromService(org))
+}
+
+// ListMembers lists members of the current user's organization
+// GET /api/v1/organization/members
+func (h *OrganizationHandler) ListMembers(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not found in context")
+		return
+	}
+
+	members, err := h.organizationService.ListMyMembers(c.Request.Context(), subject.UserID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, dto.OrganizationMembersFromService(members))
+}
+
+// InviteMember invites an existing user (by email); the user joins after accepting
+// POST /api/v1/organization/invitations
+func (h *OrganizationHandler) InviteMember(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not found in context")
+		return
+	}
+
+	var req InviteOrganizationMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	invitation, err := h.organizationService.InviteMyMember(c.Request.Context(), subject.UserID, &service.AddOrganizationMemberInput{
+		Email:              strings.TrimSpace(req.Email),
+		Role:               req.Role,
+		MonthlySpendCapUSD: req.MonthlySpendCapUSD,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, dto.OrganizationInvitationFromService(invitation))
+}
+
+// ListInvitations lists pending invitations sent by the organization (owner/admin only)
+// GET /api/v1/organization/invitations
+func (h *OrganizationHandler) ListInvitations(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not found in context")
+		return
+	}
+
+	invitations, err := h.organizationService.ListMyInvitations(c.Request.Context(), subject.UserID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, dto.OrganizationInvitationsFromService(invitations))
+}
+
+// RevokeInvitation revokes a pending invitation
+// DELETE /api/v1/organization/invitations/:id
+func (h *OrganizationHandler) RevokeInvitation(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not found in context")
+		return
+	}
+
+	invitationID, ok := parseOrganizationInvitationID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.organizationService.RevokeMyInvitation(c.Request.Context(), subject.UserID, invitationID); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "Invitation revoked successfully"})
+}
+
+// ListReceivedInvitations lists pending invitations addressed to the current user
+// GET /api/v1/organization/invitations/received
+func (h *OrganizationHandler) ListReceivedInvitations(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not found in context")
+		return
+	}
+
+	invitations, err := h.organizationService.ListReceivedInvitations(c.Request.Context(), subject.UserID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, dto.OrganizationInvitationsFromService(invitations))
+}
+
+// AcceptInvitation joins the organization of a pending invitation
+// POST /api/v1/organization/invitations/:id/accept
+func (h *OrganizationHandler) AcceptInvitation(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not found in context")
+		return
+	}
+
+	invitationID, ok := parseOrganizationInvitationID(c)
+	if !ok {
+		return
+	}
+
+	org, member, err := h.organizationService.AcceptInvitation(c.Request.Context(), subject.UserID, invitationID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"organization": dto.OrganizationFromService(org),
+		"membership":   dto.OrganizationMemberFromService(member),
+	})
+}
+
+// DeclineInvitation declines a pending invitation
+// POST /api/v1/organization/invitations/:id/decline
+func (h *OrganizationHandler) DeclineInvitation(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not found in context")
+		return
+	}
+
+	invitationID, ok := parseOrganizationInvitationID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.organizationService.DeclineInvitation(c.Request.Context(), subject.UserID, invitationID); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "Invitation declined successfully"})
+}
+
+func parseOrganizationInvitationID(c *gin.Context) (int64, bool) {
+	invitationID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || invitationID <= 0 {
+		response.BadRequest(c, "Invalid invitation ID")
+		return 0, false
+	}
+	return invitationID, true
+}
+
+// UpdateMember updates a member's role or monthly spend cap
+// PUT /api/v1/organization/members/:user_id
+func (h *OrganizationHandler) UpdateMember(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not found in context")
+		return
+	}
+
+	memberUserID, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil || memberUserID <= 0 {
+		response.BadRequest(c, "Invalid user ID")
+		return
+	}
+
+	var req UpdateOrganizationMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	member, err := h.organizationService.UpdateMyMember(c.Request.Context(), subject.UserID, memberUserID, &service.UpdateOrganizationMemberInput{
+		Role:               req.Role,
+		MonthlySpendCapUSD: req.MonthlySpendCapUSD,
+		ClearSpendCap:      req.ClearSpendCap,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, dto.OrganizationMemberFromService(member))
+}
+
+// RemoveMember removes a member; members may remove themselves to leave the organization
+// DELETE /api/v1/organization/members/:user_id
+func (h *OrganizationHandler) RemoveMember(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not found in context")
+		return
+	}
+
+	memberUserID, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil || memberUserID <= 0 {
+		response.BadRequest(c, "Invalid user ID")
+		return
+	}
+
+	if err := h.organizationService.RemoveMyMember(c.Request.Context(), subject.UserID, memberUserID); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "Member removed successfully"})
+}
+
+// TransferToWallet moves personal balance into the organization wallet
+// POST /api/v1/organization/wallet/transfer
+func (h *OrganizationHandler) TransferToWallet(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not found in context")
+		return
+	}
+
+	var req OrganizationWalletTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	org, err := h.organizationService.TransferToMyWallet(c.Request.Context(), subject.UserID, req.Amount)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, dto.OrganizationFromService(org))
+}
+
+// ListSubscriptions lists group subscriptions shared by the organization
+// GET /api/v1/organization/subscriptions
+func (h *OrganizationHandler) ListSubscriptions(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not found in context")
+		return
+	}
+
+	subs, err := h.organizationService.ListMySubscriptions(c.Request.Context(), subject.UserID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, dto.OrganizationSubscriptionsFromService(subs))
+}
+
+// Usage returns the organization usage dashboard (owner/admin only)
+// GET /api/v1/organization/usage
+// Query params: start_date, end_date (YYYY-MM-DD), timezone, granularity (day/hour)
+func (h *OrganizationHandler) Usage(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not found in context")
+		return
+	}
+
+	userTZ := c.Query("timezone")
+	now := timezone.NowInUserLocation(userTZ)
+	startTime := timezone.StartOfDayInUserLocation(now.AddDate(0, 0, -6), userTZ)
+	endTime := timezone.StartOfDayInUserLocation(now.AddDate(0, 0, 1), userTZ)
+	if startDateStr := strings.TrimSpace(c.Query("start_date")); startDateStr != "" {
+		t, err := timezone.ParseInUserLocation("2006-01-02", startDateStr, userTZ)
+		if err != nil {
+			response.BadRequest(c, "Invalid start_date format, use YYYY-MM-DD")
+			return
+		}
+		startTime = t
+	}
+	if endDateStr := strings.TrimSpace(c.Query("end_date")); endDateStr != "" {
+		t, err := timezone.ParseInUserLocation("2006-01-02", endDateStr, userTZ)
+		if err != nil {
+			response.BadRequest(c, "Invalid end_date format, use YYYY-MM-DD")
+			return
+		}
+		endTime = t.AddDate(0, 0, 1)
+	}
+	if !endTime.After(startTime) {
+		response.BadRequest(c, "end_date must not be before start_date")
+		return
+	}
+
+	dashboard, err := h.organizationService.GetMyUsageDashboard(c.Request.Context(), subject.UserID, startTime, endTime, c.DefaultQuery("granularity", "day"))
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, dto.OrganizationUsageDashboardFromService(dashboard))
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"fmt"
+	"net/http"
 	"strconv"
 	"strings"
 	"time"
@@ -154,6 +155,7 @@ func (h *PaymentHandler) GetCheckoutInfo(c *gin.Context) {
 		StripePublishableKey:          cfg.StripePublishableKey,
 		AlipayForceQRCode:             cfg.AlipayForceQRCode,
 		AlipayMobilePrecreateDeepLink: alipayMobilePrecreateDeepLink,
+		AutoRenewEnabled:              cfg.AutoRenewEnabled,
 	})
 }
 
@@ -171,6 +173,7 @@ type checkoutInfoResponse struct {
 	StripePublishableKey          string                          `json:"stripe_publishable_key"`
 	AlipayForceQRCode             bool                            `json:"alipay_force_qrcode"`
 	AlipayMobilePrecreateDeepLink bool                            `json:"alipay_mobile_precreate_deep_link"`
+	AutoRenewEnabled              bool                            `json:"auto_renew_enabled"`
 }
 
 type checkoutPlan struct {
@@ -236,6 +239,13 @@ type CreateOrderRequest struct {
 	PaymentSource     string  `json:"payment_source"`
 	OrderType         string  `json:"order_type"`
 	PlanID            int64   `json:"plan_id"`
+	// AutoRenew opts a subscription order into auto-renewal: the provider
+	// saves the payment method and later renewals are charged off-session.
+	AutoRenew bool `json:"auto_renew"`
+	// Optional invoice details printed as the buyer on issued invoices.
+	BuyerName    string `json:"buyer_name"`
+	BuyerTaxID   string `json:"buyer_tax_id"`
+	BuyerAddress string `json:"buyer_address"`
 	// IsMobile lets the frontend declare its mobile status directly. When
 	// nil we fall back to User-Agent heuristics (which miss iPadOS / some
 	// embedded browsers that strip the "Mobile" keyword).
@@ -285,6 +295,10 @@ func (h *PaymentHandler) CreateOrder(c *gin.Context) {
 		PaymentSource:   req.PaymentSource,
 		OrderType:       req.OrderType,
 		PlanID:          req.PlanID,
+		AutoRenew:       req.AutoRenew,
+		BuyerName:       req.BuyerName,
+		BuyerTaxID:      req.BuyerTaxID,
+		BuyerAddress:    req.BuyerAddress,
 		Locale:          c.GetHeader("Accept-Language"),
 	})
 	if err != nil {
@@ -399,6 +413,116 @@ func (h *PaymentHandler) CancelOrder(c *gin.Context) {
 	response.Success(c, gin.H{"message": msg})
 }
 
+// ListOrderInvoices returns the invoice and credit note issued for an order,
+// issuing any that are due.
+// GET /api/v1/payment/orders/:id/invoices
+func (h *PaymentHandler) ListOrderInvoices(c *gin.Context) {
+	subject, ok := requireAuth(c)
+	if !ok {
+		return
+	}
+
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid order ID")
+		return
+	}
+
+	invoices, err := h.paymentService.ListOrderInvoices(c.Request.Context(), orderID, subject.UserID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, invoices)
+}
+
+// DownloadOrderInvoice downloads an invoice or credit note as PDF (default) or HTML.
+// GET /api/v1/payment/orders/:id/invoices/:invoiceId?format=pdf|html
+func (h *PaymentHandler) DownloadOrderInvoice(c *gin.Context) {
+	subject, ok := requireAuth(c)
+	if !ok {
+		return
+	}
+
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid order ID")
+		return
+	}
+	invoiceID, err := strconv.ParseInt(c.Param("invoiceId"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid invoice ID")
+		return
+	}
+	format := strings.ToLower(strings.TrimSpace(c.DefaultQuery("format", service.PaymentInvoiceFormatPDF)))
+	if format != service.PaymentInvoiceFormatPDF && format != service.PaymentInvoiceFormatHTML {
+		response.BadRequest(c, "Invalid format, expect pdf or html")
+		return
+	}
+
+	inv, err := h.paymentService.GetOrderInvoice(c.Request.Context(), orderID, subject.UserID, invoiceID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	if format == service.PaymentInvoiceFormatHTML {
+		body, err := service.RenderPaymentInvoiceHTML(inv)
+		if err != nil {
+			response.ErrorFrom(c, err)
+			return
+		}
+		c.Header("Content-Disposition", `inline; filename="`+inv.InvoiceNumber+`.html"`)
+		c.Data(http.StatusOK, "text/html; charset=utf-8", body)
+		return
+	}
+	body, err := h.paymentService.RenderOrderInvoicePDF(inv)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="`+inv.InvoiceNumber+`.pdf"`)
+	c.Data(http.StatusOK, "application/pdf", body)
+}
+
+// ListAutoRenewals returns the authenticated user's subscription auto-renewals.
+// GET /api/v1/payment/auto-renewals
+func (h *PaymentHandler) ListAutoRenewals(c *gin.Context) {
+	subject, ok := requireAuth(c)
+	if !ok {
+		return
+	}
+
+	items, err := h.paymentService.ListAutoRenewals(c.Request.Context(), subject.UserID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, items)
+}
+
+// CancelAutoRenewal stops future automatic charges for a subscription.
+// The current subscription period is kept until it expires.
+// POST /api/v1/payment/auto-renewals/:id/cancel
+func (h *PaymentHandler) CancelAutoRenewal(c *gin.Context) {
+	subject, ok := requireAuth(c)
+	if !ok {
+		return
+	}
+
+	renewalID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid auto-renewal ID")
+		return
+	}
+
+	if err := h.paymentService.CancelAutoRenewal(c.Request.Context(), subject.UserID, renewalID); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "auto-renewal cancelled"})
+}
+
 // RefundRequestBody is the request body for requesting a refund.
 type RefundRequestBody struct {
 	Reason string `json:"reason"`
@@ -67,6 +67,12 @@ func (h *PaymentWebhookHandler) AirwallexWebhook(c *gin.Context) {
 	h.handleNotify(c, payment.TypeAirwallex)
 }
 
+// PayPalWebhook 处理 PayPal Webhook 事件。
+// POST /api/v1/payment/webhook/paypal
+func (h *PaymentWebhookHandler) PayPalWebhook(c *gin.Context) {
+	h.handleNotify(c, payment.TypePayPal)
+}
+
 // handleNotify is the shared logic for all provider webhook handlers.
 func (h *PaymentWebhookHandler) handleNotify(c *gin.Context, providerKey string) {
 	var rawBody string
@@ -164,6 +170,24 @@ func extractOutTradeNo(rawBody, providerKey string) string {
 		if err := json.Unmarshal([]byte(rawBody), &payload); err == nil {
 			return strings.TrimSpace(payload.Data.Object.MerchantOrderID)
 		}
+	case payment.TypePayPal:
+		// 扣款事件的 resource 是 capture，订单事件的 resource 是 order，两者都携带下单时写入的 custom_id。
+		var payload struct {
+			Resource struct {
+				CustomID      string `json:"custom_id"`
+				PurchaseUnits []struct {
+					CustomID string `json:"custom_id"`
+				} `json:"purchase_units"`
+			} `json:"resource"`
+		}
+		if err := json.Unmarshal([]byte(rawBody), &payload); err == nil {
+			if customID := strings.TrimSpace(payload.Resource.CustomID); customID != "" {
+				return customID
+			}
+			if len(payload.Resource.PurchaseUnits) > 0 {
+				return strings.TrimSpace(payload.Resource.PurchaseUnits[0].CustomID)
+			}
+		}
 	}
 	// For other providers (Stripe, Alipay direct, WxPay direct), the registry
 	// typically has only one instance, so no instance lookup is needed.
@@ -203,12 +227,12 @@ const (
 
 // writeSuccessResponse 返回各支付服务商要求的成功响应。
 // 微信支付需要 JSON {"code":"SUCCESS","message":"成功"}；
-// Stripe 和空中云汇接受空 200，其它服务商接受纯文本 "success"。
+// Stripe、空中云汇和 PayPal 接受空 200，其它服务商接受纯文本 "success"。
 func writeSuccessResponse(c *gin.Context, providerKey string) {
 	switch providerKey {
 	case payment.TypeWxpay:
 		c.JSON(http.StatusOK, wxpaySuccessResponse{Code: wxpaySuccessCode, Message: wxpaySuccessMessage})
-	case payment.TypeStripe, payment.TypeAirwallex:
+	case payment.TypeStripe, payment.TypeAirwallex, payment.TypePayPal:
 		c.String(http.StatusOK, "")
 	default:
 		c.String(http.StatusOK, "success")
@@ -54,6 +54,13 @@ func TestWriteSuccessResponse(t *testing.T) {
 			wantContentType: "text/plain",
 			wantBody:        "",
 		},
+		{
+			name:            "paypal returns empty 200",
+			providerKey:     payment.TypePayPal,
+			wantCode:        http.StatusOK,
+			wantContentType: "text/plain",
+			wantBody:        "",
+		},
 		{
 			name:            "easypay returns plain text success",
 			providerKey:     "easypay",
@@ -178,6 +185,18 @@ func TestExtractOutTradeNo(t *testing.T) {
 			rawBody:     `{"name":"payment_intent.succeeded","data":{"object":{"merchant_order_id":"sub2_awx_123"}}}`,
 			want:        "sub2_awx_123",
 		},
+		{
+			name:        "paypal capture payload",
+			providerKey: payment.TypePayPal,
+			rawBody:     `{"event_type":"PAYMENT.CAPTURE.COMPLETED","resource":{"id":"3C6","custom_id":"sub2_pp_1"}}`,
+			want:        "sub2_pp_1",
+		},
+		{
+			name:        "paypal order payload",
+			providerKey: payment.TypePayPal,
+			rawBody:     `{"event_type":"CHECKOUT.ORDER.APPROVED","resource":{"id":"5O1","purchase_units":[{"custom_id":"sub2_pp_2"}]}}`,
+			want:        "sub2_pp_2",
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,248 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	pkghttputil "github.com/Wei-Shaw/sub2api/internal/pkg/httputil"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ResponseCacheHandler 在网关处理器之前提供精确匹配响应缓存。
+//
+// 客户端可用 Cache-Control 控制：no-store 完全绕过缓存（不读也不写），
+// no-cache 跳过读取但仍写入本次响应。查缓存前先执行与网关处理器相同的安全审核，
+// 命中时仍做并发槽位与余额/限额/RPM 检查，只跳过调度与上游；
+// 计费复用网关的 usage 记录任务池。
+type ResponseCacheHandler struct {
+	cache   *service.ResponseCacheService
+	gateway *GatewayHandler
+}
+
+func NewResponseCacheHandler(cache *service.ResponseCacheService, gateway *GatewayHandler) *ResponseCacheHandler {
+	return &ResponseCacheHandler{cache: cache, gateway: gateway}
+}
+
+// Middleware 返回指定入口端点的缓存中间件（endpoint 为 service.ResponseCacheEndpoint*）。
+func (h *ResponseCacheHandler) Middleware(endpoint string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if h == nil || !h.cache.Enabled() {
+			c.Next()
+			return
+		}
+		apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+		if !ok || apiKey == nil || apiKey.User == nil || !h.cache.EnabledForGroup(apiKey.Group) || apiKey.Group.ClaudeCodeOnly {
+			c.Next()
+			return
+		}
+		cacheControl := strings.ToLower(c.GetHeader("Cache-Control"))
+		if strings.Contains(cacheControl, "no-store") {
+			c.Next()
+			return
+		}
+		req, body, ok := h.prepare(c, apiKey, endpoint)
+		if !ok {
+			c.Next()
+			return
+		}
+		// 审核通过的结果记录在 gin.Context 中，未命中时后续处理器不会重复审核。
+		if !h.checkSecurityAudit(c, apiKey, endpoint, req.Model, body) {
+			c.Abort()
+			return
+		}
+
+		if !strings.Contains(cacheControl, "no-cache") {
+			if entry, hit := h.cache.Lookup(c.Request.Context(), req); hit && h.replay(c, apiKey, req, entry) {
+				return
+			}
+		}
+
+		c.Header(service.ResponseCacheHeader, "MISS")
+		writer := &responseCacheCaptureWriter{ResponseWriter: c.Writer, limit: int(h.cache.MaxBodyBytes())}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		if writer.overflow || writer.Status() != http.StatusOK {
+			return
+		}
+		// 降级链换用了其他模型时不写入：该响应不是所请求模型的结果，不能在原模型的键下回放。
+		if served := writer.Header().Get(ModelUsedHeader); served != "" && served != req.Model {
+			return
+		}
+		accountID, _ := c.Get(opsAccountIDKey)
+		id, _ := accountID.(int64)
+		h.cache.Store(c.Request.Context(), req, writer.Status(), writer.Header().Get("Content-Type"), writer.buf.Bytes(), id)
+	}
+}
+
+// prepare 读取请求体并计算缓存键；读取后把原始字节放回 Body，后续处理器照常解析。
+func (h *ResponseCacheHandler) prepare(c *gin.Context, apiKey *service.APIKey, endpoint string) (*service.ResponseCacheRequest, []byte, bool) {
+	if c.Request.Body == nil {
+		return nil, nil, false
+	}
+	if enc := strings.ToLower(strings.TrimSpace(c.GetHeader("Content-Encoding"))); enc != "" && enc != "identity" {
+		return nil, nil, false
+	}
+	limit := h.cache.MaxBodyBytes()
+	if c.Request.ContentLength > limit {
+		return nil, nil, false
+	}
+	original := c.Request.Body
+	raw, err := io.ReadAll(io.LimitReader(original, limit+1))
+	c.Request.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(raw), original), original}
+	if err != nil || int64(len(raw)) > limit {
+		return nil, nil, false
+	}
+	body, err := pkghttputil.NormalizeLenientJSONRequestBody(raw, limit)
+	if err != nil {
+		return nil, nil, false
+	}
+	variant := ""
+	if endpoint == service.ResponseCacheEndpointMessages {
+		variant = c.GetHeader("anthropic-version") + "|" + c.GetHeader("anthropic-beta")
+	}
+	// 请求头形式的降级链不在请求体内，需并入缓存键（请求体字段 fallback_models 已随请求体计入）。
+	if fallback := strings.TrimSpace(c.GetHeader(ModelFallbackHeader)); fallback != "" {
+		variant += "|fallback=" + fallback
+	}
+	req, ok := h.cache.PrepareRequest(apiKey.Group, apiKey.User.ID, endpoint, body, variant)
+	return req, body, ok
+}
+
+// checkSecurityAudit 按入口端点的协议执行安全审核（含内容审核与提示词审计），
+// 拦截时按端点协议写出错误并返回 false。
+func (h *ResponseCacheHandler) checkSecurityAudit(c *gin.Context, apiKey *service.APIKey, endpoint, model string, body []byte) bool {
+	subject, _ := middleware2.GetAuthSubjectFromContext(c)
+	protocol := service.ContentModerationProtocolAnthropicMessages
+	switch endpoint {
+	case service.ResponseCacheEndpointChatCompletions:
+		protocol = service.ContentModerationProtocolOpenAIChat
+	case service.ResponseCacheEndpointResponses:
+		protocol = service.ContentModerationProtocolOpenAIResponses
+	}
+	reqLog := requestLogger(c, "handler.response_cache.audit", zap.Int64("api_key_id", apiKey.ID))
+	decision := h.gateway.checkSecurityAudit(c, reqLog, apiKey, subject, protocol, model, body)
+	if decision == nil || decision.AllowNextStage {
+		return true
+	}
+	switch endpoint {
+	case service.ResponseCacheEndpointChatCompletions:
+		h.gateway.openAISecurityAuditError(c, decision)
+	case service.ResponseCacheEndpointResponses:
+		h.gateway.responsesSecurityAuditError(c, decision)
+	default:
+		h.gateway.anthropicSecurityAuditError(c, decision)
+	}
+	return false
+}
+
+// errorResponse 按入口端点的协议格式写出错误。
+func (h *ResponseCacheHandler) errorResponse(c *gin.Context, endpoint string, status int, code, message string) {
+	switch endpoint {
+	case service.ResponseCacheEndpointChatCompletions:
+		h.gateway.chatCompletionsErrorResponse(c, status, code, message)
+	case service.ResponseCacheEndpointResponses:
+		h.gateway.responsesErrorResponse(c, status, code, message)
+	default:
+		h.gateway.errorResponse(c, status, code, message)
+	}
+}
+
+// replay 回放缓存响应并提交命中计费。命中请求与正常请求一样占用用户/Key 并发槽位；
+// 槽位已满或模型受限时返回 false，交由正常处理器排队或按原有逻辑返回错误。
+// 余额/订阅/限额/RPM 检查在此完成，未通过时直接返回错误，避免处理器再次累加 RPM 计数。
+func (h *ResponseCacheHandler) replay(c *gin.Context, apiKey *service.APIKey, req *service.ResponseCacheRequest, entry *service.ResponseCacheEntry) bool {
+	ctx := c.Request.Context()
+	if h.cache.ModelRestricted(ctx, apiKey.GroupID, req.Model) {
+		return false
+	}
+	subject, _ := middleware2.GetAuthSubjectFromContext(c)
+	release, acquired, err := h.gateway.concurrencyHelper.TryAcquireUserSlotForAPIKey(ctx, subject.UserID, subject.Concurrency, apiKey.ID, apiKey.MaxConcurrency)
+	if err != nil || !acquired {
+		return false
+	}
+	defer release()
+
+	subscription, _ := middleware2.GetSubscriptionFromContext(c)
+	quotaPlatform := service.QuotaPlatform(ctx, apiKey)
+	if err := h.gateway.billingCacheService.CheckBillingEligibility(ctx, apiKey.User, apiKey, apiKey.Group, subscription, quotaPlatform); err != nil {
+		status, code, message, retryAfter := billingErrorDetails(err)
+		if retryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+		}
+		h.errorResponse(c, req.Endpoint, status, code, message)
+		c.Abort()
+		return true
+	}
+
+	c.Header(service.ResponseCacheHeader, "HIT")
+	c.Data(entry.StatusCode, entry.ContentType, entry.Body)
+	c.Abort()
+
+	input := &service.ResponseCacheHitInput{
+		Entry:         entry,
+		Endpoint:      req.Endpoint,
+		RequestModel:  req.Model,
+		APIKey:        apiKey,
+		User:          apiKey.User,
+		Subscription:  subscription,
+		UserAgent:     c.GetHeader("User-Agent"),
+		IPAddress:     ip.GetClientIP(c),
+		APIKeyService: h.gateway.apiKeyService,
+		QuotaPlatform: quotaPlatform,
+	}
+	h.gateway.submitMandatoryUsageRecordTask(ctx, func(ctx context.Context) {
+		if err := h.cache.RecordHit(ctx, input); err != nil {
+			logger.L().With(
+				zap.String("component", "handler.response_cache"),
+				zap.Int64("api_key_id", apiKey.ID),
+				zap.Error(err),
+			).Error("response_cache.record_hit_failed")
+		}
+	})
+	return true
+}
+
+// responseCacheCaptureWriter 透传响应的同时缓存响应体，超过上限后停止缓存并标记溢出。
+type responseCacheCaptureWriter struct {
+	gin.ResponseWriter
+	limit    int
+	buf      bytes.Buffer
+	overflow bool
+}
+
+func (w *responseCacheCaptureWriter) capture(n int, write func()) {
+	if w.overflow {
+		return
+	}
+	if w.buf.Len()+n > w.limit {
+		w.overflow = true
+		w.buf.Reset()
+		return
+	}
+	write()
+}
+
+func (w *responseCacheCaptureWriter) Write(b []byte) (int, error) {
+	w.capture(len(b), func() { _, _ = w.buf.Write(b) })
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseCacheCaptureWriter) WriteString(s string) (int, error) {
+	w.capture(len(s), func() { _, _ = w.buf.WriteString(s) })
+	return w.ResponseWriter.WriteString(s)
+}
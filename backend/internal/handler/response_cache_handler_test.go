@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/securityaudit"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+type responseCacheHandlerTestStore struct {
+	mu      sync.Mutex
+	gets    int
+	entries map[string]*service.ResponseCacheEntry
+}
+
+func (s *responseCacheHandlerTestStore) Get(_ context.Context, key string) (*service.ResponseCacheEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gets++
+	if entry, ok := s.entries[key]; ok {
+		return entry, nil
+	}
+	return nil, service.ErrResponseCacheMiss
+}
+
+func (s *responseCacheHandlerTestStore) Set(_ context.Context, key string, entry *service.ResponseCacheEntry, _ time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = entry
+	return nil
+}
+
+func newResponseCacheHandlerTestRouter(engine securityaudit.PromptEngine, store service.ResponseCacheStore, next gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{}
+	cfg.ResponseCache = config.ResponseCacheConfig{Enabled: true, DefaultTTLSeconds: 60, MaxBodyBytes: 1 << 20}
+	h := NewResponseCacheHandler(
+		service.NewResponseCacheService(cfg, store, nil),
+		&GatewayHandler{securityAuditCoordinator: securityaudit.NewCoordinator(nil, engine)},
+	)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		groupID := int64(3)
+		c.Set(string(middleware2.ContextKeyAPIKey), &service.APIKey{
+			ID: 9, UserID: 7, User: &service.User{ID: 7}, GroupID: &groupID,
+			Group: &service.Group{ID: groupID, Platform: service.PlatformOpenAI, ResponseCacheEnabled: true},
+		})
+		c.Set(string(middleware2.ContextKeyUser), middleware2.AuthSubject{UserID: 7, Concurrency: 2})
+		c.Next()
+	})
+	router.POST("/v1/chat/completions", h.Middleware(service.ResponseCacheEndpointChatCompletions), next)
+	return router
+}
+
+func TestResponseCacheMiddlewareAuditsBeforeCacheLookup(t *testing.T) {
+	engine := &matchingPromptEngine{handlerPromptEngine: handlerPromptEngine{mode: securityaudit.ModeBlocking}, needle: "blocked cached prompt"}
+	store := &responseCacheHandlerTestStore{entries: map[string]*service.ResponseCacheEntry{}}
+	nextCalls := 0
+	router := newResponseCacheHandlerTestRouter(engine, store, func(c *gin.Context) {
+		nextCalls++
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	blocked := `{"model":"gpt-4o","temperature":0,"messages":[{"role":"user","content":"blocked cached prompt"}]}`
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(blocked)))
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+	require.Contains(t, recorder.Body.String(), securityaudit.ErrorCodeBlocked)
+	require.Zero(t, nextCalls)
+	require.Zero(t, store.gets, "blocked prompts must not reach the cache")
+
+	allowed := `{"model":"gpt-4o","temperature":0,"messages":[{"role":"user","content":"hello"}]}`
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(allowed)))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "MISS", recorder.Header().Get(service.ResponseCacheHeader))
+	require.Equal(t, 1, nextCalls)
+	require.Equal(t, 1, store.gets)
+
+	evaluated, _, _ := engine.snapshot()
+	require.Equal(t, 2, evaluated)
+}
+
+func (s *responseCacheHandlerTestStore) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+func TestResponseCacheMiddlewareSkipsStoreWhenFallbackModelServed(t *testing.T) {
+	engine := &matchingPromptEngine{handlerPromptEngine: handlerPromptEngine{mode: securityaudit.ModeBlocking}, needle: "never"}
+	store := &responseCacheHandlerTestStore{entries: map[string]*service.ResponseCacheEntry{}}
+	router := newResponseCacheHandlerTestRouter(engine, store, func(c *gin.Context) {
+		served := c.GetHeader("X-Test-Served-Model")
+		c.Set(opsAccountIDKey, int64(1))
+		c.Header(ModelUsedHeader, served)
+		c.JSON(http.StatusOK, gin.H{
+			"model": served,
+			"usage": gin.H{"prompt_tokens": 3, "completion_tokens": 2, "total_tokens": 5},
+		})
+	})
+	send := func(content, fallback, served string) {
+		body := `{"model":"gpt-4o","temperature":0,"messages":[{"role":"user","content":"` + content + `"}]}`
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+		if fallback != "" {
+			req.Header.Set(ModelFallbackHeader, fallback)
+		}
+		req.Header.Set("X-Test-Served-Model", served)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		require.Equal(t, http.StatusOK, recorder.Code)
+	}
+
+	// 降级到 gpt-4o-mini 的响应不得写入 gpt-4o 的缓存键。
+	send("degraded", "gpt-4o-mini", "gpt-4o-mini")
+	send("hello", "gpt-4o-mini", "gpt-4o")
+	require.Eventually(t, func() bool { return store.count() == 1 }, time.Second, 10*time.Millisecond)
+
+	// 降级链请求头计入缓存键：不带降级链的同一请求不会命中上面的条目。
+	send("hello", "", "gpt-4o")
+	require.Eventually(t, func() bool { return store.count() == 2 }, time.Second, 10*time.Millisecond)
+	require.Never(t, func() bool { return store.count() > 2 }, 100*time.Millisecond, 10*time.Millisecond)
+}
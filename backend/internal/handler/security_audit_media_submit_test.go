@@ -206,3 +206,71 @@ func TestSecurityAuditBlockingFailuresLeaveAllDownstreamCountersAtZero(t *testin
 		})
 	}
 }
+
+// matchingPromptEngine 只拦截请求体中包含 needle 的审核请求。
+type matchingPromptEngine struct {
+	handlerPromptEngine
+	needle string
+}
+
+func (e *matchingPromptEngine) Evaluate(_ context.Context, req securityaudit.Request) (*securityaudit.PromptDecision, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.evaluated++
+	e.requests = append(e.requests, req.Clone())
+	if strings.Contains(string(req.Body), e.needle) {
+		return &securityaudit.PromptDecision{Kind: securityaudit.DecisionBlock, ErrorCode: securityaudit.ErrorCodeBlocked}, nil
+	}
+	return &securityaudit.PromptDecision{Kind: securityaudit.DecisionAllow, AllowNextStage: true}, nil
+}
+
+func TestMessageBatchPromptGuardAuditsEveryEntryBeforeCreate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := &matchingPromptEngine{handlerPromptEngine: handlerPromptEngine{mode: securityaudit.ModeBlocking}, needle: "blocked batch entry"}
+	gateway := &GatewayHandler{securityAuditCoordinator: securityaudit.NewCoordinator(nil, engine)}
+	h := &MessageBatchHandler{gateway: gateway}
+	router := gin.New()
+	router.Use(securityAuditMediaTestMiddleware)
+	router.POST("/v1/messages/batches", h.Create)
+	body := `{"requests":[` +
+		`{"custom_id":"a","params":{"model":"claude-sonnet-4-5","max_tokens":16,"messages":[{"role":"user","content":"hello"}]}},` +
+		`{"custom_id":"b","params":{"model":"claude-haiku-4-5","max_tokens":16,"messages":[{"role":"user","content":"blocked batch entry"}]}}]}`
+	request := httptest.NewRequest(http.MethodPost, "/v1/messages/batches", strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	require.NotPanics(t, func() { router.ServeHTTP(recorder, request) }, "nil service would panic if Create were reached")
+
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+	require.Contains(t, recorder.Body.String(), securityaudit.ErrorCodeBlocked)
+	evaluated, _, requests := engine.snapshot()
+	require.Equal(t, 2, evaluated)
+	require.Equal(t, service.ContentModerationProtocolAnthropicMessages, requests[0].Protocol)
+	require.Equal(t, "claude-sonnet-4-5", requests[0].Model)
+	require.Equal(t, "batch", requests[1].Stage)
+	require.Equal(t, "claude-haiku-4-5", requests[1].Model)
+	require.NotContains(t, string(requests[1].Body), "custom_id")
+}
+
+func TestOpenAIBatchLineAuditorBlocksAndKeepsDecision(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := &matchingPromptEngine{handlerPromptEngine: handlerPromptEngine{mode: securityaudit.ModeBlocking}, needle: "blocked batch line"}
+	h := &OpenAIBatchHandler{openAI: &OpenAIGatewayHandler{securityAuditCoordinator: securityaudit.NewCoordinator(nil, engine)}}
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/files", nil)
+	apiKey := &service.APIKey{ID: 9, UserID: 7, User: &service.User{ID: 7}}
+
+	var blocked *securityaudit.Decision
+	audit := h.batchLineAuditor(c, apiKey, middleware2.AuthSubject{UserID: 7}, &blocked)
+	require.NoError(t, audit("/v1/responses", "gpt-5", []byte(`{"model":"gpt-5","input":"fine"}`)))
+	require.Nil(t, blocked)
+	require.Error(t, audit("/v1/chat/completions", "gpt-5", []byte(`{"model":"gpt-5","messages":[{"role":"user","content":"blocked batch line"}]}`)))
+	require.NotNil(t, blocked)
+	require.False(t, blocked.AllowNextStage)
+
+	evaluated, _, requests := engine.snapshot()
+	require.Equal(t, 2, evaluated)
+	require.Equal(t, service.ContentModerationProtocolOpenAIResponses, requests[0].Protocol)
+	require.Equal(t, service.ContentModerationProtocolOpenAIChat, requests[1].Protocol)
+	require.Equal(t, "batch", requests[1].Stage)
+}
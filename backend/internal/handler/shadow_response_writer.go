@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const shadowResponseNotWritten = -1
+
+// shadowResponseWriter 是影子 gin.Context 使用的内存 gin.ResponseWriter：记录状态码与响应头，
+// 响应体缓冲在内存中，由调用方决定是否回放给客户端。语义与 gin 自带的 responseWriter 一致，
+// 首次写入时才固定状态码。
+type shadowResponseWriter struct {
+	header http.Header
+	status int
+	size   int
+	body   bytes.Buffer
+}
+
+var _ gin.ResponseWriter = (*shadowResponseWriter)(nil)
+
+func newShadowResponseWriter() *shadowResponseWriter {
+	return &shadowResponseWriter{header: http.Header{}, status: http.StatusOK, size: shadowResponseNotWritten}
+}
+
+func (w *shadowResponseWriter) Header() http.Header { return w.header }
+
+func (w *shadowResponseWriter) WriteHeader(code int) {
+	if code > 0 && !w.Written() {
+		w.status = code
+	}
+}
+
+func (w *shadowResponseWriter) WriteHeaderNow() {
+	if !w.Written() {
+		w.size = 0
+	}
+}
+
+func (w *shadowResponseWriter) Write(b []byte) (int, error) {
+	w.WriteHeaderNow()
+	n, err := w.body.Write(b)
+	w.size += n
+	return n, err
+}
+
+func (w *shadowResponseWriter) WriteString(s string) (int, error) {
+	w.WriteHeaderNow()
+	n, err := w.body.WriteString(s)
+	w.size += n
+	return n, err
+}
+
+func (w *shadowResponseWriter) Status() int   { return w.status }
+func (w *shadowResponseWriter) Size() int     { return w.size }
+func (w *shadowResponseWriter) Written() bool { return w.size != shadowResponseNotWritten }
+func (w *shadowResponseWriter) Flush()        { w.WriteHeaderNow() }
+
+// Hijack 不支持：影子响应没有底层连接。
+func (w *shadowResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, http.ErrNotSupported
+}
+
+// CloseNotify 返回永不触发的通道；影子请求的取消通过 Request.Context 传递。
+func (w *shadowResponseWriter) CloseNotify() <-chan bool { return make(chan bool) }
+
+func (w *shadowResponseWriter) Pusher() http.Pusher { return nil }
+
+// Bytes 返回已缓冲的响应体。
+func (w *shadowResponseWriter) Bytes() []byte { return w.body.Bytes() }
+
+// newShadowContext 从 c 复制出在 ctx 上运行的影子上下文：保留引擎设置与上下文键，
+// 请求体替换为 body 的独立副本（并发的影子之间不共享 reader），响应写入 w。
+func newShadowContext(ctx context.Context, c *gin.Context, body []byte, w gin.ResponseWriter) *gin.Context {
+	shadow := c.Copy()
+	shadow.Request = c.Request.Clone(ctx)
+	if body != nil {
+		setModelFallbackRequestBody(shadow, body)
+	}
+	shadow.Writer = w
+	return shadow
+}
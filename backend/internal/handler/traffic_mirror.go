@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	pkghttputil "github.com/Wei-Shaw/sub2api/internal/pkg/httputil"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"go.uber.org/zap"
+)
+
+const (
+	// trafficMirrorTimeout 单次镜像重放的最长耗时，超时记为 mirror_error。
+	trafficMirrorTimeout = 10 * time.Minute
+	// 观测响应时保留的头部与尾部字节数：非流式响应通常完整落在头部，
+	// 流式响应的最终模型与用量在尾部事件里。
+	trafficMirrorCaptureHead = 1 << 20
+	trafficMirrorCaptureTail = 256 << 10
+)
+
+// TrafficMirrorHandler 按分组镜像策略把抽中的文本请求异步重放到候选分组/账号，
+// 镜像响应对客户端不可见、不计费，只留下与主请求的对比记录。
+type TrafficMirrorHandler struct {
+	mirror *service.TrafficMirrorService
+}
+
+// NewTrafficMirrorHandler 创建流量镜像处理器。
+func NewTrafficMirrorHandler(mirror *service.TrafficMirrorService) *TrafficMirrorHandler {
+	return &TrafficMirrorHandler{mirror: mirror}
+}
+
+// Wrap 包装文本生成端点的处理器 next。抽样命中时先克隆请求，主请求正常处理并原样返回给客户端，
+// 随后在后台以镜像上下文（候选分组、跳过计费与用户并发）在影子 gin.Context 上再执行一次 next，
+// 两侧响应只用于生成对比记录。主请求为客户端错误（4xx）或未产生响应时不镜像。
+func (h *TrafficMirrorHandler) Wrap(endpoint string, next gin.HandlerFunc) gin.HandlerFunc {
+	if h == nil || h.mirror == nil {
+		return next
+	}
+	return func(c *gin.Context) {
+		if c.Request == nil || c.Request.Method != http.MethodPost || c.Request.Body == nil {
+			next(c)
+			return
+		}
+		if _, forced := middleware2.GetForcePlatformFromContext(c); forced {
+			next(c)
+			return
+		}
+		apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+		if !ok || apiKey == nil || apiKey.Group == nil || !apiKey.Group.MirrorPolicy.Enabled {
+			next(c)
+			return
+		}
+		body, err := pkghttputil.ReadRequestBodyWithPrealloc(c.Request)
+		if err != nil {
+			c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), modelFallbackErrReader{err: err}))
+			next(c)
+			return
+		}
+		setModelFallbackRequestBody(c, body)
+
+		plan, release, ok := h.mirror.Begin(c.Request.Context(), apiKey.Group)
+		if !ok {
+			next(c)
+			return
+		}
+		shadow, cancel := newTrafficMirrorContext(c, apiKey, plan, body)
+		shadowWriter := shadow.Writer.(*trafficMirrorCaptureWriter)
+
+		primaryWriter := newTrafficMirrorCaptureWriter(c.Writer)
+		c.Writer = primaryWriter
+		next(c)
+		c.Writer = primaryWriter.ResponseWriter
+		primary := primaryWriter.observe(c)
+
+		if primary.Status == 0 || (primary.Status >= 400 && primary.Status < 500) {
+			cancel()
+			release()
+			return
+		}
+		req := service.TrafficMirrorRequest{
+			Endpoint: endpoint,
+			Model:    gjson.GetBytes(body, "model").String(),
+			Stream:   gjson.GetBytes(body, "stream").Bool(),
+		}
+		if requestID, _ := c.Request.Context().Value(ctxkey.ClientRequestID).(string); requestID != "" {
+			req.ClientRequestID = requestID
+		}
+		if !req.Stream {
+			primary.TTFTMs = nil
+		}
+		go func() {
+			defer release()
+			defer cancel()
+			defer func() {
+				if r := recover(); r != nil {
+					logger.L().With(zap.String("component", "handler.traffic_mirror")).Error("traffic_mirror.panic",
+						zap.Int64("group_id", plan.SourceGroup.ID), zap.Any("panic", r))
+				}
+			}()
+			shadowWriter.started = time.Now()
+			next(shadow)
+			mirror := shadowWriter.observe(shadow)
+			if mirror.Error == "" && shadow.Request.Context().Err() != nil {
+				mirror.Error = fmt.Sprintf("mirror request aborted: %v", shadow.Request.Context().Err())
+			}
+			if !req.Stream {
+				mirror.TTFTMs = nil
+			}
+			h.mirror.Record(service.NewTrafficMirrorRecord(plan, req, primary, mirror))
+		}()
+	}
+}
+
+// newTrafficMirrorContext 在主请求执行前克隆出影子上下文：请求体、API Key 的分组均指向候选分组，
+// 去掉订阅信息避免任何订阅用量被累计，响应写入只做观测的丢弃型 writer。
+func newTrafficMirrorContext(c *gin.Context, apiKey *service.APIKey, plan *service.TrafficMirrorPlan, body []byte) (*gin.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Request.Context()), trafficMirrorTimeout)
+	ctx = service.WithTrafficMirror(ctx, plan)
+
+	shadow := newShadowContext(ctx, c, body, newTrafficMirrorCaptureWriter(newTrafficMirrorDiscardWriter()))
+
+	shadowKey := *apiKey
+	targetGroupID := plan.TargetGroup.ID
+	shadowKey.Group = plan.TargetGroup
+	shadowKey.GroupID = &targetGroupID
+	shadow.Set(string(middleware2.ContextKeyAPIKey), &shadowKey)
+	delete(shadow.Keys, string(middleware2.ContextKeySubscription))
+	return shadow, cancel
+}
+
+// trafficMirrorCaptureWriter 透传写入的同时记录首字节时间，并保留响应头部与尾部用于观测。
+type trafficMirrorCaptureWriter struct {
+	gin.ResponseWriter
+	started    time.Time
+	firstWrite time.Time
+	head       bytes.Buffer
+	tail       []byte
+	truncated  bool
+}
+
+func newTrafficMirrorCaptureWriter(w gin.ResponseWriter) *trafficMirrorCaptureWriter {
+	return &trafficMirrorCaptureWriter{ResponseWriter: w, started: time.Now()}
+}
+
+func (w *trafficMirrorCaptureWriter) capture(b []byte) {
+	if w.firstWrite.IsZero() && len(b) > 0 {
+		w.firstWrite = time.Now()
+	}
+	if room := trafficMirrorCaptureHead - w.head.Len(); room > 0 {
+		n := min(room, len(b))
+		w.head.Write(b[:n])
+		b = b[n:]
+	}
+	if len(b) == 0 {
+		return
+	}
+	w.truncated = true
+	w.tail = append(w.tail, b...)
+	if over := len(w.tail) - trafficMirrorCaptureTail; over > 0 {
+		w.tail = append(w.tail[:0], w.tail[over:]...)
+	}
+}
+
+func (w *trafficMirrorCaptureWriter) Write(b []byte) (int, error) {
+	w.capture(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *trafficMirrorCaptureWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// observe 汇总这一侧的状态码、耗时、首字节时间、选中账号与响应内容。
+func (w *trafficMirrorCaptureWriter) observe(c *gin.Context) service.TrafficMirrorObservation {
+	obs := service.TrafficMirrorObservation{LatencyMs: int(time.Since(w.started).Milliseconds())}
+	if w.Written() {
+		obs.Status = w.Status()
+	}
+	if !w.firstWrite.IsZero() {
+		ttft := int(w.firstWrite.Sub(w.started).Milliseconds())
+		obs.TTFTMs = &ttft
+	}
+	if value, ok := c.Get(opsAccountIDKey); ok {
+		if id, ok := value.(int64); ok {
+			obs.AccountID = id
+		}
+	}
+	body := w.head.Bytes()
+	if w.truncated {
+		// 尾部从第一个完整行开始拼接，避免半截 SSE 事件。
+		tail := w.tail
+		if idx := bytes.IndexByte(tail, '\n'); idx >= 0 {
+			tail = tail[idx+1:]
+		}
+		body = append(append(append([]byte(nil), body...), '\n'), tail...)
+	}
+	parsed := service.ObserveTrafficMirrorResponse(body)
+	obs.Model = parsed.Model
+	obs.InputTokens = parsed.InputTokens
+	obs.OutputTokens = parsed.OutputTokens
+	obs.Output = parsed.Output
+	if obs.Status == 0 || obs.Status >= 400 {
+		obs.Error = parsed.Error
+	}
+	return obs
+}
+
+// trafficMirrorDiscardWriter 丢弃镜像响应体，只保留状态码、响应头与已写字节数；
+// 其余 gin.ResponseWriter 行为（含流式处理器需要的 Flush）沿用 shadowResponseWriter。
+type trafficMirrorDiscardWriter struct {
+	*shadowResponseWriter
+}
+
+func newTrafficMirrorDiscardWriter() trafficMirrorDiscardWriter {
+	return trafficMirrorDiscardWriter{shadowResponseWriter: newShadowResponseWriter()}
+}
+
+func (w trafficMirrorDiscardWriter) Write(b []byte) (int, error) {
+	w.WriteHeaderNow()
+	w.size += len(b)
+	return len(b), nil
+}
+
+func (w trafficMirrorDiscardWriter) WriteString(s string) (int, error) {
+	w.WriteHeaderNow()
+	w.size += len(s)
+	return len(s), nil
+}
@@ -0,0 +1,154 @@
+//go:build unit
+
+package handler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	middleware "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+type trafficMirrorHandlerRepoStub struct {
+	mu      sync.Mutex
+	records []service.TrafficMirrorRecord
+}
+
+func (s *trafficMirrorHandlerRepoStub) InsertRecords(_ context.Context, records []service.TrafficMirrorRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, records...)
+	return nil
+}
+
+func (s *trafficMirrorHandlerRepoStub) ListRecords(context.Context, service.TrafficMirrorFilter) ([]service.TrafficMirrorRecord, int64, error) {
+	return nil, 0, nil
+}
+
+func (s *trafficMirrorHandlerRepoStub) Summarize(context.Context, service.TrafficMirrorFilter) ([]service.TrafficMirrorSummary, error) {
+	return nil, nil
+}
+
+func (s *trafficMirrorHandlerRepoStub) DeleteRecordsBefore(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (s *trafficMirrorHandlerRepoStub) snapshot() []service.TrafficMirrorRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]service.TrafficMirrorRecord(nil), s.records...)
+}
+
+type trafficMirrorHandlerGroupRepoStub struct {
+	service.GroupRepository
+	target *service.Group
+}
+
+func (s *trafficMirrorHandlerGroupRepoStub) GetByIDLite(context.Context, int64) (*service.Group, error) {
+	return s.target, nil
+}
+
+func newTrafficMirrorTestContext(body string) (*gin.Context, *httptest.ResponseRecorder) {
+	targetID := int64(2)
+	source := &service.Group{ID: 1, Platform: service.PlatformOpenAI, MirrorPolicy: service.GroupMirrorPolicy{
+		Enabled: true, SampleRate: 1, TargetGroupID: &targetID, CompareOutput: true,
+	}}
+	c, w := newModelFallbackTestContext(body, "", source)
+	c.Set(string(middleware.ContextKeySubscription), &service.UserSubscription{ID: 7})
+	return c, w
+}
+
+func TestTrafficMirrorWrap_ReplaysToCandidateAndDiscardsResponse(t *testing.T) {
+	repo := &trafficMirrorHandlerRepoStub{}
+	target := &service.Group{ID: 2, Platform: service.PlatformAnthropic, Hydrated: true}
+	mirror := service.NewTrafficMirrorService(repo, &trafficMirrorHandlerGroupRepoStub{target: target})
+	mirror.Start()
+	defer mirror.Stop()
+
+	c, w := newTrafficMirrorTestContext(`{"model":"gpt-5","stream":false}`)
+	handler := NewTrafficMirrorHandler(mirror).Wrap(service.CompositeRouteEndpointChatCompletions, func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"model":"gpt-5","stream":false}`, string(body))
+		apiKey, _ := middleware.GetAPIKeyFromContext(c)
+		if !service.IsTrafficMirrorContext(c.Request.Context()) {
+			require.EqualValues(t, 1, apiKey.Group.ID)
+			c.Set(opsAccountIDKey, int64(5))
+			c.JSON(http.StatusOK, gin.H{"model": "gpt-5", "choices": []gin.H{{"message": gin.H{"content": "the answer is 42"}}}, "usage": gin.H{"prompt_tokens": 10, "completion_tokens": 4}})
+			return
+		}
+		require.EqualValues(t, 2, apiKey.Group.ID, "the shadow request runs on the candidate group")
+		require.EqualValues(t, 2, *apiKey.GroupID)
+		_, hasSubscription := middleware.GetSubscriptionFromContext(c)
+		require.False(t, hasSubscription, "mirrored calls never touch the caller's subscription")
+		c.Set(opsAccountIDKey, int64(8))
+		c.JSON(http.StatusOK, gin.H{"model": "claude-sonnet-4-5", "content": []gin.H{{"type": "text", "text": "the answer is 41"}}, "usage": gin.H{"input_tokens": 11, "output_tokens": 5}})
+	})
+	handler(c)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), "the answer is 42")
+	require.NotContains(t, w.Body.String(), "41", "the mirrored response never reaches the client")
+
+	require.Eventually(t, func() bool { return len(repo.snapshot()) == 1 }, 5*time.Second, 20*time.Millisecond)
+	record := repo.snapshot()[0]
+	require.EqualValues(t, 1, record.GroupID)
+	require.EqualValues(t, 2, record.TargetGroupID)
+	require.Equal(t, "gpt-5", record.Model)
+	require.EqualValues(t, 5, *record.PrimaryAccountID)
+	require.EqualValues(t, 8, *record.MirrorAccountID)
+	require.Equal(t, 200, record.MirrorStatus)
+	require.Equal(t, "claude-sonnet-4-5", record.MirrorModel)
+	require.Equal(t, 11, record.MirrorInputTokens)
+	require.Equal(t, 4, record.PrimaryOutputTokens)
+	require.Nil(t, record.MirrorTTFTMs, "TTFT is only recorded for streaming requests")
+	require.NotNil(t, record.OutputSimilarity)
+}
+
+func TestTrafficMirrorWrap_SkipsClientErrors(t *testing.T) {
+	repo := &trafficMirrorHandlerRepoStub{}
+	mirror := service.NewTrafficMirrorService(repo, &trafficMirrorHandlerGroupRepoStub{target: &service.Group{ID: 2, Platform: service.PlatformOpenAI, Hydrated: true}})
+
+	c, w := newTrafficMirrorTestContext(`{"model":"gpt-5"}`)
+	calls := 0
+	NewTrafficMirrorHandler(mirror).Wrap(service.CompositeRouteEndpointMessages, func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"message": "bad"}})
+	})(c)
+	mirror.Stop()
+
+	require.Equal(t, 1, calls, "client errors are not mirrored")
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Empty(t, repo.snapshot())
+
+	plain := NewTrafficMirrorHandler(nil).Wrap(service.CompositeRouteEndpointMessages, func(c *gin.Context) { calls++ })
+	c, _ = newModelFallbackTestContext(strings.Repeat(" ", 2), "", &service.Group{ID: 1})
+	plain(c)
+	require.Equal(t, 2, calls, "without a mirror service the handler is passed through")
+}
+
+func TestTrafficMirrorDiscardWriter_TracksStatusWithoutBuffering(t *testing.T) {
+	var w gin.ResponseWriter = newTrafficMirrorDiscardWriter()
+	require.False(t, w.Written())
+
+	w.WriteHeader(http.StatusAccepted)
+	w.Flush()
+	require.True(t, w.Written())
+	n, err := w.WriteString("data: {}\n\n")
+	require.NoError(t, err)
+	require.Equal(t, 10, n)
+	w.WriteHeader(http.StatusInternalServerError)
+
+	require.Equal(t, http.StatusAccepted, w.Status(), "status is fixed once the response starts")
+	require.Equal(t, 10, w.Size())
+	require.Empty(t, w.(trafficMirrorDiscardWriter).Bytes())
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UsageExportHandler 用户导出自己的用量明细（CSV/Parquet）
+type UsageExportHandler struct {
+	usageExportService *service.UsageExportService
+}
+
+// NewUsageExportHandler creates a new user usage export handler
+func NewUsageExportHandler(usageExportService *service.UsageExportService) *UsageExportHandler {
+	return &UsageExportHandler{usageExportService: usageExportService}
+}
+
+// CreateUsageExportRequest 导出请求：日期为系统时区，结束日期包含在内
+type CreateUsageExportRequest struct {
+	StartDate string `json:"start_date" binding:"required"`
+	EndDate   string `json:"end_date" binding:"required"`
+	Format    string `json:"format" binding:"omitempty,oneof=csv parquet"`
+}
+
+// Create 登记一次导出，后台生成文件
+// POST /api/v1/usage/exports
+func (h *UsageExportHandler) Create(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not found in context")
+		return
+	}
+	var req CreateUsageExportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	run, err := h.usageExportService.CreateUserExport(c.Request.Context(), subject.UserID,
+		strings.TrimSpace(req.StartDate), strings.TrimSpace(req.EndDate), req.Format)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, run)
+}
+
+// List 分页查询当前用户的导出记录
+// GET /api/v1/usage/exports
+func (h *UsageExportHandler) List(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not found in context")
+		return
+	}
+	page, pageSize := response.ParsePagination(c)
+	runs, total, err := h.usageExportService.ListRuns(c.Request.Context(), service.UsageExportRunFilter{
+		UserID:   subject.UserID,
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Paginated(c, runs, total, page, pageSize)
+}
+
+// Download 返回当前用户导出文件的限时下载链接
+// GET /api/v1/usage/exports/:id/download
+func (h *UsageExportHandler) Download(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not found in context")
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(c, "Invalid export ID")
+		return
+	}
+	run, err := h.usageExportService.GetRunForUser(c.Request.Context(), subject.UserID, id)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	url, err := h.usageExportService.DownloadURL(c.Request.Context(), run)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"url": url})
+}
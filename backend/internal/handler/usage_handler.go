@@ -453,6 +453,42 @@ func (h *UsageHandler) DashboardStats(c *gin.Context) {
 	response.Success(c, stats)
 }
 
+// DashboardVolumeTiers handles getting the user's monthly spend tier progress
+// GET /api/v1/usage/dashboard/volume-tiers
+func (h *UsageHandler) DashboardVolumeTiers(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	progress, err := h.usageService.GetUserVolumeTierProgress(c.Request.Context(), subject.UserID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"groups": progress})
+}
+
+// DashboardBalanceForecast handles getting the projected balance exhaustion date
+// GET /api/v1/usage/dashboard/balance-forecast
+func (h *UsageHandler) DashboardBalanceForecast(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	forecast, err := h.usageService.GetUserBalanceForecast(c.Request.Context(), subject.UserID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, forecast)
+}
+
 // DashboardTrend handles getting user usage trend data
 // GET /api/v1/usage/dashboard/trend
 func (h *UsageHandler) DashboardTrend(c *gin.Context) {
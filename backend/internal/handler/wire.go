@@ -45,11 +45,22 @@ func ProvideAdminHandlers(
 	affiliateHandler *admin.AffiliateHandler,
 	complianceHandler *admin.ComplianceHandler,
 	auditLogHandler *admin.AuditLogHandler,
+	organizationHandler *admin.OrganizationHandler,
+	balanceLedgerHandler *admin.BalanceLedgerHandler,
+	creditStatementHandler *admin.CreditStatementHandler,
+	spendAnomalyHandler *admin.SpendAnomalyHandler,
+	usageExportHandler *admin.UsageExportHandler,
 	upstreamBillingProbe *service.UpstreamBillingProbeService,
 	ollamaCloudUsage *service.OllamaCloudUsageService,
+	hedgeStats *service.GroupHedgeStatsService,
+	compositeExperiments *service.CompositeRouteExperimentService,
+	trafficMirror *service.TrafficMirrorService,
 ) *AdminHandlers {
 	accountHandler.SetUpstreamBillingProbeService(upstreamBillingProbe)
 	accountHandler.SetOllamaCloudUsageService(ollamaCloudUsage)
+	groupHandler.SetHedgeStatsService(hedgeStats)
+	groupHandler.SetCompositeExperimentService(compositeExperiments)
+	opsHandler.SetTrafficMirrorService(trafficMirror)
 	return &AdminHandlers{
 		Dashboard:              dashboardHandler,
 		User:                   userHandler,
@@ -85,6 +96,11 @@ func ProvideAdminHandlers(
 		Affiliate:              affiliateHandler,
 		Compliance:             complianceHandler,
 		AuditLog:               auditLogHandler,
+		Organization:           organizationHandler,
+		BalanceLedger:          balanceLedgerHandler,
+		CreditStatement:        creditStatementHandler,
+		SpendAnomaly:           spendAnomalyHandler,
+		UsageExport:            usageExportHandler,
 	}
 }
 
@@ -123,6 +139,7 @@ func ProvideOpenAIGatewayHandler(
 	contentModerationService *service.ContentModerationService,
 	opsService *service.OpsService,
 	grokQuotaService *service.GrokQuotaService,
+	hedgeStats *service.GroupHedgeStatsService,
 	cfg *config.Config,
 	coordinator *securityaudit.Coordinator,
 ) *OpenAIGatewayHandler {
@@ -130,6 +147,23 @@ func ProvideOpenAIGatewayHandler(
 		usageRecordWorkerPool, errorPassthroughService, contentModerationService, opsService, cfg)
 	h.securityAuditCoordinator = coordinator
 	h.grokMediaEligibilityProber = grokQuotaService
+	h.hedgeStats = hedgeStats
+	return h
+}
+
+func ProvideAuthHandler(
+	cfg *config.Config,
+	authService *service.AuthService,
+	userService *service.UserService,
+	settingService *service.SettingService,
+	promoService *service.PromoService,
+	redeemService *service.RedeemService,
+	totpService *service.TotpService,
+	userAttributeService *service.UserAttributeService,
+	samlReplayCache service.SAMLAssertionReplayCache,
+) *AuthHandler {
+	h := NewAuthHandler(cfg, authService, userService, settingService, promoService, redeemService, totpService, userAttributeService)
+	h.samlReplayCache = samlReplayCache
 	return h
 }
 
@@ -144,6 +178,18 @@ func ProvideBatchImageHandler(
 	return h
 }
 
+func ProvideMessageBatchHandler(batchService *service.MessageBatchService, gateway *GatewayHandler) *MessageBatchHandler {
+	h := NewMessageBatchHandler(batchService)
+	h.gateway = gateway
+	return h
+}
+
+func ProvideOpenAIBatchHandler(batchService *service.OpenAIBatchService, openAI *OpenAIGatewayHandler) *OpenAIBatchHandler {
+	h := NewOpenAIBatchHandler(batchService)
+	h.openAI = openAI
+	return h
+}
+
 // ProvideSystemHandler creates admin.SystemHandler with UpdateService
 func ProvideSystemHandler(updateService *service.UpdateService, lockService *service.SystemOperationLockService) *admin.SystemHandler {
 	return admin.NewSystemHandler(updateService, lockService)
@@ -188,6 +234,13 @@ func ProvideHandlers(
 	modelPlazaHandler *ModelPlazaHandler,
 	asyncImageHandler *AsyncImageHandler,
 	batchImageHandler *BatchImageHandler,
+	messageBatchHandler *MessageBatchHandler,
+	openAIBatchHandler *OpenAIBatchHandler,
+	responseCacheHandler *ResponseCacheHandler,
+	trafficMirrorHandler *TrafficMirrorHandler,
+	organizationHandler *OrganizationHandler,
+	creditStatementHandler *CreditStatementHandler,
+	usageExportHandler *UsageExportHandler,
 	_ *service.IdempotencyCoordinator,
 	_ *service.IdempotencyCleanupService,
 ) *Handlers {
@@ -213,13 +266,20 @@ func ProvideHandlers(
 		ModelPlaza:       modelPlazaHandler,
 		AsyncImage:       asyncImageHandler,
 		BatchImage:       batchImageHandler,
+		MessageBatch:     messageBatchHandler,
+		OpenAIBatch:      openAIBatchHandler,
+		ResponseCache:    responseCacheHandler,
+		TrafficMirror:    trafficMirrorHandler,
+		Organization:     organizationHandler,
+		CreditStatement:  creditStatementHandler,
+		UsageExport:      usageExportHandler,
 	}
 }
 
 // ProviderSet is the Wire provider set for all handlers
 var ProviderSet = wire.NewSet(
 	// Top-level handlers
-	NewAuthHandler,
+	ProvideAuthHandler,
 	NewUserHandler,
 	NewAPIKeyHandler,
 	NewUsageHandler,
@@ -239,6 +299,13 @@ var ProviderSet = wire.NewSet(
 	NewModelPlazaHandler,
 	NewAsyncImageHandler,
 	ProvideBatchImageHandler,
+	ProvideMessageBatchHandler,
+	ProvideOpenAIBatchHandler,
+	NewResponseCacheHandler,
+	NewTrafficMirrorHandler,
+	NewOrganizationHandler,
+	NewCreditStatementHandler,
+	NewUsageExportHandler,
 
 	// Admin handlers
 	admin.NewDashboardHandler,
@@ -274,6 +341,11 @@ var ProviderSet = wire.NewSet(
 	admin.NewAffiliateHandler,
 	admin.NewComplianceHandler,
 	admin.NewAuditLogHandler,
+	admin.NewOrganizationHandler,
+	admin.NewBalanceLedgerHandler,
+	admin.NewCreditStatementHandler,
+	admin.NewSpendAnomalyHandler,
+	admin.NewUsageExportHandler,
 
 	// AdminHandlers and Handlers constructors
 	ProvideAdminHandlers,
@@ -0,0 +1,719 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/payment"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	paypalSandboxAPIBase     = "https://api-m.sandbox.paypal.com"
+	paypalLiveAPIBase        = "https://api-m.paypal.com"
+	paypalHTTPTimeout        = 15 * time.Second
+	paypalMaxResponseSize    = 1 << 20
+	paypalMaxErrorSummary    = 512
+	paypalTokenSkew          = 2 * time.Minute
+	paypalMaxDescriptionLen  = 127
+	paypalMaxNoteToPayerLen  = 255
+	paypalMaxBrandNameLength = 127
+
+	paypalEventOrderApproved    = "CHECKOUT.ORDER.APPROVED"
+	paypalEventCaptureCompleted = "PAYMENT.CAPTURE.COMPLETED"
+
+	paypalOrderStatusApproved  = "APPROVED"
+	paypalOrderStatusCompleted = "COMPLETED"
+	paypalOrderStatusVoided    = "VOIDED"
+
+	paypalCaptureStatusCompleted = "COMPLETED"
+	paypalCaptureStatusDeclined  = "DECLINED"
+	paypalCaptureStatusFailed    = "FAILED"
+
+	paypalRefundStatusCompleted = "COMPLETED"
+	paypalRefundStatusPending   = "PENDING"
+	paypalRefundStatusFailed    = "FAILED"
+	paypalRefundStatusCancelled = "CANCELLED"
+
+	paypalWebhookVerificationSuccess = "SUCCESS"
+)
+
+// paypalSupportedCurrencies 是 PayPal Orders API 支持的结算币种。
+var paypalSupportedCurrencies = map[string]struct{}{
+	"AUD": {}, "BRL": {}, "CAD": {}, "CHF": {}, "CNY": {}, "CZK": {}, "DKK": {}, "EUR": {},
+	"GBP": {}, "HKD": {}, "HUF": {}, "ILS": {}, "JPY": {}, "MXN": {}, "MYR": {}, "NOK": {},
+	"NZD": {}, "PHP": {}, "PLN": {}, "SEK": {}, "SGD": {}, "THB": {}, "TWD": {}, "USD": {},
+}
+
+// PayPal 通过 Orders v2 API 收款：创建 CAPTURE 意图订单并跳转 PayPal 收银台，
+// 买家批准后由 Webhook 或主动查询触发扣款。
+type PayPal struct {
+	instanceID string
+	config     map[string]string
+	httpClient *http.Client
+}
+
+type paypalTokenState struct {
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+var paypalAccessTokens sync.Map
+
+func NewPayPal(instanceID string, config map[string]string) (*PayPal, error) {
+	for _, k := range []string{"clientId", "clientSecret", "webhookId", "apiBase"} {
+		if strings.TrimSpace(config[k]) == "" {
+			return nil, fmt.Errorf("paypal config missing required key: %s", k)
+		}
+	}
+	cfg := cloneStringMap(config)
+	apiBase, err := normalizePayPalAPIBase(cfg["apiBase"])
+	if err != nil {
+		return nil, err
+	}
+	cfg["apiBase"] = apiBase
+	currency, err := payment.NormalizePaymentCurrency(cfg["currency"])
+	if err != nil {
+		return nil, fmt.Errorf("paypal config currency: %w", err)
+	}
+	if _, ok := paypalSupportedCurrencies[currency]; !ok {
+		return nil, fmt.Errorf("paypal config currency %s is not supported by PayPal", currency)
+	}
+	cfg["currency"] = currency
+	if len([]rune(strings.TrimSpace(cfg["brandName"]))) > paypalMaxBrandNameLength {
+		return nil, fmt.Errorf("paypal config brandName must be at most %d characters", paypalMaxBrandNameLength)
+	}
+	return &PayPal{
+		instanceID: instanceID,
+		config:     cfg,
+		httpClient: &http.Client{Timeout: paypalHTTPTimeout},
+	}, nil
+}
+
+func normalizePayPalAPIBase(raw string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return "", fmt.Errorf("paypal apiBase must be an HTTPS URL")
+	}
+	if strings.Trim(parsed.Path, "/") != "" || parsed.RawQuery != "" || parsed.Fragment != "" {
+		return "", fmt.Errorf("paypal apiBase must not contain a path or query")
+	}
+	base := "https://" + strings.ToLower(parsed.Host)
+	if base != paypalSandboxAPIBase && base != paypalLiveAPIBase {
+		return "", fmt.Errorf("paypal apiBase must be %s or %s", paypalSandboxAPIBase, paypalLiveAPIBase)
+	}
+	return base, nil
+}
+
+func (p *PayPal) Name() string        { return "PayPal" }
+func (p *PayPal) ProviderKey() string { return payment.TypePayPal }
+func (p *PayPal) SupportedTypes() []payment.PaymentType {
+	return []payment.PaymentType{payment.TypePayPal}
+}
+
+func (p *PayPal) MerchantIdentityMetadata() map[string]string {
+	if p == nil {
+		return nil
+	}
+	return map[string]string{"currency": p.currency()}
+}
+
+func (p *PayPal) currency() string {
+	if p == nil {
+		return payment.DefaultPaymentCurrency
+	}
+	currency, err := payment.NormalizePaymentCurrency(p.config["currency"])
+	if err != nil {
+		return payment.DefaultPaymentCurrency
+	}
+	return currency
+}
+
+func (p *PayPal) CreatePayment(ctx context.Context, req payment.CreatePaymentRequest) (*payment.CreatePaymentResponse, error) {
+	amount, err := decimal.NewFromString(req.Amount)
+	if err != nil || amount.LessThanOrEqual(decimal.Zero) {
+		return nil, fmt.Errorf("paypal create payment: invalid amount %s", req.Amount)
+	}
+	token, err := p.accessToken(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("paypal auth: %w", err)
+	}
+
+	currency := p.currency()
+	payload := paypalCreateOrderRequest{
+		Intent: "CAPTURE",
+		PurchaseUnits: []paypalPurchaseUnitRequest{{
+			ReferenceID: req.OrderID,
+			CustomID:    req.OrderID,
+			Description: truncatePayPalText(strings.TrimSpace(req.Subject), paypalMaxDescriptionLen),
+			Amount:      paypalMoney{CurrencyCode: currency, Value: req.Amount},
+		}},
+	}
+	experience := paypalExperienceContext{
+		UserAction:         "PAY_NOW",
+		ShippingPreference: "NO_SHIPPING",
+		BrandName:          strings.TrimSpace(p.config["brandName"]),
+	}
+	if returnURL := strings.TrimSpace(req.ReturnURL); returnURL != "" {
+		experience.ReturnURL = returnURL
+		experience.CancelURL = returnURL
+	}
+	payload.PaymentSource.PayPal.ExperienceContext = experience
+
+	var order paypalOrder
+	requestID := paypalRequestID("order", req.OrderID, req.Amount, currency)
+	if err := p.doJSON(ctx, http.MethodPost, "/v2/checkout/orders", token, requestID, payload, &order); err != nil {
+		return nil, fmt.Errorf("paypal create payment: %w", err)
+	}
+	payURL := order.link("payer-action")
+	if payURL == "" {
+		payURL = order.link("approve")
+	}
+	if strings.TrimSpace(order.ID) == "" || payURL == "" {
+		return nil, fmt.Errorf("paypal create payment: missing order id or approval link")
+	}
+	return &payment.CreatePaymentResponse{
+		TradeNo:  order.ID,
+		PayURL:   payURL,
+		Currency: currency,
+	}, nil
+}
+
+// QueryOrder 查询 PayPal 订单；买家已批准但尚未扣款的订单会在此扣款，
+// 以便 Webhook 丢失时轮询与取消前检查仍能完成支付。
+func (p *PayPal) QueryOrder(ctx context.Context, tradeNo string) (*payment.QueryOrderResponse, error) {
+	orderID := strings.TrimSpace(tradeNo)
+	if orderID == "" {
+		return nil, fmt.Errorf("paypal query order: missing order id")
+	}
+	token, err := p.accessToken(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("paypal auth: %w", err)
+	}
+	order, err := p.getOrder(ctx, token, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("paypal query order: %w", err)
+	}
+	if strings.ToUpper(order.Status) == paypalOrderStatusApproved {
+		if order, err = p.captureOrder(ctx, token, orderID); err != nil {
+			return nil, fmt.Errorf("paypal capture order: %w", err)
+		}
+	}
+	return &payment.QueryOrderResponse{
+		TradeNo:  order.ID,
+		Status:   paypalOrderProviderStatus(order),
+		Amount:   order.paidAmount(),
+		Metadata: order.metadata(),
+	}, nil
+}
+
+func (p *PayPal) VerifyNotification(ctx context.Context, rawBody string, headers map[string]string) (*payment.PaymentNotification, error) {
+	token, err := p.accessToken(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("paypal auth: %w", err)
+	}
+	if err := p.verifyWebhookSignature(ctx, token, rawBody, headers); err != nil {
+		return nil, err
+	}
+
+	var event paypalWebhookEvent
+	if err := json.Unmarshal([]byte(rawBody), &event); err != nil {
+		return nil, fmt.Errorf("paypal parse webhook: %w", err)
+	}
+	switch event.EventType {
+	case paypalEventOrderApproved:
+		var approved paypalOrder
+		if err := json.Unmarshal(event.Resource, &approved); err != nil {
+			return nil, fmt.Errorf("paypal parse order: %w", err)
+		}
+		if strings.TrimSpace(approved.ID) == "" {
+			return nil, fmt.Errorf("paypal webhook missing order id")
+		}
+		order, err := p.captureOrder(ctx, token, approved.ID)
+		if err != nil {
+			return nil, fmt.Errorf("paypal capture order: %w", err)
+		}
+		if paypalOrderProviderStatus(order) != payment.ProviderStatusPaid {
+			// 扣款处于 PENDING 等状态时等待 PAYMENT.CAPTURE.COMPLETED。
+			return nil, nil
+		}
+		return &payment.PaymentNotification{
+			TradeNo:  order.ID,
+			OrderID:  order.customID(),
+			Amount:   order.paidAmount(),
+			Status:   payment.NotificationStatusSuccess,
+			RawData:  rawBody,
+			Metadata: order.metadata(),
+		}, nil
+	case paypalEventCaptureCompleted:
+		var capture paypalCapture
+		if err := json.Unmarshal(event.Resource, &capture); err != nil {
+			return nil, fmt.Errorf("paypal parse capture: %w", err)
+		}
+		orderID := strings.TrimSpace(capture.SupplementaryData.RelatedIDs.OrderID)
+		if orderID == "" || strings.TrimSpace(capture.CustomID) == "" {
+			return nil, fmt.Errorf("paypal webhook missing order id or custom_id")
+		}
+		if strings.ToUpper(capture.Status) != paypalCaptureStatusCompleted {
+			return nil, fmt.Errorf("paypal capture completed webhook has status %s", capture.Status)
+		}
+		return &payment.PaymentNotification{
+			TradeNo: orderID,
+			OrderID: capture.CustomID,
+			Amount:  capture.Amount.float(),
+			Status:  payment.NotificationStatusSuccess,
+			RawData: rawBody,
+			Metadata: map[string]string{
+				"currency": strings.ToUpper(strings.TrimSpace(capture.Amount.CurrencyCode)),
+				"status":   paypalOrderStatusCompleted,
+			},
+		}, nil
+	default:
+		return nil, nil
+	}
+}
+
+func (p *PayPal) Refund(ctx context.Context, req payment.RefundRequest) (*payment.RefundResponse, error) {
+	orderID := strings.TrimSpace(req.TradeNo)
+	if orderID == "" {
+		return nil, fmt.Errorf("paypal refund missing order id")
+	}
+	amount, err := decimal.NewFromString(req.Amount)
+	if err != nil || amount.LessThanOrEqual(decimal.Zero) {
+		return nil, fmt.Errorf("paypal refund: invalid amount %s", req.Amount)
+	}
+	token, err := p.accessToken(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("paypal auth: %w", err)
+	}
+	order, err := p.getOrder(ctx, token, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("paypal refund: %w", err)
+	}
+	capture := order.capture()
+	if capture == nil || strings.TrimSpace(capture.ID) == "" {
+		return nil, fmt.Errorf("paypal refund: order %s has no capture", orderID)
+	}
+
+	payload := paypalRefundRequest{
+		Amount:      paypalMoney{CurrencyCode: capture.Amount.CurrencyCode, Value: req.Amount},
+		NoteToPayer: truncatePayPalText(strings.TrimSpace(req.Reason), paypalMaxNoteToPayerLen),
+	}
+	var refund paypalRefund
+	requestID := paypalRequestID("refund", capture.ID, req.Amount)
+	if err := p.doJSON(ctx, http.MethodPost, "/v2/payments/captures/"+url.PathEscape(capture.ID)+"/refund", token, requestID, payload, &refund); err != nil {
+		return nil, fmt.Errorf("paypal refund: %w", err)
+	}
+	if strings.TrimSpace(refund.ID) == "" {
+		return nil, fmt.Errorf("paypal refund: missing refund id")
+	}
+	return &payment.RefundResponse{RefundID: refund.ID, Status: paypalRefundProviderStatus(refund.Status)}, nil
+}
+
+func (p *PayPal) QueryRefund(ctx context.Context, req payment.RefundQueryRequest) (*payment.RefundResponse, error) {
+	refundID := strings.TrimSpace(req.RefundID)
+	if refundID == "" {
+		return nil, fmt.Errorf("paypal query refund: missing refund id")
+	}
+	token, err := p.accessToken(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("paypal auth: %w", err)
+	}
+	var refund paypalRefund
+	if err := p.doJSON(ctx, http.MethodGet, "/v2/payments/refunds/"+url.PathEscape(refundID), token, "", nil, &refund); err != nil {
+		return nil, fmt.Errorf("paypal query refund: %w", err)
+	}
+	if strings.TrimSpace(refund.ID) == "" {
+		refund.ID = refundID
+	}
+	return &payment.RefundResponse{RefundID: refund.ID, Status: paypalRefundProviderStatus(refund.Status)}, nil
+}
+
+// CancelPayment 无需调用 PayPal：订单以 CAPTURE 意图创建，买家批准后仍须商户扣款才会转移资金，
+// 未扣款的订单到期后由 PayPal 自动作废。服务层在取消前已通过 QueryOrder 为已批准的订单扣款。
+func (p *PayPal) CancelPayment(_ context.Context, _ string) error {
+	return nil
+}
+
+func (p *PayPal) getOrder(ctx context.Context, token, orderID string) (paypalOrder, error) {
+	var order paypalOrder
+	err := p.doJSON(ctx, http.MethodGet, "/v2/checkout/orders/"+url.PathEscape(orderID), token, "", nil, &order)
+	return order, err
+}
+
+// captureOrder 对已批准的订单扣款。固定的 PayPal-Request-Id 使 Webhook 与轮询并发扣款时只会扣一次；
+// 订单已被扣款时返回当前订单状态。
+func (p *PayPal) captureOrder(ctx context.Context, token, orderID string) (paypalOrder, error) {
+	var order paypalOrder
+	err := p.doJSON(ctx, http.MethodPost, "/v2/checkout/orders/"+url.PathEscape(orderID)+"/capture", token, paypalRequestID("capture", orderID), struct{}{}, &order)
+	var httpErr *paypalHTTPError
+	if errors.As(err, &httpErr) && httpErr.Status == http.StatusUnprocessableEntity {
+		return p.getOrder(ctx, token, orderID)
+	}
+	return order, err
+}
+
+func (p *PayPal) verifyWebhookSignature(ctx context.Context, token, rawBody string, headers map[string]string) error {
+	webhookID := strings.TrimSpace(p.config["webhookId"])
+	if webhookID == "" {
+		return fmt.Errorf("paypal webhookId not configured")
+	}
+	payload := paypalVerifyWebhookRequest{
+		AuthAlgo:         strings.TrimSpace(headers["paypal-auth-algo"]),
+		CertURL:          strings.TrimSpace(headers["paypal-cert-url"]),
+		TransmissionID:   strings.TrimSpace(headers["paypal-transmission-id"]),
+		TransmissionSig:  strings.TrimSpace(headers["paypal-transmission-sig"]),
+		TransmissionTime: strings.TrimSpace(headers["paypal-transmission-time"]),
+		WebhookID:        webhookID,
+		WebhookEvent:     json.RawMessage(rawBody),
+	}
+	if payload.AuthAlgo == "" || payload.CertURL == "" || payload.TransmissionID == "" || payload.TransmissionSig == "" || payload.TransmissionTime == "" {
+		return fmt.Errorf("paypal notification missing transmission headers")
+	}
+	if !json.Valid([]byte(rawBody)) {
+		return fmt.Errorf("paypal notification body is not valid JSON")
+	}
+	var resp struct {
+		VerificationStatus string `json:"verification_status"`
+	}
+	if err := p.doJSON(ctx, http.MethodPost, "/v1/notifications/verify-webhook-signature", token, "", payload, &resp); err != nil {
+		return fmt.Errorf("paypal verify webhook signature: %w", err)
+	}
+	if strings.ToUpper(strings.TrimSpace(resp.VerificationStatus)) != paypalWebhookVerificationSuccess {
+		return fmt.Errorf("paypal invalid signature")
+	}
+	return nil
+}
+
+func (p *PayPal) accessToken(ctx context.Context) (string, error) {
+	sum := sha256.Sum256([]byte(p.config["clientSecret"]))
+	cacheKey := p.config["apiBase"] + "|" + p.config["clientId"] + "|" + hex.EncodeToString(sum[:8])
+	rawState, _ := paypalAccessTokens.LoadOrStore(cacheKey, &paypalTokenState{})
+	state, ok := rawState.(*paypalTokenState)
+	if !ok {
+		return "", fmt.Errorf("paypal auth token cache state type mismatch")
+	}
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	if state.token != "" && time.Now().Add(paypalTokenSkew).Before(state.expiresAt) {
+		return state.token, nil
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.config["apiBase"]+"/v1/oauth2/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(p.config["clientId"], p.config["clientSecret"])
+
+	body, status, err := p.do(req)
+	if err != nil {
+		return "", err
+	}
+	if status < http.StatusOK || status >= http.StatusMultipleChoices {
+		return "", fmt.Errorf("authentication HTTP %d: %s", status, summarizePayPalResponse(body))
+	}
+	var resp struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("parse authentication response: %w", err)
+	}
+	if strings.TrimSpace(resp.AccessToken) == "" {
+		return "", fmt.Errorf("authentication response missing access_token")
+	}
+	expiresIn := time.Duration(resp.ExpiresIn) * time.Second
+	if expiresIn <= 0 {
+		expiresIn = 30 * time.Minute
+	}
+	state.token = resp.AccessToken
+	state.expiresAt = time.Now().Add(expiresIn)
+	return state.token, nil
+}
+
+type paypalHTTPError struct {
+	Status  int
+	Summary string
+}
+
+func (e *paypalHTTPError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.Status, e.Summary)
+}
+
+func (p *PayPal) doJSON(ctx context.Context, method, path, token, requestID string, payload any, out any) error {
+	var bodyReader io.Reader
+	if payload != nil {
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		bodyReader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, p.config["apiBase"]+path, bodyReader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Prefer", "return=representation")
+	if requestID != "" {
+		req.Header.Set("PayPal-Request-Id", requestID)
+	}
+
+	body, status, err := p.do(req)
+	if err != nil {
+		return err
+	}
+	if status < http.StatusOK || status >= http.StatusMultipleChoices {
+		return &paypalHTTPError{Status: status, Summary: summarizePayPalResponse(body)}
+	}
+	if out == nil || len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("parse response: %w", err)
+	}
+	return nil
+}
+
+func (p *PayPal) do(req *http.Request) ([]byte, int, error) {
+	client := p.httpClient
+	if client == nil {
+		client = &http.Client{Timeout: paypalHTTPTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, paypalMaxResponseSize))
+	if err != nil {
+		return nil, resp.StatusCode, err
+	}
+	return body, resp.StatusCode, nil
+}
+
+func paypalOrderProviderStatus(order paypalOrder) string {
+	switch strings.ToUpper(strings.TrimSpace(order.Status)) {
+	case paypalOrderStatusCompleted:
+		capture := order.capture()
+		if capture == nil {
+			return payment.ProviderStatusPending
+		}
+		switch strings.ToUpper(strings.TrimSpace(capture.Status)) {
+		case paypalCaptureStatusCompleted:
+			return payment.ProviderStatusPaid
+		case paypalCaptureStatusDeclined, paypalCaptureStatusFailed:
+			return payment.ProviderStatusFailed
+		default:
+			return payment.ProviderStatusPending
+		}
+	case paypalOrderStatusVoided:
+		return payment.ProviderStatusFailed
+	default:
+		return payment.ProviderStatusPending
+	}
+}
+
+func paypalRefundProviderStatus(status string) string {
+	switch strings.ToUpper(strings.TrimSpace(status)) {
+	case paypalRefundStatusCompleted:
+		return payment.ProviderStatusSuccess
+	case paypalRefundStatusFailed, paypalRefundStatusCancelled:
+		return payment.ProviderStatusFailed
+	case paypalRefundStatusPending:
+		return payment.ProviderStatusPending
+	default:
+		return payment.ProviderStatusPending
+	}
+}
+
+// paypalRequestID 生成幂等键，同一业务操作重试时 PayPal 返回首次请求的结果。
+func paypalRequestID(parts ...string) string {
+	hash := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(hash[:16])
+}
+
+func summarizePayPalResponse(body []byte) string {
+	summary := strings.Join(strings.Fields(string(body)), " ")
+	if summary == "" {
+		return "<empty>"
+	}
+	if len(summary) > paypalMaxErrorSummary {
+		return summary[:paypalMaxErrorSummary] + "..."
+	}
+	return summary
+}
+
+func truncatePayPalText(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit])
+}
+
+type paypalMoney struct {
+	CurrencyCode string `json:"currency_code"`
+	Value        string `json:"value"`
+}
+
+func (m paypalMoney) float() float64 {
+	amount, err := decimal.NewFromString(strings.TrimSpace(m.Value))
+	if err != nil {
+		return 0
+	}
+	return amount.InexactFloat64()
+}
+
+type paypalCreateOrderRequest struct {
+	Intent        string                      `json:"intent"`
+	PurchaseUnits []paypalPurchaseUnitRequest `json:"purchase_units"`
+	PaymentSource struct {
+		PayPal struct {
+			ExperienceContext paypalExperienceContext `json:"experience_context"`
+		} `json:"paypal"`
+	} `json:"payment_source"`
+}
+
+type paypalPurchaseUnitRequest struct {
+	ReferenceID string      `json:"reference_id,omitempty"`
+	CustomID    string      `json:"custom_id,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Amount      paypalMoney `json:"amount"`
+}
+
+type paypalExperienceContext struct {
+	BrandName          string `json:"brand_name,omitempty"`
+	ShippingPreference string `json:"shipping_preference,omitempty"`
+	UserAction         string `json:"user_action,omitempty"`
+	ReturnURL          string `json:"return_url,omitempty"`
+	CancelURL          string `json:"cancel_url,omitempty"`
+}
+
+type paypalOrder struct {
+	ID            string `json:"id"`
+	Status        string `json:"status"`
+	PurchaseUnits []struct {
+		ReferenceID string      `json:"reference_id"`
+		CustomID    string      `json:"custom_id"`
+		Amount      paypalMoney `json:"amount"`
+		Payments    struct {
+			Captures []paypalCapture `json:"captures"`
+		} `json:"payments"`
+	} `json:"purchase_units"`
+	Links []struct {
+		Href string `json:"href"`
+		Rel  string `json:"rel"`
+	} `json:"links"`
+}
+
+func (o paypalOrder) link(rel string) string {
+	for _, link := range o.Links {
+		if strings.EqualFold(link.Rel, rel) {
+			return strings.TrimSpace(link.Href)
+		}
+	}
+	return ""
+}
+
+func (o paypalOrder) capture() *paypalCapture {
+	if len(o.PurchaseUnits) == 0 || len(o.PurchaseUnits[0].Payments.Captures) == 0 {
+		return nil
+	}
+	return &o.PurchaseUnits[0].Payments.Captures[0]
+}
+
+func (o paypalOrder) customID() string {
+	if len(o.PurchaseUnits) == 0 {
+		return ""
+	}
+	if customID := strings.TrimSpace(o.PurchaseUnits[0].CustomID); customID != "" {
+		return customID
+	}
+	return strings.TrimSpace(o.PurchaseUnits[0].ReferenceID)
+}
+
+// paidAmount 优先返回实际扣款金额，尚未扣款时返回订单金额。
+func (o paypalOrder) paidAmount() float64 {
+	if capture := o.capture(); capture != nil {
+		return capture.Amount.float()
+	}
+	if len(o.PurchaseUnits) == 0 {
+		return 0
+	}
+	return o.PurchaseUnits[0].Amount.float()
+}
+
+func (o paypalOrder) metadata() map[string]string {
+	currency := ""
+	if capture := o.capture(); capture != nil {
+		currency = capture.Amount.CurrencyCode
+	} else if len(o.PurchaseUnits) > 0 {
+		currency = o.PurchaseUnits[0].Amount.CurrencyCode
+	}
+	return map[string]string{
+		"currency": strings.ToUpper(strings.TrimSpace(currency)),
+		"status":   strings.ToUpper(strings.TrimSpace(o.Status)),
+	}
+}
+
+type paypalCapture struct {
+	ID                string      `json:"id"`
+	Status            string      `json:"status"`
+	CustomID          string      `json:"custom_id"`
+	Amount            paypalMoney `json:"amount"`
+	SupplementaryData struct {
+		RelatedIDs struct {
+			OrderID string `json:"order_id"`
+		} `json:"related_ids"`
+	} `json:"supplementary_data"`
+}
+
+type paypalRefundRequest struct {
+	Amount      paypalMoney `json:"amount"`
+	NoteToPayer string      `json:"note_to_payer,omitempty"`
+}
+
+type paypalRefund struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+type paypalVerifyWebhookRequest struct {
+	AuthAlgo         string          `json:"auth_algo"`
+	CertURL          string          `json:"cert_url"`
+	TransmissionID   string          `json:"transmission_id"`
+	TransmissionSig  string          `json:"transmission_sig"`
+	TransmissionTime string          `json:"transmission_time"`
+	WebhookID        string          `json:"webhook_id"`
+	WebhookEvent     json.RawMessage `json:"webhook_event"`
+}
+
+type paypalWebhookEvent struct {
+	ID        string          `json:"id"`
+	EventType string          `json:"event_type"`
+	Resource  json.RawMessage `json:"resource"`
+}
+
+var (
+	_ payment.Provider                 = (*PayPal)(nil)
+	_ payment.RefundQueryProvider      = (*PayPal)(nil)
+	_ payment.CancelableProvider       = (*PayPal)(nil)
+	_ payment.MerchantIdentityProvider = (*PayPal)(nil)
+)
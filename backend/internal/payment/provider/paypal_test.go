@@ -0,0 +1,338 @@
+//go:build unit
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/payment"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePayPal 是 PayPal REST API 的本地替身，只实现 provider 用到的端点。
+type fakePayPal struct {
+	t *testing.T
+
+	mu                 sync.Mutex
+	orderStatus        string
+	captureStatus      string
+	captureCalls       int
+	captureRequestIDs  []string
+	createRequest      paypalCreateOrderRequest
+	createRequestID    string
+	refundRequest      paypalRefundRequest
+	verifyRequest      paypalVerifyWebhookRequest
+	verificationStatus string
+}
+
+func newFakePayPal(t *testing.T) (*fakePayPal, *httptest.Server) {
+	f := &fakePayPal{t: t, orderStatus: "PAYER_ACTION_REQUIRED", captureStatus: "COMPLETED", verificationStatus: "SUCCESS"}
+	server := httptest.NewTLSServer(http.HandlerFunc(f.serve))
+	t.Cleanup(server.Close)
+	return f, server
+}
+
+func (f *fakePayPal) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.URL.Path == "/v1/oauth2/token" {
+		user, pass, ok := r.BasicAuth()
+		require.True(f.t, ok)
+		require.Equal(f.t, "cid", user)
+		require.Equal(f.t, "csecret", pass)
+		require.NoError(f.t, r.ParseForm())
+		require.Equal(f.t, "client_credentials", r.PostForm.Get("grant_type"))
+		_, _ = w.Write([]byte(`{"access_token":"token-1","token_type":"Bearer","expires_in":32400}`))
+		return
+	}
+	require.Equal(f.t, "Bearer token-1", r.Header.Get("Authorization"))
+	body, err := io.ReadAll(r.Body)
+	require.NoError(f.t, err)
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/v2/checkout/orders":
+		require.NoError(f.t, json.Unmarshal(body, &f.createRequest))
+		f.createRequestID = r.Header.Get("PayPal-Request-Id")
+		_, _ = w.Write([]byte(`{"id":"5O190127TN364715T","status":"PAYER_ACTION_REQUIRED","links":[{"href":"https://www.sandbox.paypal.com/checkoutnow?token=5O190127TN364715T","rel":"payer-action","method":"GET"}]}`))
+	case r.Method == http.MethodGet && r.URL.Path == "/v2/checkout/orders/5O190127TN364715T":
+		_, _ = w.Write([]byte(f.orderJSON()))
+	case r.Method == http.MethodPost && r.URL.Path == "/v2/checkout/orders/5O190127TN364715T/capture":
+		f.captureCalls++
+		f.captureRequestIDs = append(f.captureRequestIDs, r.Header.Get("PayPal-Request-Id"))
+		if f.orderStatus == "COMPLETED" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"name":"UNPROCESSABLE_ENTITY","details":[{"issue":"ORDER_ALREADY_CAPTURED"}]}`))
+			return
+		}
+		f.orderStatus = "COMPLETED"
+		_, _ = w.Write([]byte(f.orderJSON()))
+	case r.Method == http.MethodPost && r.URL.Path == "/v2/payments/captures/3C679366HH908993F/refund":
+		require.NotEmpty(f.t, r.Header.Get("PayPal-Request-Id"))
+		require.NoError(f.t, json.Unmarshal(body, &f.refundRequest))
+		_, _ = w.Write([]byte(`{"id":"1JU08902781691411","status":"PENDING"}`))
+	case r.Method == http.MethodGet && r.URL.Path == "/v2/payments/refunds/1JU08902781691411":
+		_, _ = w.Write([]byte(`{"id":"1JU08902781691411","status":"COMPLETED"}`))
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/notifications/verify-webhook-signature":
+		require.NoError(f.t, json.Unmarshal(body, &f.verifyRequest))
+		_, _ = w.Write([]byte(`{"verification_status":"` + f.verificationStatus + `"}`))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakePayPal) orderJSON() string {
+	captures := ""
+	if f.orderStatus == "COMPLETED" {
+		captures = `,"payments":{"captures":[{"id":"3C679366HH908993F","status":"` + f.captureStatus + `","custom_id":"sub2_order","amount":{"currency_code":"USD","value":"12.34"}}]}`
+	}
+	return `{"id":"5O190127TN364715T","status":"` + f.orderStatus + `","purchase_units":[{"reference_id":"sub2_order","custom_id":"sub2_order","amount":{"currency_code":"USD","value":"12.34"}` + captures + `}]}`
+}
+
+func mustTestPayPalProvider(t *testing.T, server *httptest.Server) *PayPal {
+	t.Helper()
+	prov, err := NewPayPal("1", map[string]string{
+		"clientId":     "cid",
+		"clientSecret": "csecret",
+		"webhookId":    "WH-1",
+		"apiBase":      paypalSandboxAPIBase,
+		"currency":     "usd",
+	})
+	require.NoError(t, err)
+	prov.config["apiBase"] = server.URL
+	prov.httpClient = server.Client()
+	return prov
+}
+
+func paypalWebhookHeaders() map[string]string {
+	return map[string]string{
+		"paypal-auth-algo":         "SHA256withRSA",
+		"paypal-cert-url":          "https://api.sandbox.paypal.com/v1/notifications/certs/CERT-360caa42",
+		"paypal-transmission-id":   "69cd13f0-d67a-11e5-baa3-778b53f4ae55",
+		"paypal-transmission-sig":  "sig",
+		"paypal-transmission-time": "2026-10-16T04:14:05Z",
+	}
+}
+
+func TestNewPayPalValidatesConfig(t *testing.T) {
+	t.Parallel()
+
+	base := func() map[string]string {
+		return map[string]string{"clientId": "cid", "clientSecret": "csecret", "webhookId": "WH-1", "apiBase": paypalLiveAPIBase}
+	}
+
+	cfg := base()
+	delete(cfg, "webhookId")
+	_, err := NewPayPal("1", cfg)
+	require.ErrorContains(t, err, "webhookId")
+
+	cfg = base()
+	cfg["apiBase"] = "https://evil.example.com"
+	_, err = NewPayPal("1", cfg)
+	require.ErrorContains(t, err, "apiBase")
+
+	cfg = base()
+	cfg["apiBase"] = paypalLiveAPIBase + "/v2"
+	_, err = NewPayPal("1", cfg)
+	require.ErrorContains(t, err, "apiBase")
+
+	cfg = base()
+	cfg["currency"] = "KRW"
+	_, err = NewPayPal("1", cfg)
+	require.ErrorContains(t, err, "not supported")
+
+	cfg = base()
+	cfg["apiBase"] = "https://API-M.PAYPAL.COM/"
+	cfg["currency"] = "eur"
+	prov, err := NewPayPal("1", cfg)
+	require.NoError(t, err)
+	require.Equal(t, payment.TypePayPal, prov.ProviderKey())
+	require.Equal(t, []payment.PaymentType{payment.TypePayPal}, prov.SupportedTypes())
+	require.Equal(t, paypalLiveAPIBase, prov.config["apiBase"])
+	require.Equal(t, map[string]string{"currency": "EUR"}, prov.MerchantIdentityMetadata())
+}
+
+func TestCreateProviderPayPal(t *testing.T) {
+	t.Parallel()
+
+	prov, err := CreateProvider(payment.TypePayPal, "1", map[string]string{
+		"clientId": "cid", "clientSecret": "csecret", "webhookId": "WH-1", "apiBase": paypalSandboxAPIBase,
+	})
+	require.NoError(t, err)
+	require.IsType(t, &PayPal{}, prov)
+}
+
+func TestPayPalCreatePayment(t *testing.T) {
+	t.Parallel()
+
+	fake, server := newFakePayPal(t)
+	prov := mustTestPayPalProvider(t, server)
+	prov.config["brandName"] = "Sub2API"
+
+	resp, err := prov.CreatePayment(context.Background(), payment.CreatePaymentRequest{
+		OrderID:   "sub2_order",
+		Amount:    "12.34",
+		Subject:   strings.Repeat("x", 200),
+		ReturnURL: "https://merchant.example.com/payment/result",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "5O190127TN364715T", resp.TradeNo)
+	require.Equal(t, "https://www.sandbox.paypal.com/checkoutnow?token=5O190127TN364715T", resp.PayURL)
+	require.Equal(t, "USD", resp.Currency)
+
+	require.Equal(t, "CAPTURE", fake.createRequest.Intent)
+	require.Len(t, fake.createRequest.PurchaseUnits, 1)
+	unit := fake.createRequest.PurchaseUnits[0]
+	require.Equal(t, "sub2_order", unit.CustomID)
+	require.Equal(t, paypalMoney{CurrencyCode: "USD", Value: "12.34"}, unit.Amount)
+	require.Len(t, unit.Description, paypalMaxDescriptionLen)
+	experience := fake.createRequest.PaymentSource.PayPal.ExperienceContext
+	require.Equal(t, "https://merchant.example.com/payment/result", experience.ReturnURL)
+	require.Equal(t, "Sub2API", experience.BrandName)
+	require.Equal(t, paypalRequestID("order", "sub2_order", "12.34", "USD"), fake.createRequestID)
+}
+
+func TestPayPalQueryOrderCapturesApprovedOrder(t *testing.T) {
+	t.Parallel()
+
+	fake, server := newFakePayPal(t)
+	prov := mustTestPayPalProvider(t, server)
+
+	resp, err := prov.QueryOrder(context.Background(), "5O190127TN364715T")
+	require.NoError(t, err)
+	require.Equal(t, payment.ProviderStatusPending, resp.Status)
+	require.Equal(t, 0, fake.captureCalls)
+
+	fake.orderStatus = "APPROVED"
+	resp, err = prov.QueryOrder(context.Background(), "5O190127TN364715T")
+	require.NoError(t, err)
+	require.Equal(t, payment.ProviderStatusPaid, resp.Status)
+	require.Equal(t, "5O190127TN364715T", resp.TradeNo)
+	require.InDelta(t, 12.34, resp.Amount, 1e-9)
+	require.Equal(t, "USD", resp.Metadata["currency"])
+	require.Equal(t, 1, fake.captureCalls)
+	require.Equal(t, paypalRequestID("capture", "5O190127TN364715T"), fake.captureRequestIDs[0])
+
+	// 已扣款订单不再重复扣款。
+	resp, err = prov.QueryOrder(context.Background(), "5O190127TN364715T")
+	require.NoError(t, err)
+	require.Equal(t, payment.ProviderStatusPaid, resp.Status)
+	require.Equal(t, 1, fake.captureCalls)
+}
+
+func TestPayPalQueryOrderDeclinedCapture(t *testing.T) {
+	t.Parallel()
+
+	fake, server := newFakePayPal(t)
+	fake.orderStatus = "COMPLETED"
+	fake.captureStatus = "DECLINED"
+	prov := mustTestPayPalProvider(t, server)
+
+	resp, err := prov.QueryOrder(context.Background(), "5O190127TN364715T")
+	require.NoError(t, err)
+	require.Equal(t, payment.ProviderStatusFailed, resp.Status)
+}
+
+func TestPayPalVerifyNotificationOrderApproved(t *testing.T) {
+	t.Parallel()
+
+	fake, server := newFakePayPal(t)
+	fake.orderStatus = "APPROVED"
+	prov := mustTestPayPalProvider(t, server)
+
+	rawBody := `{"id":"WH-EVT-1","event_type":"CHECKOUT.ORDER.APPROVED","resource":{"id":"5O190127TN364715T","status":"APPROVED","purchase_units":[{"custom_id":"sub2_order"}]}}`
+	n, err := prov.VerifyNotification(context.Background(), rawBody, paypalWebhookHeaders())
+	require.NoError(t, err)
+	require.NotNil(t, n)
+	require.Equal(t, "5O190127TN364715T", n.TradeNo)
+	require.Equal(t, "sub2_order", n.OrderID)
+	require.InDelta(t, 12.34, n.Amount, 1e-9)
+	require.Equal(t, payment.NotificationStatusSuccess, n.Status)
+	require.Equal(t, rawBody, n.RawData)
+	require.Equal(t, 1, fake.captureCalls)
+
+	require.Equal(t, "WH-1", fake.verifyRequest.WebhookID)
+	require.Equal(t, "SHA256withRSA", fake.verifyRequest.AuthAlgo)
+	require.Equal(t, "sig", fake.verifyRequest.TransmissionSig)
+	require.JSONEq(t, rawBody, string(fake.verifyRequest.WebhookEvent))
+}
+
+func TestPayPalVerifyNotificationCaptureCompleted(t *testing.T) {
+	t.Parallel()
+
+	_, server := newFakePayPal(t)
+	prov := mustTestPayPalProvider(t, server)
+
+	rawBody := `{"id":"WH-EVT-2","event_type":"PAYMENT.CAPTURE.COMPLETED","resource":{"id":"3C679366HH908993F","status":"COMPLETED","custom_id":"sub2_order","amount":{"currency_code":"USD","value":"12.34"},"supplementary_data":{"related_ids":{"order_id":"5O190127TN364715T"}}}}`
+	n, err := prov.VerifyNotification(context.Background(), rawBody, paypalWebhookHeaders())
+	require.NoError(t, err)
+	require.NotNil(t, n)
+	require.Equal(t, "5O190127TN364715T", n.TradeNo)
+	require.Equal(t, "sub2_order", n.OrderID)
+	require.InDelta(t, 12.34, n.Amount, 1e-9)
+	require.Equal(t, "USD", n.Metadata["currency"])
+
+	n, err = prov.VerifyNotification(context.Background(), `{"id":"WH-EVT-3","event_type":"PAYMENT.CAPTURE.REFUNDED","resource":{}}`, paypalWebhookHeaders())
+	require.NoError(t, err)
+	require.Nil(t, n)
+}
+
+func TestPayPalVerifyNotificationRejectsBadSignature(t *testing.T) {
+	t.Parallel()
+
+	fake, server := newFakePayPal(t)
+	fake.verificationStatus = "FAILURE"
+	prov := mustTestPayPalProvider(t, server)
+
+	rawBody := `{"id":"WH-EVT-2","event_type":"PAYMENT.CAPTURE.COMPLETED","resource":{}}`
+	_, err := prov.VerifyNotification(context.Background(), rawBody, paypalWebhookHeaders())
+	require.ErrorContains(t, err, "invalid signature")
+
+	headers := paypalWebhookHeaders()
+	delete(headers, "paypal-transmission-sig")
+	_, err = prov.VerifyNotification(context.Background(), rawBody, headers)
+	require.ErrorContains(t, err, "missing transmission headers")
+}
+
+func TestPayPalRefundAndQueryRefund(t *testing.T) {
+	t.Parallel()
+
+	fake, server := newFakePayPal(t)
+	fake.orderStatus = "COMPLETED"
+	prov := mustTestPayPalProvider(t, server)
+
+	resp, err := prov.Refund(context.Background(), payment.RefundRequest{
+		TradeNo: "5O190127TN364715T",
+		OrderID: "sub2_order",
+		Amount:  "5.00",
+		Reason:  "duplicate",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "1JU08902781691411", resp.RefundID)
+	require.Equal(t, payment.ProviderStatusPending, resp.Status)
+	require.Equal(t, paypalMoney{CurrencyCode: "USD", Value: "5.00"}, fake.refundRequest.Amount)
+	require.Equal(t, "duplicate", fake.refundRequest.NoteToPayer)
+
+	resp, err = prov.QueryRefund(context.Background(), payment.RefundQueryRequest{RefundID: "1JU08902781691411"})
+	require.NoError(t, err)
+	require.Equal(t, payment.ProviderStatusSuccess, resp.Status)
+
+	require.NoError(t, prov.CancelPayment(context.Background(), "5O190127TN364715T"))
+}
+
+func TestPayPalRefundRequiresCapture(t *testing.T) {
+	t.Parallel()
+
+	_, server := newFakePayPal(t)
+	prov := mustTestPayPalProvider(t, server)
+
+	_, err := prov.Refund(context.Background(), payment.RefundRequest{TradeNo: "5O190127TN364715T", Amount: "5.00"})
+	require.ErrorContains(t, err, "no capture")
+}
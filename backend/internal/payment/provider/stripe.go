@@ -3,6 +3,7 @@ package provider
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
@@ -18,6 +19,9 @@ const (
 	stripeEventPaymentFailed  = "payment_intent.payment_failed"
 )
 
+// stripeSetupFutureUsageOffSession 让卡支付在首单确认后保存为可离线扣款的支付方式。
+const stripeSetupFutureUsageOffSession = "off_session"
+
 // Stripe implements the payment.CancelableProvider interface for Stripe payments.
 type Stripe struct {
 	instanceID string
@@ -126,6 +130,21 @@ func (s *Stripe) CreatePayment(ctx context.Context, req payment.CreatePaymentReq
 		}
 	}
 
+	// 自动续费首单：挂到 Customer 上并只对卡保存支付方式（钱包类方式不支持离线扣款）。
+	if req.SaveForRecurring && hasStripeMethod(methods, "card") {
+		customerID, err := s.createCustomer(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("stripe create payment: %w", err)
+		}
+		params.Customer = stripe.String(customerID)
+		if params.PaymentMethodOptions == nil {
+			params.PaymentMethodOptions = &stripe.PaymentIntentCreatePaymentMethodOptionsParams{}
+		}
+		params.PaymentMethodOptions.Card = &stripe.PaymentIntentCreatePaymentMethodOptionsCardParams{
+			SetupFutureUsage: stripe.String(stripeSetupFutureUsageOffSession),
+		}
+	}
+
 	params.SetIdempotencyKey(fmt.Sprintf("pi-%s", req.OrderID))
 	params.Context = ctx
 
@@ -141,6 +160,122 @@ func (s *Stripe) CreatePayment(ctx context.Context, req payment.CreatePaymentReq
 	}, nil
 }
 
+func (s *Stripe) createCustomer(ctx context.Context, req payment.CreatePaymentRequest) (string, error) {
+	params := &stripe.CustomerCreateParams{
+		Metadata: map[string]string{"orderId": req.OrderID},
+	}
+	if email := strings.TrimSpace(req.CustomerEmail); email != "" {
+		params.Email = stripe.String(email)
+	}
+	if name := strings.TrimSpace(req.CustomerName); name != "" {
+		params.Name = stripe.String(name)
+	}
+	params.SetIdempotencyKey(fmt.Sprintf("cus-%s", req.OrderID))
+	params.Context = ctx
+
+	customer, err := s.sc.V1Customers.Create(ctx, params)
+	if err != nil {
+		return "", fmt.Errorf("create customer: %w", err)
+	}
+	return customer.ID, nil
+}
+
+// ChargeSavedPaymentMethod confirms an off-session PaymentIntent against a
+// card saved by an earlier SaveForRecurring payment. Card declines are
+// reported as a failed result rather than an error so callers can dun the user.
+func (s *Stripe) ChargeSavedPaymentMethod(ctx context.Context, req payment.RecurringChargeRequest) (*payment.RecurringChargeResponse, error) {
+	s.ensureInit()
+
+	if strings.TrimSpace(req.CustomerRef) == "" || strings.TrimSpace(req.PaymentMethodRef) == "" {
+		return nil, fmt.Errorf("stripe recurring charge: missing saved payment method")
+	}
+	currency := s.currency()
+	amountInMinorUnit, err := payment.AmountToMinorUnit(req.Amount, currency)
+	if err != nil {
+		return nil, fmt.Errorf("stripe recurring charge: %w", err)
+	}
+
+	params := &stripe.PaymentIntentCreateParams{
+		Amount:             stripe.Int64(amountInMinorUnit),
+		Currency:           stripe.String(strings.ToLower(currency)),
+		Customer:           stripe.String(req.CustomerRef),
+		PaymentMethod:      stripe.String(req.PaymentMethodRef),
+		PaymentMethodTypes: []*string{stripe.String("card")},
+		Description:        stripe.String(req.Subject),
+		Metadata:           map[string]string{"orderId": req.OrderID},
+		Confirm:            stripe.Bool(true),
+		OffSession:         stripe.Bool(true),
+	}
+	params.SetIdempotencyKey(fmt.Sprintf("pi-%s", req.OrderID))
+	params.Context = ctx
+
+	pi, err := s.sc.V1PaymentIntents.Create(ctx, params)
+	if err != nil {
+		var stripeErr *stripe.Error
+		if errors.As(err, &stripeErr) && stripeErr.Type == stripe.ErrorTypeCard {
+			resp := &payment.RecurringChargeResponse{
+				Status:        payment.ProviderStatusFailed,
+				Currency:      currency,
+				FailureReason: stripeErrorReason(stripeErr),
+			}
+			if stripeErr.PaymentIntent != nil {
+				resp.TradeNo = stripeErr.PaymentIntent.ID
+			}
+			return resp, nil
+		}
+		return nil, fmt.Errorf("stripe recurring charge: %w", err)
+	}
+	return stripeRecurringChargeResult(pi, currency), nil
+}
+
+func stripeRecurringChargeResult(pi *stripe.PaymentIntent, fallbackCurrency string) *payment.RecurringChargeResponse {
+	currency := stripeIntentCurrency(pi.Currency, fallbackCurrency)
+	resp := &payment.RecurringChargeResponse{
+		TradeNo:  pi.ID,
+		Amount:   payment.MinorUnitToAmount(pi.Amount, currency),
+		Currency: currency,
+	}
+	switch pi.Status {
+	case stripe.PaymentIntentStatusSucceeded:
+		resp.Status = payment.ProviderStatusPaid
+	case stripe.PaymentIntentStatusProcessing:
+		resp.Status = payment.ProviderStatusPending
+	case stripe.PaymentIntentStatusRequiresAction:
+		resp.Status = payment.ProviderStatusFailed
+		resp.FailureReason = "authentication_required"
+	default:
+		resp.Status = payment.ProviderStatusFailed
+		resp.FailureReason = stripeErrorReason(pi.LastPaymentError)
+	}
+	return resp
+}
+
+func stripeErrorReason(e *stripe.Error) string {
+	if e == nil {
+		return "payment_failed"
+	}
+	if e.DeclineCode != "" {
+		return string(e.DeclineCode)
+	}
+	if e.Code != "" {
+		return string(e.Code)
+	}
+	if msg := strings.TrimSpace(e.Msg); msg != "" {
+		return msg
+	}
+	return "payment_failed"
+}
+
+// stripeSavedPaymentMethodMetadata 把 PaymentIntent 上保存的 Customer / PaymentMethod 带回服务层。
+func stripeSavedPaymentMethodMetadata(pi *stripe.PaymentIntent, metadata map[string]string) map[string]string {
+	if pi == nil || pi.Customer == nil || pi.PaymentMethod == nil || pi.Customer.ID == "" || pi.PaymentMethod.ID == "" {
+		return metadata
+	}
+	metadata[payment.MetadataKeyCustomerRef] = pi.Customer.ID
+	metadata[payment.MetadataKeyPaymentMethodRef] = pi.PaymentMethod.ID
+	return metadata
+}
+
 // QueryOrder retrieves a PaymentIntent by ID.
 func (s *Stripe) QueryOrder(ctx context.Context, tradeNo string) (*payment.QueryOrderResponse, error) {
 	s.ensureInit()
@@ -163,9 +298,9 @@ func (s *Stripe) QueryOrder(ctx context.Context, tradeNo string) (*payment.Query
 		TradeNo: pi.ID,
 		Status:  status,
 		Amount:  payment.MinorUnitToAmount(pi.Amount, currency),
-		Metadata: map[string]string{
+		Metadata: stripeSavedPaymentMethodMetadata(pi, map[string]string{
 			"currency": currency,
-		},
+		}),
 	}, nil
 }
 
@@ -210,9 +345,9 @@ func parseStripePaymentIntent(event *stripe.Event, status string, rawBody string
 		Amount:  payment.MinorUnitToAmount(pi.Amount, currency),
 		Status:  status,
 		RawData: rawBody,
-		Metadata: map[string]string{
+		Metadata: stripeSavedPaymentMethodMetadata(&pi, map[string]string{
 			"currency": currency,
-		},
+		}),
 	}, nil
 }
 
@@ -349,5 +484,6 @@ func (s *Stripe) CancelPayment(ctx context.Context, tradeNo string) error {
 var (
 	_ payment.Provider                 = (*Stripe)(nil)
 	_ payment.CancelableProvider       = (*Stripe)(nil)
+	_ payment.RecurringProvider        = (*Stripe)(nil)
 	_ payment.MerchantIdentityProvider = (*Stripe)(nil)
 )
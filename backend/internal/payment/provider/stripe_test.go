@@ -68,3 +68,94 @@ func TestStripeRefundUsesStableAmountSpecificIdempotencyKey(t *testing.T) {
 	require.Equal(t, "re-sub2_order_456-1235", *backend.params[2].IdempotencyKey)
 	require.NotEqual(t, *backend.params[0].IdempotencyKey, *backend.params[2].IdempotencyKey)
 }
+
+type stripeRecurringBackend struct {
+	params []*stripe.PaymentIntentCreateParams
+	err    error
+}
+
+func (b *stripeRecurringBackend) Call(_ string, _ string, _ string, params stripe.ParamsContainer, v stripe.LastResponseSetter) error {
+	b.params = append(b.params, params.(*stripe.PaymentIntentCreateParams))
+	if b.err != nil {
+		return b.err
+	}
+	pi := v.(*stripe.PaymentIntent)
+	pi.ID = "pi_renew"
+	pi.Amount = 990
+	pi.Currency = "usd"
+	pi.Status = stripe.PaymentIntentStatusSucceeded
+	return nil
+}
+
+func (*stripeRecurringBackend) CallStreaming(string, string, string, stripe.ParamsContainer, stripe.StreamingLastResponseSetter) error {
+	return nil
+}
+
+func (*stripeRecurringBackend) CallRaw(string, string, string, []byte, *stripe.Params, stripe.LastResponseSetter) error {
+	return nil
+}
+
+func (*stripeRecurringBackend) CallMultipart(string, string, string, string, *bytes.Buffer, *stripe.Params, stripe.LastResponseSetter) error {
+	return nil
+}
+
+func (*stripeRecurringBackend) SetMaxNetworkRetries(int64) {}
+
+func TestStripeChargeSavedPaymentMethod(t *testing.T) {
+	backend := &stripeRecurringBackend{}
+	provider := &Stripe{
+		config:      map[string]string{"currency": "USD"},
+		initialized: true,
+		sc:          stripe.NewClient("sk_test", stripe.WithBackends(&stripe.Backends{API: backend})),
+	}
+	req := payment.RecurringChargeRequest{
+		OrderID:          "sub2_renew_1",
+		Amount:           "9.90",
+		Subject:          "Pro",
+		CustomerRef:      "cus_1",
+		PaymentMethodRef: "pm_1",
+	}
+
+	resp, err := provider.ChargeSavedPaymentMethod(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, payment.ProviderStatusPaid, resp.Status)
+	require.Equal(t, "pi_renew", resp.TradeNo)
+	require.InDelta(t, 9.90, resp.Amount, 0.001)
+	require.Equal(t, "USD", resp.Currency)
+
+	params := backend.params[0]
+	require.Equal(t, int64(990), *params.Amount)
+	require.Equal(t, "cus_1", *params.Customer)
+	require.Equal(t, "pm_1", *params.PaymentMethod)
+	require.True(t, *params.OffSession)
+	require.True(t, *params.Confirm)
+	require.Equal(t, "pi-sub2_renew_1", *params.IdempotencyKey)
+
+	// 卡被拒时返回失败结果而不是错误，便于上层发送催缴通知。
+	backend.err = &stripe.Error{
+		Type:          stripe.ErrorTypeCard,
+		Code:          stripe.ErrorCodeCardDeclined,
+		DeclineCode:   stripe.DeclineCodeInsufficientFunds,
+		PaymentIntent: &stripe.PaymentIntent{ID: "pi_declined"},
+	}
+	resp, err = provider.ChargeSavedPaymentMethod(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, payment.ProviderStatusFailed, resp.Status)
+	require.Equal(t, "pi_declined", resp.TradeNo)
+	require.Equal(t, "insufficient_funds", resp.FailureReason)
+
+	_, err = provider.ChargeSavedPaymentMethod(context.Background(), payment.RecurringChargeRequest{OrderID: "x", Amount: "1"})
+	require.Error(t, err)
+}
+
+func TestStripeSavedPaymentMethodMetadata(t *testing.T) {
+	md := stripeSavedPaymentMethodMetadata(&stripe.PaymentIntent{
+		Customer:      &stripe.Customer{ID: "cus_1"},
+		PaymentMethod: &stripe.PaymentMethod{ID: "pm_1"},
+	}, map[string]string{"currency": "USD"})
+	require.Equal(t, "cus_1", md[payment.MetadataKeyCustomerRef])
+	require.Equal(t, "pm_1", md[payment.MetadataKeyPaymentMethodRef])
+
+	md = stripeSavedPaymentMethodMetadata(&stripe.PaymentIntent{}, map[string]string{"currency": "USD"})
+	require.NotContains(t, md, payment.MetadataKeyCustomerRef)
+}
@@ -18,6 +18,7 @@ const (
 	TypeLink         PaymentType = "link"
 	TypeEasyPay      PaymentType = "easypay"
 	TypeAirwallex    PaymentType = "airwallex"
+	TypePayPal       PaymentType = "paypal"
 )
 
 // Order status constants shared across payment and service layers.
@@ -86,6 +87,8 @@ func GetBasePaymentType(t string) string {
 		return TypeEasyPay
 	case t == TypeAirwallex:
 		return TypeAirwallex
+	case t == TypePayPal:
+		return TypePayPal
 	case t == TypeStripe || t == TypeCard || t == TypeLink:
 		return TypeStripe
 	case len(t) >= len(TypeAlipay) && t[:len(TypeAlipay)] == TypeAlipay:
@@ -112,6 +115,11 @@ type CreatePaymentRequest struct {
 	// alipay.trade.precreate instead of alipay.trade.wap.pay.
 	AlipayMobilePrecreate bool
 	InstanceSubMethods    string // Comma-separated sub-methods from instance supported_types (for Stripe)
+	// SaveForRecurring 要求服务商保存本次使用的支付方式，供自动续费离线扣款。
+	// 仅对实现 RecurringProvider 的服务商生效。
+	SaveForRecurring bool
+	CustomerEmail    string // 保存支付方式时用于创建服务商客户档案
+	CustomerName     string
 }
 
 // CreatePaymentResultType describes the shape of the create-payment result.
@@ -177,6 +185,31 @@ type PaymentNotification struct {
 	Metadata map[string]string
 }
 
+// Notification/query metadata keys carrying a reusable payment method saved by
+// the provider (see CreatePaymentRequest.SaveForRecurring).
+const (
+	MetadataKeyCustomerRef      = "customer_ref"
+	MetadataKeyPaymentMethodRef = "payment_method_ref"
+)
+
+// RecurringChargeRequest describes an off-session charge against a saved payment method.
+type RecurringChargeRequest struct {
+	OrderID          string // Internal out_trade_no, also used as idempotency key
+	Amount           string // 按服务商实例配置的币种解释
+	Subject          string
+	CustomerRef      string
+	PaymentMethodRef string
+}
+
+// RecurringChargeResponse is the synchronous result of an off-session charge.
+type RecurringChargeResponse struct {
+	TradeNo       string
+	Status        string  // ProviderStatusPaid, ProviderStatusPending or ProviderStatusFailed
+	Amount        float64 // 按 Currency 解释的实扣金额
+	Currency      string
+	FailureReason string // Human-readable decline reason when Status is failed
+}
+
 // RefundRequest contains the parameters for requesting a refund.
 type RefundRequest struct {
 	TradeNo string
@@ -241,6 +274,13 @@ type CancelableProvider interface {
 	CancelPayment(ctx context.Context, tradeNo string) error
 }
 
+// RecurringProvider extends Provider with off-session charges against a payment
+// method saved during an earlier CreatePayment with SaveForRecurring set.
+type RecurringProvider interface {
+	Provider
+	ChargeSavedPaymentMethod(ctx context.Context, req RecurringChargeRequest) (*RecurringChargeResponse, error)
+}
+
 // MerchantIdentityProvider exposes the current non-sensitive merchant identity
 // derived from provider configuration for snapshot consistency checks.
 type MerchantIdentityProvider interface {
@@ -0,0 +1,243 @@
+package apicompat
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Non-streaming: ChatCompletionsResponse → OllamaResponse
+// ---------------------------------------------------------------------------
+
+// ChatCompletionsToOllama converts a Chat Completions response into an Ollama
+// /api/chat response, or an /api/generate response when generate is true.
+// model is echoed back as the client sent it.
+func ChatCompletionsToOllama(resp *ChatCompletionsResponse, model string, generate bool) *OllamaResponse {
+	var msg ChatMessage
+	finishReason := ""
+	if len(resp.Choices) > 0 {
+		msg = resp.Choices[0].Message
+		finishReason = resp.Choices[0].FinishReason
+	}
+
+	out := &OllamaResponse{
+		Model:      model,
+		CreatedAt:  ollamaTimestamp(time.Now()),
+		Done:       true,
+		DoneReason: chatFinishReasonToOllama(finishReason),
+	}
+	applyOllamaUsage(out, resp.Usage)
+
+	content := chatMessageText(msg.Content)
+	if generate {
+		out.Response = &content
+		out.Thinking = msg.ReasoningContent
+		return out
+	}
+	out.Message = &OllamaMessage{Role: "assistant", Content: content, Thinking: msg.ReasoningContent}
+	for i, call := range msg.ToolCalls {
+		out.Message.ToolCalls = append(out.Message.ToolCalls, ollamaToolCallFromChat(i, call.Function.Name, call.Function.Arguments))
+	}
+	return out
+}
+
+// chatMessageText extracts the text of a string or parts-array content.
+func chatMessageText(content json.RawMessage) string {
+	if len(content) == 0 {
+		return ""
+	}
+	var s string
+	if json.Unmarshal(content, &s) == nil {
+		return s
+	}
+	var parts []ChatContentPart
+	if json.Unmarshal(content, &parts) != nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, part := range parts {
+		if part.Type == "text" {
+			b.WriteString(part.Text)
+		}
+	}
+	return b.String()
+}
+
+func ollamaToolCallFromChat(index int, name, arguments string) OllamaToolCall {
+	args := json.RawMessage(strings.TrimSpace(arguments))
+	if len(args) == 0 || !json.Valid(args) {
+		args = json.RawMessage(`{}`)
+	}
+	return OllamaToolCall{Function: OllamaToolCallFunction{Index: index, Name: name, Arguments: args}}
+}
+
+func chatFinishReasonToOllama(reason string) string {
+	if reason == "length" {
+		return "length"
+	}
+	return "stop"
+}
+
+func applyOllamaUsage(out *OllamaResponse, usage *ChatUsage) {
+	if usage == nil {
+		return
+	}
+	out.PromptEvalCount = usage.PromptTokens
+	out.EvalCount = usage.CompletionTokens
+}
+
+func ollamaTimestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// ---------------------------------------------------------------------------
+// Streaming: ChatCompletionsChunk → []OllamaResponse (stateful converter)
+// ---------------------------------------------------------------------------
+
+// ChatCompletionsToOllamaState tracks state for converting Chat Completions
+// chunks into Ollama NDJSON lines. Ollama streams tool calls as whole calls,
+// so argument deltas are buffered until the choice finishes. The done line is
+// only emitted by FinalizeChatCompletionsOllamaStream because the usage chunk
+// arrives after finish_reason.
+type ChatCompletionsToOllamaState struct {
+	Model        string
+	Generate     bool
+	Finalized    bool
+	SawToolCall  bool
+	FinishReason string
+	Usage        *ChatUsage
+
+	startedAt    time.Time
+	pendingCalls map[int]*ollamaPendingStreamCall // tool call index → call being assembled
+}
+
+type ollamaPendingStreamCall struct {
+	name string
+	args strings.Builder
+}
+
+// NewChatCompletionsToOllamaState returns an initialised stream state.
+func NewChatCompletionsToOllamaState(model string, generate bool) *ChatCompletionsToOllamaState {
+	return &ChatCompletionsToOllamaState{
+		Model:        model,
+		Generate:     generate,
+		startedAt:    time.Now(),
+		pendingCalls: make(map[int]*ollamaPendingStreamCall),
+	}
+}
+
+// ChatCompletionsChunkToOllama converts a single Chat Completions chunk into
+// zero or more Ollama stream lines, updating state as it goes.
+func ChatCompletionsChunkToOllama(chunk *ChatCompletionsChunk, state *ChatCompletionsToOllamaState) []OllamaResponse {
+	if state.Finalized {
+		return nil
+	}
+	if chunk.Usage != nil {
+		state.Usage = chunk.Usage
+	}
+
+	var out []OllamaResponse
+	for _, choice := range chunk.Choices {
+		if choice.Index != 0 {
+			continue
+		}
+		delta := choice.Delta
+		thinking := ""
+		if delta.ReasoningContent != nil {
+			thinking = *delta.ReasoningContent
+		}
+		content := ""
+		if delta.Content != nil {
+			content = *delta.Content
+		}
+		if thinking != "" || content != "" {
+			out = append(out, makeOllamaStreamLine(state, content, thinking, nil))
+		}
+		for _, call := range delta.ToolCalls {
+			idx := 0
+			if call.Index != nil {
+				idx = *call.Index
+			}
+			pending, ok := state.pendingCalls[idx]
+			if !ok {
+				pending = &ollamaPendingStreamCall{}
+				state.pendingCalls[idx] = pending
+			}
+			if call.Function.Name != "" {
+				pending.name = call.Function.Name
+			}
+			pending.args.WriteString(call.Function.Arguments)
+		}
+		if choice.FinishReason != nil && *choice.FinishReason != "" {
+			state.FinishReason = *choice.FinishReason
+			if calls := flushOllamaPendingCalls(state); len(calls) > 0 {
+				out = append(out, makeOllamaStreamLine(state, "", "", calls))
+			}
+		}
+	}
+	return out
+}
+
+// FinalizeChatCompletionsOllamaStream emits any buffered tool calls and the
+// closing done line. It is idempotent.
+func FinalizeChatCompletionsOllamaStream(state *ChatCompletionsToOllamaState) []OllamaResponse {
+	if state.Finalized {
+		return nil
+	}
+	state.Finalized = true
+
+	var out []OllamaResponse
+	if calls := flushOllamaPendingCalls(state); len(calls) > 0 {
+		out = append(out, makeOllamaStreamLine(state, "", "", calls))
+	}
+	done := makeOllamaStreamLine(state, "", "", nil)
+	done.Done = true
+	done.DoneReason = chatFinishReasonToOllama(state.FinishReason)
+	done.TotalDuration = time.Since(state.startedAt).Nanoseconds()
+	applyOllamaUsage(&done, state.Usage)
+	return append(out, done)
+}
+
+// OllamaResponseToNDJSON formats an Ollama response as one NDJSON line.
+func OllamaResponseToNDJSON(resp OllamaResponse) ([]byte, error) {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+func flushOllamaPendingCalls(state *ChatCompletionsToOllamaState) []OllamaToolCall {
+	if len(state.pendingCalls) == 0 {
+		return nil
+	}
+	indexes := make([]int, 0, len(state.pendingCalls))
+	for idx := range state.pendingCalls {
+		indexes = append(indexes, idx)
+	}
+	sort.Ints(indexes)
+	calls := make([]OllamaToolCall, 0, len(indexes))
+	for _, idx := range indexes {
+		call := state.pendingCalls[idx]
+		calls = append(calls, ollamaToolCallFromChat(idx, call.name, call.args.String()))
+	}
+	state.pendingCalls = make(map[int]*ollamaPendingStreamCall)
+	state.SawToolCall = true
+	return calls
+}
+
+func makeOllamaStreamLine(state *ChatCompletionsToOllamaState, content, thinking string, calls []OllamaToolCall) OllamaResponse {
+	line := OllamaResponse{
+		Model:     state.Model,
+		CreatedAt: ollamaTimestamp(time.Now()),
+	}
+	if state.Generate {
+		line.Response = &content
+		line.Thinking = thinking
+		return line
+	}
+	line.Message = &OllamaMessage{Role: "assistant", Content: content, Thinking: thinking, ToolCalls: calls}
+	return line
+}
@@ -0,0 +1,221 @@
+package apicompat
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ---------------------------------------------------------------------------
+// GeminiToResponses tests
+// ---------------------------------------------------------------------------
+
+func TestGeminiToResponses_TextImageAndConfig(t *testing.T) {
+	var req GeminiRequest
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"systemInstruction":{"parts":[{"text":"be brief"}]},
+		"contents":[{"role":"user","parts":[
+			{"text":"what is this?"},
+			{"inlineData":{"mimeType":"image/png","data":"iVBORw0KGgo="}}
+		]}],
+		"generationConfig":{"maxOutputTokens":16,"temperature":0.2,"responseMimeType":"application/json",
+			"thinkingConfig":{"thinkingBudget":4096,"includeThoughts":true}}
+	}`), &req))
+
+	resp, err := GeminiToResponses(&req, "claude-sonnet-4-5")
+	require.NoError(t, err)
+	assert.Equal(t, "claude-sonnet-4-5", resp.Model)
+	assert.Equal(t, "be brief", resp.Instructions)
+	assert.True(t, resp.Stream)
+	assert.False(t, *resp.Store)
+	require.NotNil(t, resp.MaxOutputTokens)
+	assert.Equal(t, minMaxOutputTokens, *resp.MaxOutputTokens)
+	require.NotNil(t, resp.Temperature)
+	require.NotNil(t, resp.Reasoning)
+	assert.Equal(t, "medium", resp.Reasoning.Effort)
+	require.NotNil(t, resp.Text)
+	assert.JSONEq(t, `{"type":"json_object"}`, string(resp.Text.Format))
+
+	var items []ResponsesInputItem
+	require.NoError(t, json.Unmarshal(resp.Input, &items))
+	require.Len(t, items, 1)
+	var parts []ResponsesContentPart
+	require.NoError(t, json.Unmarshal(items[0].Content, &parts))
+	require.Len(t, parts, 2)
+	assert.Equal(t, "input_text", parts[0].Type)
+	assert.Equal(t, "input_image", parts[1].Type)
+	assert.Equal(t, "data:image/png;base64,iVBORw0KGgo=", parts[1].ImageURL)
+}
+
+func TestGeminiToResponses_FunctionCallingRoundTrip(t *testing.T) {
+	var req GeminiRequest
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"contents":[
+			{"role":"user","parts":[{"text":"weather in Paris and Rome?"}]},
+			{"role":"model","parts":[
+				{"functionCall":{"name":"get_weather","args":{"city":"Paris"}}},
+				{"functionCall":{"name":"get_weather","args":{"city":"Rome"}}}
+			]},
+			{"role":"user","parts":[
+				{"functionResponse":{"name":"get_weather","response":{"temp":20}}},
+				{"functionResponse":{"name":"get_weather","response":{"temp":25}}}
+			]}
+		],
+		"tools":[{"functionDeclarations":[{"name":"get_weather","description":"Get weather",
+			"parameters":{"type":"OBJECT","properties":{"city":{"type":"STRING","nullable":true}},"required":["city"]}}]}],
+		"toolConfig":{"functionCallingConfig":{"mode":"ANY","allowedFunctionNames":["get_weather"]}}
+	}`), &req))
+
+	resp, err := GeminiToResponses(&req, "gpt-5")
+	require.NoError(t, err)
+	assert.Nil(t, resp.Temperature)
+
+	require.Len(t, resp.Tools, 1)
+	assert.Equal(t, "function", resp.Tools[0].Type)
+	assert.JSONEq(t, `{"type":"object","properties":{"city":{"type":["string","null"]}},"required":["city"]}`, string(resp.Tools[0].Parameters))
+	assert.JSONEq(t, `{"type":"function","name":"get_weather"}`, string(resp.ToolChoice))
+
+	var items []ResponsesInputItem
+	require.NoError(t, json.Unmarshal(resp.Input, &items))
+	require.Len(t, items, 5)
+	assert.Equal(t, "function_call", items[1].Type)
+	assert.Equal(t, "function_call", items[2].Type)
+	assert.JSONEq(t, `{"city":"Paris"}`, items[1].Arguments)
+	assert.Equal(t, "function_call_output", items[3].Type)
+	assert.Equal(t, items[1].CallID, items[3].CallID)
+	assert.Equal(t, items[2].CallID, items[4].CallID)
+	assert.NotEqual(t, items[1].CallID, items[2].CallID)
+	assert.JSONEq(t, `{"temp":25}`, items[4].Output)
+
+	// The same Responses request feeds the Anthropic upstream.
+	anthropicReq, err := ResponsesToAnthropicRequest(resp)
+	require.NoError(t, err)
+	require.Len(t, anthropicReq.Messages, 3)
+	assert.Equal(t, "assistant", anthropicReq.Messages[1].Role)
+	assert.Contains(t, string(anthropicReq.Messages[1].Content), `"tool_use"`)
+	assert.Contains(t, string(anthropicReq.Messages[2].Content), `"tool_result"`)
+}
+
+func TestGeminiToResponses_SkipsThoughtsAndRejectsNonImageMedia(t *testing.T) {
+	req := &GeminiRequest{Contents: []GeminiContent{
+		{Role: "user", Parts: []GeminiPart{{Text: "hi"}}},
+		{Role: "model", Parts: []GeminiPart{{Text: "thinking...", Thought: true, ThoughtSignature: "sig"}, {Text: "hello"}}},
+	}}
+	resp, err := GeminiToResponses(req, "gpt-4o")
+	require.NoError(t, err)
+	var items []ResponsesInputItem
+	require.NoError(t, json.Unmarshal(resp.Input, &items))
+	require.Len(t, items, 2)
+	assert.Equal(t, "assistant", items[1].Role)
+	assert.NotContains(t, string(items[1].Content), "thinking")
+
+	req.Contents[0].Parts = append(req.Contents[0].Parts, GeminiPart{InlineData: &GeminiBlob{MimeType: "application/pdf", Data: "JVBERi0="}})
+	_, err = GeminiToResponses(req, "gpt-4o")
+	require.Error(t, err)
+}
+
+// ---------------------------------------------------------------------------
+// ResponsesToGemini tests
+// ---------------------------------------------------------------------------
+
+func TestResponsesToGemini_PartsAndUsage(t *testing.T) {
+	resp := &ResponsesResponse{
+		ID:     "resp_1",
+		Status: "completed",
+		Output: []ResponsesOutput{
+			{Type: "reasoning", Summary: []ResponsesSummary{{Type: "summary_text", Text: "plan"}}},
+			{Type: "message", Content: []ResponsesContentPart{{Type: "output_text", Text: "Hello"}}},
+			{Type: "function_call", CallID: "call_1", Name: "get_weather", Arguments: `{"city":"Paris"}`},
+		},
+		Usage: &ResponsesUsage{
+			InputTokens:         100,
+			OutputTokens:        30,
+			InputTokensDetails:  &ResponsesInputTokensDetails{CachedTokens: 40},
+			OutputTokensDetails: &ResponsesOutputTokensDetails{ReasoningTokens: 10},
+		},
+	}
+
+	out := ResponsesToGemini(resp, "gemini-2.5-pro")
+	require.Len(t, out.Candidates, 1)
+	cand := out.Candidates[0]
+	assert.Equal(t, "STOP", cand.FinishReason)
+	assert.Equal(t, "model", cand.Content.Role)
+	require.Len(t, cand.Content.Parts, 3)
+	assert.True(t, cand.Content.Parts[0].Thought)
+	assert.Equal(t, "Hello", cand.Content.Parts[1].Text)
+	require.NotNil(t, cand.Content.Parts[2].FunctionCall)
+	assert.Equal(t, "call_1", cand.Content.Parts[2].FunctionCall.ID)
+	assert.JSONEq(t, `{"city":"Paris"}`, string(cand.Content.Parts[2].FunctionCall.Args))
+
+	require.NotNil(t, out.UsageMetadata)
+	assert.Equal(t, GeminiUsageMetadata{
+		PromptTokenCount:        100,
+		CandidatesTokenCount:    20,
+		TotalTokenCount:         130,
+		CachedContentTokenCount: 40,
+		ThoughtsTokenCount:      10,
+	}, *out.UsageMetadata)
+	assert.Equal(t, "gemini-2.5-pro", out.ModelVersion)
+}
+
+func TestResponsesToGemini_IncompleteMaxTokens(t *testing.T) {
+	out := ResponsesToGemini(&ResponsesResponse{
+		Status:            "incomplete",
+		IncompleteDetails: &ResponsesIncompleteDetails{Reason: "max_output_tokens"},
+	}, "m")
+	assert.Equal(t, "MAX_TOKENS", out.Candidates[0].FinishReason)
+	assert.NotNil(t, out.Candidates[0].Content.Parts)
+}
+
+func TestResponsesEventToGeminiChunks_Stream(t *testing.T) {
+	state := NewResponsesEventToGeminiState("gemini-2.5-flash")
+	var chunks []GeminiResponse
+	for _, evt := range []ResponsesStreamEvent{
+		{Type: "response.created", Response: &ResponsesResponse{ID: "resp_9"}},
+		{Type: "response.reasoning_summary_text.delta", Delta: "hmm"},
+		{Type: "response.output_text.delta", Delta: "Hi"},
+		{Type: "response.output_item.added", OutputIndex: 2, Item: &ResponsesOutput{Type: "function_call", CallID: "call_x", Name: "lookup"}},
+		{Type: "response.function_call_arguments.delta", OutputIndex: 2, Delta: `{"q":`},
+		{Type: "response.function_call_arguments.delta", OutputIndex: 2, Delta: `"go"}`},
+		{Type: "response.output_item.done", OutputIndex: 2, Item: &ResponsesOutput{Type: "function_call", CallID: "call_x", Name: "lookup"}},
+		{Type: "response.completed", Response: &ResponsesResponse{Status: "completed", Usage: &ResponsesUsage{InputTokens: 5, OutputTokens: 7}}},
+	} {
+		chunks = append(chunks, ResponsesEventToGeminiChunks(&evt, state)...)
+	}
+
+	require.Len(t, chunks, 4)
+	assert.True(t, chunks[0].Candidates[0].Content.Parts[0].Thought)
+	assert.Equal(t, "Hi", chunks[1].Candidates[0].Content.Parts[0].Text)
+	call := chunks[2].Candidates[0].Content.Parts[0].FunctionCall
+	require.NotNil(t, call)
+	assert.Equal(t, "lookup", call.Name)
+	assert.JSONEq(t, `{"q":"go"}`, string(call.Args))
+	last := chunks[3]
+	assert.Equal(t, "STOP", last.Candidates[0].FinishReason)
+	require.NotNil(t, last.UsageMetadata)
+	assert.Equal(t, 12, last.UsageMetadata.TotalTokenCount)
+	assert.Equal(t, "resp_9", last.ResponseID)
+
+	assert.Nil(t, FinalizeResponsesGeminiStream(state))
+
+	sse, err := GeminiChunkToSSE(last)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(sse, "data: {"))
+	assert.True(t, strings.HasSuffix(sse, "\n\n"))
+}
+
+func TestFinalizeResponsesGeminiStream_FlushesPendingCall(t *testing.T) {
+	state := NewResponsesEventToGeminiState("m")
+	ResponsesEventToGeminiChunks(&ResponsesStreamEvent{Type: "response.output_item.added", Item: &ResponsesOutput{Type: "function_call", CallID: "c", Name: "f"}}, state)
+	ResponsesEventToGeminiChunks(&ResponsesStreamEvent{Type: "response.function_call_arguments.delta", Delta: `{"a":1}`}, state)
+
+	chunks := FinalizeResponsesGeminiStream(state)
+	require.Len(t, chunks, 1)
+	assert.Equal(t, "STOP", chunks[0].Candidates[0].FinishReason)
+	require.Len(t, chunks[0].Candidates[0].Content.Parts, 1)
+	assert.JSONEq(t, `{"a":1}`, string(chunks[0].Candidates[0].Content.Parts[0].FunctionCall.Args))
+	assert.True(t, state.SawToolCall)
+}
@@ -0,0 +1,342 @@
+package apicompat
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// GeminiToResponses converts a Gemini generateContent request into a Responses
+// API request. model comes from the request URL. Like ChatCompletionsToResponses
+// the upstream always streams, store is false and encrypted reasoning is
+// requested. Anthropic upstreams reach the same request via
+// ResponsesToAnthropicRequest (Gemini → Responses → Anthropic chain).
+//
+// Gemini function calls may omit ids; missing ids are generated and handed to
+// the matching functionResponse parts in FIFO order per function name so the
+// call/output pairing survives the conversion.
+func GeminiToResponses(req *GeminiRequest, model string) (*ResponsesRequest, error) {
+	if req == nil {
+		return nil, fmt.Errorf("gemini request is nil")
+	}
+
+	input, err := convertGeminiContentsToResponsesInput(req.Contents)
+	if err != nil {
+		return nil, err
+	}
+	inputJSON, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &ResponsesRequest{
+		Model:        model,
+		Instructions: geminiContentText(req.SystemInstruction),
+		Input:        inputJSON,
+		Stream:       true, // upstream always streams
+		Include:      []string{"reasoning.encrypted_content"},
+	}
+	storeFalse := false
+	out.Store = &storeFalse
+
+	if cfg := req.GenerationConfig; cfg != nil {
+		if !isReasoningModel(model) {
+			out.Temperature = cfg.Temperature
+			out.TopP = cfg.TopP
+		}
+		if cfg.MaxOutputTokens != nil && *cfg.MaxOutputTokens > 0 {
+			v := max(*cfg.MaxOutputTokens, minMaxOutputTokens)
+			out.MaxOutputTokens = &v
+		}
+		if format := geminiResponseFormatToResponsesTextFormat(cfg); len(format) > 0 {
+			out.Text = &ResponsesText{Format: format}
+		}
+		if effort := geminiThinkingToResponsesEffort(cfg.ThinkingConfig); effort != "" {
+			out.Reasoning = &ResponsesReasoning{Effort: effort, Summary: "auto"}
+		}
+	}
+
+	for _, tool := range req.Tools {
+		for _, decl := range tool.FunctionDeclarations {
+			if strings.TrimSpace(decl.Name) == "" {
+				continue
+			}
+			params := decl.ParametersJSONSchema
+			if len(params) == 0 {
+				params = geminiSchemaToJSONSchema(decl.Parameters)
+			}
+			out.Tools = append(out.Tools, ResponsesTool{
+				Type:        "function",
+				Name:        decl.Name,
+				Description: decl.Description,
+				Parameters:  normalizeToolParameters(params),
+			})
+		}
+	}
+
+	if len(out.Tools) > 0 && req.ToolConfig != nil && req.ToolConfig.FunctionCallingConfig != nil {
+		out.ToolChoice = geminiFunctionCallingToToolChoice(req.ToolConfig.FunctionCallingConfig)
+	}
+
+	return out, nil
+}
+
+// convertGeminiContentsToResponsesInput flattens Gemini contents into Responses
+// input items. Consecutive text/image parts of one turn become a single role
+// message; every functionCall / functionResponse part becomes its own item.
+func convertGeminiContentsToResponsesInput(contents []GeminiContent) ([]ResponsesInputItem, error) {
+	var out []ResponsesInputItem
+	pendingIDs := make(map[string][]string)
+	callSeq := 0
+
+	for _, content := range contents {
+		role := "user"
+		textType := "input_text"
+		if content.Role == "model" {
+			role = "assistant"
+			textType = "output_text"
+		}
+
+		var parts []ResponsesContentPart
+		flush := func() error {
+			if len(parts) == 0 {
+				return nil
+			}
+			raw, err := json.Marshal(parts)
+			if err != nil {
+				return err
+			}
+			out = append(out, ResponsesInputItem{Role: role, Content: raw})
+			parts = nil
+			return nil
+		}
+
+		for _, part := range content.Parts {
+			switch {
+			case part.FunctionCall != nil:
+				if err := flush(); err != nil {
+					return nil, err
+				}
+				callSeq++
+				callID := strings.TrimSpace(part.FunctionCall.ID)
+				if callID == "" {
+					callID = "call_gemini_" + strconv.Itoa(callSeq)
+				}
+				pendingIDs[part.FunctionCall.Name] = append(pendingIDs[part.FunctionCall.Name], callID)
+				args := strings.TrimSpace(string(part.FunctionCall.Args))
+				if args == "" || args == "null" {
+					args = "{}"
+				}
+				out = append(out, ResponsesInputItem{
+					Type:      "function_call",
+					CallID:    callID,
+					Name:      part.FunctionCall.Name,
+					Arguments: args,
+				})
+			case part.FunctionResponse != nil:
+				if err := flush(); err != nil {
+					return nil, err
+				}
+				callID := strings.TrimSpace(part.FunctionResponse.ID)
+				name := part.FunctionResponse.Name
+				if queue := pendingIDs[name]; len(queue) > 0 {
+					if callID == "" {
+						callID = queue[0]
+					}
+					pendingIDs[name] = removeGeminiPendingID(queue, callID)
+				}
+				if callID == "" {
+					callSeq++
+					callID = "call_gemini_" + strconv.Itoa(callSeq)
+				}
+				output := strings.TrimSpace(string(part.FunctionResponse.Response))
+				if output == "" || output == "null" {
+					output = "{}"
+				}
+				out = append(out, ResponsesInputItem{
+					Type:   "function_call_output",
+					CallID: callID,
+					Output: output,
+				})
+			case part.InlineData != nil:
+				mimeType := strings.TrimSpace(part.InlineData.MimeType)
+				if !strings.HasPrefix(strings.ToLower(mimeType), "image/") {
+					return nil, fmt.Errorf("unsupported inlineData mimeType %q", mimeType)
+				}
+				if strings.TrimSpace(part.InlineData.Data) == "" || role != "user" {
+					continue
+				}
+				parts = append(parts, ResponsesContentPart{
+					Type:     "input_image",
+					ImageURL: "data:" + mimeType + ";base64," + part.InlineData.Data,
+				})
+			case part.Thought:
+				// Thought summaries and their signatures belong to the original
+				// Gemini upstream and cannot be replayed to another provider.
+				continue
+			case part.Text != "":
+				parts = append(parts, ResponsesContentPart{Type: textType, Text: part.Text})
+			}
+		}
+		if err := flush(); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func removeGeminiPendingID(queue []string, id string) []string {
+	for i, v := range queue {
+		if v == id {
+			return append(queue[:i:i], queue[i+1:]...)
+		}
+	}
+	return queue
+}
+
+// geminiContentText joins the text parts of a content (used for systemInstruction).
+func geminiContentText(content *GeminiContent) string {
+	if content == nil {
+		return ""
+	}
+	var texts []string
+	for _, part := range content.Parts {
+		if part.Text != "" && !part.Thought {
+			texts = append(texts, part.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// geminiSchemaToJSONSchema rewrites a Gemini OpenAPI-subset schema into JSON
+// Schema: upper-case type names are lower-cased and "nullable" is folded into
+// the type union. Unknown or malformed input is returned unchanged.
+func geminiSchemaToJSONSchema(schema json.RawMessage) json.RawMessage {
+	if len(schema) == 0 {
+		return schema
+	}
+	var v any
+	if err := json.Unmarshal(schema, &v); err != nil {
+		return schema
+	}
+	out, err := json.Marshal(normalizeGeminiSchemaValue(v))
+	if err != nil {
+		return schema
+	}
+	return out
+}
+
+func normalizeGeminiSchemaValue(v any) any {
+	switch node := v.(type) {
+	case map[string]any:
+		for key, child := range node {
+			switch key {
+			case "properties", "$defs", "definitions":
+				if props, ok := child.(map[string]any); ok {
+					for name, prop := range props {
+						props[name] = normalizeGeminiSchemaValue(prop)
+					}
+				}
+			case "type", "nullable", "enum", "required", "description", "format":
+			default:
+				node[key] = normalizeGeminiSchemaValue(child)
+			}
+		}
+		if typ, ok := node["type"].(string); ok {
+			typ = strings.ToLower(typ)
+			node["type"] = typ
+			if nullable, _ := node["nullable"].(bool); nullable && typ != "null" {
+				node["type"] = []any{typ, "null"}
+			}
+		}
+		delete(node, "nullable")
+		return node
+	case []any:
+		for i, child := range node {
+			node[i] = normalizeGeminiSchemaValue(child)
+		}
+		return node
+	default:
+		return v
+	}
+}
+
+// geminiResponseFormatToResponsesTextFormat maps responseMimeType and the
+// response schema onto Responses text.format.
+func geminiResponseFormatToResponsesTextFormat(cfg *GeminiGenerationConfig) json.RawMessage {
+	if !strings.EqualFold(strings.TrimSpace(cfg.ResponseMimeType), "application/json") {
+		return nil
+	}
+	schema := cfg.ResponseJSONSchema
+	if len(schema) == 0 && len(cfg.ResponseSchema) > 0 {
+		schema = geminiSchemaToJSONSchema(cfg.ResponseSchema)
+	}
+	if len(schema) == 0 {
+		return json.RawMessage(`{"type":"json_object"}`)
+	}
+	format, err := json.Marshal(map[string]any{
+		"type":   "json_schema",
+		"name":   "response",
+		"schema": schema,
+	})
+	if err != nil {
+		return nil
+	}
+	return format
+}
+
+// geminiThinkingToResponsesEffort maps thinkingLevel / thinkingBudget onto a
+// Responses reasoning effort. A zero budget disables reasoning; dynamic (-1)
+// or unset budgets leave the upstream default.
+func geminiThinkingToResponsesEffort(cfg *GeminiThinkingConfig) string {
+	if cfg == nil {
+		return ""
+	}
+	switch strings.ToLower(strings.TrimSpace(cfg.ThinkingLevel)) {
+	case "minimal", "low":
+		return "low"
+	case "medium":
+		return "medium"
+	case "high":
+		return "high"
+	}
+	if cfg.ThinkingBudget == nil {
+		return ""
+	}
+	switch budget := *cfg.ThinkingBudget; {
+	case budget <= 0:
+		return ""
+	case budget <= 2048:
+		return "low"
+	case budget <= 16384:
+		return "medium"
+	default:
+		return "high"
+	}
+}
+
+// geminiFunctionCallingToToolChoice maps functionCallingConfig.mode onto a
+// Responses tool_choice. ANY with a single allowed function pins that function.
+func geminiFunctionCallingToToolChoice(cfg *GeminiFunctionCallingConfig) json.RawMessage {
+	var choice any
+	switch strings.ToUpper(strings.TrimSpace(cfg.Mode)) {
+	case "NONE":
+		choice = "none"
+	case "ANY":
+		if len(cfg.AllowedFunctionNames) == 1 {
+			choice = map[string]string{"type": "function", "name": cfg.AllowedFunctionNames[0]}
+		} else {
+			choice = "required"
+		}
+	case "AUTO", "VALIDATED":
+		choice = "auto"
+	default:
+		return nil
+	}
+	raw, err := json.Marshal(choice)
+	if err != nil {
+		return nil
+	}
+	return raw
+}
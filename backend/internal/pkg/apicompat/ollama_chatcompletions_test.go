@@ -0,0 +1,186 @@
+package apicompat
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ---------------------------------------------------------------------------
+// OllamaChatToChatCompletions / OllamaGenerateToChatCompletions tests
+// ---------------------------------------------------------------------------
+
+func TestOllamaChatToChatCompletions_OptionsAndImages(t *testing.T) {
+	var req OllamaChatRequest
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"model":"gpt-5:latest",
+		"messages":[
+			{"role":"system","content":"be brief"},
+			{"role":"user","content":"what is this?","images":["iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNk+M9QDwADhgGAWjR9awAAAABJRU5ErkJggg=="]}
+		],
+		"format":"json",
+		"options":{"temperature":0.2,"num_predict":64,"stop":["END"],"num_ctx":8192},
+		"think":true
+	}`), &req))
+
+	out, err := OllamaChatToChatCompletions(&req)
+	require.NoError(t, err)
+	assert.Equal(t, "gpt-5", out.Model)
+	assert.True(t, out.Stream, "Ollama streams unless stream=false")
+	require.NotNil(t, out.StreamOptions)
+	assert.True(t, out.StreamOptions.IncludeUsage)
+	require.NotNil(t, out.MaxTokens)
+	assert.Equal(t, 64, *out.MaxTokens)
+	assert.JSONEq(t, `["END"]`, string(out.Stop))
+	assert.JSONEq(t, `{"type":"json_object"}`, string(out.ResponseFormat))
+	assert.Equal(t, "medium", out.ReasoningEffort)
+
+	require.Len(t, out.Messages, 2)
+	assert.JSONEq(t, `"be brief"`, string(out.Messages[0].Content))
+	var parts []ChatContentPart
+	require.NoError(t, json.Unmarshal(out.Messages[1].Content, &parts))
+	require.Len(t, parts, 2)
+	assert.Equal(t, "text", parts[0].Type)
+	assert.Equal(t, "image_url", parts[1].Type)
+	assert.True(t, strings.HasPrefix(parts[1].ImageURL.URL, "data:image/png;base64,iVBOR"))
+}
+
+func TestOllamaChatToChatCompletions_ToolRoundTrip(t *testing.T) {
+	stream := false
+	req := &OllamaChatRequest{
+		Model:  "claude-sonnet-4-5",
+		Stream: &stream,
+		Tools:  []ChatTool{{Function: &ChatFunction{Name: "get_weather", Parameters: json.RawMessage(`{"type":"object"}`)}}},
+		Messages: []OllamaMessage{
+			{Role: "user", Content: "weather in Paris and Rome?"},
+			{Role: "assistant", ToolCalls: []OllamaToolCall{
+				{Function: OllamaToolCallFunction{Name: "get_weather", Arguments: json.RawMessage(`{"city":"Paris"}`)}},
+				{Function: OllamaToolCallFunction{Name: "get_time", Arguments: json.RawMessage(`{"city":"Rome"}`)}},
+			}},
+			{Role: "tool", ToolName: "get_time", Content: "12:00"},
+			{Role: "tool", ToolName: "get_weather", Content: "20C"},
+		},
+	}
+
+	out, err := OllamaChatToChatCompletions(req)
+	require.NoError(t, err)
+	assert.False(t, out.Stream)
+	assert.Nil(t, out.StreamOptions)
+	require.Len(t, out.Tools, 1)
+	assert.Equal(t, "function", out.Tools[0].Type)
+
+	require.Len(t, out.Messages, 4)
+	calls := out.Messages[1].ToolCalls
+	require.Len(t, calls, 2)
+	assert.JSONEq(t, `{"city":"Paris"}`, calls[0].Function.Arguments)
+	assert.Equal(t, calls[1].ID, out.Messages[2].ToolCallID, "tool results pair by name, not position")
+	assert.Equal(t, calls[0].ID, out.Messages[3].ToolCallID)
+
+	_, err = OllamaChatToChatCompletions(&OllamaChatRequest{Messages: []OllamaMessage{{Role: "tool", Content: "x"}}})
+	require.Error(t, err)
+}
+
+func TestOllamaGenerateToChatCompletions(t *testing.T) {
+	out, err := OllamaGenerateToChatCompletions(&OllamaGenerateRequest{
+		Model:  "gpt-4o",
+		System: "sys",
+		Prompt: "hi",
+		Format: json.RawMessage(`{"type":"object","properties":{"a":{"type":"string"}}}`),
+	})
+	require.NoError(t, err)
+	require.Len(t, out.Messages, 2)
+	assert.Equal(t, "system", out.Messages[0].Role)
+	assert.JSONEq(t, `"hi"`, string(out.Messages[1].Content))
+	assert.JSONEq(t, `{"type":"json_schema","json_schema":{"name":"response","schema":{"type":"object","properties":{"a":{"type":"string"}}}}}`, string(out.ResponseFormat))
+
+	_, err = OllamaGenerateToChatCompletions(&OllamaGenerateRequest{Prompt: "x", Images: []string{"JVBERi0xLjQK"}})
+	require.Error(t, err, "non-image payloads are rejected")
+}
+
+// ---------------------------------------------------------------------------
+// ChatCompletionsToOllama tests
+// ---------------------------------------------------------------------------
+
+func TestChatCompletionsToOllama_ChatAndGenerate(t *testing.T) {
+	resp := &ChatCompletionsResponse{
+		Choices: []ChatChoice{{
+			Message: ChatMessage{
+				Role:             "assistant",
+				Content:          json.RawMessage(`"Hello"`),
+				ReasoningContent: "plan",
+				ToolCalls:        []ChatToolCall{{ID: "call_1", Function: ChatFunctionCall{Name: "lookup", Arguments: `{"q":"go"}`}}},
+			},
+			FinishReason: "tool_calls",
+		}},
+		Usage: &ChatUsage{PromptTokens: 11, CompletionTokens: 7},
+	}
+
+	chat := ChatCompletionsToOllama(resp, "gpt-5:latest", false)
+	assert.Equal(t, "gpt-5:latest", chat.Model)
+	assert.True(t, chat.Done)
+	assert.Equal(t, "stop", chat.DoneReason)
+	assert.Equal(t, 11, chat.PromptEvalCount)
+	assert.Equal(t, 7, chat.EvalCount)
+	require.NotNil(t, chat.Message)
+	assert.Equal(t, "Hello", chat.Message.Content)
+	assert.Equal(t, "plan", chat.Message.Thinking)
+	require.Len(t, chat.Message.ToolCalls, 1)
+	assert.JSONEq(t, `{"q":"go"}`, string(chat.Message.ToolCalls[0].Function.Arguments))
+	assert.Nil(t, chat.Response)
+
+	gen := ChatCompletionsToOllama(resp, "gpt-5", true)
+	assert.Nil(t, gen.Message)
+	require.NotNil(t, gen.Response)
+	assert.Equal(t, "Hello", *gen.Response)
+}
+
+func TestChatCompletionsChunkToOllama_Stream(t *testing.T) {
+	state := NewChatCompletionsToOllamaState("m", false)
+	text := "Hi"
+	stop := "tool_calls"
+	idx0 := 0
+	var lines []OllamaResponse
+	for _, chunk := range []ChatCompletionsChunk{
+		{Choices: []ChatChunkChoice{{Delta: ChatDelta{Role: "assistant", Content: &text}}}},
+		{Choices: []ChatChunkChoice{{Delta: ChatDelta{ToolCalls: []ChatToolCall{{Index: &idx0, Function: ChatFunctionCall{Name: "lookup", Arguments: `{"q":`}}}}}}},
+		{Choices: []ChatChunkChoice{{Delta: ChatDelta{ToolCalls: []ChatToolCall{{Index: &idx0, Function: ChatFunctionCall{Arguments: `"go"}`}}}}}}},
+		{Choices: []ChatChunkChoice{{FinishReason: &stop}}},
+		{Usage: &ChatUsage{PromptTokens: 5, CompletionTokens: 3}},
+	} {
+		lines = append(lines, ChatCompletionsChunkToOllama(&chunk, state)...)
+	}
+	lines = append(lines, FinalizeChatCompletionsOllamaStream(state)...)
+
+	require.Len(t, lines, 3)
+	assert.Equal(t, "Hi", lines[0].Message.Content)
+	assert.False(t, lines[0].Done)
+	require.Len(t, lines[1].Message.ToolCalls, 1)
+	assert.JSONEq(t, `{"q":"go"}`, string(lines[1].Message.ToolCalls[0].Function.Arguments))
+	done := lines[2]
+	assert.True(t, done.Done)
+	assert.Equal(t, "stop", done.DoneReason)
+	assert.Equal(t, 5, done.PromptEvalCount)
+	assert.Equal(t, 3, done.EvalCount)
+	assert.Nil(t, FinalizeChatCompletionsOllamaStream(state))
+
+	line, err := OllamaResponseToNDJSON(done)
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(string(line), "}\n"))
+	assert.Equal(t, 1, strings.Count(string(line), "\n"))
+}
+
+func TestChatCompletionsChunkToOllama_GenerateLength(t *testing.T) {
+	state := NewChatCompletionsToOllamaState("m", true)
+	text := "abc"
+	length := "length"
+	lines := ChatCompletionsChunkToOllama(&ChatCompletionsChunk{Choices: []ChatChunkChoice{{Delta: ChatDelta{Content: &text}, FinishReason: &length}}}, state)
+	lines = append(lines, FinalizeChatCompletionsOllamaStream(state)...)
+	require.Len(t, lines, 2)
+	require.NotNil(t, lines[0].Response)
+	assert.Equal(t, "abc", *lines[0].Response)
+	assert.Nil(t, lines[0].Message)
+	assert.Equal(t, "length", lines[1].DoneReason)
+}
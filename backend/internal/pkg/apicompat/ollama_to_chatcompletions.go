@@ -0,0 +1,268 @@
+package apicompat
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ollamaDefaultTag is the tag Ollama clients append when none is given.
+const ollamaDefaultTag = ":latest"
+
+// NormalizeOllamaModelName strips the implicit ":latest" tag so "gpt-5:latest"
+// resolves to the gateway model "gpt-5". Other tags are kept verbatim.
+func NormalizeOllamaModelName(model string) string {
+	return strings.TrimSuffix(strings.TrimSpace(model), ollamaDefaultTag)
+}
+
+// OllamaStreamRequested reports whether an Ollama request streams; Ollama
+// streams unless "stream": false is sent explicitly.
+func OllamaStreamRequested(stream *bool) bool {
+	return stream == nil || *stream
+}
+
+// OllamaChatToChatCompletions converts an Ollama /api/chat request into a
+// Chat Completions request.
+//
+// Ollama tool calls carry no ids and tool results reference the tool by name,
+// so ids are generated for assistant tool calls and handed to the following
+// tool messages in FIFO order per tool name.
+func OllamaChatToChatCompletions(req *OllamaChatRequest) (*ChatCompletionsRequest, error) {
+	if req == nil {
+		return nil, fmt.Errorf("ollama request is nil")
+	}
+
+	var pending []ollamaPendingCall
+	callSeq := 0
+
+	messages := make([]ChatMessage, 0, len(req.Messages))
+	for i, msg := range req.Messages {
+		switch msg.Role {
+		case "system", "user":
+			content, err := ollamaContentToChat(msg.Content, msg.Images)
+			if err != nil {
+				return nil, fmt.Errorf("messages[%d]: %w", i, err)
+			}
+			messages = append(messages, ChatMessage{Role: msg.Role, Content: content})
+		case "assistant":
+			out := ChatMessage{Role: "assistant"}
+			if msg.Content != "" {
+				out.Content, _ = json.Marshal(msg.Content)
+			}
+			for _, call := range msg.ToolCalls {
+				callSeq++
+				id := fmt.Sprintf("call_ollama_%d", callSeq)
+				pending = append(pending, ollamaPendingCall{id: id, name: call.Function.Name})
+				out.ToolCalls = append(out.ToolCalls, ChatToolCall{
+					ID:   id,
+					Type: "function",
+					Function: ChatFunctionCall{
+						Name:      call.Function.Name,
+						Arguments: ollamaArgumentsToString(call.Function.Arguments),
+					},
+				})
+			}
+			messages = append(messages, out)
+		case "tool":
+			id := takeOllamaToolCallID(&pending, msg.ToolName)
+			if id == "" {
+				return nil, fmt.Errorf("messages[%d]: tool message has no matching assistant tool call", i)
+			}
+			content, _ := json.Marshal(msg.Content)
+			messages = append(messages, ChatMessage{Role: "tool", ToolCallID: id, Content: content})
+		default:
+			return nil, fmt.Errorf("messages[%d]: unsupported role %q", i, msg.Role)
+		}
+	}
+
+	out := newOllamaChatCompletionsRequest(req.Model, req.Options, req.Format, req.Think, req.Stream)
+	out.Messages = messages
+	for _, tool := range req.Tools {
+		if tool.Function == nil || tool.Function.Name == "" {
+			continue
+		}
+		if tool.Type == "" {
+			tool.Type = "function"
+		}
+		out.Tools = append(out.Tools, tool)
+	}
+	return out, nil
+}
+
+// OllamaGenerateToChatCompletions converts an Ollama /api/generate request
+// into a single-turn Chat Completions request. raw, template, context and
+// suffix have no upstream equivalent and are ignored.
+func OllamaGenerateToChatCompletions(req *OllamaGenerateRequest) (*ChatCompletionsRequest, error) {
+	if req == nil {
+		return nil, fmt.Errorf("ollama request is nil")
+	}
+	content, err := ollamaContentToChat(req.Prompt, req.Images)
+	if err != nil {
+		return nil, err
+	}
+
+	out := newOllamaChatCompletionsRequest(req.Model, req.Options, req.Format, req.Think, req.Stream)
+	if strings.TrimSpace(req.System) != "" {
+		system, _ := json.Marshal(req.System)
+		out.Messages = append(out.Messages, ChatMessage{Role: "system", Content: system})
+	}
+	out.Messages = append(out.Messages, ChatMessage{Role: "user", Content: content})
+	return out, nil
+}
+
+func newOllamaChatCompletionsRequest(model string, opts *OllamaOptions, format, think json.RawMessage, stream *bool) *ChatCompletionsRequest {
+	out := &ChatCompletionsRequest{
+		Model:  NormalizeOllamaModelName(model),
+		Stream: OllamaStreamRequested(stream),
+	}
+	if out.Stream {
+		// The final NDJSON line reports token counts.
+		out.StreamOptions = &ChatStreamOptions{IncludeUsage: true}
+	}
+	if opts != nil {
+		out.Temperature = opts.Temperature
+		out.TopP = opts.TopP
+		if opts.NumPredict != nil && *opts.NumPredict > 0 {
+			v := *opts.NumPredict
+			out.MaxTokens = &v
+		}
+		if len(opts.Stop) > 0 {
+			out.Stop, _ = json.Marshal(opts.Stop)
+		}
+	}
+	out.ResponseFormat = ollamaFormatToChatResponseFormat(format)
+	out.ReasoningEffort = ollamaThinkToReasoningEffort(think)
+	return out
+}
+
+// ollamaContentToChat builds a Chat Completions content value: a plain string
+// without images, a text + image_url parts array otherwise.
+func ollamaContentToChat(text string, images []string) (json.RawMessage, error) {
+	if len(images) == 0 {
+		return json.Marshal(text)
+	}
+	parts := make([]ChatContentPart, 0, len(images)+1)
+	if text != "" {
+		parts = append(parts, ChatContentPart{Type: "text", Text: text})
+	}
+	for i, data := range images {
+		mediaType, err := ollamaImageMediaType(data)
+		if err != nil {
+			return nil, fmt.Errorf("images[%d]: %w", i, err)
+		}
+		parts = append(parts, ChatContentPart{
+			Type:     "image_url",
+			ImageURL: &ChatImageURL{URL: "data:" + mediaType + ";base64," + data},
+		})
+	}
+	return json.Marshal(parts)
+}
+
+// ollamaImageMediaType sniffs the media type of a base64 image; Ollama sends
+// raw base64 without a mime type.
+func ollamaImageMediaType(data string) (string, error) {
+	data = strings.TrimSpace(data)
+	if data == "" {
+		return "", fmt.Errorf("empty image")
+	}
+	prefix := data
+	if len(prefix) > 684 { // 684 base64 chars decode to the 512 bytes DetectContentType reads
+		prefix = prefix[:684]
+	}
+	prefix = prefix[:len(prefix)/4*4]
+	head, err := base64.StdEncoding.DecodeString(prefix)
+	if err != nil {
+		return "", fmt.Errorf("invalid base64 image: %w", err)
+	}
+	mediaType := http.DetectContentType(head)
+	if !strings.HasPrefix(mediaType, "image/") {
+		return "", fmt.Errorf("unsupported image type %q", mediaType)
+	}
+	return mediaType, nil
+}
+
+func ollamaArgumentsToString(args json.RawMessage) string {
+	trimmed := strings.TrimSpace(string(args))
+	if trimmed == "" || trimmed == "null" {
+		return "{}"
+	}
+	// Some clients send arguments already encoded as a JSON string.
+	var s string
+	if json.Unmarshal(args, &s) == nil {
+		return s
+	}
+	return trimmed
+}
+
+// ollamaPendingCall is an assistant tool call awaiting its tool message.
+type ollamaPendingCall struct {
+	id   string
+	name string
+}
+
+// takeOllamaToolCallID pops the oldest pending call with the given name,
+// falling back to the oldest pending call when the name does not match.
+func takeOllamaToolCallID(pending *[]ollamaPendingCall, name string) string {
+	if len(*pending) == 0 {
+		return ""
+	}
+	idx := 0
+	for i, call := range *pending {
+		if call.name == name {
+			idx = i
+			break
+		}
+	}
+	id := (*pending)[idx].id
+	*pending = append((*pending)[:idx:idx], (*pending)[idx+1:]...)
+	return id
+}
+
+// ollamaFormatToChatResponseFormat maps format "json" to json_object and a
+// schema object to json_schema.
+func ollamaFormatToChatResponseFormat(format json.RawMessage) json.RawMessage {
+	trimmed := strings.TrimSpace(string(format))
+	if trimmed == "" || trimmed == "null" || trimmed == `""` {
+		return nil
+	}
+	var s string
+	if json.Unmarshal(format, &s) == nil {
+		if s == "json" {
+			return json.RawMessage(`{"type":"json_object"}`)
+		}
+		return nil
+	}
+	if !strings.HasPrefix(trimmed, "{") {
+		return nil
+	}
+	out, _ := json.Marshal(map[string]any{
+		"type": "json_schema",
+		"json_schema": map[string]any{
+			"name":   "response",
+			"schema": json.RawMessage(trimmed),
+		},
+	})
+	return out
+}
+
+// ollamaThinkToReasoningEffort maps think=true to medium effort and passes
+// explicit levels through; false or absent leaves the upstream default.
+func ollamaThinkToReasoningEffort(think json.RawMessage) string {
+	var enabled bool
+	if json.Unmarshal(think, &enabled) == nil {
+		if enabled {
+			return "medium"
+		}
+		return ""
+	}
+	var level string
+	if json.Unmarshal(think, &level) == nil {
+		switch level = strings.ToLower(strings.TrimSpace(level)); level {
+		case "low", "medium", "high":
+			return level
+		}
+	}
+	return ""
+}
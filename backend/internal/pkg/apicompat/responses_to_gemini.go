@@ -0,0 +1,253 @@
+package apicompat
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------
+// Non-streaming: ResponsesResponse → GeminiResponse
+// ---------------------------------------------------------------------------
+
+// ResponsesToGemini converts a Responses API response into a Gemini
+// generateContent response. Reasoning summaries become thought parts, message
+// text becomes text parts and function_call items become functionCall parts.
+func ResponsesToGemini(resp *ResponsesResponse, model string) *GeminiResponse {
+	parts := make([]GeminiPart, 0, len(resp.Output))
+	for _, item := range resp.Output {
+		switch item.Type {
+		case "reasoning":
+			for _, s := range item.Summary {
+				if s.Type == "summary_text" && s.Text != "" {
+					parts = append(parts, GeminiPart{Text: s.Text, Thought: true})
+				}
+			}
+		case "message":
+			for _, part := range item.Content {
+				if part.Type == "output_text" && part.Text != "" {
+					parts = append(parts, GeminiPart{Text: part.Text})
+				}
+			}
+		case "function_call":
+			parts = append(parts, GeminiPart{FunctionCall: geminiFunctionCallFromResponses(item.CallID, item.Name, item.Arguments)})
+		}
+	}
+
+	return &GeminiResponse{
+		Candidates: []GeminiCandidate{{
+			Content:      GeminiContent{Role: "model", Parts: parts},
+			FinishReason: responsesStatusToGeminiFinishReason(resp.Status, resp.IncompleteDetails),
+		}},
+		UsageMetadata: geminiUsageFromResponsesUsage(resp.Usage),
+		ModelVersion:  model,
+		ResponseID:    resp.ID,
+	}
+}
+
+func geminiFunctionCallFromResponses(callID, name, arguments string) *GeminiFunctionCall {
+	args := json.RawMessage(strings.TrimSpace(arguments))
+	if len(args) == 0 || !json.Valid(args) {
+		args = json.RawMessage(`{}`)
+	}
+	return &GeminiFunctionCall{ID: callID, Name: name, Args: args}
+}
+
+func responsesStatusToGeminiFinishReason(status string, details *ResponsesIncompleteDetails) string {
+	switch status {
+	case "incomplete":
+		if details != nil {
+			switch details.Reason {
+			case "max_output_tokens":
+				return "MAX_TOKENS"
+			case "content_filter":
+				return "SAFETY"
+			}
+		}
+		return "OTHER"
+	case "failed":
+		return "OTHER"
+	default:
+		return "STOP"
+	}
+}
+
+// geminiUsageFromResponsesUsage maps OpenAI-semantics usage (input includes
+// cached tokens, output includes reasoning tokens) onto usageMetadata.
+func geminiUsageFromResponsesUsage(u *ResponsesUsage) *GeminiUsageMetadata {
+	if u == nil {
+		return nil
+	}
+	out := &GeminiUsageMetadata{
+		PromptTokenCount:     u.InputTokens,
+		CandidatesTokenCount: u.OutputTokens,
+	}
+	if u.InputTokensDetails != nil {
+		out.CachedContentTokenCount = u.InputTokensDetails.CachedTokens
+	}
+	if u.OutputTokensDetails != nil && u.OutputTokensDetails.ReasoningTokens > 0 {
+		out.ThoughtsTokenCount = min(u.OutputTokensDetails.ReasoningTokens, u.OutputTokens)
+		out.CandidatesTokenCount = u.OutputTokens - out.ThoughtsTokenCount
+	}
+	out.TotalTokenCount = out.PromptTokenCount + out.CandidatesTokenCount + out.ThoughtsTokenCount
+	return out
+}
+
+// ---------------------------------------------------------------------------
+// Streaming: ResponsesStreamEvent → []GeminiResponse (stateful converter)
+// ---------------------------------------------------------------------------
+
+// ResponsesEventToGeminiState tracks state for converting a sequence of
+// Responses SSE events into streamGenerateContent chunks. Gemini streams
+// function calls as whole parts, so argument deltas are buffered until the
+// output item is done.
+type ResponsesEventToGeminiState struct {
+	Model       string
+	ResponseID  string
+	Finalized   bool
+	SawToolCall bool
+	Usage       *GeminiUsageMetadata
+
+	pendingCalls map[int]*geminiPendingCall // Responses output_index → call being assembled
+}
+
+type geminiPendingCall struct {
+	callID string
+	name   string
+	args   strings.Builder
+}
+
+// NewResponsesEventToGeminiState returns an initialised stream state.
+func NewResponsesEventToGeminiState(model string) *ResponsesEventToGeminiState {
+	return &ResponsesEventToGeminiState{
+		Model:        model,
+		pendingCalls: make(map[int]*geminiPendingCall),
+	}
+}
+
+// ResponsesEventToGeminiChunks converts a single Responses SSE event into zero
+// or more Gemini chunks, updating state as it goes.
+func ResponsesEventToGeminiChunks(evt *ResponsesStreamEvent, state *ResponsesEventToGeminiState) []GeminiResponse {
+	if state.Finalized {
+		return nil
+	}
+	switch evt.Type {
+	case "response.created":
+		if evt.Response != nil && evt.Response.ID != "" {
+			state.ResponseID = evt.Response.ID
+		}
+		return nil
+	case "response.output_text.delta":
+		if evt.Delta == "" {
+			return nil
+		}
+		return []GeminiResponse{makeGeminiChunk(state, []GeminiPart{{Text: evt.Delta}}, "", nil)}
+	case "response.reasoning_summary_text.delta", "response.reasoning_text.delta":
+		if evt.Delta == "" {
+			return nil
+		}
+		return []GeminiResponse{makeGeminiChunk(state, []GeminiPart{{Text: evt.Delta, Thought: true}}, "", nil)}
+	case "response.output_item.added":
+		if evt.Item != nil && evt.Item.Type == "function_call" {
+			state.pendingCalls[evt.OutputIndex] = &geminiPendingCall{callID: evt.Item.CallID, name: evt.Item.Name}
+		}
+		return nil
+	case "response.function_call_arguments.delta":
+		if call, ok := state.pendingCalls[evt.OutputIndex]; ok {
+			call.args.WriteString(evt.Delta)
+		}
+		return nil
+	case "response.output_item.done":
+		if evt.Item == nil || evt.Item.Type != "function_call" {
+			return nil
+		}
+		call := state.pendingCalls[evt.OutputIndex]
+		delete(state.pendingCalls, evt.OutputIndex)
+		args := evt.Item.Arguments
+		if args == "" && call != nil {
+			args = call.args.String()
+		}
+		state.SawToolCall = true
+		return []GeminiResponse{makeGeminiChunk(state, []GeminiPart{{
+			FunctionCall: geminiFunctionCallFromResponses(evt.Item.CallID, evt.Item.Name, args),
+		}}, "", nil)}
+	case "response.completed", "response.done", "response.incomplete", "response.failed":
+		return resToGeminiHandleCompleted(evt, state)
+	default:
+		return nil
+	}
+}
+
+// FinalizeResponsesGeminiStream emits the closing chunk if the stream ended
+// without a terminal event (e.g. upstream disconnect). It is idempotent.
+func FinalizeResponsesGeminiStream(state *ResponsesEventToGeminiState) []GeminiResponse {
+	if state.Finalized {
+		return nil
+	}
+	state.Finalized = true
+	return []GeminiResponse{makeGeminiChunk(state, flushGeminiPendingCalls(state), "STOP", state.Usage)}
+}
+
+// GeminiChunkToSSE formats a Gemini chunk as an SSE data line (alt=sse).
+func GeminiChunkToSSE(chunk GeminiResponse) (string, error) {
+	data, err := json.Marshal(chunk)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("data: %s\n\n", data), nil
+}
+
+func resToGeminiHandleCompleted(evt *ResponsesStreamEvent, state *ResponsesEventToGeminiState) []GeminiResponse {
+	state.Finalized = true
+	if evt.Usage != nil {
+		state.Usage = geminiUsageFromResponsesUsage(evt.Usage)
+	}
+	finishReason := "STOP"
+	if evt.Response != nil {
+		if evt.Response.Usage != nil {
+			state.Usage = geminiUsageFromResponsesUsage(evt.Response.Usage)
+		}
+		status := evt.Response.Status
+		if status == "" && evt.Type == "response.failed" {
+			status = "failed"
+		}
+		finishReason = responsesStatusToGeminiFinishReason(status, evt.Response.IncompleteDetails)
+	}
+	return []GeminiResponse{makeGeminiChunk(state, flushGeminiPendingCalls(state), finishReason, state.Usage)}
+}
+
+// flushGeminiPendingCalls emits calls whose output_item.done never arrived.
+func flushGeminiPendingCalls(state *ResponsesEventToGeminiState) []GeminiPart {
+	if len(state.pendingCalls) == 0 {
+		return nil
+	}
+	indexes := make([]int, 0, len(state.pendingCalls))
+	for idx := range state.pendingCalls {
+		indexes = append(indexes, idx)
+	}
+	sort.Ints(indexes)
+	parts := make([]GeminiPart, 0, len(indexes))
+	for _, idx := range indexes {
+		call := state.pendingCalls[idx]
+		parts = append(parts, GeminiPart{FunctionCall: geminiFunctionCallFromResponses(call.callID, call.name, call.args.String())})
+	}
+	state.pendingCalls = make(map[int]*geminiPendingCall)
+	state.SawToolCall = true
+	return parts
+}
+
+func makeGeminiChunk(state *ResponsesEventToGeminiState, parts []GeminiPart, finishReason string, usage *GeminiUsageMetadata) GeminiResponse {
+	if parts == nil {
+		parts = []GeminiPart{}
+	}
+	return GeminiResponse{
+		Candidates: []GeminiCandidate{{
+			Content:      GeminiContent{Role: "model", Parts: parts},
+			FinishReason: finishReason,
+		}},
+		UsageMetadata: usage,
+		ModelVersion:  state.Model,
+		ResponseID:    state.ResponseID,
+	}
+}
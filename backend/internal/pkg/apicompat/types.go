@@ -774,6 +774,208 @@ type ChatDelta struct {
 	ToolCalls        []ChatToolCall `json:"tool_calls,omitempty"`
 }
 
+// ---------------------------------------------------------------------------
+// Gemini generateContent API types
+// ---------------------------------------------------------------------------
+
+// GeminiRequest is the request body for
+// POST /v1beta/models/{model}:generateContent and :streamGenerateContent.
+// The model name travels in the URL, not in the body.
+type GeminiRequest struct {
+	Contents          []GeminiContent         `json:"contents"`
+	SystemInstruction *GeminiContent          `json:"systemInstruction,omitempty"`
+	Tools             []GeminiTool            `json:"tools,omitempty"`
+	ToolConfig        *GeminiToolConfig       `json:"toolConfig,omitempty"`
+	GenerationConfig  *GeminiGenerationConfig `json:"generationConfig,omitempty"`
+}
+
+// GeminiContent is one turn of the conversation (or the system instruction).
+type GeminiContent struct {
+	Role  string       `json:"role,omitempty"` // "user" | "model" | "function"
+	Parts []GeminiPart `json:"parts"`
+}
+
+// GeminiPart is one part of a Gemini content. Exactly one data field is set.
+type GeminiPart struct {
+	Text             string                  `json:"text,omitempty"`
+	Thought          bool                    `json:"thought,omitempty"`
+	ThoughtSignature string                  `json:"thoughtSignature,omitempty"`
+	InlineData       *GeminiBlob             `json:"inlineData,omitempty"`
+	FunctionCall     *GeminiFunctionCall     `json:"functionCall,omitempty"`
+	FunctionResponse *GeminiFunctionResponse `json:"functionResponse,omitempty"`
+}
+
+// GeminiBlob carries base64 inline media (images etc.).
+type GeminiBlob struct {
+	MimeType string `json:"mimeType"`
+	Data     string `json:"data"`
+}
+
+// GeminiFunctionCall is a model-issued function call.
+type GeminiFunctionCall struct {
+	ID   string          `json:"id,omitempty"`
+	Name string          `json:"name"`
+	Args json.RawMessage `json:"args,omitempty"`
+}
+
+// GeminiFunctionResponse is the client's result for a function call.
+type GeminiFunctionResponse struct {
+	ID       string          `json:"id,omitempty"`
+	Name     string          `json:"name"`
+	Response json.RawMessage `json:"response,omitempty"`
+}
+
+// GeminiTool groups function declarations. Google built-in tools
+// (googleSearch, codeExecution, ...) have no counterpart and are ignored.
+type GeminiTool struct {
+	FunctionDeclarations []GeminiFunctionDeclaration `json:"functionDeclarations,omitempty"`
+}
+
+// GeminiFunctionDeclaration describes a callable function. Parameters uses the
+// Gemini OpenAPI subset (upper-case types); ParametersJSONSchema is plain JSON Schema.
+type GeminiFunctionDeclaration struct {
+	Name                 string          `json:"name"`
+	Description          string          `json:"description,omitempty"`
+	Parameters           json.RawMessage `json:"parameters,omitempty"`
+	ParametersJSONSchema json.RawMessage `json:"parametersJsonSchema,omitempty"`
+}
+
+// GeminiToolConfig controls function calling behaviour.
+type GeminiToolConfig struct {
+	FunctionCallingConfig *GeminiFunctionCallingConfig `json:"functionCallingConfig,omitempty"`
+}
+
+// GeminiFunctionCallingConfig selects the function calling mode.
+type GeminiFunctionCallingConfig struct {
+	Mode                 string   `json:"mode,omitempty"` // "AUTO" | "ANY" | "NONE" | "VALIDATED"
+	AllowedFunctionNames []string `json:"allowedFunctionNames,omitempty"`
+}
+
+// GeminiGenerationConfig holds sampling and output options.
+type GeminiGenerationConfig struct {
+	MaxOutputTokens    *int                  `json:"maxOutputTokens,omitempty"`
+	Temperature        *float64              `json:"temperature,omitempty"`
+	TopP               *float64              `json:"topP,omitempty"`
+	StopSequences      []string              `json:"stopSequences,omitempty"`
+	ResponseMimeType   string                `json:"responseMimeType,omitempty"`
+	ResponseSchema     json.RawMessage       `json:"responseSchema,omitempty"`
+	ResponseJSONSchema json.RawMessage       `json:"responseJsonSchema,omitempty"`
+	ThinkingConfig     *GeminiThinkingConfig `json:"thinkingConfig,omitempty"`
+}
+
+// GeminiThinkingConfig configures thinking. ThinkingBudget=0 disables it.
+type GeminiThinkingConfig struct {
+	IncludeThoughts bool   `json:"includeThoughts,omitempty"`
+	ThinkingBudget  *int   `json:"thinkingBudget,omitempty"`
+	ThinkingLevel   string `json:"thinkingLevel,omitempty"` // "low" | "medium" | "high"
+}
+
+// GeminiResponse is the generateContent response, and also the shape of each
+// streamGenerateContent SSE chunk.
+type GeminiResponse struct {
+	Candidates    []GeminiCandidate    `json:"candidates"`
+	UsageMetadata *GeminiUsageMetadata `json:"usageMetadata,omitempty"`
+	ModelVersion  string               `json:"modelVersion,omitempty"`
+	ResponseID    string               `json:"responseId,omitempty"`
+}
+
+// GeminiCandidate is a single generated candidate.
+type GeminiCandidate struct {
+	Content      GeminiContent `json:"content"`
+	FinishReason string        `json:"finishReason,omitempty"` // "STOP" | "MAX_TOKENS" | "SAFETY" | "OTHER"
+	Index        int           `json:"index"`
+}
+
+// GeminiUsageMetadata holds token counts in Gemini format.
+// promptTokenCount includes cachedContentTokenCount; candidatesTokenCount
+// excludes thoughtsTokenCount.
+type GeminiUsageMetadata struct {
+	PromptTokenCount        int `json:"promptTokenCount"`
+	CandidatesTokenCount    int `json:"candidatesTokenCount"`
+	TotalTokenCount         int `json:"totalTokenCount"`
+	CachedContentTokenCount int `json:"cachedContentTokenCount,omitempty"`
+	ThoughtsTokenCount      int `json:"thoughtsTokenCount,omitempty"`
+}
+
+// ---------------------------------------------------------------------------
+// Ollama API types
+// ---------------------------------------------------------------------------
+
+// OllamaChatRequest is the request body for POST /api/chat.
+type OllamaChatRequest struct {
+	Model    string          `json:"model"`
+	Messages []OllamaMessage `json:"messages"`
+	Tools    []ChatTool      `json:"tools,omitempty"`  // same shape as Chat Completions tools
+	Format   json.RawMessage `json:"format,omitempty"` // "json" or a JSON schema object
+	Options  *OllamaOptions  `json:"options,omitempty"`
+	Stream   *bool           `json:"stream,omitempty"` // nil means true
+	Think    json.RawMessage `json:"think,omitempty"`  // bool or "low" | "medium" | "high"
+}
+
+// OllamaGenerateRequest is the request body for POST /api/generate.
+type OllamaGenerateRequest struct {
+	Model   string          `json:"model"`
+	Prompt  string          `json:"prompt"`
+	System  string          `json:"system,omitempty"`
+	Images  []string        `json:"images,omitempty"` // base64 without data: prefix
+	Format  json.RawMessage `json:"format,omitempty"`
+	Options *OllamaOptions  `json:"options,omitempty"`
+	Stream  *bool           `json:"stream,omitempty"`
+	Think   json.RawMessage `json:"think,omitempty"`
+}
+
+// OllamaOptions holds the model options that have a Chat Completions
+// equivalent; runner-specific options (num_ctx, top_k, ...) are ignored.
+type OllamaOptions struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	NumPredict  *int     `json:"num_predict,omitempty"` // <= 0 means unlimited
+	Stop        []string `json:"stop,omitempty"`
+}
+
+// OllamaMessage is a single chat message. Tool results carry the tool name
+// instead of a call id.
+type OllamaMessage struct {
+	Role      string           `json:"role"` // "system" | "user" | "assistant" | "tool"
+	Content   string           `json:"content"`
+	Thinking  string           `json:"thinking,omitempty"`
+	Images    []string         `json:"images,omitempty"`
+	ToolCalls []OllamaToolCall `json:"tool_calls,omitempty"`
+	ToolName  string           `json:"tool_name,omitempty"`
+}
+
+// OllamaToolCall is a complete tool call; arguments are a JSON object.
+type OllamaToolCall struct {
+	Function OllamaToolCallFunction `json:"function"`
+}
+
+// OllamaToolCallFunction contains the function name and arguments.
+type OllamaToolCallFunction struct {
+	Index     int             `json:"index,omitempty"`
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+// OllamaResponse is a /api/chat or /api/generate response (or NDJSON stream
+// line). Chat responses set Message, generate responses set Response.
+type OllamaResponse struct {
+	Model           string         `json:"model"`
+	CreatedAt       string         `json:"created_at"`
+	Message         *OllamaMessage `json:"message,omitempty"`
+	Response        *string        `json:"response,omitempty"`
+	Thinking        string         `json:"thinking,omitempty"` // generate only
+	Done            bool           `json:"done"`
+	DoneReason      string         `json:"done_reason,omitempty"` // "stop" | "length" | "load"
+	TotalDuration   int64          `json:"total_duration,omitempty"`
+	PromptEvalCount int            `json:"prompt_eval_count,omitempty"`
+	EvalCount       int            `json:"eval_count,omitempty"`
+}
+
+// OllamaErrorResponse is the error body used by every Ollama endpoint.
+type OllamaErrorResponse struct {
+	Error string `json:"error"`
+}
+
 // ---------------------------------------------------------------------------
 // Shared constants
 // ---------------------------------------------------------------------------
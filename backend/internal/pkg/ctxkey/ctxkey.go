@@ -81,4 +81,8 @@ const (
 
 	// ClaudeCodeVersion stores the extracted Claude Code version from User-Agent (e.g. "2.1.22")
 	ClaudeCodeVersion Key = "ctx_claude_code_version"
+
+	// TrafficMirror 标识流量镜像重放的影子请求：不计费、不占用户并发，
+	// 可选地将调度限定到指定账号。
+	TrafficMirror Key = "ctx_traffic_mirror"
 )
@@ -0,0 +1,30 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var billingCacheLookupsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: Namespace,
+	Subsystem: "billing_cache",
+	Name:      "lookups_total",
+	Help:      "Billing cache lookups by cache kind and result (hit/miss).",
+}, []string{"cache", "result"})
+
+func init() {
+	registry.MustRegister(billingCacheLookupsTotal)
+}
+
+// Billing cache kinds.
+const (
+	BillingCacheBalance      = "balance"
+	BillingCacheSubscription = "subscription"
+	BillingCacheAPIKeyRate   = "api_key_rate_limit"
+)
+
+// ObserveBillingCacheLookup 记录一次计费缓存查询的命中情况。
+func ObserveBillingCacheLookup(cache string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	billingCacheLookupsTotal.WithLabelValues(cache, result).Inc()
+}
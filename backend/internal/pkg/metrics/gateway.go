@@ -0,0 +1,104 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// maxModelLabelValues 模型标签的基数上限。
+	maxModelLabelValues = 500
+	// maxGroupLabelValues 分组标签的基数上限。
+	maxGroupLabelValues = 1000
+)
+
+var (
+	gatewayRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "gateway",
+		Name:      "requests_total",
+		Help:      "Gateway requests by platform, model, group and response status class.",
+	}, []string{"platform", "model", "group", "status"})
+
+	gatewayRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: "gateway",
+		Name:      "request_duration_seconds",
+		Help:      "End-to-end gateway request latency, including streaming time.",
+		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20, 30, 60, 120, 300},
+	}, []string{"platform", "model", "group"})
+
+	gatewayTTFT = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: "gateway",
+		Name:      "time_to_first_token_seconds",
+		Help:      "Time to first token reported by the upstream forwarder.",
+		Buckets:   []float64{0.1, 0.25, 0.5, 0.75, 1, 1.5, 2, 3, 5, 10, 20, 30},
+	}, []string{"platform", "model", "group"})
+
+	gatewayUpstreamErrorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "gateway",
+		Name:      "upstream_errors_total",
+		Help:      "Upstream error attempts (including retried and failed-over attempts) by platform and error class.",
+	}, []string{"platform", "class"})
+
+	modelLabels = newLabelLimiter(maxModelLabelValues)
+	groupLabels = newLabelLimiter(maxGroupLabelValues)
+)
+
+func init() {
+	registry.MustRegister(
+		gatewayRequestsTotal,
+		gatewayRequestDuration,
+		gatewayTTFT,
+		gatewayUpstreamErrorsTotal,
+	)
+}
+
+// GatewayRequest 单次网关请求的观测数据。
+type GatewayRequest struct {
+	Platform   string
+	Model      string
+	Group      string
+	StatusCode int
+	Duration   time.Duration
+	// TTFT 首字时间，<=0 表示未知（非流式或请求失败）。
+	TTFT time.Duration
+}
+
+// ObserveGatewayRequest 记录一次网关请求的计数、延迟与首字时间。
+func ObserveGatewayRequest(r GatewayRequest) {
+	platform := normalizeLabelValue(r.Platform)
+	model := modelLabels.value(r.Model)
+	group := groupLabels.value(r.Group)
+
+	gatewayRequestsTotal.WithLabelValues(platform, model, group, StatusClass(r.StatusCode)).Inc()
+	if r.Duration > 0 {
+		gatewayRequestDuration.WithLabelValues(platform, model, group).Observe(r.Duration.Seconds())
+	}
+	if r.TTFT > 0 {
+		gatewayTTFT.WithLabelValues(platform, model, group).Observe(r.TTFT.Seconds())
+	}
+}
+
+// IncUpstreamError 记录一次上游错误尝试。
+func IncUpstreamError(platform, class string) {
+	gatewayUpstreamErrorsTotal.WithLabelValues(normalizeLabelValue(platform), normalizeLabelValue(class)).Inc()
+}
+
+// StatusClass 将 HTTP 状态码归并为 2xx/4xx/5xx 等类别；429 单独保留便于观察限流。
+func StatusClass(code int) string {
+	switch {
+	case code <= 0:
+		return LabelUnknown
+	case code == 429:
+		return "429"
+	case code >= 100 && code < 600:
+		return strconv.Itoa(code/100) + "xx"
+	default:
+		return LabelOther
+	}
+}
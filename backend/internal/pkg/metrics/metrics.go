@@ -0,0 +1,116 @@
+// Package metrics 提供进程级 Prometheus 指标注册表与 /metrics 暴露。
+//
+// 网关热路径只调用本包的 Observe*/Inc* 函数（内部均为无锁或读锁操作），
+// 需要在抓取时才计算的指标（账号池、并发槽位、worker 池等）由各服务实现
+// prometheus.Collector 后通过 Register 挂载。
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace 所有指标名的统一前缀。
+const Namespace = "sub2api"
+
+const (
+	// LabelUnknown 标签值缺失时的占位值。
+	LabelUnknown = "unknown"
+	// LabelOther 超出基数上限的标签值统一归入该值。
+	LabelOther = "other"
+
+	maxLabelValueLen = 128
+)
+
+var registry = newRegistry()
+
+func newRegistry() *prometheus.Registry {
+	r := prometheus.NewRegistry()
+	r.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return r
+}
+
+// Registry 返回进程级注册表。
+func Registry() *prometheus.Registry {
+	return registry
+}
+
+// Register 挂载自定义 Collector；重复注册同一 Collector 视为成功。
+func Register(c prometheus.Collector) error {
+	if c == nil {
+		return nil
+	}
+	if err := registry.Register(c); err != nil {
+		var already prometheus.AlreadyRegisteredError
+		if errors.As(err, &already) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// Handler 返回 Prometheus 文本格式的抓取处理器。
+// 单个 Collector 出错时继续输出其余指标，避免一处故障导致整页抓取失败。
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{
+		ErrorHandling: promhttp.ContinueOnError,
+	})
+}
+
+// labelLimiter 限制单个标签的取值基数。
+// 模型名等标签来自客户端请求，不加限制会让时序数量随任意输入无限增长；
+// 超过上限的新值统一归入 LabelOther，已出现过的值不受影响。
+type labelLimiter struct {
+	mu   sync.RWMutex
+	max  int
+	seen map[string]struct{}
+}
+
+func newLabelLimiter(max int) *labelLimiter {
+	return &labelLimiter{max: max, seen: make(map[string]struct{}, max)}
+}
+
+func (l *labelLimiter) value(v string) string {
+	v = normalizeLabelValue(v)
+	if v == LabelUnknown {
+		return v
+	}
+	l.mu.RLock()
+	_, ok := l.seen[v]
+	l.mu.RUnlock()
+	if ok {
+		return v
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.seen[v]; ok {
+		return v
+	}
+	if len(l.seen) >= l.max {
+		return LabelOther
+	}
+	l.seen[v] = struct{}{}
+	return v
+}
+
+func normalizeLabelValue(v string) string {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return LabelUnknown
+	}
+	if len(v) > maxLabelValueLen {
+		v = v[:maxLabelValueLen]
+	}
+	// 非法 UTF-8 会让 WithLabelValues panic，截断也可能切开多字节字符。
+	return strings.ToValidUTF8(v, "")
+}
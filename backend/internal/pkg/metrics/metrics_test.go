@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabelLimiter_CapsCardinality(t *testing.T) {
+	l := newLabelLimiter(2)
+
+	require.Equal(t, "a", l.value("a"))
+	require.Equal(t, "b", l.value(" b "))
+	require.Equal(t, LabelOther, l.value("c"))
+	// 已出现过的值不受上限影响
+	require.Equal(t, "a", l.value("a"))
+	require.Equal(t, LabelUnknown, l.value(""))
+}
+
+func TestNormalizeLabelValue(t *testing.T) {
+	require.Equal(t, LabelUnknown, normalizeLabelValue("   "))
+	require.Len(t, normalizeLabelValue(strings.Repeat("x", 300)), maxLabelValueLen)
+	require.Equal(t, "ab", normalizeLabelValue("a\xffb"))
+}
+
+func TestStatusClass(t *testing.T) {
+	cases := map[int]string{
+		0:   LabelUnknown,
+		200: "2xx",
+		400: "4xx",
+		429: "429",
+		502: "5xx",
+		700: LabelOther,
+	}
+	for code, want := range cases {
+		require.Equal(t, want, StatusClass(code), "code=%d", code)
+	}
+}
+
+func TestObserveGatewayRequest(t *testing.T) {
+	before := testutil.ToFloat64(gatewayRequestsTotal.WithLabelValues("anthropic", "claude-test", LabelUnknown, "2xx"))
+
+	ObserveGatewayRequest(GatewayRequest{
+		Platform:   "anthropic",
+		Model:      "claude-test",
+		StatusCode: http.StatusOK,
+		Duration:   1500 * time.Millisecond,
+		TTFT:       300 * time.Millisecond,
+	})
+
+	after := testutil.ToFloat64(gatewayRequestsTotal.WithLabelValues("anthropic", "claude-test", LabelUnknown, "2xx"))
+	require.Equal(t, before+1, after)
+}
+
+func TestHandler_ExposesRegisteredMetrics(t *testing.T) {
+	ObserveBillingCacheLookup(BillingCacheBalance, true)
+	IncUpstreamError("openai", "rate_limited")
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	require.Contains(t, body, `sub2api_billing_cache_lookups_total{cache="balance",result="hit"}`)
+	require.Contains(t, body, `sub2api_gateway_upstream_errors_total{class="rate_limited",platform="openai"}`)
+	require.Contains(t, body, "go_goroutines")
+}
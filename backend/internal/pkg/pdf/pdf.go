@@ -0,0 +1,195 @@
+// Package pdf 在 signintech/gopdf 之上封装 A4 页面的文本与直线绘制，
+// 用于发票、收据这类版式固定的单据。
+//
+// 拉丁、希腊、西里尔文本使用内置的 Go 字体（golang.org/x/image/font/gofont）；
+// 中日韩等其他字符使用调用方提供的 TrueType 后备字体。所有字体都以子集形式嵌入，
+// 输出不依赖阅读器本地字体。没有任何字体能覆盖的字符会让文档生成失败，
+// 而不是静默输出缺字的单据。
+package pdf
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/signintech/gopdf"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+)
+
+// A4 页面尺寸（单位：pt）。
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+// ErrGlyphMissing 表示文本含有内置字体与后备字体都无法显示的字符。
+var ErrGlyphMissing = errors.New("pdf: no font covers the text")
+
+const (
+	familyRegular  = "regular"
+	familyBold     = "bold"
+	familyFallback = "fallback"
+)
+
+// face 是一个已注册到文档的字体，sfnt 解析结果只用于判断字形覆盖。
+type face struct {
+	family string
+	font   *sfnt.Font
+}
+
+func (f *face) covers(buf *sfnt.Buffer, r rune) bool {
+	if f == nil {
+		return false
+	}
+	idx, err := f.font.GlyphIndex(buf, r)
+	return err == nil && idx != 0
+}
+
+// Document 按页累积绘制指令。坐标原点位于页面左上角，y 轴向下。
+// 绘制方法不返回错误，首个错误记录下来由 Bytes 返回。
+type Document struct {
+	gp       *gopdf.GoPdf
+	regular  *face
+	bold     *face
+	fallback *face
+	buf      sfnt.Buffer
+	err      error
+}
+
+// New 创建只含一页空白页的文档。fallback 为可选的 TrueType 字体数据（.ttf，
+// 需为 glyf 轮廓），用于内置字体缺失的字符，例如中文。
+func New(fallback []byte) (*Document, error) {
+	d := &Document{gp: &gopdf.GoPdf{}}
+	d.gp.Start(gopdf.Config{PageSize: *gopdf.PageSizeA4})
+
+	var err error
+	if d.regular, err = d.addFont(familyRegular, goregular.TTF); err != nil {
+		return nil, err
+	}
+	if d.bold, err = d.addFont(familyBold, gobold.TTF); err != nil {
+		return nil, err
+	}
+	if len(fallback) > 0 {
+		if d.fallback, err = d.addFont(familyFallback, fallback); err != nil {
+			return nil, fmt.Errorf("load fallback font: %w", err)
+		}
+	}
+	d.AddPage()
+	return d, nil
+}
+
+func (d *Document) addFont(family string, data []byte) (*face, error) {
+	parsed, err := sfnt.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.gp.AddTTFFontData(family, data); err != nil {
+		return nil, err
+	}
+	return &face{family: family, font: parsed}, nil
+}
+
+// AddPage 追加新页，之后的绘制指令写入该页。
+func (d *Document) AddPage() {
+	d.gp.AddPage()
+}
+
+// Text 在 (x, y) 处绘制单行文本，y 为基线位置。
+func (d *Document) Text(x, y, size float64, bold bool, s string) {
+	runs, ok := d.split(s, bold)
+	if !ok {
+		d.fail(fmt.Errorf("%w: %q", ErrGlyphMissing, s))
+		return
+	}
+	d.gp.SetXY(x, y)
+	for _, run := range runs {
+		if err := d.gp.SetFont(run.face.family, "", size); err != nil {
+			d.fail(err)
+			return
+		}
+		// Text 会把当前 x 推进到该段末尾，下一段接着绘制。
+		if err := d.gp.Text(run.text); err != nil {
+			d.fail(err)
+			return
+		}
+	}
+}
+
+// TextRight 绘制右对齐于 right 的单行文本。
+func (d *Document) TextRight(right, y, size float64, bold bool, s string) {
+	d.Text(right-d.TextWidth(s, size, bold), y, size, bold, s)
+}
+
+// Line 绘制一条直线。
+func (d *Document) Line(x1, y1, x2, y2, width float64) {
+	d.gp.SetLineWidth(width)
+	d.gp.Line(x1, y1, x2, y2)
+}
+
+// TextWidth 按实际使用的字体度量文本宽度；无法显示的文本宽度为 0。
+func (d *Document) TextWidth(s string, size float64, bold bool) float64 {
+	runs, ok := d.split(s, bold)
+	if !ok {
+		return 0
+	}
+	total := 0.0
+	for _, run := range runs {
+		if err := d.gp.SetFont(run.face.family, "", size); err != nil {
+			return 0
+		}
+		w, err := d.gp.MeasureTextWidth(run.text)
+		if err != nil {
+			return 0
+		}
+		total += w
+	}
+	return total
+}
+
+// Bytes 序列化为完整的 PDF 文件；绘制过程中出现过错误时返回该错误。
+func (d *Document) Bytes() ([]byte, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	return d.gp.GetBytesPdfReturnErr()
+}
+
+func (d *Document) fail(err error) {
+	if d.err == nil {
+		d.err = err
+	}
+}
+
+type textRun struct {
+	face *face
+	text string
+}
+
+// split 把文本切成连续的同字体片段：优先使用内置字体，缺字的字符改用后备字体。
+// 任一字符两者都无法显示时返回 false。
+func (d *Document) split(s string, bold bool) ([]textRun, bool) {
+	primary := d.regular
+	if bold {
+		primary = d.bold
+	}
+	var runs []textRun
+	for _, r := range s {
+		if r == '\t' {
+			r = ' '
+		}
+		f := primary
+		if !f.covers(&d.buf, r) {
+			if !d.fallback.covers(&d.buf, r) {
+				return nil, false
+			}
+			f = d.fallback
+		}
+		if n := len(runs); n > 0 && runs[n-1].face == f {
+			runs[n-1].text += string(r)
+			continue
+		}
+		runs = append(runs, textRun{face: f, text: string(r)})
+	}
+	return runs, true
+}
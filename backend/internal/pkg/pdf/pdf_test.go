@@ -0,0 +1,74 @@
+package pdf
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/go-fonts/dejavu/dejavusans"
+	pdfreader "github.com/ledongthuc/pdf"
+	"github.com/stretchr/testify/require"
+)
+
+// readText 用独立的 PDF 解析器读回文档，返回页数与抽取出的纯文本。
+func readText(t *testing.T, out []byte) (int, string) {
+	t.Helper()
+	r, err := pdfreader.NewReader(bytes.NewReader(out), int64(len(out)))
+	require.NoError(t, err)
+	plain, err := r.GetPlainText()
+	require.NoError(t, err)
+	text, err := io.ReadAll(plain)
+	require.NoError(t, err)
+	return r.NumPage(), string(text)
+}
+
+func TestDocumentBytes_ParsesWithIndependentReader(t *testing.T) {
+	doc, err := New(nil)
+	require.NoError(t, err)
+	doc.Text(50, 60, 12, true, "Invoice (INV-USD-000001)")
+	doc.Line(50, 70, 545, 70, 0.5)
+	doc.AddPage()
+	doc.TextRight(545, 60, 10, false, "Café Müller €5")
+
+	out, err := doc.Bytes()
+	require.NoError(t, err)
+	pages, text := readText(t, out)
+	require.Equal(t, 2, pages)
+	require.Contains(t, text, "Invoice (INV-USD-000001)")
+	require.Contains(t, text, "Café Müller €5")
+}
+
+func TestDocumentText_FallbackFontEmbedsMissingGlyphs(t *testing.T) {
+	// 内置 Go 字体不含亚美尼亚字母，用 DejaVu Sans 作后备字体验证分段嵌入。
+	const mixed = "Buyer: Բարեւ LLC"
+
+	doc, err := New(nil)
+	require.NoError(t, err)
+	doc.Text(50, 60, 10, false, mixed)
+	_, err = doc.Bytes()
+	require.ErrorIs(t, err, ErrGlyphMissing)
+
+	doc, err = New(dejavusans.TTF)
+	require.NoError(t, err)
+	doc.Text(50, 60, 10, false, mixed)
+	out, err := doc.Bytes()
+	require.NoError(t, err)
+	_, text := readText(t, out)
+	require.Contains(t, text, "Բարեւ")
+	require.Contains(t, text, "Buyer:")
+}
+
+func TestNew_RejectsInvalidFallbackFont(t *testing.T) {
+	_, err := New([]byte("not a font"))
+	require.Error(t, err)
+}
+
+func TestTextWidth(t *testing.T) {
+	doc, err := New(nil)
+	require.NoError(t, err)
+	one := doc.TextWidth("0", 10, false)
+	require.Greater(t, one, 0.0)
+	require.InDelta(t, 2*one, doc.TextWidth("00", 10, false), 1e-6)
+	require.InDelta(t, 2*one, doc.TextWidth("0", 20, false), 1e-6)
+	require.Zero(t, doc.TextWidth("中", 10, false))
+}
@@ -0,0 +1,55 @@
+package saml
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+type metadataEntityDescriptor struct {
+	XMLName  xml.Name                `xml:"urn:oasis:names:tc:SAML:2.0:metadata EntityDescriptor"`
+	EntityID string                  `xml:"entityID,attr"`
+	SP       metadataSPSSODescriptor `xml:"urn:oasis:names:tc:SAML:2.0:metadata SPSSODescriptor"`
+}
+
+type metadataSPSSODescriptor struct {
+	AuthnRequestsSigned        bool                     `xml:"AuthnRequestsSigned,attr"`
+	WantAssertionsSigned       bool                     `xml:"WantAssertionsSigned,attr"`
+	ProtocolSupportEnumeration string                   `xml:"protocolSupportEnumeration,attr"`
+	NameIDFormats              []string                 `xml:"urn:oasis:names:tc:SAML:2.0:metadata NameIDFormat,omitempty"`
+	AssertionConsumerServices  []metadataIndexedService `xml:"urn:oasis:names:tc:SAML:2.0:metadata AssertionConsumerService"`
+}
+
+type metadataIndexedService struct {
+	Binding   string `xml:"Binding,attr"`
+	Location  string `xml:"Location,attr"`
+	Index     int    `xml:"index,attr"`
+	IsDefault bool   `xml:"isDefault,attr"`
+}
+
+// Metadata 生成供 IdP 导入的 SP 元数据（EntityDescriptor）。
+// SP 不对 AuthnRequest 签名，但要求 IdP 对断言签名。
+func (sp *ServiceProvider) Metadata() ([]byte, error) {
+	desc := metadataEntityDescriptor{
+		EntityID: sp.EntityID,
+		SP: metadataSPSSODescriptor{
+			AuthnRequestsSigned:        false,
+			WantAssertionsSigned:       true,
+			ProtocolSupportEnumeration: nsProtocol,
+			AssertionConsumerServices: []metadataIndexedService{{
+				Binding:   BindingHTTPPost,
+				Location:  sp.ACSURL,
+				Index:     0,
+				IsDefault: true,
+			}},
+		},
+	}
+	if sp.NameIDFormat != "" {
+		desc.SP.NameIDFormats = []string{sp.NameIDFormat}
+	}
+
+	out, err := xml.MarshalIndent(desc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("saml: marshal metadata: %w", err)
+	}
+	return append([]byte(xml.Header), out...), nil
+}
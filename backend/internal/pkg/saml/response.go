@@ -0,0 +1,294 @@
+package saml
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxResponseSize 限制 SAMLResponse 解码后的大小，防止超大文档消耗资源。
+const maxResponseSize = 512 << 10
+
+// Assertion 是校验通过后从断言中提取的登录信息。
+type Assertion struct {
+	ID           string
+	Issuer       string
+	NameID       string
+	NameIDFormat string
+	SessionIndex string
+	// InResponseTo 为空表示 IdP-initiated 登录。
+	InResponseTo string
+	// ExpiresAt 为断言可被接受的最晚时间，可作为重放缓存的过期时间。
+	ExpiresAt time.Time
+	// Attributes 同时以 Name 与 FriendlyName 为键。
+	Attributes map[string][]string
+}
+
+// FirstAttribute 依次尝试给定的属性名，返回第一个非空值。
+func (a *Assertion) FirstAttribute(names ...string) string {
+	if a == nil {
+		return ""
+	}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		for _, v := range a.Attributes[name] {
+			if v = strings.TrimSpace(v); v != "" {
+				return v
+			}
+		}
+	}
+	return ""
+}
+
+// ParseResponse 校验 HTTP-POST 绑定提交的 SAMLResponse（base64）。
+// requestID 为 SP 发起登录时生成的 AuthnRequest ID；IdP-initiated 登录时为空，
+// 此时仅在 AllowIdPInitiated 开启且响应不带 InResponseTo 时才会被接受。
+func (sp *ServiceProvider) ParseResponse(samlResponse string, requestID string) (*Assertion, error) {
+	if len(samlResponse) > maxResponseSize*4/3+4 {
+		return nil, errors.New("saml: response too large")
+	}
+	raw, err := decodeBase64Value(samlResponse)
+	if err != nil {
+		return nil, fmt.Errorf("saml: decode response: %w", err)
+	}
+	if len(raw) > maxResponseSize {
+		return nil, errors.New("saml: response too large")
+	}
+	doc, err := parseXMLTree(raw)
+	if err != nil {
+		return nil, fmt.Errorf("saml: %w", err)
+	}
+	return sp.validateResponse(doc, requestID)
+}
+
+func (sp *ServiceProvider) validateResponse(resp *element, requestID string) (*Assertion, error) {
+	now := sp.now()
+	skew := sp.clockSkew()
+
+	if !resp.is(nsProtocol, "Response") {
+		return nil, errors.New("saml: root element is not a Response")
+	}
+
+	// 响应签名有效时，后续只读取签名覆盖的副本。
+	responseSigned := false
+	switch verified, err := verifyEnvelopedSignature(resp, resp, sp.IdPCertificates, now); {
+	case err == nil:
+		responseSigned = true
+		resp = verified
+	case !errors.Is(err, errNotSigned):
+		return nil, fmt.Errorf("saml: response signature: %w", err)
+	}
+
+	if resp.attr("Version") != "2.0" {
+		return nil, errors.New("saml: unsupported version")
+	}
+	if dest := resp.attr("Destination"); dest != "" && dest != sp.ACSURL {
+		return nil, fmt.Errorf("saml: destination mismatch: %q", dest)
+	}
+	inResponseTo := resp.attr("InResponseTo")
+	if err := sp.checkInResponseTo(inResponseTo, requestID); err != nil {
+		return nil, err
+	}
+	if issuer := resp.child(nsAssertion, "Issuer"); issuer != nil && strings.TrimSpace(issuer.text()) != sp.IdPEntityID {
+		return nil, errors.New("saml: response issuer mismatch")
+	}
+	status := resp.child(nsProtocol, "Status")
+	statusCode := status.child(nsProtocol, "StatusCode")
+	if statusCode == nil || statusCode.attr("Value") != statusSuccess {
+		value := ""
+		if statusCode != nil {
+			value = statusCode.attr("Value")
+		}
+		return nil, fmt.Errorf("saml: idp returned status %q", value)
+	}
+
+	if len(resp.childrenNamed(nsAssertion, "EncryptedAssertion")) > 0 {
+		return nil, errors.New("saml: encrypted assertions are not supported")
+	}
+	assertions := resp.childrenNamed(nsAssertion, "Assertion")
+	if len(assertions) != 1 {
+		return nil, errors.New("saml: response must contain exactly one assertion")
+	}
+	assertion := assertions[0]
+
+	switch verified, err := verifyEnvelopedSignature(resp, assertion, sp.IdPCertificates, now); {
+	case err == nil:
+		assertion = verified
+	case errors.Is(err, errNotSigned):
+		if !responseSigned {
+			return nil, errors.New("saml: neither response nor assertion is signed")
+		}
+	default:
+		return nil, fmt.Errorf("saml: assertion signature: %w", err)
+	}
+
+	// 以下只读取 goxmldsig 返回的、已被签名覆盖的 assertion 副本。
+	if assertion.attr("Version") != "2.0" {
+		return nil, errors.New("saml: unsupported assertion version")
+	}
+	if strings.TrimSpace(assertion.child(nsAssertion, "Issuer").text()) != sp.IdPEntityID {
+		return nil, errors.New("saml: assertion issuer mismatch")
+	}
+
+	subject := assertion.child(nsAssertion, "Subject")
+	if subject == nil {
+		return nil, errors.New("saml: assertion has no subject")
+	}
+	nameID := subject.child(nsAssertion, "NameID")
+	if nameID == nil || strings.TrimSpace(nameID.text()) == "" {
+		return nil, errors.New("saml: assertion has no NameID")
+	}
+
+	expiresAt, err := sp.checkSubjectConfirmation(subject, inResponseTo, now, skew)
+	if err != nil {
+		return nil, err
+	}
+	conditionsExpiry, err := sp.checkConditions(assertion.child(nsAssertion, "Conditions"), now, skew)
+	if err != nil {
+		return nil, err
+	}
+	if !conditionsExpiry.IsZero() && conditionsExpiry.Before(expiresAt) {
+		expiresAt = conditionsExpiry
+	}
+
+	out := &Assertion{
+		ID:           assertion.attr("ID"),
+		Issuer:       sp.IdPEntityID,
+		NameID:       strings.TrimSpace(nameID.text()),
+		NameIDFormat: nameID.attr("Format"),
+		InResponseTo: inResponseTo,
+		ExpiresAt:    expiresAt,
+		Attributes:   map[string][]string{},
+	}
+	if authn := assertion.child(nsAssertion, "AuthnStatement"); authn != nil {
+		out.SessionIndex = authn.attr("SessionIndex")
+		if v := authn.attr("SessionNotOnOrAfter"); v != "" {
+			t, err := parseSAMLTime(v)
+			if err != nil {
+				return nil, errors.New("saml: invalid SessionNotOnOrAfter")
+			}
+			if !now.Before(t.Add(skew)) {
+				return nil, errors.New("saml: authn session expired")
+			}
+		}
+	}
+	for _, stmt := range assertion.childrenNamed(nsAssertion, "AttributeStatement") {
+		for _, attr := range stmt.childrenNamed(nsAssertion, "Attribute") {
+			var values []string
+			for _, v := range attr.childrenNamed(nsAssertion, "AttributeValue") {
+				values = append(values, strings.TrimSpace(v.text()))
+			}
+			for _, key := range []string{attr.attr("Name"), attr.attr("FriendlyName")} {
+				if key != "" {
+					out.Attributes[key] = append(out.Attributes[key], values...)
+				}
+			}
+		}
+	}
+	return out, nil
+}
+
+func (sp *ServiceProvider) checkInResponseTo(inResponseTo, requestID string) error {
+	if inResponseTo == "" {
+		if !sp.AllowIdPInitiated {
+			return errors.New("saml: unsolicited response is not allowed")
+		}
+		return nil
+	}
+	if requestID == "" || inResponseTo != requestID {
+		return errors.New("saml: InResponseTo mismatch")
+	}
+	return nil
+}
+
+func (sp *ServiceProvider) checkSubjectConfirmation(subject *element, inResponseTo string, now time.Time, skew time.Duration) (time.Time, error) {
+	var lastErr error = errors.New("saml: no bearer subject confirmation")
+	for _, sc := range subject.childrenNamed(nsAssertion, "SubjectConfirmation") {
+		if sc.attr("Method") != confirmationBearer {
+			continue
+		}
+		data := sc.child(nsAssertion, "SubjectConfirmationData")
+		if data == nil {
+			lastErr = errors.New("saml: missing SubjectConfirmationData")
+			continue
+		}
+		if data.attr("Recipient") != sp.ACSURL {
+			lastErr = errors.New("saml: subject confirmation recipient mismatch")
+			continue
+		}
+		if v := data.attr("InResponseTo"); v != "" && v != inResponseTo {
+			lastErr = errors.New("saml: subject confirmation InResponseTo mismatch")
+			continue
+		}
+		notOnOrAfter, err := parseSAMLTime(data.attr("NotOnOrAfter"))
+		if err != nil {
+			lastErr = errors.New("saml: subject confirmation has no valid NotOnOrAfter")
+			continue
+		}
+		if !now.Before(notOnOrAfter.Add(skew)) {
+			lastErr = errors.New("saml: subject confirmation expired")
+			continue
+		}
+		if v := data.attr("NotBefore"); v != "" {
+			notBefore, err := parseSAMLTime(v)
+			if err != nil || now.Add(skew).Before(notBefore) {
+				lastErr = errors.New("saml: subject confirmation not yet valid")
+				continue
+			}
+		}
+		return notOnOrAfter.Add(skew), nil
+	}
+	return time.Time{}, lastErr
+}
+
+// checkConditions 校验有效期与受众限制，返回 NotOnOrAfter（含时钟偏差，可能为零值）。
+func (sp *ServiceProvider) checkConditions(cond *element, now time.Time, skew time.Duration) (time.Time, error) {
+	if cond == nil {
+		return time.Time{}, errors.New("saml: assertion has no conditions")
+	}
+	if v := cond.attr("NotBefore"); v != "" {
+		t, err := parseSAMLTime(v)
+		if err != nil || now.Add(skew).Before(t) {
+			return time.Time{}, errors.New("saml: assertion not yet valid")
+		}
+	}
+	var expiresAt time.Time
+	if v := cond.attr("NotOnOrAfter"); v != "" {
+		t, err := parseSAMLTime(v)
+		if err != nil || !now.Before(t.Add(skew)) {
+			return time.Time{}, errors.New("saml: assertion expired")
+		}
+		expiresAt = t.Add(skew)
+	}
+
+	// 每个 AudienceRestriction 都必须包含本 SP，且至少要有一个。
+	restrictions := cond.childrenNamed(nsAssertion, "AudienceRestriction")
+	if len(restrictions) == 0 {
+		return time.Time{}, errors.New("saml: assertion has no audience restriction")
+	}
+	for _, r := range restrictions {
+		matched := false
+		for _, aud := range r.childrenNamed(nsAssertion, "Audience") {
+			if strings.TrimSpace(aud.text()) == sp.EntityID {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return time.Time{}, errors.New("saml: audience mismatch")
+		}
+	}
+	return expiresAt, nil
+}
+
+func parseSAMLTime(v string) (time.Time, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return time.Time{}, errors.New("empty time")
+	}
+	return time.Parse(time.RFC3339Nano, v)
+}
@@ -0,0 +1,272 @@
+package saml
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testdata 中的响应由 libxmlsec1 使用 testdata/idp-cert.pem 对应的私钥签名（不带 KeyInfo）：
+//   - assertion-signed.xml：仅断言签名，rsa-sha256，带 InclusiveNamespaces PrefixList="xs"
+//   - response-signed.xml：仅响应签名，rsa-sha1，默认命名空间写法，IdP-initiated
+const (
+	testSPEntityID  = "https://sp.example.com/saml"
+	testACSURL      = "https://sp.example.com/api/v1/auth/oauth/saml/acs"
+	testIdPEntityID = "https://idp.example.com/metadata"
+)
+
+func readFixture(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	return string(data)
+}
+
+func encodeFixture(doc string) string {
+	return base64.StdEncoding.EncodeToString([]byte(doc))
+}
+
+func newTestSP(t *testing.T) *ServiceProvider {
+	t.Helper()
+	certs, err := ParseCertificates(readFixture(t, "idp-cert.pem"))
+	require.NoError(t, err)
+	return &ServiceProvider{
+		EntityID:          testSPEntityID,
+		ACSURL:            testACSURL,
+		IdPEntityID:       testIdPEntityID,
+		IdPSSOURL:         "https://idp.example.com/sso",
+		IdPCertificates:   certs,
+		AllowIdPInitiated: true,
+		Now: func() time.Time {
+			return time.Date(2026, 1, 1, 0, 1, 0, 0, time.UTC)
+		},
+	}
+}
+
+func signedAssertionBlock(t *testing.T, doc string) string {
+	t.Helper()
+	start := strings.Index(doc, "<saml:Assertion")
+	end := strings.Index(doc, "</saml:Assertion>")
+	require.True(t, start > 0 && end > start)
+	return doc[start : end+len("</saml:Assertion>")]
+}
+
+func TestParseResponse_AssertionSigned(t *testing.T) {
+	sp := newTestSP(t)
+
+	a, err := sp.ParseResponse(encodeFixture(readFixture(t, "assertion-signed.xml")), "_req1")
+	require.NoError(t, err)
+	require.Equal(t, "_assert1", a.ID)
+	require.Equal(t, "idp-user-42", a.NameID)
+	require.Equal(t, NameIDFormatPersistent, a.NameIDFormat)
+	require.Equal(t, "_session1", a.SessionIndex)
+	require.Equal(t, "_req1", a.InResponseTo)
+	require.Equal(t, "Alice@Example.com", a.FirstAttribute("mail", "email"))
+	require.Equal(t, "Alice@Example.com", a.FirstAttribute("http://schemas.xmlsoap.org/ws/2005/05/identity/claims/emailaddress"))
+	require.Equal(t, "Alice & Co", a.FirstAttribute("displayName"))
+	require.Equal(t, []string{"engineering", "admins"}, a.Attributes["groups"])
+	require.Equal(t, time.Date(2026, 1, 1, 0, 7, 0, 0, time.UTC), a.ExpiresAt)
+}
+
+func TestParseResponse_ResponseSignedIdPInitiated(t *testing.T) {
+	sp := newTestSP(t)
+	resp := encodeFixture(readFixture(t, "response-signed.xml"))
+
+	a, err := sp.ParseResponse(resp, "")
+	require.NoError(t, err)
+	require.Equal(t, "bob@example.com", a.NameID)
+	require.Equal(t, "bob", a.FirstAttribute("uid"))
+	require.Empty(t, a.InResponseTo)
+
+	sp.AllowIdPInitiated = false
+	_, err = sp.ParseResponse(resp, "")
+	require.ErrorContains(t, err, "unsolicited")
+}
+
+func TestParseResponse_InResponseToMismatch(t *testing.T) {
+	sp := newTestSP(t)
+	resp := encodeFixture(readFixture(t, "assertion-signed.xml"))
+
+	_, err := sp.ParseResponse(resp, "_other")
+	require.ErrorContains(t, err, "InResponseTo")
+	_, err = sp.ParseResponse(resp, "")
+	require.ErrorContains(t, err, "InResponseTo")
+}
+
+func TestParseResponse_RejectsTamperedContent(t *testing.T) {
+	sp := newTestSP(t)
+
+	doc := strings.Replace(readFixture(t, "assertion-signed.xml"), "idp-user-42", "idp-user-43", 1)
+	_, err := sp.ParseResponse(encodeFixture(doc), "_req1")
+	require.ErrorContains(t, err, "signature verification failed")
+
+	doc = strings.Replace(readFixture(t, "response-signed.xml"), "bob@example.com", "admin@example.com", 1)
+	_, err = sp.ParseResponse(encodeFixture(doc), "")
+	require.ErrorContains(t, err, "signature verification failed")
+}
+
+func TestParseResponse_CommentInsideNameIDDoesNotTruncate(t *testing.T) {
+	sp := newTestSP(t)
+
+	// 不带注释的 C14N 会忽略注释，签名依旧有效；读取时必须拿到完整文本而不是注释前的片段。
+	doc := strings.Replace(readFixture(t, "assertion-signed.xml"), "idp-user-42", "idp-user<!-- x -->-42", 1)
+	a, err := sp.ParseResponse(encodeFixture(doc), "_req1")
+	require.NoError(t, err)
+	require.Equal(t, "idp-user-42", a.NameID)
+}
+
+func TestParseResponse_RejectsUnsignedAndForeignSignatures(t *testing.T) {
+	sp := newTestSP(t)
+	doc := readFixture(t, "assertion-signed.xml")
+
+	start := strings.Index(doc, "<ds:Signature")
+	end := strings.Index(doc, "</ds:Signature>") + len("</ds:Signature>")
+	unsigned := doc[:start] + doc[end:]
+	_, err := sp.ParseResponse(encodeFixture(unsigned), "_req1")
+	require.ErrorContains(t, err, "neither response nor assertion is signed")
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "other-idp"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}, &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "other-idp"},
+	}, &key.PublicKey, key)
+	require.NoError(t, err)
+	other, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	sp.IdPCertificates = []*x509.Certificate{other}
+	_, err = sp.ParseResponse(encodeFixture(doc), "_req1")
+	require.ErrorContains(t, err, "signature verification failed")
+}
+
+func TestParseResponse_RejectsSignatureWrapping(t *testing.T) {
+	sp := newTestSP(t)
+	doc := readFixture(t, "assertion-signed.xml")
+	original := signedAssertionBlock(t, doc)
+
+	// 把真实断言藏进 Extensions，再放一个复制了签名、ID 相同的伪造断言。
+	forged := strings.Replace(original, "idp-user-42", "attacker", 1)
+	wrapped := strings.Replace(doc, original, "<samlp:Extensions>"+original+"</samlp:Extensions>"+forged, 1)
+	_, err := sp.ParseResponse(encodeFixture(wrapped), "_req1")
+	require.Error(t, err)
+
+	// 伪造断言使用新 ID、不带签名：既没有响应签名也没有断言签名。
+	unsignedForged := strings.Replace(forged, `ID="_assert1"`, `ID="_evil"`, 1)
+	sigStart := strings.Index(unsignedForged, "<ds:Signature")
+	sigEnd := strings.Index(unsignedForged, "</ds:Signature>") + len("</ds:Signature>")
+	unsignedForged = unsignedForged[:sigStart] + unsignedForged[sigEnd:]
+	wrapped = strings.Replace(doc, original, "<samlp:Extensions>"+original+"</samlp:Extensions>"+unsignedForged, 1)
+	_, err = sp.ParseResponse(encodeFixture(wrapped), "_req1")
+	require.ErrorContains(t, err, "neither response nor assertion is signed")
+
+	// 两个断言并列时直接拒绝。
+	twice := strings.Replace(doc, original, original+unsignedForged, 1)
+	_, err = sp.ParseResponse(encodeFixture(twice), "_req1")
+	require.ErrorContains(t, err, "exactly one assertion")
+}
+
+func TestParseResponse_RejectsSignatureWrappingVariants(t *testing.T) {
+	sp := newTestSP(t)
+	doc := readFixture(t, "assertion-signed.xml")
+	original := signedAssertionBlock(t, doc)
+	sigStart := strings.Index(original, "<ds:Signature")
+	sigEnd := strings.Index(original, "</ds:Signature>") + len("</ds:Signature>")
+	signature := original[sigStart:sigEnd]
+
+	// 签名藏在断言的深层子元素里，而不是断言的直接子元素。
+	buried := original[:sigStart] + original[sigEnd:]
+	buried = strings.Replace(buried, "</saml:Subject>", signature+"</saml:Subject>", 1)
+	_, err := sp.ParseResponse(encodeFixture(strings.Replace(doc, original, buried, 1)), "_req1")
+	require.ErrorContains(t, err, "neither response nor assertion is signed")
+
+	// 伪造断言带着真实签名，真实断言以相同 ID 藏在其内部。
+	forged := strings.Replace(original, "idp-user-42", "attacker", 1)
+	nested := strings.Replace(forged, "</saml:Assertion>", original+"</saml:Assertion>", 1)
+	_, err = sp.ParseResponse(encodeFixture(strings.Replace(doc, original, nested, 1)), "_req1")
+	require.ErrorContains(t, err, "not unique")
+
+	// 换了 ID 的伪造断言复用真实签名：引用不再指向自身。
+	renamed := strings.Replace(forged, `ID="_assert1"`, `ID="_evil"`, 1)
+	_, err = sp.ParseResponse(encodeFixture(strings.Replace(doc, original, renamed, 1)), "_req1")
+	require.ErrorContains(t, err, "reference does not match")
+
+	// 伪造断言内嵌一个引用它自身 ID 的签名副本。
+	innerSig := strings.Replace(renamed[:strings.Index(renamed, "<ds:Signature")]+renamed[strings.Index(renamed, "</ds:Signature>")+len("</ds:Signature>"):],
+		"</saml:Subject>", strings.Replace(signature, `URI="#_assert1"`, `URI="#_evil"`, 1)+"</saml:Subject>", 1)
+	withOuterSig := strings.Replace(innerSig, "<saml:Subject>", strings.Replace(signature, `URI="#_assert1"`, `URI="#_evil"`, 1)+"<saml:Subject>", 1)
+	_, err = sp.ParseResponse(encodeFixture(strings.Replace(doc, original, withOuterSig, 1)), "_req1")
+	require.ErrorContains(t, err, "nested signature")
+}
+
+func TestParseResponse_ResponseSignatureWrapping(t *testing.T) {
+	sp := newTestSP(t)
+	doc := readFixture(t, "response-signed.xml")
+	start := strings.Index(doc, "<Response")
+	signed := doc[start:]
+
+	// 真实的已签名响应被包进一个未签名的外层响应：外层断言无签名，必须拒绝。
+	forged := strings.Replace(signed, "bob@example.com", "admin@example.com", 1)
+	forgedSigStart := strings.Index(forged, "<Signature")
+	forgedSigEnd := strings.Index(forged, "</Signature>") + len("</Signature>")
+	forged = forged[:forgedSigStart] + "<Extensions>" + signed + "</Extensions>" + forged[forgedSigEnd:]
+	forged = strings.Replace(forged, `ID="_resp2"`, `ID="_wrapper"`, 1)
+	_, err := sp.ParseResponse(encodeFixture(forged), "")
+	require.ErrorContains(t, err, "neither response nor assertion is signed")
+
+	// 响应签名有效时，签名之外的改动（例如注释）不会改变读取结果。
+	commented := strings.Replace(doc, "bob@example.com", "bob@<!-- x -->example.com", 1)
+	a, err := sp.ParseResponse(encodeFixture(commented), "")
+	require.NoError(t, err)
+	require.Equal(t, "bob@example.com", a.NameID)
+}
+
+func TestParseResponse_ChecksAudienceRecipientAndTime(t *testing.T) {
+	resp := encodeFixture(readFixture(t, "assertion-signed.xml"))
+
+	sp := newTestSP(t)
+	sp.EntityID = "https://other-sp.example.com"
+	_, err := sp.ParseResponse(resp, "_req1")
+	require.ErrorContains(t, err, "audience mismatch")
+
+	sp = newTestSP(t)
+	sp.ACSURL = "https://other-sp.example.com/acs"
+	_, err = sp.ParseResponse(resp, "_req1")
+	require.ErrorContains(t, err, "destination mismatch")
+
+	sp = newTestSP(t)
+	sp.IdPEntityID = "https://evil-idp.example.com"
+	_, err = sp.ParseResponse(resp, "_req1")
+	require.ErrorContains(t, err, "issuer mismatch")
+
+	sp = newTestSP(t)
+	sp.Now = func() time.Time { return time.Date(2026, 1, 1, 0, 10, 0, 0, time.UTC) }
+	_, err = sp.ParseResponse(resp, "_req1")
+	require.ErrorContains(t, err, "expired")
+
+	sp.Now = func() time.Time { return time.Date(2025, 12, 31, 23, 50, 0, 0, time.UTC) }
+	_, err = sp.ParseResponse(resp, "_req1")
+	require.ErrorContains(t, err, "not yet valid")
+}
+
+func TestParseResponse_RejectsDTD(t *testing.T) {
+	sp := newTestSP(t)
+	doc := `<?xml version="1.0"?><!DOCTYPE r [<!ENTITY x "y">]><samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol"/>`
+	_, err := sp.ParseResponse(encodeFixture(doc), "")
+	require.ErrorContains(t, err, "DTD")
+}
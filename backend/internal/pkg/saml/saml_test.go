@@ -0,0 +1,82 @@
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/base64"
+	"encoding/pem"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAuthnRequest(t *testing.T) {
+	sp := newTestSP(t)
+	sp.IdPSSOURL = "https://idp.example.com/sso?tenant=acme"
+	sp.NameIDFormat = NameIDFormatEmail
+
+	req, err := sp.NewAuthnRequest("relay-123")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(req.ID, "_"))
+
+	u, err := url.Parse(req.RedirectURL)
+	require.NoError(t, err)
+	require.Equal(t, "idp.example.com", u.Host)
+	require.Equal(t, "acme", u.Query().Get("tenant"))
+	require.Equal(t, "relay-123", u.Query().Get("RelayState"))
+
+	compressed, err := base64.StdEncoding.DecodeString(u.Query().Get("SAMLRequest"))
+	require.NoError(t, err)
+	raw, err := io.ReadAll(flate.NewReader(bytes.NewReader(compressed)))
+	require.NoError(t, err)
+
+	doc, err := parseXMLTree(raw)
+	require.NoError(t, err)
+	require.True(t, doc.is(nsProtocol, "AuthnRequest"))
+	require.Equal(t, req.ID, doc.attr("ID"))
+	require.Equal(t, testACSURL, doc.attr("AssertionConsumerServiceURL"))
+	require.Equal(t, BindingHTTPPost, doc.attr("ProtocolBinding"))
+	require.Equal(t, "https://idp.example.com/sso?tenant=acme", doc.attr("Destination"))
+	require.Equal(t, testSPEntityID, doc.child(nsAssertion, "Issuer").text())
+	require.Equal(t, NameIDFormatEmail, doc.child(nsProtocol, "NameIDPolicy").attr("Format"))
+}
+
+func TestMetadata(t *testing.T) {
+	sp := newTestSP(t)
+
+	out, err := sp.Metadata()
+	require.NoError(t, err)
+
+	doc, err := parseXMLTree(out)
+	require.NoError(t, err)
+	require.True(t, doc.is("urn:oasis:names:tc:SAML:2.0:metadata", "EntityDescriptor"))
+	require.Equal(t, testSPEntityID, doc.attr("entityID"))
+	desc := doc.child("urn:oasis:names:tc:SAML:2.0:metadata", "SPSSODescriptor")
+	require.Equal(t, "true", desc.attr("WantAssertionsSigned"))
+	acs := desc.child("urn:oasis:names:tc:SAML:2.0:metadata", "AssertionConsumerService")
+	require.Equal(t, BindingHTTPPost, acs.attr("Binding"))
+	require.Equal(t, testACSURL, acs.attr("Location"))
+}
+
+func TestParseCertificates(t *testing.T) {
+	pemData := readFixture(t, "idp-cert.pem")
+	certs, err := ParseCertificates(pemData + "\n" + pemData)
+	require.NoError(t, err)
+	require.Len(t, certs, 2)
+
+	block, _ := pem.Decode([]byte(pemData))
+	require.NotNil(t, block)
+	bare := base64.StdEncoding.EncodeToString(block.Bytes)
+	certs, err = ParseCertificates(bare[:40] + "\n  " + bare[40:])
+	require.NoError(t, err)
+	require.Len(t, certs, 1)
+	require.Equal(t, "sub2api-test-idp", certs[0].Subject.CommonName)
+
+	_, err = ParseCertificates("not a certificate")
+	require.Error(t, err)
+	_, err = ParseCertificates("")
+	require.Error(t, err)
+}
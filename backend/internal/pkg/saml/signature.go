@@ -0,0 +1,110 @@
+package saml
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/russellhaering/goxmldsig/etreeutils"
+)
+
+const nsDSig = "http://www.w3.org/2000/09/xmldsig#"
+
+// errNotSigned 表示元素没有直接携带 Signature 子元素。
+var errNotSigned = errors.New("element is not signed")
+
+// verifyEnvelopedSignature 用 goxmldsig 校验 el 上的 enveloped 签名，返回签名覆盖的元素副本。
+// 调用方只能从返回值读取数据，不能再读原文档中的节点。
+//
+// 为防御签名包装（XSW）攻击，在交给 goxmldsig 之前额外要求：Signature 必须是 el 的直接子元素，
+// 仅含一个 Reference 且 URI 指向 el 自身的 ID，该 ID 在整个文档中唯一，
+// 且子树中没有其他引用 el（或整个文档）的签名。签名只用配置的 IdP 证书验证。
+func verifyEnvelopedSignature(root, el *element, certs []*x509.Certificate, now time.Time) (*element, error) {
+	sigs := el.childrenNamed(nsDSig, "Signature")
+	if len(sigs) == 0 {
+		return nil, errNotSigned
+	}
+	if len(sigs) > 1 {
+		return nil, errors.New("multiple signatures")
+	}
+	sig := sigs[0]
+
+	id := el.attr("ID")
+	if id == "" {
+		return nil, errors.New("signed element has no ID")
+	}
+	count := 0
+	root.walk(func(e *element) {
+		if e.attr("ID") == id {
+			count++
+		}
+	})
+	if count != 1 {
+		return nil, errors.New("signed element ID is not unique")
+	}
+
+	refs := sig.child(nsDSig, "SignedInfo").childrenNamed(nsDSig, "Reference")
+	if len(refs) != 1 {
+		return nil, errors.New("signature must contain exactly one reference")
+	}
+	if refs[0].attr("URI") != "#"+id {
+		return nil, errors.New("signature reference does not match signed element")
+	}
+	stray := false
+	el.walk(func(e *element) {
+		if !e.is(nsDSig, "Signature") || e.Element == sig.Element {
+			return
+		}
+		for _, ref := range e.child(nsDSig, "SignedInfo").childrenNamed(nsDSig, "Reference") {
+			if uri := ref.attr("URI"); uri == "" || uri == "#"+id {
+				stray = true
+			}
+		}
+	})
+	if stray {
+		return nil, errors.New("unexpected nested signature referencing signed element")
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no idp certificate configured")
+	}
+
+	// 把祖先上的命名空间声明带到副本上，签名校验与后续读取都与原文档树脱钩。
+	nsCtx, err := etreeutils.NSBuildParentContext(el.Element)
+	if err != nil {
+		return nil, err
+	}
+	detached, err := etreeutils.NSDetatch(nsCtx, el.Element)
+	if err != nil {
+		return nil, err
+	}
+	var lastErr error
+	for _, cert := range certs {
+		ctx := dsig.NewDefaultValidationContext(&dsig.MemoryX509CertificateStore{Roots: []*x509.Certificate{cert}})
+		ctx.Clock = dsig.NewFakeClockAt(now)
+		verified, err := ctx.Validate(detached)
+		if err == nil {
+			return wrapElement(verified), nil
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("signature verification failed: %w", lastErr)
+}
+
+// decodeBase64Value 解码 XML 中的 base64 文本，允许其中夹带换行与空白。
+func decodeBase64Value(s string) ([]byte, error) {
+	cleaned := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '\n', '\r':
+			return -1
+		}
+		return r
+	}, s)
+	if cleaned == "" {
+		return nil, errors.New("empty value")
+	}
+	return base64.StdEncoding.DecodeString(cleaned)
+}
@@ -0,0 +1,134 @@
+package saml
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/beevik/etree"
+)
+
+const maxXMLDepth = 64
+
+// element 包装 etree 元素，提供按命名空间读取 SAML 文档所需的最小接口。
+// 所有方法都允许 nil 接收者，便于链式读取可选节点。
+type element struct {
+	*etree.Element
+}
+
+func wrapElement(el *etree.Element) *element {
+	if el == nil {
+		return nil
+	}
+	return &element{Element: el}
+}
+
+// parseXMLTree 解析 XML 文档。拒绝 DOCTYPE 等指令，避免实体扩展类攻击。
+func parseXMLTree(data []byte) (*element, error) {
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(data); err != nil {
+		return nil, fmt.Errorf("parse xml: %w", err)
+	}
+	if err := checkXMLTokens(doc.Child, 0); err != nil {
+		return nil, err
+	}
+	roots := doc.ChildElements()
+	if len(roots) == 0 {
+		return nil, errors.New("parse xml: empty document")
+	}
+	if len(roots) > 1 {
+		return nil, errors.New("parse xml: multiple root elements")
+	}
+	return wrapElement(roots[0]), nil
+}
+
+func checkXMLTokens(tokens []etree.Token, depth int) error {
+	if depth > maxXMLDepth {
+		return errors.New("parse xml: document too deep")
+	}
+	for _, tok := range tokens {
+		switch t := tok.(type) {
+		case *etree.Directive:
+			return errors.New("parse xml: DTD is not allowed")
+		case *etree.Element:
+			if err := checkXMLTokens(t.Child, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (e *element) is(ns, local string) bool {
+	return e != nil && e.Tag == local && e.NamespaceURI() == ns
+}
+
+// attr 返回无前缀属性的值。
+func (e *element) attr(local string) string {
+	if e == nil {
+		return ""
+	}
+	for _, a := range e.Attr {
+		if a.Space == "" && a.Key == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func (e *element) childElements() []*element {
+	if e == nil {
+		return nil
+	}
+	children := e.ChildElements()
+	out := make([]*element, 0, len(children))
+	for _, c := range children {
+		out = append(out, wrapElement(c))
+	}
+	return out
+}
+
+func (e *element) childrenNamed(ns, local string) []*element {
+	var out []*element
+	for _, c := range e.childElements() {
+		if c.is(ns, local) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func (e *element) child(ns, local string) *element {
+	for _, c := range e.childElements() {
+		if c.is(ns, local) {
+			return c
+		}
+	}
+	return nil
+}
+
+// text 拼接全部直接文本子节点。etree.Element.Text 遇到注释即停止，
+// "a<!---->b" 会被截断为 "a"；这里返回签名覆盖的完整值 "ab"。
+func (e *element) text() string {
+	if e == nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, c := range e.Child {
+		if t, ok := c.(*etree.CharData); ok {
+			b.WriteString(t.Data)
+		}
+	}
+	return b.String()
+}
+
+// walk 深度优先遍历子树（含自身）。
+func (e *element) walk(fn func(*element)) {
+	if e == nil {
+		return
+	}
+	fn(e)
+	for _, c := range e.childElements() {
+		c.walk(fn)
+	}
+}
@@ -0,0 +1,21 @@
+package tracing
+
+import "github.com/Wei-Shaw/sub2api/internal/config"
+
+// OptionsFromConfig 由全局配置构造追踪参数；服务名/环境缺省沿用日志配置。
+func OptionsFromConfig(cfg *config.Config, version string) Options {
+	serviceName := cfg.Tracing.ServiceName
+	if serviceName == "" {
+		serviceName = cfg.Log.ServiceName
+	}
+	return Options{
+		Enabled:        cfg.Tracing.Enabled,
+		Endpoint:       cfg.Tracing.Endpoint,
+		Insecure:       cfg.Tracing.Insecure,
+		Headers:        cfg.Tracing.Headers,
+		SampleRatio:    cfg.Tracing.SampleRatio,
+		ServiceName:    serviceName,
+		ServiceVersion: version,
+		Environment:    cfg.Log.Environment,
+	}
+}
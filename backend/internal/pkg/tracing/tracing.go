@@ -0,0 +1,145 @@
+// Package tracing 提供 OpenTelemetry 分布式追踪的初始化与 span 辅助函数。
+//
+// 未调用 Init（或 tracing.enabled=false）时全局 TracerProvider 为 noop，
+// Start/End 只产生不记录的 span，埋点处无需判断开关。
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/Wei-Shaw/sub2api"
+
+// 网关领域的 span 属性。
+const (
+	AttrAccountID = attribute.Key("sub2api.account.id")
+	AttrPlatform  = attribute.Key("sub2api.platform")
+	AttrGroupID   = attribute.Key("sub2api.group.id")
+	AttrAPIKeyID  = attribute.Key("sub2api.api_key.id")
+	AttrUserID    = attribute.Key("sub2api.user.id")
+	AttrModel     = attribute.Key("sub2api.model")
+	AttrAttempt   = attribute.Key("sub2api.attempt")
+	AttrRequestID = attribute.Key("sub2api.request_id")
+)
+
+// Options 追踪初始化参数。
+type Options struct {
+	Enabled bool
+	// Endpoint OTLP/gRPC collector 地址（host:port）。
+	Endpoint string
+	Insecure bool
+	Headers  map[string]string
+	// SampleRatio 根 span 采样率 [0,1]；客户端带 traceparent 时沿用其采样决定。
+	SampleRatio float64
+
+	ServiceName    string
+	ServiceVersion string
+	Environment    string
+}
+
+// Init 按配置安装全局 TracerProvider 与 W3C TraceContext/Baggage 传播器。
+// 返回的 shutdown 负责刷新并关闭导出器；未启用时 shutdown 为空操作。
+func Init(ctx context.Context, opts Options) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	noop := func(context.Context) error { return nil }
+	if !opts.Enabled {
+		return noop, nil
+	}
+	endpoint := strings.TrimSpace(opts.Endpoint)
+	if endpoint == "" {
+		return noop, errors.New("tracing endpoint is required")
+	}
+
+	exporterOpts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(endpoint)}
+	if opts.Insecure {
+		exporterOpts = append(exporterOpts, otlptracegrpc.WithInsecure())
+	}
+	if len(opts.Headers) > 0 {
+		exporterOpts = append(exporterOpts, otlptracegrpc.WithHeaders(opts.Headers))
+	}
+	exporter, err := otlptracegrpc.New(ctx, exporterOpts...)
+	if err != nil {
+		return noop, fmt.Errorf("create otlp trace exporter: %w", err)
+	}
+
+	serviceName := strings.TrimSpace(opts.ServiceName)
+	if serviceName == "" {
+		serviceName = "sub2api"
+	}
+	attrs := []attribute.KeyValue{semconv.ServiceName(serviceName)}
+	if v := strings.TrimSpace(opts.ServiceVersion); v != "" {
+		attrs = append(attrs, semconv.ServiceVersion(v))
+	}
+	if env := strings.TrimSpace(opts.Environment); env != "" {
+		attrs = append(attrs, semconv.DeploymentEnvironmentName(env))
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(attrs...))
+	if err != nil {
+		res = resource.NewSchemaless(attrs...)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		logger.LegacyPrintf("pkg.tracing", "[Tracing] otel error: %v", err)
+	}))
+	return tp.Shutdown, nil
+}
+
+// Tracer 返回本服务的 tracer。
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Start 在 ctx 下开启一个内部 span。
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End 结束 span；err 非空时记录错误并将 span 状态置为 Error。
+func End(span trace.Span, err error) {
+	if span == nil {
+		return
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// TraceID 返回 ctx 中当前 span 的 trace ID；无有效 span 时返回空串。
+func TraceID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.HasTraceID() {
+		return ""
+	}
+	return sc.TraceID().String()
+}
@@ -0,0 +1,46 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestInit_DisabledIsNoop(t *testing.T) {
+	shutdown, err := Init(context.Background(), Options{Enabled: false})
+	require.NoError(t, err)
+	require.NoError(t, shutdown(context.Background()))
+
+	ctx, span := Start(context.Background(), "noop")
+	require.False(t, span.IsRecording())
+	require.Empty(t, TraceID(ctx))
+	End(span, nil)
+}
+
+func TestInit_RequiresEndpoint(t *testing.T) {
+	_, err := Init(context.Background(), Options{Enabled: true, Endpoint: "  "})
+	require.Error(t, err)
+}
+
+func TestStartEnd_RecordsError(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	ctx, span := Start(context.Background(), "gateway.test", AttrAccountID.Int64(42))
+	require.NotEmpty(t, TraceID(ctx))
+	End(span, errors.New("boom"))
+
+	ended := recorder.Ended()
+	require.Len(t, ended, 1)
+	require.Equal(t, "gateway.test", ended[0].Name())
+	require.Equal(t, codes.Error, ended[0].Status().Code)
+	require.Contains(t, ended[0].Attributes(), AttrAccountID.Int64(42))
+}
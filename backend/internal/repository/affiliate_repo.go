@@ -286,6 +286,11 @@ FROM cleared`, userID)
 			return service.ErrAffiliateQuotaEmpty
 		}
 
+		if err := applyBalanceLedgerTag(txCtx, txClient, service.BalanceLedgerTag{
+			Type: service.BalanceLedgerTypeAffiliateTransfer,
+		}); err != nil {
+			return fmt.Errorf("apply balance ledger tag: %w", err)
+		}
 		affected, err := txClient.User.Update().
 			Where(user.IDEQ(userID)).
 			AddBalance(transferred).
@@ -53,7 +53,9 @@ func (r *apiKeyRepository) Create(ctx context.Context, key *service.APIKey) erro
 		SetNillableExpiresAt(key.ExpiresAt).
 		SetRateLimit5h(key.RateLimit5h).
 		SetRateLimit1d(key.RateLimit1d).
-		SetRateLimit7d(key.RateLimit7d)
+		SetRateLimit7d(key.RateLimit7d).
+		SetRpmLimit(key.RPMLimit).
+		SetMaxConcurrency(key.MaxConcurrency)
 
 	if len(key.IPWhitelist) > 0 {
 		builder.SetIPWhitelist(key.IPWhitelist)
@@ -61,6 +63,12 @@ func (r *apiKeyRepository) Create(ctx context.Context, key *service.APIKey) erro
 	if len(key.IPBlacklist) > 0 {
 		builder.SetIPBlacklist(key.IPBlacklist)
 	}
+	if len(key.ModelAllowlist) > 0 {
+		builder.SetModelAllowlist(key.ModelAllowlist)
+	}
+	if len(key.ModelDenylist) > 0 {
+		builder.SetModelDenylist(key.ModelDenylist)
+	}
 
 	created, err := builder.Save(ctx)
 	if err == nil {
@@ -136,12 +144,16 @@ func (r *apiKeyRepository) GetByKeyForAuth(ctx context.Context, key string) (*se
 			apikey.FieldStatus,
 			apikey.FieldIPWhitelist,
 			apikey.FieldIPBlacklist,
+			apikey.FieldModelAllowlist,
+			apikey.FieldModelDenylist,
 			apikey.FieldQuota,
 			apikey.FieldQuotaUsed,
 			apikey.FieldExpiresAt,
 			apikey.FieldRateLimit5h,
 			apikey.FieldRateLimit1d,
 			apikey.FieldRateLimit7d,
+			apikey.FieldRpmLimit,
+			apikey.FieldMaxConcurrency,
 		).
 		WithUser(func(q *dbent.UserQuery) {
 			q.Select(
@@ -161,6 +173,7 @@ func (r *apiKeyRepository) GetByKeyForAuth(ctx context.Context, key string) (*se
 				user.FieldLastLoginAt,
 				user.FieldLastActiveAt,
 				user.FieldRpmLimit,
+				user.FieldCreditLimit,
 			)
 			q.WithAllowedGroups(func(gq *dbent.GroupQuery) {
 				gq.Select(group.FieldID)
@@ -221,6 +234,13 @@ func (r *apiKeyRepository) GetByKeyForAuth(ctx context.Context, key string) (*se
 				group.FieldProfitControlEnabled,
 				group.FieldProfitMinMargin,
 				group.FieldProfitSafetyBuffer,
+				group.FieldResponseCacheEnabled,
+				group.FieldResponseCacheTTLSeconds,
+				group.FieldResponseCacheHitMultiplier,
+				group.FieldVolumeTiers,
+				group.FieldAllowGeminiNative,
+				group.FieldHedgePolicy,
+				group.FieldMirrorPolicy,
 			)
 		}).
 		Only(ctx)
@@ -267,6 +287,11 @@ func (r *apiKeyRepository) Update(ctx context.Context, key *service.APIKey, fiel
 			SetRateLimit1d(key.RateLimit1d).
 			SetRateLimit7d(key.RateLimit7d)
 	}
+	if fields.Throttle {
+		builder.
+			SetRpmLimit(key.RPMLimit).
+			SetMaxConcurrency(key.MaxConcurrency)
+	}
 	if fields.RateLimitUsage {
 		builder.
 			SetUsage5h(key.Usage5h).
@@ -321,6 +346,20 @@ func (r *apiKeyRepository) Update(ctx context.Context, key *service.APIKey, fiel
 		}
 	}
 
+	// 模型限制字段
+	if fields.ModelRules {
+		if len(key.ModelAllowlist) > 0 {
+			builder.SetModelAllowlist(key.ModelAllowlist)
+		} else {
+			builder.ClearModelAllowlist()
+		}
+		if len(key.ModelDenylist) > 0 {
+			builder.SetModelDenylist(key.ModelDenylist)
+		} else {
+			builder.ClearModelDenylist()
+		}
+	}
+
 	affected, err := builder.Save(ctx)
 	if err != nil {
 		return err
@@ -864,29 +903,33 @@ func apiKeyEntityToService(m *dbent.APIKey) *service.APIKey {
 		return nil
 	}
 	out := &service.APIKey{
-		ID:            m.ID,
-		UserID:        m.UserID,
-		Key:           m.Key,
-		Name:          m.Name,
-		Status:        m.Status,
-		IPWhitelist:   m.IPWhitelist,
-		IPBlacklist:   m.IPBlacklist,
-		LastUsedAt:    m.LastUsedAt,
-		CreatedAt:     m.CreatedAt,
-		UpdatedAt:     m.UpdatedAt,
-		GroupID:       m.GroupID,
-		Quota:         m.Quota,
-		QuotaUsed:     m.QuotaUsed,
-		ExpiresAt:     m.ExpiresAt,
-		RateLimit5h:   m.RateLimit5h,
-		RateLimit1d:   m.RateLimit1d,
-		RateLimit7d:   m.RateLimit7d,
-		Usage5h:       m.Usage5h,
-		Usage1d:       m.Usage1d,
-		Usage7d:       m.Usage7d,
-		Window5hStart: m.Window5hStart,
-		Window1dStart: m.Window1dStart,
-		Window7dStart: m.Window7dStart,
+		ID:             m.ID,
+		UserID:         m.UserID,
+		Key:            m.Key,
+		Name:           m.Name,
+		Status:         m.Status,
+		IPWhitelist:    m.IPWhitelist,
+		IPBlacklist:    m.IPBlacklist,
+		ModelAllowlist: m.ModelAllowlist,
+		ModelDenylist:  m.ModelDenylist,
+		LastUsedAt:     m.LastUsedAt,
+		CreatedAt:      m.CreatedAt,
+		UpdatedAt:      m.UpdatedAt,
+		GroupID:        m.GroupID,
+		Quota:          m.Quota,
+		QuotaUsed:      m.QuotaUsed,
+		ExpiresAt:      m.ExpiresAt,
+		RateLimit5h:    m.RateLimit5h,
+		RateLimit1d:    m.RateLimit1d,
+		RateLimit7d:    m.RateLimit7d,
+		Usage5h:        m.Usage5h,
+		Usage1d:        m.Usage1d,
+		Usage7d:        m.Usage7d,
+		Window5hStart:  m.Window5hStart,
+		Window1dStart:  m.Window1dStart,
+		Window7dStart:  m.Window7dStart,
+		RPMLimit:       m.RpmLimit,
+		MaxConcurrency: m.MaxConcurrency,
 	}
 	if m.Edges.User != nil {
 		out.User = userEntityToService(m.Edges.User)
@@ -931,6 +974,7 @@ func userEntityToService(u *dbent.User) *service.User {
 		BalanceNotifyThreshold:     u.BalanceNotifyThreshold,
 		TotalRecharged:             u.TotalRecharged,
 		RPMLimit:                   u.RpmLimit,
+		CreditLimit:                u.CreditLimit,
 		CreatedAt:                  u.CreatedAt,
 		UpdatedAt:                  u.UpdatedAt,
 		DeletedAt:                  u.DeletedAt,
@@ -1006,6 +1050,13 @@ func groupEntityToService(g *dbent.Group) *service.Group {
 		ProfitControlEnabled:            g.ProfitControlEnabled,
 		ProfitMinMargin:                 g.ProfitMinMargin,
 		ProfitSafetyBuffer:              g.ProfitSafetyBuffer,
+		ResponseCacheEnabled:            g.ResponseCacheEnabled,
+		ResponseCacheTTLSeconds:         g.ResponseCacheTTLSeconds,
+		ResponseCacheHitMultiplier:      g.ResponseCacheHitMultiplier,
+		VolumeTiers:                     g.VolumeTiers,
+		AllowGeminiNative:               g.AllowGeminiNative,
+		HedgePolicy:                     g.HedgePolicy,
+		MirrorPolicy:                    g.MirrorPolicy,
 		CreatedAt:                       g.CreatedAt,
 		UpdatedAt:                       g.UpdatedAt,
 	}
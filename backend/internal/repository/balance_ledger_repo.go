@@ -0,0 +1,334 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"entgo.io/ent/dialect"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/lib/pq"
+)
+
+type balanceLedgerRepository struct {
+	db *sql.DB
+}
+
+func NewBalanceLedgerRepository(db *sql.DB) service.BalanceLedgerRepository {
+	return &balanceLedgerRepository{db: db}
+}
+
+// balanceLedgerTagExecer 同时适配 ent Client 与 *sql.Tx。
+type balanceLedgerTagExecer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// applyBalanceLedgerTag 把账本标签写入当前事务，供 trg_users_balance_ledger / trg_organizations_balance_ledger 读取。
+// ent Client 按其驱动方言决定是否写入；裸 *sql.Tx 只出现在 PostgreSQL 专用路径上。
+func applyBalanceLedgerTag(ctx context.Context, exec balanceLedgerTagExecer, tag service.BalanceLedgerTag) error {
+	dialectName := dialect.Postgres
+	if d, ok := exec.(interface{ Driver() dialect.Driver }); ok {
+		dialectName = d.Driver().Dialect()
+	}
+	query, args := service.BalanceLedgerTagStatement(dialectName, tag)
+	if query == "" {
+		return nil
+	}
+	_, err := exec.ExecContext(ctx, query, args...)
+	return err
+}
+
+func (r *balanceLedgerRepository) ListEntries(ctx context.Context, filter service.BalanceLedgerFilter) (*service.BalanceLedgerList, error) {
+	conds := []string{"e.user_id = $1"}
+	args := []any{filter.UserID}
+	if filter.OrganizationID > 0 {
+		conds[0] = "e.organization_id = $1"
+		args[0] = filter.OrganizationID
+	}
+	if filter.EntryType != "" {
+		args = append(args, filter.EntryType)
+		conds = append(conds, "e.entry_type = $"+itoa(len(args)))
+	}
+	if filter.StartTime != nil {
+		args = append(args, *filter.StartTime)
+		conds = append(conds, "e.created_at >= $"+itoa(len(args)))
+	}
+	if filter.EndTime != nil {
+		args = append(args, *filter.EndTime)
+		conds = append(conds, "e.created_at < $"+itoa(len(args)))
+	}
+	where := "WHERE " + strings.Join(conds, " AND ")
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM balance_ledger_entries e "+where, args...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	args = append(args, filter.PageSize, (filter.Page-1)*filter.PageSize)
+	rows, err := r.db.QueryContext(ctx, `
+SELECT e.id, COALESCE(e.user_id, 0), e.organization_id, e.entry_type, e.balance_delta, e.frozen_delta, e.balance_after, e.frozen_balance_after,
+    e.reference_type, e.reference_id, e.note, e.created_at
+FROM balance_ledger_entries e
+`+where+`
+ORDER BY e.id DESC
+LIMIT $`+itoa(len(args)-1)+` OFFSET $`+itoa(len(args)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	entries := make([]service.BalanceLedgerEntry, 0, filter.PageSize)
+	index := make(map[int64]int)
+	ids := make([]int64, 0, filter.PageSize)
+	for rows.Next() {
+		var (
+			e              service.BalanceLedgerEntry
+			organizationID sql.NullInt64
+		)
+		if err := rows.Scan(&e.ID, &e.UserID, &organizationID, &e.EntryType, &e.BalanceDelta, &e.FrozenDelta, &e.BalanceAfter,
+			&e.FrozenBalanceAfter, &e.ReferenceType, &e.ReferenceID, &e.Note, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		e.OrganizationID = balanceLedgerNullInt64Ptr(organizationID)
+		index[e.ID] = len(entries)
+		ids = append(ids, e.ID)
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(ids) > 0 {
+		if err := r.attachPostings(ctx, entries, index, ids); err != nil {
+			return nil, err
+		}
+	}
+	return &service.BalanceLedgerList{Entries: entries, Total: total, Page: filter.Page, PageSize: filter.PageSize}, nil
+}
+
+func (r *balanceLedgerRepository) attachPostings(ctx context.Context, entries []service.BalanceLedgerEntry, index map[int64]int, ids []int64) error {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT entry_id, account, user_id, organization_id, amount
+FROM balance_ledger_postings
+WHERE entry_id = ANY($1)
+ORDER BY id`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var (
+			entryID        int64
+			p              service.BalanceLedgerPosting
+			userID         sql.NullInt64
+			organizationID sql.NullInt64
+		)
+		if err := rows.Scan(&entryID, &p.Account, &userID, &organizationID, &p.Amount); err != nil {
+			return err
+		}
+		p.UserID = balanceLedgerNullInt64Ptr(userID)
+		p.OrganizationID = balanceLedgerNullInt64Ptr(organizationID)
+		if i, ok := index[entryID]; ok {
+			entries[i].Postings = append(entries[i].Postings, p)
+		}
+	}
+	return rows.Err()
+}
+
+func (r *balanceLedgerRepository) Reconcile(ctx context.Context) (*service.BalanceReconciliation, error) {
+	run := &service.BalanceReconciliation{Status: service.BalanceReconciliationStatusRunning}
+	if err := r.db.QueryRowContext(ctx, `
+INSERT INTO balance_ledger_reconciliations (status) VALUES ($1)
+RETURNING id, started_at`, run.Status).Scan(&run.ID, &run.StartedAt); err != nil {
+		return nil, err
+	}
+
+	if err := r.reconcileSnapshot(ctx, run); err != nil {
+		// 用独立 context 落失败状态：原 ctx 可能正是因超时而失败。
+		finishCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+		_, _ = r.db.ExecContext(finishCtx, `
+UPDATE balance_ledger_reconciliations
+SET status = $2, error_message = $3, finished_at = NOW()
+WHERE id = $1`, run.ID, service.BalanceReconciliationStatusFailed, err.Error())
+		return run, err
+	}
+	return run, nil
+}
+
+// reconcileSnapshot 在同一个 REPEATABLE READ 快照内完成比对与汇总：
+// 账本由触发器与余额变更同事务写入，快照内两者必然一致，差异只可能来自绕过触发器的写入。
+func (r *balanceLedgerRepository) reconcileSnapshot(ctx context.Context, run *service.BalanceReconciliation) error {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.ExecContext(ctx, `
+INSERT INTO balance_ledger_mismatches
+    (reconciliation_id, user_id, balance, ledger_balance, frozen_balance, ledger_frozen_balance)
+SELECT $1, u.id, u.balance, COALESCE(l.available, 0), COALESCE(u.frozen_balance, 0), COALESCE(l.frozen, 0)
+FROM users u
+LEFT JOIN (
+    SELECT user_id,
+        SUM(amount) FILTER (WHERE account = 'user_available') AS available,
+        SUM(amount) FILTER (WHERE account = 'user_frozen') AS frozen
+    FROM balance_ledger_postings
+    WHERE user_id IS NOT NULL
+    GROUP BY user_id
+) l ON l.user_id = u.id
+WHERE u.balance <> COALESCE(l.available, 0)
+    OR COALESCE(u.frozen_balance, 0) <> COALESCE(l.frozen, 0)`, run.ID)
+	if err != nil {
+		return err
+	}
+	mismatched, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	run.MismatchedUsers = int(mismatched)
+
+	res, err = tx.ExecContext(ctx, `
+INSERT INTO balance_ledger_mismatches
+    (reconciliation_id, organization_id, balance, ledger_balance, frozen_balance, ledger_frozen_balance)
+SELECT $1, o.id, o.balance, COALESCE(l.available, 0), o.frozen_balance, COALESCE(l.frozen, 0)
+FROM organizations o
+LEFT JOIN (
+    SELECT organization_id,
+        SUM(amount) FILTER (WHERE account = 'organization_available') AS available,
+        SUM(amount) FILTER (WHERE account = 'organization_frozen') AS frozen
+    FROM balance_ledger_postings
+    WHERE organization_id IS NOT NULL
+    GROUP BY organization_id
+) l ON l.organization_id = o.id
+WHERE o.balance <> COALESCE(l.available, 0)
+    OR o.frozen_balance <> COALESCE(l.frozen, 0)`, run.ID)
+	if err != nil {
+		return err
+	}
+	mismatched, err = res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	run.MismatchedOrganizations = int(mismatched)
+
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM users`).Scan(&run.UsersChecked); err != nil {
+		return err
+	}
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM organizations`).Scan(&run.OrganizationsChecked); err != nil {
+		return err
+	}
+	if err := tx.QueryRowContext(ctx, `
+SELECT COUNT(*) FROM (
+    SELECT entry_id FROM balance_ledger_postings GROUP BY entry_id HAVING SUM(amount) <> 0
+) unbalanced`).Scan(&run.UnbalancedEntries); err != nil {
+		return err
+	}
+
+	run.Status = service.BalanceReconciliationStatusCompleted
+	var finishedAt time.Time
+	if err := tx.QueryRowContext(ctx, `
+UPDATE balance_ledger_reconciliations
+SET status = $2, users_checked = $3, mismatched_users = $4, unbalanced_entries = $5,
+    organizations_checked = $6, mismatched_organizations = $7, finished_at = NOW()
+WHERE id = $1
+RETURNING finished_at`, run.ID, run.Status, run.UsersChecked, run.MismatchedUsers, run.UnbalancedEntries,
+		run.OrganizationsChecked, run.MismatchedOrganizations).Scan(&finishedAt); err != nil {
+		return err
+	}
+	run.FinishedAt = &finishedAt
+	return tx.Commit()
+}
+
+const balanceReconciliationColumns = `id, status, users_checked, mismatched_users, organizations_checked, mismatched_organizations,
+    unbalanced_entries, error_message, started_at, finished_at`
+
+func scanBalanceReconciliation(row interface{ Scan(dest ...any) error }) (*service.BalanceReconciliation, error) {
+	var (
+		run        service.BalanceReconciliation
+		finishedAt sql.NullTime
+	)
+	if err := row.Scan(&run.ID, &run.Status, &run.UsersChecked, &run.MismatchedUsers, &run.OrganizationsChecked,
+		&run.MismatchedOrganizations, &run.UnbalancedEntries,
+		&run.ErrorMessage, &run.StartedAt, &finishedAt); err != nil {
+		return nil, err
+	}
+	if finishedAt.Valid {
+		run.FinishedAt = &finishedAt.Time
+	}
+	return &run, nil
+}
+
+func (r *balanceLedgerRepository) ListReconciliations(ctx context.Context, page, pageSize int) ([]service.BalanceReconciliation, int64, error) {
+	var total int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM balance_ledger_reconciliations`).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := r.db.QueryContext(ctx, `
+SELECT `+balanceReconciliationColumns+`
+FROM balance_ledger_reconciliations
+ORDER BY id DESC
+LIMIT $1 OFFSET $2`, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.BalanceReconciliation, 0, pageSize)
+	for rows.Next() {
+		run, err := scanBalanceReconciliation(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		out = append(out, *run)
+	}
+	return out, total, rows.Err()
+}
+
+func (r *balanceLedgerRepository) GetReconciliation(ctx context.Context, id int64) (*service.BalanceReconciliation, error) {
+	run, err := scanBalanceReconciliation(r.db.QueryRowContext(ctx, `
+SELECT `+balanceReconciliationColumns+`
+FROM balance_ledger_reconciliations
+WHERE id = $1`, id))
+	if err != nil {
+		return nil, translatePersistenceError(err, service.ErrBalanceReconciliationNotFound, nil)
+	}
+	return run, nil
+}
+
+func (r *balanceLedgerRepository) ListMismatches(ctx context.Context, reconciliationID int64) ([]service.BalanceMismatch, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT COALESCE(m.user_id, 0), m.organization_id, COALESCE(u.email, ''), m.balance, m.ledger_balance,
+    m.frozen_balance, m.ledger_frozen_balance, m.created_at
+FROM balance_ledger_mismatches m
+LEFT JOIN users u ON u.id = m.user_id
+WHERE m.reconciliation_id = $1
+ORDER BY m.organization_id NULLS FIRST, m.user_id`, reconciliationID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.BalanceMismatch, 0)
+	for rows.Next() {
+		var (
+			m              service.BalanceMismatch
+			organizationID sql.NullInt64
+		)
+		if err := rows.Scan(&m.UserID, &organizationID, &m.Email, &m.Balance, &m.LedgerBalance, &m.FrozenBalance, &m.LedgerFrozenBalance, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		m.OrganizationID = balanceLedgerNullInt64Ptr(organizationID)
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
+
+func balanceLedgerNullInt64Ptr(v sql.NullInt64) *int64 {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Int64
+}
@@ -0,0 +1,52 @@
+//go:build unit
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+func TestBalanceLedgerReconcile_ChecksOrganizationWallets(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	startedAt := time.Now()
+	finishedAt := startedAt.Add(time.Second)
+	mock.ExpectQuery(`INSERT INTO balance_ledger_reconciliations`).
+		WithArgs(service.BalanceReconciliationStatusRunning).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "started_at"}).AddRow(int64(5), startedAt))
+	mock.ExpectBegin()
+	mock.ExpectExec(`(?s)INSERT INTO balance_ledger_mismatches\s+\(reconciliation_id, user_id,.+FROM users u`).
+		WithArgs(int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`(?s)INSERT INTO balance_ledger_mismatches\s+\(reconciliation_id, organization_id,.+FROM organizations o.+account = 'organization_available'`).
+		WithArgs(int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM users`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(10))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM organizations`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectQuery(`(?s)SELECT COUNT\(\*\) FROM \(\s+SELECT entry_id FROM balance_ledger_postings`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(`(?s)UPDATE balance_ledger_reconciliations.+organizations_checked = \$6, mismatched_organizations = \$7`).
+		WithArgs(int64(5), service.BalanceReconciliationStatusCompleted, 10, 1, 0, 3, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"finished_at"}).AddRow(finishedAt))
+	mock.ExpectCommit()
+
+	repo := &balanceLedgerRepository{db: db}
+	run, err := repo.Reconcile(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, run.MismatchedUsers)
+	require.Equal(t, 3, run.OrganizationsChecked)
+	require.Equal(t, 2, run.MismatchedOrganizations)
+	require.Equal(t, service.BalanceReconciliationStatusCompleted, run.Status)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
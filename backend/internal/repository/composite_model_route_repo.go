@@ -41,7 +41,7 @@ func (r *compositeModelRouteRepository) Create(ctx context.Context, route *servi
 	if route == nil {
 		return service.ErrCompositeRouteNotFound
 	}
-	created, err := clientFromContext(ctx, r.client).CompositeModelRoute.Create().
+	builder := clientFromContext(ctx, r.client).CompositeModelRoute.Create().
 		SetGroupID(route.GroupID).
 		SetPublicModel(route.PublicModel).
 		SetMatchType(route.MatchType).
@@ -50,8 +50,11 @@ func (r *compositeModelRouteRepository) Create(ctx context.Context, route *servi
 		SetEndpoint(route.Endpoint).
 		SetPriority(route.Priority).
 		SetEnabled(route.Enabled).
-		SetNotes(route.Notes).
-		Save(ctx)
+		SetNotes(route.Notes)
+	if route.Split != nil {
+		builder.SetSplit(route.Split)
+	}
+	created, err := builder.Save(ctx)
 	if err != nil {
 		return translatePersistenceError(err, nil, service.ErrCompositeRouteExists)
 	}
@@ -63,7 +66,7 @@ func (r *compositeModelRouteRepository) Update(ctx context.Context, route *servi
 	if route == nil {
 		return service.ErrCompositeRouteNotFound
 	}
-	updated, err := clientFromContext(ctx, r.client).CompositeModelRoute.UpdateOneID(route.ID).
+	builder := clientFromContext(ctx, r.client).CompositeModelRoute.UpdateOneID(route.ID).
 		SetPublicModel(route.PublicModel).
 		SetMatchType(route.MatchType).
 		SetTargetPlatform(route.TargetPlatform).
@@ -71,8 +74,13 @@ func (r *compositeModelRouteRepository) Update(ctx context.Context, route *servi
 		SetEndpoint(route.Endpoint).
 		SetPriority(route.Priority).
 		SetEnabled(route.Enabled).
-		SetNotes(route.Notes).
-		Save(ctx)
+		SetNotes(route.Notes)
+	if route.Split != nil {
+		builder.SetSplit(route.Split)
+	} else {
+		builder.ClearSplit()
+	}
+	updated, err := builder.Save(ctx)
 	if err != nil {
 		return translatePersistenceError(err, service.ErrCompositeRouteNotFound, service.ErrCompositeRouteExists)
 	}
@@ -107,6 +115,7 @@ func compositeModelRouteEntityToService(row *dbent.CompositeModelRoute) *service
 		Priority:       row.Priority,
 		Enabled:        row.Enabled,
 		Notes:          derefString(row.Notes),
+		Split:          row.Split,
 		CreatedAt:      row.CreatedAt,
 		UpdatedAt:      row.UpdatedAt,
 	}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type compositeRouteExperimentRepository struct {
+	db *sql.DB
+}
+
+func NewCompositeRouteExperimentRepository(db *sql.DB) service.CompositeRouteExperimentRepository {
+	return &compositeRouteExperimentRepository{db: db}
+}
+
+func (r *compositeRouteExperimentRepository) InsertAssignments(ctx context.Context, assignments []service.CompositeRouteAssignment) error {
+	if len(assignments) == 0 {
+		return nil
+	}
+	const cols = 8
+	var sb strings.Builder
+	sb.WriteString(`INSERT INTO composite_route_experiment_assignments
+    (client_request_id, group_id, route_id, experiment, arm, target_platform, upstream_model, created_at)
+VALUES `)
+	args := make([]any, 0, len(assignments)*cols)
+	for i, a := range assignments {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		base := i * cols
+		fmt.Fprintf(&sb, "($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)", base+1, base+2, base+3, base+4, base+5, base+6, base+7, base+8)
+		args = append(args, a.ClientRequestID, a.GroupID, a.RouteID, a.Experiment, a.Arm, a.TargetPlatform, a.UpstreamModel, a.CreatedAt)
+	}
+	_, err := r.db.ExecContext(ctx, sb.String(), args...)
+	return err
+}
+
+// ListArmStats 关联 usage_logs（request_id = 'client:' || client_request_id）与 ops_error_logs：
+// 有用量记录的请求计为成功，没有用量但有错误日志的计为失败，两者都没有的（如客户端中断）只计入 assigned。
+func (r *compositeRouteExperimentRepository) ListArmStats(ctx context.Context, routeID int64, experiment string, start, end time.Time) ([]service.CompositeRouteArmStat, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT
+    a.arm,
+    COUNT(*) AS assigned,
+    COUNT(u.request_id) AS success_count,
+    COUNT(*) FILTER (
+        WHERE u.request_id IS NULL AND EXISTS (
+            SELECT 1 FROM ops_error_logs e
+            WHERE e.client_request_id = a.client_request_id
+              AND e.created_at >= a.created_at - INTERVAL '1 minute'
+        )
+    ) AS error_count,
+    COALESCE(AVG(u.duration_ms), 0) AS avg_duration_ms,
+    COALESCE(AVG(u.first_token_ms), 0) AS avg_first_token_ms,
+    COALESCE(SUM(u.input_tokens), 0) AS input_tokens,
+    COALESCE(SUM(u.output_tokens), 0) AS output_tokens,
+    COALESCE(SUM(u.total_cost), 0) AS total_cost,
+    COALESCE(SUM(u.actual_cost), 0) AS actual_cost
+FROM composite_route_experiment_assignments a
+LEFT JOIN LATERAL (
+    SELECT ul.request_id, ul.duration_ms, ul.first_token_ms, ul.input_tokens, ul.output_tokens, ul.total_cost, ul.actual_cost
+    FROM usage_logs ul
+    WHERE ul.request_id = 'client:' || a.client_request_id
+      AND ul.created_at >= a.created_at - INTERVAL '1 minute'
+    ORDER BY ul.id
+    LIMIT 1
+) u ON TRUE
+WHERE a.route_id = $1 AND a.experiment = $2 AND a.created_at >= $3 AND a.created_at < $4
+GROUP BY a.arm
+ORDER BY a.arm`, routeID, experiment, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var out []service.CompositeRouteArmStat
+	for rows.Next() {
+		var stat service.CompositeRouteArmStat
+		if err := rows.Scan(
+			&stat.Arm,
+			&stat.Assigned,
+			&stat.SuccessCount,
+			&stat.ErrorCount,
+			&stat.AvgDurationMs,
+			&stat.AvgFirstTokenMs,
+			&stat.InputTokens,
+			&stat.OutputTokens,
+			&stat.TotalCost,
+			&stat.ActualCost,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, stat)
+	}
+	return out, rows.Err()
+}
+
+func (r *compositeRouteExperimentRepository) DeleteAssignmentsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM composite_route_experiment_assignments WHERE created_at < $1`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -745,6 +745,16 @@ func (c *concurrencyCache) TrackAPIKeySlot(ctx context.Context, apiKeyID int64,
 	return err
 }
 
+// AcquireAPIKeySlot 与 AcquireUserSlot 共用占槽脚本，对 API Key 维度施加并发上限；
+// 与 TrackAPIKeySlot 写入同一有序集合，因此列表中的实时并发统计保持一致。
+func (c *concurrencyCache) AcquireAPIKeySlot(ctx context.Context, apiKeyID int64, maxConcurrency int, requestID string) (bool, error) {
+	result, _, err := runScriptInt64Pair(ctx, c.rdb, acquireScript, []string{apiKeySlotKey(apiKeyID), liveAPIKeySlotKey(apiKeyID)}, maxConcurrency, c.slotTTLSeconds, requestID)
+	if err != nil {
+		return false, err
+	}
+	return result == 1, nil
+}
+
 func (c *concurrencyCache) ReleaseAPIKeySlot(ctx context.Context, apiKeyID int64, requestID string) error {
 	key := apiKeySlotKey(apiKeyID)
 	return c.rdb.ZRem(ctx, key, requestID).Err()
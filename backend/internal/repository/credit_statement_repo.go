@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/lib/pq"
+)
+
+type creditStatementRepository struct {
+	db *sql.DB
+}
+
+func NewCreditStatementRepository(db *sql.DB) service.CreditStatementRepository {
+	return &creditStatementRepository{db: db}
+}
+
+const creditStatementColumns = `s.id, s.user_id, COALESCE(u.email, ''), s.period_start, s.period_end, s.request_count,
+    s.total_cost, s.credit_limit, s.closing_balance, s.amount_due, s.due_at, s.status, s.lines,
+    s.paid_at, s.overdue_at, s.created_at`
+
+type creditStatementScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanCreditStatement(row creditStatementScanner) (*service.CreditStatement, error) {
+	var (
+		st        service.CreditStatement
+		lines     []byte
+		paidAt    sql.NullTime
+		overdueAt sql.NullTime
+	)
+	if err := row.Scan(&st.ID, &st.UserID, &st.UserEmail, &st.PeriodStart, &st.PeriodEnd, &st.RequestCount,
+		&st.TotalCost, &st.CreditLimit, &st.ClosingBalance, &st.AmountDue, &st.DueAt, &st.Status, &lines,
+		&paidAt, &overdueAt, &st.CreatedAt); err != nil {
+		return nil, err
+	}
+	if len(lines) > 0 {
+		if err := json.Unmarshal(lines, &st.Lines); err != nil {
+			return nil, err
+		}
+	}
+	if paidAt.Valid {
+		st.PaidAt = &paidAt.Time
+	}
+	if overdueAt.Valid {
+		st.OverdueAt = &overdueAt.Time
+	}
+	return &st, nil
+}
+
+func (r *creditStatementRepository) ListCreditUsers(ctx context.Context, asOf time.Time) ([]service.CreditLineUser, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT u.id, u.email, u.username,
+    u.balance - COALESCE((
+        SELECT SUM(e.balance_delta)
+        FROM balance_ledger_entries e
+        WHERE e.user_id = u.id AND e.created_at >= $1 AND e.entry_type <> $2
+    ), 0),
+    u.credit_limit
+FROM users u
+WHERE u.credit_limit > 0 AND u.deleted_at IS NULL
+ORDER BY u.id`, asOf, service.BalanceLedgerTypeOpeningBalance)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var users []service.CreditLineUser
+	for rows.Next() {
+		var u service.CreditLineUser
+		if err := rows.Scan(&u.ID, &u.Email, &u.Username, &u.Balance, &u.CreditLimit); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+func (r *creditStatementRepository) SummarizeUsage(ctx context.Context, userID int64, start, end time.Time) ([]service.CreditStatementLine, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT ul.group_id, COALESCE(g.name, ''), ul.model, COUNT(*),
+    COALESCE(SUM(ul.input_tokens), 0), COALESCE(SUM(ul.output_tokens), 0), COALESCE(SUM(ul.actual_cost), 0)
+FROM usage_logs ul
+LEFT JOIN groups g ON g.id = ul.group_id
+WHERE ul.user_id = $1 AND ul.billing_type = $2 AND ul.created_at >= $3 AND ul.created_at < $4
+GROUP BY ul.group_id, g.name, ul.model
+ORDER BY SUM(ul.actual_cost) DESC, ul.model`, userID, service.BillingTypeBalance, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	lines := make([]service.CreditStatementLine, 0)
+	for rows.Next() {
+		var (
+			line    service.CreditStatementLine
+			groupID sql.NullInt64
+		)
+		if err := rows.Scan(&groupID, &line.GroupName, &line.Model, &line.Requests,
+			&line.InputTokens, &line.OutputTokens, &line.Cost); err != nil {
+			return nil, err
+		}
+		if groupID.Valid {
+			line.GroupID = &groupID.Int64
+		}
+		lines = append(lines, line)
+	}
+	return lines, rows.Err()
+}
+
+func (r *creditStatementRepository) Create(ctx context.Context, st *service.CreditStatement) (*service.CreditStatement, error) {
+	lines := st.Lines
+	if lines == nil {
+		lines = []service.CreditStatementLine{}
+	}
+	payload, err := json.Marshal(lines)
+	if err != nil {
+		return nil, err
+	}
+	var id int64
+	err = r.db.QueryRowContext(ctx, `
+INSERT INTO credit_statements (user_id, period_start, period_end, request_count, total_cost, credit_limit,
+    closing_balance, amount_due, due_at, status, lines, paid_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+ON CONFLICT (user_id, period_start) DO NOTHING
+RETURNING id`,
+		st.UserID, st.PeriodStart, st.PeriodEnd, st.RequestCount, st.TotalCost, st.CreditLimit,
+		st.ClosingBalance, st.AmountDue, st.DueAt, st.Status, payload, st.PaidAt,
+	).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, id)
+}
+
+func (r *creditStatementRepository) GetByID(ctx context.Context, id int64) (*service.CreditStatement, error) {
+	st, err := scanCreditStatement(r.db.QueryRowContext(ctx, `
+SELECT `+creditStatementColumns+`
+FROM credit_statements s
+LEFT JOIN users u ON u.id = s.user_id
+WHERE s.id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrCreditStatementNotFound
+	}
+	return st, err
+}
+
+func (r *creditStatementRepository) List(ctx context.Context, filter service.CreditStatementFilter) ([]service.CreditStatement, int64, error) {
+	conds := []string{"TRUE"}
+	args := []any{}
+	if filter.UserID > 0 {
+		args = append(args, filter.UserID)
+		conds = append(conds, "s.user_id = $"+itoa(len(args)))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conds = append(conds, "s.status = $"+itoa(len(args)))
+	}
+	where := "WHERE " + strings.Join(conds, " AND ")
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM credit_statements s "+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, filter.PageSize, (filter.Page-1)*filter.PageSize)
+	rows, err := r.db.QueryContext(ctx, `
+SELECT `+creditStatementColumns+`
+FROM credit_statements s
+LEFT JOIN users u ON u.id = s.user_id
+`+where+`
+ORDER BY s.period_start DESC, s.id DESC
+LIMIT $`+itoa(len(args)-1)+` OFFSET $`+itoa(len(args)), args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = rows.Close() }()
+	items := make([]service.CreditStatement, 0, filter.PageSize)
+	for rows.Next() {
+		st, err := scanCreditStatement(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		items = append(items, *st)
+	}
+	return items, total, rows.Err()
+}
+
+func (r *creditStatementRepository) ListOpen(ctx context.Context) ([]service.CreditStatement, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT `+creditStatementColumns+`
+FROM credit_statements s
+LEFT JOIN users u ON u.id = s.user_id
+WHERE s.status IN ($1, $2)
+ORDER BY s.user_id, s.period_start`, service.CreditStatementStatusIssued, service.CreditStatementStatusOverdue)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var items []service.CreditStatement
+	for rows.Next() {
+		st, err := scanCreditStatement(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *st)
+	}
+	return items, rows.Err()
+}
+
+func (r *creditStatementRepository) PaymentsSince(ctx context.Context, userID int64, since time.Time) (float64, error) {
+	var payments float64
+	err := r.db.QueryRowContext(ctx, `
+SELECT COALESCE(SUM(balance_delta), 0)
+FROM balance_ledger_entries
+WHERE user_id = $1 AND created_at >= $2 AND entry_type = ANY($3)`, userID, since, pq.Array(service.CreditStatementPaymentLedgerTypes)).Scan(&payments)
+	return payments, err
+}
+
+func (r *creditStatementRepository) MarkPaid(ctx context.Context, id int64, paidAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+UPDATE credit_statements SET status = $2, paid_at = $3, updated_at = NOW()
+WHERE id = $1 AND status <> $2`, id, service.CreditStatementStatusPaid, paidAt)
+	return err
+}
+
+func (r *creditStatementRepository) MarkOverdue(ctx context.Context, id int64, overdueAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+UPDATE credit_statements SET status = $2, overdue_at = $3, updated_at = NOW()
+WHERE id = $1 AND status = $4`, id, service.CreditStatementStatusOverdue, overdueAt, service.CreditStatementStatusIssued)
+	return err
+}
+
+func (r *creditStatementRepository) HasOverdue(ctx context.Context, userID int64) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `
+SELECT EXISTS (SELECT 1 FROM credit_statements WHERE user_id = $1 AND status = $2)`,
+		userID, service.CreditStatementStatusOverdue).Scan(&exists)
+	return exists, err
+}
+
+func (r *creditStatementRepository) TransitionAPIKeys(ctx context.Context, userID int64, from, to string) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+UPDATE api_keys SET status = $3, updated_at = NOW()
+WHERE user_id = $1 AND status = $2 AND deleted_at IS NULL`, userID, from, to)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
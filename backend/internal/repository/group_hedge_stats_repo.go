@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type groupHedgeStatsRepository struct {
+	db *sql.DB
+}
+
+func NewGroupHedgeStatsRepository(db *sql.DB) service.GroupHedgeStatsRepository {
+	return &groupHedgeStatsRepository{db: db}
+}
+
+func (r *groupHedgeStatsRepository) RecordOutcome(ctx context.Context, day time.Time, outcome service.GroupHedgeOutcome) error {
+	hedgeWin, primaryWin := 0, 0
+	switch outcome.Winner {
+	case service.HedgeWinnerHedge:
+		hedgeWin = 1
+	case service.HedgeWinnerPrimary:
+		primaryWin = 1
+	}
+	_, err := r.db.ExecContext(ctx, `
+INSERT INTO group_hedge_stats (group_id, stat_date, hedged_count, hedge_win_count, primary_win_count, hedge_cost, updated_at)
+VALUES ($1, $2::date, 1, $3, $4, $5, NOW())
+ON CONFLICT (group_id, stat_date) DO UPDATE SET
+    hedged_count = group_hedge_stats.hedged_count + 1,
+    hedge_win_count = group_hedge_stats.hedge_win_count + EXCLUDED.hedge_win_count,
+    primary_win_count = group_hedge_stats.primary_win_count + EXCLUDED.primary_win_count,
+    hedge_cost = group_hedge_stats.hedge_cost + EXCLUDED.hedge_cost,
+    updated_at = NOW()`,
+		outcome.GroupID, day.Format("2006-01-02"), hedgeWin, primaryWin, outcome.HedgeCost)
+	return err
+}
+
+func (r *groupHedgeStatsRepository) ListDaily(ctx context.Context, groupID int64, from, to time.Time) ([]service.GroupHedgeDailyStat, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT stat_date, hedged_count, hedge_win_count, primary_win_count, hedge_cost
+FROM group_hedge_stats
+WHERE group_id = $1 AND stat_date >= $2::date AND stat_date <= $3::date
+ORDER BY stat_date ASC`, groupID, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var out []service.GroupHedgeDailyStat
+	for rows.Next() {
+		var (
+			day  time.Time
+			stat service.GroupHedgeDailyStat
+		)
+		if err := rows.Scan(&day, &stat.HedgedCount, &stat.HedgeWinCount, &stat.PrimaryWinCount, &stat.HedgeCost); err != nil {
+			return nil, err
+		}
+		stat.Date = day.Format("2006-01-02")
+		out = append(out, stat)
+	}
+	return out, rows.Err()
+}
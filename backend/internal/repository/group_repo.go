@@ -110,7 +110,14 @@ func createGroupRecord(ctx context.Context, client *dbent.Client, groupIn *servi
 		SetPeakRateMultiplier(groupIn.PeakRateMultiplier).
 		SetProfitControlEnabled(groupIn.ProfitControlEnabled).
 		SetProfitMinMargin(groupIn.ProfitMinMargin).
-		SetProfitSafetyBuffer(groupIn.ProfitSafetyBuffer)
+		SetProfitSafetyBuffer(groupIn.ProfitSafetyBuffer).
+		SetResponseCacheEnabled(groupIn.ResponseCacheEnabled).
+		SetResponseCacheTTLSeconds(groupIn.ResponseCacheTTLSeconds).
+		SetResponseCacheHitMultiplier(groupIn.ResponseCacheHitMultiplier).
+		SetVolumeTiers(groupIn.VolumeTiers).
+		SetAllowGeminiNative(groupIn.AllowGeminiNative).
+		SetHedgePolicy(groupIn.HedgePolicy).
+		SetMirrorPolicy(groupIn.MirrorPolicy)
 	if groupIn.DuplicateOperationID != "" {
 		builder = builder.SetDuplicateOperationID(groupIn.DuplicateOperationID)
 	}
@@ -280,7 +287,14 @@ func (r *groupRepository) Update(ctx context.Context, groupIn *service.Group) er
 		SetPeakRateMultiplier(groupIn.PeakRateMultiplier).
 		SetProfitControlEnabled(groupIn.ProfitControlEnabled).
 		SetProfitMinMargin(groupIn.ProfitMinMargin).
-		SetProfitSafetyBuffer(groupIn.ProfitSafetyBuffer)
+		SetProfitSafetyBuffer(groupIn.ProfitSafetyBuffer).
+		SetResponseCacheEnabled(groupIn.ResponseCacheEnabled).
+		SetResponseCacheTTLSeconds(groupIn.ResponseCacheTTLSeconds).
+		SetResponseCacheHitMultiplier(groupIn.ResponseCacheHitMultiplier).
+		SetVolumeTiers(groupIn.VolumeTiers).
+		SetAllowGeminiNative(groupIn.AllowGeminiNative).
+		SetHedgePolicy(groupIn.HedgePolicy).
+		SetMirrorPolicy(groupIn.MirrorPolicy)
 
 	// 显式处理可空字段：nil 需要 clear，非 nil 需要 set。
 	if groupIn.DailyLimitUSD != nil {
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tracing"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startUpstreamSpan 为一次上游 HTTP 调用开启客户端 span，返回携带该 span 的请求副本。
+//
+// 不向上游注入 traceparent：上游是第三方模型服务，内部链路 ID 不应外泄。
+// 只记录 host 与 path，query 中可能带有 Gemini 的 key 参数。
+func startUpstreamSpan(req *http.Request, accountID int64) (*http.Request, trace.Span) {
+	if req == nil {
+		return nil, trace.SpanFromContext(context.Background())
+	}
+	attrs := []trace.SpanStartOption{
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(req.Method),
+			tracing.AttrAccountID.Int64(accountID),
+		),
+	}
+	if req.URL != nil {
+		attrs = append(attrs, trace.WithAttributes(
+			semconv.ServerAddress(req.URL.Hostname()),
+			semconv.URLPath(req.URL.Path),
+		))
+	}
+	ctx, span := tracing.Tracer().Start(req.Context(), "upstream "+req.Method, attrs...)
+	return req.WithContext(ctx), span
+}
+
+// finishUpstreamSpanHeaders 记录上游响应状态码；span 在响应体关闭时结束，以覆盖流式传输耗时。
+func finishUpstreamSpanHeaders(span trace.Span, resp *http.Response) {
+	if resp == nil {
+		return
+	}
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+	if resp.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+	}
+}
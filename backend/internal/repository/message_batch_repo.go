@@ -0,0 +1,302 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type messageBatchRepository struct {
+	sql batchImageSQLExecutor
+}
+
+func NewMessageBatchRepository(db *sql.DB) service.MessageBatchRepository {
+	return &messageBatchRepository{sql: db}
+}
+
+func (r *messageBatchRepository) CreateMessageBatchJob(ctx context.Context, job *service.MessageBatchJob) error {
+	if job.Status == "" {
+		job.Status = service.MessageBatchStatusCreated
+	}
+	err := r.sql.QueryRowContext(ctx, `
+INSERT INTO message_batch_jobs (
+    batch_id, user_id, api_key_id, account_id, group_id, status, request_count,
+    estimated_cost, hold_amount, group_rate_multiplier, account_rate_multiplier, batch_discount_multiplier,
+    idempotency_key, request_hash, next_poll_at
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+RETURNING id, created_at, updated_at`,
+		job.BatchID, job.UserID, job.APIKeyID, job.AccountID, nullInt64(job.GroupID), job.Status, job.RequestCount,
+		job.EstimatedCost, job.HoldAmount, job.GroupRateMultiplier, job.AccountRateMultiplier, job.BatchDiscountMultiplier,
+		nullString(job.IdempotencyKey), job.RequestHash, job.NextPollAt,
+	).Scan(&job.ID, &job.CreatedAt, &job.UpdatedAt)
+	return translatePersistenceError(err, nil, service.ErrMessageBatchExists)
+}
+
+func (r *messageBatchRepository) GetMessageBatchJob(ctx context.Context, batchID string) (*service.MessageBatchJob, error) {
+	job, err := scanMessageBatchJob(r.sql.QueryRowContext(ctx, messageBatchJobSelectSQL+" WHERE batch_id = $1", batchID))
+	if err != nil {
+		return nil, translatePersistenceError(err, service.ErrMessageBatchNotFound, nil)
+	}
+	return job, nil
+}
+
+func (r *messageBatchRepository) GetMessageBatchJobForOwner(ctx context.Context, apiKeyID int64, batchID string) (*service.MessageBatchJob, error) {
+	job, err := scanMessageBatchJob(r.sql.QueryRowContext(ctx, messageBatchJobSelectSQL+`
+ WHERE batch_id = $1 AND api_key_id = $2`, batchID, apiKeyID))
+	if err != nil {
+		return nil, translatePersistenceError(err, service.ErrMessageBatchNotFound, nil)
+	}
+	return job, nil
+}
+
+func (r *messageBatchRepository) GetMessageBatchJobByIdempotencyKey(ctx context.Context, apiKeyID int64, key string) (*service.MessageBatchJob, error) {
+	job, err := scanMessageBatchJob(r.sql.QueryRowContext(ctx, messageBatchJobSelectSQL+`
+ WHERE api_key_id = $1 AND idempotency_key = $2`, apiKeyID, key))
+	if err != nil {
+		return nil, translatePersistenceError(err, service.ErrMessageBatchNotFound, nil)
+	}
+	return job, nil
+}
+
+func (r *messageBatchRepository) ListMessageBatchJobsForOwner(ctx context.Context, apiKeyID int64, query service.MessageBatchListQuery) ([]*service.MessageBatchJob, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+
+	sqlText := messageBatchJobSelectSQL + " WHERE api_key_id = $1 AND upstream_batch_id IS NOT NULL"
+	args := []any{apiKeyID}
+	order := " ORDER BY id DESC"
+	switch {
+	case query.AfterID != "":
+		sqlText += " AND id < (SELECT id FROM message_batch_jobs WHERE batch_id = $" + strconv.Itoa(len(args)+1) + " AND api_key_id = $1)"
+		args = append(args, query.AfterID)
+	case query.BeforeID != "":
+		sqlText += " AND id > (SELECT id FROM message_batch_jobs WHERE batch_id = $" + strconv.Itoa(len(args)+1) + " AND api_key_id = $1)"
+		args = append(args, query.BeforeID)
+		order = " ORDER BY id ASC"
+	}
+	sqlText += order + " LIMIT $" + strconv.Itoa(len(args)+1)
+	args = append(args, limit)
+
+	rows, err := r.sql.QueryContext(ctx, sqlText, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	return scanMessageBatchJobs(rows)
+}
+
+func (r *messageBatchRepository) MarkMessageBatchJobSubmitted(ctx context.Context, batchID, upstreamBatchID string, progress service.MessageBatchProgress) error {
+	res, err := r.sql.ExecContext(ctx, `
+UPDATE message_batch_jobs
+SET upstream_batch_id = $2,
+    status = $3,
+    processing_count = $4,
+    succeeded_count = $5,
+    errored_count = $6,
+    canceled_count = $7,
+    expired_count = $8,
+    expires_at = COALESCE($9, expires_at),
+    ended_at = COALESCE($10, ended_at),
+    cancel_initiated_at = COALESCE($11, cancel_initiated_at),
+    next_poll_at = $12,
+    updated_at = NOW()
+WHERE batch_id = $1
+  AND status = 'created'`,
+		batchID, upstreamBatchID, progress.Status,
+		progress.Counts.Processing, progress.Counts.Succeeded, progress.Counts.Errored, progress.Counts.Canceled, progress.Counts.Expired,
+		progress.ExpiresAt, progress.EndedAt, progress.CancelInitiatedAt, progress.NextPollAt,
+	)
+	return messageBatchRequireAffected(res, err)
+}
+
+func (r *messageBatchRepository) UpdateMessageBatchJobProgress(ctx context.Context, batchID string, progress service.MessageBatchProgress) error {
+	res, err := r.sql.ExecContext(ctx, `
+UPDATE message_batch_jobs
+SET status = $2,
+    processing_count = $3,
+    succeeded_count = $4,
+    errored_count = $5,
+    canceled_count = $6,
+    expired_count = $7,
+    expires_at = COALESCE($8, expires_at),
+    ended_at = COALESCE($9, ended_at),
+    cancel_initiated_at = COALESCE($10, cancel_initiated_at),
+    next_poll_at = $11,
+    updated_at = NOW()
+WHERE batch_id = $1
+  AND status IN ('in_progress', 'canceling')`,
+		batchID, progress.Status,
+		progress.Counts.Processing, progress.Counts.Succeeded, progress.Counts.Errored, progress.Counts.Canceled, progress.Counts.Expired,
+		progress.ExpiresAt, progress.EndedAt, progress.CancelInitiatedAt, progress.NextPollAt,
+	)
+	return messageBatchRequireAffected(res, err)
+}
+
+func (r *messageBatchRepository) MarkMessageBatchJobSettled(ctx context.Context, params service.MarkMessageBatchJobSettledParams) error {
+	res, err := r.sql.ExecContext(ctx, `
+UPDATE message_batch_jobs
+SET status = 'completed',
+    actual_cost = $2,
+    input_tokens = $3,
+    output_tokens = $4,
+    cache_creation_tokens = $5,
+    cache_read_tokens = $6,
+    settled_at = $7,
+    next_poll_at = NULL,
+    last_error_code = NULL,
+    last_error_message = NULL,
+    updated_at = $7
+WHERE batch_id = $1
+  AND status = 'settling'`,
+		params.BatchID, params.ActualCost, params.InputTokens, params.OutputTokens,
+		params.CacheCreationTokens, params.CacheReadTokens, params.SettledAt,
+	)
+	return messageBatchRequireAffected(res, err)
+}
+
+func (r *messageBatchRepository) MarkMessageBatchJobFailed(ctx context.Context, batchID, code, message string) error {
+	res, err := r.sql.ExecContext(ctx, `
+UPDATE message_batch_jobs
+SET status = 'failed',
+    last_error_code = $2,
+    last_error_message = $3,
+    next_poll_at = NULL,
+    updated_at = NOW()
+WHERE batch_id = $1
+  AND status IN ('created', 'settling')`, batchID, code, message)
+	return messageBatchRequireAffected(res, err)
+}
+
+// MarkMessageBatchJobSettlementStuck 只更新状态与错误信息，不触碰冻结余额；轮询不会再领取该批次。
+func (r *messageBatchRepository) MarkMessageBatchJobSettlementStuck(ctx context.Context, batchID, code, message string) error {
+	res, err := r.sql.ExecContext(ctx, `
+UPDATE message_batch_jobs
+SET status = 'settlement_stuck',
+    last_error_code = $2,
+    last_error_message = $3,
+    next_poll_at = NULL,
+    updated_at = NOW()
+WHERE batch_id = $1
+  AND status = 'settling'`, batchID, code, message)
+	return messageBatchRequireAffected(res, err)
+}
+
+func (r *messageBatchRepository) RecordMessageBatchJobError(ctx context.Context, batchID, code, message string, nextPollAt time.Time) (int, error) {
+	var retryCount int
+	err := r.sql.QueryRowContext(ctx, `
+UPDATE message_batch_jobs
+SET last_error_code = $2,
+    last_error_message = $3,
+    retry_count = retry_count + 1,
+    next_poll_at = $4,
+    updated_at = NOW()
+WHERE batch_id = $1
+RETURNING retry_count`, batchID, code, message, nextPollAt).Scan(&retryCount)
+	if err != nil {
+		return 0, translatePersistenceError(err, service.ErrMessageBatchNotFound, nil)
+	}
+	return retryCount, nil
+}
+
+func (r *messageBatchRepository) ClaimDueMessageBatchJobs(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*service.MessageBatchJob, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+	rows, err := r.sql.QueryContext(ctx, `
+WITH due AS (
+    SELECT id AS due_id
+    FROM message_batch_jobs
+    WHERE status IN ('created', 'in_progress', 'canceling', 'settling')
+      AND next_poll_at IS NOT NULL
+      AND next_poll_at <= $1
+    ORDER BY next_poll_at ASC, id ASC
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+UPDATE message_batch_jobs
+SET next_poll_at = $3
+FROM due
+WHERE id = due.due_id
+RETURNING `+messageBatchJobColumns, now, limit, now.Add(lease))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	return scanMessageBatchJobs(rows)
+}
+
+func messageBatchRequireAffected(res sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return service.ErrMessageBatchInvalidTransition
+	}
+	return nil
+}
+
+const messageBatchJobColumns = `id, batch_id, user_id, api_key_id, account_id, group_id, upstream_batch_id, status, request_count,
+    processing_count, succeeded_count, errored_count, canceled_count, expired_count,
+    estimated_cost, hold_amount, actual_cost, group_rate_multiplier, account_rate_multiplier, batch_discount_multiplier,
+    input_tokens, output_tokens, cache_creation_tokens, cache_read_tokens,
+    idempotency_key, request_hash, retry_count, last_error_code, last_error_message,
+    next_poll_at, expires_at, ended_at, cancel_initiated_at, settled_at, created_at, updated_at`
+
+const messageBatchJobSelectSQL = `SELECT ` + messageBatchJobColumns + ` FROM message_batch_jobs`
+
+func scanMessageBatchJob(row rowScanner) (*service.MessageBatchJob, error) {
+	var job service.MessageBatchJob
+	var groupID sql.NullInt64
+	var upstreamBatchID, idempotencyKey, lastErrorCode, lastErrorMessage sql.NullString
+	var actualCost sql.NullFloat64
+	var nextPollAt, expiresAt, endedAt, cancelInitiatedAt, settledAt sql.NullTime
+
+	err := row.Scan(
+		&job.ID, &job.BatchID, &job.UserID, &job.APIKeyID, &job.AccountID, &groupID, &upstreamBatchID, &job.Status, &job.RequestCount,
+		&job.Counts.Processing, &job.Counts.Succeeded, &job.Counts.Errored, &job.Counts.Canceled, &job.Counts.Expired,
+		&job.EstimatedCost, &job.HoldAmount, &actualCost, &job.GroupRateMultiplier, &job.AccountRateMultiplier, &job.BatchDiscountMultiplier,
+		&job.InputTokens, &job.OutputTokens, &job.CacheCreationTokens, &job.CacheReadTokens,
+		&idempotencyKey, &job.RequestHash, &job.RetryCount, &lastErrorCode, &lastErrorMessage,
+		&nextPollAt, &expiresAt, &endedAt, &cancelInitiatedAt, &settledAt, &job.CreatedAt, &job.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	job.GroupID = batchImageNullInt64Ptr(groupID)
+	job.UpstreamBatchID = batchImageNullStringPtr(upstreamBatchID)
+	job.ActualCost = batchImageNullFloat64Ptr(actualCost)
+	job.IdempotencyKey = batchImageNullStringPtr(idempotencyKey)
+	job.LastErrorCode = batchImageNullStringPtr(lastErrorCode)
+	job.LastErrorMessage = batchImageNullStringPtr(lastErrorMessage)
+	job.NextPollAt = batchImageNullTimePtr(nextPollAt)
+	job.ExpiresAt = batchImageNullTimePtr(expiresAt)
+	job.EndedAt = batchImageNullTimePtr(endedAt)
+	job.CancelInitiatedAt = batchImageNullTimePtr(cancelInitiatedAt)
+	job.SettledAt = batchImageNullTimePtr(settledAt)
+	return &job, nil
+}
+
+func scanMessageBatchJobs(rows *sql.Rows) ([]*service.MessageBatchJob, error) {
+	var jobs []*service.MessageBatchJob
+	for rows.Next() {
+		job, err := scanMessageBatchJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
@@ -0,0 +1,406 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type openAIBatchRepository struct {
+	sql batchImageSQLExecutor
+}
+
+func NewOpenAIBatchRepository(db *sql.DB) service.OpenAIBatchRepository {
+	return &openAIBatchRepository{sql: db}
+}
+
+// ---- Files ----
+
+func (r *openAIBatchRepository) CreateOpenAIBatchFile(ctx context.Context, file *service.OpenAIBatchFile) error {
+	estimate, err := json.Marshal(file.Estimate)
+	if err != nil {
+		return err
+	}
+	if file.Estimate == nil {
+		estimate = []byte("[]")
+	}
+	err = r.sql.QueryRowContext(ctx, `
+INSERT INTO openai_batch_files (
+    file_id, user_id, api_key_id, account_id, purpose, filename, bytes, endpoint, request_count, estimate, upstream_object
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10::jsonb, $11::jsonb)
+RETURNING id, created_at`,
+		file.FileID, file.UserID, file.APIKeyID, file.AccountID, file.Purpose, file.Filename, file.Bytes,
+		file.Endpoint, file.RequestCount, string(estimate), openAIBatchNullJSON(file.UpstreamObject),
+	).Scan(&file.ID, &file.CreatedAt)
+	return translatePersistenceError(err, nil, service.ErrOpenAIBatchFileExists)
+}
+
+func (r *openAIBatchRepository) GetOpenAIBatchFileForOwner(ctx context.Context, apiKeyID int64, fileID string) (*service.OpenAIBatchFile, error) {
+	file, err := scanOpenAIBatchFile(r.sql.QueryRowContext(ctx, openAIBatchFileSelectSQL+`
+ WHERE file_id = $1 AND api_key_id = $2 AND deleted_at IS NULL`, fileID, apiKeyID))
+	if err != nil {
+		return nil, translatePersistenceError(err, service.ErrOpenAIBatchFileNotFound, nil)
+	}
+	return file, nil
+}
+
+func (r *openAIBatchRepository) ListOpenAIBatchFilesForOwner(ctx context.Context, apiKeyID int64, purpose string, query service.OpenAIBatchListQuery) ([]*service.OpenAIBatchFile, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+
+	sqlText := openAIBatchFileSelectSQL + " WHERE api_key_id = $1 AND deleted_at IS NULL"
+	args := []any{apiKeyID}
+	if purpose != "" {
+		args = append(args, purpose)
+		sqlText += " AND purpose = $" + strconv.Itoa(len(args))
+	}
+	if query.After != "" {
+		args = append(args, query.After)
+		sqlText += " AND id < (SELECT id FROM openai_batch_files WHERE file_id = $" + strconv.Itoa(len(args)) + " AND api_key_id = $1)"
+	}
+	args = append(args, limit)
+	sqlText += " ORDER BY id DESC LIMIT $" + strconv.Itoa(len(args))
+
+	rows, err := r.sql.QueryContext(ctx, sqlText, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var files []*service.OpenAIBatchFile
+	for rows.Next() {
+		file, err := scanOpenAIBatchFile(rows)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func (r *openAIBatchRepository) MarkOpenAIBatchFileDeleted(ctx context.Context, fileID string, deletedAt time.Time) error {
+	_, err := r.sql.ExecContext(ctx, `
+UPDATE openai_batch_files
+SET deleted_at = $2
+WHERE file_id = $1
+  AND deleted_at IS NULL`, fileID, deletedAt)
+	return err
+}
+
+// ---- Jobs ----
+
+func (r *openAIBatchRepository) CreateOpenAIBatchJob(ctx context.Context, job *service.OpenAIBatchJob) error {
+	if job.Status == "" {
+		job.Status = service.OpenAIBatchStatusCreated
+	}
+	err := r.sql.QueryRowContext(ctx, `
+INSERT INTO openai_batch_jobs (
+    batch_id, user_id, api_key_id, account_id, group_id, input_file_id, endpoint, status, request_count,
+    estimated_cost, hold_amount, group_rate_multiplier, account_rate_multiplier, batch_discount_multiplier,
+    idempotency_key, request_hash, next_poll_at
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+RETURNING id, created_at, updated_at`,
+		job.BatchID, job.UserID, job.APIKeyID, job.AccountID, nullInt64(job.GroupID), job.InputFileID, job.Endpoint, job.Status, job.RequestCount,
+		job.EstimatedCost, job.HoldAmount, job.GroupRateMultiplier, job.AccountRateMultiplier, job.BatchDiscountMultiplier,
+		nullString(job.IdempotencyKey), job.RequestHash, job.NextPollAt,
+	).Scan(&job.ID, &job.CreatedAt, &job.UpdatedAt)
+	return translatePersistenceError(err, nil, service.ErrOpenAIBatchExists)
+}
+
+func (r *openAIBatchRepository) GetOpenAIBatchJob(ctx context.Context, batchID string) (*service.OpenAIBatchJob, error) {
+	job, err := scanOpenAIBatchJob(r.sql.QueryRowContext(ctx, openAIBatchJobSelectSQL+" WHERE batch_id = $1", batchID))
+	if err != nil {
+		return nil, translatePersistenceError(err, service.ErrOpenAIBatchNotFound, nil)
+	}
+	return job, nil
+}
+
+func (r *openAIBatchRepository) GetOpenAIBatchJobForOwner(ctx context.Context, apiKeyID int64, batchID string) (*service.OpenAIBatchJob, error) {
+	job, err := scanOpenAIBatchJob(r.sql.QueryRowContext(ctx, openAIBatchJobSelectSQL+`
+ WHERE batch_id = $1 AND api_key_id = $2`, batchID, apiKeyID))
+	if err != nil {
+		return nil, translatePersistenceError(err, service.ErrOpenAIBatchNotFound, nil)
+	}
+	return job, nil
+}
+
+func (r *openAIBatchRepository) GetOpenAIBatchJobByIdempotencyKey(ctx context.Context, apiKeyID int64, key string) (*service.OpenAIBatchJob, error) {
+	job, err := scanOpenAIBatchJob(r.sql.QueryRowContext(ctx, openAIBatchJobSelectSQL+`
+ WHERE api_key_id = $1 AND idempotency_key = $2`, apiKeyID, key))
+	if err != nil {
+		return nil, translatePersistenceError(err, service.ErrOpenAIBatchNotFound, nil)
+	}
+	return job, nil
+}
+
+func (r *openAIBatchRepository) ListOpenAIBatchJobsForOwner(ctx context.Context, apiKeyID int64, query service.OpenAIBatchListQuery) ([]*service.OpenAIBatchJob, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+
+	sqlText := openAIBatchJobSelectSQL + " WHERE api_key_id = $1 AND upstream_batch_id IS NOT NULL"
+	args := []any{apiKeyID}
+	if query.After != "" {
+		args = append(args, query.After)
+		sqlText += " AND id < (SELECT id FROM openai_batch_jobs WHERE batch_id = $" + strconv.Itoa(len(args)) + " AND api_key_id = $1)"
+	}
+	args = append(args, limit)
+	sqlText += " ORDER BY id DESC LIMIT $" + strconv.Itoa(len(args))
+
+	rows, err := r.sql.QueryContext(ctx, sqlText, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	return scanOpenAIBatchJobs(rows)
+}
+
+func (r *openAIBatchRepository) MarkOpenAIBatchJobSubmitted(ctx context.Context, batchID, upstreamBatchID string, progress service.OpenAIBatchProgress) error {
+	res, err := r.sql.ExecContext(ctx, `
+UPDATE openai_batch_jobs
+SET upstream_batch_id = $2,
+    status = $3,
+    upstream_status = $4,
+    upstream_object = $5::jsonb,
+    output_file_id = COALESCE($6, output_file_id),
+    error_file_id = COALESCE($7, error_file_id),
+    request_count = CASE WHEN $8::int > 0 THEN $8::int ELSE request_count END,
+    next_poll_at = $9,
+    updated_at = NOW()
+WHERE batch_id = $1
+  AND status = 'created'`,
+		batchID, upstreamBatchID, progress.Status, progress.UpstreamStatus, openAIBatchNullJSON(progress.UpstreamObject),
+		nullString(progress.OutputFileID), nullString(progress.ErrorFileID), progress.RequestCount, progress.NextPollAt,
+	)
+	return openAIBatchRequireAffected(res, err)
+}
+
+func (r *openAIBatchRepository) UpdateOpenAIBatchJobProgress(ctx context.Context, batchID string, progress service.OpenAIBatchProgress) error {
+	res, err := r.sql.ExecContext(ctx, `
+UPDATE openai_batch_jobs
+SET status = $2,
+    upstream_status = $3,
+    upstream_object = COALESCE($4::jsonb, upstream_object),
+    output_file_id = COALESCE($5, output_file_id),
+    error_file_id = COALESCE($6, error_file_id),
+    request_count = CASE WHEN $7::int > 0 THEN $7::int ELSE request_count END,
+    next_poll_at = $8,
+    updated_at = NOW()
+WHERE batch_id = $1
+  AND status = 'in_progress'`,
+		batchID, progress.Status, progress.UpstreamStatus, openAIBatchNullJSON(progress.UpstreamObject),
+		nullString(progress.OutputFileID), nullString(progress.ErrorFileID), progress.RequestCount, progress.NextPollAt,
+	)
+	return openAIBatchRequireAffected(res, err)
+}
+
+func (r *openAIBatchRepository) MarkOpenAIBatchJobSettled(ctx context.Context, params service.MarkOpenAIBatchJobSettledParams) error {
+	res, err := r.sql.ExecContext(ctx, `
+UPDATE openai_batch_jobs
+SET status = 'completed',
+    actual_cost = $2,
+    input_tokens = $3,
+    output_tokens = $4,
+    cache_read_tokens = $5,
+    settled_at = $6,
+    next_poll_at = NULL,
+    last_error_code = NULL,
+    last_error_message = NULL,
+    updated_at = $6
+WHERE batch_id = $1
+  AND status = 'settling'`,
+		params.BatchID, params.ActualCost, params.InputTokens, params.OutputTokens, params.CacheReadTokens, params.SettledAt,
+	)
+	return openAIBatchRequireAffected(res, err)
+}
+
+func (r *openAIBatchRepository) MarkOpenAIBatchJobFailed(ctx context.Context, batchID, code, message string) error {
+	res, err := r.sql.ExecContext(ctx, `
+UPDATE openai_batch_jobs
+SET status = 'failed',
+    last_error_code = $2,
+    last_error_message = $3,
+    next_poll_at = NULL,
+    updated_at = NOW()
+WHERE batch_id = $1
+  AND status IN ('created', 'settling')`, batchID, code, message)
+	return openAIBatchRequireAffected(res, err)
+}
+
+// MarkOpenAIBatchJobSettlementStuck 只更新状态与错误信息，不触碰冻结余额；轮询不会再领取该批次。
+func (r *openAIBatchRepository) MarkOpenAIBatchJobSettlementStuck(ctx context.Context, batchID, code, message string) error {
+	res, err := r.sql.ExecContext(ctx, `
+UPDATE openai_batch_jobs
+SET status = 'settlement_stuck',
+    last_error_code = $2,
+    last_error_message = $3,
+    next_poll_at = NULL,
+    updated_at = NOW()
+WHERE batch_id = $1
+  AND status = 'settling'`, batchID, code, message)
+	return openAIBatchRequireAffected(res, err)
+}
+
+func (r *openAIBatchRepository) RecordOpenAIBatchJobError(ctx context.Context, batchID, code, message string, nextPollAt time.Time) (int, error) {
+	var retryCount int
+	err := r.sql.QueryRowContext(ctx, `
+UPDATE openai_batch_jobs
+SET last_error_code = $2,
+    last_error_message = $3,
+    retry_count = retry_count + 1,
+    next_poll_at = $4,
+    updated_at = NOW()
+WHERE batch_id = $1
+RETURNING retry_count`, batchID, code, message, nextPollAt).Scan(&retryCount)
+	if err != nil {
+		return 0, translatePersistenceError(err, service.ErrOpenAIBatchNotFound, nil)
+	}
+	return retryCount, nil
+}
+
+func (r *openAIBatchRepository) ClaimDueOpenAIBatchJobs(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*service.OpenAIBatchJob, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+	rows, err := r.sql.QueryContext(ctx, `
+WITH due AS (
+    SELECT id AS due_id
+    FROM openai_batch_jobs
+    WHERE status IN ('created', 'in_progress', 'settling')
+      AND next_poll_at IS NOT NULL
+      AND next_poll_at <= $1
+    ORDER BY next_poll_at ASC, id ASC
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+UPDATE openai_batch_jobs
+SET next_poll_at = $3
+FROM due
+WHERE id = due.due_id
+RETURNING `+openAIBatchJobColumns, now, limit, now.Add(lease))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	return scanOpenAIBatchJobs(rows)
+}
+
+func openAIBatchRequireAffected(res sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return service.ErrOpenAIBatchInvalidTransition
+	}
+	return nil
+}
+
+func openAIBatchNullJSON(raw json.RawMessage) sql.NullString {
+	if len(raw) == 0 {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: string(raw), Valid: true}
+}
+
+const openAIBatchFileColumns = `id, file_id, user_id, api_key_id, account_id, purpose, filename, bytes, endpoint, request_count,
+    estimate, upstream_object, deleted_at, created_at`
+
+const openAIBatchFileSelectSQL = `SELECT ` + openAIBatchFileColumns + ` FROM openai_batch_files`
+
+func scanOpenAIBatchFile(row rowScanner) (*service.OpenAIBatchFile, error) {
+	var file service.OpenAIBatchFile
+	var estimate []byte
+	var upstreamObject sql.NullString
+	var deletedAt sql.NullTime
+
+	err := row.Scan(
+		&file.ID, &file.FileID, &file.UserID, &file.APIKeyID, &file.AccountID, &file.Purpose, &file.Filename, &file.Bytes,
+		&file.Endpoint, &file.RequestCount, &estimate, &upstreamObject, &deletedAt, &file.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(estimate) > 0 {
+		if err := json.Unmarshal(estimate, &file.Estimate); err != nil {
+			return nil, err
+		}
+	}
+	if upstreamObject.Valid {
+		file.UpstreamObject = json.RawMessage(upstreamObject.String)
+	}
+	file.DeletedAt = batchImageNullTimePtr(deletedAt)
+	return &file, nil
+}
+
+const openAIBatchJobColumns = `id, batch_id, user_id, api_key_id, account_id, group_id, input_file_id, endpoint, upstream_batch_id,
+    status, upstream_status, upstream_object, output_file_id, error_file_id, request_count,
+    estimated_cost, hold_amount, actual_cost, group_rate_multiplier, account_rate_multiplier, batch_discount_multiplier,
+    input_tokens, output_tokens, cache_read_tokens,
+    idempotency_key, request_hash, retry_count, last_error_code, last_error_message,
+    next_poll_at, settled_at, created_at, updated_at`
+
+const openAIBatchJobSelectSQL = `SELECT ` + openAIBatchJobColumns + ` FROM openai_batch_jobs`
+
+func scanOpenAIBatchJob(row rowScanner) (*service.OpenAIBatchJob, error) {
+	var job service.OpenAIBatchJob
+	var groupID sql.NullInt64
+	var upstreamBatchID, upstreamObject, outputFileID, errorFileID sql.NullString
+	var idempotencyKey, lastErrorCode, lastErrorMessage sql.NullString
+	var actualCost sql.NullFloat64
+	var nextPollAt, settledAt sql.NullTime
+
+	err := row.Scan(
+		&job.ID, &job.BatchID, &job.UserID, &job.APIKeyID, &job.AccountID, &groupID, &job.InputFileID, &job.Endpoint, &upstreamBatchID,
+		&job.Status, &job.UpstreamStatus, &upstreamObject, &outputFileID, &errorFileID, &job.RequestCount,
+		&job.EstimatedCost, &job.HoldAmount, &actualCost, &job.GroupRateMultiplier, &job.AccountRateMultiplier, &job.BatchDiscountMultiplier,
+		&job.InputTokens, &job.OutputTokens, &job.CacheReadTokens,
+		&idempotencyKey, &job.RequestHash, &job.RetryCount, &lastErrorCode, &lastErrorMessage,
+		&nextPollAt, &settledAt, &job.CreatedAt, &job.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	job.GroupID = batchImageNullInt64Ptr(groupID)
+	job.UpstreamBatchID = batchImageNullStringPtr(upstreamBatchID)
+	if upstreamObject.Valid {
+		job.UpstreamObject = json.RawMessage(upstreamObject.String)
+	}
+	job.OutputFileID = batchImageNullStringPtr(outputFileID)
+	job.ErrorFileID = batchImageNullStringPtr(errorFileID)
+	job.ActualCost = batchImageNullFloat64Ptr(actualCost)
+	job.IdempotencyKey = batchImageNullStringPtr(idempotencyKey)
+	job.LastErrorCode = batchImageNullStringPtr(lastErrorCode)
+	job.LastErrorMessage = batchImageNullStringPtr(lastErrorMessage)
+	job.NextPollAt = batchImageNullTimePtr(nextPollAt)
+	job.SettledAt = batchImageNullTimePtr(settledAt)
+	return &job, nil
+}
+
+func scanOpenAIBatchJobs(rows *sql.Rows) ([]*service.OpenAIBatchJob, error) {
+	var jobs []*service.OpenAIBatchJob
+	for rows.Next() {
+		job, err := scanOpenAIBatchJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
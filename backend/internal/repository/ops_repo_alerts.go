@@ -31,6 +31,7 @@ SELECT
   cooldown_minutes,
   COALESCE(notify_email, true),
   filters,
+  notify_channels,
   last_triggered_at,
   created_at,
   updated_at
@@ -47,6 +48,7 @@ ORDER BY id DESC`
 	for rows.Next() {
 		var rule service.OpsAlertRule
 		var filtersRaw []byte
+		var channelsRaw []byte
 		var lastTriggeredAt sql.NullTime
 		if err := rows.Scan(
 			&rule.ID,
@@ -62,6 +64,7 @@ ORDER BY id DESC`
 			&rule.CooldownMinutes,
 			&rule.NotifyEmail,
 			&filtersRaw,
+			&channelsRaw,
 			&lastTriggeredAt,
 			&rule.CreatedAt,
 			&rule.UpdatedAt,
@@ -78,6 +81,7 @@ ORDER BY id DESC`
 				rule.Filters = decoded
 			}
 		}
+		rule.NotifyChannels = decodeOpsAlertNotifyChannels(channelsRaw)
 		out = append(out, &rule)
 	}
 	if err := rows.Err(); err != nil {
@@ -98,6 +102,10 @@ func (r *opsRepository) CreateAlertRule(ctx context.Context, input *service.OpsA
 	if err != nil {
 		return nil, err
 	}
+	channelsArg, err := opsNullJSONSlice(input.NotifyChannels)
+	if err != nil {
+		return nil, err
+	}
 
 	q := `
 INSERT INTO ops_alert_rules (
@@ -113,10 +121,11 @@ INSERT INTO ops_alert_rules (
   cooldown_minutes,
   notify_email,
   filters,
+  notify_channels,
   created_at,
   updated_at
 ) VALUES (
-  $1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,NOW(),NOW()
+  $1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,NOW(),NOW()
 )
 RETURNING
   id,
@@ -132,12 +141,14 @@ RETURNING
   cooldown_minutes,
   COALESCE(notify_email, true),
   filters,
+  notify_channels,
   last_triggered_at,
   created_at,
   updated_at`
 
 	var out service.OpsAlertRule
 	var filtersRaw []byte
+	var channelsRaw []byte
 	var lastTriggeredAt sql.NullTime
 
 	if err := r.db.QueryRowContext(
@@ -155,6 +166,7 @@ RETURNING
 		input.CooldownMinutes,
 		input.NotifyEmail,
 		filtersArg,
+		channelsArg,
 	).Scan(
 		&out.ID,
 		&out.Name,
@@ -169,6 +181,7 @@ RETURNING
 		&out.CooldownMinutes,
 		&out.NotifyEmail,
 		&filtersRaw,
+		&channelsRaw,
 		&lastTriggeredAt,
 		&out.CreatedAt,
 		&out.UpdatedAt,
@@ -185,6 +198,7 @@ RETURNING
 			out.Filters = decoded
 		}
 	}
+	out.NotifyChannels = decodeOpsAlertNotifyChannels(channelsRaw)
 
 	return &out, nil
 }
@@ -204,6 +218,10 @@ func (r *opsRepository) UpdateAlertRule(ctx context.Context, input *service.OpsA
 	if err != nil {
 		return nil, err
 	}
+	channelsArg, err := opsNullJSONSlice(input.NotifyChannels)
+	if err != nil {
+		return nil, err
+	}
 
 	q := `
 UPDATE ops_alert_rules
@@ -220,6 +238,7 @@ SET
   cooldown_minutes = $11,
   notify_email = $12,
   filters = $13,
+  notify_channels = $14,
   updated_at = NOW()
 WHERE id = $1
 RETURNING
@@ -236,12 +255,14 @@ RETURNING
   cooldown_minutes,
   COALESCE(notify_email, true),
   filters,
+  notify_channels,
   last_triggered_at,
   created_at,
   updated_at`
 
 	var out service.OpsAlertRule
 	var filtersRaw []byte
+	var channelsRaw []byte
 	var lastTriggeredAt sql.NullTime
 
 	if err := r.db.QueryRowContext(
@@ -260,6 +281,7 @@ RETURNING
 		input.CooldownMinutes,
 		input.NotifyEmail,
 		filtersArg,
+		channelsArg,
 	).Scan(
 		&out.ID,
 		&out.Name,
@@ -274,6 +296,7 @@ RETURNING
 		&out.CooldownMinutes,
 		&out.NotifyEmail,
 		&filtersRaw,
+		&channelsRaw,
 		&lastTriggeredAt,
 		&out.CreatedAt,
 		&out.UpdatedAt,
@@ -291,6 +314,7 @@ RETURNING
 			out.Filters = decoded
 		}
 	}
+	out.NotifyChannels = decodeOpsAlertNotifyChannels(channelsRaw)
 
 	return &out, nil
 }
@@ -351,6 +375,7 @@ SELECT
   fired_at,
   resolved_at,
   email_sent,
+  channel_deliveries,
   created_at
 FROM ops_alert_events
 ` + where + `
@@ -369,6 +394,7 @@ LIMIT ` + limitArg
 		var metricValue sql.NullFloat64
 		var thresholdValue sql.NullFloat64
 		var dimensionsRaw []byte
+		var deliveriesRaw []byte
 		var resolvedAt sql.NullTime
 		if err := rows.Scan(
 			&ev.ID,
@@ -383,6 +409,7 @@ LIMIT ` + limitArg
 			&ev.FiredAt,
 			&resolvedAt,
 			&ev.EmailSent,
+			&deliveriesRaw,
 			&ev.CreatedAt,
 		); err != nil {
 			return nil, err
@@ -405,6 +432,7 @@ LIMIT ` + limitArg
 				ev.Dimensions = decoded
 			}
 		}
+		ev.ChannelDeliveries = decodeOpsAlertChannelDeliveries(deliveriesRaw)
 		out = append(out, &ev)
 	}
 	if err := rows.Err(); err != nil {
@@ -435,6 +463,7 @@ SELECT
   fired_at,
   resolved_at,
   email_sent,
+  channel_deliveries,
   created_at
 FROM ops_alert_events
 WHERE id = $1`
@@ -472,6 +501,7 @@ SELECT
   fired_at,
   resolved_at,
   email_sent,
+  channel_deliveries,
   created_at
 FROM ops_alert_events
 WHERE rule_id = $1 AND status = $2
@@ -511,6 +541,7 @@ SELECT
   fired_at,
   resolved_at,
   email_sent,
+  channel_deliveries,
   created_at
 FROM ops_alert_events
 WHERE rule_id = $1
@@ -571,6 +602,7 @@ RETURNING
   fired_at,
   resolved_at,
   email_sent,
+  channel_deliveries,
   created_at`
 
 	row := r.db.QueryRowContext(
@@ -624,6 +656,50 @@ func (r *opsRepository) UpdateAlertEventEmailSent(ctx context.Context, eventID i
 	return err
 }
 
+// AppendAlertEventChannelDeliveries 追加渠道投递记录；使用 jsonb 拼接而非覆盖，
+// 避免 firing/resolved 两次异步投递互相覆盖。
+func (r *opsRepository) AppendAlertEventChannelDeliveries(ctx context.Context, eventID int64, deliveries []service.OpsAlertChannelDelivery) error {
+	if r == nil || r.db == nil {
+		return fmt.Errorf("nil ops repository")
+	}
+	if eventID <= 0 {
+		return fmt.Errorf("invalid event id")
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	b, err := json.Marshal(deliveries)
+	if err != nil {
+		return err
+	}
+
+	q := `
+UPDATE ops_alert_events
+SET channel_deliveries = COALESCE(channel_deliveries, '[]'::jsonb) || $2::jsonb
+WHERE id = $1`
+
+	_, err = r.db.ExecContext(ctx, q, eventID, string(b))
+	return err
+}
+
+// CountStuckBatchSettlements 统计重试耗尽、冻结待人工结算的 Message Batches / OpenAI Batch 批次。
+func (r *opsRepository) CountStuckBatchSettlements(ctx context.Context) (int64, error) {
+	if r == nil || r.db == nil {
+		return 0, fmt.Errorf("nil ops repository")
+	}
+
+	q := `
+SELECT
+  (SELECT COUNT(*) FROM message_batch_jobs WHERE status = 'settlement_stuck') +
+  (SELECT COUNT(*) FROM openai_batch_jobs WHERE status = 'settlement_stuck')`
+
+	var n int64
+	if err := r.db.QueryRowContext(ctx, q).Scan(&n); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
 type opsAlertEventRow interface {
 	Scan(dest ...any) error
 }
@@ -748,6 +824,7 @@ func scanOpsAlertEvent(row opsAlertEventRow) (*service.OpsAlertEvent, error) {
 	var metricValue sql.NullFloat64
 	var thresholdValue sql.NullFloat64
 	var dimensionsRaw []byte
+	var deliveriesRaw []byte
 	var resolvedAt sql.NullTime
 
 	if err := row.Scan(
@@ -763,6 +840,7 @@ func scanOpsAlertEvent(row opsAlertEventRow) (*service.OpsAlertEvent, error) {
 		&ev.FiredAt,
 		&resolvedAt,
 		&ev.EmailSent,
+		&deliveriesRaw,
 		&ev.CreatedAt,
 	); err != nil {
 		return nil, err
@@ -785,6 +863,7 @@ func scanOpsAlertEvent(row opsAlertEventRow) (*service.OpsAlertEvent, error) {
 			ev.Dimensions = decoded
 		}
 	}
+	ev.ChannelDeliveries = decodeOpsAlertChannelDeliveries(deliveriesRaw)
 	return &ev, nil
 }
 
@@ -851,3 +930,36 @@ func opsNullJSONMap(v map[string]any) (any, error) {
 	}
 	return sql.NullString{String: string(b), Valid: true}, nil
 }
+
+func opsNullJSONSlice[T any](v []T) (any, error) {
+	if len(v) == 0 {
+		return sql.NullString{}, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return sql.NullString{String: string(b), Valid: true}, nil
+}
+
+func decodeOpsAlertNotifyChannels(raw []byte) []service.OpsAlertNotifyChannel {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	var out []service.OpsAlertNotifyChannel
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil
+	}
+	return out
+}
+
+func decodeOpsAlertChannelDeliveries(raw []byte) []service.OpsAlertChannelDelivery {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	var out []service.OpsAlertChannelDelivery
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil
+	}
+	return out
+}
@@ -0,0 +1,499 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+// organizationMonthStartSQL 当前自然月（UTC）起点，与 service.OrganizationMember.CurrentMonthSpent 一致。
+const organizationMonthStartSQL = `(date_trunc('month', NOW() AT TIME ZONE 'UTC') AT TIME ZONE 'UTC')`
+
+const organizationSelectSQL = `
+SELECT o.id, o.name, o.owner_user_id, COALESCE(u.email, ''), o.balance, o.status,
+    (SELECT COUNT(*) FROM organization_members m WHERE m.organization_id = o.id),
+    o.created_at, o.updated_at
+FROM organizations o
+LEFT JOIN users u ON u.id = o.owner_user_id`
+
+const organizationMemberSelectSQL = `
+SELECT m.id, m.organization_id, m.user_id, COALESCE(u.email, ''), COALESCE(u.username, ''), m.role,
+    m.monthly_spend_cap_usd, m.monthly_spent_usd, m.spend_month_start, m.created_at, m.updated_at
+FROM organization_members m
+JOIN organizations o ON o.id = m.organization_id AND o.deleted_at IS NULL
+LEFT JOIN users u ON u.id = m.user_id`
+
+type organizationRepository struct {
+	db *sql.DB
+}
+
+func NewOrganizationRepository(db *sql.DB) service.OrganizationRepository {
+	return &organizationRepository{db: db}
+}
+
+type organizationRowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanOrganization(row organizationRowScanner) (*service.Organization, error) {
+	var org service.Organization
+	if err := row.Scan(&org.ID, &org.Name, &org.OwnerUserID, &org.OwnerEmail, &org.Balance, &org.Status,
+		&org.MemberCount, &org.CreatedAt, &org.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &org, nil
+}
+
+func scanOrganizationMember(row organizationRowScanner) (*service.OrganizationMember, error) {
+	var m service.OrganizationMember
+	var spendCap sql.NullFloat64
+	if err := row.Scan(&m.ID, &m.OrganizationID, &m.UserID, &m.Email, &m.Username, &m.Role,
+		&spendCap, &m.MonthlySpentUSD, &m.SpendMonthStart, &m.CreatedAt, &m.UpdatedAt); err != nil {
+		return nil, err
+	}
+	m.MonthlySpendCapUSD = nullFloat64Ptr(spendCap)
+	return &m, nil
+}
+
+func (r *organizationRepository) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *organizationRepository) Create(ctx context.Context, org *service.Organization) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		if err := tx.QueryRowContext(ctx, `
+INSERT INTO organizations (name, owner_user_id, status)
+VALUES ($1, $2, $3)
+RETURNING id, created_at, updated_at`, org.Name, org.OwnerUserID, org.Status,
+		).Scan(&org.ID, &org.CreatedAt, &org.UpdatedAt); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `
+INSERT INTO organization_members (organization_id, user_id, role)
+VALUES ($1, $2, $3)`, org.ID, org.OwnerUserID, service.OrganizationRoleOwner)
+		return translatePersistenceError(err, nil, service.ErrOrganizationMemberExists)
+	})
+}
+
+func (r *organizationRepository) GetByID(ctx context.Context, id int64) (*service.Organization, error) {
+	org, err := scanOrganization(r.db.QueryRowContext(ctx, organizationSelectSQL+`
+WHERE o.id = $1 AND o.deleted_at IS NULL`, id))
+	if err != nil {
+		return nil, translatePersistenceError(err, service.ErrOrganizationNotFound, nil)
+	}
+	return org, nil
+}
+
+func (r *organizationRepository) List(ctx context.Context, params pagination.PaginationParams, search string) ([]service.Organization, *pagination.PaginationResult, error) {
+	where := " WHERE o.deleted_at IS NULL"
+	args := []any{}
+	if search != "" {
+		args = append(args, "%"+search+"%")
+		where += " AND (o.name ILIKE $1 OR u.email ILIKE $1)"
+	}
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, `
+SELECT COUNT(*) FROM organizations o
+LEFT JOIN users u ON u.id = o.owner_user_id`+where, args...).Scan(&total); err != nil {
+		return nil, nil, err
+	}
+
+	args = append(args, params.Limit(), params.Offset())
+	rows, err := r.db.QueryContext(ctx, organizationSelectSQL+where+
+		" ORDER BY o.id DESC LIMIT $"+strconv.Itoa(len(args)-1)+" OFFSET $"+strconv.Itoa(len(args)), args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	orgs := make([]service.Organization, 0)
+	for rows.Next() {
+		org, err := scanOrganization(rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		orgs = append(orgs, *org)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	return orgs, paginationResultFromTotal(total, params), nil
+}
+
+func (r *organizationRepository) Update(ctx context.Context, org *service.Organization) error {
+	res, err := r.db.ExecContext(ctx, `
+UPDATE organizations
+SET name = $2, status = $3, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL`, org.ID, org.Name, org.Status)
+	return requireOrganizationRowsAffected(res, err, service.ErrOrganizationNotFound)
+}
+
+// Delete 软删除组织，同时解除成员关系与订阅共享，成员随即回落到个人余额计费。
+func (r *organizationRepository) Delete(ctx context.Context, id int64) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `
+UPDATE organizations
+SET deleted_at = NOW(), status = $2, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL`, id, service.OrganizationStatusDisabled)
+		if err := requireOrganizationRowsAffected(res, err, service.ErrOrganizationNotFound); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM organization_subscriptions WHERE organization_id = $1`, id); err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `DELETE FROM organization_members WHERE organization_id = $1`, id)
+		return err
+	})
+}
+
+// AdjustBalance 正数充值、负数扣减；扣减不允许把钱包扣成负数。
+func (r *organizationRepository) AdjustBalance(ctx context.Context, id int64, delta float64) (float64, error) {
+	var balance float64
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		if err := applyBalanceLedgerTag(ctx, tx, service.BalanceLedgerTag{
+			Type:          service.BalanceLedgerTypeAdminAdjustment,
+			ReferenceType: service.BalanceLedgerRefOrganization,
+			ReferenceID:   strconv.FormatInt(id, 10),
+		}); err != nil {
+			return err
+		}
+		return tx.QueryRowContext(ctx, `
+UPDATE organizations
+SET balance = balance + $2, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL AND ($2 >= 0 OR balance + $2 >= 0)
+RETURNING balance`, id, delta).Scan(&balance)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, getErr := r.GetByID(ctx, id); getErr != nil {
+			return 0, getErr
+		}
+		return 0, service.ErrOrganizationInsufficientBalance
+	}
+	return balance, err
+}
+
+func (r *organizationRepository) TransferFromUser(ctx context.Context, id, userID int64, amount float64) (float64, error) {
+	var orgBalance float64
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		if err := applyBalanceLedgerTag(ctx, tx, service.BalanceLedgerTag{
+			Type:          service.BalanceLedgerTypeOrganizationTransfer,
+			ReferenceType: service.BalanceLedgerRefOrganization,
+			ReferenceID:   strconv.FormatInt(id, 10),
+		}); err != nil {
+			return err
+		}
+		var userBalance float64
+		err := tx.QueryRowContext(ctx, `
+UPDATE users
+SET balance = balance - $2, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL AND balance >= $2
+RETURNING balance`, userID, amount).Scan(&userBalance)
+		if errors.Is(err, sql.ErrNoRows) {
+			return service.ErrOrganizationInsufficientBalance
+		}
+		if err != nil {
+			return err
+		}
+		err = tx.QueryRowContext(ctx, `
+UPDATE organizations
+SET balance = balance + $2, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING balance`, id, amount).Scan(&orgBalance)
+		return translatePersistenceError(err, service.ErrOrganizationNotFound, nil)
+	})
+	return orgBalance, err
+}
+
+// ---- Members ----
+
+func (r *organizationRepository) GetMemberByUserID(ctx context.Context, userID int64) (*service.OrganizationMember, error) {
+	member, err := scanOrganizationMember(r.db.QueryRowContext(ctx, organizationMemberSelectSQL+`
+WHERE m.user_id = $1`, userID))
+	if err != nil {
+		return nil, translatePersistenceError(err, service.ErrOrganizationMemberNotFound, nil)
+	}
+	return member, nil
+}
+
+func (r *organizationRepository) ListMembers(ctx context.Context, organizationID int64) ([]service.OrganizationMember, error) {
+	rows, err := r.db.QueryContext(ctx, organizationMemberSelectSQL+`
+WHERE m.organization_id = $1
+ORDER BY CASE m.role WHEN 'owner' THEN 0 WHEN 'admin' THEN 1 ELSE 2 END, m.id`, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	members := make([]service.OrganizationMember, 0)
+	for rows.Next() {
+		member, err := scanOrganizationMember(rows)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, *member)
+	}
+	return members, rows.Err()
+}
+
+func (r *organizationRepository) AddMember(ctx context.Context, member *service.OrganizationMember) error {
+	err := r.db.QueryRowContext(ctx, `
+INSERT INTO organization_members (organization_id, user_id, role, monthly_spend_cap_usd)
+VALUES ($1, $2, $3, $4)
+RETURNING id, created_at, updated_at`,
+		member.OrganizationID, member.UserID, member.Role, nullFloat64(member.MonthlySpendCapUSD),
+	).Scan(&member.ID, &member.CreatedAt, &member.UpdatedAt)
+	return translatePersistenceError(err, nil, service.ErrOrganizationMemberExists)
+}
+
+func (r *organizationRepository) UpdateMember(ctx context.Context, member *service.OrganizationMember) error {
+	res, err := r.db.ExecContext(ctx, `
+UPDATE organization_members
+SET role = $3, monthly_spend_cap_usd = $4, updated_at = NOW()
+WHERE organization_id = $1 AND user_id = $2`,
+		member.OrganizationID, member.UserID, member.Role, nullFloat64(member.MonthlySpendCapUSD))
+	return requireOrganizationRowsAffected(res, err, service.ErrOrganizationMemberNotFound)
+}
+
+func (r *organizationRepository) RemoveMember(ctx context.Context, organizationID, userID int64) error {
+	res, err := r.db.ExecContext(ctx, `
+DELETE FROM organization_members
+WHERE organization_id = $1 AND user_id = $2`, organizationID, userID)
+	return requireOrganizationRowsAffected(res, err, service.ErrOrganizationMemberNotFound)
+}
+
+func (r *organizationRepository) GetWalletByUserID(ctx context.Context, userID int64) (*service.OrganizationWallet, error) {
+	var wallet service.OrganizationWallet
+	var spendCap sql.NullFloat64
+	err := r.db.QueryRowContext(ctx, `
+SELECT o.id, o.balance, m.monthly_spend_cap_usd,
+    CASE WHEN m.spend_month_start < `+organizationMonthStartSQL+` THEN 0 ELSE m.monthly_spent_usd END
+FROM organization_members m
+JOIN organizations o ON o.id = m.organization_id
+WHERE m.user_id = $1 AND o.status = $2 AND o.deleted_at IS NULL`, userID, service.OrganizationStatusActive,
+	).Scan(&wallet.OrganizationID, &wallet.Balance, &spendCap, &wallet.MonthlySpentUSD)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	wallet.MonthlySpendCapUSD = nullFloat64Ptr(spendCap)
+	return &wallet, nil
+}
+
+// ChargeMemberWallet 与 usage billing 的组织钱包扣费共用同一条 SQL，供 legacy 计费路径使用。
+func (r *organizationRepository) ChargeMemberWallet(ctx context.Context, userID int64, amount float64) (bool, error) {
+	var charged bool
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		if tag, ok := service.BalanceLedgerTagFromContext(ctx); ok {
+			if err := applyBalanceLedgerTag(ctx, tx, tag); err != nil {
+				return err
+			}
+		}
+		var err error
+		_, _, charged, err = deductUsageBillingOrganizationWallet(ctx, tx, userID, amount)
+		return err
+	})
+	return charged, err
+}
+
+// ---- Invitations ----
+
+const organizationInvitationSelectSQL = `
+SELECT i.id, i.organization_id, o.name, i.user_id, COALESCE(u.email, ''), i.invited_by_user_id,
+    COALESCE(ib.email, ''), i.role, i.monthly_spend_cap_usd, i.status, i.responded_at, i.created_at
+FROM organization_invitations i
+JOIN organizations o ON o.id = i.organization_id AND o.deleted_at IS NULL
+LEFT JOIN users u ON u.id = i.user_id
+LEFT JOIN users ib ON ib.id = i.invited_by_user_id`
+
+func scanOrganizationInvitation(row organizationRowScanner) (*service.OrganizationInvitation, error) {
+	var inv service.OrganizationInvitation
+	var spendCap sql.NullFloat64
+	var respondedAt sql.NullTime
+	if err := row.Scan(&inv.ID, &inv.OrganizationID, &inv.OrganizationName, &inv.UserID, &inv.Email, &inv.InvitedByUserID,
+		&inv.InvitedByEmail, &inv.Role, &spendCap, &inv.Status, &respondedAt, &inv.CreatedAt); err != nil {
+		return nil, err
+	}
+	inv.MonthlySpendCapUSD = nullFloat64Ptr(spendCap)
+	if respondedAt.Valid {
+		inv.RespondedAt = &respondedAt.Time
+	}
+	return &inv, nil
+}
+
+func (r *organizationRepository) CreateInvitation(ctx context.Context, invitation *service.OrganizationInvitation) error {
+	err := r.db.QueryRowContext(ctx, `
+INSERT INTO organization_invitations (organization_id, user_id, invited_by_user_id, role, monthly_spend_cap_usd, status)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, created_at`,
+		invitation.OrganizationID, invitation.UserID, invitation.InvitedByUserID, invitation.Role,
+		nullFloat64(invitation.MonthlySpendCapUSD), service.OrganizationInvitationPending,
+	).Scan(&invitation.ID, &invitation.CreatedAt)
+	return translatePersistenceError(err, nil, service.ErrOrganizationInvitationExists)
+}
+
+func (r *organizationRepository) GetInvitation(ctx context.Context, id int64) (*service.OrganizationInvitation, error) {
+	invitation, err := scanOrganizationInvitation(r.db.QueryRowContext(ctx, organizationInvitationSelectSQL+`
+WHERE i.id = $1`, id))
+	if err != nil {
+		return nil, translatePersistenceError(err, service.ErrOrganizationInvitationNotFound, nil)
+	}
+	return invitation, nil
+}
+
+func (r *organizationRepository) ListInvitations(ctx context.Context, organizationID int64) ([]service.OrganizationInvitation, error) {
+	return r.listInvitations(ctx, `
+WHERE i.organization_id = $1 AND i.status = $2
+ORDER BY i.id DESC`, organizationID, service.OrganizationInvitationPending)
+}
+
+func (r *organizationRepository) ListInvitationsForUser(ctx context.Context, userID int64) ([]service.OrganizationInvitation, error) {
+	return r.listInvitations(ctx, `
+WHERE i.user_id = $1 AND i.status = $2 AND o.status = $3
+ORDER BY i.id DESC`, userID, service.OrganizationInvitationPending, service.OrganizationStatusActive)
+}
+
+func (r *organizationRepository) listInvitations(ctx context.Context, where string, args ...any) ([]service.OrganizationInvitation, error) {
+	rows, err := r.db.QueryContext(ctx, organizationInvitationSelectSQL+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	invitations := make([]service.OrganizationInvitation, 0)
+	for rows.Next() {
+		invitation, err := scanOrganizationInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, *invitation)
+	}
+	return invitations, rows.Err()
+}
+
+// AcceptInvitation 锁定 pending 邀请后写入成员；组织已删除或停用时视为邀请失效。
+func (r *organizationRepository) AcceptInvitation(ctx context.Context, id, userID int64) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		var organizationID int64
+		var role string
+		var spendCap sql.NullFloat64
+		err := tx.QueryRowContext(ctx, `
+UPDATE organization_invitations i
+SET status = $3, responded_at = NOW(), updated_at = NOW()
+FROM organizations o
+WHERE i.id = $1 AND i.user_id = $2 AND i.status = $4
+    AND o.id = i.organization_id AND o.deleted_at IS NULL AND o.status = $5
+RETURNING i.organization_id, i.role, i.monthly_spend_cap_usd`,
+			id, userID, service.OrganizationInvitationAccepted, service.OrganizationInvitationPending, service.OrganizationStatusActive,
+		).Scan(&organizationID, &role, &spendCap)
+		if err != nil {
+			return translatePersistenceError(err, service.ErrOrganizationInvitationNotFound, nil)
+		}
+		_, err = tx.ExecContext(ctx, `
+INSERT INTO organization_members (organization_id, user_id, role, monthly_spend_cap_usd)
+VALUES ($1, $2, $3, $4)`, organizationID, userID, role, spendCap)
+		return translatePersistenceError(err, nil, service.ErrOrganizationMemberExists)
+	})
+}
+
+func (r *organizationRepository) CloseInvitation(ctx context.Context, id int64, status string) error {
+	res, err := r.db.ExecContext(ctx, `
+UPDATE organization_invitations
+SET status = $2, responded_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND status = $3`, id, status, service.OrganizationInvitationPending)
+	return requireOrganizationRowsAffected(res, err, service.ErrOrganizationInvitationNotFound)
+}
+
+// ---- Subscriptions ----
+
+func (r *organizationRepository) UpsertSubscription(ctx context.Context, organizationID, groupID, subscriptionID int64) error {
+	_, err := r.db.ExecContext(ctx, `
+INSERT INTO organization_subscriptions (organization_id, group_id, user_subscription_id)
+VALUES ($1, $2, $3)
+ON CONFLICT (organization_id, group_id) DO UPDATE SET user_subscription_id = EXCLUDED.user_subscription_id`,
+		organizationID, groupID, subscriptionID)
+	return err
+}
+
+func (r *organizationRepository) ListSubscriptions(ctx context.Context, organizationID int64) ([]service.OrganizationSubscription, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT os.organization_id, os.group_id, COALESCE(g.name, ''), us.id, us.status, us.expires_at,
+    us.daily_usage_usd, us.weekly_usage_usd, us.monthly_usage_usd, os.created_at
+FROM organization_subscriptions os
+JOIN user_subscriptions us ON us.id = os.user_subscription_id
+LEFT JOIN groups g ON g.id = os.group_id
+WHERE os.organization_id = $1
+ORDER BY os.id`, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	subs := make([]service.OrganizationSubscription, 0)
+	for rows.Next() {
+		var sub service.OrganizationSubscription
+		if err := rows.Scan(&sub.OrganizationID, &sub.GroupID, &sub.GroupName, &sub.SubscriptionID, &sub.Status, &sub.ExpiresAt,
+			&sub.DailyUsageUSD, &sub.WeeklyUsageUSD, &sub.MonthlyUsageUSD, &sub.CreatedAt); err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+func (r *organizationRepository) DeleteSubscription(ctx context.Context, organizationID, groupID int64) (int64, error) {
+	var subscriptionID int64
+	err := r.db.QueryRowContext(ctx, `
+DELETE FROM organization_subscriptions
+WHERE organization_id = $1 AND group_id = $2
+RETURNING user_subscription_id`, organizationID, groupID).Scan(&subscriptionID)
+	if err != nil {
+		return 0, translatePersistenceError(err, service.ErrOrganizationSubscriptionNotFound, nil)
+	}
+	return subscriptionID, nil
+}
+
+func (r *organizationRepository) GetSubscriptionIDForMember(ctx context.Context, userID, groupID int64) (int64, error) {
+	var subscriptionID int64
+	err := r.db.QueryRowContext(ctx, `
+SELECT os.user_subscription_id
+FROM organization_members m
+JOIN organizations o ON o.id = m.organization_id AND o.status = $3 AND o.deleted_at IS NULL
+JOIN organization_subscriptions os ON os.organization_id = m.organization_id AND os.group_id = $2
+WHERE m.user_id = $1`, userID, groupID, service.OrganizationStatusActive).Scan(&subscriptionID)
+	if err != nil {
+		return 0, translatePersistenceError(err, service.ErrSubscriptionNotFound, nil)
+	}
+	return subscriptionID, nil
+}
+
+func requireOrganizationRowsAffected(res sql.Result, err error, notFound error) error {
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return notFound
+	}
+	return nil
+}
+
+func nullFloat64(v *float64) sql.NullFloat64 {
+	if v == nil {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: *v, Valid: true}
+}
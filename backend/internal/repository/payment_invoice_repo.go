@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+const paymentInvoiceSelect = `
+SELECT i.id, i.kind, i.invoice_number, i.order_id, i.user_id, i.related_invoice_id, COALESCE(ri.invoice_number, ''),
+    i.currency, i.amount, i.description, i.seller, i.buyer, i.footer, i.issued_at
+FROM payment_invoices i
+LEFT JOIN payment_invoices ri ON ri.id = i.related_invoice_id`
+
+type paymentInvoiceRepository struct {
+	db *sql.DB
+}
+
+func NewPaymentInvoiceRepository(db *sql.DB) service.PaymentInvoiceRepository {
+	return &paymentInvoiceRepository{db: db}
+}
+
+func scanPaymentInvoice(row interface{ Scan(dest ...any) error }) (*service.PaymentInvoice, error) {
+	var (
+		inv       service.PaymentInvoice
+		relatedID sql.NullInt64
+		seller    []byte
+		buyer     []byte
+	)
+	if err := row.Scan(&inv.ID, &inv.Kind, &inv.InvoiceNumber, &inv.OrderID, &inv.UserID, &relatedID, &inv.RelatedInvoiceNumber,
+		&inv.Currency, &inv.Amount, &inv.Description, &seller, &buyer, &inv.Footer, &inv.IssuedAt); err != nil {
+		return nil, err
+	}
+	if relatedID.Valid {
+		inv.RelatedInvoiceID = &relatedID.Int64
+	}
+	if err := json.Unmarshal(seller, &inv.Seller); err != nil {
+		return nil, fmt.Errorf("decode invoice seller: %w", err)
+	}
+	if err := json.Unmarshal(buyer, &inv.Buyer); err != nil {
+		return nil, fmt.Errorf("decode invoice buyer: %w", err)
+	}
+	return &inv, nil
+}
+
+func (r *paymentInvoiceRepository) Issue(ctx context.Context, in service.PaymentInvoiceIssue) (*service.PaymentInvoice, error) {
+	existing, err := r.GetByOrderAndKind(ctx, in.OrderID, in.Kind)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, service.ErrPaymentInvoiceNotFound) {
+		return nil, err
+	}
+
+	seller, err := json.Marshal(in.Seller)
+	if err != nil {
+		return nil, err
+	}
+	buyer, err := json.Marshal(in.Buyer)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// 序列行的行锁使同一前缀与币种的开具串行化，编号随事务提交或回滚，保证连续无空号。
+	var seq int64
+	if err := tx.QueryRowContext(ctx, `
+INSERT INTO payment_invoice_sequences (prefix, currency, last_value)
+VALUES ($1, $2, 1)
+ON CONFLICT (prefix, currency) DO UPDATE
+SET last_value = payment_invoice_sequences.last_value + 1, updated_at = NOW()
+RETURNING last_value`, in.NumberPrefix, in.Currency).Scan(&seq); err != nil {
+		return nil, err
+	}
+
+	var id int64
+	err = tx.QueryRowContext(ctx, `
+INSERT INTO payment_invoices
+    (kind, invoice_number, sequence_value, order_id, user_id, related_invoice_id, currency, amount,
+     description, seller, buyer, footer)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+ON CONFLICT (order_id, kind) DO NOTHING
+RETURNING id`,
+		in.Kind, service.FormatPaymentInvoiceNumber(in.NumberPrefix, in.Currency, seq), seq, in.OrderID, in.UserID,
+		in.RelatedInvoiceID, in.Currency, in.Amount, in.Description, seller, buyer, in.Footer,
+	).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		// 并发开具同一单据：放弃本事务以释放编号，返回已写入的单据。
+		_ = tx.Rollback()
+		return r.GetByOrderAndKind(ctx, in.OrderID, in.Kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, id)
+}
+
+func (r *paymentInvoiceRepository) GetByID(ctx context.Context, id int64) (*service.PaymentInvoice, error) {
+	inv, err := scanPaymentInvoice(r.db.QueryRowContext(ctx, paymentInvoiceSelect+`
+WHERE i.id = $1`, id))
+	if err != nil {
+		return nil, translatePersistenceError(err, service.ErrPaymentInvoiceNotFound, nil)
+	}
+	return inv, nil
+}
+
+func (r *paymentInvoiceRepository) GetByOrderAndKind(ctx context.Context, orderID int64, kind string) (*service.PaymentInvoice, error) {
+	inv, err := scanPaymentInvoice(r.db.QueryRowContext(ctx, paymentInvoiceSelect+`
+WHERE i.order_id = $1 AND i.kind = $2`, orderID, kind))
+	if err != nil {
+		return nil, translatePersistenceError(err, service.ErrPaymentInvoiceNotFound, nil)
+	}
+	return inv, nil
+}
+
+func (r *paymentInvoiceRepository) ListByOrder(ctx context.Context, orderID int64) ([]service.PaymentInvoice, error) {
+	rows, err := r.db.QueryContext(ctx, paymentInvoiceSelect+`
+WHERE i.order_id = $1
+ORDER BY i.id`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.PaymentInvoice, 0, 2)
+	for rows.Next() {
+		inv, err := scanPaymentInvoice(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *inv)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/servertiming"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+// responseCacheExpiresAtMeta S3 对象元数据中记录的过期时间（unix 秒）。
+const responseCacheExpiresAtMeta = "expires-at"
+
+type redisResponseCacheStore struct {
+	rdb *redis.Client
+}
+
+// NewRedisResponseCacheStore 基于 Redis 的响应缓存，过期交给 key TTL。
+func NewRedisResponseCacheStore(rdb *redis.Client) service.ResponseCacheStore {
+	return &redisResponseCacheStore{rdb: rdb}
+}
+
+func (s *redisResponseCacheStore) Get(ctx context.Context, key string) (*service.ResponseCacheEntry, error) {
+	raw, err := s.rdb.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, service.ErrResponseCacheMiss
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entry service.ResponseCacheEntry
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return nil, service.ErrResponseCacheMiss
+	}
+	return &entry, nil
+}
+
+func (s *redisResponseCacheStore) Set(ctx context.Context, key string, entry *service.ResponseCacheEntry, ttl time.Duration) error {
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return s.rdb.Set(ctx, key, raw, ttl).Err()
+}
+
+type s3ResponseCacheStore struct {
+	client *s3.Client
+	bucket string
+	prefix string
+}
+
+// NewS3ResponseCacheStore 基于 S3 兼容对象存储的响应缓存，适合体积较大的响应。
+// 对象存储没有 key 级 TTL，过期时间写入对象元数据并在读取时判断。
+func NewS3ResponseCacheStore(ctx context.Context, cfg *config.ResponseCacheS3Config) (service.ResponseCacheStore, error) {
+	client, err := newS3Client(ctx, s3ClientParams{
+		Endpoint:        cfg.Endpoint,
+		Region:          cfg.Region,
+		AccessKeyID:     cfg.AccessKeyID,
+		SecretAccessKey: cfg.SecretAccessKey,
+		ForcePathStyle:  cfg.ForcePathStyle,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &s3ResponseCacheStore{client: client, bucket: cfg.Bucket, prefix: cfg.Prefix}, nil
+}
+
+func (s *s3ResponseCacheStore) objectKey(key string) string {
+	return s.prefix + strings.ReplaceAll(key, ":", "/")
+}
+
+func (s *s3ResponseCacheStore) Get(ctx context.Context, key string) (*service.ResponseCacheEntry, error) {
+	objectKey := s.objectKey(key)
+	finish := servertiming.ObserveDependency(ctx, "s3")
+	result, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: &s.bucket,
+		Key:    &objectKey,
+	})
+	finish()
+	if err != nil {
+		var noSuchKey *s3types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, service.ErrResponseCacheMiss
+		}
+		return nil, fmt.Errorf("S3 GetObject: %w", err)
+	}
+	defer func() { _ = result.Body.Close() }()
+
+	if expiresAt, err := strconv.ParseInt(result.Metadata[responseCacheExpiresAtMeta], 10, 64); err != nil || time.Now().Unix() >= expiresAt {
+		return nil, service.ErrResponseCacheMiss
+	}
+	raw, err := io.ReadAll(result.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read S3 object: %w", err)
+	}
+	var entry service.ResponseCacheEntry
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return nil, service.ErrResponseCacheMiss
+	}
+	return &entry, nil
+}
+
+func (s *s3ResponseCacheStore) Set(ctx context.Context, key string, entry *service.ResponseCacheEntry, ttl time.Duration) error {
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	objectKey := s.objectKey(key)
+	contentType := "application/json"
+	expires := time.Now().Add(ttl)
+	finish := servertiming.ObserveDependency(ctx, "s3")
+	_, err = s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      &s.bucket,
+		Key:         &objectKey,
+		Body:        bytes.NewReader(raw),
+		ContentType: &contentType,
+		Expires:     &expires,
+		Metadata:    map[string]string{responseCacheExpiresAtMeta: strconv.FormatInt(expires.Unix(), 10)},
+	})
+	finish()
+	if err != nil {
+		return fmt.Errorf("S3 PutObject: %w", err)
+	}
+	return nil
+}
+
+// ProvideResponseCacheStore 按 response_cache.backend 选择存储后端。
+func ProvideResponseCacheStore(rdb *redis.Client, cfg *config.Config) (service.ResponseCacheStore, error) {
+	if cfg.ResponseCache.Enabled && cfg.ResponseCache.Backend == "s3" {
+		return NewS3ResponseCacheStore(context.Background(), &cfg.ResponseCache.S3)
+	}
+	return NewRedisResponseCacheStore(rdb), nil
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const samlAssertionUsedKeyPrefix = "saml:assertion_used:"
+
+type samlReplayCache struct {
+	rdb *redis.Client
+}
+
+func NewSAMLReplayCache(rdb *redis.Client) service.SAMLAssertionReplayCache {
+	return &samlReplayCache{rdb: rdb}
+}
+
+func (c *samlReplayCache) MarkSAMLAssertionUsed(ctx context.Context, issuer, assertionID string, ttl time.Duration) (bool, error) {
+	return c.rdb.SetNX(ctx, samlAssertionUsedKey(issuer, assertionID), 1, ttl).Result()
+}
+
+// samlAssertionUsedKey 对 issuer 与断言 ID 取摘要，避免 IdP 提供的任意字符串直接进入 key。
+func samlAssertionUsedKey(issuer, assertionID string) string {
+	sum := sha256.Sum256([]byte(issuer + "\x1f" + assertionID))
+	return samlAssertionUsedKeyPrefix + hex.EncodeToString(sum[:])
+}
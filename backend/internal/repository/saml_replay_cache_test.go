@@ -0,0 +1,39 @@
+//go:build unit
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSAMLReplayCache_MarkOnceUntilTTL(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	cache := NewSAMLReplayCache(rdb)
+
+	first, err := cache.MarkSAMLAssertionUsed(ctx, "https://idp.example.com", "_a1", 5*time.Minute)
+	require.NoError(t, err)
+	require.True(t, first)
+	require.Equal(t, 5*time.Minute, mr.TTL(samlAssertionUsedKey("https://idp.example.com", "_a1")))
+
+	again, err := cache.MarkSAMLAssertionUsed(ctx, "https://idp.example.com", "_a1", 5*time.Minute)
+	require.NoError(t, err)
+	require.False(t, again)
+
+	other, err := cache.MarkSAMLAssertionUsed(ctx, "https://other-idp.example.com", "_a1", 5*time.Minute)
+	require.NoError(t, err)
+	require.True(t, other)
+
+	mr.FastForward(6 * time.Minute)
+	expired, err := cache.MarkSAMLAssertionUsed(ctx, "https://idp.example.com", "_a1", 5*time.Minute)
+	require.NoError(t, err)
+	require.True(t, expired)
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type spendAnomalyRepository struct {
+	db *sql.DB
+}
+
+func NewSpendAnomalyRepository(db *sql.DB) service.SpendAnomalyRepository {
+	return &spendAnomalyRepository{db: db}
+}
+
+// 先按窗口消费筛出候选，再对少量候选做基线区间的 LATERAL 汇总，避免全表聚合历史数据。
+const spendAnomalyUserCandidatesQuery = `
+SELECT c.user_id, u.created_at, c.spend, COALESCE(h.spend, 0)
+FROM (
+    SELECT user_id, SUM(actual_cost) AS spend
+    FROM usage_logs
+    WHERE created_at >= $2 AND created_at < $3
+    GROUP BY user_id
+    HAVING SUM(actual_cost) >= $4
+) c
+JOIN users u ON u.id = c.user_id AND u.deleted_at IS NULL
+LEFT JOIN LATERAL (
+    SELECT SUM(ul.actual_cost) AS spend
+    FROM usage_logs ul
+    WHERE ul.user_id = c.user_id AND ul.created_at >= $1 AND ul.created_at < $2
+) h ON TRUE`
+
+const spendAnomalyAPIKeyCandidatesQuery = `
+SELECT c.user_id, c.api_key_id, k.name, k.created_at, c.spend, COALESCE(h.spend, 0)
+FROM (
+    SELECT user_id, api_key_id, SUM(actual_cost) AS spend
+    FROM usage_logs
+    WHERE created_at >= $2 AND created_at < $3
+    GROUP BY user_id, api_key_id
+    HAVING SUM(actual_cost) >= $4
+) c
+JOIN api_keys k ON k.id = c.api_key_id AND k.deleted_at IS NULL
+LEFT JOIN LATERAL (
+    SELECT SUM(ul.actual_cost) AS spend
+    FROM usage_logs ul
+    WHERE ul.api_key_id = c.api_key_id AND ul.created_at >= $1 AND ul.created_at < $2
+) h ON TRUE`
+
+func (r *spendAnomalyRepository) ListSpendCandidates(ctx context.Context, baselineStart, windowStart, windowEnd time.Time, minSpend float64, byAPIKey bool) ([]service.SpendAnomalyCandidate, error) {
+	query := spendAnomalyUserCandidatesQuery
+	if byAPIKey {
+		query = spendAnomalyAPIKeyCandidatesQuery
+	}
+	rows, err := r.db.QueryContext(ctx, query, baselineStart, windowStart, windowEnd, minSpend)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var candidates []service.SpendAnomalyCandidate
+	for rows.Next() {
+		var c service.SpendAnomalyCandidate
+		if byAPIKey {
+			err = rows.Scan(&c.UserID, &c.APIKeyID, &c.APIKeyName, &c.CreatedAt, &c.WindowSpend, &c.HistorySpend)
+		} else {
+			err = rows.Scan(&c.UserID, &c.CreatedAt, &c.WindowSpend, &c.HistorySpend)
+		}
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, rows.Err()
+}
+
+func (r *spendAnomalyRepository) LastEventAt(ctx context.Context, userID, apiKeyID int64) (*time.Time, error) {
+	var (
+		at  time.Time
+		err error
+	)
+	if apiKeyID > 0 {
+		err = r.db.QueryRowContext(ctx, `
+SELECT created_at FROM spend_anomaly_events
+WHERE user_id = $1 AND api_key_id = $2
+ORDER BY created_at DESC LIMIT 1`, userID, apiKeyID).Scan(&at)
+	} else {
+		err = r.db.QueryRowContext(ctx, `
+SELECT created_at FROM spend_anomaly_events
+WHERE user_id = $1 AND api_key_id IS NULL
+ORDER BY created_at DESC LIMIT 1`, userID).Scan(&at)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &at, nil
+}
+
+func (r *spendAnomalyRepository) CreateEvent(ctx context.Context, event *service.SpendAnomalyEvent) error {
+	return r.db.QueryRowContext(ctx, `
+INSERT INTO spend_anomaly_events (user_id, api_key_id, api_key_name, window_start, window_end,
+    window_spend, baseline_spend, ratio, key_disabled)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, created_at`,
+		event.UserID, event.APIKeyID, event.APIKeyName, event.WindowStart, event.WindowEnd,
+		event.WindowSpend, event.BaselineSpend, event.Ratio, event.KeyDisabled,
+	).Scan(&event.ID, &event.CreatedAt)
+}
+
+func (r *spendAnomalyRepository) ListEvents(ctx context.Context, filter service.SpendAnomalyFilter) ([]service.SpendAnomalyEvent, int64, error) {
+	conds := []string{"TRUE"}
+	args := []any{}
+	if filter.UserID > 0 {
+		args = append(args, filter.UserID)
+		conds = append(conds, "e.user_id = $"+itoa(len(args)))
+	}
+	where := "WHERE " + strings.Join(conds, " AND ")
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM spend_anomaly_events e "+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, filter.PageSize, (filter.Page-1)*filter.PageSize)
+	rows, err := r.db.QueryContext(ctx, `
+SELECT e.id, e.user_id, COALESCE(u.email, ''), e.api_key_id, e.api_key_name, e.window_start, e.window_end,
+    e.window_spend, e.baseline_spend, e.ratio, e.key_disabled, e.created_at
+FROM spend_anomaly_events e
+LEFT JOIN users u ON u.id = e.user_id
+`+where+`
+ORDER BY e.created_at DESC, e.id DESC
+LIMIT $`+itoa(len(args)-1)+` OFFSET $`+itoa(len(args)), args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = rows.Close() }()
+	events := make([]service.SpendAnomalyEvent, 0, filter.PageSize)
+	for rows.Next() {
+		var (
+			e        service.SpendAnomalyEvent
+			apiKeyID sql.NullInt64
+		)
+		if err := rows.Scan(&e.ID, &e.UserID, &e.UserEmail, &apiKeyID, &e.APIKeyName, &e.WindowStart, &e.WindowEnd,
+			&e.WindowSpend, &e.BaselineSpend, &e.Ratio, &e.KeyDisabled, &e.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		if apiKeyID.Valid {
+			e.APIKeyID = &apiKeyID.Int64
+		}
+		events = append(events, e)
+	}
+	return events, total, rows.Err()
+}
+
+func (r *spendAnomalyRepository) DisableAPIKey(ctx context.Context, apiKeyID int64) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+UPDATE api_keys SET status = $2, updated_at = NOW()
+WHERE id = $1 AND status = $3 AND deleted_at IS NULL`, apiKeyID, service.StatusAPIKeyDisabled, service.StatusAPIKeyActive)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
@@ -0,0 +1,252 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+const subscriptionAutoRenewalColumns = `
+r.id, r.user_id, r.group_id, r.plan_id, r.setup_order_id, r.provider_instance_id, r.provider_key,
+r.payment_type, r.customer_ref, r.payment_method_ref, r.status, r.failure_count, r.next_attempt_at,
+r.last_order_id, r.last_error, r.last_charged_at, r.cancelled_at, r.created_at, r.updated_at`
+
+// subscriptionAutoRenewalLiveStatusSQL 仍会发起扣款的协议状态。
+const subscriptionAutoRenewalLiveStatusSQL = `('active', 'past_due')`
+
+type subscriptionAutoRenewalRepository struct {
+	db *sql.DB
+}
+
+func NewSubscriptionAutoRenewalRepository(db *sql.DB) service.SubscriptionAutoRenewalRepository {
+	return &subscriptionAutoRenewalRepository{db: db}
+}
+
+type subscriptionAutoRenewalRowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSubscriptionAutoRenewal(row subscriptionAutoRenewalRowScanner, extra ...any) (*service.SubscriptionAutoRenewal, error) {
+	var (
+		r             service.SubscriptionAutoRenewal
+		nextAttemptAt sql.NullTime
+		lastOrderID   sql.NullInt64
+		lastError     sql.NullString
+		lastChargedAt sql.NullTime
+		cancelledAt   sql.NullTime
+	)
+	dest := []any{&r.ID, &r.UserID, &r.GroupID, &r.PlanID, &r.SetupOrderID, &r.ProviderInstanceID, &r.ProviderKey,
+		&r.PaymentType, &r.CustomerRef, &r.PaymentMethodRef, &r.Status, &r.FailureCount, &nextAttemptAt,
+		&lastOrderID, &lastError, &lastChargedAt, &cancelledAt, &r.CreatedAt, &r.UpdatedAt}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	r.NextAttemptAt = subscriptionAutoRenewalNullTime(nextAttemptAt)
+	if lastOrderID.Valid {
+		r.LastOrderID = &lastOrderID.Int64
+	}
+	r.LastError = lastError.String
+	r.LastChargedAt = subscriptionAutoRenewalNullTime(lastChargedAt)
+	r.CancelledAt = subscriptionAutoRenewalNullTime(cancelledAt)
+	return &r, nil
+}
+
+func subscriptionAutoRenewalNullTime(v sql.NullTime) *time.Time {
+	if !v.Valid {
+		return nil
+	}
+	t := v.Time
+	return &t
+}
+
+func (r *subscriptionAutoRenewalRepository) Create(ctx context.Context, renewal *service.SubscriptionAutoRenewal) error {
+	return r.db.QueryRowContext(ctx, `
+INSERT INTO subscription_auto_renewals
+    (user_id, group_id, plan_id, setup_order_id, provider_instance_id, provider_key, payment_type, status)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, created_at, updated_at`,
+		renewal.UserID, renewal.GroupID, renewal.PlanID, renewal.SetupOrderID, renewal.ProviderInstanceID,
+		renewal.ProviderKey, renewal.PaymentType, renewal.Status,
+	).Scan(&renewal.ID, &renewal.CreatedAt, &renewal.UpdatedAt)
+}
+
+func (r *subscriptionAutoRenewalRepository) GetBySetupOrderID(ctx context.Context, orderID int64) (*service.SubscriptionAutoRenewal, error) {
+	renewal, err := scanSubscriptionAutoRenewal(r.db.QueryRowContext(ctx, `
+SELECT`+subscriptionAutoRenewalColumns+`
+FROM subscription_auto_renewals r
+WHERE r.setup_order_id = $1`, orderID))
+	if err != nil {
+		return nil, translatePersistenceError(err, service.ErrAutoRenewalNotFound, nil)
+	}
+	return renewal, nil
+}
+
+func (r *subscriptionAutoRenewalRepository) GetByLastOrderID(ctx context.Context, orderID int64) (*service.SubscriptionAutoRenewal, error) {
+	renewal, err := scanSubscriptionAutoRenewal(r.db.QueryRowContext(ctx, `
+SELECT`+subscriptionAutoRenewalColumns+`
+FROM subscription_auto_renewals r
+WHERE r.last_order_id = $1
+ORDER BY r.id DESC
+LIMIT 1`, orderID))
+	if err != nil {
+		return nil, translatePersistenceError(err, service.ErrAutoRenewalNotFound, nil)
+	}
+	return renewal, nil
+}
+
+func (r *subscriptionAutoRenewalRepository) ListByUser(ctx context.Context, userID int64) ([]service.SubscriptionAutoRenewal, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT`+subscriptionAutoRenewalColumns+`,
+    COALESCE(g.name, ''), COALESCE(p.name, ''), us.expires_at
+FROM subscription_auto_renewals r
+LEFT JOIN groups g ON g.id = r.group_id
+LEFT JOIN subscription_plans p ON p.id = r.plan_id
+LEFT JOIN user_subscriptions us ON us.user_id = r.user_id AND us.group_id = r.group_id AND us.deleted_at IS NULL
+WHERE r.user_id = $1 AND r.status <> 'pending'
+ORDER BY r.id DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.SubscriptionAutoRenewal, 0)
+	for rows.Next() {
+		var groupName, planName string
+		var expiresAt sql.NullTime
+		renewal, err := scanSubscriptionAutoRenewal(rows, &groupName, &planName, &expiresAt)
+		if err != nil {
+			return nil, err
+		}
+		renewal.GroupName = groupName
+		renewal.PlanName = planName
+		renewal.SubscriptionExpiresAt = subscriptionAutoRenewalNullTime(expiresAt)
+		out = append(out, *renewal)
+	}
+	return out, rows.Err()
+}
+
+func (r *subscriptionAutoRenewalRepository) Activate(ctx context.Context, id int64, customerRef, paymentMethodRef string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var userID, groupID int64
+	err = tx.QueryRowContext(ctx, `
+SELECT user_id, group_id FROM subscription_auto_renewals
+WHERE id = $1 AND status = 'pending'
+FOR UPDATE`, id).Scan(&userID, &groupID)
+	if err != nil {
+		// 已被并发回调启用或用户已取消。
+		return translatePersistenceError(err, service.ErrAutoRenewalNotFound, nil)
+	}
+	// 新协议替代同分组下仍在生效的旧协议，保证每个分组只扣一份。
+	if _, err := tx.ExecContext(ctx, `
+UPDATE subscription_auto_renewals
+SET status = 'cancelled', cancelled_at = NOW(), next_attempt_at = NULL, updated_at = NOW()
+WHERE user_id = $1 AND group_id = $2 AND id <> $3 AND status IN `+subscriptionAutoRenewalLiveStatusSQL,
+		userID, groupID, id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+UPDATE subscription_auto_renewals
+SET status = 'active', customer_ref = $2, payment_method_ref = $3, failure_count = 0,
+    next_attempt_at = NULL, last_error = NULL, updated_at = NOW()
+WHERE id = $1`, id, customerRef, paymentMethodRef); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *subscriptionAutoRenewalRepository) ListDue(ctx context.Context, renewBefore, now time.Time, limit int) ([]service.SubscriptionAutoRenewal, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT`+subscriptionAutoRenewalColumns+`, us.expires_at
+FROM subscription_auto_renewals r
+JOIN user_subscriptions us ON us.user_id = r.user_id AND us.group_id = r.group_id
+    AND us.deleted_at IS NULL AND us.status IN ('active', 'expired')
+WHERE r.status IN `+subscriptionAutoRenewalLiveStatusSQL+`
+    AND us.expires_at <= $1
+    AND (r.next_attempt_at IS NULL OR r.next_attempt_at <= $2)
+    AND NOT EXISTS (
+        SELECT 1 FROM payment_orders po WHERE po.id = r.last_order_id AND po.status = 'PENDING'
+    )
+ORDER BY us.expires_at ASC
+LIMIT $3`, renewBefore, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.SubscriptionAutoRenewal, 0)
+	for rows.Next() {
+		var expiresAt time.Time
+		renewal, err := scanSubscriptionAutoRenewal(rows, &expiresAt)
+		if err != nil {
+			return nil, err
+		}
+		renewal.SubscriptionExpiresAt = &expiresAt
+		out = append(out, *renewal)
+	}
+	return out, rows.Err()
+}
+
+func (r *subscriptionAutoRenewalRepository) ClaimAttempt(ctx context.Context, id int64, now, nextAttemptAt time.Time) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+UPDATE subscription_auto_renewals
+SET next_attempt_at = $3, updated_at = NOW()
+WHERE id = $1 AND status IN `+subscriptionAutoRenewalLiveStatusSQL+`
+    AND (next_attempt_at IS NULL OR next_attempt_at <= $2)`, id, now, nextAttemptAt)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (r *subscriptionAutoRenewalRepository) SetLastOrder(ctx context.Context, id, orderID int64) error {
+	_, err := r.db.ExecContext(ctx, `
+UPDATE subscription_auto_renewals SET last_order_id = $2, updated_at = NOW() WHERE id = $1`, id, orderID)
+	return err
+}
+
+func (r *subscriptionAutoRenewalRepository) MarkSucceeded(ctx context.Context, id, orderID int64, chargedAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+UPDATE subscription_auto_renewals
+SET status = 'active', failure_count = 0, next_attempt_at = NULL, last_error = NULL,
+    last_charged_at = $3, updated_at = NOW()
+WHERE id = $1 AND last_order_id = $2 AND status IN `+subscriptionAutoRenewalLiveStatusSQL, id, orderID, chargedAt)
+	return err
+}
+
+func (r *subscriptionAutoRenewalRepository) MarkFailed(ctx context.Context, id int64, status string, failureCount int, lastError string, nextAttemptAt *time.Time) error {
+	// 不覆盖用户已取消的协议。
+	_, err := r.db.ExecContext(ctx, `
+UPDATE subscription_auto_renewals
+SET status = $2, failure_count = $3, last_error = $4, next_attempt_at = $5, updated_at = NOW()
+WHERE id = $1 AND status IN ('pending', 'active', 'past_due')`, id, status, failureCount, lastError, nextAttemptAt)
+	return err
+}
+
+func (r *subscriptionAutoRenewalRepository) Cancel(ctx context.Context, id, userID int64) error {
+	res, err := r.db.ExecContext(ctx, `
+UPDATE subscription_auto_renewals
+SET status = 'cancelled', cancelled_at = NOW(), next_attempt_at = NULL, updated_at = NOW()
+WHERE id = $1 AND user_id = $2 AND status IN ('pending', 'active', 'past_due')`, id, userID)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return service.ErrAutoRenewalNotFound
+	}
+	return nil
+}
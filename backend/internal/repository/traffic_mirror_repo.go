@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type trafficMirrorRepository struct {
+	db *sql.DB
+}
+
+func NewTrafficMirrorRepository(db *sql.DB) service.TrafficMirrorRepository {
+	return &trafficMirrorRepository{db: db}
+}
+
+const trafficMirrorRecordColumns = `group_id, target_group_id, target_account_id, client_request_id, endpoint, model, stream,
+    primary_account_id, primary_status, primary_latency_ms, primary_ttft_ms, primary_input_tokens, primary_output_tokens, primary_model,
+    mirror_account_id, mirror_status, mirror_latency_ms, mirror_ttft_ms, mirror_input_tokens, mirror_output_tokens, mirror_model, mirror_error,
+    output_similarity, primary_output_excerpt, mirror_output_excerpt, created_at`
+
+func (r *trafficMirrorRepository) InsertRecords(ctx context.Context, records []service.TrafficMirrorRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	const cols = 26
+	var sb strings.Builder
+	sb.WriteString("INSERT INTO traffic_mirror_records (" + trafficMirrorRecordColumns + ")\nVALUES ")
+	args := make([]any, 0, len(records)*cols)
+	for i, rec := range records {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString("(")
+		for j := 1; j <= cols; j++ {
+			if j > 1 {
+				sb.WriteString(", ")
+			}
+			fmt.Fprintf(&sb, "$%d", i*cols+j)
+		}
+		sb.WriteString(")")
+		args = append(args,
+			rec.GroupID, rec.TargetGroupID, rec.TargetAccountID, rec.ClientRequestID, rec.Endpoint, rec.Model, rec.Stream,
+			rec.PrimaryAccountID, rec.PrimaryStatus, rec.PrimaryLatencyMs, rec.PrimaryTTFTMs, rec.PrimaryInputTokens, rec.PrimaryOutputTokens, rec.PrimaryModel,
+			rec.MirrorAccountID, rec.MirrorStatus, rec.MirrorLatencyMs, rec.MirrorTTFTMs, rec.MirrorInputTokens, rec.MirrorOutputTokens, rec.MirrorModel, rec.MirrorError,
+			rec.OutputSimilarity, rec.PrimaryOutputExcerpt, rec.MirrorOutputExcerpt, rec.CreatedAt,
+		)
+	}
+	_, err := r.db.ExecContext(ctx, sb.String(), args...)
+	return err
+}
+
+// trafficMirrorWhere 构造公共过滤条件；状态码按类别（2xx/4xx/5xx）比较是否一致。
+func trafficMirrorWhere(filter service.TrafficMirrorFilter) (string, []any) {
+	conds := []string{"created_at >= $1", "created_at < $2"}
+	args := []any{filter.StartTime, filter.EndTime}
+	if filter.GroupID > 0 {
+		args = append(args, filter.GroupID)
+		conds = append(conds, fmt.Sprintf("group_id = $%d", len(args)))
+	}
+	if filter.TargetGroupID > 0 {
+		args = append(args, filter.TargetGroupID)
+		conds = append(conds, fmt.Sprintf("target_group_id = $%d", len(args)))
+	}
+	if filter.OnlyDiverged {
+		conds = append(conds, "(primary_status / 100 <> mirror_status / 100 OR (primary_model <> '' AND mirror_model <> '' AND primary_model <> mirror_model))")
+	}
+	return "WHERE " + strings.Join(conds, " AND "), args
+}
+
+func (r *trafficMirrorRepository) ListRecords(ctx context.Context, filter service.TrafficMirrorFilter) ([]service.TrafficMirrorRecord, int64, error) {
+	where, args := trafficMirrorWhere(filter)
+	var total int64
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM traffic_mirror_records "+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return nil, 0, nil
+	}
+	args = append(args, filter.PageSize, (filter.Page-1)*filter.PageSize)
+	query := fmt.Sprintf("SELECT id, %s FROM traffic_mirror_records %s ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d",
+		trafficMirrorRecordColumns, where, len(args)-1, len(args))
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = rows.Close() }()
+	var out []service.TrafficMirrorRecord
+	for rows.Next() {
+		var (
+			rec                                            service.TrafficMirrorRecord
+			targetAccountID, primaryAccount, mirrorAccount sql.NullInt64
+			primaryTTFT, mirrorTTFT                        sql.NullInt64
+			similarity                                     sql.NullFloat64
+			primaryExcerpt, mirrorExcerpt                  sql.NullString
+		)
+		if err := rows.Scan(
+			&rec.ID, &rec.GroupID, &rec.TargetGroupID, &targetAccountID, &rec.ClientRequestID, &rec.Endpoint, &rec.Model, &rec.Stream,
+			&primaryAccount, &rec.PrimaryStatus, &rec.PrimaryLatencyMs, &primaryTTFT, &rec.PrimaryInputTokens, &rec.PrimaryOutputTokens, &rec.PrimaryModel,
+			&mirrorAccount, &rec.MirrorStatus, &rec.MirrorLatencyMs, &mirrorTTFT, &rec.MirrorInputTokens, &rec.MirrorOutputTokens, &rec.MirrorModel, &rec.MirrorError,
+			&similarity, &primaryExcerpt, &mirrorExcerpt, &rec.CreatedAt,
+		); err != nil {
+			return nil, 0, err
+		}
+		rec.TargetAccountID = trafficMirrorNullInt64Ptr(targetAccountID)
+		rec.PrimaryAccountID = trafficMirrorNullInt64Ptr(primaryAccount)
+		rec.MirrorAccountID = trafficMirrorNullInt64Ptr(mirrorAccount)
+		rec.PrimaryTTFTMs = trafficMirrorNullIntPtr(primaryTTFT)
+		rec.MirrorTTFTMs = trafficMirrorNullIntPtr(mirrorTTFT)
+		if similarity.Valid {
+			v := similarity.Float64
+			rec.OutputSimilarity = &v
+		}
+		if primaryExcerpt.Valid {
+			v := primaryExcerpt.String
+			rec.PrimaryOutputExcerpt = &v
+		}
+		if mirrorExcerpt.Valid {
+			v := mirrorExcerpt.String
+			rec.MirrorOutputExcerpt = &v
+		}
+		out = append(out, rec)
+	}
+	return out, total, rows.Err()
+}
+
+// Summarize 按（源分组, 候选分组, 候选账号）聚合；延迟、TTFT 与 token 只统计两侧都成功的记录。
+func (r *trafficMirrorRepository) Summarize(ctx context.Context, filter service.TrafficMirrorFilter) ([]service.TrafficMirrorSummary, error) {
+	where, args := trafficMirrorWhere(filter)
+	rows, err := r.db.QueryContext(ctx, `
+SELECT
+    group_id,
+    target_group_id,
+    target_account_id,
+    COUNT(*) AS total,
+    COUNT(*) FILTER (WHERE primary_status BETWEEN 200 AND 299) AS primary_success,
+    COUNT(*) FILTER (WHERE mirror_status BETWEEN 200 AND 299) AS mirror_success,
+    COUNT(*) FILTER (WHERE primary_status / 100 <> mirror_status / 100) AS status_mismatch,
+    COUNT(*) FILTER (WHERE primary_model <> '' AND mirror_model <> '' AND primary_model <> mirror_model) AS model_mismatch,
+    COUNT(*) FILTER (WHERE `+trafficMirrorBothSuccess+`) AS both_success,
+    COALESCE(AVG(primary_latency_ms) FILTER (WHERE `+trafficMirrorBothSuccess+`), 0) AS avg_primary_latency_ms,
+    COALESCE(AVG(mirror_latency_ms) FILTER (WHERE `+trafficMirrorBothSuccess+`), 0) AS avg_mirror_latency_ms,
+    AVG(primary_ttft_ms) FILTER (WHERE `+trafficMirrorBothSuccess+`) AS avg_primary_ttft_ms,
+    AVG(mirror_ttft_ms) FILTER (WHERE `+trafficMirrorBothSuccess+`) AS avg_mirror_ttft_ms,
+    COALESCE(SUM(primary_input_tokens) FILTER (WHERE `+trafficMirrorBothSuccess+`), 0) AS primary_input_tokens,
+    COALESCE(SUM(primary_output_tokens) FILTER (WHERE `+trafficMirrorBothSuccess+`), 0) AS primary_output_tokens,
+    COALESCE(SUM(mirror_input_tokens) FILTER (WHERE `+trafficMirrorBothSuccess+`), 0) AS mirror_input_tokens,
+    COALESCE(SUM(mirror_output_tokens) FILTER (WHERE `+trafficMirrorBothSuccess+`), 0) AS mirror_output_tokens,
+    AVG(output_similarity) AS avg_output_similarity
+FROM traffic_mirror_records
+`+where+`
+GROUP BY group_id, target_group_id, target_account_id
+ORDER BY total DESC, group_id, target_group_id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var out []service.TrafficMirrorSummary
+	for rows.Next() {
+		var (
+			row                     service.TrafficMirrorSummary
+			targetAccountID         sql.NullInt64
+			primaryTTFT, mirrorTTFT sql.NullFloat64
+			similarity              sql.NullFloat64
+		)
+		if err := rows.Scan(
+			&row.GroupID,
+			&row.TargetGroupID,
+			&targetAccountID,
+			&row.Total,
+			&row.PrimarySuccess,
+			&row.MirrorSuccess,
+			&row.StatusMismatch,
+			&row.ModelMismatch,
+			&row.BothSuccess,
+			&row.AvgPrimaryLatencyMs,
+			&row.AvgMirrorLatencyMs,
+			&primaryTTFT,
+			&mirrorTTFT,
+			&row.PrimaryInputTokens,
+			&row.PrimaryOutputTokens,
+			&row.MirrorInputTokens,
+			&row.MirrorOutputTokens,
+			&similarity,
+		); err != nil {
+			return nil, err
+		}
+		row.TargetAccountID = trafficMirrorNullInt64Ptr(targetAccountID)
+		row.AvgPrimaryTTFTMs = nullFloat64Ptr(primaryTTFT)
+		row.AvgMirrorTTFTMs = nullFloat64Ptr(mirrorTTFT)
+		row.AvgOutputSimilarity = nullFloat64Ptr(similarity)
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
+
+const trafficMirrorBothSuccess = "primary_status BETWEEN 200 AND 299 AND mirror_status BETWEEN 200 AND 299"
+
+func (r *trafficMirrorRepository) DeleteRecordsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM traffic_mirror_records WHERE created_at < $1`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func trafficMirrorNullInt64Ptr(v sql.NullInt64) *int64 {
+	if !v.Valid {
+		return nil
+	}
+	out := v.Int64
+	return &out
+}
+
+func trafficMirrorNullIntPtr(v sql.NullInt64) *int {
+	if !v.Valid {
+		return nil
+	}
+	out := int(v.Int64)
+	return &out
+}
@@ -50,6 +50,16 @@ func (r *usageBillingRepository) Apply(ctx context.Context, cmd *service.UsageBi
 		return &service.UsageBillingApplyResult{Applied: false}, nil
 	}
 
+	if cmd.BalanceCost > 0 {
+		if err := applyBalanceLedgerTag(ctx, tx, service.BalanceLedgerTag{
+			Type:          service.BalanceLedgerTypeUsage,
+			ReferenceType: service.BalanceLedgerRefUsageRequest,
+			ReferenceID:   cmd.RequestID,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
 	result := &service.UsageBillingApplyResult{Applied: true}
 	if err := r.applyUsageBillingEffects(ctx, tx, cmd, result); err != nil {
 		return nil, err
@@ -110,20 +120,21 @@ func (r *usageBillingRepository) claimUsageBillingRequest(ctx context.Context, t
 }
 
 func (r *usageBillingRepository) ReserveBatchImageBalance(ctx context.Context, cmd *service.BatchImageBalanceHoldCommand) (*service.BatchImageBalanceHoldResult, error) {
-	return r.applyBatchImageBalanceHold(ctx, cmd, reserveUsageBillingBatchImageBalance)
+	return r.applyBatchImageBalanceHold(ctx, cmd, service.BalanceLedgerTypeBalanceHold, reserveUsageBillingBatchImageBalance)
 }
 
 func (r *usageBillingRepository) CaptureBatchImageBalance(ctx context.Context, cmd *service.BatchImageBalanceHoldCommand) (*service.BatchImageBalanceHoldResult, error) {
-	return r.applyBatchImageBalanceHold(ctx, cmd, captureUsageBillingBatchImageBalance)
+	return r.applyBatchImageBalanceHold(ctx, cmd, service.BalanceLedgerTypeHoldCapture, captureUsageBillingBatchImageBalance)
 }
 
 func (r *usageBillingRepository) ReleaseBatchImageBalance(ctx context.Context, cmd *service.BatchImageBalanceHoldCommand) (*service.BatchImageBalanceHoldResult, error) {
-	return r.applyBatchImageBalanceHold(ctx, cmd, releaseUsageBillingBatchImageBalance)
+	return r.applyBatchImageBalanceHold(ctx, cmd, service.BalanceLedgerTypeHoldRelease, releaseUsageBillingBatchImageBalance)
 }
 
 func (r *usageBillingRepository) applyBatchImageBalanceHold(
 	ctx context.Context,
 	cmd *service.BatchImageBalanceHoldCommand,
+	ledgerType string,
 	apply func(context.Context, *sql.Tx, *service.BatchImageBalanceHoldCommand) (*service.BatchImageBalanceHoldResult, error),
 ) (_ *service.BatchImageBalanceHoldResult, err error) {
 	if cmd == nil {
@@ -154,6 +165,13 @@ func (r *usageBillingRepository) applyBatchImageBalanceHold(
 	if !applied {
 		return &service.BatchImageBalanceHoldResult{Applied: false}, nil
 	}
+	if err := applyBalanceLedgerTag(ctx, tx, service.BalanceLedgerTag{
+		Type:          ledgerType,
+		ReferenceType: service.BalanceLedgerRefUsageRequest,
+		ReferenceID:   cmd.RequestID,
+	}); err != nil {
+		return nil, err
+	}
 
 	result, err := apply(ctx, tx, cmd)
 	if err != nil {
@@ -179,12 +197,21 @@ func (r *usageBillingRepository) applyUsageBillingEffects(ctx context.Context, t
 	}
 
 	if cmd.BalanceCost > 0 {
-		newBalance, sufficient, err := deductUsageBillingBalance(ctx, tx, cmd.UserID, cmd.BalanceCost)
+		organizationID, orgBalance, charged, err := deductUsageBillingOrganizationWallet(ctx, tx, cmd.UserID, cmd.BalanceCost)
 		if err != nil {
 			return err
 		}
-		result.NewBalance = &newBalance
-		result.BalanceOverdrafted = !sufficient
+		if charged {
+			result.OrganizationID = &organizationID
+			result.OrganizationBalance = &orgBalance
+		} else {
+			newBalance, sufficient, err := deductUsageBillingBalance(ctx, tx, cmd.UserID, cmd.BalanceCost)
+			if err != nil {
+				return err
+			}
+			result.NewBalance = &newBalance
+			result.BalanceOverdrafted = !sufficient
+		}
 	}
 
 	if cmd.APIKeyQuotaCost > 0 {
@@ -240,6 +267,42 @@ func incrementUsageBillingSubscription(ctx context.Context, tx *sql.Tx, subscrip
 	return service.ErrSubscriptionNotFound
 }
 
+// deductUsageBillingOrganizationWallet 成员属于 active 组织时改扣组织钱包，并累加成员当月消费
+// （跨月时先归零）。与个人余额一致，预检放行后的扣费允许透支。非成员返回 charged=false。
+func deductUsageBillingOrganizationWallet(ctx context.Context, tx *sql.Tx, userID int64, amount float64) (int64, float64, bool, error) {
+	var organizationID int64
+	var balance float64
+	err := tx.QueryRowContext(ctx, `
+		WITH member AS (
+			UPDATE organization_members m
+			SET monthly_spent_usd = CASE
+					WHEN m.spend_month_start < `+organizationMonthStartSQL+` THEN $1
+					ELSE m.monthly_spent_usd + $1
+				END,
+				spend_month_start = GREATEST(m.spend_month_start, `+organizationMonthStartSQL+`),
+				updated_at = NOW()
+			FROM organizations o
+			WHERE m.user_id = $2
+				AND o.id = m.organization_id
+				AND o.status = $3
+				AND o.deleted_at IS NULL
+			RETURNING m.organization_id
+		)
+		UPDATE organizations
+		SET balance = balance - $1,
+			updated_at = NOW()
+		WHERE id = (SELECT organization_id FROM member)
+		RETURNING id, balance
+	`, amount, userID, service.OrganizationStatusActive).Scan(&organizationID, &balance)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, 0, false, nil
+	}
+	if err != nil {
+		return 0, 0, false, err
+	}
+	return organizationID, balance, true, nil
+}
+
 func deductUsageBillingBalance(ctx context.Context, tx *sql.Tx, userID int64, amount float64) (float64, bool, error) {
 	var newBalance float64
 	err := tx.QueryRowContext(ctx, `
@@ -276,13 +339,17 @@ func reserveUsageBillingBatchImageBalance(ctx context.Context, tx *sql.Tx, cmd *
 	if cmd.HoldAmount <= 0 {
 		return &service.BatchImageBalanceHoldResult{}, nil
 	}
+	if result, ok, err := reserveOrganizationBatchImageBalance(ctx, tx, cmd); err != nil || ok {
+		return result, err
+	}
+	// 与 User.SpendableBalance 一致：授信用户的余额可冻结到 -credit_limit。
 	var balance, frozen float64
 	err := tx.QueryRowContext(ctx, `
 		UPDATE users
 		SET balance = balance - $1,
 			frozen_balance = COALESCE(frozen_balance, 0) + $1,
 			updated_at = NOW()
-		WHERE id = $2 AND deleted_at IS NULL AND balance >= $1
+		WHERE id = $2 AND deleted_at IS NULL AND balance - $1 >= -COALESCE(credit_limit, 0)
 		RETURNING balance, frozen_balance
 	`, cmd.HoldAmount, cmd.UserID).Scan(&balance, &frozen)
 	if err == nil {
@@ -306,6 +373,9 @@ func captureUsageBillingBatchImageBalance(ctx context.Context, tx *sql.Tx, cmd *
 	if cmd.ActualAmount-cmd.HoldAmount > 0.00000001 {
 		return nil, service.ErrBatchImageSettlementCostExceedsHold
 	}
+	if result, ok, err := settleOrganizationBatchImageBalance(ctx, tx, cmd, cmd.ActualAmount); err != nil || ok {
+		return result, err
+	}
 	var balance, frozen float64
 	err := tx.QueryRowContext(ctx, `
 		UPDATE users
@@ -337,7 +407,8 @@ func releaseUsageBillingBatchImageBalance(ctx context.Context, tx *sql.Tx, cmd *
 	}
 	// 释放前校验该 job 确实预留过 hold（hold request id 已被 claim），
 	// 防止从未成功冻结的 job 触发"幻影释放"，从其他用户的冻结资金池中凭空生成余额。
-	held, heldErr := batchImageHoldClaimExists(ctx, tx, service.BatchImageHoldRequestID(cmd.BatchID), cmd.APIKeyID)
+	holdRequestID := batchImageHoldRequestID(cmd)
+	held, heldErr := batchImageHoldClaimExists(ctx, tx, holdRequestID, cmd.APIKeyID)
 	if heldErr != nil {
 		return nil, heldErr
 	}
@@ -345,6 +416,9 @@ func releaseUsageBillingBatchImageBalance(ctx context.Context, tx *sql.Tx, cmd *
 		logger.LegacyPrintf("repository.usage_billing", "[BatchImage] release skipped, hold was never reserved: batch=%s", cmd.BatchID)
 		return &service.BatchImageBalanceHoldResult{}, nil
 	}
+	if result, ok, err := settleOrganizationBatchImageBalance(ctx, tx, cmd, 0); err != nil || ok {
+		return result, err
+	}
 	var balance, frozen float64
 	err := tx.QueryRowContext(ctx, `
 		UPDATE users
@@ -368,6 +442,109 @@ func releaseUsageBillingBatchImageBalance(ctx context.Context, tx *sql.Tx, cmd *
 	return nil, errors.New("batch image frozen balance is insufficient")
 }
 
+// batchImageHoldRequestID 返回 capture/release 对应的 hold request id；为空时按批量生图前缀由 BatchID 推导。
+func batchImageHoldRequestID(cmd *service.BatchImageBalanceHoldCommand) string {
+	if cmd.HoldRequestID != "" {
+		return cmd.HoldRequestID
+	}
+	return service.BatchImageHoldRequestID(cmd.BatchID)
+}
+
+// reserveOrganizationBatchImageBalance 成员属于 active 组织时改从组织钱包冻结，并记录 hold 所在钱包，
+// 供 capture/release 按记录结算（成员之后退出组织也不会落到个人余额上）。非成员返回 ok=false。
+func reserveOrganizationBatchImageBalance(ctx context.Context, tx *sql.Tx, cmd *service.BatchImageBalanceHoldCommand) (*service.BatchImageBalanceHoldResult, bool, error) {
+	var organizationID int64
+	err := tx.QueryRowContext(ctx, `
+		SELECT o.id
+		FROM organization_members m
+		JOIN organizations o ON o.id = m.organization_id
+		WHERE m.user_id = $1 AND o.status = $2 AND o.deleted_at IS NULL
+		FOR UPDATE OF o
+	`, cmd.UserID, service.OrganizationStatusActive).Scan(&organizationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	var balance, frozen float64
+	err = tx.QueryRowContext(ctx, `
+		UPDATE organizations
+		SET balance = balance - $1,
+			frozen_balance = frozen_balance + $1,
+			updated_at = NOW()
+		WHERE id = $2 AND balance >= $1
+		RETURNING balance, frozen_balance
+	`, cmd.HoldAmount, organizationID).Scan(&balance, &frozen)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, service.ErrBatchImageInsufficientBalance
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO organization_balance_holds (hold_request_id, api_key_id, organization_id, user_id, amount)
+		VALUES ($1, $2, $3, $4, $5)
+	`, cmd.RequestID, cmd.APIKeyID, organizationID, cmd.UserID, cmd.HoldAmount); err != nil {
+		return nil, false, err
+	}
+	return &service.BatchImageBalanceHoldResult{OrganizationID: &organizationID, NewBalance: &balance, FrozenBalance: &frozen}, true, nil
+}
+
+// settleOrganizationBatchImageBalance 结算落在组织钱包上的 hold：解冻 HoldAmount、扣除 actualAmount
+// （release 时为 0），实际费用计入成员当月消费，随后删除 hold 记录。hold 不在组织钱包上时返回 ok=false。
+func settleOrganizationBatchImageBalance(ctx context.Context, tx *sql.Tx, cmd *service.BatchImageBalanceHoldCommand, actualAmount float64) (*service.BatchImageBalanceHoldResult, bool, error) {
+	holdRequestID := batchImageHoldRequestID(cmd)
+	var organizationID, userID int64
+	err := tx.QueryRowContext(ctx, `
+		DELETE FROM organization_balance_holds
+		WHERE hold_request_id = $1 AND api_key_id = $2
+		RETURNING organization_id, user_id
+	`, holdRequestID, cmd.APIKeyID).Scan(&organizationID, &userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	var balance, frozen float64
+	err = tx.QueryRowContext(ctx, `
+		UPDATE organizations
+		SET balance = balance
+				+ CASE WHEN $1 > $2 THEN $1 - $2 ELSE 0 END
+				- CASE WHEN $2 > $1 THEN $2 - $1 ELSE 0 END,
+			frozen_balance = frozen_balance - $1,
+			updated_at = NOW()
+		WHERE id = $3 AND frozen_balance >= $1
+		RETURNING balance, frozen_balance
+	`, cmd.HoldAmount, actualAmount, organizationID).Scan(&balance, &frozen)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, errors.New("organization frozen balance is insufficient")
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	if actualAmount > 0 {
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE organization_members m
+			SET monthly_spent_usd = CASE
+					WHEN m.spend_month_start < `+organizationMonthStartSQL+` THEN $1
+					ELSE m.monthly_spent_usd + $1
+				END,
+				spend_month_start = GREATEST(m.spend_month_start, `+organizationMonthStartSQL+`),
+				updated_at = NOW()
+			WHERE m.user_id = $2 AND m.organization_id = $3
+		`, actualAmount, userID, organizationID); err != nil {
+			return nil, false, err
+		}
+	}
+	return &service.BatchImageBalanceHoldResult{OrganizationID: &organizationID, NewBalance: &balance, FrozenBalance: &frozen}, true, nil
+}
+
 // batchImageHoldClaimExists 检查 hold request id 是否已在 dedup（或归档）表中被 claim，
 // 即该 batch 的冻结操作确实成功提交过。
 func batchImageHoldClaimExists(ctx context.Context, tx *sql.Tx, holdRequestID string, apiKeyID int64) (bool, error) {
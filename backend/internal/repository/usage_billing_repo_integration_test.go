@@ -365,3 +365,48 @@ func TestUsageBillingRepositoryApply_DeduplicatesAgainstArchivedKey(t *testing.T
 	require.NoError(t, integrationDB.QueryRowContext(ctx, "SELECT balance FROM users WHERE id = $1", user.ID).Scan(&balance))
 	require.InDelta(t, 98.75, balance, 0.000001)
 }
+
+func TestUsageBillingRepositoryReserveBatchImageBalance_RespectsCreditLimit(t *testing.T) {
+	ctx := context.Background()
+	client := testEntClient(t)
+	repo := NewUsageBillingRepository(client, integrationDB)
+
+	user := mustCreateUser(t, client, &service.User{
+		Email:        fmt.Sprintf("usage-billing-credit-user-%d@example.com", time.Now().UnixNano()),
+		PasswordHash: "hash",
+	})
+	apiKey := mustCreateApiKey(t, client, &service.APIKey{
+		UserID: user.ID,
+		Key:    "sk-usage-billing-credit-" + uuid.NewString(),
+		Name:   "billing-credit",
+	})
+	_, err := integrationDB.ExecContext(ctx, "UPDATE users SET balance = -40, credit_limit = 100 WHERE id = $1", user.ID)
+	require.NoError(t, err)
+
+	// 透支到 -90，仍在 100 的授信额度内。
+	result, err := repo.ReserveBatchImageBalance(ctx, &service.BatchImageBalanceHoldCommand{
+		RequestID:  uuid.NewString(),
+		APIKeyID:   apiKey.ID,
+		UserID:     user.ID,
+		BatchID:    "credit-ok",
+		HoldAmount: 50,
+	})
+	require.NoError(t, err)
+	require.True(t, result.Applied)
+	require.InDelta(t, -90, *result.NewBalance, 0.000001)
+	require.InDelta(t, 50, *result.FrozenBalance, 0.000001)
+
+	// 再冻结 20 会透支到 -110，超出额度。
+	_, err = repo.ReserveBatchImageBalance(ctx, &service.BatchImageBalanceHoldCommand{
+		RequestID:  uuid.NewString(),
+		APIKeyID:   apiKey.ID,
+		UserID:     user.ID,
+		BatchID:    "credit-over",
+		HoldAmount: 20,
+	})
+	require.ErrorIs(t, err, service.ErrBatchImageInsufficientBalance)
+
+	var balance float64
+	require.NoError(t, integrationDB.QueryRowContext(ctx, "SELECT balance FROM users WHERE id = $1", user.ID).Scan(&balance))
+	require.InDelta(t, -90, balance, 0.000001)
+}
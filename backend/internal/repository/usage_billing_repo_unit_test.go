@@ -16,10 +16,17 @@ import (
 const (
 	conditionalBalanceDeductSQL = `(?s)UPDATE users\s+SET balance = balance - \$1,\s+updated_at = NOW\(\)\s+WHERE id = \$2 AND deleted_at IS NULL AND balance >= \$1\s+RETURNING balance`
 	overdraftBalanceDeductSQL   = `(?s)UPDATE users\s+SET balance = balance - \$1,\s+updated_at = NOW\(\)\s+WHERE id = \$2 AND deleted_at IS NULL\s+RETURNING balance`
-	reserveBatchImageHoldSQL    = `(?s)UPDATE users\s+SET balance = balance - \$1,\s+frozen_balance = COALESCE\(frozen_balance, 0\) \+ \$1,\s+updated_at = NOW\(\)\s+WHERE id = \$2 AND deleted_at IS NULL AND balance >= \$1\s+RETURNING balance, frozen_balance`
+	reserveBatchImageHoldSQL    = `(?s)UPDATE users\s+SET balance = balance - \$1,\s+frozen_balance = COALESCE\(frozen_balance, 0\) \+ \$1,\s+updated_at = NOW\(\)\s+WHERE id = \$2 AND deleted_at IS NULL AND balance - \$1 >= -COALESCE\(credit_limit, 0\)\s+RETURNING balance, frozen_balance`
 	captureBatchImageHoldSQL    = `(?s)UPDATE users\s+SET balance = balance\s+\+ CASE WHEN \$1 > \$2 THEN \$1 - \$2 ELSE 0 END\s+- CASE WHEN \$2 > \$1 THEN \$2 - \$1 ELSE 0 END,\s+frozen_balance = COALESCE\(frozen_balance, 0\) - \$1,\s+updated_at = NOW\(\)\s+WHERE id = \$3 AND deleted_at IS NULL AND COALESCE\(frozen_balance, 0\) >= \$1\s+RETURNING balance, frozen_balance`
 	releaseBatchImageHoldSQL    = `(?s)UPDATE users\s+SET balance = balance \+ \$1,\s+frozen_balance = COALESCE\(frozen_balance, 0\) - \$1,\s+updated_at = NOW\(\)\s+WHERE id = \$2 AND deleted_at IS NULL AND COALESCE\(frozen_balance, 0\) >= \$1\s+RETURNING balance, frozen_balance`
 	userExistsForBillingSQL     = `(?s)SELECT 1\s+FROM users\s+WHERE id = \$1 AND deleted_at IS NULL`
+	organizationWalletDeductSQL = `(?s)WITH member AS \(\s+UPDATE organization_members m.+UPDATE organizations\s+SET balance = balance - \$1,.+RETURNING id, balance`
+	organizationHoldMemberSQL   = `(?s)SELECT o\.id\s+FROM organization_members m\s+JOIN organizations o ON o\.id = m\.organization_id.+FOR UPDATE OF o`
+	organizationHoldReserveSQL  = `(?s)UPDATE organizations\s+SET balance = balance - \$1,\s+frozen_balance = frozen_balance \+ \$1,.+WHERE id = \$2 AND balance >= \$1\s+RETURNING balance, frozen_balance`
+	organizationHoldInsertSQL   = `(?s)INSERT INTO organization_balance_holds`
+	organizationHoldTakeSQL     = `(?s)DELETE FROM organization_balance_holds\s+WHERE hold_request_id = \$1 AND api_key_id = \$2\s+RETURNING organization_id, user_id`
+	organizationHoldSettleSQL   = `(?s)UPDATE organizations\s+SET balance = balance.+frozen_balance = frozen_balance - \$1,.+WHERE id = \$3 AND frozen_balance >= \$1\s+RETURNING balance, frozen_balance`
+	organizationHoldSpendSQL    = `(?s)UPDATE organization_members m\s+SET monthly_spent_usd = .+WHERE m\.user_id = \$2 AND m\.organization_id = \$3`
 )
 
 func TestDeductUsageBillingBalance_UsesSufficientBalanceGuard(t *testing.T) {
@@ -78,6 +85,9 @@ func TestApplyUsageBillingEffects_FlagsBalanceOverdraft(t *testing.T) {
 	mock.ExpectBegin()
 	tx, err := db.BeginTx(ctx, nil)
 	require.NoError(t, err)
+	mock.ExpectQuery(organizationWalletDeductSQL).
+		WithArgs(10.0, int64(42), service.OrganizationStatusActive).
+		WillReturnError(sql.ErrNoRows)
 	mock.ExpectQuery(conditionalBalanceDeductSQL).
 		WithArgs(10.0, int64(42)).
 		WillReturnError(sql.ErrNoRows)
@@ -99,6 +109,34 @@ func TestApplyUsageBillingEffects_FlagsBalanceOverdraft(t *testing.T) {
 	require.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestApplyUsageBillingEffects_ChargesOrganizationWalletForMembers(t *testing.T) {
+	ctx := context.Background()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectBegin()
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	mock.ExpectQuery(organizationWalletDeductSQL).
+		WithArgs(2.5, int64(42), service.OrganizationStatusActive).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}).AddRow(int64(7), 97.5))
+	mock.ExpectCommit()
+
+	result := &service.UsageBillingApplyResult{Applied: true}
+	err = (&usageBillingRepository{}).applyUsageBillingEffects(ctx, tx, &service.UsageBillingCommand{
+		UserID:      42,
+		BalanceCost: 2.5,
+	}, result)
+	require.NoError(t, err)
+	require.Nil(t, result.NewBalance, "personal balance must not be touched")
+	require.NotNil(t, result.OrganizationID)
+	require.Equal(t, int64(7), *result.OrganizationID)
+	require.InDelta(t, 97.5, *result.OrganizationBalance, 0.000001)
+	require.NoError(t, tx.Commit())
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestDeductUsageBillingBalance_ReturnsUserNotFoundWhenNoUserUpdated(t *testing.T) {
 	ctx := context.Background()
 	db, mock, err := sqlmock.New()
@@ -131,6 +169,9 @@ func TestReserveUsageBillingBatchImageBalance_MovesAvailableToFrozen(t *testing.
 	mock.ExpectBegin()
 	tx, err := db.BeginTx(ctx, nil)
 	require.NoError(t, err)
+	mock.ExpectQuery(organizationHoldMemberSQL).
+		WithArgs(int64(42), service.OrganizationStatusActive).
+		WillReturnError(sql.ErrNoRows)
 	mock.ExpectQuery(reserveBatchImageHoldSQL).
 		WithArgs(2.5, int64(42)).
 		WillReturnRows(sqlmock.NewRows([]string{"balance", "frozen_balance"}).AddRow(7.5, 2.5))
@@ -146,6 +187,32 @@ func TestReserveUsageBillingBatchImageBalance_MovesAvailableToFrozen(t *testing.
 	require.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestReserveUsageBillingBatchImageBalance_DrawsOnCreditLine(t *testing.T) {
+	ctx := context.Background()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	// 余额 -40、授信 100：冻结 10 后余额 -50，仍在额度内。
+	mock.ExpectBegin()
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	mock.ExpectQuery(organizationHoldMemberSQL).
+		WithArgs(int64(42), service.OrganizationStatusActive).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(reserveBatchImageHoldSQL).
+		WithArgs(10.0, int64(42)).
+		WillReturnRows(sqlmock.NewRows([]string{"balance", "frozen_balance"}).AddRow(-50.0, 10.0))
+	mock.ExpectCommit()
+
+	result, err := reserveUsageBillingBatchImageBalance(ctx, tx, &service.BatchImageBalanceHoldCommand{UserID: 42, HoldAmount: 10})
+	require.NoError(t, err)
+	require.InDelta(t, -50, *result.NewBalance, 0.000001)
+	require.InDelta(t, 10, *result.FrozenBalance, 0.000001)
+	require.NoError(t, tx.Commit())
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestReserveUsageBillingBatchImageBalance_InsufficientBalance(t *testing.T) {
 	ctx := context.Background()
 	db, mock, err := sqlmock.New()
@@ -155,6 +222,9 @@ func TestReserveUsageBillingBatchImageBalance_InsufficientBalance(t *testing.T)
 	mock.ExpectBegin()
 	tx, err := db.BeginTx(ctx, nil)
 	require.NoError(t, err)
+	mock.ExpectQuery(organizationHoldMemberSQL).
+		WithArgs(int64(42), service.OrganizationStatusActive).
+		WillReturnError(sql.ErrNoRows)
 	mock.ExpectQuery(reserveBatchImageHoldSQL).
 		WithArgs(10.0, int64(42)).
 		WillReturnError(sql.ErrNoRows)
@@ -178,12 +248,15 @@ func TestCaptureUsageBillingBatchImageBalance_ReleasesRemainder(t *testing.T) {
 	mock.ExpectBegin()
 	tx, err := db.BeginTx(ctx, nil)
 	require.NoError(t, err)
+	mock.ExpectQuery(organizationHoldTakeSQL).
+		WithArgs(service.BatchImageHoldRequestID("imgbatch_capture"), int64(7)).
+		WillReturnError(sql.ErrNoRows)
 	mock.ExpectQuery(captureBatchImageHoldSQL).
 		WithArgs(1.0, 0.25, int64(42)).
 		WillReturnRows(sqlmock.NewRows([]string{"balance", "frozen_balance"}).AddRow(9.75, 0.0))
 	mock.ExpectCommit()
 
-	result, err := captureUsageBillingBatchImageBalance(ctx, tx, &service.BatchImageBalanceHoldCommand{UserID: 42, HoldAmount: 1, ActualAmount: 0.25})
+	result, err := captureUsageBillingBatchImageBalance(ctx, tx, &service.BatchImageBalanceHoldCommand{UserID: 42, APIKeyID: 7, BatchID: "imgbatch_capture", HoldAmount: 1, ActualAmount: 0.25})
 	require.NoError(t, err)
 	require.InDelta(t, 9.75, *result.NewBalance, 0.000001)
 	require.InDelta(t, 0.0, *result.FrozenBalance, 0.000001)
@@ -220,6 +293,9 @@ func TestReleaseUsageBillingBatchImageBalance_ReturnsFrozenToAvailable(t *testin
 	mock.ExpectQuery(`SELECT 1\s+FROM usage_billing_dedup\s+WHERE request_id = \$1 AND api_key_id = \$2`).
 		WithArgs(service.BatchImageHoldRequestID("imgbatch_release"), int64(7)).
 		WillReturnRows(sqlmock.NewRows([]string{"?column?"}).AddRow(1))
+	mock.ExpectQuery(organizationHoldTakeSQL).
+		WithArgs(service.BatchImageHoldRequestID("imgbatch_release"), int64(7)).
+		WillReturnError(sql.ErrNoRows)
 	mock.ExpectQuery(releaseBatchImageHoldSQL).
 		WithArgs(1.0, int64(42)).
 		WillReturnRows(sqlmock.NewRows([]string{"balance", "frozen_balance"}).AddRow(10.0, 0.0))
@@ -259,3 +335,119 @@ func TestReleaseUsageBillingBatchImageBalance_SkipsWhenHoldNeverReserved(t *test
 	require.NoError(t, tx.Commit())
 	require.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestReserveUsageBillingBatchImageBalance_DrawsFromOrganizationWallet(t *testing.T) {
+	ctx := context.Background()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	holdID := service.MessageBatchHoldRequestID("msgbatch_org")
+	mock.ExpectBegin()
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	mock.ExpectQuery(organizationHoldMemberSQL).
+		WithArgs(int64(42), service.OrganizationStatusActive).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(9)))
+	mock.ExpectQuery(organizationHoldReserveSQL).
+		WithArgs(2.5, int64(9)).
+		WillReturnRows(sqlmock.NewRows([]string{"balance", "frozen_balance"}).AddRow(97.5, 2.5))
+	mock.ExpectExec(organizationHoldInsertSQL).
+		WithArgs(holdID, int64(7), int64(9), int64(42), 2.5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	result, err := reserveUsageBillingBatchImageBalance(ctx, tx, &service.BatchImageBalanceHoldCommand{
+		RequestID: holdID, UserID: 42, APIKeyID: 7, HoldAmount: 2.5,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, result.OrganizationID)
+	require.Equal(t, int64(9), *result.OrganizationID)
+	require.InDelta(t, 97.5, *result.NewBalance, 0.000001)
+	require.InDelta(t, 2.5, *result.FrozenBalance, 0.000001)
+	require.NoError(t, tx.Commit())
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReserveUsageBillingBatchImageBalance_OrganizationWalletInsufficient(t *testing.T) {
+	ctx := context.Background()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectBegin()
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	mock.ExpectQuery(organizationHoldMemberSQL).
+		WithArgs(int64(42), service.OrganizationStatusActive).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(9)))
+	mock.ExpectQuery(organizationHoldReserveSQL).
+		WithArgs(50.0, int64(9)).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	// 组织钱包不足时不得回落到个人余额冻结。
+	_, err = reserveUsageBillingBatchImageBalance(ctx, tx, &service.BatchImageBalanceHoldCommand{UserID: 42, APIKeyID: 7, HoldAmount: 50})
+	require.ErrorIs(t, err, service.ErrBatchImageInsufficientBalance)
+	require.NoError(t, tx.Rollback())
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCaptureUsageBillingBatchImageBalance_SettlesOrganizationHold(t *testing.T) {
+	ctx := context.Background()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	holdID := service.MessageBatchHoldRequestID("msgbatch_org")
+	mock.ExpectBegin()
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	mock.ExpectQuery(organizationHoldTakeSQL).
+		WithArgs(holdID, int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"organization_id", "user_id"}).AddRow(int64(9), int64(42)))
+	mock.ExpectQuery(organizationHoldSettleSQL).
+		WithArgs(1.0, 0.25, int64(9)).
+		WillReturnRows(sqlmock.NewRows([]string{"balance", "frozen_balance"}).AddRow(99.75, 0.0))
+	mock.ExpectExec(organizationHoldSpendSQL).
+		WithArgs(0.25, int64(42), int64(9)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	result, err := captureUsageBillingBatchImageBalance(ctx, tx, &service.BatchImageBalanceHoldCommand{
+		RequestID: "msgbatch_capture:msgbatch_org", HoldRequestID: holdID, UserID: 42, APIKeyID: 7, HoldAmount: 1, ActualAmount: 0.25,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(9), *result.OrganizationID)
+	require.InDelta(t, 99.75, *result.NewBalance, 0.000001)
+	require.NoError(t, tx.Commit())
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReleaseUsageBillingBatchImageBalance_ReturnsOrganizationHold(t *testing.T) {
+	ctx := context.Background()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectBegin()
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	mock.ExpectQuery(`SELECT 1\s+FROM usage_billing_dedup\s+WHERE request_id = \$1 AND api_key_id = \$2`).
+		WithArgs(service.BatchImageHoldRequestID("imgbatch_org"), int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"?column?"}).AddRow(1))
+	mock.ExpectQuery(organizationHoldTakeSQL).
+		WithArgs(service.BatchImageHoldRequestID("imgbatch_org"), int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"organization_id", "user_id"}).AddRow(int64(9), int64(42)))
+	mock.ExpectQuery(organizationHoldSettleSQL).
+		WithArgs(1.0, 0.0, int64(9)).
+		WillReturnRows(sqlmock.NewRows([]string{"balance", "frozen_balance"}).AddRow(100.0, 0.0))
+	mock.ExpectCommit()
+
+	result, err := releaseUsageBillingBatchImageBalance(ctx, tx, &service.BatchImageBalanceHoldCommand{UserID: 42, APIKeyID: 7, BatchID: "imgbatch_org", HoldAmount: 1})
+	require.NoError(t, err)
+	require.Equal(t, int64(9), *result.OrganizationID)
+	require.InDelta(t, 100.0, *result.NewBalance, 0.000001)
+	require.NoError(t, tx.Commit())
+	require.NoError(t, mock.ExpectationsWereMet())
+}
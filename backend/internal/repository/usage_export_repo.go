@@ -0,0 +1,347 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/lib/pq"
+)
+
+type usageExportRepository struct {
+	db *sql.DB
+}
+
+func NewUsageExportRepository(db *sql.DB) service.UsageExportRepository {
+	return &usageExportRepository{db: db}
+}
+
+type usageExportScanner interface {
+	Scan(dest ...any) error
+}
+
+// ─── 任务 ───
+
+const usageExportJobColumns = `id, name, frequency, format, storage, prefix, filters, enabled,
+    next_period_start, created_at, updated_at`
+
+func scanUsageExportJob(row usageExportScanner) (*service.UsageExportJob, error) {
+	var (
+		job     service.UsageExportJob
+		filters []byte
+	)
+	if err := row.Scan(&job.ID, &job.Name, &job.Frequency, &job.Format, &job.Storage, &job.Prefix, &filters, &job.Enabled,
+		&job.NextPeriodStart, &job.CreatedAt, &job.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if len(filters) > 0 {
+		if err := json.Unmarshal(filters, &job.Filters); err != nil {
+			return nil, err
+		}
+	}
+	return &job, nil
+}
+
+func (r *usageExportRepository) CreateJob(ctx context.Context, job *service.UsageExportJob) error {
+	filters, err := json.Marshal(job.Filters)
+	if err != nil {
+		return err
+	}
+	return r.db.QueryRowContext(ctx, `
+INSERT INTO usage_export_jobs (name, frequency, format, storage, prefix, filters, enabled, next_period_start)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, created_at, updated_at`,
+		job.Name, job.Frequency, job.Format, job.Storage, job.Prefix, filters, job.Enabled, job.NextPeriodStart,
+	).Scan(&job.ID, &job.CreatedAt, &job.UpdatedAt)
+}
+
+func (r *usageExportRepository) UpdateJob(ctx context.Context, job *service.UsageExportJob) error {
+	filters, err := json.Marshal(job.Filters)
+	if err != nil {
+		return err
+	}
+	err = r.db.QueryRowContext(ctx, `
+UPDATE usage_export_jobs
+SET name = $2, frequency = $3, format = $4, storage = $5, prefix = $6, filters = $7, enabled = $8,
+    next_period_start = $9, updated_at = NOW()
+WHERE id = $1
+RETURNING updated_at`,
+		job.ID, job.Name, job.Frequency, job.Format, job.Storage, job.Prefix, filters, job.Enabled, job.NextPeriodStart,
+	).Scan(&job.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return service.ErrUsageExportJobNotFound
+	}
+	return err
+}
+
+func (r *usageExportRepository) DeleteJob(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM usage_export_jobs WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return service.ErrUsageExportJobNotFound
+	}
+	return nil
+}
+
+func (r *usageExportRepository) GetJob(ctx context.Context, id int64) (*service.UsageExportJob, error) {
+	job, err := scanUsageExportJob(r.db.QueryRowContext(ctx, `
+SELECT `+usageExportJobColumns+` FROM usage_export_jobs WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrUsageExportJobNotFound
+	}
+	return job, err
+}
+
+func (r *usageExportRepository) ListJobs(ctx context.Context) ([]service.UsageExportJob, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT `+usageExportJobColumns+` FROM usage_export_jobs ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	jobs := make([]service.UsageExportJob, 0)
+	for rows.Next() {
+		job, err := scanUsageExportJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, *job)
+	}
+	return jobs, rows.Err()
+}
+
+func (r *usageExportRepository) AdvanceJob(ctx context.Context, id int64, next time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+UPDATE usage_export_jobs SET next_period_start = $2, updated_at = NOW() WHERE id = $1`, id, next)
+	return err
+}
+
+// ─── 导出记录 ───
+
+const usageExportRunColumns = `r.id, r.job_id, COALESCE(j.name, ''), r.user_id, r.triggered_by, r.format, r.storage,
+    r.filters, r.period_start, r.period_end, r.object_key, r.status, r.row_count, r.size_bytes, r.sha256,
+    r.error_message, r.started_at, r.finished_at, r.created_at`
+
+const usageExportRunFrom = `
+FROM usage_export_runs r
+LEFT JOIN usage_export_jobs j ON j.id = r.job_id`
+
+func scanUsageExportRun(row usageExportScanner) (*service.UsageExportRun, error) {
+	var (
+		run        service.UsageExportRun
+		jobID      sql.NullInt64
+		userID     sql.NullInt64
+		filters    []byte
+		startedAt  sql.NullTime
+		finishedAt sql.NullTime
+	)
+	if err := row.Scan(&run.ID, &jobID, &run.JobName, &userID, &run.TriggeredBy, &run.Format, &run.Storage,
+		&filters, &run.PeriodStart, &run.PeriodEnd, &run.ObjectKey, &run.Status, &run.RowCount, &run.SizeBytes, &run.SHA256,
+		&run.ErrorMessage, &startedAt, &finishedAt, &run.CreatedAt); err != nil {
+		return nil, err
+	}
+	if jobID.Valid {
+		run.JobID = &jobID.Int64
+	}
+	if userID.Valid {
+		run.UserID = &userID.Int64
+	}
+	if len(filters) > 0 {
+		if err := json.Unmarshal(filters, &run.Filters); err != nil {
+			return nil, err
+		}
+	}
+	if startedAt.Valid {
+		run.StartedAt = &startedAt.Time
+	}
+	if finishedAt.Valid {
+		run.FinishedAt = &finishedAt.Time
+	}
+	return &run, nil
+}
+
+func (r *usageExportRepository) CreateRun(ctx context.Context, run *service.UsageExportRun) error {
+	filters, err := json.Marshal(run.Filters)
+	if err != nil {
+		return err
+	}
+	return r.db.QueryRowContext(ctx, `
+INSERT INTO usage_export_runs (job_id, user_id, triggered_by, format, storage, filters,
+    period_start, period_end, object_key, status)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id, created_at`,
+		run.JobID, run.UserID, run.TriggeredBy, run.Format, run.Storage, filters,
+		run.PeriodStart, run.PeriodEnd, run.ObjectKey, run.Status,
+	).Scan(&run.ID, &run.CreatedAt)
+}
+
+func (r *usageExportRepository) GetRun(ctx context.Context, id int64) (*service.UsageExportRun, error) {
+	run, err := scanUsageExportRun(r.db.QueryRowContext(ctx, `
+SELECT `+usageExportRunColumns+usageExportRunFrom+`
+WHERE r.id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrUsageExportRunNotFound
+	}
+	return run, err
+}
+
+func (r *usageExportRepository) ListRuns(ctx context.Context, filter service.UsageExportRunFilter) ([]service.UsageExportRun, int64, error) {
+	conds := []string{"TRUE"}
+	args := []any{}
+	if filter.JobID > 0 {
+		args = append(args, filter.JobID)
+		conds = append(conds, "r.job_id = $"+itoa(len(args)))
+	}
+	if filter.UserID > 0 {
+		args = append(args, filter.UserID)
+		conds = append(conds, "r.user_id = $"+itoa(len(args)))
+	}
+	if filter.OnlyJobs {
+		conds = append(conds, "r.user_id IS NULL")
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conds = append(conds, "r.status = $"+itoa(len(args)))
+	}
+	where := "\nWHERE " + strings.Join(conds, " AND ")
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM usage_export_runs r"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, filter.PageSize, (filter.Page-1)*filter.PageSize)
+	rows, err := r.db.QueryContext(ctx, `
+SELECT `+usageExportRunColumns+usageExportRunFrom+where+`
+ORDER BY r.created_at DESC, r.id DESC
+LIMIT $`+itoa(len(args)-1)+` OFFSET $`+itoa(len(args)), args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = rows.Close() }()
+	runs := make([]service.UsageExportRun, 0, filter.PageSize)
+	for rows.Next() {
+		run, err := scanUsageExportRun(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		runs = append(runs, *run)
+	}
+	return runs, total, rows.Err()
+}
+
+func (r *usageExportRepository) ListPendingRuns(ctx context.Context, limit int) ([]service.UsageExportRun, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT `+usageExportRunColumns+usageExportRunFrom+`
+WHERE r.status = $1
+ORDER BY r.created_at, r.id
+LIMIT $2`, service.UsageExportRunStatusPending, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var runs []service.UsageExportRun
+	for rows.Next() {
+		run, err := scanUsageExportRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, *run)
+	}
+	return runs, rows.Err()
+}
+
+func (r *usageExportRepository) CountActiveUserRuns(ctx context.Context, userID int64) (int, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx, `
+SELECT COUNT(*) FROM usage_export_runs
+WHERE user_id = $1 AND status IN ($2, $3)`,
+		userID, service.UsageExportRunStatusPending, service.UsageExportRunStatusRunning).Scan(&n)
+	return n, err
+}
+
+func (r *usageExportRepository) ClaimRun(ctx context.Context, id int64, startedAt time.Time) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+UPDATE usage_export_runs SET status = $2, started_at = $3
+WHERE id = $1 AND status = $4`,
+		id, service.UsageExportRunStatusRunning, startedAt, service.UsageExportRunStatusPending)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *usageExportRepository) FinishRun(ctx context.Context, run *service.UsageExportRun) error {
+	_, err := r.db.ExecContext(ctx, `
+UPDATE usage_export_runs
+SET status = $2, row_count = $3, size_bytes = $4, sha256 = $5, error_message = $6, finished_at = $7
+WHERE id = $1`,
+		run.ID, run.Status, run.RowCount, run.SizeBytes, run.SHA256, run.ErrorMessage, run.FinishedAt)
+	return err
+}
+
+func (r *usageExportRepository) FailStaleRuns(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+UPDATE usage_export_runs
+SET status = $1, error_message = 'interrupted by server restart', finished_at = NOW()
+WHERE status = $2 AND started_at < $3`,
+		service.UsageExportRunStatusFailed, service.UsageExportRunStatusRunning, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// ─── 用量明细 ───
+
+func (r *usageExportRepository) StreamUsage(ctx context.Context, query service.UsageExportQuery, fn func(row *service.UsageExportRow) error) error {
+	args := []any{query.Start, query.End}
+	conds := []string{"ul.created_at >= $1", "ul.created_at < $2"}
+	if len(query.Filter.GroupIDs) > 0 {
+		args = append(args, pq.Array(query.Filter.GroupIDs))
+		conds = append(conds, "ul.group_id = ANY($"+itoa(len(args))+")")
+	}
+	if len(query.Filter.UserIDs) > 0 {
+		args = append(args, pq.Array(query.Filter.UserIDs))
+		conds = append(conds, "ul.user_id = ANY($"+itoa(len(args))+")")
+	}
+	if len(query.Filter.Models) > 0 {
+		args = append(args, pq.Array(query.Filter.Models))
+		conds = append(conds, "ul.model = ANY($"+itoa(len(args))+")")
+	}
+	rows, err := r.db.QueryContext(ctx, `
+SELECT ul.id, ul.created_at, ul.user_id, COALESCE(u.email, ''), ul.api_key_id, COALESCE(k.name, ''),
+    ul.account_id, COALESCE(ul.group_id, 0), COALESCE(g.name, ''), ul.model, COALESCE(ul.requested_model, ''),
+    COALESCE(ul.request_id, ''), ul.input_tokens, ul.output_tokens, ul.cache_creation_tokens, ul.cache_read_tokens,
+    ul.total_cost, ul.actual_cost, ul.rate_multiplier, ul.billing_type, ul.stream, COALESCE(ul.duration_ms, 0)
+FROM usage_logs ul
+LEFT JOIN users u ON u.id = ul.user_id
+LEFT JOIN api_keys k ON k.id = ul.api_key_id
+LEFT JOIN groups g ON g.id = ul.group_id
+WHERE `+strings.Join(conds, " AND ")+`
+ORDER BY ul.created_at, ul.id`, args...)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+	var row service.UsageExportRow
+	for rows.Next() {
+		if err := rows.Scan(&row.ID, &row.CreatedAt, &row.UserID, &row.UserEmail, &row.APIKeyID, &row.APIKeyName,
+			&row.AccountID, &row.GroupID, &row.GroupName, &row.Model, &row.RequestedModel,
+			&row.RequestID, &row.InputTokens, &row.OutputTokens, &row.CacheCreationTokens, &row.CacheReadTokens,
+			&row.TotalCost, &row.ActualCost, &row.RateMultiplier, &row.BillingType, &row.Stream, &row.DurationMs); err != nil {
+			return err
+		}
+		if err := fn(&row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -56,6 +56,39 @@ func (r *usageLogRepository) GetUserStatsAggregated(ctx context.Context, userID
 	return &stats, nil
 }
 
+// GetUserGroupSpendSince returns the user's summed actual_cost in a group since the given time.
+// Used by volume-tiered pricing to place a request in the group's monthly spend tier.
+func (r *usageLogRepository) GetUserGroupSpendSince(ctx context.Context, userID, groupID int64, since time.Time) (float64, error) {
+	query := `
+		SELECT COALESCE(SUM(actual_cost), 0)
+		FROM usage_logs
+		WHERE user_id = $1 AND group_id = $2 AND created_at >= $3
+	`
+	var spend float64
+	if err := scanSingleRow(ctx, r.sql, query, []any{userID, groupID, since}, &spend); err != nil {
+		return 0, err
+	}
+	return spend, nil
+}
+
+var _ service.UserGroupMonthlySpendReader = (*usageLogRepository)(nil)
+
+// GetUserBalanceSpendSince 返回用户自 since 起余额扣费的 actual_cost 合计，供余额耗尽预测使用。
+func (r *usageLogRepository) GetUserBalanceSpendSince(ctx context.Context, userID int64, since time.Time) (float64, error) {
+	query := `
+		SELECT COALESCE(SUM(actual_cost), 0)
+		FROM usage_logs
+		WHERE user_id = $1 AND billing_type = $2 AND created_at >= $3
+	`
+	var spend float64
+	if err := scanSingleRow(ctx, r.sql, query, []any{userID, service.BillingTypeBalance, since}, &spend); err != nil {
+		return 0, err
+	}
+	return spend, nil
+}
+
+var _ service.UserBalanceSpendReader = (*usageLogRepository)(nil)
+
 // GetAPIKeyStatsAggregated returns aggregated usage statistics for an API key using database-level aggregation
 func (r *usageLogRepository) GetAPIKeyStatsAggregated(ctx context.Context, apiKeyID int64, startTime, endTime time.Time) (*usagestats.UsageStats, error) {
 	query := `
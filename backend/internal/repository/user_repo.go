@@ -306,6 +306,9 @@ func (r *userRepository) Update(ctx context.Context, userIn *service.User, field
 	if fields.RPMLimit {
 		updateOp = updateOp.SetRpmLimit(userIn.RPMLimit)
 	}
+	if fields.CreditLimit {
+		updateOp = updateOp.SetCreditLimit(userIn.CreditLimit)
+	}
 	if fields.Status {
 		updateOp = updateOp.SetStatus(userIn.Status)
 	}
@@ -435,7 +438,8 @@ func normalizeEmailAuthIdentitySubject(email string) string {
 	if strings.HasSuffix(normalized, service.LinuxDoConnectSyntheticEmailDomain) ||
 		strings.HasSuffix(normalized, service.OIDCConnectSyntheticEmailDomain) ||
 		strings.HasSuffix(normalized, service.WeChatConnectSyntheticEmailDomain) ||
-		strings.HasSuffix(normalized, service.DingTalkConnectSyntheticEmailDomain) {
+		strings.HasSuffix(normalized, service.DingTalkConnectSyntheticEmailDomain) ||
+		strings.HasSuffix(normalized, service.SAMLConnectSyntheticEmailDomain) {
 		return ""
 	}
 	return normalized
@@ -816,7 +820,44 @@ func (r *userRepository) filterUsersByAttributes(ctx context.Context, attrs map[
 	return result, nil
 }
 
+// withBalanceLedgerTag 在 ctx 携带账本标签时，先把标签写入事务再执行余额变更，
+// 使 trg_users_balance_ledger 记录的流水带上类型与业务引用；不在事务中时开启一个短事务。
+// 未携带标签时原样执行，流水记为 unclassified。
+func (r *userRepository) withBalanceLedgerTag(ctx context.Context, fn func(ctx context.Context) error) error {
+	tag, ok := service.BalanceLedgerTagFromContext(ctx)
+	if !ok {
+		return fn(ctx)
+	}
+	if tx := dbent.TxFromContext(ctx); tx != nil {
+		if err := applyBalanceLedgerTag(ctx, tx.Client(), tag); err != nil {
+			return fmt.Errorf("apply balance ledger tag: %w", err)
+		}
+		return fn(ctx)
+	}
+
+	tx, err := r.client.Tx(ctx)
+	if err != nil {
+		return fmt.Errorf("begin balance transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	txCtx := dbent.NewTxContext(ctx, tx)
+	if err := applyBalanceLedgerTag(txCtx, tx.Client(), tag); err != nil {
+		return fmt.Errorf("apply balance ledger tag: %w", err)
+	}
+	if err := fn(txCtx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
 func (r *userRepository) UpdateBalance(ctx context.Context, id int64, amount float64) error {
+	return r.withBalanceLedgerTag(ctx, func(ctx context.Context) error {
+		return r.updateBalance(ctx, id, amount)
+	})
+}
+
+func (r *userRepository) updateBalance(ctx context.Context, id int64, amount float64) error {
 	client := clientFromContext(ctx, r.client)
 	update := client.User.Update().Where(dbuser.IDEQ(id)).AddBalance(amount)
 	// Track cumulative recharge amount for percentage-based notifications
@@ -834,6 +875,12 @@ func (r *userRepository) UpdateBalance(ctx context.Context, id int64, amount flo
 }
 
 func (r *userRepository) ApplyRedeemBalanceAdjustment(ctx context.Context, id int64, delta float64) error {
+	return r.withBalanceLedgerTag(ctx, func(ctx context.Context) error {
+		return r.applyRedeemBalanceAdjustment(ctx, id, delta)
+	})
+}
+
+func (r *userRepository) applyRedeemBalanceAdjustment(ctx context.Context, id int64, delta float64) error {
 	const updateSQL = `
 		UPDATE users
 		SET balance = GREATEST(balance + $1, 0), updated_at = NOW()
@@ -858,6 +905,12 @@ func (r *userRepository) ApplyRedeemBalanceAdjustment(ctx context.Context, id in
 // 透支策略：允许余额变为负数，确保当前请求能够完成
 // 中间件会阻止余额 <= 0 的用户发起后续请求
 func (r *userRepository) DeductBalance(ctx context.Context, id int64, amount float64) error {
+	return r.withBalanceLedgerTag(ctx, func(ctx context.Context) error {
+		return r.deductBalance(ctx, id, amount)
+	})
+}
+
+func (r *userRepository) deductBalance(ctx context.Context, id int64, amount float64) error {
 	client := clientFromContext(ctx, r.client)
 	n, err := client.User.Update().
 		Where(dbuser.IDEQ(id), dbuser.BalanceGTE(amount)).
@@ -887,6 +940,15 @@ func (r *userRepository) DeductBalance(ctx context.Context, id int64, amount flo
 // Unlike DeductBalance, this refund-specific operation never increases an
 // existing deficit or permits a concurrent deduction to cause an overdraft.
 func (r *userRepository) DeductAvailableBalance(ctx context.Context, id int64, amount float64) (deducted float64, err error) {
+	err = r.withBalanceLedgerTag(ctx, func(ctx context.Context) error {
+		var innerErr error
+		deducted, innerErr = r.deductAvailableBalance(ctx, id, amount)
+		return innerErr
+	})
+	return deducted, err
+}
+
+func (r *userRepository) deductAvailableBalance(ctx context.Context, id int64, amount float64) (deducted float64, err error) {
 	if amount < 0 {
 		return 0, fmt.Errorf("deduction amount must be nonnegative")
 	}
@@ -929,7 +991,16 @@ func (r *userRepository) DeductAvailableBalance(ctx context.Context, id int64, a
 // AdjustBalance 原子地把 delta 累加到余额上，结果为负时整条语句不生效。
 // 相比"读余额 → 算新值 → 整行写回"，这里把读与写压进同一条 UPDATE，
 // 并发的计费扣款不会被旧快照覆盖。
-func (r *userRepository) AdjustBalance(ctx context.Context, id int64, delta float64) (service.BalanceChange, error) {
+func (r *userRepository) AdjustBalance(ctx context.Context, id int64, delta float64) (change service.BalanceChange, err error) {
+	err = r.withBalanceLedgerTag(ctx, func(ctx context.Context) error {
+		var innerErr error
+		change, innerErr = r.adjustBalance(ctx, id, delta)
+		return innerErr
+	})
+	return change, err
+}
+
+func (r *userRepository) adjustBalance(ctx context.Context, id int64, delta float64) (service.BalanceChange, error) {
 	const updateSQL = `
 		UPDATE users
 		SET balance = balance + $1, updated_at = NOW()
@@ -953,7 +1024,16 @@ func (r *userRepository) AdjustBalance(ctx context.Context, id int64, delta floa
 }
 
 // SetBalance 原子地把余额置为 value，并返回变更前后的值。
-func (r *userRepository) SetBalance(ctx context.Context, id int64, value float64) (service.BalanceChange, error) {
+func (r *userRepository) SetBalance(ctx context.Context, id int64, value float64) (change service.BalanceChange, err error) {
+	err = r.withBalanceLedgerTag(ctx, func(ctx context.Context) error {
+		var innerErr error
+		change, innerErr = r.setBalance(ctx, id, value)
+		return innerErr
+	})
+	return change, err
+}
+
+func (r *userRepository) setBalance(ctx context.Context, id int64, value float64) (service.BalanceChange, error) {
 	if value < 0 {
 		// 连同当前余额一起返回，便于上层给出可读的错误信息。
 		current, err := r.currentBalance(ctx, id)
@@ -9,10 +9,10 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
-// 用户/分组级 RPM 计数器 Redis 实现。
+// 用户/分组/API Key 级 RPM 计数器 Redis 实现。
 //
 // 设计说明：
-//   - key 形式：rpm:ug:{uid}:{gid}:{minute}、rpm:u:{uid}:{minute}
+//   - key 形式：rpm:ug:{uid}:{gid}:{minute}、rpm:u:{uid}:{minute}、rpm:k:{keyID}:{minute}
 //   - 时间来源：rdb.Time()（Redis 服务端时间），避免多实例时钟漂移。
 //   - 原子操作：TxPipeline (MULTI/EXEC) 执行 INCR+EXPIRE，兼容 Redis Cluster。
 //   - TTL：120s，覆盖当前分钟窗口 + 少量冗余。
@@ -20,6 +20,7 @@ import (
 const (
 	userGroupRPMKeyPrefix = "rpm:ug:"
 	userRPMKeyPrefix      = "rpm:u:"
+	apiKeyRPMKeyPrefix    = "rpm:k:"
 
 	userRPMKeyTTL = 120 * time.Second
 )
@@ -28,7 +29,7 @@ type userRPMCacheImpl struct {
 	rdb *redis.Client
 }
 
-// NewUserRPMCache 创建用户/分组级 RPM 计数器。
+// NewUserRPMCache 创建用户/分组/API Key 级 RPM 计数器。
 func NewUserRPMCache(rdb *redis.Client) service.UserRPMCache {
 	return &userRPMCacheImpl{rdb: rdb}
 }
@@ -106,3 +107,42 @@ func (c *userRPMCacheImpl) GetUserRPM(ctx context.Context, userID int64) (int, e
 	}
 	return val, nil
 }
+
+// IncrementAPIKeyRPM 递增 API Key 分钟计数。
+func (c *userRPMCacheImpl) IncrementAPIKeyRPM(ctx context.Context, apiKeyID int64) (int, error) {
+	minute, err := c.minuteTS(ctx)
+	if err != nil {
+		return 0, err
+	}
+	key := fmt.Sprintf("%s%d:%d", apiKeyRPMKeyPrefix, apiKeyID, minute)
+	return c.atomicIncr(ctx, key)
+}
+
+// GetAPIKeyRPMBatch 批量获取 API Key 当前分钟已用 RPM（只读）。
+// 逐 key GET 走同一 Pipeline，兼容 Redis Cluster（MGET 跨 slot 会报 CROSSSLOT）。
+func (c *userRPMCacheImpl) GetAPIKeyRPMBatch(ctx context.Context, apiKeyIDs []int64) (map[int64]int, error) {
+	result := make(map[int64]int, len(apiKeyIDs))
+	if len(apiKeyIDs) == 0 {
+		return result, nil
+	}
+	minute, err := c.minuteTS(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pipe := c.rdb.Pipeline()
+	cmds := make(map[int64]*redis.StringCmd, len(apiKeyIDs))
+	for _, id := range apiKeyIDs {
+		cmds[id] = pipe.Get(ctx, fmt.Sprintf("%s%d:%d", apiKeyRPMKeyPrefix, id, minute))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("api key rpm batch get: %w", err)
+	}
+	for id, cmd := range cmds {
+		val, err := cmd.Int()
+		if err != nil {
+			continue // redis.Nil：本分钟无请求
+		}
+		result[id] = val
+	}
+	return result, nil
+}
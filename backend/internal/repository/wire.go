@@ -79,9 +79,21 @@ var ProviderSet = wire.NewSet(
 	NewPromoCodeRepository,
 	NewAnnouncementRepository,
 	NewAnnouncementReadRepository,
+	NewOrganizationRepository,
+	NewSubscriptionAutoRenewalRepository,
+	NewPaymentInvoiceRepository,
+	NewBalanceLedgerRepository,
+	NewCreditStatementRepository,
+	NewSpendAnomalyRepository,
+	NewGroupHedgeStatsRepository,
+	NewCompositeRouteExperimentRepository,
+	NewTrafficMirrorRepository,
+	NewUsageExportRepository,
 	NewUsageLogRepository,
 	NewUsageBillingRepository,
 	NewBatchImageRepository,
+	NewMessageBatchRepository,
+	NewOpenAIBatchRepository,
 	NewIdempotencyRepository,
 	NewUsageCleanupRepository,
 	NewDashboardAggregationRepository,
@@ -124,6 +136,7 @@ var ProviderSet = wire.NewSet(
 	NewRedeemCache,
 	NewUpdateCache,
 	NewGeminiTokenCache,
+	NewSAMLReplayCache,
 	NewImageTaskStore,
 	NewBatchImageQueue,
 	NewBatchImageDownloadLimiter,
@@ -137,6 +150,7 @@ var ProviderSet = wire.NewSet(
 	NewErrorPassthroughCache,
 	NewTLSFingerprintProfileCache,
 	NewContentModerationHashCache,
+	ProvideResponseCacheStore,
 
 	// Encryptors
 	NewAESEncryptor,
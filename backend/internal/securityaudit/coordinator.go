@@ -5,6 +5,9 @@ import (
 	"errors"
 	"net/http"
 	"sync"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type LegacyEngine interface {
@@ -38,7 +41,8 @@ func (c *Coordinator) Check(ctx context.Context, req Request) Decision {
 	case ModeAsync:
 		// Enqueue is deliberately best-effort. The implementation owns a bounded
 		// context and copies request memory before it can outlive the Handler.
-		_ = c.prompt.Enqueue(ctx, req.Clone())
+		enqueueCtx, span := tracing.Start(ctx, "gateway.prompt_audit", attribute.String("sub2api.prompt_audit.mode", "async"))
+		tracing.End(span, c.prompt.Enqueue(enqueueCtx, req.Clone()))
 		legacy, _ := c.checkLegacy(ctx, req)
 		return prioritize(legacy, nil)
 	case ModeBlocking:
@@ -64,7 +68,12 @@ func (c *Coordinator) checkBlocking(ctx context.Context, req Request) Decision {
 			prompt = unavailablePromptDecision(ErrorCodeUnavailable)
 			return
 		}
-		result, err := c.prompt.Evaluate(ctx, req.Clone())
+		evalCtx, span := tracing.Start(ctx, "gateway.prompt_audit", attribute.String("sub2api.prompt_audit.mode", "blocking"))
+		result, err := c.prompt.Evaluate(evalCtx, req.Clone())
+		if result != nil {
+			span.SetAttributes(attribute.String("sub2api.prompt_audit.decision", string(result.Kind)))
+		}
+		tracing.End(span, err)
 		if err != nil {
 			var guardErr *GuardError
 			if errors.As(err, &guardErr) && guardErr.Code == ErrorCodeInvalidResponse {
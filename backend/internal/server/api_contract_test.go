@@ -57,6 +57,7 @@ func TestAPIContracts(t *testing.T) {
 						"frozen_balance": 0,
 						"concurrency": 5,
 					"rpm_limit": 0,
+					"credit_limit": 0,
 					"status": "active",
 					"allowed_groups": null,
 					"created_at": "2025-01-02T03:04:05Z",
@@ -233,6 +234,8 @@ func TestAPIContracts(t *testing.T) {
 					"status": "active",
 					"ip_whitelist": null,
 					"ip_blacklist": null,
+					"model_allowlist": null,
+					"model_denylist": null,
 					"last_used_at": null,
 					"last_used_ip": null,
 					"current_concurrency": 0,
@@ -247,6 +250,9 @@ func TestAPIContracts(t *testing.T) {
 					"window_5h_start": null,
 					"window_1d_start": null,
 					"window_7d_start": null,
+					"rpm_limit": 0,
+					"max_concurrency": 0,
+					"current_rpm": 0,
 					"expires_at": null,
 					"created_at": "2025-01-02T03:04:05Z",
 					"updated_at": "2025-01-02T03:04:05Z"
@@ -284,6 +290,8 @@ func TestAPIContracts(t *testing.T) {
 							"status": "active",
 							"ip_whitelist": null,
 							"ip_blacklist": null,
+							"model_allowlist": null,
+							"model_denylist": null,
 							"last_used_at": null,
 							"last_used_ip": null,
 							"current_concurrency": 0,
@@ -298,6 +306,9 @@ func TestAPIContracts(t *testing.T) {
 							"window_5h_start": null,
 							"window_1d_start": null,
 							"window_7d_start": null,
+							"rpm_limit": 0,
+							"max_concurrency": 0,
+							"current_rpm": 0,
 							"expires_at": null,
 							"created_at": "2025-01-02T03:04:05Z",
 							"updated_at": "2025-01-02T03:04:05Z"
@@ -375,6 +386,9 @@ func TestAPIContracts(t *testing.T) {
 						"audio_tts_price_per_million_chars": null,
 						"audio_stt_price_per_hour": null,
 						"audio_realtime_price_per_min": null,
+						"response_cache_enabled": false,
+						"response_cache_ttl_seconds": 0,
+						"response_cache_hit_multiplier": 0,
 						"allow_image_generation": false,
 						"allow_batch_image_generation": false,
 						"batch_image_discount_multiplier": 0,
@@ -393,6 +407,10 @@ func TestAPIContracts(t *testing.T) {
 						"max_reasoning_effort": "",
 						"reasoning_effort_mappings": null,
 						"rpm_limit": 0,
+						"volume_tiers": null,
+						"allow_gemini_native": false,
+						"hedge_policy": {"enabled": false},
+						"mirror_policy": {"enabled": false},
 						"created_at": "2025-01-02T03:04:05Z",
 						"updated_at": "2025-01-02T03:04:05Z"
 					}
@@ -977,6 +995,18 @@ func TestAPIContracts(t *testing.T) {
 					"payment_cancel_rate_limit_window_mode": "",
 					"payment_alipay_force_qrcode": false,
 					"payment_alipay_mobile_precreate_deep_link": false,
+					"payment_auto_renew_enabled": false,
+					"payment_auto_renew_lead_days": 0,
+					"payment_auto_renew_retry_hours": 0,
+					"payment_auto_renew_max_attempts": 0,
+					"payment_invoice_enabled": false,
+					"payment_invoice_seller_name": "",
+					"payment_invoice_seller_address": "",
+					"payment_invoice_seller_tax_id": "",
+					"payment_invoice_seller_email": "",
+					"payment_invoice_number_prefix": "",
+					"payment_credit_note_number_prefix": "",
+					"payment_invoice_footer": "",
 					"balance_low_notify_enabled": false,
 					"account_quota_notify_enabled": false,
 					"account_scheduling_thresholds": {"anthropic":100,"grok":100,"openai":100},
@@ -1287,6 +1317,18 @@ func TestAPIContracts(t *testing.T) {
 					"payment_cancel_rate_limit_window_mode": "",
 					"payment_alipay_force_qrcode": false,
 					"payment_alipay_mobile_precreate_deep_link": false,
+					"payment_auto_renew_enabled": false,
+					"payment_auto_renew_lead_days": 0,
+					"payment_auto_renew_retry_hours": 0,
+					"payment_auto_renew_max_attempts": 0,
+					"payment_invoice_enabled": false,
+					"payment_invoice_seller_name": "",
+					"payment_invoice_seller_address": "",
+					"payment_invoice_seller_tax_id": "",
+					"payment_invoice_seller_email": "",
+					"payment_invoice_number_prefix": "",
+					"payment_credit_note_number_prefix": "",
+					"payment_invoice_footer": "",
 					"balance_low_notify_enabled": false,
 					"account_quota_notify_enabled": false,
 					"account_scheduling_thresholds": {"anthropic":100,"grok":100,"openai":100},
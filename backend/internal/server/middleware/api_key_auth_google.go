@@ -24,6 +24,9 @@ func APIKeyAuthGoogle(apiKeyService *service.APIKeyService, cfg *config.Config)
 // It is intended for Gemini native endpoints (/v1beta) to match Gemini SDK expectations.
 func APIKeyAuthWithSubscriptionGoogle(apiKeyService *service.APIKeyService, subscriptionService *service.SubscriptionService, cfg *config.Config) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		endSpan := startStageSpan(c, "gateway.auth.api_key")
+		defer endSpan()
+
 		if rejectInvalidAuthAbuse(c, apiKeyService) {
 			abortWithGoogleError(c, 429, "Too many invalid authentication attempts; retry later")
 			return
@@ -79,6 +82,12 @@ func APIKeyAuthWithSubscriptionGoogle(apiKeyService *service.APIKeyService, subs
 		// user/group/platform。
 		SetOpsFallbackAPIKey(c, apiKey)
 
+		if apiKey.Status == service.StatusAPIKeyCreditSuspended {
+			MarkIngressRejected(c, IngressRejectAPIKeyDisabled)
+			abortWithGoogleError(c, 403, "API key is suspended because a credit statement is overdue")
+			return
+		}
+
 		// disabled / 未知状态 → 无条件拦截（expired 和 quota_exhausted 留给计费阶段，
 		// 与主中间件 api_key_auth.go 保持一致）。
 		if !apiKey.IsActive() &&
@@ -130,6 +139,11 @@ func APIKeyAuthWithSubscriptionGoogle(apiKeyService *service.APIKeyService, subs
 			abortWithGoogleError(c, 403, "API Key 所属专属分组不再允许当前用户使用")
 			return
 		}
+		// 模型白/黑名单：与主中间件一致，在调度前拦截。
+		if abortIfAPIKeyModelNotAllowedGoogle(c, apiKey) {
+			return
+		}
+		annotateAPIKeySpan(c.Request.Context(), apiKey)
 
 		// 简易模式：跳过余额和订阅检查
 		if cfg.RunMode == config.RunModeSimple {
@@ -141,6 +155,7 @@ func APIKeyAuthWithSubscriptionGoogle(apiKeyService *service.APIKeyService, subs
 			c.Set(string(ContextKeyUserRole), apiKey.User.Role)
 			setGroupContext(c, apiKey.Group)
 			_ = apiKeyService.TouchLastUsed(c.Request.Context(), apiKey.ID)
+			endSpan()
 			c.Next()
 			return
 		}
@@ -200,7 +215,9 @@ func APIKeyAuthWithSubscriptionGoogle(apiKeyService *service.APIKeyService, subs
 
 			c.Set(string(ContextKeySubscription), subscription)
 		} else {
-			if apiKeyBalanceBelowAuthThreshold(apiKey.User.Balance, cfg) {
+			// 组织成员由组织钱包供款，个人余额耗尽不拦截
+			if apiKeyBalanceBelowAuthThreshold(apiKey.User.SpendableBalance(), cfg) &&
+				!apiKeyService.OrganizationWalletAvailable(c.Request.Context(), apiKey.User.ID) {
 				abortWithGoogleError(c, 403, "Insufficient account balance")
 				return
 			}
@@ -214,6 +231,7 @@ func APIKeyAuthWithSubscriptionGoogle(apiKeyService *service.APIKeyService, subs
 		c.Set(string(ContextKeyUserRole), apiKey.User.Role)
 		setGroupContext(c, apiKey.Group)
 		_ = apiKeyService.TouchLastUsed(c.Request.Context(), apiKey.ID)
+		endSpan()
 		c.Next()
 	}
 }
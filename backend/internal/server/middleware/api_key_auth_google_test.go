@@ -686,6 +686,53 @@ func TestApiKeyAuthWithSubscriptionGoogle_RejectsExhaustedBalance(t *testing.T)
 	require.Equal(t, "PERMISSION_DENIED", resp.Error.Status)
 }
 
+type googleOrgWalletReaderStub struct {
+	wallets map[int64]*service.OrganizationWallet
+}
+
+func (s googleOrgWalletReaderStub) GetWalletForUser(_ context.Context, userID int64) (*service.OrganizationWallet, error) {
+	return s.wallets[userID], nil
+}
+
+func TestApiKeyAuthWithSubscriptionGoogle_OrganizationWalletExemptsExhaustedBalance(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	apiKeyService := newTestAPIKeyService(fakeAPIKeyRepo{
+		getByKey: func(ctx context.Context, key string) (*service.APIKey, error) {
+			return &service.APIKey{
+				ID:     1,
+				Key:    key,
+				Status: service.StatusActive,
+				User: &service.User{
+					ID:      123,
+					Status:  service.StatusActive,
+					Balance: 0,
+				},
+			}, nil
+		},
+	})
+	apiKeyService.SetOrganizationWalletReader(googleOrgWalletReaderStub{wallets: map[int64]*service.OrganizationWallet{
+		123: {Balance: 50},
+	}})
+	r.Use(APIKeyAuthWithSubscriptionGoogle(apiKeyService, nil, &config.Config{}))
+	r.GET("/v1beta/test", func(c *gin.Context) { c.JSON(200, gin.H{"ok": true}) })
+
+	req := httptest.NewRequest(http.MethodGet, "/v1beta/test", nil)
+	req.Header.Set("Authorization", "Bearer ok")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	// 组织钱包耗尽时仍按个人余额拦截。
+	apiKeyService.SetOrganizationWalletReader(googleOrgWalletReaderStub{wallets: map[int64]*service.OrganizationWallet{
+		123: {Balance: 0},
+	}})
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusForbidden, rec.Code)
+}
+
 func TestApiKeyAuthWithSubscriptionGoogle_TouchesLastUsedOnSuccess(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+
+	pkghttputil "github.com/Wei-Shaw/sub2api/internal/pkg/httputil"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+)
+
+// apiKeyModelCheck 是模型白/黑名单检查的结果。
+type apiKeyModelCheck struct {
+	Model   string
+	Allowed bool
+	// ReadStatus 非 0 表示读取请求体失败，应以该状态码拒绝。
+	ReadStatus  int
+	ReadMessage string
+}
+
+// checkAPIKeyModelPolicy 在调度前检查请求模型是否被 API Key 的模型白/黑名单允许。
+//
+// 未配置模型限制的 Key 不读取请求体，保持认证热路径零开销；配置了限制时读取
+// 请求体取出 model 并原样回填，后续中间件与 handler 不受影响。无法识别模型的
+// 请求（GET、WebSocket 升级、无 model 字段）交由 handler 处理，不在此处拦截。
+func checkAPIKeyModelPolicy(c *gin.Context, apiKey *service.APIKey) apiKeyModelCheck {
+	if c == nil || c.Request == nil || !apiKey.HasModelRestrictions() {
+		return apiKeyModelCheck{Allowed: true}
+	}
+	model, status, message := requestModelForPolicy(c)
+	if status != 0 {
+		return apiKeyModelCheck{ReadStatus: status, ReadMessage: message}
+	}
+	if model == "" {
+		return apiKeyModelCheck{Allowed: true}
+	}
+	return apiKeyModelCheck{Model: model, Allowed: apiKey.IsModelAllowed(model)}
+}
+
+func requestModelForPolicy(c *gin.Context) (string, int, string) {
+	if model := modelFromGeminiParams(c); model != "" {
+		return model, 0, ""
+	}
+	if c.Request.Method == http.MethodGet || c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return "", 0, ""
+	}
+	body, err := pkghttputil.ReadRequestBodyWithPrealloc(c.Request)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return "", http.StatusRequestEntityTooLarge, "Request body is too large"
+		}
+		return "", http.StatusBadRequest, "Failed to read request body"
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	c.Request.ContentLength = int64(len(body))
+	c.Request.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	return RequestModelFromBody(c.GetHeader("Content-Type"), body), 0, ""
+}
+
+// RequestModelFromBody 从 JSON 或 multipart/form-data 请求体中提取 model 字段。
+func RequestModelFromBody(contentType string, body []byte) string {
+	if model := strings.TrimSpace(gjson.GetBytes(body, "model").String()); model != "" {
+		return model
+	}
+	return multipartModelFromBody(contentType, body)
+}
+
+func multipartModelFromBody(contentType string, body []byte) string {
+	mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(contentType))
+	if err != nil || !strings.EqualFold(mediaType, "multipart/form-data") {
+		return ""
+	}
+	boundary := strings.TrimSpace(params["boundary"])
+	if boundary == "" {
+		return ""
+	}
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return ""
+		}
+		if err != nil {
+			return ""
+		}
+		if part.FormName() != "model" || part.FileName() != "" {
+			continue
+		}
+		data, err := io.ReadAll(part)
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(data))
+	}
+}
+
+// modelFromGeminiParams 解析 Gemini 原生路由中的模型名（/models/:model 与 /models/{model}:{action}）。
+func modelFromGeminiParams(c *gin.Context) string {
+	if model := strings.TrimSpace(c.Param("model")); model != "" {
+		return model
+	}
+	modelAction := strings.TrimPrefix(strings.TrimSpace(c.Param("modelAction")), "/")
+	if modelAction == "" {
+		return ""
+	}
+	if idx := strings.LastIndex(modelAction, ":"); idx >= 0 {
+		return strings.TrimSpace(modelAction[:idx])
+	}
+	return modelAction
+}
+
+func apiKeyModelNotAllowedMessage(model string) string {
+	return fmt.Sprintf("Model %s is not allowed for this API key", model)
+}
+
+// abortIfAPIKeyModelNotAllowed 以网关默认错误格式拒绝不被允许的模型。
+func abortIfAPIKeyModelNotAllowed(c *gin.Context, apiKey *service.APIKey) bool {
+	check := checkAPIKeyModelPolicy(c, apiKey)
+	if check.ReadStatus != 0 {
+		AbortWithError(c, check.ReadStatus, "INVALID_REQUEST", check.ReadMessage)
+		return true
+	}
+	if check.Allowed {
+		return false
+	}
+	service.MarkOpsClientBusinessLimited(c, service.OpsClientBusinessLimitedReasonModelRestriction)
+	MarkIngressRejected(c, IngressRejectModelNotAllowed)
+	AbortWithError(c, http.StatusForbidden, "MODEL_NOT_ALLOWED", apiKeyModelNotAllowedMessage(check.Model))
+	return true
+}
+
+// abortIfAPIKeyModelNotAllowedGoogle 以 Google 错误格式拒绝不被允许的模型。
+func abortIfAPIKeyModelNotAllowedGoogle(c *gin.Context, apiKey *service.APIKey) bool {
+	check := checkAPIKeyModelPolicy(c, apiKey)
+	if check.ReadStatus != 0 {
+		abortWithGoogleError(c, check.ReadStatus, check.ReadMessage)
+		return true
+	}
+	if check.Allowed {
+		return false
+	}
+	service.MarkOpsClientBusinessLimited(c, service.OpsClientBusinessLimitedReasonModelRestriction)
+	MarkIngressRejected(c, IngressRejectModelNotAllowed)
+	abortWithGoogleError(c, http.StatusForbidden, apiKeyModelNotAllowedMessage(check.Model))
+	return true
+}
@@ -0,0 +1,125 @@
+//go:build unit
+
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newModelPolicyTestRouter(t *testing.T, apiKey *service.APIKey, seenBody *string) (*gin.Engine, *string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	apiKeyRepo := &stubApiKeyRepo{
+		getByKey: func(ctx context.Context, key string) (*service.APIKey, error) {
+			if key != apiKey.Key {
+				return nil, service.ErrAPIKeyNotFound
+			}
+			clone := *apiKey
+			return &clone, nil
+		},
+	}
+	cfg := &config.Config{RunMode: config.RunModeSimple}
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, nil, nil, nil, nil, nil, cfg)
+
+	var reason string
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Next()
+		if v, ok := c.Get(service.OpsClientBusinessLimitedReasonKey); ok {
+			reason, _ = v.(string)
+		}
+	})
+	router.Use(gin.HandlerFunc(NewAPIKeyAuthMiddleware(apiKeyService, nil, cfg)))
+	router.POST("/v1/messages", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		*seenBody = string(body)
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+	router.GET("/v1/models", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+	return router, &reason
+}
+
+func newModelPolicyTestKey() *service.APIKey {
+	user := &service.User{
+		ID:          7,
+		Role:        service.RoleUser,
+		Status:      service.StatusActive,
+		Balance:     10,
+		Concurrency: 3,
+	}
+	return &service.APIKey{
+		ID:             100,
+		UserID:         user.ID,
+		Key:            "test-key",
+		Status:         service.StatusActive,
+		User:           user,
+		ModelAllowlist: []string{"claude-*"},
+		ModelDenylist:  []string{"claude-opus-*"},
+	}
+}
+
+func TestAPIKeyAuthRejectsDisallowedModel(t *testing.T) {
+	apiKey := newModelPolicyTestKey()
+	var seenBody string
+	router, reason := newModelPolicyTestRouter(t, apiKey, &seenBody)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"claude-opus-4-1"}`))
+	req.Header.Set("x-api-key", apiKey.Key)
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusForbidden, w.Code)
+	requireAPIKeyAuthError(t, w, "MODEL_NOT_ALLOWED", "Model claude-opus-4-1 is not allowed for this API key")
+	require.Equal(t, service.OpsClientBusinessLimitedReasonModelRestriction, *reason)
+	require.Empty(t, seenBody)
+}
+
+func TestAPIKeyAuthAllowsPermittedModelAndPreservesBody(t *testing.T) {
+	apiKey := newModelPolicyTestKey()
+	var seenBody string
+	router, _ := newModelPolicyTestRouter(t, apiKey, &seenBody)
+
+	payload := `{"model":"claude-sonnet-4-5","messages":[]}`
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(payload))
+	req.Header.Set("x-api-key", apiKey.Key)
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, payload, seenBody)
+}
+
+func TestAPIKeyAuthModelPolicySkipsRequestsWithoutModel(t *testing.T) {
+	apiKey := newModelPolicyTestKey()
+	var seenBody string
+	router, _ := newModelPolicyTestRouter(t, apiKey, &seenBody)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+	req.Header.Set("x-api-key", apiKey.Key)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRequestModelFromBodyMultipart(t *testing.T) {
+	body := "--b\r\nContent-Disposition: form-data; name=\"model\"\r\n\r\ngpt-image-1\r\n--b--\r\n"
+	require.Equal(t, "gpt-image-1", RequestModelFromBody("multipart/form-data; boundary=b", []byte(body)))
+	require.Equal(t, "gpt-5", RequestModelFromBody("application/json", []byte(`{"model":" gpt-5 "}`)))
+	require.Empty(t, RequestModelFromBody("text/plain", []byte("model=gpt-5")))
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MetricsAuth 校验 Prometheus 抓取请求携带的 Bearer Token。
+// token 为空时拒绝所有请求，避免配置遗漏导致指标裸露。
+func MetricsAuth(token string) gin.HandlerFunc {
+	expected := []byte(strings.TrimSpace(token))
+	return func(c *gin.Context) {
+		var provided string
+		parts := strings.SplitN(c.GetHeader("Authorization"), " ", 2)
+		if len(parts) == 2 && strings.EqualFold(parts[0], "Bearer") {
+			provided = strings.TrimSpace(parts[1])
+		}
+		if len(expected) == 0 || provided == "" || subtle.ConstantTimeCompare([]byte(provided), expected) != 1 {
+			c.Header("WWW-Authenticate", `Bearer realm="metrics"`)
+			AbortWithError(c, 401, "UNAUTHORIZED", "Invalid metrics token")
+			return
+		}
+		c.Next()
+	}
+}
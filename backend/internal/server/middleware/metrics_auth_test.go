@@ -0,0 +1,49 @@
+//go:build unit
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(token string) *gin.Engine {
+		r := gin.New()
+		r.GET("/metrics", MetricsAuth(token), func(c *gin.Context) {
+			c.String(http.StatusOK, "ok")
+		})
+		return r
+	}
+
+	tests := []struct {
+		name   string
+		token  string
+		header string
+		want   int
+	}{
+		{name: "valid token", token: "secret", header: "Bearer secret", want: http.StatusOK},
+		{name: "case insensitive scheme", token: "secret", header: "bearer secret", want: http.StatusOK},
+		{name: "missing header", token: "secret", header: "", want: http.StatusUnauthorized},
+		{name: "wrong token", token: "secret", header: "Bearer nope", want: http.StatusUnauthorized},
+		{name: "wrong scheme", token: "secret", header: "Basic secret", want: http.StatusUnauthorized},
+		{name: "empty configured token rejects all", token: "", header: "Bearer ", want: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			newRouter(tt.token).ServeHTTP(rec, req)
+			require.Equal(t, tt.want, rec.Code)
+		})
+	}
+}
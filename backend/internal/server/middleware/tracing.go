@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tracing"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Tracing 为每个请求开启服务端 span，并从客户端 traceparent/tracestate 继续链路。
+// 需挂在 RequestLogger 之后：request-scoped logger 会追加 trace_id 字段，
+// 便于把系统日志、ops 错误日志与追踪后端互相对照。
+func Tracing() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request == nil {
+			c.Next()
+			return
+		}
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		route := c.FullPath()
+		spanName := c.Request.Method
+		if route != "" {
+			spanName += " " + route
+		}
+		attrs := []trace.SpanStartOption{
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method),
+				semconv.URLPath(c.Request.URL.Path),
+				semconv.ClientAddress(c.ClientIP()),
+			),
+		}
+		if route != "" {
+			attrs = append(attrs, trace.WithAttributes(semconv.HTTPRoute(route)))
+		}
+		ctx, span := tracing.Tracer().Start(ctx, spanName, attrs...)
+		defer span.End()
+
+		if requestID, _ := ctx.Value(ctxkey.RequestID).(string); requestID != "" {
+			span.SetAttributes(tracing.AttrRequestID.String(requestID))
+		}
+		if traceID := tracing.TraceID(ctx); traceID != "" {
+			ctx = logger.IntoContext(ctx, logger.FromContext(ctx).With(zap.String("trace_id", traceID)))
+		}
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
+
+// startStageSpan 为认证等前置中间件阶段开启子 span。
+//
+// 中间件会在自身逻辑末尾调用 c.Next()，若直接 defer span.End() 会把后续所有
+// handler 都算进该阶段。返回的 end 需在 c.Next() 之前调用（中止路径可 defer）：
+// 它结束阶段 span，并把请求上下文的当前 span 恢复为父 span，同时保留阶段内
+// 写入的其它 context 值。end 可重复调用。
+func startStageSpan(c *gin.Context, name string) (end func()) {
+	if c == nil || c.Request == nil {
+		return func() {}
+	}
+	parent := trace.SpanFromContext(c.Request.Context())
+	ctx, span := tracing.Start(c.Request.Context(), name)
+	c.Request = c.Request.WithContext(ctx)
+	done := false
+	return func() {
+		if done {
+			return
+		}
+		done = true
+		if c.IsAborted() {
+			span.SetStatus(codes.Error, fmt.Sprintf("aborted with status %d", c.Writer.Status()))
+		}
+		span.End()
+		c.Request = c.Request.WithContext(trace.ContextWithSpan(c.Request.Context(), parent))
+	}
+}
+
+// annotateAPIKeySpan 在认证 span 上记录已解析的 Key/用户/分组。
+func annotateAPIKeySpan(ctx context.Context, apiKey *service.APIKey) {
+	span := trace.SpanFromContext(ctx)
+	if apiKey == nil || !span.IsRecording() {
+		return
+	}
+	span.SetAttributes(tracing.AttrAPIKeyID.Int64(apiKey.ID))
+	if apiKey.User != nil {
+		span.SetAttributes(tracing.AttrUserID.Int64(apiKey.User.ID))
+	}
+	if apiKey.GroupID != nil {
+		span.SetAttributes(tracing.AttrGroupID.Int64(*apiKey.GroupID))
+	}
+}
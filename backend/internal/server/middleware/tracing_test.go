@@ -0,0 +1,116 @@
+//go:build unit
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func installTestTracer(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	prevProvider := otel.GetTracerProvider()
+	prevPropagator := otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+	return recorder
+}
+
+func TestTracing_ContinuesIncomingTraceparent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := installTestTracer(t)
+
+	r := gin.New()
+	r.Use(Tracing())
+	r.GET("/v1/messages/:id", func(c *gin.Context) {
+		c.Status(http.StatusBadGateway)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/messages/abc", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	ended := recorder.Ended()
+	require.Len(t, ended, 1)
+	span := ended[0]
+	require.Equal(t, "GET /v1/messages/:id", span.Name())
+	require.Equal(t, trace.SpanKindServer, span.SpanKind())
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+	require.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+	require.Contains(t, span.Attributes(), semconv.HTTPRoute("/v1/messages/:id"))
+	require.Contains(t, span.Attributes(), semconv.HTTPResponseStatusCode(http.StatusBadGateway))
+	require.Equal(t, codes.Error, span.Status().Code)
+}
+
+func TestStartStageSpan_EndsBeforeNextAndRestoresParent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := installTestTracer(t)
+
+	var handlerParent trace.SpanContext
+	r := gin.New()
+	r.Use(Tracing())
+	r.Use(func(c *gin.Context) {
+		endSpan := startStageSpan(c, "gateway.auth.api_key")
+		defer endSpan()
+		endSpan()
+		c.Next()
+	})
+	r.GET("/v1/models", func(c *gin.Context) {
+		handlerParent = trace.SpanFromContext(c.Request.Context()).SpanContext()
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/models", nil))
+
+	ended := recorder.Ended()
+	require.Len(t, ended, 2)
+	stage, server := ended[0], ended[1]
+	require.Equal(t, "gateway.auth.api_key", stage.Name())
+	require.Equal(t, server.SpanContext().SpanID(), stage.Parent().SpanID())
+	require.Equal(t, codes.Unset, stage.Status().Code)
+	require.Equal(t, server.SpanContext().SpanID(), handlerParent.SpanID())
+}
+
+func TestStartStageSpan_MarksAbortAsError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := installTestTracer(t)
+
+	r := gin.New()
+	r.Use(Tracing())
+	r.Use(func(c *gin.Context) {
+		endSpan := startStageSpan(c, "gateway.auth.api_key")
+		defer endSpan()
+		AbortWithError(c, http.StatusUnauthorized, "INVALID_API_KEY", "invalid")
+	})
+	r.GET("/v1/models", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/models", nil))
+
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	ended := recorder.Ended()
+	require.Len(t, ended, 2)
+	require.Equal(t, codes.Error, ended[0].Status().Code)
+	// 4xx 不算服务端错误
+	require.Equal(t, codes.Unset, ended[1].Status().Code)
+}
@@ -37,6 +37,17 @@ func RegisterAdminRoutes(
 		// 用户管理
 		registerUserManagementRoutes(admin, h)
 
+		// 组织管理
+		registerOrganizationRoutes(admin, h)
+
+		// 余额账本与对账
+		registerBalanceLedgerRoutes(admin, h)
+		registerCreditStatementRoutes(admin, h)
+		registerSpendAnomalyRoutes(admin, h)
+
+		// 用量导出
+		registerUsageExportRoutes(admin, h)
+
 		// 分组管理
 		registerGroupRoutes(admin, h)
 
@@ -124,6 +135,69 @@ func RegisterAdminRoutes(
 	}
 }
 
+func registerOrganizationRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	organizations := admin.Group("/organizations")
+	{
+		organizations.GET("", h.Admin.Organization.List)
+		organizations.POST("", h.Admin.Organization.Create)
+		organizations.GET("/:id", h.Admin.Organization.GetByID)
+		organizations.PUT("/:id", h.Admin.Organization.Update)
+		organizations.DELETE("/:id", h.Admin.Organization.Delete)
+		organizations.POST("/:id/balance", h.Admin.Organization.AdjustBalance)
+		organizations.GET("/:id/members", h.Admin.Organization.ListMembers)
+		organizations.POST("/:id/members", h.Admin.Organization.AddMember)
+		organizations.PUT("/:id/members/:user_id", h.Admin.Organization.UpdateMember)
+		organizations.DELETE("/:id/members/:user_id", h.Admin.Organization.RemoveMember)
+		organizations.GET("/:id/subscriptions", h.Admin.Organization.ListSubscriptions)
+		organizations.POST("/:id/subscriptions", h.Admin.Organization.AssignSubscription)
+		organizations.DELETE("/:id/subscriptions/:group_id", h.Admin.Organization.RemoveSubscription)
+		organizations.GET("/:id/usage", h.Admin.Organization.Usage)
+	}
+}
+
+func registerBalanceLedgerRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	admin.GET("/users/:id/balance-ledger", h.Admin.BalanceLedger.ListUserEntries)
+	admin.GET("/organizations/:id/balance-ledger", h.Admin.BalanceLedger.ListOrganizationEntries)
+	reconciliations := admin.Group("/balance-ledger/reconciliations")
+	{
+		reconciliations.GET("", h.Admin.BalanceLedger.ListReconciliations)
+		reconciliations.POST("", h.Admin.BalanceLedger.Reconcile)
+		reconciliations.GET("/:id", h.Admin.BalanceLedger.GetReconciliation)
+	}
+}
+
+func registerCreditStatementRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	statements := admin.Group("/credit-statements")
+	{
+		statements.GET("", h.Admin.CreditStatement.List)
+		statements.POST("/run", h.Admin.CreditStatement.Run)
+		statements.GET("/:id", h.Admin.CreditStatement.Get)
+		statements.POST("/:id/settle", h.Admin.CreditStatement.Settle)
+	}
+}
+
+func registerSpendAnomalyRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	anomalies := admin.Group("/spend-anomalies")
+	{
+		anomalies.GET("", h.Admin.SpendAnomaly.List)
+		anomalies.POST("/detect", h.Admin.SpendAnomaly.Detect)
+	}
+}
+
+func registerUsageExportRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	exports := admin.Group("/usage-exports")
+	{
+		exports.GET("/jobs", h.Admin.UsageExport.ListJobs)
+		exports.POST("/jobs", h.Admin.UsageExport.CreateJob)
+		exports.PUT("/jobs/:id", h.Admin.UsageExport.UpdateJob)
+		exports.DELETE("/jobs/:id", h.Admin.UsageExport.DeleteJob)
+		exports.POST("/jobs/:id/run", h.Admin.UsageExport.RunJob)
+		exports.POST("/jobs/:id/backfill", h.Admin.UsageExport.Backfill)
+		exports.GET("/runs", h.Admin.UsageExport.ListRuns)
+		exports.GET("/runs/:id/download", h.Admin.UsageExport.DownloadRun)
+	}
+}
+
 func registerPromptAuditRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 	promptAudit := admin.Group("/prompt-audit")
 	{
@@ -191,6 +265,7 @@ func registerOpsRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 		// Alerts (rules + events)
 		ops.GET("/alert-rules", h.Admin.Ops.ListAlertRules)
 		ops.POST("/alert-rules", h.Admin.Ops.CreateAlertRule)
+		ops.POST("/alert-rules/test-channel", h.Admin.Ops.TestAlertChannel)
 		ops.PUT("/alert-rules/:id", h.Admin.Ops.UpdateAlertRule)
 		ops.DELETE("/alert-rules/:id", h.Admin.Ops.DeleteAlertRule)
 		ops.GET("/alert-events", h.Admin.Ops.ListAlertEvents)
@@ -266,6 +341,10 @@ func registerOpsRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 		ops.GET("/dashboard/error-trend", h.Admin.Ops.GetDashboardErrorTrend)
 		ops.GET("/dashboard/error-distribution", h.Admin.Ops.GetDashboardErrorDistribution)
 		ops.GET("/dashboard/openai-token-stats", h.Admin.Ops.GetDashboardOpenAITokenStats)
+
+		// Traffic mirror comparisons (candidate upstream evaluation)
+		ops.GET("/traffic-mirror/summary", h.Admin.Ops.GetTrafficMirrorSummary)
+		ops.GET("/traffic-mirror/records", h.Admin.Ops.ListTrafficMirrorRecords)
 	}
 }
 
@@ -330,12 +409,14 @@ func registerGroupRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 		groups.POST("/:id/composite-routes/preview", h.Admin.Group.PreviewCompositeRoute)
 		groups.PUT("/:id/composite-routes/:route_id", h.Admin.Group.UpdateCompositeRoute)
 		groups.DELETE("/:id/composite-routes/:route_id", h.Admin.Group.DeleteCompositeRoute)
+		groups.GET("/:id/composite-routes/:route_id/experiment-stats", h.Admin.Group.GetCompositeRouteExperimentStats)
 		groups.GET("/:id", h.Admin.Group.GetByID)
 		groups.POST("", h.Admin.Group.Create)
 		groups.POST("/:id/duplicate", h.Admin.Group.Duplicate)
 		groups.PUT("/:id", h.Admin.Group.Update)
 		groups.DELETE("/:id", h.Admin.Group.Delete)
 		groups.GET("/:id/stats", h.Admin.Group.GetStats)
+		groups.GET("/:id/hedge-stats", h.Admin.Group.GetHedgeStats)
 		groups.GET("/:id/rate-multipliers", h.Admin.Group.GetGroupRateMultipliers)
 		groups.PUT("/:id/rate-multipliers", h.Admin.Group.BatchSetGroupRateMultipliers)
 		groups.DELETE("/:id/rate-multipliers", h.Admin.Group.ClearGroupRateMultipliers)
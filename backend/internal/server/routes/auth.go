@@ -207,6 +207,35 @@ func RegisterAuthRoutes(
 			}),
 			h.Auth.CreateOIDCOAuthAccount,
 		)
+		auth.GET("/oauth/saml/start", h.Auth.SAMLOAuthStart)
+		auth.POST("/oauth/saml/start", rateLimiter.LimitWithOptions("oauth-saml-start", 20, time.Minute, middleware.RateLimitOptions{
+			FailureMode: middleware.RateLimitFailClose,
+		}), h.Auth.SAMLOAuthStart)
+		auth.GET("/oauth/saml/bind/start", func(c *gin.Context) {
+			query := c.Request.URL.Query()
+			query.Set("intent", "bind_current_user")
+			c.Request.URL.RawQuery = query.Encode()
+			h.Auth.SAMLOAuthStart(c)
+		})
+		auth.GET("/oauth/saml/metadata", h.Auth.SAMLMetadata)
+		auth.POST("/oauth/saml/acs",
+			rateLimiter.LimitWithOptions("oauth-saml-acs", 20, time.Minute, middleware.RateLimitOptions{
+				FailureMode: middleware.RateLimitFailClose,
+			}),
+			h.Auth.SAMLAssertionConsumerService,
+		)
+		auth.POST("/oauth/saml/bind-login",
+			rateLimiter.LimitWithOptions("oauth-saml-bind-login", 20, time.Minute, middleware.RateLimitOptions{
+				FailureMode: middleware.RateLimitFailClose,
+			}),
+			h.Auth.BindSAMLOAuthLogin,
+		)
+		auth.POST("/oauth/saml/create-account",
+			rateLimiter.LimitWithOptions("oauth-saml-create-account", 10, time.Minute, middleware.RateLimitOptions{
+				FailureMode: middleware.RateLimitFailClose,
+			}),
+			h.Auth.CreateSAMLOAuthAccount,
+		)
 		auth.GET("/oauth/dingtalk/start", h.Auth.DingTalkOAuthStart)
 		auth.POST("/oauth/dingtalk/start", rateLimiter.LimitWithOptions("oauth-dingtalk-start", 20, time.Minute, middleware.RateLimitOptions{
 			FailureMode: middleware.RateLimitFailClose,
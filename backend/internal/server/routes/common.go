@@ -3,6 +3,10 @@ package routes
 import (
 	"net/http"
 
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/metrics"
+	"github.com/Wei-Shaw/sub2api/internal/server/middleware"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -30,3 +34,11 @@ func RegisterCommonRoutes(r *gin.Engine) {
 		})
 	})
 }
+
+// RegisterMetricsRoutes 注册 Prometheus 抓取端点（仅在 metrics.enabled 时挂载）
+func RegisterMetricsRoutes(r *gin.Engine, cfg *config.Config) {
+	if cfg == nil || !cfg.Metrics.Enabled {
+		return
+	}
+	r.GET("/metrics", middleware.MetricsAuth(cfg.Metrics.AuthToken), gin.WrapH(metrics.Handler()))
+}
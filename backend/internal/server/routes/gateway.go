@@ -4,8 +4,6 @@ import (
 	"bytes"
 	"errors"
 	"io"
-	"mime"
-	"mime/multipart"
 	"net/http"
 	"strconv"
 	"strings"
@@ -66,6 +64,32 @@ func RegisterGatewayRoutes(
 			h.Gateway.CountTokens(c)
 		}
 	}
+	// 文本生成端点支持客户端指定的模型降级链（handler.ModelFallbackHeader / fallback_models）：
+	// 降级后按新模型解析出的平台重新分流，跨协议由各处理器内的 apicompat 转换承接。
+	// 外层的流量镜像按分组策略把抽样请求异步重放到候选分组，镜像侧按候选分组平台分流。
+	messagesHandler := h.TrafficMirror.Wrap(service.CompositeRouteEndpointMessages, handler.ModelFallback(compositeResolver, service.CompositeRouteEndpointMessages, func(c *gin.Context) {
+		if isOpenAIResponsesCompatibleGatewayPlatform(c) {
+			h.OpenAIGateway.Messages(c)
+			return
+		}
+		h.Gateway.Messages(c)
+	}))
+	routeResponses := func(c *gin.Context) {
+		if isOpenAIResponsesCompatibleGatewayPlatform(c) {
+			h.OpenAIGateway.Responses(c)
+			return
+		}
+		h.Gateway.Responses(c)
+	}
+	responsesHandler := h.TrafficMirror.Wrap(service.CompositeRouteEndpointResponses, handler.ModelFallback(compositeResolver, service.CompositeRouteEndpointResponses, routeResponses))
+	routeChatCompletions := func(c *gin.Context) {
+		if isOpenAIResponsesCompatibleGatewayPlatform(c) {
+			h.OpenAIGateway.ChatCompletions(c)
+			return
+		}
+		h.Gateway.ChatCompletions(c)
+	}
+	chatCompletionsHandler := h.TrafficMirror.Wrap(service.CompositeRouteEndpointChatCompletions, handler.ModelFallback(compositeResolver, service.CompositeRouteEndpointChatCompletions, routeChatCompletions))
 	modelsHandler := func(c *gin.Context) {
 		if isOpenAIGatewayPlatform(c) && c.Query("client_version") != "" {
 			h.OpenAIGateway.CodexModels(c)
@@ -184,16 +208,15 @@ func RegisterGatewayRoutes(
 	gateway.Use(requireGroupAnthropic)
 	{
 		// /v1/messages: auto-route based on group platform
-		gateway.POST("/messages", func(c *gin.Context) {
-			if isOpenAIResponsesCompatibleGatewayPlatform(c) {
-				h.OpenAIGateway.Messages(c)
-				return
-			}
-			h.Gateway.Messages(c)
-		})
+		gateway.POST("/messages", h.ResponseCache.Middleware(service.ResponseCacheEndpointMessages), messagesHandler)
 		// /v1/messages/count_tokens: OpenAI bridges upstream, Grok estimates
 		// locally, and Anthropic-compatible platforms retain their existing path.
 		gateway.POST("/messages/count_tokens", countTokensHandler)
+		gateway.POST("/messages/batches", h.MessageBatch.Create)
+		gateway.GET("/messages/batches", h.MessageBatch.List)
+		gateway.GET("/messages/batches/:id", h.MessageBatch.Get)
+		gateway.POST("/messages/batches/:id/cancel", h.MessageBatch.Cancel)
+		gateway.GET("/messages/batches/:id/results", h.MessageBatch.Results)
 		// Codex CLI / Codex app refresh their model picker from the provider's
 		// /models endpoint with a client_version query and expect the ChatGPT
 		// Codex manifest format; other clients keep the OpenAI-style list.
@@ -202,32 +225,14 @@ func RegisterGatewayRoutes(
 		gateway.POST("/live", h.OpenAIGateway.Live)
 		gateway.GET("/live/:call_id", h.OpenAIGateway.LiveSideband)
 		// OpenAI Responses API: auto-route based on group platform
-		gateway.POST("/responses", func(c *gin.Context) {
-			if isOpenAIResponsesCompatibleGatewayPlatform(c) {
-				h.OpenAIGateway.Responses(c)
-				return
-			}
-			h.Gateway.Responses(c)
-		})
-		gateway.POST("/responses/*subpath", guardResponsesSubpath(func(c *gin.Context) {
-			if isOpenAIResponsesCompatibleGatewayPlatform(c) {
-				h.OpenAIGateway.Responses(c)
-				return
-			}
-			h.Gateway.Responses(c)
-		}))
+		gateway.POST("/responses", h.ResponseCache.Middleware(service.ResponseCacheEndpointResponses), responsesHandler)
+		gateway.POST("/responses/*subpath", guardResponsesSubpath(routeResponses))
 		gateway.POST("/alpha/search", textBodyLimit, h.OpenAIGateway.AlphaSearch)
 		gateway.GET("/responses", func(c *gin.Context) {
 			h.OpenAIGateway.ResponsesWebSocket(c)
 		})
 		// OpenAI Chat Completions API: auto-route based on group platform
-		gateway.POST("/chat/completions", func(c *gin.Context) {
-			if isOpenAIResponsesCompatibleGatewayPlatform(c) {
-				h.OpenAIGateway.ChatCompletions(c)
-				return
-			}
-			h.Gateway.ChatCompletions(c)
-		})
+		gateway.POST("/chat/completions", h.ResponseCache.Middleware(service.ResponseCacheEndpointChatCompletions), chatCompletionsHandler)
 		gateway.POST("/embeddings", textBodyLimit, func(c *gin.Context) {
 			if !isOpenAIOnlyEndpointGatewayPlatform(c) {
 				service.MarkOpsClientBusinessLimited(c, service.OpsClientBusinessLimitedReasonLocalFeatureGate)
@@ -256,6 +261,16 @@ func RegisterGatewayRoutes(
 		gateway.POST("/images/batches/:id/cancel", h.BatchImage.Cancel)
 		gateway.DELETE("/images/batches/:id", h.BatchImage.DeleteRecord)
 		gateway.DELETE("/images/batches/:id/outputs", h.BatchImage.DeleteOutputs)
+		// OpenAI Files / Batch API：文件与批次固定在上传时选中的 OpenAI API Key 账号上。
+		gateway.POST("/files", h.OpenAIBatch.UploadFile)
+		gateway.GET("/files", h.OpenAIBatch.ListFiles)
+		gateway.GET("/files/:id", h.OpenAIBatch.GetFile)
+		gateway.DELETE("/files/:id", h.OpenAIBatch.DeleteFile)
+		gateway.GET("/files/:id/content", h.OpenAIBatch.FileContent)
+		gateway.POST("/batches", textBodyLimit, h.OpenAIBatch.CreateBatch)
+		gateway.GET("/batches", h.OpenAIBatch.ListBatches)
+		gateway.GET("/batches/:id", h.OpenAIBatch.GetBatch)
+		gateway.POST("/batches/:id/cancel", h.OpenAIBatch.CancelBatch)
 		// OpenAI-compatible clients may create through /videos; xAI receives the
 		// canonical /videos/generations route inside the Grok media forwarder.
 		gateway.POST("/videos", videoGenerationHandler)
@@ -330,19 +345,19 @@ func RegisterGatewayRoutes(
 		gemini.GET("/models", h.Gateway.GeminiV1BetaListModels)
 		gemini.GET("/models/:model", h.Gateway.GeminiV1BetaGetModel)
 		// Gin treats ":" as a param marker, but Gemini uses "{model}:{action}" in the same segment.
-		gemini.POST("/models/*modelAction", h.Gateway.GeminiV1BetaModels)
+		// openai 分组（allow_gemini_native）经 Responses 转换链服务，其余仍走 Gemini 原生处理。
+		gemini.POST("/models/*modelAction", func(c *gin.Context) {
+			if getGroupPlatform(c) == service.PlatformOpenAI {
+				h.OpenAIGateway.GeminiV1BetaModels(c)
+				return
+			}
+			h.Gateway.GeminiV1BetaModels(c)
+		})
 	}
 
 	// OpenAI Responses API（不带v1前缀的别名）— auto-route based on group platform
-	responsesHandler := func(c *gin.Context) {
-		if isOpenAIResponsesCompatibleGatewayPlatform(c) {
-			h.OpenAIGateway.Responses(c)
-			return
-		}
-		h.Gateway.Responses(c)
-	}
-	r.POST("/responses", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), compositeTarget, requireGroupAnthropic, responsesHandler)
-	r.POST("/responses/*subpath", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), compositeTarget, requireGroupAnthropic, guardResponsesSubpath(responsesHandler))
+	r.POST("/responses", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), compositeTarget, requireGroupAnthropic, h.ResponseCache.Middleware(service.ResponseCacheEndpointResponses), responsesHandler)
+	r.POST("/responses/*subpath", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), compositeTarget, requireGroupAnthropic, guardResponsesSubpath(routeResponses))
 	r.POST("/alpha/search", textBodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), compositeTarget, requireGroupAnthropic, h.OpenAIGateway.AlphaSearch)
 	r.GET("/responses", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), compositeTarget, requireGroupAnthropic, func(c *gin.Context) {
 		h.OpenAIGateway.ResponsesWebSocket(c)
@@ -355,7 +370,7 @@ func RegisterGatewayRoutes(
 		codexDirect.POST("/realtime/calls", h.OpenAIGateway.Live)
 		codexDirect.GET("/:call_id", h.OpenAIGateway.LiveSideband)
 		codexDirect.POST("/responses", responsesHandler)
-		codexDirect.POST("/responses/*subpath", guardResponsesSubpath(responsesHandler))
+		codexDirect.POST("/responses/*subpath", guardResponsesSubpath(routeResponses))
 		codexDirect.POST("/alpha/search", textBodyLimit, h.OpenAIGateway.AlphaSearch)
 		codexDirect.GET("/responses", func(c *gin.Context) {
 			h.OpenAIGateway.ResponsesWebSocket(c)
@@ -363,13 +378,15 @@ func RegisterGatewayRoutes(
 		codexDirect.GET("/models", h.OpenAIGateway.CodexModels)
 	}
 	// OpenAI Chat Completions API（不带v1前缀的别名）— auto-route based on group platform
-	r.POST("/chat/completions", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), compositeTarget, requireGroupAnthropic, func(c *gin.Context) {
-		if isOpenAIResponsesCompatibleGatewayPlatform(c) {
-			h.OpenAIGateway.ChatCompletions(c)
-			return
-		}
-		h.Gateway.ChatCompletions(c)
-	})
+	r.POST("/chat/completions", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), compositeTarget, requireGroupAnthropic, h.ResponseCache.Middleware(service.ResponseCacheEndpointChatCompletions), chatCompletionsHandler)
+	// Ollama 兼容 API（编辑器 / 自托管 UI）：请求体在鉴权前转换为 Chat Completions，
+	// 之后走与 /v1/chat/completions 相同的鉴权、调度与计费链路。
+	ollamaChatCompletions := handler.OllamaCompletions(chatCompletionsHandler)
+	ollamaErrors := handler.OllamaErrorMiddleware()
+	r.POST("/api/chat", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, handler.OllamaCompatMiddleware(false), gin.HandlerFunc(apiKeyAuth), compositeTarget, requireGroupAnthropic, ollamaChatCompletions)
+	r.POST("/api/generate", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, handler.OllamaCompatMiddleware(true), gin.HandlerFunc(apiKeyAuth), compositeTarget, requireGroupAnthropic, ollamaChatCompletions)
+	r.GET("/api/tags", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, ollamaErrors, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, h.Gateway.OllamaTags)
+	r.POST("/api/show", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, ollamaErrors, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, h.Gateway.OllamaShow)
 	r.POST("/embeddings", textBodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), compositeTarget, requireGroupAnthropic, func(c *gin.Context) {
 		if !isOpenAIOnlyEndpointGatewayPlatform(c) {
 			service.MarkOpsClientBusinessLimited(c, service.OpsClientBusinessLimitedReasonLocalFeatureGate)
@@ -522,9 +539,9 @@ func compositeTargetPlatformMiddleware(resolver *service.CompositeRouteResolver)
 			return
 		}
 
-		model := compositeRequestModelFromBody(c.GetHeader("Content-Type"), body)
+		model := middleware.RequestModelFromBody(c.GetHeader("Content-Type"), body)
 		if model != "" {
-			decision, err := resolver.Resolve(c.Request.Context(), apiKey.Group.ID, model, compositeRouteEndpointForPath(c.Request.URL.Path))
+			decision, err := resolver.ResolveFor(c.Request.Context(), apiKey.Group.ID, model, compositeRouteEndpointForPath(c.Request.URL.Path), compositeRouteSubject(apiKey))
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": gin.H{"type": "server_error", "message": "Failed to resolve composite model route"}})
 				c.Abort()
@@ -532,6 +549,7 @@ func compositeTargetPlatformMiddleware(resolver *service.CompositeRouteResolver)
 			}
 			if decision.Matched {
 				c.Request = c.Request.WithContext(service.WithCompositeRouteDecision(c.Request.Context(), decision))
+				resolver.RecordExperimentAssignment(c.Request.Context(), decision)
 				if upstreamModel := strings.TrimSpace(decision.UpstreamModel); upstreamModel != "" && upstreamModel != model && gjson.ValidBytes(body) {
 					if rewritten, rewriteErr := sjson.SetBytes(body, "model", upstreamModel); rewriteErr == nil {
 						body = rewritten
@@ -544,42 +562,6 @@ func compositeTargetPlatformMiddleware(resolver *service.CompositeRouteResolver)
 	}
 }
 
-func compositeRequestModelFromBody(contentType string, body []byte) string {
-	if model := strings.TrimSpace(gjson.GetBytes(body, "model").String()); model != "" {
-		return model
-	}
-	return compositeMultipartModelFromBody(contentType, body)
-}
-
-func compositeMultipartModelFromBody(contentType string, body []byte) string {
-	mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(contentType))
-	if err != nil || !strings.EqualFold(mediaType, "multipart/form-data") {
-		return ""
-	}
-	boundary := strings.TrimSpace(params["boundary"])
-	if boundary == "" {
-		return ""
-	}
-	reader := multipart.NewReader(bytes.NewReader(body), boundary)
-	for {
-		part, err := reader.NextPart()
-		if errors.Is(err, io.EOF) {
-			return ""
-		}
-		if err != nil {
-			return ""
-		}
-		if part.FormName() != "model" || part.FileName() != "" {
-			continue
-		}
-		data, err := io.ReadAll(part)
-		if err != nil {
-			return ""
-		}
-		return strings.TrimSpace(string(data))
-	}
-}
-
 func compositeGeminiTargetPlatformMiddleware(resolver *service.CompositeRouteResolver) gin.HandlerFunc {
 	if resolver == nil {
 		resolver = service.NewCompositeRouteResolver(nil)
@@ -589,7 +571,7 @@ func compositeGeminiTargetPlatformMiddleware(resolver *service.CompositeRouteRes
 		if ok && apiKey != nil && apiKey.Group != nil && apiKey.Group.Platform == service.PlatformComposite {
 			model := compositeGeminiModelFromParams(c)
 			if model != "" {
-				decision, err := resolver.Resolve(c.Request.Context(), apiKey.Group.ID, model, service.CompositeRouteEndpointGemini)
+				decision, err := resolver.ResolveFor(c.Request.Context(), apiKey.Group.ID, model, service.CompositeRouteEndpointGemini, compositeRouteSubject(apiKey))
 				if err != nil {
 					c.JSON(http.StatusInternalServerError, gin.H{"error": gin.H{"type": "server_error", "message": "Failed to resolve composite model route"}})
 					c.Abort()
@@ -597,6 +579,7 @@ func compositeGeminiTargetPlatformMiddleware(resolver *service.CompositeRouteRes
 				}
 				if decision.Matched {
 					c.Request = c.Request.WithContext(service.WithCompositeRouteDecision(c.Request.Context(), decision))
+					resolver.RecordExperimentAssignment(c.Request.Context(), decision)
 				}
 			}
 			if _, resolved := service.ResolvedTargetPlatformFromContext(c.Request.Context()); !resolved {
@@ -607,6 +590,14 @@ func compositeGeminiTargetPlatformMiddleware(resolver *service.CompositeRouteRes
 	}
 }
 
+// compositeRouteSubject 返回拆分路由粘连分流使用的调用方身份。
+func compositeRouteSubject(apiKey *service.APIKey) service.CompositeRouteSubject {
+	if apiKey == nil {
+		return service.CompositeRouteSubject{}
+	}
+	return service.CompositeRouteSubject{APIKeyID: apiKey.ID, UserID: apiKey.UserID}
+}
+
 // grokCustomVoiceEndpoint derives the upstream Voice endpoint for the
 // /custom-voices/:voice_id[/audio] routes.
 //
@@ -654,7 +645,7 @@ func compositeRouteEndpointForPath(path string) string {
 		return service.CompositeRouteEndpointMessages
 	case strings.Contains(path, "/responses"):
 		return service.CompositeRouteEndpointResponses
-	case strings.Contains(path, "/chat/completions"):
+	case strings.Contains(path, "/chat/completions"), path == "/api/chat", path == "/api/generate":
 		return service.CompositeRouteEndpointChatCompletions
 	case strings.Contains(path, "/embeddings"):
 		return service.CompositeRouteEndpointEmbeddings
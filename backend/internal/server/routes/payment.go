@@ -42,8 +42,16 @@ func RegisterPaymentRoutes(
 			orders.GET("/:id", paymentHandler.GetOrder)
 			orders.POST("/:id/cancel", paymentHandler.CancelOrder)
 			orders.POST("/:id/refund-request", paymentHandler.RequestRefund)
+			orders.GET("/:id/invoices", paymentHandler.ListOrderInvoices)
+			orders.GET("/:id/invoices/:invoiceId", paymentHandler.DownloadOrderInvoice)
 			orders.GET("/refund-eligible-providers", paymentHandler.GetRefundEligibleProviders)
 		}
+
+		autoRenewals := authenticated.Group("/auto-renewals")
+		{
+			autoRenewals.GET("", paymentHandler.ListAutoRenewals)
+			autoRenewals.POST("/:id/cancel", paymentHandler.CancelAutoRenewal)
+		}
 	}
 
 	// --- Public payment endpoints (no auth) ---
@@ -66,6 +74,7 @@ func RegisterPaymentRoutes(
 		webhook.POST("/wxpay", webhookHandler.WxpayNotify)
 		webhook.POST("/stripe", webhookHandler.StripeWebhook)
 		webhook.POST("/airwallex", webhookHandler.AirwallexWebhook)
+		webhook.POST("/paypal", webhookHandler.PayPalWebhook)
 	}
 
 	// --- Admin payment endpoints (admin auth) ---
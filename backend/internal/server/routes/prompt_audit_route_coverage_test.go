@@ -32,6 +32,8 @@ func TestEveryGatewayPOSTRouteIsClassifiedForPromptAuditCoverage(t *testing.T) {
 		"/responses":                {"gateway_handler_responses.go", "openai_gateway_handler.go"},
 		"/responses/*subpath":       {"gateway_handler_responses.go", "openai_gateway_handler.go"},
 		"/chat/completions":         {"gateway_handler_chat_completions.go", "openai_chat_completions.go"},
+		"/api/chat":                 {"gateway_handler_chat_completions.go", "openai_chat_completions.go"},
+		"/api/generate":             {"gateway_handler_chat_completions.go", "openai_chat_completions.go"},
 		"/embeddings":               {"openai_embeddings.go"},
 		"/alpha/search":             {"openai_alpha_search.go"},
 		"/live":                     {"openai_live.go"},
@@ -41,6 +43,8 @@ func TestEveryGatewayPOSTRouteIsClassifiedForPromptAuditCoverage(t *testing.T) {
 		"/images/generations/async": {"image_task_handler.go"},
 		"/images/edits/async":       {"image_task_handler.go"},
 		"/images/batches":           {"batch_image_handler.go"},
+		"/messages/batches":         {"message_batch_handler.go"},
+		"/files":                    {"openai_batch_handler.go"},
 		"/videos":                   {"grok_media.go"},
 		"/videos/generations":       {"grok_media.go"},
 		"/videos/edits":             {"grok_media.go"},
@@ -50,10 +54,14 @@ func TestEveryGatewayPOSTRouteIsClassifiedForPromptAuditCoverage(t *testing.T) {
 		"/web_search":               {"gateway_web_search.go"},
 	}
 	excluded := map[string]string{
-		"/messages/count_tokens":     "tokenization only; it does not execute a model request",
-		"/images/batches/:id/cancel": "control-plane cancellation with no user prompt",
-		"/stt":                       "speech transcription is not a text-generation prompt",
-		"/custom-voices":             "voice profile management has no model prompt",
+		"/messages/count_tokens":       "tokenization only; it does not execute a model request",
+		"/images/batches/:id/cancel":   "control-plane cancellation with no user prompt",
+		"/messages/batches/:id/cancel": "control-plane cancellation with no user prompt",
+		"/batches":                     "references an uploaded input file whose lines are audited at /files",
+		"/batches/:id/cancel":          "control-plane cancellation with no user prompt",
+		"/stt":                         "speech transcription is not a text-generation prompt",
+		"/custom-voices":               "voice profile management has no model prompt",
+		"/api/show":                    "Ollama model metadata lookup with no user prompt",
 	}
 
 	unclassified := make([]string, 0)
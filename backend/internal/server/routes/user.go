@@ -39,6 +39,8 @@ func RegisterUserRoutes(
 			user.POST("/auth-identities/bind/start", h.User.StartIdentityBinding)
 			user.GET("/api-keys/:id/usage/daily", panelRateLimiter.Heavy(), h.Usage.GetMyAPIKeyDailyUsage)
 			user.GET("/platform-quotas", h.User.GetMyPlatformQuotas)
+			user.GET("/credit-statements", h.CreditStatement.List)
+			user.GET("/credit-statements/:id", h.CreditStatement.Get)
 
 			// 通知邮箱管理
 			notifyEmail := user.Group("/notify-email")
@@ -102,10 +104,15 @@ func RegisterUserRoutes(
 			usage.GET("", h.Usage.List)
 			usage.GET("/errors", h.Usage.ListErrors)
 			usage.GET("/errors/:id", h.Usage.GetErrorDetail)
+			usage.GET("/exports", h.UsageExport.List)
+			usage.POST("/exports", h.UsageExport.Create)
+			usage.GET("/exports/:id/download", h.UsageExport.Download)
 			usage.GET("/:id", h.Usage.GetByID)
 			usage.GET("/stats", h.Usage.Stats)
 			// User dashboard endpoints
 			usage.GET("/dashboard/stats", h.Usage.DashboardStats)
+			usage.GET("/dashboard/volume-tiers", h.Usage.DashboardVolumeTiers)
+			usage.GET("/dashboard/balance-forecast", h.Usage.DashboardBalanceForecast)
 			usage.GET("/dashboard/trend", h.Usage.DashboardTrend)
 			usage.GET("/dashboard/models", h.Usage.DashboardModels)
 			usage.GET("/dashboard/snapshot-v2", h.Usage.DashboardSnapshotV2)
@@ -119,6 +126,26 @@ func RegisterUserRoutes(
 			announcements.POST("/:id/read", h.Announcement.MarkRead)
 		}
 
+		// 组织（共享钱包、成员、组织订阅）
+		organization := authenticated.Group("/organization")
+		{
+			organization.GET("", h.Organization.Get)
+			organization.POST("", h.Organization.Create)
+			organization.PUT("", h.Organization.Rename)
+			organization.GET("/members", h.Organization.ListMembers)
+			organization.PUT("/members/:user_id", h.Organization.UpdateMember)
+			organization.DELETE("/members/:user_id", h.Organization.RemoveMember)
+			organization.GET("/invitations", h.Organization.ListInvitations)
+			organization.POST("/invitations", h.Organization.InviteMember)
+			organization.DELETE("/invitations/:id", h.Organization.RevokeInvitation)
+			organization.GET("/invitations/received", h.Organization.ListReceivedInvitations)
+			organization.POST("/invitations/:id/accept", h.Organization.AcceptInvitation)
+			organization.POST("/invitations/:id/decline", h.Organization.DeclineInvitation)
+			organization.POST("/wallet/transfer", h.Organization.TransferToWallet)
+			organization.GET("/subscriptions", h.Organization.ListSubscriptions)
+			organization.GET("/usage", h.Organization.Usage)
+		}
+
 		// 卡密兑换
 		redeem := authenticated.Group("/redeem")
 		{
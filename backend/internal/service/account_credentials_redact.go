@@ -12,6 +12,7 @@ var SensitiveCredentialKeys = []string{
 	// 云服务凭据
 	"aws_secret_access_key", "aws_session_token",
 	"service_account_json", "service_account", "private_key",
+	"azure_client_secret",
 }
 
 var sensitiveCredentialKeySet = func() map[string]struct{} {
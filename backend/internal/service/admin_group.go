@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
@@ -205,6 +206,15 @@ func (s *adminServiceImpl) compositeRouteBelongsToGroup(ctx context.Context, gro
 }
 
 func compositeRouteFromInput(groupID int64, input CompositeRouteInput) (*CompositeModelRoute, error) {
+	split, err := NormalizeCompositeRouteSplit(input.Split)
+	if err != nil {
+		return nil, infraerrors.Newf(http.StatusBadRequest, "INVALID_COMPOSITE_ROUTE_SPLIT", "%v", err)
+	}
+	if split != nil {
+		// 拆分路由的单一目标字段镜像首个（对照）分支，便于列表展示与旧逻辑读取。
+		input.TargetPlatform = split.Arms[0].TargetPlatform
+		input.UpstreamModel = split.Arms[0].UpstreamModel
+	}
 	input = normalizeCompositeRouteInput(input)
 	if input.PublicModel == "" {
 		return nil, fmt.Errorf("public_model is required")
@@ -225,6 +235,7 @@ func compositeRouteFromInput(groupID int64, input CompositeRouteInput) (*Composi
 		Priority:       input.Priority,
 		Enabled:        input.Enabled,
 		Notes:          input.Notes,
+		Split:          split,
 	}, nil
 }
 
@@ -309,6 +320,21 @@ func (s *adminServiceImpl) CreateGroup(ctx context.Context, input *CreateGroupIn
 	if err != nil {
 		return nil, infraerrors.Newf(http.StatusBadRequest, "INVALID_REASONING_EFFORT_MAPPING", "%v", err)
 	}
+	volumeTiers, err := NormalizeVolumeTiers(input.VolumeTiers)
+	if err != nil {
+		return nil, infraerrors.Newf(http.StatusBadRequest, "INVALID_VOLUME_TIERS", "%v", err)
+	}
+	hedgePolicy, err := NormalizeGroupHedgePolicy(input.HedgePolicy)
+	if err != nil {
+		return nil, infraerrors.Newf(http.StatusBadRequest, "INVALID_HEDGE_POLICY", "%v", err)
+	}
+	mirrorPolicy, err := NormalizeGroupMirrorPolicy(input.MirrorPolicy)
+	if err != nil {
+		return nil, infraerrors.Newf(http.StatusBadRequest, "INVALID_MIRROR_POLICY", "%v", err)
+	}
+	if err := s.validateGroupMirrorPolicy(ctx, 0, platform, mirrorPolicy); err != nil {
+		return nil, infraerrors.Newf(http.StatusBadRequest, "INVALID_MIRROR_POLICY", "%v", err)
+	}
 
 	subscriptionType := input.SubscriptionType
 	if subscriptionType == "" {
@@ -390,6 +416,18 @@ func (s *adminServiceImpl) CreateGroup(ctx context.Context, input *CreateGroupIn
 		return nil, err
 	}
 
+	responseCacheTTLSeconds := 0
+	if input.ResponseCacheTTLSeconds != nil {
+		responseCacheTTLSeconds = *input.ResponseCacheTTLSeconds
+	}
+	responseCacheHitMultiplier := defaultResponseCacheHitMultiplier
+	if input.ResponseCacheHitMultiplier != nil {
+		responseCacheHitMultiplier = *input.ResponseCacheHitMultiplier
+	}
+	if err := ValidateResponseCacheConfig(responseCacheTTLSeconds, responseCacheHitMultiplier); err != nil {
+		return nil, err
+	}
+
 	// 校验降级分组
 	if input.FallbackGroupID != nil {
 		if err := s.validateFallbackGroup(ctx, 0, *input.FallbackGroupID); err != nil {
@@ -474,6 +512,9 @@ func (s *adminServiceImpl) CreateGroup(ctx context.Context, input *CreateGroupIn
 		ProfitControlEnabled:            profitControlEnabled,
 		ProfitMinMargin:                 profitMinMargin,
 		ProfitSafetyBuffer:              profitSafetyBuffer,
+		ResponseCacheEnabled:            input.ResponseCacheEnabled,
+		ResponseCacheTTLSeconds:         responseCacheTTLSeconds,
+		ResponseCacheHitMultiplier:      responseCacheHitMultiplier,
 		ImagePrice1K:                    imagePrice1K,
 		ImagePrice2K:                    imagePrice2K,
 		ImagePrice4K:                    imagePrice4K,
@@ -502,10 +543,16 @@ func (s *adminServiceImpl) CreateGroup(ctx context.Context, input *CreateGroupIn
 		RPMLimit:                        input.RPMLimit,
 		MaxReasoningEffort:              maxReasoningEffort,
 		ReasoningEffortMappings:         reasoningEffortMappings,
+		VolumeTiers:                     volumeTiers,
+		AllowGeminiNative:               input.AllowGeminiNative,
+		HedgePolicy:                     hedgePolicy,
+		MirrorPolicy:                    mirrorPolicy,
 	}
 	sanitizeGroupMessagesDispatchFields(group)
+	sanitizeGroupGeminiNativeField(group)
 	if group.Platform != PlatformOpenAI {
 		group.AllowLive = false
+		group.HedgePolicy = GroupHedgePolicy{}
 	}
 	sanitizeGroupReasoningEffortPolicy(group)
 	if err := s.groupRepo.Create(ctx, group); err != nil {
@@ -746,6 +793,18 @@ func (s *adminServiceImpl) UpdateGroup(ctx context.Context, id int64, input *Upd
 	if err := ValidateProfitControlConfig(group.Platform, group.ProfitControlEnabled, group.ProfitMinMargin, group.ProfitSafetyBuffer); err != nil {
 		return nil, err
 	}
+	if input.ResponseCacheEnabled != nil {
+		group.ResponseCacheEnabled = *input.ResponseCacheEnabled
+	}
+	if input.ResponseCacheTTLSeconds != nil {
+		group.ResponseCacheTTLSeconds = *input.ResponseCacheTTLSeconds
+	}
+	if input.ResponseCacheHitMultiplier != nil {
+		group.ResponseCacheHitMultiplier = *input.ResponseCacheHitMultiplier
+	}
+	if err := ValidateResponseCacheConfig(group.ResponseCacheTTLSeconds, group.ResponseCacheHitMultiplier); err != nil {
+		return nil, err
+	}
 	if input.ImagePrice1K != nil {
 		group.ImagePrice1K = normalizePrice(input.ImagePrice1K)
 	}
@@ -870,9 +929,38 @@ func (s *adminServiceImpl) UpdateGroup(ctx context.Context, id int64, input *Upd
 		}
 		group.ReasoningEffortMappings = reasoningEffortMappings
 	}
+	if input.VolumeTiers != nil {
+		volumeTiers, err := NormalizeVolumeTiers(*input.VolumeTiers)
+		if err != nil {
+			return nil, infraerrors.Newf(http.StatusBadRequest, "INVALID_VOLUME_TIERS", "%v", err)
+		}
+		group.VolumeTiers = volumeTiers
+	}
+	if input.AllowGeminiNative != nil {
+		group.AllowGeminiNative = *input.AllowGeminiNative
+	}
+	if input.HedgePolicy != nil {
+		hedgePolicy, err := NormalizeGroupHedgePolicy(*input.HedgePolicy)
+		if err != nil {
+			return nil, infraerrors.Newf(http.StatusBadRequest, "INVALID_HEDGE_POLICY", "%v", err)
+		}
+		group.HedgePolicy = hedgePolicy
+	}
+	if input.MirrorPolicy != nil {
+		mirrorPolicy, err := NormalizeGroupMirrorPolicy(*input.MirrorPolicy)
+		if err != nil {
+			return nil, infraerrors.Newf(http.StatusBadRequest, "INVALID_MIRROR_POLICY", "%v", err)
+		}
+		if err := s.validateGroupMirrorPolicy(ctx, group.ID, group.Platform, mirrorPolicy); err != nil {
+			return nil, infraerrors.Newf(http.StatusBadRequest, "INVALID_MIRROR_POLICY", "%v", err)
+		}
+		group.MirrorPolicy = mirrorPolicy
+	}
 	sanitizeGroupMessagesDispatchFields(group)
+	sanitizeGroupGeminiNativeField(group)
 	if group.Platform != PlatformOpenAI {
 		group.AllowLive = false
+		group.HedgePolicy = GroupHedgePolicy{}
 	}
 	sanitizeGroupReasoningEffortPolicy(group)
 
@@ -1193,6 +1281,34 @@ func (s *adminServiceImpl) AdminResetAPIKeyRateLimitUsage(ctx context.Context, k
 	return apiKey, nil
 }
 
+// AdminUpdateAPIKeyModelRestrictions 管理员修改 API Key 的模型白/黑名单。
+func (s *adminServiceImpl) AdminUpdateAPIKeyModelRestrictions(ctx context.Context, keyID int64, allowlist, denylist *[]string) (*APIKey, error) {
+	apiKey, err := s.apiKeyRepo.GetByID(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if allowlist == nil && denylist == nil {
+		return apiKey, nil
+	}
+	if allowlist != nil {
+		if apiKey.ModelAllowlist, err = normalizeAndValidateModelPatterns(*allowlist); err != nil {
+			return nil, err
+		}
+	}
+	if denylist != nil {
+		if apiKey.ModelDenylist, err = normalizeAndValidateModelPatterns(*denylist); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.apiKeyRepo.Update(ctx, apiKey, APIKeyUpdateFields{ModelRules: true}); err != nil {
+		return nil, fmt.Errorf("update api key model restrictions: %w", err)
+	}
+	if s.authCacheInvalidator != nil {
+		s.authCacheInvalidator.InvalidateAuthCacheByKey(ctx, apiKey.Key)
+	}
+	return apiKey, nil
+}
+
 // ReplaceUserGroup 替换用户的专属分组
 func (s *adminServiceImpl) ReplaceUserGroup(ctx context.Context, userID, oldGroupID, newGroupID int64) (*ReplaceUserGroupResult, error) {
 	if oldGroupID == newGroupID {
@@ -1257,3 +1373,41 @@ func (s *adminServiceImpl) ReplaceUserGroup(ctx context.Context, userID, oldGrou
 
 	return &ReplaceUserGroupResult{MigratedKeys: migrated}, nil
 }
+
+// validateGroupMirrorPolicy 校验已启用的镜像目标：候选分组存在且不是 composite 分组，
+// 镜像到自身时必须指定账号，指定的账号必须属于候选分组。groupID 为 0 表示正在创建的分组。
+func (s *adminServiceImpl) validateGroupMirrorPolicy(ctx context.Context, groupID int64, platform string, policy GroupMirrorPolicy) error {
+	if !policy.Enabled {
+		return nil
+	}
+	targetGroupID := groupID
+	if policy.TargetGroupID != nil {
+		targetGroupID = *policy.TargetGroupID
+	}
+	switch {
+	case targetGroupID == 0 && platform == PlatformComposite:
+		return fmt.Errorf("composite groups must mirror to a target_group_id")
+	case targetGroupID == groupID && policy.TargetAccountID == nil:
+		return fmt.Errorf("mirroring to the same group requires target_account_id")
+	}
+	if targetGroupID > 0 {
+		target, err := s.groupRepo.GetByIDLite(ctx, targetGroupID)
+		if err != nil {
+			return fmt.Errorf("target group %d not found", targetGroupID)
+		}
+		if target.Platform == PlatformComposite {
+			return fmt.Errorf("target group %d is a composite group", targetGroupID)
+		}
+	}
+	if policy.TargetAccountID == nil {
+		return nil
+	}
+	account, err := s.accountRepo.GetByID(ctx, *policy.TargetAccountID)
+	if err != nil {
+		return fmt.Errorf("target account %d not found", *policy.TargetAccountID)
+	}
+	if targetGroupID > 0 && !slices.Contains(account.GroupIDs, targetGroupID) {
+		return fmt.Errorf("target account %d is not bound to group %d", account.ID, targetGroupID)
+	}
+	return nil
+}
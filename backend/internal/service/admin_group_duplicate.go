@@ -106,6 +106,13 @@ func cloneGroupForDuplicate(source *Group, operationID string) *Group {
 		ProfitControlEnabled:            source.ProfitControlEnabled,
 		ProfitMinMargin:                 source.ProfitMinMargin,
 		ProfitSafetyBuffer:              source.ProfitSafetyBuffer,
+		ResponseCacheEnabled:            source.ResponseCacheEnabled,
+		ResponseCacheTTLSeconds:         source.ResponseCacheTTLSeconds,
+		ResponseCacheHitMultiplier:      source.ResponseCacheHitMultiplier,
+		VolumeTiers:                     append([]VolumeTier(nil), source.VolumeTiers...),
+		AllowGeminiNative:               source.AllowGeminiNative,
+		HedgePolicy:                     source.HedgePolicy,
+		MirrorPolicy:                    source.MirrorPolicy,
 		IsExclusive:                     source.IsExclusive,
 		Status:                          duplicateGroupInactiveStatus,
 		DuplicateOperationID:            operationID,
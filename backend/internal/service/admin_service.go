@@ -64,6 +64,8 @@ type AdminService interface {
 	// API Key management (admin)
 	AdminUpdateAPIKeyGroupID(ctx context.Context, keyID int64, groupID *int64) (*AdminUpdateAPIKeyGroupIDResult, error)
 	AdminResetAPIKeyRateLimitUsage(ctx context.Context, keyID int64) (*APIKey, error)
+	// AdminUpdateAPIKeyModelRestrictions 修改 Key 的模型白/黑名单（nil 不修改，空数组清空）。
+	AdminUpdateAPIKeyModelRestrictions(ctx context.Context, keyID int64, allowlist, denylist *[]string) (*APIKey, error)
 
 	// ReplaceUserGroup 替换用户的专属分组：授予新分组权限、迁移 Key、移除旧分组权限
 	ReplaceUserGroup(ctx context.Context, userID, oldGroupID, newGroupID int64) (*ReplaceUserGroupResult, error)
@@ -161,6 +163,7 @@ type UpdateUserInput struct {
 	Balance       *float64 // 使用指针区分"未提供"和"设置为0"
 	Concurrency   *int     // 使用指针区分"未提供"和"设置为0"
 	RPMLimit      *int     // 使用指针区分"未提供"和"设置为0"
+	CreditLimit   *float64 // 后付费授信额度，nil 表示不修改，0 表示关闭
 	Status        string
 	AllowedGroups *[]int64 // 使用指针区分"未提供"和"设置为空数组"
 	// GroupRates 用户专属分组倍率配置
@@ -276,6 +279,18 @@ type CreateGroupInput struct {
 	ProfitControlEnabled bool
 	ProfitMinMargin      *float64
 	ProfitSafetyBuffer   *float64
+	// 精确匹配响应缓存（TTL 0 表示使用全局默认；命中倍率 nil 按默认 0.1）
+	ResponseCacheEnabled       bool
+	ResponseCacheTTLSeconds    *int
+	ResponseCacheHitMultiplier *float64
+	// VolumeTiers 月度消费阶梯，空表示不启用。
+	VolumeTiers []VolumeTier
+	// AllowGeminiNative 仅 anthropic/openai 分组生效。
+	AllowGeminiNative bool
+	// HedgePolicy 非流式对冲请求策略，仅 openai 分组生效。
+	HedgePolicy GroupHedgePolicy
+	// MirrorPolicy 流量镜像策略，用于评估候选上游。
+	MirrorPolicy GroupMirrorPolicy
 	// 从指定分组复制账号（创建分组后在同一事务内绑定）
 	CopyAccountsFromGroupIDs []int64
 }
@@ -349,6 +364,18 @@ type UpdateGroupInput struct {
 	ProfitControlEnabled *bool
 	ProfitMinMargin      *float64
 	ProfitSafetyBuffer   *float64
+	// 精确匹配响应缓存（nil 表示不修改）
+	ResponseCacheEnabled       *bool
+	ResponseCacheTTLSeconds    *int
+	ResponseCacheHitMultiplier *float64
+	// VolumeTiers nil 表示不修改，空数组表示关闭阶梯，非空数组表示替换。
+	VolumeTiers *[]VolumeTier
+	// AllowGeminiNative nil 表示不修改。
+	AllowGeminiNative *bool
+	// HedgePolicy nil 表示不修改。
+	HedgePolicy *GroupHedgePolicy
+	// MirrorPolicy nil 表示不修改。
+	MirrorPolicy *GroupMirrorPolicy
 	// 从指定分组复制账号（同步操作：先清空当前分组的账号绑定，再绑定源分组的账号）
 	CopyAccountsFromGroupIDs []int64
 }
@@ -12,6 +12,9 @@ const (
 	StatusAPIKeyDisabled       = "disabled"
 	StatusAPIKeyQuotaExhausted = "quota_exhausted"
 	StatusAPIKeyExpired        = "expired"
+	// StatusAPIKeyCreditSuspended 后付费账单逾期时由 CreditStatementService 自动挂起，
+	// 账单结清后恢复为 active；用户不能自行修改该状态。
+	StatusAPIKeyCreditSuspended = "credit_suspended"
 )
 
 // Rate limit window durations
@@ -39,6 +42,10 @@ type APIKey struct {
 	// 预编译的 IP 规则，用于认证热路径避免重复 ParseIP/ParseCIDR。
 	CompiledIPWhitelist *ip.CompiledIPRules `json:"-"`
 	CompiledIPBlacklist *ip.CompiledIPRules `json:"-"`
+	// 模型白/黑名单（glob 或 re:<regex>），黑名单优先。
+	ModelAllowlist      []string
+	ModelDenylist       []string
+	CompiledModelPolicy *APIKeyModelPolicy `json:"-"`
 	LastUsedAt          *time.Time
 	LastUsedIP          *string
 	CreatedAt           time.Time
@@ -62,6 +69,11 @@ type APIKey struct {
 	Window5hStart *time.Time // Start of current 5h window
 	Window1dStart *time.Time // Start of current 1d window
 	Window7dStart *time.Time // Start of current 7d window
+
+	// Request throttling (0 = unlimited), enforced on top of user/group limits
+	RPMLimit       int // Requests per minute for this key
+	MaxConcurrency int // Max in-flight requests for this key
+	CurrentRPM     int // Requests counted in the current minute (list display only)
 }
 
 func (k *APIKey) IsActive() bool {
@@ -4,16 +4,19 @@ import "time"
 
 // APIKeyAuthSnapshot API Key 认证缓存快照（仅包含认证所需字段）
 type APIKeyAuthSnapshot struct {
-	Version     int                      `json:"version"`
-	APIKeyID    int64                    `json:"api_key_id"`
-	UserID      int64                    `json:"user_id"`
-	GroupID     *int64                   `json:"group_id,omitempty"`
-	Name        string                   `json:"name"`
-	Status      string                   `json:"status"`
-	IPWhitelist []string                 `json:"ip_whitelist,omitempty"`
-	IPBlacklist []string                 `json:"ip_blacklist,omitempty"`
-	User        APIKeyAuthUserSnapshot   `json:"user"`
-	Group       *APIKeyAuthGroupSnapshot `json:"group,omitempty"`
+	Version     int      `json:"version"`
+	APIKeyID    int64    `json:"api_key_id"`
+	UserID      int64    `json:"user_id"`
+	GroupID     *int64   `json:"group_id,omitempty"`
+	Name        string   `json:"name"`
+	Status      string   `json:"status"`
+	IPWhitelist []string `json:"ip_whitelist,omitempty"`
+	IPBlacklist []string `json:"ip_blacklist,omitempty"`
+	// 模型白/黑名单随快照缓存，认证中间件据此在调度前拦截。
+	ModelAllowlist []string                 `json:"model_allowlist,omitempty"`
+	ModelDenylist  []string                 `json:"model_denylist,omitempty"`
+	User           APIKeyAuthUserSnapshot   `json:"user"`
+	Group          *APIKeyAuthGroupSnapshot `json:"group,omitempty"`
 
 	// Quota fields for API Key independent quota feature
 	Quota     float64 `json:"quota"`      // Quota limit in USD (0 = unlimited)
@@ -26,6 +29,10 @@ type APIKeyAuthSnapshot struct {
 	RateLimit5h float64 `json:"rate_limit_5h"`
 	RateLimit1d float64 `json:"rate_limit_1d"`
 	RateLimit7d float64 `json:"rate_limit_7d"`
+
+	// Request throttling (0 = unlimited)
+	RPMLimit       int `json:"rpm_limit,omitempty"`
+	MaxConcurrency int `json:"max_concurrency,omitempty"`
 }
 
 // APIKeyAuthUserSnapshot 用户快照
@@ -49,6 +56,9 @@ type APIKeyAuthUserSnapshot struct {
 	// RPMLimit 用户级每分钟请求数上限（0 = 不限制）；用于 billing_cache_service.checkRPM 兜底判断。
 	RPMLimit int `json:"rpm_limit"`
 
+	// CreditLimit 后付费授信额度；余额准入判断使用 balance + credit_limit。
+	CreditLimit float64 `json:"credit_limit,omitempty"`
+
 	// UserGroupRPMOverride 该 API Key 对应的 (user, group) 专属 RPM 覆盖值。
 	// nil = 无 override（回退到 group/user 级）；0 = 不限流；>0 = 专属上限。
 	UserGroupRPMOverride *int `json:"user_group_rpm_override,omitempty"`
@@ -130,6 +140,23 @@ type APIKeyAuthGroupSnapshot struct {
 	ProfitControlEnabled bool    `json:"profit_control_enabled"`
 	ProfitMinMargin      float64 `json:"profit_min_margin"`
 	ProfitSafetyBuffer   float64 `json:"profit_safety_buffer"`
+
+	// 精确匹配响应缓存：中间件在认证后直接读取，漏掉则缓存静默失效。
+	ResponseCacheEnabled       bool    `json:"response_cache_enabled"`
+	ResponseCacheTTLSeconds    int     `json:"response_cache_ttl_seconds"`
+	ResponseCacheHitMultiplier float64 `json:"response_cache_hit_multiplier"`
+
+	// 月度消费阶梯：计费路径直接读取快照分组，漏掉则阶梯静默失效。
+	VolumeTiers []VolumeTier `json:"volume_tiers,omitempty"`
+
+	// Gemini 原生协议接入：/v1beta 路由按此放行 anthropic/openai 分组。
+	AllowGeminiNative bool `json:"allow_gemini_native"`
+
+	// 对冲请求策略：OpenAI 非流式转发直接读取快照分组。
+	HedgePolicy GroupHedgePolicy `json:"hedge_policy"`
+
+	// 流量镜像策略：网关按此抽样镜像请求。
+	MirrorPolicy GroupMirrorPolicy `json:"mirror_policy"`
 }
 
 // APIKeyAuthCacheEntry 缓存条目，支持负缓存
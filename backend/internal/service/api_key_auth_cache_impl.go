@@ -14,7 +14,7 @@ import (
 	"github.com/dgraph-io/ristretto"
 )
 
-const apiKeyAuthSnapshotVersion = 19 // v19: group search/audio/video_model_prices billing fields (force refresh of pre-fix snapshots)
+const apiKeyAuthSnapshotVersion = 26 // v26: group mirror_policy
 
 type apiKeyAuthCacheConfig struct {
 	l1Size        int
@@ -336,20 +336,24 @@ func (s *APIKeyService) snapshotFromAPIKey(ctx context.Context, apiKey *APIKey)
 		return nil
 	}
 	snapshot := &APIKeyAuthSnapshot{
-		Version:     apiKeyAuthSnapshotVersion,
-		APIKeyID:    apiKey.ID,
-		UserID:      apiKey.UserID,
-		GroupID:     apiKey.GroupID,
-		Name:        apiKey.Name,
-		Status:      apiKey.Status,
-		IPWhitelist: apiKey.IPWhitelist,
-		IPBlacklist: apiKey.IPBlacklist,
-		Quota:       apiKey.Quota,
-		QuotaUsed:   apiKey.QuotaUsed,
-		ExpiresAt:   apiKey.ExpiresAt,
-		RateLimit5h: apiKey.RateLimit5h,
-		RateLimit1d: apiKey.RateLimit1d,
-		RateLimit7d: apiKey.RateLimit7d,
+		Version:        apiKeyAuthSnapshotVersion,
+		APIKeyID:       apiKey.ID,
+		UserID:         apiKey.UserID,
+		GroupID:        apiKey.GroupID,
+		Name:           apiKey.Name,
+		Status:         apiKey.Status,
+		IPWhitelist:    apiKey.IPWhitelist,
+		IPBlacklist:    apiKey.IPBlacklist,
+		ModelAllowlist: apiKey.ModelAllowlist,
+		ModelDenylist:  apiKey.ModelDenylist,
+		Quota:          apiKey.Quota,
+		QuotaUsed:      apiKey.QuotaUsed,
+		ExpiresAt:      apiKey.ExpiresAt,
+		RateLimit5h:    apiKey.RateLimit5h,
+		RateLimit1d:    apiKey.RateLimit1d,
+		RateLimit7d:    apiKey.RateLimit7d,
+		RPMLimit:       apiKey.RPMLimit,
+		MaxConcurrency: apiKey.MaxConcurrency,
 		User: APIKeyAuthUserSnapshot{
 			ID:                         apiKey.User.ID,
 			Status:                     apiKey.User.Status,
@@ -365,6 +369,7 @@ func (s *APIKeyService) snapshotFromAPIKey(ctx context.Context, apiKey *APIKey)
 			BalanceNotifyExtraEmails:   apiKey.User.BalanceNotifyExtraEmails,
 			TotalRecharged:             apiKey.User.TotalRecharged,
 			RPMLimit:                   apiKey.User.RPMLimit,
+			CreditLimit:                apiKey.User.CreditLimit,
 		},
 	}
 
@@ -428,6 +433,13 @@ func (s *APIKeyService) snapshotFromAPIKey(ctx context.Context, apiKey *APIKey)
 			ProfitControlEnabled:            apiKey.Group.ProfitControlEnabled,
 			ProfitMinMargin:                 apiKey.Group.ProfitMinMargin,
 			ProfitSafetyBuffer:              apiKey.Group.ProfitSafetyBuffer,
+			ResponseCacheEnabled:            apiKey.Group.ResponseCacheEnabled,
+			ResponseCacheTTLSeconds:         apiKey.Group.ResponseCacheTTLSeconds,
+			ResponseCacheHitMultiplier:      apiKey.Group.ResponseCacheHitMultiplier,
+			VolumeTiers:                     apiKey.Group.VolumeTiers,
+			AllowGeminiNative:               apiKey.Group.AllowGeminiNative,
+			HedgePolicy:                     apiKey.Group.HedgePolicy,
+			MirrorPolicy:                    apiKey.Group.MirrorPolicy,
 		}
 	}
 	return snapshot
@@ -438,20 +450,24 @@ func (s *APIKeyService) snapshotToAPIKey(key string, snapshot *APIKeyAuthSnapsho
 		return nil
 	}
 	apiKey := &APIKey{
-		ID:          snapshot.APIKeyID,
-		UserID:      snapshot.UserID,
-		GroupID:     snapshot.GroupID,
-		Key:         key,
-		Name:        snapshot.Name,
-		Status:      snapshot.Status,
-		IPWhitelist: snapshot.IPWhitelist,
-		IPBlacklist: snapshot.IPBlacklist,
-		Quota:       snapshot.Quota,
-		QuotaUsed:   snapshot.QuotaUsed,
-		ExpiresAt:   snapshot.ExpiresAt,
-		RateLimit5h: snapshot.RateLimit5h,
-		RateLimit1d: snapshot.RateLimit1d,
-		RateLimit7d: snapshot.RateLimit7d,
+		ID:             snapshot.APIKeyID,
+		UserID:         snapshot.UserID,
+		GroupID:        snapshot.GroupID,
+		Key:            key,
+		Name:           snapshot.Name,
+		Status:         snapshot.Status,
+		IPWhitelist:    snapshot.IPWhitelist,
+		IPBlacklist:    snapshot.IPBlacklist,
+		ModelAllowlist: snapshot.ModelAllowlist,
+		ModelDenylist:  snapshot.ModelDenylist,
+		Quota:          snapshot.Quota,
+		QuotaUsed:      snapshot.QuotaUsed,
+		ExpiresAt:      snapshot.ExpiresAt,
+		RateLimit5h:    snapshot.RateLimit5h,
+		RateLimit1d:    snapshot.RateLimit1d,
+		RateLimit7d:    snapshot.RateLimit7d,
+		RPMLimit:       snapshot.RPMLimit,
+		MaxConcurrency: snapshot.MaxConcurrency,
 		User: &User{
 			ID:                         snapshot.User.ID,
 			Status:                     snapshot.User.Status,
@@ -467,6 +483,7 @@ func (s *APIKeyService) snapshotToAPIKey(key string, snapshot *APIKeyAuthSnapsho
 			BalanceNotifyExtraEmails:   snapshot.User.BalanceNotifyExtraEmails,
 			TotalRecharged:             snapshot.User.TotalRecharged,
 			RPMLimit:                   snapshot.User.RPMLimit,
+			CreditLimit:                snapshot.User.CreditLimit,
 			UserGroupRPMOverride:       snapshot.User.UserGroupRPMOverride,
 		},
 	}
@@ -523,6 +540,13 @@ func (s *APIKeyService) snapshotToAPIKey(key string, snapshot *APIKeyAuthSnapsho
 			ProfitControlEnabled:            snapshot.Group.ProfitControlEnabled,
 			ProfitMinMargin:                 snapshot.Group.ProfitMinMargin,
 			ProfitSafetyBuffer:              snapshot.Group.ProfitSafetyBuffer,
+			ResponseCacheEnabled:            snapshot.Group.ResponseCacheEnabled,
+			ResponseCacheTTLSeconds:         snapshot.Group.ResponseCacheTTLSeconds,
+			ResponseCacheHitMultiplier:      snapshot.Group.ResponseCacheHitMultiplier,
+			VolumeTiers:                     snapshot.Group.VolumeTiers,
+			AllowGeminiNative:               snapshot.Group.AllowGeminiNative,
+			HedgePolicy:                     snapshot.Group.HedgePolicy,
+			MirrorPolicy:                    snapshot.Group.MirrorPolicy,
 		}
 	}
 	s.compileAPIKeyIPRules(apiKey)
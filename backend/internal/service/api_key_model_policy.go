@@ -0,0 +1,185 @@
+package service
+
+import (
+	"regexp"
+	"strings"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// ErrInvalidModelPattern 模型白/黑名单中存在无法编译的模式。
+var ErrInvalidModelPattern = infraerrors.BadRequest("INVALID_MODEL_PATTERN", "invalid model pattern")
+
+const (
+	// apiKeyModelRegexPrefix 标记正则模式，其余模式按 glob（* / ?）处理。
+	apiKeyModelRegexPrefix = "re:"
+	// maxAPIKeyModelPatterns 单个名单的模式数量上限，防止认证热路径被超长名单拖慢。
+	maxAPIKeyModelPatterns   = 100
+	maxAPIKeyModelPatternLen = 200
+)
+
+// APIKeyModelPolicy 预编译的 API Key 模型白/黑名单。
+//
+// 匹配规则：
+//   - 黑名单优先：命中任一黑名单模式即拒绝；
+//   - 白名单为空表示不限制；非空时必须命中至少一个白名单模式；
+//   - 匹配不区分大小写，glob 仅支持 * 与 ?，正则以 "re:" 前缀声明且整串匹配。
+type APIKeyModelPolicy struct {
+	allow []*regexp.Regexp
+	deny  []*regexp.Regexp
+	// restrictAllow 记录白名单是否配置过：即使其中模式全部无法编译也按白名单语义拒绝，
+	// 避免脏数据让受限 Key 退化为不受限。
+	restrictAllow bool
+}
+
+// CompileAPIKeyModelPolicy 编译白/黑名单；两者都为空时返回 nil（无限制）。
+// 无法编译的模式会被跳过，写入前应先经 ValidateModelPatterns 校验。
+func CompileAPIKeyModelPolicy(allowlist, denylist []string) *APIKeyModelPolicy {
+	if len(allowlist) == 0 && len(denylist) == 0 {
+		return nil
+	}
+	return &APIKeyModelPolicy{
+		allow:         compileModelPatterns(allowlist),
+		deny:          compileModelPatterns(denylist),
+		restrictAllow: len(allowlist) > 0,
+	}
+}
+
+// Allows 判断模型是否被策略允许；nil 策略放行所有模型。
+func (p *APIKeyModelPolicy) Allows(model string) bool {
+	if p == nil {
+		return true
+	}
+	model = strings.TrimSpace(model)
+	for _, re := range p.deny {
+		if re.MatchString(model) {
+			return false
+		}
+	}
+	if !p.restrictAllow {
+		return true
+	}
+	for _, re := range p.allow {
+		if re.MatchString(model) {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterModelIDs 过滤出策略允许的模型 ID，保持原有顺序。
+func (p *APIKeyModelPolicy) FilterModelIDs(models []string) []string {
+	if p == nil {
+		return models
+	}
+	out := make([]string, 0, len(models))
+	for _, model := range models {
+		if p.Allows(model) {
+			out = append(out, model)
+		}
+	}
+	return out
+}
+
+// HasModelRestrictions 报告该 Key 是否配置了模型白/黑名单。
+func (k *APIKey) HasModelRestrictions() bool {
+	return k != nil && (len(k.ModelAllowlist) > 0 || len(k.ModelDenylist) > 0)
+}
+
+// ModelPolicy 返回预编译的模型策略；未预编译时按需编译（不缓存，仅测试或旁路场景）。
+func (k *APIKey) ModelPolicy() *APIKeyModelPolicy {
+	if !k.HasModelRestrictions() {
+		return nil
+	}
+	if k.CompiledModelPolicy != nil {
+		return k.CompiledModelPolicy
+	}
+	return CompileAPIKeyModelPolicy(k.ModelAllowlist, k.ModelDenylist)
+}
+
+// IsModelAllowed 判断该 Key 是否允许调用指定模型。
+func (k *APIKey) IsModelAllowed(model string) bool {
+	return k.ModelPolicy().Allows(model)
+}
+
+// NormalizeModelPatterns 去除空白与重复项，保持原有顺序。
+func NormalizeModelPatterns(patterns []string) []string {
+	if patterns == nil {
+		return nil
+	}
+	seen := make(map[string]struct{}, len(patterns))
+	out := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if _, ok := seen[pattern]; ok {
+			continue
+		}
+		seen[pattern] = struct{}{}
+		out = append(out, pattern)
+	}
+	return out
+}
+
+// ValidateModelPatterns 返回无效的模式列表（空切片表示全部有效）。
+func ValidateModelPatterns(patterns []string) []string {
+	var invalid []string
+	if len(patterns) > maxAPIKeyModelPatterns {
+		return []string{"too many patterns"}
+	}
+	for _, pattern := range patterns {
+		if len(pattern) > maxAPIKeyModelPatternLen {
+			invalid = append(invalid, pattern)
+			continue
+		}
+		if _, err := compileModelPattern(pattern); err != nil {
+			invalid = append(invalid, pattern)
+		}
+	}
+	return invalid
+}
+
+func compileModelPatterns(patterns []string) []*regexp.Regexp {
+	if len(patterns) == 0 {
+		return nil
+	}
+	out := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := compileModelPattern(pattern)
+		if err != nil || re == nil {
+			continue
+		}
+		out = append(out, re)
+	}
+	return out
+}
+
+func compileModelPattern(pattern string) (*regexp.Regexp, error) {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return nil, ErrInvalidModelPattern
+	}
+	if expr, ok := strings.CutPrefix(pattern, apiKeyModelRegexPrefix); ok {
+		expr = strings.TrimSpace(expr)
+		if expr == "" {
+			return nil, ErrInvalidModelPattern
+		}
+		return regexp.Compile("(?i)^(?:" + expr + ")$")
+	}
+	var b strings.Builder
+	b.WriteString("(?i)^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
@@ -0,0 +1,121 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyModelPolicy_GlobAllowlist(t *testing.T) {
+	key := &APIKey{ModelAllowlist: []string{"claude-sonnet-*", "gpt-5-mini"}}
+
+	require.True(t, key.IsModelAllowed("claude-sonnet-4-5"))
+	require.True(t, key.IsModelAllowed("Claude-Sonnet-4-5"), "matching is case-insensitive")
+	require.True(t, key.IsModelAllowed("gpt-5-mini"))
+	require.False(t, key.IsModelAllowed("gpt-5-mini-2025"), "exact patterns must not prefix-match")
+	require.False(t, key.IsModelAllowed("claude-opus-4-1"))
+}
+
+func TestAPIKeyModelPolicy_DenylistWinsOverAllowlist(t *testing.T) {
+	key := &APIKey{
+		ModelAllowlist: []string{"claude-*"},
+		ModelDenylist:  []string{"claude-opus-*"},
+	}
+
+	require.True(t, key.IsModelAllowed("claude-haiku-4-5"))
+	require.False(t, key.IsModelAllowed("claude-opus-4-1"))
+}
+
+func TestAPIKeyModelPolicy_DenylistOnlyAllowsOthers(t *testing.T) {
+	key := &APIKey{ModelDenylist: []string{"re:^gpt-5(\\.\\d+)?-pro$"}}
+
+	require.False(t, key.IsModelAllowed("gpt-5-pro"))
+	require.False(t, key.IsModelAllowed("gpt-5.2-pro"))
+	require.True(t, key.IsModelAllowed("gpt-5"))
+	require.True(t, key.IsModelAllowed("claude-sonnet-4-5"))
+}
+
+func TestAPIKeyModelPolicy_RegexIsAnchored(t *testing.T) {
+	key := &APIKey{ModelAllowlist: []string{"re:gpt-5"}}
+
+	require.True(t, key.IsModelAllowed("gpt-5"))
+	require.False(t, key.IsModelAllowed("gpt-5-mini"))
+}
+
+func TestAPIKeyModelPolicy_GlobEscapesRegexMeta(t *testing.T) {
+	key := &APIKey{ModelAllowlist: []string{"gpt-4.1"}}
+
+	require.True(t, key.IsModelAllowed("gpt-4.1"))
+	require.False(t, key.IsModelAllowed("gpt-4x1"))
+}
+
+func TestAPIKeyModelPolicy_NoRestrictions(t *testing.T) {
+	var nilKey *APIKey
+	require.False(t, nilKey.HasModelRestrictions())
+	require.Nil(t, nilKey.ModelPolicy())
+	require.True(t, nilKey.IsModelAllowed("anything"))
+
+	key := &APIKey{}
+	require.False(t, key.HasModelRestrictions())
+	require.True(t, key.IsModelAllowed("anything"))
+}
+
+func TestAPIKeyModelPolicy_InvalidAllowlistFailsClosed(t *testing.T) {
+	policy := CompileAPIKeyModelPolicy([]string{"re:("}, nil)
+
+	require.NotNil(t, policy)
+	require.False(t, policy.Allows("gpt-5"))
+}
+
+func TestAPIKeyModelPolicy_FilterModelIDs(t *testing.T) {
+	policy := CompileAPIKeyModelPolicy([]string{"claude-*"}, []string{"*-opus-*"})
+
+	got := policy.FilterModelIDs([]string{"claude-sonnet-4-5", "claude-opus-4-1", "gpt-5", "claude-haiku-4-5"})
+	require.Equal(t, []string{"claude-sonnet-4-5", "claude-haiku-4-5"}, got)
+
+	var nilPolicy *APIKeyModelPolicy
+	require.Equal(t, []string{"a"}, nilPolicy.FilterModelIDs([]string{"a"}))
+}
+
+func TestValidateModelPatterns(t *testing.T) {
+	require.Empty(t, ValidateModelPatterns([]string{"claude-*", "re:^gpt-5(-mini)?$", "gemini-?.5-pro"}))
+	require.Equal(t, []string{"re:(", "re:"}, ValidateModelPatterns([]string{"claude-*", "re:(", "re:"}))
+
+	tooMany := make([]string, maxAPIKeyModelPatterns+1)
+	for i := range tooMany {
+		tooMany[i] = "m"
+	}
+	require.NotEmpty(t, ValidateModelPatterns(tooMany))
+}
+
+func TestNormalizeModelPatterns(t *testing.T) {
+	require.Nil(t, NormalizeModelPatterns(nil))
+	require.Equal(t, []string{}, NormalizeModelPatterns([]string{" ", ""}))
+	require.Equal(t, []string{"claude-*", "gpt-5"}, NormalizeModelPatterns([]string{" claude-* ", "gpt-5", "claude-*"}))
+}
+
+func TestAPIKeyService_SnapshotRoundTripKeepsModelRestrictions(t *testing.T) {
+	svc := &APIKeyService{}
+	apiKey := &APIKey{
+		ID:             1,
+		UserID:         2,
+		Key:            "k",
+		Status:         StatusActive,
+		ModelAllowlist: []string{"claude-*"},
+		ModelDenylist:  []string{"claude-opus-*"},
+		User:           &User{ID: 2, Status: StatusActive, Role: RoleUser},
+	}
+
+	snapshot := svc.snapshotFromAPIKey(t.Context(), apiKey)
+	require.Equal(t, []string{"claude-*"}, snapshot.ModelAllowlist)
+	require.Equal(t, []string{"claude-opus-*"}, snapshot.ModelDenylist)
+
+	restored, ok, err := svc.applyAuthCacheEntry("k", &APIKeyAuthCacheEntry{Snapshot: snapshot})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.NotNil(t, restored.CompiledModelPolicy)
+	require.True(t, restored.IsModelAllowed("claude-sonnet-4-5"))
+	require.False(t, restored.IsModelAllowed("claude-opus-4-1"))
+}
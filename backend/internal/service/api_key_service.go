@@ -16,6 +16,7 @@ import (
 	"github.com/Wei-Shaw/sub2api/internal/config"
 	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
 	"github.com/dgraph-io/ristretto"
@@ -40,6 +41,8 @@ var (
 	ErrAPIKeyRateLimit5hExceeded = infraerrors.TooManyRequests("API_KEY_RATE_5H_EXCEEDED", "api key 5小时限额已用完")
 	ErrAPIKeyRateLimit1dExceeded = infraerrors.TooManyRequests("API_KEY_RATE_1D_EXCEEDED", "api key 日限额已用完")
 	ErrAPIKeyRateLimit7dExceeded = infraerrors.TooManyRequests("API_KEY_RATE_7D_EXCEEDED", "api key 7天限额已用完")
+
+	ErrInvalidAPIKeyThrottle = infraerrors.BadRequest("INVALID_API_KEY_THROTTLE", "rpm_limit and max_concurrency must not be negative")
 )
 
 const (
@@ -74,6 +77,10 @@ type APIKeyUpdateFields struct {
 	RateLimitUsage bool
 	// IPRules 覆盖 ip_whitelist 与 ip_blacklist。
 	IPRules bool
+	// ModelRules 覆盖 model_allowlist 与 model_denylist。
+	ModelRules bool
+	// Throttle 覆盖 rpm_limit 与 max_concurrency。
+	Throttle bool
 }
 
 // IsEmpty 报告该次 Update 是否不写任何列。
@@ -214,6 +221,10 @@ type CreateAPIKeyRequest struct {
 	IPWhitelist []string `json:"ip_whitelist"` // IP 白名单
 	IPBlacklist []string `json:"ip_blacklist"` // IP 黑名单
 
+	// 模型白/黑名单（glob 或 re:<regex>）
+	ModelAllowlist []string `json:"model_allowlist"`
+	ModelDenylist  []string `json:"model_denylist"`
+
 	// Quota fields
 	Quota         float64 `json:"quota"`           // Quota limit in USD (0 = unlimited)
 	ExpiresInDays *int    `json:"expires_in_days"` // Days until expiry (nil = never expires)
@@ -222,6 +233,10 @@ type CreateAPIKeyRequest struct {
 	RateLimit5h float64 `json:"rate_limit_5h"`
 	RateLimit1d float64 `json:"rate_limit_1d"`
 	RateLimit7d float64 `json:"rate_limit_7d"`
+
+	// Request throttling (0 = unlimited)
+	RPMLimit       int `json:"rpm_limit"`
+	MaxConcurrency int `json:"max_concurrency"`
 }
 
 // UpdateAPIKeyRequest 更新API Key请求
@@ -232,6 +247,10 @@ type UpdateAPIKeyRequest struct {
 	IPWhitelist *[]string `json:"ip_whitelist"` // IP 白名单（nil 不修改，空数组清空）
 	IPBlacklist *[]string `json:"ip_blacklist"` // IP 黑名单（nil 不修改，空数组清空）
 
+	// 模型白/黑名单（nil 不修改，空数组清空）
+	ModelAllowlist *[]string `json:"model_allowlist"`
+	ModelDenylist  *[]string `json:"model_denylist"`
+
 	// Quota fields
 	Quota           *float64   `json:"quota"`       // Quota limit in USD (nil = no change, 0 = unlimited)
 	ExpiresAt       *time.Time `json:"expires_at"`  // Expiration time (nil = no change)
@@ -243,6 +262,10 @@ type UpdateAPIKeyRequest struct {
 	RateLimit1d         *float64 `json:"rate_limit_1d"`
 	RateLimit7d         *float64 `json:"rate_limit_7d"`
 	ResetRateLimitUsage *bool    `json:"reset_rate_limit_usage"` // Reset all usage counters to 0
+
+	// Request throttling (nil = no change, 0 = unlimited)
+	RPMLimit       *int `json:"rpm_limit"`
+	MaxConcurrency *int `json:"max_concurrency"`
 }
 
 // APIKeyService API Key服务
@@ -260,6 +283,9 @@ type APIKeyService struct {
 	cache                     APIKeyCache
 	rateLimitCacheInvalid     RateLimitCacheInvalidator // optional: invalidate Redis rate limit cache
 	concurrencyService        *ConcurrencyService
+	rpmCache                  UserRPMCache             // optional: current per-key RPM for list display
+	orgWallets                OrganizationWalletReader // optional: members draw from organization wallets
+	creditGuard               CreditStatementGuard     // optional: blocks keys while a credit statement is overdue
 	cfg                       *config.Config
 	authCacheL1               *ristretto.Cache
 	authNegativeCacheL1       *ristretto.Cache
@@ -338,12 +364,63 @@ func (s *APIKeyService) SetConcurrencyService(concurrencyService *ConcurrencySer
 	s.concurrencyService = concurrencyService
 }
 
+func (s *APIKeyService) SetUserRPMCache(cache UserRPMCache) {
+	s.rpmCache = cache
+}
+
+func (s *APIKeyService) SetOrganizationWalletReader(reader OrganizationWalletReader) {
+	s.orgWallets = reader
+}
+
+func (s *APIKeyService) SetCreditStatementGuard(guard CreditStatementGuard) {
+	s.creditGuard = guard
+}
+
+// checkCreditStatementOverdue 用户存在逾期授信账单时拒绝新建 Key，避免绕过逾期挂起。
+func (s *APIKeyService) checkCreditStatementOverdue(ctx context.Context, userID int64) error {
+	if s.creditGuard == nil {
+		return nil
+	}
+	overdue, err := s.creditGuard.HasOverdueStatement(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("check overdue credit statement: %w", err)
+	}
+	if overdue {
+		return ErrCreditStatementOverdue
+	}
+	return nil
+}
+
+// OrganizationWalletAvailable 报告用户是否由 active 组织钱包供款且钱包仍可用，
+// 鉴权层据此放行个人余额为 0 的组织成员；具体余额与月度额度由计费预检把关。
+func (s *APIKeyService) OrganizationWalletAvailable(ctx context.Context, userID int64) bool {
+	if s == nil || s.orgWallets == nil {
+		return false
+	}
+	wallet, err := s.orgWallets.GetWalletForUser(ctx, userID)
+	if err != nil {
+		logger.LegacyPrintf("service.api_key", "Warning: organization wallet lookup failed for user %d: %v", userID, err)
+		return false
+	}
+	return wallet != nil && wallet.Balance > 0
+}
+
 func (s *APIKeyService) compileAPIKeyIPRules(apiKey *APIKey) {
 	if apiKey == nil {
 		return
 	}
 	apiKey.CompiledIPWhitelist = ip.CompileIPRules(apiKey.IPWhitelist)
 	apiKey.CompiledIPBlacklist = ip.CompileIPRules(apiKey.IPBlacklist)
+	apiKey.CompiledModelPolicy = CompileAPIKeyModelPolicy(apiKey.ModelAllowlist, apiKey.ModelDenylist)
+}
+
+// normalizeAndValidateModelPatterns 规范化模型名单并校验模式可编译。
+func normalizeAndValidateModelPatterns(patterns []string) ([]string, error) {
+	normalized := NormalizeModelPatterns(patterns)
+	if invalid := ValidateModelPatterns(normalized); len(invalid) > 0 {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidModelPattern, invalid)
+	}
+	return normalized, nil
 }
 
 // GenerateKey 生成随机API Key
@@ -448,6 +525,22 @@ func (s *APIKeyService) Create(ctx context.Context, userID int64, req CreateAPIK
 		}
 	}
 
+	// 验证模型白/黑名单
+	modelAllowlist, err := normalizeAndValidateModelPatterns(req.ModelAllowlist)
+	if err != nil {
+		return nil, err
+	}
+	modelDenylist, err := normalizeAndValidateModelPatterns(req.ModelDenylist)
+	if err != nil {
+		return nil, err
+	}
+	if req.RPMLimit < 0 || req.MaxConcurrency < 0 {
+		return nil, ErrInvalidAPIKeyThrottle
+	}
+	if err := s.checkCreditStatementOverdue(ctx, userID); err != nil {
+		return nil, err
+	}
+
 	// 验证分组权限（如果指定了分组）
 	if req.GroupID != nil {
 		group, err := s.groupRepo.GetByID(ctx, *req.GroupID)
@@ -498,18 +591,22 @@ func (s *APIKeyService) Create(ctx context.Context, userID int64, req CreateAPIK
 
 	// 创建API Key记录
 	apiKey := &APIKey{
-		UserID:      userID,
-		Key:         key,
-		Name:        html.EscapeString(req.Name),
-		GroupID:     req.GroupID,
-		Status:      StatusActive,
-		IPWhitelist: req.IPWhitelist,
-		IPBlacklist: req.IPBlacklist,
-		Quota:       req.Quota,
-		QuotaUsed:   0,
-		RateLimit5h: req.RateLimit5h,
-		RateLimit1d: req.RateLimit1d,
-		RateLimit7d: req.RateLimit7d,
+		UserID:         userID,
+		Key:            key,
+		Name:           html.EscapeString(req.Name),
+		GroupID:        req.GroupID,
+		Status:         StatusActive,
+		IPWhitelist:    req.IPWhitelist,
+		IPBlacklist:    req.IPBlacklist,
+		Quota:          req.Quota,
+		QuotaUsed:      0,
+		ModelAllowlist: modelAllowlist,
+		ModelDenylist:  modelDenylist,
+		RateLimit5h:    req.RateLimit5h,
+		RateLimit1d:    req.RateLimit1d,
+		RateLimit7d:    req.RateLimit7d,
+		RPMLimit:       req.RPMLimit,
+		MaxConcurrency: req.MaxConcurrency,
 	}
 
 	// Set expiration time if specified
@@ -539,6 +636,7 @@ func (s *APIKeyService) List(ctx context.Context, userID int64, params paginatio
 		return nil, nil, fmt.Errorf("list api keys: %w", err)
 	}
 	s.fillCurrentConcurrency(ctx, keys)
+	s.fillCurrentRPM(ctx, keys)
 	return keys, pagination, nil
 }
 
@@ -554,7 +652,9 @@ func (s *APIKeyService) listByCurrentConcurrency(ctx context.Context, userID int
 	}
 	s.fillCurrentConcurrency(ctx, keys)
 	sortAPIKeysByCurrentConcurrency(keys, params.NormalizedSortOrder(pagination.SortOrderDesc))
-	return paginateAPIKeys(keys, params), apiKeyPaginationResult(int64(len(keys)), params), nil
+	page := paginateAPIKeys(keys, params)
+	s.fillCurrentRPM(ctx, page)
+	return page, apiKeyPaginationResult(int64(len(keys)), params), nil
 }
 
 func normalizedAPIKeySortBy(sortBy string) string {
@@ -641,6 +741,30 @@ func (s *APIKeyService) currentConcurrencyForAPIKey(ctx context.Context, apiKeyI
 	return counts[apiKeyID]
 }
 
+// fillCurrentRPM 填充本分钟内的请求计数；仅配置了 rpm_limit 的 Key 才会计数，失败时保持为 0。
+func (s *APIKeyService) fillCurrentRPM(ctx context.Context, keys []APIKey) {
+	if s == nil || s.rpmCache == nil || len(keys) == 0 {
+		return
+	}
+	ids := make([]int64, 0, len(keys))
+	for i := range keys {
+		if keys[i].ID > 0 && keys[i].RPMLimit > 0 {
+			ids = append(ids, keys[i].ID)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+	counts, err := s.rpmCache.GetAPIKeyRPMBatch(ctx, ids)
+	if err != nil {
+		logger.LegacyPrintf("service.api_key", "Warning: get api key rpm batch failed: %v", err)
+		return
+	}
+	for i := range keys {
+		keys[i].CurrentRPM = counts[keys[i].ID]
+	}
+}
+
 func (s *APIKeyService) VerifyOwnership(ctx context.Context, userID int64, apiKeyIDs []int64) ([]int64, error) {
 	if len(apiKeyIDs) == 0 {
 		return []int64{}, nil
@@ -662,6 +786,9 @@ func (s *APIKeyService) GetByID(ctx context.Context, id int64) (*APIKey, error)
 	s.compileAPIKeyIPRules(apiKey)
 	if apiKey != nil {
 		apiKey.CurrentConcurrency = s.currentConcurrencyForAPIKey(ctx, apiKey.ID)
+		keys := []APIKey{*apiKey}
+		s.fillCurrentRPM(ctx, keys)
+		apiKey.CurrentRPM = keys[0].CurrentRPM
 	}
 	return apiKey, nil
 }
@@ -747,6 +874,22 @@ func (s *APIKeyService) Update(ctx context.Context, id int64, userID int64, req
 		}
 	}
 
+	// 验证模型白/黑名单
+	var modelAllowlist, modelDenylist []string
+	if req.ModelAllowlist != nil {
+		if modelAllowlist, err = normalizeAndValidateModelPatterns(*req.ModelAllowlist); err != nil {
+			return nil, err
+		}
+	}
+	if req.ModelDenylist != nil {
+		if modelDenylist, err = normalizeAndValidateModelPatterns(*req.ModelDenylist); err != nil {
+			return nil, err
+		}
+	}
+	if (req.RPMLimit != nil && *req.RPMLimit < 0) || (req.MaxConcurrency != nil && *req.MaxConcurrency < 0) {
+		return nil, ErrInvalidAPIKeyThrottle
+	}
+
 	// fields 只登记本次请求真正要改的列。quota_used 与 usage_5h/1d/7d 由计费热路径
 	// 原子递增，除非用户显式点了"重置"，否则这里不用快照把它们写回去。
 	var fields APIKeyUpdateFields
@@ -781,6 +924,10 @@ func (s *APIKeyService) Update(ctx context.Context, id int64, userID int64, req
 	}
 
 	if req.Status != nil {
+		// 逾期挂起的 Key 只能由账单结清后自动恢复
+		if originalStatus == StatusAPIKeyCreditSuspended && *req.Status != StatusAPIKeyCreditSuspended {
+			return nil, ErrCreditStatementOverdue
+		}
 		apiKey.Status = *req.Status
 		fields.Status = true
 		// 如果状态改变，清除Redis缓存
@@ -832,6 +979,16 @@ func (s *APIKeyService) Update(ctx context.Context, id int64, userID int64, req
 		fields.IPRules = true
 	}
 
+	// 更新模型限制（nil 不修改，空数组清空设置）
+	if req.ModelAllowlist != nil {
+		apiKey.ModelAllowlist = modelAllowlist
+		fields.ModelRules = true
+	}
+	if req.ModelDenylist != nil {
+		apiKey.ModelDenylist = modelDenylist
+		fields.ModelRules = true
+	}
+
 	// Update rate limit configuration
 	if req.RateLimit5h != nil {
 		apiKey.RateLimit5h = *req.RateLimit5h
@@ -845,6 +1002,14 @@ func (s *APIKeyService) Update(ctx context.Context, id int64, userID int64, req
 		apiKey.RateLimit7d = *req.RateLimit7d
 		fields.RateLimits = true
 	}
+	if req.RPMLimit != nil {
+		apiKey.RPMLimit = *req.RPMLimit
+		fields.Throttle = true
+	}
+	if req.MaxConcurrency != nil {
+		apiKey.MaxConcurrency = *req.MaxConcurrency
+		fields.Throttle = true
+	}
 	resetRateLimit := req.ResetRateLimitUsage != nil && *req.ResetRateLimitUsage
 	if resetRateLimit {
 		apiKey.Usage5h = 0
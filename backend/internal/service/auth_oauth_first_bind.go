@@ -78,6 +78,16 @@ ON CONFLICT (user_id, provider_type, grant_reason) DO NOTHING`,
 	}
 
 	if providerDefaults.Balance != 0 {
+		query, args := BalanceLedgerTagStatement(client.Driver().Dialect(), BalanceLedgerTag{
+			Type:          BalanceLedgerTypeSignupBonus,
+			ReferenceType: BalanceLedgerRefAuthProvider,
+			ReferenceID:   strings.TrimSpace(providerType),
+		})
+		if query != "" {
+			if _, err := client.ExecContext(ctx, query, args...); err != nil {
+				return fmt.Errorf("apply balance ledger tag: %w", err)
+			}
+		}
 		if err := client.User.UpdateOneID(userID).AddBalance(providerDefaults.Balance).Exec(ctx); err != nil {
 			return fmt.Errorf("apply first bind balance default: %w", err)
 		}
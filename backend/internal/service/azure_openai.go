@@ -0,0 +1,460 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// Azure OpenAI 是 OpenAI API Key 账号的一种上游形态（credentials.provider = "azure"）。
+// 请求仍按 OpenAI 规则构建，发送前由 prepareAzureOpenAIRequest 改写为
+// /openai/deployments/{deployment}/...?api-version= 形态，并换成 api-key 头或 Entra 令牌。
+const (
+	azureOpenAIProvider          = "azure"
+	azureOpenAIDefaultAPIVersion = "2025-04-01-preview"
+	azureOpenAIAuthModeAPIKey    = "api_key"
+	azureOpenAIAuthModeEntra     = "entra"
+	azureOpenAIDefaultAuthority  = "login.microsoftonline.com"
+	azureOpenAITokenCacheSkew    = 5 * time.Minute
+	azureOpenAILockWaitTime      = 200 * time.Millisecond
+	azureOpenAIDefaultDeployment = "*"
+)
+
+const (
+	credKeyProvider           = "provider"
+	credKeyAzureAPIVersion    = "azure_api_version"
+	credKeyAzureDeployments   = "azure_deployments"
+	credKeyAzureAuthMode      = "azure_auth_mode"
+	credKeyAzureTenantID      = "azure_tenant_id"
+	credKeyAzureClientID      = "azure_client_id"
+	credKeyAzureClientSecret  = "azure_client_secret"
+	credKeyAzureAuthorityHost = "azure_authority_host"
+)
+
+// azureOpenAIClouds 受支持的 Entra 登录主机及对应的 Cognitive Services scope。
+// 令牌端点只允许这几个主机，避免客户端密钥被发往任意地址。
+var azureOpenAIClouds = map[string]string{
+	"login.microsoftonline.com": "https://cognitiveservices.azure.com/.default",
+	"login.microsoftonline.us":  "https://cognitiveservices.azure.us/.default",
+	"login.chinacloudapi.cn":    "https://cognitiveservices.azure.cn/.default",
+}
+
+var (
+	azureOpenAIAPIVersionPattern = regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}(-preview)?$`)
+	azureOpenAIDeploymentPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+	azureOpenAITenantPattern     = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9.-]{0,127}$`)
+)
+
+var ErrInvalidAzureOpenAICredentials = infraerrors.BadRequest("INVALID_AZURE_OPENAI_CREDENTIALS", "invalid Azure OpenAI credentials")
+
+type azureEntraTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+	Error       string `json:"error"`
+	ErrorDesc   string `json:"error_description"`
+}
+
+func (a *Account) IsAzureOpenAI() bool {
+	return a != nil && a.IsOpenAIApiKey() &&
+		strings.EqualFold(strings.TrimSpace(a.GetCredential(credKeyProvider)), azureOpenAIProvider)
+}
+
+func (a *Account) IsAzureOpenAIEntra() bool {
+	return a.IsAzureOpenAI() && a.AzureOpenAIAuthMode() == azureOpenAIAuthModeEntra
+}
+
+func (a *Account) AzureOpenAIAuthMode() string {
+	if strings.EqualFold(strings.TrimSpace(a.GetCredential(credKeyAzureAuthMode)), azureOpenAIAuthModeEntra) {
+		return azureOpenAIAuthModeEntra
+	}
+	return azureOpenAIAuthModeAPIKey
+}
+
+func (a *Account) AzureOpenAIAPIVersion() string {
+	if v := strings.TrimSpace(a.GetCredential(credKeyAzureAPIVersion)); v != "" {
+		return v
+	}
+	return azureOpenAIDefaultAPIVersion
+}
+
+// AzureOpenAIDeployment 返回模型对应的部署名：先精确匹配 azure_deployments，
+// 再取 "*" 默认部署，都没有时按 Azure 惯例把模型名当作部署名。
+func (a *Account) AzureOpenAIDeployment(model string) string {
+	model = strings.TrimSpace(model)
+	if a != nil && a.Credentials != nil {
+		if raw, ok := a.Credentials[credKeyAzureDeployments].(map[string]any); ok {
+			if dep, ok := raw[model].(string); ok && strings.TrimSpace(dep) != "" {
+				return strings.TrimSpace(dep)
+			}
+			if dep, ok := raw[azureOpenAIDefaultDeployment].(string); ok && strings.TrimSpace(dep) != "" {
+				return strings.TrimSpace(dep)
+			}
+		}
+	}
+	return model
+}
+
+func (a *Account) azureOpenAIAuthorityHost() string {
+	if v := strings.ToLower(strings.TrimSpace(a.GetCredential(credKeyAzureAuthorityHost))); v != "" {
+		return v
+	}
+	return azureOpenAIDefaultAuthority
+}
+
+// NormalizeAzureOpenAICredentials 校验并规范化 Azure OpenAI 凭证；未声明 provider 的凭证原样放行。
+func NormalizeAzureOpenAICredentials(platform, accountType string, credentials map[string]any) error {
+	if credentials == nil {
+		return nil
+	}
+	provider, _ := credentials[credKeyProvider].(string)
+	provider = strings.TrimSpace(provider)
+	if provider == "" {
+		return nil
+	}
+	invalid := func(reason string) error {
+		return ErrInvalidAzureOpenAICredentials.WithMetadata(map[string]string{"reason": reason})
+	}
+	if !strings.EqualFold(provider, azureOpenAIProvider) {
+		return invalid("unsupported provider")
+	}
+	if platform != PlatformOpenAI || accountType != AccountTypeAPIKey {
+		return invalid("azure provider requires an openai apikey account")
+	}
+	credentials[credKeyProvider] = azureOpenAIProvider
+
+	baseURL, _ := credentials["base_url"].(string)
+	parsed, err := url.Parse(strings.TrimSpace(baseURL))
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "https" && parsed.Scheme != "http") {
+		return invalid("base_url must be the resource endpoint, e.g. https://<resource>.openai.azure.com")
+	}
+
+	if v, _ := credentials[credKeyAzureAPIVersion].(string); strings.TrimSpace(v) != "" {
+		v = strings.TrimSpace(v)
+		if !azureOpenAIAPIVersionPattern.MatchString(v) {
+			return invalid("azure_api_version must look like 2025-04-01-preview")
+		}
+		credentials[credKeyAzureAPIVersion] = v
+	}
+
+	mode, _ := credentials[credKeyAzureAuthMode].(string)
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	if mode == "" {
+		mode = azureOpenAIAuthModeAPIKey
+	}
+	switch mode {
+	case azureOpenAIAuthModeAPIKey:
+		if v, _ := credentials["api_key"].(string); strings.TrimSpace(v) == "" {
+			return invalid("api_key is required")
+		}
+	case azureOpenAIAuthModeEntra:
+		tenant, _ := credentials[credKeyAzureTenantID].(string)
+		if !azureOpenAITenantPattern.MatchString(strings.TrimSpace(tenant)) {
+			return invalid("azure_tenant_id is required")
+		}
+		if v, _ := credentials[credKeyAzureClientID].(string); strings.TrimSpace(v) == "" {
+			return invalid("azure_client_id is required")
+		}
+		if v, _ := credentials[credKeyAzureClientSecret].(string); strings.TrimSpace(v) == "" {
+			return invalid("azure_client_secret is required")
+		}
+		if v, _ := credentials[credKeyAzureAuthorityHost].(string); strings.TrimSpace(v) != "" {
+			host := strings.ToLower(strings.TrimSpace(v))
+			if _, ok := azureOpenAIClouds[host]; !ok {
+				return invalid("unsupported azure_authority_host")
+			}
+			credentials[credKeyAzureAuthorityHost] = host
+		}
+	default:
+		return invalid("azure_auth_mode must be api_key or entra")
+	}
+	credentials[credKeyAzureAuthMode] = mode
+
+	if raw, ok := credentials[credKeyAzureDeployments]; ok && raw != nil {
+		entries, ok := raw.(map[string]any)
+		if !ok {
+			return invalid("azure_deployments must be an object of model to deployment name")
+		}
+		for model, value := range entries {
+			dep, _ := value.(string)
+			if strings.TrimSpace(model) == "" || !azureOpenAIDeploymentPattern.MatchString(strings.TrimSpace(dep)) {
+				return invalid("invalid deployment for model " + model)
+			}
+			entries[model] = strings.TrimSpace(dep)
+		}
+	}
+	return nil
+}
+
+// azureOpenAIOperation 从按 OpenAI 规则拼出的上游路径中取出端点相对路径，如 chat/completions、responses。
+func azureOpenAIOperation(path string) (string, bool) {
+	idx := strings.LastIndex(path, "/v1/")
+	if idx < 0 {
+		return "", false
+	}
+	op := strings.Trim(path[idx+len("/v1/"):], "/")
+	return op, op != ""
+}
+
+func isAzureOpenAIResponsesOperation(operation string) bool {
+	return operation == "responses" || strings.HasPrefix(operation, "responses/")
+}
+
+// buildAzureOpenAIURL Responses 走资源级 /openai/responses（部署名放在 body.model），
+// 其余端点走 /openai/deployments/{deployment}/...。
+func buildAzureOpenAIURL(target *url.URL, operation, deployment, apiVersion string) *url.URL {
+	u := &url.URL{Scheme: target.Scheme, Host: target.Host}
+	if isAzureOpenAIResponsesOperation(operation) {
+		u.Path = "/openai/" + operation
+	} else {
+		u.Path = "/openai/deployments/" + url.PathEscape(deployment) + "/" + operation
+	}
+	q := url.Values{}
+	q.Set("api-version", apiVersion)
+	u.RawQuery = q.Encode()
+	return u
+}
+
+// azureOpenAIRequestModel 读取请求体中的 model；图片编辑等 multipart 请求从表单字段读取。
+func azureOpenAIRequestModel(contentType string, body []byte) string {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err == nil && mediaType == "multipart/form-data" {
+		reader := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+		for {
+			part, err := reader.NextPart()
+			if err != nil {
+				return ""
+			}
+			if part.FormName() == "model" {
+				value, _ := io.ReadAll(io.LimitReader(part, 256))
+				return strings.TrimSpace(string(value))
+			}
+		}
+	}
+	return strings.TrimSpace(gjson.GetBytes(body, "model").String())
+}
+
+func readAndResetRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	setRequestBodyBytes(req, body)
+	return body, nil
+}
+
+func setRequestBodyBytes(req *http.Request, body []byte) {
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+}
+
+// prepareAzureOpenAIRequest 在发送前把 OpenAI 形态的上游请求改写为 Azure OpenAI 形态，非 Azure 账号不做处理。
+func (s *OpenAIGatewayService) prepareAzureOpenAIRequest(ctx context.Context, account *Account, req *http.Request) error {
+	if req == nil || req.URL == nil || !account.IsAzureOpenAI() {
+		return nil
+	}
+	operation, ok := azureOpenAIOperation(req.URL.Path)
+	if !ok {
+		return fmt.Errorf("azure openai: unsupported upstream path %q", req.URL.Path)
+	}
+	body, err := readAndResetRequestBody(req)
+	if err != nil {
+		return fmt.Errorf("azure openai: read request body: %w", err)
+	}
+	model := azureOpenAIRequestModel(req.Header.Get("Content-Type"), body)
+	deployment := account.AzureOpenAIDeployment(model)
+	if deployment == "" {
+		return errors.New("azure openai: request has no model to resolve a deployment")
+	}
+	if !azureOpenAIDeploymentPattern.MatchString(deployment) {
+		return fmt.Errorf("azure openai: invalid deployment name %q", deployment)
+	}
+	if isAzureOpenAIResponsesOperation(operation) && model != deployment && gjson.ValidBytes(body) {
+		rewritten, err := sjson.SetBytes(body, "model", deployment)
+		if err != nil {
+			return fmt.Errorf("azure openai: rewrite model: %w", err)
+		}
+		setRequestBodyBytes(req, rewritten)
+	}
+
+	req.URL = buildAzureOpenAIURL(req.URL, operation, deployment, account.AzureOpenAIAPIVersion())
+	req.Host = ""
+	req.Header.Del("Authorization")
+	req.Header.Del("api-key")
+	if account.IsAzureOpenAIEntra() {
+		token, err := getAzureOpenAIEntraAccessToken(ctx, s.azureOpenAITokenCache(), account)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	}
+	apiKey := strings.TrimSpace(account.GetOpenAIApiKey())
+	if apiKey == "" {
+		return errors.New("api_key not found in credentials")
+	}
+	req.Header.Set("api-key", apiKey)
+	return nil
+}
+
+func (s *OpenAIGatewayService) azureOpenAITokenCache() GeminiTokenCache {
+	if s == nil || s.openAITokenProvider == nil {
+		return nil
+	}
+	return s.openAITokenProvider.tokenCache
+}
+
+func azureOpenAIEntraCacheKey(tenant, clientID, secret string) string {
+	sum := sha256.Sum256([]byte(tenant + "\x00" + clientID + "\x00" + secret))
+	return "azure_openai:entra:" + hex.EncodeToString(sum[:8])
+}
+
+// getAzureOpenAIEntraAccessToken 以客户端凭据流换取 Azure OpenAI 访问令牌，
+// 与 Vertex 服务账号一样经共享缓存与分布式锁避免重复换取。
+func getAzureOpenAIEntraAccessToken(ctx context.Context, cache GeminiTokenCache, account *Account) (string, error) {
+	tenant := strings.TrimSpace(account.GetCredential(credKeyAzureTenantID))
+	clientID := strings.TrimSpace(account.GetCredential(credKeyAzureClientID))
+	secret := strings.TrimSpace(account.GetCredential(credKeyAzureClientSecret))
+	if !azureOpenAITenantPattern.MatchString(tenant) || clientID == "" || secret == "" {
+		return "", errors.New("azure entra credentials not configured")
+	}
+	authority := account.azureOpenAIAuthorityHost()
+	scope, ok := azureOpenAIClouds[authority]
+	if !ok {
+		return "", fmt.Errorf("unsupported azure authority host %q", authority)
+	}
+	cacheKey := azureOpenAIEntraCacheKey(tenant, clientID, secret)
+
+	if cache != nil {
+		if token, err := cache.GetAccessToken(ctx, cacheKey); err == nil && strings.TrimSpace(token) != "" {
+			return token, nil
+		}
+		locked, lockErr := cache.AcquireRefreshLock(ctx, cacheKey, 30*time.Second)
+		if lockErr == nil && locked {
+			defer func() { _ = cache.ReleaseRefreshLock(ctx, cacheKey) }()
+		} else if lockErr != nil {
+			slog.Warn("azure_openai_entra_token_lock_failed", "account_id", account.ID, "error", lockErr)
+		} else {
+			time.Sleep(azureOpenAILockWaitTime)
+			if token, err := cache.GetAccessToken(ctx, cacheKey); err == nil && strings.TrimSpace(token) != "" {
+				return token, nil
+			}
+		}
+	}
+
+	tokenURL := "https://" + authority + "/" + url.PathEscape(tenant) + "/oauth2/v2.0/token"
+	accessToken, ttl, err := exchangeAzureOpenAIEntraToken(ctx, tokenURL, scope, clientID, secret, vertexServiceAccountProxyURL(account))
+	if err != nil {
+		return "", err
+	}
+	if cache != nil {
+		_ = cache.SetAccessToken(ctx, cacheKey, accessToken, ttl)
+	}
+	return accessToken, nil
+}
+
+func exchangeAzureOpenAIEntraToken(ctx context.Context, tokenURL, scope, clientID, secret, proxyURL string) (string, time.Duration, error) {
+	values := url.Values{}
+	values.Set("grant_type", "client_credentials")
+	values.Set("client_id", clientID)
+	values.Set("client_secret", secret)
+	values.Set("scope", scope)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(values.Encode()))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	client, err := newVertexServiceAccountHTTPClient(proxyURL)
+	if err != nil {
+		return "", 0, fmt.Errorf("configure azure entra token proxy: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("azure entra token request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	var parsed azureEntraTokenResponse
+	_ = json.Unmarshal(body, &parsed)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg := strings.TrimSpace(parsed.ErrorDesc)
+		if msg == "" {
+			msg = strings.TrimSpace(parsed.Error)
+		}
+		if msg == "" {
+			msg = string(bytes.TrimSpace(body))
+		}
+		return "", 0, fmt.Errorf("azure entra token request returned %d: %s", resp.StatusCode, truncateString(msg, 512))
+	}
+	if strings.TrimSpace(parsed.AccessToken) == "" {
+		return "", 0, errors.New("azure entra token response missing access_token")
+	}
+	ttl := time.Duration(parsed.ExpiresIn) * time.Second
+	if ttl <= 0 {
+		ttl = time.Hour
+	}
+	if ttl > azureOpenAITokenCacheSkew {
+		ttl -= azureOpenAITokenCacheSkew
+	}
+	return parsed.AccessToken, ttl, nil
+}
+
+// isAzureOpenAIContentFilterRejection 识别 Azure 内容筛选拦截（400 content_filter /
+// ResponsibleAIPolicyViolation）。它由提示词本身触发，换账号重试没有意义，也不应惩罚账号。
+func isAzureOpenAIContentFilterRejection(statusCode int, responseBody []byte) bool {
+	if statusCode != http.StatusBadRequest || len(responseBody) == 0 {
+		return false
+	}
+	for _, path := range []string{"error.code", "error.innererror.code", "error.inner_error.code"} {
+		switch strings.TrimSpace(gjson.GetBytes(responseBody, path).String()) {
+		case "content_filter", "ResponsibleAIPolicyViolation":
+			return true
+		}
+	}
+	return false
+}
+
+func azureOpenAIContentFilterClientMessage(body []byte) string {
+	message := sanitizeUpstreamErrorMessage(strings.TrimSpace(extractUpstreamErrorMessage(body)))
+	if message == "" {
+		return "Request blocked by Azure OpenAI content filter"
+	}
+	return message
+}
+
+// isOpenAIContentPolicyRejection 汇总 OpenAI 兼容平台中"请求内容被上游安全策略拒绝"的判定。
+func isOpenAIContentPolicyRejection(account *Account, statusCode int, responseBody []byte) bool {
+	if account == nil {
+		return false
+	}
+	if account.Platform == PlatformGrok {
+		return isGrokContentPolicyRejection(statusCode, responseBody)
+	}
+	return account.IsAzureOpenAI() && isAzureOpenAIContentFilterRejection(statusCode, responseBody)
+}
@@ -0,0 +1,229 @@
+//go:build unit
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func newAzureOpenAITestAccount(extra map[string]any) *Account {
+	creds := map[string]any{
+		"provider": "azure",
+		"base_url": "https://contoso.openai.azure.com",
+		"api_key":  "azure-key",
+		"azure_deployments": map[string]any{
+			"gpt-5": "prod-gpt5",
+		},
+	}
+	for k, v := range extra {
+		creds[k] = v
+	}
+	return &Account{ID: 7, Platform: PlatformOpenAI, Type: AccountTypeAPIKey, Credentials: creds}
+}
+
+func TestAzureOpenAIDeploymentMapping(t *testing.T) {
+	account := newAzureOpenAITestAccount(nil)
+	require.True(t, account.IsAzureOpenAI())
+	require.Equal(t, "prod-gpt5", account.AzureOpenAIDeployment("gpt-5"))
+	require.Equal(t, "gpt-4.1", account.AzureOpenAIDeployment("gpt-4.1"), "未映射的模型按 Azure 惯例直接作为部署名")
+
+	account.Credentials["azure_deployments"] = map[string]any{"gpt-5": "prod-gpt5", "*": "fallback"}
+	require.Equal(t, "fallback", account.AzureOpenAIDeployment("gpt-4.1"))
+	require.Equal(t, azureOpenAIDefaultAPIVersion, account.AzureOpenAIAPIVersion())
+
+	plain := &Account{Platform: PlatformOpenAI, Type: AccountTypeAPIKey, Credentials: map[string]any{"api_key": "k"}}
+	require.False(t, plain.IsAzureOpenAI())
+}
+
+func TestPrepareAzureOpenAIRequestChatCompletions(t *testing.T) {
+	account := newAzureOpenAITestAccount(map[string]any{"azure_api_version": "2024-10-21"})
+	body := []byte(`{"model":"gpt-5","messages":[{"role":"user","content":"hi"}]}`)
+	req, err := http.NewRequest(http.MethodPost, "https://contoso.openai.azure.com/v1/chat/completions", bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer azure-key")
+
+	svc := &OpenAIGatewayService{}
+	require.NoError(t, svc.prepareAzureOpenAIRequest(context.Background(), account, req))
+
+	require.Equal(t, "https://contoso.openai.azure.com/openai/deployments/prod-gpt5/chat/completions?api-version=2024-10-21", req.URL.String())
+	require.Empty(t, req.Header.Get("Authorization"))
+	require.Equal(t, "azure-key", req.Header.Get("api-key"))
+	sent, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	require.Equal(t, body, sent, "chat 端点的部署在路径上，请求体保持不变")
+}
+
+func TestPrepareAzureOpenAIRequestResponsesRewritesModel(t *testing.T) {
+	account := newAzureOpenAITestAccount(nil)
+	req, err := http.NewRequest(http.MethodPost, "https://contoso.openai.azure.com/openai/v1/responses", bytes.NewReader([]byte(`{"model":"gpt-5","input":"hi"}`)))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	require.NoError(t, (&OpenAIGatewayService{}).prepareAzureOpenAIRequest(context.Background(), account, req))
+
+	require.Equal(t, "/openai/responses", req.URL.Path)
+	require.Equal(t, azureOpenAIDefaultAPIVersion, req.URL.Query().Get("api-version"))
+	sent, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	require.Equal(t, "prod-gpt5", gjson.GetBytes(sent, "model").String())
+	require.Equal(t, int64(len(sent)), req.ContentLength)
+}
+
+func TestPrepareAzureOpenAIRequestMultipartModel(t *testing.T) {
+	account := newAzureOpenAITestAccount(map[string]any{"azure_deployments": map[string]any{"gpt-image-1": "images"}})
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	require.NoError(t, writer.WriteField("model", "gpt-image-1"))
+	require.NoError(t, writer.WriteField("prompt", "a cat"))
+	require.NoError(t, writer.Close())
+
+	req, err := http.NewRequest(http.MethodPost, "https://contoso.openai.azure.com/v1/images/edits", &buf)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	require.NoError(t, (&OpenAIGatewayService{}).prepareAzureOpenAIRequest(context.Background(), account, req))
+	require.Equal(t, "/openai/deployments/images/images/edits", req.URL.Path)
+}
+
+func TestPrepareAzureOpenAIRequestEntraUsesCachedToken(t *testing.T) {
+	account := newAzureOpenAITestAccount(map[string]any{
+		"api_key":             "",
+		"azure_auth_mode":     "entra",
+		"azure_tenant_id":     "contoso.onmicrosoft.com",
+		"azure_client_id":     "client",
+		"azure_client_secret": "secret",
+	})
+	cache := newOpenAITokenCacheStub()
+	cache.tokens[azureOpenAIEntraCacheKey("contoso.onmicrosoft.com", "client", "secret")] = "entra-token"
+	svc := &OpenAIGatewayService{openAITokenProvider: &OpenAITokenProvider{tokenCache: cache}}
+
+	req, err := http.NewRequest(http.MethodPost, "https://contoso.openai.azure.com/v1/embeddings", bytes.NewReader([]byte(`{"model":"text-embedding-3-small","input":"x"}`)))
+	require.NoError(t, err)
+	require.NoError(t, svc.prepareAzureOpenAIRequest(context.Background(), account, req))
+
+	require.Equal(t, "Bearer entra-token", req.Header.Get("Authorization"))
+	require.Empty(t, req.Header.Get("api-key"))
+
+	token, mode, err := svc.GetAccessToken(context.Background(), account)
+	require.NoError(t, err)
+	require.Equal(t, "entra-token", token)
+	require.Equal(t, "apikey", mode)
+}
+
+func TestExchangeAzureOpenAIEntraToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		require.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+		require.Equal(t, "client", r.PostForm.Get("client_id"))
+		require.Equal(t, "secret", r.PostForm.Get("client_secret"))
+		require.Equal(t, "https://cognitiveservices.azure.com/.default", r.PostForm.Get("scope"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"tok","expires_in":3600,"token_type":"Bearer"}`))
+	}))
+	defer server.Close()
+
+	token, ttl, err := exchangeAzureOpenAIEntraToken(context.Background(), server.URL, "https://cognitiveservices.azure.com/.default", "client", "secret", "")
+	require.NoError(t, err)
+	require.Equal(t, "tok", token)
+	require.Equal(t, 55*time.Minute, ttl)
+}
+
+func TestExchangeAzureOpenAIEntraTokenError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":"invalid_client","error_description":"AADSTS7000215: Invalid client secret provided."}`))
+	}))
+	defer server.Close()
+
+	_, _, err := exchangeAzureOpenAIEntraToken(context.Background(), server.URL, "scope", "client", "bad", "")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "AADSTS7000215")
+}
+
+func TestIsOpenAIContentPolicyRejectionAzure(t *testing.T) {
+	account := newAzureOpenAITestAccount(nil)
+	filtered := []byte(`{"error":{"code":"content_filter","message":"The response was filtered","status":400}}`)
+	inner := []byte(`{"error":{"code":"BadRequest","innererror":{"code":"ResponsibleAIPolicyViolation"}}}`)
+
+	require.True(t, isOpenAIContentPolicyRejection(account, http.StatusBadRequest, filtered))
+	require.True(t, isOpenAIContentPolicyRejection(account, http.StatusBadRequest, inner))
+	require.False(t, isOpenAIContentPolicyRejection(account, http.StatusTooManyRequests, filtered))
+	require.False(t, isOpenAIContentPolicyRejection(account, http.StatusBadRequest, []byte(`{"error":{"code":"invalid_value"}}`)))
+
+	plain := &Account{Platform: PlatformOpenAI, Type: AccountTypeAPIKey, Credentials: map[string]any{"api_key": "k"}}
+	require.False(t, isOpenAIContentPolicyRejection(plain, http.StatusBadRequest, filtered))
+	require.Equal(t, "The response was filtered", azureOpenAIContentFilterClientMessage(filtered))
+}
+
+func TestNormalizeAzureOpenAICredentials(t *testing.T) {
+	valid := func() map[string]any {
+		return map[string]any{
+			"provider":          "Azure",
+			"base_url":          "https://contoso.openai.azure.com",
+			"api_key":           "k",
+			"azure_api_version": " 2024-10-21 ",
+			"azure_deployments": map[string]any{"gpt-5": " prod-gpt5 "},
+		}
+	}
+
+	creds := valid()
+	require.NoError(t, NormalizeAzureOpenAICredentials(PlatformOpenAI, AccountTypeAPIKey, creds))
+	require.Equal(t, "azure", creds["provider"])
+	require.Equal(t, "2024-10-21", creds["azure_api_version"])
+	require.Equal(t, "api_key", creds["azure_auth_mode"])
+	require.Equal(t, "prod-gpt5", creds["azure_deployments"].(map[string]any)["gpt-5"])
+
+	require.NoError(t, NormalizeAzureOpenAICredentials(PlatformGemini, AccountTypeOAuth, map[string]any{"api_key": "k"}), "未声明 provider 时不做校验")
+
+	cases := map[string]func(map[string]any) (string, string){
+		"wrong platform": func(m map[string]any) (string, string) { return PlatformAnthropic, AccountTypeAPIKey },
+		"missing base url": func(m map[string]any) (string, string) {
+			delete(m, "base_url")
+			return PlatformOpenAI, AccountTypeAPIKey
+		},
+		"bad api version": func(m map[string]any) (string, string) {
+			m["azure_api_version"] = "latest"
+			return PlatformOpenAI, AccountTypeAPIKey
+		},
+		"missing api key": func(m map[string]any) (string, string) { m["api_key"] = ""; return PlatformOpenAI, AccountTypeAPIKey },
+		"bad deployment name": func(m map[string]any) (string, string) {
+			m["azure_deployments"] = map[string]any{"gpt-5": "a/b"}
+			return PlatformOpenAI, AccountTypeAPIKey
+		},
+		"entra missing secret": func(m map[string]any) (string, string) {
+			m["azure_auth_mode"] = "entra"
+			m["azure_tenant_id"] = "tenant"
+			m["azure_client_id"] = "client"
+			return PlatformOpenAI, AccountTypeAPIKey
+		},
+		"entra foreign authority": func(m map[string]any) (string, string) {
+			m["azure_auth_mode"] = "entra"
+			m["azure_tenant_id"] = "tenant"
+			m["azure_client_id"] = "client"
+			m["azure_client_secret"] = "secret"
+			m["azure_authority_host"] = "evil.example.com"
+			return PlatformOpenAI, AccountTypeAPIKey
+		},
+	}
+	for name, mutate := range cases {
+		t.Run(name, func(t *testing.T) {
+			creds := valid()
+			platform, accountType := mutate(creds)
+			err := NormalizeAzureOpenAICredentials(platform, accountType, creds)
+			require.ErrorIs(t, err, ErrInvalidAzureOpenAICredentials)
+			require.Equal(t, http.StatusBadRequest, infraerrors.Code(err))
+		})
+	}
+}
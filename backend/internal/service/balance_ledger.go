@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"entgo.io/ent/dialect"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// 余额账本流水类型。账本由 users / organizations 表上的触发器写入，类型来自事务内的账本标签。
+const (
+	BalanceLedgerTypeRecharge             = "recharge"
+	BalanceLedgerTypeUsage                = "usage"
+	BalanceLedgerTypeRefund               = "refund"
+	BalanceLedgerTypeRefundRollback       = "refund_rollback"
+	BalanceLedgerTypeRedeem               = "redeem"
+	BalanceLedgerTypePromo                = "promo"
+	BalanceLedgerTypeAffiliateTransfer    = "affiliate_transfer"
+	BalanceLedgerTypeOrganizationTransfer = "organization_transfer"
+	BalanceLedgerTypeAdminAdjustment      = "admin_adjustment"
+	BalanceLedgerTypeBalanceHold          = "balance_hold"
+	BalanceLedgerTypeHoldCapture          = "hold_capture"
+	BalanceLedgerTypeHoldRelease          = "hold_release"
+	BalanceLedgerTypeSignupBonus          = "signup_bonus"
+	// 以下类型由数据库侧生成：新用户初始余额、账本上线时的期初余额、未打标签的变更。
+	BalanceLedgerTypeInitialBalance = "initial_balance"
+	BalanceLedgerTypeOpeningBalance = "opening_balance"
+	BalanceLedgerTypeUnclassified   = "unclassified"
+)
+
+// 账本流水关联的业务对象类型。
+const (
+	BalanceLedgerRefPaymentOrder = "payment_order"
+	BalanceLedgerRefRedeemCode   = "redeem_code"
+	BalanceLedgerRefPromoCode    = "promo_code"
+	BalanceLedgerRefUsageRequest = "usage_request"
+	BalanceLedgerRefOrganization = "organization"
+	BalanceLedgerRefAuthProvider = "auth_provider"
+)
+
+// 对账运行状态。
+const (
+	BalanceReconciliationStatusRunning   = "running"
+	BalanceReconciliationStatusCompleted = "completed"
+	BalanceReconciliationStatusFailed    = "failed"
+)
+
+var ErrBalanceReconciliationNotFound = infraerrors.New(http.StatusNotFound, "BALANCE_RECONCILIATION_NOT_FOUND", "balance reconciliation not found")
+
+// BalanceLedgerTag 为同一事务内的余额变更标注流水类型与业务引用。
+type BalanceLedgerTag struct {
+	Type          string
+	ReferenceType string
+	ReferenceID   string
+	Note          string
+}
+
+// BalanceLedgerTagStatement 返回把标签写入事务级会话变量的语句；
+// 必须与余额变更在同一事务内执行，事务结束后自动失效。
+// 账本触发器仅存在于 PostgreSQL，其他方言（如测试用 SQLite）返回空语句，调用方应跳过。
+func BalanceLedgerTagStatement(dialectName string, tag BalanceLedgerTag) (string, []any) {
+	if dialectName != dialect.Postgres {
+		return "", nil
+	}
+	return `SELECT set_config('sub2api.ledger_type', $1, true),
+	set_config('sub2api.ledger_ref_type', $2, true),
+	set_config('sub2api.ledger_ref_id', $3, true),
+	set_config('sub2api.ledger_note', $4, true)`,
+		[]any{tag.Type, tag.ReferenceType, tag.ReferenceID, tag.Note}
+}
+
+type balanceLedgerTagKey struct{}
+
+// WithBalanceLedgerTag 把账本标签放入 context，由 repository 在执行余额变更前写入事务。
+func WithBalanceLedgerTag(ctx context.Context, tag BalanceLedgerTag) context.Context {
+	return context.WithValue(ctx, balanceLedgerTagKey{}, tag)
+}
+
+// BalanceLedgerTagFromContext 读取 context 中的账本标签。
+func BalanceLedgerTagFromContext(ctx context.Context) (BalanceLedgerTag, bool) {
+	if ctx == nil {
+		return BalanceLedgerTag{}, false
+	}
+	tag, ok := ctx.Value(balanceLedgerTagKey{}).(BalanceLedgerTag)
+	return tag, ok && tag.Type != ""
+}
+
+// withDefaultBalanceLedgerTag 仅在调用方未指定标签时补上默认标签，
+// 例如支付充值经由兑换码入账时保留 recharge 而非 redeem。
+func withDefaultBalanceLedgerTag(ctx context.Context, tag BalanceLedgerTag) context.Context {
+	if _, ok := BalanceLedgerTagFromContext(ctx); ok {
+		return ctx
+	}
+	return WithBalanceLedgerTag(ctx, tag)
+}
+
+func paymentOrderBalanceLedgerTag(entryType string, orderID int64) BalanceLedgerTag {
+	return BalanceLedgerTag{
+		Type:          entryType,
+		ReferenceType: BalanceLedgerRefPaymentOrder,
+		ReferenceID:   strconv.FormatInt(orderID, 10),
+	}
+}
+
+// BalanceLedgerEntry 是一条不可变的余额流水（复式记账的凭证头）。
+// 组织钱包流水的 UserID 为 0，由 OrganizationID 标识所属组织。
+type BalanceLedgerEntry struct {
+	ID                 int64                  `json:"id"`
+	UserID             int64                  `json:"user_id,omitempty"`
+	OrganizationID     *int64                 `json:"organization_id,omitempty"`
+	EntryType          string                 `json:"entry_type"`
+	BalanceDelta       float64                `json:"balance_delta"`
+	FrozenDelta        float64                `json:"frozen_delta"`
+	BalanceAfter       float64                `json:"balance_after"`
+	FrozenBalanceAfter float64                `json:"frozen_balance_after"`
+	ReferenceType      string                 `json:"reference_type,omitempty"`
+	ReferenceID        string                 `json:"reference_id,omitempty"`
+	Note               string                 `json:"note,omitempty"`
+	CreatedAt          time.Time              `json:"created_at"`
+	Postings           []BalanceLedgerPosting `json:"postings,omitempty"`
+}
+
+// BalanceLedgerPosting 是凭证的一条分录；同一凭证的分录金额之和恒为 0。
+type BalanceLedgerPosting struct {
+	Account        string  `json:"account"`
+	UserID         *int64  `json:"user_id,omitempty"`
+	OrganizationID *int64  `json:"organization_id,omitempty"`
+	Amount         float64 `json:"amount"`
+}
+
+// BalanceLedgerFilter 按用户或组织（OrganizationID > 0 时）查询流水。
+type BalanceLedgerFilter struct {
+	UserID         int64
+	OrganizationID int64
+	EntryType      string
+	StartTime      *time.Time
+	EndTime        *time.Time
+	Page           int
+	PageSize       int
+}
+
+type BalanceLedgerList struct {
+	Entries  []BalanceLedgerEntry
+	Total    int64
+	Page     int
+	PageSize int
+}
+
+// BalanceReconciliation 是一次对账运行的汇总。
+type BalanceReconciliation struct {
+	ID                      int64      `json:"id"`
+	Status                  string     `json:"status"`
+	UsersChecked            int        `json:"users_checked"`
+	MismatchedUsers         int        `json:"mismatched_users"`
+	OrganizationsChecked    int        `json:"organizations_checked"`
+	MismatchedOrganizations int        `json:"mismatched_organizations"`
+	UnbalancedEntries       int        `json:"unbalanced_entries"`
+	ErrorMessage            string     `json:"error_message,omitempty"`
+	StartedAt               time.Time  `json:"started_at"`
+	FinishedAt              *time.Time `json:"finished_at,omitempty"`
+}
+
+// BalanceMismatch 记录对账时余额与账本汇总不一致的用户或组织（OrganizationID 非空）。
+type BalanceMismatch struct {
+	UserID              int64     `json:"user_id,omitempty"`
+	OrganizationID      *int64    `json:"organization_id,omitempty"`
+	Email               string    `json:"email,omitempty"`
+	Balance             float64   `json:"balance"`
+	LedgerBalance       float64   `json:"ledger_balance"`
+	FrozenBalance       float64   `json:"frozen_balance"`
+	LedgerFrozenBalance float64   `json:"ledger_frozen_balance"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+// BalanceLedgerRepository 读取账本并执行对账；账本写入只由数据库触发器完成。
+type BalanceLedgerRepository interface {
+	ListEntries(ctx context.Context, filter BalanceLedgerFilter) (*BalanceLedgerList, error)
+	// Reconcile 创建一次对账记录，比对每个用户与组织钱包的余额与账本汇总并写入差异明细。
+	Reconcile(ctx context.Context) (*BalanceReconciliation, error)
+	ListReconciliations(ctx context.Context, page, pageSize int) ([]BalanceReconciliation, int64, error)
+	GetReconciliation(ctx context.Context, id int64) (*BalanceReconciliation, error)
+	ListMismatches(ctx context.Context, reconciliationID int64) ([]BalanceMismatch, error)
+}
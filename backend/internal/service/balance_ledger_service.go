@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	balanceReconcileLeaderLockKey = "balance_ledger:reconcile:leader"
+	// balanceReconcileLeaderLockTTL 需覆盖单次对账超时，避免运行中锁过期。
+	balanceReconcileLeaderLockTTL = 35 * time.Minute
+	balanceReconcileRunTimeout    = 30 * time.Minute
+	balanceReconcileStopTimeout   = 3 * time.Second
+)
+
+var balanceReconcileCronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+
+// BalanceLedgerService 提供余额账本查询，并按 cron 定时对账：
+// 逐用户与组织比对 balance / frozen_balance 与账本分录汇总，差异写入明细供财务核查。
+type BalanceLedgerService struct {
+	repo BalanceLedgerRepository
+	cfg  *config.Config
+
+	lockCache  LeaderLockCache
+	db         *sql.DB
+	instanceID string
+
+	mu      sync.Mutex
+	cron    *cron.Cron
+	stopped bool
+}
+
+func NewBalanceLedgerService(repo BalanceLedgerRepository, cfg *config.Config) *BalanceLedgerService {
+	return &BalanceLedgerService{
+		repo:       repo,
+		cfg:        cfg,
+		instanceID: uuid.NewString(),
+	}
+}
+
+// SetLeaderLock 注入 leader 锁；两者均为 nil 时不做选主（单实例/测试）。
+func (s *BalanceLedgerService) SetLeaderLock(lockCache LeaderLockCache, db *sql.DB) {
+	if s == nil {
+		return
+	}
+	s.lockCache = lockCache
+	s.db = db
+}
+
+// ListEntries 分页查询用户或组织钱包的余额流水（含复式分录）。
+func (s *BalanceLedgerService) ListEntries(ctx context.Context, filter BalanceLedgerFilter) (*BalanceLedgerList, error) {
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.PageSize <= 0 {
+		filter.PageSize = 50
+	}
+	if filter.PageSize > 200 {
+		filter.PageSize = 200
+	}
+	filter.EntryType = strings.TrimSpace(filter.EntryType)
+	return s.repo.ListEntries(ctx, filter)
+}
+
+// Reconcile 立即执行一次对账。
+func (s *BalanceLedgerService) Reconcile(ctx context.Context) (*BalanceReconciliation, error) {
+	run, err := s.repo.Reconcile(ctx)
+	if err != nil {
+		return run, fmt.Errorf("reconcile balance ledger: %w", err)
+	}
+	if run.MismatchedUsers > 0 || run.MismatchedOrganizations > 0 || run.UnbalancedEntries > 0 {
+		slog.Warn("[BalanceLedger] reconciliation found discrepancies",
+			"reconciliation_id", run.ID,
+			"users_checked", run.UsersChecked,
+			"mismatched_users", run.MismatchedUsers,
+			"organizations_checked", run.OrganizationsChecked,
+			"mismatched_organizations", run.MismatchedOrganizations,
+			"unbalanced_entries", run.UnbalancedEntries)
+	} else {
+		slog.Info("[BalanceLedger] reconciliation passed", "reconciliation_id", run.ID, "users_checked", run.UsersChecked)
+	}
+	return run, nil
+}
+
+func (s *BalanceLedgerService) ListReconciliations(ctx context.Context, page, pageSize int) ([]BalanceReconciliation, int64, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+	return s.repo.ListReconciliations(ctx, page, pageSize)
+}
+
+// GetReconciliation 返回对账汇总及差异明细。
+func (s *BalanceLedgerService) GetReconciliation(ctx context.Context, id int64) (*BalanceReconciliation, []BalanceMismatch, error) {
+	run, err := s.repo.GetReconciliation(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	mismatches, err := s.repo.ListMismatches(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return run, mismatches, nil
+}
+
+// Start 按 balance_ledger.reconcile_schedule 启动定时对账。重复调用幂等。
+func (s *BalanceLedgerService) Start() {
+	if s == nil || s.repo == nil || s.cfg == nil || !s.cfg.BalanceLedger.ReconcileEnabled {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cron != nil || s.stopped {
+		return
+	}
+
+	loc := time.Local
+	if tz := strings.TrimSpace(s.cfg.Timezone); tz != "" {
+		if parsed, err := time.LoadLocation(tz); err == nil && parsed != nil {
+			loc = parsed
+		}
+	}
+	schedule := strings.TrimSpace(s.cfg.BalanceLedger.ReconcileSchedule)
+	c := cron.New(cron.WithParser(balanceReconcileCronParser), cron.WithLocation(loc))
+	if _, err := c.AddFunc(schedule, s.runScheduled); err != nil {
+		slog.Error("[BalanceLedger] invalid reconcile schedule, reconciliation disabled", "schedule", schedule, "error", err)
+		return
+	}
+	c.Start()
+	s.cron = c
+}
+
+// Stop 关闭定时对账。幂等。
+func (s *BalanceLedgerService) Stop() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopped = true
+	if s.cron == nil {
+		return
+	}
+	ctx := s.cron.Stop()
+	select {
+	case <-ctx.Done():
+	case <-time.After(balanceReconcileStopTimeout):
+		slog.Warn("[BalanceLedger] cron stop timed out")
+	}
+	s.cron = nil
+}
+
+func (s *BalanceLedgerService) runScheduled() {
+	lockCtx, lockCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	release, ok := tryAcquireSingletonLeaderLock(lockCtx, s.lockCache, s.db, balanceReconcileLeaderLockKey, s.instanceID, balanceReconcileLeaderLockTTL)
+	lockCancel()
+	if !ok {
+		return
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), balanceReconcileRunTimeout)
+	defer cancel()
+	if _, err := s.Reconcile(ctx); err != nil {
+		slog.Error("[BalanceLedger] scheduled reconciliation failed", "error", err)
+	}
+}
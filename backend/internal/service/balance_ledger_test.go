@@ -0,0 +1,49 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"entgo.io/ent/dialect"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBalanceLedgerTagFromContext(t *testing.T) {
+	_, ok := BalanceLedgerTagFromContext(context.Background())
+	require.False(t, ok)
+
+	ctx := WithBalanceLedgerTag(context.Background(), BalanceLedgerTag{})
+	_, ok = BalanceLedgerTagFromContext(ctx)
+	require.False(t, ok, "tag without type must be ignored")
+
+	ctx = WithBalanceLedgerTag(context.Background(), paymentOrderBalanceLedgerTag(BalanceLedgerTypeRecharge, 42))
+	tag, ok := BalanceLedgerTagFromContext(ctx)
+	require.True(t, ok)
+	require.Equal(t, BalanceLedgerTypeRecharge, tag.Type)
+	require.Equal(t, BalanceLedgerRefPaymentOrder, tag.ReferenceType)
+	require.Equal(t, "42", tag.ReferenceID)
+}
+
+func TestWithDefaultBalanceLedgerTag_KeepsCallerTag(t *testing.T) {
+	ctx := WithBalanceLedgerTag(context.Background(), BalanceLedgerTag{Type: BalanceLedgerTypeRecharge})
+	ctx = withDefaultBalanceLedgerTag(ctx, BalanceLedgerTag{Type: BalanceLedgerTypeRedeem})
+	tag, _ := BalanceLedgerTagFromContext(ctx)
+	require.Equal(t, BalanceLedgerTypeRecharge, tag.Type)
+
+	ctx = withDefaultBalanceLedgerTag(context.Background(), BalanceLedgerTag{Type: BalanceLedgerTypeRedeem})
+	tag, _ = BalanceLedgerTagFromContext(ctx)
+	require.Equal(t, BalanceLedgerTypeRedeem, tag.Type)
+}
+
+func TestBalanceLedgerTagStatement(t *testing.T) {
+	tag := BalanceLedgerTag{Type: "usage", ReferenceType: "usage_request", ReferenceID: "req-1", Note: "n"}
+	query, args := BalanceLedgerTagStatement(dialect.Postgres, tag)
+	require.Contains(t, query, "set_config('sub2api.ledger_type', $1, true)")
+	require.Equal(t, []any{"usage", "usage_request", "req-1", "n"}, args)
+
+	query, args = BalanceLedgerTagStatement(dialect.SQLite, tag)
+	require.Empty(t, query)
+	require.Empty(t, args)
+}
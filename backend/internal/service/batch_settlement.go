@@ -0,0 +1,488 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	batchSettlementMaxRetries      = 5
+	batchSettlementErrorRetryDelay = time.Minute
+	batchSettlementStuckCode       = "SETTLEMENT_STUCK"
+)
+
+// batchSettlementPhase 是结算引擎视角下的批次阶段，由各供应商的本地状态映射而来。
+type batchSettlementPhase int
+
+const (
+	// batchSettlementPhaseIdle 已结算、已失败或结算卡住，轮询无需处理。
+	batchSettlementPhaseIdle batchSettlementPhase = iota
+	// batchSettlementPhaseCreated 已冻结余额但尚未拿到上游 ID。
+	batchSettlementPhaseCreated
+	// batchSettlementPhasePolling 上游处理中（含取消中），需要同步状态。
+	batchSettlementPhasePolling
+	// batchSettlementPhaseSettling 上游已结束，等待结算。
+	batchSettlementPhaseSettling
+)
+
+// batchSettlementJob 是结算引擎读取的批次公共字段。
+type batchSettlementJob struct {
+	BatchID                 string
+	Phase                   batchSettlementPhase
+	UserID                  int64
+	APIKeyID                int64
+	AccountID               int64
+	GroupID                 *int64
+	HoldAmount              float64
+	RequestHash             string
+	RetryCount              int
+	LastErrorCode           *string
+	GroupRateMultiplier     float64
+	AccountRateMultiplier   float64
+	BatchDiscountMultiplier float64
+}
+
+// batchSettlementProvider 是供应商批次 API 的适配层，只负责本地记录读写、上游状态同步
+// 与结果解析；状态机、冻结余额的结算与释放、重试与用量记录由 batchSettlementEngine 统一处理。
+type batchSettlementProvider[J any] interface {
+	settlementJob(job J) batchSettlementJob
+	claimDueJobs(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]J, error)
+	// refreshFromUpstream 同步上游状态并返回最新的本地记录。
+	refreshFromUpstream(ctx context.Context, job J) (J, error)
+	// scanSettlementUsage 下载并解析上游结果，逐条返回成功请求的 usage；失败时同时返回错误码。
+	scanSettlementUsage(ctx context.Context, job J) ([]messageBatchModelUsage, string, error)
+	markSettled(ctx context.Context, job J, charges []messageBatchModelCharge, actualCost float64, settledAt time.Time) error
+	markFailed(ctx context.Context, batchID, code, message string) error
+	// markSettlementStuck 把 settling 批次转入不再轮询的终态，冻结余额保持不动。
+	markSettlementStuck(ctx context.Context, batchID, code, message string) error
+	// recordError 记录错误并把累计重试次数与错误码写回 job。
+	recordError(ctx context.Context, job J, code, message string, nextPollAt time.Time) error
+	// usageLog 构造单个模型的汇总用量记录；金额字段由引擎填写。
+	usageLog(job J, charge messageBatchModelCharge) *UsageLog
+}
+
+// batchSettlementErrors 是引擎对外返回的供应商错误。
+type batchSettlementErrors struct {
+	BillingFailed     *infraerrors.ApplicationError
+	InsufficientFunds *infraerrors.ApplicationError
+	PricingMissing    *infraerrors.ApplicationError
+	InvalidTransition *infraerrors.ApplicationError
+}
+
+// batchSettlementEngine 是 Message Batches 与 OpenAI Batch 共用的轮询结算引擎：
+// 领取到期批次，推进 created → 失败、polling → settling → completed 的状态机，
+// 从冻结余额中按批量折扣价结算，失败时按固定间隔重试；超过次数后保留冻结并转入
+// settlement_stuck，由运维告警（batch_settlement_stuck_count）提示人工结算。
+type batchSettlementEngine[J any] struct {
+	provider batchSettlementProvider[J]
+	name     string
+	errs     batchSettlementErrors
+
+	holdRequestID    func(batchID string) string
+	captureRequestID func(batchID string) string
+	releaseRequestID func(batchID string) string
+
+	billingRepo  UsageBillingRepository
+	usageLogRepo UsageLogRepository
+	pricing      MessageBatchCostCalculator
+	authCache    APIKeyAuthCacheInvalidator
+	groupRepo    BatchImageGroupPricingRepository
+	volumeTiers  *volumeTierResolver
+
+	now                 func() time.Time
+	pollBatchSize       int
+	upstreamTimeout     time.Duration
+	maxErrorMessageSize int
+}
+
+// runOnce 领取一轮到期批次并逐个处理，返回处理的批次数。
+func (e *batchSettlementEngine[J]) runOnce(ctx context.Context) int {
+	// lease 覆盖一次完整处理（状态查询 + 结果下载），避免处理中被其他实例重复领取。
+	lease := 2 * e.upstreamTimeout
+	jobs, err := e.provider.claimDueJobs(ctx, e.now(), lease, e.pollBatchSize)
+	if err != nil {
+		logger.L().Warn(e.name+".claim_failed", zap.Error(err))
+		return 0
+	}
+	for _, job := range jobs {
+		if ctx.Err() != nil {
+			break
+		}
+		jobCtx, cancel := context.WithTimeout(ctx, e.upstreamTimeout)
+		e.processJob(jobCtx, job)
+		cancel()
+	}
+	return len(jobs)
+}
+
+func (e *batchSettlementEngine[J]) processJob(ctx context.Context, job J) {
+	view := e.provider.settlementJob(job)
+	switch view.Phase {
+	case batchSettlementPhaseCreated:
+		e.abandonCreatedJob(ctx, job)
+	case batchSettlementPhasePolling:
+		refreshed, err := e.provider.refreshFromUpstream(ctx, job)
+		if err != nil {
+			e.recordJobError(ctx, job, "UPSTREAM_POLL_FAILED", err)
+			return
+		}
+		if e.provider.settlementJob(refreshed).Phase == batchSettlementPhaseSettling {
+			e.settleLogged(ctx, refreshed)
+		}
+	case batchSettlementPhaseSettling:
+		e.settleLogged(ctx, job)
+	}
+}
+
+func (e *batchSettlementEngine[J]) settleLogged(ctx context.Context, job J) {
+	if err := e.settle(ctx, job); err != nil {
+		logger.L().Warn(e.name+".settle_failed", zap.String("batch_id", e.provider.settlementJob(job).BatchID), zap.Error(err))
+	}
+}
+
+// abandonCreatedJob 处理提交中途退出留下的 created 批次：释放冻结并标记失败。
+// 这类批次从未拿到上游 ID，对客户端不可见。
+func (e *batchSettlementEngine[J]) abandonCreatedJob(ctx context.Context, job J) {
+	if err := e.releaseHold(ctx, job); err != nil {
+		e.recordJobError(ctx, job, "BILLING_RELEASE_FAILED", err)
+		return
+	}
+	e.markFailedBestEffort(ctx, e.provider.settlementJob(job).BatchID, "SUBMIT_ABANDONED", "batch was not submitted upstream")
+}
+
+// settle 扫描上游结果中成功请求的 usage，按批量折扣价从冻结余额中结算并写入用量记录。
+func (e *batchSettlementEngine[J]) settle(ctx context.Context, job J) error {
+	view := e.provider.settlementJob(job)
+	if view.Phase != batchSettlementPhaseSettling {
+		return e.errs.InvalidTransition
+	}
+	if view.RetryCount >= batchSettlementMaxRetries && strings.HasPrefix(derefStr(view.LastErrorCode), "SETTLEMENT_") {
+		return e.parkExhaustedSettlement(ctx, job)
+	}
+	usages, code, err := e.provider.scanSettlementUsage(ctx, job)
+	if err != nil {
+		return e.recordSettlementError(ctx, job, code, err)
+	}
+	now := e.now()
+	groupMultiplier, volumeTier := e.applyVolumeTier(ctx, view, now)
+	charges, actualCost, err := e.priceUsage(view, groupMultiplier, usages)
+	if err != nil {
+		return e.recordSettlementError(ctx, job, "SETTLEMENT_PRICING_MISSING", err)
+	}
+	if actualCost-view.HoldAmount > batchImageCostEpsilon {
+		// 估算以字节数为输入 token 上限并包含最大输出 token，正常不会超出；
+		// 超出时按冻结金额封顶结算，保证冻结资金能完整解冻。
+		logger.L().Warn(e.name+".settlement_cost_capped",
+			zap.String("batch_id", view.BatchID),
+			zap.Float64("actual_cost", actualCost),
+			zap.Float64("hold_amount", view.HoldAmount),
+		)
+		actualCost = view.HoldAmount
+	}
+
+	if view.HoldAmount > 0 || actualCost > 0 {
+		if e.billingRepo == nil {
+			return e.recordSettlementError(ctx, job, "SETTLEMENT_BILLING_FAILED", errors.New("usage billing repository is not configured"))
+		}
+		if _, err := e.billingRepo.CaptureBatchImageBalance(ctx, e.holdCommand(view, e.captureRequestID(view.BatchID), actualCost)); err != nil {
+			return e.recordSettlementError(ctx, job, "SETTLEMENT_BILLING_FAILED", err)
+		}
+		e.invalidateAuthCache(ctx, view.UserID)
+		if volumeTier != nil {
+			e.volumeTiers.Add(view.UserID, *view.GroupID, now, actualCost)
+		}
+	}
+
+	if err := e.provider.markSettled(ctx, job, charges, actualCost, now); err != nil {
+		return err
+	}
+	e.recordUsageLogs(ctx, job, charges, actualCost, groupMultiplier, volumeTier, now)
+	return nil
+}
+
+// applyVolumeTier 按结算时刻的当月累计消费为批次判档，在提交时快照的分组倍率上叠加阶梯倍率，
+// 返回叠加后的分组倍率与档位标签。分组读取失败时不叠加阶梯，与同步计费的降级方向一致（不多给折扣）。
+func (e *batchSettlementEngine[J]) applyVolumeTier(ctx context.Context, view batchSettlementJob, now time.Time) (float64, *string) {
+	if view.GroupID == nil || *view.GroupID <= 0 || e.groupRepo == nil {
+		return view.GroupRateMultiplier, nil
+	}
+	group, err := e.groupRepo.GetByIDLite(ctx, *view.GroupID)
+	if err != nil || group == nil {
+		logger.L().Warn(e.name+".load_group_failed", zap.String("batch_id", view.BatchID), zap.Int64("group_id", *view.GroupID), zap.Error(err))
+		return view.GroupRateMultiplier, nil
+	}
+	apiKey := &APIKey{ID: view.APIKeyID, GroupID: view.GroupID, Group: group}
+	return applyGroupVolumeTier(ctx, e.volumeTiers, apiKey, view.UserID, view.GroupRateMultiplier, now)
+}
+
+// priceUsage 按标准价 × 分组（含月度消费阶梯）/账号倍率 × 批量折扣计算各模型费用。
+func (e *batchSettlementEngine[J]) priceUsage(view batchSettlementJob, groupMultiplier float64, usages []messageBatchModelUsage) ([]messageBatchModelCharge, float64, error) {
+	if e.pricing == nil && len(usages) > 0 {
+		return nil, 0, e.errs.PricingMissing
+	}
+	return priceBatchModelUsages(e.pricing, usages, groupMultiplier*view.AccountRateMultiplier*view.BatchDiscountMultiplier)
+}
+
+// recordUsageLogs 为每个模型写一条汇总用量记录；金额按封顶后的实扣总额等比分摊。
+func (e *batchSettlementEngine[J]) recordUsageLogs(ctx context.Context, job J, charges []messageBatchModelCharge, capturedCost, groupMultiplier float64, volumeTier *string, createdAt time.Time) {
+	if e.usageLogRepo == nil || len(charges) == 0 {
+		return
+	}
+	uncapped := 0.0
+	for _, charge := range charges {
+		uncapped += charge.ActualCost
+	}
+	scale := 1.0
+	if uncapped > 0 && capturedCost < uncapped {
+		scale = capturedCost / uncapped
+	}
+	view := e.provider.settlementJob(job)
+	billingMode := string(BillingModeToken)
+	accountRateMultiplier := view.AccountRateMultiplier
+	for _, charge := range charges {
+		bd := charge.Breakdown
+		usageLog := e.provider.usageLog(job, charge)
+		usageLog.UserID = view.UserID
+		usageLog.APIKeyID = view.APIKeyID
+		usageLog.AccountID = view.AccountID
+		usageLog.GroupID = view.GroupID
+		usageLog.Model = charge.Usage.Model
+		usageLog.RequestedModel = charge.Usage.Model
+		usageLog.InputCost = bd.InputCost
+		usageLog.OutputCost = bd.OutputCost
+		usageLog.CacheCreationCost = bd.CacheCreationCost
+		usageLog.CacheReadCost = bd.CacheReadCost
+		usageLog.TotalCost = bd.TotalCost
+		usageLog.ActualCost = charge.ActualCost * scale
+		usageLog.RateMultiplier = groupMultiplier * view.BatchDiscountMultiplier
+		usageLog.BillingTier = volumeTier
+		usageLog.AccountRateMultiplier = &accountRateMultiplier
+		usageLog.BillingType = BillingTypeBalance
+		usageLog.RequestType = RequestTypeSync
+		usageLog.BillingMode = &billingMode
+		usageLog.CreatedAt = createdAt
+		writeUsageLogBestEffort(ctx, e.usageLogRepo, usageLog, "service."+e.name+"_settlement")
+	}
+}
+
+// parkExhaustedSettlement 处理重试耗尽的结算。上游已完成并产生费用，释放冻结等于免单，
+// 且可被刻意制造的结算错误利用；因此冻结保持不动，批次转入 settlement_stuck 等待人工结算。
+func (e *batchSettlementEngine[J]) parkExhaustedSettlement(ctx context.Context, job J) error {
+	view := e.provider.settlementJob(job)
+	message := "settlement retry limit reached: " + derefStr(view.LastErrorCode)
+	if err := e.provider.markSettlementStuck(ctx, view.BatchID, batchSettlementStuckCode, truncateString(message, e.maxErrorMessageSize)); err != nil {
+		logger.L().Warn(e.name+".mark_settlement_stuck_failed", zap.String("batch_id", view.BatchID), zap.Error(err))
+		return err
+	}
+	logger.L().Error(e.name+".settlement_stuck",
+		zap.String("batch_id", view.BatchID),
+		zap.Int64("user_id", view.UserID),
+		zap.Float64("hold_amount", view.HoldAmount),
+		zap.String("last_error_code", derefStr(view.LastErrorCode)),
+	)
+	return e.errs.BillingFailed
+}
+
+func (e *batchSettlementEngine[J]) recordSettlementError(ctx context.Context, job J, code string, cause error) error {
+	e.recordJobError(ctx, job, code, cause)
+	return e.errs.BillingFailed.WithCause(cause)
+}
+
+func (e *batchSettlementEngine[J]) recordJobError(ctx context.Context, job J, code string, cause error) {
+	message := ""
+	if cause != nil {
+		message = truncateString(cause.Error(), e.maxErrorMessageSize)
+	}
+	if err := e.provider.recordError(ctx, job, code, message, e.now().Add(batchSettlementErrorRetryDelay)); err != nil {
+		logger.L().Warn(e.name+".record_error_failed",
+			zap.String("batch_id", e.provider.settlementJob(job).BatchID),
+			zap.String("code", code),
+			zap.Error(err),
+		)
+	}
+}
+
+func (e *batchSettlementEngine[J]) markFailedBestEffort(ctx context.Context, batchID, code, message string) {
+	if err := e.provider.markFailed(ctx, batchID, code, truncateString(message, e.maxErrorMessageSize)); err != nil {
+		logger.L().Warn(e.name+".mark_failed_failed",
+			zap.String("batch_id", batchID),
+			zap.String("code", code),
+			zap.Error(err),
+		)
+	}
+}
+
+func (e *batchSettlementEngine[J]) holdCommand(view batchSettlementJob, requestID string, actualAmount float64) *BatchImageBalanceHoldCommand {
+	return &BatchImageBalanceHoldCommand{
+		RequestID:          requestID,
+		APIKeyID:           view.APIKeyID,
+		UserID:             view.UserID,
+		BatchID:            view.BatchID,
+		HoldRequestID:      e.holdRequestID(view.BatchID),
+		HoldAmount:         view.HoldAmount,
+		ActualAmount:       actualAmount,
+		RequestPayloadHash: view.RequestHash,
+	}
+}
+
+// reserveHold 在提交上游前冻结估算金额。
+func (e *batchSettlementEngine[J]) reserveHold(ctx context.Context, job J) error {
+	view := e.provider.settlementJob(job)
+	if view.HoldAmount <= 0 {
+		return nil
+	}
+	if e.billingRepo == nil {
+		return e.errs.BillingFailed.WithCause(errors.New("usage billing repository is not configured"))
+	}
+	if _, err := e.billingRepo.ReserveBatchImageBalance(ctx, e.holdCommand(view, e.holdRequestID(view.BatchID), 0)); err != nil {
+		if errors.Is(err, ErrBatchImageInsufficientBalance) {
+			return e.errs.InsufficientFunds
+		}
+		return e.errs.BillingFailed.WithCause(err)
+	}
+	e.invalidateAuthCache(ctx, view.UserID)
+	return nil
+}
+
+// releaseHold 全额退回冻结；重复释放（请求 ID 冲突）视为已完成。
+func (e *batchSettlementEngine[J]) releaseHold(ctx context.Context, job J) error {
+	view := e.provider.settlementJob(job)
+	if view.HoldAmount <= 0 {
+		return nil
+	}
+	if e.billingRepo == nil {
+		return e.errs.BillingFailed.WithCause(errors.New("usage billing repository is not configured"))
+	}
+	if _, err := e.billingRepo.ReleaseBatchImageBalance(ctx, e.holdCommand(view, e.releaseRequestID(view.BatchID), 0)); err != nil {
+		if errors.Is(err, ErrUsageBillingRequestConflict) {
+			return nil
+		}
+		return e.errs.BillingFailed.WithCause(err)
+	}
+	e.invalidateAuthCache(ctx, view.UserID)
+	return nil
+}
+
+func (e *batchSettlementEngine[J]) invalidateAuthCache(ctx context.Context, userID int64) {
+	if e.authCache != nil && userID > 0 {
+		e.authCache.InvalidateAuthCacheByUserID(ctx, userID)
+	}
+}
+
+// batchSettlementPoller 管理结算轮询的后台 goroutine。
+type batchSettlementPoller struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+	mu     sync.Mutex
+}
+
+func (p *batchSettlementPoller) start(interval time.Duration, runOnce func(ctx context.Context)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cancel != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	p.done = make(chan struct{})
+	done := p.done
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			runOnce(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (p *batchSettlementPoller) stop() {
+	p.mu.Lock()
+	cancel := p.cancel
+	done := p.done
+	p.cancel = nil
+	p.done = nil
+	p.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	if done != nil {
+		<-done
+	}
+}
+
+// messageBatchModelUsage 是单条成功请求（或单个模型汇总）的 usage。
+type messageBatchModelUsage struct {
+	Model    string
+	Requests int
+	Tokens   UsageTokens
+}
+
+// messageBatchModelCharge 是单个模型的结算金额。
+type messageBatchModelCharge struct {
+	Usage      messageBatchModelUsage
+	Breakdown  *CostBreakdown
+	ActualCost float64
+}
+
+// priceBatchModelUsages 逐条请求计价后按模型汇总；逐条计价避免多条请求的 token
+// 累加后误触长上下文阶梯价。multiplier 为作用在标准价上的总倍率。
+func priceBatchModelUsages(pricing MessageBatchCostCalculator, usages []messageBatchModelUsage, multiplier float64) ([]messageBatchModelCharge, float64, error) {
+	byModel := make(map[string]*messageBatchModelCharge)
+	total := 0.0
+	for _, usage := range usages {
+		breakdown, err := pricing.CalculateCost(usage.Model, usage.Tokens, 1.0)
+		if err != nil || breakdown == nil {
+			return nil, 0, fmt.Errorf("price model %q: %w", usage.Model, err)
+		}
+		actual := breakdown.TotalCost * multiplier
+		charge := byModel[usage.Model]
+		if charge == nil {
+			charge = &messageBatchModelCharge{Usage: messageBatchModelUsage{Model: usage.Model}, Breakdown: &CostBreakdown{}}
+			byModel[usage.Model] = charge
+		}
+		charge.Usage.Requests += usage.Requests
+		addUsageTokens(&charge.Usage.Tokens, usage.Tokens)
+		addCostBreakdown(charge.Breakdown, breakdown)
+		charge.ActualCost += actual
+		total += actual
+	}
+	charges := make([]messageBatchModelCharge, 0, len(byModel))
+	for _, charge := range byModel {
+		charges = append(charges, *charge)
+	}
+	sort.Slice(charges, func(i, j int) bool { return charges[i].Usage.Model < charges[j].Usage.Model })
+	return charges, total, nil
+}
+
+func addUsageTokens(dst *UsageTokens, src UsageTokens) {
+	dst.InputTokens += src.InputTokens
+	dst.OutputTokens += src.OutputTokens
+	dst.CacheCreationTokens += src.CacheCreationTokens
+	dst.CacheReadTokens += src.CacheReadTokens
+	dst.CacheCreation5mTokens += src.CacheCreation5mTokens
+	dst.CacheCreation1hTokens += src.CacheCreation1hTokens
+}
+
+func addCostBreakdown(dst, src *CostBreakdown) {
+	dst.InputCost += src.InputCost
+	dst.OutputCost += src.OutputCost
+	dst.CacheCreationCost += src.CacheCreationCost
+	dst.CacheReadCost += src.CacheReadCost
+	dst.TotalCost += src.TotalCost
+	dst.ActualCost += src.ActualCost
+}
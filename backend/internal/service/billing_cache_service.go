@@ -11,6 +11,7 @@ import (
 	"github.com/Wei-Shaw/sub2api/internal/config"
 	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/metrics"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
 	"golang.org/x/sync/singleflight"
 )
@@ -28,6 +29,8 @@ var (
 	// RPM 超限错误。gateway_handler 负责映射为 HTTP 429。
 	ErrGroupRPMExceeded = infraerrors.TooManyRequests("GROUP_RPM_EXCEEDED", "group requests-per-minute limit exceeded")
 	ErrUserRPMExceeded  = infraerrors.TooManyRequests("USER_RPM_EXCEEDED", "user requests-per-minute limit exceeded")
+	// ErrAPIKeyRPMExceeded 单个 API Key 的 rpm_limit 超限。
+	ErrAPIKeyRPMExceeded = infraerrors.TooManyRequests("API_KEY_RPM_EXCEEDED", "api key requests-per-minute limit exceeded")
 
 	// user × platform quota（HTTP 429 Too Many Requests + Retry-After header）。
 	// 选用 429 而非 403：限额耗尽属于"暂时性资源用尽，重试可恢复"的场景（RFC 6585），
@@ -113,6 +116,8 @@ type BillingCacheService struct {
 	cfg                   *config.Config
 	circuitBreaker        *billingCircuitBreaker
 	userPlatformQuotaRepo UserPlatformQuotaRepository
+	orgWallets            OrganizationWalletReader
+	orgCharger            OrganizationWalletCharger
 
 	cacheWriteChan     chan cacheWriteTask
 	cacheWriteWg       sync.WaitGroup
@@ -154,6 +159,24 @@ func NewBillingCacheService(
 	return svc
 }
 
+// SetOrganizationWalletReader 注入组织钱包读取器：组织成员的余额预检改查组织钱包与成员月度额度。
+func (s *BillingCacheService) SetOrganizationWalletReader(reader OrganizationWalletReader) {
+	s.orgWallets = reader
+}
+
+// SetOrganizationWalletCharger 注入组织钱包扣费入口，供 legacy 计费路径为组织成员扣组织钱包。
+func (s *BillingCacheService) SetOrganizationWalletCharger(charger OrganizationWalletCharger) {
+	s.orgCharger = charger
+}
+
+// chargeOrganizationWallet 成员属于 active 组织时从组织钱包扣费；未注入或非成员返回 false。
+func (s *BillingCacheService) chargeOrganizationWallet(ctx context.Context, userID int64, amount float64) (bool, error) {
+	if s == nil || s.orgCharger == nil {
+		return false, nil
+	}
+	return s.orgCharger.ChargeMemberWallet(ctx, userID, amount)
+}
+
 // Stop 关闭缓存写入工作池
 func (s *BillingCacheService) Stop() {
 	s.cacheWriteStopOnce.Do(func() {
@@ -316,6 +339,7 @@ func (s *BillingCacheService) GetUserBalance(ctx context.Context, userID int64)
 
 	// 尝试从缓存读取
 	balance, err := s.cache.GetUserBalance(ctx, userID)
+	metrics.ObserveBillingCacheLookup(metrics.BillingCacheBalance, err == nil)
 	if err == nil {
 		return balance, nil
 	}
@@ -419,6 +443,7 @@ func (s *BillingCacheService) GetSubscriptionStatus(ctx context.Context, userID,
 
 	// 尝试从缓存读取
 	cacheData, err := s.cache.GetSubscriptionCache(ctx, userID, groupID)
+	metrics.ObserveBillingCacheLookup(metrics.BillingCacheSubscription, err == nil && cacheData != nil)
 	if err == nil && cacheData != nil {
 		return s.convertFromPortsData(cacheData), nil
 	}
@@ -587,6 +612,7 @@ func (s *BillingCacheService) checkAPIKeyRateLimits(ctx context.Context, apiKey
 	}
 
 	cacheData, err := s.cache.GetAPIKeyRateLimit(ctx, apiKey.ID)
+	metrics.ObserveBillingCacheLookup(metrics.BillingCacheAPIKeyRate, err == nil)
 	if err != nil {
 		// Cache miss: load from DB and populate cache
 		if s.apiKeyRateLimitLoader == nil {
@@ -737,6 +763,10 @@ func (s *BillingCacheService) CheckBillingEligibility(ctx context.Context, user
 	if s.cfg.RunMode == config.RunModeSimple {
 		return nil
 	}
+	// 流量镜像请求不向用户计费，主请求已通过资格检查。
+	if IsTrafficMirrorContext(ctx) {
+		return nil
+	}
 	if s.circuitBreaker != nil && !s.circuitBreaker.Allow() {
 		return ErrBillingServiceUnavailable
 	}
@@ -745,11 +775,11 @@ func (s *BillingCacheService) CheckBillingEligibility(ctx context.Context, user
 	isSubscriptionMode := group != nil && group.IsSubscriptionType() && subscription != nil
 
 	if isSubscriptionMode {
-		if err := s.checkSubscriptionEligibility(ctx, user.ID, group, subscription); err != nil {
+		if err := s.checkSubscriptionEligibility(ctx, subscriptionHolderID(user.ID, subscription), group, subscription); err != nil {
 			return err
 		}
 	} else {
-		if err := s.checkBalanceEligibility(ctx, user.ID); err != nil {
+		if err := s.checkBalanceEligibility(ctx, user.ID, user.CreditLimit); err != nil {
 			return err
 		}
 	}
@@ -769,6 +799,10 @@ func (s *BillingCacheService) CheckBillingEligibility(ctx context.Context, user
 	}
 
 	// RPM 限流：级联回落（Override → Group → User），放在最后以避免为注定失败的请求增加计数。
+	// Key 级先于用户/分组级检查：单个 Key 超限时不消耗该用户其它 Key 共享的额度。
+	if err := s.checkAPIKeyRPM(ctx, apiKey); err != nil {
+		return err
+	}
 	if err := s.checkRPM(ctx, user, group); err != nil {
 		return err
 	}
@@ -860,6 +894,27 @@ func (s *BillingCacheService) checkRPM(ctx context.Context, user *User, group *G
 	return nil
 }
 
+// checkAPIKeyRPM 执行单 Key 的 rpm_limit 限流，与用户/分组级限流同时生效。
+// Redis 故障 fail-open，与 checkRPM 一致。
+func (s *BillingCacheService) checkAPIKeyRPM(ctx context.Context, apiKey *APIKey) error {
+	if s == nil || s.userRPMCache == nil || apiKey == nil || apiKey.RPMLimit <= 0 {
+		return nil
+	}
+	count, err := s.userRPMCache.IncrementAPIKeyRPM(ctx, apiKey.ID)
+	if err != nil {
+		logger.LegacyPrintf(
+			"service.billing_cache",
+			"Warning: rpm increment (api key) failed for api_key=%d: %v",
+			apiKey.ID, err,
+		)
+		return nil // fail-open
+	}
+	if count > apiKey.RPMLimit {
+		return ErrAPIKeyRPMExceeded
+	}
+	return nil
+}
+
 func (s *BillingCacheService) minimumBalanceReserve() float64 {
 	if s == nil || s.cfg == nil || s.cfg.Billing.MinimumBalanceReserve <= 0 {
 		return 0
@@ -875,8 +930,22 @@ func (s *BillingCacheService) balanceBelowEligibilityThreshold(balance float64)
 	return minimumReserve > 0 && balance < minimumReserve
 }
 
-// checkBalanceEligibility 检查余额模式资格
-func (s *BillingCacheService) checkBalanceEligibility(ctx context.Context, userID int64) error {
+// checkBalanceEligibility 检查余额模式资格。creditLimit 为后付费授信额度，余额可透支到 -creditLimit。
+func (s *BillingCacheService) checkBalanceEligibility(ctx context.Context, userID int64, creditLimit float64) error {
+	if s.orgWallets != nil {
+		wallet, err := s.orgWallets.GetWalletForUser(ctx, userID)
+		if err != nil {
+			if s.circuitBreaker != nil {
+				s.circuitBreaker.OnFailure(err)
+			}
+			logger.LegacyPrintf("service.billing_cache", "ALERT: organization wallet check failed for user %d: %v", userID, err)
+			return ErrBillingServiceUnavailable.WithCause(err)
+		}
+		if wallet != nil {
+			return organizationWalletEligibility(wallet, s.balanceBelowEligibilityThreshold)
+		}
+	}
+
 	balance, err := s.GetUserBalance(ctx, userID)
 	if err != nil {
 		if s.circuitBreaker != nil {
@@ -889,6 +958,9 @@ func (s *BillingCacheService) checkBalanceEligibility(ctx context.Context, userI
 		s.circuitBreaker.OnSuccess()
 	}
 
+	if creditLimit > 0 {
+		balance += creditLimit
+	}
 	if s.balanceBelowEligibilityThreshold(balance) {
 		return ErrInsufficientBalance
 	}
@@ -126,3 +126,45 @@ func TestSyncBalanceCacheAfterDeduction_QueuesDeductWhenBalanceStillEligible(t *
 		return cache.deductCalls.Load() == 1
 	}, 2*time.Second, 10*time.Millisecond)
 }
+
+type organizationWalletChargerStub struct {
+	members map[int64]bool
+	charged []float64
+}
+
+func (s *organizationWalletChargerStub) ChargeMemberWallet(_ context.Context, userID int64, amount float64) (bool, error) {
+	if !s.members[userID] {
+		return false, nil
+	}
+	s.charged = append(s.charged, amount)
+	return true, nil
+}
+
+func TestPostUsageBilling_LegacyPathChargesOrganizationWalletForMembers(t *testing.T) {
+	cache := &balanceEligibilityCacheStub{balance: 1}
+	svc := NewBillingCacheService(cache, nil, nil, nil, nil, nil, &config.Config{}, nil)
+	t.Cleanup(svc.Stop)
+	charger := &organizationWalletChargerStub{members: map[int64]bool{1: true}}
+	svc.SetOrganizationWalletCharger(charger)
+	userRepo := &openAIRecordUsageUserRepoStub{}
+	deps := &billingDeps{userRepo: userRepo, billingCacheService: svc}
+
+	postUsageBilling(context.Background(), &postUsageBillingParams{
+		Cost:    &CostBreakdown{ActualCost: 0.5},
+		User:    &User{ID: 1},
+		APIKey:  &APIKey{ID: 10},
+		Account: &Account{ID: 20},
+	}, deps)
+	require.Equal(t, []float64{0.5}, charger.charged)
+	require.Equal(t, 0, userRepo.deductCalls)
+
+	postUsageBilling(context.Background(), &postUsageBillingParams{
+		Cost:    &CostBreakdown{ActualCost: 0.25},
+		User:    &User{ID: 2},
+		APIKey:  &APIKey{ID: 11},
+		Account: &Account{ID: 21},
+	}, deps)
+	require.Len(t, charger.charged, 1)
+	require.Equal(t, 1, userRepo.deductCalls)
+	require.InDelta(t, 0.25, userRepo.lastAmount, 1e-12)
+}
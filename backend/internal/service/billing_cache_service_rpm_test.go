@@ -16,11 +16,13 @@ import (
 type userRPMCacheStub struct {
 	userGroupCalls int32
 	userCalls      int32
+	apiKeyCalls    int32
 
 	userGroupCounts []int // 依次返回的计数值
 	userGroupErr    error
 	userCounts      []int
 	userErr         error
+	apiKeyCounts    []int
 }
 
 func (s *userRPMCacheStub) IncrementUserGroupRPM(_ context.Context, _, _ int64) (int, error) {
@@ -53,6 +55,18 @@ func (s *userRPMCacheStub) GetUserRPM(_ context.Context, _ int64) (int, error) {
 	return 0, nil
 }
 
+func (s *userRPMCacheStub) IncrementAPIKeyRPM(_ context.Context, _ int64) (int, error) {
+	idx := int(atomic.AddInt32(&s.apiKeyCalls, 1)) - 1
+	if idx < len(s.apiKeyCounts) {
+		return s.apiKeyCounts[idx], nil
+	}
+	return 1, nil
+}
+
+func (s *userRPMCacheStub) GetAPIKeyRPMBatch(_ context.Context, _ []int64) (map[int64]int, error) {
+	return map[int64]int{}, nil
+}
+
 // rpmOverrideRepoStub 专用于 checkRPM 分支测试，只实现必要方法。
 type rpmOverrideRepoStub struct {
 	UserGroupRateRepository
@@ -251,3 +265,18 @@ func TestBillingCacheService_CheckRPM_NilUserIsNoop(t *testing.T) {
 	require.EqualValues(t, 0, atomic.LoadInt32(&cache.userCalls))
 	require.EqualValues(t, 0, atomic.LoadInt32(&repo.calls))
 }
+
+func TestBillingCacheService_CheckAPIKeyRPM(t *testing.T) {
+	cache := &userRPMCacheStub{apiKeyCounts: []int{1, 2, 3}}
+	svc := newBillingServiceForRPM(t, cache, nil)
+
+	// 未配置 rpm_limit 的 Key 不计数。
+	require.NoError(t, svc.checkAPIKeyRPM(context.Background(), &APIKey{ID: 7}))
+	require.EqualValues(t, 0, atomic.LoadInt32(&cache.apiKeyCalls))
+
+	apiKey := &APIKey{ID: 7, RPMLimit: 2}
+	require.NoError(t, svc.checkAPIKeyRPM(context.Background(), apiKey))
+	require.NoError(t, svc.checkAPIKeyRPM(context.Background(), apiKey))
+	require.ErrorIs(t, svc.checkAPIKeyRPM(context.Background(), apiKey), ErrAPIKeyRPMExceeded)
+	require.EqualValues(t, 3, atomic.LoadInt32(&cache.apiKeyCalls))
+}
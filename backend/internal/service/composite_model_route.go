@@ -28,23 +28,27 @@ const (
 var (
 	ErrCompositeRouteNotFound = infraerrors.NotFound("COMPOSITE_ROUTE_NOT_FOUND", "composite route not found")
 	ErrCompositeRouteExists   = infraerrors.Conflict("COMPOSITE_ROUTE_EXISTS", "composite route already exists")
+
+	ErrCompositeRouteNoExperiment = infraerrors.BadRequest("COMPOSITE_ROUTE_NO_EXPERIMENT", "composite route has no traffic split")
+	ErrCompositeRouteInvalidRange = infraerrors.BadRequest("COMPOSITE_ROUTE_INVALID_RANGE", "start_time must be before end_time")
 )
 
 // CompositeModelRoute maps one public model identifier in a composite group to
 // the concrete provider/model that should handle the request.
 type CompositeModelRoute struct {
-	ID             int64     `json:"id"`
-	GroupID        int64     `json:"group_id"`
-	PublicModel    string    `json:"public_model"`
-	MatchType      string    `json:"match_type"`
-	TargetPlatform string    `json:"target_platform"`
-	UpstreamModel  string    `json:"upstream_model"`
-	Endpoint       string    `json:"endpoint"`
-	Priority       int       `json:"priority"`
-	Enabled        bool      `json:"enabled"`
-	Notes          string    `json:"notes"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+	ID             int64                `json:"id"`
+	GroupID        int64                `json:"group_id"`
+	PublicModel    string               `json:"public_model"`
+	MatchType      string               `json:"match_type"`
+	TargetPlatform string               `json:"target_platform"`
+	UpstreamModel  string               `json:"upstream_model"`
+	Endpoint       string               `json:"endpoint"`
+	Priority       int                  `json:"priority"`
+	Enabled        bool                 `json:"enabled"`
+	Notes          string               `json:"notes"`
+	Split          *CompositeRouteSplit `json:"split,omitempty"`
+	CreatedAt      time.Time            `json:"created_at"`
+	UpdatedAt      time.Time            `json:"updated_at"`
 }
 
 type CompositeRoutePreviewRequest struct {
@@ -61,6 +65,8 @@ type CompositeRouteDecision struct {
 	UpstreamModel  string               `json:"upstream_model"`
 	Endpoint       string               `json:"endpoint"`
 	Route          *CompositeModelRoute `json:"route,omitempty"`
+	Experiment     string               `json:"experiment,omitempty"`
+	Arm            string               `json:"arm,omitempty"`
 	Reason         string               `json:"reason,omitempty"`
 }
 
@@ -73,6 +79,7 @@ type CompositeRouteInput struct {
 	Priority       int
 	Enabled        bool
 	Notes          string
+	Split          *CompositeRouteSplit
 }
 
 type CompositeModelRouteRepository interface {
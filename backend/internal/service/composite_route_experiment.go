@@ -0,0 +1,414 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/domain"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+type CompositeRouteSplit = domain.CompositeRouteSplit
+type CompositeRouteSplitArm = domain.CompositeRouteSplitArm
+
+const (
+	CompositeRouteStickyNone   = "none"
+	CompositeRouteStickyAPIKey = "api_key"
+	CompositeRouteStickyUser   = "user"
+
+	minCompositeRouteSplitArms = 2
+	maxCompositeRouteSplitArms = 8
+	maxCompositeRouteArmWeight = 10000
+	// compositeRouteSplitBuckets 分流哈希的固定取值空间，与权重总和无关。
+	compositeRouteSplitBuckets = 10000
+
+	compositeExperimentQueueSize     = 4096
+	compositeExperimentBatchSize     = 500
+	compositeExperimentFlushInterval = 2 * time.Second
+	compositeExperimentFlushTimeout  = 5 * time.Second
+	// compositeExperimentRetention 分流记录的保留期；实验统计窗口不应超过它。
+	compositeExperimentRetention    = 90 * 24 * time.Hour
+	compositeExperimentPurgeEvery   = time.Hour
+	defaultCompositeExperimentRange = 7 * 24 * time.Hour
+)
+
+var compositeExperimentNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,99}$`)
+
+// CompositeRouteSubject 是粘连分流使用的调用方身份；零值表示匿名，按请求随机分流。
+type CompositeRouteSubject struct {
+	APIKeyID int64
+	UserID   int64
+}
+
+// NormalizeCompositeRouteSplit 校验并归一化路由的流量拆分配置；nil 表示不拆分。
+func NormalizeCompositeRouteSplit(split *CompositeRouteSplit) (*CompositeRouteSplit, error) {
+	if split == nil {
+		return nil, nil
+	}
+	out := &CompositeRouteSplit{
+		Experiment: strings.TrimSpace(split.Experiment),
+		StickyBy:   strings.ToLower(strings.TrimSpace(split.StickyBy)),
+	}
+	if !compositeExperimentNamePattern.MatchString(out.Experiment) {
+		return nil, fmt.Errorf("split experiment name must be 1-100 letters, digits, '.', '_' or '-'")
+	}
+	switch out.StickyBy {
+	case "":
+		out.StickyBy = CompositeRouteStickyNone
+	case CompositeRouteStickyNone, CompositeRouteStickyAPIKey, CompositeRouteStickyUser:
+	default:
+		return nil, fmt.Errorf("split sticky_by must be one of none, api_key, user")
+	}
+	if len(split.Arms) < minCompositeRouteSplitArms || len(split.Arms) > maxCompositeRouteSplitArms {
+		return nil, fmt.Errorf("split must have between %d and %d arms", minCompositeRouteSplitArms, maxCompositeRouteSplitArms)
+	}
+	seen := make(map[string]struct{}, len(split.Arms))
+	for _, arm := range split.Arms {
+		arm.Name = strings.TrimSpace(arm.Name)
+		arm.TargetPlatform = strings.TrimSpace(arm.TargetPlatform)
+		arm.UpstreamModel = strings.TrimSpace(arm.UpstreamModel)
+		if !compositeExperimentNamePattern.MatchString(arm.Name) {
+			return nil, fmt.Errorf("split arm name must be 1-100 letters, digits, '.', '_' or '-'")
+		}
+		key := strings.ToLower(arm.Name)
+		if _, dup := seen[key]; dup {
+			return nil, fmt.Errorf("split arm %q is duplicated", arm.Name)
+		}
+		seen[key] = struct{}{}
+		if arm.Weight <= 0 || arm.Weight > maxCompositeRouteArmWeight {
+			return nil, fmt.Errorf("split arm %q weight must be between 1 and %d", arm.Name, maxCompositeRouteArmWeight)
+		}
+		if !isConcreteRequestPlatform(arm.TargetPlatform) {
+			return nil, fmt.Errorf("split arm %q target_platform must be a concrete provider", arm.Name)
+		}
+		out.Arms = append(out.Arms, arm)
+	}
+	return out, nil
+}
+
+// pickCompositeRouteArm 按权重选择分支。粘连模式下以实验名+调用方身份哈希到固定的
+// [0, compositeRouteSplitBuckets) 空间，各分支按累计权重占比切分该空间，同一调用方在实验
+// 不变时始终落在同一分支。调整权重只移动区间边界，只有落在边界移动范围内的调用方会换分支：
+// 例如两个分支由 1:1 改为 3:1，原先在第一个分支的调用方全部保留。缺少对应身份时退化为按请求随机。
+func pickCompositeRouteArm(split *CompositeRouteSplit, subject CompositeRouteSubject) (CompositeRouteSplitArm, bool) {
+	if split == nil || len(split.Arms) == 0 {
+		return CompositeRouteSplitArm{}, false
+	}
+	total := 0
+	for _, arm := range split.Arms {
+		total += max(arm.Weight, 0)
+	}
+	if total <= 0 {
+		return CompositeRouteSplitArm{}, false
+	}
+
+	var point int
+	switch {
+	case split.StickyBy == CompositeRouteStickyAPIKey && subject.APIKeyID > 0:
+		point = compositeRouteStickyPoint(split.Experiment, "k", subject.APIKeyID)
+	case split.StickyBy == CompositeRouteStickyUser && subject.UserID > 0:
+		point = compositeRouteStickyPoint(split.Experiment, "u", subject.UserID)
+	default:
+		point = rand.IntN(compositeRouteSplitBuckets)
+	}
+	// point/buckets < cumulative/total，用整数交叉相乘避免浮点误差。
+	cumulative := 0
+	for _, arm := range split.Arms {
+		if arm.Weight <= 0 {
+			continue
+		}
+		cumulative += arm.Weight
+		if point*total < cumulative*compositeRouteSplitBuckets {
+			return arm, true
+		}
+	}
+	return split.Arms[len(split.Arms)-1], true
+}
+
+func compositeRouteStickyPoint(experiment, kind string, id int64) int {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(experiment))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(kind))
+	_, _ = h.Write([]byte(strconv.FormatInt(id, 10)))
+	return int(h.Sum64() % compositeRouteSplitBuckets)
+}
+
+// CompositeRouteAssignment 记录某个请求被分到了哪个实验分支。
+// ClientRequestID 与 usage_logs.request_id（client:<id>）及 ops_error_logs.client_request_id 对应。
+type CompositeRouteAssignment struct {
+	ClientRequestID string
+	GroupID         int64
+	RouteID         int64
+	Experiment      string
+	Arm             string
+	TargetPlatform  string
+	UpstreamModel   string
+	CreatedAt       time.Time
+}
+
+// CompositeRouteArmStat 是实验单个分支在时间窗内的聚合指标。
+// ErrorRate = ErrorCount / (SuccessCount + ErrorCount)；延迟与 token 只统计成功请求。
+type CompositeRouteArmStat struct {
+	Arm             string  `json:"arm"`
+	TargetPlatform  string  `json:"target_platform"`
+	UpstreamModel   string  `json:"upstream_model"`
+	Weight          int     `json:"weight"`
+	Assigned        int64   `json:"assigned"`
+	SuccessCount    int64   `json:"success_count"`
+	ErrorCount      int64   `json:"error_count"`
+	ErrorRate       float64 `json:"error_rate"`
+	AvgDurationMs   float64 `json:"avg_duration_ms"`
+	AvgFirstTokenMs float64 `json:"avg_first_token_ms"`
+	InputTokens     int64   `json:"input_tokens"`
+	OutputTokens    int64   `json:"output_tokens"`
+	TotalCost       float64 `json:"total_cost"`
+	ActualCost      float64 `json:"actual_cost"`
+}
+
+// CompositeRouteExperimentStats 是一个拆分实验的分支对比。
+type CompositeRouteExperimentStats struct {
+	GroupID    int64                   `json:"group_id"`
+	RouteID    int64                   `json:"route_id"`
+	Experiment string                  `json:"experiment"`
+	StartTime  time.Time               `json:"start_time"`
+	EndTime    time.Time               `json:"end_time"`
+	Arms       []CompositeRouteArmStat `json:"arms"`
+}
+
+// CompositeRouteExperimentRepository 持久化分流记录，并关联 usage_logs / ops_error_logs 聚合分支指标。
+type CompositeRouteExperimentRepository interface {
+	InsertAssignments(ctx context.Context, assignments []CompositeRouteAssignment) error
+	ListArmStats(ctx context.Context, routeID int64, experiment string, start, end time.Time) ([]CompositeRouteArmStat, error)
+	DeleteAssignmentsBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// CompositeRouteExperimentService 异步批量写入分流记录，并向管理员提供实验分支对比。
+// 记录走有界队列：队列满时丢弃并计数，绝不阻塞网关请求。
+type CompositeRouteExperimentService struct {
+	repo      CompositeRouteExperimentRepository
+	routeRepo CompositeModelRouteRepository
+
+	queue     chan CompositeRouteAssignment
+	dropped   atomic.Uint64
+	lastPurge time.Time
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stopCh    chan struct{}
+	doneCh    chan struct{}
+}
+
+// NewCompositeRouteExperimentService 创建实验服务；需调用 Start 启动后台写入。
+func NewCompositeRouteExperimentService(repo CompositeRouteExperimentRepository, routeRepo CompositeModelRouteRepository) *CompositeRouteExperimentService {
+	return &CompositeRouteExperimentService{
+		repo:      repo,
+		routeRepo: routeRepo,
+		queue:     make(chan CompositeRouteAssignment, compositeExperimentQueueSize),
+		stopCh:    make(chan struct{}),
+		doneCh:    make(chan struct{}),
+	}
+}
+
+// Record 登记一次命中拆分路由的分流结果；没有客户端请求 ID 时无法关联用量，直接忽略。
+func (s *CompositeRouteExperimentService) Record(ctx context.Context, decision CompositeRouteDecision) {
+	if s == nil || decision.Experiment == "" || decision.Route == nil || ctx == nil {
+		return
+	}
+	clientRequestID, _ := ctx.Value(ctxkey.ClientRequestID).(string)
+	clientRequestID = strings.TrimSpace(clientRequestID)
+	if clientRequestID == "" {
+		return
+	}
+	assignment := CompositeRouteAssignment{
+		ClientRequestID: clientRequestID,
+		GroupID:         decision.GroupID,
+		RouteID:         decision.Route.ID,
+		Experiment:      decision.Experiment,
+		Arm:             decision.Arm,
+		TargetPlatform:  decision.TargetPlatform,
+		UpstreamModel:   decision.UpstreamModel,
+		CreatedAt:       time.Now(),
+	}
+	select {
+	case s.queue <- assignment:
+	default:
+		if s.dropped.Add(1)%1000 == 1 {
+			logger.L().Warn("composite_experiment.assignment_queue_full", zap.Uint64("dropped_total", s.dropped.Load()))
+		}
+	}
+}
+
+// Start 启动后台批量写入。
+func (s *CompositeRouteExperimentService) Start() {
+	if s == nil || s.repo == nil {
+		return
+	}
+	s.startOnce.Do(func() {
+		go s.run()
+	})
+}
+
+// Stop 停止后台写入，并尽量落盘队列中剩余的记录。
+func (s *CompositeRouteExperimentService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+		started := true
+		s.startOnce.Do(func() { started = false })
+		if started {
+			<-s.doneCh
+		}
+	})
+}
+
+func (s *CompositeRouteExperimentService) run() {
+	defer close(s.doneCh)
+	ticker := time.NewTicker(compositeExperimentFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]CompositeRouteAssignment, 0, compositeExperimentBatchSize)
+	for {
+		select {
+		case assignment := <-s.queue:
+			batch = append(batch, assignment)
+			if len(batch) >= compositeExperimentBatchSize {
+				batch = s.flush(batch)
+			}
+		case <-ticker.C:
+			batch = s.flush(batch)
+			s.purgeExpired()
+		case <-s.stopCh:
+			for {
+				select {
+				case assignment := <-s.queue:
+					batch = append(batch, assignment)
+					if len(batch) >= compositeExperimentBatchSize {
+						batch = s.flush(batch)
+					}
+				default:
+					s.flush(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+func (s *CompositeRouteExperimentService) flush(batch []CompositeRouteAssignment) []CompositeRouteAssignment {
+	if len(batch) == 0 {
+		return batch
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), compositeExperimentFlushTimeout)
+	defer cancel()
+	if err := s.repo.InsertAssignments(ctx, batch); err != nil {
+		logger.L().Warn("composite_experiment.assignment_flush_failed", zap.Int("count", len(batch)), zap.Error(err))
+	}
+	return batch[:0]
+}
+
+func (s *CompositeRouteExperimentService) purgeExpired() {
+	now := time.Now()
+	if now.Sub(s.lastPurge) < compositeExperimentPurgeEvery {
+		return
+	}
+	s.lastPurge = now
+	ctx, cancel := context.WithTimeout(context.Background(), compositeExperimentFlushTimeout)
+	defer cancel()
+	if _, err := s.repo.DeleteAssignmentsBefore(ctx, now.Add(-compositeExperimentRetention)); err != nil {
+		logger.L().Warn("composite_experiment.assignment_purge_failed", zap.Error(err))
+	}
+}
+
+// Stats 返回拆分路由在 [start, end) 内各分支的指标；未指定时间时默认最近 7 天。
+// 没有流量的分支也会列出，便于确认权重是否生效。
+func (s *CompositeRouteExperimentService) Stats(ctx context.Context, groupID, routeID int64, start, end time.Time) (*CompositeRouteExperimentStats, error) {
+	if s == nil || s.repo == nil || s.routeRepo == nil {
+		return nil, fmt.Errorf("composite route experiment service is not configured")
+	}
+	routes, err := s.routeRepo.ListByGroup(ctx, groupID, true)
+	if err != nil {
+		return nil, err
+	}
+	var route *CompositeModelRoute
+	for i := range routes {
+		if routes[i].ID == routeID {
+			route = &routes[i]
+			break
+		}
+	}
+	if route == nil {
+		return nil, ErrCompositeRouteNotFound
+	}
+	if route.Split == nil {
+		return nil, ErrCompositeRouteNoExperiment
+	}
+	if end.IsZero() {
+		end = time.Now()
+	}
+	if start.IsZero() {
+		start = end.Add(-defaultCompositeExperimentRange)
+	}
+	if !start.Before(end) {
+		return nil, ErrCompositeRouteInvalidRange
+	}
+
+	rows, err := s.repo.ListArmStats(ctx, routeID, route.Split.Experiment, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return &CompositeRouteExperimentStats{
+		GroupID:    groupID,
+		RouteID:    routeID,
+		Experiment: route.Split.Experiment,
+		StartTime:  start,
+		EndTime:    end,
+		Arms:       mergeCompositeRouteArmStats(route.Split, rows),
+	}, nil
+}
+
+// mergeCompositeRouteArmStats 按当前配置的分支顺序输出，补上无流量分支；
+// 已从配置移除但窗口内仍有记录的分支排在最后。
+func mergeCompositeRouteArmStats(split *CompositeRouteSplit, rows []CompositeRouteArmStat) []CompositeRouteArmStat {
+	byArm := make(map[string]CompositeRouteArmStat, len(rows))
+	for _, row := range rows {
+		byArm[row.Arm] = row
+	}
+	out := make([]CompositeRouteArmStat, 0, len(split.Arms)+len(rows))
+	for _, arm := range split.Arms {
+		stat, ok := byArm[arm.Name]
+		if !ok {
+			stat = CompositeRouteArmStat{Arm: arm.Name}
+		}
+		delete(byArm, arm.Name)
+		stat.TargetPlatform = arm.TargetPlatform
+		stat.UpstreamModel = arm.UpstreamModel
+		stat.Weight = arm.Weight
+		out = append(out, finalizeCompositeRouteArmStat(stat))
+	}
+	for _, row := range rows {
+		if _, ok := byArm[row.Arm]; ok {
+			out = append(out, finalizeCompositeRouteArmStat(row))
+		}
+	}
+	return out
+}
+
+func finalizeCompositeRouteArmStat(stat CompositeRouteArmStat) CompositeRouteArmStat {
+	if finished := stat.SuccessCount + stat.ErrorCount; finished > 0 {
+		stat.ErrorRate = float64(stat.ErrorCount) / float64(finished)
+	}
+	return stat
+}
@@ -0,0 +1,234 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/stretchr/testify/require"
+)
+
+type compositeExperimentRepoStub struct {
+	mu       sync.Mutex
+	inserted []CompositeRouteAssignment
+	stats    []CompositeRouteArmStat
+}
+
+func (s *compositeExperimentRepoStub) InsertAssignments(_ context.Context, assignments []CompositeRouteAssignment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inserted = append(s.inserted, assignments...)
+	return nil
+}
+
+func (s *compositeExperimentRepoStub) ListArmStats(context.Context, int64, string, time.Time, time.Time) ([]CompositeRouteArmStat, error) {
+	return s.stats, nil
+}
+
+func (s *compositeExperimentRepoStub) DeleteAssignmentsBefore(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+
+func newTestCompositeRouteSplit(stickyBy string) *CompositeRouteSplit {
+	return &CompositeRouteSplit{
+		Experiment: "gpt5-vs-sonnet",
+		StickyBy:   stickyBy,
+		Arms: []CompositeRouteSplitArm{
+			{Name: "control", Weight: 90, TargetPlatform: PlatformOpenAI, UpstreamModel: "gpt-5"},
+			{Name: "candidate", Weight: 10, TargetPlatform: PlatformAnthropic, UpstreamModel: "claude-sonnet-4-5"},
+		},
+	}
+}
+
+func TestNormalizeCompositeRouteSplit(t *testing.T) {
+	split, err := NormalizeCompositeRouteSplit(nil)
+	require.NoError(t, err)
+	require.Nil(t, split)
+
+	split, err = NormalizeCompositeRouteSplit(&CompositeRouteSplit{
+		Experiment: " exp-1 ",
+		Arms: []CompositeRouteSplitArm{
+			{Name: " a ", Weight: 1, TargetPlatform: PlatformOpenAI},
+			{Name: "b", Weight: 3, TargetPlatform: PlatformGemini, UpstreamModel: " gemini-2.5-pro "},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "exp-1", split.Experiment)
+	require.Equal(t, CompositeRouteStickyNone, split.StickyBy)
+	require.Equal(t, "a", split.Arms[0].Name)
+	require.Equal(t, "gemini-2.5-pro", split.Arms[1].UpstreamModel)
+
+	for name, bad := range map[string]*CompositeRouteSplit{
+		"missing experiment": {Arms: newTestCompositeRouteSplit("").Arms},
+		"single arm":         {Experiment: "x", Arms: newTestCompositeRouteSplit("").Arms[:1]},
+		"bad sticky":         {Experiment: "x", StickyBy: "ip", Arms: newTestCompositeRouteSplit("").Arms},
+		"zero weight": {Experiment: "x", Arms: []CompositeRouteSplitArm{
+			{Name: "a", Weight: 0, TargetPlatform: PlatformOpenAI},
+			{Name: "b", Weight: 1, TargetPlatform: PlatformOpenAI},
+		}},
+		"duplicate arm": {Experiment: "x", Arms: []CompositeRouteSplitArm{
+			{Name: "A", Weight: 1, TargetPlatform: PlatformOpenAI},
+			{Name: "a", Weight: 1, TargetPlatform: PlatformOpenAI},
+		}},
+		"composite target": {Experiment: "x", Arms: []CompositeRouteSplitArm{
+			{Name: "a", Weight: 1, TargetPlatform: PlatformOpenAI},
+			{Name: "b", Weight: 1, TargetPlatform: PlatformComposite},
+		}},
+	} {
+		_, err := NormalizeCompositeRouteSplit(bad)
+		require.Error(t, err, name)
+	}
+}
+
+func TestPickCompositeRouteArm_StickyPinsCallerAndSpreadsCallers(t *testing.T) {
+	split := newTestCompositeRouteSplit(CompositeRouteStickyAPIKey)
+	split.Arms[0].Weight, split.Arms[1].Weight = 50, 50
+
+	first, ok := pickCompositeRouteArm(split, CompositeRouteSubject{APIKeyID: 42})
+	require.True(t, ok)
+	for i := 0; i < 50; i++ {
+		arm, _ := pickCompositeRouteArm(split, CompositeRouteSubject{APIKeyID: 42, UserID: int64(i)})
+		require.Equal(t, first.Name, arm.Name, "same API key always lands on the same arm")
+	}
+
+	seen := map[string]int{}
+	for id := int64(1); id <= 200; id++ {
+		arm, _ := pickCompositeRouteArm(split, CompositeRouteSubject{APIKeyID: id})
+		seen[arm.Name]++
+	}
+	require.Len(t, seen, 2, "different API keys are spread across arms")
+
+	split.StickyBy = CompositeRouteStickyUser
+	byUser, _ := pickCompositeRouteArm(split, CompositeRouteSubject{APIKeyID: 1, UserID: 9})
+	for id := int64(2); id < 20; id++ {
+		arm, _ := pickCompositeRouteArm(split, CompositeRouteSubject{APIKeyID: id, UserID: 9})
+		require.Equal(t, byUser.Name, arm.Name, "user stickiness ignores which key is used")
+	}
+}
+
+func TestPickCompositeRouteArm_StickyWeightChangeKeepsExistingArm(t *testing.T) {
+	split := newTestCompositeRouteSplit(CompositeRouteStickyAPIKey)
+	split.Arms[0].Weight, split.Arms[1].Weight = 1, 1
+	before := map[int64]string{}
+	for id := int64(1); id <= 2000; id++ {
+		arm, _ := pickCompositeRouteArm(split, CompositeRouteSubject{APIKeyID: id})
+		before[id] = arm.Name
+	}
+
+	// 1:1 改为 3:1：第一个分支的区间只会扩大，原有调用方不迁移，只有部分 candidate 调用方移入。
+	split.Arms[0].Weight = 3
+	moved, control := 0, 0
+	for id, name := range before {
+		arm, _ := pickCompositeRouteArm(split, CompositeRouteSubject{APIKeyID: id})
+		if name == "control" {
+			require.Equal(t, "control", arm.Name, "api key %d left the arm it was on", id)
+		} else if arm.Name != name {
+			moved++
+		}
+		if arm.Name == "control" {
+			control++
+		}
+	}
+	require.Positive(t, moved)
+	require.InDelta(t, 1500, control, 150)
+}
+
+func TestPickCompositeRouteArm_RandomFollowsWeights(t *testing.T) {
+	split := newTestCompositeRouteSplit(CompositeRouteStickyNone)
+	counts := map[string]int{}
+	for i := 0; i < 10000; i++ {
+		arm, ok := pickCompositeRouteArm(split, CompositeRouteSubject{APIKeyID: 1})
+		require.True(t, ok)
+		counts[arm.Name]++
+	}
+	require.InDelta(t, 1000, counts["candidate"], 250)
+
+	// 粘连维度缺失时退化为按请求随机，仍只返回已配置的分支。
+	split.StickyBy = CompositeRouteStickyUser
+	arm, ok := pickCompositeRouteArm(split, CompositeRouteSubject{})
+	require.True(t, ok)
+	require.Contains(t, []string{"control", "candidate"}, arm.Name)
+
+	_, ok = pickCompositeRouteArm(nil, CompositeRouteSubject{})
+	require.False(t, ok)
+}
+
+func TestCompositeRouteResolver_SplitRouteSelectsArm(t *testing.T) {
+	split := newTestCompositeRouteSplit(CompositeRouteStickyAPIKey)
+	split.Arms[1].UpstreamModel = ""
+	resolver := NewCompositeRouteResolver(compositeRouteRepoStub{routes: []CompositeModelRoute{{
+		ID:             11,
+		GroupID:        7,
+		PublicModel:    "smart",
+		MatchType:      CompositeRouteMatchExact,
+		TargetPlatform: PlatformOpenAI,
+		UpstreamModel:  "gpt-5",
+		Endpoint:       CompositeRouteEndpointAny,
+		Enabled:        true,
+		Split:          split,
+	}}})
+
+	arms := map[string]CompositeRouteDecision{}
+	for id := int64(1); id <= 100; id++ {
+		decision, err := resolver.ResolveFor(context.Background(), 7, "smart", CompositeRouteEndpointChatCompletions, CompositeRouteSubject{APIKeyID: id})
+		require.NoError(t, err)
+		require.True(t, decision.Matched)
+		require.Equal(t, "gpt5-vs-sonnet", decision.Experiment)
+		arms[decision.Arm] = decision
+	}
+	require.Equal(t, PlatformOpenAI, arms["control"].TargetPlatform)
+	require.Equal(t, "gpt-5", arms["control"].UpstreamModel)
+	require.Equal(t, PlatformAnthropic, arms["candidate"].TargetPlatform)
+	require.Equal(t, "smart", arms["candidate"].UpstreamModel, "empty arm upstream_model forwards the requested model")
+}
+
+func TestCompositeRouteExperimentService_RecordsAssignmentsAndMergesStats(t *testing.T) {
+	repo := &compositeExperimentRepoStub{stats: []CompositeRouteArmStat{
+		{Arm: "control", Assigned: 10, SuccessCount: 6, ErrorCount: 2, AvgDurationMs: 800},
+		{Arm: "retired", Assigned: 3, SuccessCount: 3},
+	}}
+	split := newTestCompositeRouteSplit(CompositeRouteStickyNone)
+	svc := NewCompositeRouteExperimentService(repo, compositeRouteRepoStub{routes: []CompositeModelRoute{
+		{ID: 11, GroupID: 7, PublicModel: "smart", Enabled: true, Split: split},
+		{ID: 12, GroupID: 7, PublicModel: "plain", Enabled: true},
+	}})
+	svc.Start()
+
+	decision := CompositeRouteDecision{
+		Matched:        true,
+		GroupID:        7,
+		TargetPlatform: PlatformAnthropic,
+		UpstreamModel:  "claude-sonnet-4-5",
+		Route:          &CompositeModelRoute{ID: 11},
+		Experiment:     split.Experiment,
+		Arm:            "candidate",
+	}
+	ctx := context.WithValue(context.Background(), ctxkey.ClientRequestID, "req-1")
+	svc.Record(ctx, decision)
+	svc.Record(context.Background(), decision)                         // 无客户端请求 ID，无法关联用量
+	svc.Record(ctx, CompositeRouteDecision{Matched: true, GroupID: 7}) // 非拆分路由
+	svc.Stop()
+
+	require.Len(t, repo.inserted, 1, "Stop flushes queued assignments")
+	require.Equal(t, "req-1", repo.inserted[0].ClientRequestID)
+	require.Equal(t, "candidate", repo.inserted[0].Arm)
+	require.EqualValues(t, 11, repo.inserted[0].RouteID)
+
+	stats, err := svc.Stats(context.Background(), 7, 11, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Equal(t, "gpt5-vs-sonnet", stats.Experiment)
+	require.Equal(t, []string{"control", "candidate", "retired"}, []string{stats.Arms[0].Arm, stats.Arms[1].Arm, stats.Arms[2].Arm})
+	require.InDelta(t, 0.25, stats.Arms[0].ErrorRate, 1e-9)
+	require.Equal(t, 90, stats.Arms[0].Weight)
+	require.Zero(t, stats.Arms[1].Assigned, "arms without traffic are still listed")
+	require.Equal(t, PlatformAnthropic, stats.Arms[1].TargetPlatform)
+
+	_, err = svc.Stats(context.Background(), 7, 12, time.Time{}, time.Time{})
+	require.ErrorIs(t, err, ErrCompositeRouteNoExperiment)
+	_, err = svc.Stats(context.Background(), 7, 99, time.Time{}, time.Time{})
+	require.ErrorIs(t, err, ErrCompositeRouteNotFound)
+}
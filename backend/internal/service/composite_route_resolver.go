@@ -8,14 +8,33 @@ import (
 )
 
 type CompositeRouteResolver struct {
-	repo CompositeModelRouteRepository
+	repo        CompositeModelRouteRepository
+	experiments *CompositeRouteExperimentService
 }
 
 func NewCompositeRouteResolver(repo CompositeModelRouteRepository) *CompositeRouteResolver {
 	return &CompositeRouteResolver{repo: repo}
 }
 
+// SetExperimentService attaches the recorder for traffic-split assignments.
+func (r *CompositeRouteResolver) SetExperimentService(experiments *CompositeRouteExperimentService) {
+	r.experiments = experiments
+}
+
+// RecordExperimentAssignment 登记网关实际采用的拆分分支；预览与降级链预解析不应调用。
+func (r *CompositeRouteResolver) RecordExperimentAssignment(ctx context.Context, decision CompositeRouteDecision) {
+	if r == nil || r.experiments == nil {
+		return
+	}
+	r.experiments.Record(ctx, decision)
+}
+
 func (r *CompositeRouteResolver) Resolve(ctx context.Context, groupID int64, model, endpoint string) (CompositeRouteDecision, error) {
+	return r.ResolveFor(ctx, groupID, model, endpoint, CompositeRouteSubject{})
+}
+
+// ResolveFor 与 Resolve 相同，但命中拆分路由时按 subject 做粘连分流。
+func (r *CompositeRouteResolver) ResolveFor(ctx context.Context, groupID int64, model, endpoint string, subject CompositeRouteSubject) (CompositeRouteDecision, error) {
 	model = strings.TrimSpace(model)
 	endpoint = normalizeCompositeRouteEndpoint(endpoint)
 	decision := CompositeRouteDecision{
@@ -34,7 +53,14 @@ func (r *CompositeRouteResolver) Resolve(ctx context.Context, groupID int64, mod
 			return decision, fmt.Errorf("list composite routes: %w", err)
 		}
 		if route, ok := matchCompositeRoute(routes, model, endpoint); ok {
+			targetPlatform := route.TargetPlatform
 			upstreamModel := strings.TrimSpace(route.UpstreamModel)
+			var experiment, armName string
+			if arm, ok := pickCompositeRouteArm(route.Split, subject); ok {
+				targetPlatform = arm.TargetPlatform
+				upstreamModel = strings.TrimSpace(arm.UpstreamModel)
+				experiment, armName = route.Split.Experiment, arm.Name
+			}
 			if upstreamModel == "" {
 				upstreamModel = model
 			}
@@ -43,10 +69,12 @@ func (r *CompositeRouteResolver) Resolve(ctx context.Context, groupID int64, mod
 				Source:         CompositeRouteSourceExplicit,
 				GroupID:        groupID,
 				PublicModel:    model,
-				TargetPlatform: route.TargetPlatform,
+				TargetPlatform: targetPlatform,
 				UpstreamModel:  upstreamModel,
 				Endpoint:       endpoint,
 				Route:          &route,
+				Experiment:     experiment,
+				Arm:            armName,
 			}, nil
 		}
 	}
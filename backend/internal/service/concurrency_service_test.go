@@ -34,6 +34,8 @@ type stubConcurrencyCacheForTest struct {
 	apiKeyReleaseErr     error
 	apiKeyConcurrency    map[int64]int
 	apiKeyConcurrencyErr error
+	apiKeyAcquireResult  bool
+	apiKeyAcquireErr     error
 
 	// 记录调用
 	releasedAccountIDs       []int64
@@ -43,6 +45,8 @@ type stubConcurrencyCacheForTest struct {
 	trackedAPIKeyRequestIDs  []string
 	releasedAPIKeyIDs        []int64
 	releasedAPIKeyRequestIDs []string
+	acquiredAPIKeyIDs        []int64
+	acquiredAPIKeyMax        []int
 }
 
 type ingressLeaseCacheForTest struct {
@@ -131,6 +135,11 @@ func (c *stubConcurrencyCacheForTest) TrackAPIKeySlot(_ context.Context, apiKeyI
 	c.trackedAPIKeyRequestIDs = append(c.trackedAPIKeyRequestIDs, requestID)
 	return c.apiKeyTrackErr
 }
+func (c *stubConcurrencyCacheForTest) AcquireAPIKeySlot(_ context.Context, apiKeyID int64, maxConcurrency int, _ string) (bool, error) {
+	c.acquiredAPIKeyIDs = append(c.acquiredAPIKeyIDs, apiKeyID)
+	c.acquiredAPIKeyMax = append(c.acquiredAPIKeyMax, maxConcurrency)
+	return c.apiKeyAcquireResult, c.apiKeyAcquireErr
+}
 func (c *stubConcurrencyCacheForTest) ReleaseAPIKeySlot(_ context.Context, apiKeyID int64, requestID string) error {
 	c.releasedAPIKeyIDs = append(c.releasedAPIKeyIDs, apiKeyID)
 	c.releasedAPIKeyRequestIDs = append(c.releasedAPIKeyRequestIDs, requestID)
@@ -297,6 +306,41 @@ func TestTrackAPIKeySlot_FailOpen(t *testing.T) {
 	require.Empty(t, cache.releasedAPIKeyIDs)
 }
 
+func TestAcquireAPIKeySlot_EnforcesLimit(t *testing.T) {
+	cache := &stubConcurrencyCacheForTest{apiKeyAcquireResult: true}
+	svc := NewConcurrencyService(cache)
+
+	result, err := svc.AcquireAPIKeySlot(context.Background(), 88, 3)
+	require.NoError(t, err)
+	require.True(t, result.Acquired)
+	require.Equal(t, []int64{88}, cache.acquiredAPIKeyIDs)
+	require.Equal(t, []int{3}, cache.acquiredAPIKeyMax)
+	require.Empty(t, cache.trackedAPIKeyIDs)
+
+	result.ReleaseFunc()
+	require.Equal(t, []int64{88}, cache.releasedAPIKeyIDs)
+
+	cache.apiKeyAcquireResult = false
+	result, err = svc.AcquireAPIKeySlot(context.Background(), 88, 3)
+	require.NoError(t, err)
+	require.False(t, result.Acquired)
+	require.Nil(t, result.ReleaseFunc)
+}
+
+func TestAcquireAPIKeySlot_UnlimitedOnlyTracks(t *testing.T) {
+	cache := &stubConcurrencyCacheForTest{}
+	svc := NewConcurrencyService(cache)
+
+	result, err := svc.AcquireAPIKeySlot(context.Background(), 88, 0)
+	require.NoError(t, err)
+	require.True(t, result.Acquired)
+	require.Empty(t, cache.acquiredAPIKeyIDs)
+	require.Equal(t, []int64{88}, cache.trackedAPIKeyIDs)
+
+	result.ReleaseFunc()
+	require.Equal(t, []int64{88}, cache.releasedAPIKeyIDs)
+}
+
 func TestGetAPIKeyConcurrencyBatch_Fallbacks(t *testing.T) {
 	t.Run("nil cache returns zeroes", func(t *testing.T) {
 		svc := &ConcurrencyService{cache: nil}
@@ -24,6 +24,8 @@ import (
 	"github.com/Wei-Shaw/sub2api/internal/pkg/httpclient"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/servertiming"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const (
@@ -812,6 +814,24 @@ func (s *ContentModerationService) TestAPIKeys(ctx context.Context, input TestCo
 }
 
 func (s *ContentModerationService) Check(ctx context.Context, input ContentModerationCheckInput) (*ContentModerationDecision, error) {
+	ctx, span := tracing.Start(ctx, "gateway.content_moderation",
+		tracing.AttrAPIKeyID.Int64(input.APIKeyID),
+		tracing.AttrModel.String(input.Model),
+		attribute.String("sub2api.moderation.protocol", input.Protocol),
+	)
+	decision, err := s.check(ctx, input)
+	if decision != nil {
+		span.SetAttributes(
+			attribute.Bool("sub2api.moderation.blocked", decision.Blocked),
+			attribute.Bool("sub2api.moderation.flagged", decision.Flagged),
+			attribute.String("sub2api.moderation.action", decision.Action),
+		)
+	}
+	tracing.End(span, err)
+	return decision, err
+}
+
+func (s *ContentModerationService) check(ctx context.Context, input ContentModerationCheckInput) (*ContentModerationDecision, error) {
 	allow := &ContentModerationDecision{Allowed: true, Action: ContentModerationActionAllow}
 	if s == nil || s.settingRepo == nil || s.repo == nil {
 		slog.Info("content_moderation.skip_unavailable",
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+)
+
+// 后付费账单状态。
+const (
+	CreditStatementStatusIssued  = "issued"
+	CreditStatementStatusOverdue = "overdue"
+	CreditStatementStatusPaid    = "paid"
+)
+
+// creditStatementPaidEpsilon 吸收 decimal(20,8) 与 float64 往返带来的误差。
+const creditStatementPaidEpsilon = 1e-6
+
+var (
+	ErrCreditStatementNotFound = infraerrors.New(http.StatusNotFound, "CREDIT_STATEMENT_NOT_FOUND", "credit statement not found")
+	ErrInvalidCreditLimit      = infraerrors.BadRequest("INVALID_CREDIT_LIMIT", "credit_limit must be a non-negative number")
+	// ErrCreditStatementOverdue 用户存在逾期账单时拒绝新建或重新启用 API Key。
+	ErrCreditStatementOverdue = infraerrors.New(http.StatusPaymentRequired, "CREDIT_STATEMENT_OVERDUE", "a credit statement is overdue; API keys stay suspended until it is paid")
+)
+
+// CreditStatementLine 账单周期内按分组与模型汇总的余额扣费用量。
+type CreditStatementLine struct {
+	GroupID      *int64  `json:"group_id,omitempty"`
+	GroupName    string  `json:"group_name"`
+	Model        string  `json:"model"`
+	Requests     int64   `json:"requests"`
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	Cost         float64 `json:"cost"`
+}
+
+// CreditStatement 授信用户的月度账单。生成后金额不再变化，只有状态会随结清/逾期推进。
+type CreditStatement struct {
+	ID             int64                 `json:"id"`
+	UserID         int64                 `json:"user_id"`
+	UserEmail      string                `json:"user_email,omitempty"`
+	PeriodStart    time.Time             `json:"period_start"`
+	PeriodEnd      time.Time             `json:"period_end"`
+	RequestCount   int64                 `json:"request_count"`
+	TotalCost      float64               `json:"total_cost"`
+	CreditLimit    float64               `json:"credit_limit"`
+	ClosingBalance float64               `json:"closing_balance"`
+	AmountDue      float64               `json:"amount_due"`
+	DueAt          time.Time             `json:"due_at"`
+	Status         string                `json:"status"`
+	Lines          []CreditStatementLine `json:"lines,omitempty"`
+	PaidAt         *time.Time            `json:"paid_at,omitempty"`
+	OverdueAt      *time.Time            `json:"overdue_at,omitempty"`
+	CreatedAt      time.Time             `json:"created_at"`
+}
+
+// CreditStatementFilter 账单列表筛选条件；UserID 为 0 表示不限用户。
+type CreditStatementFilter struct {
+	UserID   int64
+	Status   string
+	Page     int
+	PageSize int
+}
+
+// CreditLineUser 需要生成账单的授信用户。Balance 为账单周期结束时刻的余额。
+type CreditLineUser struct {
+	ID          int64
+	Email       string
+	Username    string
+	Balance     float64
+	CreditLimit float64
+}
+
+// CreditStatementPaymentLedgerTypes 计为账单还款的余额账本流水类型；
+// 退款及其回滚、管理员扣减按带符号的余额变动抵减。
+var CreditStatementPaymentLedgerTypes = []string{
+	BalanceLedgerTypeRecharge,
+	BalanceLedgerTypeRedeem,
+	BalanceLedgerTypeRefund,
+	BalanceLedgerTypeRefundRollback,
+	BalanceLedgerTypeAdminAdjustment,
+}
+
+// CreditStatementRunResult 一次账单任务的处理统计。
+type CreditStatementRunResult struct {
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+	Issued      int       `json:"issued"`
+	Paid        int       `json:"paid"`
+	Overdue     int       `json:"overdue"`
+	// SuspendedKeys / ResumedKeys 本次挂起与恢复的 API Key 数量。
+	SuspendedKeys int64 `json:"suspended_keys"`
+	ResumedKeys   int64 `json:"resumed_keys"`
+}
+
+type CreditStatementRepository interface {
+	// ListCreditUsers 返回 credit_limit > 0 且未删除的用户；Balance 为 asOf 时刻的余额，
+	// 由当前余额减去 asOf 之后的余额账本变动回推（账本上线时的期初流水除外）。
+	ListCreditUsers(ctx context.Context, asOf time.Time) ([]CreditLineUser, error)
+	// SummarizeUsage 汇总用户在 [start, end) 内余额扣费（billing_type = 0）的用量。
+	SummarizeUsage(ctx context.Context, userID int64, start, end time.Time) ([]CreditStatementLine, error)
+	// Create 写入账单；同一用户同一周期已存在时返回 (nil, nil)。
+	Create(ctx context.Context, st *CreditStatement) (*CreditStatement, error)
+	GetByID(ctx context.Context, id int64) (*CreditStatement, error)
+	List(ctx context.Context, filter CreditStatementFilter) ([]CreditStatement, int64, error)
+	// ListOpen 返回 issued / overdue 的账单，按用户与周期升序。
+	ListOpen(ctx context.Context) ([]CreditStatement, error)
+	// PaymentsSince 返回用户自 since 起 CreditStatementPaymentLedgerTypes 流水的余额变动合计。
+	PaymentsSince(ctx context.Context, userID int64, since time.Time) (float64, error)
+	MarkPaid(ctx context.Context, id int64, paidAt time.Time) error
+	MarkOverdue(ctx context.Context, id int64, overdueAt time.Time) error
+	HasOverdue(ctx context.Context, userID int64) (bool, error)
+	// TransitionAPIKeys 把用户处于 from 状态的 API Key 改为 to，返回受影响的 Key 数量。
+	TransitionAPIKeys(ctx context.Context, userID int64, from, to string) (int64, error)
+}
+
+// CreditStatementGuard 供 APIKeyService 查询用户是否存在逾期账单。
+type CreditStatementGuard interface {
+	HasOverdueStatement(ctx context.Context, userID int64) (bool, error)
+}
+
+// creditStatementPeriod 返回 now 所在自然月（系统时区）的上一个完整月份。
+func creditStatementPeriod(now time.Time) (start, end time.Time) {
+	end = timezone.StartOfMonth(now)
+	start = end.AddDate(0, -1, 0)
+	return start, end
+}
+
+// creditStatementAmountDue 期末余额为负的部分即为应付金额。
+func creditStatementAmountDue(closingBalance float64) float64 {
+	if closingBalance >= 0 {
+		return 0
+	}
+	return math.Round(-closingBalance*1e8) / 1e8
+}
+
+// creditStatementSettled 判断账单是否已结清：周期结束后账本记录的还款（充值、兑换、退款、管理员调整）
+// 覆盖应付金额即视为结清。用量不参与计算，新产生的用量计入下一期账单。
+func creditStatementSettled(st *CreditStatement, payments float64) bool {
+	if st == nil {
+		return false
+	}
+	if st.AmountDue <= 0 {
+		return true
+	}
+	return payments+creditStatementPaidEpsilon >= st.AmountDue
+}
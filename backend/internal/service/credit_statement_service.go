@@ -0,0 +1,366 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	creditStatementLeaderLockKey = "credit_line:statements:leader"
+	// creditStatementLeaderLockTTL 需覆盖单次运行超时，避免运行中锁过期。
+	creditStatementLeaderLockTTL = 20 * time.Minute
+	creditStatementRunTimeout    = 15 * time.Minute
+	creditStatementStopTimeout   = 3 * time.Second
+	creditStatementEmailTimeout  = 30 * time.Second
+)
+
+var creditStatementCronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+
+// CreditStatementService 管理后付费授信账单：
+//   - 每个自然月结束后为授信用户生成上月账单并邮件通知；
+//   - 账单结清（周期结束后的还款覆盖应付金额）后标记 paid，并恢复被挂起的 API Key；
+//   - 超过付款期限仍未结清时标记 overdue，挂起该用户所有 active 的 API Key 并邮件催缴。
+//
+// 定时任务每次运行都会补生成缺失的账单，因此错过的运行不会漏账。
+type CreditStatementService struct {
+	repo                 CreditStatementRepository
+	userRepo             UserRepository
+	notificationEmail    *NotificationEmailService
+	authCacheInvalidator APIKeyAuthCacheInvalidator
+	cfg                  *config.Config
+
+	lockCache  LeaderLockCache
+	db         *sql.DB
+	instanceID string
+
+	mu      sync.Mutex
+	cron    *cron.Cron
+	stopped bool
+}
+
+func NewCreditStatementService(
+	repo CreditStatementRepository,
+	userRepo UserRepository,
+	notificationEmail *NotificationEmailService,
+	authCacheInvalidator APIKeyAuthCacheInvalidator,
+	cfg *config.Config,
+) *CreditStatementService {
+	return &CreditStatementService{
+		repo:                 repo,
+		userRepo:             userRepo,
+		notificationEmail:    notificationEmail,
+		authCacheInvalidator: authCacheInvalidator,
+		cfg:                  cfg,
+		instanceID:           uuid.NewString(),
+	}
+}
+
+// SetLeaderLock 注入 leader 锁；两者均为 nil 时不做选主（单实例/测试）。
+func (s *CreditStatementService) SetLeaderLock(lockCache LeaderLockCache, db *sql.DB) {
+	if s == nil {
+		return
+	}
+	s.lockCache = lockCache
+	s.db = db
+}
+
+func (s *CreditStatementService) dueDays() int {
+	if s == nil || s.cfg == nil || s.cfg.CreditLine.DueDays < 0 {
+		return 0
+	}
+	return s.cfg.CreditLine.DueDays
+}
+
+// Run 立即执行一次：生成上一自然月缺失的账单，再处理所有未结清账单的结清与逾期。
+func (s *CreditStatementService) Run(ctx context.Context) (*CreditStatementRunResult, error) {
+	now := timezone.Now()
+	result := &CreditStatementRunResult{}
+	result.PeriodStart, result.PeriodEnd = creditStatementPeriod(now)
+	if err := s.generate(ctx, now, result); err != nil {
+		return result, err
+	}
+	if err := s.processOpen(ctx, now, result); err != nil {
+		return result, err
+	}
+	if result.Issued > 0 || result.Paid > 0 || result.Overdue > 0 {
+		slog.Info("[CreditLine] statement run finished",
+			"issued", result.Issued,
+			"paid", result.Paid,
+			"overdue", result.Overdue,
+			"suspended_keys", result.SuspendedKeys,
+			"resumed_keys", result.ResumedKeys)
+	}
+	return result, nil
+}
+
+func (s *CreditStatementService) generate(ctx context.Context, now time.Time, result *CreditStatementRunResult) error {
+	// 期末余额取周期结束时刻的快照，与任务实际运行时间无关。
+	users, err := s.repo.ListCreditUsers(ctx, result.PeriodEnd)
+	if err != nil {
+		return fmt.Errorf("list credit users: %w", err)
+	}
+	for i := range users {
+		u := users[i]
+		lines, err := s.repo.SummarizeUsage(ctx, u.ID, result.PeriodStart, result.PeriodEnd)
+		if err != nil {
+			return fmt.Errorf("summarize usage for user %d: %w", u.ID, err)
+		}
+		st := &CreditStatement{
+			UserID:         u.ID,
+			PeriodStart:    result.PeriodStart,
+			PeriodEnd:      result.PeriodEnd,
+			CreditLimit:    u.CreditLimit,
+			ClosingBalance: u.Balance,
+			AmountDue:      creditStatementAmountDue(u.Balance),
+			DueAt:          result.PeriodEnd.AddDate(0, 0, s.dueDays()),
+			Status:         CreditStatementStatusIssued,
+			Lines:          lines,
+		}
+		for _, line := range lines {
+			st.RequestCount += line.Requests
+			st.TotalCost += line.Cost
+		}
+		if st.AmountDue <= 0 {
+			st.Status = CreditStatementStatusPaid
+			st.PaidAt = &now
+		}
+		created, err := s.repo.Create(ctx, st)
+		if err != nil {
+			return fmt.Errorf("create statement for user %d: %w", u.ID, err)
+		}
+		if created == nil {
+			continue
+		}
+		result.Issued++
+		s.sendStatementEmail(ctx, NotificationEmailEventCreditStatementIssued, u.ID, u.Email, u.Username, created)
+	}
+	return nil
+}
+
+func (s *CreditStatementService) processOpen(ctx context.Context, now time.Time, result *CreditStatementRunResult) error {
+	open, err := s.repo.ListOpen(ctx)
+	if err != nil {
+		return fmt.Errorf("list open statements: %w", err)
+	}
+	for i := range open {
+		st := &open[i]
+		user, err := s.userRepo.GetByID(ctx, st.UserID)
+		if err != nil {
+			slog.Warn("[CreditLine] load statement user failed", "statement_id", st.ID, "user_id", st.UserID, "error", err)
+			continue
+		}
+		// 期末余额截至 PeriodEnd，还款也从 PeriodEnd 起算，出账前到账的款项同样计入。
+		payments, err := s.repo.PaymentsSince(ctx, st.UserID, st.PeriodEnd)
+		if err != nil {
+			return fmt.Errorf("load payments since statement %d: %w", st.ID, err)
+		}
+		if creditStatementSettled(st, payments) {
+			if err := s.repo.MarkPaid(ctx, st.ID, now); err != nil {
+				return fmt.Errorf("mark statement %d paid: %w", st.ID, err)
+			}
+			result.Paid++
+			resumed, err := s.resumeIfClear(ctx, st.UserID)
+			if err != nil {
+				return err
+			}
+			result.ResumedKeys += resumed
+			continue
+		}
+		if st.Status == CreditStatementStatusIssued && !now.Before(st.DueAt) {
+			if err := s.repo.MarkOverdue(ctx, st.ID, now); err != nil {
+				return fmt.Errorf("mark statement %d overdue: %w", st.ID, err)
+			}
+			result.Overdue++
+			st.Status = CreditStatementStatusOverdue
+			s.sendStatementEmail(ctx, NotificationEmailEventCreditStatementOverdue, user.ID, user.Email, user.Username, st)
+		}
+		if st.Status == CreditStatementStatusOverdue {
+			// 每次运行都重新挂起：逾期期间由管理员恢复的 Key 也会再次被挂起。
+			suspended, err := s.transitionKeys(ctx, st.UserID, StatusAPIKeyActive, StatusAPIKeyCreditSuspended)
+			if err != nil {
+				return err
+			}
+			result.SuspendedKeys += suspended
+		}
+	}
+	return nil
+}
+
+// resumeIfClear 在用户没有其他逾期账单时恢复被挂起的 API Key。
+func (s *CreditStatementService) resumeIfClear(ctx context.Context, userID int64) (int64, error) {
+	overdue, err := s.repo.HasOverdue(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("check overdue statements for user %d: %w", userID, err)
+	}
+	if overdue {
+		return 0, nil
+	}
+	return s.transitionKeys(ctx, userID, StatusAPIKeyCreditSuspended, StatusAPIKeyActive)
+}
+
+func (s *CreditStatementService) transitionKeys(ctx context.Context, userID int64, from, to string) (int64, error) {
+	n, err := s.repo.TransitionAPIKeys(ctx, userID, from, to)
+	if err != nil {
+		return 0, fmt.Errorf("update api keys of user %d to %s: %w", userID, to, err)
+	}
+	if n > 0 && s.authCacheInvalidator != nil {
+		s.authCacheInvalidator.InvalidateAuthCacheByUserID(ctx, userID)
+	}
+	return n, nil
+}
+
+// Settle 由管理员手动将账单标记为已结清（例如线下收款后已核销），并在无其他逾期账单时恢复 API Key。
+func (s *CreditStatementService) Settle(ctx context.Context, id int64) (*CreditStatement, error) {
+	st, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if st.Status != CreditStatementStatusPaid {
+		if err := s.repo.MarkPaid(ctx, st.ID, time.Now()); err != nil {
+			return nil, fmt.Errorf("mark statement %d paid: %w", st.ID, err)
+		}
+		if _, err := s.resumeIfClear(ctx, st.UserID); err != nil {
+			return nil, err
+		}
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// HasOverdueStatement 供 APIKeyService 在新建/重新启用 Key 时校验。
+func (s *CreditStatementService) HasOverdueStatement(ctx context.Context, userID int64) (bool, error) {
+	if s == nil || s.repo == nil {
+		return false, nil
+	}
+	return s.repo.HasOverdue(ctx, userID)
+}
+
+func (s *CreditStatementService) List(ctx context.Context, filter CreditStatementFilter) ([]CreditStatement, int64, error) {
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.PageSize <= 0 {
+		filter.PageSize = 20
+	}
+	if filter.PageSize > 100 {
+		filter.PageSize = 100
+	}
+	filter.Status = strings.TrimSpace(filter.Status)
+	return s.repo.List(ctx, filter)
+}
+
+func (s *CreditStatementService) Get(ctx context.Context, id int64) (*CreditStatement, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// GetForUser 返回属于该用户的账单，不属于时按不存在处理，避免泄露账单 ID。
+func (s *CreditStatementService) GetForUser(ctx context.Context, userID, id int64) (*CreditStatement, error) {
+	st, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if st.UserID != userID {
+		return nil, ErrCreditStatementNotFound
+	}
+	return st, nil
+}
+
+func (s *CreditStatementService) sendStatementEmail(ctx context.Context, event string, userID int64, email, username string, st *CreditStatement) {
+	if s.notificationEmail == nil || strings.TrimSpace(email) == "" || st == nil {
+		return
+	}
+	sendCtx, cancel := context.WithTimeout(ctx, creditStatementEmailTimeout)
+	defer cancel()
+	err := s.notificationEmail.Send(sendCtx, NotificationEmailSendInput{
+		Event:          event,
+		RecipientEmail: email,
+		RecipientName:  firstNonEmpty(username, email),
+		UserID:         userID,
+		SourceType:     "credit_statement",
+		SourceID:       strconv.FormatInt(st.ID, 10),
+		Variables: map[string]string{
+			"statement_period": st.PeriodStart.In(timezone.Location()).Format("2006-01"),
+			"total_cost":       fmt.Sprintf("%.2f", st.TotalCost),
+			"closing_balance":  fmt.Sprintf("%.2f", st.ClosingBalance),
+			"amount_due":       fmt.Sprintf("%.2f", st.AmountDue),
+			"credit_limit":     fmt.Sprintf("%.2f", st.CreditLimit),
+			"due_date":         st.DueAt.In(timezone.Location()).Format("2006-01-02"),
+		},
+	})
+	if err != nil {
+		slog.Warn("[CreditLine] send statement email failed", "event", event, "statement_id", st.ID, "user_id", userID, "error", err)
+	}
+}
+
+// Start 按 credit_line.statement_schedule 启动定时任务。重复调用幂等。
+func (s *CreditStatementService) Start() {
+	if s == nil || s.repo == nil || s.cfg == nil || !s.cfg.CreditLine.StatementEnabled {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cron != nil || s.stopped {
+		return
+	}
+
+	loc := time.Local
+	if tz := strings.TrimSpace(s.cfg.Timezone); tz != "" {
+		if parsed, err := time.LoadLocation(tz); err == nil && parsed != nil {
+			loc = parsed
+		}
+	}
+	schedule := strings.TrimSpace(s.cfg.CreditLine.StatementSchedule)
+	c := cron.New(cron.WithParser(creditStatementCronParser), cron.WithLocation(loc))
+	if _, err := c.AddFunc(schedule, s.runScheduled); err != nil {
+		slog.Error("[CreditLine] invalid statement schedule, statements disabled", "schedule", schedule, "error", err)
+		return
+	}
+	c.Start()
+	s.cron = c
+}
+
+// Stop 关闭定时任务。幂等。
+func (s *CreditStatementService) Stop() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopped = true
+	if s.cron == nil {
+		return
+	}
+	ctx := s.cron.Stop()
+	select {
+	case <-ctx.Done():
+	case <-time.After(creditStatementStopTimeout):
+		slog.Warn("[CreditLine] cron stop timed out")
+	}
+	s.cron = nil
+}
+
+func (s *CreditStatementService) runScheduled() {
+	lockCtx, lockCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	release, ok := tryAcquireSingletonLeaderLock(lockCtx, s.lockCache, s.db, creditStatementLeaderLockKey, s.instanceID, creditStatementLeaderLockTTL)
+	lockCancel()
+	if !ok {
+		return
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), creditStatementRunTimeout)
+	defer cancel()
+	if _, err := s.Run(ctx); err != nil {
+		slog.Error("[CreditLine] scheduled statement run failed", "error", err)
+	}
+}
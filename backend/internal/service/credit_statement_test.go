@@ -0,0 +1,203 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type creditStatementRepoStub struct {
+	users      []CreditLineUser
+	lines      []CreditStatementLine
+	statements []CreditStatement
+	payments   float64
+	asOf       time.Time
+	keyStatus  map[int64]string // userID -> status of the user's only key
+}
+
+func (r *creditStatementRepoStub) ListCreditUsers(_ context.Context, asOf time.Time) ([]CreditLineUser, error) {
+	r.asOf = asOf
+	return r.users, nil
+}
+
+func (r *creditStatementRepoStub) SummarizeUsage(context.Context, int64, time.Time, time.Time) ([]CreditStatementLine, error) {
+	return r.lines, nil
+}
+
+func (r *creditStatementRepoStub) Create(_ context.Context, st *CreditStatement) (*CreditStatement, error) {
+	for _, existing := range r.statements {
+		if existing.UserID == st.UserID && existing.PeriodStart.Equal(st.PeriodStart) {
+			return nil, nil
+		}
+	}
+	created := *st
+	created.ID = int64(len(r.statements) + 1)
+	created.CreatedAt = time.Now()
+	r.statements = append(r.statements, created)
+	return &created, nil
+}
+
+func (r *creditStatementRepoStub) GetByID(_ context.Context, id int64) (*CreditStatement, error) {
+	for i := range r.statements {
+		if r.statements[i].ID == id {
+			st := r.statements[i]
+			return &st, nil
+		}
+	}
+	return nil, ErrCreditStatementNotFound
+}
+
+func (r *creditStatementRepoStub) List(context.Context, CreditStatementFilter) ([]CreditStatement, int64, error) {
+	return r.statements, int64(len(r.statements)), nil
+}
+
+func (r *creditStatementRepoStub) ListOpen(context.Context) ([]CreditStatement, error) {
+	var open []CreditStatement
+	for _, st := range r.statements {
+		if st.Status != CreditStatementStatusPaid {
+			open = append(open, st)
+		}
+	}
+	return open, nil
+}
+
+func (r *creditStatementRepoStub) PaymentsSince(context.Context, int64, time.Time) (float64, error) {
+	return r.payments, nil
+}
+
+func (r *creditStatementRepoStub) setStatus(id int64, status string) {
+	for i := range r.statements {
+		if r.statements[i].ID == id {
+			r.statements[i].Status = status
+		}
+	}
+}
+
+func (r *creditStatementRepoStub) MarkPaid(_ context.Context, id int64, _ time.Time) error {
+	r.setStatus(id, CreditStatementStatusPaid)
+	return nil
+}
+
+func (r *creditStatementRepoStub) MarkOverdue(_ context.Context, id int64, _ time.Time) error {
+	r.setStatus(id, CreditStatementStatusOverdue)
+	return nil
+}
+
+func (r *creditStatementRepoStub) HasOverdue(_ context.Context, userID int64) (bool, error) {
+	for _, st := range r.statements {
+		if st.UserID == userID && st.Status == CreditStatementStatusOverdue {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *creditStatementRepoStub) TransitionAPIKeys(_ context.Context, userID int64, from, to string) (int64, error) {
+	if r.keyStatus[userID] != from {
+		return 0, nil
+	}
+	r.keyStatus[userID] = to
+	return 1, nil
+}
+
+func TestCreditStatementAmountDueAndSettled(t *testing.T) {
+	require.Zero(t, creditStatementAmountDue(5))
+	require.Zero(t, creditStatementAmountDue(0))
+	require.InDelta(t, 36.4, creditStatementAmountDue(-36.4), 1e-9)
+
+	st := &CreditStatement{ClosingBalance: -40, AmountDue: 40}
+	// 还款 25，不足 40
+	require.False(t, creditStatementSettled(st, 25))
+	// 还款 40，结清；其间的用量不影响
+	require.True(t, creditStatementSettled(st, 40))
+	require.True(t, creditStatementSettled(&CreditStatement{ClosingBalance: 3}, 0))
+}
+
+func TestCreditStatementPeriodIsPreviousMonth(t *testing.T) {
+	now := time.Date(2026, 3, 10, 8, 0, 0, 0, time.Local)
+	start, end := creditStatementPeriod(now)
+	require.Equal(t, 2026, start.Year())
+	require.Equal(t, time.February, start.Month())
+	require.Equal(t, 1, start.Day())
+	require.Equal(t, time.March, end.Month())
+	require.Equal(t, 1, end.Day())
+}
+
+func TestUserSpendableBalanceIncludesCreditLimit(t *testing.T) {
+	require.Equal(t, 10.0, (&User{Balance: 10}).SpendableBalance())
+	require.Equal(t, 60.0, (&User{Balance: -40, CreditLimit: 100}).SpendableBalance())
+}
+
+func TestCreditStatementServiceRunSuspendsAndResumesKeys(t *testing.T) {
+	repo := &creditStatementRepoStub{
+		users: []CreditLineUser{{ID: 7, Email: "u@test.local", Balance: -40, CreditLimit: 100}},
+		lines: []CreditStatementLine{
+			{Model: "claude-sonnet-4", Requests: 3, Cost: 30},
+			{Model: "gpt-5", Requests: 2, Cost: 10},
+		},
+		keyStatus: map[int64]string{7: StatusAPIKeyActive},
+	}
+	user := &User{ID: 7, Email: "u@test.local", Balance: -40, CreditLimit: 100}
+	invalidator := &authCacheInvalidatorStub{}
+	cfg := &config.Config{CreditLine: config.CreditLineConfig{DueDays: 0}}
+	svc := NewCreditStatementService(repo, &userRepoStub{usersByID: map[int64]*User{7: user}}, nil, invalidator, cfg)
+
+	// 付款期限为 0 天：生成当次即逾期，Key 被挂起
+	result, err := svc.Run(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Issued)
+	require.Equal(t, 1, result.Overdue)
+	require.Equal(t, int64(1), result.SuspendedKeys)
+	require.Len(t, repo.statements, 1)
+	require.Equal(t, int64(5), repo.statements[0].RequestCount)
+	require.InDelta(t, 40, repo.statements[0].TotalCost, 1e-9)
+	require.InDelta(t, 40, repo.statements[0].AmountDue, 1e-9)
+	require.Equal(t, result.PeriodEnd, repo.asOf, "closing balance is taken at period end")
+	require.Equal(t, StatusAPIKeyCreditSuspended, repo.keyStatus[7])
+	require.Equal(t, []int64{7}, invalidator.userIDs)
+
+	overdue, err := svc.HasOverdueStatement(context.Background(), 7)
+	require.NoError(t, err)
+	require.True(t, overdue)
+
+	// 再次运行不会重复出账
+	result, err = svc.Run(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, result.Issued)
+
+	// 余额回升但没有还款流水（例如账单外的冻结释放）不算结清
+	user.Balance = 0
+	result, err = svc.Run(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, result.Paid)
+
+	// 充值结清后恢复 Key
+	repo.payments = 40
+	result, err = svc.Run(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Paid)
+	require.Equal(t, int64(1), result.ResumedKeys)
+	require.Equal(t, CreditStatementStatusPaid, repo.statements[0].Status)
+	require.Equal(t, StatusAPIKeyActive, repo.keyStatus[7])
+}
+
+func TestCreditStatementServiceSettleAndOwnership(t *testing.T) {
+	repo := &creditStatementRepoStub{
+		statements: []CreditStatement{{ID: 1, UserID: 7, Status: CreditStatementStatusOverdue, AmountDue: 12}},
+		keyStatus:  map[int64]string{7: StatusAPIKeyCreditSuspended},
+	}
+	svc := NewCreditStatementService(repo, &userRepoStub{}, nil, nil, &config.Config{})
+
+	_, err := svc.GetForUser(context.Background(), 8, 1)
+	require.ErrorIs(t, err, ErrCreditStatementNotFound)
+
+	st, err := svc.Settle(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, CreditStatementStatusPaid, st.Status)
+	require.Equal(t, StatusAPIKeyActive, repo.keyStatus[7])
+}
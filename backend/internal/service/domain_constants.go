@@ -136,6 +136,9 @@ const WeChatConnectSyntheticEmailDomain = "@wechat-connect.invalid"
 // DingTalkConnectSyntheticEmailDomain 是 DingTalk Connect 用户的合成邮箱后缀（RFC 保留域名）。
 const DingTalkConnectSyntheticEmailDomain = "@dingtalk-connect.invalid"
 
+// SAMLConnectSyntheticEmailDomain 是 SAML 用户的合成邮箱后缀（RFC 保留域名）。
+const SAMLConnectSyntheticEmailDomain = "@saml-connect.invalid"
+
 // Setting keys
 const (
 	// 注册设置
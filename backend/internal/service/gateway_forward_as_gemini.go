@@ -0,0 +1,451 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/apicompat"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/claude"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/googleapi"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/util/responseheaders"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ForwardAsGemini accepts a Gemini generateContent request body, converts it
+// to Anthropic Messages format (chained via Responses format), forwards to the
+// Anthropic upstream, and converts the response back to Gemini format. This
+// enables Gemini-native clients (google-genai SDK, Gemini CLI) to access
+// Anthropic models through Anthropic platform groups.
+//
+// model comes from the /v1beta/models/{model}:{action} path; stream selects
+// streamGenerateContent (SSE) over generateContent (JSON).
+func (s *GatewayService) ForwardAsGemini(
+	ctx context.Context,
+	c *gin.Context,
+	account *Account,
+	body []byte,
+	model string,
+	stream bool,
+) (*ForwardResult, error) {
+	startTime := time.Now()
+
+	// 1. Parse Gemini request
+	var geminiReq apicompat.GeminiRequest
+	if err := json.Unmarshal(body, &geminiReq); err != nil {
+		writeGatewayGeminiError(c, http.StatusBadRequest, "Failed to parse request body")
+		return nil, fmt.Errorf("parse gemini request: %w", err)
+	}
+	originalModel := model
+
+	// 2. Convert Gemini → Responses → Anthropic (chained conversion)
+	responsesReq, err := apicompat.GeminiToResponses(&geminiReq, originalModel)
+	if err != nil {
+		writeGatewayGeminiError(c, http.StatusBadRequest, err.Error())
+		return nil, fmt.Errorf("convert gemini to responses: %w", err)
+	}
+
+	anthropicReq, err := apicompat.ResponsesToAnthropicRequest(responsesReq)
+	if err != nil {
+		writeGatewayGeminiError(c, http.StatusBadRequest, err.Error())
+		return nil, fmt.Errorf("convert responses to anthropic: %w", err)
+	}
+
+	// 3. Force upstream streaming
+	anthropicReq.Stream = true
+	reqStream := true
+
+	// 4. Model mapping
+	mappedModel := originalModel
+	if account.Type == AccountTypeAPIKey || account.Type == AccountTypeServiceAccount {
+		mappedModel = account.GetMappedModel(originalModel)
+	}
+	if mappedModel == originalModel && account.Platform == PlatformAnthropic && account.Type == AccountTypeServiceAccount {
+		normalized := normalizeVertexAnthropicModelID(claude.NormalizeModelID(originalModel))
+		if normalized != originalModel {
+			mappedModel = normalized
+		}
+	} else if mappedModel == originalModel && account.Platform == PlatformAnthropic && account.Type != AccountTypeAPIKey {
+		normalized := claude.NormalizeModelID(originalModel)
+		if normalized != originalModel {
+			mappedModel = normalized
+		}
+	}
+	anthropicReq.Model = mappedModel
+
+	logger.L().Debug("gateway forward_as_gemini: model mapping applied",
+		zap.Int64("account_id", account.ID),
+		zap.String("original_model", originalModel),
+		zap.String("mapped_model", mappedModel),
+		zap.Bool("client_stream", stream),
+	)
+
+	// 5. Marshal Anthropic request body
+	anthropicBody, err := json.Marshal(anthropicReq)
+	if err != nil {
+		return nil, fmt.Errorf("marshal anthropic request: %w", err)
+	}
+
+	// 6. Apply Claude Code mimicry for OAuth accounts.
+	// Gemini 协议进来的请求同样不是 Claude Code 客户端，OAuth 账号需走完整伪装链路，
+	// 与 ForwardAsChatCompletions 保持一致。
+	shouldMimicClaudeCode := account.IsOAuth()
+	if shouldMimicClaudeCode {
+		anthropicBody = s.applyClaudeCodeOAuthMimicryToBody(ctx, c, account, anthropicBody, anthropicReq.System, mappedModel)
+	}
+
+	// 7. Enforce cache_control block limit
+	anthropicBody = enforceCacheControlLimit(anthropicBody)
+
+	// 8. Get access token
+	token, tokenType, err := s.GetAccessToken(ctx, account)
+	if err != nil {
+		return nil, fmt.Errorf("get access token: %w", err)
+	}
+
+	// 9. Get proxy URL
+	proxyURL := ""
+	if account.ProxyID != nil && account.Proxy != nil {
+		proxyURL = account.Proxy.URL()
+	}
+
+	// 10. Build upstream request
+	upstreamCtx, releaseUpstreamCtx := detachStreamUpstreamContext(ctx, reqStream)
+	upstreamReq, _, err := s.buildUpstreamRequest(upstreamCtx, c, account, anthropicBody, token, tokenType, mappedModel, reqStream, shouldMimicClaudeCode)
+	releaseUpstreamCtx()
+	if err != nil {
+		return nil, fmt.Errorf("build upstream request: %w", err)
+	}
+
+	// 11. Send request
+	resp, err := s.httpUpstream.DoWithTLS(upstreamReq, proxyURL, account.ID, account.Concurrency, s.tlsFPProfileService.ResolveTLSProfile(account))
+	if err != nil {
+		if resp != nil && resp.Body != nil {
+			_ = resp.Body.Close()
+		}
+		safeErr := sanitizeUpstreamErrorMessage(err.Error())
+		setOpsUpstreamError(c, 0, safeErr, "")
+		appendOpsUpstreamError(c, OpsUpstreamErrorEvent{
+			Platform:           account.Platform,
+			AccountID:          account.ID,
+			AccountName:        account.Name,
+			UpstreamStatusCode: 0,
+			Kind:               "request_error",
+			Message:            safeErr,
+		})
+		writeGatewayGeminiError(c, http.StatusBadGateway, "Upstream request failed")
+		return nil, fmt.Errorf("upstream request failed: %s", safeErr)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	// 12. Handle error response with failover
+	if resp.StatusCode >= 400 {
+		respBody, _ := s.readUpstreamErrorBody(resp)
+		_ = resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+		upstreamMsg := strings.TrimSpace(extractUpstreamErrorMessage(respBody))
+		upstreamMsg = sanitizeUpstreamErrorMessage(upstreamMsg)
+
+		if s.shouldFailoverUpstreamError(resp.StatusCode) {
+			appendOpsUpstreamError(c, OpsUpstreamErrorEvent{
+				Platform:           account.Platform,
+				AccountID:          account.ID,
+				AccountName:        account.Name,
+				UpstreamStatusCode: resp.StatusCode,
+				UpstreamRequestID:  resp.Header.Get("x-request-id"),
+				Kind:               "failover",
+				Message:            upstreamMsg,
+			})
+			if s.rateLimitService != nil {
+				s.rateLimitService.HandleUpstreamError(ctx, account, resp.StatusCode, resp.Header, respBody, mappedModel)
+			}
+			return nil, &UpstreamFailoverError{
+				StatusCode:   resp.StatusCode,
+				ResponseBody: respBody,
+			}
+		}
+
+		writeGatewayGeminiError(c, mapUpstreamStatusCode(resp.StatusCode), upstreamMsg)
+		return nil, fmt.Errorf("upstream error: %d %s", resp.StatusCode, upstreamMsg)
+	}
+
+	// 13. Reasoning effort derived from generationConfig.thinkingConfig
+	var reasoningEffort *string
+	if responsesReq.Reasoning != nil && responsesReq.Reasoning.Effort != "" {
+		if normalized := normalizeOpenAIReasoningEffort(responsesReq.Reasoning.Effort); normalized != "" {
+			reasoningEffort = &normalized
+		}
+	}
+
+	// 14. Handle normal response
+	// Read Anthropic SSE → convert to Responses events → convert to Gemini format
+	if stream {
+		return s.handleGeminiStreamingFromAnthropic(resp, c, originalModel, mappedModel, reasoningEffort, startTime)
+	}
+	return s.handleGeminiBufferedFromAnthropic(resp, c, originalModel, mappedModel, reasoningEffort, startTime)
+}
+
+// handleGeminiBufferedFromAnthropic reads Anthropic SSE events, assembles the
+// full response, then converts Anthropic → Responses → Gemini.
+func (s *GatewayService) handleGeminiBufferedFromAnthropic(
+	resp *http.Response,
+	c *gin.Context,
+	originalModel string,
+	mappedModel string,
+	reasoningEffort *string,
+	startTime time.Time,
+) (*ForwardResult, error) {
+	requestID := resp.Header.Get("x-request-id")
+
+	scanner := bufio.NewScanner(resp.Body)
+	maxLineSize := defaultMaxLineSize
+	if s.cfg != nil && s.cfg.Gateway.MaxLineSize > 0 {
+		maxLineSize = s.cfg.Gateway.MaxLineSize
+	}
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	var finalResp *apicompat.AnthropicResponse
+	var usage ClaudeUsage
+
+	for scanner.Scan() {
+		if _, ok := extractOpenAISSEEventLine(scanner.Text()); !ok {
+			continue
+		}
+		if !scanner.Scan() {
+			break
+		}
+		payload, ok := extractOpenAISSEDataLine(scanner.Text())
+		if !ok {
+			continue
+		}
+
+		var event apicompat.AnthropicStreamEvent
+		if err := json.Unmarshal([]byte(payload), &event); err != nil {
+			continue
+		}
+
+		switch event.Type {
+		case "message_start":
+			if event.Message != nil {
+				finalResp = event.Message
+				mergeAnthropicUsage(&usage, event.Message.Usage)
+			}
+		case "message_delta":
+			if event.Usage != nil {
+				mergeAnthropicUsage(&usage, *event.Usage)
+			}
+			if event.Delta != nil && event.Delta.StopReason != "" && finalResp != nil {
+				finalResp.StopReason = apicompat.AnthropicStopReasonPtr(event.Delta.StopReason)
+			}
+		case "content_block_start":
+			if event.ContentBlock != nil && finalResp != nil {
+				finalResp.Content = append(finalResp.Content, *event.ContentBlock)
+			}
+		case "content_block_delta":
+			if event.Delta == nil || finalResp == nil || event.Index == nil || *event.Index >= len(finalResp.Content) {
+				continue
+			}
+			idx := *event.Index
+			switch event.Delta.Type {
+			case "text_delta":
+				finalResp.Content[idx].Text += event.Delta.Text
+			case "thinking_delta":
+				finalResp.Content[idx].Thinking += event.Delta.Thinking
+			case "input_json_delta":
+				finalResp.Content[idx].Input = appendRawJSON(finalResp.Content[idx].Input, event.Delta.PartialJSON)
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+			logger.L().Warn("forward_as_gemini buffered: read error",
+				zap.Error(err),
+				zap.String("request_id", requestID),
+			)
+		}
+	}
+
+	if finalResp == nil {
+		writeGatewayGeminiError(c, http.StatusBadGateway, "Upstream stream ended without a response")
+		return nil, fmt.Errorf("upstream stream ended without response")
+	}
+
+	if usage.InputTokens > 0 || usage.OutputTokens > 0 {
+		finalResp.Usage = apicompat.AnthropicUsage{
+			InputTokens:              usage.InputTokens,
+			OutputTokens:             usage.OutputTokens,
+			CacheCreationInputTokens: usage.CacheCreationInputTokens,
+			CacheReadInputTokens:     usage.CacheReadInputTokens,
+		}
+	}
+
+	// Chain: Anthropic → Responses → Gemini
+	responsesResp := apicompat.AnthropicToResponsesResponse(finalResp)
+	geminiResp := apicompat.ResponsesToGemini(responsesResp, originalModel)
+
+	if s.responseHeaderFilter != nil {
+		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
+	}
+	// 上游被强制流式，透传的 text/event-stream 头需显式改回 JSON（同 CC 路径）。
+	c.Writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if respBytes, err := json.Marshal(geminiResp); err == nil {
+		respBytes = reverseToolNamesIfPresent(c, respBytes)
+		c.Data(http.StatusOK, "application/json; charset=utf-8", respBytes)
+	} else {
+		c.JSON(http.StatusOK, geminiResp)
+	}
+
+	return &ForwardResult{
+		RequestID:       requestID,
+		Usage:           usage,
+		Model:           originalModel,
+		UpstreamModel:   mappedModel,
+		ReasoningEffort: reasoningEffort,
+		Stream:          false,
+		Duration:        time.Since(startTime),
+	}, nil
+}
+
+// handleGeminiStreamingFromAnthropic reads Anthropic SSE events, converts each
+// to Responses events, then to Gemini chunks, and writes them as alt=sse.
+func (s *GatewayService) handleGeminiStreamingFromAnthropic(
+	resp *http.Response,
+	c *gin.Context,
+	originalModel string,
+	mappedModel string,
+	reasoningEffort *string,
+	startTime time.Time,
+) (*ForwardResult, error) {
+	requestID := resp.Header.Get("x-request-id")
+
+	if s.responseHeaderFilter != nil {
+		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
+	}
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+	c.Writer.Header().Set("X-Accel-Buffering", "no")
+	c.Writer.WriteHeader(http.StatusOK)
+
+	anthState := apicompat.NewAnthropicEventToResponsesState()
+	anthState.Model = originalModel
+	geminiState := apicompat.NewResponsesEventToGeminiState(originalModel)
+
+	var usage ClaudeUsage
+	var firstTokenMs *int
+	firstChunk := true
+
+	scanner := bufio.NewScanner(resp.Body)
+	maxLineSize := defaultMaxLineSize
+	if s.cfg != nil && s.cfg.Gateway.MaxLineSize > 0 {
+		maxLineSize = s.cfg.Gateway.MaxLineSize
+	}
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	resultWithUsage := func() *ForwardResult {
+		return &ForwardResult{
+			RequestID:       requestID,
+			Usage:           usage,
+			Model:           originalModel,
+			UpstreamModel:   mappedModel,
+			ReasoningEffort: reasoningEffort,
+			Stream:          true,
+			Duration:        time.Since(startTime),
+			FirstTokenMs:    firstTokenMs,
+		}
+	}
+
+	writeChunks := func(chunks []apicompat.GeminiResponse) bool {
+		for _, chunk := range chunks {
+			sse, err := apicompat.GeminiChunkToSSE(chunk)
+			if err != nil {
+				continue
+			}
+			out := string(reverseToolNamesIfPresent(c, []byte(sse)))
+			if _, err := fmt.Fprint(c.Writer, out); err != nil {
+				return true // client disconnected
+			}
+		}
+		return false
+	}
+
+	for scanner.Scan() {
+		if _, ok := extractOpenAISSEEventLine(scanner.Text()); !ok {
+			continue
+		}
+		if !scanner.Scan() {
+			break
+		}
+		payload, ok := extractOpenAISSEDataLine(scanner.Text())
+		if !ok {
+			continue
+		}
+
+		var event apicompat.AnthropicStreamEvent
+		if err := json.Unmarshal([]byte(payload), &event); err != nil {
+			continue
+		}
+
+		if firstChunk {
+			firstChunk = false
+			ms := int(time.Since(startTime).Milliseconds())
+			firstTokenMs = &ms
+		}
+		if event.Type == "message_delta" && event.Usage != nil {
+			mergeAnthropicUsage(&usage, *event.Usage)
+		}
+		if event.Type == "message_start" && event.Message != nil {
+			mergeAnthropicUsage(&usage, event.Message.Usage)
+		}
+
+		// Chain: Anthropic event → Responses events → Gemini chunks
+		for _, resEvt := range apicompat.AnthropicEventToResponsesEvents(&event, anthState) {
+			if writeChunks(apicompat.ResponsesEventToGeminiChunks(&resEvt, geminiState)) {
+				return resultWithUsage(), nil
+			}
+		}
+		c.Writer.Flush()
+	}
+
+	if err := scanner.Err(); err != nil {
+		if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+			logger.L().Warn("forward_as_gemini stream: read error",
+				zap.Error(err),
+				zap.String("request_id", requestID),
+			)
+		}
+	}
+
+	// Finalize both state machines
+	for _, resEvt := range apicompat.FinalizeAnthropicResponsesStream(anthState) {
+		writeChunks(apicompat.ResponsesEventToGeminiChunks(&resEvt, geminiState)) //nolint:errcheck
+	}
+	writeChunks(apicompat.FinalizeResponsesGeminiStream(geminiState)) //nolint:errcheck
+	c.Writer.Flush()
+
+	return resultWithUsage(), nil
+}
+
+// writeGatewayGeminiError writes an error in Google API format for the
+// non-Gemini-upstream generateContent forwarding paths.
+func writeGatewayGeminiError(c *gin.Context, statusCode int, message string) {
+	MarkResponseCommitted(c)
+	c.JSON(statusCode, gin.H{
+		"error": gin.H{
+			"code":    statusCode,
+			"message": message,
+			"status":  googleapi.HTTPStatusToGoogleStatus(statusCode),
+		},
+	})
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startAccountSelectionSpan 为一次账号调度开启 span。
+func startAccountSelectionSpan(ctx context.Context, name string, groupID *int64, platform, model string) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{tracing.AttrModel.String(model)}
+	if groupID != nil {
+		attrs = append(attrs, tracing.AttrGroupID.Int64(*groupID))
+	}
+	if platform != "" {
+		attrs = append(attrs, tracing.AttrPlatform.String(platform))
+	}
+	return tracing.Start(ctx, name, attrs...)
+}
+
+// endAccountSelectionSpan 记录选中账号与是否需要排队后结束 span。
+func endAccountSelectionSpan(span trace.Span, result *AccountSelectionResult, err error) {
+	if result != nil && result.Account != nil {
+		span.SetAttributes(
+			tracing.AttrAccountID.Int64(result.Account.ID),
+			tracing.AttrPlatform.String(result.Account.Platform),
+			attribute.Bool("sub2api.slot_acquired", result.Acquired),
+		)
+	}
+	tracing.End(span, err)
+}
+
+// startUsageBillingSpan 为一次用量记录/扣费开启 span。
+// 计费通常在 worker 池里异步执行，handler 会把请求 span 上下文带过来，因此仍挂在原请求链路下。
+func startUsageBillingSpan(ctx context.Context, account *Account, apiKey *APIKey) (context.Context, trace.Span) {
+	var attrs []attribute.KeyValue
+	if account != nil {
+		attrs = append(attrs, tracing.AttrAccountID.Int64(account.ID), tracing.AttrPlatform.String(account.Platform))
+	}
+	if apiKey != nil {
+		attrs = append(attrs, tracing.AttrAPIKeyID.Int64(apiKey.ID))
+	}
+	return tracing.Start(ctx, "gateway.usage_billing", attrs...)
+}
@@ -9,6 +9,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/Wei-Shaw/sub2api/internal/config"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/claude"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
 	"github.com/Wei-Shaw/sub2api/internal/util/urlvalidator"
@@ -900,18 +901,25 @@ func (s *GatewayService) buildCustomRelayURL(baseURL, path string, account *Acco
 }
 
 func (s *GatewayService) validateUpstreamBaseURL(raw string) (string, error) {
-	if s.cfg != nil && !s.cfg.Security.URLAllowlist.Enabled {
-		normalized, err := urlvalidator.ValidateURLFormat(raw, s.cfg.Security.URLAllowlist.AllowInsecureHTTP)
+	return validateUpstreamBaseURLWithConfig(s.cfg, raw)
+}
+
+// validateUpstreamBaseURLWithConfig 按 security.url_allowlist 校验账号 base_url，
+// 供不持有 GatewayService 的上游调用方（如 Message Batches）复用同一口径。
+func validateUpstreamBaseURLWithConfig(cfg *config.Config, raw string) (string, error) {
+	if cfg != nil && !cfg.Security.URLAllowlist.Enabled {
+		normalized, err := urlvalidator.ValidateURLFormat(raw, cfg.Security.URLAllowlist.AllowInsecureHTTP)
 		if err != nil {
 			return "", fmt.Errorf("invalid base_url: %w", err)
 		}
 		return normalized, nil
 	}
-	normalized, err := urlvalidator.ValidateHTTPSURL(raw, urlvalidator.ValidationOptions{
-		AllowedHosts:     s.cfg.Security.URLAllowlist.UpstreamHosts,
-		RequireAllowlist: true,
-		AllowPrivate:     s.cfg.Security.URLAllowlist.AllowPrivateHosts,
-	})
+	opts := urlvalidator.ValidationOptions{RequireAllowlist: true}
+	if cfg != nil {
+		opts.AllowedHosts = cfg.Security.URLAllowlist.UpstreamHosts
+		opts.AllowPrivate = cfg.Security.URLAllowlist.AllowPrivateHosts
+	}
+	normalized, err := urlvalidator.ValidateHTTPSURL(raw, opts)
 	if err != nil {
 		return "", fmt.Errorf("invalid base_url: %w", err)
 	}
@@ -10,6 +10,7 @@ import (
 	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tracing"
 )
 
 func (s *GatewayService) getUserGroupRateMultiplier(ctx context.Context, userID, groupID int64, groupDefaultMultiplier float64) float64 {
@@ -148,11 +149,18 @@ func postUsageBilling(ctx context.Context, p *postUsageBillingParams, deps *bill
 		}
 	} else {
 		if cost.ActualCost > 0 {
-			if err := deps.userRepo.DeductBalance(billingCtx, p.User.ID, cost.ActualCost); err != nil {
-				slog.Error("deduct balance failed", "user_id", p.User.ID, "error", err)
-			} else if deps.billingCacheService != nil {
-				if err := deps.billingCacheService.InvalidateUserBalance(billingCtx, p.User.ID); err != nil {
-					slog.Warn("invalidate balance cache after legacy deduction failed", "user_id", p.User.ID, "error", err)
+			balanceCtx := WithBalanceLedgerTag(billingCtx, BalanceLedgerTag{Type: BalanceLedgerTypeUsage})
+			// 与 repo.Apply 一致：组织成员的余额模式消费扣组织钱包，不落到个人余额。
+			charged, err := deps.billingCacheService.chargeOrganizationWallet(balanceCtx, p.User.ID, cost.ActualCost)
+			if err != nil {
+				slog.Error("deduct organization wallet failed", "user_id", p.User.ID, "error", err)
+			} else if !charged {
+				if err := deps.userRepo.DeductBalance(balanceCtx, p.User.ID, cost.ActualCost); err != nil {
+					slog.Error("deduct balance failed", "user_id", p.User.ID, "error", err)
+				} else if deps.billingCacheService != nil {
+					if err := deps.billingCacheService.InvalidateUserBalance(billingCtx, p.User.ID); err != nil {
+						slog.Warn("invalidate balance cache after legacy deduction failed", "user_id", p.User.ID, "error", err)
+					}
 				}
 			}
 		}
@@ -370,11 +378,13 @@ func finalizePostUsageBilling(ctx context.Context, p *postUsageBillingParams, de
 		return
 	}
 
+	chargedToOrganization := result != nil && result.OrganizationID != nil
 	if p.IsSubscriptionBill {
 		if p.Cost.ActualCost > 0 && p.User != nil && p.APIKey != nil && p.APIKey.GroupID != nil {
-			deps.billingCacheService.QueueUpdateSubscriptionUsage(p.User.ID, *p.APIKey.GroupID, p.Cost.ActualCost)
+			deps.billingCacheService.QueueUpdateSubscriptionUsage(subscriptionHolderID(p.User.ID, p.Subscription), *p.APIKey.GroupID, p.Cost.ActualCost)
 		}
-	} else if p.Cost.ActualCost > 0 && p.User != nil {
+	} else if p.Cost.ActualCost > 0 && p.User != nil && !chargedToOrganization {
+		// 组织钱包扣费不动个人余额，个人余额缓存无需同步。
 		syncBalanceCacheAfterDeduction(ctx, p, deps, result)
 	}
 
@@ -420,15 +430,26 @@ func finalizePostUsageBilling(ctx context.Context, p *postUsageBillingParams, de
 
 	// Notification checks run async — all parameters are already captured,
 	// no dependency on the request context or upstream connection.
-	go notifyBalanceLow(p, deps, result)
+	if !chargedToOrganization {
+		go notifyBalanceLow(p, deps, result)
+	}
 	go notifyAccountQuota(p, deps, result)
 }
 
+// subscriptionHolderID 返回订阅用量缓存的归属用户：组织共享订阅挂在 owner 名下，
+// 成员的用量必须累加到 owner 的缓存键上才能与预检读取的额度窗口一致。
+func subscriptionHolderID(userID int64, subscription *UserSubscription) int64 {
+	if subscription != nil && subscription.UserID > 0 {
+		return subscription.UserID
+	}
+	return userID
+}
+
 func syncBalanceCacheAfterDeduction(ctx context.Context, p *postUsageBillingParams, deps *billingDeps, result *UsageBillingApplyResult) {
 	if p == nil || p.Cost == nil || p.User == nil || deps == nil || deps.billingCacheService == nil {
 		return
 	}
-	if result != nil && result.NewBalance != nil && deps.billingCacheService.balanceBelowEligibilityThreshold(*result.NewBalance) {
+	if result != nil && result.NewBalance != nil && deps.billingCacheService.balanceBelowEligibilityThreshold(*result.NewBalance+p.User.CreditLimit) {
 		if err := deps.billingCacheService.InvalidateUserBalance(ctx, p.User.ID); err != nil {
 			slog.Warn("invalidate balance cache after exhausted deduction failed",
 				"user_id", p.User.ID,
@@ -604,7 +625,9 @@ type recordUsageOpts struct {
 }
 
 // RecordUsage 记录使用量并扣费（或更新订阅用量）
-func (s *GatewayService) RecordUsage(ctx context.Context, input *RecordUsageInput) error {
+func (s *GatewayService) RecordUsage(ctx context.Context, input *RecordUsageInput) (err error) {
+	ctx, span := startUsageBillingSpan(ctx, input.Account, input.APIKey)
+	defer func() { tracing.End(span, err) }()
 	return s.recordUsageCore(ctx, &recordUsageCoreInput{
 		Result:             input.Result,
 		APIKey:             input.APIKey,
@@ -649,7 +672,9 @@ type RecordUsageLongContextInput struct {
 }
 
 // RecordUsageWithLongContext 记录使用量并扣费，支持长上下文双倍计费（用于 Gemini）
-func (s *GatewayService) RecordUsageWithLongContext(ctx context.Context, input *RecordUsageLongContextInput) error {
+func (s *GatewayService) RecordUsageWithLongContext(ctx context.Context, input *RecordUsageLongContextInput) (err error) {
+	ctx, span := startUsageBillingSpan(ctx, input.Account, input.APIKey)
+	defer func() { tracing.End(span, err) }()
 	return s.recordUsageCore(ctx, &recordUsageCoreInput{
 		Result:             input.Result,
 		APIKey:             input.APIKey,
@@ -735,6 +760,8 @@ func (s *GatewayService) recordUsageCore(ctx context.Context, input *recordUsage
 	if pricingAt.IsZero() {
 		pricingAt = timezone.Now()
 	}
+	// 月度消费阶梯同样按请求时刻现算并乘入基础倍率，图片按次倍率随之折扣，高峰因子仍最后叠加。
+	multiplier, volumeTier := applyGroupVolumeTier(ctx, s.volumeTierResolver, apiKey, user.ID, multiplier, pricingAt)
 	multiplier, imageMultiplier := computePeakAwareMultipliers(apiKey, multiplier, pricingAt)
 
 	// 确定计费模型
@@ -777,6 +804,7 @@ func (s *GatewayService) recordUsageCore(ctx context.Context, input *recordUsage
 	accountRateMultiplier := account.BillingRateMultiplier()
 	usageLog := s.buildRecordUsageLog(ctx, input, result, apiKey, user, account, subscription,
 		requestedModel, multiplier, imageMultiplier, accountRateMultiplier, billingType, cacheTTLOverridden, cost, opts)
+	usageLog.BillingTier = volumeTier
 
 	// 计算账号统计定价费用（使用最终上游模型匹配自定义规则）
 	if apiKey.GroupID != nil {
@@ -831,6 +859,7 @@ func (s *GatewayService) recordUsageCore(ctx context.Context, input *recordUsage
 		writeUsageLogBestEffort(ctx, s.usageLogRepo, usageLog, "service.gateway")
 		return billingErr
 	}
+	recordGroupVolumeTierSpend(s.volumeTierResolver, apiKey, user.ID, pricingAt, usageLog.ActualCost)
 	writeUsageLogBestEffort(ctx, s.usageLogRepo, usageLog, "service.gateway")
 
 	return nil
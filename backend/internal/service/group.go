@@ -14,6 +14,8 @@ import (
 type OpenAIMessagesDispatchModelConfig = domain.OpenAIMessagesDispatchModelConfig
 type GroupModelsListConfig = domain.GroupModelsListConfig
 type ReasoningEffortMapping = domain.ReasoningEffortMapping
+type GroupHedgePolicy = domain.GroupHedgePolicy
+type GroupMirrorPolicy = domain.GroupMirrorPolicy
 
 type Group struct {
 	ID             int64
@@ -119,6 +121,27 @@ type Group struct {
 	ProfitMinMargin      float64 // 最低毛利率，小数存储（0.30=30%）
 	ProfitSafetyBuffer   float64 // 安全缓冲，小数，与 margin 相加后从 D 中扣除
 
+	// 精确匹配响应缓存：确定性请求（归一化请求体完全一致）直接回放缓存响应。
+	ResponseCacheEnabled       bool
+	ResponseCacheTTLSeconds    int     // 0 表示使用全局默认 TTL
+	ResponseCacheHitMultiplier float64 // 命中计费倍率，按原价乘以该值；0 表示命中免费
+
+	// 月度消费阶梯：按用户在该分组当月累计 actual_cost 选档，档位倍率再乘入基础倍率
+	// （用户覆盖 ?? 分组默认，先于高峰因子）。空表示不启用，详见 VolumeTierAt。
+	VolumeTiers []VolumeTier
+
+	// AllowGeminiNative 允许 anthropic/openai 分组经协议转换服务 Gemini 原生
+	// /v1beta generateContent 请求；其余平台恒为 false。
+	AllowGeminiNative bool
+
+	// HedgePolicy 对冲请求策略：非流式 OpenAI 请求在首个账号超过分位延迟未响应时，
+	// 向第二个账号并发同一请求，先返回者胜出；仅 openai 分组生效。
+	HedgePolicy GroupHedgePolicy
+
+	// MirrorPolicy 流量镜像策略：抽样异步重放到候选分组/账号用于评估，
+	// 镜像响应对客户端不可见且不计费，仅记录对比结果。
+	MirrorPolicy GroupMirrorPolicy
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 
@@ -457,3 +480,19 @@ func (g *Group) GetSearchPricePer1k() *float64 {
 	}
 	return g.SearchPricePer1k
 }
+
+// AllowsGeminiNative 报告分组是否经协议转换接入 Gemini 原生 /v1beta generateContent。
+func (g *Group) AllowsGeminiNative() bool {
+	if g == nil || !g.AllowGeminiNative {
+		return false
+	}
+	return g.Platform == PlatformAnthropic || g.Platform == PlatformOpenAI
+}
+
+// sanitizeGroupGeminiNativeField 清除非 anthropic/openai 分组上的 Gemini 原生接入开关。
+func sanitizeGroupGeminiNativeField(g *Group) {
+	if g == nil || g.Platform == PlatformAnthropic || g.Platform == PlatformOpenAI {
+		return
+	}
+	g.AllowGeminiNative = false
+}
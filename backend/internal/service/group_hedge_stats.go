@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+)
+
+const (
+	// HedgeWinnerPrimary 对冲已触发，但首个账号先返回。
+	HedgeWinnerPrimary = "primary"
+	// HedgeWinnerHedge 对冲账号先返回。
+	HedgeWinnerHedge = "hedge"
+	// HedgeWinnerNone 两路都失败。
+	HedgeWinnerNone = ""
+
+	defaultHedgeStatsDays = 7
+	maxHedgeStatsDays     = 90
+)
+
+// GroupHedgeOutcome 是一次已触发对冲的结果。HedgeCost 为被取消一方的估算上游成本：
+// 按胜出方的输入 token 与落败账号倍率估算，被取消前已生成的输出无法获知，不计入。
+type GroupHedgeOutcome struct {
+	GroupID   int64
+	Winner    string
+	HedgeCost float64
+	At        time.Time
+}
+
+// GroupHedgeDailyStat 分组单日对冲统计。
+type GroupHedgeDailyStat struct {
+	Date            string  `json:"date"`
+	HedgedCount     int64   `json:"hedged_count"`
+	HedgeWinCount   int64   `json:"hedge_win_count"`
+	PrimaryWinCount int64   `json:"primary_win_count"`
+	HedgeCost       float64 `json:"hedge_cost"`
+}
+
+// GroupHedgeStatsSummary 分组在最近 Days 天内的对冲汇总。
+// HedgeWinRate = 对冲胜出次数 / 已触发次数。
+type GroupHedgeStatsSummary struct {
+	GroupID         int64                 `json:"group_id"`
+	Days            int                   `json:"days"`
+	HedgedCount     int64                 `json:"hedged_count"`
+	HedgeWinCount   int64                 `json:"hedge_win_count"`
+	PrimaryWinCount int64                 `json:"primary_win_count"`
+	HedgeWinRate    float64               `json:"hedge_win_rate"`
+	HedgeCost       float64               `json:"hedge_cost"`
+	Daily           []GroupHedgeDailyStat `json:"daily"`
+}
+
+// GroupHedgeStatsRepository 持久化按日聚合的对冲统计。
+type GroupHedgeStatsRepository interface {
+	RecordOutcome(ctx context.Context, day time.Time, outcome GroupHedgeOutcome) error
+	ListDaily(ctx context.Context, groupID int64, from, to time.Time) ([]GroupHedgeDailyStat, error)
+}
+
+// GroupHedgeStatsService 记录对冲结果并向管理员提供成本与胜率。
+type GroupHedgeStatsService struct {
+	repo           GroupHedgeStatsRepository
+	billingService *BillingService
+}
+
+// NewGroupHedgeStatsService 创建对冲统计服务。
+func NewGroupHedgeStatsService(repo GroupHedgeStatsRepository, billingService *BillingService) *GroupHedgeStatsService {
+	return &GroupHedgeStatsService{repo: repo, billingService: billingService}
+}
+
+// EstimateLoserCost 估算被取消一方的上游成本（按落败账号倍率折算）。
+func (s *GroupHedgeStatsService) EstimateLoserCost(result *OpenAIForwardResult, loser *Account) float64 {
+	if s == nil || s.billingService == nil || result == nil || loser == nil {
+		return 0
+	}
+	model := result.BillingModel
+	if model == "" {
+		model = result.Model
+	}
+	cost, err := s.billingService.CalculateCost(model, UsageTokens{
+		InputTokens:      result.Usage.InputTokens,
+		ImageInputTokens: result.Usage.ImageInputTokens,
+		CacheReadTokens:  result.Usage.CacheReadInputTokens,
+	}, loser.BillingRateMultiplier())
+	if err != nil || cost == nil {
+		return 0
+	}
+	return cost.ActualCost
+}
+
+// Record 累加一次对冲结果。
+func (s *GroupHedgeStatsService) Record(ctx context.Context, outcome GroupHedgeOutcome) error {
+	if s == nil || s.repo == nil || outcome.GroupID <= 0 {
+		return nil
+	}
+	at := outcome.At
+	if at.IsZero() {
+		at = time.Now()
+	}
+	return s.repo.RecordOutcome(ctx, timezone.StartOfDay(at), outcome)
+}
+
+// Summary 返回分组最近 days 天（含今天）的对冲统计。
+func (s *GroupHedgeStatsService) Summary(ctx context.Context, groupID int64, days int) (*GroupHedgeStatsSummary, error) {
+	if days <= 0 {
+		days = defaultHedgeStatsDays
+	}
+	days = min(days, maxHedgeStatsDays)
+	today := timezone.Today()
+	daily, err := s.repo.ListDaily(ctx, groupID, today.AddDate(0, 0, -(days-1)), today)
+	if err != nil {
+		return nil, err
+	}
+	return summarizeGroupHedgeStats(groupID, days, daily), nil
+}
+
+func summarizeGroupHedgeStats(groupID int64, days int, daily []GroupHedgeDailyStat) *GroupHedgeStatsSummary {
+	out := &GroupHedgeStatsSummary{GroupID: groupID, Days: days, Daily: daily}
+	if out.Daily == nil {
+		out.Daily = []GroupHedgeDailyStat{}
+	}
+	for _, d := range daily {
+		out.HedgedCount += d.HedgedCount
+		out.HedgeWinCount += d.HedgeWinCount
+		out.PrimaryWinCount += d.PrimaryWinCount
+		out.HedgeCost += d.HedgeCost
+	}
+	if out.HedgedCount > 0 {
+		out.HedgeWinRate = float64(out.HedgeWinCount) / float64(out.HedgedCount)
+	}
+	return out
+}
@@ -90,3 +90,17 @@ func TestGroup_GetImagePrice_PartialConfig(t *testing.T) {
 	require.Nil(t, group.GetImagePrice("2K"))
 	require.Nil(t, group.GetImagePrice("4K"))
 }
+
+// TestGroup_AllowsGeminiNative 测试 Gemini 原生接入仅对 anthropic / openai 分组生效
+func TestGroup_AllowsGeminiNative(t *testing.T) {
+	var nilGroup *Group
+	require.False(t, nilGroup.AllowsGeminiNative())
+	require.False(t, (&Group{Platform: PlatformAnthropic}).AllowsGeminiNative())
+	require.True(t, (&Group{Platform: PlatformAnthropic, AllowGeminiNative: true}).AllowsGeminiNative())
+	require.True(t, (&Group{Platform: PlatformOpenAI, AllowGeminiNative: true}).AllowsGeminiNative())
+	require.False(t, (&Group{Platform: PlatformGemini, AllowGeminiNative: true}).AllowsGeminiNative())
+
+	group := &Group{Platform: PlatformAntigravity, AllowGeminiNative: true}
+	sanitizeGroupGeminiNativeField(group)
+	require.False(t, group.AllowGeminiNative)
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	dbent "github.com/Wei-Shaw/sub2api/ent"
+	"github.com/Wei-Shaw/sub2api/ent/apikey"
+	"github.com/Wei-Shaw/sub2api/ent/group"
+	"github.com/Wei-Shaw/sub2api/internal/domain"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+	gocache "github.com/patrickmn/go-cache"
+	"golang.org/x/sync/singleflight"
+)
+
+type VolumeTier = domain.VolumeTier
+
+const (
+	maxGroupVolumeTiers = 16
+
+	// defaultVolumeTierSpendCacheTTL 月累计消费快照的缓存时长。本实例计费后会把 actual_cost
+	// 累加进快照，TTL 只用于吸收其他实例写入的消费。
+	defaultVolumeTierSpendCacheTTL = time.Minute
+)
+
+// NormalizeVolumeTiers 校验并归一化分组的月度消费阶梯，CreateGroup 与 UpdateGroup 共用。
+//   - 空列表表示不启用阶梯计费，统一落库为空数组；
+//   - up_to_usd 必须为正且严格递增，只有最后一档可以（也必须）为空，表示无上限；
+//   - multiplier 必须为非负有限数，与分组/用户倍率相乘后作为最终倍率。
+func NormalizeVolumeTiers(raw []VolumeTier) ([]VolumeTier, error) {
+	if len(raw) == 0 {
+		return []VolumeTier{}, nil
+	}
+	if len(raw) > maxGroupVolumeTiers {
+		return nil, fmt.Errorf("volume tiers cannot exceed %d entries", maxGroupVolumeTiers)
+	}
+	tiers := make([]VolumeTier, 0, len(raw))
+	prevUpTo := 0.0
+	for i, tier := range raw {
+		if math.IsNaN(tier.Multiplier) || math.IsInf(tier.Multiplier, 0) || tier.Multiplier < 0 {
+			return nil, fmt.Errorf("volume tier %d multiplier must be a non-negative number", i+1)
+		}
+		last := i == len(raw)-1
+		if tier.UpToUSD == nil {
+			if !last {
+				return nil, fmt.Errorf("volume tier %d must set up_to_usd; only the last tier is open-ended", i+1)
+			}
+			tiers = append(tiers, VolumeTier{Multiplier: tier.Multiplier})
+			continue
+		}
+		if last {
+			return nil, errors.New("the last volume tier must leave up_to_usd empty")
+		}
+		upTo := *tier.UpToUSD
+		if math.IsNaN(upTo) || math.IsInf(upTo, 0) || upTo <= prevUpTo {
+			return nil, fmt.Errorf("volume tier %d up_to_usd must be greater than %v", i+1, prevUpTo)
+		}
+		prevUpTo = upTo
+		tiers = append(tiers, VolumeTier{UpToUSD: &upTo, Multiplier: tier.Multiplier})
+	}
+	return tiers, nil
+}
+
+// VolumeTierAt 返回当月累计消费 monthSpend 所处的阶梯下标与倍率。
+// 阶梯按请求开始前的累计消费整体判定：一次请求不会跨档拆分计价。
+// 未配置阶梯时返回 (0, 1.0, false)。
+func (g *Group) VolumeTierAt(monthSpend float64) (index int, multiplier float64, ok bool) {
+	if g == nil || len(g.VolumeTiers) == 0 {
+		return 0, 1.0, false
+	}
+	for i, tier := range g.VolumeTiers {
+		if tier.UpToUSD == nil || monthSpend < *tier.UpToUSD {
+			return i, tier.Multiplier, true
+		}
+	}
+	// 存量脏数据（最后一档带上限）兜底按最后一档计费。
+	last := len(g.VolumeTiers) - 1
+	return last, g.VolumeTiers[last].Multiplier, true
+}
+
+// VolumeTierLabel 返回写入 usage_logs.billing_tier 的阶梯标签，从 1 开始编号。
+func VolumeTierLabel(index int) string {
+	return fmt.Sprintf("volume_t%d", index+1)
+}
+
+// UserGroupMonthlySpendReader 读取用户在分组内自 since 起的累计 actual_cost。
+// 由 usage log 仓储实现，计费路径通过类型断言获取，未实现时阶梯按零消费判定。
+type UserGroupMonthlySpendReader interface {
+	GetUserGroupSpendSince(ctx context.Context, userID, groupID int64, since time.Time) (float64, error)
+}
+
+// volumeTierResolver 缓存 user:group 的当月累计消费，供阶梯计费在热路径上判档。
+type volumeTierResolver struct {
+	reader   UserGroupMonthlySpendReader
+	cache    *gocache.Cache
+	cacheTTL time.Duration
+	sf       singleflight.Group
+}
+
+func newVolumeTierResolver(repo UsageLogRepository) *volumeTierResolver {
+	r := &volumeTierResolver{
+		cache:    gocache.New(defaultVolumeTierSpendCacheTTL, time.Minute),
+		cacheTTL: defaultVolumeTierSpendCacheTTL,
+	}
+	if reader, ok := repo.(UserGroupMonthlySpendReader); ok {
+		r.reader = reader
+	}
+	return r
+}
+
+func volumeTierSpendCacheKey(userID, groupID int64, monthStart time.Time) string {
+	return fmt.Sprintf("%d:%d:%s", userID, groupID, monthStart.Format("2006-01"))
+}
+
+// MonthSpend 返回 now 所在自然月（系统时区）内用户在分组的累计消费。
+func (r *volumeTierResolver) MonthSpend(ctx context.Context, userID, groupID int64, now time.Time) (float64, error) {
+	if r == nil || r.reader == nil || userID <= 0 || groupID <= 0 {
+		return 0, nil
+	}
+	monthStart := timezone.StartOfMonth(now)
+	key := volumeTierSpendCacheKey(userID, groupID, monthStart)
+	if cached, ok := r.cache.Get(key); ok {
+		if spend, castOK := cached.(float64); castOK {
+			return spend, nil
+		}
+	}
+	value, err, _ := r.sf.Do(key, func() (any, error) {
+		if cached, ok := r.cache.Get(key); ok {
+			if spend, castOK := cached.(float64); castOK {
+				return spend, nil
+			}
+		}
+		spend, err := r.reader.GetUserGroupSpendSince(ctx, userID, groupID, monthStart)
+		if err != nil {
+			return nil, err
+		}
+		r.cache.Set(key, spend, r.cacheTTL)
+		return spend, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	spend, _ := value.(float64)
+	return spend, nil
+}
+
+// Add 把刚计费的 actual_cost 累加进本实例的月消费快照，避免 TTL 内持续按旧档计费。
+// 快照不存在时不做任何事，下次读取会从数据库重新汇总。
+func (r *volumeTierResolver) Add(userID, groupID int64, now time.Time, cost float64) {
+	if r == nil || userID <= 0 || groupID <= 0 || cost <= 0 {
+		return
+	}
+	key := volumeTierSpendCacheKey(userID, groupID, timezone.StartOfMonth(now))
+	_ = r.cache.IncrementFloat(key, cost)
+}
+
+// applyGroupVolumeTier 在基础倍率（系统/分组/用户级，不含高峰）上叠加月度消费阶梯倍率，
+// 返回叠加后的倍率与写入 usage_logs.billing_tier 的标签（未配置阶梯时为 nil）。
+// 月消费读取失败时按第一档计费，宁可少给折扣也不阻断计费。
+func applyGroupVolumeTier(ctx context.Context, resolver *volumeTierResolver, apiKey *APIKey, userID int64, base float64, now time.Time) (float64, *string) {
+	if apiKey == nil || apiKey.Group == nil || apiKey.GroupID == nil || len(apiKey.Group.VolumeTiers) == 0 {
+		return base, nil
+	}
+	spend, err := resolver.MonthSpend(ctx, userID, *apiKey.GroupID, now)
+	if err != nil {
+		logger.LegacyPrintf("service.volume_tier", "load month spend failed, fallback to first tier: user=%d group=%d err=%v", userID, *apiKey.GroupID, err)
+		spend = 0
+	}
+	index, tierMultiplier, _ := apiKey.Group.VolumeTierAt(spend)
+	label := VolumeTierLabel(index)
+	return base * tierMultiplier, &label
+}
+
+// recordGroupVolumeTierSpend 在用量记录后累加阶梯快照。
+func recordGroupVolumeTierSpend(resolver *volumeTierResolver, apiKey *APIKey, userID int64, now time.Time, cost float64) {
+	if apiKey == nil || apiKey.Group == nil || apiKey.GroupID == nil || len(apiKey.Group.VolumeTiers) == 0 {
+		return
+	}
+	resolver.Add(userID, *apiKey.GroupID, now, cost)
+}
+
+// UserVolumeTierProgress 用户在某个阶梯计费分组内的当月进度，供用户仪表盘展示。
+type UserVolumeTierProgress struct {
+	GroupID           int64        `json:"group_id"`
+	GroupName         string       `json:"group_name"`
+	MonthStart        time.Time    `json:"month_start"`
+	MonthSpend        float64      `json:"month_spend"`
+	CurrentTier       int          `json:"current_tier"` // 从 1 开始
+	CurrentMultiplier float64      `json:"current_multiplier"`
+	NextTierAtUSD     *float64     `json:"next_tier_at_usd,omitempty"`
+	Tiers             []VolumeTier `json:"tiers"`
+}
+
+// GetUserVolumeTierProgress 返回用户 API Key 所绑定、且启用了月度消费阶梯的分组的当月进度。
+func (s *UsageService) GetUserVolumeTierProgress(ctx context.Context, userID int64) ([]UserVolumeTierProgress, error) {
+	progress := []UserVolumeTierProgress{}
+	if s == nil || s.entClient == nil || userID <= 0 {
+		return progress, nil
+	}
+	keyGroupIDs, err := s.entClient.APIKey.Query().
+		Where(apikey.UserIDEQ(userID), apikey.DeletedAtIsNil(), apikey.GroupIDNotNil()).
+		Unique(true).
+		Select(apikey.FieldGroupID).
+		Ints(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list api key groups: %w", err)
+	}
+	if len(keyGroupIDs) == 0 {
+		return progress, nil
+	}
+	groupIDs := make([]int64, 0, len(keyGroupIDs))
+	for _, id := range keyGroupIDs {
+		groupIDs = append(groupIDs, int64(id))
+	}
+	groups, err := s.entClient.Group.Query().
+		Where(group.IDIn(groupIDs...), group.DeletedAtIsNil()).
+		Order(dbent.Asc(group.FieldSortOrder), dbent.Asc(group.FieldID)).
+		All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list groups: %w", err)
+	}
+
+	reader, _ := s.usageRepo.(UserGroupMonthlySpendReader)
+	now := timezone.Now()
+	monthStart := timezone.StartOfMonth(now)
+	for _, g := range groups {
+		if len(g.VolumeTiers) == 0 {
+			continue
+		}
+		spend := 0.0
+		if reader != nil {
+			if spend, err = reader.GetUserGroupSpendSince(ctx, userID, g.ID, monthStart); err != nil {
+				return nil, fmt.Errorf("load month spend: %w", err)
+			}
+		}
+		tiered := &Group{VolumeTiers: g.VolumeTiers}
+		index, multiplier, _ := tiered.VolumeTierAt(spend)
+		item := UserVolumeTierProgress{
+			GroupID:           g.ID,
+			GroupName:         g.Name,
+			MonthStart:        monthStart,
+			MonthSpend:        spend,
+			CurrentTier:       index + 1,
+			CurrentMultiplier: multiplier,
+			Tiers:             g.VolumeTiers,
+		}
+		if upTo := g.VolumeTiers[index].UpToUSD; upTo != nil {
+			next := *upTo
+			item.NextTierAtUSD = &next
+		}
+		progress = append(progress, item)
+	}
+	return progress, nil
+}
@@ -0,0 +1,164 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type monthlySpendReaderStub struct {
+	UsageLogRepository
+
+	spend float64
+	err   error
+	calls int
+	since time.Time
+}
+
+func (s *monthlySpendReaderStub) GetUserGroupSpendSince(ctx context.Context, userID, groupID int64, since time.Time) (float64, error) {
+	s.calls++
+	s.since = since
+	if s.err != nil {
+		return 0, s.err
+	}
+	return s.spend, nil
+}
+
+func volumeTierUSD(v float64) *float64 { return &v }
+
+func sampleVolumeTiers() []VolumeTier {
+	return []VolumeTier{
+		{UpToUSD: volumeTierUSD(100), Multiplier: 1.0},
+		{UpToUSD: volumeTierUSD(500), Multiplier: 0.9},
+		{Multiplier: 0.8},
+	}
+}
+
+func volumeTierAPIKey(tiers []VolumeTier) *APIKey {
+	groupID := int64(9)
+	return &APIKey{
+		ID:      1,
+		GroupID: &groupID,
+		Group:   &Group{ID: groupID, RateMultiplier: 1, VolumeTiers: tiers},
+	}
+}
+
+func TestNormalizeVolumeTiers(t *testing.T) {
+	tiers, err := NormalizeVolumeTiers(nil)
+	require.NoError(t, err)
+	require.NotNil(t, tiers)
+	require.Empty(t, tiers)
+
+	tiers, err = NormalizeVolumeTiers(sampleVolumeTiers())
+	require.NoError(t, err)
+	require.Len(t, tiers, 3)
+	require.Nil(t, tiers[2].UpToUSD)
+
+	cases := map[string][]VolumeTier{
+		"non increasing": {
+			{UpToUSD: volumeTierUSD(100), Multiplier: 1},
+			{UpToUSD: volumeTierUSD(100), Multiplier: 0.9},
+			{Multiplier: 0.8},
+		},
+		"last bounded":  {{UpToUSD: volumeTierUSD(100), Multiplier: 1}},
+		"middle open":   {{Multiplier: 1}, {Multiplier: 0.9}},
+		"negative rate": {{Multiplier: -1}},
+		"zero bound":    {{UpToUSD: volumeTierUSD(0), Multiplier: 1}, {Multiplier: 0.9}},
+	}
+	for name, raw := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := NormalizeVolumeTiers(raw)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestGroupVolumeTierAt(t *testing.T) {
+	group := &Group{VolumeTiers: sampleVolumeTiers()}
+
+	for _, tc := range []struct {
+		spend      float64
+		index      int
+		multiplier float64
+	}{
+		{spend: 0, index: 0, multiplier: 1.0},
+		{spend: 99.99, index: 0, multiplier: 1.0},
+		{spend: 100, index: 1, multiplier: 0.9},
+		{spend: 499, index: 1, multiplier: 0.9},
+		{spend: 500, index: 2, multiplier: 0.8},
+		{spend: 10000, index: 2, multiplier: 0.8},
+	} {
+		index, multiplier, ok := group.VolumeTierAt(tc.spend)
+		require.True(t, ok)
+		require.Equal(t, tc.index, index, "spend=%v", tc.spend)
+		require.InDelta(t, tc.multiplier, multiplier, 1e-12, "spend=%v", tc.spend)
+	}
+
+	index, multiplier, ok := (&Group{}).VolumeTierAt(1000)
+	require.False(t, ok)
+	require.Equal(t, 0, index)
+	require.Equal(t, 1.0, multiplier)
+}
+
+func TestApplyGroupVolumeTier(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	reader := &monthlySpendReaderStub{spend: 150}
+	resolver := newVolumeTierResolver(reader)
+	multiplier, label := applyGroupVolumeTier(context.Background(), resolver, volumeTierAPIKey(sampleVolumeTiers()), 42, 2.0, now)
+	require.InDelta(t, 1.8, multiplier, 1e-12)
+	require.NotNil(t, label)
+	require.Equal(t, "volume_t2", *label)
+	require.Equal(t, 1, reader.calls)
+
+	multiplier, label = applyGroupVolumeTier(context.Background(), resolver, volumeTierAPIKey(nil), 42, 2.0, now)
+	require.Equal(t, 2.0, multiplier)
+	require.Nil(t, label)
+	require.Equal(t, 1, reader.calls, "groups without tiers must not query spend")
+
+	failing := newVolumeTierResolver(&monthlySpendReaderStub{err: errors.New("db down")})
+	multiplier, label = applyGroupVolumeTier(context.Background(), failing, volumeTierAPIKey(sampleVolumeTiers()), 42, 2.0, now)
+	require.InDelta(t, 2.0, multiplier, 1e-12)
+	require.Equal(t, "volume_t1", *label)
+}
+
+func TestVolumeTierResolverCachesAndAccumulates(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	reader := &monthlySpendReaderStub{spend: 480}
+	resolver := newVolumeTierResolver(reader)
+	apiKey := volumeTierAPIKey(sampleVolumeTiers())
+
+	_, label := applyGroupVolumeTier(context.Background(), resolver, apiKey, 42, 1.0, now)
+	require.Equal(t, "volume_t2", *label)
+
+	recordGroupVolumeTierSpend(resolver, apiKey, 42, now, 25)
+	multiplier, label := applyGroupVolumeTier(context.Background(), resolver, apiKey, 42, 1.0, now)
+	require.Equal(t, "volume_t3", *label)
+	require.InDelta(t, 0.8, multiplier, 1e-12)
+	require.Equal(t, 1, reader.calls, "cached month spend must absorb local charges")
+
+	nextMonth := now.AddDate(0, 1, 0)
+	_, label = applyGroupVolumeTier(context.Background(), resolver, apiKey, 42, 1.0, nextMonth)
+	require.Equal(t, 2, reader.calls, "a new month must reload spend")
+	require.Equal(t, "volume_t2", *label)
+}
+
+func TestVolumeTiers_SnapshotRoundTrip(t *testing.T) {
+	apiKey := &APIKey{
+		User:  &User{ID: 1, Status: StatusActive, Role: RoleUser},
+		Group: &Group{ID: 9, Platform: PlatformAnthropic, Status: StatusActive, Hydrated: true, VolumeTiers: sampleVolumeTiers()},
+	}
+	svc := &APIKeyService{}
+
+	snapshot := svc.snapshotFromAPIKey(context.Background(), apiKey)
+	require.NotNil(t, snapshot)
+	require.NotNil(t, snapshot.Group)
+	restored := svc.snapshotToAPIKey("k", snapshot)
+	require.NotNil(t, restored.Group)
+	require.Equal(t, sampleVolumeTiers(), restored.Group.VolumeTiers)
+}
@@ -0,0 +1,265 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+const (
+	MessageBatchStatusCreated    = "created"
+	MessageBatchStatusInProgress = "in_progress"
+	MessageBatchStatusCanceling  = "canceling"
+	MessageBatchStatusSettling   = "settling"
+	MessageBatchStatusCompleted  = "completed"
+	MessageBatchStatusFailed     = "failed"
+	// MessageBatchStatusSettlementStuck 结算重试耗尽：冻结保持不动，等待人工处理。
+	MessageBatchStatusSettlementStuck = "settlement_stuck"
+)
+
+// Anthropic processing_status 取值。
+const (
+	MessageBatchProcessingInProgress = "in_progress"
+	MessageBatchProcessingCanceling  = "canceling"
+	MessageBatchProcessingEnded      = "ended"
+)
+
+const (
+	messageBatchIDPrefix            = "msgbatch_"
+	messageBatchHoldRequestPrefix   = "message_batch_hold:"
+	messageBatchCapturePrefix       = "message_batch_capture:"
+	messageBatchReleasePrefix       = "message_batch_release:"
+	messageBatchUsageRequestPrefix  = "message_batch_usage:"
+	messageBatchInboundEndpoint     = "/v1/messages/batches"
+	messageBatchMaxErrorMessageSize = 1000
+)
+
+var (
+	ErrMessageBatchNotFound           = infraerrors.New(http.StatusNotFound, "MESSAGE_BATCH_NOT_FOUND", "message batch not found")
+	ErrMessageBatchExists             = infraerrors.New(http.StatusConflict, "MESSAGE_BATCH_EXISTS", "message batch already exists")
+	ErrMessageBatchDisabled           = infraerrors.New(http.StatusNotFound, "MESSAGE_BATCH_DISABLED", "Message Batches API is not enabled")
+	ErrMessageBatchGroupUnsupported   = infraerrors.New(http.StatusNotFound, "MESSAGE_BATCH_GROUP_UNSUPPORTED", "Message Batches API is not supported for this platform")
+	ErrMessageBatchInvalidRequests    = infraerrors.New(http.StatusBadRequest, "MESSAGE_BATCH_INVALID_REQUESTS", "requests must be a non-empty array of {custom_id, params}")
+	ErrMessageBatchTooManyRequests    = infraerrors.New(http.StatusBadRequest, "MESSAGE_BATCH_TOO_MANY_REQUESTS", "too many requests in message batch")
+	ErrMessageBatchDuplicateCustomID  = infraerrors.New(http.StatusBadRequest, "MESSAGE_BATCH_DUPLICATE_CUSTOM_ID", "custom_id must be unique within a message batch")
+	ErrMessageBatchModelNotAllowed    = infraerrors.New(http.StatusForbidden, "MESSAGE_BATCH_MODEL_NOT_ALLOWED", "model is not allowed for this API key")
+	ErrMessageBatchNoAccountAvailable = infraerrors.New(http.StatusServiceUnavailable, "MESSAGE_BATCH_NO_ACCOUNT_AVAILABLE", "no Anthropic API key account is available for message batches")
+	ErrMessageBatchPricingMissing     = infraerrors.New(http.StatusBadRequest, "MESSAGE_BATCH_PRICING_MISSING", "pricing is not available for the requested model")
+	ErrMessageBatchInsufficientFunds  = infraerrors.New(http.StatusPaymentRequired, "MESSAGE_BATCH_INSUFFICIENT_BALANCE", "insufficient balance for message batch hold")
+	ErrMessageBatchBillingFailed      = infraerrors.New(http.StatusBadGateway, "MESSAGE_BATCH_BILLING_FAILED", "message batch billing failed")
+	ErrMessageBatchUpstreamFailed     = infraerrors.New(http.StatusBadGateway, "MESSAGE_BATCH_UPSTREAM_FAILED", "upstream message batch request failed")
+	ErrMessageBatchInvalidTransition  = infraerrors.New(http.StatusConflict, "MESSAGE_BATCH_INVALID_TRANSITION", "message batch is not in a state that allows this operation")
+	ErrMessageBatchResultsNotReady    = infraerrors.New(http.StatusConflict, "MESSAGE_BATCH_RESULTS_NOT_READY", "message batch is still processing; results are not available yet")
+	ErrMessageBatchIdempotencyReuse   = infraerrors.New(http.StatusConflict, "MESSAGE_BATCH_IDEMPOTENCY_CONFLICT", "idempotency key reused with a different message batch request")
+)
+
+// MessageBatchRequestCounts 对应 Anthropic request_counts。
+type MessageBatchRequestCounts struct {
+	Processing int `json:"processing"`
+	Succeeded  int `json:"succeeded"`
+	Errored    int `json:"errored"`
+	Canceled   int `json:"canceled"`
+	Expired    int `json:"expired"`
+}
+
+// MessageBatchJob 是网关侧的批次记录；上游批次 ID 与账号不对外暴露。
+type MessageBatchJob struct {
+	ID                      int64
+	BatchID                 string
+	UserID                  int64
+	APIKeyID                int64
+	AccountID               int64
+	GroupID                 *int64
+	UpstreamBatchID         *string
+	Status                  string
+	RequestCount            int
+	Counts                  MessageBatchRequestCounts
+	EstimatedCost           float64
+	HoldAmount              float64
+	ActualCost              *float64
+	GroupRateMultiplier     float64
+	AccountRateMultiplier   float64
+	BatchDiscountMultiplier float64
+	InputTokens             int64
+	OutputTokens            int64
+	CacheCreationTokens     int64
+	CacheReadTokens         int64
+	IdempotencyKey          *string
+	RequestHash             string
+	RetryCount              int
+	LastErrorCode           *string
+	LastErrorMessage        *string
+	NextPollAt              *time.Time
+	ExpiresAt               *time.Time
+	EndedAt                 *time.Time
+	CancelInitiatedAt       *time.Time
+	SettledAt               *time.Time
+	CreatedAt               time.Time
+	UpdatedAt               time.Time
+}
+
+// MessageBatchProgress 是一次上游状态同步要写回的字段；时间字段为 nil 时保留原值。
+type MessageBatchProgress struct {
+	Status            string
+	Counts            MessageBatchRequestCounts
+	ExpiresAt         *time.Time
+	EndedAt           *time.Time
+	CancelInitiatedAt *time.Time
+	NextPollAt        *time.Time
+}
+
+type MarkMessageBatchJobSettledParams struct {
+	BatchID             string
+	ActualCost          float64
+	InputTokens         int64
+	OutputTokens        int64
+	CacheCreationTokens int64
+	CacheReadTokens     int64
+	SettledAt           time.Time
+}
+
+// MessageBatchListQuery 对应 Anthropic 列表分页参数（before_id / after_id / limit）。
+type MessageBatchListQuery struct {
+	BeforeID string
+	AfterID  string
+	Limit    int
+}
+
+type MessageBatchRepository interface {
+	CreateMessageBatchJob(ctx context.Context, job *MessageBatchJob) error
+	GetMessageBatchJob(ctx context.Context, batchID string) (*MessageBatchJob, error)
+	GetMessageBatchJobForOwner(ctx context.Context, apiKeyID int64, batchID string) (*MessageBatchJob, error)
+	GetMessageBatchJobByIdempotencyKey(ctx context.Context, apiKeyID int64, key string) (*MessageBatchJob, error)
+	// ListMessageBatchJobsForOwner 只返回已提交到上游的批次，按离游标由近到远排序：
+	// 默认与 after_id 为 id 降序，before_id 为 id 升序。
+	ListMessageBatchJobsForOwner(ctx context.Context, apiKeyID int64, query MessageBatchListQuery) ([]*MessageBatchJob, error)
+	// MarkMessageBatchJobSubmitted 把 created 批次绑定到上游批次并转入 in_progress。
+	MarkMessageBatchJobSubmitted(ctx context.Context, batchID, upstreamBatchID string, progress MessageBatchProgress) error
+	// UpdateMessageBatchJobProgress 只更新 in_progress / canceling 批次，否则返回 ErrMessageBatchInvalidTransition。
+	UpdateMessageBatchJobProgress(ctx context.Context, batchID string, progress MessageBatchProgress) error
+	MarkMessageBatchJobSettled(ctx context.Context, params MarkMessageBatchJobSettledParams) error
+	MarkMessageBatchJobFailed(ctx context.Context, batchID, code, message string) error
+	// MarkMessageBatchJobSettlementStuck 把 settling 批次转入 settlement_stuck 终态，不再轮询。
+	MarkMessageBatchJobSettlementStuck(ctx context.Context, batchID, code, message string) error
+	// RecordMessageBatchJobError 记录一次可重试错误并推迟下次轮询，返回递增后的 retry_count。
+	RecordMessageBatchJobError(ctx context.Context, batchID, code, message string, nextPollAt time.Time) (int, error)
+	// ClaimDueMessageBatchJobs 领取到期待轮询的批次，并把 next_poll_at 推迟 lease，
+	// 多实例部署时同一批次在 lease 内只会被一个实例处理。
+	ClaimDueMessageBatchJobs(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*MessageBatchJob, error)
+}
+
+// MessageBatchOwner 标识发起请求的 API Key。
+type MessageBatchOwner struct {
+	UserID   int64
+	APIKeyID int64
+	GroupID  *int64
+	APIKey   *APIKey
+}
+
+// MessageBatchPublic 是返回给客户端的 Anthropic message_batch 对象。
+type MessageBatchPublic struct {
+	ID                string                    `json:"id"`
+	Type              string                    `json:"type"`
+	ProcessingStatus  string                    `json:"processing_status"`
+	RequestCounts     MessageBatchRequestCounts `json:"request_counts"`
+	EndedAt           *string                   `json:"ended_at"`
+	CreatedAt         string                    `json:"created_at"`
+	ExpiresAt         *string                   `json:"expires_at"`
+	ArchivedAt        *string                   `json:"archived_at"`
+	CancelInitiatedAt *string                   `json:"cancel_initiated_at"`
+	ResultsURL        *string                   `json:"results_url"`
+}
+
+type MessageBatchListResponse struct {
+	Data    []*MessageBatchPublic `json:"data"`
+	HasMore bool                  `json:"has_more"`
+	FirstID *string               `json:"first_id"`
+	LastID  *string               `json:"last_id"`
+}
+
+func NewMessageBatchID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return messageBatchIDPrefix + hex.EncodeToString(b[:]), nil
+}
+
+func MessageBatchHoldRequestID(batchID string) string {
+	return messageBatchHoldRequestPrefix + strings.TrimSpace(batchID)
+}
+
+func MessageBatchCaptureRequestID(batchID string) string {
+	return messageBatchCapturePrefix + strings.TrimSpace(batchID)
+}
+
+func MessageBatchReleaseRequestID(batchID string) string {
+	return messageBatchReleasePrefix + strings.TrimSpace(batchID)
+}
+
+// IsPendingMessageBatchStatus 报告批次是否仍需后台轮询处理。
+func IsPendingMessageBatchStatus(status string) bool {
+	switch status {
+	case MessageBatchStatusCreated, MessageBatchStatusInProgress, MessageBatchStatusCanceling, MessageBatchStatusSettling:
+		return true
+	default:
+		return false
+	}
+}
+
+// PublicMessageBatchProcessingStatus 把内部状态映射为 Anthropic processing_status：
+// 上游结束后的结算中/已结算/结算失败对客户端都是 ended。
+func PublicMessageBatchProcessingStatus(status string) string {
+	switch status {
+	case MessageBatchStatusCreated, MessageBatchStatusInProgress:
+		return MessageBatchProcessingInProgress
+	case MessageBatchStatusCanceling:
+		return MessageBatchProcessingCanceling
+	default:
+		return MessageBatchProcessingEnded
+	}
+}
+
+// MessageBatchJobToPublic 转换为 Anthropic 格式；resultsBaseURL 为网关对外地址
+// （如 https://gw.example.com），批次结束后 results_url 指向网关而非上游。
+func MessageBatchJobToPublic(job *MessageBatchJob, resultsBaseURL string) *MessageBatchPublic {
+	if job == nil {
+		return nil
+	}
+	out := &MessageBatchPublic{
+		ID:                job.BatchID,
+		Type:              "message_batch",
+		ProcessingStatus:  PublicMessageBatchProcessingStatus(job.Status),
+		RequestCounts:     job.Counts,
+		EndedAt:           messageBatchTimeString(job.EndedAt),
+		CreatedAt:         job.CreatedAt.UTC().Format(time.RFC3339),
+		ExpiresAt:         messageBatchTimeString(job.ExpiresAt),
+		CancelInitiatedAt: messageBatchTimeString(job.CancelInitiatedAt),
+	}
+	if out.ProcessingStatus == MessageBatchProcessingEnded && job.UpstreamBatchID != nil {
+		resultsURL := strings.TrimRight(resultsBaseURL, "/") + messageBatchInboundEndpoint + "/" + job.BatchID + "/results"
+		out.ResultsURL = &resultsURL
+	}
+	return out
+}
+
+func messageBatchTimeString(t *time.Time) *string {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	s := t.UTC().Format(time.RFC3339)
+	return &s
+}
+
+func messageBatchStringPtr(v string) *string {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return nil
+	}
+	return &v
+}
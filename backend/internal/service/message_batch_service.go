@@ -0,0 +1,759 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultMessageBatchDiscountMultiplier = 0.5
+	defaultMessageBatchHoldMultiplier     = 1.0
+	defaultMessageBatchMaxRequests        = 10000
+	defaultMessageBatchPollInterval       = time.Minute
+	defaultMessageBatchPollBatchSize      = 50
+	defaultMessageBatchUpstreamTimeout    = 5 * time.Minute
+	defaultMessageBatchListLimit          = 20
+	maxMessageBatchListLimit              = 1000
+	messageBatchAnthropicVersion          = "2023-06-01"
+	messageBatchMaxUpstreamErrorBody      = 64 * 1024
+	// messageBatchCreatedStaleAfter created 批次（已冻结、尚未拿到上游 ID）超过该时长
+	// 仍未推进，视为提交进程中途退出，由轮询释放冻结余额。
+	messageBatchCreatedStaleAfter = 15 * time.Minute
+)
+
+// MessageBatchCostCalculator 计算单个模型按 token 的标准价（BillingService 实现）。
+type MessageBatchCostCalculator interface {
+	CalculateCost(model string, tokens UsageTokens, rateMultiplier float64) (*CostBreakdown, error)
+}
+
+// MessageBatchService 代理 Anthropic Message Batches API。
+//
+// 批次在创建时绑定到一个 Anthropic API Key 账号，后续查询、取消与结果下载都
+// 走同一账号。创建时按「请求体字节数作为输入 token 上限 + max_tokens」估算标准价
+// 并冻结余额；批次结束后由后台轮询扫描结果中的 usage，按批量折扣价结算，
+// 多冻结部分退回。
+type MessageBatchService struct {
+	Repo              MessageBatchRepository
+	AccountRepo       BatchImageAccountSelectionRepository
+	GroupRepo         BatchImageGroupPricingRepository
+	UserGroupRateRepo BatchImageUserGroupRateRepository
+	BillingRepo       UsageBillingRepository
+	UsageLogRepo      UsageLogRepository
+	Pricing           MessageBatchCostCalculator
+	HTTPUpstream      HTTPUpstream
+	AuthCache         APIKeyAuthCacheInvalidator
+	Config            *config.Config
+
+	now func() time.Time
+
+	poller batchSettlementPoller
+	// volumeTiers 缓存分组月度消费阶梯的判档快照，结算时按阶梯计价。
+	volumeTiers *volumeTierResolver
+}
+
+func NewMessageBatchService(
+	repo MessageBatchRepository,
+	accountRepo AccountRepository,
+	groupRepo GroupRepository,
+	userGroupRateRepo UserGroupRateRepository,
+	billingRepo UsageBillingRepository,
+	usageLogRepo UsageLogRepository,
+	billingService *BillingService,
+	httpUpstream HTTPUpstream,
+	authCache APIKeyAuthCacheInvalidator,
+	cfg *config.Config,
+) *MessageBatchService {
+	return &MessageBatchService{
+		Repo:              repo,
+		AccountRepo:       accountRepo,
+		GroupRepo:         groupRepo,
+		UserGroupRateRepo: userGroupRateRepo,
+		BillingRepo:       billingRepo,
+		UsageLogRepo:      usageLogRepo,
+		Pricing:           billingService,
+		HTTPUpstream:      httpUpstream,
+		AuthCache:         authCache,
+		Config:            cfg,
+		volumeTiers:       newVolumeTierResolver(usageLogRepo),
+	}
+}
+
+// messageBatchRequestSpec 是创建请求中单条 request 的计费相关摘要。
+type messageBatchRequestSpec struct {
+	Index     int
+	CustomID  string
+	Model     string
+	MaxTokens int
+	// ParamsBytes 是 params 的 JSON 字节数，用作输入 token 的上限估计
+	// （任何分词器下 token 数都不超过字节数）。
+	ParamsBytes int
+}
+
+// messageBatchUpstreamBatch 是上游 message_batch 对象中网关关心的字段。
+type messageBatchUpstreamBatch struct {
+	ID                string                    `json:"id"`
+	ProcessingStatus  string                    `json:"processing_status"`
+	RequestCounts     MessageBatchRequestCounts `json:"request_counts"`
+	EndedAt           *time.Time                `json:"ended_at"`
+	ExpiresAt         *time.Time                `json:"expires_at"`
+	CancelInitiatedAt *time.Time                `json:"cancel_initiated_at"`
+}
+
+type messageBatchPricingSnapshot struct {
+	GroupRateMultiplier     float64
+	AccountRateMultiplier   float64
+	BatchDiscountMultiplier float64
+	EstimatedCost           float64
+	HoldAmount              float64
+}
+
+// Create 校验请求、选择账号、冻结余额并向上游提交批次。
+func (s *MessageBatchService) Create(ctx context.Context, owner MessageBatchOwner, body []byte, idempotencyKey string) (*MessageBatchJob, error) {
+	if !s.enabled() {
+		return nil, ErrMessageBatchDisabled
+	}
+	if err := s.ensureGroupSupportsMessageBatches(ctx, owner.GroupID); err != nil {
+		return nil, err
+	}
+	specs, err := parseMessageBatchCreateRequest(body, s.maxRequests())
+	if err != nil {
+		return nil, err
+	}
+	models := messageBatchDistinctModels(specs)
+	if owner.APIKey != nil && owner.APIKey.HasModelRestrictions() {
+		for _, model := range models {
+			if !owner.APIKey.IsModelAllowed(model) {
+				return nil, infraerrors.Newf(http.StatusForbidden, ErrMessageBatchModelNotAllowed.Reason, "model %q is not allowed for this API key", model)
+			}
+		}
+	}
+
+	requestHash := hashMessageBatchRequest(body)
+	idempotencyKey = strings.TrimSpace(idempotencyKey)
+	if idempotencyKey != "" {
+		existing, err := s.Repo.GetMessageBatchJobByIdempotencyKey(ctx, owner.APIKeyID, idempotencyKey)
+		if err == nil {
+			if existing.RequestHash != requestHash {
+				return nil, ErrMessageBatchIdempotencyReuse
+			}
+			if existing.UpstreamBatchID == nil {
+				return nil, ErrMessageBatchNotFound
+			}
+			return existing, nil
+		}
+		if !errors.Is(err, ErrMessageBatchNotFound) {
+			return nil, err
+		}
+	}
+
+	account, err := s.selectAccount(ctx, owner.GroupID, models)
+	if err != nil {
+		return nil, err
+	}
+	pricing, err := s.resolvePricingSnapshot(ctx, owner, account, specs)
+	if err != nil {
+		return nil, err
+	}
+	upstreamBody, err := applyMessageBatchModelMapping(body, specs, account)
+	if err != nil {
+		return nil, ErrMessageBatchInvalidRequests.WithCause(err)
+	}
+
+	batchID, err := NewMessageBatchID()
+	if err != nil {
+		return nil, err
+	}
+	now := s.nowTime()
+	staleAt := now.Add(messageBatchCreatedStaleAfter)
+	job := &MessageBatchJob{
+		BatchID:                 batchID,
+		UserID:                  owner.UserID,
+		APIKeyID:                owner.APIKeyID,
+		AccountID:               account.ID,
+		GroupID:                 owner.GroupID,
+		Status:                  MessageBatchStatusCreated,
+		RequestCount:            len(specs),
+		Counts:                  MessageBatchRequestCounts{Processing: len(specs)},
+		EstimatedCost:           pricing.EstimatedCost,
+		HoldAmount:              pricing.HoldAmount,
+		GroupRateMultiplier:     pricing.GroupRateMultiplier,
+		AccountRateMultiplier:   pricing.AccountRateMultiplier,
+		BatchDiscountMultiplier: pricing.BatchDiscountMultiplier,
+		IdempotencyKey:          messageBatchStringPtr(idempotencyKey),
+		RequestHash:             requestHash,
+		NextPollAt:              &staleAt,
+	}
+	if err := s.Repo.CreateMessageBatchJob(ctx, job); err != nil {
+		return nil, err
+	}
+	settlement := s.settlement()
+	if err := settlement.reserveHold(ctx, job); err != nil {
+		settlement.markFailedBestEffort(ctx, job.BatchID, "BILLING_HOLD_FAILED", err.Error())
+		return nil, err
+	}
+
+	upstream, err := s.submitUpstream(ctx, account, upstreamBody)
+	if err != nil {
+		if releaseErr := settlement.releaseHold(ctx, job); releaseErr != nil {
+			// 释放失败时保持 created 状态，由轮询在 stale 后重试释放。
+			_, _ = s.Repo.RecordMessageBatchJobError(ctx, job.BatchID, "BILLING_RELEASE_FAILED", truncateString(releaseErr.Error(), messageBatchMaxErrorMessageSize), now)
+			return nil, err
+		}
+		settlement.markFailedBestEffort(ctx, job.BatchID, "UPSTREAM_SUBMIT_FAILED", err.Error())
+		return nil, err
+	}
+
+	progress := s.progressFromUpstream(upstream)
+	if err := s.Repo.MarkMessageBatchJobSubmitted(ctx, job.BatchID, upstream.ID, progress); err != nil {
+		// 上游批次已创建：尽力取消，避免在本地记录缺失的情况下继续产生成本；
+		// 冻结余额由轮询在 created 超时后释放。
+		logger.L().Error("message_batch.mark_submitted_failed",
+			zap.String("batch_id", job.BatchID),
+			zap.Int64("account_id", account.ID),
+			zap.Error(err),
+		)
+		if _, cancelErr := s.cancelUpstream(ctx, account, upstream.ID); cancelErr != nil {
+			logger.L().Warn("message_batch.orphan_cancel_failed",
+				zap.String("batch_id", job.BatchID),
+				zap.Error(cancelErr),
+			)
+		}
+		return nil, err
+	}
+	return s.Repo.GetMessageBatchJob(ctx, job.BatchID)
+}
+
+// Get 返回批次；进行中的批次会尽力从上游同步一次最新状态。
+func (s *MessageBatchService) Get(ctx context.Context, owner MessageBatchOwner, batchID string) (*MessageBatchJob, error) {
+	job, err := s.getVisibleJob(ctx, owner, batchID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status == MessageBatchStatusInProgress || job.Status == MessageBatchStatusCanceling {
+		refreshed, refreshErr := s.refreshFromUpstream(ctx, job)
+		if refreshErr == nil {
+			return refreshed, nil
+		}
+		logger.L().Warn("message_batch.refresh_failed",
+			zap.String("batch_id", job.BatchID),
+			zap.Error(refreshErr),
+		)
+	}
+	return job, nil
+}
+
+// List 按创建时间倒序分页列出当前 API Key 的批次。
+func (s *MessageBatchService) List(ctx context.Context, owner MessageBatchOwner, query MessageBatchListQuery) ([]*MessageBatchJob, bool, error) {
+	if !s.enabled() {
+		return nil, false, ErrMessageBatchDisabled
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultMessageBatchListLimit
+	}
+	if limit > maxMessageBatchListLimit {
+		limit = maxMessageBatchListLimit
+	}
+	query.Limit = limit + 1
+	jobs, err := s.Repo.ListMessageBatchJobsForOwner(ctx, owner.APIKeyID, query)
+	if err != nil {
+		return nil, false, err
+	}
+	hasMore := len(jobs) > limit
+	if hasMore {
+		jobs = jobs[:limit]
+	}
+	if query.BeforeID != "" {
+		// before_id 翻页时仓储按离游标由近到远（id 升序）返回，这里恢复为新到旧。
+		for i, j := 0, len(jobs)-1; i < j; i, j = i+1, j-1 {
+			jobs[i], jobs[j] = jobs[j], jobs[i]
+		}
+	}
+	return jobs, hasMore, nil
+}
+
+// Cancel 请求上游取消批次；已在取消中的批次直接返回当前状态。
+func (s *MessageBatchService) Cancel(ctx context.Context, owner MessageBatchOwner, batchID string) (*MessageBatchJob, error) {
+	job, err := s.getVisibleJob(ctx, owner, batchID)
+	if err != nil {
+		return nil, err
+	}
+	switch job.Status {
+	case MessageBatchStatusCanceling:
+		return job, nil
+	case MessageBatchStatusInProgress:
+	default:
+		return nil, ErrMessageBatchInvalidTransition
+	}
+	account, err := s.jobAccount(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+	upstream, err := s.cancelUpstream(ctx, account, *job.UpstreamBatchID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyUpstreamProgress(ctx, job, upstream); err != nil {
+		return nil, err
+	}
+	return s.Repo.GetMessageBatchJob(ctx, job.BatchID)
+}
+
+// OpenResults 打开上游结果 JSONL 流；调用方负责关闭。
+func (s *MessageBatchService) OpenResults(ctx context.Context, owner MessageBatchOwner, batchID string) (io.ReadCloser, error) {
+	job, err := s.getVisibleJob(ctx, owner, batchID)
+	if err != nil {
+		return nil, err
+	}
+	if PublicMessageBatchProcessingStatus(job.Status) != MessageBatchProcessingEnded {
+		return nil, ErrMessageBatchResultsNotReady
+	}
+	account, err := s.jobAccount(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+	return s.openUpstreamResults(ctx, account, *job.UpstreamBatchID)
+}
+
+func (s *MessageBatchService) getVisibleJob(ctx context.Context, owner MessageBatchOwner, batchID string) (*MessageBatchJob, error) {
+	if !s.enabled() {
+		return nil, ErrMessageBatchDisabled
+	}
+	batchID = strings.TrimSpace(batchID)
+	if !strings.HasPrefix(batchID, messageBatchIDPrefix) {
+		return nil, ErrMessageBatchNotFound
+	}
+	job, err := s.Repo.GetMessageBatchJobForOwner(ctx, owner.APIKeyID, batchID)
+	if err != nil {
+		return nil, err
+	}
+	// 未成功提交到上游的批次对客户端不可见。
+	if job.UpstreamBatchID == nil || strings.TrimSpace(*job.UpstreamBatchID) == "" {
+		return nil, ErrMessageBatchNotFound
+	}
+	return job, nil
+}
+
+func (s *MessageBatchService) refreshFromUpstream(ctx context.Context, job *MessageBatchJob) (*MessageBatchJob, error) {
+	account, err := s.jobAccount(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+	upstream, err := s.getUpstream(ctx, account, *job.UpstreamBatchID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyUpstreamProgress(ctx, job, upstream); err != nil {
+		return nil, err
+	}
+	return s.Repo.GetMessageBatchJob(ctx, job.BatchID)
+}
+
+// applyUpstreamProgress 把上游状态写回本地；上游已结束的批次转入 settling 并立即可被轮询结算。
+func (s *MessageBatchService) applyUpstreamProgress(ctx context.Context, job *MessageBatchJob, upstream *messageBatchUpstreamBatch) error {
+	progress := s.progressFromUpstream(upstream)
+	err := s.Repo.UpdateMessageBatchJobProgress(ctx, job.BatchID, progress)
+	if errors.Is(err, ErrMessageBatchInvalidTransition) {
+		// 并发的轮询已推进到 settling 及之后，本次同步无需写回。
+		return nil
+	}
+	return err
+}
+
+func (s *MessageBatchService) progressFromUpstream(upstream *messageBatchUpstreamBatch) MessageBatchProgress {
+	now := s.nowTime()
+	next := now.Add(s.pollInterval())
+	status := MessageBatchStatusInProgress
+	switch upstream.ProcessingStatus {
+	case MessageBatchProcessingCanceling:
+		status = MessageBatchStatusCanceling
+	case MessageBatchProcessingEnded:
+		status = MessageBatchStatusSettling
+		next = now
+	}
+	return MessageBatchProgress{
+		Status:            status,
+		Counts:            upstream.RequestCounts,
+		ExpiresAt:         upstream.ExpiresAt,
+		EndedAt:           upstream.EndedAt,
+		CancelInitiatedAt: upstream.CancelInitiatedAt,
+		NextPollAt:        &next,
+	}
+}
+
+func (s *MessageBatchService) ensureGroupSupportsMessageBatches(ctx context.Context, groupID *int64) error {
+	if groupID == nil || *groupID <= 0 {
+		return nil
+	}
+	if s.GroupRepo == nil {
+		return ErrMessageBatchGroupUnsupported
+	}
+	group, err := s.GroupRepo.GetByIDLite(ctx, *groupID)
+	if err != nil || group == nil {
+		return ErrMessageBatchGroupUnsupported
+	}
+	if group.Platform != PlatformAnthropic {
+		return ErrMessageBatchGroupUnsupported
+	}
+	return nil
+}
+
+// selectAccount 选择支持全部请求模型的可调度 Anthropic API Key 账号。
+// OAuth / Setup Token 账号不支持 Batches API，不参与选择。
+func (s *MessageBatchService) selectAccount(ctx context.Context, groupID *int64, models []string) (*Account, error) {
+	if s.AccountRepo == nil {
+		return nil, ErrMessageBatchNoAccountAvailable
+	}
+	var (
+		accounts []Account
+		err      error
+	)
+	if groupID != nil && *groupID > 0 {
+		accounts, err = s.AccountRepo.ListSchedulableByGroupIDAndPlatform(ctx, *groupID, PlatformAnthropic)
+	} else {
+		accounts, err = s.AccountRepo.ListSchedulableByPlatform(ctx, PlatformAnthropic)
+	}
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(accounts, func(i, j int) bool {
+		if accounts[i].Priority != accounts[j].Priority {
+			return accounts[i].Priority > accounts[j].Priority
+		}
+		return accounts[i].ID < accounts[j].ID
+	})
+	for i := range accounts {
+		account := accounts[i]
+		if account.Type != AccountTypeAPIKey || !account.IsSchedulable() {
+			continue
+		}
+		supported := true
+		for _, model := range models {
+			if !account.IsModelSupported(model) {
+				supported = false
+				break
+			}
+		}
+		if supported {
+			return &account, nil
+		}
+	}
+	return nil, ErrMessageBatchNoAccountAvailable
+}
+
+func (s *MessageBatchService) jobAccount(ctx context.Context, job *MessageBatchJob) (*Account, error) {
+	if s.AccountRepo == nil {
+		return nil, ErrMessageBatchNoAccountAvailable
+	}
+	account, err := s.AccountRepo.GetByID(ctx, job.AccountID)
+	if err != nil || account == nil {
+		return nil, ErrMessageBatchUpstreamFailed.WithCause(fmt.Errorf("load account %d: %w", job.AccountID, err))
+	}
+	return account, nil
+}
+
+func (s *MessageBatchService) resolvePricingSnapshot(ctx context.Context, owner MessageBatchOwner, account *Account, specs []messageBatchRequestSpec) (*messageBatchPricingSnapshot, error) {
+	if s.Pricing == nil {
+		return nil, ErrMessageBatchPricingMissing
+	}
+	groupMultiplier := 1.0
+	if owner.GroupID != nil && *owner.GroupID > 0 && s.GroupRepo != nil {
+		group, err := s.GroupRepo.GetByIDLite(ctx, *owner.GroupID)
+		if err != nil || group == nil {
+			return nil, ErrMessageBatchPricingMissing
+		}
+		groupMultiplier = group.RateMultiplier
+		if s.UserGroupRateRepo != nil {
+			userRate, rateErr := s.UserGroupRateRepo.GetByUserAndGroup(ctx, owner.UserID, group.ID)
+			if rateErr != nil {
+				return nil, ErrMessageBatchPricingMissing
+			}
+			if userRate != nil {
+				groupMultiplier = *userRate
+			}
+		}
+	}
+	if groupMultiplier < 0 {
+		groupMultiplier = 0
+	}
+	accountMultiplier := account.BillingRateMultiplier()
+	if accountMultiplier < 0 {
+		accountMultiplier = 0
+	}
+	discount, hold := s.discountMultiplier(), s.holdMultiplier()
+	// 与批量生图一致的定价不变式：hold 比例不得低于 discount 比例。
+	if hold < discount {
+		hold = discount
+	}
+
+	standard := 0.0
+	for _, spec := range specs {
+		cost, err := s.Pricing.CalculateCost(spec.Model, UsageTokens{
+			InputTokens:  spec.ParamsBytes,
+			OutputTokens: spec.MaxTokens,
+		}, groupMultiplier*accountMultiplier)
+		if err != nil || cost == nil {
+			return nil, infraerrors.Newf(http.StatusBadRequest, ErrMessageBatchPricingMissing.Reason, "pricing is not available for model %q", spec.Model)
+		}
+		standard += cost.ActualCost
+	}
+	return &messageBatchPricingSnapshot{
+		GroupRateMultiplier:     groupMultiplier,
+		AccountRateMultiplier:   accountMultiplier,
+		BatchDiscountMultiplier: discount,
+		EstimatedCost:           standard * discount,
+		HoldAmount:              standard * hold,
+	}, nil
+}
+
+// ---- 上游调用 ----
+
+func (s *MessageBatchService) submitUpstream(ctx context.Context, account *Account, body []byte) (*messageBatchUpstreamBatch, error) {
+	resp, err := s.doUpstream(ctx, account, http.MethodPost, "", body)
+	if err != nil {
+		return nil, err
+	}
+	return decodeMessageBatchUpstreamBatch(resp)
+}
+
+func (s *MessageBatchService) getUpstream(ctx context.Context, account *Account, upstreamID string) (*messageBatchUpstreamBatch, error) {
+	resp, err := s.doUpstream(ctx, account, http.MethodGet, "/"+upstreamID, nil)
+	if err != nil {
+		return nil, err
+	}
+	return decodeMessageBatchUpstreamBatch(resp)
+}
+
+func (s *MessageBatchService) cancelUpstream(ctx context.Context, account *Account, upstreamID string) (*messageBatchUpstreamBatch, error) {
+	resp, err := s.doUpstream(ctx, account, http.MethodPost, "/"+upstreamID+"/cancel", nil)
+	if err != nil {
+		return nil, err
+	}
+	return decodeMessageBatchUpstreamBatch(resp)
+}
+
+func (s *MessageBatchService) openUpstreamResults(ctx context.Context, account *Account, upstreamID string) (io.ReadCloser, error) {
+	resp, err := s.doUpstream(ctx, account, http.MethodGet, "/"+upstreamID+"/results", nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// doUpstream 发起上游请求；非 2xx 响应会被读取并转换为错误，成功时调用方负责关闭 Body。
+func (s *MessageBatchService) doUpstream(ctx context.Context, account *Account, method, subpath string, body []byte) (*http.Response, error) {
+	if s.HTTPUpstream == nil {
+		return nil, ErrMessageBatchUpstreamFailed.WithCause(errors.New("http upstream is not configured"))
+	}
+	baseURL, err := validateUpstreamBaseURLWithConfig(s.Config, account.GetBaseURL())
+	if err != nil {
+		return nil, ErrMessageBatchUpstreamFailed.WithCause(err)
+	}
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(baseURL, "/")+messageBatchInboundEndpoint+subpath, reader)
+	if err != nil {
+		return nil, ErrMessageBatchUpstreamFailed.WithCause(err)
+	}
+	setAnthropicAPIKeyAuthHeader(req.Header, account, account.GetCredential("api_key"))
+	req.Header.Set("anthropic-version", messageBatchAnthropicVersion)
+	if body != nil {
+		req.Header.Set("content-type", "application/json")
+	}
+	account.ApplyHeaderOverrides(req.Header)
+	proxyURL := ""
+	if account.ProxyID != nil && account.Proxy != nil {
+		proxyURL = account.Proxy.URL()
+	}
+	resp, err := s.HTTPUpstream.Do(req, proxyURL, account.ID, account.Concurrency)
+	if err != nil {
+		return nil, ErrMessageBatchUpstreamFailed.WithCause(err)
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer func() { _ = resp.Body.Close() }()
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, messageBatchMaxUpstreamErrorBody))
+	return nil, messageBatchUpstreamError(resp.StatusCode, raw)
+}
+
+// messageBatchUpstreamError 把上游错误转换为对外错误：请求本身的校验错误（400/404/409/413/422）
+// 原样透出上游信息，鉴权/限流/服务端错误统一为网关错误，不暴露账号细节。
+func messageBatchUpstreamError(status int, body []byte) error {
+	message := strings.TrimSpace(gjson.GetBytes(body, "error.message").String())
+	switch status {
+	case http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity:
+		if message == "" {
+			message = http.StatusText(status)
+		}
+		return infraerrors.New(status, "MESSAGE_BATCH_UPSTREAM_REJECTED", message)
+	}
+	return ErrMessageBatchUpstreamFailed.WithCause(fmt.Errorf("upstream status %d: %s", status, truncateString(string(body), 512)))
+}
+
+func decodeMessageBatchUpstreamBatch(resp *http.Response) (*messageBatchUpstreamBatch, error) {
+	defer func() { _ = resp.Body.Close() }()
+	var out messageBatchUpstreamBatch
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, ErrMessageBatchUpstreamFailed.WithCause(fmt.Errorf("decode upstream batch: %w", err))
+	}
+	if strings.TrimSpace(out.ID) == "" {
+		return nil, ErrMessageBatchUpstreamFailed.WithCause(errors.New("upstream batch id is missing"))
+	}
+	return &out, nil
+}
+
+// ---- 请求解析 ----
+
+func parseMessageBatchCreateRequest(body []byte, maxRequests int) ([]messageBatchRequestSpec, error) {
+	if !gjson.ValidBytes(body) {
+		return nil, ErrMessageBatchInvalidRequests
+	}
+	requests := gjson.GetBytes(body, "requests")
+	if !requests.IsArray() {
+		return nil, ErrMessageBatchInvalidRequests
+	}
+	items := requests.Array()
+	if len(items) == 0 {
+		return nil, ErrMessageBatchInvalidRequests
+	}
+	if maxRequests > 0 && len(items) > maxRequests {
+		return nil, ErrMessageBatchTooManyRequests.WithMetadata(map[string]string{"max_requests": strconv.Itoa(maxRequests)})
+	}
+	specs := make([]messageBatchRequestSpec, 0, len(items))
+	seen := make(map[string]struct{}, len(items))
+	for i, item := range items {
+		customID := strings.TrimSpace(item.Get("custom_id").String())
+		params := item.Get("params")
+		if customID == "" || !params.IsObject() {
+			return nil, ErrMessageBatchInvalidRequests.WithMetadata(map[string]string{"index": strconv.Itoa(i)})
+		}
+		if _, dup := seen[customID]; dup {
+			return nil, ErrMessageBatchDuplicateCustomID.WithMetadata(map[string]string{"custom_id": customID})
+		}
+		seen[customID] = struct{}{}
+		model := strings.TrimSpace(params.Get("model").String())
+		maxTokens := params.Get("max_tokens").Int()
+		if model == "" || maxTokens <= 0 || params.Get("stream").Bool() {
+			return nil, ErrMessageBatchInvalidRequests.WithMetadata(map[string]string{"custom_id": customID})
+		}
+		specs = append(specs, messageBatchRequestSpec{
+			Index:       i,
+			CustomID:    customID,
+			Model:       model,
+			MaxTokens:   int(maxTokens),
+			ParamsBytes: len(params.Raw),
+		})
+	}
+	return specs, nil
+}
+
+func messageBatchDistinctModels(specs []messageBatchRequestSpec) []string {
+	seen := make(map[string]struct{})
+	models := make([]string, 0, 4)
+	for _, spec := range specs {
+		if _, ok := seen[spec.Model]; ok {
+			continue
+		}
+		seen[spec.Model] = struct{}{}
+		models = append(models, spec.Model)
+	}
+	return models
+}
+
+// applyMessageBatchModelMapping 按账号模型映射改写每条请求的 params.model。
+func applyMessageBatchModelMapping(body []byte, specs []messageBatchRequestSpec, account *Account) ([]byte, error) {
+	out := body
+	for _, spec := range specs {
+		mapped := account.GetMappedModel(spec.Model)
+		if mapped == "" || mapped == spec.Model {
+			continue
+		}
+		var err error
+		out, err = sjson.SetBytes(out, "requests."+strconv.Itoa(spec.Index)+".params.model", mapped)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func hashMessageBatchRequest(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// ---- 配置 ----
+
+func (s *MessageBatchService) enabled() bool {
+	return s != nil && s.Repo != nil && s.Config != nil && s.Config.MessageBatch.Enabled
+}
+
+func (s *MessageBatchService) nowTime() time.Time {
+	if s.now != nil {
+		return s.now()
+	}
+	return time.Now()
+}
+
+func (s *MessageBatchService) maxRequests() int {
+	if s.Config != nil && s.Config.MessageBatch.MaxRequestsPerBatch > 0 {
+		return s.Config.MessageBatch.MaxRequestsPerBatch
+	}
+	return defaultMessageBatchMaxRequests
+}
+
+func (s *MessageBatchService) discountMultiplier() float64 {
+	if s.Config != nil && s.Config.MessageBatch.DiscountMultiplier > 0 {
+		return s.Config.MessageBatch.DiscountMultiplier
+	}
+	return defaultMessageBatchDiscountMultiplier
+}
+
+func (s *MessageBatchService) holdMultiplier() float64 {
+	if s.Config != nil && s.Config.MessageBatch.HoldMultiplier > 0 {
+		return s.Config.MessageBatch.HoldMultiplier
+	}
+	return defaultMessageBatchHoldMultiplier
+}
+
+func (s *MessageBatchService) pollInterval() time.Duration {
+	if s.Config != nil && s.Config.MessageBatch.PollIntervalSeconds > 0 {
+		return time.Duration(s.Config.MessageBatch.PollIntervalSeconds) * time.Second
+	}
+	return defaultMessageBatchPollInterval
+}
+
+func (s *MessageBatchService) pollBatchSize() int {
+	if s.Config != nil && s.Config.MessageBatch.PollBatchSize > 0 {
+		return s.Config.MessageBatch.PollBatchSize
+	}
+	return defaultMessageBatchPollBatchSize
+}
+
+func (s *MessageBatchService) upstreamTimeout() time.Duration {
+	if s.Config != nil && s.Config.MessageBatch.UpstreamTimeoutSeconds > 0 {
+		return time.Duration(s.Config.MessageBatch.UpstreamTimeoutSeconds) * time.Second
+	}
+	return defaultMessageBatchUpstreamTimeout
+}
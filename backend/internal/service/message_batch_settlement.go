@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// messageBatchResultLine 是结果 JSONL 中计费所需的字段。
+type messageBatchResultLine struct {
+	CustomID string `json:"custom_id"`
+	Result   struct {
+		Type    string `json:"type"`
+		Message struct {
+			Model string `json:"model"`
+			Usage struct {
+				InputTokens              int `json:"input_tokens"`
+				OutputTokens             int `json:"output_tokens"`
+				CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+				CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+				CacheCreation            *struct {
+					Ephemeral5mInputTokens int `json:"ephemeral_5m_input_tokens"`
+					Ephemeral1hInputTokens int `json:"ephemeral_1h_input_tokens"`
+				} `json:"cache_creation"`
+			} `json:"usage"`
+		} `json:"message"`
+	} `json:"result"`
+}
+
+// Start 启动后台轮询：同步进行中批次的上游状态，并结算已结束的批次。
+func (s *MessageBatchService) Start() {
+	if !s.enabled() {
+		return
+	}
+	s.poller.start(s.pollInterval(), func(ctx context.Context) { s.RunOnce(ctx) })
+}
+
+func (s *MessageBatchService) Stop() {
+	if s == nil {
+		return
+	}
+	s.poller.stop()
+}
+
+// RunOnce 领取一轮到期批次并逐个处理，返回处理的批次数。
+func (s *MessageBatchService) RunOnce(ctx context.Context) int {
+	if !s.enabled() {
+		return 0
+	}
+	return s.settlement().runOnce(ctx)
+}
+
+// Settle 扫描上游结果中成功请求的 usage，按批量折扣价从冻结余额中结算并写入用量记录。
+func (s *MessageBatchService) Settle(ctx context.Context, job *MessageBatchJob) error {
+	return s.settlement().settle(ctx, job)
+}
+
+func (s *MessageBatchService) settlement() *batchSettlementEngine[*MessageBatchJob] {
+	return &batchSettlementEngine[*MessageBatchJob]{
+		provider: messageBatchSettlementProvider{s},
+		name:     "message_batch",
+		errs: batchSettlementErrors{
+			BillingFailed:     ErrMessageBatchBillingFailed,
+			InsufficientFunds: ErrMessageBatchInsufficientFunds,
+			PricingMissing:    ErrMessageBatchPricingMissing,
+			InvalidTransition: ErrMessageBatchInvalidTransition,
+		},
+		holdRequestID:       MessageBatchHoldRequestID,
+		captureRequestID:    MessageBatchCaptureRequestID,
+		releaseRequestID:    MessageBatchReleaseRequestID,
+		billingRepo:         s.BillingRepo,
+		usageLogRepo:        s.UsageLogRepo,
+		pricing:             s.Pricing,
+		authCache:           s.AuthCache,
+		groupRepo:           s.GroupRepo,
+		volumeTiers:         s.volumeTiers,
+		now:                 s.nowTime,
+		pollBatchSize:       s.pollBatchSize(),
+		upstreamTimeout:     s.upstreamTimeout(),
+		maxErrorMessageSize: messageBatchMaxErrorMessageSize,
+	}
+}
+
+// messageBatchSettlementProvider 把 Anthropic Message Batches 接入批次结算引擎。
+type messageBatchSettlementProvider struct {
+	*MessageBatchService
+}
+
+func (p messageBatchSettlementProvider) settlementJob(job *MessageBatchJob) batchSettlementJob {
+	phase := batchSettlementPhaseIdle
+	switch job.Status {
+	case MessageBatchStatusCreated:
+		phase = batchSettlementPhaseCreated
+	case MessageBatchStatusInProgress, MessageBatchStatusCanceling:
+		phase = batchSettlementPhasePolling
+	case MessageBatchStatusSettling:
+		phase = batchSettlementPhaseSettling
+	}
+	return batchSettlementJob{
+		BatchID:                 job.BatchID,
+		Phase:                   phase,
+		UserID:                  job.UserID,
+		APIKeyID:                job.APIKeyID,
+		AccountID:               job.AccountID,
+		GroupID:                 job.GroupID,
+		HoldAmount:              job.HoldAmount,
+		RequestHash:             job.RequestHash,
+		RetryCount:              job.RetryCount,
+		LastErrorCode:           job.LastErrorCode,
+		GroupRateMultiplier:     job.GroupRateMultiplier,
+		AccountRateMultiplier:   job.AccountRateMultiplier,
+		BatchDiscountMultiplier: job.BatchDiscountMultiplier,
+	}
+}
+
+func (p messageBatchSettlementProvider) claimDueJobs(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*MessageBatchJob, error) {
+	return p.Repo.ClaimDueMessageBatchJobs(ctx, now, lease, limit)
+}
+
+func (p messageBatchSettlementProvider) scanSettlementUsage(ctx context.Context, job *MessageBatchJob) ([]messageBatchModelUsage, string, error) {
+	if job.UpstreamBatchID == nil {
+		return nil, "SETTLEMENT_UPSTREAM_ID_MISSING", errors.New("upstream batch id is missing")
+	}
+	account, err := p.jobAccount(ctx, job)
+	if err != nil {
+		return nil, "SETTLEMENT_ACCOUNT_MISSING", err
+	}
+	results, err := p.openUpstreamResults(ctx, account, *job.UpstreamBatchID)
+	if err != nil {
+		return nil, "SETTLEMENT_RESULTS_FAILED", err
+	}
+	usages, err := scanMessageBatchResultUsage(results)
+	_ = results.Close()
+	if err != nil {
+		return nil, "SETTLEMENT_RESULTS_FAILED", err
+	}
+	return usages, "", nil
+}
+
+func (p messageBatchSettlementProvider) markSettled(ctx context.Context, job *MessageBatchJob, charges []messageBatchModelCharge, actualCost float64, settledAt time.Time) error {
+	params := MarkMessageBatchJobSettledParams{BatchID: job.BatchID, ActualCost: actualCost, SettledAt: settledAt}
+	for _, charge := range charges {
+		params.InputTokens += int64(charge.Usage.Tokens.InputTokens)
+		params.OutputTokens += int64(charge.Usage.Tokens.OutputTokens)
+		params.CacheCreationTokens += int64(charge.Usage.Tokens.CacheCreationTokens)
+		params.CacheReadTokens += int64(charge.Usage.Tokens.CacheReadTokens)
+	}
+	return p.Repo.MarkMessageBatchJobSettled(ctx, params)
+}
+
+func (p messageBatchSettlementProvider) markFailed(ctx context.Context, batchID, code, message string) error {
+	return p.Repo.MarkMessageBatchJobFailed(ctx, batchID, code, message)
+}
+
+func (p messageBatchSettlementProvider) markSettlementStuck(ctx context.Context, batchID, code, message string) error {
+	return p.Repo.MarkMessageBatchJobSettlementStuck(ctx, batchID, code, message)
+}
+
+func (p messageBatchSettlementProvider) recordError(ctx context.Context, job *MessageBatchJob, code, message string, nextPollAt time.Time) error {
+	retryCount, err := p.Repo.RecordMessageBatchJobError(ctx, job.BatchID, code, message, nextPollAt)
+	if err != nil {
+		return err
+	}
+	job.RetryCount = retryCount
+	job.LastErrorCode = &code
+	return nil
+}
+
+func (p messageBatchSettlementProvider) usageLog(job *MessageBatchJob, charge messageBatchModelCharge) *UsageLog {
+	endpoint := messageBatchInboundEndpoint
+	return &UsageLog{
+		RequestID:             messageBatchUsageRequestPrefix + job.BatchID + ":" + charge.Usage.Model,
+		InboundEndpoint:       &endpoint,
+		UpstreamEndpoint:      &endpoint,
+		InputTokens:           charge.Usage.Tokens.InputTokens,
+		OutputTokens:          charge.Usage.Tokens.OutputTokens,
+		CacheCreationTokens:   charge.Usage.Tokens.CacheCreationTokens,
+		CacheReadTokens:       charge.Usage.Tokens.CacheReadTokens,
+		CacheCreation5mTokens: charge.Usage.Tokens.CacheCreation5mTokens,
+		CacheCreation1hTokens: charge.Usage.Tokens.CacheCreation1hTokens,
+	}
+}
+
+// scanMessageBatchResultUsage 流式解析结果 JSONL，逐条返回 succeeded 请求的 usage；
+// errored / canceled / expired 请求不计费。
+func scanMessageBatchResultUsage(r io.Reader) ([]messageBatchModelUsage, error) {
+	dec := json.NewDecoder(r)
+	out := make([]messageBatchModelUsage, 0, 64)
+	for {
+		var line messageBatchResultLine
+		if err := dec.Decode(&line); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("decode results: %w", err)
+		}
+		if line.Result.Type != "succeeded" {
+			continue
+		}
+		msg := line.Result.Message
+		model := strings.TrimSpace(msg.Model)
+		if model == "" {
+			return nil, fmt.Errorf("result %q has no model", line.CustomID)
+		}
+		usage := messageBatchModelUsage{Model: model, Requests: 1}
+		usage.Tokens.InputTokens = msg.Usage.InputTokens
+		usage.Tokens.OutputTokens = msg.Usage.OutputTokens
+		usage.Tokens.CacheCreationTokens = msg.Usage.CacheCreationInputTokens
+		usage.Tokens.CacheReadTokens = msg.Usage.CacheReadInputTokens
+		if cc := msg.Usage.CacheCreation; cc != nil {
+			usage.Tokens.CacheCreation5mTokens = cc.Ephemeral5mInputTokens
+			usage.Tokens.CacheCreation1hTokens = cc.Ephemeral1hInputTokens
+		}
+		out = append(out, usage)
+	}
+	return out, nil
+}
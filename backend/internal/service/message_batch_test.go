@@ -0,0 +1,616 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tlsfingerprint"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+const messageBatchTestBody = `{"requests":[
+{"custom_id":"a","params":{"model":"claude-sonnet-4-5","max_tokens":100,"messages":[{"role":"user","content":"hi"}]}},
+{"custom_id":"b","params":{"model":"claude-sonnet-4-5","max_tokens":200,"messages":[{"role":"user","content":"hello"}]}}
+]}`
+
+func TestParseMessageBatchCreateRequest(t *testing.T) {
+	specs, err := parseMessageBatchCreateRequest([]byte(messageBatchTestBody), 10)
+	require.NoError(t, err)
+	require.Len(t, specs, 2)
+	require.Equal(t, "b", specs[1].CustomID)
+	require.Equal(t, 200, specs[1].MaxTokens)
+	require.Positive(t, specs[1].ParamsBytes)
+
+	cases := map[string]struct {
+		body string
+		max  int
+		want *infraerrors.ApplicationError
+	}{
+		"not json":       {body: `{`, want: ErrMessageBatchInvalidRequests},
+		"empty requests": {body: `{"requests":[]}`, want: ErrMessageBatchInvalidRequests},
+		"too many":       {body: messageBatchTestBody, max: 1, want: ErrMessageBatchTooManyRequests},
+		"duplicate id":   {body: `{"requests":[{"custom_id":"a","params":{"model":"m","max_tokens":1}},{"custom_id":"a","params":{"model":"m","max_tokens":1}}]}`, want: ErrMessageBatchDuplicateCustomID},
+		"no max_tokens":  {body: `{"requests":[{"custom_id":"a","params":{"model":"m"}}]}`, want: ErrMessageBatchInvalidRequests},
+		"stream":         {body: `{"requests":[{"custom_id":"a","params":{"model":"m","max_tokens":1,"stream":true}}]}`, want: ErrMessageBatchInvalidRequests},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseMessageBatchCreateRequest([]byte(tc.body), tc.max)
+			require.True(t, errors.Is(err, tc.want), "got %v", err)
+		})
+	}
+}
+
+func TestMessageBatchJobToPublic(t *testing.T) {
+	upstreamID := "msgbatch_upstream"
+	ended := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	job := &MessageBatchJob{
+		BatchID:         "msgbatch_local",
+		UpstreamBatchID: &upstreamID,
+		Status:          MessageBatchStatusSettling,
+		Counts:          MessageBatchRequestCounts{Succeeded: 2, Errored: 1},
+		EndedAt:         &ended,
+		CreatedAt:       ended.Add(-time.Hour),
+	}
+	out := MessageBatchJobToPublic(job, "https://gw.example.com")
+	require.Equal(t, "ended", out.ProcessingStatus)
+	require.Equal(t, "message_batch", out.Type)
+	require.NotNil(t, out.ResultsURL)
+	require.Equal(t, "https://gw.example.com/v1/messages/batches/msgbatch_local/results", *out.ResultsURL)
+	require.Equal(t, "2026-01-02T03:04:05Z", *out.EndedAt)
+
+	job.Status = MessageBatchStatusCreated
+	out = MessageBatchJobToPublic(job, "https://gw.example.com")
+	require.Equal(t, "in_progress", out.ProcessingStatus)
+	require.Nil(t, out.ResultsURL)
+	require.Equal(t, "canceling", PublicMessageBatchProcessingStatus(MessageBatchStatusCanceling))
+	require.Equal(t, "ended", PublicMessageBatchProcessingStatus(MessageBatchStatusFailed))
+}
+
+func TestMessageBatchService_CreateHoldsAndSubmitsMappedRequests(t *testing.T) {
+	upstream := &messageBatchUpstreamStub{handle: func(req *http.Request, body []byte) *http.Response {
+		require.Equal(t, http.MethodPost, req.Method)
+		require.Equal(t, "/v1/messages/batches", req.URL.Path)
+		require.Equal(t, "upstream-key", req.Header.Get("x-api-key"))
+		require.Equal(t, "claude-sonnet-4-5-20250929", gjson.GetBytes(body, "requests.0.params.model").String())
+		return messageBatchJSONResponse(http.StatusOK, `{"id":"msgbatch_up_1","processing_status":"in_progress","request_counts":{"processing":2}}`)
+	}}
+	account := testMessageBatchAccount()
+	account.Credentials["model_mapping"] = map[string]any{"claude-sonnet-4-5": "claude-sonnet-4-5-20250929"}
+	svc, repo, billing := newTestMessageBatchService(upstream, account)
+
+	job, err := svc.Create(context.Background(), testMessageBatchOwner(), []byte(messageBatchTestBody), "idem-1")
+	require.NoError(t, err)
+	require.Equal(t, MessageBatchStatusInProgress, job.Status)
+	require.Equal(t, "msgbatch_up_1", *job.UpstreamBatchID)
+	require.True(t, strings.HasPrefix(job.BatchID, messageBatchIDPrefix))
+	require.Equal(t, 2, job.Counts.Processing)
+	require.Equal(t, 0.5, job.BatchDiscountMultiplier)
+	require.InDelta(t, job.HoldAmount/2, job.EstimatedCost, 1e-12)
+
+	require.Len(t, billing.reserves, 1)
+	require.Equal(t, MessageBatchHoldRequestID(job.BatchID), billing.reserves[0].RequestID)
+	require.InDelta(t, job.HoldAmount, billing.reserves[0].HoldAmount, 1e-12)
+	require.Equal(t, account.ID, repo.jobs[job.BatchID].AccountID)
+
+	again, err := svc.Create(context.Background(), testMessageBatchOwner(), []byte(messageBatchTestBody), "idem-1")
+	require.NoError(t, err)
+	require.Equal(t, job.BatchID, again.BatchID)
+	require.Len(t, billing.reserves, 1)
+
+	_, err = svc.Create(context.Background(), testMessageBatchOwner(), []byte(strings.Replace(messageBatchTestBody, "hello", "bye", 1)), "idem-1")
+	require.True(t, errors.Is(err, ErrMessageBatchIdempotencyReuse))
+}
+
+func TestMessageBatchService_CreateReleasesHoldWhenUpstreamRejects(t *testing.T) {
+	upstream := &messageBatchUpstreamStub{handle: func(*http.Request, []byte) *http.Response {
+		return messageBatchJSONResponse(http.StatusBadRequest, `{"type":"error","error":{"type":"invalid_request_error","message":"requests.0.params: bad"}}`)
+	}}
+	svc, repo, billing := newTestMessageBatchService(upstream, testMessageBatchAccount())
+
+	_, err := svc.Create(context.Background(), testMessageBatchOwner(), []byte(messageBatchTestBody), "")
+	require.Error(t, err)
+	require.Equal(t, http.StatusBadRequest, infraerrors.Code(err))
+	require.Equal(t, "requests.0.params: bad", infraerrors.Message(err))
+	require.Len(t, billing.reserves, 1)
+	require.Len(t, billing.releases, 1)
+	require.Equal(t, MessageBatchHoldRequestID(billing.reserves[0].BatchID), billing.releases[0].HoldRequestID)
+	for _, job := range repo.jobs {
+		require.Equal(t, MessageBatchStatusFailed, job.Status)
+		require.Equal(t, "UPSTREAM_SUBMIT_FAILED", *job.LastErrorCode)
+	}
+}
+
+func TestMessageBatchService_CreateRejectsDisallowedModelAndForeignGroup(t *testing.T) {
+	svc, _, billing := newTestMessageBatchService(&messageBatchUpstreamStub{}, testMessageBatchAccount())
+	owner := testMessageBatchOwner()
+	owner.APIKey = &APIKey{ID: owner.APIKeyID, ModelDenylist: []string{"claude-sonnet-4-5"}}
+	_, err := svc.Create(context.Background(), owner, []byte(messageBatchTestBody), "")
+	require.Equal(t, http.StatusForbidden, infraerrors.Code(err))
+
+	groupID := int64(9)
+	svc.GroupRepo = &publicBatchImageGroupRepo{groups: map[int64]*Group{groupID: {ID: groupID, Platform: PlatformOpenAI, RateMultiplier: 1}}}
+	owner = testMessageBatchOwner()
+	owner.GroupID = &groupID
+	_, err = svc.Create(context.Background(), owner, []byte(messageBatchTestBody), "")
+	require.True(t, errors.Is(err, ErrMessageBatchGroupUnsupported))
+	require.Empty(t, billing.reserves)
+}
+
+func TestMessageBatchService_RunOnceSettlesEndedBatchAtBatchPrice(t *testing.T) {
+	results := strings.Join([]string{
+		`{"custom_id":"a","result":{"type":"succeeded","message":{"model":"claude-sonnet-4-5","usage":{"input_tokens":1000,"output_tokens":200,"cache_read_input_tokens":100}}}}`,
+		`{"custom_id":"b","result":{"type":"errored","error":{"type":"invalid_request","error":{"type":"invalid_request_error","message":"bad"}}}}`,
+		`{"custom_id":"c","result":{"type":"succeeded","message":{"model":"claude-sonnet-4-5","usage":{"input_tokens":500,"output_tokens":100,"cache_creation_input_tokens":40,"cache_creation":{"ephemeral_5m_input_tokens":40}}}}}`,
+	}, "\n") + "\n"
+	var paths []string
+	upstream := &messageBatchUpstreamStub{handle: func(req *http.Request, _ []byte) *http.Response {
+		paths = append(paths, req.URL.Path)
+		if strings.HasSuffix(req.URL.Path, "/results") {
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(results))}
+		}
+		return messageBatchJSONResponse(http.StatusOK, `{"id":"msgbatch_up_1","processing_status":"ended","request_counts":{"succeeded":2,"errored":1},"ended_at":"2026-01-02T03:04:05Z"}`)
+	}}
+	svc, repo, billing := newTestMessageBatchService(upstream, testMessageBatchAccount())
+	usageLogs := &openAIRecordUsageLogRepoStub{inserted: true}
+	svc.UsageLogRepo = usageLogs
+
+	job := testInProgressMessageBatchJob(svc.nowTime())
+	repo.jobs[job.BatchID] = job
+
+	require.Equal(t, 1, svc.RunOnce(context.Background()))
+	require.Equal(t, []string{"/v1/messages/batches/msgbatch_up_1", "/v1/messages/batches/msgbatch_up_1/results"}, paths)
+
+	settled := repo.jobs[job.BatchID]
+	require.Equal(t, MessageBatchStatusCompleted, settled.Status)
+	require.Equal(t, 2, settled.Counts.Succeeded)
+	require.Equal(t, int64(1500), settled.InputTokens)
+	require.Equal(t, int64(300), settled.OutputTokens)
+	require.Equal(t, int64(40), settled.CacheCreationTokens)
+	require.Equal(t, int64(100), settled.CacheReadTokens)
+	// 标准价 (1500*1 + 300*5 + 40*1.25 + 100*0.1)/1e6 = 0.00306，批量折扣 0.5。
+	require.InDelta(t, 0.00153, *settled.ActualCost, 1e-12)
+
+	require.Len(t, billing.captures, 1)
+	require.Equal(t, MessageBatchCaptureRequestID(job.BatchID), billing.captures[0].RequestID)
+	require.Equal(t, MessageBatchHoldRequestID(job.BatchID), billing.captures[0].HoldRequestID)
+	require.InDelta(t, 0.00153, billing.captures[0].ActualAmount, 1e-12)
+
+	require.Equal(t, 1, usageLogs.calls)
+	require.Equal(t, "message_batch_usage:"+job.BatchID+":claude-sonnet-4-5", usageLogs.lastLog.RequestID)
+	require.Equal(t, 40, usageLogs.lastLog.CacheCreation5mTokens)
+	require.InDelta(t, 0.5, usageLogs.lastLog.RateMultiplier, 1e-12)
+	require.InDelta(t, 0.00153, usageLogs.lastLog.ActualCost, 1e-12)
+
+	require.Equal(t, 0, svc.RunOnce(context.Background()))
+}
+
+func TestMessageBatchService_SettlementAppliesGroupVolumeTier(t *testing.T) {
+	results := `{"custom_id":"a","result":{"type":"succeeded","message":{"model":"claude-sonnet-4-5","usage":{"input_tokens":1000,"output_tokens":200}}}}` + "\n"
+	upstream := &messageBatchUpstreamStub{handle: func(*http.Request, []byte) *http.Response {
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(results))}
+	}}
+	svc, repo, billing := newTestMessageBatchService(upstream, testMessageBatchAccount())
+	usageLogs := &openAIRecordUsageLogRepoStub{inserted: true}
+	svc.UsageLogRepo = usageLogs
+	groupID := int64(9)
+	svc.GroupRepo = &publicBatchImageGroupRepo{groups: map[int64]*Group{groupID: {ID: groupID, RateMultiplier: 1, VolumeTiers: sampleVolumeTiers()}}}
+	reader := &monthlySpendReaderStub{spend: 150}
+	svc.volumeTiers = newVolumeTierResolver(reader)
+
+	job := testInProgressMessageBatchJob(svc.nowTime())
+	job.Status = MessageBatchStatusSettling
+	job.GroupID = &groupID
+	repo.jobs[job.BatchID] = job
+
+	require.NoError(t, svc.Settle(context.Background(), job))
+	// 标准价 (1000*1 + 200*5)/1e6 = 0.002，当月已消费 150 落在第二档 0.9，批量折扣 0.5。
+	require.InDelta(t, 0.0009, *repo.jobs[job.BatchID].ActualCost, 1e-12)
+	require.Len(t, billing.captures, 1)
+	require.InDelta(t, 0.0009, billing.captures[0].ActualAmount, 1e-12)
+	require.InDelta(t, 0.45, usageLogs.lastLog.RateMultiplier, 1e-12)
+	require.NotNil(t, usageLogs.lastLog.BillingTier)
+	require.Equal(t, "volume_t2", *usageLogs.lastLog.BillingTier)
+
+	// 批量结算的消费计入阶梯快照，后续请求无需等缓存过期即可按新累计判档。
+	spend, err := svc.volumeTiers.MonthSpend(context.Background(), job.UserID, groupID, svc.nowTime())
+	require.NoError(t, err)
+	require.InDelta(t, 150.0009, spend, 1e-9)
+	require.Equal(t, 1, reader.calls)
+}
+
+func TestMessageBatchService_SettlementCapsAtHoldAndRetriesBillingFailure(t *testing.T) {
+	results := `{"custom_id":"a","result":{"type":"succeeded","message":{"model":"claude-sonnet-4-5","usage":{"input_tokens":1000000,"output_tokens":0}}}}` + "\n"
+	upstream := &messageBatchUpstreamStub{handle: func(*http.Request, []byte) *http.Response {
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(results))}
+	}}
+	svc, repo, billing := newTestMessageBatchService(upstream, testMessageBatchAccount())
+	job := testInProgressMessageBatchJob(svc.nowTime())
+	job.Status = MessageBatchStatusSettling
+	job.HoldAmount = 0.2
+	repo.jobs[job.BatchID] = job
+
+	billing.captureErr = errors.New("db down")
+	require.Error(t, svc.Settle(context.Background(), job))
+	require.Equal(t, MessageBatchStatusSettling, repo.jobs[job.BatchID].Status)
+	require.Equal(t, 1, repo.jobs[job.BatchID].RetryCount)
+	require.Equal(t, "SETTLEMENT_BILLING_FAILED", *repo.jobs[job.BatchID].LastErrorCode)
+
+	billing.captureErr = nil
+	require.NoError(t, svc.Settle(context.Background(), repo.jobs[job.BatchID]))
+	require.Equal(t, MessageBatchStatusCompleted, repo.jobs[job.BatchID].Status)
+	require.InDelta(t, 0.2, *repo.jobs[job.BatchID].ActualCost, 1e-12)
+}
+
+func TestMessageBatchService_SettlementRetryExhaustedKeepsHoldFrozen(t *testing.T) {
+	svc, repo, billing := newTestMessageBatchService(&messageBatchUpstreamStub{}, testMessageBatchAccount())
+	job := testInProgressMessageBatchJob(svc.nowTime())
+	job.Status = MessageBatchStatusSettling
+	job.HoldAmount = 0.2
+	job.RetryCount = batchSettlementMaxRetries
+	code := "SETTLEMENT_PRICING_MISSING"
+	job.LastErrorCode = &code
+	repo.jobs[job.BatchID] = job
+
+	require.Error(t, svc.Settle(context.Background(), job))
+	require.Equal(t, MessageBatchStatusSettlementStuck, repo.jobs[job.BatchID].Status)
+	require.Equal(t, "SETTLEMENT_STUCK", *repo.jobs[job.BatchID].LastErrorCode)
+	require.Contains(t, *repo.jobs[job.BatchID].LastErrorMessage, code)
+	// 上游已完成的批次不能因结算失败而免单：冻结既不退回也不扣除，留待人工结算。
+	require.Empty(t, billing.releases)
+	require.Empty(t, billing.captures)
+	require.Nil(t, repo.jobs[job.BatchID].NextPollAt)
+	require.Equal(t, 0, svc.RunOnce(context.Background()))
+}
+
+func TestMessageBatchService_RunOnceAbandonsStaleCreatedBatch(t *testing.T) {
+	svc, repo, billing := newTestMessageBatchService(&messageBatchUpstreamStub{}, testMessageBatchAccount())
+	job := testInProgressMessageBatchJob(svc.nowTime())
+	job.Status = MessageBatchStatusCreated
+	job.UpstreamBatchID = nil
+	repo.jobs[job.BatchID] = job
+
+	require.Equal(t, 1, svc.RunOnce(context.Background()))
+	require.Equal(t, MessageBatchStatusFailed, repo.jobs[job.BatchID].Status)
+	require.Equal(t, "SUBMIT_ABANDONED", *repo.jobs[job.BatchID].LastErrorCode)
+	require.Len(t, billing.releases, 1)
+}
+
+func TestMessageBatchService_HidesOtherKeysAndUnsubmittedBatches(t *testing.T) {
+	svc, repo, _ := newTestMessageBatchService(&messageBatchUpstreamStub{}, testMessageBatchAccount())
+	job := testInProgressMessageBatchJob(svc.nowTime())
+	job.Status = MessageBatchStatusCompleted
+	repo.jobs[job.BatchID] = job
+
+	other := testMessageBatchOwner()
+	other.APIKeyID = 999
+	_, err := svc.Get(context.Background(), other, job.BatchID)
+	require.True(t, errors.Is(err, ErrMessageBatchNotFound))
+
+	got, err := svc.Get(context.Background(), testMessageBatchOwner(), job.BatchID)
+	require.NoError(t, err)
+	require.Equal(t, job.BatchID, got.BatchID)
+
+	_, err = svc.Cancel(context.Background(), testMessageBatchOwner(), job.BatchID)
+	require.True(t, errors.Is(err, ErrMessageBatchInvalidTransition))
+
+	job.UpstreamBatchID = nil
+	_, err = svc.Get(context.Background(), testMessageBatchOwner(), job.BatchID)
+	require.True(t, errors.Is(err, ErrMessageBatchNotFound))
+}
+
+func newTestMessageBatchService(upstream *messageBatchUpstreamStub, accounts ...*Account) (*MessageBatchService, *fakeMessageBatchRepo, *fakeBatchImageBillingRepo) {
+	cfg := &config.Config{}
+	cfg.MessageBatch = config.MessageBatchConfig{
+		Enabled:                true,
+		DiscountMultiplier:     0.5,
+		HoldMultiplier:         1.0,
+		MaxRequestsPerBatch:    100,
+		PollIntervalSeconds:    60,
+		PollBatchSize:          10,
+		UpstreamTimeoutSeconds: 30,
+	}
+	cfg.Security.URLAllowlist.Enabled = false
+	cfg.Security.URLAllowlist.AllowInsecureHTTP = true
+	accountRepo := &publicBatchImageAccountRepo{}
+	for _, account := range accounts {
+		accountRepo.accounts = append(accountRepo.accounts, *account)
+	}
+	repo := &fakeMessageBatchRepo{jobs: make(map[string]*MessageBatchJob)}
+	billing := &fakeBatchImageBillingRepo{}
+	now := time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)
+	svc := &MessageBatchService{
+		Repo:         repo,
+		AccountRepo:  accountRepo,
+		BillingRepo:  billing,
+		Pricing:      messageBatchTestPricing{},
+		HTTPUpstream: upstream,
+		Config:       cfg,
+		now:          func() time.Time { return now },
+	}
+	return svc, repo, billing
+}
+
+func testMessageBatchAccount() *Account {
+	return &Account{
+		ID:          11,
+		Platform:    PlatformAnthropic,
+		Type:        AccountTypeAPIKey,
+		Status:      StatusActive,
+		Schedulable: true,
+		Concurrency: 1,
+		Credentials: map[string]any{"api_key": "upstream-key", "base_url": "http://anthropic.test"},
+	}
+}
+
+func testMessageBatchOwner() MessageBatchOwner {
+	return MessageBatchOwner{UserID: 7, APIKeyID: 70}
+}
+
+func testInProgressMessageBatchJob(now time.Time) *MessageBatchJob {
+	upstreamID := "msgbatch_up_1"
+	due := now.Add(-time.Second)
+	return &MessageBatchJob{
+		ID:                      1,
+		BatchID:                 "msgbatch_test",
+		UserID:                  7,
+		APIKeyID:                70,
+		AccountID:               11,
+		UpstreamBatchID:         &upstreamID,
+		Status:                  MessageBatchStatusInProgress,
+		RequestCount:            3,
+		Counts:                  MessageBatchRequestCounts{Processing: 3},
+		EstimatedCost:           0.5,
+		HoldAmount:              1,
+		GroupRateMultiplier:     1,
+		AccountRateMultiplier:   1,
+		BatchDiscountMultiplier: 0.5,
+		NextPollAt:              &due,
+		CreatedAt:               now.Add(-time.Hour),
+	}
+}
+
+// messageBatchTestPricing 按每百万 token 输入 1、输出 5、缓存写 1.25、缓存读 0.1 计价。
+type messageBatchTestPricing struct{}
+
+func (messageBatchTestPricing) CalculateCost(_ string, tokens UsageTokens, rateMultiplier float64) (*CostBreakdown, error) {
+	bd := &CostBreakdown{
+		InputCost:         float64(tokens.InputTokens) * 1e-6,
+		OutputCost:        float64(tokens.OutputTokens) * 5e-6,
+		CacheCreationCost: float64(tokens.CacheCreationTokens) * 1.25e-6,
+		CacheReadCost:     float64(tokens.CacheReadTokens) * 0.1e-6,
+	}
+	bd.TotalCost = bd.InputCost + bd.OutputCost + bd.CacheCreationCost + bd.CacheReadCost
+	bd.ActualCost = bd.TotalCost * rateMultiplier
+	return bd, nil
+}
+
+type messageBatchUpstreamStub struct {
+	handle func(req *http.Request, body []byte) *http.Response
+}
+
+func (u *messageBatchUpstreamStub) Do(req *http.Request, _ string, _ int64, _ int) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		body, _ = io.ReadAll(req.Body)
+	}
+	if u.handle == nil {
+		return nil, errors.New("unexpected upstream call")
+	}
+	return u.handle(req, body), nil
+}
+
+func (u *messageBatchUpstreamStub) DoWithTLS(req *http.Request, proxyURL string, accountID int64, accountConcurrency int, _ *tlsfingerprint.Profile) (*http.Response, error) {
+	return u.Do(req, proxyURL, accountID, accountConcurrency)
+}
+
+func messageBatchJSONResponse(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+type fakeMessageBatchRepo struct {
+	jobs   map[string]*MessageBatchJob
+	nextID int64
+}
+
+func (r *fakeMessageBatchRepo) CreateMessageBatchJob(_ context.Context, job *MessageBatchJob) error {
+	for _, existing := range r.jobs {
+		if job.IdempotencyKey != nil && existing.APIKeyID == job.APIKeyID && existing.IdempotencyKey != nil && *existing.IdempotencyKey == *job.IdempotencyKey {
+			return ErrMessageBatchExists
+		}
+	}
+	r.nextID++
+	job.ID = r.nextID
+	clone := *job
+	r.jobs[job.BatchID] = &clone
+	return nil
+}
+
+func (r *fakeMessageBatchRepo) get(batchID string) (*MessageBatchJob, error) {
+	job, ok := r.jobs[batchID]
+	if !ok {
+		return nil, ErrMessageBatchNotFound
+	}
+	return job, nil
+}
+
+func (r *fakeMessageBatchRepo) GetMessageBatchJob(_ context.Context, batchID string) (*MessageBatchJob, error) {
+	job, err := r.get(batchID)
+	if err != nil {
+		return nil, err
+	}
+	clone := *job
+	return &clone, nil
+}
+
+func (r *fakeMessageBatchRepo) GetMessageBatchJobForOwner(ctx context.Context, apiKeyID int64, batchID string) (*MessageBatchJob, error) {
+	job, err := r.GetMessageBatchJob(ctx, batchID)
+	if err != nil || job.APIKeyID != apiKeyID {
+		return nil, ErrMessageBatchNotFound
+	}
+	return job, nil
+}
+
+func (r *fakeMessageBatchRepo) GetMessageBatchJobByIdempotencyKey(_ context.Context, apiKeyID int64, key string) (*MessageBatchJob, error) {
+	for _, job := range r.jobs {
+		if job.APIKeyID == apiKeyID && job.IdempotencyKey != nil && *job.IdempotencyKey == key {
+			clone := *job
+			return &clone, nil
+		}
+	}
+	return nil, ErrMessageBatchNotFound
+}
+
+func (r *fakeMessageBatchRepo) ListMessageBatchJobsForOwner(_ context.Context, apiKeyID int64, query MessageBatchListQuery) ([]*MessageBatchJob, error) {
+	var out []*MessageBatchJob
+	for _, job := range r.jobs {
+		if job.APIKeyID == apiKeyID && job.UpstreamBatchID != nil {
+			clone := *job
+			out = append(out, &clone)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID > out[j].ID })
+	if query.Limit > 0 && len(out) > query.Limit {
+		out = out[:query.Limit]
+	}
+	return out, nil
+}
+
+func (r *fakeMessageBatchRepo) applyProgress(job *MessageBatchJob, progress MessageBatchProgress) {
+	job.Status = progress.Status
+	job.Counts = progress.Counts
+	if progress.ExpiresAt != nil {
+		job.ExpiresAt = progress.ExpiresAt
+	}
+	if progress.EndedAt != nil {
+		job.EndedAt = progress.EndedAt
+	}
+	if progress.CancelInitiatedAt != nil {
+		job.CancelInitiatedAt = progress.CancelInitiatedAt
+	}
+	job.NextPollAt = progress.NextPollAt
+}
+
+func (r *fakeMessageBatchRepo) MarkMessageBatchJobSubmitted(_ context.Context, batchID, upstreamBatchID string, progress MessageBatchProgress) error {
+	job, err := r.get(batchID)
+	if err != nil {
+		return err
+	}
+	if job.Status != MessageBatchStatusCreated {
+		return ErrMessageBatchInvalidTransition
+	}
+	job.UpstreamBatchID = &upstreamBatchID
+	r.applyProgress(job, progress)
+	return nil
+}
+
+func (r *fakeMessageBatchRepo) UpdateMessageBatchJobProgress(_ context.Context, batchID string, progress MessageBatchProgress) error {
+	job, err := r.get(batchID)
+	if err != nil {
+		return err
+	}
+	if job.Status != MessageBatchStatusInProgress && job.Status != MessageBatchStatusCanceling {
+		return ErrMessageBatchInvalidTransition
+	}
+	r.applyProgress(job, progress)
+	return nil
+}
+
+func (r *fakeMessageBatchRepo) MarkMessageBatchJobSettled(_ context.Context, params MarkMessageBatchJobSettledParams) error {
+	job, err := r.get(params.BatchID)
+	if err != nil {
+		return err
+	}
+	if job.Status != MessageBatchStatusSettling {
+		return ErrMessageBatchInvalidTransition
+	}
+	job.Status = MessageBatchStatusCompleted
+	actual := params.ActualCost
+	job.ActualCost = &actual
+	job.InputTokens = params.InputTokens
+	job.OutputTokens = params.OutputTokens
+	job.CacheCreationTokens = params.CacheCreationTokens
+	job.CacheReadTokens = params.CacheReadTokens
+	settledAt := params.SettledAt
+	job.SettledAt = &settledAt
+	job.NextPollAt = nil
+	return nil
+}
+
+func (r *fakeMessageBatchRepo) MarkMessageBatchJobFailed(_ context.Context, batchID, code, message string) error {
+	job, err := r.get(batchID)
+	if err != nil {
+		return err
+	}
+	if job.Status != MessageBatchStatusCreated && job.Status != MessageBatchStatusSettling {
+		return ErrMessageBatchInvalidTransition
+	}
+	job.Status = MessageBatchStatusFailed
+	job.LastErrorCode = &code
+	job.LastErrorMessage = &message
+	job.NextPollAt = nil
+	return nil
+}
+
+func (r *fakeMessageBatchRepo) MarkMessageBatchJobSettlementStuck(_ context.Context, batchID, code, message string) error {
+	job, err := r.get(batchID)
+	if err != nil {
+		return err
+	}
+	if job.Status != MessageBatchStatusSettling {
+		return ErrMessageBatchInvalidTransition
+	}
+	job.Status = MessageBatchStatusSettlementStuck
+	job.LastErrorCode = &code
+	job.LastErrorMessage = &message
+	job.NextPollAt = nil
+	return nil
+}
+
+func (r *fakeMessageBatchRepo) RecordMessageBatchJobError(_ context.Context, batchID, code, message string, nextPollAt time.Time) (int, error) {
+	job, err := r.get(batchID)
+	if err != nil {
+		return 0, err
+	}
+	job.RetryCount++
+	job.LastErrorCode = &code
+	job.LastErrorMessage = &message
+	job.NextPollAt = &nextPollAt
+	return job.RetryCount, nil
+}
+
+func (r *fakeMessageBatchRepo) ClaimDueMessageBatchJobs(_ context.Context, now time.Time, lease time.Duration, limit int) ([]*MessageBatchJob, error) {
+	var out []*MessageBatchJob
+	for _, job := range r.jobs {
+		if len(out) >= limit {
+			break
+		}
+		if !IsPendingMessageBatchStatus(job.Status) || job.NextPollAt == nil || job.NextPollAt.After(now) {
+			continue
+		}
+		leased := now.Add(lease)
+		job.NextPollAt = &leased
+		clone := *job
+		out = append(out, &clone)
+	}
+	return out, nil
+}
+
+var _ MessageBatchRepository = (*fakeMessageBatchRepo)(nil)
+var _ HTTPUpstream = (*messageBatchUpstreamStub)(nil)
+var _ MessageBatchCostCalculator = messageBatchTestPricing{}
@@ -25,8 +25,12 @@ const (
 	NotificationEmailEventNotificationEmailVerifyCode = "notification_email.verify_code"
 	NotificationEmailEventSubscriptionPurchaseSuccess = "subscription.purchase_success"
 	NotificationEmailEventSubscriptionExpiryReminder  = "subscription.expiry_reminder"
+	NotificationEmailEventSubscriptionRenewalFailed   = "subscription.renewal_failed"
 	NotificationEmailEventBalanceLow                  = "balance.low"
 	NotificationEmailEventBalanceRechargeSuccess      = "balance.recharge_success"
+	NotificationEmailEventSpendAnomaly                = "balance.spend_anomaly"
+	NotificationEmailEventCreditStatementIssued       = "credit.statement_issued"
+	NotificationEmailEventCreditStatementOverdue      = "credit.statement_overdue"
 	NotificationEmailEventAccountQuotaAlert           = "account.quota_alert"
 	NotificationEmailEventContentModerationViolation  = "content_moderation.violation_notice"
 	NotificationEmailEventContentModerationDisabled   = "content_moderation.account_disabled"
@@ -913,6 +917,21 @@ func notificationEmailSampleVariables(locale string) map[string]string {
 			"recharge_url":        "https://example.com/recharge",
 			"recharge_amount":     "50.00",
 			"order_id":            "1024",
+			"failure_reason":      "insufficient_funds",
+			"attempts_remaining":  "2",
+			"anomaly_scope":       "API Key「prod-backend」",
+			"api_key_name":        "prod-backend",
+			"window_minutes":      "60",
+			"window_spend":        "42.80",
+			"baseline_spend":      "1.35",
+			"spend_ratio":         "31.7",
+			"key_action":          "该 API Key 已被自动禁用，确认用量正常后可在 API Key 页面重新启用。",
+			"statement_period":    "2026-05",
+			"total_cost":          "86.40",
+			"closing_balance":     "-36.40",
+			"amount_due":          "36.40",
+			"credit_limit":        "100.00",
+			"due_date":            "2026-06-15",
 			"unsubscribe_url":     "https://example.com/unsubscribe",
 			"account_id":          "1001",
 			"account_name":        "openai-main",
@@ -961,6 +980,21 @@ func notificationEmailSampleVariables(locale string) map[string]string {
 		"recharge_url":        "https://example.com/recharge",
 		"recharge_amount":     "50.00",
 		"order_id":            "1024",
+		"failure_reason":      "insufficient_funds",
+		"attempts_remaining":  "2",
+		"anomaly_scope":       "API key \"prod-backend\"",
+		"api_key_name":        "prod-backend",
+		"window_minutes":      "60",
+		"window_spend":        "42.80",
+		"baseline_spend":      "1.35",
+		"spend_ratio":         "31.7",
+		"key_action":          "The API key has been disabled automatically. Re-enable it from the API Keys page once you have confirmed the usage.",
+		"statement_period":    "2026-05",
+		"total_cost":          "86.40",
+		"closing_balance":     "-36.40",
+		"amount_due":          "36.40",
+		"credit_limit":        "100.00",
+		"due_date":            "2026-06-15",
 		"unsubscribe_url":     "https://example.com/unsubscribe",
 		"account_id":          "1001",
 		"account_name":        "openai-main",
@@ -1026,8 +1060,12 @@ var notificationEmailEventOrder = []string{
 	NotificationEmailEventNotificationEmailVerifyCode,
 	NotificationEmailEventSubscriptionPurchaseSuccess,
 	NotificationEmailEventSubscriptionExpiryReminder,
+	NotificationEmailEventSubscriptionRenewalFailed,
 	NotificationEmailEventBalanceLow,
 	NotificationEmailEventBalanceRechargeSuccess,
+	NotificationEmailEventSpendAnomaly,
+	NotificationEmailEventCreditStatementIssued,
+	NotificationEmailEventCreditStatementOverdue,
 	NotificationEmailEventAccountQuotaAlert,
 	NotificationEmailEventContentModerationViolation,
 	NotificationEmailEventContentModerationDisabled,
@@ -1077,6 +1115,14 @@ var notificationEmailEventDefinitions = map[string]NotificationEmailEventInfo{
 		Optional:     true,
 		Placeholders: append(append([]string{}, notificationEmailCommonPlaceholders...), "subscription_group", "expiry_time", "days_remaining", "unsubscribe_url"),
 	},
+	NotificationEmailEventSubscriptionRenewalFailed: {
+		Event:        NotificationEmailEventSubscriptionRenewalFailed,
+		Label:        "Subscription auto-renewal failed",
+		Description:  "Sent when charging the saved payment method for an auto-renewing subscription fails.",
+		Category:     "subscription",
+		Optional:     false,
+		Placeholders: append(append([]string{}, notificationEmailCommonPlaceholders...), "subscription_group", "expiry_time", "failure_reason", "attempts_remaining", "order_id"),
+	},
 	NotificationEmailEventBalanceLow: {
 		Event:        NotificationEmailEventBalanceLow,
 		Label:        "Low balance alert",
@@ -1093,6 +1139,32 @@ var notificationEmailEventDefinitions = map[string]NotificationEmailEventInfo{
 		Optional:     false,
 		Placeholders: append(append([]string{}, notificationEmailCommonPlaceholders...), "recharge_amount", "current_balance", "order_id"),
 	},
+	NotificationEmailEventSpendAnomaly: {
+		Event:       NotificationEmailEventSpendAnomaly,
+		Label:       "Spend anomaly alert",
+		Description: "Sent when spend on an account or API key in the recent window far exceeds its rolling baseline.",
+		Category:    "billing",
+		Optional:    false,
+		Placeholders: append(append([]string{}, notificationEmailCommonPlaceholders...),
+			"anomaly_scope", "api_key_name", "window_minutes", "window_spend", "baseline_spend", "spend_ratio", "current_balance", "key_action"),
+	},
+	NotificationEmailEventCreditStatementIssued: {
+		Event:       NotificationEmailEventCreditStatementIssued,
+		Label:       "Credit statement issued",
+		Description: "Sent to users with a postpaid credit line when their monthly statement is generated.",
+		Category:    "billing",
+		Optional:    false,
+		Placeholders: append(append([]string{}, notificationEmailCommonPlaceholders...),
+			"statement_period", "total_cost", "amount_due", "closing_balance", "credit_limit", "due_date"),
+	},
+	NotificationEmailEventCreditStatementOverdue: {
+		Event:        NotificationEmailEventCreditStatementOverdue,
+		Label:        "Credit statement overdue",
+		Description:  "Sent when a credit statement passes its due date unpaid and the user's API keys are suspended.",
+		Category:     "billing",
+		Optional:     false,
+		Placeholders: append(append([]string{}, notificationEmailCommonPlaceholders...), "statement_period", "amount_due", "due_date"),
+	},
 	NotificationEmailEventAccountQuotaAlert: {
 		Event:       NotificationEmailEventAccountQuotaAlert,
 		Label:       "Account quota alert",
@@ -1254,6 +1326,28 @@ var notificationEmailOfficialTemplates = map[string]map[string]notificationEmail
 <p class="muted"><a href="{{unsubscribe_url}}">退订此类订阅提醒</a></p>`),
 		},
 	},
+	NotificationEmailEventSubscriptionRenewalFailed: {
+		notificationEmailDefaultLocale: {
+			Subject: "[{{site_name}}] Subscription auto-renewal failed",
+			HTML: notificationEmailCard("#dc2626", "Auto-renewal payment failed", `
+<p>Hello {{recipient_name}},</p>
+<p>We could not charge your saved payment method to renew your <strong>{{subscription_group}}</strong> subscription.</p>
+<p>Reason: {{failure_reason}}</p>
+<p>We will retry automatically <strong>{{attempts_remaining}}</strong> more time(s). When no retries remain, auto-renewal is turned off.</p>
+<p>To avoid interruption, please update your payment method or renew manually before <strong>{{expiry_time}}</strong>.</p>
+<p>Order ID: {{order_id}}</p>`),
+		},
+		notificationEmailLocaleChinese: {
+			Subject: "[{{site_name}}] 订阅自动续费失败",
+			HTML: notificationEmailCard("#dc2626", "自动续费扣款失败", `
+<p>{{recipient_name}}，您好：</p>
+<p>我们未能通过您保存的支付方式为 <strong>{{subscription_group}}</strong> 订阅自动续费。</p>
+<p>失败原因：{{failure_reason}}</p>
+<p>系统还会自动重试 <strong>{{attempts_remaining}}</strong> 次，重试用尽后将关闭自动续费。</p>
+<p>为避免服务中断，请在 <strong>{{expiry_time}}</strong> 之前更新支付方式或手动续费。</p>
+<p>订单号：{{order_id}}</p>`),
+		},
+	},
 	NotificationEmailEventBalanceLow: {
 		notificationEmailDefaultLocale: {
 			Subject: "[{{site_name}}] Low balance alert",
@@ -1274,6 +1368,66 @@ var notificationEmailOfficialTemplates = map[string]map[string]notificationEmail
 <p class="muted"><a href="{{unsubscribe_url}}">退订此类余额提醒</a></p>`),
 		},
 	},
+	NotificationEmailEventSpendAnomaly: {
+		notificationEmailDefaultLocale: {
+			Subject: "[{{site_name}}] Unusual spend detected",
+			HTML: notificationEmailCard("#dc2626", "Unusual spend detected", `
+<p>Hello {{recipient_name}},</p>
+<p>Spend on {{anomaly_scope}} in the last {{window_minutes}} minutes was <strong>${{window_spend}}</strong>, about <strong>{{spend_ratio}}x</strong> the usual <strong>${{baseline_spend}}</strong> for the same length of time.</p>
+<p>Current balance: <strong>${{current_balance}}</strong></p>
+<p><strong>{{key_action}}</strong></p>
+<p class="muted">If you do not recognize this usage, your API key may have leaked. Disable or rotate it immediately.</p>`),
+		},
+		notificationEmailLocaleChinese: {
+			Subject: "[{{site_name}}] 检测到异常消费",
+			HTML: notificationEmailCard("#dc2626", "检测到异常消费", `
+<p>{{recipient_name}}，您好：</p>
+<p>{{anomaly_scope}}最近 {{window_minutes}} 分钟消费 <strong>${{window_spend}}</strong>，约为同等时长平时消费 <strong>${{baseline_spend}}</strong> 的 <strong>{{spend_ratio}} 倍</strong>。</p>
+<p>当前余额：<strong>${{current_balance}}</strong></p>
+<p><strong>{{key_action}}</strong></p>
+<p class="muted">如果这不是您本人的用量，API Key 可能已经泄露，请立即禁用或更换。</p>`),
+		},
+	},
+	NotificationEmailEventCreditStatementIssued: {
+		notificationEmailDefaultLocale: {
+			Subject: "[{{site_name}}] Your statement for {{statement_period}}",
+			HTML: notificationEmailCard("#2563eb", "Monthly statement", `
+<p>Hello {{recipient_name}},</p>
+<p>Your statement for <strong>{{statement_period}}</strong> is ready.</p>
+<p>Usage this period: <strong>${{total_cost}}</strong></p>
+<p>Closing balance: <strong>${{closing_balance}}</strong> (credit limit ${{credit_limit}})</p>
+<p>Amount due: <strong>${{amount_due}}</strong>, payable by <strong>{{due_date}}</strong>.</p>
+<p class="muted">Recharge your balance to pay. API keys are suspended if the statement is still unpaid after the due date.</p>`),
+		},
+		notificationEmailLocaleChinese: {
+			Subject: "[{{site_name}}] {{statement_period}} 月度账单",
+			HTML: notificationEmailCard("#2563eb", "月度账单", `
+<p>{{recipient_name}}，您好：</p>
+<p>您 <strong>{{statement_period}}</strong> 的账单已生成。</p>
+<p>本期用量：<strong>${{total_cost}}</strong></p>
+<p>期末余额：<strong>${{closing_balance}}</strong>（授信额度 ${{credit_limit}}）</p>
+<p>应付金额：<strong>${{amount_due}}</strong>，请于 <strong>{{due_date}}</strong> 前付清。</p>
+<p class="muted">充值余额即可还款。逾期未付的账单会导致 API Key 被暂停。</p>`),
+		},
+	},
+	NotificationEmailEventCreditStatementOverdue: {
+		notificationEmailDefaultLocale: {
+			Subject: "[{{site_name}}] Statement {{statement_period}} is overdue",
+			HTML: notificationEmailCard("#dc2626", "Statement overdue", `
+<p>Hello {{recipient_name}},</p>
+<p>Your statement for <strong>{{statement_period}}</strong> was due on <strong>{{due_date}}</strong> and is still unpaid.</p>
+<p>Amount due: <strong>${{amount_due}}</strong></p>
+<p>Your API keys have been suspended. They are resumed automatically once the amount due is recharged.</p>`),
+		},
+		notificationEmailLocaleChinese: {
+			Subject: "[{{site_name}}] {{statement_period}} 账单已逾期",
+			HTML: notificationEmailCard("#dc2626", "账单已逾期", `
+<p>{{recipient_name}}，您好：</p>
+<p>您 <strong>{{statement_period}}</strong> 的账单已于 <strong>{{due_date}}</strong> 到期，目前仍未结清。</p>
+<p>应付金额：<strong>${{amount_due}}</strong></p>
+<p>您的 API Key 已被暂停，充值结清应付金额后将自动恢复。</p>`),
+		},
+	},
 	NotificationEmailEventBalanceRechargeSuccess: {
 		notificationEmailDefaultLocale: {
 			Subject: "[{{site_name}}] Balance recharge successful",
@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/Wei-Shaw/sub2api/internal/config"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/sync/singleflight"
 )
 
@@ -370,6 +371,21 @@ func newDefaultOpenAIAccountScheduler(service *OpenAIGatewayService, stats *open
 func (s *defaultOpenAIAccountScheduler) Select(
 	ctx context.Context,
 	req OpenAIAccountScheduleRequest,
+) (*AccountSelectionResult, OpenAIAccountScheduleDecision, error) {
+	ctx, span := startAccountSelectionSpan(ctx, "openai.scheduler.select", req.GroupID, req.Platform, req.RequestedModel)
+	selection, decision, err := s.selectAccount(ctx, req)
+	span.SetAttributes(
+		attribute.String("sub2api.scheduler.layer", decision.Layer),
+		attribute.Int("sub2api.scheduler.candidates", decision.CandidateCount),
+		attribute.Int("sub2api.scheduler.top_k", decision.TopK),
+	)
+	endAccountSelectionSpan(span, selection, err)
+	return selection, decision, err
+}
+
+func (s *defaultOpenAIAccountScheduler) selectAccount(
+	ctx context.Context,
+	req OpenAIAccountScheduleRequest,
 ) (*AccountSelectionResult, OpenAIAccountScheduleDecision, error) {
 	decision := OpenAIAccountScheduleDecision{}
 	start := time.Now()
@@ -117,6 +117,10 @@ func (s *AccountTestService) ProbeOpenAIAPIKeyResponsesSupport(ctx context.Conte
 		// 仅 OpenAI APIKey 账号需要探测；其他账号类型无能力差异。
 		return
 	}
+	if account.IsAzureOpenAI() {
+		// Azure 的 Responses 可用性由 api-version 决定，且路径/鉴权与 /v1 不同；保持 unknown 交由网关处理。
+		return
+	}
 
 	apiKey := account.GetOpenAIApiKey()
 	if apiKey == "" {
@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/tidwall/sjson"
+)
+
+const (
+	OpenAIBatchStatusCreated    = "created"
+	OpenAIBatchStatusInProgress = "in_progress"
+	OpenAIBatchStatusSettling   = "settling"
+	OpenAIBatchStatusCompleted  = "completed"
+	OpenAIBatchStatusFailed     = "failed"
+	// OpenAIBatchStatusSettlementStuck 结算重试耗尽：冻结保持不动，等待人工处理。
+	OpenAIBatchStatusSettlementStuck = "settlement_stuck"
+)
+
+// OpenAI batch status 中的终态；终态批次的输出文件可能只包含部分结果（expired / cancelled）。
+const (
+	OpenAIBatchUpstreamCompleted = "completed"
+	OpenAIBatchUpstreamFailed    = "failed"
+	OpenAIBatchUpstreamExpired   = "expired"
+	OpenAIBatchUpstreamCancelled = "cancelled"
+)
+
+const (
+	OpenAIFilePurposeBatch       = "batch"
+	OpenAIFilePurposeBatchOutput = "batch_output"
+)
+
+const (
+	openAIBatchIDPrefix            = "batch_"
+	openAIBatchHoldRequestPrefix   = "openai_batch_hold:"
+	openAIBatchCapturePrefix       = "openai_batch_capture:"
+	openAIBatchReleasePrefix       = "openai_batch_release:"
+	openAIBatchUsageRequestPrefix  = "openai_batch_usage:"
+	openAIBatchInboundEndpoint     = "/v1/batches"
+	openAIBatchMaxErrorMessageSize = 1000
+)
+
+// openAIBatchSupportedEndpoints 是批处理输入文件中允许的 url。
+var openAIBatchSupportedEndpoints = map[string]bool{
+	"/v1/chat/completions": true,
+	"/v1/responses":        true,
+	"/v1/embeddings":       true,
+	"/v1/completions":      true,
+}
+
+var (
+	ErrOpenAIBatchNotFound           = infraerrors.New(http.StatusNotFound, "OPENAI_BATCH_NOT_FOUND", "batch not found")
+	ErrOpenAIBatchExists             = infraerrors.New(http.StatusConflict, "OPENAI_BATCH_EXISTS", "batch already exists")
+	ErrOpenAIBatchFileNotFound       = infraerrors.New(http.StatusNotFound, "OPENAI_FILE_NOT_FOUND", "file not found")
+	ErrOpenAIBatchFileExists         = infraerrors.New(http.StatusConflict, "OPENAI_FILE_EXISTS", "file already exists")
+	ErrOpenAIBatchDisabled           = infraerrors.New(http.StatusNotFound, "OPENAI_BATCH_DISABLED", "Files and Batch API is not enabled")
+	ErrOpenAIBatchGroupUnsupported   = infraerrors.New(http.StatusNotFound, "OPENAI_BATCH_GROUP_UNSUPPORTED", "Files and Batch API is not supported for this platform")
+	ErrOpenAIBatchInvalidUpload      = infraerrors.New(http.StatusBadRequest, "OPENAI_FILE_INVALID_UPLOAD", "multipart upload must contain purpose=batch and a file")
+	ErrOpenAIBatchInvalidFile        = infraerrors.New(http.StatusBadRequest, "OPENAI_FILE_INVALID_BATCH_INPUT", "invalid batch input file")
+	ErrOpenAIBatchFileTooLarge       = infraerrors.New(http.StatusRequestEntityTooLarge, "OPENAI_FILE_TOO_LARGE", "file exceeds the maximum allowed size")
+	ErrOpenAIBatchInvalidRequest     = infraerrors.New(http.StatusBadRequest, "OPENAI_BATCH_INVALID_REQUEST", "input_file_id and endpoint are required")
+	ErrOpenAIBatchEndpointMismatch   = infraerrors.New(http.StatusBadRequest, "OPENAI_BATCH_ENDPOINT_MISMATCH", "endpoint does not match the requests in the input file")
+	ErrOpenAIBatchModelNotAllowed    = infraerrors.New(http.StatusForbidden, "OPENAI_BATCH_MODEL_NOT_ALLOWED", "model is not allowed for this API key")
+	ErrOpenAIBatchNoAccountAvailable = infraerrors.New(http.StatusServiceUnavailable, "OPENAI_BATCH_NO_ACCOUNT_AVAILABLE", "no OpenAI API key account is available for batches")
+	ErrOpenAIBatchPricingMissing     = infraerrors.New(http.StatusBadRequest, "OPENAI_BATCH_PRICING_MISSING", "pricing is not available for the requested model")
+	ErrOpenAIBatchInsufficientFunds  = infraerrors.New(http.StatusPaymentRequired, "OPENAI_BATCH_INSUFFICIENT_BALANCE", "insufficient balance for batch hold")
+	ErrOpenAIBatchBillingFailed      = infraerrors.New(http.StatusBadGateway, "OPENAI_BATCH_BILLING_FAILED", "batch billing failed")
+	ErrOpenAIBatchUpstreamFailed     = infraerrors.New(http.StatusBadGateway, "OPENAI_BATCH_UPSTREAM_FAILED", "upstream batch request failed")
+	ErrOpenAIBatchInvalidTransition  = infraerrors.New(http.StatusConflict, "OPENAI_BATCH_INVALID_TRANSITION", "batch is not in a state that allows this operation")
+	ErrOpenAIBatchIdempotencyReuse   = infraerrors.New(http.StatusConflict, "OPENAI_BATCH_IDEMPOTENCY_CONFLICT", "idempotency key reused with a different batch request")
+)
+
+// OpenAIBatchModelEstimate 是输入文件中单个模型的冻结估算：输入以请求体字节数为上限，
+// 输出取请求声明的最大输出 token（未声明时用配置默认值）。StandardCost 为逐条计价后
+// 按倍率 1.0 汇总的标准价，创建批次时再乘以分组/账号倍率。
+type OpenAIBatchModelEstimate struct {
+	Model        string  `json:"model"`
+	Requests     int     `json:"requests"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	StandardCost float64 `json:"standard_cost"`
+}
+
+// OpenAIBatchFile 记录上游文件的归属 API Key 与所在账号。
+type OpenAIBatchFile struct {
+	ID             int64
+	FileID         string
+	UserID         int64
+	APIKeyID       int64
+	AccountID      int64
+	Purpose        string
+	Filename       string
+	Bytes          int64
+	Endpoint       string
+	RequestCount   int
+	Estimate       []OpenAIBatchModelEstimate
+	UpstreamObject json.RawMessage
+	DeletedAt      *time.Time
+	CreatedAt      time.Time
+}
+
+// OpenAIBatchJob 是网关侧的批次记录；对外 ID 为 BatchID，上游批次 ID 不对外暴露。
+type OpenAIBatchJob struct {
+	ID                      int64
+	BatchID                 string
+	UserID                  int64
+	APIKeyID                int64
+	AccountID               int64
+	GroupID                 *int64
+	InputFileID             string
+	Endpoint                string
+	UpstreamBatchID         *string
+	Status                  string
+	UpstreamStatus          string
+	UpstreamObject          json.RawMessage
+	OutputFileID            *string
+	ErrorFileID             *string
+	RequestCount            int
+	EstimatedCost           float64
+	HoldAmount              float64
+	ActualCost              *float64
+	GroupRateMultiplier     float64
+	AccountRateMultiplier   float64
+	BatchDiscountMultiplier float64
+	InputTokens             int64
+	OutputTokens            int64
+	CacheReadTokens         int64
+	IdempotencyKey          *string
+	RequestHash             string
+	RetryCount              int
+	LastErrorCode           *string
+	LastErrorMessage        *string
+	NextPollAt              *time.Time
+	SettledAt               *time.Time
+	CreatedAt               time.Time
+	UpdatedAt               time.Time
+}
+
+// OpenAIBatchProgress 是一次上游状态同步要写回的字段；文件 ID 为 nil 时保留原值。
+type OpenAIBatchProgress struct {
+	Status         string
+	UpstreamStatus string
+	UpstreamObject json.RawMessage
+	OutputFileID   *string
+	ErrorFileID    *string
+	RequestCount   int
+	NextPollAt     *time.Time
+}
+
+type MarkOpenAIBatchJobSettledParams struct {
+	BatchID         string
+	ActualCost      float64
+	InputTokens     int64
+	OutputTokens    int64
+	CacheReadTokens int64
+	SettledAt       time.Time
+}
+
+// OpenAIBatchListQuery 对应 OpenAI 列表分页参数（after / limit）。
+type OpenAIBatchListQuery struct {
+	After string
+	Limit int
+}
+
+type OpenAIBatchRepository interface {
+	// CreateOpenAIBatchFile 写入文件归属；file_id 已存在时返回 ErrOpenAIBatchFileExists。
+	CreateOpenAIBatchFile(ctx context.Context, file *OpenAIBatchFile) error
+	GetOpenAIBatchFileForOwner(ctx context.Context, apiKeyID int64, fileID string) (*OpenAIBatchFile, error)
+	ListOpenAIBatchFilesForOwner(ctx context.Context, apiKeyID int64, purpose string, query OpenAIBatchListQuery) ([]*OpenAIBatchFile, error)
+	MarkOpenAIBatchFileDeleted(ctx context.Context, fileID string, deletedAt time.Time) error
+
+	CreateOpenAIBatchJob(ctx context.Context, job *OpenAIBatchJob) error
+	GetOpenAIBatchJob(ctx context.Context, batchID string) (*OpenAIBatchJob, error)
+	GetOpenAIBatchJobForOwner(ctx context.Context, apiKeyID int64, batchID string) (*OpenAIBatchJob, error)
+	GetOpenAIBatchJobByIdempotencyKey(ctx context.Context, apiKeyID int64, key string) (*OpenAIBatchJob, error)
+	// ListOpenAIBatchJobsForOwner 只返回已提交到上游的批次，按 id 降序。
+	ListOpenAIBatchJobsForOwner(ctx context.Context, apiKeyID int64, query OpenAIBatchListQuery) ([]*OpenAIBatchJob, error)
+	// MarkOpenAIBatchJobSubmitted 把 created 批次绑定到上游批次并写入首次同步的状态。
+	MarkOpenAIBatchJobSubmitted(ctx context.Context, batchID, upstreamBatchID string, progress OpenAIBatchProgress) error
+	// UpdateOpenAIBatchJobProgress 只更新 in_progress 批次，否则返回 ErrOpenAIBatchInvalidTransition。
+	UpdateOpenAIBatchJobProgress(ctx context.Context, batchID string, progress OpenAIBatchProgress) error
+	MarkOpenAIBatchJobSettled(ctx context.Context, params MarkOpenAIBatchJobSettledParams) error
+	MarkOpenAIBatchJobFailed(ctx context.Context, batchID, code, message string) error
+	// MarkOpenAIBatchJobSettlementStuck 把 settling 批次转入 settlement_stuck 终态，不再轮询。
+	MarkOpenAIBatchJobSettlementStuck(ctx context.Context, batchID, code, message string) error
+	// RecordOpenAIBatchJobError 记录一次可重试错误并推迟下次轮询，返回递增后的 retry_count。
+	RecordOpenAIBatchJobError(ctx context.Context, batchID, code, message string, nextPollAt time.Time) (int, error)
+	// ClaimDueOpenAIBatchJobs 领取到期待轮询的批次，并把 next_poll_at 推迟 lease。
+	ClaimDueOpenAIBatchJobs(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*OpenAIBatchJob, error)
+}
+
+// OpenAIBatchOwner 标识发起请求的 API Key。
+type OpenAIBatchOwner struct {
+	UserID   int64
+	APIKeyID int64
+	GroupID  *int64
+	APIKey   *APIKey
+	// AuditLine 在上传输入文件时逐行审核请求体；返回错误即中止整个上传。
+	AuditLine func(endpoint, model string, body []byte) error
+}
+
+// OpenAIListResponse 是 OpenAI 列表接口的通用外层结构。
+type OpenAIListResponse struct {
+	Object  string            `json:"object"`
+	Data    []json.RawMessage `json:"data"`
+	FirstID *string           `json:"first_id,omitempty"`
+	LastID  *string           `json:"last_id,omitempty"`
+	HasMore bool              `json:"has_more"`
+}
+
+func NewOpenAIBatchID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return openAIBatchIDPrefix + hex.EncodeToString(b[:]), nil
+}
+
+func OpenAIBatchHoldRequestID(batchID string) string {
+	return openAIBatchHoldRequestPrefix + strings.TrimSpace(batchID)
+}
+
+func OpenAIBatchCaptureRequestID(batchID string) string {
+	return openAIBatchCapturePrefix + strings.TrimSpace(batchID)
+}
+
+func OpenAIBatchReleaseRequestID(batchID string) string {
+	return openAIBatchReleasePrefix + strings.TrimSpace(batchID)
+}
+
+// IsPendingOpenAIBatchStatus 报告批次是否仍需后台轮询处理。
+func IsPendingOpenAIBatchStatus(status string) bool {
+	switch status {
+	case OpenAIBatchStatusCreated, OpenAIBatchStatusInProgress, OpenAIBatchStatusSettling:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsTerminalOpenAIBatchUpstreamStatus 报告上游批次是否已结束（不会再产生新结果）。
+func IsTerminalOpenAIBatchUpstreamStatus(status string) bool {
+	switch status {
+	case OpenAIBatchUpstreamCompleted, OpenAIBatchUpstreamFailed, OpenAIBatchUpstreamExpired, OpenAIBatchUpstreamCancelled:
+		return true
+	default:
+		return false
+	}
+}
+
+// OpenAIBatchJobToPublic 返回上游 batch 对象，id 替换为网关侧批次 ID；
+// input/output/error 文件 ID 保持上游值，并可通过网关 /v1/files 访问。
+func OpenAIBatchJobToPublic(job *OpenAIBatchJob) json.RawMessage {
+	if job == nil {
+		return nil
+	}
+	object := job.UpstreamObject
+	if len(object) == 0 {
+		object = json.RawMessage(`{"object":"batch"}`)
+	}
+	out, err := sjson.SetBytes(object, "id", job.BatchID)
+	if err != nil {
+		return object
+	}
+	return out
+}
+
+// OpenAIBatchFileToPublic 返回上游 file 对象；缺失时按本地记录补齐基础字段。
+func OpenAIBatchFileToPublic(file *OpenAIBatchFile) json.RawMessage {
+	if file == nil {
+		return nil
+	}
+	if len(file.UpstreamObject) > 0 {
+		return file.UpstreamObject
+	}
+	out, _ := json.Marshal(map[string]any{
+		"id":         file.FileID,
+		"object":     "file",
+		"bytes":      file.Bytes,
+		"created_at": file.CreatedAt.Unix(),
+		"filename":   file.Filename,
+		"purpose":    file.Purpose,
+	})
+	return out
+}
+
+func openAIBatchStringPtr(v string) *string {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return nil
+	}
+	return &v
+}
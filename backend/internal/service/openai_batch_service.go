@@ -0,0 +1,1105 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultOpenAIBatchDiscountMultiplier = 0.5
+	defaultOpenAIBatchHoldMultiplier     = 1.0
+	defaultOpenAIBatchMaxRequests        = 50000
+	defaultOpenAIBatchMaxFileBytes       = int64(200 * 1024 * 1024)
+	defaultOpenAIBatchMaxOutputTokens    = 4096
+	defaultOpenAIBatchPollInterval       = time.Minute
+	defaultOpenAIBatchPollBatchSize      = 50
+	defaultOpenAIBatchUpstreamTimeout    = 5 * time.Minute
+	defaultOpenAIBatchListLimit          = 20
+	maxOpenAIBatchListLimit              = 100
+	defaultOpenAIFileListLimit           = 100
+	maxOpenAIFileListLimit               = 10000
+	openAIBatchMaxUpstreamErrorBody      = 64 * 1024
+	openAIBatchMaxPurposeBytes           = 64
+	// openAIBatchCreatedStaleAfter 与 Message Batches 相同：created 批次超过该时长仍未
+	// 拿到上游 ID，视为提交进程中途退出，由轮询释放冻结余额。
+	openAIBatchCreatedStaleAfter = 15 * time.Minute
+)
+
+// OpenAIBatchService 代理 OpenAI Files API 与 Batch API。
+//
+// 输入文件上传时流式校验每一行请求并改写模型映射，同时按「请求体字节数作为输入
+// token 上限 + 最大输出 token」逐条估算标准价；文件固定在上传时选中的 OpenAI
+// API Key 账号上，基于该文件创建的批次也走同一账号。创建批次时按估算冻结余额，
+// 批次结束后由后台轮询下载输出文件，按批量折扣价结算，多冻结部分退回。
+type OpenAIBatchService struct {
+	Repo              OpenAIBatchRepository
+	AccountRepo       BatchImageAccountSelectionRepository
+	GroupRepo         BatchImageGroupPricingRepository
+	UserGroupRateRepo BatchImageUserGroupRateRepository
+	BillingRepo       UsageBillingRepository
+	UsageLogRepo      UsageLogRepository
+	Pricing           MessageBatchCostCalculator
+	HTTPUpstream      HTTPUpstream
+	AuthCache         APIKeyAuthCacheInvalidator
+	Config            *config.Config
+
+	now func() time.Time
+
+	poller batchSettlementPoller
+	// volumeTiers 缓存分组月度消费阶梯的判档快照，结算时按阶梯计价。
+	volumeTiers *volumeTierResolver
+}
+
+func NewOpenAIBatchService(
+	repo OpenAIBatchRepository,
+	accountRepo AccountRepository,
+	groupRepo GroupRepository,
+	userGroupRateRepo UserGroupRateRepository,
+	billingRepo UsageBillingRepository,
+	usageLogRepo UsageLogRepository,
+	billingService *BillingService,
+	httpUpstream HTTPUpstream,
+	authCache APIKeyAuthCacheInvalidator,
+	cfg *config.Config,
+) *OpenAIBatchService {
+	return &OpenAIBatchService{
+		Repo:              repo,
+		AccountRepo:       accountRepo,
+		GroupRepo:         groupRepo,
+		UserGroupRateRepo: userGroupRateRepo,
+		BillingRepo:       billingRepo,
+		UsageLogRepo:      usageLogRepo,
+		Pricing:           billingService,
+		HTTPUpstream:      httpUpstream,
+		AuthCache:         authCache,
+		Config:            cfg,
+		volumeTiers:       newVolumeTierResolver(usageLogRepo),
+	}
+}
+
+// openAIBatchUpstreamBatch 是上游 batch 对象中网关关心的字段。
+type openAIBatchUpstreamBatch struct {
+	ID            string `json:"id"`
+	Status        string `json:"status"`
+	OutputFileID  string `json:"output_file_id"`
+	ErrorFileID   string `json:"error_file_id"`
+	RequestCounts struct {
+		Total int `json:"total"`
+	} `json:"request_counts"`
+	Raw json.RawMessage `json:"-"`
+}
+
+// openAIBatchUpstreamFile 是上游 file 对象中网关关心的字段。
+type openAIBatchUpstreamFile struct {
+	ID       string          `json:"id"`
+	Bytes    int64           `json:"bytes"`
+	Filename string          `json:"filename"`
+	Purpose  string          `json:"purpose"`
+	Raw      json.RawMessage `json:"-"`
+}
+
+type openAIBatchPricingSnapshot struct {
+	GroupRateMultiplier     float64
+	AccountRateMultiplier   float64
+	BatchDiscountMultiplier float64
+	EstimatedCost           float64
+	HoldAmount              float64
+}
+
+// openAIBatchCreateRequest 是 POST /v1/batches 的请求体。
+type openAIBatchCreateRequest struct {
+	InputFileID      string `json:"input_file_id"`
+	Endpoint         string `json:"endpoint"`
+	CompletionWindow string `json:"completion_window"`
+}
+
+// openAIBatchUploadScan 汇总上传过程中逐行校验得到的信息。
+type openAIBatchUploadScan struct {
+	Purpose   string
+	Filename  string
+	Bytes     int64
+	Endpoint  string
+	Requests  int
+	SawFile   bool
+	estimates map[string]*OpenAIBatchModelEstimate
+	customIDs map[string]struct{}
+}
+
+func (scan *openAIBatchUploadScan) Estimates() []OpenAIBatchModelEstimate {
+	out := make([]OpenAIBatchModelEstimate, 0, len(scan.estimates))
+	for _, estimate := range scan.estimates {
+		out = append(out, *estimate)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Model < out[j].Model })
+	return out
+}
+
+// ---- Files ----
+
+// UploadFile 流式转发 multipart 上传：边读边校验输入文件的每一行并改写模型映射，
+// 不在内存中缓冲整个文件。任一行校验失败都会中止上游上传。
+func (s *OpenAIBatchService) UploadFile(ctx context.Context, owner OpenAIBatchOwner, mr *multipart.Reader) (*OpenAIBatchFile, error) {
+	if !s.enabled() {
+		return nil, ErrOpenAIBatchDisabled
+	}
+	if mr == nil {
+		return nil, ErrOpenAIBatchInvalidUpload
+	}
+	if err := s.ensureGroupSupportsOpenAIBatches(ctx, owner.GroupID); err != nil {
+		return nil, err
+	}
+	account, err := s.selectAccount(ctx, owner.GroupID)
+	if err != nil {
+		return nil, err
+	}
+
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	scan := &openAIBatchUploadScan{
+		estimates: make(map[string]*OpenAIBatchModelEstimate),
+		customIDs: make(map[string]struct{}),
+	}
+	scanDone := make(chan error, 1)
+	go func() {
+		scanErr := s.streamUploadParts(mr, mw, owner, account, scan)
+		if scanErr == nil {
+			scanErr = mw.Close()
+		}
+		_ = pw.CloseWithError(scanErr)
+		scanDone <- scanErr
+	}()
+
+	resp, doErr := s.doUpstream(ctx, account, http.MethodPost, "/v1/files", pr, mw.FormDataContentType())
+	// 上游提前返回时关闭读端，解除写端阻塞。
+	_ = pr.Close()
+	scanErr := <-scanDone
+
+	var appErr *infraerrors.ApplicationError
+	if scanErr != nil && errors.As(scanErr, &appErr) {
+		// 客户端输入错误优先于上游错误返回；上游若已落盘则尽力删除。
+		if doErr == nil {
+			if uploaded, decodeErr := decodeOpenAIBatchUpstreamFile(resp); decodeErr == nil {
+				s.deleteUpstreamFileBestEffort(ctx, account, uploaded.ID)
+			}
+		}
+		return nil, scanErr
+	}
+	if doErr != nil {
+		return nil, doErr
+	}
+	uploaded, err := decodeOpenAIBatchUpstreamFile(resp)
+	if err != nil {
+		return nil, err
+	}
+	if scanErr != nil {
+		s.deleteUpstreamFileBestEffort(ctx, account, uploaded.ID)
+		return nil, ErrOpenAIBatchUpstreamFailed.WithCause(scanErr)
+	}
+	if !scan.SawFile || scan.Requests == 0 || scan.Purpose != OpenAIFilePurposeBatch {
+		s.deleteUpstreamFileBestEffort(ctx, account, uploaded.ID)
+		if scan.SawFile && scan.Requests == 0 {
+			return nil, infraerrors.New(http.StatusBadRequest, ErrOpenAIBatchInvalidFile.Reason, "batch input file is empty")
+		}
+		return nil, ErrOpenAIBatchInvalidUpload
+	}
+
+	file := &OpenAIBatchFile{
+		FileID:         uploaded.ID,
+		UserID:         owner.UserID,
+		APIKeyID:       owner.APIKeyID,
+		AccountID:      account.ID,
+		Purpose:        OpenAIFilePurposeBatch,
+		Filename:       scan.Filename,
+		Bytes:          uploaded.Bytes,
+		Endpoint:       scan.Endpoint,
+		RequestCount:   scan.Requests,
+		Estimate:       scan.Estimates(),
+		UpstreamObject: uploaded.Raw,
+	}
+	if err := s.Repo.CreateOpenAIBatchFile(ctx, file); err != nil {
+		s.deleteUpstreamFileBestEffort(ctx, account, uploaded.ID)
+		return nil, err
+	}
+	return file, nil
+}
+
+// ListFiles 列出当前 API Key 上传或拥有的文件（未删除）。
+func (s *OpenAIBatchService) ListFiles(ctx context.Context, owner OpenAIBatchOwner, purpose string, query OpenAIBatchListQuery) ([]*OpenAIBatchFile, bool, error) {
+	if !s.enabled() {
+		return nil, false, ErrOpenAIBatchDisabled
+	}
+	limit := clampOpenAIBatchListLimit(query.Limit, defaultOpenAIFileListLimit, maxOpenAIFileListLimit)
+	query.Limit = limit + 1
+	files, err := s.Repo.ListOpenAIBatchFilesForOwner(ctx, owner.APIKeyID, strings.TrimSpace(purpose), query)
+	if err != nil {
+		return nil, false, err
+	}
+	hasMore := len(files) > limit
+	if hasMore {
+		files = files[:limit]
+	}
+	return files, hasMore, nil
+}
+
+// GetFile 返回上游最新的文件对象。
+func (s *OpenAIBatchService) GetFile(ctx context.Context, owner OpenAIBatchOwner, fileID string) (json.RawMessage, error) {
+	file, account, err := s.ownedFile(ctx, owner, fileID)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.doUpstream(ctx, account, http.MethodGet, "/v1/files/"+file.FileID, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	uploaded, err := decodeOpenAIBatchUpstreamFile(resp)
+	if err != nil {
+		return nil, err
+	}
+	return uploaded.Raw, nil
+}
+
+// OpenFileContent 打开上游文件内容流；调用方负责关闭。
+func (s *OpenAIBatchService) OpenFileContent(ctx context.Context, owner OpenAIBatchOwner, fileID string) (io.ReadCloser, error) {
+	file, account, err := s.ownedFile(ctx, owner, fileID)
+	if err != nil {
+		return nil, err
+	}
+	return s.openUpstreamFileContent(ctx, account, file.FileID)
+}
+
+// DeleteFile 删除上游文件并标记本地归属记录为已删除。
+func (s *OpenAIBatchService) DeleteFile(ctx context.Context, owner OpenAIBatchOwner, fileID string) (json.RawMessage, error) {
+	file, account, err := s.ownedFile(ctx, owner, fileID)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.doUpstream(ctx, account, http.MethodDelete, "/v1/files/"+file.FileID, nil, "")
+	if err != nil && infraerrors.Code(err) != http.StatusNotFound {
+		return nil, err
+	}
+	body := json.RawMessage(fmt.Sprintf(`{"id":%q,"object":"file","deleted":true}`, file.FileID))
+	if err == nil {
+		raw, readErr := io.ReadAll(io.LimitReader(resp.Body, openAIBatchMaxUpstreamErrorBody))
+		_ = resp.Body.Close()
+		if readErr == nil && gjson.ValidBytes(raw) {
+			body = raw
+		}
+	}
+	if err := s.Repo.MarkOpenAIBatchFileDeleted(ctx, file.FileID, s.nowTime()); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+func (s *OpenAIBatchService) ownedFile(ctx context.Context, owner OpenAIBatchOwner, fileID string) (*OpenAIBatchFile, *Account, error) {
+	if !s.enabled() {
+		return nil, nil, ErrOpenAIBatchDisabled
+	}
+	fileID = strings.TrimSpace(fileID)
+	if fileID == "" {
+		return nil, nil, ErrOpenAIBatchFileNotFound
+	}
+	file, err := s.Repo.GetOpenAIBatchFileForOwner(ctx, owner.APIKeyID, fileID)
+	if err != nil {
+		return nil, nil, err
+	}
+	account, err := s.loadAccount(ctx, file.AccountID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return file, account, nil
+}
+
+// registerBatchFiles 把批次的输出/错误文件登记到批次所有者名下，使其可通过 /v1/files 访问。
+func (s *OpenAIBatchService) registerBatchFiles(ctx context.Context, job *OpenAIBatchJob, outputFileID, errorFileID *string) {
+	for _, fileID := range []*string{outputFileID, errorFileID} {
+		if fileID == nil || strings.TrimSpace(*fileID) == "" {
+			continue
+		}
+		file := &OpenAIBatchFile{
+			FileID:    *fileID,
+			UserID:    job.UserID,
+			APIKeyID:  job.APIKeyID,
+			AccountID: job.AccountID,
+			Purpose:   OpenAIFilePurposeBatchOutput,
+			Endpoint:  job.Endpoint,
+		}
+		err := s.Repo.CreateOpenAIBatchFile(ctx, file)
+		if err != nil && !errors.Is(err, ErrOpenAIBatchFileExists) {
+			logger.L().Warn("openai_batch.register_output_file_failed",
+				zap.String("batch_id", job.BatchID),
+				zap.String("file_id", *fileID),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// streamUploadParts 依次读取客户端 multipart 各部分并写入上游 multipart：
+// purpose 固定为 batch，file 部分逐行校验与改写。客户端输入错误以 ApplicationError 返回。
+func (s *OpenAIBatchService) streamUploadParts(mr *multipart.Reader, mw *multipart.Writer, owner OpenAIBatchOwner, account *Account, scan *openAIBatchUploadScan) error {
+	if err := mw.WriteField("purpose", OpenAIFilePurposeBatch); err != nil {
+		return err
+	}
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return ErrOpenAIBatchInvalidUpload.WithCause(err)
+		}
+		switch part.FormName() {
+		case "purpose":
+			raw, readErr := io.ReadAll(io.LimitReader(part, openAIBatchMaxPurposeBytes))
+			if readErr != nil {
+				return ErrOpenAIBatchInvalidUpload.WithCause(readErr)
+			}
+			scan.Purpose = strings.TrimSpace(string(raw))
+			if scan.Purpose != OpenAIFilePurposeBatch {
+				return infraerrors.New(http.StatusBadRequest, ErrOpenAIBatchInvalidUpload.Reason, "only purpose=batch is supported")
+			}
+		case "file":
+			if scan.SawFile {
+				return ErrOpenAIBatchInvalidUpload
+			}
+			scan.SawFile = true
+			scan.Filename = strings.TrimSpace(part.FileName())
+			if scan.Filename == "" {
+				scan.Filename = "batch.jsonl"
+			}
+			fw, createErr := mw.CreateFormFile("file", scan.Filename)
+			if createErr != nil {
+				return createErr
+			}
+			if err := s.transformBatchInput(part, fw, owner, account, scan); err != nil {
+				return err
+			}
+		}
+		_ = part.Close()
+	}
+}
+
+// transformBatchInput 逐行校验批处理输入 JSONL：custom_id 唯一、method 为 POST、
+// 所有行 url 相同且受支持、body.model 在 API Key 与账号允许范围内且非流式、
+// 请求体通过提示词审核；随后按账号映射改写 body.model，并逐条累计估算。
+func (s *OpenAIBatchService) transformBatchInput(r io.Reader, w io.Writer, owner OpenAIBatchOwner, account *Account, scan *openAIBatchUploadScan) error {
+	maxBytes := s.maxFileBytes()
+	maxRequests := s.maxRequests()
+	reader := bufio.NewReaderSize(io.LimitReader(r, maxBytes+1), 64*1024)
+	lineNo := 0
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			return ErrOpenAIBatchInvalidUpload.WithCause(readErr)
+		}
+		scan.Bytes += int64(len(line))
+		if scan.Bytes > maxBytes {
+			return ErrOpenAIBatchFileTooLarge.WithMetadata(map[string]string{"max_bytes": strconv.FormatInt(maxBytes, 10)})
+		}
+		trimmed := bytes.TrimSpace(line)
+		if len(trimmed) > 0 {
+			lineNo++
+			if lineNo > maxRequests {
+				return infraerrors.Newf(http.StatusBadRequest, ErrOpenAIBatchInvalidFile.Reason, "batch input file exceeds %d requests", maxRequests)
+			}
+			out, err := s.transformBatchInputLine(trimmed, lineNo, owner, account, scan)
+			if err != nil {
+				return err
+			}
+			if _, err := w.Write(out); err != nil {
+				return err
+			}
+			if _, err := w.Write([]byte{'\n'}); err != nil {
+				return err
+			}
+		}
+		if errors.Is(readErr, io.EOF) {
+			return nil
+		}
+	}
+}
+
+func (s *OpenAIBatchService) transformBatchInputLine(line []byte, lineNo int, owner OpenAIBatchOwner, account *Account, scan *openAIBatchUploadScan) ([]byte, error) {
+	invalid := func(format string, a ...any) error {
+		return infraerrors.Newf(http.StatusBadRequest, ErrOpenAIBatchInvalidFile.Reason, "line %d: "+format, append([]any{lineNo}, a...)...)
+	}
+	if !gjson.ValidBytes(line) {
+		return nil, invalid("invalid JSON")
+	}
+	parsed := gjson.ParseBytes(line)
+	customID := strings.TrimSpace(parsed.Get("custom_id").String())
+	if customID == "" {
+		return nil, invalid("custom_id is required")
+	}
+	if _, dup := scan.customIDs[customID]; dup {
+		return nil, invalid("duplicate custom_id %q", customID)
+	}
+	scan.customIDs[customID] = struct{}{}
+	if !strings.EqualFold(parsed.Get("method").String(), http.MethodPost) {
+		return nil, invalid("method must be POST")
+	}
+	endpoint := strings.TrimSpace(parsed.Get("url").String())
+	if !openAIBatchSupportedEndpoints[endpoint] {
+		return nil, invalid("unsupported url %q", endpoint)
+	}
+	if scan.Endpoint == "" {
+		scan.Endpoint = endpoint
+	} else if scan.Endpoint != endpoint {
+		return nil, invalid("all requests must use the same url")
+	}
+	body := parsed.Get("body")
+	if !body.IsObject() {
+		return nil, invalid("body must be an object")
+	}
+	model := strings.TrimSpace(body.Get("model").String())
+	if model == "" {
+		return nil, invalid("body.model is required")
+	}
+	if body.Get("stream").Bool() {
+		return nil, invalid("streaming is not supported in batches")
+	}
+	if owner.APIKey != nil && owner.APIKey.HasModelRestrictions() && !owner.APIKey.IsModelAllowed(model) {
+		return nil, infraerrors.Newf(http.StatusForbidden, ErrOpenAIBatchModelNotAllowed.Reason, "model %q is not allowed for this API key", model)
+	}
+	if !account.IsModelSupported(model) {
+		return nil, invalid("model %q is not available", model)
+	}
+	if owner.AuditLine != nil {
+		if err := owner.AuditLine(endpoint, model, []byte(body.Raw)); err != nil {
+			return nil, err
+		}
+	}
+
+	billingModel := resolveOpenAIForwardModel(account, model, "")
+	upstreamModel := normalizeOpenAIModelForUpstream(account, billingModel)
+	out := line
+	if upstreamModel != "" && upstreamModel != model {
+		var err error
+		out, err = sjson.SetBytes(line, "body.model", upstreamModel)
+		if err != nil {
+			return nil, invalid("rewrite model: %v", err)
+		}
+	}
+
+	tokens := UsageTokens{InputTokens: len(body.Raw)}
+	if endpoint != "/v1/embeddings" {
+		tokens.OutputTokens = openAIBatchMaxOutputTokens(body, s.defaultMaxOutputTokens())
+	}
+	if s.Pricing == nil {
+		return nil, ErrOpenAIBatchPricingMissing
+	}
+	cost, err := s.Pricing.CalculateCost(billingModel, tokens, 1.0)
+	if err != nil || cost == nil {
+		return nil, infraerrors.Newf(http.StatusBadRequest, ErrOpenAIBatchPricingMissing.Reason, "pricing is not available for model %q", billingModel)
+	}
+	estimate := scan.estimates[billingModel]
+	if estimate == nil {
+		estimate = &OpenAIBatchModelEstimate{Model: billingModel}
+		scan.estimates[billingModel] = estimate
+	}
+	estimate.Requests++
+	estimate.InputTokens += tokens.InputTokens
+	estimate.OutputTokens += tokens.OutputTokens
+	estimate.StandardCost += cost.TotalCost
+	scan.Requests++
+	return out, nil
+}
+
+// openAIBatchMaxOutputTokens 取请求声明的最大输出 token；未声明时使用默认值。
+func openAIBatchMaxOutputTokens(body gjson.Result, fallback int) int {
+	for _, key := range []string{"max_completion_tokens", "max_output_tokens", "max_tokens"} {
+		if v := body.Get(key).Int(); v > 0 {
+			return int(v)
+		}
+	}
+	return fallback
+}
+
+// ---- Batches ----
+
+// CreateBatch 基于已上传的输入文件创建批次：冻结估算金额后提交到文件所在账号。
+func (s *OpenAIBatchService) CreateBatch(ctx context.Context, owner OpenAIBatchOwner, body []byte, idempotencyKey string) (*OpenAIBatchJob, error) {
+	if !s.enabled() {
+		return nil, ErrOpenAIBatchDisabled
+	}
+	if err := s.ensureGroupSupportsOpenAIBatches(ctx, owner.GroupID); err != nil {
+		return nil, err
+	}
+	var req openAIBatchCreateRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, ErrOpenAIBatchInvalidRequest.WithCause(err)
+	}
+	req.InputFileID = strings.TrimSpace(req.InputFileID)
+	req.Endpoint = strings.TrimSpace(req.Endpoint)
+	if req.InputFileID == "" || req.Endpoint == "" {
+		return nil, ErrOpenAIBatchInvalidRequest
+	}
+	file, err := s.Repo.GetOpenAIBatchFileForOwner(ctx, owner.APIKeyID, req.InputFileID)
+	if err != nil {
+		return nil, err
+	}
+	if file.Purpose != OpenAIFilePurposeBatch {
+		return nil, infraerrors.New(http.StatusBadRequest, ErrOpenAIBatchInvalidRequest.Reason, "input file must have purpose=batch")
+	}
+	if file.Endpoint != req.Endpoint {
+		return nil, ErrOpenAIBatchEndpointMismatch
+	}
+	requestHash := hashMessageBatchRequest(body)
+	idempotencyKey = strings.TrimSpace(idempotencyKey)
+	if idempotencyKey != "" {
+		existing, err := s.Repo.GetOpenAIBatchJobByIdempotencyKey(ctx, owner.APIKeyID, idempotencyKey)
+		if err == nil {
+			if existing.RequestHash != requestHash {
+				return nil, ErrOpenAIBatchIdempotencyReuse
+			}
+			if existing.UpstreamBatchID == nil {
+				return nil, ErrOpenAIBatchNotFound
+			}
+			return existing, nil
+		}
+		if !errors.Is(err, ErrOpenAIBatchNotFound) {
+			return nil, err
+		}
+	}
+
+	account, err := s.loadAccount(ctx, file.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if !account.IsOpenAIApiKey() || account.IsAzureOpenAI() || !account.IsSchedulable() {
+		return nil, ErrOpenAIBatchNoAccountAvailable
+	}
+	pricing, err := s.resolvePricingSnapshot(ctx, owner, account, file.Estimate)
+	if err != nil {
+		return nil, err
+	}
+
+	batchID, err := NewOpenAIBatchID()
+	if err != nil {
+		return nil, err
+	}
+	now := s.nowTime()
+	staleAt := now.Add(openAIBatchCreatedStaleAfter)
+	job := &OpenAIBatchJob{
+		BatchID:                 batchID,
+		UserID:                  owner.UserID,
+		APIKeyID:                owner.APIKeyID,
+		AccountID:               account.ID,
+		GroupID:                 owner.GroupID,
+		InputFileID:             file.FileID,
+		Endpoint:                file.Endpoint,
+		Status:                  OpenAIBatchStatusCreated,
+		RequestCount:            file.RequestCount,
+		EstimatedCost:           pricing.EstimatedCost,
+		HoldAmount:              pricing.HoldAmount,
+		GroupRateMultiplier:     pricing.GroupRateMultiplier,
+		AccountRateMultiplier:   pricing.AccountRateMultiplier,
+		BatchDiscountMultiplier: pricing.BatchDiscountMultiplier,
+		IdempotencyKey:          openAIBatchStringPtr(idempotencyKey),
+		RequestHash:             requestHash,
+		NextPollAt:              &staleAt,
+	}
+	if err := s.Repo.CreateOpenAIBatchJob(ctx, job); err != nil {
+		return nil, err
+	}
+	settlement := s.settlement()
+	if err := settlement.reserveHold(ctx, job); err != nil {
+		settlement.markFailedBestEffort(ctx, job.BatchID, "BILLING_HOLD_FAILED", err.Error())
+		return nil, err
+	}
+
+	upstream, err := s.submitUpstream(ctx, account, body)
+	if err != nil {
+		if releaseErr := settlement.releaseHold(ctx, job); releaseErr != nil {
+			// 释放失败时保持 created 状态，由轮询在 stale 后重试释放。
+			_, _ = s.Repo.RecordOpenAIBatchJobError(ctx, job.BatchID, "BILLING_RELEASE_FAILED", truncateString(releaseErr.Error(), openAIBatchMaxErrorMessageSize), now)
+			return nil, err
+		}
+		settlement.markFailedBestEffort(ctx, job.BatchID, "UPSTREAM_SUBMIT_FAILED", err.Error())
+		return nil, err
+	}
+
+	progress := s.progressFromUpstream(upstream)
+	if err := s.Repo.MarkOpenAIBatchJobSubmitted(ctx, job.BatchID, upstream.ID, progress); err != nil {
+		// 上游批次已创建：尽力取消，冻结余额由轮询在 created 超时后释放。
+		logger.L().Error("openai_batch.mark_submitted_failed",
+			zap.String("batch_id", job.BatchID),
+			zap.Int64("account_id", account.ID),
+			zap.Error(err),
+		)
+		if _, cancelErr := s.cancelUpstream(ctx, account, upstream.ID); cancelErr != nil {
+			logger.L().Warn("openai_batch.orphan_cancel_failed",
+				zap.String("batch_id", job.BatchID),
+				zap.Error(cancelErr),
+			)
+		}
+		return nil, err
+	}
+	return s.Repo.GetOpenAIBatchJob(ctx, job.BatchID)
+}
+
+// GetBatch 返回批次；进行中的批次会尽力从上游同步一次最新状态。
+func (s *OpenAIBatchService) GetBatch(ctx context.Context, owner OpenAIBatchOwner, batchID string) (*OpenAIBatchJob, error) {
+	job, err := s.getVisibleJob(ctx, owner, batchID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status == OpenAIBatchStatusInProgress {
+		refreshed, refreshErr := s.refreshFromUpstream(ctx, job)
+		if refreshErr == nil {
+			return refreshed, nil
+		}
+		logger.L().Warn("openai_batch.refresh_failed",
+			zap.String("batch_id", job.BatchID),
+			zap.Error(refreshErr),
+		)
+	}
+	return job, nil
+}
+
+// ListBatches 按创建时间倒序分页列出当前 API Key 的批次。
+func (s *OpenAIBatchService) ListBatches(ctx context.Context, owner OpenAIBatchOwner, query OpenAIBatchListQuery) ([]*OpenAIBatchJob, bool, error) {
+	if !s.enabled() {
+		return nil, false, ErrOpenAIBatchDisabled
+	}
+	limit := clampOpenAIBatchListLimit(query.Limit, defaultOpenAIBatchListLimit, maxOpenAIBatchListLimit)
+	query.Limit = limit + 1
+	jobs, err := s.Repo.ListOpenAIBatchJobsForOwner(ctx, owner.APIKeyID, query)
+	if err != nil {
+		return nil, false, err
+	}
+	hasMore := len(jobs) > limit
+	if hasMore {
+		jobs = jobs[:limit]
+	}
+	return jobs, hasMore, nil
+}
+
+// CancelBatch 请求上游取消批次；上游已结束的批次返回当前状态。
+func (s *OpenAIBatchService) CancelBatch(ctx context.Context, owner OpenAIBatchOwner, batchID string) (*OpenAIBatchJob, error) {
+	job, err := s.getVisibleJob(ctx, owner, batchID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != OpenAIBatchStatusInProgress {
+		return nil, ErrOpenAIBatchInvalidTransition
+	}
+	account, err := s.loadAccount(ctx, job.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	upstream, err := s.cancelUpstream(ctx, account, *job.UpstreamBatchID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyUpstreamProgress(ctx, job, upstream); err != nil {
+		return nil, err
+	}
+	return s.Repo.GetOpenAIBatchJob(ctx, job.BatchID)
+}
+
+func (s *OpenAIBatchService) getVisibleJob(ctx context.Context, owner OpenAIBatchOwner, batchID string) (*OpenAIBatchJob, error) {
+	if !s.enabled() {
+		return nil, ErrOpenAIBatchDisabled
+	}
+	batchID = strings.TrimSpace(batchID)
+	if !strings.HasPrefix(batchID, openAIBatchIDPrefix) {
+		return nil, ErrOpenAIBatchNotFound
+	}
+	job, err := s.Repo.GetOpenAIBatchJobForOwner(ctx, owner.APIKeyID, batchID)
+	if err != nil {
+		return nil, err
+	}
+	// 未成功提交到上游的批次对客户端不可见。
+	if job.UpstreamBatchID == nil || strings.TrimSpace(*job.UpstreamBatchID) == "" {
+		return nil, ErrOpenAIBatchNotFound
+	}
+	return job, nil
+}
+
+func (s *OpenAIBatchService) refreshFromUpstream(ctx context.Context, job *OpenAIBatchJob) (*OpenAIBatchJob, error) {
+	account, err := s.loadAccount(ctx, job.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	upstream, err := s.getUpstream(ctx, account, *job.UpstreamBatchID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyUpstreamProgress(ctx, job, upstream); err != nil {
+		return nil, err
+	}
+	return s.Repo.GetOpenAIBatchJob(ctx, job.BatchID)
+}
+
+// applyUpstreamProgress 把上游状态写回本地；上游已结束的批次登记输出文件并转入 settling。
+func (s *OpenAIBatchService) applyUpstreamProgress(ctx context.Context, job *OpenAIBatchJob, upstream *openAIBatchUpstreamBatch) error {
+	progress := s.progressFromUpstream(upstream)
+	s.registerBatchFiles(ctx, job, progress.OutputFileID, progress.ErrorFileID)
+	err := s.Repo.UpdateOpenAIBatchJobProgress(ctx, job.BatchID, progress)
+	if errors.Is(err, ErrOpenAIBatchInvalidTransition) {
+		// 并发的轮询已推进到 settling 及之后，本次同步无需写回。
+		return nil
+	}
+	return err
+}
+
+func (s *OpenAIBatchService) progressFromUpstream(upstream *openAIBatchUpstreamBatch) OpenAIBatchProgress {
+	now := s.nowTime()
+	next := now.Add(s.pollInterval())
+	status := OpenAIBatchStatusInProgress
+	if IsTerminalOpenAIBatchUpstreamStatus(upstream.Status) {
+		status = OpenAIBatchStatusSettling
+		next = now
+	}
+	return OpenAIBatchProgress{
+		Status:         status,
+		UpstreamStatus: upstream.Status,
+		UpstreamObject: upstream.Raw,
+		OutputFileID:   openAIBatchStringPtr(upstream.OutputFileID),
+		ErrorFileID:    openAIBatchStringPtr(upstream.ErrorFileID),
+		RequestCount:   upstream.RequestCounts.Total,
+		NextPollAt:     &next,
+	}
+}
+
+func (s *OpenAIBatchService) ensureGroupSupportsOpenAIBatches(ctx context.Context, groupID *int64) error {
+	if groupID == nil || *groupID <= 0 {
+		return nil
+	}
+	if s.GroupRepo == nil {
+		return ErrOpenAIBatchGroupUnsupported
+	}
+	group, err := s.GroupRepo.GetByIDLite(ctx, *groupID)
+	if err != nil || group == nil {
+		return ErrOpenAIBatchGroupUnsupported
+	}
+	if group.Platform != PlatformOpenAI {
+		return ErrOpenAIBatchGroupUnsupported
+	}
+	return nil
+}
+
+// selectAccount 按优先级选择可调度的 OpenAI API Key 账号；OAuth 账号不支持 Files / Batch API。
+func (s *OpenAIBatchService) selectAccount(ctx context.Context, groupID *int64) (*Account, error) {
+	if s.AccountRepo == nil {
+		return nil, ErrOpenAIBatchNoAccountAvailable
+	}
+	var (
+		accounts []Account
+		err      error
+	)
+	if groupID != nil && *groupID > 0 {
+		accounts, err = s.AccountRepo.ListSchedulableByGroupIDAndPlatform(ctx, *groupID, PlatformOpenAI)
+	} else {
+		accounts, err = s.AccountRepo.ListSchedulableByPlatform(ctx, PlatformOpenAI)
+	}
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(accounts, func(i, j int) bool {
+		if accounts[i].Priority != accounts[j].Priority {
+			return accounts[i].Priority > accounts[j].Priority
+		}
+		return accounts[i].ID < accounts[j].ID
+	})
+	for i := range accounts {
+		account := accounts[i]
+		// Azure 的 Batch 接口走部署级文件与全局批处理部署，与 /v1/batches 不兼容
+		if account.IsOpenAIApiKey() && !account.IsAzureOpenAI() && account.IsSchedulable() && account.GetOpenAIApiKey() != "" {
+			return &account, nil
+		}
+	}
+	return nil, ErrOpenAIBatchNoAccountAvailable
+}
+
+func (s *OpenAIBatchService) loadAccount(ctx context.Context, accountID int64) (*Account, error) {
+	if s.AccountRepo == nil {
+		return nil, ErrOpenAIBatchNoAccountAvailable
+	}
+	account, err := s.AccountRepo.GetByID(ctx, accountID)
+	if err != nil || account == nil {
+		return nil, ErrOpenAIBatchUpstreamFailed.WithCause(fmt.Errorf("load account %d: %w", accountID, err))
+	}
+	return account, nil
+}
+
+func (s *OpenAIBatchService) resolvePricingSnapshot(ctx context.Context, owner OpenAIBatchOwner, account *Account, estimates []OpenAIBatchModelEstimate) (*openAIBatchPricingSnapshot, error) {
+	groupMultiplier := 1.0
+	if owner.GroupID != nil && *owner.GroupID > 0 && s.GroupRepo != nil {
+		group, err := s.GroupRepo.GetByIDLite(ctx, *owner.GroupID)
+		if err != nil || group == nil {
+			return nil, ErrOpenAIBatchPricingMissing
+		}
+		groupMultiplier = group.RateMultiplier
+		if s.UserGroupRateRepo != nil {
+			userRate, rateErr := s.UserGroupRateRepo.GetByUserAndGroup(ctx, owner.UserID, group.ID)
+			if rateErr != nil {
+				return nil, ErrOpenAIBatchPricingMissing
+			}
+			if userRate != nil {
+				groupMultiplier = *userRate
+			}
+		}
+	}
+	if groupMultiplier < 0 {
+		groupMultiplier = 0
+	}
+	accountMultiplier := account.BillingRateMultiplier()
+	if accountMultiplier < 0 {
+		accountMultiplier = 0
+	}
+	discount, hold := s.discountMultiplier(), s.holdMultiplier()
+	if hold < discount {
+		hold = discount
+	}
+	standard := 0.0
+	for _, estimate := range estimates {
+		standard += estimate.StandardCost
+	}
+	standard *= groupMultiplier * accountMultiplier
+	return &openAIBatchPricingSnapshot{
+		GroupRateMultiplier:     groupMultiplier,
+		AccountRateMultiplier:   accountMultiplier,
+		BatchDiscountMultiplier: discount,
+		EstimatedCost:           standard * discount,
+		HoldAmount:              standard * hold,
+	}, nil
+}
+
+// ---- 上游调用 ----
+
+func (s *OpenAIBatchService) submitUpstream(ctx context.Context, account *Account, body []byte) (*openAIBatchUpstreamBatch, error) {
+	resp, err := s.doUpstream(ctx, account, http.MethodPost, "/v1/batches", bytes.NewReader(body), "application/json")
+	if err != nil {
+		return nil, err
+	}
+	return decodeOpenAIBatchUpstreamBatch(resp)
+}
+
+func (s *OpenAIBatchService) getUpstream(ctx context.Context, account *Account, upstreamID string) (*openAIBatchUpstreamBatch, error) {
+	resp, err := s.doUpstream(ctx, account, http.MethodGet, "/v1/batches/"+upstreamID, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	return decodeOpenAIBatchUpstreamBatch(resp)
+}
+
+func (s *OpenAIBatchService) cancelUpstream(ctx context.Context, account *Account, upstreamID string) (*openAIBatchUpstreamBatch, error) {
+	resp, err := s.doUpstream(ctx, account, http.MethodPost, "/v1/batches/"+upstreamID+"/cancel", nil, "")
+	if err != nil {
+		return nil, err
+	}
+	return decodeOpenAIBatchUpstreamBatch(resp)
+}
+
+func (s *OpenAIBatchService) openUpstreamFileContent(ctx context.Context, account *Account, fileID string) (io.ReadCloser, error) {
+	resp, err := s.doUpstream(ctx, account, http.MethodGet, "/v1/files/"+fileID+"/content", nil, "")
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (s *OpenAIBatchService) deleteUpstreamFileBestEffort(ctx context.Context, account *Account, fileID string) {
+	if strings.TrimSpace(fileID) == "" {
+		return
+	}
+	resp, err := s.doUpstream(ctx, account, http.MethodDelete, "/v1/files/"+fileID, nil, "")
+	if err != nil {
+		logger.L().Warn("openai_batch.delete_upstream_file_failed",
+			zap.Int64("account_id", account.ID),
+			zap.String("file_id", fileID),
+			zap.Error(err),
+		)
+		return
+	}
+	_ = resp.Body.Close()
+}
+
+// doUpstream 发起上游请求；非 2xx 响应会被读取并转换为错误，成功时调用方负责关闭 Body。
+func (s *OpenAIBatchService) doUpstream(ctx context.Context, account *Account, method, endpoint string, body io.Reader, contentType string) (*http.Response, error) {
+	if s.HTTPUpstream == nil {
+		return nil, ErrOpenAIBatchUpstreamFailed.WithCause(errors.New("http upstream is not configured"))
+	}
+	apiKey := account.GetOpenAIApiKey()
+	if apiKey == "" {
+		return nil, ErrOpenAIBatchNoAccountAvailable
+	}
+	baseURL, err := validateUpstreamBaseURLWithConfig(s.Config, account.GetOpenAIBaseURL())
+	if err != nil {
+		return nil, ErrOpenAIBatchUpstreamFailed.WithCause(err)
+	}
+	req, err := http.NewRequestWithContext(WithHTTPUpstreamProfile(ctx, HTTPUpstreamProfileOpenAI), method, buildOpenAIEndpointURL(baseURL, endpoint), body)
+	if err != nil {
+		return nil, ErrOpenAIBatchUpstreamFailed.WithCause(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if customUA := account.GetOpenAIUserAgent(); customUA != "" {
+		req.Header.Set("User-Agent", customUA)
+	}
+	account.ApplyHeaderOverrides(req.Header)
+	proxyURL := ""
+	if account.ProxyID != nil && account.Proxy != nil {
+		proxyURL = account.Proxy.URL()
+	}
+	resp, err := s.HTTPUpstream.Do(req, proxyURL, account.ID, account.Concurrency)
+	if err != nil {
+		return nil, ErrOpenAIBatchUpstreamFailed.WithCause(err)
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer func() { _ = resp.Body.Close() }()
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, openAIBatchMaxUpstreamErrorBody))
+	return nil, openAIBatchUpstreamError(resp.StatusCode, raw)
+}
+
+// openAIBatchUpstreamError 与 messageBatchUpstreamError 口径一致：请求校验类错误透出上游信息，
+// 鉴权/限流/服务端错误统一为网关错误。
+func openAIBatchUpstreamError(status int, body []byte) error {
+	message := strings.TrimSpace(gjson.GetBytes(body, "error.message").String())
+	switch status {
+	case http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity:
+		if message == "" {
+			message = http.StatusText(status)
+		}
+		return infraerrors.New(status, "OPENAI_BATCH_UPSTREAM_REJECTED", message)
+	}
+	return ErrOpenAIBatchUpstreamFailed.WithCause(fmt.Errorf("upstream status %d: %s", status, truncateString(string(body), 512)))
+}
+
+func decodeOpenAIBatchUpstreamBatch(resp *http.Response) (*openAIBatchUpstreamBatch, error) {
+	defer func() { _ = resp.Body.Close() }()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, ErrOpenAIBatchUpstreamFailed.WithCause(fmt.Errorf("read upstream batch: %w", err))
+	}
+	var out openAIBatchUpstreamBatch
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, ErrOpenAIBatchUpstreamFailed.WithCause(fmt.Errorf("decode upstream batch: %w", err))
+	}
+	if strings.TrimSpace(out.ID) == "" {
+		return nil, ErrOpenAIBatchUpstreamFailed.WithCause(errors.New("upstream batch id is missing"))
+	}
+	out.Raw = raw
+	return &out, nil
+}
+
+func decodeOpenAIBatchUpstreamFile(resp *http.Response) (*openAIBatchUpstreamFile, error) {
+	defer func() { _ = resp.Body.Close() }()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, ErrOpenAIBatchUpstreamFailed.WithCause(fmt.Errorf("read upstream file: %w", err))
+	}
+	var out openAIBatchUpstreamFile
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, ErrOpenAIBatchUpstreamFailed.WithCause(fmt.Errorf("decode upstream file: %w", err))
+	}
+	if strings.TrimSpace(out.ID) == "" {
+		return nil, ErrOpenAIBatchUpstreamFailed.WithCause(errors.New("upstream file id is missing"))
+	}
+	out.Raw = raw
+	return &out, nil
+}
+
+func clampOpenAIBatchListLimit(limit, fallback, max int) int {
+	if limit <= 0 {
+		return fallback
+	}
+	if limit > max {
+		return max
+	}
+	return limit
+}
+
+// ---- 配置 ----
+
+func (s *OpenAIBatchService) enabled() bool {
+	return s != nil && s.Repo != nil && s.Config != nil && s.Config.OpenAIBatch.Enabled
+}
+
+func (s *OpenAIBatchService) nowTime() time.Time {
+	if s.now != nil {
+		return s.now()
+	}
+	return time.Now()
+}
+
+func (s *OpenAIBatchService) maxRequests() int {
+	if s.Config != nil && s.Config.OpenAIBatch.MaxRequestsPerFile > 0 {
+		return s.Config.OpenAIBatch.MaxRequestsPerFile
+	}
+	return defaultOpenAIBatchMaxRequests
+}
+
+func (s *OpenAIBatchService) maxFileBytes() int64 {
+	if s.Config != nil && s.Config.OpenAIBatch.MaxFileBytes > 0 {
+		return s.Config.OpenAIBatch.MaxFileBytes
+	}
+	return defaultOpenAIBatchMaxFileBytes
+}
+
+func (s *OpenAIBatchService) defaultMaxOutputTokens() int {
+	if s.Config != nil && s.Config.OpenAIBatch.DefaultMaxOutputTokens > 0 {
+		return s.Config.OpenAIBatch.DefaultMaxOutputTokens
+	}
+	return defaultOpenAIBatchMaxOutputTokens
+}
+
+func (s *OpenAIBatchService) discountMultiplier() float64 {
+	if s.Config != nil && s.Config.OpenAIBatch.DiscountMultiplier > 0 {
+		return s.Config.OpenAIBatch.DiscountMultiplier
+	}
+	return defaultOpenAIBatchDiscountMultiplier
+}
+
+func (s *OpenAIBatchService) holdMultiplier() float64 {
+	if s.Config != nil && s.Config.OpenAIBatch.HoldMultiplier > 0 {
+		return s.Config.OpenAIBatch.HoldMultiplier
+	}
+	return defaultOpenAIBatchHoldMultiplier
+}
+
+func (s *OpenAIBatchService) pollInterval() time.Duration {
+	if s.Config != nil && s.Config.OpenAIBatch.PollIntervalSeconds > 0 {
+		return time.Duration(s.Config.OpenAIBatch.PollIntervalSeconds) * time.Second
+	}
+	return defaultOpenAIBatchPollInterval
+}
+
+func (s *OpenAIBatchService) pollBatchSize() int {
+	if s.Config != nil && s.Config.OpenAIBatch.PollBatchSize > 0 {
+		return s.Config.OpenAIBatch.PollBatchSize
+	}
+	return defaultOpenAIBatchPollBatchSize
+}
+
+func (s *OpenAIBatchService) upstreamTimeout() time.Duration {
+	if s.Config != nil && s.Config.OpenAIBatch.UpstreamTimeoutSeconds > 0 {
+		return time.Duration(s.Config.OpenAIBatch.UpstreamTimeoutSeconds) * time.Second
+	}
+	return defaultOpenAIBatchUpstreamTimeout
+}
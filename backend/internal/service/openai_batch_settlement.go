@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// openAIBatchOutputLine 是输出文件 JSONL 中计费所需的字段。
+type openAIBatchOutputLine struct {
+	CustomID string `json:"custom_id"`
+	Response *struct {
+		StatusCode int `json:"status_code"`
+		Body       struct {
+			Model string `json:"model"`
+			Usage struct {
+				PromptTokens        int `json:"prompt_tokens"`
+				CompletionTokens    int `json:"completion_tokens"`
+				InputTokens         int `json:"input_tokens"`
+				OutputTokens        int `json:"output_tokens"`
+				PromptTokensDetails *struct {
+					CachedTokens int `json:"cached_tokens"`
+				} `json:"prompt_tokens_details"`
+				InputTokensDetails *struct {
+					CachedTokens int `json:"cached_tokens"`
+				} `json:"input_tokens_details"`
+			} `json:"usage"`
+		} `json:"body"`
+	} `json:"response"`
+}
+
+// Start 启动后台轮询：同步进行中批次的上游状态，并结算已结束的批次。
+func (s *OpenAIBatchService) Start() {
+	if !s.enabled() {
+		return
+	}
+	s.poller.start(s.pollInterval(), func(ctx context.Context) { s.RunOnce(ctx) })
+}
+
+func (s *OpenAIBatchService) Stop() {
+	if s == nil {
+		return
+	}
+	s.poller.stop()
+}
+
+// RunOnce 领取一轮到期批次并逐个处理，返回处理的批次数。
+func (s *OpenAIBatchService) RunOnce(ctx context.Context) int {
+	if !s.enabled() {
+		return 0
+	}
+	return s.settlement().runOnce(ctx)
+}
+
+// Settle 扫描输出文件中成功请求（status_code 200）的 usage，按批量折扣价从冻结余额中
+// 结算并写入用量记录。没有输出文件的批次（全部失败 / 过期 / 取消）结算为 0，冻结全额退回。
+func (s *OpenAIBatchService) Settle(ctx context.Context, job *OpenAIBatchJob) error {
+	return s.settlement().settle(ctx, job)
+}
+
+func (s *OpenAIBatchService) settlement() *batchSettlementEngine[*OpenAIBatchJob] {
+	return &batchSettlementEngine[*OpenAIBatchJob]{
+		provider: openAIBatchSettlementProvider{s},
+		name:     "openai_batch",
+		errs: batchSettlementErrors{
+			BillingFailed:     ErrOpenAIBatchBillingFailed,
+			InsufficientFunds: ErrOpenAIBatchInsufficientFunds,
+			PricingMissing:    ErrOpenAIBatchPricingMissing,
+			InvalidTransition: ErrOpenAIBatchInvalidTransition,
+		},
+		holdRequestID:       OpenAIBatchHoldRequestID,
+		captureRequestID:    OpenAIBatchCaptureRequestID,
+		releaseRequestID:    OpenAIBatchReleaseRequestID,
+		billingRepo:         s.BillingRepo,
+		usageLogRepo:        s.UsageLogRepo,
+		pricing:             s.Pricing,
+		authCache:           s.AuthCache,
+		groupRepo:           s.GroupRepo,
+		volumeTiers:         s.volumeTiers,
+		now:                 s.nowTime,
+		pollBatchSize:       s.pollBatchSize(),
+		upstreamTimeout:     s.upstreamTimeout(),
+		maxErrorMessageSize: openAIBatchMaxErrorMessageSize,
+	}
+}
+
+// openAIBatchSettlementProvider 把 OpenAI Batch API 接入批次结算引擎。
+type openAIBatchSettlementProvider struct {
+	*OpenAIBatchService
+}
+
+func (p openAIBatchSettlementProvider) settlementJob(job *OpenAIBatchJob) batchSettlementJob {
+	phase := batchSettlementPhaseIdle
+	switch job.Status {
+	case OpenAIBatchStatusCreated:
+		phase = batchSettlementPhaseCreated
+	case OpenAIBatchStatusInProgress:
+		phase = batchSettlementPhasePolling
+	case OpenAIBatchStatusSettling:
+		phase = batchSettlementPhaseSettling
+	}
+	return batchSettlementJob{
+		BatchID:                 job.BatchID,
+		Phase:                   phase,
+		UserID:                  job.UserID,
+		APIKeyID:                job.APIKeyID,
+		AccountID:               job.AccountID,
+		GroupID:                 job.GroupID,
+		HoldAmount:              job.HoldAmount,
+		RequestHash:             job.RequestHash,
+		RetryCount:              job.RetryCount,
+		LastErrorCode:           job.LastErrorCode,
+		GroupRateMultiplier:     job.GroupRateMultiplier,
+		AccountRateMultiplier:   job.AccountRateMultiplier,
+		BatchDiscountMultiplier: job.BatchDiscountMultiplier,
+	}
+}
+
+func (p openAIBatchSettlementProvider) claimDueJobs(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*OpenAIBatchJob, error) {
+	return p.Repo.ClaimDueOpenAIBatchJobs(ctx, now, lease, limit)
+}
+
+func (p openAIBatchSettlementProvider) scanSettlementUsage(ctx context.Context, job *OpenAIBatchJob) ([]messageBatchModelUsage, string, error) {
+	// 上游在提交时即已结束的批次不会经过 applyUpstreamProgress，这里补登记输出文件。
+	p.registerBatchFiles(ctx, job, job.OutputFileID, job.ErrorFileID)
+	outputFileID := strings.TrimSpace(derefStr(job.OutputFileID))
+	if outputFileID == "" {
+		return nil, "", nil
+	}
+	account, err := p.loadAccount(ctx, job.AccountID)
+	if err != nil {
+		return nil, "SETTLEMENT_ACCOUNT_MISSING", err
+	}
+	output, err := p.openUpstreamFileContent(ctx, account, outputFileID)
+	if err != nil {
+		return nil, "SETTLEMENT_OUTPUT_FAILED", err
+	}
+	usages, err := scanOpenAIBatchOutputUsage(output)
+	_ = output.Close()
+	if err != nil {
+		return nil, "SETTLEMENT_OUTPUT_FAILED", err
+	}
+	return usages, "", nil
+}
+
+func (p openAIBatchSettlementProvider) markSettled(ctx context.Context, job *OpenAIBatchJob, charges []messageBatchModelCharge, actualCost float64, settledAt time.Time) error {
+	params := MarkOpenAIBatchJobSettledParams{BatchID: job.BatchID, ActualCost: actualCost, SettledAt: settledAt}
+	for _, charge := range charges {
+		params.InputTokens += int64(charge.Usage.Tokens.InputTokens)
+		params.OutputTokens += int64(charge.Usage.Tokens.OutputTokens)
+		params.CacheReadTokens += int64(charge.Usage.Tokens.CacheReadTokens)
+	}
+	return p.Repo.MarkOpenAIBatchJobSettled(ctx, params)
+}
+
+func (p openAIBatchSettlementProvider) markFailed(ctx context.Context, batchID, code, message string) error {
+	return p.Repo.MarkOpenAIBatchJobFailed(ctx, batchID, code, message)
+}
+
+func (p openAIBatchSettlementProvider) markSettlementStuck(ctx context.Context, batchID, code, message string) error {
+	return p.Repo.MarkOpenAIBatchJobSettlementStuck(ctx, batchID, code, message)
+}
+
+func (p openAIBatchSettlementProvider) recordError(ctx context.Context, job *OpenAIBatchJob, code, message string, nextPollAt time.Time) error {
+	retryCount, err := p.Repo.RecordOpenAIBatchJobError(ctx, job.BatchID, code, message, nextPollAt)
+	if err != nil {
+		return err
+	}
+	job.RetryCount = retryCount
+	job.LastErrorCode = &code
+	return nil
+}
+
+func (p openAIBatchSettlementProvider) usageLog(job *OpenAIBatchJob, charge messageBatchModelCharge) *UsageLog {
+	inbound := openAIBatchInboundEndpoint
+	upstream := job.Endpoint
+	return &UsageLog{
+		RequestID:        openAIBatchUsageRequestPrefix + job.BatchID + ":" + charge.Usage.Model,
+		InboundEndpoint:  &inbound,
+		UpstreamEndpoint: &upstream,
+		InputTokens:      charge.Usage.Tokens.InputTokens,
+		OutputTokens:     charge.Usage.Tokens.OutputTokens,
+		CacheReadTokens:  charge.Usage.Tokens.CacheReadTokens,
+	}
+}
+
+// scanOpenAIBatchOutputUsage 流式解析输出文件 JSONL，逐条返回 status_code 为 200 的请求 usage。
+// Chat Completions / Embeddings 使用 prompt_tokens，Responses 使用 input_tokens；
+// 缓存命中部分从输入中扣除后按 cache read 计价。
+func scanOpenAIBatchOutputUsage(r io.Reader) ([]messageBatchModelUsage, error) {
+	dec := json.NewDecoder(r)
+	out := make([]messageBatchModelUsage, 0, 64)
+	for {
+		var line openAIBatchOutputLine
+		if err := dec.Decode(&line); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("decode output: %w", err)
+		}
+		if line.Response == nil || line.Response.StatusCode != 200 {
+			continue
+		}
+		body := line.Response.Body
+		model := strings.TrimSpace(body.Model)
+		if model == "" {
+			return nil, fmt.Errorf("output %q has no model", line.CustomID)
+		}
+		input := body.Usage.PromptTokens
+		if input == 0 {
+			input = body.Usage.InputTokens
+		}
+		output := body.Usage.CompletionTokens
+		if output == 0 {
+			output = body.Usage.OutputTokens
+		}
+		cached := 0
+		if d := body.Usage.PromptTokensDetails; d != nil {
+			cached = d.CachedTokens
+		}
+		if d := body.Usage.InputTokensDetails; d != nil && cached == 0 {
+			cached = d.CachedTokens
+		}
+		if cached > input {
+			cached = input
+		}
+		usage := messageBatchModelUsage{Model: model, Requests: 1}
+		usage.Tokens.InputTokens = input - cached
+		usage.Tokens.OutputTokens = output
+		usage.Tokens.CacheReadTokens = cached
+		out = append(out, usage)
+	}
+	return out, nil
+}
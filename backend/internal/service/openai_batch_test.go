@@ -0,0 +1,598 @@
+//go:build unit
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+const openAIBatchTestInput = `{"custom_id":"a","method":"POST","url":"/v1/chat/completions","body":{"model":"gpt-4o-mini","max_tokens":100,"messages":[{"role":"user","content":"hi"}]}}
+{"custom_id":"b","method":"POST","url":"/v1/chat/completions","body":{"model":"gpt-4o-mini","messages":[{"role":"user","content":"hello"}]}}
+`
+
+func TestOpenAIBatchService_UploadValidatesAndRewritesModels(t *testing.T) {
+	var uploaded []byte
+	upstream := &messageBatchUpstreamStub{handle: func(req *http.Request, body []byte) *http.Response {
+		require.Equal(t, http.MethodPost, req.Method)
+		require.Equal(t, "/v1/files", req.URL.Path)
+		require.Equal(t, "Bearer sk-upstream", req.Header.Get("Authorization"))
+		uploaded = readOpenAIBatchTestMultipartFile(t, req.Header.Get("Content-Type"), body)
+		return messageBatchJSONResponse(http.StatusOK, `{"id":"file-up-1","object":"file","bytes":321,"filename":"input.jsonl","purpose":"batch"}`)
+	}}
+	svc, repo, _ := newTestOpenAIBatchService(upstream, testOpenAIBatchAccount())
+
+	file, err := svc.UploadFile(context.Background(), testOpenAIBatchOwner(), newOpenAIBatchTestUpload(t, "batch", openAIBatchTestInput))
+	require.NoError(t, err)
+	require.Equal(t, "file-up-1", file.FileID)
+	require.Equal(t, "/v1/chat/completions", file.Endpoint)
+	require.Equal(t, 2, file.RequestCount)
+	require.Equal(t, int64(11), file.AccountID)
+	require.Equal(t, "file-up-1", gjson.GetBytes(OpenAIBatchFileToPublic(file), "id").String())
+
+	lines := strings.Split(strings.TrimSpace(string(uploaded)), "\n")
+	require.Len(t, lines, 2)
+	require.Equal(t, "gpt-4o-mini-2024-07-18", gjson.Get(lines[0], "body.model").String())
+	require.Equal(t, "b", gjson.Get(lines[1], "custom_id").String())
+
+	require.Len(t, file.Estimate, 1)
+	estimate := file.Estimate[0]
+	require.Equal(t, "gpt-4o-mini-2024-07-18", estimate.Model)
+	require.Equal(t, 2, estimate.Requests)
+	// 第一条声明 max_tokens=100，第二条使用默认输出上限 1000。
+	require.Equal(t, 1100, estimate.OutputTokens)
+	require.InDelta(t, float64(estimate.InputTokens)*1e-6+1100*5e-6, estimate.StandardCost, 1e-12)
+	require.Contains(t, repo.files, "file-up-1")
+}
+
+func TestOpenAIBatchService_UploadRejectsInvalidInput(t *testing.T) {
+	cases := map[string]struct {
+		purpose string
+		input   string
+		status  int
+	}{
+		"duplicate custom_id": {purpose: "batch", input: strings.Replace(openAIBatchTestInput, `"custom_id":"b"`, `"custom_id":"a"`, 1), status: http.StatusBadRequest},
+		"mixed urls":          {purpose: "batch", input: strings.Replace(openAIBatchTestInput, `"url":"/v1/chat/completions","body":{"model":"gpt-4o-mini","messages"`, `"url":"/v1/embeddings","body":{"model":"gpt-4o-mini","messages"`, 1), status: http.StatusBadRequest},
+		"stream":              {purpose: "batch", input: strings.Replace(openAIBatchTestInput, `"max_tokens":100`, `"max_tokens":100,"stream":true`, 1), status: http.StatusBadRequest},
+		"unsupported model":   {purpose: "batch", input: strings.Replace(openAIBatchTestInput, `"gpt-4o-mini","max_tokens"`, `"o3-pro","max_tokens"`, 1), status: http.StatusBadRequest},
+		"wrong purpose":       {purpose: "fine-tune", input: openAIBatchTestInput, status: http.StatusBadRequest},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var deleted []string
+			upstream := &messageBatchUpstreamStub{handle: func(req *http.Request, _ []byte) *http.Response {
+				if req.Method == http.MethodDelete {
+					deleted = append(deleted, req.URL.Path)
+					return messageBatchJSONResponse(http.StatusOK, `{"id":"file-up-1","deleted":true}`)
+				}
+				return messageBatchJSONResponse(http.StatusOK, `{"id":"file-up-1","object":"file","bytes":1,"purpose":"batch"}`)
+			}}
+			svc, repo, _ := newTestOpenAIBatchService(upstream, testOpenAIBatchAccount())
+			_, err := svc.UploadFile(context.Background(), testOpenAIBatchOwner(), newOpenAIBatchTestUpload(t, tc.purpose, tc.input))
+			require.Error(t, err)
+			require.Equal(t, tc.status, infraerrors.Code(err), "got %v", err)
+			require.Empty(t, repo.files)
+			require.Equal(t, []string{"/v1/files/file-up-1"}, deleted)
+		})
+	}
+
+	svc, _, _ := newTestOpenAIBatchService(&messageBatchUpstreamStub{handle: func(*http.Request, []byte) *http.Response {
+		return messageBatchJSONResponse(http.StatusOK, `{"id":"file-up-1","object":"file","bytes":1,"purpose":"batch"}`)
+	}}, testOpenAIBatchAccount())
+	owner := testOpenAIBatchOwner()
+	owner.APIKey = &APIKey{ID: owner.APIKeyID, ModelDenylist: []string{"gpt-4o-mini"}}
+	_, err := svc.UploadFile(context.Background(), owner, newOpenAIBatchTestUpload(t, "batch", openAIBatchTestInput))
+	require.Equal(t, http.StatusForbidden, infraerrors.Code(err))
+}
+
+func TestOpenAIBatchService_UploadAuditsEveryLine(t *testing.T) {
+	svc, repo, _ := newTestOpenAIBatchService(&messageBatchUpstreamStub{handle: func(req *http.Request, _ []byte) *http.Response {
+		if req.Method == http.MethodDelete {
+			return messageBatchJSONResponse(http.StatusOK, `{"id":"file-up-1","deleted":true}`)
+		}
+		return messageBatchJSONResponse(http.StatusOK, `{"id":"file-up-1","object":"file","bytes":1,"purpose":"batch"}`)
+	}}, testOpenAIBatchAccount())
+	owner := testOpenAIBatchOwner()
+	var audited []string
+	owner.AuditLine = func(endpoint, model string, body []byte) error {
+		require.Equal(t, "/v1/chat/completions", endpoint)
+		require.Equal(t, "gpt-4o-mini", model)
+		audited = append(audited, string(body))
+		if strings.Contains(string(body), "hello") {
+			return infraerrors.New(http.StatusForbidden, "BLOCKED", "blocked")
+		}
+		return nil
+	}
+
+	_, err := svc.UploadFile(context.Background(), owner, newOpenAIBatchTestUpload(t, "batch", openAIBatchTestInput))
+	require.Equal(t, http.StatusForbidden, infraerrors.Code(err))
+	require.Len(t, audited, 2)
+	require.Equal(t, "hi", gjson.Get(audited[0], "messages.0.content").String())
+	require.False(t, gjson.Get(audited[1], "custom_id").Exists(), "only the request body is audited")
+	require.Empty(t, repo.files)
+}
+
+func TestOpenAIBatchService_CreateHoldsAndSubmitsOnFileAccount(t *testing.T) {
+	upstream := &messageBatchUpstreamStub{handle: func(req *http.Request, body []byte) *http.Response {
+		require.Equal(t, "/v1/batches", req.URL.Path)
+		require.Equal(t, "file-up-1", gjson.GetBytes(body, "input_file_id").String())
+		return messageBatchJSONResponse(http.StatusOK, `{"id":"batch_up_1","object":"batch","status":"validating","input_file_id":"file-up-1","request_counts":{"total":2}}`)
+	}}
+	svc, repo, billing := newTestOpenAIBatchService(upstream, testOpenAIBatchAccount())
+	repo.files["file-up-1"] = testOpenAIBatchInputFile()
+	body := []byte(`{"input_file_id":"file-up-1","endpoint":"/v1/chat/completions","completion_window":"24h"}`)
+
+	job, err := svc.CreateBatch(context.Background(), testOpenAIBatchOwner(), body, "idem-1")
+	require.NoError(t, err)
+	require.Equal(t, OpenAIBatchStatusInProgress, job.Status)
+	require.Equal(t, "batch_up_1", *job.UpstreamBatchID)
+	require.Equal(t, int64(11), job.AccountID)
+	require.InDelta(t, 0.01, job.HoldAmount, 1e-12)
+	require.InDelta(t, 0.005, job.EstimatedCost, 1e-12)
+
+	public := OpenAIBatchJobToPublic(job)
+	require.Equal(t, job.BatchID, gjson.GetBytes(public, "id").String())
+	require.Equal(t, "validating", gjson.GetBytes(public, "status").String())
+
+	require.Len(t, billing.reserves, 1)
+	require.Equal(t, OpenAIBatchHoldRequestID(job.BatchID), billing.reserves[0].RequestID)
+
+	again, err := svc.CreateBatch(context.Background(), testOpenAIBatchOwner(), body, "idem-1")
+	require.NoError(t, err)
+	require.Equal(t, job.BatchID, again.BatchID)
+	require.Len(t, billing.reserves, 1)
+
+	_, err = svc.CreateBatch(context.Background(), testOpenAIBatchOwner(), []byte(`{"input_file_id":"file-up-1","endpoint":"/v1/embeddings","completion_window":"24h"}`), "")
+	require.True(t, errors.Is(err, ErrOpenAIBatchEndpointMismatch))
+
+	other := testOpenAIBatchOwner()
+	other.APIKeyID = 999
+	_, err = svc.CreateBatch(context.Background(), other, body, "")
+	require.True(t, errors.Is(err, ErrOpenAIBatchFileNotFound))
+}
+
+func TestOpenAIBatchService_RunOnceSettlesCompletedBatchFromOutputFile(t *testing.T) {
+	output := strings.Join([]string{
+		`{"id":"r1","custom_id":"a","response":{"status_code":200,"body":{"model":"gpt-4o-mini-2024-07-18","usage":{"prompt_tokens":1000,"completion_tokens":200,"prompt_tokens_details":{"cached_tokens":100}}}},"error":null}`,
+		`{"id":"r2","custom_id":"b","response":{"status_code":400,"body":{"error":{"message":"bad"}}},"error":null}`,
+		`{"id":"r3","custom_id":"c","response":{"status_code":200,"body":{"model":"gpt-4o-mini-2024-07-18","usage":{"input_tokens":500,"output_tokens":100}}},"error":null}`,
+	}, "\n") + "\n"
+	var paths []string
+	upstream := &messageBatchUpstreamStub{handle: func(req *http.Request, _ []byte) *http.Response {
+		paths = append(paths, req.URL.Path)
+		if strings.HasSuffix(req.URL.Path, "/content") {
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(output))}
+		}
+		return messageBatchJSONResponse(http.StatusOK, `{"id":"batch_up_1","object":"batch","status":"completed","output_file_id":"file-out-1","error_file_id":"file-err-1","request_counts":{"total":3,"completed":2,"failed":1}}`)
+	}}
+	svc, repo, billing := newTestOpenAIBatchService(upstream, testOpenAIBatchAccount())
+	usageLogs := &openAIRecordUsageLogRepoStub{inserted: true}
+	svc.UsageLogRepo = usageLogs
+	job := testInProgressOpenAIBatchJob(svc.nowTime())
+	repo.jobs[job.BatchID] = job
+
+	require.Equal(t, 1, svc.RunOnce(context.Background()))
+	require.Equal(t, []string{"/v1/batches/batch_up_1", "/v1/files/file-out-1/content"}, paths)
+
+	settled := repo.jobs[job.BatchID]
+	require.Equal(t, OpenAIBatchStatusCompleted, settled.Status)
+	require.Equal(t, "completed", settled.UpstreamStatus)
+	require.Equal(t, int64(1400), settled.InputTokens)
+	require.Equal(t, int64(300), settled.OutputTokens)
+	require.Equal(t, int64(100), settled.CacheReadTokens)
+	// 标准价 (1400*1 + 300*5 + 100*0.1)/1e6 = 0.00291，批量折扣 0.5。
+	require.InDelta(t, 0.001455, *settled.ActualCost, 1e-12)
+
+	require.Len(t, billing.captures, 1)
+	require.Equal(t, OpenAIBatchCaptureRequestID(job.BatchID), billing.captures[0].RequestID)
+	require.InDelta(t, 0.001455, billing.captures[0].ActualAmount, 1e-12)
+	require.Equal(t, 1, usageLogs.calls)
+	require.Equal(t, "openai_batch_usage:"+job.BatchID+":gpt-4o-mini-2024-07-18", usageLogs.lastLog.RequestID)
+	require.Equal(t, "/v1/chat/completions", *usageLogs.lastLog.UpstreamEndpoint)
+
+	// 输出与错误文件登记到批次所有者名下。
+	require.Equal(t, OpenAIFilePurposeBatchOutput, repo.files["file-out-1"].Purpose)
+	require.Equal(t, int64(70), repo.files["file-err-1"].APIKeyID)
+	require.Equal(t, 0, svc.RunOnce(context.Background()))
+}
+
+func TestOpenAIBatchService_SettleWithoutOutputReleasesHold(t *testing.T) {
+	svc, repo, billing := newTestOpenAIBatchService(&messageBatchUpstreamStub{}, testOpenAIBatchAccount())
+	job := testInProgressOpenAIBatchJob(svc.nowTime())
+	job.Status = OpenAIBatchStatusSettling
+	repo.jobs[job.BatchID] = job
+
+	require.NoError(t, svc.Settle(context.Background(), job))
+	require.Equal(t, OpenAIBatchStatusCompleted, repo.jobs[job.BatchID].Status)
+	require.Len(t, billing.captures, 1)
+	require.Zero(t, billing.captures[0].ActualAmount)
+}
+
+func TestOpenAIBatchService_HidesOtherKeysAndUnsubmittedBatches(t *testing.T) {
+	svc, repo, _ := newTestOpenAIBatchService(&messageBatchUpstreamStub{}, testOpenAIBatchAccount())
+	job := testInProgressOpenAIBatchJob(svc.nowTime())
+	job.Status = OpenAIBatchStatusCompleted
+	repo.jobs[job.BatchID] = job
+
+	other := testOpenAIBatchOwner()
+	other.APIKeyID = 999
+	_, err := svc.GetBatch(context.Background(), other, job.BatchID)
+	require.True(t, errors.Is(err, ErrOpenAIBatchNotFound))
+
+	_, err = svc.CancelBatch(context.Background(), testOpenAIBatchOwner(), job.BatchID)
+	require.True(t, errors.Is(err, ErrOpenAIBatchInvalidTransition))
+
+	job.UpstreamBatchID = nil
+	_, err = svc.GetBatch(context.Background(), testOpenAIBatchOwner(), job.BatchID)
+	require.True(t, errors.Is(err, ErrOpenAIBatchNotFound))
+}
+
+func newTestOpenAIBatchService(upstream *messageBatchUpstreamStub, accounts ...*Account) (*OpenAIBatchService, *fakeOpenAIBatchRepo, *fakeBatchImageBillingRepo) {
+	cfg := &config.Config{}
+	cfg.OpenAIBatch = config.OpenAIBatchConfig{
+		Enabled:                true,
+		DiscountMultiplier:     0.5,
+		HoldMultiplier:         1.0,
+		MaxRequestsPerFile:     100,
+		MaxFileBytes:           1 << 20,
+		DefaultMaxOutputTokens: 1000,
+		PollIntervalSeconds:    60,
+		PollBatchSize:          10,
+		UpstreamTimeoutSeconds: 30,
+	}
+	cfg.Security.URLAllowlist.Enabled = false
+	cfg.Security.URLAllowlist.AllowInsecureHTTP = true
+	accountRepo := &publicBatchImageAccountRepo{}
+	for _, account := range accounts {
+		accountRepo.accounts = append(accountRepo.accounts, *account)
+	}
+	repo := &fakeOpenAIBatchRepo{files: make(map[string]*OpenAIBatchFile), jobs: make(map[string]*OpenAIBatchJob)}
+	billing := &fakeBatchImageBillingRepo{}
+	now := time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)
+	svc := &OpenAIBatchService{
+		Repo:         repo,
+		AccountRepo:  accountRepo,
+		BillingRepo:  billing,
+		Pricing:      messageBatchTestPricing{},
+		HTTPUpstream: upstream,
+		Config:       cfg,
+		now:          func() time.Time { return now },
+	}
+	return svc, repo, billing
+}
+
+func testOpenAIBatchAccount() *Account {
+	return &Account{
+		ID:          11,
+		Platform:    PlatformOpenAI,
+		Type:        AccountTypeAPIKey,
+		Status:      StatusActive,
+		Schedulable: true,
+		Concurrency: 1,
+		Credentials: map[string]any{
+			"api_key":       "sk-upstream",
+			"base_url":      "http://openai.test",
+			"model_mapping": map[string]any{"gpt-4o-mini": "gpt-4o-mini-2024-07-18"},
+		},
+	}
+}
+
+func testOpenAIBatchOwner() OpenAIBatchOwner {
+	return OpenAIBatchOwner{UserID: 7, APIKeyID: 70}
+}
+
+func testOpenAIBatchInputFile() *OpenAIBatchFile {
+	return &OpenAIBatchFile{
+		ID:           1,
+		FileID:       "file-up-1",
+		UserID:       7,
+		APIKeyID:     70,
+		AccountID:    11,
+		Purpose:      OpenAIFilePurposeBatch,
+		Endpoint:     "/v1/chat/completions",
+		RequestCount: 2,
+		Estimate:     []OpenAIBatchModelEstimate{{Model: "gpt-4o-mini-2024-07-18", Requests: 2, InputTokens: 1000, OutputTokens: 1100, StandardCost: 0.01}},
+	}
+}
+
+func testInProgressOpenAIBatchJob(now time.Time) *OpenAIBatchJob {
+	upstreamID := "batch_up_1"
+	due := now.Add(-time.Second)
+	return &OpenAIBatchJob{
+		ID:                      1,
+		BatchID:                 "batch_test",
+		UserID:                  7,
+		APIKeyID:                70,
+		AccountID:               11,
+		InputFileID:             "file-up-1",
+		Endpoint:                "/v1/chat/completions",
+		UpstreamBatchID:         &upstreamID,
+		Status:                  OpenAIBatchStatusInProgress,
+		RequestCount:            3,
+		EstimatedCost:           0.5,
+		HoldAmount:              1,
+		GroupRateMultiplier:     1,
+		AccountRateMultiplier:   1,
+		BatchDiscountMultiplier: 0.5,
+		NextPollAt:              &due,
+		CreatedAt:               now.Add(-time.Hour),
+	}
+}
+
+func newOpenAIBatchTestUpload(t *testing.T, purpose, content string) *multipart.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	require.NoError(t, mw.WriteField("purpose", purpose))
+	fw, err := mw.CreateFormFile("file", "input.jsonl")
+	require.NoError(t, err)
+	_, err = fw.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+	return multipart.NewReader(&buf, mw.Boundary())
+}
+
+func readOpenAIBatchTestMultipartFile(t *testing.T, contentType string, body []byte) []byte {
+	t.Helper()
+	_, params, err := mime.ParseMediaType(contentType)
+	require.NoError(t, err)
+	mr := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		require.NoError(t, err)
+		if part.FormName() == "file" {
+			raw, err := io.ReadAll(part)
+			require.NoError(t, err)
+			return raw
+		}
+	}
+}
+
+type fakeOpenAIBatchRepo struct {
+	files  map[string]*OpenAIBatchFile
+	jobs   map[string]*OpenAIBatchJob
+	nextID int64
+}
+
+func (r *fakeOpenAIBatchRepo) CreateOpenAIBatchFile(_ context.Context, file *OpenAIBatchFile) error {
+	if _, ok := r.files[file.FileID]; ok {
+		return ErrOpenAIBatchFileExists
+	}
+	r.nextID++
+	file.ID = r.nextID
+	clone := *file
+	r.files[file.FileID] = &clone
+	return nil
+}
+
+func (r *fakeOpenAIBatchRepo) GetOpenAIBatchFileForOwner(_ context.Context, apiKeyID int64, fileID string) (*OpenAIBatchFile, error) {
+	file, ok := r.files[fileID]
+	if !ok || file.APIKeyID != apiKeyID || file.DeletedAt != nil {
+		return nil, ErrOpenAIBatchFileNotFound
+	}
+	clone := *file
+	return &clone, nil
+}
+
+func (r *fakeOpenAIBatchRepo) ListOpenAIBatchFilesForOwner(_ context.Context, apiKeyID int64, purpose string, query OpenAIBatchListQuery) ([]*OpenAIBatchFile, error) {
+	var out []*OpenAIBatchFile
+	for _, file := range r.files {
+		if file.APIKeyID == apiKeyID && file.DeletedAt == nil && (purpose == "" || file.Purpose == purpose) {
+			clone := *file
+			out = append(out, &clone)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID > out[j].ID })
+	if query.Limit > 0 && len(out) > query.Limit {
+		out = out[:query.Limit]
+	}
+	return out, nil
+}
+
+func (r *fakeOpenAIBatchRepo) MarkOpenAIBatchFileDeleted(_ context.Context, fileID string, deletedAt time.Time) error {
+	if file, ok := r.files[fileID]; ok {
+		file.DeletedAt = &deletedAt
+	}
+	return nil
+}
+
+func (r *fakeOpenAIBatchRepo) CreateOpenAIBatchJob(_ context.Context, job *OpenAIBatchJob) error {
+	r.nextID++
+	job.ID = r.nextID
+	clone := *job
+	r.jobs[job.BatchID] = &clone
+	return nil
+}
+
+func (r *fakeOpenAIBatchRepo) get(batchID string) (*OpenAIBatchJob, error) {
+	job, ok := r.jobs[batchID]
+	if !ok {
+		return nil, ErrOpenAIBatchNotFound
+	}
+	return job, nil
+}
+
+func (r *fakeOpenAIBatchRepo) GetOpenAIBatchJob(_ context.Context, batchID string) (*OpenAIBatchJob, error) {
+	job, err := r.get(batchID)
+	if err != nil {
+		return nil, err
+	}
+	clone := *job
+	return &clone, nil
+}
+
+func (r *fakeOpenAIBatchRepo) GetOpenAIBatchJobForOwner(ctx context.Context, apiKeyID int64, batchID string) (*OpenAIBatchJob, error) {
+	job, err := r.GetOpenAIBatchJob(ctx, batchID)
+	if err != nil || job.APIKeyID != apiKeyID {
+		return nil, ErrOpenAIBatchNotFound
+	}
+	return job, nil
+}
+
+func (r *fakeOpenAIBatchRepo) GetOpenAIBatchJobByIdempotencyKey(_ context.Context, apiKeyID int64, key string) (*OpenAIBatchJob, error) {
+	for _, job := range r.jobs {
+		if job.APIKeyID == apiKeyID && job.IdempotencyKey != nil && *job.IdempotencyKey == key {
+			clone := *job
+			return &clone, nil
+		}
+	}
+	return nil, ErrOpenAIBatchNotFound
+}
+
+func (r *fakeOpenAIBatchRepo) ListOpenAIBatchJobsForOwner(_ context.Context, apiKeyID int64, query OpenAIBatchListQuery) ([]*OpenAIBatchJob, error) {
+	var out []*OpenAIBatchJob
+	for _, job := range r.jobs {
+		if job.APIKeyID == apiKeyID && job.UpstreamBatchID != nil {
+			clone := *job
+			out = append(out, &clone)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID > out[j].ID })
+	if query.Limit > 0 && len(out) > query.Limit {
+		out = out[:query.Limit]
+	}
+	return out, nil
+}
+
+func (r *fakeOpenAIBatchRepo) applyProgress(job *OpenAIBatchJob, progress OpenAIBatchProgress) {
+	job.Status = progress.Status
+	job.UpstreamStatus = progress.UpstreamStatus
+	if len(progress.UpstreamObject) > 0 {
+		job.UpstreamObject = progress.UpstreamObject
+	}
+	if progress.OutputFileID != nil {
+		job.OutputFileID = progress.OutputFileID
+	}
+	if progress.ErrorFileID != nil {
+		job.ErrorFileID = progress.ErrorFileID
+	}
+	if progress.RequestCount > 0 {
+		job.RequestCount = progress.RequestCount
+	}
+	job.NextPollAt = progress.NextPollAt
+}
+
+func (r *fakeOpenAIBatchRepo) MarkOpenAIBatchJobSubmitted(_ context.Context, batchID, upstreamBatchID string, progress OpenAIBatchProgress) error {
+	job, err := r.get(batchID)
+	if err != nil {
+		return err
+	}
+	if job.Status != OpenAIBatchStatusCreated {
+		return ErrOpenAIBatchInvalidTransition
+	}
+	job.UpstreamBatchID = &upstreamBatchID
+	r.applyProgress(job, progress)
+	return nil
+}
+
+func (r *fakeOpenAIBatchRepo) UpdateOpenAIBatchJobProgress(_ context.Context, batchID string, progress OpenAIBatchProgress) error {
+	job, err := r.get(batchID)
+	if err != nil {
+		return err
+	}
+	if job.Status != OpenAIBatchStatusInProgress {
+		return ErrOpenAIBatchInvalidTransition
+	}
+	r.applyProgress(job, progress)
+	return nil
+}
+
+func (r *fakeOpenAIBatchRepo) MarkOpenAIBatchJobSettled(_ context.Context, params MarkOpenAIBatchJobSettledParams) error {
+	job, err := r.get(params.BatchID)
+	if err != nil {
+		return err
+	}
+	if job.Status != OpenAIBatchStatusSettling {
+		return ErrOpenAIBatchInvalidTransition
+	}
+	job.Status = OpenAIBatchStatusCompleted
+	actual := params.ActualCost
+	job.ActualCost = &actual
+	job.InputTokens = params.InputTokens
+	job.OutputTokens = params.OutputTokens
+	job.CacheReadTokens = params.CacheReadTokens
+	settledAt := params.SettledAt
+	job.SettledAt = &settledAt
+	job.NextPollAt = nil
+	return nil
+}
+
+func (r *fakeOpenAIBatchRepo) MarkOpenAIBatchJobFailed(_ context.Context, batchID, code, message string) error {
+	job, err := r.get(batchID)
+	if err != nil {
+		return err
+	}
+	if job.Status != OpenAIBatchStatusCreated && job.Status != OpenAIBatchStatusSettling {
+		return ErrOpenAIBatchInvalidTransition
+	}
+	job.Status = OpenAIBatchStatusFailed
+	job.LastErrorCode = &code
+	job.LastErrorMessage = &message
+	job.NextPollAt = nil
+	return nil
+}
+
+func (r *fakeOpenAIBatchRepo) MarkOpenAIBatchJobSettlementStuck(_ context.Context, batchID, code, message string) error {
+	job, err := r.get(batchID)
+	if err != nil {
+		return err
+	}
+	if job.Status != OpenAIBatchStatusSettling {
+		return ErrOpenAIBatchInvalidTransition
+	}
+	job.Status = OpenAIBatchStatusSettlementStuck
+	job.LastErrorCode = &code
+	job.LastErrorMessage = &message
+	job.NextPollAt = nil
+	return nil
+}
+
+func (r *fakeOpenAIBatchRepo) RecordOpenAIBatchJobError(_ context.Context, batchID, code, message string, nextPollAt time.Time) (int, error) {
+	job, err := r.get(batchID)
+	if err != nil {
+		return 0, err
+	}
+	job.RetryCount++
+	job.LastErrorCode = &code
+	job.LastErrorMessage = &message
+	job.NextPollAt = &nextPollAt
+	return job.RetryCount, nil
+}
+
+func (r *fakeOpenAIBatchRepo) ClaimDueOpenAIBatchJobs(_ context.Context, now time.Time, lease time.Duration, limit int) ([]*OpenAIBatchJob, error) {
+	var due []*OpenAIBatchJob
+	for _, job := range r.jobs {
+		if IsPendingOpenAIBatchStatus(job.Status) && job.NextPollAt != nil && !job.NextPollAt.After(now) {
+			due = append(due, job)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].ID < due[j].ID })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	out := make([]*OpenAIBatchJob, 0, len(due))
+	for _, job := range due {
+		next := now.Add(lease)
+		job.NextPollAt = &next
+		clone := *job
+		out = append(out, &clone)
+	}
+	return out, nil
+}
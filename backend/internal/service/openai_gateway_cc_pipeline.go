@@ -88,6 +88,10 @@ func (s *OpenAIGatewayService) failoverOpenAIUpstreamHTTPError(
 	upstreamMsg string,
 	upstreamModel string,
 ) *UpstreamFailoverError {
+	if account.IsAzureOpenAI() && isAzureOpenAIContentFilterRejection(resp.StatusCode, respBody) {
+		// 内容筛选拦截与账号无关：跳过错误策略，交由调用方按端点格式回写 400
+		return nil
+	}
 	shouldFailover := s.shouldFailoverOpenAIUpstreamResponse(resp.StatusCode, upstreamMsg, respBody)
 	tempUnscheduled := false
 	if c != nil && account != nil && account.Platform != PlatformGrok && !shouldFailover && !IsResponseCommitted(c) && s.rateLimitService != nil {
@@ -148,10 +152,11 @@ func (s *OpenAIGatewayService) openAIChatCompletionsTargetURL(account *Account)
 }
 
 // resolveCCFallbackTarget 解析两条 CC 回退路径共用的账号凭证与上游端点
-// （回退路径仅面向 APIKey 账号，凭证恒为 openai api_key）。
+// （回退路径仅面向 APIKey 账号，凭证恒为 openai api_key；Azure Entra 账号的
+// 令牌在发送前由 prepareAzureOpenAIRequest 注入）。
 func (s *OpenAIGatewayService) resolveCCFallbackTarget(account *Account) (apiKey string, targetURL string, err error) {
 	apiKey = account.GetOpenAIApiKey()
-	if apiKey == "" {
+	if apiKey == "" && !account.IsAzureOpenAIEntra() {
 		return "", "", fmt.Errorf("account %d missing api_key", account.ID)
 	}
 	targetURL, err = s.openAIChatCompletionsTargetURL(account)
@@ -220,6 +225,9 @@ func (s *OpenAIGatewayService) sendCCUpstreamRequest(
 	if account.Proxy != nil {
 		proxyURL = account.Proxy.URL()
 	}
+	if err := s.prepareAzureOpenAIRequest(ctx, account, upstreamReq); err != nil {
+		return nil, err
+	}
 	resp, err := s.httpUpstream.Do(upstreamReq, proxyURL, account.ID, account.Concurrency)
 	if err != nil {
 		return nil, s.handleOpenAIUpstreamTransportError(ctx, c, account, err, false)
@@ -267,6 +267,10 @@ func (s *OpenAIGatewayService) ForwardAsChatCompletions(
 		upstreamReq.Header.Set("session_id", generateSessionUUID(isolateOpenAISessionID(apiKeyID, promptCacheKey)))
 	}
 
+	if err := s.prepareAzureOpenAIRequest(ctx, account, upstreamReq); err != nil {
+		return nil, fmt.Errorf("build upstream request: %w", err)
+	}
+
 	// 7. Send request
 	proxyURL := ""
 	if account.Proxy != nil {
@@ -92,6 +92,12 @@ func (s *OpenAIGatewayService) ForwardCountTokensAsAnthropic(
 		return err
 	}
 
+	if account.IsAzureOpenAI() {
+		// Azure OpenAI 没有 /responses/input_tokens，直接使用本地估算
+		writeOpenAIOAuthInputTokensFallback(c, account, prepared, 0)
+		return nil
+	}
+
 	upstreamBody, err := marshalOpenAIUpstreamJSON(prepared.Request)
 	if err != nil {
 		writeAnthropicCountTokensError(c, http.StatusInternalServerError, "api_error", "Failed to build request")
@@ -0,0 +1,503 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/apicompat"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/googleapi"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/util/responseheaders"
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"go.uber.org/zap"
+)
+
+// ForwardAsGemini accepts a Gemini generateContent request body, converts it to
+// OpenAI Responses API format, forwards to the OpenAI upstream, and converts the
+// response back to Gemini format (JSON for generateContent, alt=sse chunks for
+// streamGenerateContent).
+//
+// 只走 Responses 路径：调度阶段已按 OpenAIEndpointCapabilityResponses 过滤掉仅支持
+// /v1/chat/completions 的 APIKey 账号，这里不再做 CC 直转分流。
+func (s *OpenAIGatewayService) ForwardAsGemini(
+	ctx context.Context,
+	c *gin.Context,
+	account *Account,
+	body []byte,
+	model string,
+	stream bool,
+	promptCacheKey string,
+	defaultMappedModel string,
+) (*OpenAIForwardResult, error) {
+	beginUpstreamResponseModelObservation(c)
+	startTime := time.Now()
+
+	// 1. Parse Gemini request
+	var geminiReq apicompat.GeminiRequest
+	if err := json.Unmarshal(body, &geminiReq); err != nil {
+		writeGatewayGeminiError(c, http.StatusBadRequest, "Failed to parse request body")
+		return nil, fmt.Errorf("parse gemini request: %w", err)
+	}
+	originalModel := model
+
+	// 2. Model mapping
+	billingModel := resolveOpenAIForwardModel(account, originalModel, defaultMappedModel)
+	upstreamModel := normalizeOpenAIModelForUpstream(account, billingModel)
+
+	// 3. Convert Gemini → Responses (upstream always streams)
+	responsesReq, err := apicompat.GeminiToResponses(&geminiReq, upstreamModel)
+	if err != nil {
+		writeGatewayGeminiError(c, http.StatusBadRequest, err.Error())
+		return nil, fmt.Errorf("convert gemini to responses: %w", err)
+	}
+	normalizeResponsesRequestServiceTier(responsesReq)
+	responsesBody, err := json.Marshal(responsesReq)
+	if err != nil {
+		return nil, fmt.Errorf("marshal responses request: %w", err)
+	}
+
+	logger.L().Debug("openai gemini: model mapping applied",
+		zap.Int64("account_id", account.ID),
+		zap.String("original_model", originalModel),
+		zap.String("billing_model", billingModel),
+		zap.String("upstream_model", upstreamModel),
+		zap.Bool("stream", stream),
+	)
+
+	promptCacheKey = strings.TrimSpace(promptCacheKey)
+	if account.Type == AccountTypeOAuth {
+		var reqBody map[string]any
+		if err := json.Unmarshal(responsesBody, &reqBody); err != nil {
+			return nil, fmt.Errorf("unmarshal for codex transform: %w", err)
+		}
+		isJSONObjectFormat := strings.EqualFold(strings.TrimSpace(gjson.GetBytes(responsesBody, "text.format.type").String()), "json_object")
+		codexResult := applyCodexOAuthTransformWithOptions(reqBody, codexOAuthTransformOptions{
+			SkipDefaultInstructions:             true,
+			OmitPromotedSystemMessagesFromInput: !isJSONObjectFormat,
+		})
+		ensureCodexOAuthInstructionsField(reqBody)
+		if codexResult.NormalizedModel != "" {
+			upstreamModel = codexResult.NormalizedModel
+		}
+		if codexResult.PromptCacheKey != "" {
+			promptCacheKey = codexResult.PromptCacheKey
+		} else if promptCacheKey != "" {
+			reqBody["prompt_cache_key"] = promptCacheKey
+		}
+		responsesBody, err = json.Marshal(reqBody)
+		if err != nil {
+			return nil, fmt.Errorf("remarshal after codex transform: %w", err)
+		}
+	}
+
+	if account.Type == AccountTypeAPIKey && promptCacheKey != "" && !gjson.GetBytes(responsesBody, "prompt_cache_key").Exists() {
+		var reqBody map[string]any
+		if err := json.Unmarshal(responsesBody, &reqBody); err != nil {
+			return nil, fmt.Errorf("unmarshal for prompt cache key injection: %w", err)
+		}
+		reqBody["prompt_cache_key"] = promptCacheKey
+		responsesBody, err = json.Marshal(reqBody)
+		if err != nil {
+			return nil, fmt.Errorf("remarshal after prompt cache key injection: %w", err)
+		}
+	}
+
+	// 3b. Apply OpenAI fast policy (may filter service_tier or block the request).
+	updatedBody, policyErr := s.applyOpenAIFastPolicyToBody(ctx, account, upstreamModel, responsesBody)
+	if policyErr != nil {
+		var blocked *OpenAIFastBlockedError
+		if errors.As(policyErr, &blocked) {
+			MarkOpsClientBusinessLimited(c, OpsClientBusinessLimitedReasonLocalPolicyDenied)
+			writeGatewayGeminiError(c, http.StatusForbidden, blocked.Message)
+		}
+		return nil, policyErr
+	}
+	responsesBody = updatedBody
+
+	// 4. Get access token
+	token, _, err := s.GetAccessToken(ctx, account)
+	if err != nil {
+		return nil, fmt.Errorf("get access token: %w", err)
+	}
+
+	// 5. Build upstream request
+	upstreamCtx, releaseUpstreamCtx := detachUpstreamContext(ctx)
+	upstreamReq, err := s.buildUpstreamRequest(upstreamCtx, c, account, responsesBody, token, true, promptCacheKey, false)
+	releaseUpstreamCtx()
+	if err != nil {
+		return nil, fmt.Errorf("build upstream request: %w", err)
+	}
+	if promptCacheKey != "" {
+		apiKeyID := getAPIKeyIDFromContext(c)
+		upstreamReq.Header.Set("session_id", generateSessionUUID(isolateOpenAISessionID(apiKeyID, promptCacheKey)))
+	}
+	if err := s.prepareAzureOpenAIRequest(ctx, account, upstreamReq); err != nil {
+		return nil, fmt.Errorf("build upstream request: %w", err)
+	}
+
+	// 6. Send request
+	proxyURL := ""
+	if account.Proxy != nil {
+		proxyURL = account.Proxy.URL()
+	}
+	resp, err := s.httpUpstream.Do(upstreamReq, proxyURL, account.ID, account.Concurrency)
+	if err != nil {
+		return nil, s.handleOpenAIUpstreamTransportError(ctx, c, account, err, false)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	// 7. Handle error response with failover
+	if resp.StatusCode >= 400 {
+		respBody, upstreamMsg := s.readOpenAIUpstreamError(resp)
+		if foErr := s.failoverOpenAIUpstreamHTTPError(ctx, c, account, resp, respBody, upstreamMsg, upstreamModel); foErr != nil {
+			return nil, foErr
+		}
+		return s.handleCompatErrorResponse(resp, c, account, writeGeminiCompatError, billingModel)
+	}
+
+	// 8. Handle normal response
+	var result *OpenAIForwardResult
+	var handleErr error
+	if stream {
+		result, handleErr = s.handleGeminiStreamingResponse(resp, c, account, originalModel, billingModel, upstreamModel, startTime)
+	} else {
+		result, handleErr = s.handleGeminiBufferedStreamingResponse(resp, c, account, originalModel, billingModel, upstreamModel, startTime)
+	}
+
+	if GetOpsCyberPolicy(c) != nil {
+		if handleErr == nil {
+			handleErr = errOpenAICyberPolicyForwarded
+		}
+		return nil, handleErr
+	}
+
+	// Propagate ServiceTier and ReasoningEffort to result for billing
+	if handleErr == nil && result != nil {
+		if responsesReq.ServiceTier != "" {
+			st := responsesReq.ServiceTier
+			result.ServiceTier = &st
+		}
+		if responsesReq.Reasoning != nil && responsesReq.Reasoning.Effort != "" {
+			re := responsesReq.Reasoning.Effort
+			result.ReasoningEffort = &re
+		}
+	}
+
+	if handleErr == nil && account.Type == AccountTypeOAuth && !account.IsShadow() {
+		if snapshot := ParseCodexRateLimitHeaders(resp.Header); snapshot != nil {
+			s.updateCodexUsageSnapshot(ctx, account.ID, snapshot)
+		}
+	}
+
+	return result, handleErr
+}
+
+// handleGeminiBufferedStreamingResponse reads all Responses SSE events from the
+// upstream, finds the terminal event and writes a generateContent JSON reply.
+func (s *OpenAIGatewayService) handleGeminiBufferedStreamingResponse(
+	resp *http.Response,
+	c *gin.Context,
+	account *Account,
+	originalModel string,
+	billingModel string,
+	upstreamModel string,
+	startTime time.Time,
+) (*OpenAIForwardResult, error) {
+	requestID := resp.Header.Get("x-request-id")
+
+	finalResponse, usage, acc, err := s.readOpenAICompatBufferedTerminal(resp, "openai gemini buffered", requestID)
+	if err != nil {
+		return nil, err
+	}
+	if finalResponse == nil {
+		writeGatewayGeminiError(c, http.StatusBadGateway, "Upstream stream ended without a terminal response event")
+		return nil, fmt.Errorf("upstream stream ended without terminal event")
+	}
+	observer := upstreamResponseModelObserverFromContext(c)
+	if observer == nil {
+		observer = beginUpstreamResponseModelObservation(c)
+	}
+	observer.Observe(finalResponse.Model, true)
+
+	if strings.TrimSpace(finalResponse.Status) == "failed" {
+		payload, _ := json.Marshal(gin.H{"type": "response.failed", "response": finalResponse})
+		if hit, code, msg := detectOpenAICyberPolicy(payload); hit {
+			MarkOpsCyberPolicy(c, CyberPolicyMark{
+				Code:           code,
+				Message:        msg,
+				Body:           truncateString(string(payload), 4096),
+				UpstreamStatus: http.StatusOK,
+				UpstreamInTok:  usage.InputTokens,
+				UpstreamOutTok: usage.OutputTokens,
+			})
+			clientMsg := msg
+			if clientMsg == "" {
+				clientMsg = "Request blocked by upstream cyber-security policy"
+			}
+			writeGatewayGeminiError(c, http.StatusBadRequest, clientMsg)
+			return nil, fmt.Errorf("openai cyber_policy: %s", msg)
+		}
+		message := openAICompatFailedResponseMessage(finalResponse)
+		if openAIStreamFailedEventShouldFailover(payload, message) {
+			return nil, s.newOpenAIStreamFailoverError(c, account, false, requestID, payload, message, resp.Header)
+		}
+		message = s.recordOpenAIStreamUpstreamError(c, account, false, requestID, "http_error", payload, message)
+		if status, _, errMsg, matched := applyOpenAIStreamFailedErrorPassthroughRule(
+			c, account.Platform, payload, message,
+		); matched {
+			if errMsg == "" {
+				errMsg = message
+			}
+			writeGatewayGeminiError(c, status, errMsg)
+			return nil, fmt.Errorf("upstream response failed (passthrough): %s", errMsg)
+		}
+		writeGatewayGeminiError(c, http.StatusBadGateway, message)
+		return nil, fmt.Errorf("upstream response failed: %s", message)
+	}
+
+	acc.SupplementResponseOutput(finalResponse)
+	geminiResp := apicompat.ResponsesToGemini(finalResponse, originalModel)
+
+	if s.responseHeaderFilter != nil {
+		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
+	}
+	c.Writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+	c.JSON(http.StatusOK, geminiResp)
+
+	return &OpenAIForwardResult{
+		RequestID:                     requestID,
+		ResponseID:                    finalResponse.ID,
+		Usage:                         usage,
+		Model:                         originalModel,
+		BillingModel:                  billingModel,
+		UpstreamModel:                 upstreamModel,
+		UpstreamResponseModel:         observedUpstreamResponseModel(c),
+		UpstreamResponseModelConflict: observedUpstreamResponseModelConflict(c),
+		Stream:                        false,
+		Duration:                      time.Since(startTime),
+	}, nil
+}
+
+// handleGeminiStreamingResponse reads Responses SSE events from upstream,
+// converts each to streamGenerateContent chunks, and writes them as alt=sse.
+// Before any chunk reaches the client a failed terminal event may still fail
+// over to another account; afterwards errors are surfaced as a Google error
+// object on the stream.
+func (s *OpenAIGatewayService) handleGeminiStreamingResponse(
+	resp *http.Response,
+	c *gin.Context,
+	account *Account,
+	originalModel string,
+	billingModel string,
+	upstreamModel string,
+	startTime time.Time,
+) (*OpenAIForwardResult, error) {
+	requestID := resp.Header.Get("x-request-id")
+	writeStreamHeaders := s.newStreamHeaderWriter(c, resp.Header)
+
+	state := apicompat.NewResponsesEventToGeminiState(originalModel)
+	var usage OpenAIUsage
+	responseID := ""
+	var firstTokenMs *int
+	firstChunk := true
+	clientDisconnected := false
+	clientOutputStarted := false
+	observer := upstreamResponseModelObserverFromContext(c)
+	if observer == nil {
+		observer = beginUpstreamResponseModelObservation(c)
+	}
+
+	resultWithUsage := func() *OpenAIForwardResult {
+		return &OpenAIForwardResult{
+			RequestID:                     requestID,
+			ResponseID:                    responseID,
+			Usage:                         usage,
+			Model:                         originalModel,
+			BillingModel:                  billingModel,
+			UpstreamModel:                 upstreamModel,
+			UpstreamResponseModel:         observedUpstreamResponseModel(c),
+			UpstreamResponseModelConflict: observedUpstreamResponseModelConflict(c),
+			Stream:                        true,
+			Duration:                      time.Since(startTime),
+			FirstTokenMs:                  firstTokenMs,
+			ClientDisconnect:              clientDisconnected,
+		}
+	}
+
+	writeChunks := func(chunks []apicompat.GeminiResponse) {
+		if clientDisconnected || len(chunks) == 0 {
+			return
+		}
+		for _, chunk := range chunks {
+			sse, err := apicompat.GeminiChunkToSSE(chunk)
+			if err != nil {
+				continue
+			}
+			writeStreamHeaders()
+			if _, err := fmt.Fprint(c.Writer, sse); err != nil {
+				clientDisconnected = true
+				logger.L().Info("openai gemini stream: client disconnected, continuing to drain upstream for billing",
+					zap.String("request_id", requestID),
+				)
+				return
+			}
+			clientOutputStarted = true
+		}
+		c.Writer.Flush()
+	}
+
+	// handleFailed returns a failover error (nil if the client already received
+	// output) after recording/writing the upstream failure.
+	handleFailed := func(payload []byte) (*UpstreamFailoverError, error) {
+		if hit, code, msg := detectOpenAICyberPolicy(payload); hit {
+			MarkOpsCyberPolicy(c, CyberPolicyMark{
+				Code:           code,
+				Message:        msg,
+				Body:           truncateString(string(payload), 4096),
+				UpstreamStatus: http.StatusOK,
+				UpstreamInTok:  usage.InputTokens,
+				UpstreamOutTok: usage.OutputTokens,
+			})
+			if msg == "" {
+				msg = "Request blocked by upstream cyber-security policy"
+			}
+			s.writeGeminiStreamError(c, writeStreamHeaders, clientOutputStarted, http.StatusBadRequest, msg)
+			clientDisconnected = true
+			return nil, fmt.Errorf("openai cyber_policy: %s", msg)
+		}
+		message := extractOpenAISSEErrorMessage(payload)
+		if !clientOutputStarted && openAIStreamFailedEventShouldFailover(payload, message) {
+			return s.newOpenAIStreamFailoverError(c, account, false, requestID, payload, message, resp.Header), nil
+		}
+		message = s.recordOpenAIStreamUpstreamError(c, account, false, requestID, "http_error", payload, message)
+		status := http.StatusBadGateway
+		if st, _, errMsg, matched := applyOpenAIStreamFailedErrorPassthroughRule(c, account.Platform, payload, message); matched {
+			status = st
+			if errMsg != "" {
+				message = errMsg
+			}
+		}
+		if !clientDisconnected {
+			s.writeGeminiStreamError(c, writeStreamHeaders, clientOutputStarted, status, message)
+		}
+		return nil, fmt.Errorf("upstream response failed: %s", message)
+	}
+
+	scanner := s.newUpstreamSSEScanner(resp.Body)
+	var parser openAICompatSSEFrameParser
+	processFrame := func(frame openAICompatSSEFrame) (done bool, result *OpenAIForwardResult, err error) {
+		payload := openAICompatPayloadWithEventType(frame.Data, frame.EventType)
+		if firstChunk {
+			firstChunk = false
+			ms := int(time.Since(startTime).Milliseconds())
+			firstTokenMs = &ms
+		}
+		var event apicompat.ResponsesStreamEvent
+		if err := json.Unmarshal([]byte(payload), &event); err != nil {
+			logger.L().Warn("openai gemini stream: failed to parse event",
+				zap.Error(err),
+				zap.String("request_id", requestID),
+			)
+			return false, nil, nil
+		}
+		observer.ObserveOpenAI([]byte(payload), event.Type)
+
+		eventType := strings.TrimSpace(event.Type)
+		if isOpenAICompatResponsesTerminalEvent(eventType) || eventType == "error" {
+			if event.Response != nil {
+				if id := strings.TrimSpace(event.Response.ID); id != "" {
+					responseID = id
+				}
+				if event.Response.Usage != nil {
+					usage = copyOpenAIUsageFromResponsesUsage(event.Response.Usage)
+				}
+			}
+			if event.Usage != nil {
+				usage = copyOpenAIUsageFromResponsesUsage(event.Usage)
+			}
+			if eventType == "response.failed" || eventType == "error" {
+				foErr, failErr := handleFailed([]byte(payload))
+				if foErr != nil {
+					return true, resultWithUsage(), foErr
+				}
+				return true, resultWithUsage(), failErr
+			}
+			writeChunks(apicompat.ResponsesEventToGeminiChunks(&event, state))
+			return true, resultWithUsage(), nil
+		}
+		writeChunks(apicompat.ResponsesEventToGeminiChunks(&event, state))
+		return false, nil, nil
+	}
+	missingTerminal := func() (*OpenAIForwardResult, error) {
+		message := "OpenAI gemini stream ended before a terminal event"
+		if !clientOutputStarted && !clientDisconnected {
+			return resultWithUsage(), s.newOpenAIStreamFailoverError(c, account, false, requestID, nil, message)
+		}
+		writeChunks(apicompat.FinalizeResponsesGeminiStream(state))
+		s.recordOpenAIMessagesStreamUpstreamError(c, account, requestID, "stream_missing_terminal", message)
+		return resultWithUsage(), fmt.Errorf("stream usage incomplete: missing terminal event")
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if isOpenAICompatDoneSentinelLine(line) {
+			return missingTerminal()
+		}
+		frame, ok := parser.AddLine(line)
+		if !ok {
+			continue
+		}
+		if done, result, err := processFrame(frame); done {
+			return result, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+			logger.L().Warn("openai gemini stream: read error",
+				zap.Error(err),
+				zap.String("request_id", requestID),
+			)
+		}
+		return resultWithUsage(), fmt.Errorf("stream usage incomplete: %w", err)
+	}
+	if frame, ok := parser.Finish(); ok && strings.TrimSpace(frame.Data) != "[DONE]" {
+		if done, result, err := processFrame(frame); done {
+			return result, err
+		}
+	}
+	return missingTerminal()
+}
+
+// writeGeminiStreamError writes a Google error object: as a plain JSON error
+// response when nothing has been streamed yet, otherwise as a final SSE frame.
+func (s *OpenAIGatewayService) writeGeminiStreamError(c *gin.Context, writeStreamHeaders func(), outputStarted bool, status int, message string) {
+	if !outputStarted {
+		writeGatewayGeminiError(c, status, message)
+		return
+	}
+	payload, err := json.Marshal(gin.H{
+		"error": gin.H{
+			"code":    status,
+			"message": message,
+			"status":  googleapi.HTTPStatusToGoogleStatus(status),
+		},
+	})
+	if err != nil {
+		return
+	}
+	writeStreamHeaders()
+	if _, err := fmt.Fprintf(c.Writer, "data: %s\n\n", payload); err == nil {
+		c.Writer.Flush()
+	}
+}
+
+// writeGeminiCompatError adapts writeGatewayGeminiError to compatErrorWriter.
+func writeGeminiCompatError(c *gin.Context, statusCode int, _ string, message string) {
+	writeGatewayGeminiError(c, statusCode, message)
+}
@@ -363,6 +363,9 @@ func (s *OpenAIGatewayService) ForwardAsAnthropic(
 				return nil, fmt.Errorf("build grok retry request: %w", err)
 			}
 		}
+		if err = s.prepareAzureOpenAIRequest(ctx, account, upstreamReq); err != nil {
+			return nil, fmt.Errorf("build upstream request: %w", err)
+		}
 		resp, err = s.httpUpstream.Do(upstreamReq, proxyURL, account.ID, account.Concurrency)
 		if err != nil {
 			return nil, s.handleOpenAIUpstreamTransportError(ctx, c, account, err, false)
@@ -201,6 +201,10 @@ func (s *OpenAIGatewayService) forwardOpenAIPassthrough(
 			return nil, buildErr
 		}
 
+		if buildErr := s.prepareAzureOpenAIRequest(ctx, account, upstreamReq); buildErr != nil {
+			return nil, buildErr
+		}
+
 		upstreamStart := time.Now()
 		resp, err = s.httpUpstream.Do(upstreamReq, proxyURL, account.ID, account.Concurrency)
 		SetOpsLatencyMs(c, OpsUpstreamLatencyMsKey, time.Since(upstreamStart).Milliseconds())
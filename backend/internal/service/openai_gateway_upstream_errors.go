@@ -358,6 +358,21 @@ func (s *OpenAIGatewayService) handleErrorResponse(
 		})
 		return nil, fmt.Errorf("grok content policy rejection: %s", clientMsg)
 	}
+	if isOpenAIContentPolicyRejection(account, resp.StatusCode, body) {
+		// Azure 内容筛选：提示词触发，原样以 400 返回调用方，不冷却账号、不 failover。
+		clientMsg := azureOpenAIContentFilterClientMessage(body)
+		setOpsUpstreamError(c, resp.StatusCode, clientMsg, truncateString(string(body), 2048))
+		writeOpenAIPassthroughResponseHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
+		MarkResponseCommitted(c)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"type":    "invalid_request_error",
+				"code":    "content_filter",
+				"message": clientMsg,
+			},
+		})
+		return nil, fmt.Errorf("azure content filter rejection: %s", clientMsg)
+	}
 
 	upstreamMsg := strings.TrimSpace(extractUpstreamErrorMessage(body))
 	upstreamMsg = sanitizeUpstreamErrorMessage(upstreamMsg)
@@ -579,6 +594,13 @@ func (s *OpenAIGatewayService) handleCompatErrorResponse(
 		writeError(c, http.StatusForbidden, "invalid_request_error", clientMsg)
 		return nil, fmt.Errorf("grok content policy rejection: %s", clientMsg)
 	}
+	if isOpenAIContentPolicyRejection(account, resp.StatusCode, body) {
+		clientMsg := azureOpenAIContentFilterClientMessage(body)
+		setOpsUpstreamError(c, resp.StatusCode, clientMsg, truncateString(string(body), 2048))
+		MarkResponseCommitted(c)
+		writeError(c, http.StatusBadRequest, "invalid_request_error", clientMsg)
+		return nil, fmt.Errorf("azure content filter rejection: %s", clientMsg)
+	}
 
 	upstreamMsg := strings.TrimSpace(extractUpstreamErrorMessage(body))
 	if upstreamMsg == "" {
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 对冲请求（hedged request）：非流式 OpenAI 请求在首个账号超过分位延迟仍未响应时，
+// 向第二个账号并发同一请求，先成功者胜出，另一路被取消；只对胜出方向用户计费。
+const (
+	defaultHedgePercentile = 95
+	minHedgePercentile     = 50
+	maxHedgePercentile     = 99
+	defaultHedgeMaxDelayMs = 5000
+	maxHedgeDelayMs        = 120000
+
+	// 每账号保留最近 N 次非流式耗时作为分位样本；样本不足时退化为 MaxDelayMs。
+	openAIHedgeLatencyWindowSize = 64
+	openAIHedgeMinLatencySamples = 10
+)
+
+// NormalizeGroupHedgePolicy 校验并归一化对冲策略，CreateGroup 与 UpdateGroup 共用。
+// 关闭状态下同样保留并校验参数，便于再次开启。
+func NormalizeGroupHedgePolicy(policy GroupHedgePolicy) (GroupHedgePolicy, error) {
+	if policy.Percentile == 0 {
+		policy.Percentile = defaultHedgePercentile
+	}
+	if policy.Percentile < minHedgePercentile || policy.Percentile > maxHedgePercentile {
+		return GroupHedgePolicy{}, fmt.Errorf("hedge percentile must be between %d and %d", minHedgePercentile, maxHedgePercentile)
+	}
+	if policy.MinDelayMs < 0 || policy.MaxDelayMs < 0 {
+		return GroupHedgePolicy{}, fmt.Errorf("hedge delays cannot be negative")
+	}
+	if policy.MinDelayMs > maxHedgeDelayMs || policy.MaxDelayMs > maxHedgeDelayMs {
+		return GroupHedgePolicy{}, fmt.Errorf("hedge delays cannot exceed %d ms", maxHedgeDelayMs)
+	}
+	if policy.MaxDelayMs == 0 {
+		policy.MaxDelayMs = max(defaultHedgeMaxDelayMs, policy.MinDelayMs)
+	}
+	if policy.MinDelayMs > policy.MaxDelayMs {
+		return GroupHedgePolicy{}, fmt.Errorf("hedge min_delay_ms cannot exceed max_delay_ms")
+	}
+	return policy, nil
+}
+
+// HedgeEnabled 报告分组是否对非流式 OpenAI 请求启用对冲。
+func (g *Group) HedgeEnabled() bool {
+	return g != nil && g.Platform == PlatformOpenAI && g.HedgePolicy.Enabled
+}
+
+// openAIHedgeDelay 按分位耗时计算对冲延迟并夹到 [MinDelayMs, MaxDelayMs]；
+// 没有足够样本时使用 MaxDelayMs，避免冷启动阶段过早对冲。
+func openAIHedgeDelay(policy GroupHedgePolicy, percentileMs float64, ok bool) time.Duration {
+	delayMs := float64(policy.MaxDelayMs)
+	if ok {
+		delayMs = math.Max(float64(policy.MinDelayMs), math.Min(percentileMs, float64(policy.MaxDelayMs)))
+	}
+	return time.Duration(delayMs * float64(time.Millisecond))
+}
+
+// openAIHedgeLatencyWindow 是单账号的耗时环形缓冲。
+type openAIHedgeLatencyWindow struct {
+	mu      sync.Mutex
+	samples [openAIHedgeLatencyWindowSize]float64
+	next    int
+	count   int
+}
+
+func (w *openAIHedgeLatencyWindow) add(ms float64) {
+	w.mu.Lock()
+	w.samples[w.next] = ms
+	w.next = (w.next + 1) % len(w.samples)
+	if w.count < len(w.samples) {
+		w.count++
+	}
+	w.mu.Unlock()
+}
+
+// percentile 返回最近样本的 p 分位（nearest-rank）。
+func (w *openAIHedgeLatencyWindow) percentile(p int) (float64, bool) {
+	w.mu.Lock()
+	if w.count < openAIHedgeMinLatencySamples {
+		w.mu.Unlock()
+		return 0, false
+	}
+	sorted := make([]float64, w.count)
+	copy(sorted, w.samples[:w.count])
+	w.mu.Unlock()
+
+	sort.Float64s(sorted)
+	rank := int(math.Ceil(float64(p) / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1], true
+}
+
+// openAIHedgeLatencyStats 按账号记录非流式请求的完整耗时。
+// 非流式响应没有首 token 时间，EWMA TTFT 无法反映这类请求的尾延迟。
+type openAIHedgeLatencyStats struct {
+	accounts sync.Map // key: int64(accountID), value: *openAIHedgeLatencyWindow
+}
+
+func (s *openAIHedgeLatencyStats) report(accountID int64, d time.Duration) {
+	if s == nil || accountID <= 0 || d <= 0 {
+		return
+	}
+	value, _ := s.accounts.LoadOrStore(accountID, &openAIHedgeLatencyWindow{})
+	value.(*openAIHedgeLatencyWindow).add(float64(d) / float64(time.Millisecond))
+}
+
+func (s *openAIHedgeLatencyStats) percentile(accountID int64, p int) (float64, bool) {
+	if s == nil {
+		return 0, false
+	}
+	value, ok := s.accounts.Load(accountID)
+	if !ok {
+		return 0, false
+	}
+	return value.(*openAIHedgeLatencyWindow).percentile(p)
+}
+
+func (s *OpenAIGatewayService) hedgeLatencyStats() *openAIHedgeLatencyStats {
+	s.openaiHedgeLatencyOnce.Do(func() {
+		if s.openaiHedgeLatency == nil {
+			s.openaiHedgeLatency = &openAIHedgeLatencyStats{}
+		}
+	})
+	return s.openaiHedgeLatency
+}
+
+// ReportOpenAIHedgeLatency 记录账号一次成功的非流式请求耗时，作为对冲延迟的分位样本。
+func (s *OpenAIGatewayService) ReportOpenAIHedgeLatency(accountID int64, d time.Duration) {
+	if s == nil {
+		return
+	}
+	s.hedgeLatencyStats().report(accountID, d)
+}
+
+// OpenAIHedgeDelay 返回首个账号应等待多久再触发对冲。
+func (s *OpenAIGatewayService) OpenAIHedgeDelay(policy GroupHedgePolicy, accountID int64) time.Duration {
+	if s == nil {
+		return openAIHedgeDelay(policy, 0, false)
+	}
+	p, ok := s.hedgeLatencyStats().percentile(accountID, policy.Percentile)
+	return openAIHedgeDelay(policy, p, ok)
+}
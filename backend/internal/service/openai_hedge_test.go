@@ -0,0 +1,86 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeGroupHedgePolicy(t *testing.T) {
+	policy, err := NormalizeGroupHedgePolicy(GroupHedgePolicy{Enabled: true})
+	require.NoError(t, err)
+	require.Equal(t, GroupHedgePolicy{Enabled: true, Percentile: 95, MaxDelayMs: 5000}, policy)
+
+	policy, err = NormalizeGroupHedgePolicy(GroupHedgePolicy{Percentile: 90, MinDelayMs: 8000})
+	require.NoError(t, err)
+	require.Equal(t, 8000, policy.MaxDelayMs, "max defaults to at least min")
+
+	for _, bad := range []GroupHedgePolicy{
+		{Percentile: 40},
+		{Percentile: 100},
+		{MinDelayMs: -1},
+		{MinDelayMs: 3000, MaxDelayMs: 2000},
+		{MaxDelayMs: maxHedgeDelayMs + 1},
+	} {
+		_, err := NormalizeGroupHedgePolicy(bad)
+		require.Error(t, err, "%+v", bad)
+	}
+}
+
+func TestGroup_HedgeEnabled(t *testing.T) {
+	require.False(t, (*Group)(nil).HedgeEnabled())
+	require.True(t, (&Group{Platform: PlatformOpenAI, HedgePolicy: GroupHedgePolicy{Enabled: true}}).HedgeEnabled())
+	require.False(t, (&Group{Platform: PlatformAnthropic, HedgePolicy: GroupHedgePolicy{Enabled: true}}).HedgeEnabled())
+	require.False(t, (&Group{Platform: PlatformOpenAI}).HedgeEnabled())
+}
+
+func TestOpenAIHedgeDelay_PercentileClampedAndColdStart(t *testing.T) {
+	svc := &OpenAIGatewayService{}
+	policy := GroupHedgePolicy{Enabled: true, Percentile: 90, MinDelayMs: 300, MaxDelayMs: 4000}
+
+	require.Equal(t, 4000*time.Millisecond, svc.OpenAIHedgeDelay(policy, 1), "no samples falls back to max delay")
+
+	for i := 1; i <= 20; i++ {
+		svc.ReportOpenAIHedgeLatency(1, time.Duration(i*100)*time.Millisecond)
+	}
+	require.Equal(t, 1800*time.Millisecond, svc.OpenAIHedgeDelay(policy, 1), "p90 of 100..2000ms")
+
+	for i := 0; i < openAIHedgeLatencyWindowSize; i++ {
+		svc.ReportOpenAIHedgeLatency(2, 50*time.Millisecond)
+		svc.ReportOpenAIHedgeLatency(3, time.Minute)
+	}
+	require.Equal(t, 300*time.Millisecond, svc.OpenAIHedgeDelay(policy, 2))
+	require.Equal(t, 4000*time.Millisecond, svc.OpenAIHedgeDelay(policy, 3))
+}
+
+func TestOpenAIHedgeLatencyWindow_KeepsMostRecentSamples(t *testing.T) {
+	var w openAIHedgeLatencyWindow
+	for i := 0; i < openAIHedgeLatencyWindowSize; i++ {
+		w.add(10000)
+	}
+	for i := 0; i < openAIHedgeLatencyWindowSize; i++ {
+		w.add(100)
+	}
+	p, ok := w.percentile(99)
+	require.True(t, ok)
+	require.Equal(t, 100.0, p)
+}
+
+func TestSummarizeGroupHedgeStats(t *testing.T) {
+	summary := summarizeGroupHedgeStats(7, 7, []GroupHedgeDailyStat{
+		{Date: "2026-10-15", HedgedCount: 6, HedgeWinCount: 2, PrimaryWinCount: 3, HedgeCost: 0.5},
+		{Date: "2026-10-16", HedgedCount: 4, HedgeWinCount: 3, PrimaryWinCount: 1, HedgeCost: 0.25},
+	})
+	require.EqualValues(t, 10, summary.HedgedCount)
+	require.EqualValues(t, 5, summary.HedgeWinCount)
+	require.EqualValues(t, 4, summary.PrimaryWinCount)
+	require.InDelta(t, 0.5, summary.HedgeWinRate, 1e-9)
+	require.InDelta(t, 0.75, summary.HedgeCost, 1e-9)
+
+	empty := summarizeGroupHedgeStats(7, 7, nil)
+	require.NotNil(t, empty.Daily)
+	require.Zero(t, empty.HedgeWinRate)
+}
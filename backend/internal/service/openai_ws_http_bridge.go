@@ -229,6 +229,9 @@ func (s *OpenAIGatewayService) proxyOpenAIWSHTTPBridgeTurn(
 		c.Set("openai_ws_http_bridge", true)
 	}
 
+	if err := s.prepareAzureOpenAIRequest(ctx, account, upstreamReq); err != nil {
+		return nil, err
+	}
 	turnStart := time.Now()
 	resp, err := s.httpUpstream.Do(upstreamReq, proxyURL, account.ID, account.Concurrency)
 	if err != nil {
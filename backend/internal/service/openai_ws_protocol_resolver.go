@@ -45,6 +45,10 @@ func (r *defaultOpenAIWSProtocolResolver) Resolve(account *Account) OpenAIWSProt
 	if account.IsOpenAIWSForceHTTPEnabled() {
 		return openAIWSHTTPDecision("account_force_http")
 	}
+	if account.IsAzureOpenAI() {
+		// Azure OpenAI 的部署路径与鉴权头只在 HTTP 链路上改写
+		return openAIWSHTTPDecision("azure_http_only")
+	}
 	if r == nil || r.cfg == nil {
 		return openAIWSHTTPDecision("config_missing")
 	}
@@ -0,0 +1,540 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/httpclient"
+	"github.com/Wei-Shaw/sub2api/internal/util/urlvalidator"
+)
+
+const (
+	opsAlertMaxNotifyChannels     = 10
+	opsAlertChannelRequestTimeout = 10 * time.Second
+	opsAlertChannelMaxAttempts    = 3
+	opsAlertChannelMaxTextLen     = 3500
+	opsAlertChannelMaxTemplateLen = 4000
+	opsAlertChannelErrorBodyLimit = 512
+
+	defaultOpsAlertTelegramAPIBase = "https://api.telegram.org"
+
+	// OpsAlertWebhookSignatureHeader 通用 webhook 的签名头：
+	// "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body))。
+	OpsAlertWebhookSignatureHeader = "X-Sub2API-Signature"
+	OpsAlertWebhookTimestampHeader = "X-Sub2API-Timestamp"
+	OpsAlertWebhookEventHeader     = "X-Sub2API-Event"
+)
+
+const (
+	defaultOpsAlertFiringTemplate = `[{{.kind_label}}][{{.severity}}] {{.rule_name}}
+Metric: {{.metric_type}} = {{.metric_value}} ({{.operator}} {{.threshold_value}})
+Fired at: {{.triggered_at}}
+{{.alert_description}}`
+
+	defaultOpsAlertResolvedTemplate = `[{{.kind_label}}][{{.severity}}] {{.rule_name}}
+Metric: {{.metric_type}} {{.operator}} {{.threshold_value}}
+Fired at: {{.triggered_at}}
+Resolved at: {{.resolved_at}}`
+)
+
+// opsAlertChannelRetryBackoff 第 N 次重试前的等待时间；仅对网络错误、429 与 5xx 重试。
+var opsAlertChannelRetryBackoff = []time.Duration{time.Second, 3 * time.Second}
+
+var validOpsAlertChannelTypes = []string{
+	OpsAlertChannelWebhook,
+	OpsAlertChannelSlack,
+	OpsAlertChannelTelegram,
+	OpsAlertChannelFeishu,
+	OpsAlertChannelDingTalk,
+}
+
+// normalizeOpsAlertNotifyChannels 校验并规整规则上的通知渠道配置。
+// URL 校验沿用 security.url_allowlist 的 http/私网策略；模板在保存时即解析，
+// 避免告警触发时才发现语法错误。
+func normalizeOpsAlertNotifyChannels(cfg *config.Config, channels []OpsAlertNotifyChannel) ([]OpsAlertNotifyChannel, error) {
+	if len(channels) == 0 {
+		return nil, nil
+	}
+	if len(channels) > opsAlertMaxNotifyChannels {
+		return nil, fmt.Errorf("at most %d notify channels are allowed", opsAlertMaxNotifyChannels)
+	}
+	out := make([]OpsAlertNotifyChannel, 0, len(channels))
+	for i, ch := range channels {
+		normalized, err := normalizeOpsAlertNotifyChannel(cfg, ch)
+		if err != nil {
+			return nil, fmt.Errorf("notify_channels[%d]: %w", i, err)
+		}
+		out = append(out, normalized)
+	}
+	return out, nil
+}
+
+func normalizeOpsAlertNotifyChannel(cfg *config.Config, ch OpsAlertNotifyChannel) (OpsAlertNotifyChannel, error) {
+	ch.Type = strings.ToLower(strings.TrimSpace(ch.Type))
+	ch.Name = strings.TrimSpace(ch.Name)
+	ch.URL = strings.TrimSpace(ch.URL)
+	ch.Secret = strings.TrimSpace(ch.Secret)
+	ch.BotToken = strings.TrimSpace(ch.BotToken)
+	ch.ChatID = strings.TrimSpace(ch.ChatID)
+	ch.SecretConfigured, ch.BotTokenConfigured = false, false
+
+	switch ch.Type {
+	case OpsAlertChannelWebhook, OpsAlertChannelSlack, OpsAlertChannelFeishu, OpsAlertChannelDingTalk:
+		if ch.URL == "" {
+			return ch, fmt.Errorf("url is required for %s channel", ch.Type)
+		}
+	case OpsAlertChannelTelegram:
+		if ch.BotToken == "" || ch.ChatID == "" {
+			return ch, errors.New("bot_token and chat_id are required for telegram channel")
+		}
+		if strings.ContainsAny(ch.BotToken, "/?#") {
+			return ch, errors.New("invalid bot_token")
+		}
+	default:
+		return ch, fmt.Errorf("type must be one of: %s", strings.Join(validOpsAlertChannelTypes, ", "))
+	}
+	if ch.URL != "" {
+		normalized, err := validateOpsAlertChannelURL(cfg, ch.URL)
+		if err != nil {
+			return ch, err
+		}
+		ch.URL = normalized
+	}
+	for _, tpl := range []string{ch.FiringTemplate, ch.ResolvedTemplate} {
+		if len(tpl) > opsAlertChannelMaxTemplateLen {
+			return ch, fmt.Errorf("template must be at most %d characters", opsAlertChannelMaxTemplateLen)
+		}
+		if strings.TrimSpace(tpl) == "" {
+			continue
+		}
+		if _, err := parseOpsAlertChannelTemplate(tpl); err != nil {
+			return ch, fmt.Errorf("invalid template: %w", err)
+		}
+	}
+	return ch, nil
+}
+
+// MaskOpsAlertRuleSecrets 返回隐去渠道密钥的规则副本，供管理端 API 输出。
+func MaskOpsAlertRuleSecrets(rule *OpsAlertRule) *OpsAlertRule {
+	if rule == nil || len(rule.NotifyChannels) == 0 {
+		return rule
+	}
+	out := *rule
+	out.NotifyChannels = make([]OpsAlertNotifyChannel, len(rule.NotifyChannels))
+	for i, ch := range rule.NotifyChannels {
+		ch.SecretConfigured = ch.Secret != ""
+		ch.BotTokenConfigured = ch.BotToken != ""
+		ch.Secret = ""
+		ch.BotToken = ""
+		out.NotifyChannels[i] = ch
+	}
+	return &out
+}
+
+// fillOpsAlertChannelSecrets 把请求中留空的 Secret/BotToken 补为已保存渠道的值。
+// 仅在类型、URL 与 ChatID 都相同时沿用，目标变更后需要重新填写密钥。
+func fillOpsAlertChannelSecrets(channels []OpsAlertNotifyChannel, stored []OpsAlertNotifyChannel) {
+	for i := range channels {
+		ch := &channels[i]
+		if strings.TrimSpace(ch.Secret) != "" && strings.TrimSpace(ch.BotToken) != "" {
+			continue
+		}
+		for _, old := range stored {
+			if !sameOpsAlertChannelTarget(*ch, old) {
+				continue
+			}
+			if strings.TrimSpace(ch.Secret) == "" {
+				ch.Secret = old.Secret
+			}
+			if strings.TrimSpace(ch.BotToken) == "" {
+				ch.BotToken = old.BotToken
+			}
+			break
+		}
+	}
+}
+
+func sameOpsAlertChannelTarget(ch, stored OpsAlertNotifyChannel) bool {
+	return strings.ToLower(strings.TrimSpace(ch.Type)) == stored.Type &&
+		strings.TrimSpace(ch.URL) == stored.URL &&
+		strings.TrimSpace(ch.ChatID) == stored.ChatID
+}
+
+func validateOpsAlertChannelURL(cfg *config.Config, raw string) (string, error) {
+	if cfg == nil || !cfg.Security.URLAllowlist.Enabled {
+		allowHTTP := cfg != nil && cfg.Security.URLAllowlist.AllowInsecureHTTP
+		return urlvalidator.ValidateURLFormat(raw, allowHTTP)
+	}
+	return urlvalidator.ValidateHTTPURL(raw, cfg.Security.URLAllowlist.AllowInsecureHTTP, urlvalidator.ValidationOptions{
+		AllowPrivate: cfg.Security.URLAllowlist.AllowPrivateHosts,
+	})
+}
+
+func parseOpsAlertChannelTemplate(text string) (*template.Template, error) {
+	return template.New("ops_alert_channel").Option("missingkey=zero").Parse(text)
+}
+
+// opsAlertChannelNotifier 负责把告警事件投递到聊天机器人/通用 webhook。
+type opsAlertChannelNotifier struct {
+	cfg     *config.Config
+	client  *http.Client
+	backoff []time.Duration
+	now     func() time.Time
+}
+
+func newOpsAlertChannelNotifier(cfg *config.Config) *opsAlertChannelNotifier {
+	return &opsAlertChannelNotifier{
+		cfg:     cfg,
+		backoff: opsAlertChannelRetryBackoff,
+		now:     time.Now,
+	}
+}
+
+func (n *opsAlertChannelNotifier) httpClient() (*http.Client, error) {
+	if n.client != nil {
+		return n.client, nil
+	}
+	opts := httpclient.Options{Timeout: opsAlertChannelRequestTimeout}
+	if n.cfg != nil && n.cfg.Security.URLAllowlist.Enabled {
+		opts.ValidateResolvedIP = true
+		opts.AllowPrivateHosts = n.cfg.Security.URLAllowlist.AllowPrivateHosts
+	}
+	return httpclient.GetClient(opts)
+}
+
+// DeliverAll 依次投递到所有启用的渠道；单个渠道失败不影响其它渠道。
+func (n *opsAlertChannelNotifier) DeliverAll(ctx context.Context, channels []OpsAlertNotifyChannel, kind string, rule *OpsAlertRule, event *OpsAlertEvent) []OpsAlertChannelDelivery {
+	out := make([]OpsAlertChannelDelivery, 0, len(channels))
+	for _, ch := range channels {
+		if !ch.IsEnabled() {
+			continue
+		}
+		out = append(out, n.Deliver(ctx, ch, kind, rule, event))
+	}
+	return out
+}
+
+// Deliver 投递单个渠道，按退避策略重试可重试错误，并返回投递记录。
+func (n *opsAlertChannelNotifier) Deliver(ctx context.Context, ch OpsAlertNotifyChannel, kind string, rule *OpsAlertRule, event *OpsAlertEvent) OpsAlertChannelDelivery {
+	delivery := OpsAlertChannelDelivery{
+		Channel: opsAlertChannelLabel(ch),
+		Type:    ch.Type,
+		Kind:    kind,
+		Status:  OpsAlertDeliveryStatusFailed,
+	}
+	text := renderOpsAlertChannelText(ch, kind, rule, event)
+
+	for attempt := 1; attempt <= opsAlertChannelMaxAttempts; attempt++ {
+		if attempt > 1 {
+			wait := n.backoff[min(attempt-2, len(n.backoff)-1)]
+			select {
+			case <-ctx.Done():
+				delivery.Error = ctx.Err().Error()
+				delivery.DeliveredAt = n.now().UTC()
+				return delivery
+			case <-time.After(wait):
+			}
+		}
+		delivery.Attempts = attempt
+		statusCode, retryable, err := n.send(ctx, ch, kind, text, rule, event)
+		delivery.StatusCode = statusCode
+		if err == nil {
+			delivery.Status = OpsAlertDeliveryStatusSent
+			delivery.Error = ""
+			break
+		}
+		delivery.Error = truncateString(err.Error(), opsAlertChannelErrorBodyLimit)
+		if !retryable || len(n.backoff) == 0 {
+			break
+		}
+	}
+	delivery.DeliveredAt = n.now().UTC()
+	return delivery
+}
+
+func (n *opsAlertChannelNotifier) send(ctx context.Context, ch OpsAlertNotifyChannel, kind, text string, rule *OpsAlertRule, event *OpsAlertEvent) (int, bool, error) {
+	req, err := n.buildRequest(ctx, ch, kind, text, rule, event)
+	if err != nil {
+		return 0, false, err
+	}
+	client, err := n.httpClient()
+	if err != nil {
+		return 0, false, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, ctx.Err() == nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return resp.StatusCode, true, fmt.Errorf("http %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, false, fmt.Errorf("http %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return resp.StatusCode, false, checkOpsAlertChannelResponse(ch.Type, body)
+}
+
+func (n *opsAlertChannelNotifier) buildRequest(ctx context.Context, ch OpsAlertNotifyChannel, kind, text string, rule *OpsAlertRule, event *OpsAlertEvent) (*http.Request, error) {
+	now := n.now()
+	target := ch.URL
+	var payload any
+
+	switch ch.Type {
+	case OpsAlertChannelWebhook:
+		payload = buildOpsAlertWebhookPayload(kind, text, rule, event, now)
+	case OpsAlertChannelSlack:
+		payload = map[string]any{"text": text}
+	case OpsAlertChannelTelegram:
+		base := strings.TrimRight(ch.URL, "/")
+		if base == "" {
+			base = defaultOpsAlertTelegramAPIBase
+		}
+		target = base + "/bot" + ch.BotToken + "/sendMessage"
+		payload = map[string]any{
+			"chat_id":                  ch.ChatID,
+			"text":                     text,
+			"disable_web_page_preview": true,
+		}
+	case OpsAlertChannelFeishu:
+		body := map[string]any{
+			"msg_type": "text",
+			"content":  map[string]string{"text": text},
+		}
+		if ch.Secret != "" {
+			ts := strconv.FormatInt(now.Unix(), 10)
+			body["timestamp"] = ts
+			body["sign"] = feishuBotSign(ts, ch.Secret)
+		}
+		payload = body
+	case OpsAlertChannelDingTalk:
+		if ch.Secret != "" {
+			signed, err := dingTalkSignedURL(ch.URL, ch.Secret, now)
+			if err != nil {
+				return nil, err
+			}
+			target = signed
+		}
+		payload = map[string]any{
+			"msgtype": "text",
+			"text":    map[string]string{"content": text},
+		}
+	default:
+		return nil, fmt.Errorf("unsupported channel type: %s", ch.Type)
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if ch.Type == OpsAlertChannelWebhook {
+		ts := strconv.FormatInt(now.Unix(), 10)
+		req.Header.Set(OpsAlertWebhookTimestampHeader, ts)
+		req.Header.Set(OpsAlertWebhookEventHeader, kind)
+		if ch.Secret != "" {
+			req.Header.Set(OpsAlertWebhookSignatureHeader, SignOpsAlertWebhook(ch.Secret, ts, body))
+		}
+	}
+	return req, nil
+}
+
+type opsAlertWebhookRule struct {
+	ID         int64   `json:"id"`
+	Name       string  `json:"name"`
+	Severity   string  `json:"severity"`
+	MetricType string  `json:"metric_type"`
+	Operator   string  `json:"operator"`
+	Threshold  float64 `json:"threshold"`
+}
+
+type opsAlertWebhookPayload struct {
+	Kind   string               `json:"kind"`
+	Text   string               `json:"text"`
+	Rule   *opsAlertWebhookRule `json:"rule,omitempty"`
+	Event  *OpsAlertEvent       `json:"event,omitempty"`
+	SentAt time.Time            `json:"sent_at"`
+}
+
+func buildOpsAlertWebhookPayload(kind, text string, rule *OpsAlertRule, event *OpsAlertEvent, now time.Time) opsAlertWebhookPayload {
+	payload := opsAlertWebhookPayload{Kind: kind, Text: text, SentAt: now.UTC()}
+	if rule != nil {
+		payload.Rule = &opsAlertWebhookRule{
+			ID:         rule.ID,
+			Name:       rule.Name,
+			Severity:   rule.Severity,
+			MetricType: rule.MetricType,
+			Operator:   rule.Operator,
+			Threshold:  rule.Threshold,
+		}
+	}
+	if event != nil {
+		ev := *event
+		ev.ChannelDeliveries = nil
+		payload.Event = &ev
+	}
+	return payload
+}
+
+// SignOpsAlertWebhook 计算通用 webhook 的签名，接收方可用同样方式校验。
+func SignOpsAlertWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// feishuBotSign 飞书自定义机器人签名：以 "timestamp\nsecret" 为密钥对空串做 HMAC-SHA256。
+func feishuBotSign(timestamp, secret string) string {
+	mac := hmac.New(sha256.New, []byte(timestamp+"\n"+secret))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// dingTalkSignedURL 钉钉机器人加签：HMAC-SHA256(secret, "timestampMs\nsecret")，附加到 URL 查询参数。
+func dingTalkSignedURL(raw, secret string, now time.Time) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", err
+	}
+	ts := strconv.FormatInt(now.UnixMilli(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts + "\n" + secret))
+	q := u.Query()
+	q.Set("timestamp", ts)
+	q.Set("sign", base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// checkOpsAlertChannelResponse 识别 HTTP 200 但业务失败的机器人响应。
+func checkOpsAlertChannelResponse(channelType string, body []byte) error {
+	switch channelType {
+	case OpsAlertChannelTelegram:
+		var resp struct {
+			OK          bool   `json:"ok"`
+			Description string `json:"description"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return fmt.Errorf("invalid telegram response: %w", err)
+		}
+		if !resp.OK {
+			return fmt.Errorf("telegram: %s", resp.Description)
+		}
+	case OpsAlertChannelFeishu:
+		var resp struct {
+			Code          *int   `json:"code"`
+			Msg           string `json:"msg"`
+			StatusCode    *int   `json:"StatusCode"`
+			StatusMessage string `json:"StatusMessage"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return fmt.Errorf("invalid feishu response: %w", err)
+		}
+		if resp.Code != nil && *resp.Code != 0 {
+			return fmt.Errorf("feishu code %d: %s", *resp.Code, resp.Msg)
+		}
+		if resp.StatusCode != nil && *resp.StatusCode != 0 {
+			return fmt.Errorf("feishu code %d: %s", *resp.StatusCode, resp.StatusMessage)
+		}
+	case OpsAlertChannelDingTalk:
+		var resp struct {
+			ErrCode int    `json:"errcode"`
+			ErrMsg  string `json:"errmsg"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return fmt.Errorf("invalid dingtalk response: %w", err)
+		}
+		if resp.ErrCode != 0 {
+			return fmt.Errorf("dingtalk errcode %d: %s", resp.ErrCode, resp.ErrMsg)
+		}
+	}
+	return nil
+}
+
+func opsAlertChannelLabel(ch OpsAlertNotifyChannel) string {
+	if ch.Name != "" {
+		return ch.Name
+	}
+	return ch.Type
+}
+
+// renderOpsAlertChannelText 渲染渠道消息正文；自定义模板执行失败或渲染为空时回退到内置模板。
+func renderOpsAlertChannelText(ch OpsAlertNotifyChannel, kind string, rule *OpsAlertRule, event *OpsAlertEvent) string {
+	vars := opsAlertChannelVariables(kind, rule, event)
+
+	custom, fallback := ch.FiringTemplate, defaultOpsAlertFiringTemplate
+	if kind == OpsAlertNotifyKindResolved {
+		custom, fallback = ch.ResolvedTemplate, defaultOpsAlertResolvedTemplate
+	}
+	if strings.TrimSpace(custom) != "" {
+		if text, err := executeOpsAlertChannelTemplate(custom, vars); err == nil && strings.TrimSpace(text) != "" {
+			return truncateString(strings.TrimSpace(text), opsAlertChannelMaxTextLen)
+		}
+	}
+	text, _ := executeOpsAlertChannelTemplate(fallback, vars)
+	return truncateString(strings.TrimSpace(text), opsAlertChannelMaxTextLen)
+}
+
+func executeOpsAlertChannelTemplate(text string, vars map[string]string) (string, error) {
+	tpl, err := parseOpsAlertChannelTemplate(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, vars); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func opsAlertChannelVariables(kind string, rule *OpsAlertRule, event *OpsAlertEvent) map[string]string {
+	vars := opsAlertEmailVariables(rule, event)
+	vars["kind"] = kind
+	vars["kind_label"] = strings.ToUpper(kind)
+	vars["resolved_at"] = "-"
+	vars["event_id"] = "-"
+	vars["title"] = "-"
+	if event != nil {
+		if event.ResolvedAt != nil {
+			vars["resolved_at"] = event.ResolvedAt.UTC().Format(time.RFC3339)
+		}
+		if event.ID > 0 {
+			vars["event_id"] = strconv.FormatInt(event.ID, 10)
+		}
+		if strings.TrimSpace(event.Title) != "" {
+			vars["title"] = strings.TrimSpace(event.Title)
+		}
+	}
+	return vars
+}
+
+func hasEnabledOpsAlertChannels(channels []OpsAlertNotifyChannel) bool {
+	for _, ch := range channels {
+		if ch.IsEnabled() {
+			return true
+		}
+	}
+	return false
+}
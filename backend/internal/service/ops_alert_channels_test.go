@@ -0,0 +1,235 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestOpsAlertChannelNotifier(srv *httptest.Server) *opsAlertChannelNotifier {
+	n := newOpsAlertChannelNotifier(nil)
+	n.client = srv.Client()
+	n.backoff = []time.Duration{0, 0}
+	n.now = func() time.Time { return time.Unix(1700000000, 0) }
+	return n
+}
+
+func testOpsAlertRuleAndEvent() (*OpsAlertRule, *OpsAlertEvent) {
+	rule := &OpsAlertRule{ID: 7, Name: "High error rate", Severity: "P1", MetricType: "error_rate", Operator: ">", Threshold: 5}
+	value := 12.5
+	event := &OpsAlertEvent{ID: 42, RuleID: 7, Severity: "P1", Status: OpsAlertStatusFiring, MetricValue: &value, FiredAt: time.Unix(1700000000, 0)}
+	return rule, event
+}
+
+func TestNormalizeOpsAlertNotifyChannels(t *testing.T) {
+	cfg := &config.Config{}
+
+	_, err := normalizeOpsAlertNotifyChannels(cfg, []OpsAlertNotifyChannel{{Type: "pagerduty", URL: "https://example.com"}})
+	require.ErrorContains(t, err, "type must be one of")
+
+	_, err = normalizeOpsAlertNotifyChannels(cfg, []OpsAlertNotifyChannel{{Type: "slack"}})
+	require.ErrorContains(t, err, "url is required")
+
+	_, err = normalizeOpsAlertNotifyChannels(cfg, []OpsAlertNotifyChannel{{Type: "webhook", URL: "http://example.com/hook"}})
+	require.ErrorContains(t, err, "invalid url scheme")
+
+	_, err = normalizeOpsAlertNotifyChannels(cfg, []OpsAlertNotifyChannel{{Type: "telegram", BotToken: "123:abc"}})
+	require.ErrorContains(t, err, "chat_id")
+
+	_, err = normalizeOpsAlertNotifyChannels(cfg, []OpsAlertNotifyChannel{{Type: "slack", URL: "https://hooks.slack.com/x", FiringTemplate: "{{.rule_name"}})
+	require.ErrorContains(t, err, "invalid template")
+
+	out, err := normalizeOpsAlertNotifyChannels(cfg, []OpsAlertNotifyChannel{{Type: " Feishu ", Name: " oncall ", URL: "https://open.feishu.cn/open-apis/bot/v2/hook/abc/"}})
+	require.NoError(t, err)
+	require.Equal(t, OpsAlertChannelFeishu, out[0].Type)
+	require.Equal(t, "oncall", out[0].Name)
+	require.True(t, out[0].IsEnabled())
+}
+
+func TestMaskOpsAlertRuleSecrets(t *testing.T) {
+	rule := &OpsAlertRule{ID: 7, NotifyChannels: []OpsAlertNotifyChannel{
+		{Type: OpsAlertChannelWebhook, URL: "https://example.com/hook", Secret: "whsec"},
+		{Type: OpsAlertChannelTelegram, BotToken: "123:abc", ChatID: "-100"},
+		{Type: OpsAlertChannelSlack, URL: "https://hooks.slack.com/x"},
+	}}
+
+	masked := MaskOpsAlertRuleSecrets(rule)
+	require.Empty(t, masked.NotifyChannels[0].Secret)
+	require.True(t, masked.NotifyChannels[0].SecretConfigured)
+	require.Empty(t, masked.NotifyChannels[1].BotToken)
+	require.True(t, masked.NotifyChannels[1].BotTokenConfigured)
+	require.False(t, masked.NotifyChannels[2].SecretConfigured)
+
+	raw, err := json.Marshal(masked)
+	require.NoError(t, err)
+	require.NotContains(t, string(raw), "whsec")
+	require.NotContains(t, string(raw), "123:abc")
+
+	// 原规则仍供告警评估使用，不能被修改。
+	require.Equal(t, "whsec", rule.NotifyChannels[0].Secret)
+	require.Equal(t, "123:abc", rule.NotifyChannels[1].BotToken)
+}
+
+func TestFillOpsAlertChannelSecrets_KeepsStoredValueForSameTarget(t *testing.T) {
+	stored := []OpsAlertNotifyChannel{
+		{Type: OpsAlertChannelWebhook, URL: "https://example.com/hook", Secret: "whsec"},
+		{Type: OpsAlertChannelTelegram, BotToken: "123:abc", ChatID: "-100"},
+	}
+	incoming := []OpsAlertNotifyChannel{
+		{Type: "Webhook", URL: " https://example.com/hook ", SecretConfigured: true},
+		{Type: OpsAlertChannelTelegram, ChatID: "-100", BotTokenConfigured: true},
+		{Type: OpsAlertChannelWebhook, URL: "https://other.example.com/hook"},
+		{Type: OpsAlertChannelWebhook, URL: "https://example.com/hook", Secret: "rotated"},
+	}
+
+	fillOpsAlertChannelSecrets(incoming, stored)
+	require.Equal(t, "whsec", incoming[0].Secret)
+	require.Equal(t, "123:abc", incoming[1].BotToken)
+	require.Empty(t, incoming[2].Secret, "changing the target must not carry the old secret over")
+	require.Equal(t, "rotated", incoming[3].Secret)
+
+	out, err := normalizeOpsAlertNotifyChannels(&config.Config{}, incoming[:2])
+	require.NoError(t, err)
+	require.False(t, out[0].SecretConfigured)
+	require.False(t, out[1].BotTokenConfigured)
+}
+
+func TestOpsAlertChannelNotifier_WebhookSigned(t *testing.T) {
+	var gotBody []byte
+	var gotHeader http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Clone()
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	rule, event := testOpsAlertRuleAndEvent()
+	n := newTestOpsAlertChannelNotifier(srv)
+	d := n.Deliver(context.Background(), OpsAlertNotifyChannel{Type: OpsAlertChannelWebhook, URL: srv.URL, Secret: "s3cret"}, OpsAlertNotifyKindFiring, rule, event)
+
+	require.Equal(t, OpsAlertDeliveryStatusSent, d.Status)
+	require.Equal(t, 1, d.Attempts)
+	require.Equal(t, "1700000000", gotHeader.Get(OpsAlertWebhookTimestampHeader))
+	require.Equal(t, OpsAlertNotifyKindFiring, gotHeader.Get(OpsAlertWebhookEventHeader))
+	require.Equal(t, SignOpsAlertWebhook("s3cret", "1700000000", gotBody), gotHeader.Get(OpsAlertWebhookSignatureHeader))
+
+	var payload map[string]any
+	require.NoError(t, json.Unmarshal(gotBody, &payload))
+	require.Equal(t, "firing", payload["kind"])
+	require.Contains(t, payload["text"], "[FIRING][P1] High error rate")
+	require.Contains(t, payload["text"], "error_rate = 12.50 (> 5.00)")
+}
+
+func TestOpsAlertChannelNotifier_RetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	rule, event := testOpsAlertRuleAndEvent()
+	d := newTestOpsAlertChannelNotifier(srv).Deliver(context.Background(), OpsAlertNotifyChannel{Type: OpsAlertChannelSlack, URL: srv.URL}, OpsAlertNotifyKindFiring, rule, event)
+	require.Equal(t, OpsAlertDeliveryStatusSent, d.Status)
+	require.Equal(t, 3, d.Attempts)
+	require.Empty(t, d.Error)
+}
+
+func TestOpsAlertChannelNotifier_DoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, "invalid_token", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	rule, event := testOpsAlertRuleAndEvent()
+	d := newTestOpsAlertChannelNotifier(srv).Deliver(context.Background(), OpsAlertNotifyChannel{Type: OpsAlertChannelSlack, Name: "ops", URL: srv.URL}, OpsAlertNotifyKindFiring, rule, event)
+	require.Equal(t, OpsAlertDeliveryStatusFailed, d.Status)
+	require.Equal(t, "ops", d.Channel)
+	require.Equal(t, http.StatusForbidden, d.StatusCode)
+	require.Contains(t, d.Error, "invalid_token")
+	require.EqualValues(t, 1, calls.Load())
+}
+
+func TestOpsAlertChannelNotifier_DingTalkSignAndBusinessError(t *testing.T) {
+	var gotQuery url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.Query()
+		_, _ = w.Write([]byte(`{"errcode":310000,"errmsg":"sign not match"}`))
+	}))
+	defer srv.Close()
+
+	rule, event := testOpsAlertRuleAndEvent()
+	d := newTestOpsAlertChannelNotifier(srv).Deliver(context.Background(), OpsAlertNotifyChannel{Type: OpsAlertChannelDingTalk, URL: srv.URL + "?access_token=tok", Secret: "SECabc"}, OpsAlertNotifyKindFiring, rule, event)
+
+	require.Equal(t, OpsAlertDeliveryStatusFailed, d.Status)
+	require.Contains(t, d.Error, "sign not match")
+	require.Equal(t, "tok", gotQuery.Get("access_token"))
+	require.Equal(t, "1700000000000", gotQuery.Get("timestamp"))
+	mac := hmac.New(sha256.New, []byte("SECabc"))
+	mac.Write([]byte("1700000000000\nSECabc"))
+	require.Equal(t, base64.StdEncoding.EncodeToString(mac.Sum(nil)), gotQuery.Get("sign"))
+}
+
+func TestOpsAlertChannelNotifier_TelegramAndFeishuPayloads(t *testing.T) {
+	var paths []string
+	var bodies []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+		if r.URL.Path == "/feishu" {
+			_, _ = w.Write([]byte(`{"code":0,"msg":"success"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+
+	rule, event := testOpsAlertRuleAndEvent()
+	resolvedAt := time.Unix(1700000600, 0)
+	event.ResolvedAt = &resolvedAt
+	channels := []OpsAlertNotifyChannel{
+		{Type: OpsAlertChannelTelegram, URL: srv.URL, BotToken: "123:abc", ChatID: "-100", ResolvedTemplate: "{{.rule_name}} back to normal at {{.resolved_at}}"},
+		{Type: OpsAlertChannelFeishu, URL: srv.URL + "/feishu", Secret: "fs"},
+		{Type: OpsAlertChannelSlack, URL: srv.URL, Enabled: boolPtr(false)},
+	}
+	deliveries := newTestOpsAlertChannelNotifier(srv).DeliverAll(context.Background(), channels, OpsAlertNotifyKindResolved, rule, event)
+
+	require.Len(t, deliveries, 2)
+	for _, d := range deliveries {
+		require.Equal(t, OpsAlertDeliveryStatusSent, d.Status, d.Error)
+	}
+	require.Equal(t, []string{"/bot123:abc/sendMessage", "/feishu"}, paths)
+	require.Equal(t, "-100", bodies[0]["chat_id"])
+	require.Equal(t, "High error rate back to normal at 2023-11-14T22:23:20Z", bodies[0]["text"])
+	require.Equal(t, "text", bodies[1]["msg_type"])
+	require.Equal(t, feishuBotSign("1700000000", "fs"), bodies[1]["sign"])
+	require.Contains(t, bodies[1]["content"].(map[string]any)["text"], "[RESOLVED][P1] High error rate")
+}
+
+func TestRenderOpsAlertChannelText_FallsBackOnEmptyTemplateOutput(t *testing.T) {
+	rule, event := testOpsAlertRuleAndEvent()
+	text := renderOpsAlertChannelText(OpsAlertNotifyChannel{FiringTemplate: "{{.no_such_key}}"}, OpsAlertNotifyKindFiring, rule, event)
+	require.Contains(t, text, "[FIRING][P1] High error rate")
+}
@@ -22,6 +22,9 @@ const (
 	opsAlertEvaluatorLeaderLockKey   = "ops:alert:evaluator:leader"
 	opsAlertEvaluatorLeaderLockTTL   = 90 * time.Second
 	opsAlertEvaluatorSkipLogInterval = 1 * time.Minute
+
+	// opsAlertChannelDispatchTimeout 单次事件全部渠道（含重试）的投递上限。
+	opsAlertChannelDispatchTimeout = 2 * time.Minute
 )
 
 var opsAlertEvaluatorReleaseScript = redis.NewScript(`
@@ -49,7 +52,8 @@ type OpsAlertEvaluatorService struct {
 	mu         sync.Mutex
 	ruleStates map[int64]*opsAlertRuleState
 
-	emailLimiter *slidingWindowLimiter
+	emailLimiter    *slidingWindowLimiter
+	channelNotifier *opsAlertChannelNotifier
 
 	skipLogMu sync.Mutex
 	skipLogAt time.Time
@@ -80,6 +84,8 @@ func NewOpsAlertEvaluatorService(
 		instanceID:   uuid.NewString(),
 		ruleStates:   map[int64]*opsAlertRuleState{},
 		emailLimiter: newSlidingWindowLimiter(0, time.Hour),
+
+		channelNotifier: newOpsAlertChannelNotifier(cfg),
 	}
 }
 
@@ -199,6 +205,7 @@ func (s *OpsAlertEvaluatorService) evaluateOnce(interval time.Duration) {
 	eventsCreated := 0
 	eventsResolved := 0
 	emailsSent := 0
+	channelDispatches := 0
 
 	now := time.Now().UTC()
 	safeEnd := now.Truncate(time.Minute)
@@ -295,6 +302,9 @@ func (s *OpsAlertEvaluatorService) evaluateOnce(interval time.Duration) {
 				if s.maybeSendAlertEmail(ctx, runtimeCfg, rule, created) {
 					emailsSent++
 				}
+				if s.dispatchAlertChannels(runtimeCfg, rule, created, OpsAlertNotifyKindFiring) {
+					channelDispatches++
+				}
 			}
 			continue
 		}
@@ -306,11 +316,16 @@ func (s *OpsAlertEvaluatorService) evaluateOnce(interval time.Duration) {
 				logger.LegacyPrintf("service.ops_alert_evaluator", "[OpsAlertEvaluator] resolve event failed (event=%d): %v", activeEvent.ID, err)
 			} else {
 				eventsResolved++
+				activeEvent.Status = OpsAlertStatusResolved
+				activeEvent.ResolvedAt = &resolvedAt
+				if s.dispatchAlertChannels(runtimeCfg, rule, activeEvent, OpsAlertNotifyKindResolved) {
+					channelDispatches++
+				}
 			}
 		}
 	}
 
-	result := truncateString(fmt.Sprintf("rules=%d enabled=%d evaluated=%d created=%d resolved=%d emails_sent=%d", rulesTotal, rulesEnabled, rulesEvaluated, eventsCreated, eventsResolved, emailsSent), 2048)
+	result := truncateString(fmt.Sprintf("rules=%d enabled=%d evaluated=%d created=%d resolved=%d emails_sent=%d channel_dispatches=%d", rulesTotal, rulesEnabled, rulesEvaluated, eventsCreated, eventsResolved, emailsSent, channelDispatches), 2048)
 	s.recordHeartbeatSuccess(runAt, time.Since(startedAt), result)
 }
 
@@ -581,6 +596,15 @@ func (s *OpsAlertEvaluatorService) computeRuleMetric(
 			return 0, false
 		}
 		return float64(n), true
+	case "batch_settlement_stuck_count":
+		if s == nil || s.opsRepo == nil {
+			return 0, false
+		}
+		n, err := s.opsRepo.CountStuckBatchSettlements(ctx)
+		if err != nil {
+			return 0, false
+		}
+		return float64(n), true
 	}
 
 	overview, err := s.opsRepo.GetDashboardOverview(ctx, &OpsDashboardFilter{
@@ -747,6 +771,44 @@ func (s *OpsAlertEvaluatorService) maybeSendAlertEmail(ctx context.Context, runt
 	return anySent
 }
 
+// dispatchAlertChannels 异步投递聊天/webhook 渠道通知，投递结果追加到事件的 channel_deliveries。
+//
+// 渠道请求带重试，可能远超单轮评估的超时，因此不在评估循环内同步等待；
+// Stop 会等待进行中的投递完成。返回是否发起了投递。
+func (s *OpsAlertEvaluatorService) dispatchAlertChannels(runtimeCfg *OpsAlertRuntimeSettings, rule *OpsAlertRule, event *OpsAlertEvent, kind string) bool {
+	if s == nil || s.channelNotifier == nil || rule == nil || event == nil || event.ID <= 0 {
+		return false
+	}
+	if !hasEnabledOpsAlertChannels(rule.NotifyChannels) {
+		return false
+	}
+	if runtimeCfg != nil && runtimeCfg.Silencing.Enabled {
+		if isOpsAlertSilenced(time.Now().UTC(), rule, event, runtimeCfg.Silencing) {
+			return false
+		}
+	}
+
+	ruleCopy := *rule
+	eventCopy := *event
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), opsAlertChannelDispatchTimeout)
+		defer cancel()
+
+		deliveries := s.channelNotifier.DeliverAll(ctx, ruleCopy.NotifyChannels, kind, &ruleCopy, &eventCopy)
+		for _, d := range deliveries {
+			if d.Status != OpsAlertDeliveryStatusSent {
+				logger.LegacyPrintf("service.ops_alert_evaluator", "[OpsAlertEvaluator] %s notify failed (event=%d channel=%s attempts=%d): %s", kind, eventCopy.ID, d.Channel, d.Attempts, d.Error)
+			}
+		}
+		if err := s.opsRepo.AppendAlertEventChannelDeliveries(ctx, eventCopy.ID, deliveries); err != nil {
+			logger.LegacyPrintf("service.ops_alert_evaluator", "[OpsAlertEvaluator] record channel deliveries failed (event=%d): %v", eventCopy.ID, err)
+		}
+	}()
+	return true
+}
+
 func opsAlertEmailVariables(rule *OpsAlertRule, event *OpsAlertEvent) map[string]string {
 	variables := map[string]string{
 		"rule_name":         "-",
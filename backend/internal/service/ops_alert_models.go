@@ -31,6 +31,9 @@ type OpsAlertRule struct {
 
 	NotifyEmail bool `json:"notify_email"`
 
+	// NotifyChannels chat/webhook notification channels; empty means email only.
+	NotifyChannels []OpsAlertNotifyChannel `json:"notify_channels,omitempty"`
+
 	Filters map[string]any `json:"filters,omitempty"`
 
 	LastTriggeredAt *time.Time `json:"last_triggered_at,omitempty"`
@@ -55,8 +58,77 @@ type OpsAlertEvent struct {
 	FiredAt    time.Time  `json:"fired_at"`
 	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
 
-	EmailSent bool      `json:"email_sent"`
-	CreatedAt time.Time `json:"created_at"`
+	EmailSent bool `json:"email_sent"`
+	// ChannelDeliveries per-channel delivery records for firing/resolved notifications.
+	ChannelDeliveries []OpsAlertChannelDelivery `json:"channel_deliveries,omitempty"`
+	CreatedAt         time.Time                 `json:"created_at"`
+}
+
+const (
+	OpsAlertChannelWebhook  = "webhook"
+	OpsAlertChannelSlack    = "slack"
+	OpsAlertChannelTelegram = "telegram"
+	OpsAlertChannelFeishu   = "feishu"
+	OpsAlertChannelDingTalk = "dingtalk"
+)
+
+const (
+	OpsAlertNotifyKindFiring   = "firing"
+	OpsAlertNotifyKindResolved = "resolved"
+	OpsAlertNotifyKindTest     = "test"
+
+	OpsAlertDeliveryStatusSent   = "sent"
+	OpsAlertDeliveryStatusFailed = "failed"
+)
+
+// OpsAlertNotifyChannel is a per-rule chat/webhook notification target.
+//
+// Field usage by type:
+//   - webhook:  URL (required), Secret (optional HMAC-SHA256 signing key)
+//   - slack:    URL (incoming webhook)
+//   - telegram: BotToken + ChatID; URL optionally overrides the Bot API base
+//   - feishu:   URL (custom bot webhook), Secret (optional signature key)
+//   - dingtalk: URL (robot webhook with access_token), Secret (optional "SEC..." sign key)
+//
+// Templates use text/template syntax over the same variables as alert emails
+// (e.g. {{.rule_name}}, {{.severity}}, {{.metric_value}}); empty uses the built-in text.
+//
+// Secret and BotToken are write-only: API responses mask them, and an empty value
+// on update keeps the stored one for the same target (type + URL + ChatID).
+type OpsAlertNotifyChannel struct {
+	Type    string `json:"type"`
+	Name    string `json:"name,omitempty"`
+	Enabled *bool  `json:"enabled,omitempty"`
+
+	URL      string `json:"url,omitempty"`
+	Secret   string `json:"secret,omitempty"`
+	BotToken string `json:"bot_token,omitempty"`
+	ChatID   string `json:"chat_id,omitempty"`
+
+	// SecretConfigured / BotTokenConfigured 仅用于 API 输出：密钥只写不读，
+	// 列表与详情中以这两个标记代替明文。
+	SecretConfigured   bool `json:"secret_configured,omitempty"`
+	BotTokenConfigured bool `json:"bot_token_configured,omitempty"`
+
+	FiringTemplate   string `json:"firing_template,omitempty"`
+	ResolvedTemplate string `json:"resolved_template,omitempty"`
+}
+
+// IsEnabled reports whether the channel should receive notifications (default true).
+func (c OpsAlertNotifyChannel) IsEnabled() bool {
+	return c.Enabled == nil || *c.Enabled
+}
+
+// OpsAlertChannelDelivery records the outcome of one notification to one channel.
+type OpsAlertChannelDelivery struct {
+	Channel     string    `json:"channel"`
+	Type        string    `json:"type"`
+	Kind        string    `json:"kind"`
+	Status      string    `json:"status"`
+	Attempts    int       `json:"attempts"`
+	StatusCode  int       `json:"status_code,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	DeliveredAt time.Time `json:"delivered_at"`
 }
 
 type OpsAlertSilence struct {
@@ -30,6 +30,11 @@ func (s *OpsService) CreateAlertRule(ctx context.Context, rule *OpsAlertRule) (*
 	if rule == nil {
 		return nil, infraerrors.BadRequest("INVALID_RULE", "invalid rule")
 	}
+	channels, err := normalizeOpsAlertNotifyChannels(s.cfg, rule.NotifyChannels)
+	if err != nil {
+		return nil, infraerrors.BadRequest("INVALID_NOTIFY_CHANNELS", err.Error())
+	}
+	rule.NotifyChannels = channels
 
 	created, err := s.opsRepo.CreateAlertRule(ctx, rule)
 	if err != nil {
@@ -48,6 +53,18 @@ func (s *OpsService) UpdateAlertRule(ctx context.Context, rule *OpsAlertRule) (*
 	if rule == nil || rule.ID <= 0 {
 		return nil, infraerrors.BadRequest("INVALID_RULE", "invalid rule")
 	}
+	if len(rule.NotifyChannels) > 0 {
+		existing, err := s.getAlertRule(ctx, rule.ID)
+		if err != nil {
+			return nil, err
+		}
+		fillOpsAlertChannelSecrets(rule.NotifyChannels, existing.NotifyChannels)
+	}
+	channels, err := normalizeOpsAlertNotifyChannels(s.cfg, rule.NotifyChannels)
+	if err != nil {
+		return nil, infraerrors.BadRequest("INVALID_NOTIFY_CHANNELS", err.Error())
+	}
+	rule.NotifyChannels = channels
 
 	updated, err := s.opsRepo.UpdateAlertRule(ctx, rule)
 	if err != nil {
@@ -59,6 +76,20 @@ func (s *OpsService) UpdateAlertRule(ctx context.Context, rule *OpsAlertRule) (*
 	return updated, nil
 }
 
+// getAlertRule 按 ID 读取规则（仓储层没有单条查询，规则数量很少，直接遍历列表）。
+func (s *OpsService) getAlertRule(ctx context.Context, id int64) (*OpsAlertRule, error) {
+	rules, err := s.opsRepo.ListAlertRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rules {
+		if r != nil && r.ID == id {
+			return r, nil
+		}
+	}
+	return nil, infraerrors.NotFound("OPS_ALERT_RULE_NOT_FOUND", "alert rule not found")
+}
+
 func (s *OpsService) DeleteAlertRule(ctx context.Context, id int64) error {
 	if err := s.RequireMonitoringEnabled(ctx); err != nil {
 		return err
@@ -78,6 +109,57 @@ func (s *OpsService) DeleteAlertRule(ctx context.Context, id int64) error {
 	return nil
 }
 
+// TestAlertChannel 向单个渠道发送一条测试通知，用于保存规则前验证配置。
+// ruleID > 0 时使用该规则渲染消息，否则使用示例规则。投递失败不视为接口错误，
+// 结果（含错误信息）通过返回的投递记录体现。
+func (s *OpsService) TestAlertChannel(ctx context.Context, channel OpsAlertNotifyChannel, ruleID int64) (*OpsAlertChannelDelivery, error) {
+	if err := s.RequireMonitoringEnabled(ctx); err != nil {
+		return nil, err
+	}
+	rule := &OpsAlertRule{
+		Name:       "Test alert",
+		Severity:   "P2",
+		MetricType: "error_rate",
+		Operator:   ">",
+		Threshold:  5,
+	}
+	if ruleID > 0 {
+		if s.opsRepo == nil {
+			return nil, infraerrors.ServiceUnavailable("OPS_REPO_UNAVAILABLE", "Ops repository not available")
+		}
+		stored, err := s.getAlertRule(ctx, ruleID)
+		if err != nil {
+			return nil, err
+		}
+		rule = stored
+		channels := []OpsAlertNotifyChannel{channel}
+		fillOpsAlertChannelSecrets(channels, rule.NotifyChannels)
+		channel = channels[0]
+	}
+	normalized, err := normalizeOpsAlertNotifyChannel(s.cfg, channel)
+	if err != nil {
+		return nil, infraerrors.BadRequest("INVALID_NOTIFY_CHANNEL", err.Error())
+	}
+
+	now := time.Now().UTC()
+	event := &OpsAlertEvent{
+		RuleID:         rule.ID,
+		Severity:       rule.Severity,
+		Status:         OpsAlertStatusFiring,
+		Title:          rule.Severity + ": " + rule.Name,
+		Description:    "This is a test notification from sub2api ops alerts.",
+		MetricValue:    float64Ptr(rule.Threshold),
+		ThresholdValue: float64Ptr(rule.Threshold),
+		FiredAt:        now,
+		CreatedAt:      now,
+	}
+	// 测试请求同步返回，不做重试，避免管理端长时间等待。
+	notifier := newOpsAlertChannelNotifier(s.cfg)
+	notifier.backoff = nil
+	delivery := notifier.Deliver(ctx, normalized, OpsAlertNotifyKindTest, rule, event)
+	return &delivery, nil
+}
+
 func (s *OpsService) ListAlertEvents(ctx context.Context, filter *OpsAlertEventFilter) ([]*OpsAlertEvent, error) {
 	if err := s.RequireMonitoringEnabled(ctx); err != nil {
 		return nil, err
@@ -49,6 +49,9 @@ type OpsRepository interface {
 	CreateAlertEvent(ctx context.Context, event *OpsAlertEvent) (*OpsAlertEvent, error)
 	UpdateAlertEventStatus(ctx context.Context, eventID int64, status string, resolvedAt *time.Time) error
 	UpdateAlertEventEmailSent(ctx context.Context, eventID int64, emailSent bool) error
+	AppendAlertEventChannelDeliveries(ctx context.Context, eventID int64, deliveries []OpsAlertChannelDelivery) error
+	// CountStuckBatchSettlements counts message/OpenAI batches parked in settlement_stuck with their hold still frozen.
+	CountStuckBatchSettlements(ctx context.Context) (int64, error)
 
 	// Alert silences
 	CreateAlertSilence(ctx context.Context, input *OpsAlertSilence) (*OpsAlertSilence, error)
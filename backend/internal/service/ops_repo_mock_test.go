@@ -165,6 +165,14 @@ func (m *opsRepoMock) UpdateAlertEventEmailSent(ctx context.Context, eventID int
 	return nil
 }
 
+func (m *opsRepoMock) AppendAlertEventChannelDeliveries(ctx context.Context, eventID int64, deliveries []OpsAlertChannelDelivery) error {
+	return nil
+}
+
+func (m *opsRepoMock) CountStuckBatchSettlements(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
 func (m *opsRepoMock) CreateAlertSilence(ctx context.Context, input *OpsAlertSilence) (*OpsAlertSilence, error) {
 	return input, nil
 }
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+)
+
+// 组织成员角色：owner 唯一且不可移除；owner/admin 可管理成员、额度与钱包转入。
+const (
+	OrganizationRoleOwner  = "owner"
+	OrganizationRoleAdmin  = "admin"
+	OrganizationRoleMember = "member"
+)
+
+const (
+	OrganizationStatusActive   = "active"
+	OrganizationStatusDisabled = "disabled"
+)
+
+// 组织邀请状态：只有被邀请用户接受 pending 邀请后才会成为成员。
+const (
+	OrganizationInvitationPending  = "pending"
+	OrganizationInvitationAccepted = "accepted"
+	OrganizationInvitationDeclined = "declined"
+	OrganizationInvitationRevoked  = "revoked"
+)
+
+var (
+	ErrOrganizationNotFound             = infraerrors.New(http.StatusNotFound, "ORGANIZATION_NOT_FOUND", "organization not found")
+	ErrOrganizationMemberNotFound       = infraerrors.New(http.StatusNotFound, "ORGANIZATION_MEMBER_NOT_FOUND", "organization member not found")
+	ErrOrganizationMemberExists         = infraerrors.New(http.StatusConflict, "ORGANIZATION_MEMBER_EXISTS", "user already belongs to an organization")
+	ErrOrganizationForbidden            = infraerrors.New(http.StatusForbidden, "ORGANIZATION_FORBIDDEN", "insufficient organization role")
+	ErrOrganizationInvalidName          = infraerrors.New(http.StatusBadRequest, "ORGANIZATION_INVALID_NAME", "organization name must be 1-100 characters")
+	ErrOrganizationInvalidRole          = infraerrors.New(http.StatusBadRequest, "ORGANIZATION_INVALID_ROLE", "role must be admin or member")
+	ErrOrganizationInvalidStatus        = infraerrors.New(http.StatusBadRequest, "ORGANIZATION_INVALID_STATUS", "status must be active or disabled")
+	ErrOrganizationInvalidSpendCap      = infraerrors.New(http.StatusBadRequest, "ORGANIZATION_INVALID_SPEND_CAP", "monthly spend cap must be >= 0")
+	ErrOrganizationInvalidAmount        = infraerrors.New(http.StatusBadRequest, "ORGANIZATION_INVALID_AMOUNT", "amount must be greater than 0")
+	ErrOrganizationOwnerImmutable       = infraerrors.New(http.StatusBadRequest, "ORGANIZATION_OWNER_IMMUTABLE", "organization owner cannot be removed or re-assigned")
+	ErrOrganizationInsufficientBalance  = infraerrors.New(http.StatusBadRequest, "ORGANIZATION_INSUFFICIENT_BALANCE", "insufficient balance")
+	ErrOrganizationSpendCapExceeded     = infraerrors.New(http.StatusForbidden, "ORGANIZATION_SPEND_CAP_EXCEEDED", "monthly organization spend cap exceeded")
+	ErrOrganizationGroupNotSubscription = infraerrors.New(http.StatusBadRequest, "ORGANIZATION_GROUP_NOT_SUBSCRIPTION", "organization subscriptions require a subscription-type group")
+	ErrOrganizationSubscriptionNotFound = infraerrors.New(http.StatusNotFound, "ORGANIZATION_SUBSCRIPTION_NOT_FOUND", "organization subscription not found")
+	ErrOrganizationInvitationNotFound   = infraerrors.New(http.StatusNotFound, "ORGANIZATION_INVITATION_NOT_FOUND", "organization invitation not found")
+	ErrOrganizationInvitationExists     = infraerrors.New(http.StatusConflict, "ORGANIZATION_INVITATION_EXISTS", "user already has a pending invitation to this organization")
+)
+
+// Organization 组织：成员共享钱包余额与组织级分组订阅。
+type Organization struct {
+	ID          int64
+	Name        string
+	OwnerUserID int64
+	OwnerEmail  string
+	Balance     float64
+	Status      string
+	MemberCount int
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (o *Organization) IsActive() bool {
+	return o != nil && o.Status == OrganizationStatusActive
+}
+
+// OrganizationMember 组织成员。MonthlySpendCapUSD 为 nil 表示不限额；
+// MonthlySpentUSD 是 SpendMonthStart 所在自然月的钱包消费，跨月后按 0 计。
+type OrganizationMember struct {
+	ID                 int64
+	OrganizationID     int64
+	UserID             int64
+	Email              string
+	Username           string
+	Role               string
+	MonthlySpendCapUSD *float64
+	MonthlySpentUSD    float64
+	SpendMonthStart    time.Time
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+// CanManage 是否可管理组织（成员、额度、钱包转入）。
+func (m *OrganizationMember) CanManage() bool {
+	return m != nil && (m.Role == OrganizationRoleOwner || m.Role == OrganizationRoleAdmin)
+}
+
+// CurrentMonthSpent 返回 now 所在自然月（UTC）的已用额度；记录停留在上个月时视为 0。
+func (m *OrganizationMember) CurrentMonthSpent(now time.Time) float64 {
+	if m == nil || m.SpendMonthStart.Before(organizationMonthStart(now)) {
+		return 0
+	}
+	return m.MonthlySpentUSD
+}
+
+func organizationMonthStart(now time.Time) time.Time {
+	now = now.UTC()
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// OrganizationInvitation 组织邀请；接受时按 Role / MonthlySpendCapUSD 写入成员。
+type OrganizationInvitation struct {
+	ID                 int64
+	OrganizationID     int64
+	OrganizationName   string
+	UserID             int64
+	Email              string
+	InvitedByUserID    int64
+	InvitedByEmail     string
+	Role               string
+	MonthlySpendCapUSD *float64
+	Status             string
+	RespondedAt        *time.Time
+	CreatedAt          time.Time
+}
+
+// OrganizationWallet 成员计费时使用的组织钱包视图（仅 active 组织）。
+type OrganizationWallet struct {
+	OrganizationID     int64
+	Balance            float64
+	MonthlySpendCapUSD *float64
+	MonthlySpentUSD    float64
+}
+
+// CapExceeded 成员当月额度是否已用尽。
+func (w *OrganizationWallet) CapExceeded() bool {
+	return w != nil && w.MonthlySpendCapUSD != nil && w.MonthlySpentUSD >= *w.MonthlySpendCapUSD
+}
+
+// OrganizationSubscription 组织级分组订阅：订阅记录挂在 owner 名下，成员共享其额度窗口。
+type OrganizationSubscription struct {
+	OrganizationID  int64
+	GroupID         int64
+	GroupName       string
+	SubscriptionID  int64
+	Status          string
+	ExpiresAt       time.Time
+	DailyUsageUSD   float64
+	WeeklyUsageUSD  float64
+	MonthlyUsageUSD float64
+	CreatedAt       time.Time
+}
+
+// OrganizationRepository 组织持久化。
+type OrganizationRepository interface {
+	// Create 创建组织并把 owner 写为成员；owner 已属于其他组织时返回 ErrOrganizationMemberExists。
+	Create(ctx context.Context, org *Organization) error
+	GetByID(ctx context.Context, id int64) (*Organization, error)
+	List(ctx context.Context, params pagination.PaginationParams, search string) ([]Organization, *pagination.PaginationResult, error)
+	Update(ctx context.Context, org *Organization) error
+	Delete(ctx context.Context, id int64) error
+	// AdjustBalance 调整钱包余额，返回调整后的余额。
+	AdjustBalance(ctx context.Context, id int64, delta float64) (float64, error)
+	// TransferFromUser 从用户个人余额划转到组织钱包，余额不足返回 ErrOrganizationInsufficientBalance。
+	TransferFromUser(ctx context.Context, id, userID int64, amount float64) (orgBalance float64, err error)
+
+	GetMemberByUserID(ctx context.Context, userID int64) (*OrganizationMember, error)
+	ListMembers(ctx context.Context, organizationID int64) ([]OrganizationMember, error)
+	// AddMember 直接写入成员，仅供管理员后台与接受邀请使用。
+	AddMember(ctx context.Context, member *OrganizationMember) error
+	UpdateMember(ctx context.Context, member *OrganizationMember) error
+	RemoveMember(ctx context.Context, organizationID, userID int64) error
+	// CreateInvitation 创建 pending 邀请；同一组织对同一用户已有 pending 邀请时返回 ErrOrganizationInvitationExists。
+	CreateInvitation(ctx context.Context, invitation *OrganizationInvitation) error
+	GetInvitation(ctx context.Context, id int64) (*OrganizationInvitation, error)
+	// ListInvitations 返回组织的 pending 邀请。
+	ListInvitations(ctx context.Context, organizationID int64) ([]OrganizationInvitation, error)
+	// ListInvitationsForUser 返回用户收到的、组织仍 active 的 pending 邀请。
+	ListInvitationsForUser(ctx context.Context, userID int64) ([]OrganizationInvitation, error)
+	// AcceptInvitation 在同一事务内把 pending 邀请标记为 accepted 并写入成员；
+	// 邀请不存在或已处理返回 ErrOrganizationInvitationNotFound，用户已属于组织返回 ErrOrganizationMemberExists。
+	AcceptInvitation(ctx context.Context, id, userID int64) error
+	// CloseInvitation 把 pending 邀请改为 declined / revoked。
+	CloseInvitation(ctx context.Context, id int64, status string) error
+	// GetWalletByUserID 返回用户所属 active 组织的钱包；非成员返回 (nil, nil)。
+	GetWalletByUserID(ctx context.Context, userID int64) (*OrganizationWallet, error)
+	// ChargeMemberWallet 从成员所属 active 组织钱包扣费并累加成员当月消费；非成员返回 charged=false。
+	ChargeMemberWallet(ctx context.Context, userID int64, amount float64) (charged bool, err error)
+
+	UpsertSubscription(ctx context.Context, organizationID, groupID, subscriptionID int64) error
+	ListSubscriptions(ctx context.Context, organizationID int64) ([]OrganizationSubscription, error)
+	// DeleteSubscription 删除映射并返回对应的 user_subscriptions.id。
+	DeleteSubscription(ctx context.Context, organizationID, groupID int64) (int64, error)
+	// GetSubscriptionIDForMember 返回成员所在 active 组织在该分组上的订阅 ID，没有时返回 ErrSubscriptionNotFound。
+	GetSubscriptionIDForMember(ctx context.Context, userID, groupID int64) (int64, error)
+}
+
+// OrganizationWalletReader 鉴权与计费预检读取成员所属组织钱包；非成员返回 (nil, nil)。
+type OrganizationWalletReader interface {
+	GetWalletForUser(ctx context.Context, userID int64) (*OrganizationWallet, error)
+}
+
+// OrganizationWalletCharger 供 legacy 计费路径把成员的余额模式消费记到组织钱包；非成员返回 charged=false。
+type OrganizationWalletCharger interface {
+	ChargeMemberWallet(ctx context.Context, userID int64, amount float64) (bool, error)
+}
+
+// OrganizationSubscriptionResolver 在成员没有个人订阅时解析组织级订阅 ID。
+type OrganizationSubscriptionResolver interface {
+	ResolveOrganizationSubscriptionID(ctx context.Context, userID, groupID int64) (int64, error)
+}
+
+func normalizeOrganizationName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len([]rune(name)) > 100 {
+		return "", ErrOrganizationInvalidName
+	}
+	return name, nil
+}
+
+// normalizeOrganizationMemberRole 非 owner 角色，空值视为 member。
+func normalizeOrganizationMemberRole(role string) (string, error) {
+	switch role {
+	case "":
+		return OrganizationRoleMember, nil
+	case OrganizationRoleAdmin, OrganizationRoleMember:
+		return role, nil
+	default:
+		return "", ErrOrganizationInvalidRole
+	}
+}
+
+func validateOrganizationSpendCap(capUSD *float64) error {
+	if capUSD != nil && *capUSD < 0 {
+		return ErrOrganizationInvalidSpendCap
+	}
+	return nil
+}
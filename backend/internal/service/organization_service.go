@@ -0,0 +1,801 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/usagestats"
+	gocache "github.com/patrickmn/go-cache"
+)
+
+// organizationWalletCacheTTL 钱包视图的进程内缓存时长。计费在事务内直接扣组织余额，
+// 缓存只影响预检，短 TTL 足以把热路径查询压到每用户每 10 秒一次。
+const organizationWalletCacheTTL = 10 * time.Second
+
+// organizationWalletCacheEntry 缓存值；wallet 为 nil 表示该用户不属于任何 active 组织。
+type organizationWalletCacheEntry struct {
+	wallet *OrganizationWallet
+}
+
+// AddOrganizationMemberInput 添加或邀请成员；UserID 与 Email 二选一（管理员后台可直接指定用户 ID）。
+type AddOrganizationMemberInput struct {
+	UserID             int64
+	Email              string
+	Role               string
+	MonthlySpendCapUSD *float64
+}
+
+// UpdateOrganizationMemberInput 修改成员角色与月度额度；ClearSpendCap 为 true 时取消限额。
+type UpdateOrganizationMemberInput struct {
+	Role               *string
+	MonthlySpendCapUSD *float64
+	ClearSpendCap      bool
+}
+
+// UpdateOrganizationInput 管理员修改组织。
+type UpdateOrganizationInput struct {
+	Name   *string
+	Status *string
+}
+
+// AssignOrganizationSubscriptionInput 为组织开通分组订阅。
+type AssignOrganizationSubscriptionInput struct {
+	GroupID      int64
+	ValidityDays int
+	AssignedBy   int64
+}
+
+// OrganizationMemberUsage 组织看板中的单个成员用量。
+type OrganizationMemberUsage struct {
+	UserID             int64
+	Email              string
+	Role               string
+	TodayActualCost    float64
+	RangeActualCost    float64
+	MonthlySpentUSD    float64
+	MonthlySpendCapUSD *float64
+}
+
+// OrganizationUsageDashboard 组织用量看板，复用按用户聚合的用量查询并按当前成员合并。
+type OrganizationUsageDashboard struct {
+	OrganizationID int64
+	StartTime      time.Time
+	EndTime        time.Time
+	Requests       int64
+	TotalTokens    int64
+	Cost           float64
+	ActualCost     float64
+	Trend          []usagestats.TrendDataPoint
+	Models         []usagestats.ModelStat
+	Members        []OrganizationMemberUsage
+}
+
+// OrganizationService 组织管理、共享钱包与组织级订阅。
+type OrganizationService struct {
+	repo                 OrganizationRepository
+	userRepo             UserRepository
+	groupRepo            GroupRepository
+	usageRepo            UsageLogRepository
+	subscriptionService  *SubscriptionService
+	billingCacheService  *BillingCacheService
+	authCacheInvalidator APIKeyAuthCacheInvalidator
+	walletCache          *gocache.Cache
+}
+
+func NewOrganizationService(
+	repo OrganizationRepository,
+	userRepo UserRepository,
+	groupRepo GroupRepository,
+	usageRepo UsageLogRepository,
+	subscriptionService *SubscriptionService,
+	billingCacheService *BillingCacheService,
+	authCacheInvalidator APIKeyAuthCacheInvalidator,
+) *OrganizationService {
+	return &OrganizationService{
+		repo:                 repo,
+		userRepo:             userRepo,
+		groupRepo:            groupRepo,
+		usageRepo:            usageRepo,
+		subscriptionService:  subscriptionService,
+		billingCacheService:  billingCacheService,
+		authCacheInvalidator: authCacheInvalidator,
+		walletCache:          gocache.New(organizationWalletCacheTTL, time.Minute),
+	}
+}
+
+// ---- 钱包与订阅解析（鉴权 / 计费热路径） ----
+
+// GetWalletForUser 返回用户所属 active 组织的钱包视图，非成员返回 (nil, nil)。
+func (s *OrganizationService) GetWalletForUser(ctx context.Context, userID int64) (*OrganizationWallet, error) {
+	if s == nil || userID <= 0 {
+		return nil, nil
+	}
+	key := strconv.FormatInt(userID, 10)
+	if cached, ok := s.walletCache.Get(key); ok {
+		if entry, ok := cached.(organizationWalletCacheEntry); ok {
+			return entry.wallet, nil
+		}
+	}
+	wallet, err := s.repo.GetWalletByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	s.walletCache.Set(key, organizationWalletCacheEntry{wallet: wallet}, gocache.DefaultExpiration)
+	return wallet, nil
+}
+
+// ChargeMemberWallet 实现 OrganizationWalletCharger。
+func (s *OrganizationService) ChargeMemberWallet(ctx context.Context, userID int64, amount float64) (bool, error) {
+	return s.repo.ChargeMemberWallet(ctx, userID, amount)
+}
+
+// ResolveOrganizationSubscriptionID 实现 OrganizationSubscriptionResolver。
+func (s *OrganizationService) ResolveOrganizationSubscriptionID(ctx context.Context, userID, groupID int64) (int64, error) {
+	return s.repo.GetSubscriptionIDForMember(ctx, userID, groupID)
+}
+
+func (s *OrganizationService) invalidateWallet(userID int64) {
+	s.walletCache.Delete(strconv.FormatInt(userID, 10))
+}
+
+// invalidateAllWallets 组织级变更（余额、状态、删除）影响全部成员，直接清空本地缓存。
+func (s *OrganizationService) invalidateAllWallets() {
+	s.walletCache.Flush()
+}
+
+// invalidateMemberSubscriptions 成员加入/离开或组织订阅变化后清理成员的订阅 L1 缓存。
+func (s *OrganizationService) invalidateMemberSubscriptions(ctx context.Context, organizationID int64, userIDs []int64) {
+	if s.subscriptionService == nil || len(userIDs) == 0 {
+		return
+	}
+	subs, err := s.repo.ListSubscriptions(ctx, organizationID)
+	if err != nil {
+		logger.LegacyPrintf("service.organization", "Warning: list organization subscriptions failed: org=%d err=%v", organizationID, err)
+		return
+	}
+	for _, sub := range subs {
+		for _, userID := range userIDs {
+			s.subscriptionService.InvalidateSubCache(userID, sub.GroupID)
+		}
+	}
+}
+
+// ---- 用户侧 ----
+
+// CreateOrganization 用户创建组织并成为 owner。
+func (s *OrganizationService) CreateOrganization(ctx context.Context, ownerUserID int64, name string) (*Organization, error) {
+	name, err := normalizeOrganizationName(name)
+	if err != nil {
+		return nil, err
+	}
+	org := &Organization{Name: name, OwnerUserID: ownerUserID, Status: OrganizationStatusActive}
+	if err := s.repo.Create(ctx, org); err != nil {
+		return nil, err
+	}
+	s.invalidateWallet(ownerUserID)
+	return s.repo.GetByID(ctx, org.ID)
+}
+
+// GetMyOrganization 返回用户所属组织及其成员身份。
+func (s *OrganizationService) GetMyOrganization(ctx context.Context, userID int64) (*Organization, *OrganizationMember, error) {
+	member, err := s.repo.GetMemberByUserID(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	org, err := s.repo.GetByID(ctx, member.OrganizationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return org, member, nil
+}
+
+// RenameMyOrganization 仅 owner 可改名。
+func (s *OrganizationService) RenameMyOrganization(ctx context.Context, actorUserID int64, name string) (*Organization, error) {
+	actor, err := s.requireMember(ctx, actorUserID, true)
+	if err != nil {
+		return nil, err
+	}
+	if actor.Role != OrganizationRoleOwner {
+		return nil, ErrOrganizationForbidden
+	}
+	return s.UpdateOrganization(ctx, actor.OrganizationID, &UpdateOrganizationInput{Name: &name})
+}
+
+// ListMyMembers 任意成员可查看成员列表。
+func (s *OrganizationService) ListMyMembers(ctx context.Context, actorUserID int64) ([]OrganizationMember, error) {
+	actor, err := s.requireMember(ctx, actorUserID, false)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListMembers(ctx, actor.OrganizationID)
+}
+
+// InviteMyMember owner/admin 按邮箱邀请用户；用户接受后才成为成员，仅 owner 可邀请 admin。
+func (s *OrganizationService) InviteMyMember(ctx context.Context, actorUserID int64, input *AddOrganizationMemberInput) (*OrganizationInvitation, error) {
+	actor, err := s.requireMember(ctx, actorUserID, true)
+	if err != nil {
+		return nil, err
+	}
+	role, err := normalizeOrganizationMemberRole(input.Role)
+	if err != nil {
+		return nil, err
+	}
+	if role == OrganizationRoleAdmin && actor.Role != OrganizationRoleOwner {
+		return nil, ErrOrganizationForbidden
+	}
+	if err := validateOrganizationSpendCap(input.MonthlySpendCapUSD); err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.GetByEmail(ctx, strings.TrimSpace(input.Email))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.GetMemberByUserID(ctx, user.ID); err == nil {
+		return nil, ErrOrganizationMemberExists
+	} else if !errors.Is(err, ErrOrganizationMemberNotFound) {
+		return nil, err
+	}
+
+	invitation := &OrganizationInvitation{
+		OrganizationID:     actor.OrganizationID,
+		UserID:             user.ID,
+		InvitedByUserID:    actorUserID,
+		Role:               role,
+		MonthlySpendCapUSD: input.MonthlySpendCapUSD,
+		Status:             OrganizationInvitationPending,
+	}
+	if err := s.repo.CreateInvitation(ctx, invitation); err != nil {
+		return nil, err
+	}
+	return s.repo.GetInvitation(ctx, invitation.ID)
+}
+
+// ListMyInvitations owner/admin 查看组织发出的 pending 邀请。
+func (s *OrganizationService) ListMyInvitations(ctx context.Context, actorUserID int64) ([]OrganizationInvitation, error) {
+	actor, err := s.requireMember(ctx, actorUserID, true)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListInvitations(ctx, actor.OrganizationID)
+}
+
+// RevokeMyInvitation owner/admin 撤回 pending 邀请；admin 邀请仅 owner 可撤回。
+func (s *OrganizationService) RevokeMyInvitation(ctx context.Context, actorUserID, invitationID int64) error {
+	actor, err := s.requireMember(ctx, actorUserID, true)
+	if err != nil {
+		return err
+	}
+	invitation, err := s.repo.GetInvitation(ctx, invitationID)
+	if err != nil {
+		return err
+	}
+	if invitation.OrganizationID != actor.OrganizationID {
+		return ErrOrganizationInvitationNotFound
+	}
+	if invitation.Role == OrganizationRoleAdmin && actor.Role != OrganizationRoleOwner {
+		return ErrOrganizationForbidden
+	}
+	return s.repo.CloseInvitation(ctx, invitationID, OrganizationInvitationRevoked)
+}
+
+// ListReceivedInvitations 返回当前用户收到的 pending 邀请。
+func (s *OrganizationService) ListReceivedInvitations(ctx context.Context, userID int64) ([]OrganizationInvitation, error) {
+	return s.repo.ListInvitationsForUser(ctx, userID)
+}
+
+// AcceptInvitation 被邀请用户接受邀请并加入组织。
+func (s *OrganizationService) AcceptInvitation(ctx context.Context, userID, invitationID int64) (*Organization, *OrganizationMember, error) {
+	invitation, err := s.getReceivedInvitation(ctx, userID, invitationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.repo.AcceptInvitation(ctx, invitationID, userID); err != nil {
+		return nil, nil, err
+	}
+	s.invalidateWallet(userID)
+	s.invalidateMemberSubscriptions(ctx, invitation.OrganizationID, []int64{userID})
+	return s.GetMyOrganization(ctx, userID)
+}
+
+// DeclineInvitation 被邀请用户拒绝邀请。
+func (s *OrganizationService) DeclineInvitation(ctx context.Context, userID, invitationID int64) error {
+	if _, err := s.getReceivedInvitation(ctx, userID, invitationID); err != nil {
+		return err
+	}
+	return s.repo.CloseInvitation(ctx, invitationID, OrganizationInvitationDeclined)
+}
+
+func (s *OrganizationService) getReceivedInvitation(ctx context.Context, userID, invitationID int64) (*OrganizationInvitation, error) {
+	invitation, err := s.repo.GetInvitation(ctx, invitationID)
+	if err != nil {
+		return nil, err
+	}
+	if invitation.UserID != userID || invitation.Status != OrganizationInvitationPending {
+		return nil, ErrOrganizationInvitationNotFound
+	}
+	return invitation, nil
+}
+
+// UpdateMyMember owner/admin 修改成员额度；角色变更与修改 admin 仅 owner 可做。
+func (s *OrganizationService) UpdateMyMember(ctx context.Context, actorUserID, memberUserID int64, input *UpdateOrganizationMemberInput) (*OrganizationMember, error) {
+	actor, err := s.requireMember(ctx, actorUserID, true)
+	if err != nil {
+		return nil, err
+	}
+	if actor.Role != OrganizationRoleOwner {
+		if input != nil && input.Role != nil {
+			return nil, ErrOrganizationForbidden
+		}
+		target, err := s.getMemberOf(ctx, actor.OrganizationID, memberUserID)
+		if err != nil {
+			return nil, err
+		}
+		if target.Role != OrganizationRoleMember {
+			return nil, ErrOrganizationForbidden
+		}
+	}
+	return s.UpdateMember(ctx, actor.OrganizationID, memberUserID, input)
+}
+
+// RemoveMyMember owner/admin 移除成员；普通成员只能移除自己（退出组织）。
+func (s *OrganizationService) RemoveMyMember(ctx context.Context, actorUserID, memberUserID int64) error {
+	actor, err := s.requireMember(ctx, actorUserID, false)
+	if err != nil {
+		return err
+	}
+	if actorUserID != memberUserID {
+		if !actor.CanManage() {
+			return ErrOrganizationForbidden
+		}
+		if actor.Role != OrganizationRoleOwner {
+			target, err := s.getMemberOf(ctx, actor.OrganizationID, memberUserID)
+			if err != nil {
+				return err
+			}
+			if target.Role != OrganizationRoleMember {
+				return ErrOrganizationForbidden
+			}
+		}
+	}
+	return s.RemoveMember(ctx, actor.OrganizationID, memberUserID)
+}
+
+// TransferToMyWallet owner/admin 从个人余额划转到组织钱包。
+func (s *OrganizationService) TransferToMyWallet(ctx context.Context, actorUserID int64, amount float64) (*Organization, error) {
+	actor, err := s.requireMember(ctx, actorUserID, true)
+	if err != nil {
+		return nil, err
+	}
+	amount = QuantizeUsageBillingAmount(amount)
+	if amount <= 0 {
+		return nil, ErrOrganizationInvalidAmount
+	}
+	if _, err := s.repo.TransferFromUser(ctx, actor.OrganizationID, actorUserID, amount); err != nil {
+		return nil, err
+	}
+	s.invalidateAllWallets()
+	if s.billingCacheService != nil {
+		if err := s.billingCacheService.InvalidateUserBalance(ctx, actorUserID); err != nil {
+			logger.LegacyPrintf("service.organization", "Warning: invalidate balance cache failed: user=%d err=%v", actorUserID, err)
+		}
+	}
+	if s.authCacheInvalidator != nil {
+		s.authCacheInvalidator.InvalidateAuthCacheByUserID(ctx, actorUserID)
+	}
+	return s.repo.GetByID(ctx, actor.OrganizationID)
+}
+
+// ListMySubscriptions 任意成员可查看组织订阅。
+func (s *OrganizationService) ListMySubscriptions(ctx context.Context, actorUserID int64) ([]OrganizationSubscription, error) {
+	actor, err := s.requireMember(ctx, actorUserID, false)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListSubscriptions(ctx, actor.OrganizationID)
+}
+
+// GetMyUsageDashboard owner/admin 查看组织用量看板。
+func (s *OrganizationService) GetMyUsageDashboard(ctx context.Context, actorUserID int64, startTime, endTime time.Time, granularity string) (*OrganizationUsageDashboard, error) {
+	actor, err := s.requireMember(ctx, actorUserID, true)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetUsageDashboard(ctx, actor.OrganizationID, startTime, endTime, granularity)
+}
+
+func (s *OrganizationService) requireMember(ctx context.Context, userID int64, manage bool) (*OrganizationMember, error) {
+	member, err := s.repo.GetMemberByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if manage && !member.CanManage() {
+		return nil, ErrOrganizationForbidden
+	}
+	return member, nil
+}
+
+func (s *OrganizationService) getMemberOf(ctx context.Context, organizationID, userID int64) (*OrganizationMember, error) {
+	member, err := s.repo.GetMemberByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member.OrganizationID != organizationID {
+		return nil, ErrOrganizationMemberNotFound
+	}
+	return member, nil
+}
+
+// ---- 管理员侧（同时被用户侧复用） ----
+
+func (s *OrganizationService) List(ctx context.Context, params pagination.PaginationParams, search string) ([]Organization, *pagination.PaginationResult, error) {
+	return s.repo.List(ctx, params, strings.TrimSpace(search))
+}
+
+func (s *OrganizationService) GetByID(ctx context.Context, id int64) (*Organization, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// UpdateOrganization 修改名称或状态；停用后成员回落到个人余额计费。
+func (s *OrganizationService) UpdateOrganization(ctx context.Context, id int64, input *UpdateOrganizationInput) (*Organization, error) {
+	org, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if input != nil && input.Name != nil {
+		name, err := normalizeOrganizationName(*input.Name)
+		if err != nil {
+			return nil, err
+		}
+		org.Name = name
+	}
+	if input != nil && input.Status != nil {
+		switch *input.Status {
+		case OrganizationStatusActive, OrganizationStatusDisabled:
+			org.Status = *input.Status
+		default:
+			return nil, ErrOrganizationInvalidStatus
+		}
+	}
+	if err := s.repo.Update(ctx, org); err != nil {
+		return nil, err
+	}
+	s.invalidateAllWallets()
+	return s.repo.GetByID(ctx, id)
+}
+
+// DeleteOrganization 软删除组织并解除全部成员关系；钱包余额不回退到个人。
+func (s *OrganizationService) DeleteOrganization(ctx context.Context, id int64) error {
+	members, err := s.repo.ListMembers(ctx, id)
+	if err != nil {
+		return err
+	}
+	userIDs := organizationMemberUserIDs(members)
+	s.invalidateMemberSubscriptions(ctx, id, userIDs)
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.invalidateAllWallets()
+	return nil
+}
+
+// AdjustBalance 管理员充值/扣减组织钱包。
+func (s *OrganizationService) AdjustBalance(ctx context.Context, id int64, delta float64) (*Organization, error) {
+	delta = QuantizeUsageBillingAmount(delta)
+	if delta == 0 {
+		return nil, ErrOrganizationInvalidAmount
+	}
+	if _, err := s.repo.AdjustBalance(ctx, id, delta); err != nil {
+		return nil, err
+	}
+	s.invalidateAllWallets()
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *OrganizationService) ListMembers(ctx context.Context, organizationID int64) ([]OrganizationMember, error) {
+	if _, err := s.repo.GetByID(ctx, organizationID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListMembers(ctx, organizationID)
+}
+
+// AddMember 管理员直接添加成员；一个用户最多属于一个组织。用户侧只能通过邀请加入。
+func (s *OrganizationService) AddMember(ctx context.Context, organizationID int64, input *AddOrganizationMemberInput) (*OrganizationMember, error) {
+	role, err := normalizeOrganizationMemberRole(input.Role)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateOrganizationSpendCap(input.MonthlySpendCapUSD); err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.GetByID(ctx, organizationID); err != nil {
+		return nil, err
+	}
+
+	userID := input.UserID
+	if userID <= 0 {
+		user, err := s.userRepo.GetByEmail(ctx, strings.TrimSpace(input.Email))
+		if err != nil {
+			return nil, err
+		}
+		userID = user.ID
+	} else if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	member := &OrganizationMember{
+		OrganizationID:     organizationID,
+		UserID:             userID,
+		Role:               role,
+		MonthlySpendCapUSD: input.MonthlySpendCapUSD,
+	}
+	if err := s.repo.AddMember(ctx, member); err != nil {
+		return nil, err
+	}
+	s.invalidateWallet(userID)
+	s.invalidateMemberSubscriptions(ctx, organizationID, []int64{userID})
+	return s.getMemberOf(ctx, organizationID, userID)
+}
+
+// UpdateMember 修改成员角色与月度额度；owner 角色不可变更。
+func (s *OrganizationService) UpdateMember(ctx context.Context, organizationID, userID int64, input *UpdateOrganizationMemberInput) (*OrganizationMember, error) {
+	member, err := s.getMemberOf(ctx, organizationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if input == nil {
+		return member, nil
+	}
+	if input.Role != nil && *input.Role != member.Role {
+		if member.Role == OrganizationRoleOwner {
+			return nil, ErrOrganizationOwnerImmutable
+		}
+		if *input.Role != OrganizationRoleAdmin && *input.Role != OrganizationRoleMember {
+			return nil, ErrOrganizationInvalidRole
+		}
+		member.Role = *input.Role
+	}
+	if input.ClearSpendCap {
+		member.MonthlySpendCapUSD = nil
+	} else if input.MonthlySpendCapUSD != nil {
+		if err := validateOrganizationSpendCap(input.MonthlySpendCapUSD); err != nil {
+			return nil, err
+		}
+		member.MonthlySpendCapUSD = input.MonthlySpendCapUSD
+	}
+	if err := s.repo.UpdateMember(ctx, member); err != nil {
+		return nil, err
+	}
+	s.invalidateWallet(userID)
+	return s.getMemberOf(ctx, organizationID, userID)
+}
+
+// RemoveMember 移除成员；owner 不可移除（删除组织代替）。
+func (s *OrganizationService) RemoveMember(ctx context.Context, organizationID, userID int64) error {
+	member, err := s.getMemberOf(ctx, organizationID, userID)
+	if err != nil {
+		return err
+	}
+	if member.Role == OrganizationRoleOwner {
+		return ErrOrganizationOwnerImmutable
+	}
+	if err := s.repo.RemoveMember(ctx, organizationID, userID); err != nil {
+		return err
+	}
+	s.invalidateWallet(userID)
+	s.invalidateMemberSubscriptions(ctx, organizationID, []int64{userID})
+	return nil
+}
+
+func (s *OrganizationService) ListSubscriptions(ctx context.Context, organizationID int64) ([]OrganizationSubscription, error) {
+	if _, err := s.repo.GetByID(ctx, organizationID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListSubscriptions(ctx, organizationID)
+}
+
+// AssignSubscription 为组织开通（或续期）分组订阅。订阅记录挂在 owner 名下，
+// owner 已有该分组订阅时直接续期并转为组织共享。
+func (s *OrganizationService) AssignSubscription(ctx context.Context, organizationID int64, input *AssignOrganizationSubscriptionInput) (*UserSubscription, error) {
+	org, err := s.repo.GetByID(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	group, err := s.groupRepo.GetByID(ctx, input.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	if !group.IsSubscriptionType() {
+		return nil, ErrOrganizationGroupNotSubscription
+	}
+	sub, _, err := s.subscriptionService.AssignOrExtendSubscription(ctx, &AssignSubscriptionInput{
+		UserID:       org.OwnerUserID,
+		GroupID:      input.GroupID,
+		ValidityDays: input.ValidityDays,
+		AssignedBy:   input.AssignedBy,
+		Notes:        fmt.Sprintf("organization #%d", organizationID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpsertSubscription(ctx, organizationID, input.GroupID, sub.ID); err != nil {
+		return nil, err
+	}
+	if members, err := s.repo.ListMembers(ctx, organizationID); err == nil {
+		for _, m := range members {
+			s.subscriptionService.InvalidateSubCache(m.UserID, input.GroupID)
+		}
+	}
+	return sub, nil
+}
+
+// RemoveSubscription 取消组织共享；订阅本身仍保留在 owner 名下，需要时在订阅管理中撤销。
+func (s *OrganizationService) RemoveSubscription(ctx context.Context, organizationID, groupID int64) error {
+	members, err := s.repo.ListMembers(ctx, organizationID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.repo.DeleteSubscription(ctx, organizationID, groupID); err != nil {
+		return err
+	}
+	for _, m := range members {
+		s.subscriptionService.InvalidateSubCache(m.UserID, groupID)
+	}
+	return nil
+}
+
+// GetUsageDashboard 按当前成员聚合用量：成员明细走批量用户统计，
+// 趋势与模型分布复用单用户聚合查询后合并。
+func (s *OrganizationService) GetUsageDashboard(ctx context.Context, organizationID int64, startTime, endTime time.Time, granularity string) (*OrganizationUsageDashboard, error) {
+	members, err := s.repo.ListMembers(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	if granularity == "" {
+		granularity = "day"
+	}
+	dashboard := &OrganizationUsageDashboard{
+		OrganizationID: organizationID,
+		StartTime:      startTime,
+		EndTime:        endTime,
+		Trend:          []usagestats.TrendDataPoint{},
+		Models:         []usagestats.ModelStat{},
+		Members:        make([]OrganizationMemberUsage, 0, len(members)),
+	}
+	if len(members) == 0 {
+		return dashboard, nil
+	}
+
+	userIDs := organizationMemberUserIDs(members)
+	batch, err := s.usageRepo.GetBatchUserUsageStats(ctx, userIDs, startTime, endTime)
+	if err != nil {
+		return nil, fmt.Errorf("get organization member usage: %w", err)
+	}
+	trends := make([][]usagestats.TrendDataPoint, 0, len(members))
+	models := make([][]usagestats.ModelStat, 0, len(members))
+	now := time.Now()
+	for i := range members {
+		m := &members[i]
+		usage := OrganizationMemberUsage{
+			UserID:             m.UserID,
+			Email:              m.Email,
+			Role:               m.Role,
+			MonthlySpentUSD:    m.CurrentMonthSpent(now),
+			MonthlySpendCapUSD: m.MonthlySpendCapUSD,
+		}
+		if stat := batch[m.UserID]; stat != nil {
+			usage.TodayActualCost = stat.TodayActualCost
+			usage.RangeActualCost = stat.TotalActualCost
+		}
+		dashboard.Members = append(dashboard.Members, usage)
+
+		trend, err := s.usageRepo.GetUserUsageTrendByUserID(ctx, m.UserID, startTime, endTime, granularity)
+		if err != nil {
+			return nil, fmt.Errorf("get organization usage trend: %w", err)
+		}
+		trends = append(trends, trend)
+		modelStats, err := s.usageRepo.GetUserModelStats(ctx, m.UserID, startTime, endTime)
+		if err != nil {
+			return nil, fmt.Errorf("get organization model stats: %w", err)
+		}
+		models = append(models, modelStats)
+	}
+	sort.SliceStable(dashboard.Members, func(i, j int) bool {
+		return dashboard.Members[i].RangeActualCost > dashboard.Members[j].RangeActualCost
+	})
+
+	dashboard.Trend = mergeOrganizationTrend(trends)
+	dashboard.Models = mergeOrganizationModelStats(models)
+	for _, point := range dashboard.Trend {
+		dashboard.Requests += point.Requests
+		dashboard.TotalTokens += point.TotalTokens
+		dashboard.Cost += point.Cost
+		dashboard.ActualCost += point.ActualCost
+	}
+	return dashboard, nil
+}
+
+func organizationMemberUserIDs(members []OrganizationMember) []int64 {
+	ids := make([]int64, 0, len(members))
+	for _, m := range members {
+		ids = append(ids, m.UserID)
+	}
+	return ids
+}
+
+func mergeOrganizationTrend(trends [][]usagestats.TrendDataPoint) []usagestats.TrendDataPoint {
+	byDate := make(map[string]*usagestats.TrendDataPoint)
+	for _, trend := range trends {
+		for _, p := range trend {
+			acc, ok := byDate[p.Date]
+			if !ok {
+				acc = &usagestats.TrendDataPoint{Date: p.Date}
+				byDate[p.Date] = acc
+			}
+			acc.Requests += p.Requests
+			acc.InputTokens += p.InputTokens
+			acc.OutputTokens += p.OutputTokens
+			acc.CacheCreationTokens += p.CacheCreationTokens
+			acc.CacheReadTokens += p.CacheReadTokens
+			acc.TotalTokens += p.TotalTokens
+			acc.Cost += p.Cost
+			acc.ActualCost += p.ActualCost
+		}
+	}
+	merged := make([]usagestats.TrendDataPoint, 0, len(byDate))
+	for _, p := range byDate {
+		merged = append(merged, *p)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Date < merged[j].Date })
+	return merged
+}
+
+func mergeOrganizationModelStats(stats [][]usagestats.ModelStat) []usagestats.ModelStat {
+	byModel := make(map[string]*usagestats.ModelStat)
+	for _, list := range stats {
+		for _, m := range list {
+			acc, ok := byModel[m.Model]
+			if !ok {
+				acc = &usagestats.ModelStat{Model: m.Model}
+				byModel[m.Model] = acc
+			}
+			acc.Requests += m.Requests
+			acc.InputTokens += m.InputTokens
+			acc.OutputTokens += m.OutputTokens
+			acc.CacheCreationTokens += m.CacheCreationTokens
+			acc.CacheReadTokens += m.CacheReadTokens
+			acc.TotalTokens += m.TotalTokens
+			acc.Cost += m.Cost
+			acc.ActualCost += m.ActualCost
+			acc.AccountCost += m.AccountCost
+		}
+	}
+	merged := make([]usagestats.ModelStat, 0, len(byModel))
+	for _, m := range byModel {
+		merged = append(merged, *m)
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		if merged[i].ActualCost != merged[j].ActualCost {
+			return merged[i].ActualCost > merged[j].ActualCost
+		}
+		return merged[i].Model < merged[j].Model
+	})
+	return merged
+}
+
+// organizationWalletEligibility 预检成员钱包：余额耗尽或当月额度用尽时拒绝。
+func organizationWalletEligibility(wallet *OrganizationWallet, belowThreshold func(float64) bool) error {
+	if wallet.CapExceeded() {
+		return ErrOrganizationSpendCapExceeded
+	}
+	if belowThreshold(wallet.Balance) {
+		return ErrInsufficientBalance
+	}
+	return nil
+}
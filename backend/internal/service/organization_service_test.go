@@ -0,0 +1,279 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/usagestats"
+	"github.com/stretchr/testify/require"
+)
+
+type organizationRepoStub struct {
+	OrganizationRepository
+
+	members     map[int64]*OrganizationMember
+	wallets     map[int64]*OrganizationWallet
+	invitations map[int64]*OrganizationInvitation
+	walletCalls int
+	removed     []int64
+	updated     []OrganizationMember
+}
+
+func newOrganizationRepoStub(members ...OrganizationMember) *organizationRepoStub {
+	stub := &organizationRepoStub{
+		members:     make(map[int64]*OrganizationMember),
+		wallets:     make(map[int64]*OrganizationWallet),
+		invitations: make(map[int64]*OrganizationInvitation),
+	}
+	for i := range members {
+		m := members[i]
+		stub.members[m.UserID] = &m
+	}
+	return stub
+}
+
+func (s *organizationRepoStub) GetMemberByUserID(_ context.Context, userID int64) (*OrganizationMember, error) {
+	m, ok := s.members[userID]
+	if !ok {
+		return nil, ErrOrganizationMemberNotFound
+	}
+	cp := *m
+	return &cp, nil
+}
+
+func (s *organizationRepoStub) UpdateMember(_ context.Context, member *OrganizationMember) error {
+	s.updated = append(s.updated, *member)
+	cp := *member
+	s.members[member.UserID] = &cp
+	return nil
+}
+
+func (s *organizationRepoStub) RemoveMember(_ context.Context, _ int64, userID int64) error {
+	s.removed = append(s.removed, userID)
+	delete(s.members, userID)
+	return nil
+}
+
+func (s *organizationRepoStub) GetWalletByUserID(_ context.Context, userID int64) (*OrganizationWallet, error) {
+	s.walletCalls++
+	return s.wallets[userID], nil
+}
+
+func (s *organizationRepoStub) CreateInvitation(_ context.Context, invitation *OrganizationInvitation) error {
+	invitation.ID = int64(len(s.invitations) + 1)
+	cp := *invitation
+	s.invitations[invitation.ID] = &cp
+	return nil
+}
+
+func (s *organizationRepoStub) GetInvitation(_ context.Context, id int64) (*OrganizationInvitation, error) {
+	invitation, ok := s.invitations[id]
+	if !ok {
+		return nil, ErrOrganizationInvitationNotFound
+	}
+	cp := *invitation
+	return &cp, nil
+}
+
+func (s *organizationRepoStub) AcceptInvitation(_ context.Context, id, userID int64) error {
+	invitation := s.invitations[id]
+	invitation.Status = OrganizationInvitationAccepted
+	s.members[userID] = &OrganizationMember{OrganizationID: invitation.OrganizationID, UserID: userID, Role: invitation.Role}
+	return nil
+}
+
+func (s *organizationRepoStub) CloseInvitation(_ context.Context, id int64, status string) error {
+	s.invitations[id].Status = status
+	return nil
+}
+
+func (s *organizationRepoStub) GetByID(_ context.Context, id int64) (*Organization, error) {
+	return &Organization{ID: id}, nil
+}
+
+func organizationTestMembers() []OrganizationMember {
+	return []OrganizationMember{
+		{OrganizationID: 1, UserID: 10, Role: OrganizationRoleOwner},
+		{OrganizationID: 1, UserID: 20, Role: OrganizationRoleAdmin},
+		{OrganizationID: 1, UserID: 30, Role: OrganizationRoleMember},
+		{OrganizationID: 1, UserID: 31, Role: OrganizationRoleMember},
+		{OrganizationID: 2, UserID: 40, Role: OrganizationRoleMember},
+	}
+}
+
+func TestOrganizationService_AdminCannotGrantAdminOrManageAdmins(t *testing.T) {
+	repo := newOrganizationRepoStub(organizationTestMembers()...)
+	svc := NewOrganizationService(repo, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+	admin := OrganizationRoleAdmin
+
+	_, err := svc.InviteMyMember(ctx, 20, &AddOrganizationMemberInput{Email: "x@example.com", Role: OrganizationRoleAdmin})
+	require.ErrorIs(t, err, ErrOrganizationForbidden)
+
+	_, err = svc.UpdateMyMember(ctx, 20, 30, &UpdateOrganizationMemberInput{Role: &admin})
+	require.ErrorIs(t, err, ErrOrganizationForbidden)
+
+	_, err = svc.UpdateMyMember(ctx, 20, 10, &UpdateOrganizationMemberInput{ClearSpendCap: true})
+	require.ErrorIs(t, err, ErrOrganizationForbidden)
+
+	require.ErrorIs(t, svc.RemoveMyMember(ctx, 20, 10), ErrOrganizationForbidden)
+	require.Empty(t, repo.removed)
+
+	capUSD := 25.0
+	member, err := svc.UpdateMyMember(ctx, 20, 30, &UpdateOrganizationMemberInput{MonthlySpendCapUSD: &capUSD})
+	require.NoError(t, err)
+	require.NotNil(t, member.MonthlySpendCapUSD)
+	require.Equal(t, 25.0, *member.MonthlySpendCapUSD)
+
+	require.NoError(t, svc.RemoveMyMember(ctx, 20, 31))
+	require.Equal(t, []int64{31}, repo.removed)
+}
+
+func TestOrganizationService_InvitedUserMustAcceptBeforeJoining(t *testing.T) {
+	repo := newOrganizationRepoStub(organizationTestMembers()...)
+	userRepo := &userRepoStub{usersByEmail: map[string]*User{
+		"new@example.com":  {ID: 50, Email: "new@example.com"},
+		"peer@example.com": {ID: 40, Email: "peer@example.com"},
+	}}
+	svc := NewOrganizationService(repo, userRepo, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	_, err := svc.InviteMyMember(ctx, 30, &AddOrganizationMemberInput{Email: "new@example.com"})
+	require.ErrorIs(t, err, ErrOrganizationForbidden)
+	_, err = svc.InviteMyMember(ctx, 20, &AddOrganizationMemberInput{Email: "peer@example.com"})
+	require.ErrorIs(t, err, ErrOrganizationMemberExists)
+
+	invitation, err := svc.InviteMyMember(ctx, 20, &AddOrganizationMemberInput{Email: "new@example.com"})
+	require.NoError(t, err)
+	require.Equal(t, OrganizationInvitationPending, invitation.Status)
+	require.Equal(t, OrganizationRoleMember, invitation.Role)
+	_, err = repo.GetMemberByUserID(ctx, 50)
+	require.ErrorIs(t, err, ErrOrganizationMemberNotFound, "inviting must not enroll the user")
+
+	// 只有被邀请人本人可以接受或拒绝
+	_, _, err = svc.AcceptInvitation(ctx, 31, invitation.ID)
+	require.ErrorIs(t, err, ErrOrganizationInvitationNotFound)
+	require.ErrorIs(t, svc.DeclineInvitation(ctx, 31, invitation.ID), ErrOrganizationInvitationNotFound)
+	// 跨组织的邀请视为不存在
+	repo.members[41] = &OrganizationMember{OrganizationID: 2, UserID: 41, Role: OrganizationRoleOwner}
+	require.ErrorIs(t, svc.RevokeMyInvitation(ctx, 41, invitation.ID), ErrOrganizationInvitationNotFound)
+
+	org, member, err := svc.AcceptInvitation(ctx, 50, invitation.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), org.ID)
+	require.Equal(t, OrganizationRoleMember, member.Role)
+
+	_, _, err = svc.AcceptInvitation(ctx, 50, invitation.ID)
+	require.ErrorIs(t, err, ErrOrganizationInvitationNotFound)
+	require.ErrorIs(t, svc.DeclineInvitation(ctx, 50, invitation.ID), ErrOrganizationInvitationNotFound)
+}
+
+func TestOrganizationService_OwnerIsImmutable(t *testing.T) {
+	repo := newOrganizationRepoStub(organizationTestMembers()...)
+	svc := NewOrganizationService(repo, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+	member := OrganizationRoleMember
+
+	_, err := svc.UpdateMyMember(ctx, 10, 10, &UpdateOrganizationMemberInput{Role: &member})
+	require.ErrorIs(t, err, ErrOrganizationOwnerImmutable)
+	require.ErrorIs(t, svc.RemoveMyMember(ctx, 10, 10), ErrOrganizationOwnerImmutable)
+
+	admin := OrganizationRoleAdmin
+	updated, err := svc.UpdateMyMember(ctx, 10, 30, &UpdateOrganizationMemberInput{Role: &admin})
+	require.NoError(t, err)
+	require.Equal(t, OrganizationRoleAdmin, updated.Role)
+}
+
+func TestOrganizationService_MembersCanOnlyRemoveThemselves(t *testing.T) {
+	repo := newOrganizationRepoStub(organizationTestMembers()...)
+	svc := NewOrganizationService(repo, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	require.ErrorIs(t, svc.RemoveMyMember(ctx, 30, 31), ErrOrganizationForbidden)
+	_, err := svc.ListMySubscriptions(ctx, 99)
+	require.ErrorIs(t, err, ErrOrganizationMemberNotFound)
+	_, err = svc.TransferToMyWallet(ctx, 30, 10)
+	require.ErrorIs(t, err, ErrOrganizationForbidden)
+
+	require.NoError(t, svc.RemoveMyMember(ctx, 30, 30))
+	require.Equal(t, []int64{30}, repo.removed)
+
+	// 跨组织的成员视为不存在
+	_, err = svc.UpdateMyMember(ctx, 10, 40, &UpdateOrganizationMemberInput{ClearSpendCap: true})
+	require.ErrorIs(t, err, ErrOrganizationMemberNotFound)
+}
+
+func TestOrganizationService_GetWalletForUserCachesMembersAndNonMembers(t *testing.T) {
+	repo := newOrganizationRepoStub()
+	repo.wallets[10] = &OrganizationWallet{OrganizationID: 1, Balance: 5}
+	svc := NewOrganizationService(repo, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		wallet, err := svc.GetWalletForUser(ctx, 10)
+		require.NoError(t, err)
+		require.Equal(t, 5.0, wallet.Balance)
+
+		wallet, err = svc.GetWalletForUser(ctx, 11)
+		require.NoError(t, err)
+		require.Nil(t, wallet)
+	}
+	require.Equal(t, 2, repo.walletCalls)
+
+	svc.invalidateWallet(10)
+	_, err := svc.GetWalletForUser(ctx, 10)
+	require.NoError(t, err)
+	require.Equal(t, 3, repo.walletCalls)
+}
+
+func TestOrganizationWalletEligibility(t *testing.T) {
+	below := func(balance float64) bool { return balance <= 0 }
+	capUSD := 10.0
+
+	require.NoError(t, organizationWalletEligibility(&OrganizationWallet{Balance: 1}, below))
+	require.ErrorIs(t, organizationWalletEligibility(&OrganizationWallet{Balance: 0}, below), ErrInsufficientBalance)
+	require.ErrorIs(t, organizationWalletEligibility(&OrganizationWallet{
+		Balance:            100,
+		MonthlySpendCapUSD: &capUSD,
+		MonthlySpentUSD:    10,
+	}, below), ErrOrganizationSpendCapExceeded)
+	require.NoError(t, organizationWalletEligibility(&OrganizationWallet{
+		Balance:            100,
+		MonthlySpendCapUSD: &capUSD,
+		MonthlySpentUSD:    9.99,
+	}, below))
+}
+
+func TestOrganizationMember_CurrentMonthSpentResetsAcrossMonths(t *testing.T) {
+	now := time.Date(2026, 3, 15, 8, 0, 0, 0, time.UTC)
+	m := &OrganizationMember{MonthlySpentUSD: 42, SpendMonthStart: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)}
+	require.Equal(t, 42.0, m.CurrentMonthSpent(now))
+
+	m.SpendMonthStart = time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	require.Equal(t, 0.0, m.CurrentMonthSpent(now))
+}
+
+func TestMergeOrganizationUsage(t *testing.T) {
+	trend := mergeOrganizationTrend([][]usagestats.TrendDataPoint{
+		{{Date: "2026-03-02", Requests: 1, TotalTokens: 10, ActualCost: 0.5}, {Date: "2026-03-01", Requests: 2, TotalTokens: 20, ActualCost: 1}},
+		{{Date: "2026-03-02", Requests: 3, TotalTokens: 30, ActualCost: 1.5}},
+	})
+	require.Len(t, trend, 2)
+	require.Equal(t, "2026-03-01", trend[0].Date)
+	require.Equal(t, int64(4), trend[1].Requests)
+	require.Equal(t, int64(40), trend[1].TotalTokens)
+	require.InDelta(t, 2.0, trend[1].ActualCost, 1e-9)
+
+	models := mergeOrganizationModelStats([][]usagestats.ModelStat{
+		{{Model: "claude-sonnet", Requests: 1, ActualCost: 1}, {Model: "gpt-5", Requests: 1, ActualCost: 3}},
+		{{Model: "claude-sonnet", Requests: 2, ActualCost: 4}},
+	})
+	require.Len(t, models, 2)
+	require.Equal(t, "claude-sonnet", models[0].Model)
+	require.Equal(t, int64(3), models[0].Requests)
+	require.InDelta(t, 5.0, models[0].ActualCost, 1e-9)
+	require.Equal(t, "gpt-5", models[1].Model)
+}
@@ -0,0 +1,394 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	dbent "github.com/Wei-Shaw/sub2api/ent"
+	"github.com/Wei-Shaw/sub2api/internal/payment"
+	"github.com/Wei-Shaw/sub2api/internal/payment/provider"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+const (
+	autoRenewalBatchSize = 50
+	autoRenewalOperator  = "system:auto_renew"
+
+	autoRenewalReasonPaymentMethodNotSaved = "payment_method_not_saved"
+	autoRenewalReasonPlanUnavailable       = "plan_unavailable"
+	autoRenewalReasonUserInactive          = "user_inactive"
+	autoRenewalReasonProviderUnavailable   = "provider_unavailable"
+	autoRenewalReasonProviderError         = "provider_error"
+)
+
+func (s *PaymentService) SetSubscriptionAutoRenewalRepository(repo SubscriptionAutoRenewalRepository) {
+	s.autoRenewalRepo = repo
+}
+
+// ListAutoRenewals returns the user's auto-renewal agreements.
+func (s *PaymentService) ListAutoRenewals(ctx context.Context, userID int64) ([]SubscriptionAutoRenewal, error) {
+	if s.autoRenewalRepo == nil {
+		return []SubscriptionAutoRenewal{}, nil
+	}
+	return s.autoRenewalRepo.ListByUser(ctx, userID)
+}
+
+// CancelAutoRenewal stops future renewal charges. The current subscription
+// period is not affected.
+func (s *PaymentService) CancelAutoRenewal(ctx context.Context, userID, renewalID int64) error {
+	if s.autoRenewalRepo == nil {
+		return ErrAutoRenewalNotFound
+	}
+	return s.autoRenewalRepo.Cancel(ctx, renewalID, userID)
+}
+
+// validateAutoRenewSelection 校验自动续费开关、订单类型以及所选服务商实例是否支持离线扣款。
+func (s *PaymentService) validateAutoRenewSelection(cfg *PaymentConfig, req CreateOrderRequest, sel *payment.InstanceSelection) error {
+	if cfg == nil || !cfg.AutoRenewEnabled || s.autoRenewalRepo == nil {
+		return ErrAutoRenewalDisabled
+	}
+	if req.OrderType != payment.OrderTypeSubscription {
+		return infraerrors.BadRequest("INVALID_INPUT", "auto-renewal requires a subscription order")
+	}
+	if sel == nil {
+		return ErrAutoRenewalUnsupported
+	}
+	prov, err := provider.CreateProvider(sel.ProviderKey, sel.InstanceID, sel.Config)
+	if err != nil {
+		return ErrAutoRenewalUnsupported
+	}
+	if _, ok := prov.(payment.RecurringProvider); !ok {
+		return ErrAutoRenewalUnsupported
+	}
+	return nil
+}
+
+// createPendingAutoRenewal 为首单创建 pending 协议，支付回调带回支付方式后再启用。
+func (s *PaymentService) createPendingAutoRenewal(ctx context.Context, order *dbent.PaymentOrder, plan *dbent.SubscriptionPlan, sel *payment.InstanceSelection) error {
+	if plan == nil || sel == nil {
+		return ErrAutoRenewalUnsupported
+	}
+	return s.autoRenewalRepo.Create(ctx, &SubscriptionAutoRenewal{
+		UserID:             order.UserID,
+		GroupID:            plan.GroupID,
+		PlanID:             plan.ID,
+		SetupOrderID:       order.ID,
+		ProviderInstanceID: strings.TrimSpace(sel.InstanceID),
+		ProviderKey:        strings.TrimSpace(sel.ProviderKey),
+		PaymentType:        order.PaymentType,
+		Status:             AutoRenewalStatusPending,
+	})
+}
+
+// syncAutoRenewalAfterPayment 在订单支付确认后推进自动续费协议：
+// 首单启用 pending 协议，续费单重置失败计数。重复回调是幂等的。
+func (s *PaymentService) syncAutoRenewalAfterPayment(ctx context.Context, orderID int64, metadata map[string]string) {
+	if s.autoRenewalRepo == nil {
+		return
+	}
+	renewal, err := s.autoRenewalRepo.GetBySetupOrderID(ctx, orderID)
+	switch {
+	case err == nil:
+		if renewal.Status != AutoRenewalStatusPending {
+			return
+		}
+		customerRef := strings.TrimSpace(metadata[payment.MetadataKeyCustomerRef])
+		paymentMethodRef := strings.TrimSpace(metadata[payment.MetadataKeyPaymentMethodRef])
+		if customerRef == "" || paymentMethodRef == "" {
+			// 例如通过 Stripe 使用钱包类支付：订单照常履约，但无法离线扣款。
+			err = s.autoRenewalRepo.MarkFailed(ctx, renewal.ID, AutoRenewalStatusFailed, 0, autoRenewalReasonPaymentMethodNotSaved, nil)
+		} else {
+			err = s.autoRenewalRepo.Activate(ctx, renewal.ID, customerRef, paymentMethodRef)
+		}
+		if err != nil {
+			slog.Warn("[AutoRenewal] failed to activate renewal", "renewalID", renewal.ID, "orderID", orderID, "error", err)
+		}
+		return
+	case !errors.Is(err, ErrAutoRenewalNotFound):
+		slog.Warn("[AutoRenewal] failed to lookup renewal by setup order", "orderID", orderID, "error", err)
+		return
+	}
+
+	renewal, err = s.autoRenewalRepo.GetByLastOrderID(ctx, orderID)
+	if err != nil {
+		if !errors.Is(err, ErrAutoRenewalNotFound) {
+			slog.Warn("[AutoRenewal] failed to lookup renewal by order", "orderID", orderID, "error", err)
+		}
+		return
+	}
+	if err := s.autoRenewalRepo.MarkSucceeded(ctx, renewal.ID, orderID, time.Now()); err != nil {
+		slog.Warn("[AutoRenewal] failed to record renewal success", "renewalID", renewal.ID, "orderID", orderID, "error", err)
+	}
+}
+
+// ProcessDueAutoRenewals charges saved payment methods for subscriptions that
+// are about to expire. It returns the number of charges attempted.
+func (s *PaymentService) ProcessDueAutoRenewals(ctx context.Context) (int, error) {
+	if s.autoRenewalRepo == nil || s.configService == nil {
+		return 0, nil
+	}
+	cfg, err := s.configService.GetPaymentConfig(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("get payment config: %w", err)
+	}
+	if !cfg.Enabled || !cfg.AutoRenewEnabled {
+		return 0, nil
+	}
+	lead, retry, maxAttempts := autoRenewalSchedule(cfg)
+	now := time.Now()
+	due, err := s.autoRenewalRepo.ListDue(ctx, now.Add(lead), now, autoRenewalBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("list due auto-renewals: %w", err)
+	}
+
+	attempted := 0
+	for i := range due {
+		renewal := &due[i]
+		plan, planErr := s.configService.GetPlan(ctx, renewal.PlanID)
+		if planErr == nil && renewal.SubscriptionExpiresAt != nil {
+			effectiveLead := autoRenewalEffectiveLead(lead, psComputeValidityDays(plan.ValidityDays, plan.ValidityUnit))
+			if renewal.SubscriptionExpiresAt.Sub(now) > effectiveLead {
+				continue
+			}
+		}
+		claimed, err := s.autoRenewalRepo.ClaimAttempt(ctx, renewal.ID, now, now.Add(retry))
+		if err != nil {
+			slog.Warn("[AutoRenewal] failed to claim renewal", "renewalID", renewal.ID, "error", err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		attempted++
+		if planErr != nil {
+			s.recordAutoRenewalFailure(ctx, renewal, nil, autoRenewalReasonPlanUnavailable, true, maxAttempts, retry)
+			continue
+		}
+		if err := s.chargeAutoRenewal(ctx, renewal, plan, cfg, maxAttempts, retry); err != nil {
+			slog.Warn("[AutoRenewal] renewal charge failed", "renewalID", renewal.ID, "error", err)
+		}
+	}
+	return attempted, nil
+}
+
+func (s *PaymentService) chargeAutoRenewal(ctx context.Context, renewal *SubscriptionAutoRenewal, plan *dbent.SubscriptionPlan, cfg *PaymentConfig, maxAttempts int, retry time.Duration) error {
+	user, err := s.userRepo.GetByID(ctx, renewal.UserID)
+	if err != nil || user.Status != payment.EntityStatusActive {
+		s.recordAutoRenewalFailure(ctx, renewal, nil, autoRenewalReasonUserInactive, true, maxAttempts, retry)
+		return nil
+	}
+	instanceID, err := strconv.ParseInt(renewal.ProviderInstanceID, 10, 64)
+	if err != nil {
+		s.recordAutoRenewalFailure(ctx, renewal, nil, autoRenewalReasonProviderUnavailable, true, maxAttempts, retry)
+		return nil
+	}
+	instCfg, err := s.loadBalancer.GetInstanceConfig(ctx, instanceID)
+	if err != nil {
+		// 实例被删除或配置暂不可读：计入失败次数，重试用尽后停止。
+		s.recordAutoRenewalFailure(ctx, renewal, nil, autoRenewalReasonProviderUnavailable, false, maxAttempts, retry)
+		return fmt.Errorf("load provider instance config: %w", err)
+	}
+	prov, err := provider.CreateProvider(renewal.ProviderKey, renewal.ProviderInstanceID, instCfg)
+	if err != nil {
+		s.recordAutoRenewalFailure(ctx, renewal, nil, autoRenewalReasonProviderUnavailable, false, maxAttempts, retry)
+		return fmt.Errorf("create provider: %w", err)
+	}
+	recurring, ok := prov.(payment.RecurringProvider)
+	if !ok {
+		s.recordAutoRenewalFailure(ctx, renewal, nil, autoRenewalReasonProviderUnavailable, true, maxAttempts, retry)
+		return nil
+	}
+
+	sel := &payment.InstanceSelection{InstanceID: renewal.ProviderInstanceID, ProviderKey: renewal.ProviderKey, Config: instCfg}
+	currency := paymentProviderConfigCurrency(renewal.ProviderKey, instCfg)
+	payAmountStr, payAmount, err := calculateCreateOrderPayAmountForOrderType(plan.Price, cfg.RechargeFeeRate, currency, payment.OrderTypeSubscription, cfg.SubscriptionUSDToCNYRate)
+	if err != nil {
+		s.recordAutoRenewalFailure(ctx, renewal, nil, autoRenewalReasonPlanUnavailable, true, maxAttempts, retry)
+		return err
+	}
+	order, err := s.createAutoRenewalOrder(ctx, renewal, user, plan, cfg, payAmount, sel, retry)
+	if err != nil {
+		return err
+	}
+	if err := s.autoRenewalRepo.SetLastOrder(ctx, renewal.ID, order.ID); err != nil {
+		return fmt.Errorf("set renewal last order: %w", err)
+	}
+
+	res, err := recurring.ChargeSavedPaymentMethod(ctx, payment.RecurringChargeRequest{
+		OrderID:          order.OutTradeNo,
+		Amount:           payAmountStr,
+		Subject:          s.buildPaymentSubject(plan, plan.Price, cfg, sel),
+		CustomerRef:      renewal.CustomerRef,
+		PaymentMethodRef: renewal.PaymentMethodRef,
+	})
+	if err != nil {
+		s.failAutoRenewalOrder(ctx, order, autoRenewalReasonProviderError)
+		s.recordAutoRenewalFailure(ctx, renewal, order, autoRenewalReasonProviderError, false, maxAttempts, retry)
+		return fmt.Errorf("charge saved payment method: %w", err)
+	}
+	if tradeNo := removePostgresTextNUL(res.TradeNo); tradeNo != "" {
+		if _, err := s.entClient.PaymentOrder.UpdateOneID(order.ID).SetPaymentTradeNo(tradeNo).Save(ctx); err != nil {
+			slog.Warn("[AutoRenewal] failed to save trade no", "orderID", order.ID, "error", err)
+		}
+	}
+	s.writeAuditLog(ctx, order.ID, "AUTO_RENEWAL_CHARGE", autoRenewalOperator, map[string]any{
+		"renewalID": renewal.ID,
+		"status":    res.Status,
+		"tradeNo":   res.TradeNo,
+		"reason":    res.FailureReason,
+	})
+
+	switch res.Status {
+	case payment.ProviderStatusPaid, payment.ProviderStatusSuccess:
+		// 与 webhook 走同一条确认路径；履约成功后 syncAutoRenewalAfterPayment 重置失败计数。
+		return s.HandlePaymentNotification(ctx, &payment.PaymentNotification{
+			TradeNo:  res.TradeNo,
+			OrderID:  order.OutTradeNo,
+			Amount:   res.Amount,
+			Status:   payment.NotificationStatusSuccess,
+			Metadata: map[string]string{"currency": res.Currency},
+		}, renewal.ProviderKey)
+	case payment.ProviderStatusPending:
+		// 异步结算的支付方式：等待 webhook，订单待支付期间不会重复扣款。
+		return nil
+	default:
+		reason := firstNonEmpty(res.FailureReason, autoRenewalReasonProviderError)
+		s.failAutoRenewalOrder(ctx, order, reason)
+		s.recordAutoRenewalFailure(ctx, renewal, order, reason, false, maxAttempts, retry)
+		return nil
+	}
+}
+
+// createAutoRenewalOrder 创建续费订单。续费由系统发起，不受用户待支付订单数和每日充值额度限制。
+func (s *PaymentService) createAutoRenewalOrder(ctx context.Context, renewal *SubscriptionAutoRenewal, user *User, plan *dbent.SubscriptionPlan, cfg *PaymentConfig, payAmount float64, sel *payment.InstanceSelection, retry time.Duration) (*dbent.PaymentOrder, error) {
+	tx, err := s.entClient.Tx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	outTradeNo, err := s.allocateOutTradeNo(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	b := tx.PaymentOrder.Create().
+		SetUserID(user.ID).
+		SetUserEmail(user.Email).
+		SetUserName(user.Username).
+		SetNillableUserNotes(psNilIfEmpty(user.Notes)).
+		SetAmount(plan.Price).
+		SetPayAmount(payAmount).
+		SetFeeRate(cfg.RechargeFeeRate).
+		SetRechargeCode("").
+		SetOutTradeNo(outTradeNo).
+		SetPaymentType(renewal.PaymentType).
+		SetPaymentTradeNo("").
+		SetOrderType(payment.OrderTypeSubscription).
+		SetStatus(OrderStatusPending).
+		// 异步结算的扣款需要足够的时间等待 webhook，与重试间隔保持一致。
+		SetExpiresAt(time.Now().Add(retry)).
+		SetClientIP("").
+		SetSrcHost("").
+		SetProviderInstanceID(sel.InstanceID).
+		SetProviderKey(sel.ProviderKey).
+		SetPlanID(plan.ID).
+		SetSubscriptionGroupID(plan.GroupID).
+		SetSubscriptionDays(psComputeValidityDays(plan.ValidityDays, plan.ValidityUnit))
+	if snapshot := buildPaymentOrderProviderSnapshot(sel, CreateOrderRequest{PaymentType: renewal.PaymentType}); snapshot != nil {
+		b.SetProviderSnapshot(snapshot)
+	}
+	order, err := b.Save(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("create renewal order: %w", err)
+	}
+	code := fmt.Sprintf("PAY-%d-%d", order.ID, time.Now().UnixNano()%100000)
+	order, err = tx.PaymentOrder.UpdateOneID(order.ID).SetRechargeCode(code).Save(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("set recharge code: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit renewal order transaction: %w", err)
+	}
+	s.writeAuditLog(ctx, order.ID, "ORDER_CREATED", autoRenewalOperator, map[string]any{
+		"renewalID":   renewal.ID,
+		"payAmount":   order.PayAmount,
+		"paymentType": order.PaymentType,
+		"orderType":   order.OrderType,
+	})
+	return order, nil
+}
+
+func (s *PaymentService) failAutoRenewalOrder(ctx context.Context, order *dbent.PaymentOrder, reason string) {
+	if _, err := s.entClient.PaymentOrder.UpdateOneID(order.ID).
+		SetStatus(OrderStatusFailed).
+		SetFailedAt(time.Now()).
+		SetFailedReason(reason).
+		Save(ctx); err != nil {
+		slog.Warn("[AutoRenewal] failed to mark renewal order failed", "orderID", order.ID, "error", err)
+	}
+}
+
+// recordAutoRenewalFailure 累计失败次数、安排重试并发送催缴邮件；terminal 为 true 时直接停止续费。
+func (s *PaymentService) recordAutoRenewalFailure(ctx context.Context, renewal *SubscriptionAutoRenewal, order *dbent.PaymentOrder, reason string, terminal bool, maxAttempts int, retry time.Duration) {
+	failureCount := renewal.FailureCount + 1
+	status, next := autoRenewalFailureOutcome(failureCount, maxAttempts, retry, time.Now())
+	if terminal {
+		status, next = AutoRenewalStatusFailed, nil
+	}
+	if err := s.autoRenewalRepo.MarkFailed(ctx, renewal.ID, status, failureCount, reason, next); err != nil {
+		slog.Warn("[AutoRenewal] failed to record renewal failure", "renewalID", renewal.ID, "error", err)
+		return
+	}
+	attemptsRemaining := 0
+	if status == AutoRenewalStatusPastDue {
+		attemptsRemaining = maxAttempts - failureCount
+	}
+	if err := s.sendAutoRenewalFailedNotification(ctx, renewal, order, reason, failureCount, attemptsRemaining); err != nil {
+		slog.Warn("[AutoRenewal] failed to send dunning email", "renewalID", renewal.ID, "error", err)
+	}
+}
+
+func (s *PaymentService) sendAutoRenewalFailedNotification(ctx context.Context, renewal *SubscriptionAutoRenewal, order *dbent.PaymentOrder, reason string, failureCount, attemptsRemaining int) error {
+	if s.notificationEmailService == nil {
+		return nil
+	}
+	user, err := s.userRepo.GetByID(ctx, renewal.UserID)
+	if err != nil || user == nil || strings.TrimSpace(user.Email) == "" {
+		return err
+	}
+	variables := map[string]string{
+		"subscription_group": "Subscription",
+		"expiry_time":        "",
+		"failure_reason":     reason,
+		"attempts_remaining": strconv.Itoa(attemptsRemaining),
+		"order_id":           "",
+	}
+	// 失败计数在续费成功后会归零，去重键需区分不同周期。
+	reminderKey := fmt.Sprintf("%d-%s", failureCount, time.Now().UTC().Format("2006-01-02"))
+	if order != nil {
+		variables["order_id"] = strconv.FormatInt(order.ID, 10)
+		reminderKey = "order-" + variables["order_id"]
+	}
+	if s.groupRepo != nil {
+		if group, err := s.groupRepo.GetByID(ctx, renewal.GroupID); err == nil && group != nil && strings.TrimSpace(group.Name) != "" {
+			variables["subscription_group"] = group.Name
+		}
+	}
+	if renewal.SubscriptionExpiresAt != nil {
+		variables["expiry_time"] = renewal.SubscriptionExpiresAt.Format("2006-01-02 15:04")
+	}
+	return s.notificationEmailService.Send(ctx, NotificationEmailSendInput{
+		Event:          NotificationEmailEventSubscriptionRenewalFailed,
+		RecipientEmail: user.Email,
+		RecipientName:  firstNonEmpty(user.Username, user.Email),
+		UserID:         user.ID,
+		SourceType:     "subscription_auto_renewal",
+		SourceID:       strconv.FormatInt(renewal.ID, 10),
+		ReminderKey:    reminderKey,
+		Variables:      variables,
+	})
+}
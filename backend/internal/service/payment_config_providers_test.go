@@ -51,6 +51,13 @@ func TestValidateProviderRequest(t *testing.T) {
 			supportedTypes: payment.TypeAirwallex,
 			wantErr:        false,
 		},
+		{
+			name:           "valid paypal provider",
+			providerKey:    payment.TypePayPal,
+			providerName:   "PayPal Provider",
+			supportedTypes: payment.TypePayPal,
+			wantErr:        false,
+		},
 		{
 			name:           "valid alipay provider",
 			providerKey:    "alipay",
@@ -244,6 +251,12 @@ func TestIsSensitiveProviderConfigField(t *testing.T) {
 		{payment.TypeAirwallex, "accountId", false},
 		{payment.TypeAirwallex, "currency", false},
 
+		// PayPal
+		{payment.TypePayPal, "clientSecret", true},
+		{payment.TypePayPal, "clientId", false},
+		{payment.TypePayPal, "webhookId", false},
+		{payment.TypePayPal, "apiBase", false},
+
 		// Unknown provider: never sensitive
 		{"unknown", "secretKey", false},
 	}
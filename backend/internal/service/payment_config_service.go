@@ -40,12 +40,29 @@ const (
 	SettingCancelWindowMode              = "CANCEL_RATE_LIMIT_WINDOW_MODE"
 	SettingAlipayForceQRCode             = "ALIPAY_FORCE_QRCODE"
 	SettingAlipayMobilePrecreateDeepLink = "ALIPAY_MOBILE_PRECREATE_DEEP_LINK"
+	// 订阅自动续费：到期前 N 天用保存的支付方式扣款，失败后按间隔重试。
+	SettingAutoRenewEnabled     = "AUTO_RENEW_ENABLED"
+	SettingAutoRenewLeadDays    = "AUTO_RENEW_LEAD_DAYS"
+	SettingAutoRenewRetryHours  = "AUTO_RENEW_RETRY_HOURS"
+	SettingAutoRenewMaxAttempts = "AUTO_RENEW_MAX_ATTEMPTS"
+	// 发票：销售方信息开票时快照到单据，编号前缀为空时使用默认值。
+	SettingInvoiceEnabled         = "INVOICE_ENABLED"
+	SettingInvoiceSellerName      = "INVOICE_SELLER_NAME"
+	SettingInvoiceSellerAddress   = "INVOICE_SELLER_ADDRESS"
+	SettingInvoiceSellerTaxID     = "INVOICE_SELLER_TAX_ID"
+	SettingInvoiceSellerEmail     = "INVOICE_SELLER_EMAIL"
+	SettingInvoiceNumberPrefix    = "INVOICE_NUMBER_PREFIX"
+	SettingCreditNoteNumberPrefix = "CREDIT_NOTE_NUMBER_PREFIX"
+	SettingInvoiceFooter          = "INVOICE_FOOTER"
 )
 
 // Default values for payment configuration settings.
 const (
-	defaultOrderTimeoutMin  = 30
-	defaultMaxPendingOrders = 3
+	defaultOrderTimeoutMin      = 30
+	defaultMaxPendingOrders     = 3
+	defaultAutoRenewLeadDays    = 3
+	defaultAutoRenewRetryHours  = 24
+	defaultAutoRenewMaxAttempts = 3
 )
 
 // PaymentConfig holds the payment system configuration.
@@ -80,6 +97,22 @@ type PaymentConfig struct {
 	AlipayForceQRCode bool `json:"alipay_force_qrcode"`
 	// Use Alipay face-to-face precreate and an app deep link on mobile clients.
 	AlipayMobilePrecreateDeepLink bool `json:"alipay_mobile_precreate_deep_link"`
+
+	// Subscription auto-renewal settings
+	AutoRenewEnabled     bool `json:"auto_renew_enabled"`
+	AutoRenewLeadDays    int  `json:"auto_renew_lead_days"`
+	AutoRenewRetryHours  int  `json:"auto_renew_retry_hours"`
+	AutoRenewMaxAttempts int  `json:"auto_renew_max_attempts"`
+
+	// Invoice settings
+	InvoiceEnabled         bool   `json:"invoice_enabled"`
+	InvoiceSellerName      string `json:"invoice_seller_name"`
+	InvoiceSellerAddress   string `json:"invoice_seller_address"`
+	InvoiceSellerTaxID     string `json:"invoice_seller_tax_id"`
+	InvoiceSellerEmail     string `json:"invoice_seller_email"`
+	InvoiceNumberPrefix    string `json:"invoice_number_prefix"`
+	CreditNoteNumberPrefix string `json:"credit_note_number_prefix"`
+	InvoiceFooter          string `json:"invoice_footer"`
 }
 
 // UpdatePaymentConfigRequest contains fields to update payment configuration.
@@ -113,6 +146,22 @@ type UpdatePaymentConfigRequest struct {
 	// Use Alipay face-to-face precreate and an app deep link on mobile clients.
 	AlipayMobilePrecreateDeepLink *bool `json:"alipay_mobile_precreate_deep_link"`
 
+	// Subscription auto-renewal settings
+	AutoRenewEnabled     *bool `json:"auto_renew_enabled"`
+	AutoRenewLeadDays    *int  `json:"auto_renew_lead_days"`
+	AutoRenewRetryHours  *int  `json:"auto_renew_retry_hours"`
+	AutoRenewMaxAttempts *int  `json:"auto_renew_max_attempts"`
+
+	// Invoice settings
+	InvoiceEnabled         *bool   `json:"invoice_enabled"`
+	InvoiceSellerName      *string `json:"invoice_seller_name"`
+	InvoiceSellerAddress   *string `json:"invoice_seller_address"`
+	InvoiceSellerTaxID     *string `json:"invoice_seller_tax_id"`
+	InvoiceSellerEmail     *string `json:"invoice_seller_email"`
+	InvoiceNumberPrefix    *string `json:"invoice_number_prefix"`
+	CreditNoteNumberPrefix *string `json:"credit_note_number_prefix"`
+	InvoiceFooter          *string `json:"invoice_footer"`
+
 	VisibleMethodAlipaySource  *string `json:"payment_visible_method_alipay_source"`
 	VisibleMethodWxpaySource   *string `json:"payment_visible_method_wxpay_source"`
 	VisibleMethodAlipayEnabled *bool   `json:"payment_visible_method_alipay_enabled"`
@@ -225,6 +274,9 @@ func (s *PaymentConfigService) GetPaymentConfig(ctx context.Context) (*PaymentCo
 		SettingCancelRateLimitOn, SettingCancelRateLimitMax,
 		SettingCancelWindowSize, SettingCancelWindowUnit, SettingCancelWindowMode,
 		SettingAlipayForceQRCode, SettingAlipayMobilePrecreateDeepLink,
+		SettingAutoRenewEnabled, SettingAutoRenewLeadDays, SettingAutoRenewRetryHours, SettingAutoRenewMaxAttempts,
+		SettingInvoiceEnabled, SettingInvoiceSellerName, SettingInvoiceSellerAddress, SettingInvoiceSellerTaxID,
+		SettingInvoiceSellerEmail, SettingInvoiceNumberPrefix, SettingCreditNoteNumberPrefix, SettingInvoiceFooter,
 		SettingPaymentVisibleMethodAlipayEnabled, SettingPaymentVisibleMethodAlipaySource,
 		SettingPaymentVisibleMethodWxpayEnabled, SettingPaymentVisibleMethodWxpaySource,
 	}
@@ -264,6 +316,20 @@ func (s *PaymentConfigService) parsePaymentConfig(vals map[string]string) *Payme
 
 		AlipayForceQRCode:             vals[SettingAlipayForceQRCode] == "true",
 		AlipayMobilePrecreateDeepLink: vals[SettingAlipayMobilePrecreateDeepLink] == "true",
+
+		AutoRenewEnabled:     vals[SettingAutoRenewEnabled] == "true",
+		AutoRenewLeadDays:    pcParseInt(vals[SettingAutoRenewLeadDays], defaultAutoRenewLeadDays),
+		AutoRenewRetryHours:  pcParseInt(vals[SettingAutoRenewRetryHours], defaultAutoRenewRetryHours),
+		AutoRenewMaxAttempts: pcParseInt(vals[SettingAutoRenewMaxAttempts], defaultAutoRenewMaxAttempts),
+
+		InvoiceEnabled:         vals[SettingInvoiceEnabled] == "true",
+		InvoiceSellerName:      vals[SettingInvoiceSellerName],
+		InvoiceSellerAddress:   vals[SettingInvoiceSellerAddress],
+		InvoiceSellerTaxID:     vals[SettingInvoiceSellerTaxID],
+		InvoiceSellerEmail:     vals[SettingInvoiceSellerEmail],
+		InvoiceNumberPrefix:    pcStringOrDefault(vals[SettingInvoiceNumberPrefix], defaultInvoiceNumberPrefix),
+		CreditNoteNumberPrefix: pcStringOrDefault(vals[SettingCreditNoteNumberPrefix], defaultCreditNoteNumberPrefix),
+		InvoiceFooter:          vals[SettingInvoiceFooter],
 	}
 	cfg.AlipayMobilePrecreateDeepLink = pcEnvBoolOverride(
 		SettingAlipayMobilePrecreateDeepLink,
@@ -343,6 +409,12 @@ func (s *PaymentConfigService) UpdatePaymentConfig(ctx context.Context, req Upda
 			return infraerrors.BadRequest("INVALID_RECHARGE_FEE_RATE", "recharge fee rate allows at most 2 decimal places")
 		}
 	}
+	for _, prefix := range []*string{req.InvoiceNumberPrefix, req.CreditNoteNumberPrefix} {
+		// 留空表示恢复默认前缀。
+		if prefix != nil && strings.TrimSpace(*prefix) != "" && !isValidInvoiceNumberPrefix(strings.TrimSpace(*prefix)) {
+			return infraerrors.BadRequest("INVALID_INVOICE_NUMBER_PREFIX", "invoice number prefix must be 1-16 letters, digits, '-' or '_'")
+		}
+	}
 	m := make(map[string]string)
 	if req.Enabled != nil {
 		m[SettingPaymentEnabled] = formatBoolOrEmpty(req.Enabled)
@@ -413,6 +485,42 @@ func (s *PaymentConfigService) UpdatePaymentConfig(ctx context.Context, req Upda
 	if req.AlipayMobilePrecreateDeepLink != nil {
 		m[SettingAlipayMobilePrecreateDeepLink] = formatBoolOrEmpty(req.AlipayMobilePrecreateDeepLink)
 	}
+	if req.AutoRenewEnabled != nil {
+		m[SettingAutoRenewEnabled] = formatBoolOrEmpty(req.AutoRenewEnabled)
+	}
+	if req.AutoRenewLeadDays != nil {
+		m[SettingAutoRenewLeadDays] = formatPositiveInt(req.AutoRenewLeadDays)
+	}
+	if req.AutoRenewRetryHours != nil {
+		m[SettingAutoRenewRetryHours] = formatPositiveInt(req.AutoRenewRetryHours)
+	}
+	if req.AutoRenewMaxAttempts != nil {
+		m[SettingAutoRenewMaxAttempts] = formatPositiveInt(req.AutoRenewMaxAttempts)
+	}
+	if req.InvoiceEnabled != nil {
+		m[SettingInvoiceEnabled] = formatBoolOrEmpty(req.InvoiceEnabled)
+	}
+	if req.InvoiceSellerName != nil {
+		m[SettingInvoiceSellerName] = strings.TrimSpace(*req.InvoiceSellerName)
+	}
+	if req.InvoiceSellerAddress != nil {
+		m[SettingInvoiceSellerAddress] = strings.TrimSpace(*req.InvoiceSellerAddress)
+	}
+	if req.InvoiceSellerTaxID != nil {
+		m[SettingInvoiceSellerTaxID] = strings.TrimSpace(*req.InvoiceSellerTaxID)
+	}
+	if req.InvoiceSellerEmail != nil {
+		m[SettingInvoiceSellerEmail] = strings.TrimSpace(*req.InvoiceSellerEmail)
+	}
+	if req.InvoiceNumberPrefix != nil {
+		m[SettingInvoiceNumberPrefix] = strings.TrimSpace(*req.InvoiceNumberPrefix)
+	}
+	if req.CreditNoteNumberPrefix != nil {
+		m[SettingCreditNoteNumberPrefix] = strings.TrimSpace(*req.CreditNoteNumberPrefix)
+	}
+	if req.InvoiceFooter != nil {
+		m[SettingInvoiceFooter] = derefStr(req.InvoiceFooter)
+	}
 	if req.VisibleMethodAlipaySource != nil {
 		m[SettingPaymentVisibleMethodAlipaySource] = derefStr(req.VisibleMethodAlipaySource)
 	}
@@ -464,6 +572,26 @@ func formatPositiveInt(v *int) string {
 	return strconv.Itoa(*v)
 }
 
+func pcStringOrDefault(v, fallback string) string {
+	if strings.TrimSpace(v) == "" {
+		return fallback
+	}
+	return strings.TrimSpace(v)
+}
+
+// isValidInvoiceNumberPrefix 仅允许字母、数字、'-'、'_'，编号会出现在下载文件名中。
+func isValidInvoiceNumberPrefix(prefix string) bool {
+	if prefix == "" || len(prefix) > invoiceNumberPrefixMaxLen {
+		return false
+	}
+	for _, r := range prefix {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
 func derefStr(v *string) string {
 	if v == nil {
 		return ""
@@ -9,7 +9,7 @@ import (
 
 func paymentProviderConfigCurrency(providerKey string, cfg map[string]string) string {
 	switch strings.TrimSpace(providerKey) {
-	case payment.TypeStripe, payment.TypeAirwallex:
+	case payment.TypeStripe, payment.TypeAirwallex, payment.TypePayPal:
 		currency, err := payment.NormalizePaymentCurrency(cfg["currency"])
 		if err == nil {
 			return currency
@@ -46,14 +46,22 @@ func (s *PaymentService) HandlePaymentNotification(ctx context.Context, n *payme
 		// Fallback only for true legacy "sub2_N" DB-ID payloads when the
 		// current out_trade_no lookup genuinely did not find an order.
 		if oid, ok := parseLegacyPaymentOrderID(n.OrderID, err); ok {
-			return s.confirmPayment(ctx, oid, n.TradeNo, n.Amount, pk, n.Metadata)
+			return s.confirmPaymentAndSyncAutoRenewal(ctx, oid, n, pk)
 		}
 		if dbent.IsNotFound(err) {
 			return fmt.Errorf("%w: out_trade_no=%s", ErrOrderNotFound, n.OrderID)
 		}
 		return fmt.Errorf("lookup order failed for out_trade_no %s: %w", n.OrderID, err)
 	}
-	return s.confirmPayment(ctx, order.ID, n.TradeNo, n.Amount, pk, n.Metadata)
+	return s.confirmPaymentAndSyncAutoRenewal(ctx, order.ID, n, pk)
+}
+
+func (s *PaymentService) confirmPaymentAndSyncAutoRenewal(ctx context.Context, oid int64, n *payment.PaymentNotification, pk string) error {
+	if err := s.confirmPayment(ctx, oid, n.TradeNo, n.Amount, pk, n.Metadata); err != nil {
+		return err
+	}
+	s.syncAutoRenewalAfterPayment(ctx, oid, n.Metadata)
+	return nil
 }
 
 func parseLegacyPaymentOrderID(orderID string, lookupErr error) (int64, bool) {
@@ -347,7 +355,8 @@ func (s *PaymentService) doBalance(ctx context.Context, o *dbent.PaymentOrder, l
 	case redeemActionRedeem:
 		// Code exists but unused — skip creation, proceed to redeem
 	}
-	if _, err := s.redeemService.Redeem(ContextSkipRedeemAffiliate(ctx), o.UserID, o.RechargeCode); err != nil {
+	redeemCtx := WithBalanceLedgerTag(ContextSkipRedeemAffiliate(ctx), paymentOrderBalanceLedgerTag(BalanceLedgerTypeRecharge, o.ID))
+	if _, err := s.redeemService.Redeem(redeemCtx, o.UserID, o.RechargeCode); err != nil {
 		return fmt.Errorf("redeem balance: %w", err)
 	}
 	if err := s.applyAffiliateRebateForOrder(ctx, o); err != nil {
@@ -383,6 +392,7 @@ func (s *PaymentService) markCompleted(ctx context.Context, o *dbent.PaymentOrde
 			"payAmount":      o.PayAmount,
 		})
 		s.dispatchPaymentFulfillmentNotification(o, auditAction)
+		s.issueOrderInvoicesBestEffort(ctx, o.ID)
 	}
 	return nil
 }
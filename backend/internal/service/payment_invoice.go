@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// 支付单据类型：订单履约后开具发票，退款完成后开具红字冲销的贷项通知单。
+const (
+	PaymentInvoiceKindInvoice    = "invoice"
+	PaymentInvoiceKindCreditNote = "credit_note"
+)
+
+const (
+	defaultInvoiceNumberPrefix    = "INV"
+	defaultCreditNoteNumberPrefix = "CN"
+	// invoiceNumberPrefixMaxLen 限制编号前缀长度，编号同时用作下载文件名。
+	invoiceNumberPrefixMaxLen = 16
+)
+
+var (
+	ErrPaymentInvoiceNotFound      = infraerrors.New(http.StatusNotFound, "PAYMENT_INVOICE_NOT_FOUND", "invoice not found")
+	ErrPaymentInvoiceDisabled      = infraerrors.New(http.StatusForbidden, "PAYMENT_INVOICE_DISABLED", "invoices are not enabled")
+	ErrPaymentInvoiceSellerMissing = infraerrors.New(http.StatusServiceUnavailable, "PAYMENT_INVOICE_SELLER_NOT_CONFIGURED", "invoice seller details are not configured")
+	ErrPaymentInvoiceFontMissing   = infraerrors.New(http.StatusServiceUnavailable, "PAYMENT_INVOICE_FONT_NOT_CONFIGURED", "no configured font can render this invoice as PDF, download it as HTML instead")
+)
+
+// InvoiceParty 是单据上的销售方或购买方信息，开具时快照，之后不随配置变化。
+type InvoiceParty struct {
+	Name    string `json:"name"`
+	Email   string `json:"email,omitempty"`
+	TaxID   string `json:"tax_id,omitempty"`
+	Address string `json:"address,omitempty"`
+}
+
+// PaymentInvoice 是一张已开具的发票或贷项通知单，开具后不可修改。
+type PaymentInvoice struct {
+	ID            int64  `json:"id"`
+	Kind          string `json:"kind"`
+	InvoiceNumber string `json:"invoice_number"`
+	OrderID       int64  `json:"order_id"`
+	UserID        int64  `json:"user_id"`
+	// RelatedInvoiceID / RelatedInvoiceNumber 仅贷项通知单使用，指向被冲销的发票。
+	RelatedInvoiceID     *int64       `json:"related_invoice_id,omitempty"`
+	RelatedInvoiceNumber string       `json:"related_invoice_number,omitempty"`
+	Currency             string       `json:"currency"`
+	Amount               float64      `json:"amount"`
+	Description          string       `json:"description"`
+	Seller               InvoiceParty `json:"seller"`
+	Buyer                InvoiceParty `json:"buyer"`
+	Footer               string       `json:"footer,omitempty"`
+	IssuedAt             time.Time    `json:"issued_at"`
+}
+
+// PaymentInvoiceIssue 是开具单据的入参；编号由仓储在同一事务内分配。
+type PaymentInvoiceIssue struct {
+	Kind             string
+	NumberPrefix     string
+	OrderID          int64
+	UserID           int64
+	RelatedInvoiceID *int64
+	Currency         string
+	Amount           float64
+	Description      string
+	Seller           InvoiceParty
+	Buyer            InvoiceParty
+	Footer           string
+}
+
+// PaymentInvoiceRepository 持久化支付单据。
+type PaymentInvoiceRepository interface {
+	// Issue 按 (NumberPrefix, Currency) 分配连续编号并写入单据；同一订单同类单据已存在时返回已有单据，不消耗编号。
+	Issue(ctx context.Context, in PaymentInvoiceIssue) (*PaymentInvoice, error)
+	GetByID(ctx context.Context, id int64) (*PaymentInvoice, error)
+	GetByOrderAndKind(ctx context.Context, orderID int64, kind string) (*PaymentInvoice, error)
+	ListByOrder(ctx context.Context, orderID int64) ([]PaymentInvoice, error)
+}
+
+// FormatPaymentInvoiceNumber 生成单据编号，例如 INV-USD-000042。
+func FormatPaymentInvoiceNumber(prefix, currency string, seq int64) string {
+	return fmt.Sprintf("%s-%s-%06d", prefix, currency, seq)
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	dbent "github.com/Wei-Shaw/sub2api/ent"
+	"github.com/Wei-Shaw/sub2api/internal/payment"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+func (s *PaymentService) SetPaymentInvoiceRepository(repo PaymentInvoiceRepository) {
+	s.invoiceRepo = repo
+}
+
+// SetInvoiceFontPath 设置 PDF 单据的中日韩后备字体路径，首次渲染 PDF 时才读取。
+func (s *PaymentService) SetInvoiceFontPath(path string) {
+	s.invoiceFontPath = strings.TrimSpace(path)
+}
+
+// RenderOrderInvoicePDF 渲染单据 PDF，按需嵌入配置的后备字体。
+func (s *PaymentService) RenderOrderInvoicePDF(inv *PaymentInvoice) ([]byte, error) {
+	s.invoiceFontOnce.Do(func() {
+		if s.invoiceFontPath == "" {
+			return
+		}
+		s.invoiceFont, s.invoiceFontErr = os.ReadFile(s.invoiceFontPath)
+		if s.invoiceFontErr != nil {
+			slog.Error("[PaymentInvoice] read cjk font failed", "path", s.invoiceFontPath, "error", s.invoiceFontErr)
+		}
+	})
+	if s.invoiceFontErr != nil {
+		return nil, fmt.Errorf("read invoice font: %w", s.invoiceFontErr)
+	}
+	return RenderPaymentInvoicePDF(inv, s.invoiceFont)
+}
+
+// ListOrderInvoices 返回订单的发票与贷项通知单；开票开启时会先补开尚未开具的单据。
+func (s *PaymentService) ListOrderInvoices(ctx context.Context, orderID, userID int64) ([]PaymentInvoice, error) {
+	if s.invoiceRepo == nil {
+		return nil, ErrPaymentInvoiceDisabled
+	}
+	o, err := s.GetOrder(ctx, orderID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ensureOrderInvoices(ctx, o); err != nil && !errors.Is(err, ErrPaymentInvoiceDisabled) {
+		return nil, err
+	}
+	return s.invoiceRepo.ListByOrder(ctx, o.ID)
+}
+
+// GetOrderInvoice 返回用户订单下的单据。已开具的单据在关闭开票后仍可下载。
+func (s *PaymentService) GetOrderInvoice(ctx context.Context, orderID, userID, invoiceID int64) (*PaymentInvoice, error) {
+	if s.invoiceRepo == nil {
+		return nil, ErrPaymentInvoiceDisabled
+	}
+	inv, err := s.invoiceRepo.GetByID(ctx, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	if inv.OrderID != orderID || inv.UserID != userID {
+		return nil, ErrPaymentInvoiceNotFound
+	}
+	return inv, nil
+}
+
+// issueOrderInvoicesBestEffort 在履约或退款完成后立即开票，失败只记日志：
+// 用户查看单据时会再次补开。
+func (s *PaymentService) issueOrderInvoicesBestEffort(ctx context.Context, orderID int64) {
+	if s == nil || s.invoiceRepo == nil || s.entClient == nil {
+		return
+	}
+	o, err := s.entClient.PaymentOrder.Get(ctx, orderID)
+	if err != nil {
+		slog.Warn("[PaymentInvoice] load order failed", "orderID", orderID, "error", err)
+		return
+	}
+	if err := s.ensureOrderInvoices(ctx, o); err != nil && !errors.Is(err, ErrPaymentInvoiceDisabled) {
+		slog.Warn("[PaymentInvoice] issue documents failed", "orderID", orderID, "error", err)
+	}
+}
+
+// ensureOrderInvoices 为已履约订单开具发票，为已退款订单开具贷项通知单。幂等。
+func (s *PaymentService) ensureOrderInvoices(ctx context.Context, o *dbent.PaymentOrder) error {
+	if !isInvoiceableOrderStatus(o.Status) {
+		return nil
+	}
+	cfg, err := s.configService.GetPaymentConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("get payment config: %w", err)
+	}
+	if !cfg.InvoiceEnabled {
+		return ErrPaymentInvoiceDisabled
+	}
+	if strings.TrimSpace(cfg.InvoiceSellerName) == "" {
+		return ErrPaymentInvoiceSellerMissing
+	}
+	invoice, err := s.invoiceRepo.Issue(ctx, s.buildOrderInvoiceIssue(ctx, o, cfg))
+	if err != nil {
+		return fmt.Errorf("issue invoice: %w", err)
+	}
+	if o.Status != OrderStatusRefunded && o.Status != OrderStatusPartiallyRefunded {
+		return nil
+	}
+	if _, err := s.invoiceRepo.Issue(ctx, buildOrderCreditNoteIssue(o, cfg, invoice)); err != nil {
+		return fmt.Errorf("issue credit note: %w", err)
+	}
+	return nil
+}
+
+// isInvoiceableOrderStatus 仅已履约的订单可开票；退款相关状态都只能由已完成订单进入。
+func isInvoiceableOrderStatus(status string) bool {
+	return status == OrderStatusCompleted || psIsRefundStatus(status)
+}
+
+func (s *PaymentService) buildOrderInvoiceIssue(ctx context.Context, o *dbent.PaymentOrder, cfg *PaymentConfig) PaymentInvoiceIssue {
+	return PaymentInvoiceIssue{
+		Kind:         PaymentInvoiceKindInvoice,
+		NumberPrefix: cfg.InvoiceNumberPrefix,
+		OrderID:      o.ID,
+		UserID:       o.UserID,
+		Currency:     PaymentOrderCurrency(o),
+		Amount:       o.PayAmount,
+		Description:  s.paymentInvoiceDescription(ctx, o),
+		Seller:       invoiceSellerFromConfig(cfg),
+		Buyer:        invoiceBuyerFromOrder(o),
+		Footer:       cfg.InvoiceFooter,
+	}
+}
+
+// buildOrderCreditNoteIssue 按实际退回的支付金额开具贷项通知单，买卖双方与原发票保持一致。
+func buildOrderCreditNoteIssue(o *dbent.PaymentOrder, cfg *PaymentConfig, invoice *PaymentInvoice) PaymentInvoiceIssue {
+	relatedID := invoice.ID
+	description := "Refund of invoice " + invoice.InvoiceNumber
+	if reason := strings.TrimSpace(psStringValue(o.RefundReason)); reason != "" {
+		description += ": " + reason
+	}
+	return PaymentInvoiceIssue{
+		Kind:             PaymentInvoiceKindCreditNote,
+		NumberPrefix:     cfg.CreditNoteNumberPrefix,
+		OrderID:          o.ID,
+		UserID:           o.UserID,
+		RelatedInvoiceID: &relatedID,
+		Currency:         invoice.Currency,
+		Amount:           calculateGatewayRefundAmount(o.Amount, o.PayAmount, o.RefundAmount, invoice.Currency),
+		Description:      description,
+		Seller:           invoice.Seller,
+		Buyer:            invoice.Buyer,
+		Footer:           cfg.InvoiceFooter,
+	}
+}
+
+func (s *PaymentService) paymentInvoiceDescription(ctx context.Context, o *dbent.PaymentOrder) string {
+	if o.OrderType != payment.OrderTypeSubscription {
+		return fmt.Sprintf("Sub2API balance recharge (%s credits)", payment.FormatAmountForCurrency(o.Amount, "USD"))
+	}
+	name := "Sub2API Subscription"
+	if o.PlanID != nil && s.configService != nil {
+		if plan, err := s.configService.GetPlan(ctx, *o.PlanID); err == nil {
+			if plan.ProductName != "" {
+				name = plan.ProductName
+			} else {
+				name = "Sub2API Subscription " + plan.Name
+			}
+		}
+	}
+	if o.SubscriptionDays != nil && *o.SubscriptionDays > 0 {
+		return fmt.Sprintf("%s (%d days)", name, *o.SubscriptionDays)
+	}
+	return name
+}
+
+func invoiceSellerFromConfig(cfg *PaymentConfig) InvoiceParty {
+	return InvoiceParty{
+		Name:    strings.TrimSpace(cfg.InvoiceSellerName),
+		Email:   strings.TrimSpace(cfg.InvoiceSellerEmail),
+		TaxID:   strings.TrimSpace(cfg.InvoiceSellerTaxID),
+		Address: strings.TrimSpace(cfg.InvoiceSellerAddress),
+	}
+}
+
+// invoiceBuyerFromOrder 优先使用下单时填写的发票抬头，未填写时使用账户用户名或邮箱。
+func invoiceBuyerFromOrder(o *dbent.PaymentOrder) InvoiceParty {
+	name := strings.TrimSpace(psStringValue(o.BuyerName))
+	if name == "" {
+		name = strings.TrimSpace(o.UserName)
+	}
+	if name == "" {
+		name = o.UserEmail
+	}
+	return InvoiceParty{
+		Name:    name,
+		Email:   o.UserEmail,
+		TaxID:   strings.TrimSpace(psStringValue(o.BuyerTaxID)),
+		Address: strings.TrimSpace(psStringValue(o.BuyerAddress)),
+	}
+}
+
+// 发票抬头字段长度上限，与 payment_orders 列定义一致。
+const (
+	invoiceBuyerNameMaxLen    = 255
+	invoiceBuyerTaxIDMaxLen   = 64
+	invoiceBuyerAddressMaxLen = 1000
+)
+
+// normalizeInvoiceBuyerFields 校验下单时填写的发票抬头。
+func normalizeInvoiceBuyerFields(req *CreateOrderRequest) error {
+	req.BuyerName = strings.TrimSpace(req.BuyerName)
+	req.BuyerTaxID = strings.TrimSpace(req.BuyerTaxID)
+	req.BuyerAddress = strings.TrimSpace(req.BuyerAddress)
+	if len([]rune(req.BuyerName)) > invoiceBuyerNameMaxLen ||
+		len([]rune(req.BuyerTaxID)) > invoiceBuyerTaxIDMaxLen ||
+		len([]rune(req.BuyerAddress)) > invoiceBuyerAddressMaxLen {
+		return infraerrors.BadRequest("INVALID_INVOICE_BUYER", "invoice buyer details are too long")
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/payment"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pdf"
+)
+
+// 单据下载格式。
+const (
+	PaymentInvoiceFormatPDF  = "pdf"
+	PaymentInvoiceFormatHTML = "html"
+)
+
+type paymentInvoiceView struct {
+	*PaymentInvoice
+	Title       string
+	IssuedDate  string
+	AmountText  string
+	SellerLines []string
+	BuyerLines  []string
+}
+
+func newPaymentInvoiceView(inv *PaymentInvoice) paymentInvoiceView {
+	title := "INVOICE"
+	amount := payment.FormatAmountForCurrency(inv.Amount, inv.Currency)
+	if inv.Kind == PaymentInvoiceKindCreditNote {
+		title = "CREDIT NOTE"
+		amount = "-" + amount
+	}
+	return paymentInvoiceView{
+		PaymentInvoice: inv,
+		Title:          title,
+		IssuedDate:     inv.IssuedAt.UTC().Format("2006-01-02"),
+		AmountText:     amount + " " + inv.Currency,
+		SellerLines:    invoicePartyLines(inv.Seller),
+		BuyerLines:     invoicePartyLines(inv.Buyer),
+	}
+}
+
+func invoicePartyLines(p InvoiceParty) []string {
+	lines := []string{p.Name}
+	for _, line := range strings.Split(p.Address, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if p.TaxID != "" {
+		lines = append(lines, "Tax ID: "+p.TaxID)
+	}
+	if p.Email != "" {
+		lines = append(lines, p.Email)
+	}
+	return lines
+}
+
+var paymentInvoiceHTMLTemplate = template.Must(template.New("invoice").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} {{.InvoiceNumber}}</title>
+<style>
+body{font-family:-apple-system,"Segoe UI",Helvetica,Arial,"PingFang SC","Microsoft YaHei",sans-serif;color:#222;max-width:760px;margin:40px auto;padding:0 24px}
+h1{font-size:26px;margin:0 0 4px}
+.meta td{padding:2px 16px 2px 0}
+.parties{display:flex;gap:48px;margin:32px 0}
+.parties div{flex:1}
+.label{font-size:12px;text-transform:uppercase;color:#777;margin-bottom:6px}
+table.items{width:100%;border-collapse:collapse;margin-top:16px}
+table.items th,table.items td{border-bottom:1px solid #ddd;padding:8px 0;text-align:left}
+table.items .amount{text-align:right}
+.total{font-weight:bold}
+footer{margin-top:40px;font-size:12px;color:#777;white-space:pre-line}
+@media print{body{margin:0}}
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<table class="meta">
+<tr><td>Number</td><td>{{.InvoiceNumber}}</td></tr>
+<tr><td>Date</td><td>{{.IssuedDate}}</td></tr>
+<tr><td>Order</td><td>#{{.OrderID}}</td></tr>
+{{- if .RelatedInvoiceNumber}}
+<tr><td>Credits invoice</td><td>{{.RelatedInvoiceNumber}}</td></tr>
+{{- end}}
+</table>
+<div class="parties">
+<div><div class="label">From</div>{{range .SellerLines}}<div>{{.}}</div>{{end}}</div>
+<div><div class="label">Bill to</div>{{range .BuyerLines}}<div>{{.}}</div>{{end}}</div>
+</div>
+<table class="items">
+<tr><th>Description</th><th class="amount">Amount</th></tr>
+<tr><td>{{.Description}}</td><td class="amount">{{.AmountText}}</td></tr>
+<tr class="total"><td>Total</td><td class="amount">{{.AmountText}}</td></tr>
+</table>
+{{- if .Footer}}
+<footer>{{.Footer}}</footer>
+{{- end}}
+</body>
+</html>
+`))
+
+// RenderPaymentInvoiceHTML 渲染可打印的 HTML 单据。
+func RenderPaymentInvoiceHTML(inv *PaymentInvoice) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := paymentInvoiceHTMLTemplate.Execute(&buf, newPaymentInvoiceView(inv)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// RenderPaymentInvoicePDF 渲染 A4 PDF 单据。cjkFont 为可选的后备字体数据；
+// 单据含有内置字体与后备字体都无法显示的字符时返回 ErrPaymentInvoiceFontMissing。
+func RenderPaymentInvoicePDF(inv *PaymentInvoice, cjkFont []byte) ([]byte, error) {
+	const (
+		left   = 50.0
+		right  = pdf.PageWidth - 50
+		middle = 310.0
+	)
+	v := newPaymentInvoiceView(inv)
+	doc, err := pdf.New(cjkFont)
+	if err != nil {
+		return nil, fmt.Errorf("load invoice font: %w", err)
+	}
+
+	doc.Text(left, 70, 24, true, v.Title)
+	y := 100.0
+	meta := [][2]string{{"Number", v.InvoiceNumber}, {"Date", v.IssuedDate}, {"Order", "#" + strconv.FormatInt(v.OrderID, 10)}}
+	if v.RelatedInvoiceNumber != "" {
+		meta = append(meta, [2]string{"Credits invoice", v.RelatedInvoiceNumber})
+	}
+	for _, m := range meta {
+		doc.Text(left, y, 10, false, m[0])
+		doc.Text(left+100, y, 10, false, m[1])
+		y += 15
+	}
+
+	y += 20
+	doc.Text(left, y, 9, true, "FROM")
+	doc.Text(middle, y, 9, true, "BILL TO")
+	sellerY, buyerY := y+16, y+16
+	for _, line := range v.SellerLines {
+		doc.Text(left, sellerY, 10, false, line)
+		sellerY += 14
+	}
+	for _, line := range v.BuyerLines {
+		doc.Text(middle, buyerY, 10, false, line)
+		buyerY += 14
+	}
+	y = math.Max(sellerY, buyerY) + 24
+
+	doc.Text(left, y, 10, true, "Description")
+	doc.TextRight(right, y, 10, true, "Amount")
+	doc.Line(left, y+6, right, y+6, 0.5)
+	y += 22
+	doc.Text(left, y, 10, false, v.Description)
+	doc.TextRight(right, y, 10, false, v.AmountText)
+	doc.Line(left, y+8, right, y+8, 0.5)
+	y += 24
+	doc.Text(left, y, 11, true, "Total")
+	doc.TextRight(right, y, 11, true, v.AmountText)
+
+	if v.Footer != "" {
+		y += 50
+		for _, line := range strings.Split(v.Footer, "\n") {
+			doc.Text(left, y, 8, false, strings.TrimSpace(line))
+			y += 11
+		}
+	}
+	out, err := doc.Bytes()
+	if errors.Is(err, pdf.ErrGlyphMissing) {
+		return nil, ErrPaymentInvoiceFontMissing.WithCause(err)
+	}
+	return out, err
+}
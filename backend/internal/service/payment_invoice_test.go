@@ -0,0 +1,110 @@
+//go:build unit
+
+package service
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	dbent "github.com/Wei-Shaw/sub2api/ent"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatPaymentInvoiceNumber(t *testing.T) {
+	require.Equal(t, "INV-USD-000042", FormatPaymentInvoiceNumber("INV", "USD", 42))
+	require.Equal(t, "CN-CNY-1234567", FormatPaymentInvoiceNumber("CN", "CNY", 1234567))
+}
+
+func TestInvoiceBuyerFromOrder(t *testing.T) {
+	buyerName := "  Acme Ltd  "
+	taxID := "DE123456789"
+	buyer := invoiceBuyerFromOrder(&dbent.PaymentOrder{
+		UserEmail:  "a@example.com",
+		UserName:   "alice",
+		BuyerName:  &buyerName,
+		BuyerTaxID: &taxID,
+	})
+	require.Equal(t, InvoiceParty{Name: "Acme Ltd", Email: "a@example.com", TaxID: taxID}, buyer)
+
+	buyer = invoiceBuyerFromOrder(&dbent.PaymentOrder{UserEmail: "b@example.com"})
+	require.Equal(t, "b@example.com", buyer.Name)
+}
+
+func TestBuildOrderCreditNoteIssue(t *testing.T) {
+	reason := "duplicate purchase"
+	o := &dbent.PaymentOrder{
+		ID:           7,
+		UserID:       3,
+		Amount:       100,
+		PayAmount:    100,
+		RefundAmount: 40,
+		RefundReason: &reason,
+	}
+	cfg := &PaymentConfig{CreditNoteNumberPrefix: "CN", InvoiceFooter: "Thanks"}
+	invoice := &PaymentInvoice{
+		ID:            11,
+		InvoiceNumber: "INV-USD-000001",
+		Currency:      "USD",
+		Seller:        InvoiceParty{Name: "Seller"},
+		Buyer:         InvoiceParty{Name: "Buyer"},
+	}
+
+	issue := buildOrderCreditNoteIssue(o, cfg, invoice)
+	require.Equal(t, PaymentInvoiceKindCreditNote, issue.Kind)
+	require.Equal(t, "CN", issue.NumberPrefix)
+	require.NotNil(t, issue.RelatedInvoiceID)
+	require.Equal(t, int64(11), *issue.RelatedInvoiceID)
+	require.InDelta(t, 40, issue.Amount, 1e-9)
+	require.Equal(t, "Refund of invoice INV-USD-000001: duplicate purchase", issue.Description)
+	require.Equal(t, invoice.Seller, issue.Seller)
+	require.Equal(t, invoice.Buyer, issue.Buyer)
+}
+
+func TestNormalizeInvoiceBuyerFields(t *testing.T) {
+	req := &CreateOrderRequest{BuyerName: " Acme ", BuyerTaxID: " X1 "}
+	require.NoError(t, normalizeInvoiceBuyerFields(req))
+	require.Equal(t, "Acme", req.BuyerName)
+	require.Equal(t, "X1", req.BuyerTaxID)
+
+	req = &CreateOrderRequest{BuyerTaxID: strings.Repeat("9", invoiceBuyerTaxIDMaxLen+1)}
+	require.Error(t, normalizeInvoiceBuyerFields(req))
+}
+
+func TestRenderPaymentInvoice(t *testing.T) {
+	inv := &PaymentInvoice{
+		Kind:                 PaymentInvoiceKindCreditNote,
+		InvoiceNumber:        "CN-USD-000001",
+		OrderID:              7,
+		RelatedInvoiceNumber: "INV-USD-000001",
+		Currency:             "USD",
+		Amount:               12.5,
+		Description:          "Refund <test>",
+		Seller:               InvoiceParty{Name: "Seller GmbH", Address: "Street 1\nBerlin"},
+		Buyer:                InvoiceParty{Name: "买家公司"},
+		IssuedAt:             time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	html, err := RenderPaymentInvoiceHTML(inv)
+	require.NoError(t, err)
+	require.Contains(t, string(html), "CREDIT NOTE")
+	require.Contains(t, string(html), "INV-USD-000001")
+	require.Contains(t, string(html), "Refund &lt;test&gt;")
+	require.Contains(t, string(html), "2026-01-02")
+
+	// 购买方含中文且未配置后备字体：PDF 拒绝输出缺字单据，HTML 仍可用。
+	_, err = RenderPaymentInvoicePDF(inv, nil)
+	require.ErrorIs(t, err, ErrPaymentInvoiceFontMissing)
+
+	inv.Buyer.Name = "Käufer AG"
+	doc, err := RenderPaymentInvoicePDF(inv, nil)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(doc), "%PDF-"))
+	require.Contains(t, string(doc), "%%EOF")
+
+	svc := &PaymentService{}
+	svc.SetInvoiceFontPath(filepath.Join(t.TempDir(), "missing.ttf"))
+	_, err = svc.RenderOrderInvoicePDF(inv)
+	require.Error(t, err)
+}
@@ -29,6 +29,9 @@ func (s *PaymentService) CreateOrder(ctx context.Context, req CreateOrderRequest
 	if normalized := NormalizeVisibleMethod(req.PaymentType); normalized != "" {
 		req.PaymentType = normalized
 	}
+	if err := normalizeInvoiceBuyerFields(&req); err != nil {
+		return nil, err
+	}
 	cfg, err := s.configService.GetPaymentConfig(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("get payment config: %w", err)
@@ -80,6 +83,11 @@ func (s *PaymentService) CreateOrder(ctx context.Context, req CreateOrderRequest
 	if err := s.validateSelectedCreateOrderInstance(ctx, req, sel); err != nil {
 		return nil, err
 	}
+	if req.AutoRenew {
+		if err := s.validateAutoRenewSelection(cfg, req, sel); err != nil {
+			return nil, err
+		}
+	}
 	selectedCurrency := payment.DefaultPaymentCurrency
 	if sel != nil {
 		selectedCurrency = paymentProviderConfigCurrency(sel.ProviderKey, sel.Config)
@@ -104,6 +112,14 @@ func (s *PaymentService) CreateOrder(ctx context.Context, req CreateOrderRequest
 	if err != nil {
 		return nil, err
 	}
+	if req.AutoRenew {
+		if err := s.createPendingAutoRenewal(ctx, order, plan, sel); err != nil {
+			_, _ = s.entClient.PaymentOrder.UpdateOneID(order.ID).
+				SetStatus(OrderStatusFailed).
+				Save(ctx)
+			return nil, fmt.Errorf("create auto-renewal: %w", err)
+		}
+	}
 	resp, err := s.invokeProvider(ctx, order, req, cfg, limitAmount, payAmountStr, payAmount, plan, sel)
 	if err != nil {
 		_, _ = s.entClient.PaymentOrder.UpdateOneID(order.ID).
@@ -193,7 +209,10 @@ func (s *PaymentService) createOrderInTx(ctx context.Context, req CreateOrderReq
 		SetStatus(OrderStatusPending).
 		SetExpiresAt(exp).
 		SetClientIP(req.ClientIP).
-		SetSrcHost(req.SrcHost)
+		SetSrcHost(req.SrcHost).
+		SetNillableBuyerName(psNilIfEmpty(req.BuyerName)).
+		SetNillableBuyerTaxID(psNilIfEmpty(req.BuyerTaxID)).
+		SetNillableBuyerAddress(psNilIfEmpty(req.BuyerAddress))
 	if req.SrcURL != "" {
 		b.SetSrcURL(req.SrcURL)
 	}
@@ -296,7 +315,7 @@ func buildPaymentOrderProviderSnapshot(sel *payment.InstanceSelection, req Creat
 			snapshot["merchant_id"] = merchantID
 		}
 	}
-	if providerKey == payment.TypeStripe {
+	if providerKey == payment.TypeStripe || providerKey == payment.TypePayPal {
 		snapshot["currency"] = paymentProviderConfigCurrency(providerKey, sel.Config)
 	}
 	if providerKey == payment.TypeAirwallex {
@@ -447,6 +466,11 @@ func (s *PaymentService) invokeProvider(ctx context.Context, order *dbent.Paymen
 		ReturnURL:   providerReturnURL,
 	}, sel, outTradeNo, payAmountStr, subject)
 	providerReq.AlipayMobilePrecreate = shouldUseAlipayMobilePrecreate(req, cfg, sel)
+	if req.AutoRenew {
+		providerReq.SaveForRecurring = true
+		providerReq.CustomerEmail = order.UserEmail
+		providerReq.CustomerName = order.UserName
+	}
 	finishProviderCall := servertiming.ObserveDependency(ctx, "payment")
 	pr, err := prov.CreatePayment(ctx, providerReq)
 	finishProviderCall()
@@ -475,6 +499,7 @@ func (s *PaymentService) invokeProvider(ctx context.Context, order *dbent.Paymen
 		"paymentType":    req.PaymentType,
 		"orderType":      req.OrderType,
 		"paymentSource":  NormalizePaymentSource(req.PaymentSource),
+		"autoRenew":      req.AutoRenew,
 	})
 	resultType := pr.ResultType
 	if resultType == "" {
@@ -186,6 +186,17 @@ func TestBuildPaymentOrderProviderSnapshot_IncludesProviderCurrency(t *testing.T
 	}, CreateOrderRequest{})
 	require.Equal(t, "USD", airwallexSnapshot["currency"])
 	require.Equal(t, "acct-78", airwallexSnapshot["merchant_id"])
+
+	paypalSnapshot := buildPaymentOrderProviderSnapshot(&payment.InstanceSelection{
+		InstanceID:  "79",
+		ProviderKey: payment.TypePayPal,
+		Config: map[string]string{
+			"currency":     "eur",
+			"clientSecret": "secret",
+		},
+	}, CreateOrderRequest{})
+	require.Equal(t, "EUR", paypalSnapshot["currency"])
+	require.NotContains(t, paypalSnapshot, "clientSecret")
 }
 
 func valueOrEmpty(v *string) string {
@@ -287,11 +287,12 @@ type availableBalanceDeductor interface {
 	DeductAvailableBalance(ctx context.Context, id int64, amount float64) (float64, error)
 }
 
-func (s *PaymentService) deductAvailableBalance(ctx context.Context, userID int64, amount float64) (float64, error) {
+func (s *PaymentService) deductAvailableBalance(ctx context.Context, orderID, userID int64, amount float64) (float64, error) {
 	repo, ok := s.userRepo.(availableBalanceDeductor)
 	if !ok {
 		return 0, errors.New("user repository does not support available balance deduction")
 	}
+	ctx = WithBalanceLedgerTag(ctx, paymentOrderBalanceLedgerTag(BalanceLedgerTypeRefund, orderID))
 	return repo.DeductAvailableBalance(ctx, userID, amount)
 }
 
@@ -307,7 +308,7 @@ func (s *PaymentService) ExecuteRefund(ctx context.Context, p *RefundPlan) (*Ref
 		// Skip balance deduction on retry if previous attempt already deducted
 		// but failed to roll back (REFUND_ROLLBACK_FAILED in audit log).
 		if !s.hasAuditLog(ctx, p.OrderID, "REFUND_ROLLBACK_FAILED") {
-			deducted, err := s.deductAvailableBalance(ctx, p.Order.UserID, p.BalanceToDeduct)
+			deducted, err := s.deductAvailableBalance(ctx, p.OrderID, p.Order.UserID, p.BalanceToDeduct)
 			if err != nil {
 				s.restoreStatus(ctx, p)
 				return nil, fmt.Errorf("deduction: %w", err)
@@ -505,6 +506,7 @@ func (s *PaymentService) finalizePendingRefundSuccess(ctx context.Context, p *Re
 	if err = tx.Commit(); err != nil {
 		return nil, fmt.Errorf("commit refund finalization: %w", err)
 	}
+	s.issueOrderInvoicesBestEffort(ctx, p.OrderID)
 	return result, nil
 }
 
@@ -534,7 +536,7 @@ func (s *PaymentService) refundFinalizePlan(o *dbent.PaymentOrder) *RefundPlan {
 
 func (s *PaymentService) applyRefundFinalDeduction(ctx context.Context, p *RefundPlan) error {
 	if p.DeductionType == payment.DeductionTypeBalance && p.BalanceToDeduct > 0 {
-		deducted, err := s.deductAvailableBalance(ctx, p.Order.UserID, p.BalanceToDeduct)
+		deducted, err := s.deductAvailableBalance(ctx, p.OrderID, p.Order.UserID, p.BalanceToDeduct)
 		if err != nil {
 			return fmt.Errorf("deduction: %w", err)
 		}
@@ -616,6 +618,7 @@ func (s *PaymentService) markRefundOk(ctx context.Context, p *RefundPlan) (*Refu
 		return nil, fmt.Errorf("mark refund: %w", err)
 	}
 	s.writeAuditLog(ctx, p.OrderID, "REFUND_SUCCESS", "admin", map[string]any{"refundAmount": p.RefundAmount, "reason": p.Reason, "balanceDeducted": p.BalanceToDeduct, "force": p.Force})
+	s.issueOrderInvoicesBestEffort(ctx, p.OrderID)
 	return &RefundResult{Success: true, BalanceDeducted: p.BalanceToDeduct, SubDaysDeducted: p.SubDaysToDeduct}, nil
 }
 
@@ -695,7 +698,8 @@ func refundResponseID(resp *payment.RefundResponse) string {
 
 func (s *PaymentService) RollbackRefund(ctx context.Context, p *RefundPlan, gErr error) bool {
 	if p.DeductionType == payment.DeductionTypeBalance && p.BalanceToDeduct > 0 {
-		if err := s.userRepo.UpdateBalance(ctx, p.Order.UserID, p.BalanceToDeduct); err != nil {
+		rollbackCtx := WithBalanceLedgerTag(ctx, paymentOrderBalanceLedgerTag(BalanceLedgerTypeRefundRollback, p.OrderID))
+		if err := s.userRepo.UpdateBalance(rollbackCtx, p.Order.UserID, p.BalanceToDeduct); err != nil {
 			slog.Error("[CRITICAL] rollback failed", "orderID", p.OrderID, "amount", p.BalanceToDeduct, "error", err)
 			s.writeAuditLog(ctx, p.OrderID, "REFUND_ROLLBACK_FAILED", "admin", map[string]any{"gatewayError": psErrMsg(gErr), "rollbackError": psErrMsg(err), "balanceDeducted": p.BalanceToDeduct})
 			return false
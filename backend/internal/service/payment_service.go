@@ -85,6 +85,12 @@ type CreateOrderRequest struct {
 	OrderType       string
 	PlanID          int64
 	Locale          string
+	// AutoRenew 要求服务商保存支付方式，并在订阅到期前自动续费（仅订阅订单）。
+	AutoRenew bool
+	// 可选的发票抬头，开票时写入购买方信息。
+	BuyerName    string
+	BuyerTaxID   string
+	BuyerAddress string
 }
 
 type CreateOrderResponse struct {
@@ -198,6 +204,12 @@ type PaymentService struct {
 	resumeService            *PaymentResumeService
 	affiliateService         *AffiliateService
 	notificationEmailService *NotificationEmailService
+	autoRenewalRepo          SubscriptionAutoRenewalRepository
+	invoiceRepo              PaymentInvoiceRepository
+	invoiceFontPath          string
+	invoiceFontOnce          sync.Once
+	invoiceFont              []byte
+	invoiceFontErr           error
 }
 
 func NewPaymentService(entClient *dbent.Client, registry *payment.Registry, loadBalancer payment.LoadBalancer, redeemService *RedeemService, subscriptionSvc *SubscriptionService, configService *PaymentConfigService, userRepo UserRepository, groupRepo GroupRepository, affiliateService *AffiliateService) *PaymentService {
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// prometheusOpsStatsTTL 账号池/并发统计需要扫描全部账号并批量读取 Redis，
+	// 缓存一个典型抓取周期，避免多个 Prometheus 副本并发抓取时重复计算。
+	prometheusOpsStatsTTL     = 15 * time.Second
+	prometheusOpsStatsTimeout = 5 * time.Second
+)
+
+// PrometheusCollector 在抓取时把进程内快照与运维统计转换为 Prometheus 指标。
+//
+// 覆盖范围：账号池可用性、账号并发槽位、使用量记录 worker 池与 OpenAI 调度器。
+// 请求级计数/延迟由网关中间件通过 metrics 包直接上报，不经过这里。
+type PrometheusCollector struct {
+	opsService            *OpsService
+	openAIGateway         *OpenAIGatewayService
+	usageRecordWorkerPool *UsageRecordWorkerPool
+
+	mu            sync.Mutex
+	opsCachedAt   time.Time
+	availability  map[string]*PlatformAvailability
+	concurrency   map[string]*PlatformConcurrencyInfo
+	opsStatsValid bool
+
+	accountPoolDesc         *prometheus.Desc
+	concurrencyInUseDesc    *prometheus.Desc
+	concurrencyCapacityDesc *prometheus.Desc
+	concurrencyWaitingDesc  *prometheus.Desc
+
+	usagePoolQueueDepthDesc *prometheus.Desc
+	usagePoolRunningDesc    *prometheus.Desc
+	usagePoolMaxDesc        *prometheus.Desc
+	usagePoolTasksDesc      *prometheus.Desc
+
+	schedulerSelectDesc      *prometheus.Desc
+	schedulerSwitchDesc      *prometheus.Desc
+	schedulerLatencyDesc     *prometheus.Desc
+	schedulerLoadSkewDesc    *prometheus.Desc
+	schedulerRuntimeAcctDesc *prometheus.Desc
+}
+
+// NewPrometheusCollector 创建 Prometheus 快照采集器。
+func NewPrometheusCollector(opsService *OpsService, openAIGateway *OpenAIGatewayService, usageRecordWorkerPool *UsageRecordWorkerPool) *PrometheusCollector {
+	desc := func(subsystem, name, help string, labels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(metrics.Namespace, subsystem, name), help, labels, nil)
+	}
+	return &PrometheusCollector{
+		opsService:            opsService,
+		openAIGateway:         openAIGateway,
+		usageRecordWorkerPool: usageRecordWorkerPool,
+
+		accountPoolDesc:         desc("account_pool", "accounts", "Accounts by platform and availability state (total/available/rate_limited/error).", "platform", "state"),
+		concurrencyInUseDesc:    desc("concurrency", "slots_in_use", "Account concurrency slots currently held, by platform.", "platform"),
+		concurrencyCapacityDesc: desc("concurrency", "slots_capacity", "Total account concurrency capacity, by platform.", "platform"),
+		concurrencyWaitingDesc:  desc("concurrency", "waiting_requests", "Requests waiting for an account concurrency slot, by platform.", "platform"),
+
+		usagePoolQueueDepthDesc: desc("usage_record_pool", "queue_depth", "Usage record tasks waiting in the worker pool queue."),
+		usagePoolRunningDesc:    desc("usage_record_pool", "running_workers", "Usage record workers currently running."),
+		usagePoolMaxDesc:        desc("usage_record_pool", "max_workers", "Current usage record worker pool concurrency limit."),
+		usagePoolTasksDesc:      desc("usage_record_pool", "tasks_total", "Usage record tasks by outcome (submitted/succeeded/failed/dropped/sync_fallback).", "outcome"),
+
+		schedulerSelectDesc:      desc("openai_scheduler", "selections_total", "OpenAI account scheduler selections by path (sticky_previous/sticky_session/load_balance/all).", "path"),
+		schedulerSwitchDesc:      desc("openai_scheduler", "account_switches_total", "OpenAI account switches during failover."),
+		schedulerLatencyDesc:     desc("openai_scheduler", "latency_ms_avg", "Average OpenAI account scheduling latency in milliseconds."),
+		schedulerLoadSkewDesc:    desc("openai_scheduler", "load_skew_avg", "Average load skew across candidate accounts at selection time."),
+		schedulerRuntimeAcctDesc: desc("openai_scheduler", "runtime_stats_accounts", "Accounts tracked by the OpenAI scheduler runtime stats."),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *PrometheusCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{
+		c.accountPoolDesc, c.concurrencyInUseDesc, c.concurrencyCapacityDesc, c.concurrencyWaitingDesc,
+		c.usagePoolQueueDepthDesc, c.usagePoolRunningDesc, c.usagePoolMaxDesc, c.usagePoolTasksDesc,
+		c.schedulerSelectDesc, c.schedulerSwitchDesc, c.schedulerLatencyDesc, c.schedulerLoadSkewDesc, c.schedulerRuntimeAcctDesc,
+	} {
+		ch <- d
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (c *PrometheusCollector) Collect(ch chan<- prometheus.Metric) {
+	c.collectOpsStats(ch)
+	c.collectUsageRecordPool(ch)
+	c.collectOpenAIScheduler(ch)
+}
+
+func (c *PrometheusCollector) collectOpsStats(ch chan<- prometheus.Metric) {
+	availability, concurrency, ok := c.loadOpsStats()
+	if !ok {
+		return
+	}
+	for platform, p := range availability {
+		if p == nil {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(c.accountPoolDesc, prometheus.GaugeValue, float64(p.TotalAccounts), platform, "total")
+		ch <- prometheus.MustNewConstMetric(c.accountPoolDesc, prometheus.GaugeValue, float64(p.AvailableCount), platform, "available")
+		ch <- prometheus.MustNewConstMetric(c.accountPoolDesc, prometheus.GaugeValue, float64(p.RateLimitCount), platform, "rate_limited")
+		ch <- prometheus.MustNewConstMetric(c.accountPoolDesc, prometheus.GaugeValue, float64(p.ErrorCount), platform, "error")
+	}
+	for platform, p := range concurrency {
+		if p == nil {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(c.concurrencyInUseDesc, prometheus.GaugeValue, float64(p.CurrentInUse), platform)
+		ch <- prometheus.MustNewConstMetric(c.concurrencyCapacityDesc, prometheus.GaugeValue, float64(p.MaxCapacity), platform)
+		ch <- prometheus.MustNewConstMetric(c.concurrencyWaitingDesc, prometheus.GaugeValue, float64(p.WaitingInQueue), platform)
+	}
+}
+
+// loadOpsStats 返回（可能缓存的）平台级账号可用性与并发统计。
+// 运维监控关闭或查询失败时返回 ok=false，对应指标本轮不输出。
+func (c *PrometheusCollector) loadOpsStats() (map[string]*PlatformAvailability, map[string]*PlatformConcurrencyInfo, bool) {
+	if c.opsService == nil {
+		return nil, nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.opsCachedAt.IsZero() && time.Since(c.opsCachedAt) < prometheusOpsStatsTTL {
+		return c.availability, c.concurrency, c.opsStatsValid
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), prometheusOpsStatsTimeout)
+	defer cancel()
+
+	c.opsCachedAt = time.Now()
+	c.opsStatsValid = false
+	if !c.opsService.IsMonitoringEnabled(ctx) {
+		return nil, nil, false
+	}
+	availability, _, _, _, err := c.opsService.GetAccountAvailabilityStats(ctx, "", nil)
+	if err != nil {
+		logger.LegacyPrintf("service.prometheus_collector", "[PrometheusCollector] account availability failed: %v", err)
+		return nil, nil, false
+	}
+	concurrency, _, _, _, err := c.opsService.GetConcurrencyStats(ctx, "", nil)
+	if err != nil {
+		logger.LegacyPrintf("service.prometheus_collector", "[PrometheusCollector] concurrency stats failed: %v", err)
+		return nil, nil, false
+	}
+	c.availability = availability
+	c.concurrency = concurrency
+	c.opsStatsValid = true
+	return availability, concurrency, true
+}
+
+func (c *PrometheusCollector) collectUsageRecordPool(ch chan<- prometheus.Metric) {
+	if c.usageRecordWorkerPool == nil {
+		return
+	}
+	stats := c.usageRecordWorkerPool.Stats()
+	ch <- prometheus.MustNewConstMetric(c.usagePoolQueueDepthDesc, prometheus.GaugeValue, float64(stats.WaitingTasks))
+	ch <- prometheus.MustNewConstMetric(c.usagePoolRunningDesc, prometheus.GaugeValue, float64(stats.RunningWorkers))
+	ch <- prometheus.MustNewConstMetric(c.usagePoolMaxDesc, prometheus.GaugeValue, float64(stats.MaxConcurrency))
+	ch <- prometheus.MustNewConstMetric(c.usagePoolTasksDesc, prometheus.CounterValue, float64(stats.SubmittedTasks), "submitted")
+	ch <- prometheus.MustNewConstMetric(c.usagePoolTasksDesc, prometheus.CounterValue, float64(stats.SuccessfulTasks), "succeeded")
+	ch <- prometheus.MustNewConstMetric(c.usagePoolTasksDesc, prometheus.CounterValue, float64(stats.FailedTasks), "failed")
+	ch <- prometheus.MustNewConstMetric(c.usagePoolTasksDesc, prometheus.CounterValue, float64(stats.DroppedQueueFull+stats.DroppedPoolStopped), "dropped")
+	ch <- prometheus.MustNewConstMetric(c.usagePoolTasksDesc, prometheus.CounterValue, float64(stats.SyncFallbackTasks), "sync_fallback")
+}
+
+func (c *PrometheusCollector) collectOpenAIScheduler(ch chan<- prometheus.Metric) {
+	if c.openAIGateway == nil {
+		return
+	}
+	snap := c.openAIGateway.SnapshotOpenAIAccountSchedulerMetrics()
+	ch <- prometheus.MustNewConstMetric(c.schedulerSelectDesc, prometheus.CounterValue, float64(snap.SelectTotal), "all")
+	ch <- prometheus.MustNewConstMetric(c.schedulerSelectDesc, prometheus.CounterValue, float64(snap.StickyPreviousHitTotal), "sticky_previous")
+	ch <- prometheus.MustNewConstMetric(c.schedulerSelectDesc, prometheus.CounterValue, float64(snap.StickySessionHitTotal), "sticky_session")
+	ch <- prometheus.MustNewConstMetric(c.schedulerSelectDesc, prometheus.CounterValue, float64(snap.LoadBalanceSelectTotal), "load_balance")
+	ch <- prometheus.MustNewConstMetric(c.schedulerSwitchDesc, prometheus.CounterValue, float64(snap.AccountSwitchTotal))
+	ch <- prometheus.MustNewConstMetric(c.schedulerLatencyDesc, prometheus.GaugeValue, snap.SchedulerLatencyMsAvg)
+	ch <- prometheus.MustNewConstMetric(c.schedulerLoadSkewDesc, prometheus.GaugeValue, snap.LoadSkewAvg)
+	ch <- prometheus.MustNewConstMetric(c.schedulerRuntimeAcctDesc, prometheus.GaugeValue, float64(snap.RuntimeStatsAccountCount))
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -124,7 +125,12 @@ func (s *PromoService) ApplyPromoCode(ctx context.Context, userID int64, code st
 	}
 
 	// 增加用户余额
-	if err := s.userRepo.UpdateBalance(txCtx, userID, promoCode.BonusAmount); err != nil {
+	balanceCtx := WithBalanceLedgerTag(txCtx, BalanceLedgerTag{
+		Type:          BalanceLedgerTypePromo,
+		ReferenceType: BalanceLedgerRefPromoCode,
+		ReferenceID:   strconv.FormatInt(promoCode.ID, 10),
+	})
+	if err := s.userRepo.UpdateBalance(balanceCtx, userID, promoCode.BonusAmount); err != nil {
 		return fmt.Errorf("update user balance: %w", err)
 	}
 
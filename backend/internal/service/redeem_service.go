@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -457,14 +458,19 @@ func (s *RedeemService) Redeem(ctx context.Context, userID int64, code string) (
 	switch redeemCode.Type {
 	case RedeemTypeBalance:
 		amount := redeemCode.Value
+		balanceCtx := withDefaultBalanceLedgerTag(txCtx, BalanceLedgerTag{
+			Type:          BalanceLedgerTypeRedeem,
+			ReferenceType: BalanceLedgerRefRedeemCode,
+			ReferenceID:   strconv.FormatInt(redeemCode.ID, 10),
+		})
 		if amount < 0 {
 			if s.redeemUserRepo == nil {
 				return nil, errors.New("user repository does not support atomic redeem balance adjustments")
 			}
-			if err := s.redeemUserRepo.ApplyRedeemBalanceAdjustment(txCtx, userID, amount); err != nil {
+			if err := s.redeemUserRepo.ApplyRedeemBalanceAdjustment(balanceCtx, userID, amount); err != nil {
 				return nil, fmt.Errorf("update user balance: %w", err)
 			}
-		} else if err := s.userRepo.UpdateBalance(txCtx, userID, amount); err != nil {
+		} else if err := s.userRepo.UpdateBalance(balanceCtx, userID, amount); err != nil {
 			return nil, fmt.Errorf("update user balance: %w", err)
 		}
 
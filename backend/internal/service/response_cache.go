@@ -0,0 +1,404 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/tidwall/gjson"
+)
+
+// 精确匹配响应缓存：对归一化后请求体完全一致的确定性请求直接回放上次的成功响应。
+//
+// 缓存键 = 分组 + 用户 + 入口端点 + 归一化请求体哈希；归一化会按键排序重新序列化 JSON，
+// 并剔除不影响输出的调用方标识（metadata / user）。缓存按用户隔离，一个用户的响应不会回放给他人。命中时回放原始状态码、
+// Content-Type 与响应体（JSON 或完整 SSE 流），按缓存时记录的 usage 乘以分组命中倍率计费。
+
+const (
+	ResponseCacheEndpointMessages        = "/v1/messages"
+	ResponseCacheEndpointChatCompletions = "/v1/chat/completions"
+	ResponseCacheEndpointResponses       = "/v1/responses"
+
+	// ResponseCacheHeader 标记响应是否来自缓存（HIT / MISS）。
+	ResponseCacheHeader = "X-Sub2API-Response-Cache"
+
+	defaultResponseCacheHitMultiplier = 0.1
+	responseCacheKeyPrefix            = "response_cache:"
+	responseCacheUsageRequestPrefix   = "response_cache:"
+	responseCacheStoreTimeout         = 10 * time.Second
+)
+
+// ErrResponseCacheMiss 表示缓存中没有对应条目（或已过期）。
+var ErrResponseCacheMiss = errors.New("response cache miss")
+
+// ResponseCacheEntry 是一条可回放的缓存响应。
+type ResponseCacheEntry struct {
+	StatusCode  int         `json:"status_code"`
+	ContentType string      `json:"content_type"`
+	Stream      bool        `json:"stream"`
+	Body        []byte      `json:"body"`
+	Model       string      `json:"model"`
+	Usage       UsageTokens `json:"usage"`
+	// AccountID 产生该响应的上游账号，命中记录沿用它以满足 usage_logs.account_id 约束。
+	AccountID int64     `json:"account_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ResponseCacheStore 响应缓存存储（Redis 或 S3 兼容对象存储）。
+type ResponseCacheStore interface {
+	// Get 未命中或已过期时返回 ErrResponseCacheMiss。
+	Get(ctx context.Context, key string) (*ResponseCacheEntry, error)
+	Set(ctx context.Context, key string, entry *ResponseCacheEntry, ttl time.Duration) error
+}
+
+// ResponseCacheRequest 是一次可缓存请求的解析结果。
+type ResponseCacheRequest struct {
+	Key      string
+	Endpoint string
+	Model    string
+	Stream   bool
+	TTL      time.Duration
+}
+
+// ResponseCacheService 负责判定请求是否可缓存、读写缓存以及命中计费。
+type ResponseCacheService struct {
+	cfg            *config.Config
+	store          ResponseCacheStore
+	gatewayService *GatewayService
+}
+
+func NewResponseCacheService(cfg *config.Config, store ResponseCacheStore, gatewayService *GatewayService) *ResponseCacheService {
+	return &ResponseCacheService{cfg: cfg, store: store, gatewayService: gatewayService}
+}
+
+// Enabled 返回全局开关是否打开。
+func (s *ResponseCacheService) Enabled() bool {
+	return s != nil && s.cfg != nil && s.cfg.ResponseCache.Enabled && s.store != nil
+}
+
+// EnabledForGroup 返回该分组是否启用了响应缓存。composite 分组按实际转发的
+// 具体模型计费，命中时无法还原，因此不参与缓存。
+func (s *ResponseCacheService) EnabledForGroup(group *Group) bool {
+	return s.Enabled() && group != nil && group.ResponseCacheEnabled && group.Platform != PlatformComposite
+}
+
+// MaxBodyBytes 返回可缓存的请求体/响应体字节上限。
+func (s *ResponseCacheService) MaxBodyBytes() int64 {
+	if s == nil || s.cfg == nil {
+		return 0
+	}
+	return s.cfg.ResponseCache.MaxBodyBytes
+}
+
+// ValidateResponseCacheConfig 校验分组的响应缓存配置。
+func ValidateResponseCacheConfig(ttlSeconds int, hitMultiplier float64) error {
+	if ttlSeconds < 0 {
+		return errors.New("response_cache_ttl_seconds must be >= 0")
+	}
+	if hitMultiplier < 0 {
+		return errors.New("response_cache_hit_multiplier must be >= 0")
+	}
+	return nil
+}
+
+// PrepareRequest 判断请求是否可缓存并计算缓存键。variant 用于区分会改变输出的请求头
+// （如 anthropic-beta），不可缓存时返回 false。
+func (s *ResponseCacheService) PrepareRequest(group *Group, userID int64, endpoint string, body []byte, variant string) (*ResponseCacheRequest, bool) {
+	if !s.EnabledForGroup(group) || userID <= 0 || len(body) == 0 {
+		return nil, false
+	}
+	if limit := s.MaxBodyBytes(); limit > 0 && int64(len(body)) > limit {
+		return nil, false
+	}
+	canonical, model, stream, ok := normalizeResponseCacheBody(endpoint, body, s.cfg.ResponseCache.RequireZeroTemperature)
+	if !ok {
+		return nil, false
+	}
+	sum := sha256.New()
+	_, _ = sum.Write(canonical)
+	_, _ = sum.Write([]byte{'\n'})
+	_, _ = sum.Write([]byte(strings.TrimSpace(variant)))
+	ttl := time.Duration(group.ResponseCacheTTLSeconds) * time.Second
+	if ttl <= 0 {
+		ttl = time.Duration(s.cfg.ResponseCache.DefaultTTLSeconds) * time.Second
+	}
+	return &ResponseCacheRequest{
+		Key:      responseCacheKeyPrefix + strconv.FormatInt(group.ID, 10) + ":" + strconv.FormatInt(userID, 10) + ":" + strings.Trim(endpoint, "/") + ":" + hex.EncodeToString(sum.Sum(nil)),
+		Endpoint: endpoint,
+		Model:    model,
+		Stream:   stream,
+		TTL:      ttl,
+	}, true
+}
+
+// normalizeResponseCacheBody 按键排序重新序列化请求体，剔除调用方标识字段。
+// 只接受确定性请求：temperature 非 0 的请求一律不缓存；requireZeroTemperature 时
+// 还要求显式声明 temperature=0。
+func normalizeResponseCacheBody(endpoint string, body []byte, requireZeroTemperature bool) ([]byte, string, bool, bool) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var payload map[string]any
+	if err := dec.Decode(&payload); err != nil || payload == nil {
+		return nil, "", false, false
+	}
+	if dec.More() {
+		return nil, "", false, false
+	}
+	model, _ := payload["model"].(string)
+	if strings.TrimSpace(model) == "" {
+		return nil, "", false, false
+	}
+	switch temperature := payload["temperature"].(type) {
+	case nil:
+		if requireZeroTemperature {
+			return nil, "", false, false
+		}
+	case json.Number:
+		if f, err := temperature.Float64(); err != nil || f != 0 {
+			return nil, "", false, false
+		}
+	default:
+		return nil, "", false, false
+	}
+	if endpoint == ResponseCacheEndpointResponses {
+		// 依赖服务端会话状态或异步执行的请求无法安全回放。
+		if _, ok := payload["previous_response_id"]; ok {
+			return nil, "", false, false
+		}
+		if background, _ := payload["background"].(bool); background {
+			return nil, "", false, false
+		}
+	}
+	stream, _ := payload["stream"].(bool)
+	delete(payload, "metadata")
+	delete(payload, "user")
+	canonical, err := json.Marshal(canonicalizeResponseCacheValue(payload))
+	if err != nil {
+		return nil, "", false, false
+	}
+	return canonical, model, stream, true
+}
+
+// canonicalizeResponseCacheValue 统一数字写法（0 / 0.0 / 1e0 等），整数保持原精度。
+func canonicalizeResponseCacheValue(v any) any {
+	switch value := v.(type) {
+	case map[string]any:
+		for k, item := range value {
+			value[k] = canonicalizeResponseCacheValue(item)
+		}
+		return value
+	case []any:
+		for i, item := range value {
+			value[i] = canonicalizeResponseCacheValue(item)
+		}
+		return value
+	case json.Number:
+		if n, err := value.Int64(); err == nil {
+			return json.Number(strconv.FormatInt(n, 10))
+		}
+		if f, err := value.Float64(); err == nil {
+			return json.Number(strconv.FormatFloat(f, 'g', -1, 64))
+		}
+		return value
+	default:
+		return v
+	}
+}
+
+// Lookup 读取缓存；存储异常按未命中处理，不影响正常转发。
+func (s *ResponseCacheService) Lookup(ctx context.Context, req *ResponseCacheRequest) (*ResponseCacheEntry, bool) {
+	if !s.Enabled() || req == nil {
+		return nil, false
+	}
+	entry, err := s.store.Get(ctx, req.Key)
+	if err != nil {
+		if !errors.Is(err, ErrResponseCacheMiss) {
+			slog.Warn("response_cache_get_failed", "key", req.Key, "error", err)
+		}
+		return nil, false
+	}
+	if entry == nil || entry.Stream != req.Stream || len(entry.Body) == 0 {
+		return nil, false
+	}
+	return entry, true
+}
+
+// Store 解析上游成功响应的 usage 后异步写入缓存；usage 不完整（如流被中断）时不缓存。
+func (s *ResponseCacheService) Store(ctx context.Context, req *ResponseCacheRequest, statusCode int, contentType string, body []byte, accountID int64) {
+	if !s.Enabled() || req == nil || statusCode != 200 || len(body) == 0 || accountID <= 0 {
+		return
+	}
+	usage, model, ok := ParseResponseCacheUsage(req.Endpoint, req.Stream, body)
+	if !ok {
+		return
+	}
+	if model == "" {
+		model = req.Model
+	}
+	entry := &ResponseCacheEntry{
+		StatusCode:  statusCode,
+		ContentType: contentType,
+		Stream:      req.Stream,
+		Body:        append([]byte(nil), body...),
+		Model:       model,
+		Usage:       usage,
+		AccountID:   accountID,
+		CreatedAt:   time.Now().UTC(),
+	}
+	storeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), responseCacheStoreTimeout)
+	go func() {
+		defer cancel()
+		if err := s.store.Set(storeCtx, req.Key, entry, req.TTL); err != nil {
+			slog.Warn("response_cache_set_failed", "key", req.Key, "error", err)
+		}
+	}()
+}
+
+// ModelRestricted 命中前复查渠道模型限制：缓存条目可能产生于限制调整之前。
+func (s *ResponseCacheService) ModelRestricted(ctx context.Context, groupID *int64, model string) bool {
+	if s == nil || s.gatewayService == nil {
+		return false
+	}
+	_, restricted := s.gatewayService.ResolveChannelMappingAndRestrict(ctx, groupID, model)
+	return restricted
+}
+
+// RecordHit 按缓存条目记录的 usage 计费并写入用量日志。
+func (s *ResponseCacheService) RecordHit(ctx context.Context, input *ResponseCacheHitInput) error {
+	if s == nil || s.gatewayService == nil {
+		return nil
+	}
+	return s.gatewayService.RecordResponseCacheHit(ctx, input)
+}
+
+// ParseResponseCacheUsage 从完整的上游响应（JSON 或 SSE）中解析 usage 与响应模型。
+// 流式响应必须包含结束事件，否则视为不完整返回 false。
+func ParseResponseCacheUsage(endpoint string, stream bool, body []byte) (UsageTokens, string, bool) {
+	if !stream {
+		if !gjson.ValidBytes(body) {
+			return UsageTokens{}, "", false
+		}
+		root := gjson.ParseBytes(body)
+		switch endpoint {
+		case ResponseCacheEndpointMessages:
+			return parseAnthropicCacheUsage(root.Get("usage")), root.Get("model").String(), root.Get("usage").Exists()
+		case ResponseCacheEndpointChatCompletions:
+			return parseChatCompletionsCacheUsage(root.Get("usage")), root.Get("model").String(), root.Get("usage").Exists()
+		case ResponseCacheEndpointResponses:
+			if status := root.Get("status").String(); status != "" && status != "completed" {
+				return UsageTokens{}, "", false
+			}
+			return parseResponsesCacheUsage(root.Get("usage")), root.Get("model").String(), root.Get("usage").Exists()
+		}
+		return UsageTokens{}, "", false
+	}
+
+	var (
+		usage    UsageTokens
+		model    string
+		hasUsage bool
+		complete bool
+	)
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(body)+1)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			if endpoint == ResponseCacheEndpointChatCompletions {
+				complete = true
+			}
+			continue
+		}
+		if !gjson.Valid(data) {
+			continue
+		}
+		event := gjson.Parse(data)
+		switch endpoint {
+		case ResponseCacheEndpointMessages:
+			switch event.Get("type").String() {
+			case "message_start":
+				msg := event.Get("message")
+				model = msg.Get("model").String()
+				usage = parseAnthropicCacheUsage(msg.Get("usage"))
+				hasUsage = msg.Get("usage").Exists()
+			case "message_delta":
+				delta := event.Get("usage")
+				if delta.Get("output_tokens").Exists() {
+					usage.OutputTokens = int(delta.Get("output_tokens").Int())
+				}
+				if delta.Get("input_tokens").Int() > 0 {
+					usage.InputTokens = int(delta.Get("input_tokens").Int())
+				}
+			case "message_stop":
+				complete = true
+			case "error":
+				return UsageTokens{}, "", false
+			}
+		case ResponseCacheEndpointChatCompletions:
+			if m := event.Get("model").String(); m != "" {
+				model = m
+			}
+			if u := event.Get("usage"); u.IsObject() {
+				usage = parseChatCompletionsCacheUsage(u)
+				hasUsage = true
+			}
+		case ResponseCacheEndpointResponses:
+			switch event.Get("type").String() {
+			case "response.completed":
+				resp := event.Get("response")
+				model = resp.Get("model").String()
+				usage = parseResponsesCacheUsage(resp.Get("usage"))
+				hasUsage = resp.Get("usage").Exists()
+				complete = true
+			case "response.failed", "response.incomplete", "error":
+				return UsageTokens{}, "", false
+			}
+		}
+	}
+	if scanner.Err() != nil || !complete || !hasUsage {
+		return UsageTokens{}, "", false
+	}
+	return usage, model, true
+}
+
+func parseAnthropicCacheUsage(u gjson.Result) UsageTokens {
+	return UsageTokens{
+		InputTokens:           int(u.Get("input_tokens").Int()),
+		OutputTokens:          int(u.Get("output_tokens").Int()),
+		CacheCreationTokens:   int(u.Get("cache_creation_input_tokens").Int()),
+		CacheReadTokens:       int(u.Get("cache_read_input_tokens").Int()),
+		CacheCreation5mTokens: int(u.Get("cache_creation.ephemeral_5m_input_tokens").Int()),
+		CacheCreation1hTokens: int(u.Get("cache_creation.ephemeral_1h_input_tokens").Int()),
+	}
+}
+
+func parseChatCompletionsCacheUsage(u gjson.Result) UsageTokens {
+	cached := int(u.Get("prompt_tokens_details.cached_tokens").Int())
+	return UsageTokens{
+		InputTokens:     max(int(u.Get("prompt_tokens").Int())-cached, 0),
+		OutputTokens:    int(u.Get("completion_tokens").Int()),
+		CacheReadTokens: cached,
+	}
+}
+
+func parseResponsesCacheUsage(u gjson.Result) UsageTokens {
+	cached := int(u.Get("input_tokens_details.cached_tokens").Int())
+	return UsageTokens{
+		InputTokens:     max(int(u.Get("input_tokens").Int())-cached, 0),
+		OutputTokens:    int(u.Get("output_tokens").Int()),
+		CacheReadTokens: cached,
+	}
+}
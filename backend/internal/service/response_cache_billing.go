@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// ResponseCacheHitInput 响应缓存命中的计费输入。
+type ResponseCacheHitInput struct {
+	Entry         *ResponseCacheEntry
+	Endpoint      string
+	RequestModel  string
+	APIKey        *APIKey
+	User          *User
+	Subscription  *UserSubscription
+	UserAgent     string
+	IPAddress     string
+	APIKeyService APIKeyQuotaUpdater
+	QuotaPlatform string
+}
+
+// RecordResponseCacheHit 对缓存命中按原价 × 用户/分组倍率 × 分组命中倍率计费。
+// 命中不消耗上游账号额度：账号倍率与账号统计费用均记为 0，
+// account_id 沿用产生缓存的账号以满足 usage_logs 外键约束。
+func (s *GatewayService) RecordResponseCacheHit(ctx context.Context, input *ResponseCacheHitInput) error {
+	if input == nil || input.Entry == nil || input.APIKey == nil || input.User == nil {
+		return nil
+	}
+	entry := input.Entry
+	apiKey := input.APIKey
+	user := input.User
+	group := apiKey.Group
+
+	multiplier := 1.0
+	if s.cfg != nil {
+		multiplier = s.cfg.Default.RateMultiplier
+	}
+	hitMultiplier := defaultResponseCacheHitMultiplier
+	if apiKey.GroupID != nil && group != nil {
+		multiplier = s.ResolveUserGroupRateMultiplier(ctx, user.ID, *apiKey.GroupID, group.RateMultiplier)
+		hitMultiplier = group.ResponseCacheHitMultiplier
+	}
+	now := time.Now()
+	multiplier, volumeTier := applyGroupVolumeTier(ctx, s.volumeTierResolver, apiKey, user.ID, multiplier, now)
+	multiplier, _ = computePeakAwareMultipliers(apiKey, multiplier, now)
+	effectiveMultiplier := multiplier * hitMultiplier
+
+	model := entry.Model
+	cost, err := s.billingService.CalculateCost(model, entry.Usage, effectiveMultiplier)
+	if (err != nil || cost == nil) && input.RequestModel != "" && input.RequestModel != model {
+		model = input.RequestModel
+		cost, err = s.billingService.CalculateCost(model, entry.Usage, effectiveMultiplier)
+	}
+	if err != nil || cost == nil {
+		logger.LegacyPrintf("service.response_cache", "Calculate cache hit cost failed: model=%s err=%v", entry.Model, err)
+		cost = &CostBreakdown{}
+	}
+
+	isSubscriptionBilling := input.Subscription != nil && group != nil && group.IsSubscriptionType()
+	billingType := BillingTypeBalance
+	if isSubscriptionBilling {
+		billingType = BillingTypeSubscription
+	}
+	requestType := RequestTypeSync
+	if entry.Stream {
+		requestType = RequestTypeStream
+	}
+	billingMode := string(BillingModeToken)
+	zero := 0.0
+	responseCacheMultiplier := hitMultiplier
+	usageLog := &UsageLog{
+		UserID:                  user.ID,
+		APIKeyID:                apiKey.ID,
+		AccountID:               entry.AccountID,
+		RequestID:               responseCacheUsageRequestPrefix + uuid.NewString(),
+		Model:                   model,
+		RequestedModel:          input.RequestModel,
+		InboundEndpoint:         optionalTrimmedStringPtr(input.Endpoint),
+		GroupID:                 apiKey.GroupID,
+		SubscriptionID:          optionalSubscriptionID(input.Subscription),
+		InputTokens:             entry.Usage.InputTokens,
+		OutputTokens:            entry.Usage.OutputTokens,
+		CacheCreationTokens:     entry.Usage.CacheCreationTokens,
+		CacheReadTokens:         entry.Usage.CacheReadTokens,
+		CacheCreation5mTokens:   entry.Usage.CacheCreation5mTokens,
+		CacheCreation1hTokens:   entry.Usage.CacheCreation1hTokens,
+		InputCost:               cost.InputCost,
+		OutputCost:              cost.OutputCost,
+		CacheCreationCost:       cost.CacheCreationCost,
+		CacheReadCost:           cost.CacheReadCost,
+		TotalCost:               cost.TotalCost,
+		ActualCost:              cost.ActualCost,
+		RateMultiplier:          effectiveMultiplier,
+		AccountRateMultiplier:   &zero,
+		AccountStatsCost:        &zero,
+		ResponseCacheMultiplier: &responseCacheMultiplier,
+		BillingType:             billingType,
+		RequestType:             requestType,
+		Stream:                  entry.Stream,
+		BillingMode:             &billingMode,
+		BillingTier:             volumeTier,
+		UserAgent:               optionalTrimmedStringPtr(input.UserAgent),
+		IPAddress:               optionalTrimmedStringPtr(input.IPAddress),
+		CreatedAt:               now,
+	}
+
+	if s.cfg != nil && s.cfg.RunMode == config.RunModeSimple {
+		writeUsageLogBestEffort(ctx, s.usageLogRepo, usageLog, "service.response_cache")
+		return nil
+	}
+
+	quotaPlatform := input.QuotaPlatform
+	if quotaPlatform == "" {
+		quotaPlatform = PlatformFromAPIKey(apiKey)
+	}
+	_, billingErr := applyUsageBilling(ctx, usageLog.RequestID, usageLog, &postUsageBillingParams{
+		Cost:               cost,
+		User:               user,
+		APIKey:             apiKey,
+		Account:            &Account{ID: entry.AccountID},
+		Subscription:       input.Subscription,
+		IsSubscriptionBill: isSubscriptionBilling,
+		APIKeyService:      input.APIKeyService,
+		Platform:           quotaPlatform,
+	}, s.billingDeps(), s.usageBillingRepo)
+	if billingErr != nil {
+		usageLog.ActualCost = 0
+		writeUsageLogBestEffort(ctx, s.usageLogRepo, usageLog, "service.response_cache")
+		return billingErr
+	}
+	recordGroupVolumeTierSpend(s.volumeTierResolver, apiKey, user.ID, now, usageLog.ActualCost)
+	writeUsageLogBestEffort(ctx, s.usageLogRepo, usageLog, "service.response_cache")
+	return nil
+}
@@ -0,0 +1,264 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeResponseCacheStore struct {
+	mu      sync.Mutex
+	entries map[string]*ResponseCacheEntry
+	ttls    map[string]time.Duration
+	setCh   chan string
+}
+
+func newFakeResponseCacheStore() *fakeResponseCacheStore {
+	return &fakeResponseCacheStore{
+		entries: make(map[string]*ResponseCacheEntry),
+		ttls:    make(map[string]time.Duration),
+		setCh:   make(chan string, 4),
+	}
+}
+
+func (f *fakeResponseCacheStore) Get(_ context.Context, key string) (*ResponseCacheEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	entry, ok := f.entries[key]
+	if !ok {
+		return nil, ErrResponseCacheMiss
+	}
+	return entry, nil
+}
+
+func (f *fakeResponseCacheStore) Set(_ context.Context, key string, entry *ResponseCacheEntry, ttl time.Duration) error {
+	f.mu.Lock()
+	f.entries[key] = entry
+	f.ttls[key] = ttl
+	f.mu.Unlock()
+	f.setCh <- key
+	return nil
+}
+
+func newTestResponseCacheService(store ResponseCacheStore) *ResponseCacheService {
+	cfg := &config.Config{}
+	cfg.ResponseCache = config.ResponseCacheConfig{
+		Enabled:                true,
+		Backend:                "redis",
+		DefaultTTLSeconds:      600,
+		MaxBodyBytes:           1 << 20,
+		RequireZeroTemperature: true,
+	}
+	return NewResponseCacheService(cfg, store, nil)
+}
+
+func TestResponseCacheService_PrepareRequestNormalizesBody(t *testing.T) {
+	svc := newTestResponseCacheService(newFakeResponseCacheStore())
+	group := &Group{ID: 7, Platform: PlatformAnthropic, ResponseCacheEnabled: true}
+
+	a, ok := svc.PrepareRequest(group, 1, ResponseCacheEndpointMessages,
+		[]byte(`{"model":"claude-sonnet-4-5","temperature":0,"max_tokens":16,"messages":[{"role":"user","content":"hi"}],"metadata":{"user_id":"u1"}}`), "")
+	require.True(t, ok)
+	b, ok := svc.PrepareRequest(group, 1, ResponseCacheEndpointMessages,
+		[]byte(`{ "messages":[{"content":"hi","role":"user"}], "max_tokens":16, "temperature":0.0, "model":"claude-sonnet-4-5", "metadata":{"user_id":"u2"} }`), "")
+	require.True(t, ok)
+	require.Equal(t, a.Key, b.Key, "key order, whitespace and metadata must not change the key")
+	require.Equal(t, "claude-sonnet-4-5", a.Model)
+	require.Equal(t, 600*time.Second, a.TTL)
+
+	streamed, ok := svc.PrepareRequest(group, 1, ResponseCacheEndpointMessages,
+		[]byte(`{"model":"claude-sonnet-4-5","temperature":0,"max_tokens":16,"stream":true,"messages":[{"role":"user","content":"hi"}]}`), "")
+	require.True(t, ok)
+	require.True(t, streamed.Stream)
+	require.NotEqual(t, a.Key, streamed.Key)
+
+	beta, ok := svc.PrepareRequest(group, 1, ResponseCacheEndpointMessages,
+		[]byte(`{"model":"claude-sonnet-4-5","temperature":0,"max_tokens":16,"messages":[{"role":"user","content":"hi"}]}`), "2023-06-01|some-beta")
+	require.True(t, ok)
+	require.NotEqual(t, a.Key, beta.Key)
+
+	other := &Group{ID: 8, Platform: PlatformAnthropic, ResponseCacheEnabled: true, ResponseCacheTTLSeconds: 30}
+	c, ok := svc.PrepareRequest(other, 1, ResponseCacheEndpointMessages,
+		[]byte(`{"model":"claude-sonnet-4-5","temperature":0,"max_tokens":16,"messages":[{"role":"user","content":"hi"}]}`), "")
+	require.True(t, ok)
+	require.NotEqual(t, a.Key, c.Key)
+	require.Equal(t, 30*time.Second, c.TTL)
+
+	otherUser, ok := svc.PrepareRequest(group, 2, ResponseCacheEndpointMessages,
+		[]byte(`{"model":"claude-sonnet-4-5","temperature":0,"max_tokens":16,"messages":[{"role":"user","content":"hi"}],"metadata":{"user_id":"u1"}}`), "")
+	require.True(t, ok)
+	require.NotEqual(t, a.Key, otherUser.Key, "cached responses must not be shared across users")
+}
+
+func TestResponseCacheService_PrepareRequestRejectsNonDeterministic(t *testing.T) {
+	svc := newTestResponseCacheService(newFakeResponseCacheStore())
+	group := &Group{ID: 1, Platform: PlatformOpenAI, ResponseCacheEnabled: true}
+
+	cases := []struct {
+		name     string
+		endpoint string
+		body     string
+	}{
+		{"missing temperature", ResponseCacheEndpointChatCompletions, `{"model":"gpt-4o","messages":[]}`},
+		{"positive temperature", ResponseCacheEndpointChatCompletions, `{"model":"gpt-4o","temperature":0.7,"messages":[]}`},
+		{"missing model", ResponseCacheEndpointChatCompletions, `{"temperature":0,"messages":[]}`},
+		{"invalid json", ResponseCacheEndpointChatCompletions, `{"model":`},
+		{"previous response", ResponseCacheEndpointResponses, `{"model":"gpt-4o","temperature":0,"input":"x","previous_response_id":"resp_1"}`},
+		{"background", ResponseCacheEndpointResponses, `{"model":"gpt-4o","temperature":0,"input":"x","background":true}`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, ok := svc.PrepareRequest(group, 1, tc.endpoint, []byte(tc.body), "")
+			require.False(t, ok)
+		})
+	}
+
+	_, ok := svc.PrepareRequest(&Group{ID: 1, Platform: PlatformOpenAI}, 1, ResponseCacheEndpointChatCompletions,
+		[]byte(`{"model":"gpt-4o","temperature":0,"messages":[]}`), "")
+	require.False(t, ok, "groups must opt in")
+	_, ok = svc.PrepareRequest(&Group{ID: 1, Platform: PlatformComposite, ResponseCacheEnabled: true}, 1, ResponseCacheEndpointChatCompletions,
+		[]byte(`{"model":"gpt-4o","temperature":0,"messages":[]}`), "")
+	require.False(t, ok, "composite groups are never cached")
+
+	svc.cfg.ResponseCache.RequireZeroTemperature = false
+	_, ok = svc.PrepareRequest(group, 1, ResponseCacheEndpointChatCompletions, []byte(`{"model":"gpt-4o","messages":[]}`), "")
+	require.True(t, ok)
+	_, ok = svc.PrepareRequest(group, 1, ResponseCacheEndpointChatCompletions, []byte(`{"model":"gpt-4o","temperature":1,"messages":[]}`), "")
+	require.False(t, ok)
+}
+
+func TestParseResponseCacheUsage(t *testing.T) {
+	cases := []struct {
+		name     string
+		endpoint string
+		stream   bool
+		body     string
+		usage    UsageTokens
+		model    string
+		ok       bool
+	}{
+		{
+			name:     "messages json",
+			endpoint: ResponseCacheEndpointMessages,
+			body:     `{"id":"msg_1","model":"claude-sonnet-4-5","usage":{"input_tokens":10,"output_tokens":5,"cache_read_input_tokens":3,"cache_creation_input_tokens":2,"cache_creation":{"ephemeral_5m_input_tokens":2}}}`,
+			usage:    UsageTokens{InputTokens: 10, OutputTokens: 5, CacheReadTokens: 3, CacheCreationTokens: 2, CacheCreation5mTokens: 2},
+			model:    "claude-sonnet-4-5",
+			ok:       true,
+		},
+		{
+			name:     "messages sse",
+			endpoint: ResponseCacheEndpointMessages,
+			stream:   true,
+			body: "event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"model\":\"claude-sonnet-4-5\",\"usage\":{\"input_tokens\":10,\"output_tokens\":1}}}\n\n" +
+				"event: message_delta\ndata: {\"type\":\"message_delta\",\"usage\":{\"output_tokens\":7}}\n\n" +
+				"event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n",
+			usage: UsageTokens{InputTokens: 10, OutputTokens: 7},
+			model: "claude-sonnet-4-5",
+			ok:    true,
+		},
+		{
+			name:     "messages sse truncated",
+			endpoint: ResponseCacheEndpointMessages,
+			stream:   true,
+			body:     "event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"model\":\"claude-sonnet-4-5\",\"usage\":{\"input_tokens\":10}}}\n\n",
+		},
+		{
+			name:     "chat completions json",
+			endpoint: ResponseCacheEndpointChatCompletions,
+			body:     `{"model":"gpt-4o","usage":{"prompt_tokens":12,"completion_tokens":4,"prompt_tokens_details":{"cached_tokens":2}}}`,
+			usage:    UsageTokens{InputTokens: 10, OutputTokens: 4, CacheReadTokens: 2},
+			model:    "gpt-4o",
+			ok:       true,
+		},
+		{
+			name:     "chat completions sse",
+			endpoint: ResponseCacheEndpointChatCompletions,
+			stream:   true,
+			body: "data: {\"model\":\"gpt-4o\",\"choices\":[{\"delta\":{\"content\":\"hi\"}}],\"usage\":null}\n\n" +
+				"data: {\"model\":\"gpt-4o\",\"choices\":[],\"usage\":{\"prompt_tokens\":12,\"completion_tokens\":4}}\n\n" +
+				"data: [DONE]\n\n",
+			usage: UsageTokens{InputTokens: 12, OutputTokens: 4},
+			model: "gpt-4o",
+			ok:    true,
+		},
+		{
+			name:     "chat completions sse without usage",
+			endpoint: ResponseCacheEndpointChatCompletions,
+			stream:   true,
+			body:     "data: {\"model\":\"gpt-4o\",\"choices\":[]}\n\ndata: [DONE]\n\n",
+		},
+		{
+			name:     "responses sse",
+			endpoint: ResponseCacheEndpointResponses,
+			stream:   true,
+			body: "event: response.created\ndata: {\"type\":\"response.created\"}\n\n" +
+				"event: response.completed\ndata: {\"type\":\"response.completed\",\"response\":{\"model\":\"gpt-5\",\"usage\":{\"input_tokens\":20,\"output_tokens\":8,\"input_tokens_details\":{\"cached_tokens\":5}}}}\n\n",
+			usage: UsageTokens{InputTokens: 15, OutputTokens: 8, CacheReadTokens: 5},
+			model: "gpt-5",
+			ok:    true,
+		},
+		{
+			name:     "responses json incomplete",
+			endpoint: ResponseCacheEndpointResponses,
+			body:     `{"model":"gpt-5","status":"incomplete","usage":{"input_tokens":20,"output_tokens":8}}`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			usage, model, ok := ParseResponseCacheUsage(tc.endpoint, tc.stream, []byte(tc.body))
+			require.Equal(t, tc.ok, ok)
+			if tc.ok {
+				require.Equal(t, tc.usage, usage)
+				require.Equal(t, tc.model, model)
+			}
+		})
+	}
+}
+
+func TestResponseCacheService_StoreThenLookup(t *testing.T) {
+	store := newFakeResponseCacheStore()
+	svc := newTestResponseCacheService(store)
+	group := &Group{ID: 3, Platform: PlatformOpenAI, ResponseCacheEnabled: true, ResponseCacheTTLSeconds: 120}
+	req, ok := svc.PrepareRequest(group, 1, ResponseCacheEndpointChatCompletions, []byte(`{"model":"gpt-4o","temperature":0,"messages":[]}`), "")
+	require.True(t, ok)
+
+	_, hit := svc.Lookup(context.Background(), req)
+	require.False(t, hit)
+
+	body := []byte(`{"model":"gpt-4o-2024-08-06","usage":{"prompt_tokens":12,"completion_tokens":4}}`)
+	svc.Store(context.Background(), req, 500, "application/json", body, 42)
+	svc.Store(context.Background(), req, 200, "application/json", body, 0)
+	svc.Store(context.Background(), req, 200, "application/json", body, 42)
+	select {
+	case key := <-store.setCh:
+		require.Equal(t, req.Key, key)
+	case <-time.After(2 * time.Second):
+		t.Fatal("cache entry was not stored")
+	}
+	require.Equal(t, 120*time.Second, store.ttls[req.Key])
+
+	entry, hit := svc.Lookup(context.Background(), req)
+	require.True(t, hit)
+	require.Equal(t, int64(42), entry.AccountID)
+	require.Equal(t, "gpt-4o-2024-08-06", entry.Model)
+	require.Equal(t, UsageTokens{InputTokens: 12, OutputTokens: 4}, entry.Usage)
+	require.Equal(t, body, entry.Body)
+
+	streamReq := *req
+	streamReq.Stream = true
+	_, hit = svc.Lookup(context.Background(), &streamReq)
+	require.False(t, hit, "a JSON entry must never be replayed to a streaming request")
+}
+
+func TestValidateResponseCacheConfig(t *testing.T) {
+	require.NoError(t, ValidateResponseCacheConfig(0, 0))
+	require.NoError(t, ValidateResponseCacheConfig(300, 0.25))
+	require.Error(t, ValidateResponseCacheConfig(-1, 0.1))
+	require.Error(t, ValidateResponseCacheConfig(0, -0.1))
+}
@@ -0,0 +1,12 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// SAMLAssertionReplayCache 记录已消费的 SAML 断言，多实例部署下共享，防止同一断言被重放。
+type SAMLAssertionReplayCache interface {
+	// MarkSAMLAssertionUsed 原子地登记断言，首次登记返回 true；ttl 应覆盖断言剩余有效期。
+	MarkSAMLAssertionUsed(ctx context.Context, issuer, assertionID string, ttl time.Duration) (bool, error)
+}
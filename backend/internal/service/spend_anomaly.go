@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+)
+
+// SpendAnomalyCandidate 当前窗口消费达到下限的用户或 API Key。APIKeyID 为 0 表示用户维度。
+type SpendAnomalyCandidate struct {
+	UserID     int64
+	APIKeyID   int64
+	APIKeyName string
+	// CreatedAt 用户或 Key 的创建时间，用于计算基线实际覆盖的时长。
+	CreatedAt   time.Time
+	WindowSpend float64
+	// HistorySpend 基线区间 [baselineStart, windowStart) 内的累计消费。
+	HistorySpend float64
+}
+
+// SpendAnomalyEvent 一次被判定为异常的消费窗口。
+type SpendAnomalyEvent struct {
+	ID          int64     `json:"id"`
+	UserID      int64     `json:"user_id"`
+	UserEmail   string    `json:"user_email,omitempty"`
+	APIKeyID    *int64    `json:"api_key_id,omitempty"`
+	APIKeyName  string    `json:"api_key_name,omitempty"`
+	WindowStart time.Time `json:"window_start"`
+	WindowEnd   time.Time `json:"window_end"`
+	WindowSpend float64   `json:"window_spend"`
+	// BaselineSpend 基线区间内折算到一个窗口长度的平均消费。
+	BaselineSpend float64   `json:"baseline_spend"`
+	Ratio         float64   `json:"ratio"`
+	KeyDisabled   bool      `json:"key_disabled"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// SpendAnomalyFilter 异常记录列表筛选；UserID 为 0 表示不限用户。
+type SpendAnomalyFilter struct {
+	UserID   int64
+	Page     int
+	PageSize int
+}
+
+// SpendAnomalyRunResult 一次检测的统计。
+type SpendAnomalyRunResult struct {
+	WindowStart  time.Time `json:"window_start"`
+	WindowEnd    time.Time `json:"window_end"`
+	Candidates   int       `json:"candidates"`
+	Alerts       int       `json:"alerts"`
+	DisabledKeys int       `json:"disabled_keys"`
+}
+
+type SpendAnomalyRepository interface {
+	// ListSpendCandidates 返回 [windowStart, windowEnd) 内消费不低于 minSpend 的用户（byAPIKey=false）
+	// 或 API Key（byAPIKey=true），并附带 [baselineStart, windowStart) 的历史消费。
+	ListSpendCandidates(ctx context.Context, baselineStart, windowStart, windowEnd time.Time, minSpend float64, byAPIKey bool) ([]SpendAnomalyCandidate, error)
+	// LastEventAt 返回用户（apiKeyID 为 0）或 Key 最近一次异常记录的时间，没有记录时返回 nil。
+	LastEventAt(ctx context.Context, userID, apiKeyID int64) (*time.Time, error)
+	CreateEvent(ctx context.Context, event *SpendAnomalyEvent) error
+	ListEvents(ctx context.Context, filter SpendAnomalyFilter) ([]SpendAnomalyEvent, int64, error)
+	// DisableAPIKey 把 active 的 Key 改为 disabled，返回是否实际修改。
+	DisableAPIKey(ctx context.Context, apiKeyID int64) (bool, error)
+}
+
+// spendAnomalyBaseline 把历史消费折算为一个窗口长度的平均值。
+// 基线起点取 baselineStart 与创建时间的较晚者；覆盖时长不足 minHistory 时返回 ok=false。
+func spendAnomalyBaseline(c SpendAnomalyCandidate, baselineStart, windowStart time.Time, window, minHistory time.Duration) (float64, bool) {
+	start := baselineStart
+	if c.CreatedAt.After(start) {
+		start = c.CreatedAt
+	}
+	span := windowStart.Sub(start)
+	if span <= 0 || span < minHistory {
+		return 0, false
+	}
+	return c.HistorySpend * float64(window) / float64(span), true
+}
+
+// spendAnomalyExceeded 窗口消费同时达到 minSpend 且超过 multiplier 倍基线时判定为异常。
+func spendAnomalyExceeded(windowSpend, baseline, multiplier, minSpend float64) bool {
+	return windowSpend >= minSpend && windowSpend > baseline*multiplier
+}
+
+// spendAnomalyRatio 基线为 0 时返回 0，表示此前没有消费。
+func spendAnomalyRatio(windowSpend, baseline float64) float64 {
+	if baseline <= 0 {
+		return 0
+	}
+	return math.Round(windowSpend/baseline*100) / 100
+}
+
+// balanceForecastWindow 余额耗尽预测使用的历史消费区间。
+const balanceForecastWindow = 7 * 24 * time.Hour
+
+// UserBalanceSpendReader 读取用户自 since 起余额扣费（billing_type = 0）的累计 actual_cost。
+// 由 usage log 仓储实现，未实现时不给出耗尽预测。
+type UserBalanceSpendReader interface {
+	GetUserBalanceSpendSince(ctx context.Context, userID int64, since time.Time) (float64, error)
+}
+
+// UserBalanceForecast 按近 7 天的平均余额消耗推算可用余额（含授信额度）的耗尽时间。
+type UserBalanceForecast struct {
+	Balance          float64 `json:"balance"`
+	CreditLimit      float64 `json:"credit_limit"`
+	SpendableBalance float64 `json:"spendable_balance"`
+	WindowDays       int     `json:"window_days"`
+	AvgDailySpend    float64 `json:"avg_daily_spend"`
+	// ProjectedExhaustionAt 为空表示近期没有余额消耗，无法预测。
+	ProjectedExhaustionAt *time.Time `json:"projected_exhaustion_at,omitempty"`
+	DaysRemaining         *float64   `json:"days_remaining,omitempty"`
+}
+
+// projectBalanceExhaustion 按每小时消耗推算耗尽时间；已无可用余额时返回 now。
+func projectBalanceExhaustion(now time.Time, spendable, hourlySpend float64) (*time.Time, *float64) {
+	if hourlySpend <= 0 {
+		return nil, nil
+	}
+	hours := 0.0
+	if spendable > 0 {
+		hours = spendable / hourlySpend
+	}
+	// 超过十年的预测没有意义，也避免 Duration 溢出
+	if hours > 10*365*24 {
+		return nil, nil
+	}
+	at := now.Add(time.Duration(hours * float64(time.Hour)))
+	days := math.Round(hours/24*10) / 10
+	return &at, &days
+}
+
+// GetUserBalanceForecast 返回用户仪表盘上的余额耗尽预测。
+func (s *UsageService) GetUserBalanceForecast(ctx context.Context, userID int64) (*UserBalanceForecast, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+	forecast := &UserBalanceForecast{
+		Balance:          user.Balance,
+		CreditLimit:      user.CreditLimit,
+		SpendableBalance: user.SpendableBalance(),
+		WindowDays:       int(balanceForecastWindow / (24 * time.Hour)),
+	}
+	reader, ok := s.usageRepo.(UserBalanceSpendReader)
+	if !ok {
+		return forecast, nil
+	}
+	now := timezone.Now()
+	since := now.Add(-balanceForecastWindow)
+	if user.CreatedAt.After(since) {
+		since = user.CreatedAt
+	}
+	span := now.Sub(since)
+	if span < time.Hour {
+		return forecast, nil
+	}
+	spend, err := reader.GetUserBalanceSpendSince(ctx, userID, since)
+	if err != nil {
+		return nil, fmt.Errorf("load balance spend: %w", err)
+	}
+	hourly := spend / span.Hours()
+	forecast.AvgDailySpend = math.Round(hourly*24*1e4) / 1e4
+	forecast.ProjectedExhaustionAt, forecast.DaysRemaining = projectBalanceExhaustion(now, forecast.SpendableBalance, hourly)
+	return forecast, nil
+}
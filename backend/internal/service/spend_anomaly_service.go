@@ -0,0 +1,298 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	spendAnomalyLeaderLockKey  = "spend_anomaly:detect:leader"
+	spendAnomalyLeaderLockTTL  = 10 * time.Minute
+	spendAnomalyRunTimeout     = 5 * time.Minute
+	spendAnomalyStopTimeout    = 3 * time.Second
+	spendAnomalyReminderLayout = "2006-01-02T15"
+)
+
+var spendAnomalyCronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+
+// SpendAnomalyService 按用户与 API Key 维护消费滚动基线，发现消费突增（例如 Key 泄露）时
+// 邮件提醒用户，并可按配置自动禁用异常的 Key。与余额阈值提醒互补：后者只在余额跌破阈值时触发。
+type SpendAnomalyService struct {
+	repo                 SpendAnomalyRepository
+	userRepo             UserRepository
+	notificationEmail    *NotificationEmailService
+	authCacheInvalidator APIKeyAuthCacheInvalidator
+	cfg                  *config.Config
+
+	lockCache  LeaderLockCache
+	db         *sql.DB
+	instanceID string
+
+	mu      sync.Mutex
+	cron    *cron.Cron
+	stopped bool
+}
+
+func NewSpendAnomalyService(
+	repo SpendAnomalyRepository,
+	userRepo UserRepository,
+	notificationEmail *NotificationEmailService,
+	authCacheInvalidator APIKeyAuthCacheInvalidator,
+	cfg *config.Config,
+) *SpendAnomalyService {
+	return &SpendAnomalyService{
+		repo:                 repo,
+		userRepo:             userRepo,
+		notificationEmail:    notificationEmail,
+		authCacheInvalidator: authCacheInvalidator,
+		cfg:                  cfg,
+		instanceID:           uuid.NewString(),
+	}
+}
+
+// SetLeaderLock 注入 leader 锁；两者均为 nil 时不做选主（单实例/测试）。
+func (s *SpendAnomalyService) SetLeaderLock(lockCache LeaderLockCache, db *sql.DB) {
+	if s == nil {
+		return
+	}
+	s.lockCache = lockCache
+	s.db = db
+}
+
+// Detect 立即执行一次检测：先按用户、再按 API Key 比较当前窗口与基线。
+func (s *SpendAnomalyService) Detect(ctx context.Context) (*SpendAnomalyRunResult, error) {
+	opts := s.cfg.SpendAnomaly
+	now := time.Now()
+	window := time.Duration(opts.WindowMinutes) * time.Minute
+	windowStart := now.Add(-window)
+	baselineStart := windowStart.AddDate(0, 0, -opts.BaselineDays)
+	result := &SpendAnomalyRunResult{WindowStart: windowStart, WindowEnd: now}
+	// 关闭检测时配置不做校验，手动触发也不能用零值把所有消费判为异常
+	if opts.WindowMinutes <= 0 || opts.BaselineDays <= 0 || opts.Multiplier <= 1 {
+		return result, nil
+	}
+
+	for _, byAPIKey := range []bool{false, true} {
+		candidates, err := s.repo.ListSpendCandidates(ctx, baselineStart, windowStart, now, opts.MinSpend, byAPIKey)
+		if err != nil {
+			return result, fmt.Errorf("list spend candidates: %w", err)
+		}
+		result.Candidates += len(candidates)
+		for _, c := range candidates {
+			baseline, ok := spendAnomalyBaseline(c, baselineStart, windowStart, window, time.Duration(opts.MinHistoryHours)*time.Hour)
+			if !ok || !spendAnomalyExceeded(c.WindowSpend, baseline, opts.Multiplier, opts.MinSpend) {
+				continue
+			}
+			last, err := s.repo.LastEventAt(ctx, c.UserID, c.APIKeyID)
+			if err != nil {
+				return result, fmt.Errorf("load last anomaly: %w", err)
+			}
+			if last != nil && now.Sub(*last) < time.Duration(opts.CooldownMinutes)*time.Minute {
+				continue
+			}
+
+			event := &SpendAnomalyEvent{
+				UserID:        c.UserID,
+				APIKeyName:    c.APIKeyName,
+				WindowStart:   windowStart,
+				WindowEnd:     now,
+				WindowSpend:   c.WindowSpend,
+				BaselineSpend: baseline,
+				Ratio:         spendAnomalyRatio(c.WindowSpend, baseline),
+			}
+			if byAPIKey {
+				keyID := c.APIKeyID
+				event.APIKeyID = &keyID
+				if opts.AutoDisableKeys {
+					disabled, err := s.repo.DisableAPIKey(ctx, keyID)
+					if err != nil {
+						return result, fmt.Errorf("disable api key %d: %w", keyID, err)
+					}
+					if disabled {
+						event.KeyDisabled = true
+						result.DisabledKeys++
+						if s.authCacheInvalidator != nil {
+							s.authCacheInvalidator.InvalidateAuthCacheByUserID(ctx, c.UserID)
+						}
+					}
+				}
+			}
+			if err := s.repo.CreateEvent(ctx, event); err != nil {
+				return result, fmt.Errorf("record spend anomaly: %w", err)
+			}
+			result.Alerts++
+			slog.Warn("[SpendAnomaly] spend spike detected",
+				"user_id", c.UserID,
+				"api_key_id", c.APIKeyID,
+				"window_spend", c.WindowSpend,
+				"baseline", baseline,
+				"key_disabled", event.KeyDisabled)
+			s.notify(ctx, event)
+		}
+	}
+	return result, nil
+}
+
+func (s *SpendAnomalyService) notify(ctx context.Context, event *SpendAnomalyEvent) {
+	if s.notificationEmail == nil || s.userRepo == nil {
+		return
+	}
+	user, err := s.userRepo.GetByID(ctx, event.UserID)
+	if err != nil {
+		slog.Warn("[SpendAnomaly] load user for alert failed", "user_id", event.UserID, "error", err)
+		return
+	}
+	sourceID := "user:" + strconv.FormatInt(event.UserID, 10)
+	if event.APIKeyID != nil {
+		sourceID = "api_key:" + strconv.FormatInt(*event.APIKeyID, 10)
+	}
+	ratio := fmt.Sprintf("%.1f", event.Ratio)
+	if event.Ratio <= 0 {
+		ratio = "-"
+	}
+
+	recipients := append([]string{user.Email}, filterVerifiedEmails(user.BalanceNotifyExtraEmails)...)
+	seen := make(map[string]bool, len(recipients))
+	for _, to := range recipients {
+		to = strings.TrimSpace(to)
+		if to == "" || seen[strings.ToLower(to)] {
+			continue
+		}
+		seen[strings.ToLower(to)] = true
+		locale := s.notificationEmail.ResolveRecipientLocale(ctx, user.ID, to)
+		scope, keyAction := spendAnomalyEmailTexts(locale, event)
+		sendCtx, cancel := context.WithTimeout(ctx, emailSendTimeout)
+		err := s.notificationEmail.Send(sendCtx, NotificationEmailSendInput{
+			Event:          NotificationEmailEventSpendAnomaly,
+			Locale:         locale,
+			RecipientEmail: to,
+			RecipientName:  firstNonEmpty(user.Username, user.Email),
+			UserID:         user.ID,
+			SourceType:     "spend_anomaly",
+			SourceID:       sourceID,
+			ReminderKey:    event.WindowEnd.UTC().Format(spendAnomalyReminderLayout),
+			Variables: map[string]string{
+				"anomaly_scope":   scope,
+				"api_key_name":    event.APIKeyName,
+				"window_minutes":  strconv.Itoa(s.cfg.SpendAnomaly.WindowMinutes),
+				"window_spend":    fmt.Sprintf("%.2f", event.WindowSpend),
+				"baseline_spend":  fmt.Sprintf("%.2f", event.BaselineSpend),
+				"spend_ratio":     ratio,
+				"current_balance": fmt.Sprintf("%.2f", user.Balance),
+				"key_action":      keyAction,
+			},
+		})
+		cancel()
+		if err != nil {
+			slog.Warn("[SpendAnomaly] send alert email failed", "user_id", user.ID, "to", to, "error", err)
+		}
+	}
+}
+
+// spendAnomalyEmailTexts 返回邮件中随语言变化的异常范围与 Key 处置说明。
+func spendAnomalyEmailTexts(locale string, event *SpendAnomalyEvent) (scope, keyAction string) {
+	if normalizeNotificationLocale(locale) == notificationEmailLocaleChinese {
+		scope = "您的账户"
+		if event.APIKeyID != nil {
+			scope = "API Key「" + event.APIKeyName + "」"
+		}
+		if event.KeyDisabled {
+			keyAction = "该 API Key 已被自动禁用，确认用量正常后可在 API Key 页面重新启用。"
+		}
+		return scope, keyAction
+	}
+	scope = "your account"
+	if event.APIKeyID != nil {
+		scope = "API key \"" + event.APIKeyName + "\""
+	}
+	if event.KeyDisabled {
+		keyAction = "The API key has been disabled automatically. Re-enable it from the API Keys page once you have confirmed the usage."
+	}
+	return scope, keyAction
+}
+
+func (s *SpendAnomalyService) ListEvents(ctx context.Context, filter SpendAnomalyFilter) ([]SpendAnomalyEvent, int64, error) {
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.PageSize <= 0 {
+		filter.PageSize = 20
+	}
+	if filter.PageSize > 100 {
+		filter.PageSize = 100
+	}
+	return s.repo.ListEvents(ctx, filter)
+}
+
+// Start 按 spend_anomaly.schedule 启动定时检测。重复调用幂等。
+func (s *SpendAnomalyService) Start() {
+	if s == nil || s.repo == nil || s.cfg == nil || !s.cfg.SpendAnomaly.Enabled {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cron != nil || s.stopped {
+		return
+	}
+
+	loc := time.Local
+	if tz := strings.TrimSpace(s.cfg.Timezone); tz != "" {
+		if parsed, err := time.LoadLocation(tz); err == nil && parsed != nil {
+			loc = parsed
+		}
+	}
+	schedule := strings.TrimSpace(s.cfg.SpendAnomaly.Schedule)
+	c := cron.New(cron.WithParser(spendAnomalyCronParser), cron.WithLocation(loc))
+	if _, err := c.AddFunc(schedule, s.runScheduled); err != nil {
+		slog.Error("[SpendAnomaly] invalid schedule, detection disabled", "schedule", schedule, "error", err)
+		return
+	}
+	c.Start()
+	s.cron = c
+}
+
+// Stop 关闭定时检测。幂等。
+func (s *SpendAnomalyService) Stop() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopped = true
+	if s.cron == nil {
+		return
+	}
+	ctx := s.cron.Stop()
+	select {
+	case <-ctx.Done():
+	case <-time.After(spendAnomalyStopTimeout):
+		slog.Warn("[SpendAnomaly] cron stop timed out")
+	}
+	s.cron = nil
+}
+
+func (s *SpendAnomalyService) runScheduled() {
+	lockCtx, lockCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	release, ok := tryAcquireSingletonLeaderLock(lockCtx, s.lockCache, s.db, spendAnomalyLeaderLockKey, s.instanceID, spendAnomalyLeaderLockTTL)
+	lockCancel()
+	if !ok {
+		return
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), spendAnomalyRunTimeout)
+	defer cancel()
+	if _, err := s.Detect(ctx); err != nil {
+		slog.Error("[SpendAnomaly] scheduled detection failed", "error", err)
+	}
+}
@@ -0,0 +1,156 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type spendAnomalyRepoStub struct {
+	userCandidates []SpendAnomalyCandidate
+	keyCandidates  []SpendAnomalyCandidate
+	lastEventAt    map[[2]int64]time.Time
+	events         []SpendAnomalyEvent
+	disabledKeys   []int64
+}
+
+func (r *spendAnomalyRepoStub) ListSpendCandidates(_ context.Context, _, _, _ time.Time, _ float64, byAPIKey bool) ([]SpendAnomalyCandidate, error) {
+	if byAPIKey {
+		return r.keyCandidates, nil
+	}
+	return r.userCandidates, nil
+}
+
+func (r *spendAnomalyRepoStub) LastEventAt(_ context.Context, userID, apiKeyID int64) (*time.Time, error) {
+	if at, ok := r.lastEventAt[[2]int64{userID, apiKeyID}]; ok {
+		return &at, nil
+	}
+	return nil, nil
+}
+
+func (r *spendAnomalyRepoStub) CreateEvent(_ context.Context, event *SpendAnomalyEvent) error {
+	event.ID = int64(len(r.events) + 1)
+	r.events = append(r.events, *event)
+	return nil
+}
+
+func (r *spendAnomalyRepoStub) ListEvents(context.Context, SpendAnomalyFilter) ([]SpendAnomalyEvent, int64, error) {
+	return r.events, int64(len(r.events)), nil
+}
+
+func (r *spendAnomalyRepoStub) DisableAPIKey(_ context.Context, apiKeyID int64) (bool, error) {
+	r.disabledKeys = append(r.disabledKeys, apiKeyID)
+	return true, nil
+}
+
+func spendAnomalyTestConfig(autoDisable bool) *config.Config {
+	return &config.Config{SpendAnomaly: config.SpendAnomalyConfig{
+		Enabled:         true,
+		WindowMinutes:   60,
+		BaselineDays:    7,
+		Multiplier:      5,
+		MinSpend:        1,
+		MinHistoryHours: 24,
+		CooldownMinutes: 360,
+		AutoDisableKeys: autoDisable,
+	}}
+}
+
+func TestSpendAnomalyBaseline(t *testing.T) {
+	windowStart := time.Date(2026, 5, 8, 12, 0, 0, 0, time.UTC)
+	baselineStart := windowStart.AddDate(0, 0, -7)
+
+	// 7 天共消费 16.8：每小时 0.1
+	baseline, ok := spendAnomalyBaseline(SpendAnomalyCandidate{CreatedAt: baselineStart.AddDate(0, -1, 0), HistorySpend: 16.8},
+		baselineStart, windowStart, time.Hour, 24*time.Hour)
+	require.True(t, ok)
+	require.InDelta(t, 0.1, baseline, 1e-9)
+
+	// 两天前才创建：基线只按两天折算
+	baseline, ok = spendAnomalyBaseline(SpendAnomalyCandidate{CreatedAt: windowStart.Add(-48 * time.Hour), HistorySpend: 4.8},
+		baselineStart, windowStart, time.Hour, 24*time.Hour)
+	require.True(t, ok)
+	require.InDelta(t, 0.1, baseline, 1e-9)
+
+	// 历史不足 24 小时不判定
+	_, ok = spendAnomalyBaseline(SpendAnomalyCandidate{CreatedAt: windowStart.Add(-2 * time.Hour)},
+		baselineStart, windowStart, time.Hour, 24*time.Hour)
+	require.False(t, ok)
+
+	require.True(t, spendAnomalyExceeded(5.1, 1, 5, 1))
+	require.False(t, spendAnomalyExceeded(5, 1, 5, 1))
+	require.False(t, spendAnomalyExceeded(0.5, 0, 5, 1), "below min_spend")
+	require.True(t, spendAnomalyExceeded(1, 0, 5, 1), "no prior spend")
+	require.Equal(t, 0.0, spendAnomalyRatio(3, 0))
+	require.Equal(t, 30.0, spendAnomalyRatio(3, 0.1))
+}
+
+func TestSpendAnomalyDetectAlertsDisablesAndRespectsCooldown(t *testing.T) {
+	longAgo := time.Now().AddDate(0, -1, 0)
+	repo := &spendAnomalyRepoStub{
+		userCandidates: []SpendAnomalyCandidate{
+			{UserID: 1, CreatedAt: longAgo, WindowSpend: 30, HistorySpend: 16.8}, // 300x
+			{UserID: 2, CreatedAt: longAgo, WindowSpend: 3, HistorySpend: 168},   // 3x，正常
+		},
+		keyCandidates: []SpendAnomalyCandidate{
+			{UserID: 1, APIKeyID: 11, APIKeyName: "leaked", CreatedAt: longAgo, WindowSpend: 30, HistorySpend: 16.8},
+			{UserID: 3, APIKeyID: 31, APIKeyName: "cooling", CreatedAt: longAgo, WindowSpend: 30, HistorySpend: 0},
+		},
+		lastEventAt: map[[2]int64]time.Time{{3, 31}: time.Now().Add(-time.Hour)},
+	}
+	invalidator := &authCacheInvalidatorStub{}
+	svc := NewSpendAnomalyService(repo, nil, nil, invalidator, spendAnomalyTestConfig(true))
+
+	result, err := svc.Detect(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 4, result.Candidates)
+	require.Equal(t, 2, result.Alerts)
+	require.Equal(t, 1, result.DisabledKeys)
+	require.Equal(t, []int64{11}, repo.disabledKeys)
+	require.Equal(t, []int64{1}, invalidator.userIDs)
+
+	require.Len(t, repo.events, 2)
+	require.Nil(t, repo.events[0].APIKeyID)
+	require.False(t, repo.events[0].KeyDisabled)
+	require.NotNil(t, repo.events[1].APIKeyID)
+	require.Equal(t, int64(11), *repo.events[1].APIKeyID)
+	require.True(t, repo.events[1].KeyDisabled)
+	require.Equal(t, 300.0, repo.events[1].Ratio)
+}
+
+func TestSpendAnomalyDetectWithoutAutoDisableOnlyAlerts(t *testing.T) {
+	repo := &spendAnomalyRepoStub{
+		keyCandidates: []SpendAnomalyCandidate{
+			{UserID: 1, APIKeyID: 11, APIKeyName: "k", CreatedAt: time.Now().AddDate(0, -1, 0), WindowSpend: 30, HistorySpend: 16.8},
+		},
+	}
+	svc := NewSpendAnomalyService(repo, nil, nil, nil, spendAnomalyTestConfig(false))
+
+	result, err := svc.Detect(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Alerts)
+	require.Zero(t, result.DisabledKeys)
+	require.Empty(t, repo.disabledKeys)
+}
+
+func TestProjectBalanceExhaustion(t *testing.T) {
+	now := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	at, days := projectBalanceExhaustion(now, 48, 1)
+	require.NotNil(t, at)
+	require.Equal(t, now.Add(48*time.Hour), *at)
+	require.Equal(t, 2.0, *days)
+
+	at, days = projectBalanceExhaustion(now, -5, 1)
+	require.Equal(t, now, *at)
+	require.Equal(t, 0.0, *days)
+
+	at, days = projectBalanceExhaustion(now, 100, 0)
+	require.Nil(t, at)
+	require.Nil(t, days)
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// 自动续费协议状态。
+const (
+	// AutoRenewalStatusPending 首单尚未支付，等待服务商回传可复用的支付方式。
+	AutoRenewalStatusPending = "pending"
+	// AutoRenewalStatusActive 已保存支付方式，到期前自动扣款。
+	AutoRenewalStatusActive = "active"
+	// AutoRenewalStatusPastDue 最近一次扣款失败，等待重试。
+	AutoRenewalStatusPastDue = "past_due"
+	// AutoRenewalStatusFailed 重试次数用尽或无法继续扣款，自动续费已停止。
+	AutoRenewalStatusFailed = "failed"
+	// AutoRenewalStatusCancelled 用户取消或被同分组的新协议替代。
+	AutoRenewalStatusCancelled = "cancelled"
+)
+
+var (
+	ErrAutoRenewalNotFound    = infraerrors.New(http.StatusNotFound, "AUTO_RENEWAL_NOT_FOUND", "auto-renewal not found")
+	ErrAutoRenewalDisabled    = infraerrors.New(http.StatusForbidden, "AUTO_RENEW_DISABLED", "subscription auto-renewal is disabled")
+	ErrAutoRenewalUnsupported = infraerrors.New(http.StatusBadRequest, "AUTO_RENEW_UNSUPPORTED", "the selected payment method does not support auto-renewal")
+)
+
+// SubscriptionAutoRenewal 是用户对某个订阅分组的自动续费协议。
+// 首单支付成功后服务商保存的客户/支付方式引用写入 CustomerRef/PaymentMethodRef，
+// 之后由 SubscriptionAutoRenewalService 在订阅到期前离线扣款续期。
+type SubscriptionAutoRenewal struct {
+	ID                 int64      `json:"id"`
+	UserID             int64      `json:"user_id"`
+	GroupID            int64      `json:"group_id"`
+	PlanID             int64      `json:"plan_id"`
+	SetupOrderID       int64      `json:"setup_order_id"`
+	ProviderInstanceID string     `json:"-"`
+	ProviderKey        string     `json:"provider_key"`
+	PaymentType        string     `json:"payment_type"`
+	CustomerRef        string     `json:"-"`
+	PaymentMethodRef   string     `json:"-"`
+	Status             string     `json:"status"`
+	FailureCount       int        `json:"failure_count"`
+	NextAttemptAt      *time.Time `json:"next_attempt_at,omitempty"`
+	LastOrderID        *int64     `json:"last_order_id,omitempty"`
+	LastError          string     `json:"last_error,omitempty"`
+	LastChargedAt      *time.Time `json:"last_charged_at,omitempty"`
+	CancelledAt        *time.Time `json:"cancelled_at,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+
+	// 以下字段由关联查询填充
+	GroupName             string     `json:"group_name,omitempty"`
+	PlanName              string     `json:"plan_name,omitempty"`
+	SubscriptionExpiresAt *time.Time `json:"subscription_expires_at,omitempty"`
+}
+
+// SubscriptionAutoRenewalRepository 持久化自动续费协议。
+type SubscriptionAutoRenewalRepository interface {
+	Create(ctx context.Context, renewal *SubscriptionAutoRenewal) error
+	GetBySetupOrderID(ctx context.Context, orderID int64) (*SubscriptionAutoRenewal, error)
+	GetByLastOrderID(ctx context.Context, orderID int64) (*SubscriptionAutoRenewal, error)
+	// ListByUser 返回用户已生效过的协议（不含仍在等待首单支付的 pending 协议）。
+	ListByUser(ctx context.Context, userID int64) ([]SubscriptionAutoRenewal, error)
+	// Activate 写入支付方式引用并启用 pending 协议，同时取消同一用户同一分组下的旧协议。
+	Activate(ctx context.Context, id int64, customerRef, paymentMethodRef string) error
+	// ListDue 返回订阅在 renewBefore 之前到期、且已到重试时间的协议；上一笔续费订单仍待支付的协议会被跳过。
+	ListDue(ctx context.Context, renewBefore, now time.Time, limit int) ([]SubscriptionAutoRenewal, error)
+	// ClaimAttempt 把 next_attempt_at 推到 nextAttemptAt 以占用本次扣款，返回 false 表示已被占用。
+	ClaimAttempt(ctx context.Context, id int64, now, nextAttemptAt time.Time) (bool, error)
+	SetLastOrder(ctx context.Context, id, orderID int64) error
+	// MarkSucceeded 在 last_order_id 对应的续费订单履约后重置失败计数。
+	MarkSucceeded(ctx context.Context, id, orderID int64, chargedAt time.Time) error
+	MarkFailed(ctx context.Context, id int64, status string, failureCount int, lastError string, nextAttemptAt *time.Time) error
+	// Cancel 取消用户自己的协议；协议不存在、不属于该用户或已终止时返回 ErrAutoRenewalNotFound。
+	Cancel(ctx context.Context, id, userID int64) error
+}
+
+// autoRenewalSchedule 规范化续费配置：提前扣款时长、失败重试间隔与最大尝试次数。
+func autoRenewalSchedule(cfg *PaymentConfig) (lead, retry time.Duration, maxAttempts int) {
+	leadDays, retryHours, maxAttempts := defaultAutoRenewLeadDays, defaultAutoRenewRetryHours, defaultAutoRenewMaxAttempts
+	if cfg != nil {
+		if cfg.AutoRenewLeadDays > 0 {
+			leadDays = cfg.AutoRenewLeadDays
+		}
+		if cfg.AutoRenewRetryHours > 0 {
+			retryHours = cfg.AutoRenewRetryHours
+		}
+		if cfg.AutoRenewMaxAttempts > 0 {
+			maxAttempts = cfg.AutoRenewMaxAttempts
+		}
+	}
+	return time.Duration(leadDays) * 24 * time.Hour, time.Duration(retryHours) * time.Hour, maxAttempts
+}
+
+// autoRenewalEffectiveLead 把提前量限制在套餐有效期的一半以内，
+// 避免短周期套餐续期后仍落在提前窗口内而被反复扣款。
+func autoRenewalEffectiveLead(lead time.Duration, validityDays int) time.Duration {
+	if validityDays <= 0 {
+		return lead
+	}
+	if half := time.Duration(validityDays) * 24 * time.Hour / 2; half < lead {
+		return half
+	}
+	return lead
+}
+
+// autoRenewalFailureOutcome 根据累计失败次数决定协议状态与下次重试时间；
+// 达到最大尝试次数后停止自动续费。
+func autoRenewalFailureOutcome(failureCount, maxAttempts int, retry time.Duration, now time.Time) (string, *time.Time) {
+	if failureCount >= maxAttempts {
+		return AutoRenewalStatusFailed, nil
+	}
+	next := now.Add(retry)
+	return AutoRenewalStatusPastDue, &next
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// autoRenewalLeaderLockKey 保证多实例部署时同一周期只有一个实例发起离线扣款。
+	autoRenewalLeaderLockKey = "payment:subscription:auto_renew:leader"
+	// autoRenewalLeaderLockTTL 需覆盖单次运行超时，避免运行中锁过期。
+	autoRenewalLeaderLockTTL = 5 * time.Minute
+	autoRenewalRunTimeout    = 3 * time.Minute
+)
+
+// SubscriptionAutoRenewalService 定期为即将到期的自动续费订阅扣款。
+type SubscriptionAutoRenewalService struct {
+	paymentSvc *PaymentService
+	interval   time.Duration
+	stopCh     chan struct{}
+	stopOnce   sync.Once
+	wg         sync.WaitGroup
+
+	lockCache  LeaderLockCache
+	db         *sql.DB
+	instanceID string
+}
+
+func NewSubscriptionAutoRenewalService(paymentSvc *PaymentService, interval time.Duration) *SubscriptionAutoRenewalService {
+	return &SubscriptionAutoRenewalService{
+		paymentSvc: paymentSvc,
+		interval:   interval,
+		stopCh:     make(chan struct{}),
+		instanceID: uuid.NewString(),
+	}
+}
+
+// SetLeaderLock 注入 leader 锁；两者均为 nil 时不做选主（单实例/测试）。
+func (s *SubscriptionAutoRenewalService) SetLeaderLock(lockCache LeaderLockCache, db *sql.DB) {
+	if s == nil {
+		return
+	}
+	s.lockCache = lockCache
+	s.db = db
+}
+
+func (s *SubscriptionAutoRenewalService) Start() {
+	if s == nil || s.paymentSvc == nil || s.interval <= 0 {
+		return
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		s.runOnce()
+		for {
+			select {
+			case <-ticker.C:
+				s.runOnce()
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+func (s *SubscriptionAutoRenewalService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+	s.wg.Wait()
+}
+
+func (s *SubscriptionAutoRenewalService) runOnce() {
+	lockCtx, lockCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	release, ok := tryAcquireSingletonLeaderLock(lockCtx, s.lockCache, s.db, autoRenewalLeaderLockKey, s.instanceID, autoRenewalLeaderLockTTL)
+	lockCancel()
+	if !ok {
+		return
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), autoRenewalRunTimeout)
+	defer cancel()
+	attempted, err := s.paymentSvc.ProcessDueAutoRenewals(ctx)
+	if err != nil {
+		slog.Error("[AutoRenewal] failed to process due renewals", "error", err)
+		return
+	}
+	if attempted > 0 {
+		slog.Info("[AutoRenewal] processed due renewals", "count", attempted)
+	}
+}
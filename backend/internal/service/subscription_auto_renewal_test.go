@@ -0,0 +1,47 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoRenewalSchedule(t *testing.T) {
+	lead, retry, maxAttempts := autoRenewalSchedule(nil)
+	require.Equal(t, time.Duration(defaultAutoRenewLeadDays)*24*time.Hour, lead)
+	require.Equal(t, time.Duration(defaultAutoRenewRetryHours)*time.Hour, retry)
+	require.Equal(t, defaultAutoRenewMaxAttempts, maxAttempts)
+
+	lead, retry, maxAttempts = autoRenewalSchedule(&PaymentConfig{
+		AutoRenewLeadDays:    1,
+		AutoRenewRetryHours:  6,
+		AutoRenewMaxAttempts: 5,
+	})
+	require.Equal(t, 24*time.Hour, lead)
+	require.Equal(t, 6*time.Hour, retry)
+	require.Equal(t, 5, maxAttempts)
+}
+
+func TestAutoRenewalEffectiveLead(t *testing.T) {
+	lead := 3 * 24 * time.Hour
+	require.Equal(t, lead, autoRenewalEffectiveLead(lead, 30))
+	require.Equal(t, lead, autoRenewalEffectiveLead(lead, 0))
+	// 2 天套餐最多提前 1 天扣款
+	require.Equal(t, 24*time.Hour, autoRenewalEffectiveLead(lead, 2))
+}
+
+func TestAutoRenewalFailureOutcome(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	status, next := autoRenewalFailureOutcome(1, 3, 24*time.Hour, now)
+	require.Equal(t, AutoRenewalStatusPastDue, status)
+	require.NotNil(t, next)
+	require.Equal(t, now.Add(24*time.Hour), *next)
+
+	status, next = autoRenewalFailureOutcome(3, 3, 24*time.Hour, now)
+	require.Equal(t, AutoRenewalStatusFailed, status)
+	require.Nil(t, next)
+}
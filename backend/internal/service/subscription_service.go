@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"math/rand/v2"
@@ -57,6 +58,9 @@ type SubscriptionService struct {
 
 	maintenanceQueue *SubscriptionMaintenanceQueue
 	now              func() time.Time
+
+	// orgSubResolver 成员没有个人订阅时回落到组织共享订阅（可选）
+	orgSubResolver OrganizationSubscriptionResolver
 }
 
 // NewSubscriptionService 创建订阅服务
@@ -85,6 +89,11 @@ func (s *SubscriptionService) initMaintenanceQueue(cfg *config.Config) {
 	s.maintenanceQueue = NewSubscriptionMaintenanceQueue(mc.WorkerCount, mc.QueueSize)
 }
 
+// SetOrganizationSubscriptionResolver 注入组织共享订阅解析器。
+func (s *SubscriptionService) SetOrganizationSubscriptionResolver(resolver OrganizationSubscriptionResolver) {
+	s.orgSubResolver = resolver
+}
+
 // Stop stops the maintenance worker pool.
 func (s *SubscriptionService) Stop() {
 	if s == nil {
@@ -741,6 +750,9 @@ func (s *SubscriptionService) GetActiveSubscription(ctx context.Context, userID,
 	// singleflight 防止并发击穿
 	value, err, _ := s.subCacheGroup.Do(key, func() (any, error) {
 		sub, err := s.userSubRepo.GetActiveByUserIDAndGroupID(ctx, userID, groupID)
+		if errors.Is(err, ErrSubscriptionNotFound) && s.orgSubResolver != nil {
+			sub, err = s.getOrganizationSubscription(ctx, userID, groupID)
+		}
 		if err != nil {
 			return nil, err // 直接透传 repo 已翻译的错误（NotFound → ErrSubscriptionNotFound，其他错误原样返回）
 		}
@@ -762,6 +774,23 @@ func (s *SubscriptionService) GetActiveSubscription(ctx context.Context, userID,
 	return &cp, nil
 }
 
+// getOrganizationSubscription 解析成员所在组织的共享订阅（挂在 owner 名下），
+// 与个人订阅一样要求 active 且未过期。
+func (s *SubscriptionService) getOrganizationSubscription(ctx context.Context, userID, groupID int64) (*UserSubscription, error) {
+	subscriptionID, err := s.orgSubResolver.ResolveOrganizationSubscriptionID(ctx, userID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	sub, err := s.userSubRepo.GetByID(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if sub.GroupID != groupID || sub.Status != SubscriptionStatusActive || !sub.ExpiresAt.After(s.now()) {
+		return nil, ErrSubscriptionNotFound
+	}
+	return sub, nil
+}
+
 // ListUserSubscriptions 获取用户的所有订阅
 func (s *SubscriptionService) ListUserSubscriptions(ctx context.Context, userID int64) ([]UserSubscription, error) {
 	subs, err := s.userSubRepo.ListByUserID(ctx, userID)
@@ -0,0 +1,729 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/tidwall/gjson"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxTrafficMirrorInFlight 同时在后台重放的镜像请求上限；已满时本次不镜像，绝不排队。
+	maxTrafficMirrorInFlight = 32
+
+	trafficMirrorQueueSize     = 2048
+	trafficMirrorBatchSize     = 200
+	trafficMirrorFlushInterval = 2 * time.Second
+	trafficMirrorFlushTimeout  = 5 * time.Second
+	trafficMirrorRetention     = 30 * 24 * time.Hour
+	trafficMirrorPurgeEvery    = time.Hour
+	trafficMirrorGroupCacheTTL = 30 * time.Second
+	defaultTrafficMirrorRange  = 24 * time.Hour
+	maxTrafficMirrorPageSize   = 200
+)
+
+var (
+	ErrTrafficMirrorInvalidRange = infraerrors.BadRequest("TRAFFIC_MIRROR_INVALID_RANGE", "start_time must be before end_time")
+
+	errTrafficMirrorAccountPinned = errors.New("traffic mirror request is pinned to another account")
+)
+
+// NormalizeGroupMirrorPolicy 校验并归一化分组的流量镜像策略；非正数 ID 视为未设置。
+// 目标分组/账号是否存在由管理服务在保存时校验。
+func NormalizeGroupMirrorPolicy(policy GroupMirrorPolicy) (GroupMirrorPolicy, error) {
+	if policy.TargetGroupID != nil && *policy.TargetGroupID <= 0 {
+		policy.TargetGroupID = nil
+	}
+	if policy.TargetAccountID != nil && *policy.TargetAccountID <= 0 {
+		policy.TargetAccountID = nil
+	}
+	if policy.SampleRate < 0 || policy.SampleRate > 1 {
+		return GroupMirrorPolicy{}, fmt.Errorf("mirror sample_rate must be between 0 and 1")
+	}
+	if !policy.Enabled {
+		return policy, nil
+	}
+	if policy.SampleRate == 0 {
+		return GroupMirrorPolicy{}, fmt.Errorf("mirror sample_rate must be greater than 0 when enabled")
+	}
+	if policy.TargetGroupID == nil && policy.TargetAccountID == nil {
+		return GroupMirrorPolicy{}, fmt.Errorf("mirror requires target_group_id or target_account_id")
+	}
+	return policy, nil
+}
+
+// TrafficMirrorPlan 是一次被抽中的镜像请求：在 TargetGroup 上重放，
+// TargetAccountID > 0 时调度只允许选中该账号。
+type TrafficMirrorPlan struct {
+	SourceGroup     *Group
+	TargetGroup     *Group
+	TargetAccountID int64
+	CompareOutput   bool
+}
+
+// WithTrafficMirror 构造镜像影子请求的上下文：打上镜像标记（计费、用户并发、
+// 资格检查与内容审核据此跳过），把请求分组替换为候选分组，并清除源分组的 composite
+// 路由决策，让候选分组按自身平台与模型映射处理原始请求。
+func WithTrafficMirror(ctx context.Context, plan *TrafficMirrorPlan) context.Context {
+	if ctx == nil || plan == nil || plan.TargetGroup == nil {
+		return ctx
+	}
+	ctx = context.WithValue(ctx, ctxkey.TrafficMirror, plan)
+	ctx = context.WithValue(ctx, ctxkey.Group, plan.TargetGroup)
+	for _, key := range []ctxkey.Key{ctxkey.ResolvedTargetPlatform, ctxkey.ResolvedUpstreamModel, ctxkey.RequestedPublicModel, ctxkey.CompositeRouteSource} {
+		if _, ok := ctx.Value(key).(string); ok {
+			ctx = context.WithValue(ctx, key, "")
+		}
+	}
+	return ctx
+}
+
+// IsTrafficMirrorContext 报告当前请求是否为流量镜像的影子请求。
+func IsTrafficMirrorContext(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	plan, ok := ctx.Value(ctxkey.TrafficMirror).(*TrafficMirrorPlan)
+	return ok && plan != nil
+}
+
+func trafficMirrorPinnedAccountID(ctx context.Context) int64 {
+	if ctx == nil {
+		return 0
+	}
+	if plan, ok := ctx.Value(ctxkey.TrafficMirror).(*TrafficMirrorPlan); ok && plan != nil {
+		return plan.TargetAccountID
+	}
+	return 0
+}
+
+// filterTrafficMirrorPinnedAccounts 在镜像请求指定了目标账号时只保留该账号。
+func filterTrafficMirrorPinnedAccounts(ctx context.Context, accounts []Account) []Account {
+	pinned := trafficMirrorPinnedAccountID(ctx)
+	if pinned <= 0 {
+		return accounts
+	}
+	for i := range accounts {
+		if accounts[i].ID == pinned {
+			return []Account{accounts[i]}
+		}
+	}
+	return []Account{}
+}
+
+// checkTrafficMirrorPinnedAccount 拒绝镜像请求复用指定账号以外的粘性会话账号。
+func checkTrafficMirrorPinnedAccount(ctx context.Context, accountID int64) error {
+	if pinned := trafficMirrorPinnedAccountID(ctx); pinned > 0 && pinned != accountID {
+		return errTrafficMirrorAccountPinned
+	}
+	return nil
+}
+
+// TrafficMirrorObservation 是一侧（主请求或镜像）响应的观测结果。
+// Status 为 0 表示没有产生响应（超时或处理器未写出）。
+type TrafficMirrorObservation struct {
+	AccountID    int64
+	Status       int
+	LatencyMs    int
+	TTFTMs       *int
+	InputTokens  int
+	OutputTokens int
+	Model        string
+	Output       string
+	Error        string
+}
+
+// TrafficMirrorRecord 是一次镜像请求的对比记录。
+type TrafficMirrorRecord struct {
+	ID              int64  `json:"id"`
+	GroupID         int64  `json:"group_id"`
+	TargetGroupID   int64  `json:"target_group_id"`
+	TargetAccountID *int64 `json:"target_account_id,omitempty"`
+	ClientRequestID string `json:"client_request_id"`
+	Endpoint        string `json:"endpoint"`
+	Model           string `json:"model"`
+	Stream          bool   `json:"stream"`
+
+	PrimaryAccountID    *int64 `json:"primary_account_id,omitempty"`
+	PrimaryStatus       int    `json:"primary_status"`
+	PrimaryLatencyMs    int    `json:"primary_latency_ms"`
+	PrimaryTTFTMs       *int   `json:"primary_ttft_ms,omitempty"`
+	PrimaryInputTokens  int    `json:"primary_input_tokens"`
+	PrimaryOutputTokens int    `json:"primary_output_tokens"`
+	PrimaryModel        string `json:"primary_model"`
+
+	MirrorAccountID    *int64 `json:"mirror_account_id,omitempty"`
+	MirrorStatus       int    `json:"mirror_status"`
+	MirrorLatencyMs    int    `json:"mirror_latency_ms"`
+	MirrorTTFTMs       *int   `json:"mirror_ttft_ms,omitempty"`
+	MirrorInputTokens  int    `json:"mirror_input_tokens"`
+	MirrorOutputTokens int    `json:"mirror_output_tokens"`
+	MirrorModel        string `json:"mirror_model"`
+	MirrorError        string `json:"mirror_error"`
+
+	OutputSimilarity     *float64 `json:"output_similarity,omitempty"`
+	PrimaryOutputExcerpt *string  `json:"primary_output_excerpt,omitempty"`
+	MirrorOutputExcerpt  *string  `json:"mirror_output_excerpt,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TrafficMirrorRequest 描述被镜像的原始请求。
+type TrafficMirrorRequest struct {
+	ClientRequestID string
+	Endpoint        string
+	Model           string
+	Stream          bool
+}
+
+// NewTrafficMirrorRecord 组装对比记录；CompareOutput 时附带输出摘录与相似度。
+func NewTrafficMirrorRecord(plan *TrafficMirrorPlan, req TrafficMirrorRequest, primary, mirror TrafficMirrorObservation) TrafficMirrorRecord {
+	record := TrafficMirrorRecord{
+		ClientRequestID: req.ClientRequestID,
+		Endpoint:        req.Endpoint,
+		Model:           truncateRunes(req.Model, maxTrafficMirrorModelRunes),
+		Stream:          req.Stream,
+
+		PrimaryAccountID:    positiveInt64Ptr(primary.AccountID),
+		PrimaryStatus:       primary.Status,
+		PrimaryLatencyMs:    primary.LatencyMs,
+		PrimaryTTFTMs:       primary.TTFTMs,
+		PrimaryInputTokens:  primary.InputTokens,
+		PrimaryOutputTokens: primary.OutputTokens,
+		PrimaryModel:        truncateRunes(primary.Model, maxTrafficMirrorModelRunes),
+
+		MirrorAccountID:    positiveInt64Ptr(mirror.AccountID),
+		MirrorStatus:       mirror.Status,
+		MirrorLatencyMs:    mirror.LatencyMs,
+		MirrorTTFTMs:       mirror.TTFTMs,
+		MirrorInputTokens:  mirror.InputTokens,
+		MirrorOutputTokens: mirror.OutputTokens,
+		MirrorModel:        truncateRunes(mirror.Model, maxTrafficMirrorModelRunes),
+		MirrorError:        truncateRunes(mirror.Error, maxTrafficMirrorErrorRunes),
+
+		CreatedAt: time.Now(),
+	}
+	if plan != nil {
+		if plan.SourceGroup != nil {
+			record.GroupID = plan.SourceGroup.ID
+		}
+		if plan.TargetGroup != nil {
+			record.TargetGroupID = plan.TargetGroup.ID
+		}
+		record.TargetAccountID = positiveInt64Ptr(plan.TargetAccountID)
+		if plan.CompareOutput && trafficMirrorSucceeded(primary.Status) && trafficMirrorSucceeded(mirror.Status) {
+			similarity := TrafficMirrorOutputSimilarity(primary.Output, mirror.Output)
+			primaryExcerpt := truncateRunes(primary.Output, maxTrafficMirrorExcerptRunes)
+			mirrorExcerpt := truncateRunes(mirror.Output, maxTrafficMirrorExcerptRunes)
+			record.OutputSimilarity = &similarity
+			record.PrimaryOutputExcerpt = &primaryExcerpt
+			record.MirrorOutputExcerpt = &mirrorExcerpt
+		}
+	}
+	return record
+}
+
+func trafficMirrorSucceeded(status int) bool {
+	return status >= 200 && status < 300
+}
+
+func positiveInt64Ptr(v int64) *int64 {
+	if v <= 0 {
+		return nil
+	}
+	return &v
+}
+
+func truncateRunes(s string, limit int) string {
+	if limit <= 0 || len(s) <= limit {
+		return s
+	}
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit])
+}
+
+// TrafficMirrorFilter 镜像记录查询条件；零值字段不过滤。
+type TrafficMirrorFilter struct {
+	GroupID       int64
+	TargetGroupID int64
+	StartTime     time.Time
+	EndTime       time.Time
+	// OnlyDiverged 只返回状态码类别不一致或响应模型不一致的记录。
+	OnlyDiverged bool
+	Page         int
+	PageSize     int
+}
+
+// TrafficMirrorSummary 是一个（源分组, 候选分组, 候选账号）组合在时间窗内的对比汇总。
+// 成功率按 2xx 计；延迟、TTFT 与 token 只统计两侧都成功的请求，保证可比。
+type TrafficMirrorSummary struct {
+	GroupID             int64    `json:"group_id"`
+	TargetGroupID       int64    `json:"target_group_id"`
+	TargetAccountID     *int64   `json:"target_account_id,omitempty"`
+	Total               int64    `json:"total"`
+	PrimarySuccess      int64    `json:"primary_success"`
+	MirrorSuccess       int64    `json:"mirror_success"`
+	PrimarySuccessRate  float64  `json:"primary_success_rate"`
+	MirrorSuccessRate   float64  `json:"mirror_success_rate"`
+	StatusMismatch      int64    `json:"status_mismatch"`
+	ModelMismatch       int64    `json:"model_mismatch"`
+	BothSuccess         int64    `json:"both_success"`
+	AvgPrimaryLatencyMs float64  `json:"avg_primary_latency_ms"`
+	AvgMirrorLatencyMs  float64  `json:"avg_mirror_latency_ms"`
+	AvgPrimaryTTFTMs    *float64 `json:"avg_primary_ttft_ms,omitempty"`
+	AvgMirrorTTFTMs     *float64 `json:"avg_mirror_ttft_ms,omitempty"`
+	PrimaryInputTokens  int64    `json:"primary_input_tokens"`
+	PrimaryOutputTokens int64    `json:"primary_output_tokens"`
+	MirrorInputTokens   int64    `json:"mirror_input_tokens"`
+	MirrorOutputTokens  int64    `json:"mirror_output_tokens"`
+	AvgOutputSimilarity *float64 `json:"avg_output_similarity,omitempty"`
+}
+
+// TrafficMirrorRepository 持久化镜像对比记录。
+type TrafficMirrorRepository interface {
+	InsertRecords(ctx context.Context, records []TrafficMirrorRecord) error
+	ListRecords(ctx context.Context, filter TrafficMirrorFilter) ([]TrafficMirrorRecord, int64, error)
+	Summarize(ctx context.Context, filter TrafficMirrorFilter) ([]TrafficMirrorSummary, error)
+	DeleteRecordsBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+type trafficMirrorGroupEntry struct {
+	group     *Group
+	expiresAt time.Time
+}
+
+// TrafficMirrorService 负责镜像抽样、后台重放并发控制与对比记录的异步批量写入。
+// 抽样命中但并发已满、候选分组不可用时直接放弃镜像，绝不影响主请求。
+type TrafficMirrorService struct {
+	repo      TrafficMirrorRepository
+	groupRepo GroupRepository
+
+	inFlight chan struct{}
+	queue    chan TrafficMirrorRecord
+	skipped  atomic.Uint64
+	dropped  atomic.Uint64
+
+	groupMu sync.Mutex
+	groups  map[int64]trafficMirrorGroupEntry
+
+	lastPurge time.Time
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stopCh    chan struct{}
+	doneCh    chan struct{}
+}
+
+// NewTrafficMirrorService 创建流量镜像服务；需调用 Start 启动后台写入。
+func NewTrafficMirrorService(repo TrafficMirrorRepository, groupRepo GroupRepository) *TrafficMirrorService {
+	return &TrafficMirrorService{
+		repo:      repo,
+		groupRepo: groupRepo,
+		inFlight:  make(chan struct{}, maxTrafficMirrorInFlight),
+		queue:     make(chan TrafficMirrorRecord, trafficMirrorQueueSize),
+		groups:    make(map[int64]trafficMirrorGroupEntry),
+		stopCh:    make(chan struct{}),
+		doneCh:    make(chan struct{}),
+	}
+}
+
+// Begin 为源分组的一次请求做镜像抽样。命中时占用一个后台重放名额并返回计划，
+// 调用方必须在影子请求结束（或放弃镜像）后调用 release。
+func (s *TrafficMirrorService) Begin(ctx context.Context, source *Group) (*TrafficMirrorPlan, func(), bool) {
+	if s == nil || source == nil || !source.MirrorPolicy.Enabled || source.MirrorPolicy.SampleRate <= 0 {
+		return nil, nil, false
+	}
+	policy := source.MirrorPolicy
+	if policy.SampleRate < 1 && rand.Float64() >= policy.SampleRate {
+		return nil, nil, false
+	}
+	select {
+	case s.inFlight <- struct{}{}:
+	default:
+		if s.skipped.Add(1)%100 == 1 {
+			logger.L().Warn("traffic_mirror.in_flight_full", zap.Int64("group_id", source.ID), zap.Uint64("skipped_total", s.skipped.Load()))
+		}
+		return nil, nil, false
+	}
+	var once sync.Once
+	release := func() { once.Do(func() { <-s.inFlight }) }
+
+	targetGroupID := source.ID
+	if policy.TargetGroupID != nil {
+		targetGroupID = *policy.TargetGroupID
+	}
+	target, err := s.targetGroup(ctx, targetGroupID)
+	if err != nil {
+		release()
+		logger.L().Warn("traffic_mirror.target_group_unavailable", zap.Int64("group_id", source.ID), zap.Int64("target_group_id", targetGroupID), zap.Error(err))
+		return nil, nil, false
+	}
+	plan := &TrafficMirrorPlan{SourceGroup: source, TargetGroup: target, CompareOutput: policy.CompareOutput}
+	if policy.TargetAccountID != nil {
+		plan.TargetAccountID = *policy.TargetAccountID
+	}
+	return plan, release, true
+}
+
+// targetGroup 带短 TTL 缓存地加载候选分组；composite 分组不能作为镜像目标。
+func (s *TrafficMirrorService) targetGroup(ctx context.Context, id int64) (*Group, error) {
+	now := time.Now()
+	s.groupMu.Lock()
+	entry, ok := s.groups[id]
+	s.groupMu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.group, nil
+	}
+	if s.groupRepo == nil {
+		return nil, ErrGroupNotFound
+	}
+	group, err := s.groupRepo.GetByIDLite(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if group.Platform == PlatformComposite {
+		return nil, fmt.Errorf("mirror target group %d is a composite group", id)
+	}
+	s.groupMu.Lock()
+	s.groups[id] = trafficMirrorGroupEntry{group: group, expiresAt: now.Add(trafficMirrorGroupCacheTTL)}
+	s.groupMu.Unlock()
+	return group, nil
+}
+
+// Record 登记一次镜像对比结果；队列满时丢弃并计数。
+func (s *TrafficMirrorService) Record(record TrafficMirrorRecord) {
+	if s == nil || record.GroupID <= 0 {
+		return
+	}
+	select {
+	case s.queue <- record:
+	default:
+		if s.dropped.Add(1)%1000 == 1 {
+			logger.L().Warn("traffic_mirror.record_queue_full", zap.Uint64("dropped_total", s.dropped.Load()))
+		}
+	}
+}
+
+// Start 启动后台批量写入。
+func (s *TrafficMirrorService) Start() {
+	if s == nil || s.repo == nil {
+		return
+	}
+	s.startOnce.Do(func() {
+		go s.run()
+	})
+}
+
+// Stop 停止后台写入，并尽量落盘队列中剩余的记录。
+func (s *TrafficMirrorService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+		started := true
+		s.startOnce.Do(func() { started = false })
+		if started {
+			<-s.doneCh
+		}
+	})
+}
+
+func (s *TrafficMirrorService) run() {
+	defer close(s.doneCh)
+	ticker := time.NewTicker(trafficMirrorFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]TrafficMirrorRecord, 0, trafficMirrorBatchSize)
+	for {
+		select {
+		case record := <-s.queue:
+			batch = append(batch, record)
+			if len(batch) >= trafficMirrorBatchSize {
+				batch = s.flush(batch)
+			}
+		case <-ticker.C:
+			batch = s.flush(batch)
+			s.purgeExpired()
+		case <-s.stopCh:
+			for {
+				select {
+				case record := <-s.queue:
+					batch = append(batch, record)
+					if len(batch) >= trafficMirrorBatchSize {
+						batch = s.flush(batch)
+					}
+				default:
+					s.flush(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+func (s *TrafficMirrorService) flush(batch []TrafficMirrorRecord) []TrafficMirrorRecord {
+	if len(batch) == 0 {
+		return batch
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), trafficMirrorFlushTimeout)
+	defer cancel()
+	if err := s.repo.InsertRecords(ctx, batch); err != nil {
+		logger.L().Warn("traffic_mirror.record_flush_failed", zap.Int("count", len(batch)), zap.Error(err))
+	}
+	return batch[:0]
+}
+
+func (s *TrafficMirrorService) purgeExpired() {
+	now := time.Now()
+	if now.Sub(s.lastPurge) < trafficMirrorPurgeEvery {
+		return
+	}
+	s.lastPurge = now
+	ctx, cancel := context.WithTimeout(context.Background(), trafficMirrorFlushTimeout)
+	defer cancel()
+	if _, err := s.repo.DeleteRecordsBefore(ctx, now.Add(-trafficMirrorRetention)); err != nil {
+		logger.L().Warn("traffic_mirror.record_purge_failed", zap.Error(err))
+	}
+}
+
+// normalizeFilter 补齐默认时间窗（最近 24 小时）与分页。
+func (s *TrafficMirrorService) normalizeFilter(filter TrafficMirrorFilter) (TrafficMirrorFilter, error) {
+	if filter.EndTime.IsZero() {
+		filter.EndTime = time.Now()
+	}
+	if filter.StartTime.IsZero() {
+		filter.StartTime = filter.EndTime.Add(-defaultTrafficMirrorRange)
+	}
+	if !filter.StartTime.Before(filter.EndTime) {
+		return filter, ErrTrafficMirrorInvalidRange
+	}
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.PageSize <= 0 {
+		filter.PageSize = 20
+	}
+	filter.PageSize = min(filter.PageSize, maxTrafficMirrorPageSize)
+	return filter, nil
+}
+
+// ListRecords 分页返回镜像对比记录（按时间倒序）。
+func (s *TrafficMirrorService) ListRecords(ctx context.Context, filter TrafficMirrorFilter) ([]TrafficMirrorRecord, int64, TrafficMirrorFilter, error) {
+	if s == nil || s.repo == nil {
+		return nil, 0, filter, fmt.Errorf("traffic mirror service is not configured")
+	}
+	filter, err := s.normalizeFilter(filter)
+	if err != nil {
+		return nil, 0, filter, err
+	}
+	records, total, err := s.repo.ListRecords(ctx, filter)
+	if err != nil {
+		return nil, 0, filter, err
+	}
+	if records == nil {
+		records = []TrafficMirrorRecord{}
+	}
+	return records, total, filter, nil
+}
+
+// Summaries 返回时间窗内每个镜像目标的对比汇总。
+func (s *TrafficMirrorService) Summaries(ctx context.Context, filter TrafficMirrorFilter) ([]TrafficMirrorSummary, TrafficMirrorFilter, error) {
+	if s == nil || s.repo == nil {
+		return nil, filter, fmt.Errorf("traffic mirror service is not configured")
+	}
+	filter, err := s.normalizeFilter(filter)
+	if err != nil {
+		return nil, filter, err
+	}
+	rows, err := s.repo.Summarize(ctx, filter)
+	if err != nil {
+		return nil, filter, err
+	}
+	out := make([]TrafficMirrorSummary, 0, len(rows))
+	for _, row := range rows {
+		if row.Total > 0 {
+			row.PrimarySuccessRate = float64(row.PrimarySuccess) / float64(row.Total)
+			row.MirrorSuccessRate = float64(row.MirrorSuccess) / float64(row.Total)
+		}
+		out = append(out, row)
+	}
+	return out, filter, nil
+}
+
+const (
+	// maxTrafficMirrorOutputBytes 观测时最多拼接的输出文本字节数，用于相似度计算与摘录。
+	maxTrafficMirrorOutputBytes  = 8 << 10
+	maxTrafficMirrorExcerptRunes = 2000
+	maxTrafficMirrorErrorRunes   = 500
+	maxTrafficMirrorModelRunes   = 200
+)
+
+// TrafficMirrorResponse 是从响应体（JSON 或 SSE）中解析出的可比较字段。
+type TrafficMirrorResponse struct {
+	Model        string
+	InputTokens  int
+	OutputTokens int
+	Output       string
+	Error        string
+}
+
+// ObserveTrafficMirrorResponse 从 Anthropic Messages、OpenAI Chat Completions 与
+// Responses 三种协议的响应体中提取响应模型、token 用量、输出文本与错误信息。
+// 流式响应逐个解析 SSE data 行，后出现的模型与用量覆盖先出现的。
+func ObserveTrafficMirrorResponse(body []byte) TrafficMirrorResponse {
+	var out TrafficMirrorResponse
+	var text strings.Builder
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') && gjson.ValidBytes(trimmed) {
+		observeTrafficMirrorEvent(gjson.ParseBytes(trimmed), &out, &text)
+	} else {
+		for _, line := range bytes.Split(body, []byte("\n")) {
+			line = bytes.TrimSpace(line)
+			if !bytes.HasPrefix(line, []byte("data:")) {
+				continue
+			}
+			payload := bytes.TrimSpace(line[len("data:"):])
+			if len(payload) == 0 || bytes.Equal(payload, []byte("[DONE]")) || !gjson.ValidBytes(payload) {
+				continue
+			}
+			observeTrafficMirrorEvent(gjson.ParseBytes(payload), &out, &text)
+		}
+	}
+	out.Output = text.String()
+	return out
+}
+
+func observeTrafficMirrorEvent(event gjson.Result, out *TrafficMirrorResponse, text *strings.Builder) {
+	for _, path := range []string{"model", "message.model", "response.model"} {
+		if m := event.Get(path); m.Type == gjson.String && m.String() != "" {
+			out.Model = m.String()
+			break
+		}
+	}
+	for _, path := range []string{"usage", "message.usage", "response.usage"} {
+		usage := event.Get(path)
+		if !usage.IsObject() {
+			continue
+		}
+		input := usage.Get("input_tokens")
+		if !input.Exists() {
+			input = usage.Get("prompt_tokens")
+		}
+		output := usage.Get("output_tokens")
+		if !output.Exists() {
+			output = usage.Get("completion_tokens")
+		}
+		if input.Exists() {
+			out.InputTokens = int(input.Int() + usage.Get("cache_read_input_tokens").Int() + usage.Get("cache_creation_input_tokens").Int())
+		}
+		if output.Exists() {
+			out.OutputTokens = int(output.Int())
+		}
+		break
+	}
+	if e := event.Get("error"); e.Exists() {
+		switch {
+		case e.Get("message").Type == gjson.String:
+			out.Error = e.Get("message").String()
+		case e.Type == gjson.String:
+			out.Error = e.String()
+		}
+	}
+
+	appendText := func(s string) {
+		if s == "" || text.Len() >= maxTrafficMirrorOutputBytes {
+			return
+		}
+		if remain := maxTrafficMirrorOutputBytes - text.Len(); len(s) > remain {
+			s = strings.ToValidUTF8(s[:remain], "")
+		}
+		text.WriteString(s)
+	}
+	// Anthropic：非流式 content[].text，流式 content_block_delta 的 delta.text。
+	event.Get("content").ForEach(func(_, block gjson.Result) bool {
+		if block.Get("type").String() == "text" {
+			appendText(block.Get("text").String())
+		}
+		return true
+	})
+	if delta := event.Get("delta.text"); delta.Type == gjson.String {
+		appendText(delta.String())
+	}
+	// Chat Completions：choices.0.message.content / choices.0.delta.content。
+	if content := event.Get("choices.0.message.content"); content.Type == gjson.String {
+		appendText(content.String())
+	}
+	if content := event.Get("choices.0.delta.content"); content.Type == gjson.String {
+		appendText(content.String())
+	}
+	// Responses：非流式 output[].content[] 的 output_text；流式 response.output_text.delta。
+	event.Get("output").ForEach(func(_, item gjson.Result) bool {
+		item.Get("content").ForEach(func(_, part gjson.Result) bool {
+			if part.Get("type").String() == "output_text" {
+				appendText(part.Get("text").String())
+			}
+			return true
+		})
+		return true
+	})
+	if event.Get("type").String() == "response.output_text.delta" {
+		appendText(event.Get("delta").String())
+	}
+}
+
+// TrafficMirrorOutputSimilarity 计算两段输出的词集合 Jaccard 相似度（0~1）。
+// 英文按非字母数字切词并忽略大小写，中日韩字符逐字计为一个词；两段都为空时视为一致。
+func TrafficMirrorOutputSimilarity(a, b string) float64 {
+	setA := trafficMirrorTokenSet(a)
+	setB := trafficMirrorTokenSet(b)
+	if len(setA) == 0 && len(setB) == 0 {
+		return 1
+	}
+	intersection := 0
+	for token := range setA {
+		if _, ok := setB[token]; ok {
+			intersection++
+		}
+	}
+	union := len(setA) + len(setB) - intersection
+	return float64(intersection) / float64(union)
+}
+
+func trafficMirrorTokenSet(s string) map[string]struct{} {
+	set := make(map[string]struct{})
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			set[word.String()] = struct{}{}
+			word.Reset()
+		}
+	}
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r):
+			flush()
+			set[string(r)] = struct{}{}
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word.WriteRune(r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return set
+}
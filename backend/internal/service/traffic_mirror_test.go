@@ -0,0 +1,212 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/stretchr/testify/require"
+)
+
+type trafficMirrorRepoStub struct {
+	mu       sync.Mutex
+	inserted []TrafficMirrorRecord
+}
+
+func (s *trafficMirrorRepoStub) InsertRecords(_ context.Context, records []TrafficMirrorRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inserted = append(s.inserted, records...)
+	return nil
+}
+
+func (s *trafficMirrorRepoStub) ListRecords(context.Context, TrafficMirrorFilter) ([]TrafficMirrorRecord, int64, error) {
+	return nil, 0, nil
+}
+
+func (s *trafficMirrorRepoStub) Summarize(context.Context, TrafficMirrorFilter) ([]TrafficMirrorSummary, error) {
+	return []TrafficMirrorSummary{{GroupID: 1, TargetGroupID: 2, Total: 4, PrimarySuccess: 4, MirrorSuccess: 3}}, nil
+}
+
+func (s *trafficMirrorRepoStub) DeleteRecordsBefore(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+
+type trafficMirrorGroupRepoStub struct {
+	GroupRepository
+	groups map[int64]*Group
+	loads  int
+}
+
+func (s *trafficMirrorGroupRepoStub) GetByIDLite(_ context.Context, id int64) (*Group, error) {
+	s.loads++
+	if g, ok := s.groups[id]; ok {
+		return g, nil
+	}
+	return nil, ErrGroupNotFound
+}
+
+func TestNormalizeGroupMirrorPolicy(t *testing.T) {
+	zero := int64(0)
+	target := int64(9)
+
+	policy, err := NormalizeGroupMirrorPolicy(GroupMirrorPolicy{TargetGroupID: &zero})
+	require.NoError(t, err)
+	require.Nil(t, policy.TargetGroupID, "non-positive ids are treated as unset")
+
+	policy, err = NormalizeGroupMirrorPolicy(GroupMirrorPolicy{Enabled: true, SampleRate: 0.05, TargetGroupID: &target})
+	require.NoError(t, err)
+	require.Equal(t, int64(9), *policy.TargetGroupID)
+
+	for name, bad := range map[string]GroupMirrorPolicy{
+		"rate above one":    {SampleRate: 1.5},
+		"negative rate":     {SampleRate: -0.1},
+		"enabled zero":      {Enabled: true, TargetGroupID: &target},
+		"enabled no target": {Enabled: true, SampleRate: 0.1},
+	} {
+		_, err := NormalizeGroupMirrorPolicy(bad)
+		require.Error(t, err, name)
+	}
+}
+
+func TestObserveTrafficMirrorResponse(t *testing.T) {
+	anthropic := ObserveTrafficMirrorResponse([]byte(`{"model":"claude-sonnet-4-5","content":[{"type":"thinking","thinking":"x"},{"type":"text","text":"Hello world"}],"usage":{"input_tokens":10,"cache_read_input_tokens":5,"output_tokens":3}}`))
+	require.Equal(t, "claude-sonnet-4-5", anthropic.Model)
+	require.Equal(t, 15, anthropic.InputTokens)
+	require.Equal(t, 3, anthropic.OutputTokens)
+	require.Equal(t, "Hello world", anthropic.Output)
+
+	chat := ObserveTrafficMirrorResponse([]byte(`{"model":"gpt-5","choices":[{"message":{"content":"Hi"}}],"usage":{"prompt_tokens":7,"completion_tokens":2}}`))
+	require.Equal(t, "gpt-5", chat.Model)
+	require.Equal(t, 7, chat.InputTokens)
+	require.Equal(t, 2, chat.OutputTokens)
+	require.Equal(t, "Hi", chat.Output)
+
+	anthropicStream := ObserveTrafficMirrorResponse([]byte("event: message_start\n" +
+		`data: {"type":"message_start","message":{"model":"claude-opus-4-1","usage":{"input_tokens":20,"output_tokens":1}}}` + "\n\n" +
+		`data: {"type":"content_block_delta","delta":{"type":"text_delta","text":"Hel"}}` + "\n\n" +
+		`data: {"type":"content_block_delta","delta":{"type":"text_delta","text":"lo"}}` + "\n\n" +
+		`data: {"type":"message_delta","usage":{"output_tokens":9}}` + "\n\n"))
+	require.Equal(t, "claude-opus-4-1", anthropicStream.Model)
+	require.Equal(t, 20, anthropicStream.InputTokens)
+	require.Equal(t, 9, anthropicStream.OutputTokens, "later usage events win")
+	require.Equal(t, "Hello", anthropicStream.Output)
+
+	responsesStream := ObserveTrafficMirrorResponse([]byte(
+		`data: {"type":"response.output_text.delta","delta":"Bon"}` + "\n\n" +
+			`data: {"type":"response.output_text.delta","delta":"jour"}` + "\n\n" +
+			`data: {"type":"response.completed","response":{"model":"gpt-5-mini","usage":{"input_tokens":4,"output_tokens":2}}}` + "\n\n" +
+			"data: [DONE]\n\n"))
+	require.Equal(t, "gpt-5-mini", responsesStream.Model)
+	require.Equal(t, 4, responsesStream.InputTokens)
+	require.Equal(t, "Bonjour", responsesStream.Output)
+
+	failed := ObserveTrafficMirrorResponse([]byte(`{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`))
+	require.Equal(t, "Overloaded", failed.Error)
+}
+
+func TestTrafficMirrorOutputSimilarity(t *testing.T) {
+	require.Equal(t, 1.0, TrafficMirrorOutputSimilarity("", ""))
+	require.Equal(t, 1.0, TrafficMirrorOutputSimilarity("Hello, World!", "hello world"))
+	require.InDelta(t, 0.4, TrafficMirrorOutputSimilarity("a b c", "b c d e"), 1e-9)
+	require.Zero(t, TrafficMirrorOutputSimilarity("foo", ""))
+	require.InDelta(t, 2.0/3.0, TrafficMirrorOutputSimilarity("你好", "你好吗"), 1e-9, "CJK runes count as single tokens")
+}
+
+func TestWithTrafficMirror_PinsAccountAndClearsCompositeDecision(t *testing.T) {
+	target := &Group{ID: 2, Platform: PlatformOpenAI, Hydrated: true}
+	ctx := WithCompositeRouteDecision(context.Background(), CompositeRouteDecision{
+		Matched:        true,
+		TargetPlatform: PlatformAnthropic,
+		UpstreamModel:  "claude-sonnet-4-5",
+		PublicModel:    "smart",
+	})
+	require.False(t, IsTrafficMirrorContext(ctx))
+
+	ctx = WithTrafficMirror(ctx, &TrafficMirrorPlan{SourceGroup: &Group{ID: 1}, TargetGroup: target, TargetAccountID: 42})
+	require.True(t, IsTrafficMirrorContext(ctx))
+	require.Same(t, target, ctx.Value(ctxkey.Group))
+	_, resolved := ResolvedTargetPlatformFromContext(ctx)
+	require.False(t, resolved, "the candidate group routes the request on its own platform")
+	_, resolved = ResolvedUpstreamModelFromContext(ctx)
+	require.False(t, resolved)
+
+	accounts := []Account{{ID: 41}, {ID: 42}, {ID: 43}}
+	require.Equal(t, []Account{{ID: 42}}, filterTrafficMirrorPinnedAccounts(ctx, accounts))
+	require.NoError(t, checkTrafficMirrorPinnedAccount(ctx, 42))
+	require.ErrorIs(t, checkTrafficMirrorPinnedAccount(ctx, 41), errTrafficMirrorAccountPinned)
+
+	plain := context.Background()
+	require.Len(t, filterTrafficMirrorPinnedAccounts(plain, accounts), 3)
+	require.NoError(t, checkTrafficMirrorPinnedAccount(plain, 41))
+}
+
+func TestTrafficMirrorService_BeginSamplesAndRecords(t *testing.T) {
+	targetID := int64(2)
+	repo := &trafficMirrorRepoStub{}
+	groups := &trafficMirrorGroupRepoStub{groups: map[int64]*Group{
+		2: {ID: 2, Platform: PlatformAnthropic, Hydrated: true},
+		3: {ID: 3, Platform: PlatformComposite, Hydrated: true},
+	}}
+	svc := NewTrafficMirrorService(repo, groups)
+	svc.Start()
+
+	source := &Group{ID: 1, Platform: PlatformOpenAI, MirrorPolicy: GroupMirrorPolicy{Enabled: true, SampleRate: 1, TargetGroupID: &targetID, CompareOutput: true}}
+	plan, release, ok := svc.Begin(context.Background(), source)
+	require.True(t, ok)
+	require.Equal(t, int64(2), plan.TargetGroup.ID)
+	release()
+	release() // 重复释放不会多还名额
+
+	_, _, ok = svc.Begin(context.Background(), source)
+	require.True(t, ok)
+	require.Equal(t, 1, groups.loads, "target group is cached")
+
+	_, _, ok = svc.Begin(context.Background(), &Group{ID: 1, MirrorPolicy: GroupMirrorPolicy{SampleRate: 1, TargetGroupID: &targetID}})
+	require.False(t, ok, "disabled policy never mirrors")
+	compositeID := int64(3)
+	_, _, ok = svc.Begin(context.Background(), &Group{ID: 1, MirrorPolicy: GroupMirrorPolicy{Enabled: true, SampleRate: 1, TargetGroupID: &compositeID}})
+	require.False(t, ok, "composite groups cannot be mirror targets")
+
+	full := NewTrafficMirrorService(repo, groups)
+	for i := 0; i < maxTrafficMirrorInFlight; i++ {
+		_, _, ok := full.Begin(context.Background(), source)
+		require.True(t, ok)
+	}
+	_, _, ok = full.Begin(context.Background(), source)
+	require.False(t, ok, "mirrors are skipped instead of queued when all slots are busy")
+
+	primaryTTFT := 120
+	record := NewTrafficMirrorRecord(plan, TrafficMirrorRequest{ClientRequestID: "req-1", Endpoint: CompositeRouteEndpointMessages, Model: "gpt-5"},
+		TrafficMirrorObservation{AccountID: 5, Status: 200, LatencyMs: 900, TTFTMs: &primaryTTFT, Model: "gpt-5", Output: "the answer is 42"},
+		TrafficMirrorObservation{AccountID: 8, Status: 200, LatencyMs: 1200, Model: "claude-sonnet-4-5", Output: "the answer is 41"},
+	)
+	require.EqualValues(t, 1, record.GroupID)
+	require.EqualValues(t, 2, record.TargetGroupID)
+	require.Nil(t, record.TargetAccountID)
+	require.EqualValues(t, 8, *record.MirrorAccountID)
+	require.InDelta(t, 0.6, *record.OutputSimilarity, 1e-9)
+	require.Equal(t, "the answer is 41", *record.MirrorOutputExcerpt)
+
+	failed := NewTrafficMirrorRecord(plan, TrafficMirrorRequest{}, TrafficMirrorObservation{Status: 200}, TrafficMirrorObservation{Status: 502, Error: "bad gateway"})
+	require.Nil(t, failed.OutputSimilarity, "outputs are only compared when both sides succeed")
+	require.Equal(t, "bad gateway", failed.MirrorError)
+
+	svc.Record(record)
+	svc.Record(TrafficMirrorRecord{}) // 无源分组的记录被忽略
+	svc.Stop()
+	require.Len(t, repo.inserted, 1, "Stop flushes queued records")
+	require.Equal(t, "req-1", repo.inserted[0].ClientRequestID)
+
+	summaries, filter, err := svc.Summaries(context.Background(), TrafficMirrorFilter{})
+	require.NoError(t, err)
+	require.InDelta(t, 0.75, summaries[0].MirrorSuccessRate, 1e-9)
+	require.WithinDuration(t, filter.EndTime.Add(-24*time.Hour), filter.StartTime, time.Second)
+
+	_, _, err = svc.Summaries(context.Background(), TrafficMirrorFilter{StartTime: time.Now(), EndTime: time.Now().Add(-time.Hour)})
+	require.ErrorIs(t, err, ErrTrafficMirrorInvalidRange)
+}
@@ -168,6 +168,8 @@ type UsageBillingApplyResult struct {
 	NewBalance           *float64           // post-deduction balance (nil = no balance deduction)
 	BalanceOverdrafted   bool               // true when the sufficient-balance guard missed and debt was still recorded
 	QuotaState           *AccountQuotaState // post-increment quota state (nil = no quota increment)
+	OrganizationID       *int64             // set when the balance cost was charged to an organization wallet
+	OrganizationBalance  *float64           // post-deduction organization wallet balance
 }
 
 // BatchImageBalanceHoldCommand describes an idempotent balance hold operation.
@@ -178,8 +180,11 @@ type BatchImageBalanceHoldCommand struct {
 	RequestPayloadHash string
 	UserID             int64
 	BatchID            string
-	HoldAmount         float64
-	ActualAmount       float64
+	// HoldRequestID 是释放前用于校验冻结确实发生过的 hold request id；
+	// 为空时按批量生图前缀由 BatchID 推导。
+	HoldRequestID string
+	HoldAmount    float64
+	ActualAmount  float64
 }
 
 func (c *BatchImageBalanceHoldCommand) Normalize() {
@@ -188,6 +193,7 @@ func (c *BatchImageBalanceHoldCommand) Normalize() {
 	}
 	c.RequestID = strings.TrimSpace(c.RequestID)
 	c.BatchID = strings.TrimSpace(c.BatchID)
+	c.HoldRequestID = strings.TrimSpace(c.HoldRequestID)
 	if strings.TrimSpace(c.RequestFingerprint) == "" {
 		c.RequestFingerprint = buildBatchImageBalanceHoldFingerprint(c)
 	}
@@ -216,6 +222,8 @@ type BatchImageBalanceHoldResult struct {
 	Applied       bool
 	NewBalance    *float64
 	FrozenBalance *float64
+	// OrganizationID 非空表示 hold 落在组织钱包上，此时 NewBalance/FrozenBalance 为组织钱包余额。
+	OrganizationID *int64
 }
 
 type UsageBillingRepository interface {
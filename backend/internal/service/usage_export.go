@@ -0,0 +1,323 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+	"github.com/parquet-go/parquet-go"
+)
+
+// 导出周期。周期按系统时区切分，周从周一开始。
+const (
+	UsageExportFrequencyDaily   = "daily"
+	UsageExportFrequencyWeekly  = "weekly"
+	UsageExportFrequencyMonthly = "monthly"
+)
+
+// 导出文件格式。
+const (
+	UsageExportFormatCSV     = "csv"
+	UsageExportFormatParquet = "parquet"
+)
+
+// 导出目标存储：复用数据库备份的 S3 配置，或异步生图的对象存储。
+const (
+	UsageExportStorageBackup       = "backup"
+	UsageExportStorageImageStorage = "image_storage"
+)
+
+// 导出运行状态。
+const (
+	UsageExportRunStatusPending   = "pending"
+	UsageExportRunStatusRunning   = "running"
+	UsageExportRunStatusSucceeded = "succeeded"
+	UsageExportRunStatusFailed    = "failed"
+)
+
+// 导出运行的触发来源。
+const (
+	UsageExportTriggerSchedule = "schedule"
+	UsageExportTriggerManual   = "manual"
+	UsageExportTriggerBackfill = "backfill"
+	UsageExportTriggerUser     = "user"
+)
+
+var (
+	ErrUsageExportJobNotFound      = infraerrors.New(http.StatusNotFound, "USAGE_EXPORT_JOB_NOT_FOUND", "usage export job not found")
+	ErrUsageExportRunNotFound      = infraerrors.New(http.StatusNotFound, "USAGE_EXPORT_RUN_NOT_FOUND", "usage export not found")
+	ErrUsageExportInvalidJob       = infraerrors.BadRequest("USAGE_EXPORT_INVALID_JOB", "invalid usage export job")
+	ErrUsageExportInvalidRange     = infraerrors.BadRequest("USAGE_EXPORT_INVALID_RANGE", "invalid usage export date range")
+	ErrUsageExportTooManyPending   = infraerrors.TooManyRequests("USAGE_EXPORT_TOO_MANY_PENDING", "too many usage exports in progress; wait for them to finish")
+	ErrUsageExportNotReady         = infraerrors.Conflict("USAGE_EXPORT_NOT_READY", "usage export has not finished successfully")
+	ErrUsageExportStorageNotConfig = infraerrors.BadRequest("USAGE_EXPORT_STORAGE_NOT_CONFIGURED", "the selected export storage is not configured")
+)
+
+// UsageExportFilter 导出过滤条件；空列表表示不限。
+type UsageExportFilter struct {
+	GroupIDs []int64  `json:"group_ids,omitempty"`
+	UserIDs  []int64  `json:"user_ids,omitempty"`
+	Models   []string `json:"models,omitempty"`
+}
+
+// UsageExportJob 管理员定义的周期导出任务。
+type UsageExportJob struct {
+	ID        int64             `json:"id"`
+	Name      string            `json:"name"`
+	Frequency string            `json:"frequency"`
+	Format    string            `json:"format"`
+	Storage   string            `json:"storage"`
+	Prefix    string            `json:"prefix"`
+	Filters   UsageExportFilter `json:"filters"`
+	Enabled   bool              `json:"enabled"`
+	// NextPeriodStart 尚未生成导出的第一个周期起点。
+	NextPeriodStart time.Time `json:"next_period_start"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// UsageExportRun 一次导出（一个文件）。JobID 为空且 UserID 非空表示用户自助导出。
+type UsageExportRun struct {
+	ID           int64             `json:"id"`
+	JobID        *int64            `json:"job_id,omitempty"`
+	JobName      string            `json:"job_name,omitempty"`
+	UserID       *int64            `json:"user_id,omitempty"`
+	TriggeredBy  string            `json:"triggered_by"`
+	Format       string            `json:"format"`
+	Storage      string            `json:"storage"`
+	Filters      UsageExportFilter `json:"filters"`
+	PeriodStart  time.Time         `json:"period_start"`
+	PeriodEnd    time.Time         `json:"period_end"`
+	ObjectKey    string            `json:"object_key"`
+	Status       string            `json:"status"`
+	RowCount     int64             `json:"row_count"`
+	SizeBytes    int64             `json:"size_bytes"`
+	SHA256       string            `json:"sha256"`
+	ErrorMessage string            `json:"error_message,omitempty"`
+	StartedAt    *time.Time        `json:"started_at,omitempty"`
+	FinishedAt   *time.Time        `json:"finished_at,omitempty"`
+	CreatedAt    time.Time         `json:"created_at"`
+}
+
+// UsageExportRunFilter 导出记录列表筛选；JobID/UserID 为 0 表示不限，OnlyJobs 只列任务导出。
+type UsageExportRunFilter struct {
+	JobID    int64
+	UserID   int64
+	OnlyJobs bool
+	Status   string
+	Page     int
+	PageSize int
+}
+
+// UsageExportQuery 一次导出读取的用量范围 [Start, End)。
+type UsageExportQuery struct {
+	Filter UsageExportFilter
+	Start  time.Time
+	End    time.Time
+}
+
+// UsageExportRow 导出文件中的一行用量明细。
+type UsageExportRow struct {
+	ID                  int64     `parquet:"id"`
+	CreatedAt           time.Time `parquet:"created_at,timestamp(millisecond)"`
+	UserID              int64     `parquet:"user_id"`
+	UserEmail           string    `parquet:"user_email"`
+	APIKeyID            int64     `parquet:"api_key_id"`
+	APIKeyName          string    `parquet:"api_key_name"`
+	AccountID           int64     `parquet:"account_id"`
+	GroupID             int64     `parquet:"group_id"`
+	GroupName           string    `parquet:"group_name"`
+	Model               string    `parquet:"model"`
+	RequestedModel      string    `parquet:"requested_model"`
+	RequestID           string    `parquet:"request_id"`
+	InputTokens         int64     `parquet:"input_tokens"`
+	OutputTokens        int64     `parquet:"output_tokens"`
+	CacheCreationTokens int64     `parquet:"cache_creation_tokens"`
+	CacheReadTokens     int64     `parquet:"cache_read_tokens"`
+	TotalCost           float64   `parquet:"total_cost"`
+	ActualCost          float64   `parquet:"actual_cost"`
+	RateMultiplier      float64   `parquet:"rate_multiplier"`
+	BillingType         int64     `parquet:"billing_type"`
+	Stream              bool      `parquet:"stream"`
+	DurationMs          int64     `parquet:"duration_ms"`
+}
+
+type UsageExportRepository interface {
+	CreateJob(ctx context.Context, job *UsageExportJob) error
+	UpdateJob(ctx context.Context, job *UsageExportJob) error
+	DeleteJob(ctx context.Context, id int64) error
+	GetJob(ctx context.Context, id int64) (*UsageExportJob, error)
+	ListJobs(ctx context.Context) ([]UsageExportJob, error)
+	// AdvanceJob 记录已生成导出的周期，下一次从 next 开始。
+	AdvanceJob(ctx context.Context, id int64, next time.Time) error
+
+	CreateRun(ctx context.Context, run *UsageExportRun) error
+	GetRun(ctx context.Context, id int64) (*UsageExportRun, error)
+	ListRuns(ctx context.Context, filter UsageExportRunFilter) ([]UsageExportRun, int64, error)
+	ListPendingRuns(ctx context.Context, limit int) ([]UsageExportRun, error)
+	// CountActiveUserRuns 返回用户 pending/running 的自助导出数。
+	CountActiveUserRuns(ctx context.Context, userID int64) (int, error)
+	// ClaimRun 把 pending 改为 running，返回是否抢到（多实例下只有一个实例执行）。
+	ClaimRun(ctx context.Context, id int64, startedAt time.Time) (bool, error)
+	FinishRun(ctx context.Context, run *UsageExportRun) error
+	// FailStaleRuns 把 startedAt 早于 before 仍为 running 的记录标记为失败（进程重启遗留）。
+	FailStaleRuns(ctx context.Context, before time.Time) (int64, error)
+
+	// StreamUsage 按 id 顺序流式读取范围内的用量明细。
+	StreamUsage(ctx context.Context, query UsageExportQuery, fn func(row *UsageExportRow) error) error
+}
+
+// usageExportPeriodStart 返回 t 所在周期的起点。
+func usageExportPeriodStart(frequency string, t time.Time) time.Time {
+	switch frequency {
+	case UsageExportFrequencyWeekly:
+		return timezone.StartOfWeek(t)
+	case UsageExportFrequencyMonthly:
+		return timezone.StartOfMonth(t)
+	default:
+		return timezone.StartOfDay(t)
+	}
+}
+
+// usageExportPeriodEnd 返回以 start 为起点的周期终点（下一周期起点）。
+func usageExportPeriodEnd(frequency string, start time.Time) time.Time {
+	switch frequency {
+	case UsageExportFrequencyWeekly:
+		return start.AddDate(0, 0, 7)
+	case UsageExportFrequencyMonthly:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// usageExportFileName 导出文件名：起止日期（结束日期为开区间）+ 格式扩展名。
+func usageExportFileName(start, end time.Time, format string) string {
+	return fmt.Sprintf("%s_%s.%s", start.Format("20060102"), end.Format("20060102"), format)
+}
+
+func joinObjectKey(parts ...string) string {
+	segments := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.Trim(p, "/"); p != "" {
+			segments = append(segments, p)
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func usageExportContentType(format string) string {
+	if format == UsageExportFormatParquet {
+		return "application/vnd.apache.parquet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+type usageExportColumn struct {
+	name  string
+	value func(r *UsageExportRow) any
+}
+
+// usageExportColumns CSV 导出文件的列；Parquet 的列由 UsageExportRow 的 parquet 标签定义，两者顺序与列名一致。
+var usageExportColumns = []usageExportColumn{
+	{"id", func(r *UsageExportRow) any { return r.ID }},
+	{"created_at", func(r *UsageExportRow) any { return r.CreatedAt }},
+	{"user_id", func(r *UsageExportRow) any { return r.UserID }},
+	{"user_email", func(r *UsageExportRow) any { return r.UserEmail }},
+	{"api_key_id", func(r *UsageExportRow) any { return r.APIKeyID }},
+	{"api_key_name", func(r *UsageExportRow) any { return r.APIKeyName }},
+	{"account_id", func(r *UsageExportRow) any { return r.AccountID }},
+	{"group_id", func(r *UsageExportRow) any { return r.GroupID }},
+	{"group_name", func(r *UsageExportRow) any { return r.GroupName }},
+	{"model", func(r *UsageExportRow) any { return r.Model }},
+	{"requested_model", func(r *UsageExportRow) any { return r.RequestedModel }},
+	{"request_id", func(r *UsageExportRow) any { return r.RequestID }},
+	{"input_tokens", func(r *UsageExportRow) any { return r.InputTokens }},
+	{"output_tokens", func(r *UsageExportRow) any { return r.OutputTokens }},
+	{"cache_creation_tokens", func(r *UsageExportRow) any { return r.CacheCreationTokens }},
+	{"cache_read_tokens", func(r *UsageExportRow) any { return r.CacheReadTokens }},
+	{"total_cost", func(r *UsageExportRow) any { return r.TotalCost }},
+	{"actual_cost", func(r *UsageExportRow) any { return r.ActualCost }},
+	{"rate_multiplier", func(r *UsageExportRow) any { return r.RateMultiplier }},
+	{"billing_type", func(r *UsageExportRow) any { return r.BillingType }},
+	{"stream", func(r *UsageExportRow) any { return r.Stream }},
+	{"duration_ms", func(r *UsageExportRow) any { return r.DurationMs }},
+}
+
+// usageExportParquetRowGroupSize 每个行组缓存的行数；行组写出后即释放内存。
+const usageExportParquetRowGroupSize = 100_000
+
+// usageExportEncoder 把用量行写成导出文件。
+type usageExportEncoder interface {
+	Write(row *UsageExportRow) error
+	Close() error
+}
+
+func newUsageExportEncoder(format string, w io.Writer) (usageExportEncoder, error) {
+	if format == UsageExportFormatParquet {
+		pw := parquet.NewGenericWriter[UsageExportRow](w,
+			parquet.Compression(&parquet.Gzip),
+			parquet.MaxRowsPerRowGroup(usageExportParquetRowGroupSize),
+		)
+		return &usageExportParquetEncoder{w: pw, rows: make([]UsageExportRow, 1)}, nil
+	}
+	cw := csv.NewWriter(w)
+	header := make([]string, len(usageExportColumns))
+	for i, c := range usageExportColumns {
+		header[i] = c.name
+	}
+	if err := cw.Write(header); err != nil {
+		return nil, err
+	}
+	return &usageExportCSVEncoder{w: cw, record: make([]string, len(header))}, nil
+}
+
+type usageExportCSVEncoder struct {
+	w      *csv.Writer
+	record []string
+}
+
+func (e *usageExportCSVEncoder) Write(row *UsageExportRow) error {
+	for i, c := range usageExportColumns {
+		switch v := c.value(row).(type) {
+		case int64:
+			e.record[i] = strconv.FormatInt(v, 10)
+		case float64:
+			e.record[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			e.record[i] = strconv.FormatBool(v)
+		case time.Time:
+			e.record[i] = v.UTC().Format(time.RFC3339Nano)
+		case string:
+			e.record[i] = v
+		}
+	}
+	return e.w.Write(e.record)
+}
+
+func (e *usageExportCSVEncoder) Close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type usageExportParquetEncoder struct {
+	w    *parquet.GenericWriter[UsageExportRow]
+	rows []UsageExportRow
+}
+
+func (e *usageExportParquetEncoder) Write(row *UsageExportRow) error {
+	e.rows[0] = *row
+	_, err := e.w.Write(e.rows)
+	return err
+}
+
+func (e *usageExportParquetEncoder) Close() error {
+	return e.w.Close()
+}
@@ -0,0 +1,682 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	usageExportLeaderLockKey = "usage_export:schedule:leader"
+	usageExportLeaderLockTTL = 10 * time.Minute
+	usageExportRunTimeout    = 30 * time.Minute
+	usageExportPollInterval  = time.Minute
+	usageExportStopTimeout   = 5 * time.Second
+	usageExportPendingBatch  = 10
+	usageExportErrorMaxLen   = 500
+	usageExportDefaultPrefix = "usage-exports"
+)
+
+var usageExportCronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+
+// UsageExportJobInput 创建/更新导出任务的参数。
+type UsageExportJobInput struct {
+	Name      string
+	Frequency string
+	Format    string
+	Storage   string
+	Prefix    string
+	Filters   UsageExportFilter
+	Enabled   bool
+}
+
+// UsageExportService 把 usage_logs 按任务定义导出为 CSV/Parquet 文件写入对象存储。
+//
+// 任务只负责"生成哪些周期"：调度把每个已结束、尚未导出的周期登记为 pending 记录；
+// 实际导出由各实例的 worker 抢占 pending 记录执行，因此补跑与用户导出也走同一条路径。
+type UsageExportService struct {
+	repo         UsageExportRepository
+	backup       *BackupService
+	imageStorage *ImageStorageSettingService
+	cfg          *config.Config
+
+	// resolveStore 按存储类型构建对象存储，测试中可替换。
+	resolveStore func(ctx context.Context, storage string) (BackupObjectStore, error)
+
+	lockCache  LeaderLockCache
+	db         *sql.DB
+	instanceID string
+
+	kick chan struct{}
+
+	mu      sync.Mutex
+	cron    *cron.Cron
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	started bool
+	stopped bool
+}
+
+func NewUsageExportService(
+	repo UsageExportRepository,
+	backup *BackupService,
+	imageStorage *ImageStorageSettingService,
+	cfg *config.Config,
+) *UsageExportService {
+	s := &UsageExportService{
+		repo:         repo,
+		backup:       backup,
+		imageStorage: imageStorage,
+		cfg:          cfg,
+		instanceID:   uuid.NewString(),
+		kick:         make(chan struct{}, 1),
+	}
+	s.resolveStore = s.objectStore
+	return s
+}
+
+// SetLeaderLock 注入 leader 锁；两者均为 nil 时不做选主（单实例/测试）。
+func (s *UsageExportService) SetLeaderLock(lockCache LeaderLockCache, db *sql.DB) {
+	if s == nil {
+		return
+	}
+	s.lockCache = lockCache
+	s.db = db
+}
+
+// ─── 任务管理 ───
+
+func normalizeUsageExportJobInput(in *UsageExportJobInput) error {
+	in.Name = strings.TrimSpace(in.Name)
+	in.Prefix = strings.Trim(strings.TrimSpace(in.Prefix), "/")
+	if in.Name == "" || len(in.Name) > 100 {
+		return ErrUsageExportInvalidJob.WithMetadata(map[string]string{"field": "name"})
+	}
+	switch in.Frequency {
+	case UsageExportFrequencyDaily, UsageExportFrequencyWeekly, UsageExportFrequencyMonthly:
+	default:
+		return ErrUsageExportInvalidJob.WithMetadata(map[string]string{"field": "frequency"})
+	}
+	switch in.Format {
+	case UsageExportFormatCSV, UsageExportFormatParquet:
+	default:
+		return ErrUsageExportInvalidJob.WithMetadata(map[string]string{"field": "format"})
+	}
+	switch in.Storage {
+	case UsageExportStorageBackup, UsageExportStorageImageStorage:
+	default:
+		return ErrUsageExportInvalidJob.WithMetadata(map[string]string{"field": "storage"})
+	}
+	if len(in.Prefix) > 200 || strings.Contains(in.Prefix, "..") {
+		return ErrUsageExportInvalidJob.WithMetadata(map[string]string{"field": "prefix"})
+	}
+	models := in.Filters.Models[:0]
+	for _, m := range in.Filters.Models {
+		if m = strings.TrimSpace(m); m != "" {
+			models = append(models, m)
+		}
+	}
+	in.Filters.Models = models
+	return nil
+}
+
+// usageExportLastCompletePeriod 返回 now 之前最后一个已结束周期的起点。
+func usageExportLastCompletePeriod(frequency string, now time.Time) time.Time {
+	current := usageExportPeriodStart(frequency, now)
+	return usageExportPeriodStart(frequency, current.Add(-time.Nanosecond))
+}
+
+// CreateJob 创建导出任务。新任务从最近一个已结束的周期开始导出。
+func (s *UsageExportService) CreateJob(ctx context.Context, in UsageExportJobInput) (*UsageExportJob, error) {
+	if err := normalizeUsageExportJobInput(&in); err != nil {
+		return nil, err
+	}
+	job := &UsageExportJob{
+		Name:            in.Name,
+		Frequency:       in.Frequency,
+		Format:          in.Format,
+		Storage:         in.Storage,
+		Prefix:          in.Prefix,
+		Filters:         in.Filters,
+		Enabled:         in.Enabled,
+		NextPeriodStart: usageExportLastCompletePeriod(in.Frequency, timezone.Now()),
+	}
+	if err := s.repo.CreateJob(ctx, job); err != nil {
+		return nil, fmt.Errorf("create usage export job: %w", err)
+	}
+	return job, nil
+}
+
+// UpdateJob 更新导出任务。修改频率时把下一周期起点对齐到新频率的周期边界。
+func (s *UsageExportService) UpdateJob(ctx context.Context, id int64, in UsageExportJobInput) (*UsageExportJob, error) {
+	if err := normalizeUsageExportJobInput(&in); err != nil {
+		return nil, err
+	}
+	job, err := s.repo.GetJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Frequency != in.Frequency {
+		job.NextPeriodStart = usageExportPeriodStart(in.Frequency, job.NextPeriodStart)
+	}
+	job.Name = in.Name
+	job.Frequency = in.Frequency
+	job.Format = in.Format
+	job.Storage = in.Storage
+	job.Prefix = in.Prefix
+	job.Filters = in.Filters
+	job.Enabled = in.Enabled
+	if err := s.repo.UpdateJob(ctx, job); err != nil {
+		return nil, fmt.Errorf("update usage export job: %w", err)
+	}
+	return job, nil
+}
+
+func (s *UsageExportService) DeleteJob(ctx context.Context, id int64) error {
+	return s.repo.DeleteJob(ctx, id)
+}
+
+func (s *UsageExportService) GetJob(ctx context.Context, id int64) (*UsageExportJob, error) {
+	return s.repo.GetJob(ctx, id)
+}
+
+func (s *UsageExportService) ListJobs(ctx context.Context) ([]UsageExportJob, error) {
+	return s.repo.ListJobs(ctx)
+}
+
+// RunJobNow 立即导出任务最近一个已结束的周期（已导出过则覆盖）。
+func (s *UsageExportService) RunJobNow(ctx context.Context, id int64) (*UsageExportRun, error) {
+	job, err := s.repo.GetJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	start := usageExportLastCompletePeriod(job.Frequency, timezone.Now())
+	run, err := s.enqueueJobRun(ctx, job, start, UsageExportTriggerManual)
+	if err != nil {
+		return nil, err
+	}
+	s.kickWorker()
+	return run, nil
+}
+
+// Backfill 为 [from, to) 覆盖到的每个已结束周期登记一次导出，已导出的周期会被重新导出并覆盖。
+func (s *UsageExportService) Backfill(ctx context.Context, id int64, from, to time.Time) ([]UsageExportRun, error) {
+	if !from.Before(to) {
+		return nil, ErrUsageExportInvalidRange
+	}
+	job, err := s.repo.GetJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	now := timezone.Now()
+	var starts []time.Time
+	for start := usageExportPeriodStart(job.Frequency, from); start.Before(to); start = usageExportPeriodEnd(job.Frequency, start) {
+		if usageExportPeriodEnd(job.Frequency, start).After(now) {
+			break
+		}
+		starts = append(starts, start)
+	}
+	if len(starts) == 0 {
+		return nil, ErrUsageExportInvalidRange.WithMetadata(map[string]string{"reason": "no completed period in range"})
+	}
+	if len(starts) > s.cfg.UsageExport.MaxBackfillPeriods {
+		return nil, ErrUsageExportInvalidRange.WithMetadata(map[string]string{
+			"reason":      "too many periods",
+			"max_periods": strconv.Itoa(s.cfg.UsageExport.MaxBackfillPeriods),
+		})
+	}
+	runs := make([]UsageExportRun, 0, len(starts))
+	for _, start := range starts {
+		run, err := s.enqueueJobRun(ctx, job, start, UsageExportTriggerBackfill)
+		if err != nil {
+			return runs, err
+		}
+		runs = append(runs, *run)
+	}
+	s.kickWorker()
+	return runs, nil
+}
+
+func (s *UsageExportService) enqueueJobRun(ctx context.Context, job *UsageExportJob, start time.Time, trigger string) (*UsageExportRun, error) {
+	end := usageExportPeriodEnd(job.Frequency, start)
+	jobID := job.ID
+	prefix := job.Prefix
+	if prefix == "" {
+		prefix = usageExportDefaultPrefix
+	}
+	run := &UsageExportRun{
+		JobID:       &jobID,
+		JobName:     job.Name,
+		TriggeredBy: trigger,
+		Format:      job.Format,
+		Storage:     job.Storage,
+		Filters:     job.Filters,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		ObjectKey:   joinObjectKey(prefix, "job-"+strconv.FormatInt(job.ID, 10), usageExportFileName(start, end, job.Format)),
+		Status:      UsageExportRunStatusPending,
+	}
+	if err := s.repo.CreateRun(ctx, run); err != nil {
+		return nil, fmt.Errorf("create usage export run: %w", err)
+	}
+	return run, nil
+}
+
+// EnqueueDue 为所有启用的任务登记已结束但尚未导出的周期，停机期间错过的周期也会补上。
+func (s *UsageExportService) EnqueueDue(ctx context.Context) (int, error) {
+	jobs, err := s.repo.ListJobs(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("list usage export jobs: %w", err)
+	}
+	now := timezone.Now()
+	enqueued := 0
+	for i := range jobs {
+		job := &jobs[i]
+		if !job.Enabled {
+			continue
+		}
+		next := job.NextPeriodStart
+		for count := 0; count < s.cfg.UsageExport.MaxBackfillPeriods; count++ {
+			end := usageExportPeriodEnd(job.Frequency, next)
+			if end.After(now) {
+				break
+			}
+			if _, err := s.enqueueJobRun(ctx, job, next, UsageExportTriggerSchedule); err != nil {
+				return enqueued, err
+			}
+			enqueued++
+			next = end
+		}
+		if !next.Equal(job.NextPeriodStart) {
+			if err := s.repo.AdvanceJob(ctx, job.ID, next); err != nil {
+				return enqueued, fmt.Errorf("advance usage export job %d: %w", job.ID, err)
+			}
+		}
+	}
+	return enqueued, nil
+}
+
+// ─── 用户自助导出 ───
+
+// CreateUserExport 导出用户自己在 [startDate, endDate]（含结束日，系统时区）内的用量。
+func (s *UsageExportService) CreateUserExport(ctx context.Context, userID int64, startDate, endDate, format string) (*UsageExportRun, error) {
+	if format == "" {
+		format = UsageExportFormatCSV
+	}
+	if format != UsageExportFormatCSV && format != UsageExportFormatParquet {
+		return nil, ErrUsageExportInvalidJob.WithMetadata(map[string]string{"field": "format"})
+	}
+	start, err := timezone.ParseInLocation("2006-01-02", startDate)
+	if err != nil {
+		return nil, ErrUsageExportInvalidRange
+	}
+	endDay, err := timezone.ParseInLocation("2006-01-02", endDate)
+	if err != nil || endDay.Before(start) {
+		return nil, ErrUsageExportInvalidRange
+	}
+	end := endDay.AddDate(0, 0, 1)
+	if end.After(start.AddDate(0, 0, s.cfg.UsageExport.UserMaxRangeDays)) {
+		return nil, ErrUsageExportInvalidRange.WithMetadata(map[string]string{
+			"reason":   "range too long",
+			"max_days": strconv.Itoa(s.cfg.UsageExport.UserMaxRangeDays),
+		})
+	}
+	now := timezone.Now()
+	if !start.Before(now) {
+		return nil, ErrUsageExportInvalidRange
+	}
+	if end.After(now) {
+		end = now
+	}
+
+	active, err := s.repo.CountActiveUserRuns(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("count active user exports: %w", err)
+	}
+	if active >= s.cfg.UsageExport.UserMaxPending {
+		return nil, ErrUsageExportTooManyPending
+	}
+
+	uid := userID
+	name := fmt.Sprintf("%s_%s_%d.%s", start.Format("20060102"), endDay.Format("20060102"), now.Unix(), format)
+	run := &UsageExportRun{
+		UserID:      &uid,
+		TriggeredBy: UsageExportTriggerUser,
+		Format:      format,
+		Storage:     s.cfg.UsageExport.UserStorage,
+		Filters:     UsageExportFilter{UserIDs: []int64{userID}},
+		PeriodStart: start,
+		PeriodEnd:   end,
+		ObjectKey:   joinObjectKey(usageExportDefaultPrefix, "users", strconv.FormatInt(userID, 10), name),
+		Status:      UsageExportRunStatusPending,
+	}
+	if err := s.repo.CreateRun(ctx, run); err != nil {
+		return nil, fmt.Errorf("create user usage export: %w", err)
+	}
+	s.kickWorker()
+	return run, nil
+}
+
+// ─── 查询与下载 ───
+
+func (s *UsageExportService) ListRuns(ctx context.Context, filter UsageExportRunFilter) ([]UsageExportRun, int64, error) {
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.PageSize <= 0 {
+		filter.PageSize = 20
+	}
+	if filter.PageSize > 100 {
+		filter.PageSize = 100
+	}
+	return s.repo.ListRuns(ctx, filter)
+}
+
+// GetRunForUser 只返回属于该用户的自助导出。
+func (s *UsageExportService) GetRunForUser(ctx context.Context, userID, runID int64) (*UsageExportRun, error) {
+	run, err := s.repo.GetRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	if run.UserID == nil || *run.UserID != userID {
+		return nil, ErrUsageExportRunNotFound
+	}
+	return run, nil
+}
+
+func (s *UsageExportService) GetRun(ctx context.Context, runID int64) (*UsageExportRun, error) {
+	return s.repo.GetRun(ctx, runID)
+}
+
+// DownloadURL 为已成功的导出生成限时下载链接。
+func (s *UsageExportService) DownloadURL(ctx context.Context, run *UsageExportRun) (string, error) {
+	if run.Status != UsageExportRunStatusSucceeded {
+		return "", ErrUsageExportNotReady
+	}
+	store, err := s.resolveStore(ctx, run.Storage)
+	if err != nil {
+		return "", err
+	}
+	expiry := time.Duration(s.cfg.UsageExport.DownloadURLExpiryMinutes) * time.Minute
+	url, err := store.PresignURL(ctx, run.ObjectKey, expiry)
+	if err != nil {
+		return "", fmt.Errorf("presign usage export: %w", err)
+	}
+	return url, nil
+}
+
+// ─── 执行 ───
+
+// objectStore 复用备份模块的 S3 客户端工厂：backup 直接使用备份配置，
+// image_storage 使用异步生图对象存储的（已解析）配置。
+func (s *UsageExportService) objectStore(ctx context.Context, storage string) (BackupObjectStore, error) {
+	if s.backup == nil {
+		return nil, ErrUsageExportStorageNotConfig
+	}
+	if storage == UsageExportStorageImageStorage {
+		if s.imageStorage == nil {
+			return nil, ErrUsageExportStorageNotConfig
+		}
+		cfg, err := s.imageStorage.effectiveConfig(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("load image storage config: %w", err)
+		}
+		if cfg == nil || !cfg.IsConfigured() {
+			return nil, ErrUsageExportStorageNotConfig
+		}
+		return s.backup.storeFactory(ctx, &BackupS3Config{
+			Endpoint:        cfg.Endpoint,
+			Region:          cfg.Region,
+			Bucket:          cfg.Bucket,
+			AccessKeyID:     cfg.AccessKeyID,
+			SecretAccessKey: cfg.SecretAccessKey,
+			ForcePathStyle:  cfg.ForcePathStyle,
+		})
+	}
+	cfg, err := s.backup.loadS3Config(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load backup s3 config: %w", err)
+	}
+	if cfg == nil || !cfg.IsConfigured() {
+		return nil, ErrUsageExportStorageNotConfig
+	}
+	return s.backup.getOrCreateStore(ctx, cfg)
+}
+
+// ProcessPending 依次抢占并执行 pending 导出，直到没有待处理记录。
+func (s *UsageExportService) ProcessPending(ctx context.Context) {
+	for ctx.Err() == nil {
+		runs, err := s.repo.ListPendingRuns(ctx, usageExportPendingBatch)
+		if err != nil {
+			slog.Error("[UsageExport] list pending runs failed", "error", err)
+			return
+		}
+		if len(runs) == 0 {
+			return
+		}
+		for i := range runs {
+			if ctx.Err() != nil {
+				return
+			}
+			run := &runs[i]
+			claimed, err := s.repo.ClaimRun(ctx, run.ID, time.Now())
+			if err != nil {
+				slog.Error("[UsageExport] claim run failed", "run_id", run.ID, "error", err)
+				return
+			}
+			if !claimed {
+				continue
+			}
+			s.executeRun(ctx, run)
+		}
+	}
+}
+
+func (s *UsageExportService) executeRun(parent context.Context, run *UsageExportRun) {
+	ctx, cancel := context.WithTimeout(parent, usageExportRunTimeout)
+	defer cancel()
+
+	err := s.writeExport(ctx, run)
+	now := time.Now()
+	run.FinishedAt = &now
+	if err != nil {
+		msg := err.Error()
+		if len(msg) > usageExportErrorMaxLen {
+			msg = msg[:usageExportErrorMaxLen]
+		}
+		run.Status = UsageExportRunStatusFailed
+		run.ErrorMessage = msg
+		slog.Error("[UsageExport] export failed", "run_id", run.ID, "object_key", run.ObjectKey, "error", err)
+	} else {
+		run.Status = UsageExportRunStatusSucceeded
+		run.ErrorMessage = ""
+		slog.Info("[UsageExport] export finished", "run_id", run.ID, "object_key", run.ObjectKey, "rows", run.RowCount, "bytes", run.SizeBytes)
+	}
+	// 结果落库不受导出超时影响
+	finishCtx, finishCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer finishCancel()
+	if err := s.repo.FinishRun(finishCtx, run); err != nil {
+		slog.Error("[UsageExport] record run result failed", "run_id", run.ID, "error", err)
+	}
+}
+
+// writeExport 先写临时文件再上传：边写边算 SHA-256，上传的内容与校验和一致。
+func (s *UsageExportService) writeExport(ctx context.Context, run *UsageExportRun) error {
+	store, err := s.resolveStore(ctx, run.Storage)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp("", "usage-export-*."+run.Format)
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	defer func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+
+	hash := sha256.New()
+	enc, err := newUsageExportEncoder(run.Format, io.MultiWriter(tmp, hash))
+	if err != nil {
+		return fmt.Errorf("init encoder: %w", err)
+	}
+	var rows int64
+	err = s.repo.StreamUsage(ctx, UsageExportQuery{Filter: run.Filters, Start: run.PeriodStart, End: run.PeriodEnd}, func(row *UsageExportRow) error {
+		rows++
+		return enc.Write(row)
+	})
+	if err != nil {
+		return fmt.Errorf("read usage: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("finish file: %w", err)
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("rewind temp file: %w", err)
+	}
+	size, err := store.Upload(ctx, run.ObjectKey, tmp, usageExportContentType(run.Format))
+	if err != nil {
+		return fmt.Errorf("upload: %w", err)
+	}
+	run.RowCount = rows
+	run.SizeBytes = size
+	run.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	return nil
+}
+
+// ─── 生命周期 ───
+
+func (s *UsageExportService) kickWorker() {
+	select {
+	case s.kick <- struct{}{}:
+	default:
+	}
+}
+
+// Start 启动导出 worker，并在开启调度时按 usage_export.schedule 登记到期周期。重复调用幂等。
+func (s *UsageExportService) Start() {
+	if s == nil || s.repo == nil || s.cfg == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started || s.stopped {
+		return
+	}
+	s.started = true
+
+	recoverCtx, recoverCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	if n, err := s.repo.FailStaleRuns(recoverCtx, time.Now().Add(-usageExportRunTimeout)); err != nil {
+		slog.Warn("[UsageExport] recover stale runs failed", "error", err)
+	} else if n > 0 {
+		slog.Info("[UsageExport] marked interrupted runs as failed", "count", n)
+	}
+	recoverCancel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.wg.Add(1)
+	go s.worker(ctx)
+	s.kickWorker()
+
+	if !s.cfg.UsageExport.SchedulerEnabled {
+		return
+	}
+	loc := time.Local
+	if tz := strings.TrimSpace(s.cfg.Timezone); tz != "" {
+		if parsed, err := time.LoadLocation(tz); err == nil && parsed != nil {
+			loc = parsed
+		}
+	}
+	schedule := strings.TrimSpace(s.cfg.UsageExport.Schedule)
+	c := cron.New(cron.WithParser(usageExportCronParser), cron.WithLocation(loc))
+	if _, err := c.AddFunc(schedule, s.runScheduled); err != nil {
+		slog.Error("[UsageExport] invalid schedule, periodic exports disabled", "schedule", schedule, "error", err)
+		return
+	}
+	c.Start()
+	s.cron = c
+}
+
+// Stop 停止调度与 worker；正在执行的导出会被取消并在下次启动时标记为失败。幂等。
+func (s *UsageExportService) Stop() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopped = true
+	if s.cron != nil {
+		ctx := s.cron.Stop()
+		select {
+		case <-ctx.Done():
+		case <-time.After(usageExportStopTimeout):
+			slog.Warn("[UsageExport] cron stop timed out")
+		}
+		s.cron = nil
+	}
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+		done := make(chan struct{})
+		go func() {
+			s.wg.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(usageExportStopTimeout):
+			slog.Warn("[UsageExport] worker stop timed out")
+		}
+	}
+}
+
+// worker 处理被唤醒的导出，并定期轮询以接手其他实例登记的记录。
+func (s *UsageExportService) worker(ctx context.Context) {
+	defer s.wg.Done()
+	ticker := time.NewTicker(usageExportPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.kick:
+		case <-ticker.C:
+		}
+		s.ProcessPending(ctx)
+	}
+}
+
+func (s *UsageExportService) runScheduled() {
+	lockCtx, lockCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	release, ok := tryAcquireSingletonLeaderLock(lockCtx, s.lockCache, s.db, usageExportLeaderLockKey, s.instanceID, usageExportLeaderLockTTL)
+	lockCancel()
+	if !ok {
+		return
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	n, err := s.EnqueueDue(ctx)
+	if err != nil {
+		slog.Error("[UsageExport] enqueue due exports failed", "error", err)
+	}
+	if n > 0 {
+		s.kickWorker()
+	}
+}
@@ -0,0 +1,324 @@
+//go:build unit
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/require"
+)
+
+type usageExportRepoStub struct {
+	jobs     map[int64]*UsageExportJob
+	runs     []*UsageExportRun
+	active   int
+	rows     []UsageExportRow
+	advanced map[int64]time.Time
+	finished []*UsageExportRun
+}
+
+func newUsageExportRepoStub() *usageExportRepoStub {
+	return &usageExportRepoStub{jobs: map[int64]*UsageExportJob{}, advanced: map[int64]time.Time{}}
+}
+
+func (r *usageExportRepoStub) CreateJob(_ context.Context, job *UsageExportJob) error {
+	job.ID = int64(len(r.jobs) + 1)
+	r.jobs[job.ID] = job
+	return nil
+}
+
+func (r *usageExportRepoStub) UpdateJob(_ context.Context, job *UsageExportJob) error {
+	r.jobs[job.ID] = job
+	return nil
+}
+
+func (r *usageExportRepoStub) DeleteJob(_ context.Context, id int64) error {
+	delete(r.jobs, id)
+	return nil
+}
+
+func (r *usageExportRepoStub) GetJob(_ context.Context, id int64) (*UsageExportJob, error) {
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil, ErrUsageExportJobNotFound
+	}
+	cp := *job
+	return &cp, nil
+}
+
+func (r *usageExportRepoStub) ListJobs(context.Context) ([]UsageExportJob, error) {
+	out := make([]UsageExportJob, 0, len(r.jobs))
+	for _, job := range r.jobs {
+		out = append(out, *job)
+	}
+	return out, nil
+}
+
+func (r *usageExportRepoStub) AdvanceJob(_ context.Context, id int64, next time.Time) error {
+	r.advanced[id] = next
+	return nil
+}
+
+func (r *usageExportRepoStub) CreateRun(_ context.Context, run *UsageExportRun) error {
+	run.ID = int64(len(r.runs) + 1)
+	run.Status = UsageExportRunStatusPending
+	r.runs = append(r.runs, run)
+	return nil
+}
+
+func (r *usageExportRepoStub) GetRun(_ context.Context, id int64) (*UsageExportRun, error) {
+	for _, run := range r.runs {
+		if run.ID == id {
+			return run, nil
+		}
+	}
+	return nil, ErrUsageExportRunNotFound
+}
+
+func (r *usageExportRepoStub) ListRuns(context.Context, UsageExportRunFilter) ([]UsageExportRun, int64, error) {
+	return nil, 0, nil
+}
+
+func (r *usageExportRepoStub) ListPendingRuns(_ context.Context, limit int) ([]UsageExportRun, error) {
+	var out []UsageExportRun
+	for _, run := range r.runs {
+		if run.Status == UsageExportRunStatusPending && len(out) < limit {
+			out = append(out, *run)
+		}
+	}
+	return out, nil
+}
+
+func (r *usageExportRepoStub) CountActiveUserRuns(context.Context, int64) (int, error) {
+	return r.active, nil
+}
+
+func (r *usageExportRepoStub) ClaimRun(_ context.Context, id int64, startedAt time.Time) (bool, error) {
+	for _, run := range r.runs {
+		if run.ID == id && run.Status == UsageExportRunStatusPending {
+			run.Status = UsageExportRunStatusRunning
+			run.StartedAt = &startedAt
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *usageExportRepoStub) FinishRun(_ context.Context, run *UsageExportRun) error {
+	for _, existing := range r.runs {
+		if existing.ID == run.ID {
+			*existing = *run
+		}
+	}
+	r.finished = append(r.finished, run)
+	return nil
+}
+
+func (r *usageExportRepoStub) FailStaleRuns(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (r *usageExportRepoStub) StreamUsage(_ context.Context, _ UsageExportQuery, fn func(row *UsageExportRow) error) error {
+	for i := range r.rows {
+		if err := fn(&r.rows[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newUsageExportTestService(repo *usageExportRepoStub) *UsageExportService {
+	cfg := &config.Config{UsageExport: config.UsageExportConfig{
+		MaxBackfillPeriods:       10,
+		UserStorage:              UsageExportStorageBackup,
+		UserMaxRangeDays:         31,
+		UserMaxPending:           2,
+		DownloadURLExpiryMinutes: 60,
+	}}
+	return NewUsageExportService(repo, nil, nil, cfg)
+}
+
+func TestUsageExportPeriods(t *testing.T) {
+	_ = timezone.Init("UTC")
+	now := time.Date(2026, 3, 4, 15, 30, 0, 0, time.UTC) // Wednesday
+
+	require.Equal(t, time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC), usageExportLastCompletePeriod(UsageExportFrequencyDaily, now))
+	require.Equal(t, time.Date(2026, 2, 23, 0, 0, 0, 0, time.UTC), usageExportLastCompletePeriod(UsageExportFrequencyWeekly, now))
+	require.Equal(t, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), usageExportLastCompletePeriod(UsageExportFrequencyMonthly, now))
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.Equal(t, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), usageExportPeriodEnd(UsageExportFrequencyMonthly, start))
+	require.Equal(t, "usage-exports/job-7/20260101_20260201.csv", joinObjectKey("/usage-exports/", "job-7", usageExportFileName(start, usageExportPeriodEnd(UsageExportFrequencyMonthly, start), UsageExportFormatCSV)))
+}
+
+func TestUsageExportEnqueueDueCatchesUpMissedPeriods(t *testing.T) {
+	_ = timezone.Init("UTC")
+	repo := newUsageExportRepoStub()
+	today := timezone.StartOfDay(timezone.Now())
+	repo.jobs[1] = &UsageExportJob{ID: 1, Name: "daily", Frequency: UsageExportFrequencyDaily, Format: UsageExportFormatCSV,
+		Storage: UsageExportStorageBackup, Enabled: true, NextPeriodStart: today.AddDate(0, 0, -3)}
+	repo.jobs[2] = &UsageExportJob{ID: 2, Name: "disabled", Frequency: UsageExportFrequencyDaily, Format: UsageExportFormatCSV,
+		Storage: UsageExportStorageBackup, Enabled: false, NextPeriodStart: today.AddDate(0, 0, -3)}
+	svc := newUsageExportTestService(repo)
+
+	n, err := svc.EnqueueDue(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+	require.Len(t, repo.runs, 3)
+	for i, run := range repo.runs {
+		require.Equal(t, today.AddDate(0, 0, i-3), run.PeriodStart)
+		require.Equal(t, UsageExportTriggerSchedule, run.TriggeredBy)
+		require.Equal(t, int64(1), *run.JobID)
+	}
+	require.Equal(t, today, repo.advanced[1])
+	_, ok := repo.advanced[2]
+	require.False(t, ok)
+
+	// 已追平后不再重复登记
+	repo.jobs[1].NextPeriodStart = today
+	n, err = svc.EnqueueDue(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, n)
+}
+
+func TestUsageExportBackfill(t *testing.T) {
+	_ = timezone.Init("UTC")
+	repo := newUsageExportRepoStub()
+	today := timezone.StartOfDay(timezone.Now())
+	repo.jobs[1] = &UsageExportJob{ID: 1, Name: "daily", Frequency: UsageExportFrequencyDaily, Format: UsageExportFormatParquet,
+		Storage: UsageExportStorageBackup, Enabled: true, NextPeriodStart: today}
+	svc := newUsageExportTestService(repo)
+
+	// 未结束的今天不会被补跑
+	runs, err := svc.Backfill(context.Background(), 1, today.AddDate(0, 0, -2), today.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	require.Equal(t, UsageExportTriggerBackfill, runs[0].TriggeredBy)
+	require.True(t, strings.HasSuffix(runs[0].ObjectKey, ".parquet"))
+
+	_, err = svc.Backfill(context.Background(), 1, today.AddDate(0, 0, -30), today)
+	require.ErrorIs(t, err, ErrUsageExportInvalidRange)
+
+	_, err = svc.Backfill(context.Background(), 1, today, today)
+	require.ErrorIs(t, err, ErrUsageExportInvalidRange)
+}
+
+func TestUsageExportCreateUserExport(t *testing.T) {
+	_ = timezone.Init("UTC")
+	repo := newUsageExportRepoStub()
+	svc := newUsageExportTestService(repo)
+	ctx := context.Background()
+	today := timezone.StartOfDay(timezone.Now())
+	day := func(offset int) string { return today.AddDate(0, 0, offset).Format("2006-01-02") }
+
+	run, err := svc.CreateUserExport(ctx, 42, day(-5), day(0), "")
+	require.NoError(t, err)
+	require.Equal(t, UsageExportFormatCSV, run.Format)
+	require.Equal(t, []int64{42}, run.Filters.UserIDs)
+	require.Equal(t, today.AddDate(0, 0, -5), run.PeriodStart)
+	require.False(t, run.PeriodEnd.After(timezone.Now()))
+	require.True(t, strings.HasPrefix(run.ObjectKey, "usage-exports/users/42/"))
+
+	_, err = svc.CreateUserExport(ctx, 42, day(-40), day(-1), UsageExportFormatCSV)
+	require.ErrorIs(t, err, ErrUsageExportInvalidRange)
+	_, err = svc.CreateUserExport(ctx, 42, day(-1), day(-2), UsageExportFormatCSV)
+	require.ErrorIs(t, err, ErrUsageExportInvalidRange)
+	_, err = svc.CreateUserExport(ctx, 42, "not-a-date", day(-2), UsageExportFormatCSV)
+	require.ErrorIs(t, err, ErrUsageExportInvalidRange)
+
+	repo.active = 2
+	_, err = svc.CreateUserExport(ctx, 42, day(-2), day(-1), UsageExportFormatCSV)
+	require.ErrorIs(t, err, ErrUsageExportTooManyPending)
+}
+
+func TestUsageExportProcessPendingUploadsCSV(t *testing.T) {
+	_ = timezone.Init("UTC")
+	repo := newUsageExportRepoStub()
+	repo.rows = []UsageExportRow{
+		{ID: 1, CreatedAt: time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC), UserID: 42, UserEmail: "a@example.com", Model: "claude-sonnet-4", InputTokens: 10, OutputTokens: 20, TotalCost: 0.5, ActualCost: 0.25, RateMultiplier: 0.5},
+		{ID: 2, CreatedAt: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC), UserID: 42, UserEmail: "a@example.com", Model: "gpt-5", Stream: true},
+	}
+	store := newMockObjectStore()
+	svc := newUsageExportTestService(repo)
+	svc.resolveStore = func(context.Context, string) (BackupObjectStore, error) { return store, nil }
+
+	jobID := int64(1)
+	require.NoError(t, repo.CreateRun(context.Background(), &UsageExportRun{
+		JobID:       &jobID,
+		Format:      UsageExportFormatCSV,
+		Storage:     UsageExportStorageBackup,
+		PeriodStart: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:   time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC),
+		ObjectKey:   "usage-exports/job-1/20260301_20260302.csv",
+	}))
+
+	svc.ProcessPending(context.Background())
+
+	run := repo.runs[0]
+	require.Equal(t, UsageExportRunStatusSucceeded, run.Status, run.ErrorMessage)
+	require.Equal(t, int64(2), run.RowCount)
+	data := store.objects[run.ObjectKey]
+	require.NotEmpty(t, data)
+	require.Equal(t, int64(len(data)), run.SizeBytes)
+	sum := sha256.Sum256(data)
+	require.Equal(t, hex.EncodeToString(sum[:]), run.SHA256)
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 3)
+	require.True(t, strings.HasPrefix(lines[0], "id,created_at,"))
+	require.Contains(t, lines[1], "claude-sonnet-4")
+	require.Contains(t, lines[2], "gpt-5")
+
+	// 存储不可用时记录失败原因
+	require.NoError(t, repo.CreateRun(context.Background(), &UsageExportRun{
+		JobID: &jobID, Format: UsageExportFormatCSV, Storage: UsageExportStorageBackup, ObjectKey: "x.csv",
+	}))
+	svc.resolveStore = func(context.Context, string) (BackupObjectStore, error) { return nil, ErrUsageExportStorageNotConfig }
+	svc.ProcessPending(context.Background())
+	require.Equal(t, UsageExportRunStatusFailed, repo.runs[1].Status)
+	require.NotEmpty(t, repo.runs[1].ErrorMessage)
+}
+
+func TestUsageExportParquetEncoderMatchesCSVColumns(t *testing.T) {
+	rows := []UsageExportRow{
+		{ID: 1, CreatedAt: time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC), UserID: 42, UserEmail: "a@example.com", Model: "claude-sonnet-4", InputTokens: 10, TotalCost: 0.5, ActualCost: 0.25},
+		{ID: 2, CreatedAt: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC), UserID: 42, Model: "gpt-5", Stream: true, DurationMs: 1200},
+	}
+	var out bytes.Buffer
+	enc, err := newUsageExportEncoder(UsageExportFormatParquet, &out)
+	require.NoError(t, err)
+	for i := range rows {
+		require.NoError(t, enc.Write(&rows[i]))
+	}
+	require.NoError(t, enc.Close())
+
+	file, err := parquet.OpenFile(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	require.NoError(t, err)
+	require.Equal(t, int64(2), file.NumRows())
+	fields := file.Schema().Fields()
+	require.Len(t, fields, len(usageExportColumns))
+	for i, c := range usageExportColumns {
+		require.Equal(t, c.name, fields[i].Name(), "parquet column %d", i)
+	}
+	require.Contains(t, fields[1].Type().LogicalType().String(), "TIMESTAMP")
+	require.Contains(t, fields[1].Type().LogicalType().String(), "MILLIS")
+	require.Equal(t, "STRING", fields[3].Type().LogicalType().String())
+
+	got, err := parquet.Read[UsageExportRow](bytes.NewReader(out.Bytes()), int64(out.Len()))
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	require.Equal(t, "claude-sonnet-4", got[0].Model)
+	require.True(t, got[0].CreatedAt.Equal(rows[0].CreatedAt))
+	require.InDelta(t, 0.25, got[0].ActualCost, 1e-12)
+	require.True(t, got[1].Stream)
+	require.Equal(t, int64(1200), got[1].DurationMs)
+}
@@ -124,7 +124,7 @@ type UsageLog struct {
 	ChannelID *int64
 	// ModelMappingChain 模型映射链，如 "a→b→c"
 	ModelMappingChain *string
-	// BillingTier 计费层级标签（per_request/image 模式）
+	// BillingTier 计费层级标签（per_request/image 模式，或分组月度消费阶梯 volume_tN）
 	BillingTier *string
 	// BillingMode 计费模式：token/image
 	BillingMode *string
@@ -167,6 +167,8 @@ type UsageLog struct {
 	AccountRateMultiplier *float64
 	// AccountStatsCost 账号统计定价预计算费用（nil = 使用默认公式 total_cost × account_rate_multiplier）
 	AccountStatsCost *float64
+	// ResponseCacheMultiplier 响应缓存命中计费倍率（nil = 非缓存命中记录）
+	ResponseCacheMultiplier *float64
 
 	BillingType  int8
 	RequestType  RequestType
@@ -55,6 +55,10 @@ type User struct {
 	// 且该 (用户, 分组) 无 rpm_override 时作为全局兜底生效，计数键 rpm:u:{userID}:{min}。
 	RPMLimit int
 
+	// CreditLimit 后付费授信额度（0 = 纯预付费）。余额可透支到 -CreditLimit，
+	// 按月生成账单，逾期未结清时挂起 API Key（见 CreditStatementService）。
+	CreditLimit float64
+
 	// UserGroupRPMOverride 来自 auth cache snapshot 的 (user, group) RPM 覆盖值。
 	// nil = 该 API Key 对应的 (user, group) 无 override；非 nil 时 checkRPM 直接使用，
 	// 避免每请求查 DB。字段不持久化到数据库。
@@ -72,6 +76,17 @@ func (u *User) IsActive() bool {
 	return u.Status == StatusActive
 }
 
+// SpendableBalance 返回余额模式下可用于准入判断的额度：余额加上后付费授信额度。
+func (u *User) SpendableBalance() float64 {
+	if u == nil {
+		return 0
+	}
+	if u.CreditLimit > 0 {
+		return u.Balance + u.CreditLimit
+	}
+	return u.Balance
+}
+
 // CanBindGroup checks whether a user can bind to a given group.
 // For standard groups:
 // - Public groups (non-exclusive): all users can bind
@@ -2,12 +2,13 @@ package service
 
 import "context"
 
-// UserRPMCache 用户/分组级 RPM 计数器接口。
+// UserRPMCache 用户/分组/API Key 级 RPM 计数器接口。
 //
 // 与账号级 RPMCache 的区别：
 //   - RPMCache    —— 按外部 AI provider 账号聚合（key: rpm:{accountID}:{min}）。
 //   - UserRPMCache —— 按用户或 (用户, 分组) 聚合，杜绝"同一用户创建多个 API Key 绕过 RPM"的路径。
 //     key 形如 rpm:ug:{userID}:{groupID}:{min} 或 rpm:u:{userID}:{min}。
+//     单 Key 限额另以 rpm:k:{apiKeyID}:{min} 计数，避免同一用户的某个 Key 挤占其他 Key 的额度。
 type UserRPMCache interface {
 	// IncrementUserGroupRPM 原子递增 (user, group) 级分钟计数并返回最新值。
 	// 用于分组 rpm_limit 与 user-group rpm_override 两种命中分支。
@@ -22,4 +23,10 @@ type UserRPMCache interface {
 
 	// GetUserRPM 获取用户当前分钟已用 RPM（只读，不递增）。
 	GetUserRPM(ctx context.Context, userID int64) (count int, err error)
+
+	// IncrementAPIKeyRPM 原子递增 API Key 级分钟计数并返回最新值，用于 api_keys.rpm_limit。
+	IncrementAPIKeyRPM(ctx context.Context, apiKeyID int64) (count int, err error)
+
+	// GetAPIKeyRPMBatch 批量获取 API Key 当前分钟已用 RPM（只读），缺失的 Key 计为 0。
+	GetAPIKeyRPMBatch(ctx context.Context, apiKeyIDs []int64) (map[int64]int, error)
 }
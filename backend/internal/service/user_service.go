@@ -104,6 +104,7 @@ type UserUpdateFields struct {
 	Status       bool
 	Concurrency  bool
 	RPMLimit     bool
+	CreditLimit  bool
 	SignupSource bool
 	LastLoginAt  bool
 	LastActiveAt bool
@@ -794,7 +795,7 @@ func (s *UserService) canUnbindProvider(provider string, user *User, records []U
 		return true
 	}
 
-	for _, candidate := range []string{"linuxdo", "oidc", "wechat", "dingtalk"} {
+	for _, candidate := range []string{"linuxdo", "oidc", "wechat", "dingtalk", "saml"} {
 		if candidate == provider {
 			continue
 		}
@@ -1150,7 +1151,8 @@ func (s *UserService) List(ctx context.Context, params pagination.PaginationPara
 
 // UpdateBalance 更新用户余额（管理员功能）
 func (s *UserService) UpdateBalance(ctx context.Context, userID int64, amount float64) error {
-	if err := s.userRepo.UpdateBalance(ctx, userID, amount); err != nil {
+	balanceCtx := withDefaultBalanceLedgerTag(ctx, BalanceLedgerTag{Type: BalanceLedgerTypeAdminAdjustment})
+	if err := s.userRepo.UpdateBalance(balanceCtx, userID, amount); err != nil {
 		return fmt.Errorf("update balance: %w", err)
 	}
 	if s.authCacheInvalidator != nil {
@@ -100,6 +100,42 @@ func ProvideBatchImageModelPricingResolver(resolver *ModelPricingResolver) *Batc
 	return &BatchImageModelPricingResolver{Resolver: resolver}
 }
 
+// ProvideMessageBatchService 创建并启动 Message Batches 轮询结算服务。
+func ProvideMessageBatchService(
+	repo MessageBatchRepository,
+	accountRepo AccountRepository,
+	groupRepo GroupRepository,
+	userGroupRateRepo UserGroupRateRepository,
+	billingRepo UsageBillingRepository,
+	usageLogRepo UsageLogRepository,
+	billingService *BillingService,
+	httpUpstream HTTPUpstream,
+	authCache APIKeyAuthCacheInvalidator,
+	cfg *config.Config,
+) *MessageBatchService {
+	svc := NewMessageBatchService(repo, accountRepo, groupRepo, userGroupRateRepo, billingRepo, usageLogRepo, billingService, httpUpstream, authCache, cfg)
+	svc.Start()
+	return svc
+}
+
+// ProvideOpenAIBatchService 创建并启动 OpenAI Batch API 轮询结算服务。
+func ProvideOpenAIBatchService(
+	repo OpenAIBatchRepository,
+	accountRepo AccountRepository,
+	groupRepo GroupRepository,
+	userGroupRateRepo UserGroupRateRepository,
+	billingRepo UsageBillingRepository,
+	usageLogRepo UsageLogRepository,
+	billingService *BillingService,
+	httpUpstream HTTPUpstream,
+	authCache APIKeyAuthCacheInvalidator,
+	cfg *config.Config,
+) *OpenAIBatchService {
+	svc := NewOpenAIBatchService(repo, accountRepo, groupRepo, userGroupRateRepo, billingRepo, usageLogRepo, billingService, httpUpstream, authCache, cfg)
+	svc.Start()
+	return svc
+}
+
 func ProvideBatchImageCleanupService(repo BatchImageRepository, accountRepo AccountRepository, cfg *config.Config) *BatchImageCleanupService {
 	svc := NewBatchImageCleanupService(repo, accountRepo, cfg)
 	svc.Start()
@@ -315,6 +351,27 @@ func ProvideDashboardAggregationService(repo DashboardAggregationRepository, tim
 	return svc
 }
 
+// ProvideCompositeRouteExperimentService 创建并启动流量拆分实验的分流记录写入。
+func ProvideCompositeRouteExperimentService(repo CompositeRouteExperimentRepository, routeRepo CompositeModelRouteRepository) *CompositeRouteExperimentService {
+	svc := NewCompositeRouteExperimentService(repo, routeRepo)
+	svc.Start()
+	return svc
+}
+
+// ProvideTrafficMirrorService 创建并启动流量镜像对比记录写入。
+func ProvideTrafficMirrorService(repo TrafficMirrorRepository, groupRepo GroupRepository) *TrafficMirrorService {
+	svc := NewTrafficMirrorService(repo, groupRepo)
+	svc.Start()
+	return svc
+}
+
+// ProvideCompositeRouteResolver 创建 composite 路由解析器并挂上实验分流记录。
+func ProvideCompositeRouteResolver(repo CompositeModelRouteRepository, experiments *CompositeRouteExperimentService) *CompositeRouteResolver {
+	resolver := NewCompositeRouteResolver(repo)
+	resolver.SetExperimentService(experiments)
+	return resolver
+}
+
 // ProvideUsageCleanupService 创建并启动使用记录清理任务服务
 func ProvideUsageCleanupService(repo UsageCleanupRepository, timingWheel *TimingWheelService, dashboardAgg *DashboardAggregationService, cfg *config.Config) *UsageCleanupService {
 	svc := NewUsageCleanupService(repo, timingWheel, dashboardAgg, cfg)
@@ -737,10 +794,32 @@ func ProvideAPIKeyService(
 	cfg *config.Config,
 	billingCacheService *BillingCacheService,
 	concurrencyService *ConcurrencyService,
+	rpmCache UserRPMCache,
 ) *APIKeyService {
 	svc := NewAPIKeyService(apiKeyRepo, userRepo, groupRepo, userSubRepo, userGroupRateRepo, cache, cfg)
 	svc.SetRateLimitCacheInvalidator(billingCacheService)
 	svc.SetConcurrencyService(concurrencyService)
+	svc.SetUserRPMCache(rpmCache)
+	return svc
+}
+
+// ProvideOrganizationService wires OrganizationService and registers it as the
+// organization wallet / subscription source of billing, subscription and auth checks.
+func ProvideOrganizationService(
+	repo OrganizationRepository,
+	userRepo UserRepository,
+	groupRepo GroupRepository,
+	usageRepo UsageLogRepository,
+	subscriptionService *SubscriptionService,
+	billingCacheService *BillingCacheService,
+	apiKeyService *APIKeyService,
+	authCacheInvalidator APIKeyAuthCacheInvalidator,
+) *OrganizationService {
+	svc := NewOrganizationService(repo, userRepo, groupRepo, usageRepo, subscriptionService, billingCacheService, authCacheInvalidator)
+	billingCacheService.SetOrganizationWalletReader(svc)
+	billingCacheService.SetOrganizationWalletCharger(svc)
+	subscriptionService.SetOrganizationSubscriptionResolver(svc)
+	apiKeyService.SetOrganizationWalletReader(svc)
 	return svc
 }
 
@@ -754,7 +833,9 @@ var ProviderSet = wire.NewSet(
 	ProvideAPIKeyAuthCacheInvalidator,
 	ProvideAuthCacheInvalidationWorker,
 	NewGroupService,
-	NewCompositeRouteResolver,
+	ProvideCompositeRouteResolver,
+	ProvideCompositeRouteExperimentService,
+	ProvideTrafficMirrorService,
 	NewAccountService,
 	NewProxyService,
 	NewRedeemService,
@@ -765,6 +846,7 @@ var ProviderSet = wire.NewSet(
 	NewBillingService,
 	ProvideBillingCacheService,
 	NewAnnouncementService,
+	ProvideOrganizationService,
 	NewAdminService,
 	NewGatewayService,
 	NewOpenAIGatewayService,
@@ -775,6 +857,9 @@ var ProviderSet = wire.NewSet(
 	NewBatchImageDownloadService,
 	ProvideBatchImageCleanupService,
 	ProvideBatchImageWorkerRuntime,
+	ProvideMessageBatchService,
+	ProvideOpenAIBatchService,
+	NewResponseCacheService,
 	wire.Bind(new(AccountRuntimeBlocker), new(*OpenAIGatewayService)),
 	NewOAuthService,
 	ProvideOpenAIOAuthService,
@@ -823,6 +908,7 @@ var ProviderSet = wire.NewSet(
 	ProvideConcurrencyService,
 	ProvideUserMessageQueueService,
 	NewUsageRecordWorkerPool,
+	NewPrometheusCollector,
 	ProvideSchedulerSnapshotService,
 	NewIdentityService,
 	NewCRSSyncService,
@@ -858,6 +944,12 @@ var ProviderSet = wire.NewSet(
 	ProvidePaymentConfigService,
 	ProvidePaymentService,
 	ProvidePaymentOrderExpiryService,
+	ProvideSubscriptionAutoRenewalService,
+	ProvideBalanceLedgerService,
+	ProvideCreditStatementService,
+	ProvideSpendAnomalyService,
+	NewGroupHedgeStatsService,
+	ProvideUsageExportService,
 	ProvideBalanceNotifyService,
 	ProvideChannelMonitorService,
 	ProvideChannelMonitorRunner,
@@ -887,10 +979,16 @@ func ProvideBalanceNotifyService(emailService *EmailService, settingRepo Setting
 	return svc
 }
 
-// ProvidePaymentService creates PaymentService and attaches notification email delivery.
-func ProvidePaymentService(entClient *dbent.Client, registry *payment.Registry, loadBalancer payment.LoadBalancer, redeemService *RedeemService, subscriptionSvc *SubscriptionService, configService *PaymentConfigService, userRepo UserRepository, groupRepo GroupRepository, affiliateService *AffiliateService, notificationEmailService *NotificationEmailService) *PaymentService {
+// ProvidePaymentService creates PaymentService and attaches notification email delivery,
+// the auto-renewal agreement store and the invoice store and PDF font.
+func ProvidePaymentService(entClient *dbent.Client, registry *payment.Registry, loadBalancer payment.LoadBalancer, redeemService *RedeemService, subscriptionSvc *SubscriptionService, configService *PaymentConfigService, userRepo UserRepository, groupRepo GroupRepository, affiliateService *AffiliateService, notificationEmailService *NotificationEmailService, autoRenewalRepo SubscriptionAutoRenewalRepository, invoiceRepo PaymentInvoiceRepository, cfg *config.Config) *PaymentService {
 	svc := NewPaymentService(entClient, registry, loadBalancer, redeemService, subscriptionSvc, configService, userRepo, groupRepo, affiliateService)
 	svc.SetNotificationEmailService(notificationEmailService)
+	svc.SetSubscriptionAutoRenewalRepository(autoRenewalRepo)
+	svc.SetPaymentInvoiceRepository(invoiceRepo)
+	if cfg != nil {
+		svc.SetInvoiceFontPath(cfg.PaymentInvoice.CJKFontPath)
+	}
 	return svc
 }
 
@@ -902,6 +1000,48 @@ func ProvidePaymentOrderExpiryService(paymentSvc *PaymentService, lockCache Lead
 	return svc
 }
 
+// ProvideBalanceLedgerService creates BalanceLedgerService and starts scheduled reconciliation.
+func ProvideBalanceLedgerService(repo BalanceLedgerRepository, cfg *config.Config, lockCache LeaderLockCache, db *sql.DB) *BalanceLedgerService {
+	svc := NewBalanceLedgerService(repo, cfg)
+	svc.SetLeaderLock(lockCache, db)
+	svc.Start()
+	return svc
+}
+
+// ProvideCreditStatementService creates CreditStatementService, starts the monthly
+// statement job and lets APIKeyService reject keys while a statement is overdue.
+func ProvideCreditStatementService(repo CreditStatementRepository, userRepo UserRepository, notificationEmailService *NotificationEmailService, apiKeyService *APIKeyService, cfg *config.Config, lockCache LeaderLockCache, db *sql.DB) *CreditStatementService {
+	svc := NewCreditStatementService(repo, userRepo, notificationEmailService, apiKeyService, cfg)
+	svc.SetLeaderLock(lockCache, db)
+	apiKeyService.SetCreditStatementGuard(svc)
+	svc.Start()
+	return svc
+}
+
+// ProvideSpendAnomalyService creates SpendAnomalyService and starts scheduled detection.
+func ProvideSpendAnomalyService(repo SpendAnomalyRepository, userRepo UserRepository, notificationEmailService *NotificationEmailService, authCacheInvalidator APIKeyAuthCacheInvalidator, cfg *config.Config, lockCache LeaderLockCache, db *sql.DB) *SpendAnomalyService {
+	svc := NewSpendAnomalyService(repo, userRepo, notificationEmailService, authCacheInvalidator, cfg)
+	svc.SetLeaderLock(lockCache, db)
+	svc.Start()
+	return svc
+}
+
+// ProvideUsageExportService creates UsageExportService and starts the export worker and scheduler.
+func ProvideUsageExportService(repo UsageExportRepository, backupService *BackupService, imageStorage *ImageStorageSettingService, cfg *config.Config, lockCache LeaderLockCache, db *sql.DB) *UsageExportService {
+	svc := NewUsageExportService(repo, backupService, imageStorage, cfg)
+	svc.SetLeaderLock(lockCache, db)
+	svc.Start()
+	return svc
+}
+
+// ProvideSubscriptionAutoRenewalService creates and starts SubscriptionAutoRenewalService.
+func ProvideSubscriptionAutoRenewalService(paymentSvc *PaymentService, lockCache LeaderLockCache, db *sql.DB) *SubscriptionAutoRenewalService {
+	svc := NewSubscriptionAutoRenewalService(paymentSvc, 5*time.Minute)
+	svc.SetLeaderLock(lockCache, db)
+	svc.Start()
+	return svc
+}
+
 // ProvideChannelMonitorService 创建渠道监控服务（CRUD + RunCheck + 用户视图聚合）。
 // 加密器复用 wire 中已注入的 SecretEncryptor（AES-256-GCM）。
 // settingService gates RunCheck via channel_monitor_enabled + channel_monitor_mode.
@@ -0,0 +1,43 @@
+-- Per-API-key model allowlist / denylist.
+-- Patterns are glob-style ("claude-sonnet-*") or regular expressions prefixed
+-- with "re:" ("re:^gpt-5(-mini)?$"). Denylist wins over allowlist; an empty
+-- allowlist means every model not denied is allowed.
+
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS model_allowlist JSONB DEFAULT NULL;
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS model_denylist JSONB DEFAULT NULL;
+
+COMMENT ON COLUMN api_keys.model_allowlist IS 'JSON array of allowed model patterns, e.g. ["claude-sonnet-*", "gpt-5-mini"]';
+COMMENT ON COLUMN api_keys.model_denylist IS 'JSON array of blocked model patterns; deny wins over allow';
+
+-- Model restrictions are part of the API-key auth snapshot. Extend the durable
+-- invalidation trigger so out-of-band edits cannot leave cached snapshots with
+-- stale model policy. Based on the function body from
+-- 184_auth_cache_invalidation_outbox.sql.
+CREATE OR REPLACE FUNCTION enqueue_api_key_auth_cache_invalidation()
+RETURNS TRIGGER
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM enqueue_auth_cache_invalidation(OLD.key);
+        RETURN OLD;
+    END IF;
+
+    IF OLD.key IS DISTINCT FROM NEW.key
+       OR OLD.status IS DISTINCT FROM NEW.status
+       OR OLD.deleted_at IS DISTINCT FROM NEW.deleted_at
+       OR OLD.user_id IS DISTINCT FROM NEW.user_id
+       OR OLD.group_id IS DISTINCT FROM NEW.group_id
+       OR OLD.ip_whitelist IS DISTINCT FROM NEW.ip_whitelist
+       OR OLD.ip_blacklist IS DISTINCT FROM NEW.ip_blacklist
+       OR OLD.model_allowlist IS DISTINCT FROM NEW.model_allowlist
+       OR OLD.model_denylist IS DISTINCT FROM NEW.model_denylist
+       OR OLD.expires_at IS DISTINCT FROM NEW.expires_at THEN
+        PERFORM enqueue_auth_cache_invalidation(OLD.key);
+        IF NEW.deleted_at IS NULL AND NEW.key IS DISTINCT FROM OLD.key THEN
+            PERFORM enqueue_auth_cache_invalidation(NEW.key);
+        END IF;
+    END IF;
+    RETURN NEW;
+END;
+$$;
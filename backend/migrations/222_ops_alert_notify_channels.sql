@@ -0,0 +1,12 @@
+-- Ops alert chat/webhook notification channels.
+-- notify_channels: per-rule JSON array of channel configs
+--   [{"type":"slack","name":"oncall","url":"https://hooks.slack.com/..."}]
+--   supported types: webhook / slack / telegram / feishu / dingtalk
+-- channel_deliveries: per-event JSON array of delivery records (firing and
+-- resolved notifications are appended, never overwritten).
+
+ALTER TABLE ops_alert_rules ADD COLUMN IF NOT EXISTS notify_channels JSONB DEFAULT NULL;
+ALTER TABLE ops_alert_events ADD COLUMN IF NOT EXISTS channel_deliveries JSONB DEFAULT NULL;
+
+COMMENT ON COLUMN ops_alert_rules.notify_channels IS 'JSON array of notification channel configs (webhook/slack/telegram/feishu/dingtalk)';
+COMMENT ON COLUMN ops_alert_events.channel_deliveries IS 'JSON array of per-channel delivery records, appended for firing/resolved notifications';
@@ -0,0 +1,55 @@
+-- Anthropic Message Batches proxy (/v1/messages/batches).
+-- Each row binds one client-visible batch (batch_id, msgbatch_ prefix) to the
+-- upstream batch created on a single Anthropic API-key account. The estimated
+-- cost is held from users.balance at submit time (usage_billing_dedup request
+-- id message_batch_hold:<batch_id>) and captured at batch pricing on settlement.
+--
+-- status: created -> in_progress -> (canceling) -> settling -> completed
+--         created/settling -> failed (hold released)
+
+CREATE TABLE IF NOT EXISTS message_batch_jobs (
+    id BIGSERIAL PRIMARY KEY,
+    batch_id VARCHAR(64) NOT NULL UNIQUE,
+    user_id BIGINT NOT NULL,
+    api_key_id BIGINT NOT NULL,
+    account_id BIGINT NOT NULL,
+    group_id BIGINT,
+    upstream_batch_id VARCHAR(128),
+    status VARCHAR(32) NOT NULL DEFAULT 'created',
+    request_count INTEGER NOT NULL,
+    processing_count INTEGER NOT NULL DEFAULT 0,
+    succeeded_count INTEGER NOT NULL DEFAULT 0,
+    errored_count INTEGER NOT NULL DEFAULT 0,
+    canceled_count INTEGER NOT NULL DEFAULT 0,
+    expired_count INTEGER NOT NULL DEFAULT 0,
+    estimated_cost DECIMAL(20,10) NOT NULL DEFAULT 0,
+    hold_amount DECIMAL(20,10) NOT NULL DEFAULT 0,
+    actual_cost DECIMAL(20,10),
+    group_rate_multiplier DECIMAL(10,4) NOT NULL DEFAULT 1,
+    account_rate_multiplier DECIMAL(10,4) NOT NULL DEFAULT 1,
+    batch_discount_multiplier DECIMAL(10,4) NOT NULL DEFAULT 0.5,
+    input_tokens BIGINT NOT NULL DEFAULT 0,
+    output_tokens BIGINT NOT NULL DEFAULT 0,
+    cache_creation_tokens BIGINT NOT NULL DEFAULT 0,
+    cache_read_tokens BIGINT NOT NULL DEFAULT 0,
+    idempotency_key VARCHAR(255),
+    request_hash VARCHAR(128) NOT NULL DEFAULT '',
+    retry_count INTEGER NOT NULL DEFAULT 0,
+    last_error_code VARCHAR(128),
+    last_error_message TEXT,
+    next_poll_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ,
+    ended_at TIMESTAMPTZ,
+    cancel_initiated_at TIMESTAMPTZ,
+    settled_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS message_batch_jobs_api_key_id_idx ON message_batch_jobs (api_key_id, id DESC);
+CREATE INDEX IF NOT EXISTS message_batch_jobs_due_idx ON message_batch_jobs (next_poll_at)
+    WHERE status IN ('created', 'in_progress', 'canceling', 'settling');
+CREATE UNIQUE INDEX IF NOT EXISTS message_batch_jobs_idempotency_uq ON message_batch_jobs (api_key_id, idempotency_key)
+    WHERE idempotency_key IS NOT NULL AND idempotency_key <> '';
+
+COMMENT ON TABLE message_batch_jobs IS 'Anthropic Message Batches proxied through Anthropic API-key accounts, with balance hold and batch-price settlement';
@@ -0,0 +1,80 @@
+-- OpenAI Files / Batch API proxy (/v1/files, /v1/batches).
+--
+-- openai_batch_files records which sub2api API key owns an upstream file and on
+-- which OpenAI API-key account it lives. Input files carry the per-model cost
+-- estimate computed while streaming the upload; batch output/error files are
+-- registered under the batch owner when the batch ends.
+--
+-- openai_batch_jobs binds a client-visible batch (batch_id) to the upstream
+-- batch on the account that owns its input file. The estimated cost is held
+-- from users.balance (usage_billing_dedup request id openai_batch_hold:<batch_id>)
+-- and captured at batch pricing from the output file.
+--
+-- status: created -> in_progress -> settling -> completed
+--         created/settling -> failed (hold released)
+
+CREATE TABLE IF NOT EXISTS openai_batch_files (
+    id BIGSERIAL PRIMARY KEY,
+    file_id VARCHAR(128) NOT NULL UNIQUE,
+    user_id BIGINT NOT NULL,
+    api_key_id BIGINT NOT NULL,
+    account_id BIGINT NOT NULL,
+    purpose VARCHAR(32) NOT NULL,
+    filename VARCHAR(255) NOT NULL DEFAULT '',
+    bytes BIGINT NOT NULL DEFAULT 0,
+    endpoint VARCHAR(64) NOT NULL DEFAULT '',
+    request_count INTEGER NOT NULL DEFAULT 0,
+    estimate JSONB NOT NULL DEFAULT '[]'::jsonb,
+    upstream_object JSONB,
+    deleted_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS openai_batch_files_api_key_id_idx ON openai_batch_files (api_key_id, id DESC)
+    WHERE deleted_at IS NULL;
+
+COMMENT ON TABLE openai_batch_files IS 'Upstream OpenAI files owned by sub2api API keys, pinned to the account that stores them';
+
+CREATE TABLE IF NOT EXISTS openai_batch_jobs (
+    id BIGSERIAL PRIMARY KEY,
+    batch_id VARCHAR(64) NOT NULL UNIQUE,
+    user_id BIGINT NOT NULL,
+    api_key_id BIGINT NOT NULL,
+    account_id BIGINT NOT NULL,
+    group_id BIGINT,
+    input_file_id VARCHAR(128) NOT NULL,
+    endpoint VARCHAR(64) NOT NULL,
+    upstream_batch_id VARCHAR(128),
+    status VARCHAR(32) NOT NULL DEFAULT 'created',
+    upstream_status VARCHAR(32) NOT NULL DEFAULT '',
+    upstream_object JSONB,
+    output_file_id VARCHAR(128),
+    error_file_id VARCHAR(128),
+    request_count INTEGER NOT NULL DEFAULT 0,
+    estimated_cost DECIMAL(20,10) NOT NULL DEFAULT 0,
+    hold_amount DECIMAL(20,10) NOT NULL DEFAULT 0,
+    actual_cost DECIMAL(20,10),
+    group_rate_multiplier DECIMAL(10,4) NOT NULL DEFAULT 1,
+    account_rate_multiplier DECIMAL(10,4) NOT NULL DEFAULT 1,
+    batch_discount_multiplier DECIMAL(10,4) NOT NULL DEFAULT 0.5,
+    input_tokens BIGINT NOT NULL DEFAULT 0,
+    output_tokens BIGINT NOT NULL DEFAULT 0,
+    cache_read_tokens BIGINT NOT NULL DEFAULT 0,
+    idempotency_key VARCHAR(255),
+    request_hash VARCHAR(128) NOT NULL DEFAULT '',
+    retry_count INTEGER NOT NULL DEFAULT 0,
+    last_error_code VARCHAR(128),
+    last_error_message TEXT,
+    next_poll_at TIMESTAMPTZ,
+    settled_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS openai_batch_jobs_api_key_id_idx ON openai_batch_jobs (api_key_id, id DESC);
+CREATE INDEX IF NOT EXISTS openai_batch_jobs_due_idx ON openai_batch_jobs (next_poll_at)
+    WHERE status IN ('created', 'in_progress', 'settling');
+CREATE UNIQUE INDEX IF NOT EXISTS openai_batch_jobs_idempotency_uq ON openai_batch_jobs (api_key_id, idempotency_key)
+    WHERE idempotency_key IS NOT NULL AND idempotency_key <> '';
+
+COMMENT ON TABLE openai_batch_jobs IS 'OpenAI batches proxied through OpenAI API-key accounts, with balance hold and batch-price settlement';
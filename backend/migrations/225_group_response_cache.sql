@@ -0,0 +1,19 @@
+-- Exact-match response cache for deterministic gateway requests.
+--
+-- Groups opt in with response_cache_enabled; a hit replays the stored response
+-- and is billed at the original token price multiplied by
+-- response_cache_hit_multiplier. usage_logs.response_cache_multiplier is set
+-- only on rows produced by a cache hit.
+
+ALTER TABLE groups
+    ADD COLUMN IF NOT EXISTS response_cache_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS response_cache_ttl_seconds INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS response_cache_hit_multiplier DECIMAL(10,4) NOT NULL DEFAULT 0.1;
+
+COMMENT ON COLUMN groups.response_cache_enabled IS '是否对该分组的确定性请求启用精确匹配响应缓存';
+COMMENT ON COLUMN groups.response_cache_ttl_seconds IS '响应缓存有效期（秒），0 表示使用全局默认值';
+COMMENT ON COLUMN groups.response_cache_hit_multiplier IS '缓存命中计费倍率，按原价乘以该值计费；0 表示命中免费';
+
+ALTER TABLE usage_logs ADD COLUMN IF NOT EXISTS response_cache_multiplier DECIMAL(10,4);
+
+COMMENT ON COLUMN usage_logs.response_cache_multiplier IS '响应缓存命中计费倍率；非空表示该记录由缓存命中产生';
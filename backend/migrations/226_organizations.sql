@@ -0,0 +1,86 @@
+-- Organizations: shared wallet, member roles, per-member spend caps and pooled
+-- group subscriptions.
+--
+-- A user belongs to at most one organization (organization_members.user_id is
+-- unique). While the organization is active, balance-mode usage of every member
+-- API key is deducted from organizations.balance instead of users.balance, and
+-- accumulates into the member's calendar-month spend (monthly_spent_usd, reset
+-- lazily when spend_month_start falls behind the current month).
+--
+-- organization_subscriptions maps a group to a user_subscriptions row held by
+-- the organization owner. Members without a personal subscription for that group
+-- resolve to it, so daily/weekly/monthly quotas are pooled across the organization.
+--
+-- organization_invitations: owners/admins invite an existing user by email and
+-- the user joins only after accepting. At most one pending invitation exists per
+-- (organization, user); accepting inserts the organization_members row in the
+-- same transaction. Direct member adds remain admin-only.
+
+CREATE TABLE IF NOT EXISTS organizations (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    owner_user_id BIGINT NOT NULL REFERENCES users(id),
+    balance DECIMAL(20,8) NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS organizations_owner_user_id_idx ON organizations (owner_user_id)
+    WHERE deleted_at IS NULL;
+
+COMMENT ON TABLE organizations IS 'Organizations whose members draw from a shared wallet';
+COMMENT ON COLUMN organizations.balance IS 'Shared wallet balance (USD), deducted by balance-mode usage of member API keys';
+COMMENT ON COLUMN organizations.status IS 'active | disabled; disabled organizations stop funding members';
+
+CREATE TABLE IF NOT EXISTS organization_members (
+    id BIGSERIAL PRIMARY KEY,
+    organization_id BIGINT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL DEFAULT 'member',
+    monthly_spend_cap_usd DECIMAL(20,8),
+    monthly_spent_usd DECIMAL(20,8) NOT NULL DEFAULT 0,
+    spend_month_start TIMESTAMPTZ NOT NULL DEFAULT (date_trunc('month', NOW() AT TIME ZONE 'UTC') AT TIME ZONE 'UTC'),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS organization_members_organization_id_idx ON organization_members (organization_id);
+
+COMMENT ON COLUMN organization_members.role IS 'owner | admin | member';
+COMMENT ON COLUMN organization_members.monthly_spend_cap_usd IS 'Per-member monthly spend cap on the shared wallet (USD); NULL = unlimited';
+COMMENT ON COLUMN organization_members.monthly_spent_usd IS 'Wallet spend of the member in the month starting at spend_month_start';
+
+CREATE TABLE IF NOT EXISTS organization_subscriptions (
+    id BIGSERIAL PRIMARY KEY,
+    organization_id BIGINT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    group_id BIGINT NOT NULL REFERENCES groups(id),
+    user_subscription_id BIGINT NOT NULL REFERENCES user_subscriptions(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (organization_id, group_id)
+);
+
+COMMENT ON TABLE organization_subscriptions IS 'Group subscriptions shared by all members of an organization';
+
+CREATE TABLE IF NOT EXISTS organization_invitations (
+    id BIGSERIAL PRIMARY KEY,
+    organization_id BIGINT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    invited_by_user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL DEFAULT 'member',
+    monthly_spend_cap_usd DECIMAL(20,8),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    responded_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS organization_invitations_pending_uidx
+    ON organization_invitations (organization_id, user_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS organization_invitations_user_pending_idx
+    ON organization_invitations (user_id) WHERE status = 'pending';
+
+COMMENT ON TABLE organization_invitations IS 'Pending and answered invitations to join an organization';
+COMMENT ON COLUMN organization_invitations.role IS 'Role granted on acceptance: admin | member';
+COMMENT ON COLUMN organization_invitations.status IS 'pending | accepted | declined | revoked';
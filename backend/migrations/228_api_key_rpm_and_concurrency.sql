@@ -0,0 +1,44 @@
+-- Per-API-key request throttling.
+-- rpm_limit / max_concurrency are enforced in addition to the user and group
+-- limits, so one runaway key cannot exhaust the budget shared by the user's
+-- other keys. 0 means unlimited.
+
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS rpm_limit integer NOT NULL DEFAULT 0;
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS max_concurrency integer NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN api_keys.rpm_limit IS 'Requests per minute for this API key (0 = unlimited)';
+COMMENT ON COLUMN api_keys.max_concurrency IS 'Max in-flight requests for this API key (0 = unlimited)';
+
+-- Both limits are part of the API-key auth snapshot. Extend the durable
+-- invalidation trigger so out-of-band edits refresh cached snapshots. Based on
+-- the function body from 221_api_key_model_restrictions.sql.
+CREATE OR REPLACE FUNCTION enqueue_api_key_auth_cache_invalidation()
+RETURNS TRIGGER
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM enqueue_auth_cache_invalidation(OLD.key);
+        RETURN OLD;
+    END IF;
+
+    IF OLD.key IS DISTINCT FROM NEW.key
+       OR OLD.status IS DISTINCT FROM NEW.status
+       OR OLD.deleted_at IS DISTINCT FROM NEW.deleted_at
+       OR OLD.user_id IS DISTINCT FROM NEW.user_id
+       OR OLD.group_id IS DISTINCT FROM NEW.group_id
+       OR OLD.ip_whitelist IS DISTINCT FROM NEW.ip_whitelist
+       OR OLD.ip_blacklist IS DISTINCT FROM NEW.ip_blacklist
+       OR OLD.model_allowlist IS DISTINCT FROM NEW.model_allowlist
+       OR OLD.model_denylist IS DISTINCT FROM NEW.model_denylist
+       OR OLD.rpm_limit IS DISTINCT FROM NEW.rpm_limit
+       OR OLD.max_concurrency IS DISTINCT FROM NEW.max_concurrency
+       OR OLD.expires_at IS DISTINCT FROM NEW.expires_at THEN
+        PERFORM enqueue_auth_cache_invalidation(OLD.key);
+        IF NEW.deleted_at IS NULL AND NEW.key IS DISTINCT FROM OLD.key THEN
+            PERFORM enqueue_auth_cache_invalidation(NEW.key);
+        END IF;
+    END IF;
+    RETURN NEW;
+END;
+$$;
@@ -0,0 +1,49 @@
+-- Subscription auto-renewal agreements.
+--
+-- A row is created in 'pending' status together with the first (setup) payment
+-- order when the user opts into auto-renewal. Once the provider confirms the
+-- payment and returns a reusable customer / payment-method reference the row
+-- becomes 'active'. Shortly before the matching user_subscriptions row expires
+-- the background worker charges the saved payment method off-session; failed
+-- charges move the row to 'past_due' and are retried every next_attempt_at
+-- until the configured attempt limit, after which it becomes 'failed'.
+
+CREATE TABLE IF NOT EXISTS subscription_auto_renewals (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    group_id BIGINT NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    plan_id BIGINT NOT NULL,
+    setup_order_id BIGINT NOT NULL,
+    provider_instance_id VARCHAR(64) NOT NULL DEFAULT '',
+    provider_key VARCHAR(30) NOT NULL DEFAULT '',
+    payment_type VARCHAR(30) NOT NULL DEFAULT '',
+    customer_ref VARCHAR(255) NOT NULL DEFAULT '',
+    payment_method_ref VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    failure_count INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ,
+    last_order_id BIGINT,
+    last_error TEXT,
+    last_charged_at TIMESTAMPTZ,
+    cancelled_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS subscription_auto_renewals_setup_order_id_idx
+    ON subscription_auto_renewals (setup_order_id);
+CREATE INDEX IF NOT EXISTS subscription_auto_renewals_last_order_id_idx
+    ON subscription_auto_renewals (last_order_id) WHERE last_order_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS subscription_auto_renewals_user_id_idx
+    ON subscription_auto_renewals (user_id);
+-- At most one live agreement per user and subscription group.
+CREATE UNIQUE INDEX IF NOT EXISTS subscription_auto_renewals_live_user_group_idx
+    ON subscription_auto_renewals (user_id, group_id) WHERE status IN ('active', 'past_due');
+
+COMMENT ON TABLE subscription_auto_renewals IS 'Opt-in subscription auto-renewal agreements backed by a provider-saved payment method';
+COMMENT ON COLUMN subscription_auto_renewals.setup_order_id IS 'payment_orders.id of the first order that saved the payment method';
+COMMENT ON COLUMN subscription_auto_renewals.customer_ref IS 'Provider customer reference (e.g. Stripe cus_...)';
+COMMENT ON COLUMN subscription_auto_renewals.payment_method_ref IS 'Provider reusable payment method / mandate reference (e.g. Stripe pm_...)';
+COMMENT ON COLUMN subscription_auto_renewals.status IS 'pending | active | past_due | failed | cancelled';
+COMMENT ON COLUMN subscription_auto_renewals.next_attempt_at IS 'Earliest time of the next charge attempt; NULL = as soon as the subscription enters the renewal window';
+COMMENT ON COLUMN subscription_auto_renewals.last_order_id IS 'payment_orders.id of the latest renewal charge';
@@ -0,0 +1,216 @@
+-- Immutable double-entry balance ledger.
+--
+-- Every change to users.balance / users.frozen_balance is journaled by the
+-- trg_users_balance_ledger trigger in the same transaction as the change, so
+-- no code path (usage billing, payments, refunds, redeem/promo codes,
+-- affiliate transfers, admin adjustments, batch holds...) can move money
+-- without a ledger entry.
+--
+-- Each journal entry has postings that always sum to zero:
+--   user_available  the user's spendable balance (users.balance)
+--   user_frozen     the user's held balance (users.frozen_balance)
+--   system:<type>   the counterpart account, e.g. system:recharge, system:usage
+-- A batch hold therefore moves money user_available -> user_frozen without a
+-- system posting, while a recharge credits user_available from system:recharge.
+--
+-- Callers classify entries through transaction-local settings
+-- (set_config(..., true)): sub2api.ledger_type, sub2api.ledger_ref_type,
+-- sub2api.ledger_ref_id and sub2api.ledger_note. Untagged changes are still
+-- recorded as 'unclassified'.
+
+CREATE TABLE IF NOT EXISTS balance_ledger_entries (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    entry_type VARCHAR(40) NOT NULL,
+    balance_delta DECIMAL(20,8) NOT NULL DEFAULT 0,
+    frozen_delta DECIMAL(20,8) NOT NULL DEFAULT 0,
+    balance_after DECIMAL(20,8) NOT NULL,
+    frozen_balance_after DECIMAL(20,8) NOT NULL,
+    reference_type VARCHAR(40) NOT NULL DEFAULT '',
+    reference_id VARCHAR(128) NOT NULL DEFAULT '',
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS balance_ledger_entries_user_id_idx
+    ON balance_ledger_entries (user_id, id DESC);
+CREATE INDEX IF NOT EXISTS balance_ledger_entries_reference_idx
+    ON balance_ledger_entries (reference_type, reference_id) WHERE reference_id <> '';
+CREATE INDEX IF NOT EXISTS balance_ledger_entries_created_at_idx
+    ON balance_ledger_entries (created_at);
+
+CREATE TABLE IF NOT EXISTS balance_ledger_postings (
+    id BIGSERIAL PRIMARY KEY,
+    entry_id BIGINT NOT NULL REFERENCES balance_ledger_entries(id),
+    account VARCHAR(64) NOT NULL,
+    user_id BIGINT,
+    amount DECIMAL(20,8) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS balance_ledger_postings_entry_id_idx
+    ON balance_ledger_postings (entry_id);
+CREATE INDEX IF NOT EXISTS balance_ledger_postings_user_account_idx
+    ON balance_ledger_postings (user_id, account) WHERE user_id IS NOT NULL;
+
+CREATE OR REPLACE FUNCTION reject_balance_ledger_mutation()
+RETURNS TRIGGER
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    RAISE EXCEPTION 'balance ledger is append-only (% on %)', TG_OP, TG_TABLE_NAME;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_balance_ledger_entries_immutable ON balance_ledger_entries;
+CREATE TRIGGER trg_balance_ledger_entries_immutable
+BEFORE UPDATE OR DELETE ON balance_ledger_entries
+FOR EACH ROW EXECUTE FUNCTION reject_balance_ledger_mutation();
+DROP TRIGGER IF EXISTS trg_balance_ledger_entries_no_truncate ON balance_ledger_entries;
+CREATE TRIGGER trg_balance_ledger_entries_no_truncate
+BEFORE TRUNCATE ON balance_ledger_entries
+FOR EACH STATEMENT EXECUTE FUNCTION reject_balance_ledger_mutation();
+
+DROP TRIGGER IF EXISTS trg_balance_ledger_postings_immutable ON balance_ledger_postings;
+CREATE TRIGGER trg_balance_ledger_postings_immutable
+BEFORE UPDATE OR DELETE ON balance_ledger_postings
+FOR EACH ROW EXECUTE FUNCTION reject_balance_ledger_mutation();
+DROP TRIGGER IF EXISTS trg_balance_ledger_postings_no_truncate ON balance_ledger_postings;
+CREATE TRIGGER trg_balance_ledger_postings_no_truncate
+BEFORE TRUNCATE ON balance_ledger_postings
+FOR EACH STATEMENT EXECUTE FUNCTION reject_balance_ledger_mutation();
+
+-- append_balance_ledger_entry writes one balanced journal entry.
+CREATE OR REPLACE FUNCTION append_balance_ledger_entry(
+    p_user_id BIGINT,
+    p_entry_type TEXT,
+    p_balance_delta DECIMAL(20,8),
+    p_frozen_delta DECIMAL(20,8),
+    p_balance_after DECIMAL(20,8),
+    p_frozen_after DECIMAL(20,8),
+    p_reference_type TEXT,
+    p_reference_id TEXT,
+    p_note TEXT
+)
+RETURNS VOID
+LANGUAGE plpgsql
+AS $$
+DECLARE
+    new_entry_id BIGINT;
+    counterpart DECIMAL(20,8);
+BEGIN
+    INSERT INTO balance_ledger_entries (
+        user_id, entry_type, balance_delta, frozen_delta, balance_after, frozen_balance_after,
+        reference_type, reference_id, note
+    )
+    VALUES (
+        p_user_id, p_entry_type, p_balance_delta, p_frozen_delta, p_balance_after, p_frozen_after,
+        COALESCE(p_reference_type, ''), COALESCE(p_reference_id, ''), COALESCE(p_note, '')
+    )
+    RETURNING id INTO new_entry_id;
+
+    IF p_balance_delta <> 0 THEN
+        INSERT INTO balance_ledger_postings (entry_id, account, user_id, amount)
+        VALUES (new_entry_id, 'user_available', p_user_id, p_balance_delta);
+    END IF;
+    IF p_frozen_delta <> 0 THEN
+        INSERT INTO balance_ledger_postings (entry_id, account, user_id, amount)
+        VALUES (new_entry_id, 'user_frozen', p_user_id, p_frozen_delta);
+    END IF;
+    counterpart := -(p_balance_delta + p_frozen_delta);
+    IF counterpart <> 0 THEN
+        INSERT INTO balance_ledger_postings (entry_id, account, user_id, amount)
+        VALUES (new_entry_id, 'system:' || p_entry_type, NULL, counterpart);
+    END IF;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION record_user_balance_ledger()
+RETURNS TRIGGER
+LANGUAGE plpgsql
+AS $$
+DECLARE
+    old_balance DECIMAL(20,8) := 0;
+    old_frozen DECIMAL(20,8) := 0;
+    new_frozen DECIMAL(20,8) := COALESCE(NEW.frozen_balance, 0);
+    entry_type TEXT;
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        old_balance := OLD.balance;
+        old_frozen := COALESCE(OLD.frozen_balance, 0);
+    END IF;
+    IF NEW.balance = old_balance AND new_frozen = old_frozen THEN
+        RETURN NEW;
+    END IF;
+
+    entry_type := NULLIF(current_setting('sub2api.ledger_type', true), '');
+    IF entry_type IS NULL THEN
+        entry_type := CASE WHEN TG_OP = 'INSERT' THEN 'initial_balance' ELSE 'unclassified' END;
+    END IF;
+
+    PERFORM append_balance_ledger_entry(
+        NEW.id,
+        entry_type,
+        NEW.balance - old_balance,
+        new_frozen - old_frozen,
+        NEW.balance,
+        new_frozen,
+        current_setting('sub2api.ledger_ref_type', true),
+        current_setting('sub2api.ledger_ref_id', true),
+        current_setting('sub2api.ledger_note', true)
+    );
+    RETURN NEW;
+END;
+$$;
+
+-- Block balance writes while opening entries are taken so the trigger and the
+-- backfill never double count or miss a change.
+LOCK TABLE users IN SHARE ROW EXCLUSIVE MODE;
+
+DROP TRIGGER IF EXISTS trg_users_balance_ledger ON users;
+CREATE TRIGGER trg_users_balance_ledger
+AFTER INSERT OR UPDATE OF balance, frozen_balance ON users
+FOR EACH ROW EXECUTE FUNCTION record_user_balance_ledger();
+
+-- Opening entries carry existing balances into the ledger.
+SELECT append_balance_ledger_entry(
+    u.id, 'opening_balance', u.balance, COALESCE(u.frozen_balance, 0), u.balance, COALESCE(u.frozen_balance, 0),
+    '', '', 'ledger opening balance'
+)
+FROM users u
+WHERE (u.balance <> 0 OR COALESCE(u.frozen_balance, 0) <> 0)
+  AND NOT EXISTS (SELECT 1 FROM balance_ledger_entries e WHERE e.user_id = u.id);
+
+CREATE TABLE IF NOT EXISTS balance_ledger_reconciliations (
+    id BIGSERIAL PRIMARY KEY,
+    status VARCHAR(20) NOT NULL DEFAULT 'running',
+    users_checked INT NOT NULL DEFAULT 0,
+    mismatched_users INT NOT NULL DEFAULT 0,
+    unbalanced_entries INT NOT NULL DEFAULT 0,
+    error_message TEXT NOT NULL DEFAULT '',
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS balance_ledger_reconciliations_started_at_idx
+    ON balance_ledger_reconciliations (started_at DESC);
+
+CREATE TABLE IF NOT EXISTS balance_ledger_mismatches (
+    id BIGSERIAL PRIMARY KEY,
+    reconciliation_id BIGINT NOT NULL REFERENCES balance_ledger_reconciliations(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL,
+    balance DECIMAL(20,8) NOT NULL,
+    ledger_balance DECIMAL(20,8) NOT NULL,
+    frozen_balance DECIMAL(20,8) NOT NULL,
+    ledger_frozen_balance DECIMAL(20,8) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS balance_ledger_mismatches_reconciliation_id_idx
+    ON balance_ledger_mismatches (reconciliation_id, user_id);
+
+COMMENT ON TABLE balance_ledger_entries IS 'Append-only journal of users.balance / frozen_balance changes, written by trg_users_balance_ledger';
+COMMENT ON COLUMN balance_ledger_entries.entry_type IS 'recharge | usage | refund | refund_rollback | redeem | promo | affiliate_transfer | organization_transfer | admin_adjustment | balance_hold | hold_capture | hold_release | signup_bonus | initial_balance | opening_balance | unclassified';
+COMMENT ON COLUMN balance_ledger_entries.balance_after IS 'Running users.balance after this entry';
+COMMENT ON TABLE balance_ledger_postings IS 'Double-entry postings; amounts of one entry always sum to zero';
+COMMENT ON TABLE balance_ledger_reconciliations IS 'Reconciliation runs comparing users balances with ledger sums';
+COMMENT ON TABLE balance_ledger_mismatches IS 'Users whose balance differed from the ledger sum in a reconciliation run';
@@ -0,0 +1,57 @@
+-- Invoices and credit notes for payment orders.
+--
+-- Buyer tax details are optionally captured at checkout on payment_orders and
+-- snapshotted into the document together with the seller details configured
+-- in payment settings, so later edits never change an issued document.
+--
+-- Each order gets at most one invoice (once fulfilled) and at most one credit
+-- note (once refunded). Numbers are gapless and sequential per number prefix
+-- and currency (e.g. INV-USD-000042): payment_invoice_sequences is incremented
+-- in the same transaction that inserts the document, so a rolled back issue
+-- releases its number.
+
+ALTER TABLE payment_orders ADD COLUMN IF NOT EXISTS buyer_name VARCHAR(255);
+ALTER TABLE payment_orders ADD COLUMN IF NOT EXISTS buyer_tax_id VARCHAR(64);
+ALTER TABLE payment_orders ADD COLUMN IF NOT EXISTS buyer_address TEXT;
+
+CREATE TABLE IF NOT EXISTS payment_invoice_sequences (
+    prefix VARCHAR(16) NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    last_value BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (prefix, currency)
+);
+
+CREATE TABLE IF NOT EXISTS payment_invoices (
+    id BIGSERIAL PRIMARY KEY,
+    kind VARCHAR(20) NOT NULL,
+    invoice_number VARCHAR(64) NOT NULL,
+    sequence_value BIGINT NOT NULL,
+    order_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    related_invoice_id BIGINT REFERENCES payment_invoices(id),
+    currency VARCHAR(3) NOT NULL,
+    amount DECIMAL(20,2) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    seller JSONB NOT NULL DEFAULT '{}'::jsonb,
+    buyer JSONB NOT NULL DEFAULT '{}'::jsonb,
+    footer TEXT NOT NULL DEFAULT '',
+    issued_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS payment_invoices_number_idx
+    ON payment_invoices (invoice_number);
+CREATE UNIQUE INDEX IF NOT EXISTS payment_invoices_order_kind_idx
+    ON payment_invoices (order_id, kind);
+CREATE INDEX IF NOT EXISTS payment_invoices_user_id_idx
+    ON payment_invoices (user_id, issued_at DESC);
+
+COMMENT ON TABLE payment_invoices IS 'Immutable invoices / credit notes issued for payment orders';
+COMMENT ON COLUMN payment_invoices.kind IS 'invoice | credit_note';
+COMMENT ON COLUMN payment_invoices.related_invoice_id IS 'For credit notes: the invoice being credited';
+COMMENT ON COLUMN payment_invoices.amount IS 'Amount actually paid (invoice) or refunded (credit note) in currency';
+COMMENT ON COLUMN payment_invoices.seller IS 'Seller details snapshot at issue time';
+COMMENT ON COLUMN payment_invoices.buyer IS 'Buyer details snapshot at issue time';
+COMMENT ON COLUMN payment_invoices.footer IS 'Invoice footer text snapshot at issue time';
+COMMENT ON TABLE payment_invoice_sequences IS 'Gapless document numbering per number prefix and currency';
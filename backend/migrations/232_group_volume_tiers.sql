@@ -0,0 +1,4 @@
+-- Cumulative monthly spend tiers per group. Each entry is {up_to_usd, multiplier};
+-- the last entry omits up_to_usd. The effective tier is recorded in usage_logs.billing_tier.
+ALTER TABLE groups
+    ADD COLUMN IF NOT EXISTS volume_tiers JSONB NOT NULL DEFAULT '[]'::jsonb;
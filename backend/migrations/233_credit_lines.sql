@@ -0,0 +1,45 @@
+-- Postpaid credit lines with monthly statements.
+--
+-- users.credit_limit lets an admin allow a user's balance to go negative down
+-- to -credit_limit (0 keeps the account fully prepaid).
+--
+-- Every calendar month (system timezone) a statement is generated for each
+-- user with a credit line, summarizing balance-billed usage by group/model and
+-- snapshotting the balance at generation time. amount_due is the negative part
+-- of that closing balance. A statement is paid once net balance credits since
+-- generation cover amount_due; past due_at it becomes overdue and the user's
+-- active API keys are moved to status credit_suspended until it is paid.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS credit_limit DECIMAL(20,8) NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS credit_statements (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    period_start TIMESTAMPTZ NOT NULL,
+    period_end TIMESTAMPTZ NOT NULL,
+    request_count BIGINT NOT NULL DEFAULT 0,
+    total_cost DECIMAL(20,8) NOT NULL DEFAULT 0,
+    credit_limit DECIMAL(20,8) NOT NULL DEFAULT 0,
+    closing_balance DECIMAL(20,8) NOT NULL DEFAULT 0,
+    amount_due DECIMAL(20,8) NOT NULL DEFAULT 0,
+    due_at TIMESTAMPTZ NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'issued',
+    lines JSONB NOT NULL DEFAULT '[]'::jsonb,
+    paid_at TIMESTAMPTZ,
+    overdue_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS credit_statements_user_period_idx
+    ON credit_statements (user_id, period_start);
+CREATE INDEX IF NOT EXISTS credit_statements_open_idx
+    ON credit_statements (due_at)
+    WHERE status IN ('issued', 'overdue');
+
+COMMENT ON COLUMN users.credit_limit IS 'Postpaid credit line in USD: balance may go down to -credit_limit';
+COMMENT ON TABLE credit_statements IS 'Monthly postpaid statements for users with a credit line';
+COMMENT ON COLUMN credit_statements.status IS 'issued | overdue | paid';
+COMMENT ON COLUMN credit_statements.closing_balance IS 'users.balance when the statement was generated';
+COMMENT ON COLUMN credit_statements.amount_due IS 'GREATEST(-closing_balance, 0)';
+COMMENT ON COLUMN credit_statements.lines IS 'Balance-billed usage in the period grouped by group and model';
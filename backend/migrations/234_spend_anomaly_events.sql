@@ -0,0 +1,30 @@
+-- Spend anomaly detection.
+--
+-- A periodic job compares each user's and API key's spend in a rolling window
+-- with their average spend for the same window length over the preceding
+-- baseline days. When it exceeds the configured multiple, the user is emailed,
+-- the key is optionally disabled, and one row is recorded here. The latest row
+-- per user/key also drives the alert cooldown.
+
+CREATE TABLE IF NOT EXISTS spend_anomaly_events (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    api_key_id BIGINT REFERENCES api_keys(id) ON DELETE SET NULL,
+    api_key_name VARCHAR(100) NOT NULL DEFAULT '',
+    window_start TIMESTAMPTZ NOT NULL,
+    window_end TIMESTAMPTZ NOT NULL,
+    window_spend DECIMAL(20,8) NOT NULL DEFAULT 0,
+    baseline_spend DECIMAL(20,8) NOT NULL DEFAULT 0,
+    ratio DECIMAL(20,4) NOT NULL DEFAULT 0,
+    key_disabled BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS spend_anomaly_events_user_key_idx
+    ON spend_anomaly_events (user_id, api_key_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS spend_anomaly_events_created_idx
+    ON spend_anomaly_events (created_at DESC);
+
+COMMENT ON TABLE spend_anomaly_events IS 'Detected spend spikes per user (api_key_id NULL) or per API key';
+COMMENT ON COLUMN spend_anomaly_events.baseline_spend IS 'Average spend for one window length over the baseline period';
+COMMENT ON COLUMN spend_anomaly_events.ratio IS 'window_spend / baseline_spend at detection time';
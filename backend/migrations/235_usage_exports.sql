@@ -0,0 +1,67 @@
+-- Scheduled usage exports to object storage.
+--
+-- usage_export_jobs are admin-defined periodic exports of usage_logs
+-- (daily/weekly/monthly, CSV or Parquet) filtered by group/user/model and
+-- written to either the backup S3 storage or the image storage bucket.
+-- next_period_start is the first period not yet enqueued; the scheduler keeps
+-- enqueuing runs until it reaches the current, unfinished period.
+--
+-- usage_export_runs records every export file: one per job period (scheduled,
+-- manual or backfill) or one per self-service user export (job_id NULL,
+-- user_id set). Filters are snapshotted on the run so that it can be executed
+-- or re-run independently of later job edits. Re-running a period overwrites
+-- the same object key.
+
+CREATE TABLE IF NOT EXISTS usage_export_jobs (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    frequency VARCHAR(10) NOT NULL,
+    format VARCHAR(10) NOT NULL,
+    storage VARCHAR(20) NOT NULL,
+    prefix VARCHAR(255) NOT NULL DEFAULT '',
+    filters JSONB NOT NULL DEFAULT '{}'::jsonb,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    next_period_start TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS usage_export_runs (
+    id BIGSERIAL PRIMARY KEY,
+    job_id BIGINT REFERENCES usage_export_jobs(id) ON DELETE SET NULL,
+    user_id BIGINT REFERENCES users(id) ON DELETE CASCADE,
+    triggered_by VARCHAR(20) NOT NULL,
+    format VARCHAR(10) NOT NULL,
+    storage VARCHAR(20) NOT NULL,
+    filters JSONB NOT NULL DEFAULT '{}'::jsonb,
+    period_start TIMESTAMPTZ NOT NULL,
+    period_end TIMESTAMPTZ NOT NULL,
+    object_key VARCHAR(512) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    row_count BIGINT NOT NULL DEFAULT 0,
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    sha256 VARCHAR(64) NOT NULL DEFAULT '',
+    error_message TEXT NOT NULL DEFAULT '',
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS usage_export_runs_job_idx
+    ON usage_export_runs (job_id, period_start DESC);
+CREATE INDEX IF NOT EXISTS usage_export_runs_user_idx
+    ON usage_export_runs (user_id, created_at DESC)
+    WHERE user_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS usage_export_runs_pending_idx
+    ON usage_export_runs (created_at)
+    WHERE status = 'pending';
+
+COMMENT ON TABLE usage_export_jobs IS 'Admin-defined periodic usage_logs exports to object storage';
+COMMENT ON COLUMN usage_export_jobs.frequency IS 'daily | weekly | monthly (periods in the system timezone, weeks start on Monday)';
+COMMENT ON COLUMN usage_export_jobs.format IS 'csv | parquet';
+COMMENT ON COLUMN usage_export_jobs.storage IS 'backup | image_storage';
+COMMENT ON COLUMN usage_export_jobs.filters IS '{"group_ids":[],"user_ids":[],"models":[]}; empty lists mean no filter';
+COMMENT ON TABLE usage_export_runs IS 'Export files written by usage export jobs and self-service user exports';
+COMMENT ON COLUMN usage_export_runs.triggered_by IS 'schedule | manual | backfill | user';
+COMMENT ON COLUMN usage_export_runs.status IS 'pending | running | succeeded | failed';
+COMMENT ON COLUMN usage_export_runs.sha256 IS 'Hex SHA-256 of the uploaded file';
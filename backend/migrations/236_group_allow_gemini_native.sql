@@ -0,0 +1,6 @@
+-- Anthropic/OpenAI groups may opt in to serving Gemini-native
+-- /v1beta/models/{model}:generateContent requests through protocol conversion.
+ALTER TABLE groups
+    ADD COLUMN IF NOT EXISTS allow_gemini_native BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN groups.allow_gemini_native IS '是否允许 Anthropic/OpenAI 分组通过协议转换接入 Gemini 原生 generateContent 请求';
@@ -0,0 +1,27 @@
+-- Hedged requests for non-streaming OpenAI calls.
+--
+-- Groups opt in with hedge_policy.enabled. When the first account has not
+-- answered within a percentile-based delay, the same request is sent to a
+-- second account; the first answer wins and only the winner is billed to the
+-- user. group_hedge_stats keeps one row per group and day with how often the
+-- hedge fired, who won, and the estimated upstream cost of the losing calls.
+
+ALTER TABLE groups
+    ADD COLUMN IF NOT EXISTS hedge_policy JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+COMMENT ON COLUMN groups.hedge_policy IS 'OpenAI 非流式对冲请求策略 {enabled, percentile, min_delay_ms, max_delay_ms}';
+
+CREATE TABLE IF NOT EXISTS group_hedge_stats (
+    group_id BIGINT NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    stat_date DATE NOT NULL,
+    hedged_count BIGINT NOT NULL DEFAULT 0,
+    hedge_win_count BIGINT NOT NULL DEFAULT 0,
+    primary_win_count BIGINT NOT NULL DEFAULT 0,
+    hedge_cost DECIMAL(20,8) NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (group_id, stat_date)
+);
+
+COMMENT ON TABLE group_hedge_stats IS 'Daily hedged-request outcomes per group';
+COMMENT ON COLUMN group_hedge_stats.hedged_count IS 'Requests for which a second account was dispatched';
+COMMENT ON COLUMN group_hedge_stats.hedge_cost IS 'Estimated upstream cost (USD, standard price x account rate) of cancelled losing attempts';
@@ -0,0 +1,38 @@
+-- Weighted / sticky traffic splits on composite model routes.
+--
+-- A route with a non-null split spreads matching requests across named arms
+-- (each with its own target platform and upstream model) instead of always
+-- using the route's single target. Arms are chosen by weight, optionally
+-- pinned per API key or per user by hashing the caller with the experiment
+-- name.
+--
+-- composite_route_experiment_assignments records which arm served each
+-- request, keyed by the client request id, so per-arm latency, error rate,
+-- token usage and cost can be aggregated by joining usage_logs
+-- (request_id = 'client:' || client_request_id) and ops_error_logs.
+
+ALTER TABLE composite_model_routes
+    ADD COLUMN IF NOT EXISTS split JSONB NULL;
+
+COMMENT ON COLUMN composite_model_routes.split IS '流量拆分实验 {experiment, sticky_by, arms:[{name, weight, target_platform, upstream_model}]}；NULL 表示单一目标';
+
+CREATE TABLE IF NOT EXISTS composite_route_experiment_assignments (
+    id BIGSERIAL PRIMARY KEY,
+    client_request_id VARCHAR(64) NOT NULL,
+    group_id BIGINT NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    route_id BIGINT NOT NULL,
+    experiment VARCHAR(100) NOT NULL,
+    arm VARCHAR(100) NOT NULL,
+    target_platform VARCHAR(50) NOT NULL,
+    upstream_model VARCHAR(200) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_composite_route_experiment_assignments_route_time
+    ON composite_route_experiment_assignments (route_id, experiment, created_at);
+
+CREATE INDEX IF NOT EXISTS idx_composite_route_experiment_assignments_created_at
+    ON composite_route_experiment_assignments (created_at);
+
+COMMENT ON TABLE composite_route_experiment_assignments IS 'Which split arm served each composite-routed request';
+COMMENT ON COLUMN composite_route_experiment_assignments.client_request_id IS 'Matches usage_logs.request_id (client:<id>) and ops_error_logs.client_request_id';
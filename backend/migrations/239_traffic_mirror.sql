@@ -0,0 +1,59 @@
+-- Traffic mirroring for evaluating candidate upstreams on real traffic.
+--
+-- Groups opt in with mirror_policy.enabled. A sampled fraction of the group's
+-- messages / responses / chat-completions requests is replayed asynchronously
+-- to a candidate group (optionally pinned to one account). The mirrored
+-- response is discarded and never billed; traffic_mirror_records keeps one
+-- comparison row per mirrored request for the ops dashboard.
+
+ALTER TABLE groups
+    ADD COLUMN IF NOT EXISTS mirror_policy JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+COMMENT ON COLUMN groups.mirror_policy IS '流量镜像策略 {enabled, sample_rate, target_group_id, target_account_id, compare_output}';
+
+CREATE TABLE IF NOT EXISTS traffic_mirror_records (
+    id BIGSERIAL PRIMARY KEY,
+    group_id BIGINT NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    target_group_id BIGINT NOT NULL,
+    target_account_id BIGINT NULL,
+    client_request_id VARCHAR(64) NOT NULL DEFAULT '',
+    endpoint VARCHAR(50) NOT NULL,
+    model VARCHAR(200) NOT NULL DEFAULT '',
+    stream BOOLEAN NOT NULL DEFAULT FALSE,
+
+    primary_account_id BIGINT NULL,
+    primary_status INT NOT NULL DEFAULT 0,
+    primary_latency_ms INT NOT NULL DEFAULT 0,
+    primary_ttft_ms INT NULL,
+    primary_input_tokens INT NOT NULL DEFAULT 0,
+    primary_output_tokens INT NOT NULL DEFAULT 0,
+    primary_model VARCHAR(200) NOT NULL DEFAULT '',
+
+    mirror_account_id BIGINT NULL,
+    mirror_status INT NOT NULL DEFAULT 0,
+    mirror_latency_ms INT NOT NULL DEFAULT 0,
+    mirror_ttft_ms INT NULL,
+    mirror_input_tokens INT NOT NULL DEFAULT 0,
+    mirror_output_tokens INT NOT NULL DEFAULT 0,
+    mirror_model VARCHAR(200) NOT NULL DEFAULT '',
+    mirror_error TEXT NOT NULL DEFAULT '',
+
+    output_similarity DOUBLE PRECISION NULL,
+    primary_output_excerpt TEXT NULL,
+    mirror_output_excerpt TEXT NULL,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_traffic_mirror_records_group_time
+    ON traffic_mirror_records (group_id, created_at);
+
+CREATE INDEX IF NOT EXISTS idx_traffic_mirror_records_target_time
+    ON traffic_mirror_records (target_group_id, created_at);
+
+CREATE INDEX IF NOT EXISTS idx_traffic_mirror_records_created_at
+    ON traffic_mirror_records (created_at);
+
+COMMENT ON TABLE traffic_mirror_records IS 'Primary vs mirrored response comparison per sampled request';
+COMMENT ON COLUMN traffic_mirror_records.mirror_status IS 'HTTP status of the discarded mirrored response; 0 when no response was produced (timeout / dropped)';
+COMMENT ON COLUMN traffic_mirror_records.output_similarity IS 'Token Jaccard similarity of the two outputs (0..1); NULL unless compare_output is enabled';
@@ -0,0 +1,12 @@
+-- Batch settlement that exhausts its retries no longer releases the hold.
+-- The batch already ran upstream, so refunding would make failed settlement
+-- free usage. Jobs move to the terminal status settlement_stuck instead: the
+-- hold stays frozen on users.balance and the ops alert metric
+-- batch_settlement_stuck_count flags them for manual capture.
+--
+-- status: ... settling -> settlement_stuck (hold kept, no further polling)
+
+CREATE INDEX IF NOT EXISTS message_batch_jobs_settlement_stuck_idx ON message_batch_jobs (id)
+    WHERE status = 'settlement_stuck';
+CREATE INDEX IF NOT EXISTS openai_batch_jobs_settlement_stuck_idx ON openai_batch_jobs (id)
+    WHERE status = 'settlement_stuck';
@@ -0,0 +1,30 @@
+-- Batch balance holds for organization members draw from the organization
+-- wallet, matching how their synchronous usage is charged.
+--
+-- Reserve moves the estimate from organizations.balance to
+-- organizations.frozen_balance and records where the hold lives in
+-- organization_balance_holds (keyed by the hold request id). Capture and
+-- release look the row up instead of re-resolving membership, so a hold always
+-- settles against the wallet it was taken from even if the member has left
+-- since. Capture also accrues the actual cost into the member's monthly spend.
+-- Rows are deleted once the hold is captured or released.
+
+ALTER TABLE organizations
+    ADD COLUMN IF NOT EXISTS frozen_balance DECIMAL(20,8) NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN organizations.frozen_balance IS 'Wallet funds held by in-flight member batches (USD)';
+
+CREATE TABLE IF NOT EXISTS organization_balance_holds (
+    hold_request_id VARCHAR(255) NOT NULL,
+    api_key_id BIGINT NOT NULL,
+    organization_id BIGINT NOT NULL REFERENCES organizations(id),
+    user_id BIGINT NOT NULL,
+    amount DECIMAL(20,8) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (hold_request_id, api_key_id)
+);
+
+CREATE INDEX IF NOT EXISTS organization_balance_holds_organization_id_idx
+    ON organization_balance_holds (organization_id);
+
+COMMENT ON TABLE organization_balance_holds IS 'Open batch holds taken from an organization wallet';
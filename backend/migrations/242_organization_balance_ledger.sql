@@ -0,0 +1,137 @@
+-- Extend the balance ledger to organization wallets.
+--
+-- organizations.balance / organizations.frozen_balance are journaled by
+-- trg_organizations_balance_ledger exactly like users, so member usage, batch
+-- holds, transfers from a member's personal balance and admin adjustments on
+-- the shared wallet all leave entries. Organization entries carry
+-- organization_id instead of user_id and post to two accounts:
+--   organization_available  the wallet's spendable balance (organizations.balance)
+--   organization_frozen     wallet funds held by member batches (organizations.frozen_balance)
+-- A transfer from a member therefore writes two entries in one transaction
+-- whose system:organization_transfer postings cancel out.
+--
+-- Reconciliation also compares every organization with its ledger sums;
+-- mismatch rows name either a user or an organization.
+
+ALTER TABLE balance_ledger_entries ALTER COLUMN user_id DROP NOT NULL;
+ALTER TABLE balance_ledger_entries ADD COLUMN IF NOT EXISTS organization_id BIGINT;
+ALTER TABLE balance_ledger_entries DROP CONSTRAINT IF EXISTS balance_ledger_entries_owner_check;
+ALTER TABLE balance_ledger_entries ADD CONSTRAINT balance_ledger_entries_owner_check
+    CHECK ((user_id IS NULL) <> (organization_id IS NULL));
+
+CREATE INDEX IF NOT EXISTS balance_ledger_entries_organization_id_idx
+    ON balance_ledger_entries (organization_id, id DESC) WHERE organization_id IS NOT NULL;
+
+ALTER TABLE balance_ledger_postings ADD COLUMN IF NOT EXISTS organization_id BIGINT;
+
+CREATE INDEX IF NOT EXISTS balance_ledger_postings_organization_account_idx
+    ON balance_ledger_postings (organization_id, account) WHERE organization_id IS NOT NULL;
+
+-- append_organization_ledger_entry writes one balanced journal entry for an organization wallet.
+CREATE OR REPLACE FUNCTION append_organization_ledger_entry(
+    p_organization_id BIGINT,
+    p_entry_type TEXT,
+    p_balance_delta DECIMAL(20,8),
+    p_frozen_delta DECIMAL(20,8),
+    p_balance_after DECIMAL(20,8),
+    p_frozen_after DECIMAL(20,8),
+    p_reference_type TEXT,
+    p_reference_id TEXT,
+    p_note TEXT
+)
+RETURNS VOID
+LANGUAGE plpgsql
+AS $$
+DECLARE
+    new_entry_id BIGINT;
+    counterpart DECIMAL(20,8);
+BEGIN
+    INSERT INTO balance_ledger_entries (
+        organization_id, entry_type, balance_delta, frozen_delta, balance_after, frozen_balance_after,
+        reference_type, reference_id, note
+    )
+    VALUES (
+        p_organization_id, p_entry_type, p_balance_delta, p_frozen_delta, p_balance_after, p_frozen_after,
+        COALESCE(p_reference_type, ''), COALESCE(p_reference_id, ''), COALESCE(p_note, '')
+    )
+    RETURNING id INTO new_entry_id;
+
+    IF p_balance_delta <> 0 THEN
+        INSERT INTO balance_ledger_postings (entry_id, account, organization_id, amount)
+        VALUES (new_entry_id, 'organization_available', p_organization_id, p_balance_delta);
+    END IF;
+    IF p_frozen_delta <> 0 THEN
+        INSERT INTO balance_ledger_postings (entry_id, account, organization_id, amount)
+        VALUES (new_entry_id, 'organization_frozen', p_organization_id, p_frozen_delta);
+    END IF;
+    counterpart := -(p_balance_delta + p_frozen_delta);
+    IF counterpart <> 0 THEN
+        INSERT INTO balance_ledger_postings (entry_id, account, amount)
+        VALUES (new_entry_id, 'system:' || p_entry_type, counterpart);
+    END IF;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION record_organization_balance_ledger()
+RETURNS TRIGGER
+LANGUAGE plpgsql
+AS $$
+DECLARE
+    old_balance DECIMAL(20,8) := 0;
+    old_frozen DECIMAL(20,8) := 0;
+    entry_type TEXT;
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        old_balance := OLD.balance;
+        old_frozen := OLD.frozen_balance;
+    END IF;
+    IF NEW.balance = old_balance AND NEW.frozen_balance = old_frozen THEN
+        RETURN NEW;
+    END IF;
+
+    entry_type := NULLIF(current_setting('sub2api.ledger_type', true), '');
+    IF entry_type IS NULL THEN
+        entry_type := CASE WHEN TG_OP = 'INSERT' THEN 'initial_balance' ELSE 'unclassified' END;
+    END IF;
+
+    PERFORM append_organization_ledger_entry(
+        NEW.id,
+        entry_type,
+        NEW.balance - old_balance,
+        NEW.frozen_balance - old_frozen,
+        NEW.balance,
+        NEW.frozen_balance,
+        current_setting('sub2api.ledger_ref_type', true),
+        current_setting('sub2api.ledger_ref_id', true),
+        current_setting('sub2api.ledger_note', true)
+    );
+    RETURN NEW;
+END;
+$$;
+
+LOCK TABLE organizations IN SHARE ROW EXCLUSIVE MODE;
+
+DROP TRIGGER IF EXISTS trg_organizations_balance_ledger ON organizations;
+CREATE TRIGGER trg_organizations_balance_ledger
+AFTER INSERT OR UPDATE OF balance, frozen_balance ON organizations
+FOR EACH ROW EXECUTE FUNCTION record_organization_balance_ledger();
+
+SELECT append_organization_ledger_entry(
+    o.id, 'opening_balance', o.balance, o.frozen_balance, o.balance, o.frozen_balance,
+    '', '', 'ledger opening balance'
+)
+FROM organizations o
+WHERE (o.balance <> 0 OR o.frozen_balance <> 0)
+  AND NOT EXISTS (SELECT 1 FROM balance_ledger_entries e WHERE e.organization_id = o.id);
+
+ALTER TABLE balance_ledger_reconciliations
+    ADD COLUMN IF NOT EXISTS organizations_checked INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS mismatched_organizations INT NOT NULL DEFAULT 0;
+
+ALTER TABLE balance_ledger_mismatches ALTER COLUMN user_id DROP NOT NULL;
+ALTER TABLE balance_ledger_mismatches ADD COLUMN IF NOT EXISTS organization_id BIGINT;
+
+COMMENT ON TABLE balance_ledger_entries IS 'Append-only journal of users and organizations balance / frozen_balance changes, written by trg_users_balance_ledger and trg_organizations_balance_ledger';
+COMMENT ON COLUMN balance_ledger_entries.organization_id IS 'Set instead of user_id for organization wallet entries';
+COMMENT ON COLUMN balance_ledger_entries.balance_after IS 'Running users.balance or organizations.balance after this entry';
+COMMENT ON TABLE balance_ledger_mismatches IS 'Users or organizations whose balance differed from the ledger sum in a reconciliation run';
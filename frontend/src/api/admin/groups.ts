@@ -11,8 +11,10 @@ import type {
   CompositeModelRouteInput,
   CompositeRoutePreviewRequest,
   CompositeRouteDecision,
+  CompositeRouteExperimentStats,
   CreateGroupRequest,
   UpdateGroupRequest,
+  GroupHedgeStatsSummary,
   PaginatedResponse
 } from '@/types'
 
@@ -327,6 +329,18 @@ export async function previewCompositeRoute(
   return data
 }
 
+export async function getCompositeRouteExperimentStats(
+  id: number,
+  routeId: number,
+  params?: { start_date?: string; end_date?: string; timezone?: string }
+): Promise<CompositeRouteExperimentStats> {
+  const { data } = await apiClient.get<CompositeRouteExperimentStats>(
+    `/admin/groups/${id}/composite-routes/${routeId}/experiment-stats`,
+    { params }
+  )
+  return data
+}
+
 /**
  * Rate multiplier entry for a user in a group
  */
@@ -461,6 +475,18 @@ export async function getUsageSummary(
   return data
 }
 
+/**
+ * Get hedged request statistics (win rate + estimated hedge cost) for a group
+ * @param id - Group ID
+ * @param days - Lookback window in days (default 7, max 90)
+ */
+export async function getHedgeStats(id: number, days?: number): Promise<GroupHedgeStatsSummary> {
+  const { data } = await apiClient.get<GroupHedgeStatsSummary>(`/admin/groups/${id}/hedge-stats`, {
+    params: days ? { days } : undefined
+  })
+  return data
+}
+
 /**
  * Get capacity summary (concurrency/sessions/RPM) for all active groups
  */
@@ -493,6 +519,7 @@ export const groupsAPI = {
   updateCompositeRoute,
   deleteCompositeRoute,
   previewCompositeRoute,
+  getCompositeRouteExperimentStats,
   getGroupRateMultipliers,
   clearGroupRateMultipliers,
   batchSetGroupRateMultipliers,
@@ -501,7 +528,8 @@ export const groupsAPI = {
   batchSetGroupRPMOverrides,
   updateSortOrder,
   getUsageSummary,
-  getCapacitySummary
+  getCapacitySummary,
+  getHedgeStats
 }
 
 export default groupsAPI
@@ -244,6 +244,80 @@ export interface OpsOpenAITokenStatsParams {
   top_n?: number
 }
 
+export interface OpsTrafficMirrorSummaryItem {
+  group_id: number
+  target_group_id: number
+  target_account_id?: number | null
+  total: number
+  primary_success: number
+  mirror_success: number
+  primary_success_rate: number
+  mirror_success_rate: number
+  status_mismatch: number
+  model_mismatch: number
+  both_success: number
+  avg_primary_latency_ms: number
+  avg_mirror_latency_ms: number
+  avg_primary_ttft_ms?: number | null
+  avg_mirror_ttft_ms?: number | null
+  primary_input_tokens: number
+  primary_output_tokens: number
+  mirror_input_tokens: number
+  mirror_output_tokens: number
+  avg_output_similarity?: number | null
+}
+
+export interface OpsTrafficMirrorSummaryResponse {
+  start_time: string
+  end_time: string
+  items: OpsTrafficMirrorSummaryItem[]
+}
+
+export interface OpsTrafficMirrorRecord {
+  id: number
+  group_id: number
+  target_group_id: number
+  target_account_id?: number | null
+  client_request_id: string
+  endpoint: string
+  model: string
+  stream: boolean
+  primary_account_id?: number | null
+  primary_status: number
+  primary_latency_ms: number
+  primary_ttft_ms?: number | null
+  primary_input_tokens: number
+  primary_output_tokens: number
+  primary_model: string
+  mirror_account_id?: number | null
+  mirror_status: number
+  mirror_latency_ms: number
+  mirror_ttft_ms?: number | null
+  mirror_input_tokens: number
+  mirror_output_tokens: number
+  mirror_model: string
+  mirror_error?: string
+  output_similarity?: number | null
+  primary_output_excerpt?: string | null
+  mirror_output_excerpt?: string | null
+  created_at: string
+}
+
+export type OpsTrafficMirrorRecordsResponse = PaginatedResponse<OpsTrafficMirrorRecord>
+
+export type OpsTrafficMirrorTimeRange = '1h' | '6h' | '24h' | '7d' | '30d'
+
+export interface OpsTrafficMirrorParams {
+  time_range?: OpsTrafficMirrorTimeRange
+  start_time?: string
+  end_time?: string
+  group_id?: number | null
+  target_group_id?: number | null
+  only_diverged?: boolean
+  page?: number
+  page_size?: number
+}
+
 export interface OpsSystemMetricsSnapshot {
   id: number
   created_at: string
@@ -688,6 +762,7 @@ export type MetricType =
   | 'account_error_ratio'
   | 'account_temp_unscheduled_count'
   | 'overload_account_count'
+  | 'batch_settlement_stuck_count'
 export type Operator = '>' | '>=' | '<' | '<=' | '==' | '!='
 
 export interface AlertRule {
@@ -1079,6 +1154,28 @@ export async function getOpenAITokenStats(
   return data
 }
 
+export async function getTrafficMirrorSummary(
+  params: OpsTrafficMirrorParams,
+  options: OpsRequestOptions = {}
+): Promise<OpsTrafficMirrorSummaryResponse> {
+  const { data } = await apiClient.get<OpsTrafficMirrorSummaryResponse>('/admin/ops/traffic-mirror/summary', {
+    params,
+    signal: options.signal
+  })
+  return data
+}
+
+export async function listTrafficMirrorRecords(
+  params: OpsTrafficMirrorParams,
+  options: OpsRequestOptions = {}
+): Promise<OpsTrafficMirrorRecordsResponse> {
+  const { data } = await apiClient.get<OpsTrafficMirrorRecordsResponse>('/admin/ops/traffic-mirror/records', {
+    params,
+    signal: options.signal
+  })
+  return data
+}
+
 export type OpsErrorListView = 'errors' | 'excluded' | 'all'
 
 export type OpsErrorListQueryParams = {
@@ -1313,6 +1410,8 @@ export const opsAPI = {
   getErrorTrend,
   getErrorDistribution,
   getOpenAITokenStats,
+  getTrafficMirrorSummary,
+  listTrafficMirrorRecords,
   getConcurrencyStats,
   getUserConcurrencyStats,
   getAccountAvailabilityStats,
@@ -0,0 +1,119 @@
+/**
+ * Admin usage export API.
+ *
+ * Jobs export usage logs to object storage (the backup S3 bucket or the image
+ * storage bucket) on a daily/weekly/monthly schedule. Runs are the individual
+ * files, including scheduled, manual, backfill and user self-service exports.
+ */
+
+import { apiClient } from '../client'
+import type { PaginatedResponse } from '@/types'
+import type { UsageExportFormat, UsageExportRun } from '../usage'
+
+export type UsageExportFrequency = 'daily' | 'weekly' | 'monthly'
+export type UsageExportStorage = 'backup' | 'image_storage'
+
+export interface UsageExportFilters {
+  group_ids?: number[]
+  user_ids?: number[]
+  models?: string[]
+}
+
+export interface UsageExportJob {
+  id: number
+  name: string
+  frequency: UsageExportFrequency
+  format: UsageExportFormat
+  storage: UsageExportStorage
+  prefix: string
+  filters: UsageExportFilters
+  enabled: boolean
+  next_period_start: string
+  created_at: string
+  updated_at: string
+}
+
+export interface UsageExportJobRequest {
+  name: string
+  frequency: UsageExportFrequency
+  format: UsageExportFormat
+  storage: UsageExportStorage
+  prefix?: string
+  group_ids?: number[]
+  user_ids?: number[]
+  models?: string[]
+  enabled?: boolean
+}
+
+export interface UsageExportRunQuery {
+  page?: number
+  page_size?: number
+  job_id?: number
+  user_id?: number
+  status?: string
+  scope?: 'jobs' | 'user'
+}
+
+export async function listJobs(): Promise<UsageExportJob[]> {
+  const { data } = await apiClient.get<UsageExportJob[]>('/admin/usage-exports/jobs')
+  return data
+}
+
+export async function createJob(payload: UsageExportJobRequest): Promise<UsageExportJob> {
+  const { data } = await apiClient.post<UsageExportJob>('/admin/usage-exports/jobs', payload)
+  return data
+}
+
+export async function updateJob(id: number, payload: UsageExportJobRequest): Promise<UsageExportJob> {
+  const { data } = await apiClient.put<UsageExportJob>(`/admin/usage-exports/jobs/${id}`, payload)
+  return data
+}
+
+export async function deleteJob(id: number): Promise<void> {
+  await apiClient.delete(`/admin/usage-exports/jobs/${id}`)
+}
+
+export async function runJob(id: number): Promise<UsageExportRun> {
+  const { data } = await apiClient.post<UsageExportRun>(`/admin/usage-exports/jobs/${id}/run`)
+  return data
+}
+
+/**
+ * Re-export every completed period between the two dates (inclusive, system timezone).
+ */
+export async function backfillJob(
+  id: number,
+  startDate: string,
+  endDate: string
+): Promise<{ runs: UsageExportRun[]; count: number }> {
+  const { data } = await apiClient.post<{ runs: UsageExportRun[]; count: number }>(
+    `/admin/usage-exports/jobs/${id}/backfill`,
+    { start_date: startDate, end_date: endDate }
+  )
+  return data
+}
+
+export async function listRuns(query: UsageExportRunQuery = {}): Promise<PaginatedResponse<UsageExportRun>> {
+  const { data } = await apiClient.get<PaginatedResponse<UsageExportRun>>('/admin/usage-exports/runs', {
+    params: query
+  })
+  return data
+}
+
+export async function getRunDownloadURL(id: number): Promise<string> {
+  const { data } = await apiClient.get<{ url: string }>(`/admin/usage-exports/runs/${id}/download`)
+  return data.url
+}
+
+export const usageExportsAPI = {
+  listJobs,
+  createJob,
+  updateJob,
+  deleteJob,
+  runJob,
+  backfillJob,
+  listRuns,
+  getRunDownloadURL
+}
+
+export default usageExportsAPI
@@ -15,7 +15,8 @@ import type {
   UsageRequestType,
   UserErrorRequest,
   UserErrorRequestDetail,
-  UserErrorListParams
+  UserErrorListParams,
+  VolumeTier
 } from '@/types'
 
 // ==================== Dashboard Types ====================
@@ -320,6 +321,49 @@ export async function getDashboardSnapshotV2(
   return data
 }
 
+export interface UserVolumeTierProgress {
+  group_id: number
+  group_name: string
+  month_start: string
+  month_spend: number
+  current_tier: number
+  current_multiplier: number
+  next_tier_at_usd?: number
+  tiers: VolumeTier[]
+}
+
+export interface DashboardVolumeTiersResponse {
+  groups: UserVolumeTierProgress[]
+}
+
+/**
+ * Get current-month volume tier progress for groups bound to the user's API keys
+ * @returns Groups with volume tiers and the user's position within them
+ */
+export async function getDashboardVolumeTiers(): Promise<DashboardVolumeTiersResponse> {
+  const { data } = await apiClient.get<DashboardVolumeTiersResponse>('/usage/dashboard/volume-tiers')
+  return data
+}
+
+export interface UserBalanceForecast {
+  balance: number
+  credit_limit: number
+  spendable_balance: number
+  window_days: number
+  avg_daily_spend: number
+  projected_exhaustion_at?: string
+  days_remaining?: number
+}
+
+/**
+ * Get balance exhaustion forecast based on the recent average daily spend
+ * @returns Spendable balance, average daily spend and projected exhaustion time
+ */
+export async function getDashboardBalanceForecast(): Promise<UserBalanceForecast> {
+  const { data } = await apiClient.get<UserBalanceForecast>('/usage/dashboard/balance-forecast')
+  return data
+}
+
 export interface BatchApiKeyUsageStats {
   api_key_id: number
   today_actual_cost: number
@@ -368,6 +412,66 @@ export async function getMyErrorDetail(id: number): Promise<UserErrorRequestDeta
   return data
 }
 
+export type UsageExportFormat = 'csv' | 'parquet'
+
+export interface UsageExportRun {
+  id: number
+  job_id?: number
+  job_name?: string
+  user_id?: number
+  triggered_by: 'schedule' | 'manual' | 'backfill' | 'user'
+  format: UsageExportFormat
+  storage: string
+  period_start: string
+  period_end: string
+  object_key: string
+  status: 'pending' | 'running' | 'succeeded' | 'failed'
+  row_count: number
+  size_bytes: number
+  sha256: string
+  error_message?: string
+  started_at?: string
+  finished_at?: string
+  created_at: string
+}
+
+/**
+ * List the current user's usage export files
+ */
+export async function listMyUsageExports(
+  page: number = 1,
+  pageSize: number = 10
+): Promise<PaginatedResponse<UsageExportRun>> {
+  const { data } = await apiClient.get<PaginatedResponse<UsageExportRun>>('/usage/exports', {
+    params: { page, page_size: pageSize }
+  })
+  return data
+}
+
+/**
+ * Request a usage export for an inclusive date range; the file is generated in the background
+ */
+export async function createMyUsageExport(
+  startDate: string,
+  endDate: string,
+  format: UsageExportFormat
+): Promise<UsageExportRun> {
+  const { data } = await apiClient.post<UsageExportRun>('/usage/exports', {
+    start_date: startDate,
+    end_date: endDate,
+    format
+  })
+  return data
+}
+
+/**
+ * Get a time-limited download URL for a finished export
+ */
+export async function getMyUsageExportDownloadURL(id: number): Promise<string> {
+  const { data } = await apiClient.get<{ url: string }>(`/usage/exports/${id}/download`)
+  return data.url
+}
+
 export const usageAPI = {
   list,
   query,
@@ -382,9 +486,15 @@ export const usageAPI = {
   getMyApiKeyDailyUsage,
   getDashboardSnapshotV2,
   getDashboardApiKeysUsage,
+  getDashboardVolumeTiers,
+  getDashboardBalanceForecast,
   // Error requests
   listMyErrorRequests,
-  getMyErrorDetail
+  getMyErrorDetail,
+  // File exports
+  listMyUsageExports,
+  createMyUsageExport,
+  getMyUsageExportDownloadURL
 }
 
 export default usageAPI
@@ -4,19 +4,23 @@ import {
   HEADER_OVERRIDE_ENABLED_CREDENTIAL_KEY,
   HEADER_OVERRIDES_CREDENTIAL_KEY,
   applyAntigravityProjectID,
+  applyAzureOpenAICredentials,
   applyHeaderOverride,
   applyInterceptWarmup,
   applyPlanType,
   buildHeaderOverridesObject,
   buildPlanTypeOptions,
+  createAzureOpenAIFormState,
   isCustomGrokBaseUrl,
   isHeaderOverrideCapable,
   GROK_BASE_URL_PRESETS,
   parseHeaderOverridesJson,
+  readAzureOpenAIFormState,
   planTypeDisplayLabel,
   readPlanType,
   serializeHeaderOverrideRows,
   splitHeaderOverridesObject,
+  validateAzureOpenAIForm,
   validateHeaderOverrideRows
 } from '../credentialsBuilder'
 
@@ -469,3 +473,57 @@ describe('plan_type helpers', () => {
   })
 })
 
+
+describe('Azure OpenAI credentials', () => {
+  it('writes provider, auth mode and deployment map when enabled', () => {
+    const state = createAzureOpenAIFormState()
+    state.enabled = true
+    state.deployments = [
+      { model: 'gpt-5', deployment: ' prod-gpt5 ' },
+      { model: '', deployment: '' }
+    ]
+    const creds: Record<string, unknown> = { api_key: 'k' }
+    applyAzureOpenAICredentials(creds, state, 'create')
+    expect(creds.provider).toBe('azure')
+    expect(creds.azure_auth_mode).toBe('api_key')
+    expect(creds.azure_deployments).toEqual({ 'gpt-5': 'prod-gpt5' })
+  })
+
+  it('edit + disabled removes every Azure field', () => {
+    const creds: Record<string, unknown> = {
+      api_key: 'k',
+      provider: 'azure',
+      azure_api_version: '2024-10-21',
+      azure_deployments: { '*': 'dep' }
+    }
+    applyAzureOpenAICredentials(creds, createAzureOpenAIFormState(), 'edit')
+    expect(creds).toEqual({ api_key: 'k' })
+  })
+
+  it('keeps a stored client secret when left empty on edit', () => {
+    const state = readAzureOpenAIFormState({
+      provider: 'azure',
+      azure_auth_mode: 'entra',
+      azure_tenant_id: 'tenant',
+      azure_client_id: 'client'
+    })
+    expect(state.enabled).toBe(true)
+    expect(validateAzureOpenAIForm(state)).toBe('entraFieldsRequired')
+    expect(validateAzureOpenAIForm(state, true)).toBeNull()
+
+    const creds: Record<string, unknown> = {}
+    applyAzureOpenAICredentials(creds, state, 'edit')
+    expect(creds).not.toHaveProperty('azure_client_secret')
+    expect(creds.azure_authority_host).toBe('login.microsoftonline.com')
+  })
+
+  it('rejects malformed api versions and deployment names', () => {
+    const state = createAzureOpenAIFormState()
+    state.enabled = true
+    state.apiVersion = 'latest'
+    expect(validateAzureOpenAIForm(state)).toBe('invalidApiVersion')
+    state.apiVersion = '2024-10-21'
+    state.deployments = [{ model: 'gpt-5', deployment: 'a/b' }]
+    expect(validateAzureOpenAIForm(state)).toBe('invalidDeployment')
+  })
+})
@@ -344,3 +344,145 @@ export function applyPlanType(
   }
   return credentials
 }
+
+// ========== Azure OpenAI（openai api_key 账号的 provider=azure 形态，与后端 NormalizeAzureOpenAICredentials 保持一致） ==========
+
+export const AZURE_OPENAI_DEFAULT_API_VERSION = '2025-04-01-preview'
+export const AZURE_OPENAI_AUTHORITY_HOSTS = [
+  'login.microsoftonline.com',
+  'login.microsoftonline.us',
+  'login.chinacloudapi.cn'
+]
+
+const AZURE_OPENAI_CREDENTIAL_KEYS = [
+  'provider',
+  'azure_api_version',
+  'azure_auth_mode',
+  'azure_tenant_id',
+  'azure_client_id',
+  'azure_client_secret',
+  'azure_authority_host',
+  'azure_deployments'
+]
+const AZURE_OPENAI_API_VERSION_PATTERN = /^\d{4}-\d{2}-\d{2}(-preview)?$/
+const AZURE_OPENAI_DEPLOYMENT_PATTERN = /^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$/
+
+export interface AzureDeploymentRow {
+  model: string
+  deployment: string
+}
+
+export interface AzureOpenAIFormState {
+  enabled: boolean
+  apiVersion: string
+  authMode: 'api_key' | 'entra'
+  tenantId: string
+  clientId: string
+  clientSecret: string
+  authorityHost: string
+  deployments: AzureDeploymentRow[]
+}
+
+export function createAzureOpenAIFormState(): AzureOpenAIFormState {
+  return {
+    enabled: false,
+    apiVersion: AZURE_OPENAI_DEFAULT_API_VERSION,
+    authMode: 'api_key',
+    tenantId: '',
+    clientId: '',
+    clientSecret: '',
+    authorityHost: AZURE_OPENAI_AUTHORITY_HOSTS[0],
+    deployments: []
+  }
+}
+
+/** 从已保存的 credentials 还原表单；client_secret 已脱敏，始终留空表示保留 */
+export function readAzureOpenAIFormState(
+  credentials: Record<string, unknown> | undefined | null
+): AzureOpenAIFormState {
+  const state = createAzureOpenAIFormState()
+  if (!credentials || String(credentials.provider ?? '').toLowerCase() !== 'azure') {
+    return state
+  }
+  const str = (key: string) => (typeof credentials[key] === 'string' ? (credentials[key] as string) : '')
+  state.enabled = true
+  state.apiVersion = str('azure_api_version') || AZURE_OPENAI_DEFAULT_API_VERSION
+  state.authMode = str('azure_auth_mode') === 'entra' ? 'entra' : 'api_key'
+  state.tenantId = str('azure_tenant_id')
+  state.clientId = str('azure_client_id')
+  state.authorityHost = str('azure_authority_host') || AZURE_OPENAI_AUTHORITY_HOSTS[0]
+  const deployments = credentials.azure_deployments
+  if (deployments && typeof deployments === 'object' && !Array.isArray(deployments)) {
+    state.deployments = Object.entries(deployments as Record<string, unknown>)
+      .filter(([, v]) => typeof v === 'string')
+      .map(([model, deployment]) => ({ model, deployment: deployment as string }))
+  }
+  return state
+}
+
+/**
+ * 校验 Azure 表单，返回 admin.accounts.azure.* 下的错误 key；通过返回 null。
+ * hasStoredSecret 为编辑时后端已保存 client_secret 的标记（留空即保留）。
+ */
+export function validateAzureOpenAIForm(
+  state: AzureOpenAIFormState,
+  hasStoredSecret = false
+): string | null {
+  if (!state.enabled) return null
+  if (!AZURE_OPENAI_API_VERSION_PATTERN.test(state.apiVersion.trim())) return 'invalidApiVersion'
+  if (state.authMode === 'entra') {
+    if (!state.tenantId.trim() || !state.clientId.trim()) return 'entraFieldsRequired'
+    if (!state.clientSecret.trim() && !hasStoredSecret) return 'entraFieldsRequired'
+  }
+  for (const row of state.deployments) {
+    if (!row.model.trim() && !row.deployment.trim()) continue
+    if (!row.model.trim() || !AZURE_OPENAI_DEPLOYMENT_PATTERN.test(row.deployment.trim())) {
+      return 'invalidDeployment'
+    }
+  }
+  return null
+}
+
+/**
+ * 将 Azure 配置写入 credentials。
+ * create 模式：未启用时不写入；edit 模式：未启用时删除全部 Azure 字段。
+ */
+export function applyAzureOpenAICredentials(
+  credentials: Record<string, unknown>,
+  state: AzureOpenAIFormState,
+  mode: 'create' | 'edit'
+): void {
+  if (!state.enabled) {
+    if (mode === 'edit') {
+      for (const key of AZURE_OPENAI_CREDENTIAL_KEYS) delete credentials[key]
+    }
+    return
+  }
+  credentials.provider = 'azure'
+  credentials.azure_api_version = state.apiVersion.trim()
+  credentials.azure_auth_mode = state.authMode
+  if (state.authMode === 'entra') {
+    credentials.azure_tenant_id = state.tenantId.trim()
+    credentials.azure_client_id = state.clientId.trim()
+    credentials.azure_authority_host = state.authorityHost
+    if (state.clientSecret.trim()) {
+      credentials.azure_client_secret = state.clientSecret.trim()
+    }
+  } else {
+    delete credentials.azure_tenant_id
+    delete credentials.azure_client_id
+    delete credentials.azure_client_secret
+    delete credentials.azure_authority_host
+  }
+  const deployments: Record<string, string> = {}
+  for (const row of state.deployments) {
+    if (row.model.trim() && row.deployment.trim()) {
+      deployments[row.model.trim()] = row.deployment.trim()
+    }
+  }
+  if (Object.keys(deployments).length > 0) {
+    credentials.azure_deployments = deployments
+  } else {
+    delete credentials.azure_deployments
+  }
+}
@@ -48,6 +48,21 @@ describe('PROVIDER_CONFIG_FIELDS.airwallex', () => {
   })
 })
 
+describe('PROVIDER_CONFIG_FIELDS.paypal', () => {
+  it('keeps sensitive fields aligned with the backend', () => {
+    expect(findField('paypal', 'clientSecret')?.sensitive).toBe(true)
+    expect(findField('paypal', 'clientId')?.sensitive).toBe(false)
+    expect(findField('paypal', 'webhookId')?.sensitive).toBe(false)
+  })
+
+  it('defaults to the live API and USD', () => {
+    expect(findField('paypal', 'apiBase')?.defaultValue).toBe('https://api-m.paypal.com')
+    expect(findField('paypal', 'currency')?.defaultValue).toBe('USD')
+    expect(findField('paypal', 'currency')?.options).toBe(PAYMENT_CURRENCY_OPTIONS)
+    expect(findField('paypal', 'brandName')?.optional).toBe(true)
+  })
+})
+
 describe('PROVIDER_CONFIG_FIELDS.stripe', () => {
   it('adds currency config with CNY as the default', () => {
     const currency = findField('stripe', 'currency')
@@ -728,6 +728,29 @@ export default {
       interceptWarmupRequests: 'Intercept Warmup Requests',
       interceptWarmupRequestsDesc:
         'When enabled, warmup requests like title generation will return mock responses without consuming upstream tokens',
+      azure: {
+        title: 'Azure OpenAI',
+        hint: 'Route this key to an Azure OpenAI resource. Set Base URL to the resource endpoint, e.g. https://<resource>.openai.azure.com',
+        apiVersion: 'API Version',
+        authMode: 'Authentication',
+        authModes: {
+          api_key: 'API Key (api-key header)',
+          entra: 'Microsoft Entra ID (client credentials)'
+        },
+        tenantId: 'Tenant ID',
+        clientId: 'Client ID',
+        clientSecret: 'Client Secret',
+        authorityHost: 'Authority Host',
+        deployments: 'Deployment Mapping',
+        deploymentsHint:
+          'Map requested models to deployment names. Use * as the default; unmapped models use the model name as the deployment.',
+        modelPlaceholder: 'Model, e.g. gpt-5 or *',
+        deploymentPlaceholder: 'Deployment name',
+        addDeployment: 'Add deployment',
+        invalidApiVersion: 'API version must look like 2025-04-01-preview',
+        entraFieldsRequired: 'Entra authentication requires tenant ID, client ID and client secret',
+        invalidDeployment: 'Each deployment row needs a model and a valid deployment name'
+      },
       headerOverride: {
         title: 'Header Override',
         hint: 'Override same-named request headers on forwarding (case-insensitive)',
@@ -173,6 +173,32 @@ export default {
           requestsWithFirstToken: 'Requests With First Token'
         }
       },
+      trafficMirror: {
+        title: 'Traffic Mirror Comparison',
+        description: 'Sampled requests replayed to candidate groups/accounts. Mirrored responses are discarded and never billed.',
+        failedToLoad: 'Failed to load traffic mirror data',
+        empty: 'No mirrored requests in the selected range',
+        recordsTitle: 'Recent Mirrored Requests',
+        onlyDiverged: 'Only diverged',
+        noRecords: 'No matching records',
+        account: 'Account',
+        primary: 'Primary',
+        mirror: 'Mirror',
+        showDetail: 'Show output',
+        hideDetail: 'Hide output',
+        table: {
+          source: 'Source Group',
+          target: 'Candidate',
+          total: 'Mirrored',
+          successRate: 'Success Rate',
+          statusMismatch: 'Status Mismatch',
+          modelMismatch: 'Model Mismatch',
+          latency: 'Avg Latency (ms)',
+          ttft: 'Avg TTFT (ms)',
+          outputTokens: 'Output Tokens',
+          similarity: 'Output Similarity'
+        }
+      },
       fullscreen: {
         enter: 'Enter Fullscreen'
       },
@@ -497,7 +523,8 @@ export default {
           accountErrorCount: 'Error Accounts (excluding temporarily unschedulable)',
           accountErrorRatio: 'Error Account Ratio (%)',
           accountTempUnscheduledCount: 'Temporarily Unschedulable Accounts',
-          overloadAccountCount: 'Overloaded Accounts'
+          overloadAccountCount: 'Overloaded Accounts',
+          batchSettlementStuckCount: 'Stuck Batch Settlements'
         },
         metricDescriptions: {
           successRate: 'Percentage of successful requests in the window (0-100).',
@@ -515,7 +542,8 @@ export default {
           accountErrorCount: 'Number of error accounts within the window (excluding temporarily unschedulable).',
           accountErrorRatio: 'Error account ratio within the window (0-100).',
           accountTempUnscheduledCount: 'Number of accounts currently temporarily unschedulable (e.g. proxy/credential failure auto-eviction).',
-          overloadAccountCount: 'Number of overloaded accounts within the window.'
+          overloadAccountCount: 'Number of overloaded accounts within the window.',
+          batchSettlementStuckCount: 'Message/OpenAI batches whose settlement exhausted its retries. Their balance hold stays frozen until settled manually.'
         },
         hints: {
           recommended: 'Recommended: operator {operator}, threshold {threshold}{unit}',
@@ -509,7 +509,10 @@ export default {
         selectStatus: 'Select status',
         rpmLimit: 'Requests Per Minute (RPM)',
         rpmLimitPlaceholder: '0 = unlimited',
-        rpmLimitHint: 'Max requests per minute for this user; 0 = unlimited. Acts as a fallback only when the group has no rpm_limit set.'
+        rpmLimitHint: 'Max requests per minute for this user; 0 = unlimited. Acts as a fallback only when the group has no rpm_limit set.',
+        creditLimit: 'Credit Limit (USD)',
+        creditLimitPlaceholder: '0 = prepaid only',
+        creditLimitHint: 'Postpaid credit line: the balance may go negative down to -limit. A monthly statement is issued for the negative balance; API keys are suspended if it is unpaid after the due date.'
       },
       columns: {
         user: 'User',
@@ -863,6 +866,17 @@ export default {
         unsupportedFrom: 'Request value is not supported by this platform',
         unsupportedTo: 'Forwarded value is not supported by this platform',
         duplicateFrom: 'Request value A must be unique',
+        volumeTiers: 'Monthly volume tiers',
+        volumeTiersHint: 'Multiplier applied on top of the rate multiplier based on each user\'s actual spend in this group this calendar month. A request is priced entirely at the tier reached before it. Leave empty to disable.',
+        addVolumeTier: 'Add tier',
+        removeVolumeTier: 'Remove tier',
+        volumeTierUpTo: 'Monthly spend below (USD)',
+        volumeTierUpToPlaceholder: 'e.g. 100',
+        volumeTierUnlimited: 'and above',
+        volumeTierMultiplier: 'Tier multiplier',
+        volumeTierUpToRequired: 'Enter the upper bound of this tier',
+        volumeTierUpToIncreasing: 'Upper bounds must be positive and increasing',
+        volumeTierMultiplierInvalid: 'Multiplier must be a non-negative number',
         exclusiveLabel: 'Exclusive Group',
         exclusiveHint: 'Exclusive group, can be manually assigned to users',
         platformLabel: 'Platform Restriction',
@@ -1091,6 +1105,29 @@ export default {
         sources: {
           route: 'Route',
           detector: 'Detector'
+        },
+        split: {
+          enabled: 'Traffic split (A/B experiment)',
+          hint: 'Spread matching requests across arms by weight. Sticky mode keeps each API key or user on the same arm; per-arm latency, error rate, tokens and cost are compared from usage logs.',
+          experiment: 'Experiment',
+          stickyBy: 'Sticky by',
+          arm: 'Arm',
+          weight: 'Weight',
+          addArm: 'Add Arm',
+          badge: 'Experiment: {name}',
+          stats: 'Experiment stats',
+          statsTitle: 'Experiment {name} (last 7 days)',
+          requests: 'Requests',
+          errorRate: 'Error Rate',
+          latency: 'Avg Latency',
+          tokens: 'Tokens In / Out',
+          cost: 'Cost (USD)',
+          failedToLoadStats: 'Failed to load experiment stats',
+          sticky: {
+            none: 'Per request',
+            apiKey: 'API key',
+            user: 'User'
+          }
         }
       },
       claudeCode: {
@@ -1132,6 +1169,33 @@ export default {
         unsupportedMessage: 'This Sub2API server cannot generate the required Live attestation. Live will not work even if enabled. Continue anyway?',
         enableAnyway: 'Enable anyway'
       },
+      geminiNative: {
+        title: 'Gemini Native API',
+        allow: 'Serve Gemini generateContent',
+        hint: 'When enabled, API keys in this group can call /v1beta/models/<model>:generateContent and :streamGenerateContent. Requests are translated to Anthropic Messages or OpenAI Responses, including function calling, inline images and usageMetadata.'
+      },
+      hedge: {
+        title: 'Hedged Requests',
+        enabled: 'Enable hedging for non-streaming requests',
+        hint: 'When the first account has not answered after the delay below, the same request is sent to a second account and the first success is returned. The slower one is cancelled and only the winner is billed. Streaming, previous_response_id, compact and image requests are never hedged.',
+        percentile: 'Latency percentile',
+        minDelayMs: 'Min delay (ms)',
+        maxDelayMs: 'Max delay (ms)',
+        delayHint: 'Hedge delay = the account\'s recent latency at this percentile, clamped to [min, max]. Until enough samples exist the max delay is used.',
+        stats: 'Last {days} days: {count} hedged, hedge won {rate}%, estimated extra upstream cost {cost} USD'
+      },
+      mirror: {
+        title: 'Traffic Mirroring',
+        enabled: 'Mirror sampled requests to a candidate upstream',
+        hint: 'A sampled share of this group\'s messages / responses / chat-completions requests is replayed in the background to the candidate below. The client only ever sees the primary response, mirrored calls are not billed, and each pair is recorded for comparison in the ops dashboard.',
+        sampleRate: 'Sample rate (0-1)',
+        targetGroup: 'Candidate group',
+        sameGroup: 'This group',
+        targetAccount: 'Candidate account ID',
+        anyAccount: 'Any account in the candidate group',
+        compareOutput: 'Store output excerpts and similarity',
+        targetHint: 'Pick a candidate group, an account ID, or both. Mirroring to this group requires an account ID. The account must belong to the candidate group; composite groups cannot be candidates.'
+      },
       invalidRequestFallback: {
         title: 'Invalid Request Fallback Group',
         hint: 'Triggered only when upstream explicitly returns prompt too long. Leave empty to disable fallback.',
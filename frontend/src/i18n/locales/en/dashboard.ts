@@ -31,6 +31,25 @@ export default {
       noLimit: 'unlimited',
       disabled: 'Disabled',
     },
+    balanceForecast: {
+      title: 'Balance Forecast',
+      spendable: 'Spendable balance',
+      includesCredit: 'includes {amount} credit line',
+      avgDailySpend: 'Avg. daily spend (last {days} days)',
+      daysRemaining: 'Estimated days remaining',
+      daysValue: '{days} days',
+      exhaustedAt: 'Projected to run out around {time}',
+      exhausted: 'Your spendable balance is used up. Please top up to keep using the API.',
+      noSpend: 'No balance spend in the last {days} days, nothing to forecast.',
+    },
+    volumeTiers: {
+      title: 'Volume Pricing',
+      monthSpend: 'This month',
+      currentTier: 'Tier {tier}',
+      multiplier: '×{value}',
+      nextTierAt: '{amount} more to reach the next tier',
+      topTier: 'Top tier reached',
+    },
     tokenUsageTrend: 'Token Usage Trend',
     noDataAvailable: 'No data available',
     model: 'Model',
@@ -300,6 +319,31 @@ export default {
     exportCancelled: 'Export cancelled',
     exporting: 'Exporting...',
     preparingExport: 'Preparing export...',
+    fileExports: {
+      open: 'Export file',
+      title: 'Usage file exports',
+      description: 'Generate a complete CSV or Parquet file of your usage for the selected dates. Large ranges are processed in the background; download links expire after a short time.',
+      range: 'Range: {start} to {end}',
+      create: 'Generate file',
+      creating: 'Submitting...',
+      created: 'Export queued, the file will be ready shortly',
+      createFailed: 'Failed to create export',
+      loadFailed: 'Failed to load exports',
+      downloadFailed: 'Failed to get download link',
+      empty: 'No exports yet',
+      period: 'Period',
+      format: 'Format',
+      status: 'Status',
+      rows: 'Rows',
+      size: 'Size',
+      download: 'Download',
+      statuses: {
+        pending: 'Queued',
+        running: 'Generating',
+        succeeded: 'Ready',
+        failed: 'Failed'
+      }
+    },
     model: 'Model',
     requestedModel: 'Requested',
     upstreamModel: 'Upstream',
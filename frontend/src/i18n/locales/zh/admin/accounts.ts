@@ -792,6 +792,28 @@ export default {
       errorCodeExists: '该错误码已被选中',
       interceptWarmupRequests: '拦截预热请求',
       interceptWarmupRequestsDesc: '启用后，标题生成等预热请求将返回 mock 响应，不消耗上游 token',
+      azure: {
+        title: 'Azure OpenAI',
+        hint: '将该 Key 路由到 Azure OpenAI 资源。Base URL 填写资源终结点，如 https://<resource>.openai.azure.com',
+        apiVersion: 'API 版本',
+        authMode: '鉴权方式',
+        authModes: {
+          api_key: 'API Key（api-key 请求头）',
+          entra: 'Microsoft Entra ID（客户端凭据）'
+        },
+        tenantId: '租户 ID',
+        clientId: '客户端 ID',
+        clientSecret: '客户端密钥',
+        authorityHost: '登录主机',
+        deployments: '部署映射',
+        deploymentsHint: '将请求模型映射到部署名。* 为默认部署；未映射的模型直接以模型名作为部署名。',
+        modelPlaceholder: '模型，如 gpt-5 或 *',
+        deploymentPlaceholder: '部署名',
+        addDeployment: '添加部署',
+        invalidApiVersion: 'API 版本格式应类似 2025-04-01-preview',
+        entraFieldsRequired: 'Entra 鉴权需要填写租户 ID、客户端 ID 和客户端密钥',
+        invalidDeployment: '每行部署映射都需要模型和合法的部署名'
+      },
       headerOverride: {
         title: '请求头覆写',
         hint: '转发时用配置值覆盖同名请求头（不区分大小写）',
@@ -173,6 +173,32 @@ export default {
         startTime: '开始时间',
         endTime: '结束时间'
       },
+      trafficMirror: {
+        title: '流量镜像对比',
+        description: '按抽样比例重放到候选分组/账号的请求；镜像响应不会返回给客户端，也不计费。',
+        failedToLoad: '加载流量镜像数据失败',
+        empty: '所选时间范围内没有镜像请求',
+        recordsTitle: '最近的镜像请求',
+        onlyDiverged: '仅看差异',
+        noRecords: '没有符合条件的记录',
+        account: '账号',
+        primary: '主请求',
+        mirror: '镜像',
+        showDetail: '查看输出',
+        hideDetail: '收起输出',
+        table: {
+          source: '源分组',
+          target: '候选',
+          total: '镜像次数',
+          successRate: '成功率',
+          statusMismatch: '状态不一致',
+          modelMismatch: '模型不一致',
+          latency: '平均耗时 (ms)',
+          ttft: '平均首字延迟 (ms)',
+          outputTokens: '输出 Token',
+          similarity: '输出相似度'
+        }
+      },
       fullscreen: {
         enter: '进入全屏'
       },
@@ -497,7 +523,8 @@ export default {
           accountErrorCount: '错误账号数（不含临时不可调度）',
           accountErrorRatio: '错误账号比例 (%)',
           accountTempUnscheduledCount: '临时不可调度账号数',
-          overloadAccountCount: '过载账号数'
+          overloadAccountCount: '过载账号数',
+          batchSettlementStuckCount: '结算卡住的批次数'
         },
         metricDescriptions: {
           successRate: '统计窗口内成功请求占比（0~100）。',
@@ -515,7 +542,8 @@ export default {
           accountErrorCount: '统计窗口内产生错误的账号数量（不含临时不可调度）。',
           accountErrorRatio: '统计窗口内错误账号占比（0~100）。',
           accountTempUnscheduledCount: '当前处于临时不可调度状态的账号数量（如代理/凭据故障被自动摘除）。',
-          overloadAccountCount: '统计窗口内过载账号数量。'
+          overloadAccountCount: '统计窗口内过载账号数量。',
+          batchSettlementStuckCount: '结算重试耗尽的 Message/OpenAI 批次数量，其冻结余额在人工结算前保持不动。'
         },
         hints: {
           recommended: '推荐：运算符 {operator}，阈值 {threshold}{unit}',
@@ -574,7 +574,10 @@ export default {
         selectStatus: '选择状态',
         rpmLimit: '每分钟请求数 (RPM)',
         rpmLimitPlaceholder: '0 表示不限制',
-        rpmLimitHint: '该用户每分钟最大请求数，0 = 不限制；仅在所用分组未设置 rpm_limit 时作为兜底生效'
+        rpmLimitHint: '该用户每分钟最大请求数，0 = 不限制；仅在所用分组未设置 rpm_limit 时作为兜底生效',
+        creditLimit: '授信额度 (USD)',
+        creditLimitPlaceholder: '0 表示仅预付费',
+        creditLimitHint: '后付费授信：余额最多可透支到 -授信额度。每月按负余额出账，逾期未付将暂停该用户的 API Key'
       },
       adjustBalance: '调整余额',
       adjustConcurrency: '调整并发数',
@@ -845,6 +848,17 @@ export default {
         unsupportedFrom: '请求值不受当前平台支持',
         unsupportedTo: '转发值不受当前平台支持',
         duplicateFrom: '请求值 A 不能重复',
+        volumeTiers: '月度消费阶梯',
+        volumeTiersHint: '按用户本自然月在该分组的实际消费判定档位，阶梯倍率与费率倍率相乘；单次请求整体按请求前所处档位计费。留空表示不启用。',
+        addVolumeTier: '添加档位',
+        removeVolumeTier: '删除档位',
+        volumeTierUpTo: '月消费低于（USD）',
+        volumeTierUpToPlaceholder: '例如 100',
+        volumeTierUnlimited: '及以上',
+        volumeTierMultiplier: '阶梯倍率',
+        volumeTierUpToRequired: '请填写该档位的消费上限',
+        volumeTierUpToIncreasing: '消费上限必须为正且逐档递增',
+        volumeTierMultiplierInvalid: '倍率必须为非负数',
         exclusiveLabel: '专属分组',
         exclusiveHint: '专属分组，可以手动指定给用户',
         platformLabel: '平台限制',
@@ -1088,6 +1102,29 @@ export default {
         sources: {
           route: '路由',
           detector: '内置识别'
+        },
+        split: {
+          enabled: '流量拆分（A/B 实验）',
+          hint: '按权重把命中的请求分配到多个分支。粘连模式下同一 API Key 或用户始终落在同一分支；各分支的延迟、错误率、Token 与成本从使用记录中对比统计。',
+          experiment: '实验名称',
+          stickyBy: '粘连维度',
+          arm: '分支',
+          weight: '权重',
+          addArm: '添加分支',
+          badge: '实验：{name}',
+          stats: '实验统计',
+          statsTitle: '实验 {name}（最近 7 天）',
+          requests: '请求数',
+          errorRate: '错误率',
+          latency: '平均延迟',
+          tokens: '输入 / 输出 Token',
+          cost: '成本（USD）',
+          failedToLoadStats: '加载实验统计失败',
+          sticky: {
+            none: '按请求',
+            apiKey: 'API Key',
+            user: '用户'
+          }
         }
       },
       claudeCode: {
@@ -1130,6 +1167,33 @@ export default {
         unsupportedMessage: '当前 Sub2API 服务端无法生成 Live 所需的设备证明，即使开启也不能使用。是否仍然开启？',
         enableAnyway: '仍然开启'
       },
+      geminiNative: {
+        title: 'Gemini 原生接口',
+        allow: '允许 Gemini generateContent',
+        hint: '启用后，此分组的 API Key 可调用 /v1beta/models/<model>:generateContent 与 :streamGenerateContent，请求会转换为 Anthropic Messages 或 OpenAI Responses，支持函数调用、内联图片与 usageMetadata。'
+      },
+      hedge: {
+        title: '请求对冲',
+        enabled: '为非流式请求启用对冲',
+        hint: '首个账号超过下方延迟仍未响应时，向第二个账号并发同一请求，先成功者返回，较慢一方被取消且只对胜出方计费。流式、previous_response_id、compact 与生图请求不会对冲。',
+        percentile: '延迟分位',
+        minDelayMs: '最小延迟（毫秒）',
+        maxDelayMs: '最大延迟（毫秒）',
+        delayHint: '对冲延迟 = 该账号近期耗时在此分位的取值，并限制在 [最小, 最大] 之间；样本不足时使用最大延迟。',
+        stats: '最近 {days} 天：触发 {count} 次，对冲胜出 {rate}%，估算额外上游成本 {cost} USD'
+      },
+      mirror: {
+        title: '流量镜像',
+        enabled: '将抽样请求镜像到候选上游',
+        hint: '按比例抽取本分组的 messages / responses / chat-completions 请求，在后台重放到下方候选目标。客户端只会收到主请求的响应，镜像调用不计费，每一对请求都会记录到运维监控中用于对比。',
+        sampleRate: '抽样比例（0-1）',
+        targetGroup: '候选分组',
+        sameGroup: '本分组',
+        targetAccount: '候选账号 ID',
+        anyAccount: '候选分组内任意账号',
+        compareOutput: '保存输出摘录与相似度',
+        targetHint: '可指定候选分组、账号 ID 或两者。镜像到本分组时必须指定账号；账号必须属于候选分组，composite 分组不能作为候选。'
+      },
       invalidRequestFallback: {
         title: '无效请求兜底分组',
         hint: '仅当上游明确返回 prompt too long 时才会触发，留空表示不兜底',
@@ -731,6 +731,7 @@ export default {
         providerWxpay: '微信官方',
         providerStripe: 'Stripe',
         providerAirwallex: 'Airwallex',
+        providerPayPal: 'PayPal',
         typeDisabled: '类型已禁用',
         enableTypesFirst: '请先在上方启用至少一种服务商',
         easypayRedirect: '跳转',
@@ -771,7 +772,12 @@ export default {
         field_currency: '支付币种',
         field_accountId: 'Airwallex 账户 ID',
         field_airwallexApiBaseHint: '必须和 API Key 所属环境一致：沙箱/测试密钥使用 https://api-demo.airwallex.com/api/v1，生产密钥使用 https://api.airwallex.com/api/v1。环境混用会返回 credentials_invalid / Access Denied。',
-        field_paymentCurrencyHint: '默认 CNY。Stripe 和 Airwallex 可按账户支持从下拉项选择 HKD、USD 等币种；微信、支付宝、易支付仍按 CNY。',
+        field_paymentCurrencyHint: '默认 CNY。Stripe、Airwallex 和 PayPal 可按账户支持从下拉项选择 HKD、USD 等币种；微信、支付宝、易支付仍按 CNY。',
+        field_clientSecret: 'Client Secret',
+        field_webhookId: 'Webhook ID',
+        field_webhookIdHint: '在 PayPal 开发者后台添加下方 Webhook 地址后显示的 Webhook ID，用于校验事件签名。',
+        field_brandName: 'PayPal 收银台显示的商户名称',
+        field_paypalApiBaseHint: '必须和 REST 应用所属环境一致：沙箱应用使用 https://api-m.sandbox.paypal.com，正式应用使用 https://api-m.paypal.com。',
         field_accountIdHint: '不涉及多账户、组织级密钥或连接账户收款时可以不填；单账户 Scoped API Key 会默认使用所选账户。',
         field_cid: '支付渠道 ID',
         field_cidAlipay: '支付宝渠道 ID',
@@ -786,6 +792,7 @@ export default {
         stripeWebhookHint: '请在 Stripe Dashboard 中将以下地址配置为 Webhook 端点：',
         stripeWebhookApiVersionHint: 'Webhook 端点的 API 版本请与当前集成的 Stripe SDK 对齐，建议选择 {version}；版本不一致可能导致回调事件解析失败。',
         airwallexWebhookHint: '请在 Airwallex 后台将以下地址配置为 Webhook 端点；事件至少选择 Payment Intent -> Succeeded（payment_intent.succeeded），建议同时选择 Payment Intent -> Cancelled（payment_intent.cancelled）；API version 选择账户默认或最新稳定版本。',
+        paypalWebhookHint: '请在 PayPal REST 应用中将以下地址添加为 Webhook，事件至少选择 Checkout order approved（CHECKOUT.ORDER.APPROVED）和 Payment capture completed（PAYMENT.CAPTURE.COMPLETED），然后把 Webhook ID 填入上方字段。',
         airwallexGuideSummary: '创建 Airwallex Scoped API 密钥时，建议只在账户级权限中为 Payment Acceptance 勾选读取和写入。',
         airwallexGuideNote: '不需要勾选 Spend、Payouts、Transfers、Funds Splits、POS 终端等与在线收款无关的权限。Webhook 事件至少选择 payment_intent.succeeded，建议同时选择 payment_intent.cancelled；API version 选择账户默认或最新稳定版本。',
         limitsTitle: '限额配置',
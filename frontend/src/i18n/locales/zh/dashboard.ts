@@ -31,6 +31,25 @@ export default {
       noLimit: '不限制',
       disabled: '已禁用',
     },
+    balanceForecast: {
+      title: '余额预测',
+      spendable: '可用余额',
+      includesCredit: '含授信额度 {amount}',
+      avgDailySpend: '近 {days} 天日均消费',
+      daysRemaining: '预计可用天数',
+      daysValue: '{days} 天',
+      exhaustedAt: '预计在 {time} 左右用完',
+      exhausted: '可用余额已用完，请充值后继续使用 API。',
+      noSpend: '近 {days} 天没有余额消费，暂无法预测。',
+    },
+    volumeTiers: {
+      title: '阶梯计价',
+      monthSpend: '本月消费',
+      currentTier: '第 {tier} 档',
+      multiplier: '×{value}',
+      nextTierAt: '再消费 {amount} 进入下一档',
+      topTier: '已达最高档',
+    },
     tokenUsageTrend: 'Token 使用趋势',
     noDataAvailable: '暂无数据',
     model: '模型',
@@ -305,6 +324,31 @@ export default {
     exportCancelled: '导出已取消',
     exporting: '导出中...',
     preparingExport: '正在准备导出...',
+    fileExports: {
+      open: '导出文件',
+      title: '用量文件导出',
+      description: '按所选日期生成完整的 CSV 或 Parquet 用量文件。大范围导出在后台处理，下载链接短时间内有效。',
+      range: '范围：{start} 至 {end}',
+      create: '生成文件',
+      creating: '提交中...',
+      created: '导出已排队，文件稍后可下载',
+      createFailed: '创建导出失败',
+      loadFailed: '加载导出记录失败',
+      downloadFailed: '获取下载链接失败',
+      empty: '暂无导出记录',
+      period: '时间段',
+      format: '格式',
+      status: '状态',
+      rows: '行数',
+      size: '大小',
+      download: '下载',
+      statuses: {
+        pending: '排队中',
+        running: '生成中',
+        succeeded: '可下载',
+        failed: '失败'
+      }
+    },
     model: '模型',
     requestedModel: '请求',
     upstreamModel: '上游',
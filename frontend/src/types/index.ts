@@ -89,6 +89,7 @@ export interface User {
   frozen_balance?: number // Balance currently held by async batch jobs
   concurrency: number // Allowed concurrent requests
   rpm_limit?: number // User-level RPM cap (0 = unlimited); effective as fallback when group has no rpm_limit
+  credit_limit?: number // Postpaid credit line in USD (0 = prepaid only); balance may go down to -credit_limit
   status: 'active' | 'disabled' // Account status
   allowed_groups: number[] | null // Allowed group IDs (null = all non-exclusive groups)
   balance_notify_enabled: boolean
@@ -543,6 +544,13 @@ export interface ReasoningEffortMapping {
   to: string
 }
 
+// VolumeTier is one band of a group's cumulative monthly spend pricing.
+// The last band omits up_to_usd (open-ended).
+export interface VolumeTier {
+  up_to_usd?: number | null
+  multiplier: number
+}
+
 export interface Group {
   id: number
   name: string
@@ -552,6 +560,7 @@ export interface Group {
   rpm_limit?: number // Group-level RPM cap (0 = unlimited); overrides user-level rpm_limit when set
   max_reasoning_effort?: string // OpenAI/Codex reasoning ceiling; empty means unlimited
   reasoning_effort_mappings?: ReasoningEffortMapping[]
+  volume_tiers?: VolumeTier[]
   is_exclusive: boolean
   status: 'active' | 'inactive'
   subscription_type: SubscriptionType
@@ -595,6 +604,10 @@ export interface Group {
   allow_messages_dispatch?: boolean
   // OpenAI Live 接口开关
   allow_live: boolean
+  // anthropic / openai 分组是否服务 Gemini 原生 generateContent
+  allow_gemini_native?: boolean
+  hedge_policy?: GroupHedgePolicy
+  mirror_policy?: GroupMirrorPolicy
   default_mapped_model?: string
   messages_dispatch_model_config?: OpenAIMessagesDispatchModelConfig
   require_oauth_only: boolean
@@ -639,6 +652,42 @@ export interface ModelsListConfig {
   models: string[]
 }
 
+// 非流式 OpenAI 请求对冲策略：首个账号超过 percentile 分位耗时仍未响应时向第二个账号并发
+export interface GroupHedgePolicy {
+  enabled: boolean
+  percentile?: number
+  min_delay_ms?: number
+  max_delay_ms?: number
+}
+
+// 流量镜像：抽样把请求异步重放到候选分组/账号，镜像响应不返回给客户端、不计费，只保留对比记录
+export interface GroupMirrorPolicy {
+  enabled: boolean
+  sample_rate?: number
+  target_group_id?: number | null
+  target_account_id?: number | null
+  compare_output?: boolean
+}
+
+export interface GroupHedgeDailyStat {
+  date: string
+  hedged_count: number
+  hedge_win_count: number
+  primary_win_count: number
+  hedge_cost: number
+}
+
+export interface GroupHedgeStatsSummary {
+  group_id: number
+  days: number
+  hedged_count: number
+  hedge_win_count: number
+  primary_win_count: number
+  hedge_win_rate: number
+  hedge_cost: number
+  daily: GroupHedgeDailyStat[]
+}
+
 export type CompositeRouteMatchType = 'exact' | 'prefix'
 
 export type CompositeRouteEndpoint =
@@ -653,6 +702,48 @@ export type CompositeRouteEndpoint =
 
 export type CompositeRouteSource = 'route' | 'detector' | string
 
+export type CompositeRouteStickyBy = 'none' | 'api_key' | 'user'
+
+export interface CompositeRouteSplitArm {
+  name: string
+  weight: number
+  target_platform: Exclude<GroupPlatform, 'composite'>
+  upstream_model?: string
+}
+
+/** Weighted / sticky traffic split that turns a route into a named A/B experiment. */
+export interface CompositeRouteSplit {
+  experiment: string
+  sticky_by?: CompositeRouteStickyBy
+  arms: CompositeRouteSplitArm[]
+}
+
+export interface CompositeRouteArmStat {
+  arm: string
+  target_platform: string
+  upstream_model: string
+  weight: number
+  assigned: number
+  success_count: number
+  error_count: number
+  error_rate: number
+  avg_duration_ms: number
+  avg_first_token_ms: number
+  input_tokens: number
+  output_tokens: number
+  total_cost: number
+  actual_cost: number
+}
+
+export interface CompositeRouteExperimentStats {
+  group_id: number
+  route_id: number
+  experiment: string
+  start_time: string
+  end_time: string
+  arms: CompositeRouteArmStat[]
+}
+
 export interface CompositeModelRoute {
   id: number
   group_id: number
@@ -664,6 +755,7 @@ export interface CompositeModelRoute {
   priority: number
   enabled: boolean
   notes: string
+  split?: CompositeRouteSplit | null
   created_at?: string
   updated_at?: string
 }
@@ -677,6 +769,7 @@ export interface CompositeModelRouteInput {
   priority?: number
   enabled?: boolean
   notes?: string
+  split?: CompositeRouteSplit | null
 }
 
 export interface CompositeRoutePreviewRequest {
@@ -693,6 +786,8 @@ export interface CompositeRouteDecision {
   upstream_model: string
   endpoint: CompositeRouteEndpoint
   route?: CompositeModelRoute
+  experiment?: string
+  arm?: string
   reason?: string
 }
 
@@ -802,6 +897,9 @@ export interface CreateGroupRequest {
   models_list_config?: ModelsListConfig
   allow_messages_dispatch?: boolean
   allow_live?: boolean
+  allow_gemini_native?: boolean
+  hedge_policy?: GroupHedgePolicy
+  mirror_policy?: GroupMirrorPolicy
   default_mapped_model?: string
   messages_dispatch_model_config?: OpenAIMessagesDispatchModelConfig
   model_routing?: Record<string, number[]> | null
@@ -809,6 +907,7 @@ export interface CreateGroupRequest {
   rpm_limit?: number
   max_reasoning_effort?: string
   reasoning_effort_mappings?: ReasoningEffortMapping[]
+  volume_tiers?: VolumeTier[]
   require_oauth_only?: boolean
   require_privacy_set?: boolean
   // 从指定分组复制账号
@@ -862,6 +961,9 @@ export interface UpdateGroupRequest {
   models_list_config?: ModelsListConfig
   allow_messages_dispatch?: boolean
   allow_live?: boolean
+  allow_gemini_native?: boolean
+  hedge_policy?: GroupHedgePolicy
+  mirror_policy?: GroupMirrorPolicy
   default_mapped_model?: string
   messages_dispatch_model_config?: OpenAIMessagesDispatchModelConfig
   model_routing?: Record<string, number[]> | null
@@ -869,6 +971,7 @@ export interface UpdateGroupRequest {
   rpm_limit?: number
   max_reasoning_effort?: string
   reasoning_effort_mappings?: ReasoningEffortMapping[]
+  volume_tiers?: VolumeTier[]
   require_oauth_only?: boolean
   require_privacy_set?: boolean
   copy_accounts_from_group_ids?: number[]
@@ -1951,6 +2054,7 @@ export interface UpdateUserRequest {
   balance?: number
   concurrency?: number
   rpm_limit?: number
+  credit_limit?: number
   status?: 'active' | 'disabled'
   allowed_groups?: number[] | null
   // 用户专属分组倍率配置 (group_id -> rate_multiplier | null)
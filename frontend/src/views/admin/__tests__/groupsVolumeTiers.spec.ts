@@ -0,0 +1,64 @@
+import { describe, expect, it } from "vitest";
+
+import {
+  createVolumeTierRow,
+  validateVolumeTiers,
+  volumeTiersToAPI,
+  volumeTiersToRows,
+} from "../groupsVolumeTiers";
+
+describe("groupsVolumeTiers", () => {
+  it("round-trips API tiers and always sends the last tier open-ended", () => {
+    const rows = volumeTiersToRows([
+      { up_to_usd: 100, multiplier: 1 },
+      { up_to_usd: 500, multiplier: 0.9 },
+      { multiplier: 0.8 },
+    ]);
+    expect(rows.map((row) => row.up_to_usd)).toEqual([100, 500, null]);
+
+    rows[2].up_to_usd = 900;
+    expect(volumeTiersToAPI(rows)).toEqual([
+      { up_to_usd: 100, multiplier: 1 },
+      { up_to_usd: 500, multiplier: 0.9 },
+      { multiplier: 0.8 },
+    ]);
+    expect(volumeTiersToRows(null)).toEqual([]);
+    expect(volumeTiersToAPI([])).toEqual([]);
+  });
+
+  it("creates rows with unique ids and a neutral multiplier", () => {
+    const a = createVolumeTierRow();
+    const b = createVolumeTierRow();
+    expect(a.id).not.toBe(b.id);
+    expect(a.multiplier).toBe(1);
+    expect(a.up_to_usd).toBeNull();
+  });
+
+  it("requires increasing bounds on every tier but the last", () => {
+    const rows = [
+      { id: "a", up_to_usd: 100, multiplier: 1 },
+      { id: "b", up_to_usd: 100, multiplier: 0.9 },
+      { id: "c", up_to_usd: null, multiplier: 0.8 },
+    ];
+    expect(validateVolumeTiers(rows)).toEqual({
+      b: { up_to_usd: "volumeTierUpToIncreasing" },
+    });
+
+    expect(
+      validateVolumeTiers([
+        { id: "a", up_to_usd: null, multiplier: 1 },
+        { id: "b", up_to_usd: null, multiplier: -1 },
+      ]),
+    ).toEqual({
+      a: { up_to_usd: "volumeTierUpToRequired" },
+      b: { multiplier: "volumeTierMultiplierInvalid" },
+    });
+
+    expect(
+      validateVolumeTiers([
+        { id: "a", up_to_usd: 50, multiplier: 1 },
+        { id: "b", up_to_usd: null, multiplier: 0.5 },
+      ]),
+    ).toEqual({});
+  });
+});
@@ -0,0 +1,74 @@
+import type { VolumeTier } from "@/types";
+
+export interface VolumeTierRow {
+  id: string;
+  up_to_usd: number | null;
+  multiplier: number | null;
+}
+
+export type VolumeTierErrorCode =
+  | "volumeTierUpToRequired"
+  | "volumeTierUpToIncreasing"
+  | "volumeTierMultiplierInvalid";
+
+export type VolumeTierErrors = Record<
+  string,
+  Partial<Record<"up_to_usd" | "multiplier", VolumeTierErrorCode>>
+>;
+
+let nextVolumeTierRowID = 0;
+
+export function createVolumeTierRow(
+  tier: Partial<VolumeTier> = {},
+): VolumeTierRow {
+  nextVolumeTierRowID += 1;
+  return {
+    id: `volume-tier-${nextVolumeTierRowID}`,
+    up_to_usd: tier.up_to_usd ?? null,
+    multiplier: tier.multiplier ?? 1,
+  };
+}
+
+export function volumeTiersToRows(
+  tiers?: VolumeTier[] | null,
+): VolumeTierRow[] {
+  return (tiers ?? []).map((tier) => createVolumeTierRow(tier));
+}
+
+// The last row is always sent open-ended, matching the backend rule that only
+// the final tier may (and must) omit up_to_usd.
+export function volumeTiersToAPI(rows: VolumeTierRow[]): VolumeTier[] {
+  return rows.map((row, index) => {
+    const tier: VolumeTier = { multiplier: Number(row.multiplier ?? 0) };
+    if (index < rows.length - 1 && row.up_to_usd != null) {
+      tier.up_to_usd = Number(row.up_to_usd);
+    }
+    return tier;
+  });
+}
+
+export function validateVolumeTiers(rows: VolumeTierRow[]): VolumeTierErrors {
+  const errors: VolumeTierErrors = {};
+  let previous = 0;
+  rows.forEach((row, index) => {
+    const multiplier = row.multiplier;
+    if (
+      multiplier == null ||
+      !Number.isFinite(Number(multiplier)) ||
+      Number(multiplier) < 0
+    ) {
+      errors[row.id] = { ...errors[row.id], multiplier: "volumeTierMultiplierInvalid" };
+    }
+    if (index === rows.length - 1) return;
+    const upTo = row.up_to_usd;
+    if (upTo == null || !Number.isFinite(Number(upTo))) {
+      errors[row.id] = { ...errors[row.id], up_to_usd: "volumeTierUpToRequired" };
+      return;
+    }
+    if (Number(upTo) <= previous) {
+      errors[row.id] = { ...errors[row.id], up_to_usd: "volumeTierUpToIncreasing" };
+    }
+    previous = Math.max(previous, Number(upTo));
+  });
+  return errors;
+}
@@ -142,6 +142,7 @@ function mountUsageView() {
         GroupDistributionChart: chartStub,
         EndpointDistributionChart: chartStub,
         TokenUsageTrend: chartStub,
+        UsageFileExportsDialog: true,
       },
     },
   })